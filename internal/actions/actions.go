@@ -43,6 +43,31 @@ records whether the action was approved or denied, by whom, and when.
 An action may be approved or denied multiple times.
 Approval is denied if there is at least one denial.
 
+# Deferred actions
+
+An action that cannot be carried out yet, but may be later (for example,
+because of a posting policy; see [golang.org/x/oscar/internal/policy]),
+can return an error wrapping [ErrDeferred]. The action is then left
+pending, and [Run] tries it again the next time it is called.
+
+# Coalesced actions
+
+An [Actioner] that also implements [Coalescer] reports the target of each of its
+actions, such as the GitHub comment that an action edits. When a coalescing delay
+is set with [SetCoalesceDelay], [Run] waits until the newest pending action for a
+target is at least that old before running it, and marks all older pending actions
+of the same kind for that target as done without running them. Rapid successive
+updates to the same comment thus collapse into a single edit.
+
+# Deleting old entries
+
+[DeleteBefore] deletes the entries of actions completed before a given time,
+to keep the log from growing without bound. It leaves behind a small tombstone
+for each deleted entry, recording its kind, key, times, result and error,
+so that components that rely on the log to perform an action only once
+still find the entry with [Get], and the before function still refuses to
+log the action again. Only the encoded action and approval decisions are lost.
+
 # Other DB entries
 
 This package stores other relationships in the database besides
@@ -54,6 +79,10 @@ list when they are first logged, and removed when they have been approved (if ne
 and executed. (We cannot use a [timed.Watcher] for this purpose, because approvals can
 happen out of order.)
 
+Keys beginning with "action.Tombstone" store the tombstones of entries
+deleted by [DeleteBefore]. The rest of the key is the deleted entry's key,
+and the value is the JSON of what remains of the entry.
+
 Keys beginning with "action.Wallclock" map wall clock times ([time.Time] values)
 to DBTimes. The mapping facilitates common log queries, like "show me the last hour
 of logs." The keys have the form
@@ -66,6 +95,7 @@ same wall clock time.
 package actions
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -75,6 +105,7 @@ import (
 	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -83,10 +114,15 @@ import (
 	"rsc.io/ordered"
 )
 
+// ErrDeferred is wrapped by errors returned from [Actioner.Run]
+// for actions that should be left pending and tried again later.
+var ErrDeferred = errors.New("action deferred")
+
 const (
 	logKind     = "action.Log"       // everything in the log
 	wallKind    = "action.Wallclock" // mapping from time.Time to timed.DBTime
 	pendingKind = "action.Pending"   // unexecuted actions
+	tombKind    = "action.Tombstone" // remains of deleted entries
 )
 
 // An Entry is one entry in the action log.
@@ -103,6 +139,9 @@ type Entry struct {
 	// Fields for approval
 	ApprovalRequired bool
 	Decisions        []Decision // approval decisions
+	// Superseded is the key of a newer action of the same kind that
+	// replaced this one, which was not run (see [Coalescer]).
+	Superseded []byte
 }
 
 // IsDone reports whether e is done.
@@ -163,6 +202,7 @@ type entry struct {
 	Error            string
 	ApprovalRequired bool
 	Decisions        []decision
+	Superseded       []byte
 }
 
 // decision is the database representation of Decision.
@@ -185,6 +225,7 @@ func toEntry(e *entry) *Entry {
 		Result:           e.Result,
 		Error:            e.Error,
 		ApprovalRequired: e.ApprovalRequired,
+		Superseded:       e.Superseded,
 	}
 	for _, d := range e.Decisions {
 		e2.Decisions = append(e2.Decisions, Decision(d))
@@ -203,6 +244,7 @@ func fromEntry(e *Entry) *entry {
 		Result:           e.Result,
 		Error:            e.Error,
 		ApprovalRequired: e.ApprovalRequired,
+		Superseded:       e.Superseded,
 	}
 	for _, d := range e.Decisions {
 		e2.Decisions = append(e2.Decisions, decision(d))
@@ -220,6 +262,9 @@ func before(db storage.DB, actionKind string, key, action []byte, requiresApprov
 	if _, ok := timed.Get(db, logKind, dkey); ok {
 		return false
 	}
+	if _, ok := db.Get(tombKey(dkey)); ok {
+		return false
+	}
 	e := &entry{
 		Created:          time.Now(), // wall clock time
 		Kind:             actionKind,
@@ -234,11 +279,15 @@ func before(db storage.DB, actionKind string, key, action []byte, requiresApprov
 // Get looks up the Entry associated with the given arguments.
 // If there is no entry for key in the database, Get returns nil, false.
 // Otherwise it returns the entry and true.
+// For an entry deleted by [DeleteBefore], Get returns what remains
+// of it: the entry without its Action and Decisions.
 func Get(db storage.DB, actionKind string, key []byte) (*Entry, bool) {
 	dkey := dbKey(actionKind, key)
 	e, ok := getEntry(db, dkey)
 	if !ok {
-		return nil, false
+		if e, ok = getTombstone(db, dkey); !ok {
+			return nil, false
+		}
 	}
 	return toEntry(e), true
 }
@@ -270,7 +319,8 @@ func ReRunAction(ctx context.Context, lg *slog.Logger, db storage.DB, actionKind
 	if e.Error == "" {
 		return errors.New("did not fail")
 	}
-	return runEntry(ctx, lg, db, e)
+	_, err = runEntry(ctx, lg, db, e)
+	return err
 }
 
 // AddDecision adds a Decision to the action referred to by actionKind,
@@ -303,6 +353,59 @@ func (e *Entry) Approved() bool {
 	return fromEntry(e).approved()
 }
 
+// A Status summarizes the state of an action in the log.
+type Status string
+
+const (
+	StatusPending    Status = "pending"    // waiting for approval
+	StatusApproved   Status = "approved"   // approved (or not requiring approval) and waiting to run
+	StatusDenied     Status = "denied"     // denied, so never run
+	StatusRun        Status = "run"        // run successfully
+	StatusFailed     Status = "failed"     // run with an error
+	StatusSuperseded Status = "superseded" // replaced by a newer action, so never run
+)
+
+// Statuses lists the possible values of [Entry.Status].
+var Statuses = []Status{StatusPending, StatusApproved, StatusDenied, StatusRun, StatusFailed, StatusSuperseded}
+
+// Status returns the status of the action in e.
+func (e *Entry) Status() Status {
+	switch {
+	case e.Superseded != nil:
+		return StatusSuperseded
+	case e.IsDone() && e.Error != "":
+		return StatusFailed
+	case e.IsDone():
+		return StatusRun
+	case e.Approved():
+		return StatusApproved
+	case len(e.Decisions) > 0:
+		// Not approved, with decisions: at least one is a denial.
+		return StatusDenied
+	}
+	return StatusPending
+}
+
+// Issue returns the GitHub project and issue (or pull request) number
+// that the action in e is about, if its key says.
+// Most action kinds use keys that contain a project such as "golang/go"
+// followed by a number, possibly after other values;
+// Issue reports the first such pair.
+func (e *Entry) Issue() (project string, issue int64, ok bool) {
+	vals, err := ordered.DecodeAny(e.Key)
+	if err != nil {
+		return "", 0, false
+	}
+	for i := 0; i+1 < len(vals); i++ {
+		p, ok1 := vals[i].(string)
+		n, ok2 := vals[i+1].(int64)
+		if ok1 && ok2 && strings.Contains(p, "/") {
+			return p, n, true
+		}
+	}
+	return "", 0, false
+}
+
 func (e *entry) approved() bool {
 	if !e.ApprovalRequired {
 		return true
@@ -399,6 +502,28 @@ type Actioner interface {
 	ForDisplay([]byte) string
 }
 
+// A Coalescer is an [Actioner] whose pending actions can be coalesced.
+// See the package documentation for details.
+type Coalescer interface {
+	Actioner
+	// Target returns a string identifying what the action modifies,
+	// such as the URL of a GitHub comment, or "" if the action
+	// should never be coalesced.
+	// The action is provided in serialized form, as with Run.
+	Target([]byte) string
+}
+
+// coalesceDelay is the coalescing delay set by [SetCoalesceDelay].
+var coalesceDelay atomic.Int64
+
+// SetCoalesceDelay sets the delay that [Run] waits after the newest
+// pending action for a target of a [Coalescer] is logged before running it,
+// and enables coalescing of older pending actions for the same target.
+// A delay of zero, the default, disables coalescing.
+func SetCoalesceDelay(d time.Duration) {
+	coalesceDelay.Store(int64(d))
+}
+
 // BeforeFunc is the type of functions that are called to log an action before it is run.
 // It writes an entry to db's action log with the given key and a representation
 // of the action. The key must be created with [ordered.Encode].
@@ -430,8 +555,9 @@ func Register(actionKind string, a Actioner) BeforeFunc {
 func Run(ctx context.Context, lg *slog.Logger, db storage.DB) error {
 	// Scan all pending actions, from earliest to latest.
 	var errs []error
+	newest := newestPending(lg, db)
 	for te := range timed.ScanAfter(lg, db, pendingKind, 0, nil) {
-		if _, err := maybeRunEntry(ctx, lg, db, te.Key); err != nil {
+		if _, err := maybeRunEntry(ctx, lg, db, te.Key, newest); err != nil {
 			lg.Error("action failed", "key", storage.Fmt(te.Key), "err", err)
 			errs = append(errs, err)
 		}
@@ -442,7 +568,8 @@ func Run(ctx context.Context, lg *slog.Logger, db storage.DB) error {
 // A RunReport contains information about an action log run.
 type RunReport struct {
 	Completed int     // the number of actions successfully completed
-	Skipped   int     // the number of actions skipped
+	Skipped   int     // the number of actions skipped (unapproved, deferred or delayed)
+	Coalesced int     // the number of actions superseded by newer ones (see [Coalescer])
 	Errors    []error // the errors returned by actions that failed
 }
 
@@ -450,23 +577,79 @@ type RunReport struct {
 // about the run.
 func RunWithReport(ctx context.Context, lg *slog.Logger, db storage.DB) *RunReport {
 	report := &RunReport{}
+	newest := newestPending(lg, db)
 	for te := range timed.ScanAfter(lg, db, pendingKind, 0, nil) {
-		if done, err := maybeRunEntry(ctx, lg, db, te.Key); err != nil {
+		if status, err := maybeRunEntry(ctx, lg, db, te.Key, newest); err != nil {
 			lg.Error("action failed", "key", storage.Fmt(te.Key), "err", err)
 			report.Errors = append(report.Errors, err)
-		} else if done {
-			report.Completed++
 		} else {
-			report.Skipped++
+			switch status {
+			case completed:
+				report.Completed++
+			case coalesced:
+				report.Coalesced++
+			default:
+				report.Skipped++
+			}
 		}
 	}
 	return report
 }
 
+// A runStatus is the outcome of a call to [maybeRunEntry].
+type runStatus int
+
+const (
+	skipped   runStatus = iota // not run
+	completed                  // run and not deferred
+	coalesced                  // superseded by a newer action and not run
+)
+
+// newestPending returns a map from the kind and target of each
+// pending action of a [Coalescer] to the newest such action,
+// or nil if coalescing is disabled.
+func newestPending(lg *slog.Logger, db storage.DB) map[string]*entry {
+	if coalesceDelay.Load() <= 0 {
+		return nil
+	}
+	newest := make(map[string]*entry)
+	for te := range timed.ScanAfter(lg, db, pendingKind, 0, nil) {
+		e, ok := getEntry(db, te.Key)
+		if !ok {
+			continue
+		}
+		t := coalesceTarget(e)
+		if t == "" {
+			continue
+		}
+		if n, ok := newest[t]; !ok || !e.Created.Before(n.Created) {
+			newest[t] = e
+		}
+	}
+	return newest
+}
+
+// coalesceTarget returns the key identifying the target of e
+// for coalescing, or "" if e cannot be coalesced.
+func coalesceTarget(e *entry) string {
+	c, ok := lookupActioner(e.Kind).(Coalescer)
+	if !ok {
+		return ""
+	}
+	t := c.Target(e.Action)
+	if t == "" {
+		return ""
+	}
+	return e.Kind + "\x00" + t
+}
+
 // maybeRunEntry runs the entry with dkey if it is ready.
 // It locks the entry's DB key so that it can check the entry's status and run it atomically.
-// done reports whether the action was completed or not.
-func maybeRunEntry(ctx context.Context, lg *slog.Logger, db storage.DB, dkey []byte) (done bool, _ error) {
+// If newest is not nil, it maps coalescing targets to their newest pending actions
+// (see [newestPending]): an older action is marked done without running,
+// and the newest action is run only once the coalescing delay has passed.
+// The returned status reports whether the action was completed, coalesced or skipped.
+func maybeRunEntry(ctx context.Context, lg *slog.Logger, db storage.DB, dkey []byte, newest map[string]*entry) (runStatus, error) {
 	// dkey includes the action kind and user key (third arg to [before]), but not the logKind.
 	// e.Key is only the user key.
 	lockName := logKind + "-" + string(dkey)
@@ -480,17 +663,35 @@ func maybeRunEntry(ctx context.Context, lg *slog.Logger, db storage.DB, dkey []b
 	}
 	if !e.Done.IsZero() {
 		// This action was already run. It should have been removed from the pending list.
-		return false, fmt.Errorf("done action %s on pending list", storage.Fmt(dkey))
+		return skipped, fmt.Errorf("done action %s on pending list", storage.Fmt(dkey))
+	}
+	if n, ok := newest[coalesceTarget(e)]; ok {
+		if !bytes.Equal(n.Key, e.Key) {
+			lg.Info("action log: coalesced", "kind", e.Kind, "key", storage.Fmt(e.Key), "newer", storage.Fmt(n.Key))
+			e.Done = time.Now()
+			e.Superseded = n.Key
+			setEntry(db, dkey, e)
+			return coalesced, nil
+		}
+		if time.Since(e.Created) < time.Duration(coalesceDelay.Load()) {
+			return skipped, nil
+		}
 	}
 	if !e.approved() {
-		return false, nil
+		return skipped, nil
+	}
+	done, err := runEntry(ctx, lg, db, e)
+	if done {
+		return completed, err
 	}
-	return true, runEntry(ctx, lg, db, e)
+	return skipped, err
 }
 
 // runEntry runs the action in entry e. It assumes it is ready to run (and so must
-// be called with a lock held). It returns the error resulting from the run.
-func runEntry(ctx context.Context, lg *slog.Logger, db storage.DB, e *entry) error {
+// be called with a lock held). It returns the error resulting from the run,
+// and reports whether the action is done: that is, whether it ran
+// and was not deferred (see [ErrDeferred]).
+func runEntry(ctx context.Context, lg *slog.Logger, db storage.DB, e *entry) (done bool, _ error) {
 	a := lookupActioner(e.Kind)
 	if a == nil {
 		// unreachable unless bug, or if an action kind was removed
@@ -499,6 +700,17 @@ func runEntry(ctx context.Context, lg *slog.Logger, db storage.DB, e *entry) err
 	}
 	lg.Info("action log: running", "kind", e.Kind, "key", storage.Fmt(e.Key))
 	result, err := a.Run(ctx, e.Action)
+	if errors.Is(err, ErrDeferred) {
+		lg.Info("action log: deferred", "kind", e.Kind, "key", storage.Fmt(e.Key), "err", err)
+		if !e.Done.IsZero() {
+			// A re-run of a failed action: put it back on the pending list.
+			e.Done = time.Time{}
+			e.Result = nil
+			e.Error = ""
+			setEntry(db, dbKey(e.Kind, e.Key), e)
+		}
+		return false, nil
+	}
 	// mark done
 	e.Done = time.Now()
 	e.Result = result
@@ -508,7 +720,7 @@ func runEntry(ctx context.Context, lg *slog.Logger, db storage.DB, e *entry) err
 		e.Error = ""
 	}
 	setEntry(db, dbKey(e.Kind, e.Key), e)
-	return err
+	return true, err
 }
 
 // ClearLogForTesting deletes the entire action log.
@@ -519,6 +731,60 @@ func ClearLogForTesting(_ *testing.T, db storage.DB) {
 		db.Panic("ClearLogForTesting: bad type", "type", dbt)
 	}
 	db.DeleteRange(ordered.Encode(logKind), ordered.Encode(logKind, ordered.Inf))
+	db.DeleteRange(ordered.Encode(tombKind), ordered.Encode(tombKind, ordered.Inf))
+}
+
+// DeleteBefore deletes the entries of the actions that were done
+// before time t, along with their wall clock mappings,
+// replacing each with a tombstone that [Get] still finds.
+// Pending actions are never deleted, however old.
+// It returns the number of entries deleted.
+func DeleteBefore(db storage.DB, t time.Time) int {
+	n := 0
+	minKept := timed.DBTime(math.MaxInt64) // oldest entry kept
+	b := db.Batch()
+	for te := range timed.Scan(db, logKind, nil, ordered.Encode(ordered.Inf)) {
+		e := unmarshalTimedEntry(te)
+		if e.Done.IsZero() || !e.Done.Before(t) {
+			minKept = min(minKept, e.ModTime)
+			continue
+		}
+		unlock := lockAction(db, e.Kind, e.Key)
+		// Check again with the lock held,
+		// in case the entry has changed.
+		if e, ok := getEntry(db, te.Key); ok && !e.Done.IsZero() && e.Done.Before(t) {
+			b.Set(tombKey(te.Key), storage.JSON(&entry{
+				Created: e.Created,
+				Kind:    e.Kind,
+				Key:     e.Key,
+				Done:    e.Done,
+				Result:  e.Result,
+				Error:   e.Error,
+			}))
+			timed.Delete(db, b, logKind, te.Key)
+			n++
+		} else if ok {
+			minKept = min(minKept, e.ModTime)
+		}
+		b.Apply()
+		unlock()
+	}
+	// Delete the wall clock mappings before t, except those
+	// ScanAfter needs to find the entries that remain.
+	for key := range db.Scan(ordered.Encode(wallKind), ordered.Encode(wallKind, t.UnixNano())) {
+		var dbt int64
+		if err := ordered.Decode(key, nil, nil, &dbt); err != nil {
+			// unreachable unless corrupt DB
+			db.Panic("actions.DeleteBefore decode", "key", storage.Fmt(key), "err", err)
+		}
+		if timed.DBTime(dbt) < minKept {
+			b.Delete(key)
+			b.MaybeApply()
+		}
+	}
+	b.Apply()
+	db.Flush()
+	return n
 }
 
 // unmarshalTimedEntry extracts an entry from a timed.Entry.
@@ -542,6 +808,26 @@ func lockAction(db storage.DB, actionKind string, key []byte) func() {
 	return func() { db.Unlock(name) }
 }
 
+// tombKey returns the key of the tombstone of the entry with key dkey.
+func tombKey(dkey []byte) []byte {
+	return append(ordered.Encode(tombKind), dkey...)
+}
+
+// getTombstone returns the remains of the entry with key dkey,
+// deleted by [DeleteBefore].
+func getTombstone(db storage.DB, dkey []byte) (*entry, bool) {
+	val, ok := db.Get(tombKey(dkey))
+	if !ok {
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal(val, &e); err != nil {
+		// unreachable unless bug in this package
+		db.Panic("actions: json.Unmarshal tombstone", "dkey", storage.Fmt(dkey), "err", err)
+	}
+	return &e, true
+}
+
 func getEntry(db storage.DB, dkey []byte) (*entry, bool) {
 	te, ok := timed.Get(db, logKind, dkey)
 	if !ok {
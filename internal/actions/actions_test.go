@@ -231,6 +231,50 @@ func TestApproved(t *testing.T) {
 	}
 }
 
+func TestStatus(t *testing.T) {
+	approve := Decision{Name: "n", Time: time.Now(), Approved: true}
+	deny := Decision{Name: "n", Time: time.Now(), Approved: false}
+	done := time.Now()
+	for _, test := range []struct {
+		e    *Entry
+		want Status
+	}{
+		{&Entry{}, StatusApproved},
+		{&Entry{ApprovalRequired: true}, StatusPending},
+		{&Entry{ApprovalRequired: true, Decisions: []Decision{approve}}, StatusApproved},
+		{&Entry{ApprovalRequired: true, Decisions: []Decision{deny}}, StatusDenied},
+		{&Entry{Done: done}, StatusRun},
+		{&Entry{Done: done, Error: "bad"}, StatusFailed},
+		{&Entry{Done: done, Superseded: []byte("k")}, StatusSuperseded},
+	} {
+		if got := test.e.Status(); got != test.want {
+			t.Errorf("%+v: got %s, want %s", test.e, got, test.want)
+		}
+	}
+}
+
+func TestIssue(t *testing.T) {
+	for _, test := range []struct {
+		key     []byte
+		project string
+		issue   int64
+		ok      bool
+	}{
+		{ordered.Encode("golang/go", 12), "golang/go", 12, true},
+		{ordered.Encode("post", "golang/go", 12, 3), "golang/go", 12, true},
+		{ordered.Encode("golang/go", "x"), "", 0, false},
+		{ordered.Encode("num", 23), "", 0, false},
+		{[]byte("not ordered"), "", 0, false},
+	} {
+		e := &Entry{Key: test.key}
+		project, issue, ok := e.Issue()
+		if project != test.project || issue != test.issue || ok != test.ok {
+			t.Errorf("%s: got (%q, %d, %t), want (%q, %d, %t)", storage.Fmt(test.key),
+				project, issue, ok, test.project, test.issue, test.ok)
+		}
+	}
+}
+
 func TestRun(t *testing.T) {
 	ctx := context.Background()
 	const actionKind = "akind"
@@ -238,6 +282,7 @@ func TestRun(t *testing.T) {
 	var errAction = errors.New("action failed")
 	lg := testutil.Slogger(t)
 	nRunCalls := 0
+	deferring := true
 
 	before := Register(actionKind, testActioner{
 		run: func(_ context.Context, action []byte) ([]byte, error) {
@@ -245,6 +290,9 @@ func TestRun(t *testing.T) {
 			if string(action) == "fail" {
 				return nil, errAction
 			}
+			if string(action) == "defer" && deferring {
+				return nil, fmt.Errorf("not now: %w", ErrDeferred)
+			}
 			return append([]byte("result "), action...), nil
 		},
 	})
@@ -352,6 +400,30 @@ func TestRun(t *testing.T) {
 		checkRunAndDone(key2, true)
 	})
 
+	t.Run("deferred", func(t *testing.T) {
+		check := testutil.Checker(t)
+		db := storage.MemDB()
+		before(db, key, []byte("defer"), !RequiresApproval)
+
+		// Deferred actions stay pending.
+		deferring = true
+		check(Run(ctx, lg, db))
+		if e, ok := Get(db, actionKind, key); !ok || e.IsDone() {
+			t.Fatal("deferred action is done")
+		}
+		if got := RunWithReport(ctx, lg, db); got.Skipped != 1 || got.Completed != 0 {
+			t.Errorf("RunWithReport = %+v, want 1 skipped", got)
+		}
+
+		// They run once they are no longer deferred.
+		deferring = false
+		check(Run(ctx, lg, db))
+		e, ok := Get(db, actionKind, key)
+		if !ok || !e.IsDone() || string(e.Result) != "result defer" {
+			t.Fatalf("action not run: %v", e)
+		}
+	})
+
 	t.Run("WithReport", func(t *testing.T) {
 		db := storage.MemDB()
 		before(db, ordered.Encode(0), []byte("a1"), !RequiresApproval)
@@ -446,6 +518,123 @@ func TestReRunAction(t *testing.T) {
 	}
 }
 
+func TestDeleteBefore(t *testing.T) {
+	const actionKind = "dkind"
+	db := storage.MemDB()
+	lg := testutil.Slogger(t)
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	// In the order they are logged, as wall clock times
+	// and DBTimes both increase.
+	for _, e := range []*entry{
+		{Key: []byte("old"), Created: old.Add(-time.Hour), Done: old.Add(-time.Hour)},                // deleted
+		{Key: []byte("failed"), Created: old.Add(-time.Hour), Done: old.Add(-time.Hour), Error: "x"}, // deleted
+		{Key: []byte("pending"), Created: old},                                                       // never deleted
+		{Key: []byte("recent"), Created: now, Done: now},                                             // done recently
+	} {
+		e.Kind = actionKind
+		setEntry(db, dbKey(actionKind, e.Key), e)
+	}
+	walls := func() int {
+		n := 0
+		for range db.Scan(ordered.Encode(wallKind), ordered.Encode(wallKind, ordered.Inf)) {
+			n++
+		}
+		return n
+	}
+
+	if n := DeleteBefore(db, now.Add(-time.Hour)); n != 2 {
+		t.Errorf("DeleteBefore = %d, want 2", n)
+	}
+	var keys []string
+	for e := range ScanAfter(lg, db, time.Time{}, nil) {
+		keys = append(keys, string(e.Key))
+	}
+	slices.Sort(keys)
+	if want := []string{"pending", "recent"}; !slices.Equal(keys, want) {
+		t.Errorf("after DeleteBefore, ScanAfter = %v, want %v", keys, want)
+	}
+	if n := DeleteBefore(db, now.Add(-time.Hour)); n != 0 {
+		t.Errorf("second DeleteBefore = %d, want 0", n)
+	}
+	// The wall clock mappings of the deleted entries are gone.
+	if n := walls(); n != 2 {
+		t.Errorf("after DeleteBefore, %d wall clock mappings, want 2", n)
+	}
+	// Deleted entries leave tombstones, so they are not logged again.
+	if e, ok := Get(db, actionKind, []byte("failed")); !ok || e.Error != "x" || !e.IsDone() {
+		t.Errorf("Get(failed) = %v, %t, want tombstone", e, ok)
+	}
+	before := Register(actionKind, testActioner{})
+	if before(db, []byte("old"), []byte("again"), false) {
+		t.Errorf("before(old) = true after DeleteBefore, want false")
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	ctx := context.Background()
+	const actionKind = "coalesce"
+	lg := testutil.Slogger(t)
+	var ran []string
+	before := Register(actionKind, testCoalescer{
+		testActioner: testActioner{
+			run: func(_ context.Context, action []byte) ([]byte, error) {
+				ran = append(ran, string(action))
+				return nil, nil
+			},
+		},
+		target: func(action []byte) string {
+			// "comment1 edit2" targets "comment1"; "post" has no target.
+			t, _, _ := strings.Cut(string(action), " ")
+			if t == "post" {
+				return ""
+			}
+			return t
+		},
+	})
+	defer SetCoalesceDelay(0)
+
+	db := storage.MemDB()
+	for i, a := range []string{"c1 e1", "post", "c1 e2", "c2 e1", "c1 e3"} {
+		before(db, ordered.Encode(i), []byte(a), !RequiresApproval)
+	}
+
+	// With a long delay, older edits of c1 are coalesced,
+	// and only the action without a target runs.
+	SetCoalesceDelay(time.Hour)
+	got := RunWithReport(ctx, lg, db)
+	want := &RunReport{Completed: 1, Skipped: 2, Coalesced: 2}
+	if !gcmp.Equal(got, want) {
+		t.Errorf("RunWithReport = %+v, want %+v", got, want)
+	}
+	if want := []string{"post"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+	e, ok := Get(db, actionKind, ordered.Encode(0))
+	if !ok || !e.IsDone() || !bytes.Equal(e.Superseded, ordered.Encode(4)) {
+		t.Errorf("first edit: got %v, want superseded by last edit", e)
+	}
+
+	// Once the delay has passed, the newest edits run.
+	SetCoalesceDelay(time.Nanosecond)
+	if err := Run(ctx, lg, db); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"post", "c2 e1", "c1 e3"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+}
+
+type testCoalescer struct {
+	testActioner
+	target func([]byte) string
+}
+
+func (t testCoalescer) Target(data []byte) string {
+	return t.target(data)
+}
+
 type testActioner struct {
 	Actioner
 	run func(context.Context, []byte) ([]byte, error)
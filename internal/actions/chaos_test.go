@@ -0,0 +1,133 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"golang.org/x/oscar/internal/chaos"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+	"rsc.io/ordered"
+)
+
+// An editActioner edits a fake GitHub, naming the edited object
+// in the URL path.
+type editActioner struct {
+	hc  *http.Client
+	url string
+}
+
+func (a *editActioner) Run(ctx context.Context, action []byte) ([]byte, error) {
+	resp, err := a.hc.Post(a.url+"/edit/"+string(action), "text/plain", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return []byte("edited"), nil
+}
+
+func (a *editActioner) ForDisplay(action []byte) string {
+	return string(action)
+}
+
+// TestChaos logs and runs actions while storage writes and GitHub edits
+// fail at random, retrying as Gaby does: components log their actions
+// again on every run, [Run] runs periodically, and failed actions
+// are re-run. It checks that every action is logged exactly once and
+// eventually succeeds, and that an edit is repeated only after a failure
+// hid whether it had been made.
+func TestChaos(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	in := chaos.New(lg, 1)
+	in.SetRate(chaos.Storage, 0.1)
+	in.SetRate(chaos.GitHub, 0.2)
+	db := in.DB(storage.MemDB())
+
+	var mu sync.Mutex
+	edits := make(map[string]int) // by path
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		edits[r.URL.Path]++
+	}))
+	defer srv.Close()
+
+	const kind = "chaosEdit"
+	before := Register(kind, &editActioner{hc: in.Client(chaos.GitHub, srv.Client()), url: srv.URL})
+
+	// try calls f, recovering from injected storage failures.
+	try := func(f func()) {
+		defer func() {
+			if e := recover(); e != nil {
+				if err, ok := e.(error); !ok || !errors.Is(err, chaos.ErrInjected) {
+					panic(e)
+				}
+			}
+		}()
+		f()
+	}
+
+	const N = 50
+	key := func(i int) []byte { return ordered.Encode(i) }
+	added := make([]int, N)
+	for round := 0; ; round++ {
+		if round == 100 {
+			t.Fatalf("actions not done after %d rounds", round)
+		}
+		for i := range N {
+			try(func() {
+				if before(db, key(i), []byte(fmt.Sprint(i)), !RequiresApproval) {
+					added[i]++
+				}
+			})
+		}
+		try(func() { Run(ctx, lg, db) })
+		done := 0
+		for i := range N {
+			e, ok := Get(db, kind, key(i))
+			switch {
+			case !ok || !e.IsDone():
+			case e.Error != "":
+				try(func() { ReRunAction(ctx, lg, db, kind, key(i)) })
+			default:
+				done++
+			}
+		}
+		if done == N {
+			break
+		}
+	}
+
+	for i, n := range added {
+		if n != 1 {
+			t.Errorf("action %d logged %d times, want 1", i, n)
+		}
+	}
+	if len(edits) != N {
+		t.Errorf("%d objects edited, want %d", len(edits), N)
+	}
+	repeats := -N
+	for _, n := range edits {
+		repeats += n
+	}
+	failures := in.Injected(chaos.Storage) + in.Injected(chaos.GitHub)
+	if repeats > failures {
+		t.Errorf("%d repeated edits, more than the %d injected failures", repeats, failures)
+	}
+	if in.Injected(chaos.Storage) == 0 || in.Injected(chaos.GitHub) == 0 {
+		t.Errorf("injected %d storage and %d GitHub failures; want some of each",
+			in.Injected(chaos.Storage), in.Injected(chaos.GitHub))
+	}
+	t.Logf("%d storage and %d GitHub failures injected; %d repeated edits",
+		in.Injected(chaos.Storage), in.Injected(chaos.GitHub), repeats)
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ann implements approximate nearest-neighbor search
+// over a [storage.VectorDB].
+//
+// A [VectorDB] wraps an underlying VectorDB, which continues to store
+// the vectors, and keeps an in-memory HNSW graph of them
+// (see Malkov and Yashunin, https://arxiv.org/abs/1603.09320).
+// Search then examines a few hundred vectors instead of all of them,
+// at the cost of occasionally missing one of the true nearest neighbors.
+// The graph is updated incrementally by Set, Delete and batches,
+// so it never needs to be rebuilt from scratch after the initial [VectorDB.Load].
+// The graph holds its own copy of each vector, so the index uses
+// at least as much memory as an in-memory underlying VectorDB
+// (see [VectorDB.MemoryUsage]).
+//
+// The tradeoff between recall and speed is set by [Config].
+package ann
+
+import (
+	"cmp"
+	"iter"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A Config configures the index of a [VectorDB].
+// Zero fields take their value from [DefaultConfig].
+type Config struct {
+	// M is the number of neighbors of each vector in the graph
+	// (twice as many in the bottom layer).
+	// Larger values improve recall, especially for high-dimensional
+	// vectors, at the cost of memory and insertion time.
+	M int
+
+	// EfConstruction is the number of candidates examined
+	// when inserting a vector. Larger values build a better graph,
+	// improving recall, at the cost of insertion time.
+	EfConstruction int
+
+	// EfSearch is the number of candidates examined by Search.
+	// Larger values improve recall at the cost of search time.
+	// Search always examines at least as many candidates as it returns.
+	EfSearch int
+
+	// MinSize is the number of vectors (of a given length)
+	// below which Search uses the exact search of the underlying VectorDB,
+	// which is fast enough for small databases.
+	MinSize int
+}
+
+// DefaultConfig is the default index configuration,
+// which favors recall: in tests, it finds nearly all
+// of the true nearest neighbors.
+var DefaultConfig = Config{
+	M:              16,
+	EfConstruction: 200,
+	EfSearch:       100,
+	MinSize:        10000,
+}
+
+// withDefaults returns c with zero fields set from [DefaultConfig].
+func (c Config) withDefaults() Config {
+	c.M = cmp.Or(c.M, DefaultConfig.M)
+	c.EfConstruction = cmp.Or(c.EfConstruction, DefaultConfig.EfConstruction)
+	c.EfSearch = cmp.Or(c.EfSearch, DefaultConfig.EfSearch)
+	c.MinSize = cmp.Or(c.MinSize, DefaultConfig.MinSize)
+	return c
+}
+
+// A VectorDB is a [storage.VectorDB] that implements Search
+// using an approximate nearest-neighbor index.
+// It is safe for concurrent use.
+type VectorDB struct {
+	slog *slog.Logger
+	base storage.VectorDB
+	cfg  Config
+
+	mu      sync.RWMutex
+	graphs  map[int]*graph  // graphs by vector length
+	loaded  bool            // Load has completed
+	touched map[string]bool // IDs changed during Load
+}
+
+var (
+	_ storage.VectorDB       = (*VectorDB)(nil)
+	_ storage.MemoryReporter = (*VectorDB)(nil)
+)
+
+// New returns a new VectorDB that stores its vectors in base
+// and indexes them according to cfg.
+// Until [VectorDB.Load] is called and returns,
+// Search uses the (exact) Search method of base.
+func New(lg *slog.Logger, base storage.VectorDB, cfg Config) *VectorDB {
+	return &VectorDB{
+		slog:    lg,
+		base:    base,
+		cfg:     cfg.withDefaults(),
+		graphs:  make(map[int]*graph),
+		touched: make(map[string]bool),
+	}
+}
+
+// Load indexes the vectors already stored in the underlying VectorDB.
+// Indexing a large database takes minutes, so servers
+// typically call Load in a separate goroutine.
+// Other methods may be called concurrently with Load.
+func (db *VectorDB) Load() {
+	start := time.Now()
+	n := 0
+	for id, vec := range db.base.All() {
+		v := vec()
+		db.mu.Lock()
+		// Set and Delete during Load update the graph directly,
+		// and v may already be out of date.
+		if !db.touched[id] {
+			db.graph(len(v)).set(id, v)
+			n++
+		}
+		db.mu.Unlock()
+	}
+	db.mu.Lock()
+	db.loaded = true
+	db.touched = nil
+	db.mu.Unlock()
+	db.slog.Info("ann: loaded index", "n", n, "latency", time.Since(start))
+}
+
+// graph returns the graph for vectors of length n, creating it if needed.
+// The caller must hold db.mu for writing.
+func (db *VectorDB) graph(n int) *graph {
+	g := db.graphs[n]
+	if g == nil {
+		g = newGraph(db.cfg)
+		db.graphs[n] = g
+	}
+	return g
+}
+
+// set indexes vec under id.
+// The caller must hold db.mu for writing.
+func (db *VectorDB) set(id string, vec llm.Vector) {
+	if db.touched != nil {
+		db.touched[id] = true
+	}
+	// The vector may have changed length.
+	for n, g := range db.graphs {
+		if n != len(vec) {
+			g.delete(id)
+		}
+	}
+	db.graph(len(vec)).set(id, vec)
+}
+
+// delete removes any vector with the given id from the index.
+// The caller must hold db.mu for writing.
+func (db *VectorDB) delete(id string) {
+	if db.touched != nil {
+		db.touched[id] = true
+	}
+	for _, g := range db.graphs {
+		g.delete(id)
+	}
+}
+
+// Set implements [storage.VectorDB.Set].
+func (db *VectorDB) Set(id string, vec llm.Vector) {
+	db.base.Set(id, vec)
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.set(id, vec)
+}
+
+// Delete implements [storage.VectorDB.Delete].
+func (db *VectorDB) Delete(id string) {
+	db.base.Delete(id)
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.delete(id)
+}
+
+// Get implements [storage.VectorDB.Get].
+func (db *VectorDB) Get(id string) (llm.Vector, bool) {
+	return db.base.Get(id)
+}
+
+// All implements [storage.VectorDB.All].
+func (db *VectorDB) All() iter.Seq2[string, func() llm.Vector] {
+	return db.base.All()
+}
+
+// Flush implements [storage.VectorDB.Flush].
+func (db *VectorDB) Flush() {
+	db.base.Flush()
+}
+
+// Search implements [storage.VectorDB.Search].
+// Once the index is loaded and holds at least [Config.MinSize]
+// vectors of the same length as vec, the results are approximate:
+// they are the most similar vectors found by examining
+// [Config.EfSearch] candidates, which are usually
+// but not always the most similar vectors overall.
+func (db *VectorDB) Search(vec llm.Vector, n int, keep storage.VectorFilter) []storage.VectorResult {
+	db.mu.RLock()
+	g := db.graphs[len(vec)]
+	if !db.loaded || g == nil || g.live() < db.cfg.MinSize {
+		db.mu.RUnlock()
+		return db.base.Search(vec, n, keep)
+	}
+	defer db.mu.RUnlock()
+
+	var res []storage.VectorResult
+	for _, c := range g.search(vec, n, db.cfg.EfSearch, keep) {
+		res = append(res, storage.VectorResult{ID: g.nodes[c.n].id, Score: c.sim})
+	}
+	// Order as the exact search does, breaking ties by ID.
+	slices.SortFunc(res, func(x, y storage.VectorResult) int {
+		if x.Score != y.Score {
+			return -cmp.Compare(x.Score, y.Score)
+		}
+		return -cmp.Compare(x.ID, y.ID)
+	})
+	return res
+}
+
+// MemoryUsage implements [storage.MemoryReporter].
+// It reports the memory used by the index added to that of
+// the underlying VectorDB, if it reports its memory use.
+// The limit and spilled items are those of the underlying VectorDB.
+func (db *VectorDB) MemoryUsage() storage.MemoryUsage {
+	var u storage.MemoryUsage
+	if mr, ok := db.base.(storage.MemoryReporter); ok {
+		u = mr.MemoryUsage()
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, g := range db.graphs {
+		u.Used += g.memory()
+	}
+	return u
+}
+
+// Batch implements [storage.VectorDB.Batch].
+func (db *VectorDB) Batch() storage.VectorBatch {
+	return &batch{db: db, b: db.base.Batch()}
+}
+
+// A batch is a [storage.VectorBatch] for a [VectorDB].
+// It records the operations to apply to the index
+// once they are applied to the underlying VectorDB.
+type batch struct {
+	db  *VectorDB
+	b   storage.VectorBatch
+	ops []op
+}
+
+// An op is a pending index operation: set vec, or delete if vec is nil.
+type op struct {
+	id  string
+	vec llm.Vector
+}
+
+// Set implements [storage.VectorBatch.Set].
+func (b *batch) Set(id string, vec llm.Vector) {
+	b.b.Set(id, vec)
+	if vec == nil {
+		vec = llm.Vector{}
+	}
+	b.ops = append(b.ops, op{id, slices.Clone(vec)})
+}
+
+// Delete implements [storage.VectorBatch.Delete].
+func (b *batch) Delete(id string) {
+	b.b.Delete(id)
+	b.ops = append(b.ops, op{id, nil})
+}
+
+// MaybeApply implements [storage.VectorBatch.MaybeApply].
+func (b *batch) MaybeApply() bool {
+	if !b.b.MaybeApply() {
+		return false
+	}
+	b.index()
+	return true
+}
+
+// Apply implements [storage.VectorBatch.Apply].
+func (b *batch) Apply() {
+	b.b.Apply()
+	b.index()
+}
+
+// index applies the pending operations to the index.
+func (b *batch) index() {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+	for _, o := range b.ops {
+		if o.vec == nil {
+			b.db.delete(o.id)
+		} else {
+			b.db.set(o.id, o.vec)
+		}
+	}
+	b.ops = nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ann
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// exact indexes every vector, so that the conformance tests
+// exercise the graph and not only the exact fallback.
+var exact = Config{MinSize: 1}
+
+func TestVectorDB(t *testing.T) {
+	db := storage.MemDB()
+	storage.TestVectorDB(t, func() storage.VectorDB {
+		lg := testutil.Slogger(t)
+		vdb := New(lg, storage.MemVectorDB(db, lg, ""), exact)
+		vdb.Load()
+		return vdb
+	})
+
+	lg := testutil.Slogger(t)
+	vdb := New(lg, storage.MemVectorDB(storage.MemDB(), lg, ""), exact)
+	vdb.Load()
+	storage.TestVectorDBFilter(t, vdb)
+}
+
+// randVec returns a random unit vector of length n.
+func randVec(r *rand.Rand, n int) llm.Vector {
+	v := make(llm.Vector, n)
+	var d float64
+	for i := range v {
+		v[i] = float32(r.NormFloat64())
+		d += float64(v[i] * v[i])
+	}
+	d = 1 / math.Sqrt(d)
+	for i := range v {
+		v[i] *= float32(d)
+	}
+	return v
+}
+
+// recall returns the fraction of the exact top n results kept by keep
+// for the queries that the approximate search finds.
+func recall(t *testing.T, vdb *VectorDB, base storage.VectorDB, queries []llm.Vector, n int, keep storage.VectorFilter) float64 {
+	found, total := 0, 0
+	for _, q := range queries {
+		want := make(map[string]bool)
+		for _, r := range base.Search(q, n, keep) {
+			want[r.ID] = true
+		}
+		have := vdb.Search(q, n, keep)
+		if len(have) != n {
+			t.Fatalf("Search returned %d results, want %d", len(have), n)
+		}
+		for i, r := range have {
+			if i > 0 && r.Score > have[i-1].Score {
+				t.Fatalf("Search results out of order: %v", have)
+			}
+			if keep != nil && !keep(r.ID) {
+				t.Fatalf("Search returned %s, which the filter removes", r.ID)
+			}
+			if want[r.ID] {
+				found++
+			}
+		}
+		total += len(want)
+	}
+	return float64(found) / float64(total)
+}
+
+func TestRecall(t *testing.T) {
+	const (
+		dim = 32
+		N   = 2000
+	)
+	lg := testutil.Slogger(t)
+	r := rand.New(rand.NewPCG(3, 4))
+	base := storage.MemVectorDB(storage.MemDB(), lg, "")
+	b := base.Batch()
+	for i := range N {
+		b.Set(fmt.Sprint(i), randVec(r, dim))
+	}
+	b.Apply()
+	var queries []llm.Vector
+	for range 50 {
+		queries = append(queries, randVec(r, dim))
+	}
+
+	vdb := New(lg, base, Config{MinSize: N + 1})
+	vdb.Load()
+	if got := recall(t, vdb, base, queries, 10, nil); got != 1 {
+		t.Errorf("recall below MinSize = %.3f, want 1", got)
+	}
+
+	vdb = New(lg, base, exact)
+	vdb.Load()
+	for _, ef := range []int{10, 100} {
+		vdb.cfg.EfSearch = ef
+		got := recall(t, vdb, base, queries, 10, nil)
+		t.Logf("EfSearch=%d: recall %.3f", ef, got)
+		if ef == 100 && got < 0.95 {
+			t.Errorf("EfSearch=%d: recall %.3f, want ≥ 0.95", ef, got)
+		}
+	}
+
+	// A filter keeping one vector in ten is applied during the search,
+	// which still finds n results.
+	tenth := func(id string) bool { return strings.HasSuffix(id, "0") }
+	got := recall(t, vdb, base, queries, 10, tenth)
+	t.Logf("EfSearch=%d, filtered: recall %.3f", vdb.cfg.EfSearch, got)
+	if got < 0.95 {
+		t.Errorf("filtered: recall %.3f, want ≥ 0.95", got)
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	const dim = 16
+	lg := testutil.Slogger(t)
+	r := rand.New(rand.NewPCG(5, 6))
+	base := storage.MemVectorDB(storage.MemDB(), lg, "")
+	vdb := New(lg, base, exact)
+
+	// Before Load, Search uses the exact search.
+	vdb.Set("a", randVec(r, dim))
+	if res := vdb.Search(randVec(r, dim), 1, nil); len(res) != 1 || res[0].ID != "a" {
+		t.Fatalf("Search before Load = %v, want a", res)
+	}
+	vdb.Load()
+
+	// Insert, replace and delete many vectors, in batches and not.
+	for i := range 2000 {
+		id := fmt.Sprint(i % 500)
+		switch i % 7 {
+		case 0:
+			vdb.Delete(id)
+		case 1:
+			b := vdb.Batch()
+			b.Set(id, randVec(r, dim))
+			b.Apply()
+		default:
+			vdb.Set(id, randVec(r, dim))
+		}
+	}
+
+	// Every stored vector is found by searching for itself,
+	// and no deleted vector is found.
+	n := 0
+	for id, vec := range vdb.All() {
+		n++
+		res := vdb.Search(vec(), 1, nil)
+		if len(res) != 1 || res[0].ID != id {
+			t.Errorf("Search(%s) = %v", id, res)
+		}
+	}
+	for _, g := range vdb.graphs {
+		if g.live() != n {
+			t.Errorf("index has %d vectors, want %d", g.live(), n)
+		}
+		if g.deleted > len(g.nodes)/2 {
+			t.Errorf("index has %d deleted of %d nodes; not rebuilt", g.deleted, len(g.nodes))
+		}
+	}
+	for _, res := range vdb.Search(randVec(r, dim), 600, nil) {
+		if _, ok := vdb.Get(res.ID); !ok {
+			t.Errorf("Search found deleted vector %s", res.ID)
+		}
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	lg := testutil.Slogger(t)
+	base := storage.MemVectorDB(storage.MemDB(), lg, "")
+	vdb := New(lg, base, exact)
+	vdb.Load()
+	baseUsage := func() storage.MemoryUsage { return base.(storage.MemoryReporter).MemoryUsage() }
+
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range 100 {
+		vdb.Set(fmt.Sprint(i), randVec(r, 16))
+	}
+	// The index holds a copy of each vector in addition to the base's.
+	if u, bu := vdb.MemoryUsage(), baseUsage(); u.Used < 2*100*4*16 || u.Used <= bu.Used || u.Cached != bu.Cached {
+		t.Errorf("MemoryUsage() = %+v, want more than base %+v and 2 copies of each vector", u, bu)
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ann
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A graph is a hierarchical navigable small world (HNSW) graph
+// of vectors of a single length, as described in
+// Malkov and Yashunin, "Efficient and robust approximate nearest
+// neighbor search using Hierarchical Navigable Small World graphs"
+// (https://arxiv.org/abs/1603.09320).
+//
+// Similarity is the dot product, as in [storage.VectorDB].
+// Deleted vectors remain in the graph, for navigation,
+// until more than half the nodes are deleted and the graph is rebuilt.
+// A graph is not safe for concurrent use.
+type graph struct {
+	cfg       Config
+	levelMult float64
+	rand      *rand.Rand
+
+	nodes    []*node
+	ids      map[string]int32 // live nodes, by ID
+	entry    int32            // entry point; -1 if the graph is empty
+	maxLevel int              // level of the entry point
+	deleted  int              // number of deleted nodes
+}
+
+// A node is a single vector in a graph.
+type node struct {
+	id      string
+	vec     llm.Vector
+	friends [][]int32 // neighbors, by layer
+	deleted bool
+}
+
+func newGraph(cfg Config) *graph {
+	return &graph{
+		cfg:       cfg,
+		levelMult: 1 / math.Log(float64(cfg.M)),
+		// A fixed seed makes the graph, and so the results,
+		// depend only on the order of insertion.
+		rand:  rand.New(rand.NewPCG(1, 2)),
+		ids:   make(map[string]int32),
+		entry: -1,
+	}
+}
+
+// live returns the number of live vectors in the graph.
+func (g *graph) live() int {
+	return len(g.ids)
+}
+
+// nodeOverhead is the approximate memory used by a node in a graph,
+// in addition to its ID, vector and neighbor lists.
+const nodeOverhead = 128
+
+// memory returns the approximate memory used by the graph,
+// including its deleted nodes.
+func (g *graph) memory() int64 {
+	var n int64
+	for _, nd := range g.nodes {
+		n += int64(nodeOverhead + len(nd.id) + 4*len(nd.vec))
+		for _, fs := range nd.friends {
+			n += int64(24 + 4*cap(fs)) // slice header and neighbors
+		}
+	}
+	return n
+}
+
+// A cand is a candidate node in a search, with its similarity to the query.
+type cand struct {
+	n   int32
+	sim float64
+}
+
+// set inserts the vector with the given ID into the graph,
+// replacing any vector it already has.
+func (g *graph) set(id string, vec llm.Vector) {
+	if i, ok := g.ids[id]; ok {
+		if slices.Equal(g.nodes[i].vec, vec) {
+			return
+		}
+		g.delete(id)
+	}
+	level := int(-math.Log(1-g.rand.Float64()) * g.levelMult)
+	nd := &node{id: id, vec: slices.Clone(vec), friends: make([][]int32, level+1)}
+	n := int32(len(g.nodes))
+	g.nodes = append(g.nodes, nd)
+	g.ids[id] = n
+	if g.entry < 0 {
+		g.entry = n
+		g.maxLevel = level
+		return
+	}
+
+	ep := g.entry
+	for l := g.maxLevel; l > level; l-- {
+		ep = g.greedy(vec, ep, l)
+	}
+	for l := min(level, g.maxLevel); l >= 0; l-- {
+		cands := g.searchLayer(vec, ep, g.cfg.EfConstruction, l, nil)
+		nd.friends[l] = g.closest(cands, g.cfg.M)
+		max := g.maxFriends(l)
+		for _, f := range nd.friends[l] {
+			fn := g.nodes[f]
+			fn.friends[l] = append(fn.friends[l], n)
+			if len(fn.friends[l]) > max {
+				g.prune(fn, l, max)
+			}
+		}
+		ep = cands[0].n
+	}
+	if level > g.maxLevel {
+		g.entry = n
+		g.maxLevel = level
+	}
+}
+
+// delete deletes the vector with the given ID from the graph.
+func (g *graph) delete(id string) {
+	i, ok := g.ids[id]
+	if !ok {
+		return
+	}
+	g.nodes[i].deleted = true
+	delete(g.ids, id)
+	g.deleted++
+	if g.deleted > len(g.nodes)/2 {
+		g.rebuild()
+	}
+}
+
+// rebuild rebuilds the graph from its live nodes.
+func (g *graph) rebuild() {
+	nodes := g.nodes
+	*g = *newGraph(g.cfg)
+	for _, nd := range nodes {
+		if !nd.deleted {
+			g.set(nd.id, nd.vec)
+		}
+	}
+}
+
+// maxFriends returns the maximum number of neighbors of a node on layer l.
+func (g *graph) maxFriends(l int) int {
+	if l == 0 {
+		return 2 * g.cfg.M
+	}
+	return g.cfg.M
+}
+
+// closest returns the nodes of the first m candidates,
+// which are sorted by decreasing similarity.
+func (g *graph) closest(cands []cand, m int) []int32 {
+	ns := make([]int32, 0, min(m, len(cands)))
+	for _, c := range cands[:min(m, len(cands))] {
+		ns = append(ns, c.n)
+	}
+	return ns
+}
+
+// prune reduces the neighbors of nd on layer l
+// to the max most similar to it.
+func (g *graph) prune(nd *node, l, max int) {
+	cands := make([]cand, len(nd.friends[l]))
+	for i, f := range nd.friends[l] {
+		cands[i] = cand{f, nd.vec.Dot(g.nodes[f].vec)}
+	}
+	sortCands(cands)
+	nd.friends[l] = g.closest(cands, max)
+}
+
+// greedy returns the node on layer l most similar to q
+// that is reachable from ep by moving to ever more similar neighbors.
+func (g *graph) greedy(q llm.Vector, ep int32, l int) int32 {
+	best := q.Dot(g.nodes[ep].vec)
+	for changed := true; changed; {
+		changed = false
+		for _, f := range g.nodes[ep].friends[l] {
+			if s := q.Dot(g.nodes[f].vec); s > best {
+				best, ep, changed = s, f, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer returns the (up to) ef nodes on layer l most similar to q
+// found by a best-first search from ep, sorted by decreasing similarity.
+// If ok is non-nil, the result includes only the nodes for which ok
+// returns true, but the search still passes through the other nodes;
+// ok is only called for nodes that would be among the best found so far.
+// Otherwise the result includes deleted nodes.
+func (g *graph) searchLayer(q llm.Vector, ep int32, ef, l int, ok func(int32) bool) []cand {
+	visited := map[int32]bool{ep: true}
+	first := cand{ep, q.Dot(g.nodes[ep].vec)}
+	todo := &candHeap{max: true, c: []cand{first}} // candidates to expand, best first
+	found := &candHeap{}                           // best ef found, worst first
+	if ok == nil || ok(ep) {
+		found.c = append(found.c, first)
+	}
+	for todo.Len() > 0 {
+		c := heap.Pop(todo).(cand)
+		if found.Len() >= ef && c.sim < found.c[0].sim {
+			break
+		}
+		for _, f := range g.nodes[c.n].friends[l] {
+			if visited[f] {
+				continue
+			}
+			visited[f] = true
+			s := q.Dot(g.nodes[f].vec)
+			if found.Len() < ef || s > found.c[0].sim {
+				heap.Push(todo, cand{f, s})
+				if ok != nil && !ok(f) {
+					continue
+				}
+				heap.Push(found, cand{f, s})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+	sortCands(found.c)
+	return found.c
+}
+
+// search returns the (up to) n live nodes most similar to q
+// whose IDs keep reports true for (all if keep is nil),
+// examining at least ef candidates, sorted by decreasing similarity.
+// A restrictive keep makes the search examine more candidates,
+// up to all the nodes reachable from the entry point.
+func (g *graph) search(q llm.Vector, n, ef int, keep storage.VectorFilter) []cand {
+	if g.entry < 0 {
+		return nil
+	}
+	ep := g.entry
+	for l := g.maxLevel; l > 0; l-- {
+		ep = g.greedy(q, ep, l)
+	}
+	ok := func(i int32) bool {
+		nd := g.nodes[i]
+		return !nd.deleted && (keep == nil || keep(nd.id))
+	}
+	res := g.searchLayer(q, ep, max(ef, n), 0, ok)
+	return res[:min(n, len(res))]
+}
+
+// sortCands sorts cands by decreasing similarity.
+func sortCands(cands []cand) {
+	slices.SortFunc(cands, func(x, y cand) int {
+		if x.sim != y.sim {
+			if x.sim > y.sim {
+				return -1
+			}
+			return +1
+		}
+		return int(x.n - y.n)
+	})
+}
+
+// A candHeap is a heap of candidates:
+// a max-heap by similarity if max is set, otherwise a min-heap.
+type candHeap struct {
+	max bool
+	c   []cand
+}
+
+func (h *candHeap) Len() int { return len(h.c) }
+func (h *candHeap) Less(i, j int) bool {
+	if h.max {
+		return h.c[i].sim > h.c[j].sim
+	}
+	return h.c[i].sim < h.c[j].sim
+}
+func (h *candHeap) Swap(i, j int) { h.c[i], h.c[j] = h.c[j], h.c[i] }
+func (h *candHeap) Push(x any)    { h.c = append(h.c, x.(cand)) }
+func (h *candHeap) Pop() any {
+	x := h.c[len(h.c)-1]
+	h.c = h.c[:len(h.c)-1]
+	return x
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package anthropic implements access to Anthropic's Claude models.
+//
+// [Client] implements [llm.ContentGenerator]. Use [NewClient] to connect.
+// Anthropic does not offer an embedding API, so Client does not
+// implement [llm.Embedder].
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/secret"
+)
+
+// NOTE: Like package ollama, this package does not use third party
+// packages for the Anthropic API to avoid bringing in their many dependencies.
+
+// A Client represents a connection to Anthropic.
+type Client struct {
+	slog        *slog.Logger
+	hc          *http.Client
+	url         *url.URL // base URL of the API
+	key         string
+	model       string
+	temperature float32 // negative means use default
+}
+
+const (
+	DefaultServer = "https://api.anthropic.com/v1"
+	DefaultModel  = "claude-3-5-sonnet-latest"
+
+	apiVersion = "2023-06-01" // value of the anthropic-version header
+	maxTokens  = 8192         // maximum tokens in a response
+)
+
+// NewClient returns a connection to Anthropic, using the given logger and HTTP client.
+// It expects to find a secret of the form "sk-ant-..." or "user:sk-ant-..." in sdb
+// under the name "api.anthropic.com".
+// The model is the model name to use for generation, such as claude-3-5-sonnet-latest.
+func NewClient(lg *slog.Logger, sdb secret.DB, hc *http.Client, model string) (*Client, error) {
+	key, ok := sdb.Get("api.anthropic.com")
+	if !ok {
+		return nil, fmt.Errorf("missing api key for api.anthropic.com")
+	}
+	// If key is from .netrc, ignore user name.
+	if _, pass, ok := strings.Cut(key, ":"); ok {
+		key = pass
+	}
+	u, err := url.Parse(DefaultServer)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		slog:        lg,
+		hc:          hc,
+		url:         u,
+		key:         key,
+		model:       model,
+		temperature: -1,
+	}, nil
+}
+
+// SetServer sets the base URL of the API, such as "https://api.anthropic.com/v1".
+func (c *Client) SetServer(server string) error {
+	u, err := url.Parse(server)
+	if err != nil {
+		return err
+	}
+	c.url = u
+	return nil
+}
+
+var _ llm.ContentGenerator = (*Client)(nil)
+
+// Model returns the name of the client's generative model.
+func (c *Client) Model() string {
+	return c.model
+}
+
+// SetTemperature sets the temperature of the client's generative model.
+func (c *Client) SetTemperature(t float32) {
+	c.temperature = t
+}
+
+// responseTool is the name of the tool that a schema-constrained
+// request forces the model to call. The Messages API has no JSON mode,
+// so GenerateContent asks for the response as the tool's input.
+const responseTool = "response"
+
+// GenerateContent returns the model's response for the prompt parts,
+// implementing [llm.ContentGenerator.GenerateContent].
+func (c *Client) GenerateContent(ctx context.Context, schema *llm.Schema, promptParts []llm.Part) (string, error) {
+	content, err := parts(promptParts)
+	if err != nil {
+		return "", fmt.Errorf("anthropic.GenerateContent: %w", err)
+	}
+	req := map[string]any{
+		"model":      c.model,
+		"max_tokens": maxTokens,
+		"messages":   []map[string]any{{"role": "user", "content": content}},
+	}
+	if c.temperature >= 0 {
+		req["temperature"] = c.temperature
+	}
+	if schema != nil {
+		req["tools"] = []map[string]any{{
+			"name":         responseTool,
+			"description":  "Record the response.",
+			"input_schema": schema.JSONSchema(),
+		}}
+		req["tool_choice"] = map[string]any{"type": "tool", "name": responseTool}
+	}
+	var resp struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+	}
+	if err := c.post(ctx, "messages", req, &resp); err != nil {
+		return "", fmt.Errorf("anthropic.GenerateContent: %w", err)
+	}
+	var texts []string
+	for _, b := range resp.Content {
+		switch b.Type {
+		case "text":
+			texts = append(texts, b.Text)
+		case "tool_use":
+			if schema != nil && b.Name == responseTool {
+				return string(b.Input), nil
+			}
+		}
+	}
+	if schema != nil {
+		return "", fmt.Errorf("anthropic.GenerateContent: no structured response")
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// parts converts the prompt parts to Anthropic message content.
+func parts(promptParts []llm.Part) ([]map[string]any, error) {
+	var content []map[string]any
+	for _, p := range promptParts {
+		switch p := p.(type) {
+		case llm.Text:
+			content = append(content, map[string]any{"type": "text", "text": string(p)})
+		case llm.Blob:
+			content = append(content, map[string]any{
+				"type": "image",
+				"source": map[string]any{
+					"type":       "base64",
+					"media_type": p.MIMEType,
+					"data":       base64.StdEncoding.EncodeToString(p.Data),
+				},
+			})
+		default:
+			return nil, fmt.Errorf("bad type for part: %T; need string or llm.Blob", p)
+		}
+	}
+	return content, nil
+}
+
+// post sends req as JSON to the API endpoint with the given path
+// and decodes the JSON response into resp.
+func (c *Client) post(ctx context.Context, path string, req, resp any) error {
+	js, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url.JoinPath(path).String(), bytes.NewReader(js))
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.Header.Set("Accept", "application/json")
+	hreq.Header.Set("X-Api-Key", c.key)
+	hreq.Header.Set("Anthropic-Version", apiVersion)
+
+	hresp, err := c.hc.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+	data, err := io.ReadAll(hresp.Body)
+	if err != nil {
+		return err
+	}
+	if hresp.StatusCode != http.StatusOK {
+		// Anthropic returns JSON with an error object on failure.
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Error.Message != "" {
+			return fmt.Errorf("%s: %s", hresp.Status, e.Error.Message)
+		}
+		return fmt.Errorf("%s", hresp.Status)
+	}
+	return json.Unmarshal(data, resp)
+}
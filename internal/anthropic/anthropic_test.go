@@ -0,0 +1,98 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// newTestClient returns a client for a fake Anthropic server
+// that calls handle for each request.
+func newTestClient(t *testing.T, handle func(req map[string]any) (int, any)) *Client {
+	check := testutil.Checker(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("path = %s, want /v1/messages", r.URL.Path)
+		}
+		if got := r.Header.Get("X-Api-Key"); got != "sk-ant-test" {
+			t.Errorf("X-Api-Key = %q, want sk-ant-test", got)
+		}
+		if got := r.Header.Get("Anthropic-Version"); got != apiVersion {
+			t.Errorf("Anthropic-Version = %q, want %q", got, apiVersion)
+		}
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		code, resp := handle(req)
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	sdb := secret.Map{"api.anthropic.com": "sk-ant-test"}
+	c, err := NewClient(testutil.Slogger(t), sdb, srv.Client(), DefaultModel)
+	check(err)
+	check(c.SetServer(srv.URL + "/v1"))
+	return c
+}
+
+func TestGenerateContent(t *testing.T) {
+	c := newTestClient(t, func(req map[string]any) (int, any) {
+		if req["model"] != DefaultModel || req["tools"] != nil {
+			t.Errorf("request %v", req)
+		}
+		return 200, map[string]any{"content": []any{
+			map[string]any{"type": "text", "text": "hello"},
+			map[string]any{"type": "text", "text": "world"},
+		}}
+	})
+	out, err := c.GenerateContent(context.Background(), nil, []llm.Part{llm.Text("hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "hello\nworld" {
+		t.Errorf("GenerateContent = %q, want hello\\nworld", out)
+	}
+}
+
+func TestGenerateContentSchema(t *testing.T) {
+	c := newTestClient(t, func(req map[string]any) (int, any) {
+		choice, _ := json.Marshal(req["tool_choice"])
+		if string(choice) != `{"name":"response","type":"tool"}` {
+			t.Errorf("tool_choice = %s", choice)
+		}
+		return 200, map[string]any{"content": []any{
+			map[string]any{"type": "tool_use", "name": "response", "input": map[string]any{"x": "y"}},
+		}}
+	})
+	schema := &llm.Schema{Type: llm.TypeObject, Properties: map[string]*llm.Schema{"x": {Type: llm.TypeString}}}
+	out, err := c.GenerateContent(context.Background(), schema, []llm.Part{llm.Text("hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != `{"x":"y"}` {
+		t.Errorf("GenerateContent = %q, want {\"x\":\"y\"}", out)
+	}
+}
+
+func TestError(t *testing.T) {
+	c := newTestClient(t, func(req map[string]any) (int, any) {
+		return 529, map[string]any{"type": "error", "error": map[string]any{"type": "overloaded_error", "message": "Overloaded"}}
+	})
+	_, err := c.GenerateContent(context.Background(), nil, []llm.Part{llm.Text("hi")})
+	if err == nil || !strings.Contains(err.Error(), "529") || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("GenerateContent error = %v, want 529 Overloaded", err)
+	}
+}
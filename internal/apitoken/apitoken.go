@@ -0,0 +1,217 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package apitoken manages bearer tokens that grant scoped access
+// to Gaby's API to clients outside the web UI, such as the
+// "gh oscar" GitHub CLI extension and editor integrations.
+//
+// A token is a random secret, shown once when it is issued.
+// Only a hash of the secret is stored, along with the token's
+// scopes (which endpoints it may call), the GitHub projects it
+// may read, and its expiration time.
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// A Scope names a group of API endpoints a token may call.
+type Scope string
+
+const (
+	ScopeOverview Scope = "overview" // issue overviews
+	ScopeRelated  Scope = "related"  // related documents
+	ScopeLabels   Scope = "labels"   // label suggestions
+	ScopeHover    Scope = "hover"    // issues related to code, for editor hovers
+)
+
+// Scopes lists the valid scopes.
+var Scopes = []Scope{ScopeOverview, ScopeRelated, ScopeLabels, ScopeHover}
+
+// Prefix starts every token secret, so that leaked secrets are
+// easy to recognize (for example, by secret scanners).
+const Prefix = "oscar_"
+
+var (
+	// ErrUnauthorized is returned by [Store.Authorize] for secrets that are
+	// missing, unknown, revoked or expired.
+	ErrUnauthorized = errors.New("apitoken: invalid or expired token")
+	// ErrForbidden is returned by [Store.Authorize] for valid tokens
+	// that do not grant the requested access.
+	ErrForbidden = errors.New("apitoken: token does not grant access")
+	// ErrNotFound is returned by [Store.Revoke] for unknown tokens.
+	ErrNotFound = errors.New("apitoken: no such token")
+)
+
+// A Token describes an issued token. It never holds the secret.
+type Token struct {
+	ID       string    // short public identifier, derived from the secret's hash
+	Name     string    // who or what the token is for, such as a GitHub login
+	User     string    // who issued the token
+	Scopes   []Scope   // the scopes the token grants
+	Projects []string  // the GitHub projects the token may read, such as "golang/go"; empty means all
+	Created  time.Time // when the token was issued
+	Expires  time.Time // when the token stops working; zero means never
+}
+
+// Expired reports whether the token has expired at time now.
+func (t *Token) Expired(now time.Time) bool {
+	return !t.Expires.IsZero() && !now.Before(t.Expires)
+}
+
+// Allows reports whether the token grants scope s on the project.
+// An empty project is allowed by any token with scope s.
+func (t *Token) Allows(s Scope, project string) bool {
+	if !slices.Contains(t.Scopes, s) {
+		return false
+	}
+	return project == "" || len(t.Projects) == 0 || slices.Contains(t.Projects, project)
+}
+
+// A Store holds the issued tokens in a database.
+type Store struct {
+	slog *slog.Logger
+	db   storage.DB
+}
+
+// NewStore returns a Store that keeps tokens in db.
+func NewStore(lg *slog.Logger, db storage.DB) *Store {
+	return &Store{slog: lg, db: db}
+}
+
+const tokenKind = "apitoken.Token"
+
+// hash returns the hex SHA-256 hash of secret, the token's database key.
+func hash(secret string) string {
+	h := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(h[:])
+}
+
+// Issue issues a new token for name on behalf of user, granting the
+// scopes on the projects (empty means all) until expires (zero means never).
+// It returns the token's secret, which cannot be recovered later,
+// and its description.
+func (s *Store) Issue(name, user string, scopes []Scope, projects []string, expires time.Time) (secret string, _ *Token, _ error) {
+	if name == "" {
+		return "", nil, errors.New("apitoken: missing name")
+	}
+	if len(scopes) == 0 {
+		return "", nil, errors.New("apitoken: no scopes")
+	}
+	for _, sc := range scopes {
+		if !slices.Contains(Scopes, sc) {
+			return "", nil, fmt.Errorf("apitoken: unknown scope %q", sc)
+		}
+	}
+	for _, p := range projects {
+		if strings.Count(p, "/") != 1 {
+			return "", nil, fmt.Errorf("apitoken: invalid project %q (want owner/repo)", p)
+		}
+	}
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", nil, err
+	}
+	secret = Prefix + base64.RawURLEncoding.EncodeToString(b[:])
+	h := hash(secret)
+	t := &Token{
+		ID:       h[:12],
+		Name:     name,
+		User:     user,
+		Scopes:   slices.Clone(scopes),
+		Projects: slices.Clone(projects),
+		Created:  time.Now(),
+		Expires:  expires,
+	}
+	s.db.Set(ordered.Encode(tokenKind, h), storage.JSON(t))
+	s.slog.Info("apitoken issued", "id", t.ID, "name", name, "user", user, "scopes", scopes, "projects", projects, "expires", expires)
+	return secret, t, nil
+}
+
+// Authorize returns the token with the secret if it grants scope s
+// on the project (see [Token.Allows]).
+// It returns an error wrapping [ErrUnauthorized] if there is no
+// such unexpired token, and one wrapping [ErrForbidden] if the
+// token does not grant the access.
+func (s *Store) Authorize(secret string, sc Scope, project string) (*Token, error) {
+	if !strings.HasPrefix(secret, Prefix) {
+		return nil, ErrUnauthorized
+	}
+	t, ok := s.lookup(hash(secret))
+	if !ok || t.Expired(time.Now()) {
+		return nil, ErrUnauthorized
+	}
+	if !t.Allows(sc, project) {
+		if project == "" {
+			return nil, fmt.Errorf("%w: scope %s", ErrForbidden, sc)
+		}
+		return nil, fmt.Errorf("%w: scope %s on %s", ErrForbidden, sc, project)
+	}
+	return t, nil
+}
+
+// lookup returns the token with the given secret hash.
+func (s *Store) lookup(h string) (*Token, bool) {
+	val, ok := s.db.Get(ordered.Encode(tokenKind, h))
+	if !ok {
+		return nil, false
+	}
+	var t Token
+	if err := json.Unmarshal(val, &t); err != nil {
+		s.db.Panic("apitoken: decode", "hash", h, "err", err)
+	}
+	return &t, true
+}
+
+// Revoke revokes the token with the given ID on behalf of user.
+func (s *Store) Revoke(id, user string) error {
+	for h, t := range s.all() {
+		if t.ID == id {
+			s.db.Delete(ordered.Encode(tokenKind, h))
+			s.slog.Info("apitoken revoked", "id", id, "name", t.Name, "user", user)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// List returns the issued tokens, oldest first.
+func (s *Store) List() []*Token {
+	var list []*Token
+	for _, t := range s.all() {
+		list = append(list, t)
+	}
+	slices.SortFunc(list, func(a, b *Token) int { return a.Created.Compare(b.Created) })
+	return list
+}
+
+// all returns the issued tokens, keyed by secret hash.
+func (s *Store) all() map[string]*Token {
+	m := make(map[string]*Token)
+	for key, fn := range s.db.Scan(ordered.Encode(tokenKind), ordered.Encode(tokenKind, ordered.Inf)) {
+		var h string
+		if err := ordered.Decode(key, nil, &h); err != nil {
+			s.db.Panic("apitoken: decode key", "key", storage.Fmt(key), "err", err)
+		}
+		var t Token
+		if err := json.Unmarshal(fn(), &t); err != nil {
+			s.db.Panic("apitoken: decode", "hash", h, "err", err)
+		}
+		m[h] = &t
+	}
+	return m
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apitoken
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(testutil.Slogger(t), storage.MemDB())
+
+	for _, tc := range []struct {
+		name     string
+		scopes   []Scope
+		projects []string
+	}{
+		{"", []Scope{ScopeOverview}, nil},
+		{"gopher", nil, nil},
+		{"gopher", []Scope{"admin"}, nil},
+		{"gopher", []Scope{ScopeOverview}, []string{"golang"}},
+	} {
+		if _, _, err := s.Issue(tc.name, "admin@golang.org", tc.scopes, tc.projects, time.Time{}); err == nil {
+			t.Errorf("Issue(%q, %v, %v) succeeded, want error", tc.name, tc.scopes, tc.projects)
+		}
+	}
+
+	secret, tok, err := s.Issue("gopher", "admin@golang.org", []Scope{ScopeOverview, ScopeRelated}, []string{"golang/go"}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, Prefix) {
+		t.Errorf("secret %q does not start with %q", secret, Prefix)
+	}
+
+	for _, tc := range []struct {
+		secret  string
+		scope   Scope
+		project string
+		want    error
+	}{
+		{secret, ScopeOverview, "golang/go", nil},
+		{secret, ScopeRelated, "", nil},
+		{secret, ScopeLabels, "golang/go", ErrForbidden},
+		{secret, ScopeOverview, "golang/tools", ErrForbidden},
+		{"", ScopeOverview, "golang/go", ErrUnauthorized},
+		{secret + "x", ScopeOverview, "golang/go", ErrUnauthorized},
+	} {
+		got, err := s.Authorize(tc.secret, tc.scope, tc.project)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("Authorize(%q, %s, %q) = %v, want %v", tc.secret, tc.scope, tc.project, err, tc.want)
+		}
+		if err == nil && got.ID != tok.ID {
+			t.Errorf("Authorize(%q, %s, %q) = token %s, want %s", tc.secret, tc.scope, tc.project, got.ID, tok.ID)
+		}
+	}
+
+	expired, _, err := s.Issue("old", "admin@golang.org", []Scope{ScopeLabels}, nil, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authorize(expired, ScopeLabels, "golang/go"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Authorize(expired) = %v, want ErrUnauthorized", err)
+	}
+
+	if list := s.List(); len(list) != 2 || list[0].Name != "gopher" || list[1].Name != "old" {
+		t.Errorf("List() = %v, want gopher and old", list)
+	}
+
+	if err := s.Revoke(tok.ID, "admin@golang.org"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authorize(secret, ScopeOverview, "golang/go"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Authorize(revoked) = %v, want ErrUnauthorized", err)
+	}
+	if err := s.Revoke(tok.ID, "admin@golang.org"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revoke(revoked) = %v, want ErrNotFound", err)
+	}
+}
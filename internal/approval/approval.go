@@ -0,0 +1,308 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package approval lets maintainers approve or deny pending actions
+// (see [golang.org/x/oscar/internal/actions]) from GitHub,
+// without access to the Gaby UI.
+//
+// An [Approver] posts a preview comment for each action that requires
+// approval on a tracking issue, which is typically in a private
+// repository. Each preview shows the action and a short ID for it.
+// An allowed user (see [Approver.AllowUser]) can then decide the action
+// by reacting to the preview with 👍 (approve) or 👎 (deny),
+// or by commenting on the tracking issue with a command line
+//
+//	/oscar approve ID
+//	/oscar deny ID
+//
+// (see [ParseCommands]). Commands are read from the tracking issue's
+// comments as they are synced, using a GitHub event watcher,
+// so the tracking issue's project must be synced by the GitHub client.
+package approval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+const (
+	previewKind = "approval.Preview" // (name, id) -> preview JSON
+	cursorKind  = "approval.Cursor"  // (name) -> last action log DBTime considered
+)
+
+// An Approver posts previews of pending actions to a tracking issue
+// and records the decisions made there.
+type Approver struct {
+	slog    *slog.Logger
+	db      storage.DB
+	github  *github.Client
+	project string // tracking issue's project
+	issue   int64  // tracking issue number
+	name    string
+	users   map[string]bool // users allowed to decide
+	watcher *timed.Watcher[*github.Event]
+}
+
+// New creates and returns a new Approver. It logs to lg, stores state
+// in db, and uses gh to post previews to and read decisions from the
+// tracking issue with the given project and number.
+// For the purposes of storing its own state, it uses the given name.
+// Future calls to New with the same name will use the same state.
+//
+// No one can decide actions until allowed with [Approver.AllowUser].
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, project string, issue int64, name string) *Approver {
+	return &Approver{
+		slog:    lg,
+		db:      db,
+		github:  gh,
+		project: project,
+		issue:   issue,
+		name:    name,
+		users:   make(map[string]bool),
+		watcher: gh.EventWatcher("approval.Approver:" + name),
+	}
+}
+
+// AllowUser allows the GitHub user with the given login to
+// approve and deny actions.
+func (a *Approver) AllowUser(login string) {
+	a.users[login] = true
+}
+
+// A preview records the preview comment posted for an action.
+type preview struct {
+	Kind       string // action kind
+	Key        []byte // action key
+	CommentURL string // API URL of the preview comment
+	Decided    bool   // whether the action has been decided (or run)
+}
+
+// ID returns the short ID of the action with the given kind and key,
+// as shown in its preview.
+func ID(kind string, key []byte) string {
+	h := sha256.Sum256(ordered.Encode(kind, string(key)))
+	return hex.EncodeToString(h[:4])
+}
+
+// Run posts previews for new actions that require approval,
+// then records the decisions in new commands on the tracking issue
+// and in reactions to the undecided previews.
+func (a *Approver) Run(ctx context.Context) error {
+	a.slog.Info("approval.Approver start", "name", a.name, "issue", a.issue)
+	defer a.slog.Info("approval.Approver end", "name", a.name)
+
+	if err := a.postPreviews(ctx); err != nil {
+		return err
+	}
+	a.readCommands()
+	return a.readReactions(ctx)
+}
+
+// postPreviews posts previews for the actions that require approval
+// and were logged since the last call.
+func (a *Approver) postPreviews(ctx context.Context) error {
+	iss, err := github.LookupIssue(a.db, a.project, a.issue)
+	if err != nil {
+		return fmt.Errorf("approval: tracking issue: %w", err)
+	}
+	ckey := ordered.Encode(cursorKind, a.name)
+	var cursor int64
+	if val, ok := a.db.Get(ckey); ok {
+		if err := ordered.Decode(val, &cursor); err != nil {
+			a.db.Panic("approval cursor decode", "val", storage.Fmt(val), "err", err)
+		}
+	}
+	for e := range actions.ScanAfterDBTime(a.slog, a.db, timed.DBTime(cursor), nil) {
+		if e.ApprovalRequired && !e.IsDone() && len(e.Decisions) == 0 {
+			id := ID(e.Kind, e.Key)
+			if _, ok := a.lookup(id); !ok {
+				_, _, err := a.github.PostIssueComment(ctx, iss, &github.IssueCommentChanges{Body: previewBody(id, e)})
+				if err != nil {
+					return fmt.Errorf("approval: posting preview of %s: %w", id, err)
+				}
+				// The comment's API URL is learned when it is synced;
+				// see readCommands.
+				a.set(id, &preview{Kind: e.Kind, Key: e.Key})
+			}
+		}
+		a.db.Set(ckey, ordered.Encode(int64(e.ModTime)))
+	}
+	return nil
+}
+
+// previewBody returns the body of the preview comment for e.
+func previewBody(id string, e *actions.Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Action `%s` (%s) needs approval:\n\n", id, e.Kind)
+	for _, line := range strings.Split(strings.TrimSpace(e.ActionForDisplay()), "\n") {
+		fmt.Fprintf(&b, "> %s\n", line)
+	}
+	fmt.Fprintf(&b, "\nReact with 👍 to approve or 👎 to deny, or comment `/oscar approve %s` or `/oscar deny %s`.\n", id, id)
+	return b.String()
+}
+
+// readCommands records the decisions in new comments on the tracking issue,
+// and notes the API URLs of new preview comments.
+func (a *Approver) readCommands() {
+	defer a.watcher.Flush()
+	for e := range a.watcher.Recent() {
+		a.watcher.MarkOld(e.DBTime)
+		if e.Project != a.project || e.Issue != a.issue || e.API != "/issues/comments" {
+			continue
+		}
+		c := e.Typed.(*github.IssueComment)
+		if id, ok := previewID(c.Body); ok {
+			if p, ok := a.lookup(id); ok && p.CommentURL == "" {
+				p.CommentURL = c.URL
+				a.set(id, p)
+			}
+			continue
+		}
+		for _, cmd := range ParseCommands(c.Body) {
+			a.decide(cmd.ID, cmd.Approve, c.User.Login)
+		}
+	}
+}
+
+// previewID returns the action ID from the body of a preview comment.
+func previewID(body string) (string, bool) {
+	rest, ok := strings.CutPrefix(body, "Action `")
+	if !ok {
+		return "", false
+	}
+	id, _, ok := strings.Cut(rest, "`")
+	return id, ok
+}
+
+// readReactions records the decisions in the reactions to
+// undecided previews.
+func (a *Approver) readReactions(ctx context.Context) error {
+	var errs []error
+	for id, p := range a.undecided() {
+		if p.CommentURL == "" {
+			continue // not synced yet
+		}
+		rs, err := a.github.CommentReactions(ctx, &github.IssueComment{URL: p.CommentURL})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("approval: reactions to %s: %w", id, err))
+			continue
+		}
+		for _, r := range rs {
+			switch r.Content {
+			case "+1":
+				a.decide(id, true, r.User.Login)
+			case "-1":
+				a.decide(id, false, r.User.Login)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// decide records the decision of the user about the action with the given ID,
+// if the user is allowed to decide and the action is undecided.
+func (a *Approver) decide(id string, approve bool, user string) {
+	if !a.users[user] {
+		a.slog.Info("approval.Approver: user not allowed", "id", id, "user", user)
+		return
+	}
+	p, ok := a.lookup(id)
+	if !ok {
+		a.slog.Info("approval.Approver: unknown action", "id", id, "user", user)
+		return
+	}
+	if p.Decided {
+		return
+	}
+	e, ok := actions.Get(a.db, p.Kind, p.Key)
+	if ok && !e.IsDone() && len(e.Decisions) == 0 {
+		d := actions.Decision{Name: "github:" + user, Time: time.Now(), Approved: approve}
+		a.slog.Info("approval.Approver decided", "id", id, "kind", p.Kind, "key", storage.Fmt(p.Key), "decision", d)
+		actions.AddDecision(a.db, p.Kind, p.Key, d)
+	}
+	p.Decided = true
+	a.set(id, p)
+}
+
+// undecided returns the previews of undecided actions, by ID.
+// It marks as decided the previews of actions decided by other means
+// (such as the Gaby UI).
+func (a *Approver) undecided() map[string]*preview {
+	m := make(map[string]*preview)
+	for key, val := range a.db.Scan(ordered.Encode(previewKind, a.name), ordered.Encode(previewKind, a.name, ordered.Inf)) {
+		var id string
+		if err := ordered.Decode(key, nil, nil, &id); err != nil {
+			a.db.Panic("approval preview key decode", "key", storage.Fmt(key), "err", err)
+		}
+		var p preview
+		if err := json.Unmarshal(val(), &p); err != nil {
+			a.db.Panic("approval preview decode", "key", storage.Fmt(key), "err", err)
+		}
+		if p.Decided {
+			continue
+		}
+		if e, ok := actions.Get(a.db, p.Kind, p.Key); !ok || e.IsDone() || len(e.Decisions) > 0 {
+			p.Decided = true
+			a.set(id, &p)
+			continue
+		}
+		m[id] = &p
+	}
+	return m
+}
+
+func (a *Approver) lookup(id string) (*preview, bool) {
+	val, ok := a.db.Get(ordered.Encode(previewKind, a.name, id))
+	if !ok {
+		return nil, false
+	}
+	var p preview
+	if err := json.Unmarshal(val, &p); err != nil {
+		a.db.Panic("approval preview decode", "id", id, "err", err)
+	}
+	return &p, true
+}
+
+func (a *Approver) set(id string, p *preview) {
+	a.db.Set(ordered.Encode(previewKind, a.name, id), storage.JSON(p))
+}
+
+// A Command is an approval command in a comment.
+type Command struct {
+	Approve bool   // true for "approve", false for "deny"
+	ID      string // the action ID
+}
+
+// ParseCommands returns the approval commands in the comment body:
+// lines of the form "/oscar approve ID" or "/oscar deny ID".
+// Surrounding spaces and letter case are ignored.
+func ParseCommands(body string) []Command {
+	var cmds []Command
+	for _, line := range strings.Split(body, "\n") {
+		f := strings.Fields(strings.ToLower(line))
+		if len(f) != 3 || f[0] != "/oscar" {
+			continue
+		}
+		switch f[1] {
+		case "approve":
+			cmds = append(cmds, Command{Approve: true, ID: f[2]})
+		case "deny":
+			cmds = append(cmds, Command{Approve: false, ID: f[2]})
+		}
+	}
+	return cmds
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package approval
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+	"rsc.io/ordered"
+)
+
+var ctx = context.Background()
+
+const tracking = "golang/oscar-private"
+
+type testActioner struct{}
+
+func (testActioner) Run(context.Context, []byte) ([]byte, error) { return nil, nil }
+func (testActioner) ForDisplay(b []byte) string                  { return "do " + string(b) }
+
+func TestApprover(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	tc := gh.Testing()
+	tc.AddIssue(tracking, &github.Issue{Number: 1, Title: "approvals"})
+
+	before := actions.Register("approval.test", testActioner{})
+	for _, k := range []string{"a", "b", "c"} {
+		before(db, ordered.Encode(k), []byte(k), true)
+	}
+	before(db, ordered.Encode("auto"), []byte("auto"), false)
+	idA := ID("approval.test", ordered.Encode("a"))
+	idB := ID("approval.test", ordered.Encode("b"))
+	idC := ID("approval.test", ordered.Encode("c"))
+
+	a := New(lg, db, gh, tracking, 1, "test")
+	a.AllowUser("maintainer")
+	check(a.Run(ctx))
+
+	// One preview for each action that requires approval.
+	edits := tc.Edits()
+	if len(edits) != 3 {
+		t.Fatalf("got %d edits, want 3:\n%v", len(edits), edits)
+	}
+	for i, id := range []string{idA, idB, idC} {
+		body := edits[i].IssueCommentChanges.Body
+		if !strings.HasPrefix(body, "Action `"+id+"`") || !strings.Contains(body, "> do "+string("abc"[i])) {
+			t.Errorf("preview %d:\n%s", i, body)
+		}
+	}
+
+	// Running again posts nothing new.
+	check(a.Run(ctx))
+	if n := len(tc.Edits()); n != 3 {
+		t.Errorf("after second run, got %d edits, want 3", n)
+	}
+
+	// Simulate the sync of the previews.
+	previews := make(map[string]*github.IssueComment)
+	for i, id := range []string{idA, idB, idC} {
+		c := &github.IssueComment{User: github.User{Login: "gabyhelp"}, Body: edits[i].IssueCommentChanges.Body}
+		tc.AddIssueComment(tracking, 1, c)
+		previews[id] = c
+	}
+
+	// Approve a by command, deny b by reaction.
+	// Ignore the decisions of other users about c.
+	tc.AddIssueComment(tracking, 1, &github.IssueComment{
+		User: github.User{Login: "maintainer"},
+		Body: "LGTM\n/oscar approve " + idA + "\n",
+	})
+	tc.AddIssueComment(tracking, 1, &github.IssueComment{
+		User: github.User{Login: "someone"},
+		Body: "/oscar approve " + idC,
+	})
+	tc.AddReaction(previews[idB], "maintainer", "-1")
+	tc.AddReaction(previews[idC], "someone", "+1")
+	check(a.Run(ctx))
+
+	decided := func(key string) []bool {
+		e, ok := actions.Get(db, "approval.test", ordered.Encode(key))
+		if !ok {
+			t.Fatalf("action %s not found", key)
+		}
+		var ds []bool
+		for _, d := range e.Decisions {
+			if d.Name != "github:maintainer" {
+				t.Errorf("action %s: decision by %q", key, d.Name)
+			}
+			ds = append(ds, d.Approved)
+		}
+		return ds
+	}
+	if diff := cmp.Diff([]bool{true}, decided("a")); diff != "" {
+		t.Errorf("a decisions (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]bool{false}, decided("b")); diff != "" {
+		t.Errorf("b decisions (-want, +got):\n%s", diff)
+	}
+	if got := decided("c"); len(got) != 0 {
+		t.Errorf("c decisions = %v, want none", got)
+	}
+
+	// A decision made elsewhere takes c out of consideration.
+	actions.AddDecision(db, "approval.test", ordered.Encode("c"), actions.Decision{Name: "ui", Approved: true})
+	check(a.Run(ctx))
+	if len(a.undecided()) != 0 {
+		t.Errorf("undecided = %v, want none", a.undecided())
+	}
+}
+
+func TestParseCommands(t *testing.T) {
+	body := "Looks fine.\n/oscar approve abc123\n  /Oscar DENY def456  \n/oscar approve\n/oscar merge x\n"
+	want := []Command{{Approve: true, ID: "abc123"}, {Approve: false, ID: "def456"}}
+	if diff := cmp.Diff(want, ParseCommands(body)); diff != "" {
+		t.Errorf("ParseCommands (-want, +got):\n%s", diff)
+	}
+}
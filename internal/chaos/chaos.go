@@ -0,0 +1,306 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chaos injects random failures into Oscar's dependencies,
+// to test how the rest of the system copes with them.
+//
+// An [Injector] wraps a [storage.DB], an [http.Client]
+// (such as the one used to edit GitHub or to call an LLM),
+// an [llm.ContentGenerator] or an [llm.Embedder],
+// making a configurable fraction of the operations on each [Target] fail.
+// Failed storage writes panic, following the [storage] conventions,
+// without changing the database. Failed HTTP requests and LLM calls
+// return an error wrapping [ErrInjected]; half of the failed HTTP
+// requests are sent first and only their responses are lost,
+// which is the harder case for callers that must not repeat an edit.
+//
+// Fault injection is only available in tests and in binaries built
+// with the "chaos" build tag, such as staging builds:
+// in other binaries, [New] and [Parse] fail, so a production server
+// cannot inject faults even if misconfigured.
+// The wrappers of a nil *Injector return their argument unchanged.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// ErrInjected is wrapped by the errors and panics of injected failures.
+var ErrInjected = errors.New("chaos: injected failure")
+
+// A Target is a kind of operation in which to inject failures.
+type Target string
+
+const (
+	Storage Target = "storage" // storage writes: Set, Delete, DeleteRange and batch Apply
+	GitHub  Target = "github"  // GitHub requests other than GET and HEAD (edits)
+	LLM     Target = "llm"     // LLM requests and calls
+)
+
+// Targets lists all the targets.
+var Targets = []Target{Storage, GitHub, LLM}
+
+// Enabled reports whether fault injection is available:
+// in tests and in binaries built with the "chaos" build tag.
+func Enabled() bool {
+	return buildEnabled || testing.Testing()
+}
+
+// An Injector injects failures at configurable rates.
+// It is safe for concurrent use.
+type Injector struct {
+	slog *slog.Logger
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	rates    map[Target]float64
+	injected map[Target]int
+}
+
+// New returns a new Injector that injects no failures until
+// [Injector.SetRate] is called. The seed determines the sequence
+// of random decisions, so that failing tests can be reproduced.
+// New panics if fault injection is not [Enabled].
+func New(lg *slog.Logger, seed uint64) *Injector {
+	if !Enabled() {
+		panic("chaos: fault injection not enabled in this build (build with -tags chaos)")
+	}
+	return &Injector{
+		slog:     lg,
+		rand:     rand.New(rand.NewPCG(seed, seed)),
+		rates:    make(map[Target]float64),
+		injected: make(map[Target]int),
+	}
+}
+
+// Parse returns a new Injector configured by spec, a comma-separated
+// list of TARGET=RATE pairs such as "storage=0.01,github=0.05",
+// seeded with seed.
+func Parse(lg *slog.Logger, spec string, seed uint64) (*Injector, error) {
+	if !Enabled() {
+		return nil, errors.New("chaos: fault injection not enabled in this build (build with -tags chaos)")
+	}
+	in := New(lg, seed)
+	for _, kv := range strings.Split(spec, ",") {
+		t, r, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		rate, err := strconv.ParseFloat(r, 64)
+		if !ok || err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("chaos: bad entry %q: want TARGET=RATE with RATE between 0 and 1", kv)
+		}
+		if !slices.Contains(Targets, Target(t)) {
+			return nil, fmt.Errorf("chaos: unknown target %q (want one of %v)", t, Targets)
+		}
+		in.SetRate(Target(t), rate)
+	}
+	return in, nil
+}
+
+// SetRate sets the fraction of the operations on t that fail,
+// from 0 (none, the default) to 1 (all).
+func (in *Injector) SetRate(t Target, rate float64) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rates[t] = rate
+}
+
+// Injected returns the number of failures injected so far on t.
+func (in *Injector) Injected(t Target) int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.injected[t]
+}
+
+// fail decides whether the operation op on t fails,
+// returning an error wrapping [ErrInjected] if so.
+func (in *Injector) fail(t Target, op string) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.rand.Float64() >= in.rates[t] {
+		return nil
+	}
+	in.injected[t]++
+	in.slog.Warn("chaos: injecting failure", "target", t, "op", op)
+	return fmt.Errorf("%s %s: %w", t, op, ErrInjected)
+}
+
+// coin returns a random boolean.
+func (in *Injector) coin() bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.rand.IntN(2) == 0
+}
+
+// DB returns a [storage.DB] that behaves like db
+// except that writes fail at the [Storage] rate.
+// A failed write panics with an error wrapping [ErrInjected]
+// and does not change db.
+func (in *Injector) DB(db storage.DB) storage.DB {
+	if in == nil {
+		return db
+	}
+	return &chaosDB{DB: db, in: in}
+}
+
+type chaosDB struct {
+	storage.DB
+	in *Injector
+}
+
+// check panics if the write op fails.
+func (in *Injector) check(op string) {
+	if err := in.fail(Storage, op); err != nil {
+		panic(err)
+	}
+}
+
+func (db *chaosDB) Set(key, val []byte) {
+	db.in.check("Set")
+	db.DB.Set(key, val)
+}
+
+func (db *chaosDB) Delete(key []byte) {
+	db.in.check("Delete")
+	db.DB.Delete(key)
+}
+
+func (db *chaosDB) DeleteRange(start, end []byte) {
+	db.in.check("DeleteRange")
+	db.DB.DeleteRange(start, end)
+}
+
+func (db *chaosDB) Scan(start, end []byte) iter.Seq2[[]byte, func() []byte] {
+	return db.DB.Scan(start, end)
+}
+
+func (db *chaosDB) Batch() storage.Batch {
+	return &chaosBatch{Batch: db.DB.Batch(), in: db.in}
+}
+
+type chaosBatch struct {
+	storage.Batch
+	in *Injector
+}
+
+func (b *chaosBatch) MaybeApply() bool {
+	b.in.check("MaybeApply")
+	return b.Batch.MaybeApply()
+}
+
+func (b *chaosBatch) Apply() {
+	b.in.check("Apply")
+	b.Batch.Apply()
+}
+
+// Client returns an HTTP client that behaves like hc
+// (or [http.DefaultClient] if hc is nil) except that requests
+// other than GET and HEAD fail at the rate of t.
+// Half of the failed requests are not sent;
+// the other half are sent, but their responses are discarded.
+func (in *Injector) Client(t Target, hc *http.Client) *http.Client {
+	if in == nil {
+		return hc
+	}
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	c := *hc
+	rt := c.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	c.Transport = &transport{in: in, t: t, rt: rt}
+	return &c
+}
+
+type transport struct {
+	in *Injector
+	t  Target
+	rt http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return t.rt.RoundTrip(req)
+	}
+	err := t.in.fail(t.t, req.Method+" "+req.URL.Path)
+	if err == nil {
+		return t.rt.RoundTrip(req)
+	}
+	if t.in.coin() {
+		// Lose the response.
+		resp, rerr := t.rt.RoundTrip(req)
+		if rerr != nil {
+			return nil, rerr
+		}
+		resp.Body.Close()
+		return nil, fmt.Errorf("response lost: %w", err)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, err
+}
+
+// ContentGenerator returns an [llm.ContentGenerator] that behaves
+// like cg except that GenerateContent fails at the [LLM] rate.
+func (in *Injector) ContentGenerator(cg llm.ContentGenerator) llm.ContentGenerator {
+	if in == nil {
+		return cg
+	}
+	return &generator{ContentGenerator: cg, in: in}
+}
+
+type generator struct {
+	llm.ContentGenerator
+	in *Injector
+}
+
+func (g *generator) GenerateContent(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+	if err := g.in.fail(LLM, "GenerateContent"); err != nil {
+		return "", err
+	}
+	return g.ContentGenerator.GenerateContent(ctx, schema, parts)
+}
+
+func (g *generator) GenerateContentModel(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, string, error) {
+	if err := g.in.fail(LLM, "GenerateContent"); err != nil {
+		return "", "", err
+	}
+	return llm.GenerateContentModel(ctx, g.ContentGenerator, schema, parts)
+}
+
+// Embedder returns an [llm.Embedder] that behaves like e
+// except that EmbedDocs fails at the [LLM] rate.
+func (in *Injector) Embedder(e llm.Embedder) llm.Embedder {
+	if in == nil {
+		return e
+	}
+	return &embedder{e: e, in: in}
+}
+
+type embedder struct {
+	e  llm.Embedder
+	in *Injector
+}
+
+func (e *embedder) EmbedDocs(ctx context.Context, docs []llm.EmbedDoc) ([]llm.Vector, error) {
+	if err := e.in.fail(LLM, "EmbedDocs"); err != nil {
+		return nil, err
+	}
+	return e.e.EmbedDocs(ctx, docs)
+}
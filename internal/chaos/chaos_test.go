@@ -0,0 +1,110 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chaos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestParse(t *testing.T) {
+	lg := testutil.Slogger(t)
+	in, err := Parse(lg, "storage=0.5, llm=1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.rates[Storage] != 0.5 || in.rates[LLM] != 1 || in.rates[GitHub] != 0 {
+		t.Errorf("Parse: rates = %v", in.rates)
+	}
+	for _, bad := range []string{"storage", "storage=2", "disk=0.1", "llm=x"} {
+		if _, err := Parse(lg, bad, 1); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestDB(t *testing.T) {
+	in := New(testutil.Slogger(t), 1)
+	mdb := storage.MemDB()
+	db := in.DB(mdb)
+
+	db.Set([]byte("a"), []byte("1"))
+	in.SetRate(Storage, 1)
+	testutil.StopPanic(func() {
+		db.Set([]byte("b"), []byte("2"))
+		t.Fatal("Set did not fail")
+	})
+	testutil.StopPanic(func() {
+		b := db.Batch()
+		b.Delete([]byte("a"))
+		b.Apply()
+		t.Fatal("Apply did not fail")
+	})
+	if _, ok := db.Get([]byte("a")); !ok {
+		t.Error("failed batch deleted a")
+	}
+	if _, ok := mdb.Get([]byte("b")); ok {
+		t.Error("failed Set wrote b")
+	}
+	if n := in.Injected(Storage); n != 2 {
+		t.Errorf("Injected(Storage) = %d, want 2", n)
+	}
+}
+
+func TestClient(t *testing.T) {
+	in := New(testutil.Slogger(t), 1)
+	sent := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+	}))
+	defer srv.Close()
+	hc := in.Client(GitHub, srv.Client())
+	in.SetRate(GitHub, 1)
+
+	// Reads never fail.
+	resp, err := hc.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	const N = 100
+	for range N {
+		_, err := hc.Post(srv.URL, "text/plain", nil)
+		if !errors.Is(err, ErrInjected) {
+			t.Fatalf("Post error = %v, want ErrInjected", err)
+		}
+	}
+	// About half the failed edits are sent anyway.
+	if lost := sent - 1; lost < N/4 || lost > 3*N/4 {
+		t.Errorf("%d of %d failed requests sent, want about half", lost, N)
+	}
+}
+
+func TestLLM(t *testing.T) {
+	in := New(testutil.Slogger(t), 1)
+	in.SetRate(LLM, 1)
+	gen := in.ContentGenerator(llm.EchoContentGenerator())
+	if _, err := gen.GenerateContent(context.Background(), nil, []llm.Part{llm.Text("hi")}); !errors.Is(err, ErrInjected) {
+		t.Errorf("GenerateContent error = %v, want ErrInjected", err)
+	}
+	embed := in.Embedder(llm.QuoteEmbedder())
+	if _, err := embed.EmbedDocs(context.Background(), []llm.EmbedDoc{{Text: "hi"}}); !errors.Is(err, ErrInjected) {
+		t.Errorf("EmbedDocs error = %v, want ErrInjected", err)
+	}
+
+	// A nil Injector injects nothing.
+	var none *Injector
+	if _, err := none.ContentGenerator(llm.EchoContentGenerator()).GenerateContent(context.Background(), nil, []llm.Part{llm.Text("hi")}); err != nil {
+		t.Errorf("nil Injector: %v", err)
+	}
+}
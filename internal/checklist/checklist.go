@@ -0,0 +1,211 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package checklist generates checklists for reviewing pull requests,
+// tailored to each change and to its project's review guidelines,
+// and posts them as collapsed comments on the pull requests.
+//
+// Create a Checklister with [New], set each project's guidelines with
+// [Checklister.SetGuidelines], and generate checklists with
+// [Checklister.ForPullRequest]. [Checklister.Post] adds an action to the
+// action log to post a checklist; by default, the action requires approval.
+//
+// Database entries are as follows:
+//
+//   - (checklist.Guidelines, $project) -> the project's review guidelines, as text
+//   - Action log entries of kind "checklist.Post", keyed by ($name, $project, $number, $hash).
+package checklist
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// A Checklister generates and posts review checklists for pull requests.
+type Checklister struct {
+	slog *slog.Logger
+	db   storage.DB
+	gh   *github.Client
+	lc   *llmapp.Client
+	name string
+
+	plainText       map[string]bool // projects to post plain-text comments in
+	requireApproval bool
+	logAction       actions.BeforeFunc
+}
+
+const (
+	guidelinesKind = "checklist.Guidelines"
+	actionKind     = "checklist.Post"
+)
+
+// New returns a new Checklister that reads pull requests with gh,
+// generates checklists with lc, and stores state in db.
+// The name distinguishes the actions of different Checklisters
+// in the action log.
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, lc *llmapp.Client, name string) *Checklister {
+	c := &Checklister{
+		slog:            lg,
+		db:              db,
+		gh:              gh,
+		lc:              lc,
+		name:            name,
+		requireApproval: true,
+	}
+	c.logAction = actions.Register(actionKind, &actioner{c})
+	return c
+}
+
+// AutoApprove configures the Checklister to auto-approve its actions.
+// By default, posting a checklist requires approval.
+func (c *Checklister) AutoApprove() {
+	c.requireApproval = false
+}
+
+// EnablePlainText configures the Checklister to post plain-text comments
+// (see [github.PlainText]) on pull requests in the given GitHub project,
+// so that they read well with a screen reader.
+func (c *Checklister) EnablePlainText(project string) {
+	if c.plainText == nil {
+		c.plainText = make(map[string]bool)
+	}
+	c.plainText[project] = true
+}
+
+// SetGuidelines sets the review guidelines for the GitHub project,
+// such as "Exported identifiers must have doc comments."
+// The guidelines are free-form text (typically a Markdown list) given to
+// the LLM along with each pull request; they are stored in the database,
+// so they are shared by all Checklisters using it.
+// Setting empty guidelines deletes them.
+func (c *Checklister) SetGuidelines(project, text string) {
+	key := ordered.Encode(guidelinesKind, project)
+	if strings.TrimSpace(text) == "" {
+		c.db.Delete(key)
+		return
+	}
+	c.db.Set(key, []byte(text))
+}
+
+// Guidelines returns the review guidelines for the GitHub project,
+// or "" if there are none.
+func (c *Checklister) Guidelines(project string) string {
+	text, ok := c.db.Get(ordered.Encode(guidelinesKind, project))
+	if !ok {
+		return ""
+	}
+	return string(text)
+}
+
+// A Result is the result of [Checklister.ForPullRequest].
+type Result struct {
+	Files      int            // number of files changed by the pull request
+	Guidelines bool           // whether the project has review guidelines
+	Checklist  *llmapp.Result // the LLM-generated checklist, a Markdown task list
+}
+
+// ForPullRequest returns an LLM-generated checklist for reviewing the pull
+// request, combining its diff, which it downloads from GitHub, with its
+// project's review guidelines (see [Checklister.SetGuidelines]).
+// It returns an error if pr is not a pull request.
+func (c *Checklister) ForPullRequest(ctx context.Context, pr *github.Issue) (*Result, error) {
+	if pr.PullRequest == nil {
+		return nil, fmt.Errorf("checklist: %s#%d is not a pull request", pr.Project(), pr.Number)
+	}
+	files, err := c.gh.PullRequestFiles(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*llmapp.Doc
+	for _, f := range files {
+		docs = append(docs, f.ToLLMDoc())
+	}
+	guidelines := c.Guidelines(pr.Project())
+	r, err := c.lc.ReviewChecklist(ctx, pr.ToLLMDoc(), guidelines, docs)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Files:      len(files),
+		Guidelines: guidelines != "",
+		Checklist:  r,
+	}, nil
+}
+
+// Post adds an action to the action log to post the checklist r
+// on the pull request as a collapsed comment, or as plain text
+// in projects configured with [Checklister.EnablePlainText].
+// It reports whether the action was added: an identical checklist
+// is only posted once.
+func (c *Checklister) Post(pr *github.Issue, r *Result) bool {
+	body := comment(r.Checklist.Response)
+	if c.plainText[pr.Project()] {
+		body = github.PlainText(body)
+	}
+	a := &action{
+		Issue:   pr,
+		Changes: &github.IssueCommentChanges{Body: body},
+	}
+	h := sha256.Sum256([]byte(a.Changes.Body))
+	key := ordered.Encode(c.name, pr.Project(), pr.Number, fmt.Sprintf("%x", h[:8]))
+	return c.logAction(c.db, key, storage.JSON(a), c.requireApproval)
+}
+
+// comment returns the text of a comment posting the checklist,
+// collapsed so that it does not take over the conversation.
+func comment(checklist string) string {
+	// These strings may be freely edited.
+	return "<details><summary>Review checklist (generated by AI)</summary>\n\n" +
+		strings.TrimSpace(checklist) + "\n\n" +
+		"<sub>(Generated by AI from the diff and the project's review guidelines. It may be wrong or incomplete.)</sub>\n" +
+		"</details>\n"
+}
+
+// An action is an action to post a checklist.
+type action struct {
+	Issue   *github.Issue               // the pull request
+	Changes *github.IssueCommentChanges // the comment to post
+}
+
+// A result is the result of running an [action].
+type result struct {
+	URL string // URL of the posted comment
+}
+
+// actioner implements [actions.Actioner].
+type actioner struct {
+	c *Checklister
+}
+
+// Implements [actions.Actioner.Run].
+func (ar *actioner) Run(ctx context.Context, data []byte) ([]byte, error) {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	_, url, err := ar.c.gh.PostIssueComment(ctx, a.Issue, a.Changes)
+	if err != nil {
+		return nil, fmt.Errorf("checklist: posting on %s: %w", a.Issue.HTMLURL, err)
+	}
+	return storage.JSON(&result{URL: url}), nil
+}
+
+// Implements [actions.Actioner.ForDisplay].
+func (ar *actioner) ForDisplay(data []byte) string {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	return "post review checklist to: " + a.Issue.HTMLURL + "\nnew comment:\n" + a.Changes.Body
+}
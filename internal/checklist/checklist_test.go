@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checklist
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestChecklister(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	lc := llmapp.New(lg, llm.EchoContentGenerator(), db)
+	check := testutil.Checker(t)
+
+	gh := github.New(lg, db, nil, nil)
+	const project = "test/test"
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "an issue"})
+	gh.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "a change", PullRequest: new(struct{})})
+	iss, err := github.LookupIssue(db, project, 1)
+	check(err)
+	pr, err := github.LookupIssue(db, project, 2)
+	check(err)
+	gh.Testing().SetPullRequestFiles(pr, []*github.PullRequestFile{
+		{Filename: "a.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -1 +1 @@\n-x\n+y"},
+	})
+
+	c := New(lg, db, gh, lc, "test")
+	c.AutoApprove()
+
+	if _, err := c.ForPullRequest(ctx, iss); err == nil {
+		t.Error("ForPullRequest(issue) succeeded, want error")
+	}
+
+	// Without guidelines.
+	r, err := c.ForPullRequest(ctx, pr)
+	check(err)
+	if r.Files != 1 || r.Guidelines || len(r.Checklist.Prompt) != 6 {
+		t.Errorf("ForPullRequest without guidelines: files=%d guidelines=%v\n%s", r.Files, r.Guidelines, r.Checklist.Response)
+	}
+
+	// With guidelines.
+	const guidelines = "- Exported identifiers need doc comments."
+	c.SetGuidelines(project, guidelines)
+	if got := c.Guidelines(project); got != guidelines {
+		t.Errorf("Guidelines = %q, want %q", got, guidelines)
+	}
+	if got := c.Guidelines("other/project"); got != "" {
+		t.Errorf("Guidelines(other) = %q, want empty", got)
+	}
+	r, err = c.ForPullRequest(ctx, pr)
+	check(err)
+	if !r.Guidelines || !strings.Contains(r.Checklist.Response, "Exported identifiers need doc comments") || !strings.Contains(r.Checklist.Response, "a.go") {
+		t.Errorf("ForPullRequest with guidelines: guidelines=%v\n%s", r.Guidelines, r.Checklist.Response)
+	}
+
+	// Post logs one action per distinct checklist.
+	if !c.Post(pr, r) {
+		t.Fatal("Post: not logged")
+	}
+	if c.Post(pr, r) {
+		t.Error("Post (same checklist): logged again")
+	}
+	check(actions.Run(ctx, lg, db))
+	edits := gh.Testing().Edits()
+	if len(edits) != 1 {
+		t.Fatalf("got %d edits, want 1", len(edits))
+	}
+	body := edits[0].IssueCommentChanges.Body
+	if edits[0].Issue != 2 || !strings.HasPrefix(body, "<details><summary>Review checklist") || !strings.HasSuffix(body, "</details>\n") {
+		t.Errorf("posted comment on #%d:\n%s", edits[0].Issue, body)
+	}
+
+	// In plain-text projects, the comment is not collapsed.
+	c.EnablePlainText(project)
+	r.Checklist.Response += "\n- Another item."
+	if !c.Post(pr, r) {
+		t.Fatal("Post (plain text): not logged")
+	}
+	check(actions.Run(ctx, lg, db))
+	edits = gh.Testing().Edits()
+	if len(edits) != 2 {
+		t.Fatalf("got %d edits, want 2", len(edits))
+	}
+	body = edits[1].IssueCommentChanges.Body
+	if !strings.HasPrefix(body, "Review checklist (generated by AI):\n") || strings.Contains(body, "<") {
+		t.Errorf("posted plain-text comment:\n%s", body)
+	}
+
+	c.SetGuidelines(project, "")
+	if got := c.Guidelines(project); got != "" {
+		t.Errorf("Guidelines after clearing = %q, want empty", got)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package circuit implements circuit breakers for calls to
+// external dependencies, such as GitHub, LLM services, and vector databases.
+//
+// A [Breaker] starts out closed, letting all calls through.
+// After a configurable number of consecutive failures, it opens,
+// and all calls fail immediately with [ErrOpen] instead of reaching
+// the dependency. After a cooldown period, the breaker becomes half-open
+// and lets a single probe call through: if the probe succeeds, the breaker
+// closes again; if it fails, the breaker reopens for another cooldown period.
+//
+// This keeps retries during an outage from exhausting quotas
+// or wedging the callers that are waiting on the dependency.
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrOpen is the error returned (wrapped) for calls rejected by an open [Breaker].
+var ErrOpen = errors.New("circuit breaker open")
+
+// A State is the state of a [Breaker].
+type State int
+
+const (
+	Closed   State = iota // calls are allowed
+	Open                  // calls are rejected
+	HalfOpen              // a single probe call is allowed
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Default configuration for a [Breaker].
+const (
+	defaultThreshold = 5
+	defaultCooldown  = 1 * time.Minute
+)
+
+// A Breaker is a circuit breaker for calls to a single dependency.
+// It is safe for concurrent use.
+type Breaker struct {
+	slog      *slog.Logger
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time // for testing
+
+	mu       sync.Mutex
+	state    State
+	failures int       // consecutive failures while closed
+	openedAt time.Time // time of last transition to Open
+	probing  bool      // a half-open probe is in flight
+	stats    Stats
+}
+
+// Stats holds counts of the calls made through a [Breaker].
+type Stats struct {
+	Successes int64 // calls that succeeded
+	Failures  int64 // calls that failed
+	Rejected  int64 // calls rejected because the breaker was open
+	Opened    int64 // number of times the breaker opened
+}
+
+// New returns a new, closed Breaker with the given name,
+// which is used in errors, logs and metrics.
+// By default, the Breaker opens after 5 consecutive failures
+// and stays open for 1 minute before probing.
+func New(lg *slog.Logger, name string) *Breaker {
+	return &Breaker{
+		slog:      lg,
+		name:      name,
+		threshold: defaultThreshold,
+		cooldown:  defaultCooldown,
+		now:       time.Now,
+	}
+}
+
+// SetThreshold sets the number of consecutive failures
+// that cause the Breaker to open.
+func (b *Breaker) SetThreshold(n int) {
+	b.threshold = max(n, 1)
+}
+
+// SetCooldown sets the time the Breaker stays open
+// before allowing a probe call.
+func (b *Breaker) SetCooldown(d time.Duration) {
+	b.cooldown = d
+}
+
+// Name returns the Breaker's name.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the Breaker's current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.cooldown {
+		// Report that the next call will be a probe.
+		return HalfOpen
+	}
+	return b.state
+}
+
+// Stats returns the Breaker's call statistics.
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// Do calls f if the Breaker allows it, and records the outcome.
+// Any error other than context cancellation counts as a failure.
+// If the Breaker is open, Do returns an error wrapping [ErrOpen]
+// without calling f.
+func (b *Breaker) Do(f func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := f()
+	if errors.Is(err, context.Canceled) {
+		b.cancel()
+	} else {
+		b.record(err == nil)
+	}
+	return err
+}
+
+// allow reports whether a call may proceed,
+// returning an error wrapping [ErrOpen] if not.
+// Every successful call to allow must be followed by
+// a call to record or cancel.
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		return nil
+	case Open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			break
+		}
+		b.setState(HalfOpen)
+		fallthrough
+	case HalfOpen:
+		if !b.probing {
+			b.probing = true
+			return nil
+		}
+	}
+	b.stats.Rejected++
+	return fmt.Errorf("%s: %w", b.name, ErrOpen)
+}
+
+// record records the outcome of a call allowed by allow.
+func (b *Breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok {
+		b.stats.Successes++
+		b.failures = 0
+		if b.state == HalfOpen {
+			b.probing = false
+			b.setState(Closed)
+		}
+		return
+	}
+
+	b.stats.Failures++
+	switch b.state {
+	case Closed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	case HalfOpen:
+		b.probing = false
+		b.open()
+	}
+}
+
+// cancel records that a call allowed by allow was abandoned
+// by the caller, which says nothing about the dependency.
+func (b *Breaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		b.probing = false
+	}
+}
+
+// open opens the breaker. b.mu must be held.
+func (b *Breaker) open() {
+	b.failures = 0
+	b.openedAt = b.now()
+	b.stats.Opened++
+	b.setState(Open)
+}
+
+// setState changes the state of the breaker. b.mu must be held.
+func (b *Breaker) setState(s State) {
+	if b.state == s {
+		return
+	}
+	b.slog.Warn("circuit breaker state change", "name", b.name, "from", b.state, "to", s)
+	b.state = s
+}
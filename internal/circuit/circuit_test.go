@@ -0,0 +1,152 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package circuit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// newTestBreaker returns a Breaker with a fake clock,
+// and a function to advance the clock.
+func newTestBreaker(t *testing.T) (*Breaker, func(time.Duration)) {
+	b := New(testutil.Slogger(t), "test")
+	b.SetThreshold(2)
+	b.SetCooldown(time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	return b, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestBreaker(t *testing.T) {
+	b, advance := newTestBreaker(t)
+	errFail := errors.New("fail")
+	calls := 0
+	fail := func() error { calls++; return errFail }
+	succeed := func() error { calls++; return nil }
+
+	checkState := func(want State) {
+		t.Helper()
+		if got := b.State(); got != want {
+			t.Fatalf("State() = %v, want %v", got, want)
+		}
+	}
+
+	// Context cancellation is not a failure.
+	b.Do(func() error { return context.Canceled })
+	b.Do(fail)
+	checkState(Closed)
+	b.Do(succeed) // resets consecutive failures
+	b.Do(fail)
+	checkState(Closed)
+	b.Do(fail)
+	checkState(Open)
+
+	// Open: calls are rejected without calling f.
+	calls = 0
+	if err := b.Do(succeed); !errors.Is(err, ErrOpen) || calls != 0 {
+		t.Fatalf("Do on open breaker: err=%v, calls=%d; want ErrOpen, 0", err, calls)
+	}
+
+	// After the cooldown, a failed probe reopens the breaker.
+	advance(time.Minute)
+	checkState(HalfOpen)
+	if err := b.Do(fail); err != errFail {
+		t.Fatalf("probe: err=%v, want %v", err, errFail)
+	}
+	checkState(Open)
+
+	// Only one probe is allowed at a time.
+	advance(time.Minute)
+	err := b.Do(func() error {
+		if err := b.Do(succeed); !errors.Is(err, ErrOpen) {
+			t.Errorf("concurrent probe: err=%v, want ErrOpen", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkState(Closed)
+
+	want := Stats{Successes: 2, Failures: 4, Rejected: 2, Opened: 2}
+	if got := b.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClient(t *testing.T) {
+	status := http.StatusInternalServerError
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	b, _ := newTestBreaker(t)
+	hc := b.Client(srv.Client())
+	for range 2 {
+		resp, err := hc.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := hc.Get(srv.URL); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Get with open breaker: err=%v, want ErrOpen", err)
+	}
+
+	// Rate limits and other client errors are not failures.
+	b, _ = newTestBreaker(t)
+	hc = b.Client(srv.Client())
+	status = http.StatusForbidden
+	for range 3 {
+		resp, err := hc.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if s := b.State(); s != Closed {
+		t.Errorf("State() = %v after 403s, want closed", s)
+	}
+}
+
+func TestVectorDB(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	b, _ := newTestBreaker(t)
+	vdb := b.VectorDB(storage.MemVectorDB(db, lg, "vecs"))
+
+	vdb.Set("a", llm.Vector{1, 0})
+	if _, ok := vdb.Get("a"); !ok {
+		t.Fatal("Get(a) failed")
+	}
+	vb := vdb.Batch()
+	vb.Set("b", llm.Vector{0, 1})
+	vb.Apply()
+	if res := vdb.Search(llm.Vector{0, 1}, 1, nil); len(res) != 1 || res[0].ID != "b" {
+		t.Fatalf("Search = %v, want b", res)
+	}
+
+	// Force the breaker open.
+	b.record(false)
+	b.record(false)
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrOpen) {
+			t.Fatalf("Get with open breaker: panic %v, want ErrOpen", err)
+		}
+	}()
+	vdb.Get("a")
+	t.Fatal("Get with open breaker did not panic")
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// Client returns an HTTP client that behaves like hc (or [http.DefaultClient]
+// if hc is nil) but sends its requests through the Breaker.
+// Transport errors and responses with status 5xx count as failures.
+// Other responses, including rate-limiting errors, count as successes:
+// callers are expected to handle those themselves.
+func (b *Breaker) Client(hc *http.Client) *http.Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	c := *hc
+	rt := c.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	c.Transport = &transport{b: b, rt: rt}
+	return &c
+}
+
+type transport struct {
+	b  *Breaker
+	rt http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.b.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := t.rt.RoundTrip(req)
+	switch {
+	case errors.Is(err, context.Canceled):
+		t.b.cancel()
+	case err != nil:
+		t.b.record(false)
+	default:
+		t.b.record(resp.StatusCode < 500)
+	}
+	return resp, err
+}
+
+// VectorDB returns a [storage.VectorDB] that behaves like vdb but sends
+// its operations through the Breaker.
+//
+// Following the [storage] conventions, operations report failures by panicking.
+// A panic from vdb counts as a failure and is propagated;
+// an operation rejected by the open Breaker panics with an error wrapping [ErrOpen].
+func (b *Breaker) VectorDB(vdb storage.VectorDB) storage.VectorDB {
+	return &vectorDB{b: b, vdb: vdb}
+}
+
+type vectorDB struct {
+	b   *Breaker
+	vdb storage.VectorDB
+}
+
+// guard calls f through the Breaker, converting panics
+// into failures and rejections into panics.
+func (b *Breaker) guard(f func()) {
+	if err := b.allow(); err != nil {
+		panic(err)
+	}
+	ok := false
+	defer func() { b.record(ok) }()
+	f()
+	ok = true
+}
+
+func (v *vectorDB) Set(id string, vec llm.Vector) {
+	v.b.guard(func() { v.vdb.Set(id, vec) })
+}
+
+func (v *vectorDB) Delete(id string) {
+	v.b.guard(func() { v.vdb.Delete(id) })
+}
+
+func (v *vectorDB) Get(id string) (vec llm.Vector, ok bool) {
+	v.b.guard(func() { vec, ok = v.vdb.Get(id) })
+	return vec, ok
+}
+
+// All is not guarded by the Breaker other than failing
+// immediately when the Breaker is open, because panics during the
+// iteration may come from the caller's loop body.
+func (v *vectorDB) All() iter.Seq2[string, func() llm.Vector] {
+	if v.b.State() == Open {
+		panic(fmt.Errorf("%s: %w", v.b.name, ErrOpen))
+	}
+	return v.vdb.All()
+}
+
+func (v *vectorDB) Batch() storage.VectorBatch {
+	return &vectorBatch{b: v.b, vb: v.vdb.Batch()}
+}
+
+func (v *vectorDB) Search(vec llm.Vector, n int, keep storage.VectorFilter) (res []storage.VectorResult) {
+	v.b.guard(func() { res = v.vdb.Search(vec, n, keep) })
+	return res
+}
+
+func (v *vectorDB) Flush() {
+	v.b.guard(func() { v.vdb.Flush() })
+}
+
+type vectorBatch struct {
+	b  *Breaker
+	vb storage.VectorBatch
+}
+
+func (vb *vectorBatch) Set(id string, vec llm.Vector) {
+	vb.vb.Set(id, vec)
+}
+
+func (vb *vectorBatch) Delete(id string) {
+	vb.vb.Delete(id)
+}
+
+func (vb *vectorBatch) MaybeApply() (applied bool) {
+	vb.b.guard(func() { applied = vb.vb.MaybeApply() })
+	return applied
+}
+
+func (vb *vectorBatch) Apply() {
+	vb.b.guard(func() { vb.vb.Apply() })
+}
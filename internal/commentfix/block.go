@@ -0,0 +1,233 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commentfix
+
+import (
+	"regexp"
+	"strings"
+
+	"rsc.io/markdown"
+)
+
+// FenceCode instructs the fixer to repair code blocks.
+// It wraps unfenced program output, such as panics, goroutine
+// stack traces, compiler errors, and shell sessions starting with "$ ",
+// in ``` fences, so that GitHub does not reflow it as prose.
+// It also moves text that follows a code fence marker on the same
+// line, as in "``` more text", onto its own line.
+//
+// FenceCode applies to the raw text before the other fixes,
+// which then see the repaired code blocks.
+func (f *Fixer) FenceCode() {
+	f.init()
+	f.textFixes = append(f.textFixes, fenceCode)
+}
+
+// RemoveEmptySections instructs the fixer to remove the headings of
+// sections that have no content, such as the sections of an issue
+// template that the author left unfilled.
+// A section is empty if nothing but HTML comments or the "_No response_"
+// placeholder that GitHub issue forms use for empty fields appears
+// between its heading and the next heading of the same or higher level.
+func (f *Fixer) RemoveEmptySections() {
+	f.init()
+	f.blockFixes = append(f.blockFixes, removeEmptySections)
+}
+
+var (
+	fenceRE     = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	codeStartRE = regexp.MustCompile(`^(panic: |fatal error: |goroutine \d+ \[|\$ [a-z]|\S+\.go:\d+(:\d+)?: )`)
+	codeLineRE  = regexp.MustCompile(`^\t|\.go:\d+\b`)
+)
+
+// A textLine is a line of Markdown text, as processed by [fenceCode].
+type textLine struct {
+	text  string
+	fence bool // line is a fence or inside a fenced code block
+}
+
+// fenceCode implements [Fixer.FenceCode].
+func fenceCode(text string) (string, bool) {
+	lines, fixed := splitFences(text)
+
+	var out []string
+	for i := 0; i < len(lines); {
+		l := lines[i]
+		if l.fence || isBlank(l.text) || isIndented(l.text) {
+			out = append(out, l.text)
+			i++
+			continue
+		}
+		j := paragraphEnd(lines, i)
+		if !codeStartRE.MatchString(lines[i].text) && !(j-i > 1 && codeLines(lines[i:j]) == j-i) {
+			for _, l := range lines[i:j] {
+				out = append(out, l.text)
+			}
+			i = j
+			continue
+		}
+		// Extend the code block through following paragraphs
+		// that are mostly code, like the goroutines of a stack trace.
+		for {
+			k := j
+			for k < len(lines) && !lines[k].fence && isBlank(lines[k].text) {
+				k++
+			}
+			if k == len(lines) || lines[k].fence {
+				break
+			}
+			e := paragraphEnd(lines, k)
+			if 2*codeLines(lines[k:e]) < e-k {
+				break
+			}
+			j = e
+		}
+		out = append(out, "```")
+		for _, l := range lines[i:j] {
+			out = append(out, l.text)
+		}
+		out = append(out, "```")
+		fixed = true
+		i = j
+	}
+	if !fixed {
+		return "", false
+	}
+	return strings.Join(out, "\n"), true
+}
+
+// splitFences splits text into lines, marking the ones in fenced code blocks.
+// It moves text following a fence marker onto a separate line, reporting
+// whether it did so.
+func splitFences(text string) (_ []textLine, fixed bool) {
+	var lines []textLine
+	fence := "" // closing marker of the current fenced block, if any
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.TrimSuffix(l, "\r")
+		if fence == "" {
+			m := fenceRE.FindStringSubmatch(l)
+			if m == nil {
+				lines = append(lines, textLine{text: l})
+				continue
+			}
+			fence = m[1]
+			// An info string is a single word like "go";
+			// more than that is code on the fence line.
+			if rest, ok := strings.CutPrefix(l[len(m[0]):], " "); ok && strings.Contains(strings.TrimSpace(rest), " ") {
+				lines = append(lines, textLine{m[0], true}, textLine{strings.TrimSpace(rest), true})
+				fixed = true
+				continue
+			}
+			lines = append(lines, textLine{l, true})
+			continue
+		}
+		t := strings.TrimLeft(l, " ")
+		if len(l)-len(t) <= 3 && strings.HasPrefix(t, fence) {
+			rest := strings.TrimLeft(t, fence[:1])
+			if isBlank(rest) {
+				lines = append(lines, textLine{l, true})
+				fence = ""
+				continue
+			}
+			if rest[0] == ' ' {
+				// Closing fence followed by text.
+				lines = append(lines, textLine{l[:len(l)-len(rest)], true}, textLine{text: strings.TrimSpace(rest)})
+				fence = ""
+				fixed = true
+				continue
+			}
+		}
+		lines = append(lines, textLine{l, true})
+	}
+	return lines, fixed
+}
+
+// paragraphEnd returns the index of the line after
+// the paragraph starting at lines[i].
+func paragraphEnd(lines []textLine, i int) int {
+	for i < len(lines) && !lines[i].fence && !isBlank(lines[i].text) {
+		i++
+	}
+	return i
+}
+
+// codeLines returns the number of lines that look like program output.
+func codeLines(lines []textLine) int {
+	n := 0
+	for _, l := range lines {
+		if codeStartRE.MatchString(l.text) || codeLineRE.MatchString(l.text) {
+			n++
+		}
+	}
+	return n
+}
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// isIndented reports whether s starts an indented code block.
+func isIndented(s string) bool {
+	return strings.HasPrefix(s, "\t") || strings.HasPrefix(s, "    ")
+}
+
+// removeEmptySections implements [Fixer.RemoveEmptySections].
+func removeEmptySections(doc *markdown.Document) bool {
+	fixed := false
+	// Removing a subsection can leave its parent section empty,
+	// so repeat until nothing changes.
+	for removeEmptySection(doc) {
+		fixed = true
+	}
+	return fixed
+}
+
+// removeEmptySection removes the empty sections at the top level of doc
+// that have no empty subsections, reporting whether it removed any.
+func removeEmptySection(doc *markdown.Document) bool {
+	fixed := false
+	var out []markdown.Block
+	for i := 0; i < len(doc.Blocks); {
+		h, ok := doc.Blocks[i].(*markdown.Heading)
+		if !ok {
+			out = append(out, doc.Blocks[i])
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(doc.Blocks) && isPlaceholder(doc.Blocks[j]) {
+			j++
+		}
+		if j < len(doc.Blocks) {
+			if next, ok := doc.Blocks[j].(*markdown.Heading); !ok || next.Level > h.Level {
+				out = append(out, doc.Blocks[i:j]...)
+				i = j
+				continue
+			}
+		}
+		// Drop the heading and its placeholders.
+		fixed = true
+		i = j
+	}
+	if fixed {
+		doc.Blocks = out
+	}
+	return fixed
+}
+
+// isPlaceholder reports whether b is a block that
+// stands in for missing section content.
+func isPlaceholder(b markdown.Block) bool {
+	switch b := b.(type) {
+	case *markdown.Empty:
+		return true
+	case *markdown.HTMLBlock:
+		text := strings.TrimSpace(strings.Join(b.Text, "\n"))
+		return strings.HasPrefix(text, "<!--") && strings.HasSuffix(text, "-->")
+	case *markdown.Paragraph:
+		return strings.TrimSpace(markdown.Format(b)) == "_No response_"
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commentfix
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/diff"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestFenceCode(t *testing.T) {
+	for _, tt := range []struct {
+		name, in, out string
+	}{
+		{
+			name: "panic",
+			in: `It crashes:
+
+panic: runtime error: index out of range [3] with length 3
+
+goroutine 1 [running]:
+main.main()
+	/tmp/x.go:5 +0x1d
+exit status 2
+
+Any ideas?
+`,
+			out: "It crashes:\n\n```\npanic: runtime error: index out of range [3] with length 3\n\ngoroutine 1 [running]:\nmain.main()\n\t/tmp/x.go:5 +0x1d\nexit status 2\n```\n\nAny ideas?\n",
+		},
+		{
+			name: "shell",
+			in:   "Run:\n\n$ go build\n./x.go:3:2: undefined: y\n",
+			out:  "Run:\n\n```\n$ go build\n./x.go:3:2: undefined: y\n```\n",
+		},
+		{
+			name: "closing-fence-text",
+			in:   "```\nx := 1\n``` That is all.\n",
+			out:  "```\nx := 1\n```\n\nThat is all.\n",
+		},
+		{
+			name: "opening-fence-code",
+			in:   "``` x := 1\ny := 2\n```\n",
+			out:  "```\nx := 1\ny := 2\n```\n",
+		},
+		{
+			name: "fenced",
+			in:   "```\npanic: boom\n```\n\n```go\nx := 1\n```\n",
+		},
+		{
+			name: "prose",
+			in:   "The file x.go:3 has a bug.\n\nPlease fix.\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var f Fixer
+			f.FenceCode()
+			got, fixed := f.Fix(tt.in)
+			if fixed != (tt.out != "") {
+				t.Fatalf("Fix() fixed = %v, want %v; got:\n%s", fixed, tt.out != "", got)
+			}
+			if got != tt.out {
+				t.Errorf("Fix: incorrect output:\n%s", diff.Diff("want", []byte(tt.out), "have", []byte(got)))
+			}
+		})
+	}
+}
+
+func TestRemoveEmptySections(t *testing.T) {
+	for _, tt := range []struct {
+		name, in, out string
+	}{
+		{
+			name: "form",
+			in:   "### Go version\n\ngo1.23\n\n### Output of go env\n\n_No response_\n\n### What did you do?\n\nRan it.\n",
+			out:  "### Go version\n\ngo1.23\n\n### What did you do?\n\nRan it.\n",
+		},
+		{
+			name: "nested",
+			in:   "## Details\n\n### Logs\n\n<!-- paste logs here -->\n\n## Summary\n\nBroken.\n",
+			out:  "## Summary\n\nBroken.\n",
+		},
+		{
+			name: "trailing",
+			in:   "Broken.\n\n### Extra\n",
+			out:  "Broken.\n",
+		},
+		{
+			name: "filled",
+			in:   "### Go version\n\ngo1.23\n\n### What did you do?\n\nRan it.\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var f Fixer
+			f.RemoveEmptySections()
+			got, fixed := f.Fix(tt.in)
+			if fixed != (tt.out != "") {
+				t.Fatalf("Fix() fixed = %v, want %v; got:\n%s", fixed, tt.out != "", got)
+			}
+			if got != tt.out {
+				t.Errorf("Fix: incorrect output:\n%s", diff.Diff("want", []byte(tt.out), "have", []byte(got)))
+			}
+		})
+	}
+}
+
+func TestIssuesOnly(t *testing.T) {
+	gh := testGitHub(t)
+	db := storage.MemDB()
+	check := testutil.Checker(t)
+
+	f := New(testutil.Slogger(t), gh, db, "issuesonly")
+	f.SetStderr(testutil.LogWriter(t))
+	f.EnableProject("rsc/tmp")
+	f.SetTimeLimit(time.Time{})
+	f.ReplaceText("cancelled", "canceled")
+	f.IssuesOnly()
+	f.EnableEdits()
+	check(f.Run(ctx))
+
+	entries := actionLogEntries(db)
+	if len(entries) != 2 {
+		t.Fatalf("got %d actions, want 2 (issues 18 and 20 but not the comment)", len(entries))
+	}
+}
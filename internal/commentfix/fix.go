@@ -31,7 +31,8 @@ import (
 
 // A Fixer rewrites issue texts and issue comments using a set of rules.
 // After creating a fixer with [New], new rules can be added using
-// the [Fixer.AutoLink], [Fixer.ReplaceText], and [Fixer.ReplaceURL] methods,
+// the [Fixer.AutoLink], [Fixer.ReplaceText], [Fixer.ReplaceURL],
+// [Fixer.FenceCode], and [Fixer.RemoveEmptySections] methods,
 // and then repeated calls to [Fixer.Run] apply the replacements on GitHub.
 //
 // The zero value of a Fixer can be used in “offline” mode with [Fixer.Fix],
@@ -44,9 +45,12 @@ type Fixer struct {
 	github          *github.Client
 	watcher         *timed.Watcher[*github.Event]
 	fixes           []func(any, int) any
+	textFixes       []func(string) (string, bool)   // fixes applied before parsing
+	blockFixes      []func(*markdown.Document) bool // fixes applied to the parsed document
 	projects        map[string]bool
 	edit            bool
 	requireApproval bool
+	issuesOnly      bool
 	timeLimit       time.Time
 	db              storage.DB
 	logAction       actions.BeforeFunc
@@ -144,6 +148,13 @@ func (f *Fixer) RequireApproval() {
 	f.requireApproval = true
 }
 
+// IssuesOnly configures the fixer to rewrite only issue texts,
+// leaving issue comments alone.
+func (f *Fixer) IssuesOnly() {
+	f.init()
+	f.issuesOnly = true
+}
+
 // AutoLink instructs the fixer to turn any text matching the
 // regular expression pattern into a link to the URL.
 // The URL can contain substitution values like $1
@@ -431,6 +442,9 @@ func (f *Fixer) newAction(e *github.Event) *action {
 		ic = &issueOrComment{Issue: x}
 		f.slog.Info("fixer run issue", "dbtime", e.DBTime, "issue", ic.Issue.Number)
 	case *github.IssueComment:
+		if f.issuesOnly {
+			return nil
+		}
 		ic = &issueOrComment{Comment: x}
 		f.slog.Info("fixer run comment", "dbtime", e.DBTime, "url", ic.Comment.URL)
 	}
@@ -560,7 +574,18 @@ func (ic *issueOrComment) editBody(ctx context.Context, gh *github.Client, body
 // If no fixes apply, it returns "", false.
 // If any fixes apply, it returns the updated text and true.
 func (f *Fixer) Fix(text string) (newText string, fixed bool) {
+	for _, fix := range f.textFixes {
+		if t, ok := fix(text); ok {
+			text = t
+			fixed = true
+		}
+	}
 	doc := github.ParseMarkdown(text)
+	for _, fix := range f.blockFixes {
+		if fix(doc) {
+			fixed = true
+		}
+	}
 	for _, fixer := range f.fixes {
 		if f.fixOne(fixer, doc) {
 			fixed = true
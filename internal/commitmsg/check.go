@@ -0,0 +1,241 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commitmsg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Problem is one way in which a commit message departs from
+// the Go commit message conventions.
+type Problem struct {
+	Rule    string // short name of the rule, such as "prefix"
+	Message string // explanation for the change author, in Markdown
+}
+
+// A Result is the result of [Check].
+type Result struct {
+	Problems   []*Problem
+	Suggestion string // corrected message; "" if there are no problems
+}
+
+// maxSubjectLen is the longest subject line that does not draw
+// a complaint. Go wraps commit message bodies at about 76 columns;
+// the subject should fit in the same width.
+const maxSubjectLen = 76
+
+// Check checks the commit message msg, whose first line is the subject,
+// against the Go commit message conventions described at
+// https://go.dev/doc/contribute#commit_messages. It returns the
+// problems found, in a fixed order, along with a corrected message.
+//
+// The message is for a change to the GitHub repository repo
+// (for example "golang/tools"), whose issues are tracked in the
+// repository issueRepo (usually "golang/go"). If the two differ,
+// references to issues must name issueRepo, as in "Fixes golang/go#123".
+//
+// Check cannot know which package a change affects, so when the
+// subject is missing a package prefix, the suggestion uses
+// the placeholder "pkg".
+func Check(repo, issueRepo, msg string) *Result {
+	r := new(Result)
+	problem := func(rule, format string, args ...any) {
+		r.Problems = append(r.Problems, &Problem{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	msg = strings.TrimSpace(strings.ReplaceAll(msg, "\r\n", "\n"))
+	subject, body, _ := strings.Cut(msg, "\n")
+	subject = strings.TrimSpace(subject)
+	body = strings.Trim(body, "\n")
+
+	// Issue references belong in the body.
+	var refs []string
+	for _, m := range subjectRefRE.FindAllStringSubmatch(subject, -1) {
+		ref := canonicalRef(m[1], m[2], m[3], repo, issueRepo)
+		problem("subject-ref", "Issue references such as %q belong at the end of the body, not in the subject.", ref)
+		refs = append(refs, ref)
+	}
+	subject = strings.TrimSpace(subjectRefRE.ReplaceAllString(subject, ""))
+
+	prefix, rest := "pkg", subject
+	if m := prefixRE.FindStringSubmatch(subject); m != nil {
+		prefix, rest = m[1], m[2]
+	} else {
+		problem("prefix", "The subject should start with the primary affected package and a colon, as in %q.", "net/http: add Server.Shutdown")
+	}
+
+	if strings.HasSuffix(rest, ".") && !strings.HasSuffix(rest, "...") {
+		problem("period", "The subject should not end with a period.")
+		rest = strings.TrimRight(rest, ".")
+	}
+
+	word, tail, _ := strings.Cut(rest, " ")
+	if base, ok := imperatives[strings.ToLower(word)]; ok {
+		problem("imperative", "The subject should complete the sentence %q, so it should start with %q, not %q.",
+			"This change modifies Go to ___", base, word)
+		word = base
+	} else if capitalized(word) {
+		lower := strings.ToLower(word[:1]) + word[1:]
+		problem("lowercase", "The word after the colon should be lowercase: %q, not %q.", lower, word)
+		word = lower
+	}
+	if tail != "" {
+		rest = word + " " + tail
+	} else {
+		rest = word
+	}
+	subject = prefix + ": " + rest
+	if n := utf8.RuneCountInString(subject); n > maxSubjectLen {
+		problem("length", "The subject is %d characters long; keep it under %d.", n, maxSubjectLen)
+	}
+
+	// Split the body into text and trailing metadata lines,
+	// like "Change-Id: I1234...".
+	lines := strings.Split(body, "\n")
+	if body == "" {
+		lines = nil
+	}
+	end := len(lines)
+	for end > 0 && trailerRE.MatchString(lines[end-1]) {
+		end--
+	}
+	text, trailers := lines[:end], lines[end:]
+
+	explained := false
+	for i, line := range text {
+		m := bodyRefRE.FindStringSubmatch(line)
+		if m == nil {
+			if strings.TrimSpace(line) != "" {
+				explained = true
+			}
+			continue
+		}
+		if ref := canonicalRef(m[1], m[2]+m[3], m[4], repo, issueRepo); ref != line {
+			problem("issue-ref", "Write issue references as %q, not %q.", ref, strings.TrimSpace(line))
+			text[i] = ref
+		}
+	}
+	if !explained {
+		problem("body", "The body should explain the change: why it is needed and how it works.")
+	}
+
+	if len(r.Problems) == 0 {
+		return r
+	}
+
+	// Assemble the suggestion: the subject, the explanation,
+	// the issue references moved from the subject, and the trailers.
+	var paras []string
+	paras = append(paras, subject)
+	if t := strings.Trim(strings.Join(text, "\n"), "\n"); t != "" {
+		paras = append(paras, t)
+	}
+	if len(refs) > 0 {
+		paras = append(paras, strings.Join(refs, "\n"))
+	}
+	if len(trailers) > 0 {
+		paras = append(paras, strings.Join(trailers, "\n"))
+	}
+	r.Suggestion = strings.Join(paras, "\n\n")
+	return r
+}
+
+var (
+	// prefixRE matches a subject with a package prefix, such as
+	// "net/http: ...", "cmd/{go,vet}: ...", "go/types, types2: ..."
+	// or "[release-branch.go1.22] cmd/go: ...".
+	prefixRE = regexp.MustCompile(`^((?:\[[^\]]+\] )?[\w.+\-/{}*]+(?:, ?[\w.+\-/{}*]+)*): +(.*)$`)
+
+	// subjectRefRE matches an issue reference in a subject,
+	// such as "(fixes #123)".
+	subjectRefRE = regexp.MustCompile(`(?i)\s*[(\[]?\b(fix|fixes|fixed|close|closes|closed|resolve|resolves|resolved|update|updates|updated)\b:?\s+(?:issue\s+)?(?:([\w.-]+/[\w.-]+)?#(\d+))[)\]]?`)
+
+	// bodyRefRE matches a line of the body consisting of an issue
+	// reference, correct or not: "Fixes #123", "fixes: golang/go#123",
+	// "Closes https://github.com/golang/go/issues/123." and so on.
+	bodyRefRE = regexp.MustCompile(`(?i)^\s*(fix|fixes|fixed|close|closes|closed|resolve|resolves|resolved|update|updates|updated|for)\b:?\s+(?:issue\s+)?(?:https?://github\.com/([\w.-]+/[\w.-]+)/issues/|([\w.-]+/[\w.-]+)?#)(\d+)[.,;]?\s*$`)
+
+	// trailerRE matches a metadata line at the end of a message,
+	// such as "Change-Id: I1234..." or "Reviewed-on: https://...".
+	trailerRE = regexp.MustCompile(`^[A-Z][A-Za-z0-9-]*: \S`)
+)
+
+// canonicalRef returns the conventional form of an issue reference
+// with the given verb, repository (possibly empty) and issue number,
+// in a change to repo whose issues are tracked in issueRepo.
+func canonicalRef(verb, refRepo, num, repo, issueRepo string) string {
+	switch v := strings.ToLower(verb); {
+	case strings.HasPrefix(v, "update"):
+		verb = "Updates"
+	case v == "for":
+		verb = "For"
+	default:
+		verb = "Fixes"
+	}
+	if refRepo == "" && repo != issueRepo {
+		refRepo = issueRepo
+	}
+	return verb + " " + refRepo + "#" + num
+}
+
+// capitalized reports whether word is a capitalized ordinary word,
+// like "Add", as opposed to an identifier or initialism,
+// like "HTTP" or "ServeMux", or the proper noun "Go".
+func capitalized(word string) bool {
+	if word == "Go" {
+		return false
+	}
+	for i, c := range word {
+		if i == 0 && !unicode.IsUpper(c) || i > 0 && !unicode.IsLower(c) {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// imperatives maps common non-imperative verb forms,
+// such as "adds", "added" and "adding", to the imperative "add".
+var imperatives = make(map[string]string)
+
+// verbs are the verbs recognized in the subject by [Check].
+const verbs = `
+	add allow avoid bump change check clarify clean convert copy correct
+	delete deprecate disable document drop enable ensure export expose fix
+	handle ignore implement improve introduce make move optimize pass
+	prevent print reduce reject remove rename reorganize replace report
+	restore return reuse revert rewrite run simplify skip sort split stop
+	support unexport update use validate wrap
+`
+
+func init() {
+	for _, v := range strings.Fields(verbs) {
+		forms := []string{v + "s", v + "ing"}
+		switch {
+		case strings.HasSuffix(v, "e"):
+			forms = append(forms, v+"d", v[:len(v)-1]+"ing")
+		case strings.HasSuffix(v, "y") && !strings.ContainsRune("aeiou", rune(v[len(v)-2])):
+			stem := v[:len(v)-1]
+			forms = append(forms, stem+"ies", stem+"ied")
+		default:
+			forms = append(forms, v+"ed", v+"es")
+		}
+		for _, f := range forms {
+			imperatives[f] = v
+		}
+	}
+	// Irregular forms and doubled final consonants.
+	for _, fv := range strings.Fields(`
+		dropped:drop dropping:drop made:make ran:run running:run
+		rewrote:rewrite rewritten:rewrite skipped:skip skipping:skip
+		splitting:split stopped:stop stopping:stop wrapped:wrap wrapping:wrap
+	`) {
+		f, v, _ := strings.Cut(fv, ":")
+		imperatives[f] = v
+	}
+}
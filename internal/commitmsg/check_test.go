@@ -0,0 +1,96 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commitmsg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		repo       string
+		msg        string
+		rules      []string
+		suggestion string
+	}{
+		{
+			name: "ok",
+			msg:  "net/http: add Server.Shutdown\n\nShutdown stops the server gracefully.\n\nFixes #123",
+		},
+		{
+			name: "identifier",
+			msg:  "go/types, types2: HTTP handlers are fine\n\nSee the discussion.",
+		},
+		{
+			name:       "prefix",
+			msg:        "add Server.Shutdown\n\nShutdown stops the server.",
+			rules:      []string{"prefix"},
+			suggestion: "pkg: add Server.Shutdown\n\nShutdown stops the server.",
+		},
+		{
+			name:       "style",
+			msg:        "net/http: Added Server.Shutdown.\n\nShutdown stops the server.",
+			rules:      []string{"period", "imperative"},
+			suggestion: "net/http: add Server.Shutdown\n\nShutdown stops the server.",
+		},
+		{
+			name:       "lowercase",
+			msg:        "cmd/go: Teach go vet a trick\n\nIt is a good trick.",
+			rules:      []string{"lowercase"},
+			suggestion: "cmd/go: teach go vet a trick\n\nIt is a good trick.",
+		},
+		{
+			name:       "subject-ref",
+			msg:        "net/http: fix crash (fixes #123)\n\nDon't crash.\n\nChange-Id: I1234",
+			rules:      []string{"subject-ref"},
+			suggestion: "net/http: fix crash\n\nDon't crash.\n\nFixes #123\n\nChange-Id: I1234",
+		},
+		{
+			name:       "issue-ref",
+			msg:        "net/http: fix crash\n\nDon't crash.\n\nfixes: https://github.com/golang/go/issues/123.\nUpdates #45",
+			rules:      []string{"issue-ref"},
+			suggestion: "net/http: fix crash\n\nDon't crash.\n\nFixes golang/go#123\nUpdates #45",
+		},
+		{
+			name:       "subrepo",
+			repo:       "golang/tools",
+			msg:        "gopls: fix crash\n\nDon't crash.\n\nFixes #123",
+			rules:      []string{"issue-ref"},
+			suggestion: "gopls: fix crash\n\nDon't crash.\n\nFixes golang/go#123",
+		},
+		{
+			name:       "body",
+			msg:        "net/http: fix crash\n\nFixes #123",
+			rules:      []string{"body"},
+			suggestion: "net/http: fix crash\n\nFixes #123",
+		},
+		{
+			name:       "length",
+			msg:        "net/http: fix the crash that happens when the server shuts down during a request\n\nDon't crash.",
+			rules:      []string{"length"},
+			suggestion: "net/http: fix the crash that happens when the server shuts down during a request\n\nDon't crash.",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := tt.repo
+			if repo == "" {
+				repo = "golang/go"
+			}
+			r := Check(repo, "golang/go", tt.msg)
+			var rules []string
+			for _, p := range r.Problems {
+				rules = append(rules, p.Rule)
+			}
+			if !slices.Equal(rules, tt.rules) {
+				t.Errorf("rules = %q, want %q", rules, tt.rules)
+			}
+			if r.Suggestion != tt.suggestion {
+				t.Errorf("suggestion:\n%s\nwant:\n%s", r.Suggestion, tt.suggestion)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package commitmsg checks the titles and descriptions of Gerrit changes
+// and GitHub pull requests against the Go commit message conventions
+// (https://go.dev/doc/contribute#commit_messages): a package prefix,
+// a lowercase imperative summary and well-formed issue references
+// such as "Fixes #123". When a message departs from the conventions,
+// a [Checker] replies with the problems and a suggested correction.
+//
+// [Check] checks a single message. A Checker watches for new changes
+// and pull requests, and adds an action to the action log to reply to
+// each one whose message has problems; by default, the actions
+// require approval.
+//
+// Database entries are as follows:
+//
+//   - Action log entries of kind "commitmsg.Checker", keyed by
+//     ($name, "github", $project, $number) or ($name, "gerrit", $project, $number).
+package commitmsg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/gerrit"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// A Checker checks the commit messages of new Gerrit changes and
+// GitHub pull requests and suggests corrections.
+type Checker struct {
+	slog      *slog.Logger
+	db        storage.DB
+	github    *github.Client
+	gerrit    *gerrit.Client
+	name      string
+	timeLimit time.Time
+
+	githubProjects map[string]bool   // GitHub projects whose pull requests are checked
+	gerritProjects map[string]string // Gerrit projects whose changes are checked, to GitHub repo
+	issueRepos     map[string]string // GitHub repo to repo tracking its issues
+
+	githubWatcher *timed.Watcher[*github.Event]
+	gerritWatcher *timed.Watcher[*gerrit.ChangeEvent]
+
+	requireApproval bool
+	logAction       actions.BeforeFunc
+}
+
+const actionKind = "commitmsg.Checker"
+
+// defaultTooOld is how old a change can be and still be checked.
+// Older changes have presumably already been reviewed by people.
+const defaultTooOld = 48 * time.Hour
+
+// New returns a new Checker that reads pull requests with gh and
+// Gerrit changes with gr, and stores state in db.
+// Either client may be nil, to check only the other.
+// The name distinguishes the watchers and actions of different Checkers.
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, gr *gerrit.Client, name string) *Checker {
+	c := &Checker{
+		slog:            lg,
+		db:              db,
+		github:          gh,
+		gerrit:          gr,
+		name:            name,
+		timeLimit:       time.Now().Add(-defaultTooOld),
+		githubProjects:  make(map[string]bool),
+		gerritProjects:  make(map[string]string),
+		issueRepos:      make(map[string]string),
+		requireApproval: true,
+	}
+	if gh != nil {
+		c.githubWatcher = gh.EventWatcher(actionKind + ":" + name)
+	}
+	if gr != nil {
+		c.gerritWatcher = gr.ChangeWatcher(actionKind + ":" + name)
+	}
+	c.logAction = actions.Register(actionKind, &actioner{c})
+	return c
+}
+
+// EnableProject enables the Checker to check pull requests
+// in the given GitHub project (for example "golang/oscar").
+func (c *Checker) EnableProject(project string) {
+	c.githubProjects[project] = true
+}
+
+// EnableGerritProject enables the Checker to check changes in the
+// given Gerrit project (for example "tools"), which is mirrored
+// to the GitHub repository repo (for example "golang/tools").
+func (c *Checker) EnableGerritProject(project, repo string) {
+	c.gerritProjects[project] = repo
+}
+
+// SetIssueRepo records that the issues for the GitHub repository repo
+// are tracked in issueRepo, so that the messages of changes to repo
+// must refer to issues as "Fixes issueRepo#123".
+// By default, each repository tracks its own issues.
+func (c *Checker) SetIssueRepo(repo, issueRepo string) {
+	c.issueRepos[repo] = issueRepo
+}
+
+// AutoApprove configures the Checker to auto-approve its actions.
+// By default, replies require approval.
+func (c *Checker) AutoApprove() {
+	c.requireApproval = false
+}
+
+// Check checks the commit message msg of a change to
+// the GitHub repository repo, as configured by [Checker.SetIssueRepo].
+func (c *Checker) Check(repo, msg string) *Result {
+	issueRepo := c.issueRepos[repo]
+	if issueRepo == "" {
+		issueRepo = repo
+	}
+	return Check(repo, issueRepo, msg)
+}
+
+// Run checks the messages of all new pull requests and changes
+// in the enabled projects, adding actions to the action log to
+// reply to those with problems.
+func (c *Checker) Run(ctx context.Context) error {
+	c.slog.Info("commitmsg.Checker start", "name", c.name)
+	defer c.slog.Info("commitmsg.Checker end", "name", c.name)
+
+	if w := c.githubWatcher; w != nil {
+		defer w.Flush()
+		for e := range w.Recent() {
+			c.checkPullRequest(e)
+			w.MarkOld(e.DBTime)
+		}
+	}
+	if w := c.gerritWatcher; w != nil {
+		defer w.Flush()
+		for e := range w.Recent() {
+			c.checkChange(e)
+			w.MarkOld(e.DBTime)
+		}
+	}
+	return nil
+}
+
+// checkPullRequest checks the pull request of the event e,
+// if it is a new, open pull request in an enabled project.
+func (c *Checker) checkPullRequest(e *github.Event) {
+	if !c.githubProjects[e.Project] || e.API != "/issues" {
+		return
+	}
+	pr, ok := e.Typed.(*github.Issue)
+	if !ok || pr.PullRequest == nil || pr.State == "closed" {
+		return
+	}
+	if tm, err := time.Parse(time.RFC3339, pr.CreatedAt); err != nil || tm.Before(c.timeLimit) {
+		return
+	}
+	r := c.Check(e.Project, pr.Title+"\n\n"+pr.Body)
+	if len(r.Problems) == 0 {
+		return
+	}
+	c.log(ordered.Encode(c.name, "github", e.Project, e.Issue), &action{
+		Issue: pr,
+		Body:  comment(r),
+	})
+}
+
+// checkChange checks the Gerrit change of the event e,
+// if it is a new, open change in an enabled project.
+func (c *Checker) checkChange(e *gerrit.ChangeEvent) {
+	for project, repo := range c.gerritProjects {
+		ch := c.gerrit.Change(project, e.ChangeNum)
+		if ch == nil || c.gerrit.ChangeProject(ch) != project {
+			continue
+		}
+		if c.gerrit.ChangeStatus(ch) != "NEW" || c.gerrit.ChangeTimes(ch).Created.Before(c.timeLimit) {
+			return
+		}
+		r := c.Check(repo, c.gerrit.ChangeDescription(ch))
+		if len(r.Problems) == 0 {
+			return
+		}
+		c.log(ordered.Encode(c.name, "gerrit", project, e.ChangeNum), &action{
+			Project:   project,
+			ChangeNum: e.ChangeNum,
+			Body:      comment(r),
+		})
+		return
+	}
+}
+
+// log adds the action a to the action log under key,
+// unless an action was already logged for the change.
+func (c *Checker) log(key []byte, a *action) {
+	if _, ok := actions.Get(c.db, actionKind, key); ok {
+		return
+	}
+	c.logAction(c.db, key, storage.JSON(a), c.requireApproval)
+}
+
+// comment returns the reply describing the problems in r.
+func comment(r *Result) string {
+	var b strings.Builder
+	b.WriteString("This commit message does not follow the [Go commit message conventions](https://go.dev/doc/contribute#commit_messages):\n\n")
+	for _, p := range r.Problems {
+		fmt.Fprintf(&b, "- %s\n", p.Message)
+	}
+	fmt.Fprintf(&b, "\nA corrected message might read:\n\n```\n%s\n```\n", r.Suggestion)
+	return b.String()
+}
+
+// An action is a reply to post on a pull request or a Gerrit change.
+type action struct {
+	Issue     *github.Issue `json:",omitempty"` // pull request, if GitHub
+	Project   string        `json:",omitempty"` // Gerrit project, if Gerrit
+	ChangeNum int           `json:",omitempty"` // Gerrit change number, if Gerrit
+	Body      string
+}
+
+type actioner struct {
+	c *Checker
+}
+
+func (ar *actioner) Run(ctx context.Context, data []byte) ([]byte, error) {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	c := ar.c
+	if a.Issue != nil {
+		_, url, err := c.github.PostIssueComment(ctx, a.Issue, &github.IssueCommentChanges{Body: a.Body})
+		if err != nil {
+			return nil, fmt.Errorf("commitmsg: posting on %s: %w", a.Issue.HTMLURL, err)
+		}
+		return storage.JSON(url), nil
+	}
+	ch := c.gerrit.Change(a.Project, a.ChangeNum)
+	if ch == nil {
+		return nil, fmt.Errorf("commitmsg: unknown Gerrit change %s/%d", a.Project, a.ChangeNum)
+	}
+	if err := c.gerrit.PostReview(ctx, ch, a.Body); err != nil {
+		return nil, fmt.Errorf("commitmsg: posting on Gerrit change %s/%d: %w", a.Project, a.ChangeNum, err)
+	}
+	return nil, nil
+}
+
+func (ar *actioner) ForDisplay(data []byte) string {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	if a.Issue != nil {
+		return a.Issue.HTMLURL + "\n" + a.Body
+	}
+	return fmt.Sprintf("Gerrit change %s/%d\n%s", a.Project, a.ChangeNum, a.Body)
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commitmsg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/gerrit"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestChecker(t *testing.T) {
+	ctx := context.Background()
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+
+	gh := github.New(lg, db, nil, nil)
+	now := time.Now().UTC().Format(time.RFC3339)
+	gh.Testing().AddIssue("golang/oscar", &github.Issue{
+		Number:      1,
+		Title:       "Fixed the crash",
+		Body:        "It crashed.\n\nfixes #2",
+		CreatedAt:   now,
+		PullRequest: new(struct{}),
+	})
+	gh.Testing().AddIssue("golang/oscar", &github.Issue{
+		Number:      3,
+		Title:       "internal/gaby: fix the crash",
+		Body:        "It crashed.\n\nFixes #2",
+		CreatedAt:   now,
+		PullRequest: new(struct{}),
+	})
+	gh.Testing().AddIssue("golang/oscar", &github.Issue{
+		Number:    4,
+		Title:     "Not a pull request",
+		CreatedAt: now,
+	})
+
+	gr := gerrit.New("go-review.googlesource.com", lg, db, nil, nil)
+	check(gr.Testing().LoadTxtar("testdata/changes.txt"))
+	check(gr.Add("tools"))
+	check(gr.Sync(ctx))
+
+	c := New(lg, db, gh, gr, "test")
+	c.timeLimit = time.Time{}
+	c.EnableProject("golang/oscar")
+	c.EnableGerritProject("tools", "golang/tools")
+	c.SetIssueRepo("golang/tools", "golang/go")
+	c.AutoApprove()
+	check(c.Run(ctx))
+	check(actions.Run(ctx, lg, db))
+
+	edits := gh.Testing().Edits()
+	if len(edits) != 1 {
+		t.Fatalf("got %d GitHub edits, want 1: %v", len(edits), edits)
+	}
+	if e := edits[0]; e.Issue != 1 || !strings.Contains(e.IssueCommentChanges.Body, "pkg: fix the crash\n\nIt crashed.\n\nFixes #2\n") {
+		t.Errorf("GitHub edit:\n%v", e)
+	}
+
+	reviews := gr.Testing().Reviews()
+	if len(reviews) != 1 {
+		t.Fatalf("got %d Gerrit reviews, want 1: %v", len(reviews), reviews)
+	}
+	if r := reviews[0]; r.ChangeNum != 1 || !strings.Contains(r.Message, "```\npkg: fix the crash\n```") {
+		t.Errorf("Gerrit review:\n%+v", r)
+	}
+
+	// Running again posts nothing new.
+	gh.Testing().ClearEdits()
+	check(c.Run(ctx))
+	check(actions.Run(ctx, lg, db))
+	if edits := gh.Testing().Edits(); len(edits) != 0 {
+		t.Errorf("second run: %v", edits)
+	}
+	if n := len(gr.Testing().Reviews()); n != 1 {
+		t.Errorf("second run: %d Gerrit reviews, want 1", n)
+	}
+}
+
+// A refuseTransport is an [http.RoundTripper] that
+// fails the test for any request.
+type refuseTransport struct {
+	t *testing.T
+}
+
+func (rt refuseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+	return nil, errors.New("refused")
+}
+
+func TestCheckerDryRun(t *testing.T) {
+	ctx := context.Background()
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+
+	// Sync the test changes into db with a testing client.
+	tgr := gerrit.New("go-review.googlesource.com", lg, db, nil, nil)
+	check(tgr.Testing().LoadTxtar("testdata/changes.txt"))
+	check(tgr.Add("tools"))
+	check(tgr.Sync(ctx))
+
+	// A client that is not testing would post to Gerrit,
+	// but not in dry-run mode.
+	sdb := secret.Map{"go-review.googlesource.com": "user:pass"}
+	gr := gerrit.New("go-review.googlesource.com", lg, db, sdb, &http.Client{Transport: refuseTransport{t}})
+	gr.EnableDryRun()
+	gh := github.New(lg, db, nil, nil)
+
+	c := New(lg, db, gh, gr, "test")
+	c.timeLimit = time.Time{}
+	c.EnableGerritProject("tools", "golang/tools")
+	c.AutoApprove()
+	check(c.Run(ctx))
+	check(actions.Run(ctx, lg, db))
+
+	reviews := slices.Collect(gr.DivertedReviews(0))
+	if len(reviews) != 1 {
+		t.Fatalf("got %d diverted reviews, want 1: %v", len(reviews), reviews)
+	}
+	if r := reviews[0].Review; r.Project != "tools" || r.ChangeNum != 1 || !strings.Contains(r.Message, "pkg: fix the crash") {
+		t.Errorf("diverted review: %+v", r)
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package compose combines the comments that several bots would
+// post on the same GitHub issue into a single comment.
+//
+// Bots configured with a [Composer] (for example, with
+// related.Poster.SetComposer) still log their own actions, which are
+// approved as usual, but when those actions run they add their
+// comments to the Composer as parts instead of posting them.
+// The first part added for an issue opens a window (see
+// [Composer.SetWindow]); once it has closed, [Composer.Run] logs a
+// single action that posts all the parts added for the issue in the
+// meantime as one comment. Parts added later open a new window.
+package compose
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// A Composer combines comments from several sources on the same
+// GitHub issue into one comment.
+type Composer struct {
+	slog   *slog.Logger
+	db     storage.DB
+	github *github.Client
+	name   string
+	window time.Duration
+	order  map[string]int // source → position in combined comments; see SetOrder
+	// For the action log.
+	actionKind string
+	logAction  actions.BeforeFunc
+}
+
+// New creates and returns a new Composer. It logs to lg, stores state
+// in db and posts combined comments to GitHub using gh.
+// For the purposes of storing its own state, it uses the given name.
+// Future calls to New with the same name will use the same state.
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, name string) *Composer {
+	c := &Composer{
+		slog:   lg,
+		db:     db,
+		github: gh,
+		name:   name,
+		window: defaultWindow,
+		order:  make(map[string]int),
+	}
+	c.actionKind = "compose.Composer"
+	c.logAction = actions.Register(c.actionKind, &actioner{c})
+	return c
+}
+
+// SetWindow sets how long the Composer waits after the first part is
+// added for an issue for other parts to add to the same comment.
+// The default is 10 minutes, which leaves time for the bots that
+// react to a new issue to run.
+func (c *Composer) SetWindow(d time.Duration) {
+	c.window = d
+}
+
+const defaultWindow = 10 * time.Minute
+
+// SetOrder sets the order of the parts in combined comments by their
+// sources: parts from the first source come first, and so on.
+// Parts from sources not listed follow, in order of their source names.
+func (c *Composer) SetOrder(sources ...string) {
+	clear(c.order)
+	for i, s := range sources {
+		c.order[s] = i + 1
+	}
+}
+
+// A Part is a part of a combined comment.
+type Part struct {
+	Source string // the bot that added the part, such as "related"
+	Body   string // Markdown or plain text
+	Note   bool   // the part is only posted with other, non-note parts
+}
+
+// pending is the state of a combined comment that has not been posted.
+type pending struct {
+	Issue *github.Issue
+	Start time.Time // when the first part was added
+	Parts []*Part
+}
+
+// Add adds a part from the given source to the next comment
+// on the issue, opening a window if there is none.
+// A source adds at most one part to each comment;
+// if it adds another, the new part replaces the old one.
+// Add is meant to be called while running an approved action,
+// in place of posting the body as a comment.
+func (c *Composer) Add(issue *github.Issue, source, body string) {
+	c.add(issue, &Part{Source: source, Body: body})
+}
+
+// AddNote is like [Composer.Add], but the part is a note that is
+// only posted if some other source adds a part that is not a note
+// to the same comment. For example, a bot that labels issues can
+// add a note saying so without posting a comment of its own.
+func (c *Composer) AddNote(issue *github.Issue, source, body string) {
+	c.add(issue, &Part{Source: source, Body: body, Note: true})
+}
+
+func (c *Composer) add(issue *github.Issue, part *Part) {
+	key := c.pendingKey(issue.Project(), issue.Number)
+	c.db.Lock(string(key))
+	defer c.db.Unlock(string(key))
+
+	p := &pending{Issue: issue, Start: time.Now()}
+	if val, ok := c.db.Get(key); ok {
+		if err := json.Unmarshal(val, p); err != nil {
+			// Unreachable unless the database is corrupt.
+			c.db.Panic("compose.Composer decode", "key", storage.Fmt(key), "err", err)
+		}
+	}
+	p.Parts = slices.DeleteFunc(p.Parts, func(q *Part) bool { return q.Source == part.Source })
+	p.Parts = append(p.Parts, part)
+	c.db.Set(key, storage.JSON(p))
+	c.slog.Info("compose.Composer add", "name", c.name, "project", issue.Project(),
+		"issue", issue.Number, "source", part.Source, "note", part.Note)
+}
+
+func (c *Composer) pendingKey(project string, issue int64) []byte {
+	return ordered.Encode("compose.Pending", c.name, project, issue)
+}
+
+// Pending returns the parts added for the next comment on the issue,
+// in the order they would be posted.
+func (c *Composer) Pending(project string, issue int64) []*Part {
+	val, ok := c.db.Get(c.pendingKey(project, issue))
+	if !ok {
+		return nil
+	}
+	var p pending
+	if err := json.Unmarshal(val, &p); err != nil {
+		c.db.Panic("compose.Composer decode", "project", project, "issue", issue, "err", err)
+	}
+	c.sort(p.Parts)
+	return p.Parts
+}
+
+// An action has all the information needed to post a combined comment.
+type action struct {
+	Issue   *github.Issue
+	Sources []string // sources of the parts, in order
+	Changes *github.IssueCommentChanges
+}
+
+// result is the result of applying an action.
+type result struct {
+	URL string // URL of new comment
+}
+
+// Run logs an action to post a combined comment on each issue whose
+// window has closed. Combined comments do not require approval,
+// because each of their parts was added by an approved action.
+// Issues whose parts are all notes get no comment.
+func (c *Composer) Run(ctx context.Context) error {
+	start := ordered.Encode("compose.Pending", c.name)
+	end := ordered.Encode("compose.Pending", c.name, ordered.Inf)
+	var keys [][]byte
+	for key := range c.db.Scan(start, end) {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		c.flush(key)
+	}
+	return nil
+}
+
+// flush logs the combined comment stored at key, if its window has closed.
+func (c *Composer) flush(key []byte) {
+	c.db.Lock(string(key))
+	defer c.db.Unlock(string(key))
+
+	val, ok := c.db.Get(key)
+	if !ok {
+		return
+	}
+	var p pending
+	if err := json.Unmarshal(val, &p); err != nil {
+		c.db.Panic("compose.Composer decode", "key", storage.Fmt(key), "err", err)
+	}
+	if time.Since(p.Start) < c.window {
+		return
+	}
+	c.db.Delete(key)
+
+	c.sort(p.Parts)
+	if !slices.ContainsFunc(p.Parts, func(q *Part) bool { return !q.Note }) {
+		c.slog.Info("compose.Composer skip: only notes", "name", c.name,
+			"project", p.Issue.Project(), "issue", p.Issue.Number)
+		return
+	}
+	act := &action{Issue: p.Issue, Changes: &github.IssueCommentChanges{Body: combine(p.Parts)}}
+	for _, q := range p.Parts {
+		act.Sources = append(act.Sources, q.Source)
+	}
+	c.slog.Info("compose.Composer post", "name", c.name, "project", p.Issue.Project(),
+		"issue", p.Issue.Number, "sources", act.Sources)
+	c.logAction(c.db, ordered.Encode(c.name, p.Issue.Project(), p.Issue.Number, p.Start.UnixNano()), storage.JSON(act), false)
+}
+
+// sort sorts the parts by the order of their sources (see [Composer.SetOrder]).
+func (c *Composer) sort(parts []*Part) {
+	rank := func(s string) int {
+		if n, ok := c.order[s]; ok {
+			return n
+		}
+		return len(c.order) + 1
+	}
+	slices.SortStableFunc(parts, func(a, b *Part) int {
+		return cmp.Or(cmp.Compare(rank(a.Source), rank(b.Source)), strings.Compare(a.Source, b.Source))
+	})
+}
+
+// combine returns the text of a comment made of the parts,
+// separated by horizontal rules.
+func combine(parts []*Part) string {
+	var bodies []string
+	for _, q := range parts {
+		bodies = append(bodies, strings.TrimSpace(q.Body))
+	}
+	return strings.Join(bodies, "\n\n---\n\n") + "\n"
+}
+
+type actioner struct {
+	c *Composer
+}
+
+func (ar *actioner) Run(ctx context.Context, data []byte) ([]byte, error) {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	_, url, err := ar.c.github.PostIssueComment(ctx, a.Issue, a.Changes)
+	if err != nil {
+		return nil, fmt.Errorf("compose.Composer: post comment on %s: %w", a.Issue.HTMLURL, err)
+	}
+	return storage.JSON(&result{URL: url}), nil
+}
+
+func (ar *actioner) ForDisplay(data []byte) string {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	return a.Issue.HTMLURL + " (" + strings.Join(a.Sources, ", ") + ")\n" + a.Changes.Body
+}
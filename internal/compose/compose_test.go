@@ -0,0 +1,116 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package compose
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+var ctx = context.Background()
+
+const project = "golang/go"
+
+func TestComposer(t *testing.T) {
+	lg := testutil.Slogger(t)
+	check := testutil.Checker(t)
+
+	newComposer := func() (*Composer, *github.Client) {
+		db := storage.MemDB()
+		gh := github.New(lg, db, nil, nil)
+		c := New(lg, db, gh, t.Name())
+		c.SetOrder("rules", "related", "labels")
+		return c, gh
+	}
+	issue := func(gh *github.Client, n int64) *github.Issue {
+		iss := &github.Issue{Number: n, Title: "title", Body: "body"}
+		gh.Testing().AddIssue(project, iss)
+		return iss
+	}
+	run := func(c *Composer) {
+		t.Helper()
+		check(c.Run(ctx))
+		check(actions.Run(ctx, lg, c.db))
+	}
+	bodies := func(gh *github.Client) map[int64]string {
+		m := make(map[int64]string)
+		for _, e := range gh.Testing().Edits() {
+			m[e.Issue] = e.IssueCommentChanges.Body
+		}
+		return m
+	}
+
+	t.Run("combine", func(t *testing.T) {
+		c, gh := newComposer()
+		c.SetWindow(0)
+		iss := issue(gh, 1)
+		c.AddNote(iss, "labels", "Labels added: NeedsInvestigation.")
+		c.Add(iss, "related", "**Related Issues**\n\n - old\n")
+		c.Add(iss, "rules", "Please fill in the template.\n")
+		c.Add(iss, "related", "**Related Issues**\n\n - new\n") // replaces earlier part
+
+		var sources []string
+		for _, p := range c.Pending(project, 1) {
+			sources = append(sources, p.Source)
+		}
+		if want := []string{"rules", "related", "labels"}; !cmp.Equal(sources, want) {
+			t.Errorf("Pending sources = %q, want %q", sources, want)
+		}
+
+		run(c)
+		want := map[int64]string{1: "Please fill in the template.\n\n---\n\n**Related Issues**\n\n - new\n\n---\n\nLabels added: NeedsInvestigation.\n"}
+		if diff := cmp.Diff(want, bodies(gh)); diff != "" {
+			t.Errorf("comments mismatch (-want +got):\n%s", diff)
+		}
+		if p := c.Pending(project, 1); p != nil {
+			t.Errorf("Pending after Run = %v, want nil", p)
+		}
+
+		// A late part opens a new window.
+		gh.Testing().ClearEdits()
+		c.Add(iss, "duplicate", "Possible duplicate.")
+		run(c)
+		if diff := cmp.Diff(map[int64]string{1: "Possible duplicate.\n"}, bodies(gh)); diff != "" {
+			t.Errorf("late comment mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		c, gh := newComposer()
+		iss := issue(gh, 2)
+		c.Add(iss, "related", "related")
+		run(c) // window still open
+		if len(gh.Testing().Edits()) != 0 || len(c.Pending(project, 2)) != 1 {
+			t.Fatalf("Run posted before window closed")
+		}
+		c.SetWindow(time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		run(c)
+		if diff := cmp.Diff(map[int64]string{2: "related\n"}, bodies(gh)); diff != "" {
+			t.Errorf("comments mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("notes", func(t *testing.T) {
+		c, gh := newComposer()
+		c.SetWindow(0)
+		iss := issue(gh, 3)
+		c.AddNote(iss, "labels", "Labels added: Documentation.")
+		run(c)
+		if edits := gh.Testing().Edits(); len(edits) != 0 {
+			t.Errorf("posted notes alone: %v", edits)
+		}
+		if p := c.Pending(project, 3); p != nil {
+			t.Errorf("Pending after Run = %v, want nil", p)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package corpuscheck cross-checks the stores that feed related-content
+// search: the GitHub issues in the database, the documents in the
+// corpus derived from them, and the vectors embedding those documents.
+//
+// Each store is updated from the previous one by a watcher
+// ([docs.Sync] and [embeddocs.Sync]), so normally they agree,
+// but a failed write or a hand edit of the database can leave them
+// out of step, which otherwise goes unnoticed until search
+// results look wrong.
+// A [Checker] reports such drift and, if enabled, repairs it.
+package corpuscheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+const reportKind = "corpuscheck.Report"
+
+// A Checker checks the consistency of GitHub issues, documents and vectors.
+type Checker struct {
+	slog     *slog.Logger
+	db       storage.DB
+	github   *github.Client
+	docs     *docs.Corpus
+	vector   storage.VectorDB
+	embed    llm.Embedder
+	projects map[string]bool
+	repair   bool
+}
+
+// New returns a new Checker for the issues synced by gh into db,
+// the corpus dc and the vector database vdb, which holds the
+// embeddings of dc's documents made with embed.
+// The Checker logs to lg.
+//
+// The Checker only checks issues in projects enabled with
+// [Checker.EnableProject], and only reports problems
+// unless repairs are enabled with [Checker.EnableRepair].
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, dc *docs.Corpus, vdb storage.VectorDB, embed llm.Embedder) *Checker {
+	return &Checker{
+		slog:     lg,
+		db:       db,
+		github:   gh,
+		docs:     dc,
+		vector:   vdb,
+		embed:    embed,
+		projects: make(map[string]bool),
+	}
+}
+
+// EnableProject enables checking the issues in the GitHub project.
+func (c *Checker) EnableProject(project string) {
+	c.projects[project] = true
+}
+
+// EnableRepair enables repairs: [Checker.Run] adds missing documents,
+// embeds documents with missing vectors, and deletes orphaned vectors.
+func (c *Checker) EnableRepair() {
+	c.repair = true
+}
+
+// A Report is the result of a check.
+type Report struct {
+	Time time.Time // when the check ran
+
+	// MissingDocs lists the IDs of documents for issues that
+	// have been synced to the corpus but are not in it.
+	MissingDocs []string
+	// MissingVectors lists the IDs of documents that have been
+	// embedded but have no vector.
+	MissingVectors []string
+	// OrphanedVectors lists the IDs of vectors with no document.
+	OrphanedVectors []string
+	// Unembedded is the number of documents written since the last
+	// embedding sync. They are not errors, but a large number means
+	// embedding is falling behind.
+	Unembedded int
+
+	Repaired bool // whether the problems were repaired
+}
+
+// OK reports whether the check found no problems.
+func (r *Report) OK() bool {
+	return len(r.MissingDocs) == 0 && len(r.MissingVectors) == 0 && len(r.OrphanedVectors) == 0
+}
+
+// Run checks the stores, repairs them if enabled, and returns a report,
+// which it also saves for [LastReport].
+// Run returns an error only if a repair fails.
+func (c *Checker) Run(ctx context.Context) (*Report, error) {
+	c.slog.Info("corpuscheck start")
+	r := &Report{Time: time.Now()}
+	c.checkDocs(r)
+	c.checkVectors(r)
+	c.slog.Info("corpuscheck done", "ok", r.OK(),
+		"missingDocs", len(r.MissingDocs),
+		"missingVectors", len(r.MissingVectors),
+		"orphanedVectors", len(r.OrphanedVectors),
+		"unembedded", r.Unembedded)
+
+	var err error
+	if c.repair && !r.OK() {
+		if err = c.fix(ctx, r); err == nil {
+			r.Repaired = true
+		}
+	}
+	c.db.Set(ordered.Encode(reportKind), storage.JSON(r))
+	return r, err
+}
+
+// LastReport returns the report of the last check run on db, if any.
+func LastReport(db storage.DB) (*Report, bool) {
+	val, ok := db.Get(ordered.Encode(reportKind))
+	if !ok {
+		return nil, false
+	}
+	var r Report
+	if err := json.Unmarshal(val, &r); err != nil {
+		db.Panic("corpuscheck report decode", "err", err)
+	}
+	return &r, true
+}
+
+// checkDocs records in r the issues that have been synced
+// to the corpus but have no documents.
+func (c *Checker) checkDocs(r *Report) {
+	synced := docs.Latest(c.github)
+	for project := range c.projects {
+		for e := range c.github.Events(project, 0, -1) {
+			if e.API != "/issues" || e.DBTime > synced {
+				continue
+			}
+			ds, ok := c.github.ToDocs(e)
+			if !ok {
+				continue
+			}
+			for d := range ds {
+				if _, ok := c.docs.Get(d.ID); !ok {
+					r.MissingDocs = append(r.MissingDocs, d.ID)
+				}
+			}
+		}
+	}
+}
+
+// checkVectors records in r the embedded documents without vectors,
+// the vectors without documents and the number of unembedded documents.
+func (c *Checker) checkVectors(r *Report) {
+	embedded := embeddocs.Latest(c.docs)
+	for d := range c.docs.Docs("") {
+		if d.DBTime > embedded {
+			r.Unembedded++
+			continue
+		}
+		if _, ok := c.vector.Get(d.ID); !ok {
+			r.MissingVectors = append(r.MissingVectors, d.ID)
+		}
+	}
+	for id := range c.vector.All() {
+		if _, ok := c.docs.Get(id); !ok {
+			r.OrphanedVectors = append(r.OrphanedVectors, id)
+		}
+	}
+}
+
+// fix repairs the problems in r.
+func (c *Checker) fix(ctx context.Context, r *Report) error {
+	// Re-add missing documents. They are newer than the last
+	// embedding sync, so the next sync embeds them.
+	missing := make(map[string]bool)
+	for _, id := range r.MissingDocs {
+		missing[id] = true
+	}
+	for project := range c.projects {
+		for e := range c.github.Events(project, 0, -1) {
+			if e.API != "/issues" {
+				continue
+			}
+			ds, ok := c.github.ToDocs(e)
+			if !ok {
+				continue
+			}
+			for d := range ds {
+				if missing[d.ID] {
+					c.slog.Info("corpuscheck add doc", "id", d.ID)
+					c.docs.Add(d.ID, d.Title, d.Text)
+				}
+			}
+		}
+	}
+
+	// Embed the documents with missing vectors.
+	const batchSize = 100
+	for i := 0; i < len(r.MissingVectors); i += batchSize {
+		ids := r.MissingVectors[i:min(i+batchSize, len(r.MissingVectors))]
+		var batch []llm.EmbedDoc
+		for _, id := range ids {
+			d, _ := c.docs.Get(id)
+			batch = append(batch, llm.EmbedDoc{Title: d.Title, Text: d.Text})
+		}
+		vecs, err := c.embed.EmbedDocs(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("corpuscheck: embedding: %w", err)
+		}
+		if len(vecs) != len(ids) {
+			return fmt.Errorf("corpuscheck: embedding length mismatch: docs=%d vecs=%d", len(ids), len(vecs))
+		}
+		vb := c.vector.Batch()
+		for j, v := range vecs {
+			c.slog.Info("corpuscheck embed doc", "id", ids[j])
+			vb.Set(ids[j], v)
+		}
+		vb.Apply()
+	}
+
+	// Delete orphaned vectors.
+	vb := c.vector.Batch()
+	for _, id := range r.OrphanedVectors {
+		c.slog.Info("corpuscheck delete vector", "id", id)
+		vb.Delete(id)
+	}
+	vb.Apply()
+	c.vector.Flush()
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package corpuscheck
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+var ctx = context.Background()
+
+func TestChecker(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	dc := docs.New(lg, db)
+	vdb := storage.MemVectorDB(db, lg, "vecs")
+	embed := llm.QuoteEmbedder()
+
+	for n := range int64(3) {
+		gh.Testing().AddIssue("golang/go", &github.Issue{Number: n + 1, Title: "title", Body: "body"})
+	}
+	docs.Sync(dc, gh)
+	check(embeddocs.Sync(ctx, lg, vdb, embed, dc))
+
+	c := New(lg, db, gh, dc, vdb, embed)
+	c.EnableProject("golang/go")
+	r, err := c.Run(ctx)
+	check(err)
+	if !r.OK() || r.Unembedded != 0 {
+		t.Fatalf("consistent stores: report %+v", r)
+	}
+
+	// Introduce drift.
+	dc.Delete("https://github.com/golang/go/issues/1")
+	vdb.Delete("https://github.com/golang/go/issues/2")
+	vdb.Set("https://github.com/golang/go/issues/99", llm.Vector{1})
+	dc.Add("https://go.dev/doc/new", "new", "not yet embedded")
+
+	r, err = c.Run(ctx)
+	check(err)
+	want := &Report{
+		Time:            r.Time,
+		MissingDocs:     []string{"https://github.com/golang/go/issues/1"},
+		MissingVectors:  []string{"https://github.com/golang/go/issues/2"},
+		OrphanedVectors: []string{"https://github.com/golang/go/issues/1", "https://github.com/golang/go/issues/99"},
+		Unembedded:      1,
+	}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("report (-want, +got):\n%s", diff)
+	}
+	if last, ok := LastReport(db); !ok || !cmp.Equal(last.MissingDocs, r.MissingDocs) {
+		t.Errorf("LastReport = %+v, %v, want %+v", last, ok, r)
+	}
+
+	// Repair, then let the embedding sync catch up.
+	c.EnableRepair()
+	r, err = c.Run(ctx)
+	check(err)
+	if !r.Repaired {
+		t.Errorf("repair run: Repaired = false")
+	}
+	check(embeddocs.Sync(ctx, lg, vdb, embed, dc))
+	r, err = c.Run(ctx)
+	check(err)
+	if !r.OK() || r.Unembedded != 0 || r.Repaired {
+		t.Errorf("after repair: report %+v", r)
+	}
+}
@@ -14,6 +14,16 @@
 //
 //	A Firestore DB in the given GCP project and Firestore database.
 //
+// postgres:DSN[~VECTOR_NAMESPACE]
+//
+//	A Postgres database with the pgvector extension, described by
+//	the data source name DSN, such as postgres://user@host/db.
+//
+// sqlite:FILE[~VECTOR_NAMESPACE]
+//
+//	A SQLite database in the file FILE, which is created if needed.
+//	FILE can be relative or absolute.
+//
 // mem[~VECTOR_NAMESPACE]
 //
 //	An in-memory database.
@@ -32,13 +42,15 @@ import (
 
 	"golang.org/x/oscar/internal/gcp/firestore"
 	"golang.org/x/oscar/internal/pebble"
+	"golang.org/x/oscar/internal/postgres"
+	"golang.org/x/oscar/internal/sqlite"
 	"golang.org/x/oscar/internal/storage"
 )
 
 // A Spec is the parsed representation of a DB specification string.
 type Spec struct {
 	Kind      string // "pebble", "firestore", etc.
-	Location  string // directory, project, etc.
+	Location  string // directory, project, DSN, etc.
 	Name      string // database name, for firestore
 	IsVector  bool   // spec refers to the vector part of the database
 	Namespace string // namespace of vector DB, possibly empty
@@ -56,6 +68,10 @@ func (s *Spec) String() string {
 		return "pebble:" + s.Location + vs
 	case "firestore":
 		return fmt.Sprintf("firestore:%s,%s%s", s.Location, s.Name, vs)
+	case "postgres":
+		return "postgres:" + s.Location + vs
+	case "sqlite":
+		return "sqlite:" + s.Location + vs
 	default:
 		return fmt.Sprintf("%#v", s)
 	}
@@ -70,6 +86,10 @@ func (s *Spec) Open(ctx context.Context, lg *slog.Logger) (storage.DB, error) {
 		return pebble.Open(lg, s.Location)
 	case "firestore":
 		return firestore.NewDB(ctx, lg, s.Location, s.Name)
+	case "postgres":
+		return postgres.Open(ctx, lg, postgres.DefaultDriver, s.Location)
+	case "sqlite":
+		return sqlite.Open(ctx, lg, sqlite.DefaultDriver, s.Location)
 	default:
 		return nil, fmt.Errorf("unknown DB kind %q", s.Kind)
 	}
@@ -115,6 +135,18 @@ func Parse(s string) (_ *Spec, err error) {
 		spec.Location = proj
 		spec.Name = db
 
+	case "postgres":
+		if middle == "" {
+			return nil, errors.New("postgres spec missing DSN; want postgres:DSN[~VECTOR_NAMESPACE]")
+		}
+		spec.Location = middle
+
+	case "sqlite":
+		if middle == "" {
+			return nil, errors.New("sqlite spec missing file; want sqlite:FILE[~VECTOR_NAMESPACE]")
+		}
+		spec.Location = filepath.Clean(middle)
+
 	default:
 		return nil, fmt.Errorf("unknown kind %q", kind)
 	}
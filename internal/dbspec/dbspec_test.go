@@ -5,9 +5,13 @@
 package dbspec
 
 import (
+	"context"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"golang.org/x/oscar/internal/testutil"
 )
 
 func TestParse(t *testing.T) {
@@ -133,6 +137,40 @@ func TestParse(t *testing.T) {
 				Namespace: "ns",
 			},
 		},
+		{
+			in:      "postgres:",
+			wantErr: "missing DSN",
+		},
+		{
+			in:      "sqlite:",
+			wantErr: "missing file",
+		},
+		{
+			in:   "sqlite:./gaby.db",
+			want: Spec{Kind: "sqlite", Location: "gaby.db"},
+		},
+		{
+			in: "sqlite:/var/gaby/state.db~ns",
+			want: Spec{
+				Kind:      "sqlite",
+				Location:  "/var/gaby/state.db",
+				IsVector:  true,
+				Namespace: "ns",
+			},
+		},
+		{
+			in:   "postgres:postgres://gaby@localhost:5432/oscar?sslmode=disable",
+			want: Spec{Kind: "postgres", Location: "postgres://gaby@localhost:5432/oscar?sslmode=disable"},
+		},
+		{
+			in: "postgres:host=/tmp dbname=oscar~ns",
+			want: Spec{
+				Kind:      "postgres",
+				Location:  "host=/tmp dbname=oscar",
+				IsVector:  true,
+				Namespace: "ns",
+			},
+		},
 	} {
 		got, err := Parse(tc.in)
 		if err != nil {
@@ -175,6 +213,14 @@ func TestString(t *testing.T) {
 			in:   Spec{Kind: "firestore", Location: "p", Name: "o"},
 			want: "firestore:p,o",
 		},
+		{
+			in:   Spec{Kind: "postgres", Location: "postgres://localhost/oscar", IsVector: true, Namespace: "ns"},
+			want: "postgres:postgres://localhost/oscar~ns",
+		},
+		{
+			in:   Spec{Kind: "sqlite", Location: "gaby.db"},
+			want: "sqlite:gaby.db",
+		},
 	} {
 		got := tc.in.String()
 		if got != tc.want {
@@ -182,3 +228,62 @@ func TestString(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenPostgres(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+
+	// The driver is linked in, so an unreachable database
+	// fails to connect rather than failing to find the driver.
+	spec, err := Parse("postgres:postgres://oscar@127.0.0.1:1/oscar?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spec.Open(ctx, lg); err == nil || strings.Contains(err.Error(), "unknown driver") {
+		t.Errorf("Open(%s) = %v, want connection error", spec, err)
+	}
+
+	dsn := os.Getenv("OSCAR_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("$OSCAR_POSTGRES_DSN not set")
+	}
+	spec, err = Parse("postgres:" + dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := spec.Open(ctx, lg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.Set([]byte("dbspec"), []byte("ok"))
+	if v, ok := db.Get([]byte("dbspec")); !ok || string(v) != "ok" {
+		t.Errorf("Get = %q, %v, want %q, true", v, ok, "ok")
+	}
+}
+
+func TestOpenSQLite(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	file := filepath.Join(t.TempDir(), "oscar.db")
+	spec, err := Parse("sqlite:" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := spec.Open(ctx, lg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Set([]byte("dbspec"), []byte("ok"))
+	db.Close()
+
+	// The data persists in the file.
+	db, err = spec.Open(ctx, lg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if v, ok := db.Get([]byte("dbspec")); !ok || string(v) != "ok" {
+		t.Errorf("after reopen, Get = %q, %v, want %q, true", v, ok, "ok")
+	}
+}
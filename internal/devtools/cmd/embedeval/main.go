@@ -0,0 +1,147 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Embedeval is a program for choosing an embedding model.
+It applies the internal/embedeval package to evaluate how well each
+candidate model retrieves the originals of duplicate issues,
+as found by gaby's duplicate mining job (see internal/dupmine).
+
+Usage:
+
+	embedeval [-project p] [-extra n] [-k list] model...
+
+Each model is PROVIDER or PROVIDER:MODEL, where PROVIDER is gemini,
+openai or ollama, and MODEL defaults to the provider's default
+embedding model. The issues are read from the production DB.
+
+For each model, embedeval prints the number of duplicates searched for,
+the recall@k for each k in the -k list (the fraction of duplicates
+whose original is among the k nearest neighbors) and the mean
+reciprocal rank (MRR) of the originals. Higher is better for both.
+
+The corpus searched holds the duplicates, their originals and
+the -extra issues with the highest numbers. More extra issues make
+the evaluation more realistic, but slower and more expensive.
+
+A typical run compares the current model with a new one:
+
+	go run . gemini openai:text-embedding-3-large
+*/
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/embedeval"
+	"golang.org/x/oscar/internal/gcp/firestore"
+	"golang.org/x/oscar/internal/gcp/gemini"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/ollama"
+	"golang.org/x/oscar/internal/openai"
+	"golang.org/x/oscar/internal/secret"
+)
+
+var (
+	project = flag.String("project", "golang/go", "GitHub project whose duplicate issues to use")
+	extra   = flag.Int("extra", 5000, "number of other issues in the corpus (-1 for all)")
+	ks      = flag.String("k", "1,5,10", "comma-separated list of k for recall@k")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: embedeval [flags] model...\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("embedeval: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+	}
+	if err := run(context.Background(), flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, models []string) error {
+	var kList []int
+	for _, f := range strings.Split(*ks, ",") {
+		k, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || k <= 0 || k > embedeval.MaxRank {
+			return fmt.Errorf("bad -k value %q", f)
+		}
+		kList = append(kList, k)
+	}
+
+	lg := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	embedders := make([]llm.Embedder, len(models))
+	for i, m := range models {
+		e, err := newEmbedder(ctx, lg, m)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m, err)
+		}
+		embedders[i] = e
+	}
+
+	db, err := firestore.NewDB(ctx, lg, "oscar-go-1", "prod")
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	ds := embedeval.NewDataset(db, *project, *extra)
+	log.Printf("found %d duplicates and %d issues in %.1fs", len(ds.Pairs), len(ds.Issues), time.Since(start).Seconds())
+	if len(ds.Pairs) == 0 {
+		return fmt.Errorf("no duplicates in %s", *project)
+	}
+
+	fmt.Printf("%-40s %7s", "model", "queries")
+	for _, k := range kList {
+		fmt.Printf(" %9s", fmt.Sprintf("recall@%d", k))
+	}
+	fmt.Printf(" %6s\n", "MRR")
+	for i, m := range models {
+		start := time.Now()
+		r, err := embedeval.Evaluate(ctx, lg, embedders[i], ds, kList)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m, err)
+		}
+		log.Printf("evaluated %s in %.1fs", m, time.Since(start).Seconds())
+		fmt.Printf("%-40s %7d", m, r.Queries)
+		for _, k := range kList {
+			fmt.Printf(" %9.3f", r.Recall[k])
+		}
+		fmt.Printf(" %6.3f\n", r.MRR)
+	}
+	return nil
+}
+
+// newEmbedder returns the embedder for the model m,
+// of the form PROVIDER or PROVIDER:MODEL.
+func newEmbedder(ctx context.Context, lg *slog.Logger, m string) (llm.Embedder, error) {
+	provider, model, _ := strings.Cut(m, ":")
+	sdb := secret.Netrc()
+	switch provider {
+	case "gemini":
+		return gemini.NewClient(ctx, lg, sdb, http.DefaultClient, cmp.Or(model, gemini.DefaultEmbeddingModel), gemini.DefaultGenerativeModel)
+	case "openai":
+		return openai.NewClient(lg, sdb, http.DefaultClient, cmp.Or(model, openai.DefaultEmbeddingModel), openai.DefaultGenerativeModel)
+	case "ollama":
+		return ollama.NewClient(lg, http.DefaultClient, "", cmp.Or(model, ollama.DefaultEmbeddingModel))
+	}
+	return nil, fmt.Errorf("unknown provider %q", provider)
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Embedmigrate re-embeds gaby's documents with a new embedding model
+and then switches gaby's searches over to the new embeddings.
+
+Usage:
+
+	embedmigrate [-project p] [-firestoredb db] [-workers n] model
+
+The model has the form PROVIDER/NAME, where PROVIDER is gemini,
+openai or ollama (for example, gemini/text-embedding-004).
+The API keys are read from $HOME/.netrc.
+
+Vectors made by different models cannot be compared, so the
+embeddings of each model are kept in their own namespace of
+the vector database (see embeddocs.Index). Embedmigrate fills
+the namespace of the new model while gaby keeps serving from the
+active one, then records the new namespace as active in a single
+write; gaby instances switch within a minute. If embedmigrate is
+interrupted, running it again resumes where it stopped.
+The -workers flag sets the number of concurrent batch-embedding
+requests; raise it to migrate faster, within the provider's quota.
+
+To change the embedding model:
+
+ 1. Deploy gaby with the -embedder flag naming the new provider.
+    Gaby keeps using the active model, and can embed with the new one.
+ 2. Run embedmigrate with the new model.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/gcp/firestore"
+	"golang.org/x/oscar/internal/gcp/gemini"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/ollama"
+	"golang.org/x/oscar/internal/openai"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+)
+
+var (
+	project     = flag.String("project", "oscar-go-1", "name of the Google Cloud Project")
+	firestoredb = flag.String("firestoredb", "prod", "name of the firestore db")
+	workers     = flag.Int("workers", embeddocs.DefaultPipeline.Workers, "number of concurrent batch-embedding requests")
+)
+
+// index is the name of gaby's vector index.
+const index = "gaby"
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: embedmigrate [flags] model\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("embedmigrate: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+	}
+	if err := run(context.Background(), flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, model string) error {
+	lg := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	embed, err := newEmbedder(ctx, lg, model)
+	if err != nil {
+		return err
+	}
+	db, err := firestore.NewDB(ctx, lg, *project, *firestoredb)
+	if err != nil {
+		return err
+	}
+	// The empty model means the index must already be recorded,
+	// which gaby does when it starts.
+	ix := embeddocs.NewIndex(lg, db, index, "", func(namespace string) (storage.VectorDB, error) {
+		return firestore.NewVectorDB(ctx, lg, *project, *firestoredb, namespace)
+	})
+	from := ix.Active()
+	if from.Model == "" {
+		return fmt.Errorf("no active embedding model recorded for %s; start gaby first", index)
+	}
+	log.Printf("migrating %s from %s (%s) to %s (%s)", index, from.Model, from.Namespace, model, embeddocs.Namespace(index, model))
+	ix.SetEmbedder(model, embed)
+	ix.SetChunker(embeddocs.NewChunker(embeddocs.DefaultChunkTokens, embeddocs.DefaultChunkOverlap))
+	pipeline := embeddocs.DefaultPipeline
+	pipeline.Workers = *workers
+	ix.SetPipeline(pipeline)
+	if err := ix.Migrate(ctx, docs.New(lg, db), model); err != nil {
+		return err
+	}
+	st := ix.Stats()
+	log.Printf("%s now uses %s (%d documents, %d chunks, %d requests, %d retries)", index, model, st.Docs, st.Chunks, st.Requests, st.Retries)
+	return nil
+}
+
+// newEmbedder returns the embedder for model, of the form PROVIDER/NAME.
+func newEmbedder(ctx context.Context, lg *slog.Logger, model string) (llm.Embedder, error) {
+	provider, name, ok := strings.Cut(model, "/")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("invalid model %q: want PROVIDER/NAME", model)
+	}
+	sdb := secret.Netrc()
+	switch provider {
+	case "gemini":
+		return gemini.NewClient(ctx, lg, sdb, http.DefaultClient, name, gemini.DefaultGenerativeModel)
+	case "openai":
+		return openai.NewClient(lg, sdb, http.DefaultClient, name, openai.DefaultGenerativeModel)
+	case "ollama":
+		return ollama.NewClient(lg, http.DefaultClient, "", name)
+	}
+	return nil, fmt.Errorf("unknown embedding provider %q", provider)
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Evals is a program for comparing prompt and model versions of
+gaby's issue overviews and related-document rankings.
+It applies the internal/evals package to a corpus of issues with
+golden summaries and relevant-document sets.
+
+Usage:
+
+	evals -corpus file [-judge model] [-k n] [-save prefix] variant...
+	evals -compare a,b
+
+Each variant is MODEL or MODEL,DIR. MODEL is PROVIDER or PROVIDER:NAME,
+where PROVIDER is gemini, openai or anthropic and NAME defaults to the
+provider's default generative model. DIR, if given, is a directory of
+prompt files that replace the built-in prompts (see llmapp.LoadPromptDir).
+
+For each variant, evals prints the mean scores of its overviews
+(ROUGE-1 and ROUGE-L F1 against the golden summaries and, with -judge,
+the 1-5 rating by the judge model) and of its related-document rankings
+(precision and recall of the first -k documents, and MRR).
+With two variants, it also prints the scores of each case side by side.
+
+With -save, the reports are stored in the production DB under the
+names prefix/variant, where gaby's /evals page can display and
+compare them. With -compare, evals prints the comparison of two
+stored reports without running anything.
+
+A typical run compares a new overview prompt with the current one:
+
+	go run . -corpus corpus.json -judge gemini gemini gemini,newprompts
+*/
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/oscar/internal/anthropic"
+	"golang.org/x/oscar/internal/evals"
+	"golang.org/x/oscar/internal/gcp/firestore"
+	"golang.org/x/oscar/internal/gcp/gemini"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/openai"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+)
+
+var (
+	corpusFile = flag.String("corpus", "", "JSON file holding the evaluation corpus")
+	judgeModel = flag.String("judge", "", "if set, the model (PROVIDER or PROVIDER:NAME) that rates overviews")
+	k          = flag.Int("k", evals.DefaultK, "number of related documents scored")
+	save       = flag.String("save", "", "if set, store the reports in the production DB under prefix/variant")
+	compare    = flag.String("compare", "", "if set, compare the stored reports a,b instead of running")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: evals -corpus file [flags] variant...\n       evals -compare a,b\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("evals: ")
+	flag.Usage = usage
+	flag.Parse()
+	ctx := context.Background()
+	lg := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	if *compare != "" {
+		if flag.NArg() != 0 {
+			usage()
+		}
+		if err := runCompare(ctx, lg, *compare); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *corpusFile == "" || flag.NArg() == 0 {
+		usage()
+	}
+	if err := run(ctx, lg, flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, lg *slog.Logger, variants []string) error {
+	corpus, err := evals.ReadCorpus(*corpusFile)
+	if err != nil {
+		return err
+	}
+	r := evals.NewRunner(lg)
+	r.SetK(*k)
+	if *judgeModel != "" {
+		j, err := newGenerator(ctx, lg, *judgeModel)
+		if err != nil {
+			return fmt.Errorf("judge: %w", err)
+		}
+		r.SetJudge(j)
+	}
+	var db storage.DB
+	if *save != "" {
+		if db, err = firestore.NewDB(ctx, lg, "oscar-go-1", "prod"); err != nil {
+			return err
+		}
+	}
+
+	var reps []*evals.Report
+	for _, v := range variants {
+		c, err := newClient(ctx, lg, v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", v, err)
+		}
+		name := v
+		if *save != "" {
+			name = *save + "/" + v
+		}
+		rep, err := r.Run(ctx, name, c, corpus)
+		if err != nil {
+			return err
+		}
+		if db != nil {
+			evals.SaveReport(db, rep)
+		}
+		reps = append(reps, rep)
+	}
+
+	printHeader()
+	for _, rep := range reps {
+		printScores(rep.Name, rep.Scores)
+	}
+	if len(reps) == 2 {
+		fmt.Println()
+		printCases(evals.Compare(reps[0], reps[1]))
+	}
+	return nil
+}
+
+func runCompare(ctx context.Context, lg *slog.Logger, names string) error {
+	na, nb, ok := strings.Cut(names, ",")
+	if !ok {
+		return fmt.Errorf("bad -compare value %q, want a,b", names)
+	}
+	db, err := firestore.NewDB(ctx, lg, "oscar-go-1", "prod")
+	if err != nil {
+		return err
+	}
+	a, ok := evals.LoadReport(db, na)
+	if !ok {
+		return fmt.Errorf("no report %q", na)
+	}
+	b, ok := evals.LoadReport(db, nb)
+	if !ok {
+		return fmt.Errorf("no report %q", nb)
+	}
+	c := evals.Compare(a, b)
+	printHeader()
+	printScores(a.Name, a.Scores)
+	printScores(b.Name, b.Scores)
+	printScores("delta", c.Delta)
+	fmt.Println()
+	printCases(c)
+	return nil
+}
+
+func printHeader() {
+	fmt.Printf("%-40s %7s %7s %5s %9s %6s %6s %6s\n", "variant", "ROUGE-1", "ROUGE-L", "judge", "precision", "recall", "MRR", "errors")
+}
+
+func printScores(name string, s evals.Scores) {
+	fmt.Printf("%-40s %7.3f %7.3f %5.2f %9.3f %6.3f %6.3f %6d\n", name, s.Rouge1, s.RougeL, s.Judge, s.Precision, s.Recall, s.MRR, s.Errors)
+}
+
+// printCases prints the ROUGE-L, judge and MRR scores of each case
+// in the two reports of c.
+func printCases(c *evals.Comparison) {
+	fmt.Printf("%-30s %15s %11s %13s\n", "case", "ROUGE-L a/b", "judge a/b", "MRR a/b")
+	score := func(cr *evals.CaseResult, f func(evals.Scores) float64) string {
+		if cr == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", f(cr.Scores))
+	}
+	rougeL := func(s evals.Scores) float64 { return s.RougeL }
+	judge := func(s evals.Scores) float64 { return s.Judge }
+	mrr := func(s evals.Scores) float64 { return s.MRR }
+	for _, cc := range c.Cases {
+		fmt.Printf("%-30s %15s %11s %13s\n", cc.Name,
+			score(cc.A, rougeL)+"/"+score(cc.B, rougeL),
+			score(cc.A, judge)+"/"+score(cc.B, judge),
+			score(cc.A, mrr)+"/"+score(cc.B, mrr))
+	}
+}
+
+// newClient returns the llmapp client for the variant v,
+// of the form MODEL or MODEL,DIR.
+func newClient(ctx context.Context, lg *slog.Logger, v string) (*llmapp.Client, error) {
+	model, dir, _ := strings.Cut(v, ",")
+	g, err := newGenerator(ctx, lg, model)
+	if err != nil {
+		return nil, err
+	}
+	// Cache responses in memory, so that each variant
+	// is evaluated with fresh responses.
+	c := llmapp.New(lg, g, storage.MemDB())
+	if dir != "" {
+		if err := c.LoadPromptDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// newGenerator returns the content generator for the model m,
+// of the form PROVIDER or PROVIDER:NAME.
+func newGenerator(ctx context.Context, lg *slog.Logger, m string) (llm.ContentGenerator, error) {
+	provider, model, _ := strings.Cut(m, ":")
+	sdb := secret.Netrc()
+	switch provider {
+	case "gemini":
+		return gemini.NewClient(ctx, lg, sdb, http.DefaultClient, gemini.DefaultEmbeddingModel, cmp.Or(model, gemini.DefaultGenerativeModel))
+	case "openai":
+		return openai.NewClient(lg, sdb, http.DefaultClient, openai.DefaultEmbeddingModel, cmp.Or(model, openai.DefaultGenerativeModel))
+	case "anthropic":
+		return anthropic.NewClient(lg, sdb, http.DefaultClient, cmp.Or(model, anthropic.DefaultModel))
+	}
+	return nil, fmt.Errorf("unknown provider %q", provider)
+}
@@ -0,0 +1,382 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Kindgen generates typed helpers for storing a kind of record
+// in a [storage.DB], in place of hand-written key and value encoding.
+//
+//	Usage: kindgen -type T -kind KIND -key FIELD,... [-name NAME] [-version N] [-output FILE]
+//
+// Kindgen is meant to be run by go generate, from a directive
+// in the package that defines the record type T, such as:
+//
+//	//go:generate go run golang.org/x/oscar/internal/devtools/cmd/kindgen -type Mute -kind mute.Issue -key Project,Issue
+//
+// For a record type Mute, the generated file mute_kind.go defines:
+//
+//	const muteKind = "mute.Issue"  // the key kind
+//	const muteVersion = 1          // the encoding version (-version)
+//	func muteKey(project string, issue int64) []byte
+//	func getMute(db storage.DB, project string, issue int64) (*Mute, bool)
+//	func setMute(db storage.DB, x *Mute)
+//	func deleteMute(db storage.DB, project string, issue int64)
+//	func scanMute(db storage.DB) iter.Seq[*Mute]
+//	func scanMuteByProject(db storage.DB, project string) iter.Seq[*Mute]
+//
+// The -name flag changes the name used in the generated identifiers,
+// for example to avoid a conflict with an existing promptVersion:
+// with -type Prompt -name storedPrompt, the helpers are named
+// storedPromptKind, getStoredPrompt, and so on.
+//
+// A record is stored under the key (KIND, FIELD, ...), made from
+// the -key fields of the record, which must have types that
+// [rsc.io/ordered] can encode. The value is the record encoded by
+// [storage.EncodeRecord] with the encoding version. There is a scan
+// function for every proper prefix of the key fields.
+//
+// Records stored as plain JSON before the kind had generated helpers
+// are read as version 0, which must have the same JSON form as version 1.
+// When a change to the record type needs more than the usual JSON
+// compatibility (such as a renamed or reinterpreted field), increase
+// -version and write a function
+//
+//	func upgradeMute(x *Mute, from int64)
+//
+// which the generated code calls to update records stored with
+// earlier versions as they are read. Reading a record stored with
+// a later version than the generated code knows panics, as for any
+// other database corruption.
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+var flags struct {
+	typ     string
+	name    string
+	kind    string
+	key     string
+	version int
+	output  string
+}
+
+func init() {
+	flag.StringVar(&flags.typ, "type", "", "name of the record type")
+	flag.StringVar(&flags.name, "name", "", "name used in the generated identifiers (default the -type name)")
+	flag.StringVar(&flags.kind, "kind", "", "key kind of the records, such as mute.Issue")
+	flag.StringVar(&flags.key, "key", "", "comma-separated list of the record fields that make up the key")
+	flag.IntVar(&flags.version, "version", 1, "encoding version of the records")
+	flag.StringVar(&flags.output, "output", "", "output file (default NAME_kind.go, in lower case)")
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: kindgen -type T -kind KIND -key FIELD,... [-name NAME] [-version N] [-output FILE]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("kindgen: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 0 || flags.typ == "" || flags.kind == "" || flags.key == "" {
+		usage()
+	}
+	spec := &spec{
+		Type:    flags.typ,
+		Name:    cmp.Or(flags.name, flags.typ),
+		Kind:    flags.kind,
+		Version: flags.version,
+		Args:    strings.Join(os.Args[1:], " "),
+	}
+	out, err := generate(".", spec, strings.Split(flags.key, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+	file := flags.output
+	if file == "" {
+		file = strings.ToLower(spec.Name) + "_kind.go"
+	}
+	if err := os.WriteFile(file, out, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// A spec describes the helpers to generate for a record type.
+type spec struct {
+	Package string // package name
+	Type    string // record type name
+	Name    string // name used in generated identifiers
+	Kind    string // key kind
+	Version int    // encoding version
+	Args    string // kindgen arguments, for the generated header
+	Key     []*keyField
+}
+
+// A keyField is a field of the record type that is part of the key.
+type keyField struct {
+	Field string // field name
+	Param string // parameter name
+	Type  string // Go type
+}
+
+// generate returns the helpers for spec, for the record type
+// defined in the package in dir, with the given key fields.
+func generate(dir string, spec *spec, key []string) ([]byte, error) {
+	if spec.Version < 1 {
+		return nil, fmt.Errorf("bad -version %d: must be at least 1", spec.Version)
+	}
+	pkg, st, err := findStruct(dir, spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	spec.Package = pkg
+	fields := make(map[string]string)
+	for _, f := range st.Fields.List {
+		for _, name := range f.Names {
+			fields[name.Name] = types.ExprString(f.Type)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, name := range key {
+		name = strings.TrimSpace(name)
+		typ, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s has no field %q", spec.Type, name)
+		}
+		if !keyTypes[typ] {
+			return nil, fmt.Errorf("key field %s.%s has type %s; want one of string, int, int64, uint64, bool or float64", spec.Type, name, typ)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate key field %q", name)
+		}
+		seen[name] = true
+		spec.Key = append(spec.Key, &keyField{Field: name, Param: param(name), Type: typ})
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, spec); err != nil {
+		return nil, err
+	}
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v\n%s", err, buf.Bytes())
+	}
+	return out, nil
+}
+
+// keyTypes are the allowed types of key fields,
+// which [rsc.io/ordered] can encode.
+var keyTypes = map[string]bool{
+	"string":  true,
+	"int":     true,
+	"int64":   true,
+	"uint64":  true,
+	"bool":    true,
+	"float64": true,
+}
+
+// findStruct returns the package name and the definition
+// of the struct type named typ in the package in dir.
+func findStruct(dir, typ string) (string, *ast.StructType, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, decl := range f.Decls {
+			g, ok := decl.(*ast.GenDecl)
+			if !ok || g.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range g.Specs {
+				ts := s.(*ast.TypeSpec)
+				if ts.Name.Name != typ {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					return "", nil, fmt.Errorf("type %s is not a struct", typ)
+				}
+				return f.Name.Name, st, nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("no type %s in %s", typ, dir)
+}
+
+// param returns the parameter name for the field name.
+func param(name string) string {
+	r := []rune(name)
+	// Lower the leading upper-case letters: ID -> id, URLPath -> urlPath.
+	i := 0
+	for i < len(r) && unicode.IsUpper(r[i]) {
+		i++
+	}
+	if i > 1 && i < len(r) {
+		i-- // keep the start of the next word
+	}
+	for j := range i {
+		r[j] = unicode.ToLower(r[j])
+	}
+	p := string(r)
+	if token.IsKeyword(p) || p == "db" || p == "x" {
+		p += "_"
+	}
+	return p
+}
+
+var tmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"lower": func(s string) string {
+		r := []rune(s)
+		r[0] = unicode.ToLower(r[0])
+		return string(r)
+	},
+	"upper": func(s string) string {
+		r := []rune(s)
+		r[0] = unicode.ToUpper(r[0])
+		return string(r)
+	},
+	"params": func(key []*keyField) string {
+		var list []string
+		for _, k := range key {
+			list = append(list, k.Param+" "+k.Type)
+		}
+		return strings.Join(list, ", ")
+	},
+	"args": func(key []*keyField) string {
+		var list []string
+		for _, k := range key {
+			list = append(list, k.Param)
+		}
+		return strings.Join(list, ", ")
+	},
+	"fields": func(key []*keyField) string {
+		var list []string
+		for _, k := range key {
+			list = append(list, "x."+k.Field)
+		}
+		return strings.Join(list, ", ")
+	},
+	"names": func(key []*keyField) string {
+		var list []string
+		for _, k := range key {
+			list = append(list, k.Field)
+		}
+		return strings.Join(list, "")
+	},
+	"prefixes": func(key []*keyField) [][]*keyField {
+		var list [][]*keyField
+		for i := 1; i < len(key); i++ {
+			list = append(list, key[:i])
+		}
+		return list
+	},
+}).Parse(`// Code generated by "kindgen {{.Args}}"; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"iter"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+{{$T := .Type}}{{$N := upper .Name}}{{$t := lower .Name}}
+// {{$t}}Kind is the key kind of a stored [{{$T}}]:
+//
+//	({{.Kind}}{{range .Key}}, {{.Field}}{{end}}) -> [{{$T}}]
+const {{$t}}Kind = "{{.Kind}}"
+
+// {{$t}}Version is the encoding version of a stored [{{$T}}].
+const {{$t}}Version = {{.Version}}
+
+// {{$t}}Key returns the database key of the [{{$T}}] with the given key fields.
+func {{$t}}Key({{params .Key}}) []byte {
+	return ordered.Encode({{$t}}Kind, {{args .Key}})
+}
+
+// get{{$N}} returns the stored [{{$T}}] with the given key fields, if any.
+func get{{$N}}(db storage.DB, {{params .Key}}) (*{{$T}}, bool) {
+	val, ok := db.Get({{$t}}Key({{args .Key}}))
+	if !ok {
+		return nil, false
+	}
+	return decode{{$N}}(db, val), true
+}
+
+// set{{$N}} stores x under the key made from its key fields.
+func set{{$N}}(db storage.DB, x *{{$T}}) {
+	db.Set({{$t}}Key({{fields .Key}}), storage.EncodeRecord({{$t}}Version, x))
+}
+
+// delete{{$N}} deletes the stored [{{$T}}] with the given key fields, if any.
+func delete{{$N}}(db storage.DB, {{params .Key}}) {
+	db.Delete({{$t}}Key({{args .Key}}))
+}
+
+// scan{{$N}} returns the stored [{{$T}}] records, in key order.
+func scan{{$N}}(db storage.DB) iter.Seq[*{{$T}}] {
+	return scan{{$N}}Range(db, ordered.Encode({{$t}}Kind), ordered.Encode({{$t}}Kind, ordered.Inf))
+}
+{{range prefixes .Key}}
+// scan{{$N}}By{{names .}} returns the stored [{{$T}}] records
+// with the given {{range $i, $k := .}}{{if $i}} and {{end}}{{$k.Field}}{{end}}, in key order.
+func scan{{$N}}By{{names .}}(db storage.DB, {{params .}}) iter.Seq[*{{$T}}] {
+	return scan{{$N}}Range(db, ordered.Encode({{$t}}Kind, {{args .}}), ordered.Encode({{$t}}Kind, {{args .}}, ordered.Inf))
+}
+{{end}}
+// scan{{$N}}Range returns the stored [{{$T}}] records
+// with keys in the range [start, end], in key order.
+func scan{{$N}}Range(db storage.DB, start, end []byte) iter.Seq[*{{$T}}] {
+	return func(yield func(*{{$T}}) bool) {
+		for _, val := range db.Scan(start, end) {
+			if !yield(decode{{$N}}(db, val())) {
+				return
+			}
+		}
+	}
+}
+
+// decode{{$N}} decodes a stored [{{$T}}].
+func decode{{$N}}(db storage.DB, val []byte) *{{$T}} {
+	var x {{$T}}
+	v, err := storage.DecodeRecord(val, &x)
+	if err != nil {
+		// unreachable unless database corruption
+		db.Panic("decode {{.Kind}}", "err", err)
+	}
+	if v > {{$t}}Version {
+		db.Panic("decode {{.Kind}}: stored with newer encoding", "version", v, "known", {{$t}}Version)
+	}
+{{- if gt .Version 1}}
+	if v < {{$t}}Version {
+		upgrade{{$N}}(&x, v)
+	}
+{{- end}}
+	return &x
+}
+`))
@@ -0,0 +1,78 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update testdata/record_kind.golden")
+
+func TestGenerate(t *testing.T) {
+	spec := &spec{
+		Type:    "Record",
+		Name:    "Record",
+		Kind:    "record.Record",
+		Version: 2,
+		Args:    "-type Record -kind record.Record -key Project,Type,ID -version 2",
+	}
+	out, err := generate("testdata", spec, []string{"Project", "Type", "ID"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const golden = "testdata/record_kind.golden"
+	if *update {
+		if err := os.WriteFile(golden, out, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(out)); diff != "" {
+		t.Errorf("generated code mismatch (-want +got); run with -update if expected:\n%s", diff)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, tt := range []struct {
+		typ     string
+		key     []string
+		version int
+		want    string
+	}{
+		{"Missing", []string{"ID"}, 1, "no type Missing"},
+		{"NotStruct", []string{"ID"}, 1, "not a struct"},
+		{"Record", []string{"Name"}, 1, `no field "Name"`},
+		{"Record", []string{"ID", "ID"}, 1, "duplicate key field"},
+		{"Record", []string{"ID"}, 0, "bad -version"},
+	} {
+		spec := &spec{Type: tt.typ, Name: tt.typ, Kind: "k", Version: tt.version}
+		_, err := generate("testdata", spec, tt.key)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("generate(%s, %v, version %d): err = %v, want %q", tt.typ, tt.key, tt.version, err, tt.want)
+		}
+	}
+}
+
+func TestParam(t *testing.T) {
+	for in, want := range map[string]string{
+		"Project": "project",
+		"ID":      "id",
+		"URLPath": "urlPath",
+		"Type":    "type_",
+		"X":       "x_",
+	} {
+		if got := param(in); got != want {
+			t.Errorf("param(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
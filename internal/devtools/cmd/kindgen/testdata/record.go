@@ -0,0 +1,10 @@
+package record
+
+type Record struct {
+	Project string
+	Type    string
+	ID      int64
+	Text    string
+}
+
+type NotStruct int
@@ -0,0 +1,102 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Learnrank trains the learned reranker of gaby's related documents
+(see internal/learnrank) from the emoji votes on posted related comments.
+
+Usage:
+
+	learnrank [-name name] [-save]
+
+Learnrank reads the related comments, the votes on them and the
+vector embeddings from the production DB, trains a model and prints
+its weights, log loss and accuracy, along with those of the model
+currently stored under the name, evaluated on the same examples.
+
+With -save, learnrank stores the new model under the name.
+A gaby running with -relatedrerank=name picks up the new model
+without restarting.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/feedback"
+	"golang.org/x/oscar/internal/gcp/firestore"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/learnrank"
+	"golang.org/x/oscar/internal/storage"
+)
+
+var (
+	name = flag.String("name", "related", "name of the model")
+	save = flag.Bool("save", false, "store the trained model under -name")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: learnrank [flags]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("learnrank: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 0 {
+		usage()
+	}
+	if err := run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context) error {
+	lg := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, err := firestore.NewDB(ctx, lg, "oscar-go-1", "prod")
+	if err != nil {
+		return err
+	}
+	// Use the namespace of the active embedding model.
+	vdb := embeddocs.NewIndex(lg, db, "gaby", "", func(namespace string) (storage.VectorDB, error) {
+		return firestore.NewVectorDB(ctx, lg, "oscar-go-1", "prod", namespace)
+	})
+	// Only read the comments that gaby's collector tracks.
+	fb := feedback.New(lg, db, github.New(lg, db, nil, nil), "gabyhelp", "feedback")
+
+	x := learnrank.NewExtractor(db, fb)
+	exs := x.Examples(vdb)
+	log.Printf("found %d examples", len(exs))
+	m, err := learnrank.Train(exs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-10s %8s\n", "feature", "weight")
+	for _, f := range learnrank.FeatureNames {
+		fmt.Printf("%-10s %8.3f\n", f, m.Weights[f])
+	}
+	fmt.Printf("%-10s %8.3f\n", "(bias)", m.Bias)
+	fmt.Printf("\n%-10s %8s %8s\n", "model", "loss", "accuracy")
+	fmt.Printf("%-10s %8.3f %8.3f\n", "new", m.Loss, m.Accuracy)
+	if old, ok := learnrank.LoadModel(db, *name); ok {
+		loss, acc := learnrank.Evaluate(old, exs)
+		fmt.Printf("%-10s %8.3f %8.3f\n", "current", loss, acc)
+	}
+
+	if *save {
+		learnrank.SaveModel(db, *name, m)
+		log.Printf("saved model %q", *name)
+	}
+	return nil
+}
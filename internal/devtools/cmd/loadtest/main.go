@@ -0,0 +1,242 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// loadtest sends concurrent requests to the search and overview
+// pages of a running Gaby and reports request latencies.
+//
+//	Usage: go run . [-server http://localhost:4229] [-n 1000] [-c 8] [-pages search,overview] [-queries file] [-issues 1-1000]
+//
+// Search requests cycle through the queries in the -queries file
+// (one per line), or a built-in list if there is none.
+// Overview requests cycle through the golang/go issues in the
+// -issues range.
+//
+// For each page, loadtest prints the number of requests and
+// errors, the throughput, and the p50, p90, p99 and maximum latency.
+//
+// To measure allocations, see the benchmarks in
+// golang.org/x/oscar/internal/gaby (bench_test.go), which run the
+// same handlers in-process against a synthetic corpus.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+var flags = struct {
+	server  string
+	n       int
+	c       int
+	pages   string
+	queries string
+	issues  string
+	timeout time.Duration
+}{}
+
+func init() {
+	flag.StringVar(&flags.server, "server", "http://localhost:4229", "base URL of the Gaby server")
+	flag.IntVar(&flags.n, "n", 1000, "number of requests to send to each page")
+	flag.IntVar(&flags.c, "c", 8, "number of concurrent requests")
+	flag.StringVar(&flags.pages, "pages", "search,overview", "comma-separated list of pages to load")
+	flag.StringVar(&flags.queries, "queries", "", "file of search queries, one per line")
+	flag.StringVar(&flags.issues, "issues", "1-1000", "range of golang/go issue numbers for overview requests")
+	flag.DurationVar(&flags.timeout, "timeout", time.Minute, "timeout for each request")
+}
+
+// defaultQueries are the search queries used if there is no -queries file.
+var defaultQueries = []string{
+	"cmd/go: build cache grows without bound",
+	"runtime: fatal error: concurrent map writes",
+	"net/http: request canceled while waiting for connection",
+	"proposal: spec: add sum types",
+	"x/tools/gopls: high memory usage",
+	"cmd/compile: internal compiler error with generics",
+	"testing: flaky timeout on windows",
+	"https://github.com/golang/go/issues/1",
+}
+
+func main() {
+	flag.Parse()
+	if flags.n <= 0 || flags.c <= 0 {
+		log.Fatal("-n and -c must be positive")
+	}
+
+	queries := defaultQueries
+	if flags.queries != "" {
+		var err error
+		if queries, err = readLines(flags.queries); err != nil {
+			log.Fatal(err)
+		}
+		if len(queries) == 0 {
+			log.Fatalf("%s: no queries", flags.queries)
+		}
+	}
+	lo, hi, err := parseRange(flags.issues)
+	if err != nil {
+		log.Fatalf("-issues: %v", err)
+	}
+
+	var errs []string
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "page\trequests\terrors\treq/s\tp50\tp90\tp99\tmax\t")
+	for _, page := range strings.Split(flags.pages, ",") {
+		var target func(i int) string
+		switch page = strings.TrimSpace(page); page {
+		case "search":
+			target = func(i int) string {
+				return "/search?q=" + url.QueryEscape(queries[i%len(queries)])
+			}
+		case "overview":
+			target = func(i int) string {
+				return fmt.Sprintf("/overview?q=%d", lo+int64(i)%(hi-lo+1))
+			}
+		default:
+			log.Fatalf("unknown page %q", page)
+		}
+		r := run(context.Background(), flags.server, target, flags.n, flags.c)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%v\t%v\t%v\t%v\t\n", page,
+			len(r.latencies), r.errors, float64(len(r.latencies))/r.elapsed.Seconds(),
+			r.percentile(50), r.percentile(90), r.percentile(99), r.percentile(100))
+		if r.firstErr != nil {
+			errs = append(errs, fmt.Sprintf("%s: first error: %v", page, r.firstErr))
+		}
+	}
+	w.Flush()
+	for _, e := range errs {
+		log.Print(e)
+	}
+}
+
+// A result holds the measurements of a single load test.
+type result struct {
+	latencies []time.Duration // sorted
+	errors    int
+	firstErr  error
+	elapsed   time.Duration
+}
+
+// percentile returns the p'th percentile latency.
+func (r *result) percentile(p int) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	return r.latencies[(len(r.latencies)-1)*p/100].Round(time.Microsecond)
+}
+
+// run sends n GET requests for server+target(i), for i in [0, n),
+// using c concurrent workers.
+func run(ctx context.Context, server string, target func(int) string, n, c int) *result {
+	var (
+		mu   sync.Mutex
+		r    = &result{}
+		wg   sync.WaitGroup
+		next = make(chan int)
+	)
+	client := &http.Client{Timeout: flags.timeout}
+	start := time.Now()
+	for range c {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				t := time.Now()
+				err := get(ctx, client, server+target(i))
+				d := time.Since(t)
+				mu.Lock()
+				r.latencies = append(r.latencies, d)
+				if err != nil {
+					r.errors++
+					if r.firstErr == nil {
+						r.firstErr = err
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	r.elapsed = time.Since(start)
+	slices.Sort(r.latencies)
+	return r
+}
+
+// get fetches the URL and reads the entire response body.
+// Gaby reports page errors in the page itself,
+// so get treats a page containing an error message as failed.
+func get(ctx context.Context, client *http.Client, u string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("GET %s: %v", u, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	if _, msg, ok := strings.Cut(string(body), "<p>Error: "); ok {
+		msg, _, _ = strings.Cut(msg, "</p>")
+		return fmt.Errorf("GET %s: %s", u, msg)
+	}
+	return nil
+}
+
+// readLines returns the non-empty lines of the named file.
+func readLines(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, s.Err()
+}
+
+// parseRange parses a range of the form "lo-hi" or "n".
+func parseRange(s string) (lo, hi int64, err error) {
+	los, his, ok := strings.Cut(s, "-")
+	if !ok {
+		his = los
+	}
+	if lo, err = strconv.ParseInt(los, 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if hi, err = strconv.ParseInt(his, 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if lo <= 0 || hi < lo {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	return lo, hi, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Oscar is a command-line client for the data gaby stores,
+for maintainers who would rather stay in the terminal.
+
+Usage:
+
+	oscar [-color auto|always|never] [-width n] command args...
+
+The commands are:
+
+	overview issue...
+		print the latest overview of each issue
+
+Each issue is a number in the -project, project#number
+(for example, golang/go#12345) or a GitHub issue URL.
+
+Overviews are read from the production DB; use gaby's /overview
+page to generate an overview for an issue that has none.
+The output is formatted for a terminal: Markdown is wrapped at
+-width columns (by default, the width of the terminal) and styled
+with ANSI escape sequences, unless standard output is not a terminal,
+-color=never is given or the NO_COLOR environment variable is set.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oscar/internal/gcp/firestore"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/termfmt"
+)
+
+var (
+	project = flag.String("project", "golang/go", "GitHub project of issues given by number")
+	color   = flag.String("color", "auto", "whether to color the output: auto, always or never")
+	width   = flag.Int("width", 0, "wrap the output at this many columns; 0 means the width of the terminal")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: oscar [flags] overview issue...\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("oscar: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 2 || flag.Arg(0) != "overview" {
+		usage()
+	}
+	opts := termfmt.ForTerminal(os.Stdout)
+	switch *color {
+	case "auto":
+	case "always":
+		opts.Color = true
+	case "never":
+		opts.Color = false
+	default:
+		log.Fatalf("invalid -color %q", *color)
+	}
+	if *width > 0 {
+		opts.Width = *width
+	}
+	if err := printOverviews(context.Background(), flag.Args()[1:], opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printOverviews prints the latest overview of each issue in args.
+func printOverviews(ctx context.Context, args []string, opts termfmt.Options) error {
+	lg := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, err := firestore.NewDB(ctx, lg, "oscar-go-1", "prod")
+	if err != nil {
+		return err
+	}
+	gh := github.New(lg, db, nil, nil)
+	// The same name and bot as gaby's overview client, to share its history.
+	oc := overview.New(lg, db, gh, nil, "overview", "gabyhelp")
+	for i, arg := range args {
+		proj, n, err := parseIssue(arg)
+		if err != nil {
+			return err
+		}
+		iss, err := github.LookupIssue(db, proj, n)
+		if err != nil {
+			return err
+		}
+		r, generated, ok := oc.LastForIssue(iss)
+		if !ok {
+			return fmt.Errorf("%s#%d has no overview", proj, n)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(overview.TerminalText(iss, r, generated, opts))
+	}
+	return nil
+}
+
+// parseIssue parses an issue argument: a number in the -project,
+// project#number, or a GitHub issue URL.
+func parseIssue(s string) (string, int64, error) {
+	if strings.HasPrefix(s, "https://") {
+		return github.ParseIssueURL(s)
+	}
+	proj, num, ok := strings.Cut(s, "#")
+	if !ok {
+		proj, num = *project, s
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n <= 0 {
+		return "", 0, fmt.Errorf("invalid issue %q", s)
+	}
+	return proj, n, nil
+}
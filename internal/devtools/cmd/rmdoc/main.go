@@ -18,6 +18,7 @@ import (
 	"cloud.google.com/go/compute/metadata"
 	"golang.org/x/oscar/internal/dbspec"
 	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
 	"golang.org/x/oscar/internal/gcp/firestore"
 	"golang.org/x/oscar/internal/storage"
 )
@@ -103,10 +104,9 @@ func initGCP() (storage.DB, storage.VectorDB) {
 		log.Fatal(err)
 	}
 
-	const vectorDBNamespace = "gaby"
-	vdb, err := firestore.NewVectorDB(ctx, slog.Default(), flags.project, flags.firestoredb, vectorDBNamespace)
-	if err != nil {
-		log.Fatal(err)
-	}
+	// Use the namespace of the active embedding model.
+	vdb := embeddocs.NewIndex(logger, db, "gaby", "", func(namespace string) (storage.VectorDB, error) {
+		return firestore.NewVectorDB(ctx, logger, flags.project, flags.firestoredb, namespace)
+	})
 	return db, vdb
 }
@@ -11,6 +11,7 @@ import (
 // Discussion represents a GitHub discussion and its metadata.
 type Discussion struct {
 	URL              string         `json:"url"`
+	NodeID           string         `json:"node_id,omitempty"` // GraphQL node ID, used to post comments
 	Number           int64          `json:"number"`
 	Author           github.User    `json:"author"`
 	Title            string         `json:"title"`
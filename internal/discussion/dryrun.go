@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"encoding/json"
+	"iter"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// EnableDryRun enables dry-run mode, in which comments are diverted
+// instead of being posted to GitHub, as in testing mode.
+// Unlike testing mode, diverted comments are recorded in the database,
+// where they can be inspected using [Client.DivertedComments].
+// Syncing and other read-only operations are unaffected.
+func (c *Client) EnableDryRun() {
+	c.dryRun = true
+}
+
+// DryRun reports whether the client is in dry-run mode.
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
+
+// divertedCommentKind is the timed kind for comments recorded in dry-run mode.
+// The key is (discussion URL, wall-clock nanoseconds).
+const divertedCommentKind = "discussion.DivertedComment"
+
+// A DivertedComment is a comment that was recorded in dry-run mode
+// instead of being posted to GitHub.
+type DivertedComment struct {
+	DBTime timed.DBTime // time the comment was recorded in the database
+	Time   time.Time    // wall-clock time the comment was posted
+	Edit   *TestingEdit
+}
+
+// recordEdit records the diverted comment e.
+// In testing mode, e is saved in memory for [TestingClient.Edits].
+// In dry-run mode, e is written to the database.
+// The caller must hold c.testMu.
+func (c *Client) recordEdit(e *TestingEdit) {
+	if c.testing {
+		c.testEdits = append(c.testEdits, e)
+	}
+	if c.dryRun {
+		now := time.Now()
+		b := c.db.Batch()
+		timed.Set(c.db, b, divertedCommentKind, ordered.Encode(e.URL, now.UnixNano()), storage.JSON(e))
+		b.Apply()
+		c.slog.Info("discussion dry run: diverted comment", "url", e.URL)
+	}
+}
+
+// DivertedComments returns an iterator over the comments recorded in dry-run mode
+// after the given DBTime, in the order they were posted.
+func (c *Client) DivertedComments(after timed.DBTime) iter.Seq[*DivertedComment] {
+	return func(yield func(*DivertedComment) bool) {
+		for te := range timed.ScanAfter(c.slog, c.db, divertedCommentKind, after, nil) {
+			var url string
+			var nanos int64
+			if err := ordered.Decode(te.Key, &url, &nanos); err != nil {
+				// unreachable unless database corruption
+				c.db.Panic("discussion.DivertedComments decode key", "key", storage.Fmt(te.Key), "err", err)
+			}
+			d := &DivertedComment{
+				DBTime: te.ModTime,
+				Time:   time.Unix(0, nanos),
+				Edit:   new(TestingEdit),
+			}
+			if err := json.Unmarshal(te.Val, d.Edit); err != nil {
+				// unreachable unless database corruption
+				c.db.Panic("discussion.DivertedComments decode value", "key", storage.Fmt(te.Key), "err", err)
+			}
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
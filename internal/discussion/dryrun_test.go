@@ -0,0 +1,55 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// A countTransport is an [http.RoundTripper] that
+// counts and refuses all requests.
+type countTransport struct {
+	n int
+}
+
+func (ct *countTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	ct.n++
+	return nil, errors.New("refused")
+}
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	c := New(ctx, testutil.Slogger(t), secret.Empty(), storage.MemDB())
+	ct := new(countTransport)
+	c.gql = newGQLClient(&http.Client{Transport: ct})
+	c.DisableTesting()
+	d := &Discussion{URL: "https://github.com/golang/go/discussions/1", NodeID: "D_1"}
+
+	// Outside testing and dry-run modes, the comment is sent.
+	if _, err := c.PostComment(ctx, d, "hello"); err == nil || ct.n == 0 {
+		t.Fatalf("PostComment = %v after %d requests, want refused request", err, ct.n)
+	}
+
+	ct.n = 0
+	c.EnableDryRun()
+	if _, err := c.PostComment(ctx, d, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if ct.n != 0 {
+		t.Errorf("dry run sent %d requests, want 0", ct.n)
+	}
+	got := slices.Collect(c.DivertedComments(0))
+	if len(got) != 1 || *got[0].Edit != (TestingEdit{URL: d.URL, Body: "hello"}) {
+		t.Errorf("DivertedComments = %v, want the dry-run comment", got)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"context"
+	"fmt"
+
+	gql "github.com/shurcooL/githubv4"
+)
+
+// PostComment posts a new comment with the given body (written in Markdown)
+// on the discussion, returning the URL of the new comment.
+// The discussion must have been synced since GraphQL node IDs were recorded
+// ([Discussion.NodeID]).
+//
+// In testing mode (see [Client.Testing]), PostComment records the comment
+// for [TestingClient.Edits] instead of posting it; in dry-run mode
+// (see [Client.EnableDryRun]), it records the comment in the database
+// for [Client.DivertedComments].
+func (c *Client) PostComment(ctx context.Context, d *Discussion, body string) (url string, err error) {
+	if c.testing || c.dryRun {
+		c.testMu.Lock()
+		defer c.testMu.Unlock()
+
+		c.recordEdit(&TestingEdit{URL: d.URL, Body: body})
+		return "test-url", nil
+	}
+
+	if d.NodeID == "" {
+		return "", fmt.Errorf("discussion.PostComment %s: no node ID (sync the discussion again)", d.URL)
+	}
+	var m struct {
+		AddDiscussionComment struct {
+			Comment struct {
+				URL gql.URI
+			}
+		} `graphql:"addDiscussionComment(input: $input)"`
+	}
+	in := gql.AddDiscussionCommentInput{
+		DiscussionID: gql.ID(d.NodeID),
+		Body:         gql.String(body),
+	}
+	if err := c.gql.Mutate(ctx, &m, in, nil); err != nil {
+		return "", fmt.Errorf("discussion.PostComment %s: %w", d.URL, err)
+	}
+	return m.AddDiscussionComment.Comment.URL.String(), nil
+}
@@ -5,8 +5,10 @@
 package discussion
 
 import (
+	"fmt"
 	"iter"
 	"slices"
+	"strings"
 
 	"golang.org/x/oscar/internal/docs"
 	"golang.org/x/oscar/internal/github"
@@ -23,18 +25,68 @@ func (c *Client) DocWatcher() *timed.Watcher[*Event] {
 	return c.EventWatcher(DocWatcherID)
 }
 
-// ToDocs converts an event containing a discussion to
-// an embeddable document (wrapped as an iterator).
-// It returns (nil, false) if the event is not a discussion.
+// ToDocs converts an event containing a discussion or a comment to
+// an embeddable document (wrapped as an iterator) for the whole
+// discussion thread: the discussion followed by its comments and
+// replies, so that search can surface answers given in the comments.
+// A comment event rewrites the document for its discussion.
+// It returns (nil, false) if the event is neither a discussion nor a
+// comment, or if the comment's discussion is not in the database.
 // Implements [docs.Source.ToDocs].
-func (*Client) ToDocs(e *Event) (iter.Seq[*docs.Doc], bool) {
-	d, ok := e.Typed.(*Discussion)
-	if !ok {
+func (c *Client) ToDocs(e *Event) (iter.Seq[*docs.Doc], bool) {
+	var d *Discussion
+	switch e.Typed.(type) {
+	case *Discussion, *Comment:
+		// Build the document from the current state of the
+		// thread in the database.
+		d = c.LookupDiscussion(e.Project, e.Discussion)
+	}
+	if d == nil {
 		return nil, false
 	}
 	return slices.Values([]*docs.Doc{{
 		ID:    d.URL,
 		Title: github.CleanTitle(d.Title),
-		Text:  github.CleanBody(d.Body),
+		Text:  c.threadText(e.Project, d),
 	}}), true
 }
+
+// LookupDiscussion returns the discussion with the given number
+// in the database, or nil if it is not present.
+func (c *Client) LookupDiscussion(project string, number int64) *Discussion {
+	for e := range c.Events(project, number, number) {
+		if d, ok := e.Typed.(*Discussion); ok {
+			return d
+		}
+	}
+	return nil
+}
+
+// threadText returns the text of the discussion d followed
+// by the text of its comments, oldest first.
+// A discussion without comments is represented by its body alone.
+func (c *Client) threadText(project string, d *Discussion) string {
+	var b strings.Builder
+	b.WriteString(github.CleanBody(d.Body))
+	for e := range c.Events(project, d.Number, d.Number) {
+		cm, ok := e.Typed.(*Comment)
+		if !ok || strings.TrimSpace(cm.Body) == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n---\n\n%s:\n\n%s", commentLabel(cm), github.CleanBody(cm.Body))
+	}
+	return b.String()
+}
+
+// commentLabel returns a short label introducing the comment cm
+// in a discussion thread.
+func commentLabel(cm *Comment) string {
+	kind := "Comment"
+	if cm.ReplyToURL != "" {
+		kind = "Reply"
+	}
+	if cm.Author.Login == "" {
+		return kind
+	}
+	return kind + " by " + cm.Author.Login
+}
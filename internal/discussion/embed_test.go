@@ -13,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/secret"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/testutil"
@@ -38,11 +39,17 @@ func TestDiscussionDocSync(t *testing.T) {
 		Body:  "Another body",
 	}
 	c1 := &Comment{
-		Body: "comment",
+		Author: github.User{Login: "gopher"},
+		Body:   "comment",
+	}
+	c2 := &Comment{
+		ReplyToURL: "reply-to",
+		Body:       "reply",
 	}
 
 	id := c.Testing().AddDiscussion(project, d1)
-	_ = c.Testing().AddComment(project, id, c1) // ignored (comment)
+	_ = c.Testing().AddComment(project, id, c1) // added to the discussion's doc
+	_ = c.Testing().AddComment(project, id, c2)
 	id2 := c.Testing().AddDiscussion(project, d2)
 
 	dc := docs.New(lg, db)
@@ -51,7 +58,7 @@ func TestDiscussionDocSync(t *testing.T) {
 	dURL := func(d int64) string { return fmt.Sprintf("https://github.com/test/project/discussions/%d", d) }
 	got := slices.Collect(dc.Docs(""))
 	want := []*docs.Doc{
-		{ID: dURL(id), Title: d1.Title, Text: d1.Body},
+		{ID: dURL(id), Title: d1.Title, Text: "A body\n\n---\n\nComment by gopher:\n\ncomment\n\n---\n\nReply:\n\nreply"},
 		{ID: dURL(id2), Title: d2.Title, Text: d2.Body},
 	}
 	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(docs.Doc{}, "DBTime")); diff != "" {
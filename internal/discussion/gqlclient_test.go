@@ -37,6 +37,7 @@ func TestDiscussions(t *testing.T) {
 	want := []*Discussion{
 		{
 			URL:              "https://github.com/tatianab/scratch/discussions/51",
+			NodeID:           "D_kwDOHoUMN84AbzB_",
 			Number:           51,
 			Author:           github.User{Login: "tatianab"},
 			Title:            "A general discussion",
@@ -51,6 +52,7 @@ func TestDiscussions(t *testing.T) {
 		},
 		{
 			URL:              "https://github.com/tatianab/scratch/discussions/52",
+			NodeID:           "D_kwDOHoUMN84AbzCI",
 			Number:           52,
 			Author:           github.User{Login: "tatianab"},
 			Title:            "A third discussion",
@@ -65,6 +67,7 @@ func TestDiscussions(t *testing.T) {
 		},
 		{
 			URL:              "https://github.com/tatianab/scratch/discussions/50",
+			NodeID:           "D_kwDOHoUMN84AbzBx",
 			Number:           50,
 			Author:           github.User{Login: "tatianab"},
 			Title:            "Welcome to discussions",
@@ -274,8 +274,10 @@ func (d *discussion) convert() *Discussion {
 	if d.ActiveLockReason != nil {
 		activeLockReason = string(*d.ActiveLockReason)
 	}
+	nodeID, _ := d.ID.(string)
 	return &Discussion{
 		URL:              string(d.URL.String()),
+		NodeID:           nodeID,
 		Number:           int64(d.Number),
 		Author:           d.Author.convert(),
 		Title:            string(d.Title),
@@ -45,6 +45,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"testing"
 	"time"
 
 	"golang.org/x/oscar/internal/secret"
@@ -62,9 +63,13 @@ type Client struct {
 	slog *slog.Logger
 	db   storage.DB
 
+	testing bool // divert comments to testEdits (see [Client.PostComment])
+	dryRun  bool // see [Client.EnableDryRun]
+
 	testMu     sync.Mutex
 	testClient *TestingClient
 	testEvents map[string]json.RawMessage
+	testEdits  []*TestingEdit
 }
 
 // New creates a new client for making requests to the GitHub
@@ -75,9 +80,10 @@ type Client struct {
 // ("ghp_...").
 func New(ctx context.Context, lg *slog.Logger, sdb secret.DB, db storage.DB) *Client {
 	return &Client{
-		gql:  newGQLClient(authClient(ctx, sdb)),
-		slog: lg,
-		db:   db,
+		gql:     newGQLClient(authClient(ctx, sdb)),
+		slog:    lg,
+		db:      db,
+		testing: testing.Testing(),
 	}
 }
 
@@ -16,8 +16,7 @@ import (
 // Testing returns a TestingClient, which provides access to Client functionality
 // intended for testing.
 // Testing only returns a non-nil TestingClient in testing mode,
-// which is active if the current program is a test binary (that is, [testing.Testing] returns true)
-// or if [Client.EnableTesting] has been called.
+// which is active if the current program is a test binary (that is, [testing.Testing] returns true).
 // Otherwise, Testing returns nil.
 //
 // Each Client has only one TestingClient associated with it. Every call to Testing returns the same TestingClient.
@@ -34,6 +33,13 @@ func (c *Client) Testing() *TestingClient {
 	return c.testClient
 }
 
+// DisableTesting disables testing mode, so that comments are posted
+// to GitHub even in a test binary (see [Client.PostComment]).
+// It is meant for tests of other modes, such as dry-run mode.
+func (c *Client) DisableTesting() {
+	c.testing = false
+}
+
 // A TestingClient provides access to Client functionality intended for testing.
 //
 // See [Client.Testing] for a description of testing mode.
@@ -71,6 +77,7 @@ var issueID int64 = 1e9
 // Perhaps they should, but normally there is just one Client.
 func (tc *TestingClient) AddDiscussion(project string, d *Discussion) int64 {
 	id := atomic.AddInt64(&issueID, +1)
+	d.Number = id
 	d.URL = fmt.Sprintf("https://github.com/%s/discussions/%d", project, id)
 	tc.addEvent(d.URL, &Event{
 		Project:    project,
@@ -105,3 +112,19 @@ func (tc *TestingClient) AddComment(project string, disc int64, comment *Comment
 	})
 	return id
 }
+
+// A TestingEdit is a comment that would have been posted on a discussion
+// if the Client were not in testing mode.
+type TestingEdit struct {
+	URL  string // URL of the discussion
+	Body string // body of the comment
+}
+
+// Edits returns the comments that have been posted using [Client.PostComment].
+// These comments have not been posted on GitHub, only diverted into the [TestingClient].
+func (tc *TestingClient) Edits() []*TestingEdit {
+	tc.c.testMu.Lock()
+	defer tc.c.testMu.Unlock()
+
+	return tc.c.testEdits
+}
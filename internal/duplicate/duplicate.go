@@ -0,0 +1,560 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package duplicate implements posting about likely duplicate issues to GitHub.
+//
+// For each new issue, a [Poster] finds earlier issues in the same project
+// whose embeddings are close to the new issue's, asks an LLM whether
+// the new issue duplicates any of them, and if so, logs an action
+// that comments on the new issue and optionally adds a label to it.
+// See also package related, which posts about related documents
+// in general.
+package duplicate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/compose"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/seed"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// A Poster posts to GitHub about likely duplicate issues.
+type Poster struct {
+	slog          *slog.Logger
+	db            storage.DB
+	vdb           storage.VectorDB
+	github        *github.Client
+	docs          *docs.Corpus
+	llm           *llmapp.Client
+	projects      map[string]bool
+	plainText     map[string]bool   // projects to post plain-text comments in
+	feedback      map[string]string // project → feedback URL; see SetFeedbackURL
+	watcher       *timed.Watcher[*github.Event]
+	name          string
+	timeLimit     time.Time
+	ignores       []func(*github.Issue) bool
+	maxCandidates int
+	scoreCutoff   float64
+	seed          *seed.Seed // if non-nil, the seed for every run; see SetSeed
+	comment       bool
+	label         string
+	post          bool
+	avail         *llm.Availability // if non-nil, defer posts while the LLM is unavailable
+	composer      *compose.Composer // if non-nil, add comments to it; see SetComposer
+	// For the action log.
+	requireApproval bool
+	actionKind      string
+	logAction       actions.BeforeFunc
+}
+
+// New creates and returns a new Poster. It logs to lg, stores state in db,
+// watches for new GitHub issues using gh, looks up candidate duplicates in vdb,
+// reads the document content from docs, and verifies candidates using lc.
+// For the purposes of storing its own state, it uses the given name.
+// Future calls to New with the same name will use the same state.
+//
+// Use the [Poster] methods to configure the posting parameters
+// (especially [Poster.EnableProject] and [Poster.EnablePosts])
+// before calling [Poster.Run].
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, vdb storage.VectorDB, docs *docs.Corpus, lc *llmapp.Client, name string) *Poster {
+	p := &Poster{
+		slog:          lg,
+		db:            db,
+		vdb:           vdb,
+		github:        gh,
+		docs:          docs,
+		llm:           lc,
+		projects:      make(map[string]bool),
+		watcher:       gh.EventWatcher("duplicate.Poster:" + name),
+		name:          name,
+		timeLimit:     time.Now().Add(-defaultTooOld),
+		maxCandidates: defaultMaxCandidates,
+		scoreCutoff:   defaultScoreCutoff,
+		comment:       true,
+	}
+	// As with related.Poster, the action kind does not include the name,
+	// so that we only ever post to each issue once.
+	p.actionKind = "duplicate.Poster"
+	p.logAction = actions.Register(p.actionKind, &actioner{p})
+	return p
+}
+
+// SetTimeLimit controls how old an issue can be for the Poster to post to it.
+// Issues created before time t will be skipped.
+// The default is not to post to issues that are more than 48 hours old
+// at the time of the call to [New].
+func (p *Poster) SetTimeLimit(t time.Time) {
+	p.timeLimit = t
+}
+
+const defaultTooOld = 48 * time.Hour
+
+// SetMaxCandidates sets the maximum number of earlier issues
+// that the LLM is asked to compare with a new issue.
+// The default is 5.
+func (p *Poster) SetMaxCandidates(max int) {
+	p.maxCandidates = max
+}
+
+const defaultMaxCandidates = 5
+
+// SetMinScore sets the minimum vector search score that an earlier issue
+// must have to be considered a candidate duplicate.
+// The default is 0.9, which is stricter than the cutoff for related documents.
+func (p *Poster) SetMinScore(min float64) {
+	p.scoreCutoff = min
+}
+
+const defaultScoreCutoff = 0.9
+
+// SetSeed configures the Poster to use s as the seed for
+// every run, instead of a new random seed.
+// The seed breaks ties between equally similar earlier issues
+// when there are more than the maximum number of candidates.
+// Each run logs its seed, and each logged action records it,
+// so that SetSeed can be used to reproduce an earlier decision.
+func (p *Poster) SetSeed(s seed.Seed) {
+	p.seed = &s
+}
+
+// runSeed returns the seed to use for a run.
+func (p *Poster) runSeed() seed.Seed {
+	if p.seed != nil {
+		return *p.seed
+	}
+	return seed.New()
+}
+
+// SkipBodyContains configures the Poster to skip issues with a body containing
+// the given text.
+func (p *Poster) SkipBodyContains(text string) {
+	p.ignores = append(p.ignores, func(issue *github.Issue) bool {
+		return strings.Contains(issue.Body, text)
+	})
+}
+
+// SkipTitlePrefix configures the Poster to skip issues with a title starting
+// with the given prefix.
+func (p *Poster) SkipTitlePrefix(prefix string) {
+	p.ignores = append(p.ignores, func(issue *github.Issue) bool {
+		return strings.HasPrefix(issue.Title, prefix)
+	})
+}
+
+// SetLabel configures the Poster to add the given label (for example, "duplicate")
+// to issues that are likely duplicates.
+// By default, the Poster does not add a label.
+func (p *Poster) SetLabel(label string) {
+	p.label = label
+}
+
+// DisableComments configures the Poster not to comment on issues that are
+// likely duplicates, so that it only adds the label set by [Poster.SetLabel].
+func (p *Poster) DisableComments() {
+	p.comment = false
+}
+
+// EnableProject enables the Poster to post on issues in the given GitHub project (for example "golang/go").
+// See also [Poster.EnablePosts], which must also be called to post anything to GitHub.
+func (p *Poster) EnableProject(project string) {
+	p.projects[project] = true
+}
+
+// SetFeedbackURL sets the URL linked from comments in the project
+// as the place to leave detailed feedback about duplicate detection.
+// By default, comments only ask for emoji votes.
+func (p *Poster) SetFeedbackURL(project, url string) {
+	if p.feedback == nil {
+		p.feedback = make(map[string]string)
+	}
+	p.feedback[project] = url
+}
+
+// EnablePlainText configures the Poster to post plain-text comments
+// (see [github.PlainText]) on issues in the given GitHub project,
+// so that they read well with a screen reader.
+func (p *Poster) EnablePlainText(project string) {
+	if p.plainText == nil {
+		p.plainText = make(map[string]bool)
+	}
+	p.plainText[project] = true
+}
+
+// EnablePosts enables the Poster to post to GitHub.
+// If EnablePosts has not been called, [Poster.Run] logs what it would post but does not post the messages.
+// See also [Poster.EnableProject], which must also be called to set the projects being considered.
+func (p *Poster) EnablePosts() {
+	p.post = true
+}
+
+// RequireApproval configures the Poster to log actions that require approval.
+func (p *Poster) RequireApproval() {
+	p.requireApproval = true
+}
+
+// SetComposer configures the Poster to add its comments on issues
+// to c, which combines them with other bots' comments on the same
+// issue, instead of posting them itself.
+func (p *Poster) SetComposer(c *compose.Composer) {
+	p.composer = c
+}
+
+// DeferWhenUnavailable configures the Poster to defer, rather than skip,
+// issues that cannot be checked while a is reporting the LLM service
+// as unavailable.
+// When such an issue is found, [Poster.Run] stops without advancing past it,
+// so that it is retried on a later call.
+func (p *Poster) DeferWhenUnavailable(a *llm.Availability) {
+	p.avail = a
+}
+
+// An action has all the information needed to mark a GitHub issue
+// as a likely duplicate.
+type action struct {
+	Issue     *github.Issue
+	Duplicate string                      // URL of the issue that Issue likely duplicates
+	Changes   *github.IssueCommentChanges // comment to post; nil for none
+	Label     string                      // label to add; empty for none
+	Seed      seed.Seed                   // seed used to choose the candidates
+}
+
+// result is the result of applying an action.
+type result struct {
+	URL string // URL of new comment, if any
+}
+
+// Run runs a single round of posting to GitHub.
+// It scans all open issues that have been created since the last call to [Poster.Run]
+// using a Poster with the same name (see [New]).
+// Run skips closed issues, and it also skips pull requests.
+//
+// For each issue that matches the configured posting constraints
+// (see [Poster.EnableProject], [Poster.SetTimeLimit], [Poster.SkipBodyContains]
+// and [Poster.SkipTitlePrefix]), Run looks in the vector database for
+// earlier issues in the same project that are aligned closely enough with
+// the issue (see [Poster.SetMinScore] and [Poster.SetMaxCandidates]),
+// and asks the LLM whether the issue duplicates one of them.
+// If so, Run adds an action to the action log that will comment on the issue
+// and add a label to it (see [Poster.SetLabel] and [Poster.DisableComments]).
+//
+// Run logs each post to the [slog.Logger] passed to [New].
+// When [Poster.EnablePosts] has not been called, Run only logs the comments it would post.
+// Future calls to Run will reprocess the same issues and re-log the same comments.
+func (p *Poster) Run(ctx context.Context) error {
+	s := p.runSeed()
+	p.slog.Info("duplicate.Poster start", "name", p.name, "post", p.post, "latest", p.watcher.Latest(), "seed", s)
+	defer func() {
+		p.slog.Info("duplicate.Poster end", "name", p.name, "latest", p.watcher.Latest())
+	}()
+
+	defer p.watcher.Flush()
+	for e := range p.watcher.Recent() {
+		advance, err := p.logPostIssue(ctx, e, s)
+		if err != nil {
+			if p.avail != nil && !p.avail.Available() {
+				// The issue is probably not embedded yet, or it cannot be
+				// checked, because the LLM is down. Try again later.
+				p.slog.Warn("duplicate.Poster deferred (LLM unavailable)", "issue", e.Issue, "event", e, "error", err)
+				return nil
+			}
+			p.slog.Error("duplicate.Poster", "issue", e.Issue, "event", e, "error", err)
+			continue
+		}
+		if advance {
+			p.watcher.MarkOld(e.DBTime)
+			// Flush immediately to make sure we don't re-post if interrupted later in the loop.
+			p.watcher.Flush()
+		}
+	}
+	return nil
+}
+
+var (
+	errVectorSearchFailed = errors.New("vector search failed")
+	errCheckFailed        = errors.New("duplicate check failed")
+)
+
+// logPostIssue logs an action to mark the event's issue as a likely duplicate,
+// if it is one.
+// advance is true if the event should be considered handled,
+// which is when posting is enabled and either an action was logged
+// or the issue was found not to be a likely duplicate.
+// The seed s breaks ties when choosing candidates.
+func (p *Poster) logPostIssue(ctx context.Context, e *github.Event, s seed.Seed) (advance bool, _ error) {
+	if skip, reason := p.skip(e); skip {
+		p.slog.Info("duplicate.Poster skip", "name", p.name, "project",
+			e.Project, "issue", e.Issue, "reason", reason, "event", e)
+		return false, nil
+	}
+
+	// If an action has already been logged for this event, do nothing.
+	// This avoids an expensive vector search and LLM call.
+	if _, ok := actions.Get(p.db, p.actionKind, logKey(e)); ok {
+		p.slog.Info("duplicate.Poster already logged", "name", p.name, "project", e.Project, "issue", e.Issue, "event", e)
+		return p.post, nil
+	}
+
+	issue := e.Typed.(*github.Issue)
+	candidates, err := p.candidates(issue, s)
+	if err != nil {
+		return false, err
+	}
+	if len(candidates) == 0 {
+		p.slog.Info("duplicate.Poster found no candidates", "name", p.name, "project", e.Project, "issue", e.Issue)
+		return p.post, nil
+	}
+	var cdocs []*llmapp.Doc
+	for _, c := range candidates {
+		cdocs = append(cdocs, c.ToLLMDoc())
+	}
+	res, err := p.llm.CheckDuplicate(ctx, issue.ToLLMDoc(), cdocs)
+	if err != nil {
+		return false, fmt.Errorf("%w issue=%d: %v", errCheckFailed, e.Issue, err)
+	}
+	if res.Duplicate < 0 {
+		p.slog.Info("duplicate.Poster found no duplicate", "name", p.name, "project", e.Project, "issue", e.Issue, "explanation", res.Explanation)
+		return p.post, nil
+	}
+	dup := candidates[res.Duplicate]
+	act := &action{
+		Issue:     issue,
+		Duplicate: dup.HTMLURL,
+		Label:     p.label,
+		Seed:      s,
+	}
+	if p.comment {
+		body := comment(dup, res.Explanation, p.feedback[e.Project])
+		if p.plainText[e.Project] {
+			body = github.PlainText(body)
+		}
+		act.Changes = &github.IssueCommentChanges{Body: body}
+	}
+	p.slog.Info("duplicate.Poster post", "name", p.name, "project", e.Project, "issue", e.Issue, "seed", s, "duplicate", dup.Number, "explanation", res.Explanation)
+
+	if !p.post {
+		// Posting is disabled so we did not handle this issue.
+		return false, nil
+	}
+	p.logAction(p.db, logKey(e), storage.JSON(act), p.requireApproval)
+	return true, nil
+}
+
+// candidates returns the earlier issues in the same project whose
+// embeddings are closest to the issue's, most similar first,
+// using the seed s to break ties at the maximum number of candidates
+// (see [seed.Cap]).
+// It expects that there is already an entry for the issue in the
+// vector database.
+func (p *Poster) candidates(issue *github.Issue, s seed.Seed) ([]*github.Issue, error) {
+	u := issue.DocID()
+	vec, ok := p.vdb.Get(u)
+	if !ok {
+		return nil, fmt.Errorf("%w url=%s", errVectorSearchFailed, u)
+	}
+	created, err := time.Parse(time.RFC3339, issue.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	results := search.Vector(p.vdb, p.docs, &search.VectorRequest{
+		Options: search.Options{
+			Threshold:     p.scoreCutoff,
+			Limit:         p.maxCandidates + 5, // add a buffer for filters and ties
+			AllowKind:     []string{search.KindGitHubIssue},
+			Sources:       []string{issue.Project()},
+			CreatedBefore: created,
+			Info:          search.GitHubInfo(p.github),
+		},
+		Vector: vec,
+	})
+	type cand struct {
+		issue *github.Issue
+		score float64
+	}
+	var cands []cand
+	for _, r := range results {
+		c, err := p.github.LookupIssueURL(r.ID)
+		if err != nil || c.PullRequest != nil || c.Number == issue.Number {
+			continue
+		}
+		cands = append(cands, cand{c, r.Score})
+	}
+	cands = seed.Cap(s.Rand(u), cands, p.maxCandidates,
+		func(c cand) float64 { return c.score },
+		func(c cand) string { return c.issue.DocID() })
+	var issues []*github.Issue
+	for _, c := range cands {
+		issues = append(issues, c.issue)
+	}
+	return issues, nil
+}
+
+// comment returns the comment to post on an issue that likely
+// duplicates dup, for the given reason, linking to feedbackURL
+// (if not empty) for detailed feedback.
+func comment(dup *github.Issue, explanation, feedbackURL string) string {
+	var b strings.Builder
+	state := ""
+	if dup.State == "closed" {
+		state = " (closed)"
+	}
+	fmt.Fprintf(&b, "This issue is possibly a duplicate of #%d%s: [%s](%s)\n\n", dup.Number, state, markdownEscape(dup.Title), dup.HTMLURL)
+	if explanation != "" {
+		fmt.Fprintf(&b, "> %s\n\n", strings.ReplaceAll(strings.TrimSpace(explanation), "\n", "\n> "))
+	}
+	b.WriteString("If it is, please close this issue and continue the discussion there.\n")
+	if feedbackURL != "" {
+		fmt.Fprintf(&b, "\n<sub>(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](%s).)</sub>\n", feedbackURL)
+	} else {
+		b.WriteString("\n<sub>(Emoji vote if this was helpful or unhelpful.)</sub>\n")
+	}
+	return b.String()
+}
+
+type actioner struct {
+	p *Poster
+}
+
+func (ar *actioner) Run(ctx context.Context, data []byte) ([]byte, error) {
+	return ar.p.runFromActionLog(ctx, data)
+}
+
+func (ar *actioner) ForDisplay(data []byte) string {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	s := a.Issue.HTMLURL + "\nduplicate of " + a.Duplicate
+	if a.Label != "" {
+		s += "\nadd label " + a.Label
+	}
+	if a.Changes != nil {
+		s += "\n" + a.Changes.Body
+	}
+	return s
+}
+
+// runFromActionLog is called by actions.Run to execute an action.
+// It decodes the action, calls [Poster.runAction], then encodes the result.
+func (p *Poster) runFromActionLog(ctx context.Context, data []byte) ([]byte, error) {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	res, err := p.runAction(ctx, &a)
+	if err != nil {
+		return nil, err
+	}
+	return storage.JSON(res), nil
+}
+
+// runAction runs the given action.
+func (p *Poster) runAction(ctx context.Context, a *action) (*result, error) {
+	var res result
+	if a.Changes != nil && p.composer != nil {
+		p.composer.Add(a.Issue, "duplicate", a.Changes.Body)
+	} else if a.Changes != nil {
+		_, url, err := p.github.PostIssueComment(ctx, a.Issue, a.Changes)
+		if err != nil {
+			return nil, fmt.Errorf("duplicate.Poster: post comment on %s: %w", a.Issue.HTMLURL, err)
+		}
+		res.URL = url
+	}
+	if a.Label != "" {
+		// As in the labels package, read the existing labels and
+		// immediately write them back along with the new one.
+		issue, err := p.github.DownloadIssue(ctx, a.Issue.URL)
+		if err != nil {
+			return nil, fmt.Errorf("duplicate.Poster: download %s: %w", a.Issue.URL, err)
+		}
+		names := map[string]bool{a.Label: true}
+		for _, lab := range issue.Labels {
+			names[lab.Name] = true
+		}
+		labels := slices.Sorted(maps.Keys(names))
+		if err := p.github.EditIssue(ctx, a.Issue, &github.IssueChanges{Labels: &labels}); err != nil {
+			if res.URL != "" {
+				// The comment has been posted. Fail instead of
+				// deferring the action, which would post it again.
+				return nil, fmt.Errorf("duplicate.Poster: edit %s: %v", a.Issue.URL, err)
+			}
+			return nil, fmt.Errorf("duplicate.Poster: edit %s: %w", a.Issue.URL, err)
+		}
+	}
+	return &res, nil
+}
+
+// skip reports whether the event should be skipped and why.
+func (p *Poster) skip(e *github.Event) (_ bool, reason string) {
+	if !p.projects[e.Project] {
+		return true, fmt.Sprintf("project %s not enabled for this Poster", e.Project)
+	}
+	if e.API != "/issues" {
+		return true, fmt.Sprintf("wrong API %s (expected %s)", e.API, "/issues")
+	}
+	issue := e.Typed.(*github.Issue)
+	if issue.State == "closed" {
+		return true, "issue is closed"
+	}
+	if issue.PullRequest != nil {
+		return true, "pull request"
+	}
+	tm, err := time.Parse(time.RFC3339, issue.CreatedAt)
+	if err != nil {
+		p.slog.Error("duplicate.Poster parse createdat", "CreatedAt", issue.CreatedAt, "err", err)
+		return true, "could not parse createdat"
+	}
+	if tm.Before(p.timeLimit) {
+		return true, fmt.Sprintf("created=%s before time limit=%s", tm, p.timeLimit)
+	}
+	for i, ig := range p.ignores {
+		if ig(issue) {
+			return true, fmt.Sprintf("ignored by function ignores[%d]", i)
+		}
+	}
+	return false, ""
+}
+
+// logKey returns the key for the event in the action log.
+// This is only a portion of the database key; it is prefixed by the Poster's action
+// kind.
+func logKey(e *github.Event) []byte {
+	return ordered.Encode(e.Project, e.Issue)
+}
+
+// Latest returns the latest known DBTime marked old by the Poster's Watcher.
+func (p *Poster) Latest() timed.DBTime {
+	return p.watcher.Latest()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"_", `\_`,
+	"*", `\*`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"&", `\&`,
+)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
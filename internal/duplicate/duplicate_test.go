@@ -0,0 +1,204 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package duplicate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+var ctx = context.Background()
+
+const project = "rsc/markdown"
+
+// duplicateGenerator returns a content generator that reports
+// issue 19 as a duplicate of the first candidate and every other issue
+// as a duplicate of none of them.
+// It returns an error if fail is set.
+func duplicateGenerator(fail *bool) llm.ContentGenerator {
+	return llm.TestContentGenerator("duplicate-test", func(_ context.Context, _ *llm.Schema, parts []llm.Part) (string, error) {
+		if fail != nil && *fail {
+			return "", errors.New("LLM down")
+		}
+		if strings.Contains(string(parts[2].(llm.Text)), "/issues/19") {
+			return `{"duplicate": 0, "explanation": "Both ask for lowercase anchors."}`, nil
+		}
+		return `{"duplicate": -1, "explanation": "no match"}`, nil
+	})
+}
+
+func newTestPoster(t *testing.T, fail *bool) (*Poster, *github.Client) {
+	t.Helper()
+
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	gh.Testing().LoadTxtar("../testdata/markdown.txt")
+
+	dc := docs.New(lg, db)
+	docs.Sync(dc, gh)
+
+	vdb := storage.MemVectorDB(db, lg, "vecs")
+	embeddocs.Sync(ctx, lg, vdb, llm.QuoteEmbedder(), dc)
+
+	lc := llmapp.New(lg, duplicateGenerator(fail), db)
+	p := New(lg, db, gh, vdb, dc, lc, t.Name())
+	p.EnableProject(project)
+	p.SetTimeLimit(time.Time{})
+	return p, gh
+}
+
+// loggedActions returns the actions in the action log.
+func loggedActions(t *testing.T, p *Poster) []*action {
+	t.Helper()
+	var acts []*action
+	for e := range actions.ScanAfter(p.slog, p.db, time.Time{}, nil) {
+		var a action
+		if err := json.Unmarshal(e.Action, &a); err != nil {
+			t.Fatal(err)
+		}
+		acts = append(acts, &a)
+	}
+	return acts
+}
+
+func TestRun(t *testing.T) {
+	check := testutil.Checker(t)
+	p, gh := newTestPoster(t, nil)
+
+	// Without EnablePosts, nothing is logged.
+	check(p.Run(ctx))
+	if acts := loggedActions(t, p); len(acts) != 0 {
+		t.Fatalf("logged %d actions before EnablePosts, want 0", len(acts))
+	}
+
+	p.EnablePosts()
+	p.SetLabel("duplicate")
+	check(p.Run(ctx))
+	acts := loggedActions(t, p)
+	if len(acts) != 1 {
+		t.Fatalf("logged %d actions, want 1", len(acts))
+	}
+	a := acts[0]
+	if a.Issue.Number != 19 || a.Duplicate != "https://github.com/rsc/markdown/issues/2" || a.Label != "duplicate" {
+		t.Errorf("action = {%d, %s, %q}, want {19, .../issues/2, duplicate}", a.Issue.Number, a.Duplicate, a.Label)
+	}
+	want := "This issue is possibly a duplicate of #2 (closed): [allow capital X in task list items](https://github.com/rsc/markdown/issues/2)\n\n" +
+		"> Both ask for lowercase anchors.\n\n" +
+		"If it is, please close this issue and continue the discussion there.\n"
+	if a.Changes == nil || !strings.HasPrefix(a.Changes.Body, want) {
+		t.Errorf("comment:\n%v\nwant prefix:\n%s", a.Changes, want)
+	}
+	// Without a feedback URL, the comment only asks for votes.
+	if a.Changes != nil && !strings.HasSuffix(a.Changes.Body, "<sub>(Emoji vote if this was helpful or unhelpful.)</sub>\n") {
+		t.Errorf("comment footer:\n%s\nwant only a request for votes", a.Changes.Body)
+	}
+
+	// Running the action posts the comment and adds the label.
+	check(actions.Run(ctx, p.slog, p.db))
+	edits := gh.Testing().Edits()
+	if len(edits) != 2 {
+		t.Fatalf("got %d edits, want 2: %v", len(edits), edits)
+	}
+	if edits[0].IssueCommentChanges == nil || edits[0].IssueCommentChanges.Body != a.Changes.Body {
+		t.Errorf("first edit = %v, want comment", edits[0])
+	}
+	if diff := cmp.Diff(&github.IssueChanges{Labels: &[]string{"duplicate"}}, edits[1].IssueChanges); diff != "" {
+		t.Errorf("label edit (-want +got):\n%s", diff)
+	}
+
+	// A second run does nothing.
+	check(p.Run(ctx))
+	if acts := loggedActions(t, p); len(acts) != 1 {
+		t.Errorf("logged %d actions after second run, want 1", len(acts))
+	}
+}
+
+func TestLabelOnly(t *testing.T) {
+	check := testutil.Checker(t)
+	p, _ := newTestPoster(t, nil)
+	p.EnablePosts()
+	p.SetLabel("duplicate")
+	p.DisableComments()
+	check(p.Run(ctx))
+	acts := loggedActions(t, p)
+	if len(acts) != 1 || acts[0].Changes != nil || acts[0].Label != "duplicate" {
+		t.Fatalf("actions = %v, want one label-only action", acts)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	check := testutil.Checker(t)
+	p, _ := newTestPoster(t, nil)
+	p.EnablePosts()
+	p.EnablePlainText(project)
+	p.SetFeedbackURL(project, "https://example.com/duplicates")
+	check(p.Run(ctx))
+	acts := loggedActions(t, p)
+	if len(acts) != 1 || acts[0].Changes == nil {
+		t.Fatalf("actions = %v, want one comment action", acts)
+	}
+	want := "This issue is possibly a duplicate of #2 (closed): allow capital X in task list items (https://github.com/rsc/markdown/issues/2)\n\n" +
+		"> Both ask for lowercase anchors.\n\n" +
+		"If it is, please close this issue and continue the discussion there.\n\n" +
+		"(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in this discussion (https://example.com/duplicates).)\n"
+	if got := acts[0].Changes.Body; got != want {
+		t.Errorf("comment:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNoCandidates(t *testing.T) {
+	check := testutil.Checker(t)
+	p, _ := newTestPoster(t, nil)
+	p.EnablePosts()
+	p.SetMinScore(2.0) // impossible
+	check(p.Run(ctx))
+	if acts := loggedActions(t, p); len(acts) != 0 {
+		t.Errorf("logged %d actions, want 0", len(acts))
+	}
+}
+
+func TestDeferWhenUnavailable(t *testing.T) {
+	check := testutil.Checker(t)
+	fail := true
+	p, _ := newTestPoster(t, &fail)
+	p.EnablePosts()
+	avail := llm.NewAvailability()
+	p.DeferWhenUnavailable(avail)
+	avail.SetFailureThreshold(1)
+	avail.Record(&llm.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"})
+
+	// Run stops without advancing past the first issue.
+	check(p.Run(ctx))
+	if acts := loggedActions(t, p); len(acts) != 0 {
+		t.Fatalf("logged %d actions while LLM down, want 0", len(acts))
+	}
+
+	fail = false
+	avail.Record(nil)
+	check(p.Run(ctx))
+	var issues []int64
+	for _, a := range loggedActions(t, p) {
+		issues = append(issues, a.Issue.Number)
+	}
+	if !slices.Equal(issues, []int64{19}) {
+		t.Errorf("actions for issues %v, want [19]", issues)
+	}
+}
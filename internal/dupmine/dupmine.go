@@ -0,0 +1,228 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dupmine mines the history of GitHub issues for
+// duplicate relationships, building a labeled dataset of
+// (duplicate, original) issue pairs for evaluating and training
+// duplicate detection (see, for example, package embedeval).
+//
+// A closed issue is a duplicate of issue N if one of its comments
+// says so. Comments of the form "Duplicate of #N", which GitHub
+// itself uses to mark duplicates, are parsed directly. Other comments
+// that mention duplication and refer to an issue, such as
+// "Closing as a dup of #N.", are parsed by an LLM.
+// If several comments name an original, the last one wins.
+//
+// The pairs are stored in the database:
+//
+//	(dupmine.Pair, $project, $duplicate) -> [Pair], encoded by [storage.EncodeRecord]
+package dupmine
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+)
+
+// A Miner mines GitHub issues for duplicate relationships.
+type Miner struct {
+	slog     *slog.Logger
+	db       storage.DB
+	github   *github.Client
+	cgen     llm.ContentGenerator
+	projects map[string]bool
+	watcher  *timed.Watcher[*github.Event]
+}
+
+// New returns a new Miner that reads issues using gh, stores the
+// pairs it finds in db, and uses cgen to parse comments that
+// mention duplication informally.
+// If cgen is nil, only "Duplicate of #N" comments are parsed.
+//
+// Use [Miner.EnableProject] to configure the projects to mine
+// before calling [Miner.Run].
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, cgen llm.ContentGenerator) *Miner {
+	return &Miner{
+		slog:     lg,
+		db:       db,
+		github:   gh,
+		cgen:     cgen,
+		projects: make(map[string]bool),
+		watcher:  gh.EventWatcher("dupmine.Miner"),
+	}
+}
+
+// EnableProject enables the Miner to mine the issues
+// in the given GitHub project (for example "golang/go").
+func (m *Miner) EnableProject(project string) {
+	m.projects[project] = true
+}
+
+//go:generate go run golang.org/x/oscar/internal/devtools/cmd/kindgen -type Pair -kind dupmine.Pair -key Project,Duplicate
+
+// A Pair records that an issue is a duplicate of another.
+type Pair struct {
+	Project   string
+	Duplicate int64     // the duplicate issue
+	Original  int64     // the issue it duplicates
+	Source    string    // how the pair was found: "comment" for "Duplicate of #N", or "llm"
+	URL       string    // the comment naming the original
+	Time      time.Time // when the pair was recorded
+}
+
+// Run mines the issues in the enabled projects that were closed,
+// or commented on after being closed, since the last call to Run.
+// The first time it is called, Run mines the whole history.
+//
+// If the LLM fails, Run returns the error and resumes from
+// the failed issue at the next call.
+func (m *Miner) Run(ctx context.Context) error {
+	m.slog.Info("dupmine.Miner start", "latest", m.watcher.Latest())
+	defer func() {
+		m.slog.Info("dupmine.Miner end", "latest", m.watcher.Latest())
+	}()
+
+	defer m.watcher.Flush()
+	mined := make(map[string]bool) // issues mined in this run, by URL
+	for e := range m.watcher.Recent() {
+		if m.projects[e.Project] && (e.API == "/issues" || e.API == "/issues/comments") {
+			iss, err := github.LookupIssue(m.db, e.Project, e.Issue)
+			if err == nil && !mined[iss.URL] && iss.State == "closed" && iss.PullRequest == nil {
+				if err := m.mine(ctx, iss); err != nil {
+					return err
+				}
+				mined[iss.URL] = true
+			}
+		}
+		m.watcher.MarkOld(e.DBTime)
+	}
+	return nil
+}
+
+// mine records the original of the closed issue,
+// as named by the last of its comments that names one.
+func (m *Miner) mine(ctx context.Context, iss *github.Issue) error {
+	project := iss.Project()
+	var p *Pair
+	for c := range m.github.Comments(iss) {
+		orig, source, err := m.parse(ctx, project, iss.Number, c.Body)
+		if err != nil {
+			return fmt.Errorf("dupmine: %s#%d: %w", project, iss.Number, err)
+		}
+		if orig == 0 || orig == iss.Number {
+			continue
+		}
+		p = &Pair{
+			Project:   project,
+			Duplicate: iss.Number,
+			Original:  orig,
+			Source:    source,
+			URL:       c.HTMLURL,
+		}
+	}
+	if p == nil {
+		return nil
+	}
+	if old, ok := getPair(m.db, project, iss.Number); ok && old.Original == p.Original && old.URL == p.URL {
+		return nil
+	}
+	m.slog.Info("dupmine.Miner found duplicate", "project", project, "issue", iss.Number, "original", p.Original, "source", p.Source)
+	p.Time = time.Now()
+	setPair(m.db, p)
+	return nil
+}
+
+var (
+	// dupOfRE matches a "Duplicate of" line, capturing the reference.
+	dupOfRE = regexp.MustCompile(`(?im)^\s*duplicate of\s+(#\d+|https://github\.com/\S+/issues/\d+)\b`)
+	// mentionRE matches words that suggest a comment is about duplication.
+	mentionRE = regexp.MustCompile(`(?i)\b(dup|dupe|dups|dupes|duplicate|duplicates|duplicated)\b`)
+	// refRE matches issue references.
+	refRE = regexp.MustCompile(`#\d+|/issues/\d+`)
+)
+
+// parse returns the number of the issue that the comment body,
+// on the given issue, says the issue duplicates, and the source
+// of the information ("comment" or "llm").
+// It returns 0 if the body does not name an original.
+func (m *Miner) parse(ctx context.Context, project string, issue int64, body string) (int64, string, error) {
+	if matches := dupOfRE.FindAllStringSubmatch(body, -1); matches != nil {
+		// An explicit marker; if it names an issue in
+		// another project, there is no original here.
+		var orig int64
+		for _, match := range matches {
+			if n, ok := issueRef(project, match[1]); ok {
+				orig = n
+			}
+		}
+		return orig, "comment", nil
+	}
+	if m.cgen == nil || !mentionRE.MatchString(body) || !refRE.MatchString(body) {
+		return 0, "", nil
+	}
+	var res llmResponse
+	prompt := fmt.Sprintf(llmPrompt, project, issue, body)
+	if err := llm.GenerateJSON(ctx, m.cgen, nil, &res, []llm.Part{llm.Text(prompt)}); err != nil {
+		return 0, "", err
+	}
+	if !res.Duplicate || res.Original <= 0 {
+		return 0, "", nil
+	}
+	return res.Original, "llm", nil
+}
+
+// issueRef returns the issue number of ref, of the form #N or
+// https://github.com/project/issues/N, if it refers to an issue
+// in the project.
+func issueRef(project, ref string) (int64, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		var ok bool
+		if ref, ok = strings.CutPrefix(ref, "https://github.com/"+project+"/issues/"); !ok {
+			return 0, false
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimPrefix(ref, "#"), 10, 64)
+	return n, err == nil
+}
+
+// llmResponse is the response of the LLM to [llmPrompt].
+type llmResponse struct {
+	Duplicate bool  `description:"whether the comment says that the issue is a duplicate of an earlier issue in the same project"`
+	Original  int64 `description:"the number of the issue that the issue duplicates, or 0 if none"`
+}
+
+// llmPrompt asks the LLM whether a comment marks an issue as a duplicate.
+// Its arguments are the project, the issue number and the comment.
+const llmPrompt = `The following is a comment on issue #%[2]d in the GitHub project %[1]s,
+which was later closed.
+Does the comment say that issue #%[2]d is a duplicate of another issue in %[1]s,
+and if so, which one?
+Answer no if the comment only mentions similar issues,
+or says that another issue is a duplicate of #%[2]d.
+
+Comment:
+%[3]s
+`
+
+// Lookup returns the pair recording the original of the
+// duplicate issue in the project, if any.
+func Lookup(db storage.DB, project string, duplicate int64) (*Pair, bool) {
+	return getPair(db, project, duplicate)
+}
+
+// Pairs returns an iterator over the pairs mined in the project,
+// in order of duplicate issue number.
+func Pairs(db storage.DB, project string) iter.Seq[*Pair] {
+	return scanPairByProject(db, project)
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dupmine
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+var ctx = context.Background()
+
+const project = "golang/go"
+
+func TestMiner(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	tc := gh.Testing()
+
+	var prompts []string
+	llmErr := error(nil)
+	cgen := llm.TestContentGenerator("test", func(_ context.Context, _ *llm.Schema, parts []llm.Part) (string, error) {
+		if llmErr != nil {
+			return "", llmErr
+		}
+		p := string(parts[0].(llm.Text))
+		prompts = append(prompts, p)
+		if strings.Contains(p, "dup of #2") {
+			return `{"Duplicate": true, "Original": 2}`, nil
+		}
+		return `{"Duplicate": false, "Original": 0}`, nil
+	})
+
+	for n := range int64(9) {
+		tc.AddIssue(project, &github.Issue{Number: n + 1, State: "closed"})
+	}
+	tc.AddIssue(project, &github.Issue{Number: 10, State: "open"})
+	tc.AddIssue(project, &github.Issue{Number: 11, State: "closed", PullRequest: new(struct{})})
+	tc.AddIssue("other/repo", &github.Issue{Number: 1, State: "closed"})
+	tc.AddIssueComment(project, 3, &github.IssueComment{Body: "Duplicate of #1"})
+	tc.AddIssueComment(project, 4, &github.IssueComment{Body: "Duplicate of #1"})
+	tc.AddIssueComment(project, 4, &github.IssueComment{Body: "Oops.\n\nDuplicate of https://github.com/golang/go/issues/2\n"})
+	tc.AddIssueComment(project, 5, &github.IssueComment{Body: "Closing as a dup of #2."})
+	tc.AddIssueComment(project, 6, &github.IssueComment{Body: "#7 looks like a duplicate of this one."})
+	tc.AddIssueComment(project, 7, &github.IssueComment{Body: "Duplicate of https://github.com/other/repo/issues/1"})
+	tc.AddIssueComment(project, 8, &github.IssueComment{Body: "Not a duplicate; no reference."})
+	tc.AddIssueComment(project, 10, &github.IssueComment{Body: "Duplicate of #1"})
+	tc.AddIssueComment(project, 11, &github.IssueComment{Body: "Duplicate of #1"})
+	tc.AddIssueComment("other/repo", 1, &github.IssueComment{Body: "Duplicate of #1"})
+
+	m := New(lg, db, gh, cgen)
+	m.EnableProject(project)
+	check(m.Run(ctx))
+
+	want := []*Pair{
+		{Project: project, Duplicate: 3, Original: 1, Source: "comment", URL: "https://github.com/golang/go/issues/3#issuecomment-10000000001"},
+		{Project: project, Duplicate: 4, Original: 2, Source: "comment", URL: "https://github.com/golang/go/issues/4#issuecomment-10000000003"},
+		{Project: project, Duplicate: 5, Original: 2, Source: "llm", URL: "https://github.com/golang/go/issues/5#issuecomment-10000000004"},
+	}
+	ignoreTime := cmpopts.IgnoreFields(Pair{}, "Time")
+	if diff := cmp.Diff(want, slices.Collect(Pairs(db, project)), ignoreTime); diff != "" {
+		t.Errorf("Pairs (-want, +got):\n%s", diff)
+	}
+	if len(prompts) != 2 {
+		t.Errorf("LLM called %d times, want 2 (issues 5 and 6)", len(prompts))
+	}
+	if p, ok := Lookup(db, project, 5); !ok || p.Original != 2 {
+		t.Errorf("Lookup(5) = %+v, %v, want original 2", p, ok)
+	}
+
+	// A comment after the issue is closed.
+	// LLM errors stop the run and are retried.
+	tc.AddIssueComment(project, 9, &github.IssueComment{Body: "Duplicate of #8"})
+	tc.AddIssueComment(project, 1, &github.IssueComment{Body: "Seems a dupe of #8"})
+	llmErr = errors.New("LLM down")
+	if err := m.Run(ctx); !errors.Is(err, llmErr) {
+		t.Fatalf("Run with LLM down: err = %v, want %v", err, llmErr)
+	}
+	if _, ok := Lookup(db, project, 9); !ok {
+		t.Errorf("Lookup(9) failed after run with LLM down")
+	}
+	llmErr = nil
+	prompts = nil
+	check(m.Run(ctx))
+	if len(prompts) != 1 {
+		t.Errorf("LLM called %d times on retry, want 1", len(prompts))
+	}
+	if _, ok := Lookup(db, project, 1); ok {
+		t.Errorf("Lookup(1) succeeded, want no pair")
+	}
+}
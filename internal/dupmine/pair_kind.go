@@ -0,0 +1,79 @@
+// Code generated by "kindgen -type Pair -kind dupmine.Pair -key Project,Duplicate"; DO NOT EDIT.
+
+package dupmine
+
+import (
+	"iter"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// pairKind is the key kind of a stored [Pair]:
+//
+//	(dupmine.Pair, Project, Duplicate) -> [Pair]
+const pairKind = "dupmine.Pair"
+
+// pairVersion is the encoding version of a stored [Pair].
+const pairVersion = 1
+
+// pairKey returns the database key of the [Pair] with the given key fields.
+func pairKey(project string, duplicate int64) []byte {
+	return ordered.Encode(pairKind, project, duplicate)
+}
+
+// getPair returns the stored [Pair] with the given key fields, if any.
+func getPair(db storage.DB, project string, duplicate int64) (*Pair, bool) {
+	val, ok := db.Get(pairKey(project, duplicate))
+	if !ok {
+		return nil, false
+	}
+	return decodePair(db, val), true
+}
+
+// setPair stores x under the key made from its key fields.
+func setPair(db storage.DB, x *Pair) {
+	db.Set(pairKey(x.Project, x.Duplicate), storage.EncodeRecord(pairVersion, x))
+}
+
+// deletePair deletes the stored [Pair] with the given key fields, if any.
+func deletePair(db storage.DB, project string, duplicate int64) {
+	db.Delete(pairKey(project, duplicate))
+}
+
+// scanPair returns the stored [Pair] records, in key order.
+func scanPair(db storage.DB) iter.Seq[*Pair] {
+	return scanPairRange(db, ordered.Encode(pairKind), ordered.Encode(pairKind, ordered.Inf))
+}
+
+// scanPairByProject returns the stored [Pair] records
+// with the given Project, in key order.
+func scanPairByProject(db storage.DB, project string) iter.Seq[*Pair] {
+	return scanPairRange(db, ordered.Encode(pairKind, project), ordered.Encode(pairKind, project, ordered.Inf))
+}
+
+// scanPairRange returns the stored [Pair] records
+// with keys in the range [start, end], in key order.
+func scanPairRange(db storage.DB, start, end []byte) iter.Seq[*Pair] {
+	return func(yield func(*Pair) bool) {
+		for _, val := range db.Scan(start, end) {
+			if !yield(decodePair(db, val())) {
+				return
+			}
+		}
+	}
+}
+
+// decodePair decodes a stored [Pair].
+func decodePair(db storage.DB, val []byte) *Pair {
+	var x Pair
+	v, err := storage.DecodeRecord(val, &x)
+	if err != nil {
+		// unreachable unless database corruption
+		db.Panic("decode dupmine.Pair", "err", err)
+	}
+	if v > pairVersion {
+		db.Panic("decode dupmine.Pair: stored with newer encoding", "version", v, "known", pairVersion)
+	}
+	return &x
+}
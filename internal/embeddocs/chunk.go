@@ -0,0 +1,248 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embeddocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// A Chunker splits long documents into chunks that fit in the
+// input limit of an embedding model. Embedding models truncate
+// longer inputs, so without chunking the end of a long issue
+// or documentation page would never match a search.
+//
+// A Chunker splits Markdown text at headings first, so that chunks
+// follow the structure of the document, and then splits sections
+// that are still too long into windows of words that overlap,
+// so that text near a window boundary appears in full in a chunk.
+type Chunker struct {
+	maxTokens int
+	overlap   int
+}
+
+// Default chunk sizes, in tokens as estimated by [llm.EstimateTokens].
+// The estimate is generous, and embedding models accept at least
+// 2048 tokens, so DefaultChunkTokens leaves room for the title.
+const (
+	DefaultChunkTokens  = 1024
+	DefaultChunkOverlap = 128
+)
+
+// NewChunker returns a new Chunker that makes chunks of at most
+// maxTokens tokens, including the title, overlapping by about
+// overlap tokens. The overlap is limited to half the chunk size.
+func NewChunker(maxTokens, overlap int) *Chunker {
+	return &Chunker{maxTokens: maxTokens, overlap: min(overlap, maxTokens/2)}
+}
+
+// A Chunk is a part of a document to embed.
+type Chunk struct {
+	Title string // document title, followed by the chunk's headings, if any
+	Text  string // text of the chunk
+}
+
+// tokens returns the estimated number of tokens in s.
+func tokens(s string) int {
+	return llm.EstimateTokens([]llm.Part{llm.Text(s)})
+}
+
+// Split splits the document with the given title and text into chunks.
+// A document that fits in a chunk is returned as a single chunk
+// with the original title and text.
+func (c *Chunker) Split(title, text string) []Chunk {
+	max := max(1, c.maxTokens-tokens(title))
+	if tokens(text) <= max {
+		return []Chunk{{Title: title, Text: text}}
+	}
+	var (
+		chunks []Chunk
+		cur    *Chunk // chunk being built from whole sections
+		n      int    // tokens in cur
+	)
+	flush := func() {
+		if cur != nil {
+			chunks = append(chunks, *cur)
+			cur, n = nil, 0
+		}
+	}
+	for _, s := range sections(text) {
+		stitle := title
+		if len(s.headings) > 0 {
+			stitle += " > " + strings.Join(s.headings, " > ")
+		}
+		t := tokens(s.text)
+		if t <= max {
+			// Merge small sections.
+			if cur != nil && n+t <= max {
+				cur.Text += s.text
+				n += t
+				continue
+			}
+			flush()
+			cur, n = &Chunk{Title: stitle, Text: s.text}, t
+			continue
+		}
+		flush()
+		for _, w := range c.windows(s.text, max) {
+			chunks = append(chunks, Chunk{Title: stitle, Text: w})
+		}
+	}
+	flush()
+	return chunks
+}
+
+// A section is a part of a Markdown document
+// starting at a heading (except for the first).
+type section struct {
+	headings []string // the heading and those above it, outermost first
+	text     string
+}
+
+var (
+	headingRE = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+	fenceRE   = regexp.MustCompile("^[ ]{0,3}(```|~~~)")
+)
+
+// sections splits the Markdown text at its headings,
+// ignoring lines in fenced code blocks.
+func sections(text string) []section {
+	var (
+		ss      []section
+		stack   []string // headings by level
+		start   int      // start of the current section
+		fenced  bool
+		current []string
+	)
+	for off := 0; off < len(text); {
+		end := strings.IndexByte(text[off:], '\n') + 1
+		if end == 0 {
+			end = len(text) - off
+		}
+		line := strings.TrimRight(text[off:off+end], "\r\n")
+		if fenceRE.MatchString(line) {
+			fenced = !fenced
+		} else if m := headingRE.FindStringSubmatch(line); m != nil && !fenced {
+			if off > start {
+				ss = append(ss, section{current, text[start:off]})
+			}
+			level := len(m[1])
+			stack = append(stack[:min(len(stack), level-1)], m[2])
+			current = append([]string(nil), stack...)
+			start = off
+		}
+		off += end
+	}
+	if start < len(text) {
+		ss = append(ss, section{current, text[start:]})
+	}
+	return ss
+}
+
+var wordRE = regexp.MustCompile(`\S+`)
+
+// windows splits text into windows of at most max tokens
+// that overlap by about c.overlap tokens.
+// Each window is a substring of text.
+func (c *Chunker) windows(text string, max int) []string {
+	words := wordRE.FindAllStringIndex(text, -1)
+	toks := make([]int, len(words))
+	for i, w := range words {
+		toks[i] = tokens(text[w[0]:w[1]]) + 1 // and the space before it
+	}
+	var ws []string
+	for i := 0; i < len(words); {
+		j, n := i, 0
+		for j < len(words) && (j == i || n+toks[j] <= max) {
+			n += toks[j]
+			j++
+		}
+		ws = append(ws, text[words[i][0]:words[j-1][1]])
+		if j == len(words) {
+			break
+		}
+		// Back up to overlap the next window with this one.
+		k, ov := j, 0
+		for k-1 > i && ov+toks[k-1] <= c.overlap {
+			k--
+			ov += toks[k]
+		}
+		i = k
+	}
+	return ws
+}
+
+// chunkMarker separates a document ID from
+// the number of a chunk in the chunk's ID.
+const chunkMarker = "#chunk-"
+
+// ChunkID returns the vector ID of the i'th chunk
+// of the document with the given ID.
+func ChunkID(id string, i int) string {
+	return fmt.Sprintf("%s%s%d", id, chunkMarker, i)
+}
+
+// Parent returns the ID of the document of the chunk with the given
+// vector ID, and true, or returns id and false if id is not a chunk.
+// Chunk parents are recorded in db by [Index.Sync] and [Index.Migrate].
+func Parent(db storage.DB, id string) (string, bool) {
+	if !strings.Contains(id, chunkMarker) {
+		return id, false
+	}
+	parent, ok := db.Get(ordered.Encode(chunkParentKind, id))
+	if !ok {
+		return id, false
+	}
+	return string(parent), true
+}
+
+// Database entries for chunks:
+//
+//   - (embeddocs.ChunkParent, $chunkid) -> $id: the document of a chunk
+//   - (embeddocs.ChunkCount, $namespace, $id) -> JSON int: the number
+//     of chunks of a document in a namespace, if more than 1
+const (
+	chunkParentKind = "embeddocs.ChunkParent"
+	chunkCountKind  = "embeddocs.ChunkCount"
+)
+
+// chunkCount returns the number of chunks of the document
+// with the given ID stored in the namespace.
+func chunkCount(db storage.DB, namespace, id string) int {
+	data, ok := db.Get(ordered.Encode(chunkCountKind, namespace, id))
+	if !ok {
+		return 0
+	}
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		// unreachable unless db corruption
+		db.Panic("embeddocs chunk count decode", "id", id, "err", err)
+	}
+	return n
+}
+
+// mean returns the normalized mean of the vectors,
+// which stands for the whole document of the chunks.
+func mean(vecs []llm.Vector) llm.Vector {
+	m := make(llm.Vector, len(vecs[0]))
+	for _, v := range vecs {
+		for i := range min(len(m), len(v)) {
+			m[i] += v[i]
+		}
+	}
+	if d := math.Sqrt(m.Dot(m)); d > 0 {
+		for i := range m {
+			m[i] /= float32(d)
+		}
+	}
+	return m
+}
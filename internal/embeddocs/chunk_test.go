@@ -0,0 +1,169 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embeddocs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// words returns n distinct words.
+func words(prefix string, n int) string {
+	var ws []string
+	for i := range n {
+		ws = append(ws, fmt.Sprintf("%s%d", prefix, i))
+	}
+	return strings.Join(ws, " ")
+}
+
+func TestChunkerShort(t *testing.T) {
+	c := NewChunker(100, 10)
+	got := c.Split("title", "# Heading\n\nshort text")
+	want := []Chunk{{Title: "title", Text: "# Heading\n\nshort text"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Split mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestChunkerHeadings(t *testing.T) {
+	c := NewChunker(60, 5)
+	text := "intro\n\n" +
+		"# A\n\nsmall a\n\n" +
+		"## B\n\nsmall b\n\n" +
+		"# C\n\n" + words("c", 40) + "\n\n" +
+		"```\n# not a heading\n```\n"
+	got := c.Split("T", text)
+	var titles []string
+	for _, ch := range got {
+		titles = append(titles, ch.Title)
+		if n := tokens(ch.Title) + tokens(ch.Text); n > 60 {
+			t.Errorf("chunk %q has %d tokens, want <= 60", ch.Title, n)
+		}
+	}
+	// The small sections are merged; C is split.
+	if len(titles) < 3 || titles[0] != "T" || slices.ContainsFunc(titles[1:], func(s string) bool { return s != "T > C" }) {
+		t.Errorf("chunk titles = %q, want T, then T > C at least twice", titles)
+	}
+	if !strings.HasPrefix(got[0].Text, "intro") || !strings.Contains(got[0].Text, "small b") {
+		t.Errorf("first chunk = %q, want intro through B", got[0].Text)
+	}
+	if !strings.Contains(got[len(got)-1].Text, "# not a heading") {
+		t.Errorf("last chunk = %q, want the code block", got[len(got)-1].Text)
+	}
+}
+
+func TestChunkerWindows(t *testing.T) {
+	c := NewChunker(50, 10)
+	text := words("w", 200)
+	chunks := c.Split("", text)
+	if len(chunks) < 4 {
+		t.Fatalf("Split made %d chunks, want at least 4", len(chunks))
+	}
+	var prevLast string
+	for i, ch := range chunks {
+		if n := tokens(ch.Text); n > 50 {
+			t.Errorf("chunk %d has %d tokens, want <= 50", i, n)
+		}
+		ws := strings.Fields(ch.Text)
+		if i > 0 && !slices.Contains(ws, prevLast) {
+			t.Errorf("chunk %d does not overlap chunk %d: %q does not contain %q", i, i-1, ch.Text, prevLast)
+		}
+		prevLast = ws[len(ws)-1]
+	}
+	if prevLast != "w199" {
+		t.Errorf("last word = %q, want w199", prevLast)
+	}
+}
+
+func TestIndexChunks(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	vdb := storage.MemVectorDB(db, lg, "gaby")
+	open := func(string) (storage.VectorDB, error) { return vdb, nil }
+	dc := docs.New(lg, db)
+	long := words("alpha", 40) + "\n\n" + words("beta", 40)
+	dc.Add("long", "", long)
+	dc.Add("short", "", "beta0 beta1")
+
+	ix := NewIndex(lg, db, "gaby", "quote", open)
+	ix.SetEmbedder("quote", llm.QuoteEmbedder())
+	ix.SetChunker(NewChunker(100, 10))
+	check(ix.Sync(ctx, dc))
+
+	n := chunkCount(db, "gaby", "long")
+	if n < 2 {
+		t.Fatalf("long doc has %d chunks, want at least 2", n)
+	}
+	for i := range n {
+		id := ChunkID("long", i)
+		if _, ok := vdb.Get(id); !ok {
+			t.Errorf("%s missing", id)
+		}
+		if p, ok := Parent(db, id); !ok || p != "long" {
+			t.Errorf("Parent(%s) = %q, %v, want long, true", id, p, ok)
+		}
+	}
+	if _, ok := ix.Get("long"); !ok {
+		t.Error("long doc has no vector of its own")
+	}
+	if p, ok := Parent(db, "short"); ok || p != "short" {
+		t.Errorf("Parent(short) = %q, %v, want short, false", p, ok)
+	}
+
+	// The chunks match, but only their document is returned.
+	vecs, err := ix.EmbedDocs(ctx, []llm.EmbedDoc{{Text: words("beta", 40)}})
+	check(err)
+	var ids []string
+	for _, r := range ix.Search(vecs[0], 3, nil) {
+		ids = append(ids, r.ID)
+	}
+	slices.Sort(ids)
+	if want := []string{"long", "short"}; !slices.Equal(ids, want) {
+		t.Errorf("Search = %v, want %v", ids, want)
+	}
+	ids = nil
+	for id := range ix.All() {
+		ids = append(ids, id)
+	}
+	if want := []string{"long", "short"}; !slices.Equal(ids, want) {
+		t.Errorf("All = %v, want %v", ids, want)
+	}
+
+	// Deleting a document deletes its chunks.
+	dc.Add("long2", "", long)
+	check(ix.Sync(ctx, dc))
+	if _, ok := vdb.Get(ChunkID("long2", 0)); !ok {
+		t.Fatal("long2 not chunked")
+	}
+	ix.Delete("long2")
+	if _, ok := vdb.Get(ChunkID("long2", 0)); ok {
+		t.Error("Delete did not delete the chunks")
+	}
+
+	// When the document shrinks, its chunks are deleted.
+	dc.Add("long", "", "now short")
+	check(ix.Sync(ctx, dc))
+	for i := range n {
+		if _, ok := vdb.Get(ChunkID("long", i)); ok {
+			t.Errorf("%s not deleted", ChunkID("long", i))
+		}
+	}
+	if n := chunkCount(db, "gaby", "long"); n != 0 {
+		t.Errorf("chunk count = %d after shrinking, want 0", n)
+	}
+	vec, _ := ix.Get("long")
+	if got := llm.UnquoteVector(vec); got != "now short" {
+		t.Errorf("long decoded to %q, want %q", got, "now short")
+	}
+}
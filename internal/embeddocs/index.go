@@ -0,0 +1,409 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embeddocs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// A Space is a namespace of a vector database holding
+// the embeddings of a corpus made by a single embedding model.
+// Vectors made by different models cannot be compared,
+// so each model gets its own space.
+type Space struct {
+	Model     string    // embedding model, as "provider/name" (for example, "gemini/text-embedding-004")
+	Namespace string    // vector database namespace holding the embeddings
+	Time      time.Time // when the space became active; zero for the original space
+}
+
+// Namespace returns the namespace of the space holding the
+// embeddings made by model for the index with the given name.
+// The result is a valid Firestore collection ID.
+func Namespace(index, model string) string {
+	return index + "-" + strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9', r == '-', r == '.':
+			return r
+		case 'A' <= r && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, model)
+}
+
+// An Index is the vector index of a document corpus.
+// Its vectors live in the active [Space], which is recorded in
+// the database so that all processes using the index agree on it.
+//
+// An Index is both a [storage.VectorDB] and an [llm.Embedder]
+// that delegate to the active space and its model, so that
+// searches always compare vectors made by the same model.
+// [Index.Migrate] re-embeds the corpus with another model into
+// a new space and then switches the index to it atomically;
+// other processes notice the switch within a minute.
+//
+// Database entries are as follows:
+//
+//   - (embeddocs.Index, $name) -> [Space]: the active space of the index
+//   - Watchers with name "embeddocs" (for the original space) or
+//     "embeddocs:"+$namespace (for the others), which record
+//     the documents embedded in each space.
+//   - The chunks of long documents (see [Chunker] and [Parent]).
+type Index struct {
+	slog  *slog.Logger
+	db    storage.DB
+	name  string
+	model string
+	open  func(namespace string) (storage.VectorDB, error)
+	now   func() time.Time
+
+	chunker  *Chunker // nil to embed whole documents
+	pipeline Pipeline
+	stats    stats
+
+	mu        sync.Mutex
+	embedders map[string]llm.Embedder     // by model
+	vdbs      map[string]storage.VectorDB // opened namespaces
+	active    *Space                      // nil if not loaded
+	vdb       storage.VectorDB            // vector database of active
+	loadTime  time.Time                   // when active was loaded
+}
+
+// indexTTL is how long an Index uses its active space before
+// reading it from the database again, which accounts for
+// migrations made by other processes.
+const indexTTL = time.Minute
+
+const indexKind = "embeddocs.Index"
+
+// NewIndex returns a new Index with the given name, which stores its
+// state in db and logs to lg. The open function returns the vector
+// database for a namespace.
+//
+// If no active space is recorded for the index, NewIndex records
+// the original space: the namespace named after the index, holding
+// embeddings made by model, as they were before spaces existed.
+func NewIndex(lg *slog.Logger, db storage.DB, name, model string, open func(namespace string) (storage.VectorDB, error)) *Index {
+	ix := &Index{
+		slog:      lg,
+		db:        db,
+		name:      name,
+		model:     model,
+		open:      open,
+		now:       time.Now,
+		pipeline:  DefaultPipeline,
+		embedders: make(map[string]llm.Embedder),
+		vdbs:      make(map[string]storage.VectorDB),
+	}
+	if _, ok := db.Get(ix.key()); !ok && model != "" {
+		db.Set(ix.key(), storage.JSON(&Space{Model: model, Namespace: name}))
+		db.Flush()
+	}
+	return ix
+}
+
+// SetEmbedder sets the embedder to use for the given model.
+// The index needs the embedder of its active model to embed
+// new documents and queries, and the embedder of the new model
+// to migrate to it.
+func (ix *Index) SetEmbedder(model string, embed llm.Embedder) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.embedders[model] = embed
+}
+
+// SetChunker sets the chunker used to split long documents
+// before embedding them (see [Chunker]). The index records the
+// chunks of each document, and its Search method returns the
+// documents of the chunks that match, rather than the chunks.
+//
+// Only documents embedded after the call are chunked; the others
+// are chunked when they change or when the index migrates to
+// another model.
+func (ix *Index) SetChunker(c *Chunker) {
+	ix.chunker = c
+}
+
+// SetPipeline sets how the index sends documents to the embedder
+// when it syncs or migrates (see [Pipeline]).
+// A zero BatchSize or Workers means the value in [DefaultPipeline].
+func (ix *Index) SetPipeline(p Pipeline) {
+	ix.pipeline = p
+}
+
+// Stats returns the cumulative counts of the work done
+// by the syncs and migrations of the index.
+func (ix *Index) Stats() Stats {
+	return ix.stats.snapshot()
+}
+
+func (ix *Index) key() []byte {
+	return ordered.Encode(indexKind, ix.name)
+}
+
+// Active returns the active space of the index.
+func (ix *Index) Active() Space {
+	s, _ := ix.load()
+	return *s
+}
+
+// load returns the active space and its vector database,
+// reading the space from the database if it is older than indexTTL.
+func (ix *Index) load() (*Space, storage.VectorDB) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	now := ix.now()
+	if ix.active != nil && now.Sub(ix.loadTime) <= indexTTL {
+		return ix.active, ix.vdb
+	}
+	s := &Space{Model: ix.model, Namespace: ix.name}
+	if data, ok := ix.db.Get(ix.key()); ok {
+		if err := json.Unmarshal(data, s); err != nil {
+			// unreachable unless db corruption
+			ix.db.Panic("embeddocs.Index decode", "name", ix.name, "err", err)
+		}
+	}
+	vdb := ix.vectorDB(s.Namespace)
+	if ix.active != nil && ix.active.Namespace != s.Namespace {
+		ix.slog.Info("embeddocs.Index switched", "name", ix.name, "model", s.Model, "namespace", s.Namespace)
+	}
+	ix.active, ix.vdb, ix.loadTime = s, vdb, now
+	return s, vdb
+}
+
+// vectorDB returns the vector database of the namespace.
+// ix.mu must be held.
+func (ix *Index) vectorDB(namespace string) storage.VectorDB {
+	if vdb, ok := ix.vdbs[namespace]; ok {
+		return vdb
+	}
+	vdb, err := ix.open(namespace)
+	if err != nil {
+		ix.db.Panic("embeddocs.Index open", "name", ix.name, "namespace", namespace, "err", err)
+	}
+	ix.vdbs[namespace] = vdb
+	return vdb
+}
+
+// embedder returns the embedder of model.
+func (ix *Index) embedder(model string) (llm.Embedder, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	e, ok := ix.embedders[model]
+	if !ok {
+		return nil, fmt.Errorf("embeddocs.Index %s: no embedder for model %q", ix.name, model)
+	}
+	return e, nil
+}
+
+// watcher returns the name of the watcher recording
+// the documents embedded in the space.
+func (ix *Index) watcher(s *Space) string {
+	if s.Namespace == ix.name {
+		return "embeddocs" // the original space; see [Sync]
+	}
+	return "embeddocs:" + s.Namespace
+}
+
+// Sync embeds the new documents in dc into the active space.
+func (ix *Index) Sync(ctx context.Context, dc *docs.Corpus) error {
+	s, vdb := ix.load()
+	ix.slog.Info("embeddocs sync", "model", s.Model, "namespace", s.Namespace)
+	embed, err := ix.embedder(s.Model)
+	if err != nil {
+		return err
+	}
+	_, err = ix.syncer(s.Namespace, vdb, embed).sync(ctx, dc.DocWatcher(ix.watcher(s)))
+	return err
+}
+
+// syncer returns a syncer embedding documents with embed
+// into vdb, the vector database of the namespace.
+func (ix *Index) syncer(namespace string, vdb storage.VectorDB, embed llm.Embedder) *syncer {
+	return &syncer{
+		slog:      ix.slog,
+		vdb:       vdb,
+		embed:     embed,
+		pipeline:  ix.pipeline,
+		stats:     &ix.stats,
+		chunker:   ix.chunker,
+		db:        ix.db,
+		namespace: namespace,
+	}
+}
+
+// Latest returns the latest DBTime of the documents
+// embedded in the active space.
+func (ix *Index) Latest(dc *docs.Corpus) timed.DBTime {
+	s, _ := ix.load()
+	return dc.DocWatcher(ix.watcher(s)).Latest()
+}
+
+// ErrActive is returned by [Index.Migrate] for the model
+// of the active space.
+var ErrActive = errors.New("model already active")
+
+// Migrate embeds all the documents in dc with model into the space
+// for the model (see [Namespace]) and then makes it the active space.
+// It needs the embedder of model (see [Index.SetEmbedder]).
+//
+// Migrate may take a long time for a large corpus, during which the
+// index keeps using the active space. It records its progress, so
+// if it fails or is interrupted, calling it again resumes the work.
+func (ix *Index) Migrate(ctx context.Context, dc *docs.Corpus, model string) error {
+	if ix.Active().Model == model {
+		return fmt.Errorf("embeddocs.Index %s: %w: %s", ix.name, ErrActive, model)
+	}
+	embed, err := ix.embedder(model)
+	if err != nil {
+		return err
+	}
+	to := &Space{Model: model, Namespace: Namespace(ix.name, model)}
+	ix.mu.Lock()
+	vdb := ix.vectorDB(to.Namespace)
+	ix.mu.Unlock()
+	w := dc.DocWatcher(ix.watcher(to))
+
+	ix.slog.Info("embeddocs.Index migrate start", "name", ix.name, "model", model, "namespace", to.Namespace)
+	// Repeat until no documents are left, in case
+	// documents are added during the migration.
+	total := 0
+	for {
+		n, err := ix.syncer(to.Namespace, vdb, embed).sync(ctx, w)
+		total += n
+		if err != nil {
+			return err
+		}
+		ix.slog.Info("embeddocs.Index migrate progress", "name", ix.name, "namespace", to.Namespace, "embedded", total)
+		if n == 0 {
+			break
+		}
+	}
+
+	// Switch.
+	to.Time = ix.now().UTC()
+	ix.db.Set(ix.key(), storage.JSON(to))
+	ix.db.Flush()
+	ix.mu.Lock()
+	ix.active = nil // reload
+	ix.mu.Unlock()
+	ix.slog.Info("embeddocs.Index migrate done", "name", ix.name, "model", model, "namespace", to.Namespace, "embedded", total)
+	return nil
+}
+
+var (
+	_ storage.VectorDB = (*Index)(nil)
+	_ llm.Embedder     = (*Index)(nil)
+)
+
+// EmbedDocs embeds the documents using the model of the active space.
+func (ix *Index) EmbedDocs(ctx context.Context, list []llm.EmbedDoc) ([]llm.Vector, error) {
+	embed, err := ix.embedder(ix.Active().Model)
+	if err != nil {
+		return nil, err
+	}
+	return embed.EmbedDocs(ctx, list)
+}
+
+// Set implements [storage.VectorDB.Set] for the active space.
+func (ix *Index) Set(id string, vec llm.Vector) {
+	_, vdb := ix.load()
+	vdb.Set(id, vec)
+}
+
+// Delete implements [storage.VectorDB.Delete] for the active space.
+// It also deletes the chunks of the document.
+func (ix *Index) Delete(id string) {
+	s, vdb := ix.load()
+	vdb.Delete(id)
+	if n := chunkCount(ix.db, s.Namespace, id); n > 0 {
+		for i := range n {
+			vdb.Delete(ChunkID(id, i))
+		}
+		ix.db.Delete(ordered.Encode(chunkCountKind, s.Namespace, id))
+	}
+}
+
+// Get implements [storage.VectorDB.Get] for the active space.
+func (ix *Index) Get(id string) (llm.Vector, bool) {
+	_, vdb := ix.load()
+	return vdb.Get(id)
+}
+
+// All implements [storage.VectorDB.All] for the active space.
+// It omits the vectors of chunks.
+func (ix *Index) All() iter.Seq2[string, func() llm.Vector] {
+	_, vdb := ix.load()
+	return func(yield func(string, func() llm.Vector) bool) {
+		for id, vec := range vdb.All() {
+			if _, ok := Parent(ix.db, id); ok {
+				continue
+			}
+			if !yield(id, vec) {
+				return
+			}
+		}
+	}
+}
+
+// Batch implements [storage.VectorDB.Batch] for the active space.
+func (ix *Index) Batch() storage.VectorBatch {
+	_, vdb := ix.load()
+	return vdb.Batch()
+}
+
+// Search implements [storage.VectorDB.Search] for the active space.
+// It replaces the chunks of long documents (see [Index.SetChunker])
+// with their documents, scored by their best match.
+// The filter keep applies to the documents, not their chunks.
+func (ix *Index) Search(vec llm.Vector, n int, keep storage.VectorFilter) []storage.VectorResult {
+	_, vdb := ix.load()
+	if ix.chunker == nil {
+		return vdb.Search(vec, n, keep)
+	}
+	if keep != nil {
+		docKeep := keep
+		keep = func(id string) bool {
+			id, _ = Parent(ix.db, id)
+			return docKeep(id)
+		}
+	}
+	// Several chunks of a document may match,
+	// so ask for more results than needed.
+	var res []storage.VectorResult
+	seen := make(map[string]bool)
+	for _, r := range vdb.Search(vec, 2*n, keep) {
+		r.ID, _ = Parent(ix.db, r.ID)
+		if seen[r.ID] {
+			continue
+		}
+		seen[r.ID] = true
+		res = append(res, r)
+		if len(res) == n {
+			break
+		}
+	}
+	return res
+}
+
+// Flush implements [storage.VectorDB.Flush] for the active space.
+func (ix *Index) Flush() {
+	_, vdb := ix.load()
+	vdb.Flush()
+}
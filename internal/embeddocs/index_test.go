@@ -0,0 +1,123 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embeddocs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// rot13Embedder embeds the rot13 of the documents,
+// to tell its vectors from those of [llm.QuoteEmbedder].
+type rot13Embedder struct{}
+
+func (rot13Embedder) EmbedDocs(ctx context.Context, list []llm.EmbedDoc) ([]llm.Vector, error) {
+	var rot []llm.EmbedDoc
+	for _, d := range list {
+		rot = append(rot, llm.EmbedDoc{Title: d.Title, Text: testutil.Rot13(d.Text)})
+	}
+	return llm.QuoteEmbedder().EmbedDocs(ctx, rot)
+}
+
+func TestNamespace(t *testing.T) {
+	if got, want := Namespace("gaby", "Gemini/text-embedding-004"), "gaby-gemini-text-embedding-004"; got != want {
+		t.Errorf("Namespace = %q, want %q", got, want)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	open := func(namespace string) (storage.VectorDB, error) {
+		return storage.MemVectorDB(db, lg, namespace), nil
+	}
+	dc := docs.New(lg, db)
+	for i, text := range texts {
+		dc.Add(fmt.Sprintf("URL%d", i), "", text)
+	}
+
+	ix := NewIndex(lg, db, "gaby", "quote/1", open)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	ix.now = func() time.Time { return now }
+	ix.SetEmbedder("quote/1", llm.QuoteEmbedder())
+	if got, want := ix.Active(), (Space{Model: "quote/1", Namespace: "gaby"}); got != want {
+		t.Fatalf("Active() = %+v, want %+v", got, want)
+	}
+
+	// Sync embeds into the original namespace, as Sync does.
+	check(ix.Sync(ctx, dc))
+	vdb := storage.MemVectorDB(db, lg, "gaby")
+	wantText := func(vdb storage.VectorDB, id, text string) {
+		t.Helper()
+		vec, ok := vdb.Get(id)
+		if !ok {
+			t.Errorf("%s missing", id)
+			return
+		}
+		if got := llm.UnquoteVector(vec); got != text {
+			t.Errorf("%s decoded to %q, want %q", id, got, text)
+		}
+	}
+	wantText(vdb, "URL0", texts[0])
+	wantText(ix, "URL0", texts[0])
+	if got, want := ix.Latest(dc), Latest(dc); got != want {
+		t.Errorf("Latest = %d, want %d", got, want)
+	}
+
+	// Another index on the same database uses the recorded space,
+	// not its own model.
+	other := NewIndex(lg, db, "gaby", "rot13/2", open)
+	other.now = ix.now
+	if got := other.Active().Model; got != "quote/1" {
+		t.Errorf("other index: Active().Model = %q, want quote/1", got)
+	}
+
+	// Migrate needs the embedder of the new model.
+	if err := ix.Migrate(ctx, dc, "rot13/2"); err == nil {
+		t.Error("Migrate without embedder succeeded, want error")
+	}
+	ix.SetEmbedder("rot13/2", rot13Embedder{})
+	check(ix.Migrate(ctx, dc, "rot13/2"))
+	if err := ix.Migrate(ctx, dc, "rot13/2"); !errors.Is(err, ErrActive) {
+		t.Errorf("Migrate to active model: got %v, want ErrActive", err)
+	}
+	want := Space{Model: "rot13/2", Namespace: "gaby-rot13-2", Time: now}
+	if got := ix.Active(); got != want {
+		t.Fatalf("after Migrate: Active() = %+v, want %+v", got, want)
+	}
+	wantText(ix, "URL0", testutil.Rot13(texts[0]))
+	wantText(vdb, "URL0", texts[0]) // the old space is kept
+	vecs, err := ix.EmbedDocs(ctx, []llm.EmbedDoc{{Text: "hello"}})
+	check(err)
+	if got := llm.UnquoteVector(vecs[0]); got != testutil.Rot13("hello") {
+		t.Errorf("EmbedDocs after Migrate = %q, want the new model's", got)
+	}
+
+	// The other index notices the switch once its space is stale.
+	if got := other.Active().Model; got != "quote/1" {
+		t.Errorf("other index before TTL: Active().Model = %q, want quote/1", got)
+	}
+	now = now.Add(2 * indexTTL)
+	if got := other.Active(); got != want {
+		t.Errorf("other index after TTL: Active() = %+v, want %+v", got, want)
+	}
+
+	// New documents are embedded into the new space only.
+	dc.Add("new", "", "new doc")
+	check(ix.Sync(ctx, dc))
+	wantText(ix, "new", testutil.Rot13("new doc"))
+	if _, ok := storage.MemVectorDB(db, lg, "gaby").Get("new"); ok {
+		t.Error("new doc embedded into the old space")
+	}
+}
@@ -3,21 +3,34 @@
 // license that can be found in the LICENSE file.
 
 // Package embeddocs implements embedding text docs into a vector database.
+//
+// [Sync] embeds new documents into a single vector database.
+// An [Index] keeps the embeddings made by each embedding model
+// in a namespace of its own, and can migrate a corpus to a new model.
+// It can also split long documents into chunks with a [Chunker],
+// embedding each chunk, and merge the chunks that match a search
+// back into their documents.
 package embeddocs
 
 import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/oscar/internal/docs"
 	"golang.org/x/oscar/internal/llm"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
 )
 
 // Sync reads new documents from dc, embeds them using embed,
 // and then writes the (docid, vector) pairs to vdb.
+// It sends the documents to embed in batches, several at a time,
+// as [DefaultPipeline] says.
 //
 // Sync uses [docs.DocWatcher] with the the name “embeddocs” to
 // save its position across multiple calls.
@@ -25,62 +38,317 @@ import (
 // Sync logs status and unexpected problems to lg.
 func Sync(ctx context.Context, lg *slog.Logger, vdb storage.VectorDB, embed llm.Embedder, dc *docs.Corpus) error {
 	lg.Info("embeddocs sync")
+	s := &syncer{slog: lg, vdb: vdb, embed: embed, pipeline: DefaultPipeline, stats: new(stats)}
+	_, err := s.sync(ctx, dc.DocWatcher("embeddocs"))
+	return err
+}
+
+// A Pipeline says how documents are sent to the embedder.
+//
+// Documents (or their chunks) are grouped into batches of at most
+// BatchSize, each sent in a single batch-embedding request.
+// Up to Workers requests run at a time. A request that fails is
+// retried up to Retries times, waiting Backoff before the first retry
+// and twice as long before each of the next ones.
+type Pipeline struct {
+	BatchSize int
+	Workers   int
+	Retries   int
+	Backoff   time.Duration
+}
+
+// DefaultPipeline is the pipeline used by [Sync], and by an [Index]
+// unless changed with [Index.SetPipeline].
+var DefaultPipeline = Pipeline{
+	BatchSize: 100,
+	Workers:   4,
+	Retries:   3,
+	Backoff:   1 * time.Second,
+}
+
+// withDefaults returns p with a zero BatchSize or Workers
+// replaced by that of [DefaultPipeline], and negative
+// Retries or Backoff replaced by zero.
+func (p Pipeline) withDefaults() Pipeline {
+	if p.BatchSize <= 0 {
+		p.BatchSize = DefaultPipeline.BatchSize
+	}
+	if p.Workers <= 0 {
+		p.Workers = DefaultPipeline.Workers
+	}
+	if p.Retries < 0 {
+		p.Retries = 0
+	}
+	if p.Backoff < 0 {
+		p.Backoff = 0
+	}
+	return p
+}
+
+// Stats are the cumulative counts of the work done by the syncs
+// of an [Index], for monitoring its throughput and failures.
+type Stats struct {
+	Docs     int64         // documents embedded and written
+	Chunks   int64         // chunks (or whole documents) embedded
+	Requests int64         // batch-embedding requests that succeeded
+	Retries  int64         // failed requests that were retried
+	Failures int64         // requests that failed for good
+	Time     time.Duration // total time spent in requests, including failed ones
+}
+
+// stats holds the counters behind [Stats].
+type stats struct {
+	docs, chunks, requests, retries, failures, nanos atomic.Int64
+}
+
+func (st *stats) snapshot() Stats {
+	return Stats{
+		Docs:     st.docs.Load(),
+		Chunks:   st.chunks.Load(),
+		Requests: st.requests.Load(),
+		Retries:  st.retries.Load(),
+		Failures: st.failures.Load(),
+		Time:     time.Duration(st.nanos.Load()),
+	}
+}
+
+// A syncer embeds new documents into a vector database.
+type syncer struct {
+	slog     *slog.Logger
+	vdb      storage.VectorDB
+	embed    llm.Embedder
+	pipeline Pipeline
+	stats    *stats
+
+	// If chunker is non-nil, long documents are embedded in chunks,
+	// which are recorded in db under the namespace of vdb.
+	chunker   *Chunker
+	db        storage.DB
+	namespace string
+}
+
+// A pendingDoc is a document waiting to be embedded.
+type pendingDoc struct {
+	id     string
+	chunks []Chunk
+}
+
+// A batch is the documents sent in a single embedding request,
+// along with the result of the request.
+type batch struct {
+	docs []pendingDoc
+	size int          // number of chunks in docs
+	last timed.DBTime // DBTime of the last document
+
+	vecs []llm.Vector // the vectors of the chunks, in order; may be short on error
+	err  error
+}
 
-	const batchSize = 100
+// sync embeds the documents that are new to w,
+// writes the (docid, vector) pairs to s.vdb and marks them old in w.
+// It returns the number of documents embedded.
+//
+// The batches are embedded in rounds of up to s.pipeline.Workers
+// concurrent requests. After each round, the results are written in
+// order, up to the first failed batch, so that w only ever marks old
+// a prefix of the documents.
+func (s *syncer) sync(ctx context.Context, w *timed.Watcher[*docs.Doc]) (int, error) {
+	p := s.pipeline.withDefaults()
+	start := time.Now()
 	var (
-		batch     []llm.EmbedDoc
-		ids       []string
-		batchLast timed.DBTime
+		round  []*batch
+		cur    = new(batch)
+		n      int
+		chunks int
 	)
-	w := dc.DocWatcher("embeddocs")
 
 	flush := func() error {
-		vecs, err := embed.EmbedDocs(ctx, batch)
-		if len(vecs) > len(ids) {
-			return fmt.Errorf("embeddocs length mismatch: batch=%d vecs=%d ids=%d", len(batch), len(vecs), len(ids))
+		if cur.size > 0 {
+			round = append(round, cur)
+			cur = new(batch)
 		}
-		vbatch := vdb.Batch()
-		for i, v := range vecs {
-			vbatch.Set(ids[i], v)
+		var wg sync.WaitGroup
+		for _, b := range round {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.embedBatch(ctx, p, b)
+			}()
 		}
-		vbatch.Apply()
-		if err != nil {
-			return fmt.Errorf("embeddocs EmbedDocs error: %w", err)
+		wg.Wait()
+
+		var err error
+		for _, b := range round {
+			k, done := s.write(b)
+			n += k
+			if !done {
+				err = b.err
+				break
+			}
+			chunks += b.size
+			w.MarkOld(b.last)
 		}
-		if len(vecs) != len(ids) {
-			return fmt.Errorf("embeddocs length mismatch: batch=%d vecs=%d ids=%d", len(batch), len(vecs), len(ids))
+		s.vdb.Flush()
+		if s.db != nil {
+			s.db.Flush()
 		}
-		vdb.Flush()
-		w.MarkOld(batchLast)
 		w.Flush()
-		batch = nil
-		ids = nil
-		return nil
+		round = nil
+		return err
 	}
 
 	for d := range w.Recent() {
-		lg.Debug("embeddocs sync start", "doc", d.ID)
-		batch = append(batch, llm.EmbedDoc{Title: d.Title, Text: d.Text})
-		ids = append(ids, d.ID)
-		batchLast = d.DBTime
-		if len(batch) >= batchSize {
-			if err := flush(); err != nil {
-				return err
+		s.slog.Debug("embeddocs sync start", "doc", d.ID)
+		dchunks := []Chunk{{Title: d.Title, Text: d.Text}}
+		if s.chunker != nil {
+			dchunks = s.chunker.Split(d.Title, d.Text)
+		}
+		cur.docs = append(cur.docs, pendingDoc{d.ID, dchunks})
+		cur.size += len(dchunks)
+		cur.last = d.DBTime
+		if cur.size >= p.BatchSize {
+			round = append(round, cur)
+			cur = new(batch)
+			if len(round) >= p.Workers {
+				if err := flush(); err != nil {
+					return n, err
+				}
 			}
 		}
 	}
-	if len(batch) > 0 {
+	if cur.size > 0 || len(round) > 0 {
 		// More to flush, but flush uses w.MarkOld,
 		// which has to be called during an iteration over w.Recent.
 		// Start a new iteration just to call flush and then break out.
 		for _ = range w.Recent() {
 			if err := flush(); err != nil {
-				return err
+				return n, err
 			}
 			break
 		}
 	}
-	return nil
+	if n > 0 {
+		elapsed := time.Since(start)
+		s.slog.Info("embeddocs sync done", "docs", n, "chunks", chunks, "elapsed", elapsed,
+			"docs/s", float64(n)/elapsed.Seconds())
+	}
+	return n, nil
+}
+
+// embedBatch embeds the chunks of the documents in b,
+// setting b.vecs and b.err, retrying failed requests as p says.
+func (s *syncer) embedBatch(ctx context.Context, p Pipeline, b *batch) {
+	var edocs []llm.EmbedDoc
+	for _, d := range b.docs {
+		for _, c := range d.chunks {
+			edocs = append(edocs, llm.EmbedDoc{Title: c.Title, Text: c.Text})
+		}
+	}
+	backoff := p.Backoff
+	for try := 0; ; try++ {
+		t := time.Now()
+		vecs, err := s.embed.EmbedDocs(ctx, edocs)
+		s.stats.nanos.Add(int64(time.Since(t)))
+		if len(vecs) > len(edocs) {
+			// A bug in the embedder, not worth retrying.
+			b.vecs, b.err = nil, fmt.Errorf("embeddocs length mismatch: docs=%d vecs=%d ids=%d", len(b.docs), len(vecs), len(edocs))
+			s.stats.failures.Add(1)
+			return
+		}
+		b.vecs, b.err = vecs, nil
+		if err == nil && len(vecs) == len(edocs) {
+			s.stats.requests.Add(1)
+			return
+		}
+		if err != nil {
+			b.err = fmt.Errorf("embeddocs EmbedDocs error: %w", err)
+		} else {
+			// Also a bug in the embedder, but the vectors it
+			// returned are still good.
+			b.err = fmt.Errorf("embeddocs length mismatch: docs=%d vecs=%d ids=%d", len(b.docs), len(vecs), len(edocs))
+			s.stats.failures.Add(1)
+			return
+		}
+		if try >= p.Retries || ctx.Err() != nil {
+			s.stats.failures.Add(1)
+			return
+		}
+		s.stats.retries.Add(1)
+		s.slog.Warn("embeddocs EmbedDocs retry", "try", try+1, "backoff", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			s.stats.failures.Add(1)
+			b.err = fmt.Errorf("embeddocs EmbedDocs error: %w (retry canceled: %w)", err, ctx.Err())
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// write writes the vectors of the documents in b whose chunks
+// were all embedded, returning the number of documents written
+// and whether that is all of them.
+func (s *syncer) write(b *batch) (int, bool) {
+	vecs := b.vecs
+	vbatch := s.vdb.Batch()
+	var dbatch storage.Batch
+	if s.db != nil {
+		dbatch = s.db.Batch()
+	}
+	n := 0
+	for _, d := range b.docs {
+		if len(vecs) < len(d.chunks) {
+			break
+		}
+		s.set(vbatch, dbatch, d.id, vecs[:len(d.chunks)])
+		vecs = vecs[len(d.chunks):]
+		n++
+	}
+	vbatch.Apply()
+	if dbatch != nil {
+		dbatch.Apply()
+	}
+	s.stats.docs.Add(int64(n))
+	s.stats.chunks.Add(int64(len(b.vecs) - len(vecs)))
+	return n, b.err == nil && n == len(b.docs)
+}
+
+// set adds the vectors of the chunks of the document
+// with the given ID to vbatch, and its chunk records to dbatch.
+// A document in several chunks also gets the mean of their vectors,
+// so that it can be looked up (and found) as a whole.
+// Chunks left over from a longer version of the document are deleted.
+func (s *syncer) set(vbatch storage.VectorBatch, dbatch storage.Batch, id string, vecs []llm.Vector) {
+	old := 0
+	if s.db != nil {
+		old = chunkCount(s.db, s.namespace, id)
+	}
+	if len(vecs) == 1 {
+		vbatch.Set(id, vecs[0])
+	} else {
+		vbatch.Set(id, mean(vecs))
+		for i, v := range vecs {
+			cid := ChunkID(id, i)
+			vbatch.Set(cid, v)
+			dbatch.Set(ordered.Encode(chunkParentKind, cid), []byte(id))
+		}
+		dbatch.Set(ordered.Encode(chunkCountKind, s.namespace, id), storage.JSON(len(vecs)))
+	}
+	n := len(vecs)
+	if n == 1 {
+		n = 0 // no chunks
+	}
+	for i := n; i < old; i++ {
+		vbatch.Delete(ChunkID(id, i))
+	}
+	if old > 0 && n == 0 {
+		dbatch.Delete(ordered.Encode(chunkCountKind, s.namespace, id))
+	}
+	vbatch.MaybeApply()
+	if dbatch != nil {
+		dbatch.MaybeApply()
+	}
 }
 
 // Latest returns the latest known DBTime marked old by the corpus's Watcher.
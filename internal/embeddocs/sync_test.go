@@ -7,7 +7,9 @@ package embeddocs
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"golang.org/x/oscar/internal/docs"
 	"golang.org/x/oscar/internal/llm"
@@ -107,6 +109,8 @@ func TestBigSync(t *testing.T) {
 
 func TestBadEmbedders(t *testing.T) {
 	const N = 150
+	defer func(p Pipeline) { DefaultPipeline = p }(DefaultPipeline)
+	DefaultPipeline.Backoff = 0
 	lg := testutil.Slogger(t)
 	db := storage.MemDB()
 	dc := docs.New(lg, db)
@@ -139,6 +143,78 @@ func TestBadEmbedders(t *testing.T) {
 	}
 }
 
+func TestSyncPipeline(t *testing.T) {
+	const N = 95
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	open := func(namespace string) (storage.VectorDB, error) {
+		return storage.MemVectorDB(db, lg, namespace), nil
+	}
+	dc := docs.New(lg, db)
+	for i := range N {
+		dc.Add(fmt.Sprintf("URL%03d", i), "", fmt.Sprintf("Text%d", i))
+	}
+
+	// The first two requests fail, and are retried.
+	flaky := &flakyEmbed{fails: 2}
+	ix := NewIndex(lg, db, "vdb", "flaky", open)
+	ix.SetEmbedder("flaky", flaky)
+	ix.SetPipeline(Pipeline{BatchSize: 10, Workers: 3, Retries: 2, Backoff: time.Millisecond})
+	check(ix.Sync(ctx, dc))
+	for i := range N {
+		if _, ok := ix.Get(fmt.Sprintf("URL%03d", i)); !ok {
+			t.Errorf("URL%03d missing after sync", i)
+		}
+	}
+	want := Stats{Docs: N, Chunks: N, Requests: 10, Retries: 2}
+	if got := ix.Stats(); got.Docs != want.Docs || got.Chunks != want.Chunks ||
+		got.Requests != want.Requests || got.Retries != want.Retries || got.Failures != 0 {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	// With no retries left, the sync fails,
+	// and the next one resumes the work.
+	for i := range N {
+		dc.Add(fmt.Sprintf("new%03d", i), "", fmt.Sprintf("New%d", i))
+	}
+	flaky.fails = 1000
+	ix.SetPipeline(Pipeline{BatchSize: 10, Workers: 3, Retries: 1})
+	if err := ix.Sync(ctx, dc); err == nil {
+		t.Fatal("Sync with failing embedder succeeded")
+	}
+	if got := ix.Stats(); got.Failures == 0 {
+		t.Errorf("Stats().Failures = 0 after failed sync")
+	}
+	flaky.fails = 0
+	check(ix.Sync(ctx, dc))
+	for i := range N {
+		if _, ok := ix.Get(fmt.Sprintf("new%03d", i)); !ok {
+			t.Errorf("new%03d missing after resumed sync", i)
+		}
+	}
+}
+
+// A flakyEmbed fails requests while fails > 0,
+// decrementing it for each failure.
+type flakyEmbed struct {
+	mu    sync.Mutex
+	fails int
+}
+
+func (f *flakyEmbed) EmbedDocs(ctx context.Context, docs []llm.EmbedDoc) ([]llm.Vector, error) {
+	f.mu.Lock()
+	fail := f.fails > 0
+	if fail {
+		f.fails--
+	}
+	f.mu.Unlock()
+	if fail {
+		return nil, fmt.Errorf("FLAKY ERROR")
+	}
+	return llm.QuoteEmbedder().EmbedDocs(ctx, docs)
+}
+
 type tooManyEmbed struct{}
 
 func (tooManyEmbed) EmbedDocs(ctx context.Context, docs []llm.EmbedDoc) ([]llm.Vector, error) {
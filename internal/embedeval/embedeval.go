@@ -0,0 +1,165 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package embedeval evaluates embedding models by how well they
+// retrieve the original of a duplicate issue.
+//
+// The labeled data comes from history: the duplicate issues
+// and their originals found by package dupmine.
+// For each embedding model, [Evaluate]
+// embeds a corpus of issues containing the duplicates, their originals
+// and other issues, searches for the nearest neighbors of each duplicate,
+// and reports where its original ranks, as recall@k and
+// mean reciprocal rank (MRR).
+package embedeval
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"golang.org/x/oscar/internal/dupmine"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A Dataset is a labeled dataset for evaluating embedding models.
+type Dataset struct {
+	Project string
+	Pairs   []*dupmine.Pair // duplicate pairs, by duplicate number
+	Issues  []*github.Issue // corpus of issues to search, by number
+}
+
+// NewDataset returns the dataset of the duplicate issues in the
+// project recorded in db by a [dupmine.Miner].
+//
+// The corpus of the dataset contains the duplicates, their originals,
+// and up to extra other issues (the ones with the highest numbers),
+// which make retrieval harder and more realistic.
+// If extra is negative, the corpus contains all the issues in the project.
+// Pull requests are ignored.
+func NewDataset(db storage.DB, project string, extra int) *Dataset {
+	issues := make(map[int64]*github.Issue)
+	for e := range github.Events(db, project, 0, -1) {
+		if iss, ok := e.Typed.(*github.Issue); ok && iss.PullRequest == nil {
+			issues[iss.Number] = iss
+		}
+	}
+
+	ds := &Dataset{Project: project}
+	inPair := make(map[int64]bool)
+	for p := range dupmine.Pairs(db, project) {
+		if issues[p.Duplicate] == nil || issues[p.Original] == nil {
+			continue
+		}
+		ds.Pairs = append(ds.Pairs, p)
+		inPair[p.Duplicate] = true
+		inPair[p.Original] = true
+	}
+
+	nums := slices.Sorted(maps.Keys(issues))
+	for i := len(nums) - 1; i >= 0; i-- {
+		n := nums[i]
+		switch {
+		case inPair[n]:
+		case extra != 0:
+			extra--
+		default:
+			continue
+		}
+		ds.Issues = append(ds.Issues, issues[n])
+	}
+	slices.Reverse(ds.Issues)
+	return ds
+}
+
+// A Result holds the retrieval metrics of an embedding model.
+type Result struct {
+	Queries int             // number of duplicates searched for
+	Recall  map[int]float64 // recall@k: fraction of originals among the k nearest neighbors, by k
+	MRR     float64         // mean reciprocal rank of the originals, counting 0 below rank [MaxRank]
+}
+
+// MaxRank is the lowest rank of an original that counts
+// towards the mean reciprocal rank.
+const MaxRank = 100
+
+// batchSize is the number of issues embedded in each call to the embedder.
+const batchSize = 100
+
+// Evaluate embeds the corpus of ds using embed and returns the metrics
+// of retrieving the original of each duplicate, for each k in ks.
+// The duplicate itself is not counted as a neighbor.
+// Evaluate logs progress to lg.
+func Evaluate(ctx context.Context, lg *slog.Logger, embed llm.Embedder, ds *Dataset, ks []int) (*Result, error) {
+	vdb := storage.MemVectorDB(storage.MemDB(), lg, "embedeval")
+	ids := make(map[int64]string)
+	for _, iss := range ds.Issues {
+		ids[iss.Number] = iss.DocID()
+	}
+	for batch := range slices.Chunk(ds.Issues, batchSize) {
+		var docs []llm.EmbedDoc
+		for _, iss := range batch {
+			docs = append(docs, llm.EmbedDoc{Title: github.CleanTitle(iss.Title), Text: github.CleanBody(iss.Body)})
+		}
+		vecs, err := embed.EmbedDocs(ctx, docs)
+		if err != nil {
+			return nil, fmt.Errorf("embedeval: %w", err)
+		}
+		if len(vecs) != len(docs) {
+			return nil, fmt.Errorf("embedeval: embedded %d of %d documents", len(vecs), len(docs))
+		}
+		vb := vdb.Batch()
+		for i, iss := range batch {
+			vb.Set(iss.DocID(), vecs[i])
+		}
+		vb.Apply()
+	}
+	lg.Info("embedeval embedded corpus", "issues", len(ds.Issues))
+
+	r := &Result{Recall: make(map[int]float64)}
+	for _, k := range ks {
+		r.Recall[k] = 0
+	}
+	var rr float64
+	for _, p := range ds.Pairs {
+		self, orig := ids[p.Duplicate], ids[p.Original]
+		vec, ok := vdb.Get(self)
+		if !ok || orig == "" {
+			continue
+		}
+		r.Queries++
+		rank := 0
+		i := 0
+		for _, res := range vdb.Search(vec, MaxRank+1, nil) {
+			if res.ID == self {
+				continue
+			}
+			i++
+			if res.ID == orig {
+				rank = i
+				break
+			}
+		}
+		if rank == 0 {
+			continue
+		}
+		rr += 1 / float64(rank)
+		for _, k := range ks {
+			if rank <= k {
+				r.Recall[k]++
+			}
+		}
+	}
+	if r.Queries > 0 {
+		for _, k := range ks {
+			r.Recall[k] /= float64(r.Queries)
+		}
+		r.MRR = rr / float64(r.Queries)
+	}
+	return r, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embedeval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/dupmine"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+var ctx = context.Background()
+
+// titleVectors is the embedding of each test issue, by title.
+var titleVectors = map[string]llm.Vector{
+	"a":    {1, 0, 0},
+	"b":    {0, 1, 0},
+	"c":    {0, 0, 1},
+	"a2":   {0.9, 0.1, 0},
+	"c2":   {0.1, 0.3, 0.95},
+	"open": {0.5, 0.5, 0},
+	"pr":   {1, 0, 0},
+}
+
+type titleEmbedder struct{}
+
+func (titleEmbedder) EmbedDocs(_ context.Context, docs []llm.EmbedDoc) ([]llm.Vector, error) {
+	var vecs []llm.Vector
+	for _, d := range docs {
+		vecs = append(vecs, titleVectors[d.Title])
+	}
+	return vecs, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	tc := gh.Testing()
+	const project = "golang/go"
+
+	for i, title := range []string{"a", "b", "c", "a2", "c2", "c", "open"} {
+		state := "closed"
+		if title == "open" {
+			state = "open"
+		}
+		tc.AddIssue(project, &github.Issue{Number: int64(i + 1), Title: title, State: state})
+	}
+	tc.AddIssue(project, &github.Issue{Number: 8, Title: "pr", State: "closed", PullRequest: new(struct{})})
+	tc.AddIssueComment(project, 4, &github.IssueComment{Body: "Duplicate of #3"})
+	tc.AddIssueComment(project, 4, &github.IssueComment{Body: "Oops.\nDuplicate of #1\n"})
+	tc.AddIssueComment(project, 5, &github.IssueComment{Body: "Duplicate of https://github.com/golang/go/issues/2"})
+	tc.AddIssueComment(project, 6, &github.IssueComment{Body: "Duplicate of #99"})
+	tc.AddIssueComment(project, 3, &github.IssueComment{Body: "Duplicate of https://github.com/other/repo/issues/1"})
+	tc.AddIssueComment(project, 7, &github.IssueComment{Body: "Duplicate of #1"})
+	tc.AddIssueComment(project, 8, &github.IssueComment{Body: "Duplicate of #1"})
+
+	m := dupmine.New(lg, db, gh, nil)
+	m.EnableProject(project)
+	check(m.Run(ctx))
+
+	ds := NewDataset(db, project, 0)
+	var pairs [][2]int64
+	for _, p := range ds.Pairs {
+		pairs = append(pairs, [2]int64{p.Duplicate, p.Original})
+	}
+	if diff := cmp.Diff([][2]int64{{4, 1}, {5, 2}}, pairs); diff != "" {
+		t.Errorf("Pairs (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int64{1, 2, 4, 5}, numbers(ds.Issues)); diff != "" {
+		t.Errorf("Issues (-want, +got):\n%s", diff)
+	}
+	r, err := Evaluate(ctx, lg, titleEmbedder{}, ds, []int{1, 5})
+	check(err)
+	want := &Result{Queries: 2, Recall: map[int]float64{1: 1, 5: 1}, MRR: 1}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("Evaluate without extra issues (-want, +got):\n%s", diff)
+	}
+
+	// With the other issues, issues 3 and 6 rank above the original of issue 5.
+	ds = NewDataset(db, project, 2)
+	if diff := cmp.Diff([]int64{1, 2, 4, 5, 6, 7}, numbers(ds.Issues)); diff != "" {
+		t.Errorf("Issues with extra (-want, +got):\n%s", diff)
+	}
+	ds = NewDataset(db, project, -1)
+	if diff := cmp.Diff([]int64{1, 2, 3, 4, 5, 6, 7}, numbers(ds.Issues)); diff != "" {
+		t.Errorf("Issues with all (-want, +got):\n%s", diff)
+	}
+	r, err = Evaluate(ctx, lg, titleEmbedder{}, ds, []int{1, 3})
+	check(err)
+	want = &Result{Queries: 2, Recall: map[int]float64{1: 0.5, 3: 1}, MRR: (1 + 1.0/3) / 2}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("Evaluate with all issues (-want, +got):\n%s", diff)
+	}
+}
+
+func numbers(issues []*github.Issue) []int64 {
+	var ns []int64
+	for _, iss := range issues {
+		ns = append(ns, iss.Number)
+	}
+	return ns
+}
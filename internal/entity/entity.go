@@ -0,0 +1,282 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package entity defines a canonical identifier ([ID]) for each kind of
+// entity Oscar stores or refers to: GitHub issues, pull requests and
+// their comments, GitHub discussions and their comments, Gerrit changes,
+// and web pages and their sections (document chunks).
+// It converts between IDs and the entities' URLs,
+// document IDs (see [golang.org/x/oscar/internal/docs])
+// and vector database keys (see [golang.org/x/oscar/internal/storage.VectorDB]).
+//
+// [Parse] accepts the URLs and document IDs of every kind of entity,
+// and [ParseIssue] also accepts the short forms of GitHub issues
+// used in the Gaby UI, such as "golang/go#12345".
+// The canonical string form of an ID ([ID.String]) is its document ID,
+// which is also its vector database key.
+package entity
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Kind is a kind of entity.
+type Kind string
+
+const (
+	GitHubIssue             Kind = "GitHubIssue"
+	GitHubPullRequest       Kind = "GitHubPullRequest"
+	GitHubIssueComment      Kind = "GitHubIssueComment" // including comments on pull requests
+	GitHubReviewComment     Kind = "GitHubReviewComment"
+	GitHubDiscussion        Kind = "GitHubDiscussion"
+	GitHubDiscussionComment Kind = "GitHubDiscussionComment"
+	GerritChange            Kind = "GerritChange"
+	WebPage                 Kind = "WebPage" // any other web page, or a section of one
+)
+
+// An ID identifies an entity.
+// Which fields are set depends on the Kind:
+// all kinds set Host, GitHub and Gerrit kinds set Project and Number,
+// comment kinds set Comment, and web pages set Path and, for a section
+// of a page, Section.
+type ID struct {
+	Kind    Kind
+	Host    string // "github.com", "go-review.googlesource.com", "go.dev", ...
+	Project string // GitHub project ("golang/go") or Gerrit repository ("go")
+	Number  int64  // issue, pull request, discussion or change number
+	Comment int64  // comment ID
+	Path    string // web page path, including any query ("/doc/faq")
+	Section string // web page section (URL fragment), if any
+}
+
+// Issue returns the ID of a GitHub issue.
+func Issue(project string, number int64) ID {
+	return ID{Kind: GitHubIssue, Host: "github.com", Project: project, Number: number}
+}
+
+// PullRequest returns the ID of a GitHub pull request.
+func PullRequest(project string, number int64) ID {
+	return ID{Kind: GitHubPullRequest, Host: "github.com", Project: project, Number: number}
+}
+
+// IssueComment returns the ID of a comment on a GitHub issue or pull request.
+func IssueComment(project string, issue, comment int64) ID {
+	return ID{Kind: GitHubIssueComment, Host: "github.com", Project: project, Number: issue, Comment: comment}
+}
+
+// Change returns the ID of a change on the Gerrit instance with the
+// given host name (for example, "go-review.googlesource.com").
+func Change(instance, project string, number int64) ID {
+	return ID{Kind: GerritChange, Host: instance, Project: project, Number: number}
+}
+
+// relatedContent is the fragment that distinguishes the document ID
+// of a Gerrit change from its URL. See [ID.DocID].
+const relatedContent = "related-content"
+
+// URL returns the URL of the entity's web page.
+func (id ID) URL() string {
+	gh := "https://" + id.Host + "/" + id.Project
+	switch id.Kind {
+	case GitHubIssue:
+		return fmt.Sprintf("%s/issues/%d", gh, id.Number)
+	case GitHubPullRequest:
+		return fmt.Sprintf("%s/pull/%d", gh, id.Number)
+	case GitHubIssueComment:
+		return fmt.Sprintf("%s/issues/%d#issuecomment-%d", gh, id.Number, id.Comment)
+	case GitHubReviewComment:
+		return fmt.Sprintf("%s/pull/%d#discussion_r%d", gh, id.Number, id.Comment)
+	case GitHubDiscussion:
+		return fmt.Sprintf("%s/discussions/%d", gh, id.Number)
+	case GitHubDiscussionComment:
+		return fmt.Sprintf("%s/discussions/%d#discussioncomment-%d", gh, id.Number, id.Comment)
+	case GerritChange:
+		return fmt.Sprintf("https://%s/c/%s/+/%d", id.Host, id.Project, id.Number)
+	case WebPage:
+		u := "https://" + id.Host + id.Path
+		if id.Section != "" {
+			u += "#" + id.Section
+		}
+		return u
+	}
+	return ""
+}
+
+// DocID returns the ID of the entity's document in a docs corpus.
+// It is the entity's URL, except that for a Gerrit change it has a
+// "#related-content" fragment, leaving the plain URL for other
+// documents about the change.
+func (id ID) DocID() string {
+	if id.Kind == GerritChange {
+		return id.URL() + "#" + relatedContent
+	}
+	return id.URL()
+}
+
+// VectorKey returns the key of the entity's embedding in a vector database,
+// which is its document ID.
+func (id ID) VectorKey() string {
+	return id.DocID()
+}
+
+// String returns the canonical string form of the ID, its document ID.
+func (id ID) String() string {
+	return id.DocID()
+}
+
+// APIURL returns the GitHub REST API URL for a GitHub issue or pull request,
+// or the empty string for other entities.
+func (id ID) APIURL() string {
+	switch id.Kind {
+	case GitHubIssue, GitHubPullRequest:
+		return fmt.Sprintf("https://api.%s/repos/%s/issues/%d", id.Host, id.Project, id.Number)
+	}
+	return ""
+}
+
+// Parse parses the URL or document ID of an entity.
+// It accepts the web page URLs returned by [ID.URL],
+// the document IDs returned by [ID.DocID], and the GitHub REST API URLs
+// of issues and pull requests ("https://api.github.com/repos/OWNER/REPO/issues/N").
+// Every other http or https URL is a [WebPage];
+// their IDs always use https.
+func Parse(s string) (ID, error) {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return ID{}, fmt.Errorf("entity.Parse: not an absolute URL: %q", s)
+	}
+	switch {
+	case u.Host == "github.com":
+		if id, ok := parseGitHub(u); ok {
+			return id, nil
+		}
+	case u.Host == "api.github.com":
+		if m := githubAPIRE.FindStringSubmatch(u.Path); m != nil && u.Fragment == "" {
+			kind := GitHubIssue
+			if m[2] == "pulls" {
+				kind = GitHubPullRequest
+			}
+			return ID{Kind: kind, Host: "github.com", Project: m[1], Number: atoi(m[3])}, nil
+		}
+		return ID{}, fmt.Errorf("entity.Parse: unknown GitHub API URL %q", s)
+	case strings.HasSuffix(u.Host, "-review.googlesource.com"):
+		if m := gerritRE.FindStringSubmatch(u.Path); m != nil && (u.Fragment == "" || u.Fragment == relatedContent) {
+			return Change(u.Host, m[1], atoi(m[2])), nil
+		}
+	}
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return ID{Kind: WebPage, Host: u.Host, Path: path, Section: u.Fragment}, nil
+}
+
+var (
+	// githubRE matches the path of a GitHub web page for an entity:
+	// /OWNER/REPO/API/NUMBER.
+	githubRE = regexp.MustCompile(`^/([\w.-]+/[\w.-]+)/(issues|pull|discussions)/([1-9][0-9]*)$`)
+
+	// githubAPIRE matches the path of a GitHub REST API URL
+	// for an issue or pull request.
+	githubAPIRE = regexp.MustCompile(`^/repos/([\w.-]+/[\w.-]+)/(issues|pulls)/([1-9][0-9]*)$`)
+
+	// gerritRE matches the path of a Gerrit change page.
+	gerritRE = regexp.MustCompile(`^/c/(.+)/\+/([1-9][0-9]*)/?$`)
+)
+
+// parseGitHub parses the GitHub web page URL u.
+func parseGitHub(u *url.URL) (ID, bool) {
+	m := githubRE.FindStringSubmatch(u.Path)
+	if m == nil {
+		return ID{}, false
+	}
+	id := ID{Host: u.Host, Project: m[1], Number: atoi(m[3])}
+	frag := u.Fragment
+	switch m[2] {
+	case "issues", "pull":
+		id.Kind = GitHubIssue
+		if m[2] == "pull" {
+			id.Kind = GitHubPullRequest
+		}
+		if c, ok := strings.CutPrefix(frag, "issuecomment-"); ok {
+			id.Kind, id.Comment, frag = GitHubIssueComment, atoi(c), ""
+		} else if c, ok := strings.CutPrefix(frag, "discussion_r"); ok && m[2] == "pull" {
+			id.Kind, id.Comment, frag = GitHubReviewComment, atoi(c), ""
+		}
+	case "discussions":
+		id.Kind = GitHubDiscussion
+		if c, ok := strings.CutPrefix(frag, "discussioncomment-"); ok {
+			id.Kind, id.Comment, frag = GitHubDiscussionComment, atoi(c), ""
+		}
+	}
+	if frag != "" {
+		// Some other fragment, such as a heading anchor.
+		return ID{}, false
+	}
+	switch id.Kind {
+	case GitHubIssueComment, GitHubReviewComment, GitHubDiscussionComment:
+		if id.Comment == 0 {
+			return ID{}, false // malformed comment ID
+		}
+	}
+	return id, true
+}
+
+// atoi returns the positive decimal number s, or 0 if s is not one.
+func atoi(s string) int64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+// ParseIssue parses a reference to a GitHub issue or pull request.
+// In addition to the URLs accepted by [Parse], it accepts
+//
+//   - "12345", for which the returned ID has an empty Project,
+//     to be filled in by the caller
+//   - "golang/go#12345"
+//   - "github.com/golang/go/issues/12345" (a URL without the scheme)
+//   - "go.dev/issue/12345" or "go.dev/issues/12345", with or without
+//     the scheme, for issues in golang/go
+func ParseIssue(s string) (ID, error) {
+	s = strings.TrimSpace(s)
+	bad := func() (ID, error) {
+		return ID{}, fmt.Errorf("entity.ParseIssue: invalid issue %q", s)
+	}
+	if n := atoi(s); n > 0 {
+		return Issue("", n), nil
+	}
+	if proj, num, ok := strings.Cut(s, "#"); ok && !strings.Contains(proj, ":") && githubProjectRE.MatchString(proj) {
+		if n := atoi(num); n > 0 {
+			return Issue(proj, n), nil
+		}
+		return bad()
+	}
+	u := s
+	if !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		u = "https://" + u
+	}
+	for _, prefix := range []string{"https://go.dev/issue/", "https://go.dev/issues/"} {
+		if num, ok := strings.CutPrefix(u, prefix); ok {
+			if n := atoi(num); n > 0 {
+				return Issue("golang/go", n), nil
+			}
+			return bad()
+		}
+	}
+	id, err := Parse(u)
+	if err != nil || (id.Kind != GitHubIssue && id.Kind != GitHubPullRequest) {
+		return bad()
+	}
+	return id, nil
+}
+
+// githubProjectRE matches a GitHub project name "OWNER/REPO".
+var githubProjectRE = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
@@ -0,0 +1,183 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want ID
+		// canonical form, if different from in
+		out string
+	}{
+		{
+			in:   "https://github.com/golang/go/issues/12345",
+			want: Issue("golang/go", 12345),
+		},
+		{
+			in:   "https://github.com/golang/go/pull/12345",
+			want: PullRequest("golang/go", 12345),
+		},
+		{
+			in:   "https://github.com/golang/go/issues/12345#issuecomment-135132324",
+			want: IssueComment("golang/go", 12345, 135132324),
+		},
+		{
+			in:   "https://github.com/golang/go/pull/12345#issuecomment-135132324",
+			want: IssueComment("golang/go", 12345, 135132324),
+			out:  "https://github.com/golang/go/issues/12345#issuecomment-135132324",
+		},
+		{
+			in:   "https://github.com/golang/go/pull/12345#discussion_r77",
+			want: ID{Kind: GitHubReviewComment, Host: "github.com", Project: "golang/go", Number: 12345, Comment: 77},
+		},
+		{
+			in:   "https://github.com/golang/go/discussions/5",
+			want: ID{Kind: GitHubDiscussion, Host: "github.com", Project: "golang/go", Number: 5},
+		},
+		{
+			in:   "https://github.com/golang/go/discussions/5#discussioncomment-6",
+			want: ID{Kind: GitHubDiscussionComment, Host: "github.com", Project: "golang/go", Number: 5, Comment: 6},
+		},
+		{
+			// a repo named "issues"
+			in:   "https://github.com/golang/issues/issues/1",
+			want: Issue("golang/issues", 1),
+		},
+		{
+			in:   "https://api.github.com/repos/golang/go/issues/12345",
+			want: Issue("golang/go", 12345),
+			out:  "https://github.com/golang/go/issues/12345",
+		},
+		{
+			in:   "https://go-review.googlesource.com/c/go/+/12345#related-content",
+			want: Change("go-review.googlesource.com", "go", 12345),
+		},
+		{
+			in:   "https://go-review.googlesource.com/c/go/+/12345",
+			want: Change("go-review.googlesource.com", "go", 12345),
+			out:  "https://go-review.googlesource.com/c/go/+/12345#related-content",
+		},
+		{
+			in:   "https://go.dev/doc/faq#x",
+			want: ID{Kind: WebPage, Host: "go.dev", Path: "/doc/faq", Section: "x"},
+		},
+		{
+			in:   "https://pkg.go.dev/search?q=x",
+			want: ID{Kind: WebPage, Host: "pkg.go.dev", Path: "/search?q=x"},
+		},
+		{
+			in:   "http://go.dev/doc/",
+			want: ID{Kind: WebPage, Host: "go.dev", Path: "/doc/"},
+			out:  "https://go.dev/doc/",
+		},
+		{
+			// an unrecognized fragment makes it a plain web page
+			in:   "https://github.com/golang/go/issues/1#heading",
+			want: ID{Kind: WebPage, Host: "github.com", Path: "/golang/go/issues/1", Section: "heading"},
+		},
+		{
+			in:   "https://github.com/golang/go/issues/0",
+			want: ID{Kind: WebPage, Host: "github.com", Path: "/golang/go/issues/0"},
+		},
+	} {
+		got, err := Parse(tc.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tc.in, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("Parse(%q) mismatch (-want +got):\n%s", tc.in, diff)
+		}
+		out := tc.out
+		if out == "" {
+			out = tc.in
+		}
+		if s := got.String(); s != out {
+			t.Errorf("Parse(%q).String() = %q, want %q", tc.in, s, out)
+		}
+		if got.VectorKey() != got.DocID() {
+			t.Errorf("Parse(%q): VectorKey %q != DocID %q", tc.in, got.VectorKey(), got.DocID())
+		}
+	}
+
+	for _, in := range []string{
+		"",
+		"12345",
+		"golang/go#12345",
+		"github.com/golang/go/issues/1",
+		"mailto:gopher@golang.org",
+		"https://api.github.com/repos/golang/go/issues/comments/1",
+	} {
+		if id, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", in, id)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	for _, tc := range []struct {
+		id   ID
+		url  string
+		api  string
+		kind Kind
+	}{
+		{Issue("golang/go", 1), "https://github.com/golang/go/issues/1", "https://api.github.com/repos/golang/go/issues/1", GitHubIssue},
+		{PullRequest("golang/go", 2), "https://github.com/golang/go/pull/2", "https://api.github.com/repos/golang/go/issues/2", GitHubPullRequest},
+		{IssueComment("golang/go", 1, 3), "https://github.com/golang/go/issues/1#issuecomment-3", "", GitHubIssueComment},
+		{Change("go-review.googlesource.com", "tools", 4), "https://go-review.googlesource.com/c/tools/+/4", "", GerritChange},
+	} {
+		if u := tc.id.URL(); u != tc.url {
+			t.Errorf("%+v.URL() = %q, want %q", tc.id, u, tc.url)
+		}
+		if u := tc.id.APIURL(); u != tc.api {
+			t.Errorf("%+v.APIURL() = %q, want %q", tc.id, u, tc.api)
+		}
+		if tc.id.Kind != tc.kind {
+			t.Errorf("%+v.Kind = %q, want %q", tc.id, tc.id.Kind, tc.kind)
+		}
+	}
+}
+
+func TestParseIssue(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    ID
+		wantErr bool
+	}{
+		{in: "12345", want: Issue("", 12345)},
+		{in: " 123", want: Issue("", 123)},
+		{in: "golang/go#12345", want: Issue("golang/go", 12345)},
+		{in: "https://github.com/foo/bar/issues/12345", want: Issue("foo/bar", 12345)},
+		{in: "github.com/foo/bar/issues/12345", want: Issue("foo/bar", 12345)},
+		{in: "https://github.com/foo/bar/pull/12345", want: PullRequest("foo/bar", 12345)},
+		{in: "https://go.dev/issues/234", want: Issue("golang/go", 234)},
+		{in: "go.dev/issue/234", want: Issue("golang/go", 234)},
+		{in: "https://api.github.com/repos/foo/bar/issues/1", want: Issue("foo/bar", 1)},
+		{in: "", wantErr: true},
+		{in: "x012x", wantErr: true},
+		{in: "012", want: Issue("", 12)},
+		{in: "golang/go", wantErr: true},
+		{in: "golang/go#x", wantErr: true},
+		{in: "go.dev/issues/x", wantErr: true},
+		{in: "https://example.com/foo/bar/issues/12345", wantErr: true},
+		{in: "https://github.com/foo/bar/issues/1#issuecomment-2", wantErr: true},
+		{in: "https://go-review.googlesource.com/c/go/+/12345", wantErr: true},
+	} {
+		got, err := ParseIssue(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseIssue(%q): error = %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseIssue(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
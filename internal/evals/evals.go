@@ -0,0 +1,294 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package evals evaluates the quality of LLM-generated issue overviews
+// and related-document rankings, so that prompt and model changes
+// can be compared before they are deployed.
+//
+// A [Corpus] holds issues, each with a golden summary written or
+// approved by a person and/or a set of candidate documents of which
+// some are known to be relevant. A [Runner] runs an [llmapp.Client]
+// (configured with the model and prompts to evaluate) on every case
+// and scores its outputs:
+//
+//   - overviews, by their word overlap with the golden summary
+//     (ROUGE-1 and ROUGE-L F1) and, if the Runner has a judge model,
+//     by the judge's 1-5 rating of the overview against the summary;
+//   - related documents, by the precision and recall of the
+//     first k documents of the ranking, and the reciprocal rank
+//     of the first relevant document.
+//
+// Reports can be stored ([SaveReport]) and compared ([Compare]),
+// for example on gaby's /evals page.
+package evals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+)
+
+// A Corpus is a set of evaluation cases.
+type Corpus struct {
+	Name  string  `json:"name"`
+	Cases []*Case `json:"cases"`
+}
+
+// A Case is an issue with its expected outputs.
+type Case struct {
+	Name     string        `json:"name"`               // unique name of the case, such as "golang/go#12345"
+	Issue    *llmapp.Doc   `json:"issue"`              // the issue
+	Comments []*llmapp.Doc `json:"comments,omitempty"` // the comments on the issue
+
+	// Summary is the golden overview of the issue and its comments.
+	// If empty, overviews are not evaluated for the case.
+	Summary string `json:"summary,omitempty"`
+
+	// Candidates are documents that a search found for the issue,
+	// and Relevant holds the URLs of the ones that are relevant to it,
+	// at least one if there are candidates.
+	// If there are no candidates, related documents are not evaluated
+	// for the case.
+	Candidates []*llmapp.Doc `json:"candidates,omitempty"`
+	Relevant   []string      `json:"relevant,omitempty"`
+}
+
+// ReadCorpus reads the corpus in JSON form from the file.
+func ReadCorpus(file string) (*Corpus, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var c Corpus
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return &c, nil
+}
+
+// validate reports an error if a case in c is malformed.
+func (c *Corpus) validate() error {
+	seen := make(map[string]bool)
+	for i, cs := range c.Cases {
+		if cs.Name == "" {
+			return fmt.Errorf("case %d: missing name", i)
+		}
+		if seen[cs.Name] {
+			return fmt.Errorf("case %s: duplicate name", cs.Name)
+		}
+		seen[cs.Name] = true
+		if cs.Issue == nil {
+			return fmt.Errorf("case %s: missing issue", cs.Name)
+		}
+		if len(cs.Candidates) > 0 && len(cs.Relevant) == 0 {
+			return fmt.Errorf("case %s: candidates but no relevant documents", cs.Name)
+		}
+		urls := make(map[string]bool)
+		for _, d := range cs.Candidates {
+			urls[d.URL] = true
+		}
+		for _, u := range cs.Relevant {
+			if !urls[u] {
+				return fmt.Errorf("case %s: relevant document %s is not a candidate", cs.Name, u)
+			}
+		}
+	}
+	return nil
+}
+
+// A Runner runs evaluations.
+type Runner struct {
+	slog  *slog.Logger
+	judge llm.ContentGenerator // if non-nil, rates overviews; see SetJudge
+	k     int                  // number of related documents scored; see SetK
+	now   func() time.Time
+}
+
+// DefaultK is the default number of related documents that
+// a [Runner] scores, the most that gaby posts by default.
+const DefaultK = 10
+
+// NewRunner returns a new Runner that logs to lg.
+// It scores overviews by word overlap only, and the
+// first [DefaultK] related documents.
+func NewRunner(lg *slog.Logger) *Runner {
+	return &Runner{slog: lg, k: DefaultK, now: time.Now}
+}
+
+// SetJudge sets the model that rates overviews against the golden
+// summaries ("LLM as judge"). The judge should be a strong model that
+// is kept the same across the evaluations to compare.
+func (r *Runner) SetJudge(g llm.ContentGenerator) {
+	r.judge = g
+}
+
+// SetK sets the number of related documents to score,
+// the first ones of the ranking.
+func (r *Runner) SetK(k int) {
+	r.k = k
+}
+
+// A Report is the result of evaluating a client on a corpus.
+type Report struct {
+	Name   string    // name of the evaluation, such as "gemini-1.5-pro/new-overview-prompt"
+	Corpus string    // name of the corpus
+	Time   time.Time // when the evaluation ran
+	Model  string    // generative model evaluated
+	Judge  string    // judge model, or "" if none
+
+	// Prompt versions of the overviews and related rankings
+	// (see [llmapp.Result.PromptVersion]).
+	OverviewVersion string
+	RelatedVersion  string
+
+	Scores Scores        // mean scores over the cases
+	Cases  []*CaseResult // results for each case, in corpus order
+}
+
+// Scores are the scores of one case, or the mean scores of a [Report].
+// Fields that were not evaluated are zero, and the counts say
+// how many cases contributed to the means.
+type Scores struct {
+	Overviews int     // number of overviews scored
+	Rouge1    float64 // ROUGE-1 F1 against the golden summary, in [0, 1]
+	RougeL    float64 // ROUGE-L F1 against the golden summary, in [0, 1]
+	Judged    int     // number of overviews rated by the judge
+	Judge     float64 // judge rating, in [1, 5]
+
+	Rankings  int     // number of related rankings scored
+	Precision float64 // fraction of the first k documents that are relevant
+	Recall    float64 // fraction of the relevant documents in the first k
+	MRR       float64 // reciprocal rank of the first relevant document (mean reciprocal rank in a Report)
+
+	Errors int // number of failed LLM calls
+}
+
+// A CaseResult is the result of evaluating a client on a case.
+type CaseResult struct {
+	Name     string
+	Overview string   // generated overview, if any
+	Reason   string   // judge's reason for its rating, if any
+	Ranking  []string // URLs of the candidates, most relevant first, if any
+	Errors   []string // errors from LLM calls
+	Scores   Scores
+}
+
+// Run evaluates the client c on the corpus and returns the report,
+// with the given name.
+// Run returns an error only if the context is canceled;
+// failed LLM calls are recorded in the report.
+func (r *Runner) Run(ctx context.Context, name string, c *llmapp.Client, corpus *Corpus) (*Report, error) {
+	rep := &Report{
+		Name:   name,
+		Corpus: corpus.Name,
+		Time:   r.now().UTC(),
+	}
+	if r.judge != nil {
+		rep.Judge = r.judge.Model()
+	}
+	for _, cs := range corpus.Cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		cr := &CaseResult{Name: cs.Name}
+		if cs.Summary != "" {
+			r.runOverview(ctx, c, cs, cr, rep)
+		}
+		if len(cs.Candidates) > 0 {
+			r.runRelated(ctx, c, cs, cr, rep)
+		}
+		cr.Scores.Errors = len(cr.Errors)
+		rep.Cases = append(rep.Cases, cr)
+		r.slog.Info("evals: ran case", "name", name, "case", cs.Name, "errors", cr.Errors)
+	}
+	rep.Scores = mean(rep.Cases)
+	return rep, nil
+}
+
+// runOverview generates and scores the overview of cs.
+func (r *Runner) runOverview(ctx context.Context, c *llmapp.Client, cs *Case, cr *CaseResult, rep *Report) {
+	res, err := c.PostOverview(ctx, cs.Issue, cs.Comments)
+	if err != nil {
+		cr.Errors = append(cr.Errors, fmt.Sprintf("overview: %v", err))
+		return
+	}
+	rep.Model, rep.OverviewVersion = res.Model, res.PromptVersion
+	cr.Overview = res.Response
+	cr.Scores.Overviews = 1
+	cr.Scores.Rouge1 = rouge1(cs.Summary, res.Response)
+	cr.Scores.RougeL = rougeL(cs.Summary, res.Response)
+	if r.judge == nil {
+		return
+	}
+	score, reason, err := judge(ctx, r.judge, cs.Summary, res.Response)
+	if err != nil {
+		cr.Errors = append(cr.Errors, fmt.Sprintf("judge: %v", err))
+		return
+	}
+	cr.Scores.Judged = 1
+	cr.Scores.Judge = float64(score)
+	cr.Reason = reason
+}
+
+// runRelated ranks and scores the candidates of cs.
+func (r *Runner) runRelated(ctx context.Context, c *llmapp.Client, cs *Case, cr *CaseResult, rep *Report) {
+	res, err := c.Rerank(ctx, cs.Issue, cs.Candidates)
+	if err != nil {
+		cr.Errors = append(cr.Errors, fmt.Sprintf("related: %v", err))
+		return
+	}
+	rep.Model, rep.RelatedVersion = res.Model, res.PromptVersion
+	for _, i := range res.Order {
+		cr.Ranking = append(cr.Ranking, cs.Candidates[i].URL)
+	}
+	cr.Scores.Rankings = 1
+	cr.Scores.Precision, cr.Scores.Recall, cr.Scores.MRR = rankScores(cr.Ranking, cs.Relevant, r.k)
+}
+
+// mean returns the mean scores of the cases.
+func mean(cases []*CaseResult) Scores {
+	var m Scores
+	for _, cr := range cases {
+		s := &cr.Scores
+		m.Errors += s.Errors
+		if s.Overviews > 0 {
+			m.Overviews++
+			m.Rouge1 += s.Rouge1
+			m.RougeL += s.RougeL
+		}
+		if s.Judged > 0 {
+			m.Judged++
+			m.Judge += s.Judge
+		}
+		if s.Rankings > 0 {
+			m.Rankings++
+			m.Precision += s.Precision
+			m.Recall += s.Recall
+			m.MRR += s.MRR
+		}
+	}
+	if m.Overviews > 0 {
+		m.Rouge1 /= float64(m.Overviews)
+		m.RougeL /= float64(m.Overviews)
+	}
+	if m.Judged > 0 {
+		m.Judge /= float64(m.Judged)
+	}
+	if m.Rankings > 0 {
+		n := float64(m.Rankings)
+		m.Precision /= n
+		m.Recall /= n
+		m.MRR /= n
+	}
+	return m
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package evals
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// testGenerator returns a content generator that writes the overview,
+// ranks the candidates in reverse order and rates every overview 4.
+func testGenerator(overview string) llm.ContentGenerator {
+	return llm.TestContentGenerator("test-model", func(_ context.Context, schema *llm.Schema, _ []llm.Part) (string, error) {
+		switch {
+		case schema == nil:
+			return overview, nil
+		case schema.Properties["ranking"] != nil:
+			return `{"ranking": [1, 0]}`, nil
+		case schema.Properties["score"] != nil:
+			return `{"score": 4, "reason": "good"}`, nil
+		}
+		return "", errors.New("unexpected schema")
+	})
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	corpus, err := ReadCorpus("testdata/corpus.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRunner(lg)
+	r.now = func() time.Time { return time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC) }
+	r.SetJudge(testGenerator(""))
+	r.SetK(1)
+	c := llmapp.New(lg, testGenerator("The garbage collector crashes on arm64."), storage.MemDB())
+	rep, err := r.Run(ctx, "a", c, corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Name != "a" || rep.Corpus != "test" || rep.Model != "test-model" || rep.Judge != "test-model" ||
+		rep.OverviewVersion == "" || rep.RelatedVersion == "" {
+		t.Errorf("report = %+v", rep)
+	}
+	if len(rep.Cases) != 2 {
+		t.Fatalf("got %d cases, want 2", len(rep.Cases))
+	}
+	c1 := rep.Cases[0]
+	wantRanking := []string{"https://github.com/golang/go/issues/3", "https://github.com/golang/go/issues/2"}
+	if !slices.Equal(c1.Ranking, wantRanking) {
+		t.Errorf("ranking = %v, want %v", c1.Ranking, wantRanking)
+	}
+	if c1.Scores.Precision != 1 || c1.Scores.Recall != 1 || c1.Scores.MRR != 1 {
+		t.Errorf("case 1 ranking scores = %+v, want all 1", c1.Scores)
+	}
+	if c1.Scores.Judge != 4 || c1.Reason != "good" || c1.Scores.Rouge1 <= 0 || c1.Scores.Rouge1 >= 1 {
+		t.Errorf("case 1 overview scores = %+v", c1.Scores)
+	}
+	if c2 := rep.Cases[1]; c2.Scores.Rankings != 0 || c2.Ranking != nil {
+		t.Errorf("case 2 has no candidates but was ranked: %+v", c2)
+	}
+	s := rep.Scores
+	if s.Overviews != 2 || s.Judged != 2 || s.Rankings != 1 || s.Errors != 0 || s.Judge != 4 || s.MRR != 1 {
+		t.Errorf("mean scores = %+v", s)
+	}
+
+	// LLM failures are recorded.
+	fail := llm.TestContentGenerator("test-model", func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+		return "", errors.New("LLM down")
+	})
+	rep, err = r.Run(ctx, "b", llmapp.New(lg, fail, storage.MemDB()), corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Scores.Errors != 3 || rep.Scores.Overviews != 0 || !strings.Contains(rep.Cases[0].Errors[0], "LLM down") {
+		t.Errorf("failing report = %+v %v", rep.Scores, rep.Cases[0].Errors)
+	}
+}
+
+func TestReadCorpusErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name, json, want string
+	}{
+		{"noname", `{"cases": [{"issue": {"text": "x"}}]}`, "missing name"},
+		{"dup", `{"cases": [{"name": "a", "issue": {"text": "x"}}, {"name": "a", "issue": {"text": "y"}}]}`, "duplicate name"},
+		{"noissue", `{"cases": [{"name": "a"}]}`, "missing issue"},
+		{"norelevant", `{"cases": [{"name": "a", "issue": {"text": "x"}, "candidates": [{"url": "u", "text": "y"}]}]}`, "no relevant"},
+		{"notcandidate", `{"cases": [{"name": "a", "issue": {"text": "x"}, "candidates": [{"url": "u", "text": "y"}], "relevant": ["v"]}]}`, "not a candidate"},
+	} {
+		file := filepath.Join(dir, test.name+".json")
+		if err := os.WriteFile(file, []byte(test.json), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadCorpus(file); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: ReadCorpus error = %v, want %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	for _, test := range []struct {
+		want, got      string
+		rouge1, rougeL float64
+	}{
+		{"the cat sat", "the cat sat", 1, 1},
+		{"the cat sat", "sat the cat", 1, 2.0 / 3},
+		{"the cat sat", "a dog ran", 0, 0},
+		{"The Cat, sat.", "the cat", 0.8, 0.8},
+		{"", "anything", 0, 0},
+	} {
+		if r := rouge1(test.want, test.got); !near(r, test.rouge1) {
+			t.Errorf("rouge1(%q, %q) = %v, want %v", test.want, test.got, r, test.rouge1)
+		}
+		if r := rougeL(test.want, test.got); !near(r, test.rougeL) {
+			t.Errorf("rougeL(%q, %q) = %v, want %v", test.want, test.got, r, test.rougeL)
+		}
+	}
+
+	ranking := []string{"a", "b", "c", "d"}
+	for _, test := range []struct {
+		relevant []string
+		k        int
+		p, r, rr float64
+	}{
+		{[]string{"a"}, 2, 0.5, 1, 1},
+		{[]string{"c"}, 2, 0, 0, 1.0 / 3},
+		{[]string{"b", "d"}, 2, 0.5, 0.5, 0.5},
+		{[]string{"x"}, 10, 0, 0, 0},
+	} {
+		p, r, rr := rankScores(ranking, test.relevant, test.k)
+		if !near(p, test.p) || !near(r, test.r) || !near(rr, test.rr) {
+			t.Errorf("rankScores(%v, %d) = %v, %v, %v, want %v, %v, %v", test.relevant, test.k, p, r, rr, test.p, test.r, test.rr)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	db := storage.MemDB()
+	t0 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	a := &Report{Name: "a", Time: t0, Scores: Scores{Rouge1: 0.5, MRR: 0.5},
+		Cases: []*CaseResult{
+			{Name: "x", Scores: Scores{Rouge1: 0.5}},
+			{Name: "y", Scores: Scores{MRR: 0.5}},
+		}}
+	b := &Report{Name: "b", Time: t0.Add(time.Hour), Scores: Scores{Rouge1: 0.75, MRR: 0.25},
+		Cases: []*CaseResult{
+			{Name: "x", Scores: Scores{Rouge1: 0.75}},
+			{Name: "z", Scores: Scores{MRR: 0.25}},
+		}}
+	SaveReport(db, a)
+	SaveReport(db, b)
+	if got, ok := LoadReport(db, "a"); !ok || !cmp.Equal(got, a) {
+		t.Errorf("LoadReport(a) = %+v, %v", got, ok)
+	}
+	var names []string
+	for _, r := range Reports(db) {
+		names = append(names, r.Name)
+	}
+	if !slices.Equal(names, []string{"b", "a"}) {
+		t.Errorf("Reports = %v, want [b a]", names)
+	}
+
+	c := Compare(a, b)
+	if c.Delta.Rouge1 != 0.25 || c.Delta.MRR != -0.25 {
+		t.Errorf("Delta = %+v", c.Delta)
+	}
+	var got []string
+	for _, cc := range c.Cases {
+		got = append(got, cc.Name)
+	}
+	if !slices.Equal(got, []string{"x", "y", "z"}) {
+		t.Errorf("cases = %v, want [x y z]", got)
+	}
+	if c.Cases[0].Delta.Rouge1 != 0.25 || c.Cases[1].B != nil || c.Cases[2].A != nil {
+		t.Errorf("case comparisons = %+v %+v %+v", c.Cases[0], c.Cases[1], c.Cases[2])
+	}
+}
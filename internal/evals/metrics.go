@@ -0,0 +1,97 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package evals
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var wordRE = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// words returns the lower-cased words of s, in order.
+func words(s string) []string {
+	return wordRE.FindAllString(strings.ToLower(s), -1)
+}
+
+// f1 returns the harmonic mean of the precision and recall
+// of n matches among got and want words.
+func f1(n, got, want int) float64 {
+	if n == 0 {
+		return 0
+	}
+	p := float64(n) / float64(got)
+	r := float64(n) / float64(want)
+	return 2 * p * r / (p + r)
+}
+
+// rouge1 returns the ROUGE-1 F1 score of got against want:
+// the overlap of their words, regardless of order.
+func rouge1(want, got string) float64 {
+	ww, gw := words(want), words(got)
+	counts := make(map[string]int)
+	for _, w := range ww {
+		counts[w]++
+	}
+	n := 0
+	for _, w := range gw {
+		if counts[w] > 0 {
+			counts[w]--
+			n++
+		}
+	}
+	return f1(n, len(gw), len(ww))
+}
+
+// rougeL returns the ROUGE-L F1 score of got against want:
+// the overlap of their longest common subsequence of words,
+// which rewards words in the same order.
+func rougeL(want, got string) float64 {
+	ww, gw := words(want), words(got)
+	return f1(lcs(ww, gw), len(gw), len(ww))
+}
+
+// lcs returns the length of the longest common subsequence of a and b.
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(cur[j], prev[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// rankScores returns the precision and recall of the first k
+// documents of the ranking, and the reciprocal rank of the first
+// relevant document in the whole ranking (0 if there is none).
+// There must be at least one relevant document.
+func rankScores(ranking, relevant []string, k int) (precision, recall, rr float64) {
+	top := ranking[:min(k, len(ranking))]
+	n := 0
+	for _, u := range top {
+		if slices.Contains(relevant, u) {
+			n++
+		}
+	}
+	if len(top) > 0 {
+		precision = float64(n) / float64(len(top))
+	}
+	recall = float64(n) / float64(len(relevant))
+	for i, u := range ranking {
+		if slices.Contains(relevant, u) {
+			rr = 1 / float64(i+1)
+			break
+		}
+	}
+	return precision, recall, rr
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package evals
+
+import (
+	"slices"
+
+	"golang.org/x/oscar/internal/storage"
+)
+
+//go:generate go run golang.org/x/oscar/internal/devtools/cmd/kindgen -type Report -kind evals.Report -key Name
+
+// SaveReport stores the report in db under its name,
+// replacing any report with the same name.
+func SaveReport(db storage.DB, r *Report) {
+	setReport(db, r)
+}
+
+// LoadReport returns the report stored in db with the given name, if any.
+func LoadReport(db storage.DB, name string) (*Report, bool) {
+	return getReport(db, name)
+}
+
+// Reports returns the reports stored in db, most recent first.
+func Reports(db storage.DB) []*Report {
+	rs := slices.Collect(scanReport(db))
+	slices.SortStableFunc(rs, func(a, b *Report) int { return b.Time.Compare(a.Time) })
+	return rs
+}
+
+// A Comparison compares two reports, usually of the same corpus.
+type Comparison struct {
+	A, B  *Report
+	Delta Scores // B's mean scores minus A's
+	Cases []*CaseComparison
+}
+
+// A CaseComparison compares the results of a case in two reports.
+type CaseComparison struct {
+	Name  string
+	A, B  *CaseResult // nil if the case is not in the report
+	Delta Scores      // B's scores minus A's, if the case is in both
+}
+
+// Compare compares the reports a and b.
+// The cases are those of a followed by the ones only in b.
+func Compare(a, b *Report) *Comparison {
+	c := &Comparison{A: a, B: b, Delta: sub(b.Scores, a.Scores)}
+	bc := make(map[string]*CaseResult)
+	for _, cr := range b.Cases {
+		bc[cr.Name] = cr
+	}
+	for _, ca := range a.Cases {
+		cc := &CaseComparison{Name: ca.Name, A: ca, B: bc[ca.Name]}
+		if cc.B != nil {
+			cc.Delta = sub(cc.B.Scores, ca.Scores)
+			delete(bc, ca.Name)
+		}
+		c.Cases = append(c.Cases, cc)
+	}
+	for _, cb := range b.Cases {
+		if bc[cb.Name] != nil {
+			c.Cases = append(c.Cases, &CaseComparison{Name: cb.Name, B: cb})
+		}
+	}
+	return c
+}
+
+// sub returns x - y.
+func sub(x, y Scores) Scores {
+	return Scores{
+		Overviews: x.Overviews - y.Overviews,
+		Rouge1:    x.Rouge1 - y.Rouge1,
+		RougeL:    x.RougeL - y.RougeL,
+		Judged:    x.Judged - y.Judged,
+		Judge:     x.Judge - y.Judge,
+		Rankings:  x.Rankings - y.Rankings,
+		Precision: x.Precision - y.Precision,
+		Recall:    x.Recall - y.Recall,
+		MRR:       x.MRR - y.MRR,
+		Errors:    x.Errors - y.Errors,
+	}
+}
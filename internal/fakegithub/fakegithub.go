@@ -0,0 +1,1029 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fakegithub implements an in-memory fake of the subset of the
+// GitHub REST API used by package [github], so that tests can exercise
+// the real sync and edit code paths hermetically.
+//
+// A [Server] holds the state of any number of projects ("owner/repo").
+// Tests populate it with [Server.AddIssue], [Server.AddIssueComment],
+// [Server.AddReviewComment], [Server.AddCrossReference], [Server.AddLabel],
+// [Server.AddMilestone] and [Server.AddBoardItem],
+// point a [github.Client] at it using [Server.Client],
+// and then inspect the resulting state with [Server.Issue],
+// [Server.Comments], [Server.Labels], [Server.Reviews] and [Server.CheckRuns].
+//
+// The fake implements:
+//
+//   - GET /repos/OWNER/REPO/issues (with since, sort=updated, direction=asc)
+//   - GET /repos/OWNER/REPO/issues/comments (with since)
+//   - GET /repos/OWNER/REPO/issues/events (newest first)
+//   - GET /repos/OWNER/REPO/pulls/comments (with since)
+//   - GET /repos/OWNER/REPO/pulls/N (number and head commit only)
+//   - GET /repos/OWNER/REPO/pulls/N/files
+//   - POST /repos/OWNER/REPO/pulls/N/reviews
+//   - POST /repos/OWNER/REPO/check-runs
+//   - GET and PATCH /repos/OWNER/REPO/issues/N
+//   - GET /repos/OWNER/REPO/issues/N/events
+//   - GET /repos/OWNER/REPO/issues/N/timeline (events and cross references only)
+//   - GET /repos/OWNER/REPO/issues/N/comments (with since)
+//   - POST /repos/OWNER/REPO/issues/N/comments
+//   - GET and PATCH /repos/OWNER/REPO/issues/comments/ID
+//   - GET /repos/OWNER/REPO/milestones (with state)
+//   - GET and POST /repos/OWNER/REPO/labels
+//   - GET, PATCH and DELETE /repos/OWNER/REPO/labels/NAME
+//   - POST /graphql, for the queries made by package github
+//
+// List endpoints are paginated using the page and per_page parameters
+// and the Link response header, like the real API.
+// They also set ETags and honor If-None-Match.
+//
+// The fake does not parse GraphQL: it recognizes the queries by their
+// operation names ("Issues", "IssueComments" and "Board") and answers them
+// using only the variables.
+//
+// The fake's clock starts at a fixed time and advances by one second
+// for each change, so results are deterministic.
+package fakegithub
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+)
+
+const (
+	apiURL  = "https://api.github.com"
+	htmlURL = "https://github.com"
+)
+
+// start is the initial time of a Server's clock.
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// A Server is a fake GitHub server.
+// It is safe for concurrent use.
+type Server struct {
+	mu       sync.Mutex
+	now      time.Time
+	nextID   int64
+	projects map[string]*project
+	boards   map[string]*board // by boardKey
+}
+
+// A project is the state of a single GitHub project.
+type project struct {
+	name        string
+	issues      map[int64]*issue
+	comments    []*comment
+	reviews     []*reviewComment
+	events      []*event // in increasing ID order
+	crossRefs   []*crossReference
+	labels      []github.Label
+	milestones  []*github.Milestone // in number order
+	pullReviews []*pullReview
+	checkRuns   []*github.CheckRun
+	files       map[int64][]*github.PullRequestFile // by pull request number
+}
+
+// A pullReview is the JSON form of a pull request review served by the fake.
+type pullReview struct {
+	ID      int64  `json:"id"`
+	Number  int64  `json:"-"` // pull request number
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+// A board is a project board (a GitHub "project").
+type board struct {
+	title string
+	url   string
+	items []*boardItem
+}
+
+// A boardItem is an issue on a board.
+type boardItem struct {
+	project string
+	issue   int64
+	status  string
+}
+
+// boardKey returns the key in Server.boards for the given board.
+func boardKey(owner string, number int) string {
+	return fmt.Sprintf("%s/%d", owner, number)
+}
+
+// An issue is the JSON form of an issue served by the fake.
+// [github.Issue] lacks the ID, which the sync code requires.
+type issue struct {
+	ID int64 `json:"id"`
+	github.Issue
+}
+
+// A comment is the JSON form of an issue comment served by the fake.
+type comment struct {
+	ID int64 `json:"id"`
+	github.IssueComment
+}
+
+// A reviewComment is the JSON form of a pull request review comment
+// served by the fake.
+type reviewComment struct {
+	ID int64 `json:"id"`
+	github.ReviewComment
+}
+
+// An event is the JSON form of an issue event served by the fake.
+type event struct {
+	github.IssueEvent
+	Issue *eventIssue `json:"issue,omitempty"` // only in the project-wide feed
+}
+
+type eventIssue struct {
+	Number int64 `json:"number"`
+}
+
+// A crossReference is the JSON form of a cross reference
+// in an issue timeline served by the fake.
+type crossReference struct {
+	issue     int64       // number of the referenced issue
+	Event     string      `json:"event"`
+	Actor     github.User `json:"actor"`
+	CreatedAt string      `json:"created_at"`
+	UpdatedAt string      `json:"updated_at"`
+	Source    struct {
+		Type  string `json:"type"`
+		Issue *issue `json:"issue"`
+	} `json:"source"`
+}
+
+// New returns a new, empty Server.
+func New() *Server {
+	return &Server{
+		now:      start,
+		nextID:   1000,
+		projects: make(map[string]*project),
+		boards:   make(map[string]*board),
+	}
+}
+
+// Client returns an HTTP client that sends requests
+// for https://api.github.com to s, without using the network.
+// Requests for other hosts fail.
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: transport{s}}
+}
+
+type transport struct {
+	s *Server
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme+"://"+req.URL.Host != apiURL {
+		return nil, fmt.Errorf("fakegithub: unexpected request for %s", req.URL)
+	}
+	w := httptest.NewRecorder()
+	sreq := req
+	if sreq.Body == nil {
+		// Like a real server, give handlers a non-nil body.
+		sreq = req.Clone(req.Context())
+		sreq.Body = http.NoBody
+	}
+	t.s.ServeHTTP(w, sreq)
+	resp := w.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// tick advances the clock and returns the new time,
+// formatted as in GitHub JSON.
+// s.mu must be held.
+func (s *Server) tick() string {
+	s.now = s.now.Add(time.Second)
+	return s.now.Format(time.RFC3339)
+}
+
+// id returns a new unique ID.
+// s.mu must be held.
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// project returns the named project, creating it if needed.
+// s.mu must be held.
+func (s *Server) project(name string) *project {
+	p := s.projects[name]
+	if p == nil {
+		p = &project{name: name, issues: make(map[int64]*issue)}
+		s.projects[name] = p
+	}
+	return p
+}
+
+// AddIssue adds a copy of iss to the project and returns the copy.
+// It fills in the issue's URLs and timestamps.
+// If iss.Number is zero, AddIssue assigns the next issue number.
+// If iss.State is empty, the issue is open.
+func (s *Server) AddIssue(project string, iss *github.Issue) *github.Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.project(project)
+	x := &issue{ID: s.id(), Issue: *iss}
+	if x.Number == 0 {
+		x.Number = int64(len(p.issues) + 1)
+		for p.issues[x.Number] != nil {
+			x.Number++
+		}
+	}
+	if _, ok := p.issues[x.Number]; ok {
+		panic(fmt.Sprintf("fakegithub: duplicate issue %s#%d", project, x.Number))
+	}
+	x.URL = fmt.Sprintf("%s/repos/%s/issues/%d", apiURL, project, x.Number)
+	x.HTMLURL = fmt.Sprintf("%s/%s/issues/%d", htmlURL, project, x.Number)
+	x.CreatedAt = s.tick()
+	x.UpdatedAt = x.CreatedAt
+	if x.State == "" {
+		x.State = "open"
+	}
+	x.Labels = slices.Clone(x.Labels)
+	p.issues[x.Number] = x
+	c := x.Issue
+	return &c
+}
+
+// AddIssueComment adds a copy of c to the issue and returns the copy.
+// It fills in the comment's URLs and timestamps.
+// AddIssueComment panics if the issue does not exist.
+func (s *Server) AddIssueComment(project string, issue int64, c *github.IssueComment) *github.IssueComment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	x := s.addComment(s.project(project), issue, c)
+	cc := x.IssueComment
+	return &cc
+}
+
+// addComment adds a copy of c to the issue.
+// s.mu must be held.
+func (s *Server) addComment(p *project, issue int64, c *github.IssueComment) *comment {
+	iss := p.issues[issue]
+	if iss == nil {
+		panic(fmt.Sprintf("fakegithub: no issue %s#%d", p.name, issue))
+	}
+	x := &comment{ID: s.id(), IssueComment: *c}
+	x.URL = fmt.Sprintf("%s/repos/%s/issues/comments/%d", apiURL, p.name, x.ID)
+	x.IssueURL = iss.URL
+	x.HTMLURL = fmt.Sprintf("%s#issuecomment-%d", iss.HTMLURL, x.ID)
+	x.CreatedAt = s.tick()
+	x.UpdatedAt = x.CreatedAt
+	iss.UpdatedAt = x.CreatedAt
+	p.comments = append(p.comments, x)
+	return x
+}
+
+// AddReviewComment adds a copy of c to the pull request and returns the copy.
+// It fills in the comment's URLs and timestamps.
+// To add a reply, set c.InReplyTo to the ID of the first comment in the thread,
+// as returned by [github.ReviewComment.CommentID].
+// AddReviewComment panics if the pull request does not exist.
+func (s *Server) AddReviewComment(project string, pr int64, c *github.ReviewComment) *github.ReviewComment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.project(project)
+	iss := p.issues[pr]
+	if iss == nil || iss.PullRequest == nil {
+		panic(fmt.Sprintf("fakegithub: no pull request %s#%d", project, pr))
+	}
+	x := &reviewComment{ID: s.id(), ReviewComment: *c}
+	x.URL = fmt.Sprintf("%s/repos/%s/pulls/comments/%d", apiURL, project, x.ID)
+	x.PullRequestURL = fmt.Sprintf("%s/repos/%s/pulls/%d", apiURL, project, pr)
+	x.HTMLURL = fmt.Sprintf("%s/%s/pull/%d#discussion_r%d", htmlURL, project, pr, x.ID)
+	x.CreatedAt = s.tick()
+	x.UpdatedAt = x.CreatedAt
+	iss.UpdatedAt = x.CreatedAt
+	p.reviews = append(p.reviews, x)
+	rc := x.ReviewComment
+	return &rc
+}
+
+// AddCrossReference records that the source issue or pull request,
+// which must have been added with [Server.AddIssue] (to any project),
+// mentioned the identified issue.
+// It does not change the issue's update time.
+// AddCrossReference panics if either issue does not exist.
+func (s *Server) AddCrossReference(project string, issue int64, source *github.Issue, actor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.project(project)
+	if p.issues[issue] == nil {
+		panic(fmt.Sprintf("fakegithub: no issue %s#%d", project, issue))
+	}
+	sp := s.projects[source.Project()]
+	if sp == nil || sp.issues[source.Number] == nil {
+		panic(fmt.Sprintf("fakegithub: no issue %s#%d", source.Project(), source.Number))
+	}
+	x := &crossReference{issue: issue, Event: "cross-referenced", Actor: github.User{Login: actor}}
+	x.CreatedAt = s.tick()
+	x.UpdatedAt = x.CreatedAt
+	x.Source.Type = "issue"
+	x.Source.Issue = sp.issues[source.Number]
+	p.crossRefs = append(p.crossRefs, x)
+}
+
+// AddLabel adds the label to the project.
+func (s *Server) AddLabel(project string, lab github.Label) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.project(project)
+	p.labels = append(p.labels, lab)
+}
+
+// SetFiles sets the files changed by the pull request,
+// which must already have been added with [Server.AddIssue].
+func (s *Server) SetFiles(project string, pr int64, files []*github.PullRequestFile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.project(project)
+	if p.files == nil {
+		p.files = make(map[int64][]*github.PullRequestFile)
+	}
+	p.files[pr] = slices.Clone(files)
+}
+
+// AddMilestone adds a milestone to the project, numbering it
+// and setting its state to "open" if it is empty,
+// and returns a copy of the milestone.
+// Issues can refer to the milestone in their Milestone field.
+func (s *Server) AddMilestone(project string, m github.Milestone) github.Milestone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.project(project)
+	m.Number = int64(len(p.milestones) + 1)
+	if m.State == "" {
+		m.State = "open"
+	}
+	p.milestones = append(p.milestones, &m)
+	return m
+}
+
+// AddBoardItem adds the issue in the given project to the project board
+// with the given owner, number and title, with the given status.
+// It creates the board if necessary.
+// If the issue is already on the board, AddBoardItem updates its status.
+func (s *Server) AddBoardItem(owner string, number int, title, project string, issue int64, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := boardKey(owner, number)
+	b := s.boards[key]
+	if b == nil {
+		b = &board{title: title, url: fmt.Sprintf("%s/orgs/%s/projects/%d", htmlURL, owner, number)}
+		s.boards[key] = b
+	}
+	for _, item := range b.items {
+		if item.project == project && item.issue == issue {
+			item.status = status
+			return
+		}
+	}
+	b.items = append(b.items, &boardItem{project: project, issue: issue, status: status})
+}
+
+// RemoveBoardItem removes the issue in the given project from the project board
+// with the given owner and number.
+func (s *Server) RemoveBoardItem(owner string, number int, project string, issue int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b := s.boards[boardKey(owner, number)]; b != nil {
+		b.items = slices.DeleteFunc(b.items, func(item *boardItem) bool {
+			return item.project == project && item.issue == issue
+		})
+	}
+}
+
+// Issue returns a copy of the current state of the issue.
+func (s *Server) Issue(project string, number int64) (*github.Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.projects[project]
+	if p == nil || p.issues[number] == nil {
+		return nil, false
+	}
+	x := p.issues[number].Issue
+	x.Labels = slices.Clone(x.Labels)
+	return &x, true
+}
+
+// Comments returns copies of the comments on the issue, oldest first.
+func (s *Server) Comments(project string, number int64) []*github.IssueComment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var cs []*github.IssueComment
+	if p := s.projects[project]; p != nil {
+		for _, c := range p.comments {
+			if c.Issue() == number {
+				x := c.IssueComment
+				cs = append(cs, &x)
+			}
+		}
+	}
+	return cs
+}
+
+// Labels returns a copy of the project's labels.
+func (s *Server) Labels(project string) []github.Label {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p := s.projects[project]; p != nil {
+		return slices.Clone(p.labels)
+	}
+	return nil
+}
+
+// Reviews returns the bodies of the reviews posted on the pull request,
+// oldest first.
+func (s *Server) Reviews(project string, number int64) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var bodies []string
+	if p := s.projects[project]; p != nil {
+		for _, r := range p.pullReviews {
+			if r.Number == number {
+				bodies = append(bodies, r.Body)
+			}
+		}
+	}
+	return bodies
+}
+
+// CheckRuns returns copies of the check runs created in the project,
+// oldest first.
+func (s *Server) CheckRuns(project string) []*github.CheckRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var runs []*github.CheckRun
+	if p := s.projects[project]; p != nil {
+		for _, r := range p.checkRuns {
+			x := *r
+			runs = append(runs, &x)
+		}
+	}
+	return runs
+}
+
+// HeadSHA returns the fake head commit of the pull request.
+func HeadSHA(project string, number int64) string {
+	h := sha256.Sum256(fmt.Appendf(nil, "%s#%d", project, number))
+	return fmt.Sprintf("%x", h[:20])
+}
+
+// addEvent records a new issue event.
+// s.mu must be held.
+func (s *Server) addEvent(p *project, iss *issue, e *github.IssueEvent) {
+	e.ID = s.id()
+	e.URL = fmt.Sprintf("%s/repos/%s/issues/events/%d", apiURL, p.name, e.ID)
+	e.CreatedAt = s.tick()
+	iss.UpdatedAt = e.CreatedAt
+	p.events = append(p.events, &event{IssueEvent: *e, Issue: &eventIssue{Number: iss.Number}})
+}
+
+// ServeHTTP implements [http.Handler], serving the fake GitHub API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/graphql" {
+		if r.Method != "POST" {
+			methodNotAllowed(w)
+			return
+		}
+		s.graphQL(w, r)
+		return
+	}
+
+	// Paths have the form /repos/OWNER/REPO/API...
+	f := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(f) < 4 || f[0] != "repos" {
+		notFound(w)
+		return
+	}
+	p := s.projects[f[1]+"/"+f[2]]
+	if p == nil {
+		notFound(w)
+		return
+	}
+	route := r.Method + " " + strings.Join(f[3:], "/")
+	switch {
+	case route == "GET issues":
+		s.listIssues(w, r, p)
+	case route == "GET issues/comments":
+		s.listComments(w, r, p)
+	case route == "GET issues/events":
+		s.listEvents(w, r, p, 0)
+	case route == "GET pulls/comments":
+		s.listReviewComments(w, r, p)
+	case route == "GET milestones":
+		s.listMilestones(w, r, p)
+	case route == "GET labels":
+		serveList(w, r, p.labels)
+	case route == "POST labels":
+		s.createLabel(w, r, p)
+	case route == "POST check-runs":
+		s.createCheckRun(w, r, p)
+	case len(f) >= 5 && f[3] == "pulls":
+		n, err := strconv.ParseInt(f[4], 10, 64)
+		if err != nil || p.issues[n] == nil || p.issues[n].PullRequest == nil {
+			notFound(w)
+			return
+		}
+		switch route = r.Method + " " + strings.Join(f[5:], "/"); route {
+		case "GET ":
+			serveJSON(w, http.StatusOK, map[string]any{"number": n, "head": map[string]string{"sha": HeadSHA(p.name, n)}})
+		case "GET files":
+			serveList(w, r, p.files[n])
+		case "POST reviews":
+			s.postReview(w, r, p, n)
+		default:
+			notFound(w)
+		}
+	case len(f) == 5 && f[3] == "labels":
+		s.label(w, r, p, f[4])
+	case len(f) == 6 && f[3] == "issues" && f[4] == "comments":
+		s.comment(w, r, p, f[5])
+	case len(f) >= 5 && f[3] == "issues":
+		n, err := strconv.ParseInt(f[4], 10, 64)
+		if err != nil || p.issues[n] == nil {
+			notFound(w)
+			return
+		}
+		switch strings.Join(f[5:], "/") {
+		case "":
+			s.issue(w, r, p, p.issues[n])
+		case "events":
+			if r.Method != "GET" {
+				methodNotAllowed(w)
+				return
+			}
+			s.listEvents(w, r, p, n)
+		case "timeline":
+			if r.Method != "GET" {
+				methodNotAllowed(w)
+				return
+			}
+			s.listTimeline(w, r, p, n)
+		case "comments":
+			switch r.Method {
+			case "GET":
+				s.listIssueComments(w, r, p, p.issues[n])
+			case "POST":
+				s.postComment(w, r, p, n)
+			default:
+				methodNotAllowed(w)
+			}
+		default:
+			notFound(w)
+		}
+	default:
+		notFound(w)
+	}
+}
+
+// listIssues serves the issues in the project, sorted by update time.
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request, p *project) {
+	var list []*issue
+	for _, iss := range p.issues {
+		if since(r, iss.UpdatedAt) {
+			list = append(list, iss)
+		}
+	}
+	slices.SortFunc(list, func(x, y *issue) int {
+		if c := strings.Compare(x.UpdatedAt, y.UpdatedAt); c != 0 {
+			return c
+		}
+		return int(x.Number - y.Number)
+	})
+	serveList(w, r, list)
+}
+
+// listComments serves the comments in the project, sorted by update time.
+func (s *Server) listComments(w http.ResponseWriter, r *http.Request, p *project) {
+	var list []*comment
+	for _, c := range p.comments {
+		if since(r, c.UpdatedAt) {
+			list = append(list, c)
+		}
+	}
+	slices.SortStableFunc(list, func(x, y *comment) int {
+		return strings.Compare(x.UpdatedAt, y.UpdatedAt)
+	})
+	serveList(w, r, list)
+}
+
+// listIssueComments serves the comments on iss, oldest first.
+func (s *Server) listIssueComments(w http.ResponseWriter, r *http.Request, p *project, iss *issue) {
+	var list []*comment
+	for _, c := range p.comments {
+		if c.IssueURL == iss.URL && since(r, c.UpdatedAt) {
+			list = append(list, c)
+		}
+	}
+	serveList(w, r, list)
+}
+
+// listReviewComments serves the pull request review comments
+// in the project, sorted by update time.
+func (s *Server) listReviewComments(w http.ResponseWriter, r *http.Request, p *project) {
+	var list []*reviewComment
+	for _, c := range p.reviews {
+		if since(r, c.UpdatedAt) {
+			list = append(list, c)
+		}
+	}
+	slices.SortStableFunc(list, func(x, y *reviewComment) int {
+		return strings.Compare(x.UpdatedAt, y.UpdatedAt)
+	})
+	serveList(w, r, list)
+}
+
+// listMilestones serves the milestones in the project
+// with the requested state (default "open"), in number order.
+func (s *Server) listMilestones(w http.ResponseWriter, r *http.Request, p *project) {
+	state := r.FormValue("state")
+	if state == "" {
+		state = "open"
+	}
+	list := []*github.Milestone{}
+	for _, m := range p.milestones {
+		if state == "all" || m.State == state {
+			list = append(list, m)
+		}
+	}
+	serveList(w, r, list)
+}
+
+// since reports whether the time t is at or after
+// the request's "since" parameter, if any.
+func since(r *http.Request, t string) bool {
+	s := r.FormValue("since")
+	return s == "" || t >= s
+}
+
+// listEvents serves the events in the project.
+// If number is 0, it serves the project-wide feed, newest first.
+// Otherwise it serves the events for the given issue, oldest first,
+// without the issue field, like GitHub does.
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request, p *project, number int64) {
+	var list []any
+	if number == 0 {
+		for _, e := range slices.Backward(p.events) {
+			list = append(list, e)
+		}
+	} else {
+		for _, e := range p.events {
+			if e.Issue.Number == number {
+				list = append(list, &e.IssueEvent)
+			}
+		}
+	}
+	serveList(w, r, list)
+}
+
+// listTimeline serves the timeline of the given issue, oldest first.
+// The timeline has the issue's events and cross references
+// but, unlike GitHub's, not its comments.
+func (s *Server) listTimeline(w http.ResponseWriter, r *http.Request, p *project, number int64) {
+	type entry struct {
+		created string
+		val     any
+	}
+	var entries []entry
+	for _, e := range p.events {
+		if e.Issue.Number == number {
+			entries = append(entries, entry{e.CreatedAt, &e.IssueEvent})
+		}
+	}
+	for _, x := range p.crossRefs {
+		if x.issue == number {
+			entries = append(entries, entry{x.CreatedAt, x})
+		}
+	}
+	slices.SortStableFunc(entries, func(x, y entry) int {
+		return strings.Compare(x.created, y.created)
+	})
+	list := []any{}
+	for _, e := range entries {
+		list = append(list, e.val)
+	}
+	serveList(w, r, list)
+}
+
+// issue serves GET and PATCH requests for an issue.
+func (s *Server) issue(w http.ResponseWriter, r *http.Request, p *project, iss *issue) {
+	switch r.Method {
+	case "GET":
+		serveJSON(w, http.StatusOK, iss)
+	case "PATCH":
+		var ch github.IssueChanges
+		if !decode(w, r, &ch) {
+			return
+		}
+		if ch.Title != "" && ch.Title != iss.Title {
+			s.addEvent(p, iss, &github.IssueEvent{Event: "renamed", Rename: github.Rename{From: iss.Title, To: ch.Title}})
+			iss.Title = ch.Title
+		}
+		if ch.Body != "" {
+			iss.Body = ch.Body
+			iss.UpdatedAt = s.tick()
+		}
+		if ch.State != "" && ch.State != iss.State {
+			switch ch.State {
+			case "closed":
+				s.addEvent(p, iss, &github.IssueEvent{Event: "closed"})
+				iss.ClosedAt = iss.UpdatedAt
+			case "open":
+				s.addEvent(p, iss, &github.IssueEvent{Event: "reopened"})
+				iss.ClosedAt = ""
+			default:
+				serveError(w, http.StatusUnprocessableEntity, "invalid state %q", ch.State)
+				return
+			}
+			iss.State = ch.State
+		}
+		if ch.Labels != nil {
+			s.setLabels(p, iss, *ch.Labels)
+		}
+		serveJSON(w, http.StatusOK, iss)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// setLabels sets the issue's labels to the named labels,
+// creating any that do not exist and recording
+// "labeled" and "unlabeled" events.
+func (s *Server) setLabels(p *project, iss *issue, names []string) {
+	var labels []github.Label
+	for _, name := range names {
+		i := slices.IndexFunc(p.labels, func(l github.Label) bool { return l.Name == name })
+		if i < 0 {
+			p.labels = append(p.labels, github.Label{Name: name, Color: "ededed"})
+			i = len(p.labels) - 1
+		}
+		labels = append(labels, p.labels[i])
+	}
+	has := func(list []github.Label, name string) bool {
+		return slices.ContainsFunc(list, func(l github.Label) bool { return l.Name == name })
+	}
+	for _, l := range iss.Labels {
+		if !has(labels, l.Name) {
+			s.addEvent(p, iss, &github.IssueEvent{Event: "unlabeled", Label: l})
+		}
+	}
+	for _, l := range labels {
+		if !has(iss.Labels, l.Name) {
+			s.addEvent(p, iss, &github.IssueEvent{Event: "labeled", Label: l})
+		}
+	}
+	iss.Labels = labels
+}
+
+// postComment serves a request to add a comment to an issue.
+func (s *Server) postComment(w http.ResponseWriter, r *http.Request, p *project, number int64) {
+	var ch github.IssueCommentChanges
+	if !decode(w, r, &ch) {
+		return
+	}
+	if ch.Body == "" {
+		serveError(w, http.StatusUnprocessableEntity, "missing body")
+		return
+	}
+	c := s.addComment(p, number, &github.IssueComment{Body: ch.Body, User: github.User{Login: user(r)}})
+	serveJSON(w, http.StatusCreated, c)
+}
+
+// comment serves GET and PATCH requests for an issue comment.
+func (s *Server) comment(w http.ResponseWriter, r *http.Request, p *project, id string) {
+	i := slices.IndexFunc(p.comments, func(c *comment) bool { return strconv.FormatInt(c.ID, 10) == id })
+	if i < 0 {
+		notFound(w)
+		return
+	}
+	c := p.comments[i]
+	switch r.Method {
+	case "GET":
+		serveJSON(w, http.StatusOK, c)
+	case "PATCH":
+		var ch github.IssueCommentChanges
+		if !decode(w, r, &ch) {
+			return
+		}
+		if ch.Body != "" {
+			c.Body = ch.Body
+			c.UpdatedAt = s.tick()
+		}
+		serveJSON(w, http.StatusOK, c)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// postReview serves a request to post a review on a pull request.
+// Only reviews that comment are supported.
+func (s *Server) postReview(w http.ResponseWriter, r *http.Request, p *project, number int64) {
+	var req struct {
+		Body  string `json:"body"`
+		Event string `json:"event"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Event != "COMMENT" || req.Body == "" {
+		serveError(w, http.StatusUnprocessableEntity, "unsupported review: event %q, body %q", req.Event, req.Body)
+		return
+	}
+	id := s.id()
+	rv := &pullReview{
+		ID:      id,
+		Number:  number,
+		Body:    req.Body,
+		State:   "COMMENTED",
+		HTMLURL: fmt.Sprintf("%s/%s/pull/%d#pullrequestreview-%d", htmlURL, p.name, number, id),
+	}
+	p.pullReviews = append(p.pullReviews, rv)
+	s.tick()
+	serveJSON(w, http.StatusOK, rv)
+}
+
+// createCheckRun serves a request to create a check run.
+func (s *Server) createCheckRun(w http.ResponseWriter, r *http.Request, p *project) {
+	var run github.CheckRun
+	if !decode(w, r, &run) {
+		return
+	}
+	if run.Name == "" || run.HeadSHA == "" {
+		serveError(w, http.StatusUnprocessableEntity, "missing name or head_sha")
+		return
+	}
+	p.checkRuns = append(p.checkRuns, &run)
+	id := s.id()
+	s.tick()
+	serveJSON(w, http.StatusCreated, map[string]any{
+		"id":       id,
+		"html_url": fmt.Sprintf("%s/%s/runs/%d", htmlURL, p.name, id),
+	})
+}
+
+// createLabel serves a request to create a label.
+func (s *Server) createLabel(w http.ResponseWriter, r *http.Request, p *project) {
+	var lab github.Label
+	if !decode(w, r, &lab) {
+		return
+	}
+	if lab.Name == "" || slices.ContainsFunc(p.labels, func(l github.Label) bool { return l.Name == lab.Name }) {
+		serveError(w, http.StatusUnprocessableEntity, "invalid or duplicate label %q", lab.Name)
+		return
+	}
+	p.labels = append(p.labels, lab)
+	serveJSON(w, http.StatusCreated, lab)
+}
+
+// label serves GET, PATCH and DELETE requests for a label.
+func (s *Server) label(w http.ResponseWriter, r *http.Request, p *project, name string) {
+	i := slices.IndexFunc(p.labels, func(l github.Label) bool { return l.Name == name })
+	if i < 0 {
+		notFound(w)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		serveJSON(w, http.StatusOK, p.labels[i])
+	case "PATCH":
+		var ch github.LabelChanges
+		if !decode(w, r, &ch) {
+			return
+		}
+		lab := &p.labels[i]
+		if ch.NewName != "" {
+			lab.Name = ch.NewName
+		}
+		if ch.Description != "" {
+			lab.Description = ch.Description
+		}
+		if ch.Color != "" {
+			lab.Color = ch.Color
+		}
+		serveJSON(w, http.StatusOK, lab)
+	case "DELETE":
+		p.labels = slices.Delete(p.labels, i, i+1)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// user returns the login of the user making the request:
+// the user name from basic authentication, or "gopher".
+func user(r *http.Request) string {
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		return u
+	}
+	return "gopher"
+}
+
+// serveList serves one page of list,
+// as selected by the page and per_page parameters,
+// setting the Link header if there are more pages.
+// Like GitHub, it sets an ETag derived from the page's content
+// and serves 304 Not Modified to a request with a matching If-None-Match header.
+func serveList[T any](w http.ResponseWriter, r *http.Request, list []T) {
+	page, _ := strconv.Atoi(r.FormValue("page"))
+	page = max(page, 1)
+	perPage, _ := strconv.Atoi(r.FormValue("per_page"))
+	if perPage <= 0 {
+		perPage = 30
+	}
+	perPage = min(perPage, 100)
+
+	lo := min((page-1)*perPage, len(list))
+	hi := min(lo+perPage, len(list))
+	if hi < len(list) {
+		q := r.URL.Query()
+		q.Set("page", strconv.Itoa(page+1))
+		next := apiURL + r.URL.Path + "?" + q.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next))
+	}
+	page0 := list[lo:hi]
+	if page0 == nil {
+		page0 = []T{}
+	}
+	js, err := json.Marshal(page0)
+	if err != nil {
+		// Unreachable: all values served are JSON-encodable.
+		panic(err)
+	}
+	etag := fmt.Sprintf(`W/"%x"`, sha256.Sum256(js))
+	w.Header().Set("Etag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(js)
+}
+
+// decode decodes the JSON request body into v.
+// If that fails, it serves an error and returns false.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		serveError(w, http.StatusBadRequest, "invalid JSON: %v", err)
+		return false
+	}
+	return true
+}
+
+func serveJSON(w http.ResponseWriter, code int, v any) {
+	js, err := json.Marshal(v)
+	if err != nil {
+		// Unreachable: all values served are JSON-encodable.
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write(js)
+}
+
+func serveError(w http.ResponseWriter, code int, format string, args ...any) {
+	serveJSON(w, code, map[string]string{"message": fmt.Sprintf(format, args...)})
+}
+
+func notFound(w http.ResponseWriter) {
+	serveError(w, http.StatusNotFound, "Not Found")
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	serveError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+}
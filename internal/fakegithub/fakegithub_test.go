@@ -0,0 +1,837 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fakegithub
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+const testProject = "rsc/tmp"
+
+// newClient returns a GitHub client connected to s and using db,
+// with testing mode disabled.
+func newClient(t *testing.T, s *Server, db storage.DB) *github.Client {
+	lg := testutil.Slogger(t)
+	gh := github.New(lg, db, secret.Map{"api.github.com": "gabyhelp:pass"}, s.Client())
+	gh.DisableTesting()
+	gh.SetEditInterval(0)
+	if err := gh.Add(testProject); err != nil {
+		t.Fatal(err)
+	}
+	return gh
+}
+
+func TestEndToEnd(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	s.AddLabel(testProject, github.Label{Name: "bug", Color: "ff0000"})
+	// Enough issues and comments to need more than one page.
+	for i := range 120 {
+		iss := s.AddIssue(testProject, &github.Issue{Title: "title", Body: "body", User: github.User{Login: "gopher"}})
+		if i%2 == 0 {
+			s.AddIssueComment(testProject, iss.Number, &github.IssueComment{Body: "comment", User: github.User{Login: "rsc"}})
+		}
+	}
+
+	db := storage.MemDB()
+	gh := newClient(t, s, db)
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+
+	iss, err := github.LookupIssue(db, testProject, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := s.Issue(testProject, 1); !cmp.Equal(iss, want) {
+		t.Fatalf("synced issue differs from server (-server +synced):\n%s", cmp.Diff(want, iss))
+	}
+	count := 0
+	for range github.LookupIssues(db, testProject, 0, -1) {
+		count++
+	}
+	if count != 120 {
+		t.Errorf("synced %d issues, want 120", count)
+	}
+	var comments []*github.IssueComment
+	for c := range gh.Comments(iss) {
+		comments = append(comments, c)
+	}
+	if diff := cmp.Diff(s.Comments(testProject, 1), comments); diff != "" {
+		t.Errorf("synced comments differ from server (-server +synced):\n%s", diff)
+	}
+
+	// Edit using the real HTTP code paths.
+	url, htmlURL, err := gh.PostIssueComment(ctx, iss, &github.IssueCommentChanges{Body: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := s.Comments(testProject, 1)
+	if len(cs) != 2 || cs[1].URL != url || cs[1].HTMLURL != htmlURL || cs[1].Body != "hello" || cs[1].User.Login != "gabyhelp" {
+		t.Fatalf("after PostIssueComment(%q, %q), comments = %+v", url, htmlURL, cs)
+	}
+	c, err := gh.DownloadIssueComment(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.EditIssueComment(ctx, c, &github.IssueCommentChanges{Body: "hello, world"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.EditIssue(ctx, iss, &github.IssueChanges{State: "closed", Labels: &[]string{"bug"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.CreateLabel(ctx, testProject, github.Label{Name: "new", Color: "00ff00"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.EditLabel(ctx, testProject, "new", github.LabelChanges{NewName: "newer"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := s.Issue(testProject, 1)
+	if got.State != "closed" || len(got.Labels) != 1 || got.Labels[0].Name != "bug" {
+		t.Errorf("after EditIssue, issue = %+v", got)
+	}
+	wantLabels := []github.Label{{Name: "bug", Color: "ff0000"}, {Name: "newer", Color: "00ff00"}}
+	if diff := cmp.Diff(wantLabels, s.Labels(testProject)); diff != "" {
+		t.Errorf("labels (-want +got):\n%s", diff)
+	}
+	if lab, err := gh.DownloadLabel(ctx, testProject, "newer"); err != nil || lab != wantLabels[1] {
+		t.Errorf("DownloadLabel(newer) = %+v, %v, want %+v, nil", lab, err, wantLabels[1])
+	}
+
+	// Sync again and check that the edits are visible.
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	iss, err = github.LookupIssue(db, testProject, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iss.State != "closed" {
+		t.Errorf("after sync, issue state = %q, want closed", iss.State)
+	}
+	var bodies []string
+	for c := range gh.Comments(iss) {
+		bodies = append(bodies, c.Body)
+	}
+	if want := []string{"comment", "hello, world"}; !cmp.Equal(bodies, want) {
+		t.Errorf("after sync, comments = %q, want %q", bodies, want)
+	}
+	var events []string
+	for e := range gh.Events(testProject, 1, 1) {
+		if ev, ok := e.Typed.(*github.IssueEvent); ok {
+			events = append(events, ev.Event)
+		}
+	}
+	if want := []string{"closed", "labeled"}; !cmp.Equal(events, want) {
+		t.Errorf("after sync, events = %q, want %q", events, want)
+	}
+}
+
+func TestReviewComments(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	pr := s.AddIssue(testProject, &github.Issue{Title: "fix bug", PullRequest: new(struct{})})
+	first := s.AddReviewComment(testProject, pr.Number, &github.ReviewComment{Body: "why?", Path: "x.go", Line: 3})
+	s.AddReviewComment(testProject, pr.Number, &github.ReviewComment{Body: "other", Path: "y.go", Line: 1})
+
+	db := storage.MemDB()
+	gh := newClient(t, s, db)
+	// Review comments are not synced by default.
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	iss, err := github.LookupIssue(db, testProject, pr.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(gh.ReviewThreads(iss)); n != 0 {
+		t.Fatalf("before EnableReviewComments, got %d review threads, want 0", n)
+	}
+
+	if err := gh.EnableReviewComments(testProject); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	// Incremental sync picks up replies.
+	s.AddReviewComment(testProject, pr.Number, &github.ReviewComment{Body: "because", Path: "x.go", Line: 3, InReplyTo: first.CommentID()})
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][]string
+	for _, th := range gh.ReviewThreads(iss) {
+		var bodies []string
+		for _, rc := range th.Comments {
+			if rc.PullRequest() != pr.Number {
+				t.Errorf("review comment %s: PullRequest() = %d, want %d", rc.URL, rc.PullRequest(), pr.Number)
+			}
+			bodies = append(bodies, rc.Body)
+		}
+		got = append(got, append([]string{th.Path}, bodies...))
+	}
+	want := [][]string{{"x.go", "why?", "because"}, {"y.go", "other"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("review threads (-want +got):\n%s", diff)
+	}
+
+	if err := gh.EnableReviewComments("unknown/project"); err == nil {
+		t.Error("EnableReviewComments(unknown project) succeeded, want error")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := New()
+	s.AddIssue(testProject, &github.Issue{Title: "title"})
+	for _, tt := range []struct {
+		method, path string
+		code         int
+	}{
+		{"GET", "/repos/rsc/tmp/issues/1", 200},
+		{"GET", "/repos/rsc/tmp/issues/2", 404},
+		{"GET", "/repos/rsc/other/issues", 404},
+		{"DELETE", "/repos/rsc/tmp/issues/1", 405},
+		{"GET", "/repos/rsc/tmp/labels/missing", 404},
+		{"POST", "/graphql", 400}, // no query
+		{"GET", "/graphql", 405},
+	} {
+		req, err := http.NewRequest(tt.method, "https://api.github.com"+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := s.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.code {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, resp.StatusCode, tt.code)
+		}
+	}
+	if _, err := s.Client().Get("https://example.com/"); err == nil {
+		t.Errorf("Get(example.com) succeeded, want error")
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	// Enough issues to need more than one page,
+	// and an issue with enough comments to need more than one page.
+	m := s.AddMilestone(testProject, github.Milestone{Title: "Go1.25", DueOn: "2025-08-01T07:00:00Z"})
+	for i := range 120 {
+		iss := s.AddIssue(testProject, &github.Issue{
+			Title:  "title",
+			Body:   "body",
+			User:   github.User{Login: "gopher"},
+			Labels: []github.Label{{Name: "bug", Color: "ff0000"}},
+		})
+		if i%3 == 0 {
+			iss.Milestone = m
+		}
+		if i%2 == 0 {
+			s.AddIssueComment(testProject, iss.Number, &github.IssueComment{Body: "comment", User: github.User{Login: "rsc"}})
+		}
+	}
+	pr := s.AddIssue(testProject, &github.Issue{Title: "fix", User: github.User{Login: "ghost"}, State: "closed", PullRequest: new(struct{})})
+	// The fake's REST API reports minimization too, so that the databases agree.
+	s.AddIssueComment(testProject, pr.Number, &github.IssueComment{Body: "off topic", User: github.User{Login: "gabyhelp"}, Minimized: true, MinimizedReason: "off-topic"})
+	for range 150 {
+		s.AddIssueComment(testProject, 1, &github.IssueComment{Body: "more", User: github.User{Login: "gopher"}})
+	}
+
+	// Sync the same server with REST and with GraphQL
+	// and check that the databases agree.
+	restDB := storage.MemDB()
+	rest := newClient(t, s, restDB)
+	if err := rest.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+
+	var n counter
+	db := storage.MemDB()
+	lg := testutil.Slogger(t)
+	gh := github.New(lg, db, secret.Empty(), &http.Client{Transport: &n})
+	n.rt = s.Client().Transport
+	gh.DisableTesting()
+	if err := gh.Add(testProject); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.EnableGraphQL(testProject); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	// The project has no issue events, so each SyncProject is a full sync,
+	// which lists the issues a second time.
+	// The first listing takes 2 pages of issues and 1 more page
+	// of comments for issue 1; the second listing takes 1 page.
+	if got := n.graphQL; got != 4 {
+		t.Errorf("initial sync made %d GraphQL requests, want 4", got)
+	}
+	diffIssues(t, s, restDB, db)
+
+	// Incremental sync only fetches what changed.
+	n.graphQL = 0
+	s.AddIssueComment(testProject, pr.Number, &github.IssueComment{Body: "late", User: github.User{Login: "rsc"}})
+	s.AddIssue(testProject, &github.Issue{Title: "new", User: github.User{Login: "rsc"}})
+	if err := rest.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	if got := n.graphQL; got != 2 {
+		t.Errorf("incremental sync made %d GraphQL requests, want 2", got)
+	}
+	diffIssues(t, s, restDB, db)
+
+	// An edit to an old comment on an updated issue is synced
+	// even if the comment is not on the first page of comments.
+	old := s.Comments(testProject, 1)[0]
+	if err := rest.EditIssueComment(ctx, old, &github.IssueCommentChanges{Body: "edited"}); err != nil {
+		t.Fatal(err)
+	}
+	s.AddIssueComment(testProject, 1, &github.IssueComment{Body: "newer", User: github.User{Login: "rsc"}})
+	if err := rest.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := gh.LookupIssueCommentURL(old.HTMLURL); err != nil || c.Body != "edited" {
+		t.Errorf("edited comment %s not synced: %v, %v", old.HTMLURL, c, err)
+	}
+	diffIssues(t, s, restDB, db)
+
+	if err := gh.EnableGraphQL("unknown/project"); err == nil {
+		t.Error("EnableGraphQL(unknown project) succeeded, want error")
+	}
+}
+
+func TestMilestonesAndBoards(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	go125 := s.AddMilestone(testProject, github.Milestone{Title: "Go1.25", DueOn: "2025-08-01T07:00:00Z"})
+	go124 := s.AddMilestone(testProject, github.Milestone{Title: "Go1.24", State: "closed"})
+	iss1 := s.AddIssue(testProject, &github.Issue{Title: "proposal: x", User: github.User{Login: "gopher"}, Milestone: go125})
+	iss2 := s.AddIssue(testProject, &github.Issue{Title: "proposal: y", User: github.User{Login: "gopher"}})
+	s.AddBoardItem("golang", 17, "Proposals", testProject, iss1.Number, "Active")
+	s.AddBoardItem("golang", 17, "Proposals", "golang/other", iss2.Number, "Hold")
+
+	db := storage.MemDB()
+	gh := newClient(t, s, db)
+	if err := gh.EnableMilestones(testProject); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.EnableBoard(testProject, "golang", 17); err != nil {
+		t.Fatal(err)
+	}
+	sync := func() {
+		t.Helper()
+		if err := gh.SyncProject(ctx, testProject); err != nil {
+			t.Fatal(err)
+		}
+	}
+	boards := func(n int64) []*github.BoardItem {
+		t.Helper()
+		iss, err := github.LookupIssue(db, testProject, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gh.Boards(iss)
+	}
+	sync()
+
+	var milestones []github.Milestone
+	for m := range gh.Milestones(testProject) {
+		milestones = append(milestones, *m)
+	}
+	if diff := cmp.Diff([]github.Milestone{go125, go124}, milestones); diff != "" {
+		t.Errorf("Milestones mismatch (-want +got):\n%s", diff)
+	}
+	if iss, _ := github.LookupIssue(db, testProject, iss1.Number); iss.Milestone != go125 {
+		t.Errorf("issue milestone = %+v, want %+v", iss.Milestone, go125)
+	}
+
+	proposals := "https://github.com/orgs/golang/projects/17"
+	want := []*github.BoardItem{{Board: "Proposals", URL: proposals, Status: "Active"}}
+	if diff := cmp.Diff(want, boards(iss1.Number)); diff != "" {
+		t.Errorf("Boards(1) mismatch (-want +got):\n%s", diff)
+	}
+	// The item for the other repository's issue 2 is ignored.
+	if got := boards(iss2.Number); len(got) != 0 {
+		t.Errorf("Boards(2) = %v, want none", got)
+	}
+
+	// Board changes are seen.
+	s.RemoveBoardItem("golang", 17, testProject, iss1.Number)
+	s.AddBoardItem("golang", 17, "Proposals", testProject, iss2.Number, "Incoming")
+	sync()
+	if got := boards(iss1.Number); len(got) != 0 {
+		t.Errorf("Boards(1) after removal = %v, want none", got)
+	}
+	want = []*github.BoardItem{{Board: "Proposals", URL: proposals, Status: "Incoming"}}
+	if diff := cmp.Diff(want, boards(iss2.Number)); diff != "" {
+		t.Errorf("Boards(2) mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := gh.EnableBoard(testProject, "golang", 99); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.SyncProject(ctx, testProject); err == nil {
+		t.Error("SyncProject with missing board succeeded, want error")
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	old1 := s.AddIssue(testProject, &github.Issue{Title: "old 1", User: github.User{Login: "gopher"}})
+	s.AddIssue(testProject, &github.Issue{Title: "old 2", User: github.User{Login: "gopher"}})
+	s.AddIssueComment(testProject, old1.Number, &github.IssueComment{Body: "old comment", User: github.User{Login: "rsc"}})
+	recent := s.AddIssue(testProject, &github.Issue{Title: "recent", User: github.User{Login: "gopher"}})
+	s.AddIssueComment(testProject, recent.Number, &github.IssueComment{Body: "recent comment", User: github.User{Login: "rsc"}})
+	start, err := time.Parse(time.RFC3339, recent.CreatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := storage.MemDB()
+	gh := github.New(testutil.Slogger(t), db, secret.Map{"api.github.com": "gabyhelp:pass"}, s.Client())
+	gh.DisableTesting()
+	if err := gh.AddSince(testProject, start); err != nil {
+		t.Fatal(err)
+	}
+	check := func(wantIssues []string, wantComments int) {
+		t.Helper()
+		var titles []string
+		comments := 0
+		for iss := range github.LookupIssues(db, testProject, 0, -1) {
+			titles = append(titles, iss.Title)
+			comments += len(slices.Collect(gh.Comments(iss)))
+		}
+		if !slices.Equal(titles, wantIssues) || comments != wantComments {
+			t.Errorf("synced issues %q with %d comments, want %q with %d", titles, comments, wantIssues, wantComments)
+		}
+	}
+
+	// The first sync only imports recent items.
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	check([]string{"recent"}, 1)
+
+	// A floor after the sync start imports nothing.
+	if err := gh.Backfill(ctx, testProject, start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	check([]string{"recent"}, 1)
+
+	// Backfill imports the rest.
+	if err := gh.Backfill(ctx, testProject, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	all := []string{"old 1", "old 2", "recent"}
+	check(all, 2)
+
+	// Later syncs and backfills import nothing twice.
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.Backfill(ctx, testProject, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	check(all, 2)
+}
+
+func TestSyncConfig(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	body := strings.Repeat("é", 1000) // 2000 bytes
+	iss := s.AddIssue(testProject, &github.Issue{Title: "long", Body: body, User: github.User{Login: "gopher"}})
+	s.AddIssueComment(testProject, iss.Number, &github.IssueComment{Body: "short", User: github.User{Login: "rsc"},
+		Reactions: &github.Reactions{TotalCount: 1, PlusOne: 1}})
+
+	db := storage.MemDB()
+	gh := newClient(t, s, db)
+	cfg := github.SyncConfig{SkipReactions: true, SkipEvents: true, MaxBodyKB: 1}
+	if err := gh.SetSyncConfig(testProject, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := gh.SyncConfig(testProject); err != nil || got != cfg {
+		t.Fatalf("SyncConfig() = %+v, %v, want %+v, nil", got, err, cfg)
+	}
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	// Closing the issue creates an issue event.
+	if err := gh.EditIssue(ctx, iss, &github.IssueChanges{State: "closed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+
+	var issues, comments int
+	for e := range gh.Events(testProject, iss.Number, iss.Number) {
+		switch x := e.Typed.(type) {
+		case *github.Issue:
+			issues++
+			if len(x.Body) > 1024+20 || !strings.HasPrefix(body, strings.TrimSuffix(x.Body, "\n\n[…truncated]")) {
+				t.Errorf("stored body has %d bytes, want truncated prefix of at most 1024", len(x.Body))
+			}
+			if got := gh.FullBody(e); got != body {
+				t.Errorf("FullBody(issue) has %d bytes, want %d", len(got), len(body))
+			}
+		case *github.IssueComment:
+			comments++
+			if x.Reactions != nil {
+				t.Errorf("stored comment has reactions %+v, want none", x.Reactions)
+			}
+			if got := gh.FullBody(e); got != "short" {
+				t.Errorf("FullBody(comment) = %q, want %q", got, "short")
+			}
+		case *github.IssueEvent:
+			t.Errorf("stored issue event %q, want none", x.Event)
+		}
+	}
+	if issues != 1 || comments != 1 {
+		t.Errorf("stored %d issues and %d comments, want 1 and 1", issues, comments)
+	}
+	if err := gh.SetSyncConfig("unknown/project", cfg); err == nil {
+		t.Error("SetSyncConfig(unknown project) succeeded, want error")
+	}
+}
+
+func TestAsOf(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	iss := s.AddIssue(testProject, &github.Issue{Title: "title", User: github.User{Login: "gopher"}})
+	db := storage.MemDB()
+	gh := newClient(t, s, db)
+	sync := func() timed.DBTime {
+		t.Helper()
+		if err := gh.SyncProject(ctx, testProject); err != nil {
+			t.Fatal(err)
+		}
+		return timed.DBTimeAt(time.Now())
+	}
+	t1 := sync()
+	if err := gh.EditIssue(ctx, iss, &github.IssueChanges{State: "closed"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := gh.PostIssueComment(ctx, iss, &github.IssueCommentChanges{Body: "closing"}); err != nil {
+		t.Fatal(err)
+	}
+	t2 := sync()
+
+	for _, tt := range []struct {
+		t        timed.DBTime
+		state    string
+		comments int
+	}{
+		{t1, "open", 0},
+		{t2, "closed", 1},
+	} {
+		got, err := github.LookupIssueAsOf(db, testProject, iss.Number, tt.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.State != tt.state {
+			t.Errorf("LookupIssueAsOf(%d).State = %q, want %q", tt.t, got.State, tt.state)
+		}
+		if n := len(slices.Collect(gh.CommentsAsOf(got, tt.t))); n != tt.comments {
+			t.Errorf("CommentsAsOf(%d) has %d comments, want %d", tt.t, n, tt.comments)
+		}
+	}
+	if _, err := github.LookupIssueAsOf(db, testProject, iss.Number, t1-timed.DBTime(time.Hour)); err == nil {
+		t.Errorf("LookupIssueAsOf before sync succeeded, want error")
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	iss := s.AddIssue(testProject, &github.Issue{Title: "bug", User: github.User{Login: "gopher"}})
+	pr := s.AddIssue(testProject, &github.Issue{Title: "fix bug", User: github.User{Login: "gopher"}, PullRequest: &struct{}{}})
+	other := s.AddIssue("rsc/other", &github.Issue{Title: "same bug", User: github.User{Login: "gopher"}})
+	s.AddCrossReference(testProject, iss.Number, pr, "gopher")
+
+	db := storage.MemDB()
+	gh := newClient(t, s, db)
+	if err := gh.EnableTimeline(testProject); err != nil {
+		t.Fatal(err)
+	}
+	sync := func() {
+		t.Helper()
+		if err := gh.SyncProject(ctx, testProject); err != nil {
+			t.Fatal(err)
+		}
+	}
+	type entry struct{ Event, Actor, Source string }
+	timeline := func() []entry {
+		t.Helper()
+		var list []entry
+		for e := range gh.Timeline(iss) {
+			x := entry{Event: e.Event, Actor: e.Actor.Login}
+			if e.CrossReference != nil {
+				x.Source = e.CrossReference.Source.Issue.HTMLURL
+			}
+			list = append(list, x)
+		}
+		return list
+	}
+	sync()
+	want := []entry{{"cross-referenced", "gopher", pr.HTMLURL}}
+	if diff := cmp.Diff(want, timeline()); diff != "" {
+		t.Fatalf("Timeline mismatch (-want +got):\n%s", diff)
+	}
+
+	// A new cross reference is noticed once the issue changes.
+	s.AddCrossReference(testProject, iss.Number, other, "rsc")
+	sync()
+	if diff := cmp.Diff(want, timeline()); diff != "" {
+		t.Fatalf("Timeline before change mismatch (-want +got):\n%s", diff)
+	}
+	if err := gh.EditIssue(ctx, iss, &github.IssueChanges{State: "closed"}); err != nil {
+		t.Fatal(err)
+	}
+	sync()
+	want = append(want, entry{"cross-referenced", "rsc", other.HTMLURL}, entry{"closed", "", ""})
+	if diff := cmp.Diff(want, timeline()); diff != "" {
+		t.Fatalf("Timeline after change mismatch (-want +got):\n%s", diff)
+	}
+
+	// Unchanged cross references are not rewritten.
+	var times []timed.DBTime
+	for e := range gh.EventsByAPI(testProject, iss.Number, "/issues/timeline") {
+		times = append(times, e.DBTime)
+	}
+	if _, _, err := gh.PostIssueComment(ctx, iss, &github.IssueCommentChanges{Body: "fixed"}); err != nil {
+		t.Fatal(err)
+	}
+	sync()
+	var times2 []timed.DBTime
+	for e := range gh.EventsByAPI(testProject, iss.Number, "/issues/timeline") {
+		times2 = append(times2, e.DBTime)
+	}
+	if !slices.Equal(times, times2) || len(times) != 2 {
+		t.Errorf("cross reference DBTimes changed: %v, then %v", times, times2)
+	}
+}
+
+func TestETags(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	for range 3 {
+		iss := s.AddIssue(testProject, &github.Issue{Title: "title", User: github.User{Login: "gopher"}})
+		s.AddIssueComment(testProject, iss.Number, &github.IssueComment{Body: "comment", User: github.User{Login: "rsc"}})
+	}
+
+	var n counter
+	n.rt = s.Client().Transport
+	db := storage.MemDB()
+	gh := github.New(testutil.Slogger(t), db, secret.Empty(), &http.Client{Transport: &n})
+	gh.DisableTesting()
+	if err := gh.Add(testProject); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first sync fetches everything; a sync with nothing new
+	// records the ETags, and the next one sends them.
+	for range 2 {
+		if err := gh.SyncProject(ctx, testProject); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n.notModified = nil
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/repos/rsc/tmp/issues",
+		"/repos/rsc/tmp/issues/comments",
+	}
+	// The project has no issue events, so each SyncProject is a full sync,
+	// which lists the issues a second time.
+	want = append(want, want[0])
+	if diff := cmp.Diff(want, n.notModified); diff != "" {
+		t.Errorf("not modified responses (-want +got):\n%s", diff)
+	}
+
+	// A change is still seen. A new comment also updates its issue,
+	// so neither list is unmodified.
+	n.notModified = nil
+	s.AddIssueComment(testProject, 2, &github.IssueComment{Body: "new", User: github.User{Login: "rsc"}})
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.notModified) != 0 {
+		t.Errorf("not modified responses after change: %v, want none", n.notModified)
+	}
+	iss, err := github.LookupIssue(db, testProject, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bodies []string
+	for c := range gh.Comments(iss) {
+		bodies = append(bodies, c.Body)
+	}
+	if diff := cmp.Diff([]string{"comment", "new"}, bodies); diff != "" {
+		t.Errorf("comments (-want +got):\n%s", diff)
+	}
+}
+
+// diffIssues checks that the issues and comments
+// in the REST-synced database match those in the
+// GraphQL-synced database.
+func diffIssues(t *testing.T, s *Server, restDB, db storage.DB) {
+	t.Helper()
+	collect := func(db storage.DB) (issues []*github.Issue, comments []*github.IssueComment) {
+		gh := github.New(testutil.Slogger(t), db, nil, nil)
+		for iss := range github.LookupIssues(db, testProject, 0, -1) {
+			issues = append(issues, iss)
+			for c := range gh.Comments(iss) {
+				comments = append(comments, c)
+			}
+		}
+		return issues, comments
+	}
+	wantIssues, wantComments := collect(restDB)
+	issues, comments := collect(db)
+	if diff := cmp.Diff(wantIssues, issues); diff != "" {
+		t.Errorf("GraphQL-synced issues differ from REST-synced (-rest +graphql):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantComments, comments); diff != "" {
+		t.Errorf("GraphQL-synced comments differ from REST-synced (-rest +graphql):\n%s", diff)
+	}
+}
+
+// A counter is an [http.RoundTripper] that counts GraphQL requests
+// and Not Modified responses.
+type counter struct {
+	rt          http.RoundTripper
+	graphQL     int
+	notModified []string // paths
+}
+
+func (c *counter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/graphql" {
+		c.graphQL++
+	}
+	resp, err := c.rt.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusNotModified {
+		c.notModified = append(c.notModified, req.URL.Path)
+	}
+	return resp, err
+}
+
+func TestPullReviewsAndCheckRuns(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	pr := s.AddIssue(testProject, &github.Issue{Title: "fix bug", PullRequest: new(struct{})})
+	iss := s.AddIssue(testProject, &github.Issue{Title: "bug"})
+
+	db := storage.MemDB()
+	gh := newClient(t, s, db)
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	pr, err := github.LookupIssue(db, testProject, pr.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, url, err := gh.PostPullReview(ctx, pr, "see also #1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url == "" {
+		t.Errorf("PostPullReview: empty URL")
+	}
+	if diff := cmp.Diff([]string{"see also #1"}, s.Reviews(testProject, pr.Number)); diff != "" {
+		t.Errorf("reviews (-want +got):\n%s", diff)
+	}
+	if n := len(s.Comments(testProject, pr.Number)); n != 0 {
+		t.Errorf("PostPullReview added %d comments", n)
+	}
+
+	if _, err := gh.CreateCheckRun(ctx, pr, "related", &github.CheckRunOutput{Title: "Related", Summary: "see also #1"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []*github.CheckRun{{
+		Name:       "related",
+		HeadSHA:    HeadSHA(testProject, pr.Number),
+		Status:     "completed",
+		Conclusion: "neutral",
+		Output:     github.CheckRunOutput{Title: "Related", Summary: "see also #1"},
+	}}
+	if diff := cmp.Diff(want, s.CheckRuns(testProject)); diff != "" {
+		t.Errorf("check runs (-want +got):\n%s", diff)
+	}
+
+	// Neither works on issues.
+	iss, err = github.LookupIssue(db, testProject, iss.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := gh.PostPullReview(ctx, iss, "x"); err == nil {
+		t.Error("PostPullReview on issue succeeded")
+	}
+	if _, err := gh.CreateCheckRun(ctx, iss, "x", &github.CheckRunOutput{}); err == nil {
+		t.Error("CreateCheckRun on issue succeeded")
+	}
+}
+
+func TestPullRequestFiles(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	pr := s.AddIssue(testProject, &github.Issue{Title: "big change", PullRequest: new(struct{})})
+	var want []*github.PullRequestFile
+	for i := range 150 { // more than one page
+		want = append(want, &github.PullRequestFile{
+			Filename:  fmt.Sprintf("f%d.go", i),
+			Status:    "modified",
+			Additions: 1,
+			Deletions: 1,
+			Patch:     "@@ -1 +1 @@\n-old\n+new",
+		})
+	}
+	s.SetFiles(testProject, pr.Number, want)
+
+	db := storage.MemDB()
+	gh := newClient(t, s, db)
+	if err := gh.SyncProject(ctx, testProject); err != nil {
+		t.Fatal(err)
+	}
+	pr, err := github.LookupIssue(db, testProject, pr.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := gh.PullRequestFiles(ctx, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("PullRequestFiles (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fakegithub
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oscar/internal/github"
+)
+
+// graphQL serves a GraphQL request.
+func (s *Server) graphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	vars := req.Variables
+	var data any
+	switch op := operation(req.Query); op {
+	case "Issues":
+		data = s.searchIssues(str(vars["q"]), num(vars["first"]), str(vars["after"]))
+	case "IssueComments":
+		p := s.projects[str(vars["owner"])+"/"+str(vars["name"])]
+		var iss *issue
+		if p != nil {
+			iss = p.issues[int64(num(vars["number"]))]
+		}
+		if iss == nil {
+			serveGraphQLError(w, "NOT_FOUND", "Could not resolve to an issue or pull request.")
+			return
+		}
+		data = map[string]any{
+			"repository": map[string]any{
+				"issueOrPullRequest": map[string]any{
+					"comments": s.gqlComments(p, iss, num(vars["last"]), str(vars["before"])),
+				},
+			},
+		}
+	case "Board":
+		b := s.boards[boardKey(str(vars["owner"]), num(vars["number"]))]
+		if b == nil {
+			data = map[string]any{"repositoryOwner": nil}
+			break
+		}
+		data = map[string]any{
+			"repositoryOwner": map[string]any{
+				"projectV2": s.gqlBoard(b, num(vars["first"]), str(vars["after"])),
+			},
+		}
+	default:
+		serveGraphQLError(w, "UNKNOWN_OPERATION", "unknown operation "+strconv.Quote(op))
+		return
+	}
+	serveJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// operation returns the operation name of a GraphQL query,
+// as in "query Name(...) {...}".
+func operation(query string) string {
+	name, ok := strings.CutPrefix(strings.TrimSpace(query), "query ")
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexAny(name, "({ "); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// searchIssues serves a search for issues and pull requests.
+// The query must be "repo:OWNER/REPO sort:updated-asc",
+// optionally followed by "updated:>=TIME".
+// Cursors are indexes into the search results.
+func (s *Server) searchIssues(q string, first int, after string) any {
+	var p *project
+	var since string
+	for _, f := range strings.Fields(q) {
+		if name, ok := strings.CutPrefix(f, "repo:"); ok {
+			p = s.projects[name]
+		}
+		if t, ok := strings.CutPrefix(f, "updated:>="); ok {
+			since = t
+		}
+	}
+	var list []*issue
+	if p != nil {
+		for _, iss := range p.issues {
+			if iss.UpdatedAt >= since {
+				list = append(list, iss)
+			}
+		}
+	}
+	slices.SortFunc(list, func(x, y *issue) int {
+		if c := strings.Compare(x.UpdatedAt, y.UpdatedAt); c != 0 {
+			return c
+		}
+		return int(x.Number - y.Number)
+	})
+
+	lo, _ := strconv.Atoi(after)
+	lo = min(lo, len(list))
+	hi := min(lo+first, len(list))
+	var nodes []any
+	for _, iss := range list[lo:hi] {
+		nodes = append(nodes, s.gqlIssue(p, iss, first))
+	}
+	return map[string]any{
+		"search": map[string]any{
+			"pageInfo": map[string]any{
+				"hasNextPage": hi < len(list),
+				"endCursor":   strconv.Itoa(hi),
+			},
+			"nodes": nodes,
+		},
+	}
+}
+
+// gqlIssue returns the GraphQL JSON for iss,
+// including its last n comments.
+func (s *Server) gqlIssue(p *project, iss *issue, n int) any {
+	typename := "Issue"
+	if iss.PullRequest != nil {
+		typename = "PullRequest"
+	}
+	var assignees, labels []any
+	for _, u := range iss.Assignees {
+		assignees = append(assignees, gqlUser(u))
+	}
+	for _, l := range iss.Labels {
+		labels = append(labels, l)
+	}
+	var milestone any
+	if m := iss.Milestone; m.Title != "" {
+		milestone = map[string]any{
+			"number":      m.Number,
+			"title":       m.Title,
+			"description": m.Description,
+			"state":       strings.ToUpper(m.State),
+			"dueOn":       nullable(m.DueOn),
+		}
+	}
+	return map[string]any{
+		"__typename":       typename,
+		"databaseId":       iss.ID,
+		"number":           iss.Number,
+		"url":              iss.HTMLURL,
+		"title":            iss.Title,
+		"body":             iss.Body,
+		"createdAt":        iss.CreatedAt,
+		"updatedAt":        iss.UpdatedAt,
+		"closedAt":         nullable(iss.ClosedAt),
+		"state":            strings.ToUpper(iss.State),
+		"locked":           iss.Locked,
+		"activeLockReason": nullable(strings.ToUpper(iss.ActiveLockReason)),
+		"author":           gqlUser(iss.User),
+		"assignees":        map[string]any{"nodes": assignees},
+		"milestone":        milestone,
+		"labels":           map[string]any{"nodes": labels},
+		"comments":         s.gqlComments(p, iss, n, ""),
+	}
+}
+
+// gqlComments returns the GraphQL JSON for the last n comments
+// on iss before the cursor, which is an index into the issue's comments.
+// An empty cursor means the end of the comments.
+func (s *Server) gqlComments(p *project, iss *issue, n int, before string) any {
+	var list []*comment
+	for _, c := range p.comments {
+		if c.IssueURL == iss.URL {
+			list = append(list, c)
+		}
+	}
+	hi := len(list)
+	if before != "" {
+		hi, _ = strconv.Atoi(before)
+		hi = min(max(hi, 0), len(list))
+	}
+	lo := max(hi-n, 0)
+	var nodes []any
+	for _, c := range list[lo:hi] {
+		nodes = append(nodes, map[string]any{
+			"databaseId": c.ID,
+			"url":        c.HTMLURL,
+			"body":       c.Body,
+			"createdAt":  c.CreatedAt,
+			"updatedAt":  c.UpdatedAt,
+			"author":     gqlUser(c.User),
+
+			"authorAssociation": c.AuthorAssociation,
+
+			"isMinimized":     c.Minimized,
+			"minimizedReason": nullable(c.MinimizedReason),
+		})
+	}
+	return map[string]any{
+		"pageInfo": map[string]any{
+			"hasPreviousPage": lo > 0,
+			"startCursor":     strconv.Itoa(lo),
+		},
+		"nodes": nodes,
+	}
+}
+
+// gqlBoard returns the GraphQL JSON for the first n items on b after the cursor,
+// which is an index into the board's items.
+func (s *Server) gqlBoard(b *board, n int, after string) any {
+	lo, _ := strconv.Atoi(after)
+	lo = min(max(lo, 0), len(b.items))
+	hi := min(lo+n, len(b.items))
+	var nodes []any
+	for _, item := range b.items[lo:hi] {
+		var status any
+		if item.status != "" {
+			status = map[string]any{"name": item.status}
+		}
+		nodes = append(nodes, map[string]any{
+			"isArchived": false,
+			"content": map[string]any{
+				"number":     item.issue,
+				"repository": map[string]any{"nameWithOwner": item.project},
+			},
+			"status": status,
+		})
+	}
+	return map[string]any{
+		"title": b.title,
+		"url":   b.url,
+		"items": map[string]any{
+			"pageInfo": map[string]any{
+				"hasNextPage": hi < len(b.items),
+				"endCursor":   strconv.Itoa(hi),
+			},
+			"nodes": nodes,
+		},
+	}
+}
+
+// gqlUser returns the GraphQL JSON for an author or assignee.
+// GraphQL has no author for deleted ("ghost") accounts.
+func gqlUser(u github.User) any {
+	if u.Login == "ghost" {
+		return nil
+	}
+	return map[string]any{"login": u.Login}
+}
+
+// nullable returns s, or nil (JSON null) if s is empty.
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// str returns the string value of a GraphQL variable, or "".
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// num returns the integer value of a GraphQL variable, or 0.
+func num(v any) int {
+	f, _ := v.(float64)
+	return int(f)
+}
+
+func serveGraphQLError(w http.ResponseWriter, typ, msg string) {
+	serveJSON(w, http.StatusOK, map[string]any{
+		"errors": []any{map[string]string{"type": typ, "message": msg}},
+	})
+}
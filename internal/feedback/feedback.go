@@ -0,0 +1,294 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package feedback collects the emoji votes that GitHub users
+// leave on comments posted by a bot, as a measure of how useful
+// the bot's comments are.
+//
+// The bot's comments ask readers to "emoji vote" on them.
+// A [Collector] tracks the comments posted by the bot, periodically
+// downloads their reactions, and aggregates the 👍 and 👎 votes
+// by kind of comment (see [Collector.Report]).
+package feedback
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/github/wrap"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// A Collector collects feedback on the comments posted by a bot.
+type Collector struct {
+	slog     *slog.Logger
+	db       storage.DB
+	github   *github.Client
+	bot      string
+	name     string
+	projects map[string]bool
+	kinds    []commentMarker
+	maxAge   time.Duration
+	watcher  *timed.Watcher[*github.Event]
+}
+
+// A commentMarker identifies a kind of bot comment by a marker in its body.
+type commentMarker struct {
+	kind, marker string
+}
+
+// New creates and returns a new Collector. It logs to lg, stores state
+// in db, and watches for comments posted by the GitHub user bot using gh.
+// For the purposes of storing its own state, it uses the given name.
+// Future calls to New with the same name will use the same state.
+//
+// Use the [Collector] methods to configure the collection
+// (especially [Collector.EnableProject]) before calling [Collector.Run].
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, bot, name string) *Collector {
+	return &Collector{
+		slog:     lg,
+		db:       db,
+		github:   gh,
+		bot:      bot,
+		name:     name,
+		projects: make(map[string]bool),
+		maxAge:   defaultMaxAge,
+		watcher:  gh.EventWatcher("feedback.Collector:" + name),
+	}
+}
+
+// EnableProject enables the Collector to track comments
+// in the given GitHub project (for example "golang/go").
+func (c *Collector) EnableProject(project string) {
+	c.projects[project] = true
+}
+
+// AddKind configures the Collector to report comments whose body
+// contains marker as being of the given kind (for example "related").
+// Markers are tried in the order they were added.
+// Comments wrapped by the [wrap] package are reported as the kind
+// in their tags. Other comments are reported as kind "other".
+func (c *Collector) AddKind(kind, marker string) {
+	c.kinds = append(c.kinds, commentMarker{kind: kind, marker: marker})
+}
+
+// SetMaxAge sets how long after a comment is posted the Collector
+// keeps refreshing its votes. Votes on older comments are frozen.
+// The default is 30 days.
+func (c *Collector) SetMaxAge(d time.Duration) {
+	c.maxAge = d
+}
+
+const defaultMaxAge = 30 * 24 * time.Hour
+
+// A Comment is a bot comment tracked by a [Collector],
+// along with the votes on it.
+type Comment struct {
+	Project string
+	Issue   int64
+	ID      int64     // the comment ID
+	URL     string    // the comment's API URL
+	HTMLURL string    // the comment's web URL
+	Kind    string    // the kind of comment (see [Collector.AddKind])
+	Created time.Time // when the comment was posted
+
+	// Reactions is the last known summary of reactions to the comment.
+	Reactions github.Reactions
+	// Checked is when Reactions was last refreshed
+	// (the zero time if it never was).
+	Checked time.Time
+}
+
+// Up returns the number of 👍 votes on the comment.
+func (cm *Comment) Up() int { return cm.Reactions.PlusOne }
+
+// Down returns the number of 👎 votes on the comment.
+func (cm *Comment) Down() int { return cm.Reactions.MinusOne }
+
+// Run tracks new comments posted by the bot in the enabled projects,
+// and refreshes the votes on the tracked comments that were posted
+// within the maximum age (see [Collector.SetMaxAge]).
+// It returns the errors, if any, from downloading comments;
+// comments that fail to download are retried on the next run.
+func (c *Collector) Run(ctx context.Context) error {
+	return c.run(ctx, time.Now())
+}
+
+func (c *Collector) run(ctx context.Context, now time.Time) error {
+	c.slog.Info("feedback.Collector start", "name", c.name, "bot", c.bot)
+	defer c.slog.Info("feedback.Collector end", "name", c.name)
+
+	c.track()
+
+	var errs []error
+	for cm := range c.Comments() {
+		if now.Sub(cm.Created) > c.maxAge {
+			continue
+		}
+		ic, err := c.github.DownloadIssueComment(ctx, cm.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("feedback: %s: %w", cm.HTMLURL, err))
+			continue
+		}
+		if ic.Reactions != nil {
+			cm.Reactions = *ic.Reactions
+		}
+		cm.Checked = now
+		c.set(cm)
+	}
+	return errors.Join(errs...)
+}
+
+// track records the comments posted by the bot since the last call.
+func (c *Collector) track() {
+	defer c.watcher.Flush()
+	for e := range c.watcher.Recent() {
+		c.watcher.MarkOld(e.DBTime)
+		if !c.projects[e.Project] || e.API != "/issues/comments" {
+			continue
+		}
+		ic := e.Typed.(*github.IssueComment)
+		if ic.User.Login != c.bot {
+			continue
+		}
+		if _, ok := c.lookup(e.Project, e.Issue, e.ID); ok {
+			// Already tracked; keep the votes.
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, ic.CreatedAt)
+		cm := &Comment{
+			Project: e.Project,
+			Issue:   e.Issue,
+			ID:      e.ID,
+			URL:     ic.URL,
+			HTMLURL: ic.HTMLURL,
+			Kind:    c.kind(ic.Body),
+			Created: created,
+		}
+		if ic.Reactions != nil {
+			cm.Reactions = *ic.Reactions
+		}
+		c.slog.Info("feedback.Collector tracking comment", "name", c.name, "url", cm.HTMLURL, "kind", cm.Kind)
+		c.set(cm)
+	}
+}
+
+// kind returns the kind of a bot comment with the given body.
+func (c *Collector) kind(body string) string {
+	if u := wrap.Parse(strings.TrimSpace(body)); u != nil && u.Kind != "" {
+		return u.Kind
+	}
+	for _, m := range c.kinds {
+		if strings.Contains(body, m.marker) {
+			return m.kind
+		}
+	}
+	return "other"
+}
+
+const commentKind = "feedback.Comment"
+
+func (c *Collector) key(project string, issue, id int64) []byte {
+	return ordered.Encode(commentKind, c.name, project, issue, id)
+}
+
+func (c *Collector) set(cm *Comment) {
+	c.db.Set(c.key(cm.Project, cm.Issue, cm.ID), storage.JSON(cm))
+}
+
+func (c *Collector) lookup(project string, issue, id int64) (*Comment, bool) {
+	val, ok := c.db.Get(c.key(project, issue, id))
+	if !ok {
+		return nil, false
+	}
+	var cm Comment
+	if err := json.Unmarshal(val, &cm); err != nil {
+		// unreachable unless database corruption
+		c.db.Panic("feedback.Collector: cannot unmarshal", "err", err)
+	}
+	return &cm, true
+}
+
+// Comments returns an iterator over the tracked comments,
+// in (project, issue, comment ID) order.
+func (c *Collector) Comments() iter.Seq[*Comment] {
+	return func(yield func(*Comment) bool) {
+		start := ordered.Encode(commentKind, c.name)
+		end := ordered.Encode(commentKind, c.name, ordered.Inf)
+		for key, fn := range c.db.Scan(start, end) {
+			var cm Comment
+			if err := json.Unmarshal(fn(), &cm); err != nil {
+				// unreachable unless database corruption
+				c.db.Panic("feedback.Comments: cannot unmarshal", "key", storage.Fmt(key), "err", err)
+			}
+			if !yield(&cm) {
+				return
+			}
+		}
+	}
+}
+
+// A Report summarizes the votes on a bot's comments.
+type Report struct {
+	Kinds []*KindReport // per-kind summaries, sorted by kind
+	Total KindReport    // summary over all kinds (with empty Kind)
+}
+
+// A KindReport summarizes the votes on one kind of comment.
+type KindReport struct {
+	Kind     string
+	Comments int // number of comments posted
+	Voted    int // number of comments with at least one 👍 or 👎
+	Up       int // total 👍 votes
+	Down     int // total 👎 votes
+}
+
+// Helpful returns the fraction of votes that are 👍,
+// or 0 if there are no votes.
+func (k *KindReport) Helpful() float64 {
+	if k.Up+k.Down == 0 {
+		return 0
+	}
+	return float64(k.Up) / float64(k.Up+k.Down)
+}
+
+func (k *KindReport) add(cm *Comment) {
+	k.Comments++
+	if cm.Up()+cm.Down() > 0 {
+		k.Voted++
+	}
+	k.Up += cm.Up()
+	k.Down += cm.Down()
+}
+
+// Report returns a summary of the votes on the tracked comments.
+func (c *Collector) Report() *Report {
+	r := new(Report)
+	byKind := make(map[string]*KindReport)
+	for cm := range c.Comments() {
+		k := byKind[cm.Kind]
+		if k == nil {
+			k = &KindReport{Kind: cm.Kind}
+			byKind[cm.Kind] = k
+			r.Kinds = append(r.Kinds, k)
+		}
+		k.add(cm)
+		r.Total.add(cm)
+	}
+	slices.SortFunc(r.Kinds, func(x, y *KindReport) int {
+		return cmp.Compare(x.Kind, y.Kind)
+	})
+	return r
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package feedback
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/github/wrap"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+var now = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func TestCollector(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	ctx := context.Background()
+
+	const project = "test/test"
+	tc := gh.Testing()
+	tc.AddIssue(project, &github.Issue{Number: 1})
+	tc.AddIssue(project, &github.Issue{Number: 2})
+
+	bot := github.User{Login: "bot"}
+	recent := now.Add(-time.Hour).Format(time.RFC3339)
+	overview, err := wrap.New("bot", "overview").Wrap("an overview", nil)
+	check(err)
+	c1 := &github.IssueComment{User: bot, CreatedAt: recent, Body: "**Related Issues**\n"}
+	c2 := &github.IssueComment{User: bot, CreatedAt: recent, Body: overview}
+	c3 := &github.IssueComment{User: bot, CreatedAt: now.Add(-60 * 24 * time.Hour).Format(time.RFC3339), Body: "**Related Issues**\n",
+		Reactions: &github.Reactions{TotalCount: 1, MinusOne: 1}}
+	c4 := &github.IssueComment{User: bot, CreatedAt: recent, Body: "something else"}
+	tc.AddIssueComment(project, 1, c1)
+	tc.AddIssueComment(project, 1, &github.IssueComment{User: github.User{Login: "human"}, Body: "**Related Issues**"})
+	tc.AddIssueComment(project, 2, c2)
+	tc.AddIssueComment(project, 2, c3)
+	tc.AddIssueComment(project, 2, c4)
+	tc.AddIssueComment("other/project", 3, &github.IssueComment{User: bot, CreatedAt: recent})
+
+	c := New(lg, db, gh, "bot", "test")
+	c.EnableProject(project)
+	c.AddKind("related", "**Related ")
+
+	// Votes arrive after the comments are posted.
+	tc.SetReactions(c1, &github.Reactions{TotalCount: 3, PlusOne: 2, Heart: 1})
+	tc.SetReactions(c2, &github.Reactions{TotalCount: 1, MinusOne: 1})
+	// Votes on old comments are not refreshed.
+	tc.SetReactions(c3, &github.Reactions{TotalCount: 5, PlusOne: 5})
+	check(c.run(ctx, now))
+
+	var got []string
+	for cm := range c.Comments() {
+		got = append(got, cm.Kind)
+	}
+	if want := []string{"related", "overview", "related", "other"}; !slices.Equal(got, want) {
+		t.Errorf("comment kinds = %v, want %v", got, want)
+	}
+
+	want := &Report{
+		Kinds: []*KindReport{
+			{Kind: "other", Comments: 1},
+			{Kind: "overview", Comments: 1, Voted: 1, Down: 1},
+			{Kind: "related", Comments: 2, Voted: 2, Up: 2, Down: 1},
+		},
+		Total: KindReport{Comments: 4, Voted: 3, Up: 2, Down: 2},
+	}
+	r := c.Report()
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("Report() mismatch (-want +got):\n%s", diff)
+	}
+	if h := r.Kinds[2].Helpful(); h != 2.0/3 {
+		t.Errorf("Helpful() = %v, want 2/3", h)
+	}
+
+	// A second run picks up new votes but does not re-track comments.
+	tc.SetReactions(c1, &github.Reactions{TotalCount: 4, PlusOne: 3, Heart: 1})
+	check(c.run(ctx, now))
+	r = c.Report()
+	if r.Total.Comments != 4 || r.Total.Up != 3 {
+		t.Errorf("after second run: Total = %+v, want 4 comments, 3 up", r.Total)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -28,9 +29,66 @@ type actionLogPage struct {
 	Start, End         endpoint
 	StartTime, EndTime string // formatted times that the endpoints describe
 	Filter             string
+	Query              actionQuery // the raw values of the other filters
+	Statuses           []actions.Status
 	Entries            []*actions.Entry
 }
 
+// actionQuery holds the raw values of the action log filters
+// other than the time range and the filter expression.
+// Empty values match all actions.
+type actionQuery struct {
+	Kind    string // action kind, such as "related.Poster"
+	Project string // GitHub project, such as "golang/go"
+	Issue   string // issue number in Project
+	Status  string // an actions.Status
+}
+
+// formValues populates q from the values in the form.
+func (q *actionQuery) formValues(r *http.Request) {
+	q.Kind = strings.TrimSpace(r.FormValue("kind"))
+	q.Project = strings.TrimSpace(r.FormValue("project"))
+	q.Issue = strings.TrimSpace(r.FormValue("issue"))
+	q.Status = r.FormValue("status")
+}
+
+// match returns a function reporting whether an action log entry
+// matches q and the filter expression f.
+func (q *actionQuery) match(f string) (func(*actions.Entry) bool, error) {
+	filter, err := newFilter(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %v", err)
+	}
+	var issue int64
+	if q.Issue != "" {
+		if q.Project == "" {
+			return nil, errors.New("issue requires project")
+		}
+		issue, err = strconv.ParseInt(q.Issue, 10, 64)
+		if err != nil || issue <= 0 {
+			return nil, fmt.Errorf("invalid issue %q", q.Issue)
+		}
+	}
+	if q.Status != "" && !slices.Contains(actions.Statuses, actions.Status(q.Status)) {
+		return nil, fmt.Errorf("invalid status %q", q.Status)
+	}
+	return func(e *actions.Entry) bool {
+		if q.Kind != "" && e.Kind != q.Kind {
+			return false
+		}
+		if q.Project != "" {
+			project, n, ok := e.Issue()
+			if !ok || project != q.Project || issue != 0 && n != issue {
+				return false
+			}
+		}
+		if q.Status != "" && e.Status() != actions.Status(q.Status) {
+			return false
+		}
+		return filter(e)
+	}, nil
+}
+
 // An endpoint holds the values for a UI component for selecting a point in time.
 type endpoint struct {
 	Radio   string // "fixed", "dur" or "date"
@@ -53,6 +111,8 @@ func (g *Gaby) doActionLog(r *http.Request) (content []byte, status int, err err
 
 	// Fill in the endpoint values from the form on the page.
 	page.Filter = r.FormValue("filter")
+	page.Query.formValues(r)
+	page.Statuses = actions.Statuses
 	page.Start.formValues(r, "start")
 	page.End.formValues(r, "end")
 
@@ -74,11 +134,11 @@ func (g *Gaby) doActionLog(r *http.Request) (content []byte, status int, err err
 
 	// Retrieve and display entries if something was set.
 	if r.FormValue("start") != "" {
-		filter, err := newFilter(page.Filter)
+		match, err := page.Query.match(page.Filter)
 		if err != nil {
-			return nil, http.StatusBadRequest, fmt.Errorf("invalid filter: %v", err)
+			return nil, http.StatusBadRequest, err
 		}
-		page.Entries = g.actionsBetween(startTime, endTime, filter)
+		page.Entries = g.actionsBetween(startTime, endTime, match)
 		for _, e := range page.Entries {
 			e.Created = e.Created.In(loc)
 			e.Done = e.Done.In(loc)
@@ -218,6 +278,14 @@ func (e *endpoint) timeOrDuration(browserLoc *time.Location) (time.Time, time.Du
 
 // actionsBetween returns the action entries between start and end, inclusive.
 func (g *Gaby) actionsBetween(start, end time.Time, filter func(*actions.Entry) bool) []*actions.Entry {
+	es, _ := g.actionsBetweenLimit(start, end, filter, 0)
+	return es
+}
+
+// actionsBetweenLimit is like actionsBetween, but returns at most
+// limit entries (all of them if limit is 0), and reports whether
+// there were more.
+func (g *Gaby) actionsBetweenLimit(start, end time.Time, filter func(*actions.Entry) bool, limit int) (_ []*actions.Entry, more bool) {
 	var es []*actions.Entry
 	// Scan entries created in [start, end].
 	for e := range actions.ScanAfter(g.slog, g.db, start.Add(-time.Nanosecond), nil) {
@@ -225,10 +293,139 @@ func (g *Gaby) actionsBetween(start, end time.Time, filter func(*actions.Entry)
 			break
 		}
 		if filter(e) {
+			if limit > 0 && len(es) == limit {
+				return es, true
+			}
 			es = append(es, e)
 		}
 	}
-	return es
+	return es, false
+}
+
+// Limits on the number of entries returned by /api/actionlog.
+const (
+	defaultActionLogLimit = 100
+	maxActionLogLimit     = 1000
+)
+
+// actionLogResult is the response to /api/actionlog.
+type actionLogResult struct {
+	Entries []*actionLogEntry
+	More    bool // whether there are more matching entries after the last one
+}
+
+// An actionLogEntry is an action log entry in an [actionLogResult].
+type actionLogEntry struct {
+	Created          time.Time
+	Kind             string
+	Key              string // the key, formatted for display
+	KeyHex           string // the key in hex, as used by /action-decision and /action-rerun
+	Project          string `json:",omitempty"`
+	Issue            int64  `json:",omitempty"`
+	Status           actions.Status
+	ApprovalRequired bool
+	Decisions        []actions.Decision `json:",omitempty"`
+	Done             time.Time
+	Action           json.RawMessage // the action, as logged before it ran
+	Result           json.RawMessage // the result, as logged after it ran
+	Error            string          `json:",omitempty"`
+}
+
+// handleActionLogAPI handles GET /api/actionlog, which responds with
+// an [actionLogResult] holding the action log entries created
+// in a time range, in the order they were last changed.
+// The query parameters are:
+//
+//	start, end: the time range in RFC 3339 format (default: the 24 hours before end, and now)
+//	kind, project, issue, status: the filters of the action log page
+//	filter: a filter expression, as on the action log page
+//	limit: the maximum number of entries (default 100, at most 1000)
+func (g *Gaby) handleActionLogAPI(w http.ResponseWriter, r *http.Request) {
+	res, err := g.actionLogAPI(r, time.Now())
+	if err != nil {
+		writeAPIError(w, codeInvalidQuery, err)
+		return
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		writeAPIError(w, codeInternal, fmt.Errorf("json.Marshal: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// actionLogAPI returns the response to the /api/actionlog request r.
+func (g *Gaby) actionLogAPI(r *http.Request, now time.Time) (*actionLogResult, error) {
+	parseTime := func(name string, def time.Time) (time.Time, error) {
+		v := r.FormValue(name)
+		if v == "" {
+			return def, nil
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		return t, nil
+	}
+	end, err := parseTime("end", now)
+	if err != nil {
+		return nil, err
+	}
+	start, err := parseTime("start", end.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, errors.New("end time before start time")
+	}
+	limit := defaultActionLogLimit
+	if v := r.FormValue("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxActionLogLimit {
+			return nil, fmt.Errorf("limit must be in [1, %d] (got: %s)", maxActionLogLimit, v)
+		}
+	}
+	var q actionQuery
+	q.formValues(r)
+	match, err := q.match(r.FormValue("filter"))
+	if err != nil {
+		return nil, err
+	}
+
+	es, more := g.actionsBetweenLimit(start, end, match, limit)
+	res := &actionLogResult{Entries: []*actionLogEntry{}, More: more}
+	for _, e := range es {
+		project, issue, _ := e.Issue()
+		res.Entries = append(res.Entries, &actionLogEntry{
+			Created:          e.Created,
+			Kind:             e.Kind,
+			Key:              storage.Fmt(e.Key),
+			KeyHex:           hex.EncodeToString(e.Key),
+			Project:          project,
+			Issue:            issue,
+			Status:           e.Status(),
+			ApprovalRequired: e.ApprovalRequired,
+			Decisions:        e.Decisions,
+			Done:             e.Done,
+			Action:           jsonPayload(e.Action),
+			Result:           jsonPayload(e.Result),
+			Error:            e.Error,
+		})
+	}
+	return res, nil
+}
+
+// jsonPayload returns b as JSON: b itself if it is JSON,
+// a JSON string otherwise, and null if it is empty.
+func jsonPayload(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return json.RawMessage("null")
+	}
+	if json.Valid(b) {
+		return b
+	}
+	return storage.JSON(string(b))
 }
 
 func (g *Gaby) handleActionDecision(w http.ResponseWriter, r *http.Request) {
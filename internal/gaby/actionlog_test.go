@@ -308,3 +308,119 @@ func TestDoActionDecision(t *testing.T) {
 		})
 	}
 }
+
+func TestActionQuery(t *testing.T) {
+	done := time.Now()
+	entries := []*actions.Entry{
+		{Kind: "a", Key: ordered.Encode("golang/go", 1)},
+		{Kind: "a", Key: ordered.Encode("golang/go", 2), Done: done},
+		{Kind: "b", Key: ordered.Encode("post", "golang/go", 1), Done: done, Error: "bad"},
+		{Kind: "b", Key: ordered.Encode("golang/oscar", 1), ApprovalRequired: true},
+		{Kind: "b", Key: ordered.Encode("other")},
+	}
+	for _, tc := range []struct {
+		q      actionQuery
+		filter string
+		want   []int // indexes in entries
+	}{
+		{actionQuery{}, "", []int{0, 1, 2, 3, 4}},
+		{actionQuery{Kind: "b"}, "", []int{2, 3, 4}},
+		{actionQuery{Project: "golang/go"}, "", []int{0, 1, 2}},
+		{actionQuery{Project: "golang/go", Issue: "1"}, "", []int{0, 2}},
+		{actionQuery{Status: "failed"}, "", []int{2}},
+		{actionQuery{Status: "pending"}, "", []int{3}},
+		{actionQuery{Status: "approved"}, "", []int{0, 4}},
+		{actionQuery{Project: "golang/go"}, "Kind=a", []int{0, 1}},
+	} {
+		match, err := tc.q.match(tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int
+		for i, e := range entries {
+			if match(e) {
+				got = append(got, i)
+			}
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%+v %q: got %v, want %v", tc.q, tc.filter, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		q      actionQuery
+		filter string
+	}{
+		{actionQuery{Issue: "1"}, ""},
+		{actionQuery{Project: "golang/go", Issue: "x"}, ""},
+		{actionQuery{Status: "maybe"}, ""},
+		{actionQuery{}, "Kind="},
+	} {
+		if _, err := tc.q.match(tc.filter); err == nil {
+			t.Errorf("%+v %q: succeeded, want error", tc.q, tc.filter)
+		}
+	}
+}
+
+func TestActionLogAPI(t *testing.T) {
+	const kind = "actionlogapi"
+	db := storage.MemDB()
+	before := actions.Register(kind, testActioner{})
+	g := &Gaby{slog: testutil.Slogger(t), db: db}
+
+	before(db, ordered.Encode("golang/go", 1), []byte(`{"Comment": "hi"}`), true)
+	actions.AddDecision(db, kind, ordered.Encode("golang/go", 1), actions.Decision{Name: "gopher", Approved: false})
+	before(db, ordered.Encode("golang/go", 2), []byte("not json"), false)
+
+	get := func(query string) (*actionLogResult, error) {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/api/actionlog?"+query, nil)
+		return g.actionLogAPI(r, time.Now().Add(time.Minute))
+	}
+
+	res, err := get("kind=" + kind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Entries) != 2 || res.More {
+		t.Fatalf("got %d entries (more=%t), want 2", len(res.Entries), res.More)
+	}
+	e := res.Entries[0]
+	if e.Project != "golang/go" || e.Issue != 1 || e.Status != actions.StatusDenied ||
+		string(e.Action) != `{"Comment": "hi"}` || string(e.Result) != "null" || len(e.Decisions) != 1 {
+		t.Errorf("entry 0 = %+v, want denied comment on golang/go#1", e)
+	}
+	if e := res.Entries[1]; string(e.Action) != `"not json"` || e.Status != actions.StatusApproved {
+		t.Errorf("entry 1 = %+v, want approved with string action", e)
+	}
+
+	res, err = get("kind=" + kind + "&limit=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Entries) != 1 || !res.More || res.Entries[0].Issue != 1 {
+		t.Errorf("limit=1: got %d entries (more=%t), want issue 1 and more", len(res.Entries), res.More)
+	}
+
+	res, err = get("kind=" + kind + "&project=golang/go&issue=2&status=approved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Entries) != 1 || res.Entries[0].Issue != 2 {
+		t.Errorf("issue 2: got %d entries, want issue 2", len(res.Entries))
+	}
+
+	res, err = get("kind=" + kind + "&start=" + time.Now().Add(time.Hour).Format(time.RFC3339) + "&end=" + time.Now().Add(2*time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Entries) != 0 {
+		t.Errorf("future range: got %d entries, want 0", len(res.Entries))
+	}
+
+	for _, query := range []string{"limit=0", "limit=1001", "start=yesterday", "status=maybe", "start=2025-01-02T00:00:00Z&end=2025-01-01T00:00:00Z"} {
+		if _, err := get(query); err == nil {
+			t.Errorf("%s: succeeded, want error", query)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// The JSON APIs (/api/...) report failures with an HTTP error status
+// and a JSON body holding an [apiError], for example
+//
+//	{"Code": "invalid_query", "Message": "limit must be >= 0 (got: -1)"}
+//
+// Clients should branch on the Code, which is one of the errorCode
+// constants below; the Message is for people and may change.
+
+// An errorCode is a stable, machine-readable code for an API failure.
+type errorCode string
+
+const (
+	// The request is malformed or has invalid values.
+	codeInvalidQuery errorCode = "invalid_query"
+	// The request has a missing, invalid or expired API token.
+	codeUnauthorized errorCode = "unauthorized"
+	// The request's API token does not grant access to the
+	// endpoint or project.
+	codeForbidden errorCode = "forbidden"
+	// The request names a project that Gaby does not know about.
+	codeUnknownProject errorCode = "unknown_project"
+	// The LLM service needed for the request is unavailable;
+	// the request may succeed if retried later.
+	codeLLMUnavailable errorCode = "llm_unavailable"
+	// The data needed for the request has not been synced yet;
+	// the request may succeed if retried later.
+	codeNotSynced errorCode = "not_synced"
+	// Any other failure.
+	codeInternal errorCode = "internal"
+)
+
+// status returns the HTTP status code for responses with code c.
+func (c errorCode) status() int {
+	switch c {
+	case codeInvalidQuery:
+		return http.StatusBadRequest
+	case codeUnauthorized:
+		return http.StatusUnauthorized
+	case codeForbidden:
+		return http.StatusForbidden
+	case codeUnknownProject:
+		return http.StatusNotFound
+	case codeLLMUnavailable, codeNotSynced:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// An apiError is the body of a failed API response.
+type apiError struct {
+	Code    errorCode
+	Message string
+}
+
+// writeAPIError writes a failed API response with the given code
+// and err's message.
+func writeAPIError(w http.ResponseWriter, code errorCode, err error) {
+	data, jerr := json.Marshal(&apiError{Code: code, Message: err.Error()})
+	if jerr != nil {
+		// Unreachable: apiError always marshals.
+		http.Error(w, err.Error(), code.status())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code.status())
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+)
+
+func TestSearchAPIErrors(t *testing.T) {
+	g := newTestGaby(t)
+	g.githubProjects = []string{"golang/go", "golang/tools"}
+	g.github.Testing().AddIssue("golang/go", &github.Issue{Number: 1, Title: "hello"})
+	g.docs.Add("https://github.com/golang/go/issues/1", "hello", "hello world")
+	g.embedAll(context.Background())
+
+	for _, tc := range []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   errorCode
+	}{
+		{"ok", `{"Text": "hello", "Sources": ["golang/go", "go.dev"]}`, http.StatusOK, ""},
+		{"bad json", `{"Text": `, http.StatusBadRequest, codeInvalidQuery},
+		{"empty", `{"Text": " "}`, http.StatusBadRequest, codeInvalidQuery},
+		{"bad limit", `{"Text": "hello", "Limit": -1}`, http.StatusBadRequest, codeInvalidQuery},
+		{"unknown project", `{"Text": "hello", "Sources": ["golang/nope"]}`, http.StatusNotFound, codeUnknownProject},
+		{"not synced", `{"Text": "hello", "Sources": ["golang/tools"]}`, http.StatusServiceUnavailable, codeNotSynced},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, code := searchAPI(g, tc.body)
+			if status != tc.wantStatus || code != tc.wantCode {
+				t.Errorf("got (%d, %q), want (%d, %q)", status, code, tc.wantStatus, tc.wantCode)
+			}
+		})
+	}
+
+	t.Run("llm unavailable", func(t *testing.T) {
+		errDown := &llm.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}
+		g.embed = llmAvailability.Embedder(failEmbedder{errDown})
+		defer llmAvailability.Record(nil)
+		// The search below fails once more, making the LLM unavailable.
+		for range llm.DefaultFailureThreshold - 1 {
+			llmAvailability.Record(errDown)
+		}
+
+		status, code := searchAPI(g, `{"Text": "hello"}`)
+		if status != http.StatusServiceUnavailable || code != codeLLMUnavailable {
+			t.Errorf("got (%d, %q), want (%d, %q)", status, code, http.StatusServiceUnavailable, codeLLMUnavailable)
+		}
+	})
+}
+
+// searchAPI calls the search API with the body,
+// returning the response status and error code.
+func searchAPI(g *Gaby, body string) (int, errorCode) {
+	w := httptest.NewRecorder()
+	g.handleSearchAPI(w, httptest.NewRequest("POST", "/api/search", strings.NewReader(body)))
+	if w.Code == http.StatusOK {
+		return w.Code, ""
+	}
+	var e apiError
+	if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil {
+		return w.Code, errorCode("bad body: " + w.Body.String())
+	}
+	return w.Code, e.Code
+}
+
+type failEmbedder struct{ err error }
+
+func (f failEmbedder) EmbedDocs(context.Context, []llm.EmbedDoc) ([]llm.Vector, error) {
+	return nil, f.err
+}
@@ -0,0 +1,220 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/apitoken"
+)
+
+// apiTokensPage holds the fields needed to display the tokens
+// for the /api/gh/ endpoints.
+type apiTokensPage struct {
+	CommonPage
+
+	Params apiTokensParams   // the raw parameters
+	Error  error             // if non-nil, the error from the requested action
+	Done   string            // description of the completed action, if any
+	Secret string            // the secret of the token just issued, if any
+	Tokens []*apitoken.Token // the issued tokens, oldest first
+}
+
+// apiTokensParams holds the raw inputs to the API tokens form.
+type apiTokensParams struct {
+	Action   string // actionIssue or actionRevoke
+	Name     string // who the token is for, such as a GitHub login
+	Scopes   string // comma-separated scopes; empty means all
+	Projects string // comma-separated GitHub projects; empty means all
+	Days     string // days until the token expires (int); 0 means never
+	ID       string // ID of the token to revoke
+}
+
+const (
+	paramTokenAction   = "action"
+	paramTokenName     = "name"
+	paramTokenScopes   = "scopes"
+	paramTokenProjects = "projects"
+	paramTokenDays     = "days"
+	paramTokenID       = "id"
+
+	actionIssue  = "issue"
+	actionRevoke = "revoke"
+
+	// defaultTokenDays is the default lifetime of a token, in days.
+	defaultTokenDays = 90
+)
+
+var (
+	safeTokenName     = toSafeID(paramTokenName)
+	safeTokenScopes   = toSafeID(paramTokenScopes)
+	safeTokenProjects = toSafeID(paramTokenProjects)
+	safeTokenDays     = toSafeID(paramTokenDays)
+	safeTokenID       = toSafeID(paramTokenID)
+)
+
+var apiTokensPageTmpl = newTemplate(apiTokensTmplFile, nil)
+
+func (g *Gaby) handleAPITokens(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateAPITokensPage(r), apiTokensPageTmpl)
+}
+
+// populateAPITokensPage returns the contents of the API tokens page,
+// after issuing or revoking a token as the "action" form value says,
+// if any.
+func (g *Gaby) populateAPITokensPage(r *http.Request) *apiTokensPage {
+	p := &apiTokensPage{
+		Params: apiTokensParams{
+			Action:   r.FormValue(paramTokenAction),
+			Name:     strings.TrimSpace(r.FormValue(paramTokenName)),
+			Scopes:   strings.TrimSpace(r.FormValue(paramTokenScopes)),
+			Projects: strings.TrimSpace(r.FormValue(paramTokenProjects)),
+			Days:     strings.TrimSpace(r.FormValue(paramTokenDays)),
+			ID:       strings.TrimSpace(r.FormValue(paramTokenID)),
+		},
+	}
+	switch {
+	case p.Params.Action == actionRevoke && p.Params.ID != "":
+		p.Error = g.tokens.Revoke(p.Params.ID, requestUser(r))
+		if p.Error == nil {
+			p.Done = "revoked token " + p.Params.ID
+		}
+	case p.Params.Action == actionRevoke && p.Params.Name != "":
+		p.Error = errors.New("the ID of the token to revoke is required")
+	case p.Params.Name != "":
+		var tok *apitoken.Token
+		p.Secret, tok, p.Error = g.issueToken(p.Params, requestUser(r))
+		if p.Error == nil {
+			p.Done = fmt.Sprintf("issued token %s for %s", tok.ID, tok.Name)
+		}
+	}
+	p.Tokens = g.tokens.List()
+	p.setCommonPage()
+	return p
+}
+
+// issueToken issues a token as pm says on behalf of user,
+// returning its secret.
+func (g *Gaby) issueToken(pm apiTokensParams, user string) (string, *apitoken.Token, error) {
+	scopes := apitoken.Scopes
+	if pm.Scopes != "" {
+		scopes = nil
+		for _, s := range splitList(pm.Scopes) {
+			scopes = append(scopes, apitoken.Scope(s))
+		}
+	}
+	projects := splitList(pm.Projects)
+	days := defaultTokenDays
+	if pm.Days != "" {
+		var err error
+		if days, err = strconv.Atoi(pm.Days); err != nil || days < 0 {
+			return "", nil, fmt.Errorf("invalid days %q", pm.Days)
+		}
+	}
+	var expires time.Time
+	if days > 0 {
+		expires = time.Now().Add(time.Duration(days) * 24 * time.Hour)
+	}
+	return g.tokens.Issue(pm.Name, user, scopes, projects, expires)
+}
+
+// splitList splits a comma- or space-separated list.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+func (p *apiTokensPage) setCommonPage() {
+	var scopes []string
+	for _, s := range apitoken.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	p.CommonPage = CommonPage{
+		ID:          apiTokensID,
+		Description: `Issue and revoke the tokens that let contributors use the /api/gh/ endpoints, for example from the "gh oscar" GitHub CLI extension. A token's secret is shown only once, when it is issued.`,
+		Form: Form{
+			Inputs: []FormInput{
+				{
+					Label:       "action",
+					Type:        "radio choice",
+					Description: `"issue" issues a new token; "revoke" revokes the token with the given ID`,
+					Name:        toSafeID(paramTokenAction),
+					Required:    true,
+					Typed: RadioInput{
+						Choices: []RadioChoice{
+							{
+								Label:   actionIssue,
+								ID:      toSafeID(actionIssue),
+								Value:   actionIssue,
+								Checked: p.Params.Action != actionRevoke,
+							},
+							{
+								Label:   actionRevoke,
+								ID:      toSafeID(actionRevoke),
+								Value:   actionRevoke,
+								Checked: p.Params.Action == actionRevoke,
+							},
+						},
+					},
+				},
+				{
+					Label:       "name",
+					Type:        "string",
+					Description: "who the token is for, such as their GitHub login",
+					Name:        safeTokenName,
+					Typed: TextInput{
+						ID:    safeTokenName,
+						Value: p.Params.Name,
+					},
+				},
+				{
+					Label:       "scopes",
+					Type:        "comma-separated list",
+					Description: fmt.Sprintf("the endpoints the token may call, among %s (default: all)", strings.Join(scopes, ", ")),
+					Name:        safeTokenScopes,
+					Typed: TextInput{
+						ID:    safeTokenScopes,
+						Value: p.Params.Scopes,
+					},
+				},
+				{
+					Label:       "projects",
+					Type:        "comma-separated list",
+					Description: `the GitHub projects the token may read, such as "golang/go" (default: all)`,
+					Name:        safeTokenProjects,
+					Typed: TextInput{
+						ID:    safeTokenProjects,
+						Value: p.Params.Projects,
+					},
+				},
+				{
+					Label:       "days",
+					Type:        "int",
+					Description: fmt.Sprintf("days until the token expires; 0 means never (default: %d)", defaultTokenDays),
+					Name:        safeTokenDays,
+					Typed: TextInput{
+						ID:    safeTokenDays,
+						Value: p.Params.Days,
+					},
+				},
+				{
+					Label:       "token ID",
+					Type:        "string",
+					Description: "the ID of the token to revoke",
+					Name:        safeTokenID,
+					Typed: TextInput{
+						ID:    safeTokenID,
+						Value: p.Params.ID,
+					},
+				},
+			},
+			SubmitText: "submit",
+		},
+	}
+}
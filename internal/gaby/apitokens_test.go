@@ -0,0 +1,71 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/apitoken"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestAPITokensPage(t *testing.T) {
+	lg := testutil.Slogger(t)
+	g := &Gaby{slog: lg, db: storage.MemDB(), tokens: apitoken.NewStore(lg, storage.MemDB())}
+
+	populate := func(q url.Values) *apiTokensPage {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/apitokens?"+q.Encode(), nil)
+		r.Header.Set("X-Goog-Authenticated-User-Email", "accounts.google.com:gopher@golang.org")
+		return g.populateAPITokensPage(r)
+	}
+
+	if p := populate(url.Values{paramTokenName: {"gopher"}, paramTokenScopes: {"admin"}}); p.Error == nil {
+		t.Error("issue with unknown scope succeeded, want error")
+	}
+	if p := populate(url.Values{paramTokenName: {"gopher"}, paramTokenDays: {"-1"}}); p.Error == nil {
+		t.Error("issue with negative days succeeded, want error")
+	}
+
+	p := populate(url.Values{
+		paramTokenAction:   {actionIssue},
+		paramTokenName:     {"gopher"},
+		paramTokenScopes:   {"overview, related"},
+		paramTokenProjects: {"golang/go"},
+	})
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if len(p.Tokens) != 1 {
+		t.Fatalf("Tokens = %v, want one", p.Tokens)
+	}
+	tok := p.Tokens[0]
+	if tok.User != "gopher@golang.org" || !slices.Equal(tok.Scopes, []apitoken.Scope{"overview", "related"}) ||
+		!slices.Equal(tok.Projects, []string{"golang/go"}) || tok.Expires.IsZero() {
+		t.Errorf("token = %+v, want overview and related on golang/go, expiring", tok)
+	}
+	if _, err := g.tokens.Authorize(p.Secret, apitoken.ScopeOverview, "golang/go"); err != nil {
+		t.Errorf("Authorize(issued secret) = %v", err)
+	}
+
+	if p := populate(url.Values{paramTokenAction: {actionRevoke}, paramTokenName: {"gopher"}}); p.Error == nil {
+		t.Error("revoke without ID succeeded, want error")
+	}
+	p = populate(url.Values{paramTokenAction: {actionRevoke}, paramTokenID: {tok.ID}})
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if len(p.Tokens) != 0 || p.Secret != "" {
+		t.Errorf("after revoke: Tokens = %v, Secret = %q, want none", p.Tokens, p.Secret)
+	}
+	if p := populate(url.Values{paramTokenAction: {actionRevoke}, paramTokenID: {tok.ID}}); !errors.Is(p.Error, apitoken.ErrNotFound) {
+		t.Errorf("revoke of revoked token: got %v, want ErrNotFound", p.Error)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+
+	"golang.org/x/oscar/internal/approval"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/entity"
+	"golang.org/x/oscar/internal/github"
+)
+
+// Actions that require approval can be approved or denied from
+// the private tracking issue named by the -approvalissue flag,
+// by the GitHub users listed in the -approvers flag.
+// See package approval for details.
+
+// parseApprovalIssue parses the argument to the -approvalissue flag,
+// a GitHub issue such as "golang/oscar-private#1".
+// It returns the issue's project and number.
+func parseApprovalIssue(s string) (project string, issue int64, err error) {
+	id, err := entity.ParseIssue(s)
+	if err != nil || id.Project == "" {
+		return "", 0, fmt.Errorf("invalid arg %q to -approvalissue: want OWNER/REPO#NUMBER", s)
+	}
+	return id.Project, id.Number, nil
+}
+
+// initApprover initializes g.approver from the flags, if set.
+// It adds the tracking issue's project to g.github,
+// so it must be called before the first sync.
+func (g *Gaby) initApprover() error {
+	if flags.approvalIssue == "" {
+		return nil
+	}
+	project, issue, err := parseApprovalIssue(flags.approvalIssue)
+	if err != nil {
+		return err
+	}
+	if flags.approvers == "" {
+		return errors.New("-approvalissue requires -approvers")
+	}
+	if err := g.github.Add(project); err != nil {
+		return err
+	}
+	g.approvalProject = project
+	g.approver = approval.New(g.slog, g.db, g.github, project, issue, "gabyhelp")
+	for _, u := range strings.Split(flags.approvers, ",") {
+		g.approver.AllowUser(strings.TrimSpace(u))
+	}
+	return nil
+}
+
+// runApprovals posts previews of pending actions to the tracking issue
+// and records the decisions made there.
+func (g *Gaby) runApprovals(ctx context.Context) error {
+	if g.approver == nil {
+		return nil
+	}
+	g.db.Lock(gabyApprovalLock)
+	defer g.db.Unlock(gabyApprovalLock)
+
+	return g.approver.Run(ctx)
+}
+
+// githubDocs returns the source of GitHub documents for g.docs.
+// The tracking issue's project is private, so it is left out.
+func (g *Gaby) githubDocs() *githubDocSource {
+	return &githubDocSource{Client: g.github, skip: g.approvalProject}
+}
+
+// A githubDocSource is a [docs.Source] for a GitHub client
+// that skips the issues in one project.
+type githubDocSource struct {
+	*github.Client
+	skip string // project to skip; "" for none
+}
+
+// ToDocs implements [docs.Source.ToDocs].
+func (s *githubDocSource) ToDocs(e *github.Event) (iter.Seq[*docs.Doc], bool) {
+	if s.skip != "" && e.Project == s.skip {
+		return nil, false
+	}
+	return s.Client.ToDocs(e)
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestParseApprovalIssue(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		project string
+		issue   int64
+	}{
+		{"golang/oscar-private#1", "golang/oscar-private", 1},
+		{"https://github.com/golang/oscar-private/issues/12", "golang/oscar-private", 12},
+		{"12", "", 0},
+		{"golang/oscar-private", "", 0},
+	} {
+		project, issue, err := parseApprovalIssue(tc.in)
+		if tc.project == "" {
+			if err == nil {
+				t.Errorf("parseApprovalIssue(%q) = %q, %d, want error", tc.in, project, issue)
+			}
+			continue
+		}
+		if err != nil || project != tc.project || issue != tc.issue {
+			t.Errorf("parseApprovalIssue(%q) = %q, %d, %v, want %q, %d", tc.in, project, issue, err, tc.project, tc.issue)
+		}
+	}
+}
+
+func TestGitHubDocsSkipsApprovalProject(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	g := &Gaby{
+		slog:            lg,
+		db:              db,
+		github:          github.New(lg, db, secret.Empty(), nil),
+		docs:            docs.New(lg, db),
+		approvalProject: "golang/private",
+	}
+	g.github.Testing().AddIssue("golang/go", &github.Issue{Number: 1, Title: "public"})
+	g.github.Testing().AddIssue("golang/private", &github.Issue{Number: 1, Title: "private"})
+	docs.Sync(g.docs, g.githubDocs())
+
+	var ids []string
+	for d := range g.docs.Docs("") {
+		ids = append(ids, d.ID)
+	}
+	if len(ids) != 1 || ids[0] != "https://github.com/golang/go/issues/1" {
+		t.Errorf("docs = %v, want only golang/go#1", ids)
+	}
+}
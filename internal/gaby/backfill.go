@@ -0,0 +1,112 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"golang.org/x/oscar/internal/related"
+)
+
+const (
+	// defaultBackfillPosts is the default number of related-document
+	// comments logged by a single backfill request.
+	defaultBackfillPosts = 10
+	// maxBackfillPosts is the most comments a single backfill
+	// request can log, so that requests finish in reasonable time.
+	maxBackfillPosts = 100
+	// defaultBackfillInterval is the default time to wait after
+	// logging each comment.
+	defaultBackfillInterval = 1 * time.Second
+)
+
+// handleRelatedBackfillAPI logs actions to post related documents
+// on existing open issues of a project (see [related.Poster.Backfill]).
+// The form values are:
+//
+//   - project: the GitHub project (required)
+//   - min, max: the range of issue numbers (optional)
+//   - after, before: the range of creation dates, as YYYY-MM-DD (optional)
+//   - posts: the maximum number of comments to log (default 10, at most 100)
+//   - interval: the time to wait after logging each comment, such as "5s" (default 1s)
+//
+// Each request resumes where the last one with the same project and
+// ranges stopped. The response is a JSON [related.BackfillResult];
+// repeat the request until it reports Done.
+func (g *Gaby) handleRelatedBackfillAPI(w http.ResponseWriter, r *http.Request) {
+	b, err := parseBackfill(r)
+	if err != nil {
+		writeAPIError(w, codeInvalidQuery, err)
+		return
+	}
+	if !slices.Contains(g.githubProjects, b.Project) {
+		writeAPIError(w, codeUnknownProject, fmt.Errorf("unknown project %q", b.Project))
+		return
+	}
+
+	g.db.Lock(gabyPostRelatedLock)
+	defer g.db.Unlock(gabyPostRelatedLock)
+
+	res, err := g.relatedPoster.Backfill(r.Context(), b)
+	if err != nil {
+		writeAPIError(w, codeInternal, err)
+		return
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		writeAPIError(w, codeInternal, fmt.Errorf("json.Marshal: %w", err))
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+// parseBackfill parses the form values of a backfill request
+// (see [Gaby.handleRelatedBackfillAPI]).
+func parseBackfill(r *http.Request) (*related.Backfill, error) {
+	b := &related.Backfill{
+		Project:  r.FormValue("project"),
+		MaxPosts: defaultBackfillPosts,
+		Interval: defaultBackfillInterval,
+	}
+	if b.Project == "" {
+		return nil, fmt.Errorf("missing project")
+	}
+	var err error
+	parseInt := func(name string, p *int64) {
+		if v := r.FormValue(name); v != "" && err == nil {
+			if *p, err = strconv.ParseInt(v, 10, 64); err == nil && *p < 0 {
+				err = fmt.Errorf("negative %s", name)
+			}
+		}
+	}
+	parseDate := func(name string, p *time.Time) {
+		if v := r.FormValue(name); v != "" && err == nil {
+			*p, err = time.Parse(time.DateOnly, v)
+		}
+	}
+	parseInt("min", &b.MinIssue)
+	parseInt("max", &b.MaxIssue)
+	parseDate("after", &b.After)
+	parseDate("before", &b.Before)
+	if v := r.FormValue("posts"); v != "" && err == nil {
+		if b.MaxPosts, err = strconv.Atoi(v); err == nil && (b.MaxPosts < 1 || b.MaxPosts > maxBackfillPosts) {
+			err = fmt.Errorf("posts must be between 1 and %d", maxBackfillPosts)
+		}
+	}
+	if v := r.FormValue("interval"); v != "" && err == nil {
+		if b.Interval, err = time.ParseDuration(v); err == nil && b.Interval < 0 {
+			err = fmt.Errorf("negative interval")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
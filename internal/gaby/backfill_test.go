@@ -0,0 +1,58 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/related"
+)
+
+func TestParseBackfill(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  *related.Backfill // nil for error
+	}{
+		{"", nil},
+		{"project=a/b", &related.Backfill{Project: "a/b", MaxPosts: defaultBackfillPosts, Interval: defaultBackfillInterval}},
+		{
+			"project=a/b&min=10&max=20&after=2024-01-02&before=2024-03-04&posts=5&interval=1m",
+			&related.Backfill{
+				Project:  "a/b",
+				MinIssue: 10,
+				MaxIssue: 20,
+				After:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				Before:   time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+				MaxPosts: 5,
+				Interval: time.Minute,
+			},
+		},
+		{"project=a/b&min=x", nil},
+		{"project=a/b&max=-1", nil},
+		{"project=a/b&after=yesterday", nil},
+		{"project=a/b&posts=0", nil},
+		{"project=a/b&posts=1000", nil},
+		{"project=a/b&interval=-1s", nil},
+	} {
+		r := httptest.NewRequest("POST", "/api/relatedbackfill?"+tt.query, nil)
+		got, err := parseBackfill(r)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%q: got %+v, want error", tt.query, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%q: (-want, +got):\n%s", tt.query, diff)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// Backups are the disaster-recovery story for the database:
+// the action log, watcher positions, approvals and everything else
+// gaby stores, along with the vectors of the index's active
+// embedding model (see [storage.Export] for the format).
+// Re-embedding the documents would also recover the vectors,
+// but at the cost of a full pass through the embedder.
+//
+// Usage:
+//
+//	gaby -backup FILE: write a backup of the database to FILE and exit
+//	gaby -restore FILE: restore the backup in FILE into an empty database and exit
+//	/backup: download a backup of the database
+
+// backup writes a backup of the database and of the active
+// vector space to w.
+func (g *Gaby) backup(w io.Writer) (storage.BackupStats, error) {
+	ns := g.index.Active().Namespace
+	return storage.Export(w, g.db, map[string]storage.VectorDB{ns: g.index})
+}
+
+// backupFile writes a backup to the named file (see -backup).
+func (g *Gaby) backupFile(file string) (err error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(file)
+		}
+	}()
+	start := time.Now()
+	stats, err := g.backup(f)
+	if err != nil {
+		return err
+	}
+	g.slog.Info("gaby backup", "file", file, "pairs", stats.Pairs, "vectors", stats.Vectors, "duration", time.Since(start))
+	return nil
+}
+
+// errRestoreNotEmpty is the error returned by [Gaby.restoreFile]
+// if the database is not empty.
+var errRestoreNotEmpty = errors.New("database is not empty; restore into an empty database")
+
+// restoreFile restores the backup in the named file (see -restore)
+// into g.db and the vector databases opened by g.openVec.
+// The database must be empty, so that the result is
+// exactly the backed-up state.
+func (g *Gaby) restoreFile(file string) error {
+	for range g.db.Scan(nil, ordered.Encode(ordered.Inf)) {
+		return errRestoreNotEmpty
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	start := time.Now()
+	stats, err := storage.Import(f, g.db, func(ns string) storage.VectorDB {
+		vdb, err := g.openVec(ns)
+		if err != nil {
+			// unreachable for the vector databases gaby uses
+			g.db.Panic("gaby restore: open vector namespace", "namespace", ns, "err", err)
+		}
+		return vdb
+	})
+	if err != nil {
+		return err
+	}
+	g.slog.Info("gaby restore", "file", file, "pairs", stats.Pairs, "vectors", stats.Vectors, "duration", time.Since(start))
+	return nil
+}
+
+// handleBackup handles the /backup endpoint, which
+// responds with a backup of the database.
+func (g *Gaby) handleBackup(w http.ResponseWriter, r *http.Request) {
+	name := fmt.Sprintf("gaby-%s.backup", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	stats, err := g.backup(w)
+	if err != nil {
+		// The response has started, so the client
+		// sees the error as a truncated backup.
+		g.slog.Error("gaby backup", "err", err)
+		return
+	}
+	g.slog.Info("gaby backup", "endpoint", "/backup", "pairs", stats.Pairs, "vectors", stats.Vectors)
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestBackup(t *testing.T) {
+	lg := testutil.Slogger(t)
+
+	// newGaby returns a Gaby using db and Postgres-like vector
+	// databases, which do not store their vectors in db.
+	// If index is false, it does not set up the index,
+	// which writes to db.
+	newGaby := func(db storage.DB, index bool) *Gaby {
+		vdbs := make(map[string]storage.VectorDB)
+		g := &Gaby{slog: lg, db: db}
+		g.openVec = func(ns string) (storage.VectorDB, error) {
+			if vdbs[ns] == nil {
+				vdbs[ns] = storage.MemVectorDB(storage.MemDB(), lg, ns)
+			}
+			return vdbs[ns], nil
+		}
+		if index {
+			g.index = embeddocs.NewIndex(lg, db, vectorDBNamespace, "test/model", g.openVec)
+		}
+		return g
+	}
+
+	g := newGaby(storage.MemDB(), true)
+	g.db.Set([]byte("watcher"), []byte("position"))
+	g.index.Set("doc1", llm.Vector{1, 0})
+	g.index.Set("doc2", llm.Vector{0, 1})
+
+	file := filepath.Join(t.TempDir(), "gaby.backup")
+	if err := g.backupFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	g2 := newGaby(storage.MemDB(), false)
+	// Only an empty DB can be restored into.
+	g2.db.Set([]byte("x"), nil)
+	if err := g2.restoreFile(file); !errors.Is(err, errRestoreNotEmpty) {
+		t.Fatalf("restoreFile into non-empty DB: %v, want %v", err, errRestoreNotEmpty)
+	}
+	g2.db.Delete([]byte("x"))
+	if err := g2.restoreFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, _ := g2.db.Get([]byte("watcher")); string(v) != "position" {
+		t.Errorf("restored watcher = %q, want %q", v, "position")
+	}
+	g2.index = embeddocs.NewIndex(lg, g2.db, vectorDBNamespace, "test/model", g2.openVec)
+	if res := g2.index.Search(llm.Vector{0, 1}, 1, nil); len(res) != 1 || res[0].ID != "doc2" {
+		t.Errorf("restored index: Search = %v, want doc2", res)
+	}
+
+	// The /backup endpoint serves the same backup.
+	rec := httptest.NewRecorder()
+	g.handleBackup(rec, httptest.NewRequest("GET", "/backup", nil))
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The backups differ only in their header, which holds the time.
+	skipHeader := func(b []byte) []byte { return b[slices.Index(b, '\n')+1:] }
+	if have := rec.Body.Bytes(); string(skipHeader(have)) != string(skipHeader(data)) {
+		t.Errorf("/backup:\n%s\nwant:\n%s", have, data)
+	}
+}
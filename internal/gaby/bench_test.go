@@ -0,0 +1,215 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// The benchmarks in this file measure the search and overview
+// handlers against a synthetic corpus of GitHub issues.
+// In addition to the usual time and allocations per request,
+// they report the median (p50-ns) and 99th percentile (p99-ns)
+// request latency.
+//
+// To collect allocation profiles, run, for example:
+//
+//	go test -run=NONE -bench=Search -benchmem -memprofile=mem.out
+//	go tool pprof -sample_index=alloc_space mem.out
+//
+// For load tests against a running Gaby, see
+// golang.org/x/oscar/internal/devtools/cmd/loadtest.
+
+var benchCorpus = flag.Int("corpus", 2000, "number of issues in the synthetic benchmark corpus")
+
+const benchProject = "golang/go"
+
+// benchWords are the words used to generate the synthetic corpus.
+var benchWords = []string{
+	"cmd/go", "runtime", "net/http", "crash", "panic", "slow", "build",
+	"module", "test", "vet", "gc", "linker", "race", "generics", "proposal",
+	"windows", "darwin", "linux", "arm64", "regression", "flaky", "timeout",
+}
+
+// benchText returns deterministic synthetic text with n words,
+// derived from seed.
+func benchText(seed, n int) string {
+	var b []byte
+	for i := range n {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = append(b, benchWords[(seed*7+i*13+i*i)%len(benchWords)]...)
+	}
+	return string(b)
+}
+
+// newBenchGaby returns a Gaby whose database holds a synthetic
+// corpus of n issues (each with a few comments), converted to
+// documents and embedded.
+// It uses the quote embedder and a content generator that
+// returns canned responses, so it measures Gaby's own overhead,
+// not that of an LLM.
+func newBenchGaby(b *testing.B, n int) *Gaby {
+	b.Helper()
+
+	lg := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db := storage.MemDB()
+	gh := github.New(lg, db, secret.Empty(), nil)
+	lc := llmapp.New(lg, benchGenerator(), db)
+	g := &Gaby{
+		slog:           lg,
+		db:             db,
+		vector:         storage.MemVectorDB(db, lg, "vector"),
+		github:         gh,
+		llmapp:         lc,
+		overview:       overview.New(lg, db, gh, lc, "bench", "bench-bot"),
+		docs:           docs.New(lg, db),
+		embed:          llm.QuoteEmbedder(),
+		githubProjects: []string{benchProject},
+	}
+	gh.Add(benchProject)
+	for i := range n {
+		iss := &github.Issue{
+			Number:    int64(i + 1),
+			Title:     benchText(i, 6),
+			Body:      benchText(i+1, 40),
+			State:     []string{"open", "closed"}[i%2],
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+		}
+		gh.Testing().AddIssue(benchProject, iss)
+		for j := range i % 4 {
+			gh.Testing().AddIssueComment(benchProject, iss.Number, &github.IssueComment{Body: benchText(i+j, 20)})
+		}
+		g.docs.Add(iss.DocID(), iss.Title, iss.Body)
+	}
+	if err := g.embedAll(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	return g
+}
+
+// benchGenerator returns a content generator for benchmarks.
+// It returns a valid related-documents analysis for requests
+// with a schema, and echoes the prompt otherwise.
+// The corpus is large enough that the analysis always
+// has the maximum number of related documents (5).
+func benchGenerator() llm.ContentGenerator {
+	echo := llm.EchoContentGenerator()
+	related := llmapp.Related{Summary: "summary"}
+	for range 5 {
+		related.Related = append(related.Related, llmapp.RelatedDoc{Title: "title", URL: "URL", Relevance: "HIGH"})
+	}
+	js := string(storage.JSON(related))
+	return llm.TestContentGenerator("bench", func(ctx context.Context, schema *llm.Schema, promptParts []llm.Part) (string, error) {
+		if schema != nil {
+			return js, nil
+		}
+		return echo.GenerateContent(ctx, schema, promptParts)
+	})
+}
+
+// benchHandler serves the requests for urls (in turn) using h,
+// b.N times in total, and reports latency percentiles.
+func benchHandler(b *testing.B, h http.HandlerFunc, urls []string) {
+	b.Helper()
+
+	latencies := make([]time.Duration, 0, b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		r := httptest.NewRequest("GET", urls[i%len(urls)], nil)
+		w := httptest.NewRecorder()
+		start := time.Now()
+		h(w, r)
+		latencies = append(latencies, time.Since(start))
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "<p>Error: ") {
+			b.Fatalf("GET %s: %d\n%s", r.URL, w.Code, w.Body)
+		}
+	}
+	b.StopTimer()
+
+	slices.Sort(latencies)
+	b.ReportMetric(float64(percentile(latencies, 50)), "p50-ns")
+	b.ReportMetric(float64(percentile(latencies, 99)), "p99-ns")
+}
+
+// percentile returns the p'th percentile of the sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+// benchURLs returns 100 URLs generated by f.
+func benchURLs(f func(i int) string) []string {
+	var urls []string
+	for i := range 100 {
+		urls = append(urls, f(i))
+	}
+	return urls
+}
+
+func BenchmarkSearch(b *testing.B) {
+	g := newBenchGaby(b, *benchCorpus)
+
+	b.Run("text", func(b *testing.B) {
+		benchHandler(b, g.handleSearch, benchURLs(func(i int) string {
+			return "/search?q=" + url.QueryEscape(benchText(i*31, 5))
+		}))
+	})
+	b.Run("id", func(b *testing.B) {
+		benchHandler(b, g.handleSearch, benchURLs(func(i int) string {
+			return fmt.Sprintf("/search?q=https://github.com/%s/issues/%d", benchProject, 1+(i*37)%*benchCorpus)
+		}))
+	})
+	b.Run("filtered", func(b *testing.B) {
+		benchHandler(b, g.handleSearch, benchURLs(func(i int) string {
+			return "/search?state=open&created_after=2020-02-01&q=" + url.QueryEscape(benchText(i*31, 5))
+		}))
+	})
+	b.Run("page2", func(b *testing.B) {
+		benchHandler(b, g.handleSearch, benchURLs(func(i int) string {
+			return "/search?cursor=20&q=" + url.QueryEscape(benchText(i*31, 5))
+		}))
+	})
+}
+
+func BenchmarkOverview(b *testing.B) {
+	g := newBenchGaby(b, *benchCorpus)
+
+	// Only the first request for each issue calls the content
+	// generator; later requests are served from the LLM response cache.
+	b.Run("issue", func(b *testing.B) {
+		benchHandler(b, g.handleOverview, benchURLs(func(i int) string {
+			return fmt.Sprintf("/overview?t=%s&q=%d", issueOverviewType, 1+(i*37)%*benchCorpus)
+		}))
+	})
+	b.Run("related", func(b *testing.B) {
+		benchHandler(b, g.handleOverview, benchURLs(func(i int) string {
+			return fmt.Sprintf("/overview?t=%s&q=%s%%23%d", relatedOverviewType, benchProject, 1+(i*37)%*benchCorpus)
+		}))
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"golang.org/x/oscar/internal/github"
+)
+
+// maxGuidelinesBytes is the maximum size of a project's review guidelines.
+const maxGuidelinesBytes = 64 << 10
+
+// handleReviewGuidelinesAPI serves the review guidelines of the
+// project named by the "project" form value, used to tailor
+// review checklists (see [checklist.Checklister.SetGuidelines]).
+// A GET returns the guidelines as text; a POST replaces them with
+// the request body, and an empty body deletes them.
+func (g *Gaby) handleReviewGuidelinesAPI(w http.ResponseWriter, r *http.Request) {
+	project := r.FormValue("project")
+	if project == "" {
+		writeAPIError(w, codeInvalidQuery, fmt.Errorf("missing project"))
+		return
+	}
+	if !slices.Contains(g.githubProjects, project) {
+		writeAPIError(w, codeUnknownProject, fmt.Errorf("unknown project %q", project))
+		return
+	}
+	if r.Method == http.MethodPost {
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxGuidelinesBytes+1))
+		if err != nil {
+			writeAPIError(w, codeInvalidQuery, fmt.Errorf("reading body: %w", err))
+			return
+		}
+		if len(data) > maxGuidelinesBytes {
+			writeAPIError(w, codeInvalidQuery, fmt.Errorf("guidelines too long (max %d bytes)", maxGuidelinesBytes))
+			return
+		}
+		g.checklist.SetGuidelines(project, string(data))
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, g.checklist.Guidelines(project))
+}
+
+// checklistOverview generates a checklist for reviewing the pull request.
+// If post is set, it also adds an action to the action log to post the
+// checklist on the pull request.
+func (g *Gaby) checklistOverview(ctx context.Context, iss *github.Issue, post bool) (*overviewResult, error) {
+	r, err := g.checklist.ForPullRequest(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	desc := fmt.Sprintf("the diff of pull request %d (%d files)", iss.Number, r.Files)
+	if r.Guidelines {
+		desc += " and the project's review guidelines"
+	}
+	return &overviewResult{
+		Raw:    r.Checklist,
+		Issue:  iss,
+		Typed:  r,
+		Type:   checklistOverviewType,
+		Desc:   desc,
+		Posted: post && g.checklist.Post(iss, r),
+	}, nil
+}
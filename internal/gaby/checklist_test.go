@@ -0,0 +1,75 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/checklist"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestReviewChecklist(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, secret.Empty(), nil)
+	lc := llmapp.New(lg, llm.EchoContentGenerator(), db)
+	project := "hello/world"
+	g := &Gaby{
+		slog:           lg,
+		db:             db,
+		github:         gh,
+		githubProjects: []string{project},
+		checklist:      checklist.New(lg, db, gh, lc, "test"),
+	}
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "a change", PullRequest: new(struct{})})
+	pr, err := github.LookupIssue(db, project, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gh.Testing().SetPullRequestFiles(pr, []*github.PullRequestFile{{Filename: "hello.go", Status: "added", Additions: 1}})
+
+	call := func(method, query, body string) (int, string) {
+		t.Helper()
+		r := httptest.NewRequest(method, "/api/reviewguidelines?"+query, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		g.handleReviewGuidelinesAPI(w, r)
+		return w.Code, w.Body.String()
+	}
+	if code, _ := call("GET", "", ""); code != http.StatusBadRequest {
+		t.Errorf("GET without project: status %d, want %d", code, http.StatusBadRequest)
+	}
+	if code, _ := call("GET", "project=other/repo", ""); code != http.StatusNotFound {
+		t.Errorf("GET unknown project: status %d, want %d", code, http.StatusNotFound)
+	}
+	const guidelines = "- New files need a copyright header."
+	if code, body := call("POST", "project="+project, guidelines); code != http.StatusOK || body != guidelines {
+		t.Errorf("POST: %d %q, want 200 %q", code, body, guidelines)
+	}
+	if code, body := call("GET", "project="+project, ""); code != http.StatusOK || body != guidelines {
+		t.Errorf("GET: %d %q, want 200 %q", code, body, guidelines)
+	}
+
+	r, err := g.checklistOverview(ctx, pr, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Type != checklistOverviewType || !r.Posted || r.Desc != "the diff of pull request 1 (1 files) and the project's review guidelines" {
+		t.Errorf("checklistOverview: type=%s posted=%v desc=%q", r.Type, r.Posted, r.Desc)
+	}
+	if !strings.Contains(r.Raw.Response, "copyright header") {
+		t.Errorf("checklist does not use guidelines:\n%s", r.Raw.Response)
+	}
+}
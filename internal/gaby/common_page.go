@@ -87,6 +87,18 @@ func (TextInput) InputType() string {
 	return "text"
 }
 
+// CheckboxInput is an HTML "checkbox" input.
+type CheckboxInput struct {
+	ID      safeID // HTML "id"
+	Value   string // HTML "value"
+	Checked bool   // whether the box should be checked
+}
+
+// Implements [typedInput.InputType].
+func (CheckboxInput) InputType() string {
+	return "checkbox"
+}
+
 // RadioInput is a collection of HTML "radio" inputs.
 type RadioInput struct {
 	Choices []RadioChoice
@@ -0,0 +1,57 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"slices"
+
+	"golang.org/x/oscar/internal/discussion"
+	"golang.org/x/oscar/internal/gerrit"
+	"golang.org/x/oscar/internal/github"
+)
+
+// divertedEditsPage is the data for the diverted edits HTML template.
+type divertedEditsPage struct {
+	CommonPage
+
+	DryRun  bool                     // whether gaby is running in dry-run mode
+	Edits   []*github.DivertedEdit   // diverted GitHub edits, newest first
+	Reviews []*gerrit.DivertedReview // diverted Gerrit reviews, newest first
+	// diverted GitHub Discussions comments, newest first
+	Comments []*discussion.DivertedComment
+}
+
+var divertedEditsPageTmpl = newTemplate(divertedEditsTmplFile, nil)
+
+func (g *Gaby) handleDivertedEdits(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateDivertedEditsPage(), divertedEditsPageTmpl)
+}
+
+// populateDivertedEditsPage returns the contents of the diverted edits page.
+func (g *Gaby) populateDivertedEditsPage() *divertedEditsPage {
+	p := &divertedEditsPage{
+		DryRun:   g.github.DryRun(),
+		Edits:    slices.Collect(g.github.DivertedEdits(0)),
+		Reviews:  slices.Collect(g.gerrit.DivertedReviews(0)),
+		Comments: slices.Collect(g.disc.DivertedComments(0)),
+	}
+	slices.Reverse(p.Edits)
+	slices.Reverse(p.Reviews)
+	slices.Reverse(p.Comments)
+	p.setCommonPage()
+	return p
+}
+
+func (p *divertedEditsPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          divertedEditsID,
+		Description: "Browse GitHub edits, GitHub Discussions comments and Gerrit reviews recorded, but not applied, in dry-run mode.",
+		Form: Form{
+			Inputs:     nil,
+			SubmitText: "void",
+		},
+	}
+}
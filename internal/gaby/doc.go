@@ -175,6 +175,8 @@
 // but when backed by a persistent database, the implementation suffices for
 // small-scale production use (say, up to a million documents, which would
 // require 3 GB of vectors).
+// [storage.MemVectorDBWithLimit] bounds the memory used by the in-memory copy,
+// reading the vectors that do not fit from the underlying database instead.
 //
 // It is possible that the package ordering here is wrong and that VectorDB
 // should be defined in the llm package, built on top of storage,
@@ -255,9 +257,9 @@
 // Gaby downloads the issue tracker state using GitHub's REST API, which makes
 // incremental updating very easy but does not provide access to a few newer features
 // such as project boards and discussions, which are only available in the GraphQL API.
-// Sync'ing using the GraphQL API is left for future work: there is enough data available
-// from the REST API that for now we can focus on what to do with that data and not
-// that a few newer GitHub features are missing.
+// Projects listed in the -graphql flag sync their issues and comments using the
+// GraphQL API instead, which takes far fewer requests for large projects;
+// the REST API remains the default.
 //
 // The github package provides two important aids for testing. For issue tracker state,
 // it also allows loading issue data from a simple text-based issue description, avoiding
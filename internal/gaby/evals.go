@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/oscar/internal/evals"
+)
+
+// evalsPage holds the fields needed to display the stored
+// evaluation reports of overviews and related documents
+// (see devtools/cmd/evals), and to compare two of them.
+type evalsPage struct {
+	CommonPage
+
+	Params     evalsParams       // the raw parameters
+	Error      error             // if non-nil, the error to display instead of the comparison
+	Reports    []*evals.Report   // the stored reports, most recent first
+	Comparison *evals.Comparison // the comparison of reports a and b, if requested
+}
+
+// evalsParams holds the raw inputs to the evaluations form.
+type evalsParams struct {
+	A, B string // names of the reports to compare
+}
+
+const (
+	paramEvalA = "a"
+	paramEvalB = "b"
+)
+
+var (
+	safeEvalA = toSafeID(paramEvalA)
+	safeEvalB = toSafeID(paramEvalB)
+)
+
+var evalsPageTmpl = newTemplate(evalsTmplFile, template.FuncMap{
+	"score": func(f float64) string { return fmt.Sprintf("%.3f", f) },
+	"delta": func(f float64) string { return fmt.Sprintf("%+.3f", f) },
+})
+
+func (g *Gaby) handleEvals(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateEvalsPage(r), evalsPageTmpl)
+}
+
+// populateEvalsPage returns the contents of the evaluations page,
+// with the comparison of the reports in the "a" and "b" form values,
+// if both are set.
+func (g *Gaby) populateEvalsPage(r *http.Request) *evalsPage {
+	p := &evalsPage{
+		Params:  evalsParams{A: r.FormValue(paramEvalA), B: r.FormValue(paramEvalB)},
+		Reports: evals.Reports(g.db),
+	}
+	if p.Params.A != "" && p.Params.B != "" {
+		a, ok := evals.LoadReport(g.db, p.Params.A)
+		b, okb := evals.LoadReport(g.db, p.Params.B)
+		switch {
+		case !ok:
+			p.Error = fmt.Errorf("no report %q", p.Params.A)
+		case !okb:
+			p.Error = fmt.Errorf("no report %q", p.Params.B)
+		default:
+			p.Comparison = evals.Compare(a, b)
+		}
+	}
+	p.setCommonPage()
+	return p
+}
+
+func (p *evalsPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          evalsID,
+		Description: "Compare evaluations of the quality of overviews and related documents, as run by devtools/cmd/evals.",
+		Form: Form{
+			Inputs: []FormInput{
+				{
+					Label:       "report a",
+					Type:        "string",
+					Description: "the name of the baseline report",
+					Name:        safeEvalA,
+					Typed: TextInput{
+						ID:    safeEvalA,
+						Value: p.Params.A,
+					},
+				},
+				{
+					Label:       "report b",
+					Type:        "string",
+					Description: "the name of the report to compare with the baseline",
+					Name:        safeEvalB,
+					Typed: TextInput{
+						ID:    safeEvalB,
+						Value: p.Params.B,
+					},
+				},
+			},
+			SubmitText: "compare",
+		},
+	}
+}
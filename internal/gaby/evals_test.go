@@ -0,0 +1,34 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oscar/internal/evals"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestEvalsPage(t *testing.T) {
+	db := storage.MemDB()
+	g := &Gaby{slog: testutil.Slogger(t), db: db}
+	evals.SaveReport(db, &evals.Report{Name: "a", Scores: evals.Scores{MRR: 0.5}})
+	evals.SaveReport(db, &evals.Report{Name: "b", Scores: evals.Scores{MRR: 0.75}})
+
+	p := g.populateEvalsPage(httptest.NewRequest("GET", "/evals", nil))
+	if len(p.Reports) != 2 || p.Comparison != nil || p.Error != nil {
+		t.Errorf("page without comparison = %+v", p)
+	}
+	p = g.populateEvalsPage(httptest.NewRequest("GET", "/evals?a=a&b=b", nil))
+	if p.Error != nil || p.Comparison == nil || p.Comparison.Delta.MRR != 0.25 {
+		t.Errorf("comparison = %+v, error %v", p.Comparison, p.Error)
+	}
+	p = g.populateEvalsPage(httptest.NewRequest("GET", "/evals?a=a&b=c", nil))
+	if p.Error == nil {
+		t.Error("comparison with missing report succeeded, want error")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/oscar/internal/feedback"
+)
+
+// feedbackPage is the data for the feedback HTML template.
+type feedbackPage struct {
+	CommonPage
+
+	Report   *feedback.Report    // the summary of votes by kind of comment
+	Comments []*feedback.Comment // the comments with votes, most recent first
+}
+
+var feedbackPageTmpl = newTemplate(feedbackTmplFile, template.FuncMap{
+	"percent": func(f float64) float64 { return 100 * f },
+})
+
+func (g *Gaby) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateFeedbackPage(), feedbackPageTmpl)
+}
+
+// populateFeedbackPage returns the contents of the feedback page.
+func (g *Gaby) populateFeedbackPage() *feedbackPage {
+	p := &feedbackPage{
+		Report: g.feedback.Report(),
+	}
+	for cm := range g.feedback.Comments() {
+		if cm.Up()+cm.Down() > 0 {
+			p.Comments = append(p.Comments, cm)
+		}
+	}
+	slices.SortStableFunc(p.Comments, func(x, y *feedback.Comment) int {
+		return y.Created.Compare(x.Created)
+	})
+	p.setCommonPage()
+	return p
+}
+
+// handleFeedbackAPI serves the feedback report as JSON.
+func (g *Gaby) handleFeedbackAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(g.feedback.Report())
+	if err != nil {
+		writeAPIError(w, codeInternal, fmt.Errorf("json.Marshal: %w", err))
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+func (p *feedbackPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          feedbackID,
+		Description: "Measure the usefulness of Gaby's GitHub comments by the emoji votes they received.",
+		Form: Form{
+			Inputs:     nil,
+			SubmitText: "void",
+		},
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/feedback"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestFeedback(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, secret.Empty(), nil)
+	project := "hello/world"
+	g := &Gaby{
+		slog:     lg,
+		db:       db,
+		github:   gh,
+		feedback: feedback.New(lg, db, gh, "gabyhelp", "test"),
+	}
+	g.feedback.EnableProject(project)
+
+	created := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1})
+	voted := &github.IssueComment{User: github.User{Login: "gabyhelp"}, CreatedAt: created, Body: "a"}
+	gh.Testing().AddIssueComment(project, 1, voted)
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{User: github.User{Login: "gabyhelp"}, CreatedAt: created, Body: "b"})
+	gh.Testing().SetReactions(voted, &github.Reactions{TotalCount: 1, PlusOne: 1})
+	if err := g.syncFeedback(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	p := g.populateFeedbackPage()
+	if p.Report.Total.Comments != 2 || p.Report.Total.Up != 1 {
+		t.Errorf("Report.Total = %+v, want 2 comments, 1 up", p.Report.Total)
+	}
+	if len(p.Comments) != 1 || p.Comments[0].HTMLURL != voted.HTMLURL {
+		t.Errorf("Comments = %v, want only the voted comment", p.Comments)
+	}
+
+	w := httptest.NewRecorder()
+	g.handleFeedbackAPI(w, httptest.NewRequest("GET", "/api/feedback", nil))
+	var r feedback.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Total.Up != 1 {
+		t.Errorf("API report Total.Up = %d, want 1", r.Total.Up)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/oscar/internal/gc"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// Garbage collection deletes data gaby no longer needs
+// (see [gc.Policy] and the -gc* and -llmcachettl flags).
+// It runs only when requested, since it scans large parts of the
+// database; Cloud Scheduler should request it about once a day.
+//
+// Usage:
+//
+//	/gc: report what garbage collection would delete, without deleting it
+//	/gc?delete=true: delete it, archiving it in -gcarchive if set
+
+// newGC returns the garbage collector configured by the flags.
+func (g *Gaby) newGC() *gc.Collector {
+	c := gc.New(g.slog, g.db, gc.Policy{
+		LLMCacheTTL:  flags.llmCacheTTL,
+		ActionLogAge: flags.gcActionLog,
+		OverviewAge:  flags.gcOverview,
+		Vectors:      flags.gcVectors,
+	})
+	c.SetIndex(g.index, g.docs)
+	return c
+}
+
+// runGC runs the garbage collector, archiving the data it deletes
+// in a new file in dir, unless dir is empty or dryRun is true.
+func (g *Gaby) runGC(dryRun bool, dir string) (_ *gc.Report, err error) {
+	g.db.Lock(gabyGCLock)
+	defer g.db.Unlock(gabyGCLock)
+
+	var archive io.Writer
+	if !dryRun && dir != "" {
+		file := filepath.Join(dir, fmt.Sprintf("gc-%s.backup", time.Now().UTC().Format("20060102-150405")))
+		f, err := os.Create(file)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		archive = f
+	}
+	r, err := g.gc.Run(dryRun, archive)
+	if err != nil {
+		return r, err
+	}
+	g.slog.Info("gaby gc", "dryrun", dryRun, "bytes", r.Bytes())
+	return r, nil
+}
+
+// handleGC handles the /gc endpoint, which responds with the
+// JSON [gc.Report] of a garbage collection.
+// It deletes data only if the delete parameter is "true".
+func (g *Gaby) handleGC(w http.ResponseWriter, r *http.Request) {
+	dryRun := r.FormValue("delete") != "true"
+	if !dryRun {
+		if err := g.checkLeader(); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+	}
+	report, err := g.runGC(dryRun, flags.gcArchive)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(storage.JSON(report))
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/gc"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestGC(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	g := &Gaby{slog: lg, db: db, docs: docs.New(lg, db)}
+	g.index = embeddocs.NewIndex(lg, db, vectorDBNamespace, "test/model", func(ns string) (storage.VectorDB, error) {
+		return storage.MemVectorDB(db, lg, ns), nil
+	})
+	g.docs.Add("doc", "title", "text")
+	g.index.Set("doc", llm.Vector{1, 0})
+	g.index.Set("gone", llm.Vector{0, 1})
+
+	defer func(v bool) { flags.gcVectors = v }(flags.gcVectors)
+	flags.gcVectors = true
+	g.gc = g.newGC()
+
+	get := func(url string) *gc.Report {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		g.handleGC(rec, httptest.NewRequest("GET", url, nil))
+		var r gc.Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+			t.Fatalf("%s: %v\n%s", url, err, rec.Body.Bytes())
+		}
+		return &r
+	}
+
+	// By default, /gc is a dry run.
+	if r := get("/gc"); !r.DryRun || len(r.Kinds) != 1 || r.Kinds[0].Items != 1 {
+		t.Errorf("/gc = %+v, want dry run finding 1 vector", r)
+	}
+	if _, ok := g.index.Get("gone"); !ok {
+		t.Errorf("dry run deleted vector")
+	}
+
+	dir := t.TempDir()
+	defer func(v string) { flags.gcArchive = v }(flags.gcArchive)
+	flags.gcArchive = dir
+	if r := get("/gc?delete=true"); r.DryRun || r.Bytes() == 0 {
+		t.Errorf("/gc?delete=true = %+v, want deletion", r)
+	}
+	if _, ok := g.index.Get("gone"); ok {
+		t.Errorf("vector not deleted")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "gc-*.backup"))
+	if len(files) != 1 {
+		t.Fatalf("archives = %v, want 1", files)
+	}
+	if data, err := os.ReadFile(files[0]); err != nil || len(data) == 0 {
+		t.Errorf("archive: %v, %d bytes", err, len(data))
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/apitoken"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/labels"
+	"golang.org/x/oscar/internal/search"
+)
+
+// The /api/gh/ endpoints serve the "gh oscar" GitHub CLI extension,
+// which shows Gaby's view of an issue in the current repository:
+//
+//	GET /api/gh/overview?project=P&issue=N  (scope "overview")
+//	GET /api/gh/related?project=P&issue=N   (scope "related")
+//	GET /api/gh/labels?project=P&issue=N    (scope "labels")
+//
+// Unlike the other pages and APIs, which are only for people
+// signed in to the web UI, these endpoints authenticate their callers
+// with an "Authorization: Bearer <token>" header, holding a token
+// issued on the /apitokens page. Each token grants some of the scopes
+// above, on some or all of the projects (see [apitoken.Token]).
+// The endpoints only read data; none of them posts to GitHub.
+
+// A ghOverview is the response of /api/gh/overview.
+type ghOverview struct {
+	Project  string
+	Issue    int64
+	Title    string
+	URL      string
+	Overview string // the overview, as Markdown
+	Model    string // the model that generated the overview
+	// Stale is the time the overview was generated, if a new
+	// overview could not be generated and an older one is served.
+	Stale *time.Time `json:",omitempty"`
+}
+
+// A ghRelated is the response of /api/gh/related.
+type ghRelated struct {
+	Project string
+	Issue   int64
+	Title   string
+	URL     string
+	Related []search.Result // the related documents, best first
+}
+
+// A ghLabels is the response of /api/gh/labels.
+// Projects without configured labels have no Category or Suggestions.
+type ghLabels struct {
+	Project     string
+	Issue       int64
+	Title       string
+	URL         string
+	Category    string              `json:",omitempty"` // the issue's category (see [labels.IssueCategory])
+	Explanation string              `json:",omitempty"` // why the LLM chose the category
+	Suggestions []labels.Suggestion `json:",omitempty"` // suggested labels, most confident first
+}
+
+func (g *Gaby) handleGHOverviewAPI(w http.ResponseWriter, r *http.Request) {
+	iss, _, ok := g.ghIssue(w, r, apitoken.ScopeOverview)
+	if !ok {
+		return
+	}
+	var res *overviewResult
+	var err error
+	if iss.PullRequest != nil {
+		res, err = g.prOverview(r.Context(), g.overview, iss)
+	} else {
+		res, err = g.issueOverview(r.Context(), g.overview, iss)
+	}
+	if err != nil {
+		writeGHLLMError(w, err)
+		return
+	}
+	ov := &ghOverview{
+		Project:  iss.Project(),
+		Issue:    iss.Number,
+		Title:    iss.Title,
+		URL:      iss.HTMLURL,
+		Overview: res.Raw.Response,
+		Model:    res.Raw.Model,
+	}
+	if res.Stale != nil {
+		ov.Stale = &res.Stale.Generated
+	}
+	writeGHResponse(w, ov)
+}
+
+func (g *Gaby) handleGHRelatedAPI(w http.ResponseWriter, r *http.Request) {
+	iss, tok, ok := g.ghIssue(w, r, apitoken.ScopeRelated)
+	if !ok {
+		return
+	}
+	if g.relatedPoster == nil {
+		writeAPIError(w, codeInternal, errors.New("related documents are not enabled"))
+		return
+	}
+	results, ok := g.relatedPoster.Related(iss.DocID())
+	if !ok {
+		writeAPIError(w, codeNotSynced, fmt.Errorf("issue %s#%d not embedded yet", iss.Project(), iss.Number))
+		return
+	}
+	// Issues and discussions of GitHub projects the token does not
+	// grant are left out. Documents from other sources,
+	// such as the Go documentation, are public and always included.
+	results = slices.DeleteFunc(results, func(r search.Result) bool {
+		src := search.Source(r.ID)
+		return strings.Contains(src, "/") && !tok.Allows(apitoken.ScopeRelated, src)
+	})
+	for i := range results {
+		results[i].Round()
+	}
+	writeGHResponse(w, &ghRelated{
+		Project: iss.Project(),
+		Issue:   iss.Number,
+		Title:   iss.Title,
+		URL:     iss.HTMLURL,
+		Related: results,
+	})
+}
+
+func (g *Gaby) handleGHLabelsAPI(w http.ResponseWriter, r *http.Request) {
+	iss, _, ok := g.ghIssue(w, r, apitoken.ScopeLabels)
+	if !ok {
+		return
+	}
+	if iss.PullRequest != nil {
+		writeAPIError(w, codeInvalidQuery, fmt.Errorf("%s#%d is a pull request", iss.Project(), iss.Number))
+		return
+	}
+	res := &ghLabels{
+		Project: iss.Project(),
+		Issue:   iss.Number,
+		Title:   iss.Title,
+		URL:     iss.HTMLURL,
+	}
+	cgen := g.featureLLM("labels")
+	if len(labels.CategoriesForProject(iss.Project())) > 0 {
+		cat, exp, err := labels.IssueCategory(r.Context(), g.db, cgen, iss)
+		if err != nil {
+			writeGHLLMError(w, err)
+			return
+		}
+		res.Category = cat.Name
+		res.Explanation = exp
+	}
+	if labs := labels.SuggestionLabelsForProject(iss.Project()); len(labs) > 0 {
+		sugs, err := labels.SuggestLabels(r.Context(), cgen, iss, labs)
+		if err != nil {
+			writeGHLLMError(w, err)
+			return
+		}
+		res.Suggestions = sugs
+	}
+	writeGHResponse(w, res)
+}
+
+// ghIssue authorizes the /api/gh/ request r for the scope and
+// looks up the issue named by its "project" and "issue" form values.
+// It returns the issue and the token that authorized the request.
+// If that fails, it writes the error response and returns ok=false.
+func (g *Gaby) ghIssue(w http.ResponseWriter, r *http.Request, scope apitoken.Scope) (_ *github.Issue, _ *apitoken.Token, ok bool) {
+	secret, ok := bearerToken(r)
+	if !ok {
+		writeUnauthorized(w, errors.New("missing bearer token"))
+		return nil, nil, false
+	}
+	project := r.FormValue("project")
+	if project == "" {
+		writeAPIError(w, codeInvalidQuery, errors.New("missing project"))
+		return nil, nil, false
+	}
+	num, err := strconv.ParseInt(r.FormValue("issue"), 10, 64)
+	if err != nil || num <= 0 {
+		writeAPIError(w, codeInvalidQuery, fmt.Errorf("invalid issue %q", r.FormValue("issue")))
+		return nil, nil, false
+	}
+	tok, err := g.tokens.Authorize(secret, scope, project)
+	if err != nil {
+		if errors.Is(err, apitoken.ErrForbidden) {
+			writeAPIError(w, codeForbidden, err)
+		} else {
+			writeUnauthorized(w, err)
+		}
+		return nil, nil, false
+	}
+	if !slices.Contains(g.githubProjects, project) {
+		writeAPIError(w, codeUnknownProject, fmt.Errorf("unknown project %q", project))
+		return nil, nil, false
+	}
+	iss, err := github.LookupIssue(g.db, project, num)
+	if err != nil {
+		writeAPIError(w, codeNotSynced, err)
+		return nil, nil, false
+	}
+	return iss, tok, true
+}
+
+// bearerToken returns the token in r's Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// writeUnauthorized writes a failed API response for a request
+// without a valid token.
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="oscar"`)
+	writeAPIError(w, codeUnauthorized, err)
+}
+
+// writeGHLLMError writes a failed API response for err,
+// returned by a call to the LLM.
+func writeGHLLMError(w http.ResponseWriter, err error) {
+	code := codeInternal
+	if !llmAvailability.Available() {
+		code = codeLLMUnavailable
+	}
+	writeAPIError(w, code, llmError(err))
+}
+
+// writeGHResponse writes v as a JSON response.
+func writeGHResponse(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeAPIError(w, codeInternal, fmt.Errorf("json.Marshal: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/apitoken"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/related"
+)
+
+func TestGHAPI(t *testing.T) {
+	g := newTestGaby(t)
+	project := "hello/world"
+	g.githubProjects = []string{project, "hello/other"}
+	g.github.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "hello", Body: "hello world"})
+	g.github.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "hello again", Body: "hello world again"})
+	g.github.Testing().AddIssue("hello/other", &github.Issue{Number: 3, Title: "greetings", Body: "hello world elsewhere"})
+	ctx := context.Background()
+	docs.Sync(g.docs, g.github)
+	embeddocs.Sync(ctx, g.slog, g.vector, g.embed, g.docs)
+	g.overview = overview.New(g.slog, g.db, g.github, g.llmapp, "test", "test-bot")
+	g.relatedPoster = related.New(g.slog, g.db, g.github, g.vector, g.docs, "related")
+	g.relatedPoster.SetMinScore(0) // the quote embedder's scores are low
+	g.tokens = apitoken.NewStore(g.slog, g.db)
+
+	all, _, err := g.tokens.Issue("gopher", "admin", apitoken.Scopes, []string{project}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	related, _, err := g.tokens.Issue("gopher", "admin", []apitoken.Scope{apitoken.ScopeRelated}, nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	labelsOnly, _, err := g.tokens.Issue("bot", "admin", []apitoken.Scope{apitoken.ScopeLabels}, nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := map[string]http.HandlerFunc{
+		"overview": g.handleGHOverviewAPI,
+		"related":  g.handleGHRelatedAPI,
+		"labels":   g.handleGHLabelsAPI,
+	}
+	call := func(endpoint, token, query string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/api/gh/"+endpoint+"?"+query, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handlers[endpoint](w, r)
+		return w
+	}
+
+	for _, tc := range []struct {
+		name     string
+		endpoint string
+		token    string
+		query    string
+		want     errorCode
+	}{
+		{"no token", "overview", "", "project=hello/world&issue=1", codeUnauthorized},
+		{"bad token", "overview", "oscar_nope", "project=hello/world&issue=1", codeUnauthorized},
+		{"missing project", "overview", all, "issue=1", codeInvalidQuery},
+		{"bad issue", "overview", all, "project=hello/world&issue=x", codeInvalidQuery},
+		{"wrong scope", "overview", labelsOnly, "project=hello/world&issue=1", codeForbidden},
+		{"wrong project", "related", all, "project=hello/other&issue=1", codeForbidden},
+		{"unknown project", "labels", labelsOnly, "project=hello/nope&issue=1", codeUnknownProject},
+		{"unknown issue", "related", all, "project=hello/world&issue=99", codeNotSynced},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := call(tc.endpoint, tc.token, tc.query)
+			var e apiError
+			if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil || e.Code != tc.want || w.Code != tc.want.status() {
+				t.Errorf("got (%d, %s), want (%d, %q)", w.Code, w.Body, tc.want.status(), tc.want)
+			}
+			if tc.want == codeUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+
+	t.Run("overview", func(t *testing.T) {
+		w := call("overview", all, "project=hello/world&issue=1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+		var ov ghOverview
+		if err := json.Unmarshal(w.Body.Bytes(), &ov); err != nil {
+			t.Fatal(err)
+		}
+		if ov.Issue != 1 || ov.Title != "hello" || ov.Overview == "" || ov.Stale != nil {
+			t.Errorf("got %+v, want fresh overview of issue 1", ov)
+		}
+	})
+
+	t.Run("related", func(t *testing.T) {
+		// The token scoped to hello/world does not see
+		// the related issue in hello/other; the unscoped token does.
+		for _, tc := range []struct {
+			token string
+			want  []string
+		}{
+			{all, []string{"https://github.com/hello/world/issues/2"}},
+			{related, []string{"https://github.com/hello/world/issues/2", "https://github.com/hello/other/issues/3"}},
+		} {
+			w := call("related", tc.token, "project=hello/world&issue=1")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", w.Code, w.Body)
+			}
+			var rel ghRelated
+			if err := json.Unmarshal(w.Body.Bytes(), &rel); err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, r := range rel.Related {
+				ids = append(ids, r.ID)
+			}
+			slices.Sort(ids)
+			slices.Sort(tc.want)
+			if !slices.Equal(ids, tc.want) {
+				t.Errorf("Related = %v, want %v", ids, tc.want)
+			}
+		}
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		// hello/world has no label configuration,
+		// so the response has no category or suggestions.
+		w := call("labels", labelsOnly, "project=hello/world&issue=1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+		var labs ghLabels
+		if err := json.Unmarshal(w.Body.Bytes(), &labs); err != nil {
+			t.Fatal(err)
+		}
+		if labs.Issue != 1 || labs.URL != "https://github.com/hello/world/issues/1" {
+			t.Errorf("got %+v, want issue 1", labs)
+		}
+	})
+}
@@ -156,6 +156,9 @@ func (g *Gaby) fixGitHubIssue(ctx context.Context, project string, issue int64)
 	if err := g.commentFixer.LogFixGitHubIssue(ctx, project, issue); err != nil {
 		return err
 	}
+	if err := g.issueFixer.LogFixGitHubIssue(ctx, project, issue); err != nil {
+		return err
+	}
 	if err := actions.Run(ctx, g.slog, g.db); err != nil {
 		return err
 	}
@@ -171,7 +174,7 @@ func (g *Gaby) syncGitHubProject(ctx context.Context, project string) error {
 	if err := g.github.SyncProject(ctx, project); err != nil {
 		return err
 	}
-	docs.Sync(g.docs, g.github)
+	docs.Sync(g.docs, g.githubDocs())
 	return nil
 }
 
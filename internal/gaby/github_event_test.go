@@ -154,6 +154,12 @@ func testGaby(t *testing.T, secret secret.DB) *Gaby {
 	// No fixes yet.
 	cf.EnableEdits()
 
+	ifx := commentfix.New(lg, gh, db, "issuefix")
+	ifx.EnableProject(testProject)
+	ifx.EnableProject(testProject2)
+	ifx.IssuesOnly()
+	ifx.EnableEdits()
+
 	lab := labels.New(lg, db, gh, cgen, "labels")
 
 	return &Gaby{
@@ -166,6 +172,7 @@ func testGaby(t *testing.T, secret secret.DB) *Gaby {
 		embed:          emb,
 		docs:           dc,
 		commentFixer:   cf,
+		issueFixer:     ifx,
 		relatedPoster:  rp,
 		labeler:        lab,
 	}
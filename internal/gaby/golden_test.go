@@ -0,0 +1,442 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/template"
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/apitoken"
+	"golang.org/x/oscar/internal/bisect"
+	"golang.org/x/oscar/internal/diff"
+	"golang.org/x/oscar/internal/discussion"
+	"golang.org/x/oscar/internal/evals"
+	"golang.org/x/oscar/internal/feedback"
+	"golang.org/x/oscar/internal/gerrit"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/labels"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/llmcost"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/rules"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage/timed"
+	"golang.org/x/oscar/internal/weekly"
+	"golang.org/x/oscar/internal/workload"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+// goldenTime is the fixed time used in golden test fixtures.
+var goldenTime = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// goldenIssue returns the issue used in golden test fixtures.
+func goldenIssue() *github.Issue {
+	return &github.Issue{
+		URL:       "https://api.github.com/repos/golang/go/issues/12",
+		HTMLURL:   "https://github.com/golang/go/issues/12",
+		Number:    12,
+		User:      github.User{Login: "gopher"},
+		Title:     "cmd/go: a <problem> & more",
+		CreatedAt: "2025-01-01T00:00:00Z",
+		UpdatedAt: "2025-01-02T00:00:00Z",
+		Body:      "The body.",
+		State:     "open",
+	}
+}
+
+// goldenPages are the pages rendered by [TestGolden].
+// Each page template (except common.tmpl) must be rendered
+// by at least one entry; see [TestGoldenCoverage].
+var goldenPages = []struct {
+	name string
+	tmpl *template.Template
+	page testPage
+}{
+	{"search-empty", searchPageTmpl, &searchPage{}},
+	{"search", searchPageTmpl, &searchPage{
+		Params: searchParams{Query: "golang/go#12", Threshold: ".5", Limit: "2", Allow: "GitHubIssue", Projects: "golang/go", State: "open", After: "2024-01-01", Rerank: true},
+		Results: []search.Result{
+			{Kind: search.KindGoDocumentation, Title: "the FAQ", Pinned: true},
+			{Kind: search.KindGitHubIssue, Title: "an issue"},
+			{Kind: search.KindGoDocumentation, Title: "a doc"},
+		},
+		Next: "/search?created_after=2024-01-01&cursor=2&limit=2&project=golang%2Fgo&q=golang%2Fgo%2312&rerank=on&state=open",
+	}},
+	{"search-nomore", searchPageTmpl, &searchPage{
+		Params: searchParams{Query: "golang/go#12", Limit: "2", Cursor: "2"},
+	}},
+	{"search-error", searchPageTmpl, &searchPage{
+		Params: searchParams{Query: "x"},
+		Error:  fmt.Errorf("a search <error>"),
+	}},
+	{"overview-empty", overviewPageTmpl, &overviewPage{}},
+	{"overview-issue", overviewPageTmpl, &overviewPage{
+		Params: overviewParams{Query: "12", OverviewType: issueOverviewType},
+		Result: &overviewResult{
+			Raw: &llmapp.Result{
+				Response: "## Overview\n\nAn *overview*.",
+				Prompt:   []llm.Part{llm.Text("a prompt")},
+			},
+			Typed: &overview.IssueResult{TotalComments: 2},
+			Issue: goldenIssue(),
+			Type:  issueOverviewType,
+			Desc:  "issue 12 and all 2 comments",
+		},
+	}},
+	{"overview-stale", overviewPageTmpl, &overviewPage{
+		Params: overviewParams{Query: "12"},
+		Result: &overviewResult{
+			Raw:   &llmapp.Result{Response: "An old overview.", Cached: true},
+			Typed: &overview.IssueResult{TotalComments: 2},
+			Issue: goldenIssue(),
+			Type:  issueOverviewType,
+			Desc:  "issue 12 and all 2 comments",
+			Stale: &staleResult{Generated: goldenTime, Err: fmt.Errorf("LLM unavailable")},
+		},
+	}},
+	{"overview-related", overviewPageTmpl, &overviewPage{
+		Params: overviewParams{Query: "12", OverviewType: relatedOverviewType, AllProjects: true},
+		Result: &overviewResult{
+			Raw: &llmapp.Result{Response: "{}"},
+			Typed: &search.Analysis{RelatedAnalysis: llmapp.RelatedAnalysis{
+				Output: llmapp.Related{
+					Summary: "A summary.",
+					Related: []llmapp.RelatedDoc{
+						{Title: "issue", URL: "https://github.com/golang/go/issues/1", Summary: "s", Relationship: "r", Relevance: "high", RelevanceReason: "rr"},
+						{Title: "doc", URL: "https://go.dev/doc/x", Summary: "s2", Relationship: "r2", Relevance: "low", RelevanceReason: "rr2"},
+					},
+				},
+			}},
+			Issue:       goldenIssue(),
+			Type:        relatedOverviewType,
+			Desc:        "issue 12 and 2 related docs from all projects",
+			AllProjects: true,
+		},
+	}},
+	{"overview-error", overviewPageTmpl, &overviewPage{
+		Params: overviewParams{Query: "12"},
+		Error:  fmt.Errorf("an <error>"),
+	}},
+	{"overviewhistory", overviewHistoryPageTmpl, &overviewHistoryPage{
+		Params: overviewHistoryParams{Query: "12"},
+		Issue:  goldenIssue(),
+		Entries: []*historyEntry{
+			{
+				HistoryEntry: &overview.HistoryEntry{Type: overview.IssueOverview, Time: goldenTime.Add(time.Hour), Model: "m", PromptVersion: "v", Text: "new", TotalComments: 3},
+				HTML:         safehtml.HTMLEscaped("new"),
+				Diff:         string(diff.Diff("old", []byte("old\n"), "new", []byte("new\n"))),
+			},
+			{
+				HistoryEntry: &overview.HistoryEntry{Type: overview.IssueOverview, Time: goldenTime, Model: "m", PromptVersion: "v", Text: "old", TotalComments: 2},
+				HTML:         safehtml.HTMLEscaped("old"),
+			},
+		},
+	}},
+	{"rules", rulesPageTmpl, &rulesPage{
+		Params: rulesParams{Query: "12"},
+		Result: &rulesResult{
+			Issue:       goldenIssue(),
+			IssueResult: rules.IssueResult{Response: "a rule violation"},
+			HTML:        safehtml.HTMLEscaped("a rule violation"),
+		},
+	}},
+	{"labels", labelsPageTmpl, &labelsPage{
+		Params: labelsParams{Query: "12"},
+		Results: []*labelsResult{{
+			Issue:       goldenIssue(),
+			Category:    labels.Category{Name: "bug", Label: "BugReport", Description: "a bug"},
+			Explanation: "an explanation",
+			BodyHTML:    safehtml.HTMLEscaped("The body."),
+		}},
+	}},
+	{"dbview", dbviewPageTmpl, &dbviewPage{
+		Params: dbviewParams{Start: "a", End: "b", Limit: "2"},
+		Result: &dbviewResult{Items: []item{{Key: "(a)", Value: "1"}, {Key: "(a, b)", Value: "<2>"}}},
+	}},
+	{"bisectlog", bisectLogPageTmpl, &bisectLogPage{
+		Tasks: []*bisect.Task{{
+			ID:         "task1",
+			Trigger:    "https://github.com/golang/go/issues/12#issuecomment-1",
+			Issue:      "https://github.com/golang/go/issues/12",
+			Repository: "https://go.googlesource.com/go",
+			Bad:        "master",
+			Good:       "go1.22.0",
+			Regression: "package p",
+			Commit:     "abc",
+			Created:    goldenTime,
+			Updated:    goldenTime.Add(time.Minute),
+		}},
+	}},
+	{"actionlog", actionLogPageTmpl, &actionLogPage{
+		StartTime: goldenTime.Format(time.DateTime),
+		EndTime:   goldenTime.Add(time.Hour).Format(time.DateTime),
+		Query:     actionQuery{Kind: "k", Project: "golang/go", Status: "run"},
+		Statuses:  actions.Statuses,
+		Entries: []*actions.Entry{{
+			Created: goldenTime,
+			Kind:    "k",
+			Key:     []byte("key"),
+			Action:  []byte(`{"a": 1}`),
+			Done:    goldenTime.Add(time.Minute),
+			Result:  []byte(`{"r": 2}`),
+		}},
+	}},
+	{"feedback", feedbackPageTmpl, &feedbackPage{
+		Report: &feedback.Report{
+			Kinds: []*feedback.KindReport{
+				{Kind: "overview", Comments: 3},
+				{Kind: "related", Comments: 4, Voted: 2, Up: 3, Down: 1},
+			},
+			Total: feedback.KindReport{Comments: 7, Voted: 2, Up: 3, Down: 1},
+		},
+		Comments: []*feedback.Comment{{
+			Project:   "golang/go",
+			Issue:     12,
+			HTMLURL:   "https://github.com/golang/go/issues/12#issuecomment-1",
+			Kind:      "related",
+			Created:   goldenTime,
+			Reactions: github.Reactions{TotalCount: 3, PlusOne: 2, MinusOne: 1},
+		}},
+	}},
+	{"workload", workloadPageTmpl, &workloadPage{
+		Params: workloadParams{Project: "golang/go"},
+		Report: &workload.Report{
+			Project: "golang/go",
+			Time:    goldenTime,
+			Areas: []*workload.AreaLoad{
+				{Area: "gopls", OpenIssues: 3, OpenPulls: 1, Unassigned: 1, Maintainers: []string{"bob", "alice"}},
+				{Area: "Documentation", OpenIssues: 1},
+			},
+			Maintainers: []*workload.MaintainerLoad{
+				{Login: "alice", Areas: []string{"gopls"}, OpenIssues: 2, Closed: 4},
+				{Login: "bob", Areas: []string{"gopls"}, OpenPulls: 1},
+			},
+			NoArea: workload.AreaLoad{OpenIssues: 5, Unassigned: 5},
+		},
+	}},
+	{"workload-error", workloadPageTmpl, &workloadPage{
+		Params: workloadParams{Project: "golang/go"},
+		Error:  errors.New("no workload report for golang/go yet; reports are computed daily"),
+	}},
+	{"weekly", weeklyPageTmpl, &weeklyPage{
+		Params: weeklyParams{Project: "golang/go"},
+		Report: &weekly.Report{
+			Project: "golang/go",
+			Start:   goldenTime.AddDate(0, 0, -7),
+			End:     goldenTime,
+			Time:    goldenTime,
+			Themes: []*weekly.Theme{
+				{Name: "gopls crashes", Summary: "Crashes in gopls.", Issues: []*weekly.Issue{
+					{Number: 1, Title: "gopls: crash on hover", URL: "https://github.com/golang/go/issues/1"},
+				}},
+				{Name: "Other", Issues: []*weekly.Issue{
+					{Number: 2, Title: "doc: typo", URL: "https://github.com/golang/go/issues/2"},
+				}},
+			},
+			Hot: []*weekly.Hot{
+				{Issue: weekly.Issue{Number: 3, Title: "proposal: add x", URL: "https://github.com/golang/go/issues/3"}, Comments: 25},
+			},
+			Closed: []*weekly.Issue{
+				{Number: 4, Title: "cmd/go: bug", URL: "https://github.com/golang/go/issues/4"},
+			},
+		},
+	}},
+	{"weekly-error", weeklyPageTmpl, &weeklyPage{
+		Params: weeklyParams{Project: "golang/go"},
+		Error:  errors.New("no weekly report for golang/go yet; reports are computed after each week (Monday to Monday, UTC)"),
+	}},
+	{"divertededits", divertedEditsPageTmpl, &divertedEditsPage{
+		DryRun: true,
+		Edits: []*github.DivertedEdit{{
+			DBTime: timed.DBTime(1),
+			Time:   goldenTime,
+			Edit: &github.TestingEdit{
+				Project:             "golang/go",
+				Issue:               12,
+				IssueCommentChanges: &github.IssueCommentChanges{Body: "hello"},
+			},
+		}},
+		Reviews: []*gerrit.DivertedReview{{
+			DBTime: timed.DBTime(2),
+			Time:   goldenTime,
+			Review: &gerrit.TestingReview{Project: "tools", ChangeNum: 34, Message: "hello"},
+		}},
+		Comments: []*discussion.DivertedComment{{
+			DBTime: timed.DBTime(3),
+			Time:   goldenTime,
+			Edit:   &discussion.TestingEdit{URL: "https://github.com/golang/go/discussions/56", Body: "hello"},
+		}},
+	}},
+	{"llmcache", llmCachePageTmpl, &llmCachePage{
+		Params:      llmCacheParams{Invalidate: "abc123"},
+		Stats:       llmapp.CacheStats{Hits: 3, Misses: 1},
+		TTL:         "720h0m0s",
+		Invalidated: 2,
+		Versions: []*llmapp.CacheVersion{
+			{Version: "", Model: "gemini-1.5-pro", Entries: 4},
+			{Version: "v2-0123456789ab", Model: "gemini-1.5-pro", Entries: 10},
+		},
+	}},
+	{"llmcost", llmCostPageTmpl, &llmCostPage{
+		Report: &llmcost.Report{
+			Time:          goldenTime,
+			DailyBudget:   5,
+			MonthlyBudget: 100,
+			Enforced:      true,
+			Paused:        true,
+			Essential:     []string{"labels", "rules"},
+			Today:         llmcost.Total{Name: "2025-01-02", Calls: 40, InputTokens: 800000, OutputTokens: 200000, Cost: 5.25},
+			Month:         llmcost.Total{Name: "2025-01", Calls: 60, InputTokens: 1000000, OutputTokens: 250000, Cost: 6.5},
+			Days: []*llmcost.Total{
+				{Name: "2025-01-02", Calls: 40, InputTokens: 800000, OutputTokens: 200000, Cost: 5.25},
+				{Name: "2025-01-01", Calls: 20, InputTokens: 200000, OutputTokens: 50000, Cost: 1.25},
+			},
+			Features: []*llmcost.Total{
+				{Name: "overview", Calls: 50, InputTokens: 900000, OutputTokens: 230000, Cost: 6.25},
+				{Name: "labels", Calls: 10, InputTokens: 100000, OutputTokens: 20000, Cost: 0.25},
+			},
+			Models: []*llmcost.Total{
+				{Name: "gemini-1.5-pro", Calls: 60, InputTokens: 1000000, OutputTokens: 250000, Cost: 6.5},
+			},
+		},
+	}},
+	{"suppress", suppressPageTmpl, &suppressPage{
+		Params: suppressParams{ID: "https://github.com/golang/go/issues/12", Action: "suppress", Reason: "misleading <old> issue"},
+		Done:   "suppressed https://github.com/golang/go/issues/12",
+		Suppressions: []*search.Suppression{
+			{ID: "https://github.com/golang/go/issues/12", User: "gopher@golang.org", Reason: "misleading <old> issue", Time: goldenTime},
+		},
+		Events: []*search.DenylistEvent{
+			{Time: goldenTime, User: "gopher@golang.org", Action: "suppress", ID: "https://github.com/golang/go/issues/12", Reason: "misleading <old> issue"},
+			{Time: goldenTime.Add(-time.Hour), User: "gopher@golang.org", Action: "unsuppress", ID: "https://go.dev/doc/x", Reason: "fixed"},
+		},
+	}},
+	{"evals", evalsPageTmpl, goldenEvalsPage()},
+	{"pins", pinsPageTmpl, &pinsPage{
+		Params: pinsParams{Topic: "generic <type> aliases", ID: "https://go.dev/doc/faq", Action: "pin", Reason: "FAQ entry"},
+		Done:   `pinned https://go.dev/doc/faq to "generic <type> aliases"`,
+		Pins: []*search.Pin{
+			{Topic: "generic <type> aliases", MinScore: search.DefaultPinScore, ID: "https://go.dev/doc/faq", User: "gopher@golang.org", Reason: "FAQ entry", Time: goldenTime},
+		},
+	}},
+	{"apitokens", apiTokensPageTmpl, &apiTokensPage{
+		Params: apiTokensParams{Action: "issue", Name: "gopher", Scopes: "overview, related", Projects: "golang/go"},
+		Done:   "issued token 0123456789ab for gopher",
+		Secret: "oscar_secret",
+		Tokens: []*apitoken.Token{
+			{ID: "0123456789ab", Name: "gopher", User: "admin@golang.org", Scopes: []apitoken.Scope{"overview", "related"}, Projects: []string{"golang/go"}, Created: goldenTime, Expires: goldenTime.Add(90 * 24 * time.Hour)},
+			{ID: "ba9876543210", Name: "<bot>", User: "admin@golang.org", Scopes: apitoken.Scopes, Created: goldenTime.Add(-time.Hour)},
+		},
+	}},
+	{"watchers", watchersPageTmpl, &watchersPage{
+		Params: watchersParams{Watcher: "github.Event:related", To: "-24h", Reason: "redo <bad> posts"},
+		Done:   "rewound github.Event:related from 1735787045000000000 to 1735700645000000000",
+		Watchers: []*watcherRow{
+			{&timed.WatcherState{WatcherID: timed.WatcherID{Kind: "docs.Doc", Name: "embeddocs"}}, 0},
+			{&timed.WatcherState{WatcherID: timed.WatcherID{Kind: "github.Event", Name: "related"}, Cursor: timed.DBTimeAt(goldenTime.Add(-24 * time.Hour)), Pending: 10000, More: true, Oldest: timed.DBTimeAt(goldenTime.Add(-23 * time.Hour))}, 23 * time.Hour},
+		},
+		Events: []*timed.WatcherEvent{
+			{WatcherID: timed.WatcherID{Kind: "github.Event", Name: "related"}, Time: goldenTime, User: "gopher@golang.org", Old: timed.DBTimeAt(goldenTime), New: timed.DBTimeAt(goldenTime.Add(-24 * time.Hour)), Reason: "redo <bad> posts"},
+		},
+	}},
+}
+
+// goldenEvalsPage returns the evaluations page used in golden tests.
+func goldenEvalsPage() *evalsPage {
+	a := &evals.Report{
+		Name: "baseline", Corpus: "go", Time: goldenTime.Add(-time.Hour), Model: "gemini-1.5-pro", Judge: "gemini-1.5-pro",
+		OverviewVersion: "abc123", RelatedVersion: "def456",
+		Scores: evals.Scores{Overviews: 2, Rouge1: 0.5, RougeL: 0.4, Judged: 2, Judge: 3.5, Rankings: 1, Precision: 0.2, Recall: 1, MRR: 1},
+		Cases: []*evals.CaseResult{
+			{Name: "golang/go#1", Scores: evals.Scores{Overviews: 1, Rouge1: 0.5, RougeL: 0.4, Judged: 1, Judge: 3}},
+			{Name: "golang/go#2", Scores: evals.Scores{Overviews: 1, Rouge1: 0.5, RougeL: 0.4, Judged: 1, Judge: 4}},
+		},
+	}
+	b := &evals.Report{
+		Name: "new <prompt>", Corpus: "go", Time: goldenTime, Model: "gemini-1.5-pro", Judge: "gemini-1.5-pro",
+		OverviewVersion: "abc999", RelatedVersion: "def456",
+		Scores: evals.Scores{Overviews: 2, Rouge1: 0.6, RougeL: 0.45, Judged: 2, Judge: 4, Rankings: 1, Precision: 0.2, Recall: 1, MRR: 0.5, Errors: 1},
+		Cases: []*evals.CaseResult{
+			{Name: "golang/go#1", Scores: evals.Scores{Overviews: 1, Rouge1: 0.6, RougeL: 0.45, Judged: 1, Judge: 4}},
+			{Name: "golang/go#3", Scores: evals.Scores{Overviews: 1, Rouge1: 0.6, RougeL: 0.45, Judged: 1, Judge: 4}},
+		},
+	}
+	return &evalsPage{
+		Params:     evalsParams{A: a.Name, B: b.Name},
+		Reports:    []*evals.Report{b, a},
+		Comparison: evals.Compare(a, b),
+	}
+}
+
+// TestGolden renders each page in [goldenPages] and compares
+// the result against testdata/golden/NAME.html.
+// Run "go test -run=TestGolden -update" to update the golden files
+// after an intentional change to a template.
+func TestGolden(t *testing.T) {
+	for _, test := range goldenPages {
+		t.Run(test.name, func(t *testing.T) {
+			test.page.setCommonPage()
+			got, err := Exec(test.tmpl, test.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validateHTML(string(got)); err != nil {
+				t.Fatalf("invalid HTML:\n%s", err)
+			}
+			file := filepath.Join("testdata", "golden", test.name+".html")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(file), 0o777); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(file, got, 0o666); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s mismatch (run with -update to accept):\n%s", file, diff.Diff("want", want, "got", got))
+			}
+		})
+	}
+}
+
+// TestGoldenCoverage checks that every page template
+// is rendered by at least one golden test.
+func TestGoldenCoverage(t *testing.T) {
+	files, err := fs.Glob(tmplFS, "tmpl/*.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	covered := make(map[string]bool)
+	for _, test := range goldenPages {
+		covered[test.tmpl.Name()] = true
+	}
+	for _, file := range files {
+		name := path.Base(file)
+		if name == commonTmpl {
+			continue
+		}
+		if !covered[name] {
+			t.Errorf("template %s has no golden test; add one to goldenPages", name)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/oscar/internal/apitoken"
+	"golang.org/x/oscar/internal/entity"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/search"
+)
+
+// The /api/hover endpoint serves editor integrations, such as a
+// gopls or VS Code hover, that show the issues related to the code
+// or error message under the cursor:
+//
+//	GET /api/hover?pkg=net/http&symbol=Client.Do
+//	GET /api/hover?pkg=net/http&error=http:+server+gave+HTTP+response+to+HTTPS+client
+//
+// The optional "limit" form value is the most issues to return
+// (default 3, at most 10). Callers authenticate with a token with the
+// "hover" scope, as for the /api/gh/ endpoints (see ghapi.go); only
+// issues in the token's projects are returned.
+//
+// Hovers are frequent and must be fast, so the endpoint never calls
+// the generative LLM: the summaries come from the issues' stored
+// overviews, or their bodies. Responses are cached, both by Gaby
+// and by clients (see hoverTTL).
+
+const (
+	defaultHoverLimit = 3
+	maxHoverLimit     = 10
+	// hoverMinScore is the lowest score of a returned issue.
+	// It is a little below the related poster's default cutoff,
+	// because hover queries are shorter than issues.
+	hoverMinScore = 0.78
+	// hoverTTL is how long hover responses are cached.
+	hoverTTL = 1 * time.Hour
+	// maxHoverCache is the most responses kept in the cache.
+	maxHoverCache = 10000
+	// maxHoverSummary is the most runes in an issue summary.
+	maxHoverSummary = 120
+)
+
+// A hoverResponse is the response of /api/hover.
+type hoverResponse struct {
+	Query  string       // the search text built from the request
+	Issues []hoverIssue // the related issues, best first
+}
+
+// A hoverIssue is an issue in a [hoverResponse].
+type hoverIssue struct {
+	URL     string
+	Number  int64
+	Title   string
+	State   string  // "open" or "closed"
+	Summary string  `json:",omitempty"` // one line, from the overview or body
+	Score   float64 // the similarity to the query
+}
+
+// A hoverCache caches hover responses by request.
+// The zero value is an empty cache.
+type hoverCache struct {
+	mu      sync.Mutex
+	entries map[string]hoverEntry
+}
+
+type hoverEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// get returns the unexpired cached response for key.
+func (c *hoverCache) get(key string, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || now.After(e.expires) {
+		return nil, false
+	}
+	return e.data, true
+}
+
+// put caches data for key until hoverTTL after now.
+// When the cache is full, it drops the expired entries,
+// or all of them if none has expired.
+func (c *hoverCache) put(key string, data []byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]hoverEntry)
+	}
+	if len(c.entries) >= maxHoverCache {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxHoverCache {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = hoverEntry{data: data, expires: now.Add(hoverTTL)}
+}
+
+func (g *Gaby) handleHoverAPI(w http.ResponseWriter, r *http.Request) {
+	secret, ok := bearerToken(r)
+	if !ok {
+		writeUnauthorized(w, errors.New("missing bearer token"))
+		return
+	}
+	tok, err := g.tokens.Authorize(secret, apitoken.ScopeHover, "")
+	if err != nil {
+		if errors.Is(err, apitoken.ErrForbidden) {
+			writeAPIError(w, codeForbidden, err)
+		} else {
+			writeUnauthorized(w, err)
+		}
+		return
+	}
+	q := hoverQuery(r.FormValue("pkg"), r.FormValue("symbol"), r.FormValue("error"))
+	if q == "" {
+		writeAPIError(w, codeInvalidQuery, errors.New("missing symbol or error"))
+		return
+	}
+	limit := defaultHoverLimit
+	if s := r.FormValue("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 || limit > maxHoverLimit {
+			writeAPIError(w, codeInvalidQuery, fmt.Errorf("limit must be between 1 and %d (got: %q)", maxHoverLimit, s))
+			return
+		}
+	}
+	projects := g.githubProjects
+	if len(tok.Projects) > 0 {
+		projects = slices.DeleteFunc(slices.Clone(projects), func(p string) bool { return !slices.Contains(tok.Projects, p) })
+	}
+	if len(projects) == 0 {
+		writeAPIError(w, codeForbidden, errors.New("token grants access to no known project"))
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(hoverTTL.Seconds())))
+	key := fmt.Sprintf("%s\x00%d\x00%s", q, limit, strings.Join(projects, ","))
+	now := time.Now()
+	if data, ok := g.hovers.get(key, now); ok {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+		return
+	}
+	results, err := g.search(r.Context(), q, search.Options{
+		Threshold: hoverMinScore,
+		Limit:     limit,
+		AllowKind: []string{search.KindGitHubIssue},
+		Sources:   projects,
+	})
+	if err != nil {
+		writeGHLLMError(w, err)
+		return
+	}
+	res := &hoverResponse{Query: q, Issues: []hoverIssue{}}
+	for _, sr := range results {
+		id, err := entity.Parse(sr.ID)
+		if err != nil || id.Kind != entity.GitHubIssue {
+			continue
+		}
+		iss, err := github.LookupIssue(g.db, id.Project, id.Number)
+		if err != nil {
+			continue
+		}
+		res.Issues = append(res.Issues, hoverIssue{
+			URL:     iss.HTMLURL,
+			Number:  iss.Number,
+			Title:   iss.Title,
+			State:   iss.State,
+			Summary: g.hoverSummary(iss),
+			Score:   sr.Score,
+		})
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		writeAPIError(w, codeInternal, fmt.Errorf("json.Marshal: %w", err))
+		return
+	}
+	g.hovers.put(key, data, now)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// hoverQuery returns the search text for a hover on the symbol,
+// or the error message, in the package.
+func hoverQuery(pkg, symbol, errText string) string {
+	symbol, errText = strings.TrimSpace(symbol), strings.Join(strings.Fields(errText), " ")
+	if symbol == "" && errText == "" {
+		return ""
+	}
+	var parts []string
+	for _, s := range []string{strings.TrimSpace(pkg), symbol, errText} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// hoverSummary returns a one-line summary of the issue:
+// the first line of its last overview, if any, or of its body.
+func (g *Gaby) hoverSummary(iss *github.Issue) string {
+	if g.overview != nil {
+		if r, _, ok := g.overview.LastForIssue(iss); ok {
+			if s := firstLine(r.Overview.Response); s != "" {
+				return s
+			}
+		}
+	}
+	return firstLine(iss.Body)
+}
+
+// firstLine returns the first line of prose in the Markdown text,
+// skipping headings, code blocks, quotes and HTML comments,
+// without Markdown emphasis and cut to at most maxHoverSummary runes.
+func firstLine(text string) string {
+	inCode, inComment := false, false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "```"):
+			inCode = !inCode
+			continue
+		case inCode:
+			continue
+		case strings.HasPrefix(line, "<!--"):
+			inComment = !strings.Contains(line, "-->")
+			continue
+		case inComment:
+			inComment = !strings.Contains(line, "-->")
+			continue
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ">"):
+			continue
+		}
+		line = strings.TrimLeft(line, "-*+ ")
+		line = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) > maxHoverSummary {
+			r := []rune(line)[:maxHoverSummary-1]
+			line = strings.TrimSpace(string(r)) + "…"
+		}
+		return line
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/apitoken"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+)
+
+func TestHoverAPI(t *testing.T) {
+	g := newTestGaby(t)
+	project := "hello/world"
+	g.githubProjects = []string{project}
+	// The test embedder only gives high scores to nearly identical texts,
+	// so issue 1's body is the query.
+	g.github.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "net/http: Client.Do hangs", Body: "net/http Client.Do", State: "open"})
+	g.github.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "cmd/go: build fails", Body: "It fails.", State: "closed"})
+	ctx := context.Background()
+	docs.Sync(g.docs, g.github)
+	embeddocs.Sync(ctx, g.slog, g.vector, g.embed, g.docs)
+	g.tokens = apitoken.NewStore(g.slog, g.db)
+
+	hover, _, err := g.tokens.Issue("editor", "admin", []apitoken.Scope{apitoken.ScopeHover}, nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := g.tokens.Issue("gh", "admin", []apitoken.Scope{apitoken.ScopeOverview}, nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(token string, q url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/api/hover?"+q.Encode(), nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		g.handleHoverAPI(w, r)
+		return w
+	}
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		q     url.Values
+		want  errorCode
+	}{
+		{"no token", "", url.Values{"symbol": {"Client.Do"}}, codeUnauthorized},
+		{"wrong scope", other, url.Values{"symbol": {"Client.Do"}}, codeForbidden},
+		{"no symbol", hover, url.Values{"pkg": {"net/http"}}, codeInvalidQuery},
+		{"bad limit", hover, url.Values{"symbol": {"Client.Do"}, "limit": {"100"}}, codeInvalidQuery},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := call(tc.token, tc.q)
+			var e apiError
+			if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil || e.Code != tc.want || w.Code != tc.want.status() {
+				t.Errorf("got (%d, %s), want (%d, %q)", w.Code, w.Body, tc.want.status(), tc.want)
+			}
+		})
+	}
+
+	q := url.Values{"pkg": {"net/http"}, "symbol": {"Client.Do"}, "limit": {"1"}}
+	w := call(hover, q)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "max-age=") {
+		t.Errorf("Cache-Control = %q, want max-age", cc)
+	}
+	var res hoverResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Query != "net/http Client.Do" || len(res.Issues) != 1 {
+		t.Fatalf("got %+v, want query %q and 1 issue", res, "net/http Client.Do")
+	}
+	if iss := res.Issues[0]; iss.Number != 1 || iss.State != "open" || iss.Summary != "net/http Client.Do" {
+		t.Errorf("got %+v, want open issue 1 summarized by its body", iss)
+	}
+
+	// The same request is served from the cache,
+	// without embedding the query again.
+	g.embed = failEmbedder{errors.New("no embedding")}
+	if w2 := call(hover, q); w2.Code != http.StatusOK || w2.Body.String() != w.Body.String() {
+		t.Errorf("cached request: got (%d, %s), want (200, %s)", w2.Code, w2.Body, w.Body)
+	}
+}
+
+func TestFirstLine(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"hello\nworld", "hello"},
+		{"# Title\n\n**Summary**: it `breaks`", "Summary: it breaks"},
+		{"<!--\ntemplate\n-->\n```\ncode\n```\n> quote\n- item", "item"},
+		{strings.Repeat("x", 200), strings.Repeat("x", maxHoverSummary-1) + "…"},
+	} {
+		if got := firstLine(tc.in); got != tc.want {
+			t.Errorf("firstLine(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
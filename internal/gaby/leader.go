@@ -0,0 +1,64 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"os"
+
+	"golang.org/x/oscar/internal/leader"
+)
+
+// With the -elect flag, the Gaby instances sharing a database elect
+// a leader (see package leader). Only the leader runs the scheduled
+// jobs; the others stand by, and take over within a minute
+// if the leader dies. Every instance should receive the requests
+// that trigger the jobs, for example from its own local cron.
+
+// initLeader starts the election of the instance
+// that runs the scheduled jobs.
+func (g *Gaby) initLeader() {
+	g.leader = leader.New(g.slog, g.db, "gaby", instanceID())
+	g.meta["instance"] = g.leader.ID()
+	g.leader.Campaign()
+	go g.leader.Run(g.ctx, leader.DefaultRenew)
+	g.registerLeaderMetric()
+}
+
+// instanceID returns an ID for this process that is unique
+// among the Gaby instances sharing the database.
+func instanceID() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s/%s/%d", cmp.Or(os.Getenv("K_REVISION"), "local"), host, os.Getpid())
+}
+
+// standby reports whether g must not run the scheduled job,
+// because another instance leads. If so, it says so in w.
+// Otherwise, it records the job as running until done is called.
+func (g *Gaby) standby(w http.ResponseWriter, job string) (standby bool, done func()) {
+	if g.leader == nil {
+		return false, func() {}
+	}
+	if !g.leader.IsLeader() {
+		l, _ := g.leader.Lease()
+		g.slog.Debug("gaby: standing by", "job", job, "leader", l.Holder)
+		fmt.Fprintf(w, "standing by: %s leads\n", l.Holder)
+		return true, nil
+	}
+	g.leader.SetJob(job)
+	return false, func() { g.leader.SetJob("") }
+}
+
+// checkLeader returns an error if g is not (or is no longer)
+// the leader, in which case it must not make changes.
+func (g *Gaby) checkLeader() error {
+	if g.leader == nil || g.leader.Verify() {
+		return nil
+	}
+	l, _ := g.leader.Lease()
+	return fmt.Errorf("gaby: no longer the leader (%s leads); not running actions", l.Holder)
+}
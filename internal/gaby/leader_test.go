@@ -0,0 +1,56 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/leader"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestStandby(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+
+	// Without -elect, every instance runs the jobs.
+	g := &Gaby{slog: lg, db: db}
+	if standby, done := g.standby(httptest.NewRecorder(), "cron"); standby {
+		t.Fatal("standby without an election")
+	} else {
+		done()
+	}
+	if err := g.checkLeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	primary := &Gaby{slog: lg, db: db, leader: leader.New(lg, db, "gaby", "primary")}
+	other := &Gaby{slog: lg, db: db, leader: leader.New(lg, db, "gaby", "other")}
+	primary.leader.Campaign()
+	other.leader.Campaign()
+
+	standby, done := primary.standby(httptest.NewRecorder(), "cron")
+	if standby {
+		t.Fatal("leader stood by")
+	}
+	if err := primary.checkLeader(); err != nil {
+		t.Error(err)
+	}
+	done()
+
+	w := httptest.NewRecorder()
+	if standby, _ := other.standby(w, "cron"); !standby {
+		t.Fatal("standby ran job")
+	}
+	if !strings.Contains(w.Body.String(), "primary leads") {
+		t.Errorf("standby response = %q, want mention of leader", w.Body.String())
+	}
+	if err := other.checkLeader(); err == nil {
+		t.Error("standby passed checkLeader")
+	}
+}
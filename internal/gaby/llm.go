@@ -0,0 +1,164 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/anthropic"
+	"golang.org/x/oscar/internal/chaos"
+	"golang.org/x/oscar/internal/circuit"
+	"golang.org/x/oscar/internal/gcp/gemini"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/ollama"
+	"golang.org/x/oscar/internal/openai"
+)
+
+// llmProviders are the LLM providers that can be named
+// in the -llm and -embedder flags.
+var llmProviders = []string{"gemini", "openai", "anthropic", "ollama"}
+
+// defaultEmbeddingModels are the embedding models used for the
+// providers named in the -embedder flag.
+var defaultEmbeddingModels = map[string]string{
+	"gemini": gemini.DefaultEmbeddingModel,
+	"openai": openai.DefaultEmbeddingModel,
+	"ollama": ollama.DefaultEmbeddingModel,
+}
+
+// embeddingModel returns the embedding model used for the provider,
+// as "provider/name" (see [embeddocs.Space]).
+func embeddingModel(provider string) string {
+	return provider + "/" + defaultEmbeddingModels[provider]
+}
+
+// initLLM connects to the LLM providers named by the -llm and -embedder flags.
+// It returns a content generator that fails over between the -llm providers
+// in order, subject to the -llmrates limits, and the embedder of g.index.
+// Each provider's HTTP client is guarded by its own circuit breaker,
+// added to g.breakers, so that the failover skips a provider
+// that is down without waiting for it.
+//
+// The index embeds with the model of its active space, which is
+// the -embedder model unless the flag changed since the corpus was
+// last embedded; in that case, initLLM also connects to the active
+// model, so that Gaby keeps working until the corpus is migrated
+// to the -embedder model (see devtools/cmd/embedmigrate).
+func (g *Gaby) initLLM() (*llm.Failover, llm.Embedder, error) {
+	clients := make(map[string]any)      // by provider and embedding model
+	hcs := make(map[string]*http.Client) // by provider
+	connect := func(name, embedModel string) (any, error) {
+		if !slices.Contains(llmProviders, name) {
+			return nil, fmt.Errorf("unknown LLM provider %q (want one of %s)", name, strings.Join(llmProviders, ", "))
+		}
+		embedModel = cmp.Or(embedModel, defaultEmbeddingModels[name])
+		key := name + "/" + embedModel
+		if c, ok := clients[key]; ok {
+			return c, nil
+		}
+		hc, ok := hcs[name]
+		if !ok {
+			// The Gemini breaker keeps its original name,
+			// from when Gemini was the only provider.
+			bname := "llm"
+			if name != "gemini" {
+				bname = "llm-" + name
+			}
+			b := circuit.New(g.slog, bname)
+			g.breakers = append(g.breakers, b)
+			hc = b.Client(g.chaos.Client(chaos.LLM, g.http))
+			hcs[name] = hc
+		}
+		var c any
+		var err error
+		switch name {
+		case "gemini":
+			c, err = gemini.NewClient(g.ctx, g.slog, g.secret, hc, embedModel, gemini.DefaultGenerativeModel)
+		case "openai":
+			c, err = openai.NewClient(g.slog, g.secret, hc, embedModel, openai.DefaultGenerativeModel)
+		case "anthropic":
+			c, err = anthropic.NewClient(g.slog, g.secret, hc, anthropic.DefaultModel)
+		case "ollama":
+			var oc *ollama.Client
+			oc, err = ollama.NewClient(g.slog, hc, "", embedModel)
+			if err == nil {
+				oc.SetGenerativeModel(ollama.DefaultGenerativeModel)
+			}
+			c = oc
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		clients[key] = c
+		return c, nil
+	}
+	connectEmbedder := func(model string) (llm.Embedder, error) {
+		name, embedModel, _ := strings.Cut(model, "/")
+		c, err := connect(name, embedModel)
+		if err != nil {
+			return nil, err
+		}
+		embed, ok := c.(llm.Embedder)
+		if !ok || embedModel == "" {
+			return nil, fmt.Errorf("%s does not support embedding", name)
+		}
+		return embed, nil
+	}
+
+	gen := llm.NewFailover(g.slog)
+	for _, name := range strings.Split(flags.llm, ",") {
+		name = strings.TrimSpace(name)
+		if slices.Contains(gen.Providers(), name) {
+			return nil, nil, fmt.Errorf("-llm: duplicate provider %q", name)
+		}
+		c, err := connect(name, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("-llm: %w", err)
+		}
+		if bg, ok := c.(llm.BatchContentGenerator); ok && len(gen.Providers()) == 0 {
+			// Only the first provider's model is used for batches,
+			// since it answers the ordinary calls that read the batch
+			// responses from the LLM cache.
+			g.batchLLM = bg
+		}
+		gen.Add(name, c.(llm.ContentGenerator), nil)
+	}
+	if flags.llmRates != "" {
+		for _, kv := range strings.Split(flags.llmRates, ",") {
+			name, n, ok := strings.Cut(kv, "=")
+			rpm, err := strconv.Atoi(n)
+			if !ok || err != nil || rpm <= 0 {
+				return nil, nil, fmt.Errorf("-llmrates: bad entry %q: want PROVIDER=REQUESTS_PER_MINUTE", kv)
+			}
+			if !slices.Contains(gen.Providers(), name) {
+				return nil, nil, fmt.Errorf("-llmrates: provider %q not in -llm", name)
+			}
+			gen.SetRateLimit(name, rpm, time.Minute)
+		}
+	}
+
+	model := embeddingModel(flags.embedder)
+	embed, err := connectEmbedder(model)
+	if err != nil {
+		return nil, nil, fmt.Errorf("-embedder: %w", err)
+	}
+	g.index.SetEmbedder(model, embed)
+	if active := g.index.Active().Model; active != model {
+		g.slog.Warn("gaby: -embedder model is not the active embedding model; run embedmigrate to switch",
+			"embedder", model, "active", active)
+		embed, err := connectEmbedder(active)
+		if err != nil {
+			return nil, nil, fmt.Errorf("active embedding model: %w", err)
+		}
+		g.index.SetEmbedder(active, embed)
+	}
+	return gen, g.index, nil
+}
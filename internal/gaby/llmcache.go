@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/oscar/internal/llmapp"
+)
+
+// llmCachePage holds the fields needed to display the state
+// of the cache of LLM responses.
+type llmCachePage struct {
+	CommonPage
+
+	Params      llmCacheParams         // the raw parameters
+	Stats       llmapp.CacheStats      // hits and misses since gaby started
+	TTL         string                 // the time to live of cached responses, or "" if none
+	Versions    []*llmapp.CacheVersion // cached responses by prompt version and model
+	Invalidated int                    // number of responses deleted by the invalidate parameter
+}
+
+// llmCacheParams holds the raw inputs to the LLM cache form.
+type llmCacheParams struct {
+	Invalidate string // prompt version whose responses to delete, or "all"
+}
+
+const paramInvalidate = "invalidate"
+
+var safeInvalidate = toSafeID(paramInvalidate)
+
+var llmCachePageTmpl = newTemplate(llmCacheTmplFile, template.FuncMap{
+	"percent": func(f float64) float64 { return 100 * f },
+})
+
+func (g *Gaby) handleLLMCache(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateLLMCachePage(r), llmCachePageTmpl)
+}
+
+// populateLLMCachePage returns the contents of the LLM cache page,
+// after deleting the cached responses of the prompt version in the
+// "invalidate" form value, if any ("all" deletes all responses).
+func (g *Gaby) populateLLMCachePage(r *http.Request) *llmCachePage {
+	p := &llmCachePage{
+		Params: llmCacheParams{Invalidate: r.FormValue(paramInvalidate)},
+	}
+	switch v := p.Params.Invalidate; v {
+	case "":
+	case "all":
+		p.Invalidated = g.llmapp.InvalidateCache("")
+	default:
+		p.Invalidated = g.llmapp.InvalidateCache(v)
+	}
+	p.Stats = g.llmapp.CacheStats()
+	if flags.llmCacheTTL > 0 {
+		p.TTL = flags.llmCacheTTL.String()
+	}
+	p.Versions = g.llmapp.CacheVersions()
+	p.setCommonPage()
+	return p
+}
+
+func (p *llmCachePage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          llmCacheID,
+		Description: "Inspect the cache of LLM responses, and invalidate the responses of a prompt version.",
+		Form: Form{
+			Inputs: []FormInput{
+				{
+					Label:       "invalidate",
+					Type:        "string",
+					Description: `the prompt version whose cached responses to delete, or "all"`,
+					Name:        safeInvalidate,
+					Typed: TextInput{
+						ID:    safeInvalidate,
+						Value: p.Params.Invalidate,
+					},
+				},
+			},
+			SubmitText: "invalidate",
+		},
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/llmcost"
+)
+
+// initCost sets up g.cost, the accounting of the cost of LLM use,
+// according to the -llmprices, -llmdailybudget, -llmmonthlybudget,
+// -llmenforcebudget and -llmessential flags.
+func (g *Gaby) initCost() error {
+	prices, err := parseLLMPrices(flags.llmPrices)
+	if err != nil {
+		return err
+	}
+	if flags.llmDailyBudget < 0 || flags.llmMonthlyBudget < 0 {
+		return fmt.Errorf("-llmdailybudget and -llmmonthlybudget must not be negative")
+	}
+	m := llmcost.New(g.slog, g.db)
+	for model, p := range prices {
+		m.SetPrice(model, p)
+	}
+	m.SetBudgets(flags.llmDailyBudget, flags.llmMonthlyBudget)
+	if flags.llmEnforce {
+		var essential []string
+		for _, f := range strings.Split(flags.llmEssential, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				essential = append(essential, f)
+			}
+		}
+		m.EnableEnforcement(essential...)
+	}
+	g.cost = m
+	return nil
+}
+
+// parseLLMPrices parses the argument to the -llmprices flag,
+// a comma-separated list of MODEL=INPUT/OUTPUT entries giving the
+// price of a model in dollars per million prompt and completion tokens.
+func parseLLMPrices(s string) (map[string]llmcost.Price, error) {
+	if s == "" {
+		return nil, nil
+	}
+	m := make(map[string]llmcost.Price)
+	for _, kv := range strings.Split(s, ",") {
+		model, price, ok := strings.Cut(kv, "=")
+		in, out, ok2 := strings.Cut(price, "/")
+		fin, err := strconv.ParseFloat(in, 64)
+		fout, err2 := strconv.ParseFloat(out, 64)
+		if !ok || !ok2 || model == "" || err != nil || err2 != nil || fin < 0 || fout < 0 {
+			return nil, fmt.Errorf("invalid arg %q to -llmprices: want MODEL=INPUT/OUTPUT dollars per million tokens", kv)
+		}
+		m[model] = llmcost.Price{Input: fin, Output: fout}
+	}
+	return m, nil
+}
+
+// featureLLM returns the content generator to use for the feature,
+// which attributes its use of the LLM to the feature and checks
+// its output with g.moderator.
+func (g *Gaby) featureLLM(feature string) llm.ContentGenerator {
+	gen := g.meteredLLM(feature)
+	if g.moderator == nil {
+		return gen
+	}
+	return g.moderator.ContentGenerator(gen)
+}
+
+// featureLLMApp returns the LLM client to use for the feature,
+// which attributes its use of the LLM to the feature.
+// The client checks the LLM output with its own moderator
+// (see [llmapp.Client.SetModerator]).
+func (g *Gaby) featureLLMApp(feature string) *llmapp.Client {
+	if g.cost == nil {
+		return g.llmapp
+	}
+	return g.llmapp.WithContentGenerator(g.meteredLLM(feature))
+}
+
+// meteredLLM returns the content generator that attributes
+// its use of the LLM to the feature.
+func (g *Gaby) meteredLLM(feature string) llm.ContentGenerator {
+	if g.cost == nil {
+		return g.llm
+	}
+	return g.cost.ContentGenerator(feature, g.llm)
+}
+
+// paused reports whether the feature is paused because an LLM budget
+// is spent (see -llmenforcebudget), logging if so.
+func (g *Gaby) paused(feature string) bool {
+	if g.cost == nil || !g.cost.Paused(feature) {
+		return false
+	}
+	g.slog.Warn("gaby: LLM budget spent; feature paused", "feature", feature)
+	return true
+}
+
+// llmCostPage holds the fields needed to display the cost of LLM use.
+type llmCostPage struct {
+	CommonPage
+
+	Report *llmcost.Report // the use of LLMs this month
+}
+
+var llmCostPageTmpl = newTemplate(llmCostTmplFile, template.FuncMap{
+	"dollars": func(f float64) string { return fmt.Sprintf("$%.2f", f) },
+	"join":    func(s []string) string { return strings.Join(s, ", ") },
+	"totals": func(name string, ts []*llmcost.Total) llmCostTable {
+		return llmCostTable{Name: name, Totals: ts}
+	},
+})
+
+// llmCostTable is a table of the totals by day, feature or model.
+type llmCostTable struct {
+	Name   string // the column header of the names
+	Totals []*llmcost.Total
+}
+
+func (g *Gaby) handleLLMCost(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateLLMCostPage(), llmCostPageTmpl)
+}
+
+// populateLLMCostPage returns the contents of the LLM cost page.
+func (g *Gaby) populateLLMCostPage() *llmCostPage {
+	p := &llmCostPage{
+		Report: g.cost.Report(),
+	}
+	p.setCommonPage()
+	return p
+}
+
+func (p *llmCostPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          llmCostID,
+		Description: "Track the estimated tokens and cost of LLM calls by day, feature and model, against the daily and monthly budgets.",
+		Form: Form{
+			Inputs:     nil,
+			SubmitText: "void",
+		},
+	}
+}
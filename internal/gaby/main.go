@@ -16,7 +16,9 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,48 +27,137 @@ import (
 	ometric "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/noop"
 	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/ann"
+	"golang.org/x/oscar/internal/apitoken"
+	"golang.org/x/oscar/internal/approval"
 	"golang.org/x/oscar/internal/bisect"
+	"golang.org/x/oscar/internal/chaos"
+	"golang.org/x/oscar/internal/checklist"
+	"golang.org/x/oscar/internal/circuit"
 	"golang.org/x/oscar/internal/commentfix"
+	"golang.org/x/oscar/internal/commitmsg"
+	"golang.org/x/oscar/internal/compose"
+	"golang.org/x/oscar/internal/corpuscheck"
 	"golang.org/x/oscar/internal/crawl"
 	"golang.org/x/oscar/internal/dbspec"
 	"golang.org/x/oscar/internal/discussion"
 	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/duplicate"
+	"golang.org/x/oscar/internal/dupmine"
 	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/feedback"
+	"golang.org/x/oscar/internal/gc"
 	"golang.org/x/oscar/internal/gcp/checks"
 	"golang.org/x/oscar/internal/gcp/firestore"
 	"golang.org/x/oscar/internal/gcp/gcphandler"
 	"golang.org/x/oscar/internal/gcp/gcpmetrics"
 	"golang.org/x/oscar/internal/gcp/gcpsecret"
-	"golang.org/x/oscar/internal/gcp/gemini"
 	"golang.org/x/oscar/internal/gcp/tasks"
 	"golang.org/x/oscar/internal/gerrit"
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/googlegroups"
 	"golang.org/x/oscar/internal/labels"
+	"golang.org/x/oscar/internal/leader"
+	"golang.org/x/oscar/internal/learnrank"
 	"golang.org/x/oscar/internal/llm"
 	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/llmcost"
+	"golang.org/x/oscar/internal/moderate"
+	"golang.org/x/oscar/internal/mute"
 	"golang.org/x/oscar/internal/overview"
-	"golang.org/x/oscar/internal/pebble"
+	"golang.org/x/oscar/internal/pipeline"
+	"golang.org/x/oscar/internal/plugins"
+	"golang.org/x/oscar/internal/policy"
+	"golang.org/x/oscar/internal/postgres"
 	"golang.org/x/oscar/internal/queue"
 	"golang.org/x/oscar/internal/related"
+	"golang.org/x/oscar/internal/repoconfig"
 	"golang.org/x/oscar/internal/rules"
 	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/searchdiff"
 	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/sqlite"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/storage/timed"
+	"golang.org/x/oscar/internal/weekly"
+	"golang.org/x/oscar/internal/workload"
 )
 
 type gabyFlags struct {
-	search        bool
-	project       string
-	firestoredb   string
-	enablesync    bool
-	enablechanges bool
-	testactions   bool
-	level         string
-	overlay       string
-	autoApprove   string // list of packages that do not require manual approval
-	enforcePolicy bool
+	search           bool
+	project          string
+	firestoredb      string
+	enablesync       bool
+	enablechanges    bool
+	testactions      bool
+	level            string
+	overlay          string
+	localDB          string // DB spec to use instead of Firestore
+	autoApprove      string // list of packages that do not require manual approval
+	plainText        string // list of GitHub projects to post plain-text comments in
+	graphQL          string // list of GitHub projects to sync using the GraphQL API
+	enforcePolicy    bool
+	dryRun           bool
+	selfTest         bool
+	snapshot         string // DB spec of a read-only snapshot to serve
+	elect            bool   // elect the instance that runs scheduled jobs
+	vectorMem        int64  // memory limit for in-memory vector DB, in MiB
+	vectorANN        int    // if > 0, candidates examined per approximate vector search
+	chaos            string // TARGET=RATE list of failures to inject (chaos builds only)
+	pipelineDir      string // directory of bot pipeline definitions
+	backup           string // file to which to write a backup of the DB
+	restore          string // file from which to restore a backup into an empty DB
+	pprof            bool
+	gcActionLog      time.Duration // if > 0, age of completed actions deleted by /gc
+	gcOverview       time.Duration // if > 0, age of superseded overview history deleted by /gc
+	gcVectors        bool          // delete the vectors of deleted documents in /gc
+	gcArchive        string        // directory in which /gc archives the data it deletes
+	pluginDir        string        // directory of plugin manifests for pipelines
+	policyFile       string        // YAML file of per-project posting policies
+	pluginSandbox    string        // command prefix running exec plugins in a sandbox
+	relatedPulls     bool          // post related documents on pull requests
+	relatedPRMode    string        // how to post related documents on pull requests
+	relatedScores    string        // minimum scores for related documents, by kind
+	relatedCalib     float64       // if > 0, percentile for calibrating related document scores
+	relatedDisc      bool          // post related documents on discussions
+	relatedWhy       bool          // explain why each related document is related
+	relatedRerank    string        // name of the learned model reranking related documents
+	approvalIssue    string        // GitHub issue for approving actions
+	approvers        string        // list of GitHub users who can approve actions
+	commitMsgs       bool          // check commit messages of changes and pull requests
+	acknowledge      string        // projects whose new issues are acknowledged, with triage days
+	combineWindow    time.Duration // if > 0, combine bot comments on the same issue posted within this window
+	dupFeedback      string        // URL for detailed feedback on duplicate comments
+	overviewPRs      int           // if > 0, review comments a pull request needs to get an overview
+	coalesce         time.Duration // if > 0, delay before running the newest of successive updates to a comment
+	digestDays       int           // if > 0, post digests of new comments on hot issues at most this often
+	digestMin        int           // new comments an issue needs to get a digest
+	weeklyDisc       string        // GitHub discussion on which to post weekly reports
+	muteLabel        string        // label that stops bot comments on an issue
+	muteCommand      string        // comment that stops bot comments on an issue
+	unmuteCommand    string        // comment that undoes muteCommand
+	repoConfig       bool          // apply the settings in each GitHub project's .oscar.yaml
+	optOutLabels     string        // labels that stop bot comments on an issue while it has them
+	language         string        // default language of posted overviews
+	promptDir        string        // directory of prompts replacing the built-in LLM prompts
+	compressDB       int           // if > 0, compress stored values of at least this many bytes
+	llm              string        // LLM providers for content generation, in failover order
+	llmRates         string        // rate limits of LLM providers, in requests per minute
+	embedder         string        // LLM provider for embeddings
+	embedWorkers     int           // number of concurrent embedding requests
+	contextWindow    int           // size of the LLM's context window, in tokens
+	llmCacheTTL      time.Duration // if > 0, time to live of cached LLM responses
+	llmPrices        string        // prices of LLM models, overriding the defaults
+	llmDailyBudget   float64       // if > 0, daily LLM budget in dollars
+	llmMonthlyBudget float64       // if > 0, monthly LLM budget in dollars
+	llmEnforce       bool          // pause non-essential features when an LLM budget is spent
+	llmEssential     string        // features that are never paused
+	moderateDeny     string        // file of regexps that block LLM output
+	moderatePolicy   bool          // block LLM output that violates safety policies
+	moderateMentions bool          // strip @-mentions from LLM output
+	moderateLinks    string        // hosts that LLM output may link to; empty means all
+	searchQueries    string        // file of benchmark search queries to diff after each index change
+	searchChurn      float64       // mean churn of the benchmark search results that raises an alert
 }
 
 var flags gabyFlags
@@ -80,45 +171,143 @@ func init() {
 	flag.BoolVar(&flags.testactions, "testactions", false, "allow approved actions to run (for testing only)")
 	flag.StringVar(&flags.level, "level", "info", "initial log level")
 	flag.StringVar(&flags.overlay, "overlay", "", "spec for overlay to DB; see internal/dbspec for syntax")
+	flag.StringVar(&flags.localDB, "db", "", "if set, spec for the DB to use instead of the -firestoredb DB, such as postgres:DSN or sqlite:FILE; see internal/dbspec for syntax")
 	flag.StringVar(&flags.autoApprove, "autoapprove", "", "comma-separated list of packages whose actions do not require approval")
+	flag.StringVar(&flags.plainText, "plaintext", "", "comma-separated list of GitHub projects whose bot comments should be plain text (no hidden tags, collapsible sections or heavy formatting), for screen-reader friendliness")
+	flag.StringVar(&flags.graphQL, "graphql", "", "comma-separated list of GitHub projects whose issues and comments are synced using the GraphQL API, which uses far fewer requests than the REST API for large projects (other projects are synced using the REST API)")
 	flag.BoolVar(&flags.enforcePolicy, "enforcepolicy", false, "whether to enforce safety policies on LLM inputs and outputs")
+	flag.BoolVar(&flags.dryRun, "dryrun", false, "record GitHub edits, GitHub Discussions comments and Gerrit reviews in the database instead of applying them; implies -enablechanges")
+	flag.BoolVar(&flags.selfTest, "selftest", false, "check the configuration and dependencies, print a JSON report and exit")
+	flag.StringVar(&flags.snapshot, "snapshot", "", "if set, spec for a DB (such as pebble:snapshot.db) from which to serve only search by document ID and stored overviews, read-only and without the LLM, GitHub or GCP; see internal/dbspec for syntax")
+	flag.BoolVar(&flags.elect, "elect", false, "elect a leader among the gaby instances sharing the DB: only the leader runs scheduled jobs (/cron, /crawl and /corpuscheck), and if it dies a standby instance takes over within a minute")
+	flag.BoolVar(&flags.pprof, "pprof", false, "serve /debug/pprof and /profile endpoints for capturing profiles")
+	flag.BoolVar(&flags.relatedPulls, "relatedprs", false, "also post related documents on new pull requests")
+	flag.StringVar(&flags.relatedPRMode, "relatedprmode", "comment", "with -relatedprs, how to post related documents on pull requests: comment, review or checkrun")
+	flag.StringVar(&flags.relatedScores, "relatedminscores", "", "comma-separated list of KIND=SCORE minimum scores for related documents of each kind (such as GoDocumentation=0.78), overriding the default and calibration")
+	flag.Float64Var(&flags.relatedCalib, "relatedcalibrate", 0, "if set, a percentile (such as 0.95) of recorded scores at which to calibrate the minimum score for each kind of related document")
+	flag.BoolVar(&flags.relatedDisc, "relateddiscussions", false, "also post related documents on new discussions")
+	flag.BoolVar(&flags.relatedWhy, "relatedexplain", false, "use the LLM to explain, in one line under each link, why each posted related document is related")
+	flag.StringVar(&flags.relatedRerank, "relatedrerank", "", "if set, the name of a learned model (trained by devtools/cmd/learnrank) with which to rerank related documents; a newly trained model is used without restarting")
+	flag.BoolVar(&flags.commitMsgs, "commitmsgs", false, "suggest corrections to the commit messages of new Gerrit changes and pull requests")
+	flag.IntVar(&flags.overviewPRs, "overviewprs", 0, "if set, also post overviews of open pull requests once they have this many review comments, updated after each further such number")
+	flag.StringVar(&flags.dupFeedback, "duplicatefeedback", "", "if set, the URL linked from duplicate-issue comments as the place for detailed feedback; otherwise the comments only ask for emoji votes")
+	flag.DurationVar(&flags.combineWindow, "combinecomments", 0, "if set, a window (such as 10m) within which the comments that the related, duplicate and rules bots would post on the same issue, and a note of labels added, are combined into a single comment")
+	flag.IntVar(&flags.digestDays, "overviewdigest", 0, "if set, every this many days post a digest of the new comments on hot issues that have an overview")
+	flag.IntVar(&flags.digestMin, "overviewdigestmin", 20, "the number of new comments since the last overview or digest that make an issue hot (see -overviewdigest)")
+	flag.StringVar(&flags.weeklyDisc, "weeklydiscussion", "", "GitHub discussion (OWNER/REPO#NUMBER) on which to post the weekly digest of the discussion's project")
+	flag.StringVar(&flags.promptDir, "promptdir", "", "directory of NAME.tmpl files replacing the built-in LLM prompts of the same names, after those stored in the DB (see llmapp.Prompt)")
+	flag.StringVar(&flags.muteLabel, "mutelabel", mute.DefaultLabel, "label that, once added to an issue, stops the overview and related bots from ever touching the issue again (empty to disable)")
+	flag.StringVar(&flags.muteCommand, "mutecommand", mute.DefaultCommand, "comment line that, once posted on an issue by a maintainer (the repository's owner, an organization member or a collaborator), stops the overview and related bots from touching the issue again until -unmutecommand (empty to disable)")
+	flag.StringVar(&flags.unmuteCommand, "unmutecommand", mute.DefaultUnmuteCommand, "comment line that, once posted on a muted issue by a maintainer, lets the overview and related bots touch it again (empty to disable)")
+	flag.BoolVar(&flags.repoConfig, "repoconfig", false, "sync each GitHub project's .oscar.yaml, in which its maintainers can add opt-out labels, raise the minimum score of related documents and choose the language of overviews; see internal/repoconfig for how these merge with -optoutlabels and -language")
+	flag.StringVar(&flags.optOutLabels, "optoutlabels", "", "comma-separated list of labels that stop the overview and related bots from posting on an issue while it has any of them")
+	flag.StringVar(&flags.language, "language", "", "BCP 47 tag of the language in which to write posted overviews, such as ja (default: the prompt's, English); projects can override it with -repoconfig")
+	flag.DurationVar(&flags.coalesce, "coalesceupdates", 0, "if set, a delay (such as 5m) to wait after the latest update to a bot comment before editing it, so that rapid successive updates collapse into one edit")
+	flag.StringVar(&flags.acknowledge, "acknowledge", "", "comma-separated list of PROJECT=DAYS pairs: new issues in each GitHub project get a single first comment thanking the author, saying that triage usually takes DAYS days and listing related documents")
+	flag.StringVar(&flags.approvalIssue, "approvalissue", "", "GitHub issue (OWNER/REPO#NUMBER), typically private, on which to preview actions that require approval, so they can be approved by reaction or command")
+	flag.StringVar(&flags.approvers, "approvers", "", "comma-separated list of GitHub users who can approve actions on the -approvalissue")
+	flag.StringVar(&flags.llm, "llm", "gemini", "comma-separated list of LLM providers for content generation ("+strings.Join(llmProviders, ", ")+"), tried in order: when one fails or is over its rate limit, the next is used")
+	flag.StringVar(&flags.llmRates, "llmrates", "", "comma-separated list of PROVIDER=N rate limits, in requests per minute, for the -llm providers")
+	flag.StringVar(&flags.embedder, "embedder", "gemini", "LLM provider for embeddings ("+strings.Join(llmProviders, ", ")+" except anthropic); after a change, Gaby keeps using the previous model until the documents are re-embedded with embedmigrate")
+	flag.IntVar(&flags.embedWorkers, "embedworkers", embeddocs.DefaultPipeline.Workers, "number of concurrent batch-embedding requests when embedding new documents")
+	flag.IntVar(&flags.contextWindow, "contextwindow", llmapp.DefaultContextWindow, "size in tokens of the context window of the -llm models; overviews of longer discussions summarize the comments in batches first")
+	flag.DurationVar(&flags.llmCacheTTL, "llmcachettl", 0, "if set, the time to live (such as 720h) of cached LLM responses, after which they are generated again; cached responses can also be invalidated on the /llmcache page")
+	flag.StringVar(&flags.llmPrices, "llmprices", "", "comma-separated list of MODEL=INPUT/OUTPUT prices of LLM models, in dollars per million prompt and completion tokens, overriding the defaults for the default models")
+	flag.Float64Var(&flags.llmDailyBudget, "llmdailybudget", 0, "if set, the daily budget in dollars for LLM use, shown on the /llmcost page")
+	flag.Float64Var(&flags.llmMonthlyBudget, "llmmonthlybudget", 0, "if set, the monthly budget in dollars for LLM use, shown on the /llmcost page")
+	flag.BoolVar(&flags.llmEnforce, "llmenforcebudget", false, "once the -llmdailybudget or -llmmonthlybudget is spent, pause the features that use the LLM, except the -llmessential ones, until the next day or month")
+	flag.StringVar(&flags.llmEssential, "llmessential", "labels,rules", "comma-separated list of features that -llmenforcebudget never pauses")
+	flag.StringVar(&flags.moderateDeny, "moderatedeny", "", "file of regular expressions, one per line, any match of which in LLM output blocks it from being posted (for example, signs of prompt injection)")
+	flag.BoolVar(&flags.moderatePolicy, "moderatepolicy", false, "check all LLM output with the moderation API and block output that violates any safety policy")
+	flag.BoolVar(&flags.moderateMentions, "moderatementions", true, "strip GitHub @-mentions from LLM output, so that posts never notify users the LLM names")
+	flag.StringVar(&flags.moderateLinks, "moderatelinks", "go.dev,golang.org,github.com,googlesource.com", "comma-separated list of hosts (and their subdomains) to which LLM output may link; other links are removed (empty allows all links)")
+	flag.IntVar(&flags.compressDB, "compressdb", 0, "if set, compress (with zstd) stored DB values of at least this many bytes, such as 4096; values already stored are read unchanged")
+	flag.StringVar(&flags.searchQueries, "searchqueries", "", "file of benchmark search queries, one per line; after each change to the vector index, their top results are compared with those of the previous run")
+	flag.Float64Var(&flags.searchChurn, "searchchurn", searchdiff.DefaultThreshold, "mean fraction of the previous top results of the -searchqueries that, if no longer found, raises an alert")
+	flag.Int64Var(&flags.vectorMem, "vectormem", 0, "memory limit in MiB for the in-memory vector DB; vectors beyond it are read from the DB (0 means no limit)")
+	flag.StringVar(&flags.chaos, "chaos", "", "in binaries built with -tags chaos (for staging), comma-separated list of TARGET=RATE pairs, such as storage=0.01,github=0.05,llm=0.1, making that fraction of storage writes, GitHub edits or LLM calls fail at random; see internal/chaos")
+	flag.StringVar(&flags.backup, "backup", "", "if set, write a backup of the DB, including the vectors of the active embedding model, to this file and exit; see also /backup")
+	flag.StringVar(&flags.restore, "restore", "", "if set, restore the backup in this file (written by -backup or /backup) into the DB, which must be empty, and exit")
+	flag.DurationVar(&flags.gcActionLog, "gcactionlog", 0, "if set, /gc?delete=true deletes the action log entries of actions completed longer ago than this, such as 4320h, keeping only a small record of each action's result so that bots never repeat it")
+	flag.DurationVar(&flags.gcOverview, "gcoverview", 0, "if set, /gc?delete=true deletes the overview history entries older than this, such as 720h, that a newer overview of the same kind has superseded")
+	flag.BoolVar(&flags.gcVectors, "gcvectors", false, "if set, /gc?delete=true deletes the vectors of documents no longer in the corpus")
+	flag.StringVar(&flags.gcArchive, "gcarchive", "", "if set, directory in which /gc?delete=true writes the data it deletes, in the format of -backup")
+	flag.StringVar(&flags.pluginDir, "plugins", "", "directory of NAME.yaml plugin manifests, defining custom issue filters and comment post-processors that -pipelines steps can use; see internal/plugins")
+	flag.StringVar(&flags.pluginSandbox, "pluginsandbox", "", "if set, space-separated command prefix that runs each exec plugin in a sandbox, such as 'runsc do' or 'bwrap --ro-bind / / --unshare-all'")
+	flag.StringVar(&flags.policyFile, "policies", "", "YAML file of per-project posting policies, setting the allowed actions, quiet hours, hourly budget and skipped users of each project; see internal/policy.ParseConfig. Fields it does not set take the values of the default policy (see defaultPolicy): at most 60 actions an hour, and no actions on issues opened by gopherbot")
+	flag.StringVar(&flags.pipelineDir, "pipelines", "", "directory of NAME.yaml bot pipeline definitions, each assembling a bot from stages such as label filters, related-document search, LLM summaries and comments; see internal/pipeline")
+	flag.IntVar(&flags.vectorANN, "vectorann", 0, "if set, search vectors with an approximate nearest-neighbor (HNSW) index, examining this many candidates per search, such as 100: larger values find more of the true nearest neighbors but are slower (see internal/ann); the index keeps a copy of every vector in memory, so it cannot be used with -vectormem")
 }
 
 // Gaby holds the state for gaby's execution.
 type Gaby struct {
 	ctx            context.Context
 	cloud          bool              // running on Cloud Run
+	snapshot       bool              // serving a read-only snapshot (see -snapshot)
 	meta           map[string]string // any metadata we want to expose
 	addr           string            // address to serve HTTP on
 	githubProjects []string          // github projects to monitor and update
 	gerritProjects []string          // gerrit projects to monitor and update
 	googleGroups   []string          // google groups to monitor and update
 
-	slog      *slog.Logger           // slog output to use
-	slogLevel *slog.LevelVar         // slog level, for changing as needed
-	http      *http.Client           // http client to use
-	db        storage.DB             // database to use
-	vector    storage.VectorDB       // vector database to use
-	secret    secret.DB              // secret database to use
-	docs      *docs.Corpus           // document corpus to use
-	embed     llm.Embedder           // LLM embedder to use
-	llm       llm.ContentGenerator   // LLM content generator to use
-	policy    llm.PolicyChecker      // LLM checker to use
-	llmapp    *llmapp.Client         // LLM client to use
-	github    *github.Client         // github client to use
-	disc      *discussion.Client     // github discussion client to use
-	gerrit    *gerrit.Client         // gerrit client to use
-	ggroups   *googlegroups.Client   // google groups client to use
-	crawler   *crawl.Crawler         // web crawler to use
-	bisect    *bisect.Client         // bisect client to use
-	meter     ometric.Meter          // used to create Open Telemetry instruments
-	report    *errorreporting.Client // used to report important gaby errors to Cloud Error Reporting service
-
-	relatedPoster *related.Poster   // used to post related issues
-	rulesPoster   *rules.Poster     // used to post rule violations
-	commentFixer  *commentfix.Fixer // used to fix GitHub comments
-	overview      *overview.Client  // used to generate and post overviews
-	labeler       *labels.Labeler   // used to assign labels to issues
+	// openVec opens the vector database of a namespace
+	// for g.index; set by initGCP.
+	openVec func(namespace string) (storage.VectorDB, error)
+
+	slog      *slog.Logger              // slog output to use
+	slogLevel *slog.LevelVar            // slog level, for changing as needed
+	http      *http.Client              // http client to use
+	db        storage.DB                // database to use
+	vector    storage.VectorDB          // vector database to use
+	index     *embeddocs.Index          // vector index of docs; also g.vector and behind g.embed
+	vectorMem vectorMemory              // memory use of the index's vector DBs
+	secret    secret.DB                 // secret database to use
+	docs      *docs.Corpus              // document corpus to use
+	denylist  *search.Denylist          // documents suppressed from search and related posts
+	pins      *search.Pins              // documents pinned to search topics
+	tokens    *apitoken.Store           // tokens for the /api/gh/ and /api/hover endpoints
+	hovers    hoverCache                // cached /api/hover responses
+	embed     llm.Embedder              // LLM embedder to use
+	llm       llm.ContentGenerator      // LLM content generator to use
+	batchLLM  llm.BatchContentGenerator // if non-nil, LLM content generator to use for batches
+	policy    llm.PolicyChecker         // LLM checker to use
+	llmapp    *llmapp.Client            // LLM client to use
+	cost      *llmcost.Meter            // used to account for and limit the cost of LLM use
+	moderator *moderate.Filter          // used to check LLM output before it is used
+	github    *github.Client            // github client to use
+	disc      *discussion.Client        // github discussion client to use
+	gerrit    *gerrit.Client            // gerrit client to use
+	ggroups   *googlegroups.Client      // google groups client to use
+	crawler   *crawl.Crawler            // web crawler to use
+	bisect    *bisect.Client            // bisect client to use
+	meter     ometric.Meter             // used to create Open Telemetry instruments
+	report    *errorreporting.Client    // used to report important gaby errors to Cloud Error Reporting service
+	breakers  []*circuit.Breaker        // circuit breakers around external dependencies
+	chaos     *chaos.Injector           // injects failures into dependencies; nil unless -chaos
+	leader    *leader.Elector           // if non-nil, elects the instance that runs scheduled jobs
+
+	composer        *compose.Composer      // if non-nil, used to combine bot comments on an issue
+	relatedPoster   *related.Poster        // used to post related issues
+	duplicatePoster *duplicate.Poster      // used to post likely duplicate issues
+	rulesPoster     *rules.Poster          // used to post rule violations
+	commentFixer    *commentfix.Fixer      // used to fix GitHub comments
+	issueFixer      *commentfix.Fixer      // used to fix formatting of new GitHub issues
+	overview        *overview.Client       // used to generate and post overviews
+	checklist       *checklist.Checklister // used to generate and post review checklists
+	workload        *workload.Analyzer     // used to report maintainer workload
+	dupMiner        *dupmine.Miner         // used to mine duplicate issues for evaluation and training
+	weekly          *weekly.Reporter       // used to report weekly activity
+	mute            *mute.Set              // issues that opted out of bot comments
+	repoConfig      *repoconfig.Config     // per-project settings (see -repoconfig)
+	commitChecker   *commitmsg.Checker     // used to check commit messages
+	labeler         *labels.Labeler        // used to assign labels to issues
+	pipelines       []*pipeline.Pipeline   // bots assembled from pipeline definitions (see -pipelines)
+	feedback        *feedback.Collector    // used to collect emoji votes on posted comments
+	approver        *approval.Approver     // used to approve actions from GitHub
+	corpusChecker   *corpuscheck.Checker   // used to check issues, docs and vectors agree
+	searchDiff      *searchdiff.Checker    // used to diff benchmark search results; nil if disabled
+	gc              *gc.Collector          // used to delete data that is no longer needed
+	approvalProject string                 // private GitHub project of the approval issue
 }
 
 func main() {
@@ -141,21 +330,165 @@ func main() {
 		googleGroups:   []string{"golang-nuts"},
 	}
 
+	if flags.snapshot != "" {
+		// Serve the snapshot without connecting to GCP, GitHub or the LLM.
+		if err := g.initSnapshot(); err != nil {
+			log.Fatal(err)
+		}
+		g.serveHTTP()
+		log.Printf("serving snapshot %s on %s", flags.snapshot, g.addr)
+		select {}
+	}
+
+	if flags.dryRun {
+		// In dry-run mode, run everything as usual,
+		// but divert GitHub edits (see below).
+		flags.enablechanges = true
+	}
+
 	autoApprovePkgs, err := parseApprovalPkgs(flags.autoApprove)
 	if err != nil {
 		log.Fatal(err)
 	}
+	plainTextProjects, err := g.parseProjects("plaintext", flags.plainText)
+	if err != nil {
+		log.Fatal(err)
+	}
+	graphQLProjects, err := g.parseProjects("graphql", flags.graphQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	relatedMinScores, err := parseMinScores(flags.relatedScores)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ackDays, err := g.parseAcknowledge(flags.acknowledge)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if flags.relatedCalib < 0 || flags.relatedCalib >= 1 {
+		log.Fatalf("invalid -relatedcalibrate %v: want a percentile between 0 and 1", flags.relatedCalib)
+	}
+	if flags.vectorMem < 0 {
+		log.Fatalf("invalid -vectormem %d: want a non-negative number of MiB", flags.vectorMem)
+	}
+	if flags.vectorANN > 0 && flags.vectorMem > 0 {
+		// The index holds a copy of every vector in memory.
+		log.Fatal("-vectorann cannot be used with -vectormem")
+	}
+	if flags.chaos != "" {
+		g.chaos, err = chaos.Parse(g.slog, flags.chaos, uint64(time.Now().UnixNano()))
+		if err != nil {
+			log.Fatalf("-chaos: %v", err)
+		}
+		g.slog.Warn("gaby: injecting failures", "chaos", flags.chaos)
+	}
 
-	shutdown := g.initGCP() // sets up g.db, g.vector, g.secret, ...
+	shutdown := g.initGCP() // sets up g.db, g.openVec, g.secret, ...
 	defer shutdown()
 
-	g.github = github.New(g.slog, g.db, g.secret, g.http)
+	if flags.restore != "" {
+		// Restore before anything writes to the DB.
+		if err := g.restoreFile(flags.restore); err != nil {
+			shutdown()
+			log.Fatalf("-restore: %v", err)
+		}
+		return
+	}
+
+	// Guard external dependencies with circuit breakers,
+	// so that retries during an outage fail fast.
+	githubBreaker := circuit.New(g.slog, "github")
+	vectorBreaker := circuit.New(g.slog, "vector")
+	g.breakers = []*circuit.Breaker{githubBreaker, vectorBreaker} // LLM breakers added by initLLM
+
+	// The vectors live in the namespace of the index's active
+	// embedding model (see embeddocs.Index and devtools/cmd/embedmigrate).
+	g.index = embeddocs.NewIndex(g.slog, g.db, vectorDBNamespace, embeddingModel(flags.embedder), g.openIndexVec(vectorBreaker))
+	// Split long documents, which exceed the embedder's input limit.
+	g.index.SetChunker(embeddocs.NewChunker(embeddocs.DefaultChunkTokens, embeddocs.DefaultChunkOverlap))
+	ep := embeddocs.DefaultPipeline
+	ep.Workers = flags.embedWorkers
+	g.index.SetPipeline(ep)
+	g.vector = g.index
+
+	if flags.backup != "" {
+		if err := g.backupFile(flags.backup); err != nil {
+			shutdown()
+			log.Fatalf("-backup: %v", err)
+		}
+		return
+	}
+
+	g.github = github.New(g.slog, g.db, g.secret, githubBreaker.Client(g.chaos.Client(chaos.GitHub, g.http)))
+	if flags.dryRun {
+		g.github.EnableDryRun()
+	}
 	for _, project := range g.githubProjects {
 		if err := g.github.Add(project); err != nil {
 			log.Fatalf("github.Add failed: %v", err)
 		}
+		if err := g.github.EnableReviewComments(project); err != nil {
+			log.Fatalf("github.EnableReviewComments failed: %v", err)
+		}
+		if slices.Contains(graphQLProjects, project) {
+			if err := g.github.EnableGraphQL(project); err != nil {
+				log.Fatalf("github.EnableGraphQL failed: %v", err)
+			}
+		} else if err := g.github.DisableGraphQL(project); err != nil {
+			log.Fatalf("github.DisableGraphQL failed: %v", err)
+		}
+		if err := g.github.EnableMilestones(project); err != nil {
+			log.Fatalf("github.EnableMilestones failed: %v", err)
+		}
+		if flags.repoConfig {
+			if err := g.github.EnableRepoConfig(project); err != nil {
+				log.Fatalf("github.EnableRepoConfig failed: %v", err)
+			}
+		}
+	}
+	if err := g.initApprover(); err != nil {
+		log.Fatal(err)
+	}
+	// Posting policies apply to every poster below.
+	policies, err := g.loadPolicies(flags.policyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g.github.SetPolicies(policies)
+	// Hiding one of gaby's comments on an issue means "stop posting here".
+	g.github.StopOnMinimized("gabyhelp")
+	// So does a label or command (see -mutelabel and -mutecommand),
+	// for the overview and related posters.
+	g.mute = mute.New(g.slog, g.db, g.github)
+	for _, project := range g.githubProjects {
+		g.mute.EnableProject(project)
+	}
+	g.mute.SetLabel(flags.muteLabel)
+	g.mute.SetCommand(flags.muteCommand)
+	g.mute.SetUnmuteCommand(flags.unmuteCommand)
+	// Projects can tune the overview and related posters further
+	// (see -repoconfig, -optoutlabels and -language).
+	var optOutLabels []string
+	for _, l := range strings.Split(flags.optOutLabels, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			optOutLabels = append(optOutLabels, l) // labels can contain spaces
+		}
+	}
+	g.repoConfig = repoconfig.New(g.slog, g.github)
+	for _, project := range g.githubProjects {
+		err := g.repoConfig.SetOperator(project, &repoconfig.Settings{
+			OptOutLabels: optOutLabels,
+			Language:     flags.language,
+		})
+		if err != nil {
+			log.Fatalf("-language: %v", err)
+		}
 	}
 	g.disc = discussion.New(g.ctx, g.slog, g.secret, g.db)
+	if flags.dryRun {
+		g.disc.EnableDryRun()
+	}
 	for _, project := range g.githubProjects {
 		if err := g.disc.Add(project); err != nil {
 			log.Fatalf("discussion.Add failed: %v", err)
@@ -163,6 +496,9 @@ func main() {
 	}
 
 	g.gerrit = gerrit.New("go-review.googlesource.com", g.slog, g.db, g.secret, g.http)
+	if flags.dryRun {
+		g.gerrit.EnableDryRun()
+	}
 	for _, project := range g.gerritProjects {
 		if err := g.gerrit.Add(project); err != nil {
 			log.Fatalf("gerrit.Add failed: %v", err)
@@ -177,18 +513,48 @@ func main() {
 	}
 
 	g.docs = docs.New(g.slog, g.db)
+	g.denylist = search.NewDenylist(g.slog, g.db)
+	g.pins = search.NewPins(g.slog, g.db)
+	g.tokens = apitoken.NewStore(g.slog, g.db)
 
-	ai, err := gemini.NewClient(g.ctx, g.slog, g.secret, g.http, gemini.DefaultEmbeddingModel, gemini.DefaultGenerativeModel)
+	ai, embed, err := g.initLLM()
 	if err != nil {
 		log.Fatal(err)
 	}
-	g.embed = ai
-	g.llm = ai
-	g.llmapp = llmapp.NewWithChecker(g.slog, ai, g.policy, g.db)
-	ov := overview.New(g.slog, g.db, g.github, g.llmapp, "overview", "gabyhelp")
+	g.embed = llmAvailability.Embedder(embed)
+	g.llm = llmAvailability.ContentGenerator(ai)
+	if err := g.initCost(); err != nil {
+		log.Fatal(err)
+	}
+	if err := g.initModerator(); err != nil {
+		log.Fatal(err)
+	}
+	g.llmapp = llmapp.NewWithChecker(g.slog, g.llm, g.policy, g.db)
+	g.llmapp.SetModerator(g.moderator)
+	if g.batchLLM != nil {
+		g.llmapp.SetBatchGenerator(g.batchLLM)
+	}
+	g.llmapp.SetContextWindow(flags.contextWindow)
+	g.llmapp.SetCacheTTL(flags.llmCacheTTL)
+	// Prompts tuned for this deployment replace the built-in ones.
+	if err := g.llmapp.LoadPrompts(); err != nil {
+		log.Fatal(err)
+	}
+	if flags.promptDir != "" {
+		if err := g.llmapp.LoadPromptDir(flags.promptDir); err != nil {
+			log.Fatal(err)
+		}
+	}
+	ov := overview.New(g.slog, g.db, g.github, g.featureLLMApp("overview"), "overview", "gabyhelp")
+	// Overview comments lead with a TL;DR for triage; the collapsed
+	// details can be longer, but should stay readable.
+	ov.SetPostLength(llmapp.Medium)
 	for _, proj := range g.githubProjects {
 		ov.EnableProject(proj)
 	}
+	for _, proj := range plainTextProjects {
+		ov.EnablePlainText(proj)
+	}
 	if !slices.Contains(autoApprovePkgs, "overview") {
 		ov.RequireApproval()
 	} else {
@@ -197,15 +563,97 @@ func main() {
 
 	ov.SkipIssueAuthor("gopherbot")
 	ov.SkipCommentsBy("gopherbot")
+	ov.EnablePullRequests(flags.overviewPRs)
+	ov.EnableDigests(time.Duration(flags.digestDays)*24*time.Hour, flags.digestMin)
+	ov.SkipMuted(g.mute)
+	ov.SetRepoConfig(g.repoConfig)
 	g.overview = ov
 
+	cl := checklist.New(g.slog, g.db, g.github, g.featureLLMApp("checklist"), "checklist")
+	if slices.Contains(autoApprovePkgs, "checklist") {
+		cl.AutoApprove()
+	}
+	for _, proj := range plainTextProjects {
+		cl.EnablePlainText(proj)
+	}
+	g.checklist = cl
+
+	cm := commitmsg.New(g.slog, g.db, g.github, g.gerrit, "gaby")
+	for _, proj := range g.githubProjects {
+		cm.EnableProject(proj)
+	}
+	for _, proj := range g.gerritProjects {
+		// Go's Gerrit projects are mirrored to GitHub, where the
+		// issues for all of them are tracked in golang/go.
+		repo := "golang/" + proj
+		cm.EnableGerritProject(proj, repo)
+		cm.SetIssueRepo(repo, "golang/go")
+	}
+	if slices.Contains(autoApprovePkgs, "commitmsg") {
+		cm.AutoApprove()
+	}
+	g.commitChecker = cm
+
+	wl := workload.New(g.slog, g.db)
+	for _, proj := range g.githubProjects {
+		wl.EnableProject(proj)
+	}
+	wl.SetAreas("golang/go", "compiler/runtime", "gopls", "Tools", "Documentation", "Security")
+	g.workload = wl
+
+	dm := dupmine.New(g.slog, g.db, g.github, g.featureLLM("dupmine"))
+	for _, proj := range g.githubProjects {
+		dm.EnableProject(proj)
+	}
+	g.dupMiner = dm
+
+	wr := weekly.New(g.slog, g.db, g.github, g.featureLLMApp("weekly"), g.disc)
+	for _, proj := range g.githubProjects {
+		wr.EnableProject(proj)
+	}
+	if flags.weeklyDisc != "" {
+		project, num, err := parseWeeklyDiscussion(flags.weeklyDisc)
+		if err != nil {
+			log.Fatal(err)
+		}
+		wr.PublishTo(project, num)
+	}
+	if slices.Contains(autoApprovePkgs, "weekly") {
+		wr.AutoApprove()
+	}
+	g.weekly = wr
+
 	cr := crawl.New(g.slog, g.db, g.http)
 	cr.Add("https://go.dev/")
+	cr.Add("https://pkg.go.dev/std")
 	cr.Allow(godevAllow...)
 	cr.Deny(godevDeny...)
+	cr.Allow(pkgsiteAllow...)
+	cr.Deny(pkgsiteDeny...)
 	cr.Clean(godevClean)
+	cr.Clean(pkgsiteClean)
 	g.crawler = cr
 
+	cc := corpuscheck.New(g.slog, g.db, g.github, g.docs, g.vector, g.embed)
+	for _, proj := range g.githubProjects {
+		cc.EnableProject(proj)
+	}
+	if flags.enablesync {
+		cc.EnableRepair()
+	}
+	g.corpusChecker = cc
+
+	if flags.searchQueries != "" {
+		queries, err := readSearchQueries(flags.searchQueries)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sd := searchdiff.New(g.slog, g.db, g.vector, g.docs, g.embed)
+		sd.SetQueries(queries)
+		sd.SetThreshold(flags.searchChurn)
+		g.searchDiff = sd
+	}
+
 	// Set up bisection if we are on Cloud Run.
 	if g.cloud {
 		q, err := taskQueue(g)
@@ -216,6 +664,14 @@ func main() {
 		g.bisect = bs
 	}
 
+	if flags.selfTest {
+		if !g.selfTest(g.ctx, os.Stdout) {
+			shutdown()
+			os.Exit(1)
+		}
+		return
+	}
+
 	if flags.search {
 		g.searchLoop()
 		return
@@ -233,32 +689,141 @@ func main() {
 	}
 	g.commentFixer = cf
 
+	ifx := commentfix.New(g.slog, g.github, g.db, "issuebodies")
+	for _, proj := range g.githubProjects {
+		ifx.EnableProject(proj)
+	}
+	ifx.IssuesOnly()
+	ifx.FenceCode()
+	ifx.RemoveEmptySections()
+	ifx.AutoLink(`\b[Ii]ssue ([0-9]+)\b`, "https://go.dev/issue/$1")
+	ifx.EnableEdits()
+	if !slices.Contains(autoApprovePkgs, "commentfix") {
+		ifx.RequireApproval()
+	}
+	g.issueFixer = ifx
+
+	actions.SetCoalesceDelay(flags.coalesce)
+
+	if flags.combineWindow > 0 {
+		g.composer = compose.New(g.slog, g.db, g.github, "gabyhelp")
+		g.composer.SetWindow(flags.combineWindow)
+		g.composer.SetOrder("rules", "duplicate", "related", "labels")
+	}
+
 	rp := related.New(g.slog, g.db, g.github, g.vector, g.docs, "related")
+	rp.SetDenylist(g.denylist)
 	for _, proj := range g.githubProjects {
 		rp.EnableProject(proj)
 	}
+	for _, proj := range plainTextProjects {
+		rp.EnablePlainText(proj)
+	}
 	// TODO(hyangah): shouldn't these rules be configured differently for different github projects?
 	rp.SkipBodyContains("— [watchflakes](https://go.dev/wiki/Watchflakes)")
 	rp.SkipTitlePrefix("x/tools/gopls: release version v")
 	rp.SkipTitleSuffix(" backport]")
 	rp.SkipTitlePrefix("security: fix CVE-") // CVE issues are boilerplate
+	rp.SkipMuted(g.mute)
+	rp.SetRepoConfig(g.repoConfig)
+	for kind, min := range relatedMinScores {
+		rp.SetKindMinScore(kind, min)
+	}
+	if flags.relatedWhy {
+		rp.EnableExplanations(g.featureLLMApp("related"))
+	}
+	for proj, days := range ackDays {
+		rp.EnableAcknowledgement(proj, days)
+	}
+	if flags.relatedCalib > 0 {
+		rp.EnableCalibration(flags.relatedCalib)
+	}
+	if flags.relatedPulls {
+		rp.EnablePullRequests()
+		switch flags.relatedPRMode {
+		case "comment":
+			rp.SetPullMode(related.PullComment)
+		case "review":
+			rp.SetPullMode(related.PullReview)
+		case "checkrun":
+			rp.SetPullMode(related.PullCheckRun)
+		default:
+			log.Fatalf("invalid -relatedprmode %q: want comment, review or checkrun", flags.relatedPRMode)
+		}
+	}
+	if flags.relatedDisc {
+		rp.EnableDiscussions(g.disc)
+	}
 	rp.EnablePosts()
+	rp.DeferWhenUnavailable(llmAvailability)
 	if !slices.Contains(autoApprovePkgs, "related") {
 		rp.RequireApproval()
 	}
+	if g.composer != nil {
+		rp.SetComposer(g.composer)
+	}
 	g.relatedPoster = rp
 
-	rulep := rules.New(g.slog, g.db, g.github, g.llm, "rules")
+	dp := duplicate.New(g.slog, g.db, g.github, g.vector, g.docs, g.featureLLMApp("duplicate"), "duplicate")
+	for _, proj := range g.githubProjects {
+		dp.EnableProject(proj)
+	}
+	for _, proj := range plainTextProjects {
+		dp.EnablePlainText(proj)
+	}
+	if flags.dupFeedback != "" {
+		for _, proj := range g.githubProjects {
+			dp.SetFeedbackURL(proj, flags.dupFeedback)
+		}
+	}
+	dp.SkipBodyContains("— [watchflakes](https://go.dev/wiki/Watchflakes)")
+	dp.SkipTitlePrefix("x/tools/gopls: release version v")
+	dp.SkipTitlePrefix("security: fix CVE-")
+	dp.SetLabel("duplicate")
+	dp.EnablePosts()
+	dp.DeferWhenUnavailable(llmAvailability)
+	if !slices.Contains(autoApprovePkgs, "duplicate") {
+		dp.RequireApproval()
+	}
+	if g.composer != nil {
+		dp.SetComposer(g.composer)
+	}
+	g.duplicatePoster = dp
+
+	rulep := rules.New(g.slog, g.db, g.github, g.featureLLM("rules"), "rules")
 	for _, proj := range g.githubProjects {
 		rulep.EnableProject(proj)
 	}
+	for _, proj := range plainTextProjects {
+		rulep.EnablePlainText(proj)
+	}
 	rulep.EnablePosts()
 	if !slices.Contains(autoApprovePkgs, "rules") {
 		rulep.RequireApproval()
 	}
+	if g.composer != nil {
+		rulep.SetComposer(g.composer)
+	}
 	g.rulesPoster = rulep
 
-	labeler := labels.New(g.slog, g.db, g.github, ai, "gabyhelp")
+	fc := feedback.New(g.slog, g.db, g.github, "gabyhelp", "feedback")
+	for _, proj := range g.githubProjects {
+		fc.EnableProject(proj)
+	}
+	// Overview comments are identified by their hidden tags,
+	// except in plain-text mode.
+	fc.AddKind("duplicate", "This issue is possibly a duplicate of")
+	fc.AddKind("rules", "We've identified some possible problems with your issue")
+	fc.AddKind("overview", "TL;DR: ")
+	fc.AddKind("related", "Related ")
+	g.feedback = fc
+	if flags.relatedRerank != "" {
+		// The reranker learns from the votes on related comments.
+		x := learnrank.NewExtractor(g.db, fc)
+		rp.SetReranker(learnrank.NewRanker(g.slog, g.db, x, flags.relatedRerank))
+	}
+
+	labeler := labels.New(g.slog, g.db, g.github, g.featureLLM("labels"), "gabyhelp")
 	for _, proj := range g.githubProjects {
 		// TODO: support other projects.
 		if proj != "golang/go" {
@@ -268,11 +833,29 @@ func main() {
 	}
 	labeler.SkipAuthor("gopherbot")
 	labeler.EnableLabels()
+	labeler.EnableSuggestions()
 	if !slices.Contains(autoApprovePkgs, "labels") {
 		labeler.RequireApproval()
 	}
+	if g.composer != nil {
+		labeler.SetComposer(g.composer)
+	}
 	g.labeler = labeler
 
+	if flags.pipelineDir != "" {
+		ps, err := g.loadPipelines(flags.pipelineDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, p := range ps {
+			p.EnablePosts()
+			if !slices.Contains(autoApprovePkgs, "pipeline") {
+				p.RequireApproval()
+			}
+		}
+		g.pipelines = ps
+	}
+
 	// Named functions to retrieve latest Watcher times.
 	watcherLatests := map[string]func() timed.DBTime{
 		github.DocWatcherID:       docs.LatestFunc(g.github),
@@ -281,17 +864,38 @@ func main() {
 		crawl.DocWatcherID:        docs.LatestFunc(cr),
 		googlegroups.DocWatcherID: docs.LatestFunc(g.ggroups),
 
-		"embeddocs": func() timed.DBTime { return embeddocs.Latest(g.docs) },
+		"embeddocs": func() timed.DBTime { return g.index.Latest(g.docs) },
 
 		"gerritlinks fix": cf.Latest,
+		"issuebodies fix": ifx.Latest,
 		"related":         rp.Latest,
+		"duplicate":       dp.Latest,
 		"rules":           rulep.Latest,
 		"labeler":         labeler.Latest,
 		"overview":        ov.Latest,
 	}
+	for _, p := range g.pipelines {
+		watcherLatests["pipeline "+p.Name()] = p.Latest
+	}
+
+	g.gc = g.newGC()
 
 	// Install a metric that observes the latest values of the watchers each time metrics are sampled.
 	g.registerWatcherMetric(watcherLatests)
+	g.registerBreakerMetrics(g.breakers)
+	g.registerEmbedMetrics(g.index)
+	g.registerMemoryMetrics("vector", g.vectorMemoryUsage)
+	if cr, ok := g.db.(storage.CompressionReporter); ok {
+		g.registerCompressionMetrics(cr)
+	}
+	if g.searchDiff != nil {
+		g.registerSearchDiffMetric()
+	}
+	g.registerGCMetrics(g.gc)
+
+	if flags.elect {
+		g.initLeader()
+	}
 
 	g.serveHTTP()
 	log.Printf("serving %s", g.addr)
@@ -303,7 +907,7 @@ func main() {
 	select {}
 }
 
-var validApprovalPkgs = []string{"commentfix", "related", "rules", "labels", "overview"}
+var validApprovalPkgs = []string{"commentfix", "related", "duplicate", "rules", "labels", "overview", "checklist", "commitmsg", "weekly", "pipeline"}
 
 // parseApprovalPkgs parses a comma-separated list of package names,
 // checking that the packages are valid.
@@ -321,19 +925,140 @@ func parseApprovalPkgs(s string) ([]string, error) {
 	return pkgs, nil
 }
 
-// initLocal initializes a local Gaby instance.
-// No longer used, but here for experimentation.
-func (g *Gaby) initLocal() {
-	g.slog.Info("gaby local init", "flags", flags)
+// parseProjects parses the argument to the named flag (such as -plaintext),
+// a comma-separated list of GitHub projects monitored by g.
+func (g *Gaby) parseProjects(name, s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	projects := strings.Split(s, ",")
+	for _, p := range projects {
+		if !slices.Contains(g.githubProjects, p) {
+			return nil, fmt.Errorf("invalid arg %q to -%s: valid values are: %s",
+				p, name, strings.Join(g.githubProjects, ", "))
+		}
+	}
+	return projects, nil
+}
+
+// parseAcknowledge parses the argument to the -acknowledge flag,
+// a comma-separated list of PROJECT=DAYS pairs, where PROJECT is a
+// GitHub project monitored by g and DAYS is the usual triage time.
+func (g *Gaby) parseAcknowledge(s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	m := make(map[string]int)
+	for _, kv := range strings.Split(s, ",") {
+		proj, days, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid arg %q to -acknowledge: want PROJECT=DAYS", kv)
+		}
+		if !slices.Contains(g.githubProjects, proj) {
+			return nil, fmt.Errorf("invalid arg %q to -acknowledge: valid projects are: %s",
+				kv, strings.Join(g.githubProjects, ", "))
+		}
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid arg %q to -acknowledge: days must be a positive integer", kv)
+		}
+		m[proj] = n
+	}
+	return m, nil
+}
+
+// parseMinScores parses the argument to the -relatedminscores flag,
+// a comma-separated list of KIND=SCORE pairs, where KIND is a
+// document kind recognized by package search.
+func parseMinScores(s string) (map[string]float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	m := make(map[string]float64)
+	for _, kv := range strings.Split(s, ",") {
+		kind, score, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid arg %q to -relatedminscores: want KIND=SCORE", kv)
+		}
+		if err := (&search.Options{AllowKind: []string{kind}}).Validate(); err != nil {
+			return nil, fmt.Errorf("invalid arg %q to -relatedminscores: %v", kv, err)
+		}
+		f, err := strconv.ParseFloat(score, 64)
+		if err != nil || f < 0 || f > 1 {
+			return nil, fmt.Errorf("invalid arg %q to -relatedminscores: score must be between 0 and 1", kv)
+		}
+		m[kind] = f
+	}
+	return m, nil
+}
+
+// compressDB returns db, wrapped to compress large values
+// if the -compressdb flag is set.
+func compressDB(db storage.DB) storage.DB {
+	if flags.compressDB <= 0 {
+		return db
+	}
+	return storage.NewCompressedDB(db, flags.compressDB)
+}
 
-	g.secret = secret.Netrc()
+// vectorDBNamespace is the name of Gaby's vector index,
+// and the namespace of its original embeddings (see [embeddocs.Index]).
+const vectorDBNamespace = "gaby"
 
-	db, err := pebble.Open(g.slog, "gaby.db")
+// openDB opens the database described by the -db spec,
+// setting g.db and g.openVec.
+// Postgres and SQLite databases hold their own vectors;
+// other databases keep them in memory.
+func (g *Gaby) openDB(dbspecStr string) error {
+	spec, err := dbspec.Parse(dbspecStr)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if spec.IsVector {
+		return errors.New("omit vector DB spec for -db")
+	}
+	db, err := spec.Open(g.ctx, g.slog)
+	if err != nil {
+		return err
+	}
+	g.db = g.chaos.DB(compressDB(db))
+	g.openVec = func(namespace string) (storage.VectorDB, error) {
+		return storage.MemVectorDBWithLimit(g.db, g.slog, namespace, flags.vectorMem<<20), nil
+	}
+	if pdb, ok := db.(*postgres.DB); ok {
+		// Search the vectors in Postgres rather than in memory.
+		g.openVec = func(namespace string) (storage.VectorDB, error) {
+			return postgres.NewVectorDB(pdb, namespace), nil
+		}
+	}
+	if sdb, ok := db.(*sqlite.DB); ok {
+		// Keep the vectors in the same file as everything else.
+		g.openVec = func(namespace string) (storage.VectorDB, error) {
+			return sqlite.NewVectorDB(sdb, namespace), nil
+		}
+	}
+	return nil
+}
+
+// openIndexVec returns the function that g.index uses to open the
+// vector DB of a namespace: g.openVec, behind an ANN index if -vectorann
+// is set and behind the circuit breaker b. It records the DBs that
+// report their memory use (including that of the ANN index)
+// in g.vectorMem, as the circuit breaker hides them.
+func (g *Gaby) openIndexVec(b *circuit.Breaker) func(namespace string) (storage.VectorDB, error) {
+	return func(namespace string) (storage.VectorDB, error) {
+		vdb, err := g.openVec(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if flags.vectorANN > 0 {
+			a := ann.New(g.slog, vdb, ann.Config{EfSearch: flags.vectorANN})
+			go a.Load()
+			vdb = a
+		}
+		g.vectorMem.add(namespace, vdb)
+		return b.VectorDB(vdb), nil
 	}
-	g.db = db
-	g.vector = storage.MemVectorDB(db, g.slog, "")
 }
 
 // initGCP initializes a Gaby instance to use GCP databases and other resources.
@@ -365,21 +1090,29 @@ func (g *Gaby) initGCP() (shutdown func()) {
 		"k_service", os.Getenv("K_SERVICE"),
 		"k_revision", os.Getenv("K_REVISION"))
 
-	if flags.firestoredb == "" {
-		log.Fatal("missing -firestoredb flag")
-	}
-	spec := &dbspec.Spec{
-		Kind:     "firestore",
-		Location: flags.project,
-		Name:     flags.firestoredb,
-	}
-	db, err := spec.Open(g.ctx, g.slog)
-	if err != nil {
-		log.Fatal(err)
+	if flags.localDB != "" {
+		if err := g.openDB(flags.localDB); err != nil {
+			log.Fatalf("-db: %v", err)
+		}
+	} else {
+		if flags.firestoredb == "" {
+			log.Fatal("missing -firestoredb or -db flag")
+		}
+		spec := &dbspec.Spec{
+			Kind:     "firestore",
+			Location: flags.project,
+			Name:     flags.firestoredb,
+		}
+		db, err := spec.Open(g.ctx, g.slog)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.db = g.chaos.DB(compressDB(db))
+		g.openVec = func(namespace string) (storage.VectorDB, error) {
+			return firestore.NewVectorDB(g.ctx, g.slog, spec.Location, spec.Name, namespace)
+		}
 	}
-	g.db = db
 
-	const vectorDBNamespace = "gaby"
 	if flags.overlay != "" {
 		spec, err := dbspec.Parse(flags.overlay)
 		if err != nil {
@@ -393,13 +1126,9 @@ func (g *Gaby) initGCP() (shutdown func()) {
 			log.Fatal(err)
 		}
 		g.db = storage.NewOverlayDB(odb, g.db)
-		g.vector = storage.MemVectorDB(g.db, g.slog, vectorDBNamespace)
-	} else {
-		vdb, err := firestore.NewVectorDB(g.ctx, g.slog, spec.Location, spec.Name, vectorDBNamespace)
-		if err != nil {
-			log.Fatal(err)
+		g.openVec = func(namespace string) (storage.VectorDB, error) {
+			return storage.MemVectorDBWithLimit(g.db, g.slog, namespace, flags.vectorMem<<20), nil
 		}
-		g.vector = vdb
 	}
 
 	sdb, err := gcpsecret.NewSecretDB(g.ctx, flags.project)
@@ -525,6 +1254,9 @@ func (g *Gaby) serveHTTP() {
 		}
 	}
 	mux := g.newServer(report)
+	if g.snapshot {
+		mux = g.newSnapshotServer()
+	}
 	// Listen in this goroutine so that we can return a synchronous error
 	// if the port is already in use or the address is otherwise invalid.
 	// Run the actual server in a background goroutine.
@@ -550,10 +1282,12 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 		setLevelEndpoint    = "setlevel"
 		githubEventEndpoint = "github-event"
 		crawlEndpoint       = "crawl"
+		corpusCheckEndpoint = "corpuscheck"
 		bisectEndpoint      = "bisect"
 	)
 	cronEndpointCounter := g.newEndpointCounter(cronEndpoint)
 	crawlEndpointCounter := g.newEndpointCounter(crawlEndpoint)
+	corpusCheckEndpointCounter := g.newEndpointCounter(corpusCheckEndpoint)
 	githubEventEndpointCounter := g.newEndpointCounter(githubEventEndpoint)
 
 	mux := http.NewServeMux()
@@ -562,6 +1296,10 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 		fmt.Fprintf(w, "meta: %+v\n", g.meta)
 		fmt.Fprintf(w, "flags: %+v\n", flags)
 		fmt.Fprintf(w, "log level: %v\n", g.slogLevel.Level())
+		if g.leader != nil {
+			l, _ := g.leader.Lease()
+			fmt.Fprintf(w, "leader: %s (term %d, leading: %v)\n", l.Holder, l.Term, g.leader.IsLeader())
+		}
 	})
 
 	// serve static files
@@ -581,12 +1319,22 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 
 	// cronEndpoint is called periodically by a Cloud Scheduler job.
 	mux.HandleFunc("GET /"+cronEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		standby, done := g.standby(w, cronEndpoint)
+		if standby {
+			return
+		}
+		defer done()
+
 		g.slog.Info(cronEndpoint + " start")
 		defer g.slog.Info(cronEndpoint + " end")
 
-		const cronLock = "gabycron"
-		g.db.Lock(cronLock)
-		defer g.db.Unlock(cronLock)
+		// Only the leader runs cron, so an elected leader does not
+		// need the lock, which a dead leader may still hold.
+		if g.leader == nil {
+			const cronLock = "gabycron"
+			g.db.Lock(cronLock)
+			defer g.db.Unlock(cronLock)
+		}
 
 		if errs := g.syncAndRunAll(g.ctx); len(errs) != 0 {
 			for _, err := range errs {
@@ -600,6 +1348,12 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 	// It is intended to be triggered by a Cloud Scheduler job (or similar)
 	// to run periodically.
 	mux.HandleFunc("GET /"+crawlEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		standby, done := g.standby(w, crawlEndpoint)
+		if standby {
+			return
+		}
+		defer done()
+
 		g.slog.Info(crawlEndpoint + " start")
 		defer g.slog.Info(crawlEndpoint + " end")
 
@@ -615,6 +1369,35 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 		crawlEndpointCounter.Add(r.Context(), 1)
 	})
 
+	// corpusCheckEndpoint cross-checks the GitHub issues, documents and
+	// vectors, repairing any drift if syncs are enabled.
+	// It is intended to be triggered periodically by a Cloud Scheduler job.
+	mux.HandleFunc("GET /"+corpusCheckEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		standby, done := g.standby(w, corpusCheckEndpoint)
+		if standby {
+			return
+		}
+		defer done()
+
+		g.slog.Info(corpusCheckEndpoint + " start")
+		defer g.slog.Info(corpusCheckEndpoint + " end")
+
+		rep, err := g.checkCorpus(r.Context())
+		if err != nil {
+			report(err, r)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !rep.OK() && !rep.Repaired {
+			report(fmt.Errorf("corpuscheck: %d missing docs, %d missing vectors, %d orphaned vectors",
+				len(rep.MissingDocs), len(rep.MissingVectors), len(rep.OrphanedVectors)), r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(storage.JSON(rep))
+
+		corpusCheckEndpointCounter.Add(r.Context(), 1)
+	})
+
 	// githubEventEndpoint is called by a GitHub webhook when a new
 	// event occurs on the githubProject repo.
 	mux.HandleFunc("POST /"+githubEventEndpoint, func(w http.ResponseWriter, r *http.Request) {
@@ -675,6 +1458,10 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 		g.db.Lock(runActionsLock)
 		defer g.db.Unlock(runActionsLock)
 
+		if err := g.checkLeader(); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		if flags.enablechanges || flags.testactions {
 			report := actions.RunWithReport(g.ctx, g.slog, g.db)
 			_, _ = w.Write(storage.JSON(report))
@@ -685,7 +1472,7 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 
 	// syncEndpoint is called manually to invoke a specific sync job.
 	// It performs a sync if enablesync is true.
-	// Usage: /sync?job={github | crawl | gerrit | discussion | groups}
+	// Usage: /sync?job={github | crawl | gerrit | discussion | groups | feedback}
 	mux.HandleFunc("GET /"+syncEndpoint, func(w http.ResponseWriter, r *http.Request) {
 		g.slog.Info(syncEndpoint + " start")
 		defer g.slog.Info(syncEndpoint + " end")
@@ -712,6 +1499,8 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 			err = g.syncGerrit(g.ctx)
 		case "groups":
 			err = g.syncGroups(g.ctx)
+		case "feedback":
+			err = g.syncFeedback(g.ctx)
 		default:
 			err = fmt.Errorf("unrecognized sync job %s", job)
 		}
@@ -743,6 +1532,10 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 	// /overview?q=...: generate an overview using the value of q as input.
 	mux.HandleFunc(get(overviewID), g.handleOverview)
 
+	// /overviewhistory: display a form for viewing the overview history of an issue.
+	// /overviewhistory?q=...: display the overviews generated for issue q, with diffs.
+	mux.HandleFunc(get(overviewHistoryID), g.handleOverviewHistory)
+
 	// /rules: display a form for entering an issue to check for rule violations.
 	// /rules?q=...: generate a list of violated rules for issue q.
 	mux.HandleFunc(get(rulesID), g.handleRules)
@@ -752,12 +1545,17 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 	mux.HandleFunc(get(labelsID), g.handleLabels)
 
 	// /api/search: perform a vector similarity search.
+	// Like all the /api/ endpoints, it reports failures as JSON (see apierror.go).
 	// POST because the arguments to the request are in the body.
 	mux.HandleFunc("POST /api/search", g.handleSearchAPI)
 
 	// /actionlog: display action log
 	mux.HandleFunc(get(actionlogID), g.handleActionLog)
 
+	// /api/actionlog: the action log entries matching the query
+	// parameters, as JSON (see actionlog.go)
+	mux.HandleFunc("GET /api/actionlog", g.handleActionLogAPI)
+
 	// /reviews: display review dashboard
 	mux.HandleFunc(get(reviewsID), g.handleReviewDashboard)
 
@@ -766,6 +1564,92 @@ func (g *Gaby) newServer(report func(error, *http.Request)) *http.ServeMux {
 
 	// /bisectlog: display bisection tasks
 	mux.HandleFunc(get(bisectlogID), g.handleBisectLog)
+
+	// /divertededits: display GitHub edits diverted in dry-run mode
+	mux.HandleFunc(get(divertedEditsID), g.handleDivertedEdits)
+
+	// /llmcache: display and invalidate the cache of LLM responses
+	mux.HandleFunc(get(llmCacheID), g.handleLLMCache)
+
+	// /llmcost: display the cost of LLM use against the budgets
+	mux.HandleFunc(get(llmCostID), g.handleLLMCost)
+
+	// /suppress: display, suppress and unsuppress documents
+	// that never appear in search results or related posts
+	mux.HandleFunc(get(suppressID), g.handleSuppress)
+
+	// /evals: display and compare the stored evaluations
+	// of overviews and related documents
+	mux.HandleFunc(get(evalsID), g.handleEvals)
+
+	// /pins: display, pin and unpin documents that come first
+	// in search results for matching topics
+	mux.HandleFunc(get(pinsID), g.handlePins)
+
+	// /apitokens: display, issue and revoke the tokens
+	// that authenticate callers of the /api/gh/ endpoints
+	mux.HandleFunc(get(apiTokensID), g.handleAPITokens)
+
+	// /watchers: display the positions of the watchers
+	// and rewind or advance them
+	mux.HandleFunc(get(watchersID), g.handleWatchers)
+
+	// /api/gh/overview, /api/gh/related, /api/gh/labels?project=P&issue=N:
+	// Gaby's view of issue N in P, for the "gh oscar" GitHub CLI extension.
+	// They authenticate callers with tokens instead of the web UI sign-in
+	// (see ghapi.go).
+	mux.HandleFunc("GET /api/gh/overview", g.handleGHOverviewAPI)
+	mux.HandleFunc("GET /api/gh/related", g.handleGHRelatedAPI)
+	mux.HandleFunc("GET /api/gh/labels", g.handleGHLabelsAPI)
+
+	// /api/hover?pkg=P&symbol=S or /api/hover?pkg=P&error=E: the issues
+	// related to symbol S or error E in package P, for editor hovers.
+	// It authenticates callers with tokens, as the /api/gh/ endpoints do
+	// (see hover.go).
+	mux.HandleFunc("GET /api/hover", g.handleHoverAPI)
+
+	// /feedback: display the emoji votes on Gaby's GitHub comments.
+	// /api/feedback: the summary of the votes, as JSON.
+	mux.HandleFunc(get(feedbackID), g.handleFeedback)
+	mux.HandleFunc("GET /api/feedback", g.handleFeedbackAPI)
+
+	// /workload: display the workload of maintainer areas and maintainers.
+	// /workload?project=P: the same, for project P.
+	mux.HandleFunc(get(workloadID), g.handleWorkload)
+
+	// /weekly: display the last weekly digest of the first project.
+	// /weekly?project=P: the same, for project P.
+	mux.HandleFunc(get(weeklyID), g.handleWeekly)
+
+	// /api/reviewguidelines?project=P: get (GET) or set (POST)
+	// the review guidelines used for P's review checklists.
+	mux.HandleFunc("GET /api/reviewguidelines", g.handleReviewGuidelinesAPI)
+	mux.HandleFunc("POST /api/reviewguidelines", g.handleReviewGuidelinesAPI)
+
+	// /api/relatedbackfill?project=P&...: post related documents on
+	// existing issues in P, a few at a time (see backfill.go).
+	mux.HandleFunc("POST /api/relatedbackfill", g.handleRelatedBackfillAPI)
+
+	// /api/overviewbatch?project=P&...: submit a batch generating
+	// overviews of existing issues in P; ?id=ID: check on the batch
+	// (see overviewbatch.go).
+	mux.HandleFunc("POST /api/overviewbatch", g.handleOverviewBatchAPI)
+	mux.HandleFunc("GET /api/overviewbatch", g.handleOverviewBatchStatusAPI)
+
+	// /backup: download a backup of the DB (see backup.go).
+	mux.HandleFunc("GET /backup", g.handleBackup)
+
+	// gc deletes data that is no longer needed, or reports what it would delete.
+	mux.HandleFunc("GET /gc", g.handleGC)
+
+	// /profile: run a job under the profiler, or list profiled runs.
+	// /profile/ID/KIND: download a stored profile.
+	// Both require the -pprof flag, as does /debug/pprof/.
+	mux.HandleFunc("GET /profile", g.handleProfile)
+	mux.HandleFunc("GET /profile/{id}/{kind}", g.handleProfileData)
+	if flags.pprof {
+		registerPprof(mux)
+	}
 	return mux
 }
 
@@ -783,6 +1667,18 @@ func (g *Gaby) crawl(ctx context.Context) error {
 	return g.embedAll(ctx)
 }
 
+// checkCorpus runs the corpus consistency check.
+// It holds the GitHub sync and embedding locks,
+// so that the stores do not change during the check.
+func (g *Gaby) checkCorpus(ctx context.Context) (*corpuscheck.Report, error) {
+	g.db.Lock(gabyGitHubSyncLock)
+	defer g.db.Unlock(gabyGitHubSyncLock)
+	g.db.Lock(gabyEmbedLock)
+	defer g.db.Unlock(gabyEmbedLock)
+
+	return g.corpusChecker.Run(ctx)
+}
+
 // syncCrawl crawls webpages and adds them to the document corpus.
 func (g *Gaby) syncCrawl(ctx context.Context) error {
 	g.db.Lock(gabyCrawlLock)
@@ -807,6 +1703,15 @@ func (g *Gaby) syncAndRunAll(ctx context.Context) (errs []error) {
 			errs = append(errs, err)
 		}
 	}
+	// Jobs of features that use the LLM are skipped
+	// while the feature is paused (see -llmenforcebudget).
+	// The related poster runs regardless: without the LLM,
+	// it posts without explanations.
+	run := func(feature string, job func(context.Context) error) {
+		if !g.paused(feature) {
+			check(job(ctx))
+		}
+	}
 
 	if flags.enablesync {
 		// Independent syncs can run in any order.
@@ -814,23 +1719,42 @@ func (g *Gaby) syncAndRunAll(ctx context.Context) (errs []error) {
 		check(g.syncGitHubDiscussions(ctx))
 		check(g.syncGerrit(ctx))
 		check(g.syncGroups(ctx))
+		check(g.syncFeedback(ctx))
+		check(g.computeWorkload(ctx))
+		run("dupmine", g.mineDuplicates)
 
 		// Embed must happen last.
 		check(g.embedAll(ctx))
+		check(g.diffSearch(ctx))
 	}
 
 	if flags.enablechanges {
 		// Changes can run in almost any order; the labeler should
 		// run before anything that uses labels.
 		// Write all changes to the action log.
+		// Mutes must be recorded before the posters consult them.
+		check(g.recordMutes(ctx))
 		check(g.fixAllComments(ctx))
 		check(g.postAllRelated(ctx))
-		check(g.labelAll(ctx))
-		check(g.postAllRules(ctx))
+		run("duplicate", g.postAllDuplicates)
+		run("labels", g.labelAll)
+		run("rules", g.postAllRules)
+		run("pipeline", g.runPipelines)
 		check(g.postAllBisections(ctx))
-		check(g.postAllOverviews(ctx))
-
-		// Apply all actions.
+		run("overview", g.pollOverviewBatches)
+		run("overview", g.postAllOverviews)
+		check(g.checkAllCommitMessages(ctx))
+		run("weekly", g.reportWeekly)
+		// Combine the comments added by the actions run last time.
+		check(g.postAllCombined(ctx))
+
+		// Collect approvals from GitHub, then apply all actions,
+		// unless another instance took over meanwhile (see -elect).
+		if err := g.checkLeader(); err != nil {
+			check(err)
+			return errs
+		}
+		check(g.runApprovals(ctx))
 		check(g.runActions())
 	}
 
@@ -845,6 +1769,43 @@ func (g *Gaby) runActions() error {
 	return actions.Run(g.ctx, g.slog, g.db)
 }
 
+// defaultPolicy returns the posting policy of projects, and of
+// policy fields, that -policies does not configure.
+// It limits the comments, edits and label changes in each GitHub
+// project, shared by all posters, to 60 an hour, and it leaves alone
+// the issues opened by gopherbot, the Go project's own bot.
+func defaultPolicy() *policy.Policy {
+	return &policy.Policy{
+		MaxPostsPerHour: 60,
+		SkipAuthors:     []string{"gopherbot"},
+	}
+}
+
+// loadPolicies returns the posting policies of the GitHub projects,
+// read from file (see -policies) if it is not empty.
+func (g *Gaby) loadPolicies(file string) (*policy.Set, error) {
+	c := &policy.Config{Default: defaultPolicy()}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("-policies: %w", err)
+		}
+		if c, err = policy.ParseConfig(data, defaultPolicy()); err != nil {
+			return nil, fmt.Errorf("-policies: %w", err)
+		}
+		for proj := range c.Projects {
+			if !slices.Contains(g.githubProjects, proj) {
+				return nil, fmt.Errorf("-policies: project %s is not synced", proj)
+			}
+		}
+	}
+	policies := policy.New(g.db)
+	for _, project := range g.githubProjects {
+		policies.SetPolicy(project, c.Policy(project))
+	}
+	return policies, nil
+}
+
 const (
 	gabyGitHubSyncLock     = "gabygithubsync"
 	gabyDiscussionSyncLock = "gabydiscussionsync"
@@ -852,13 +1813,24 @@ const (
 	gabyGroupsSyncLock     = "gabygroupssync"
 	gabyEmbedLock          = "gabyembedsync"
 	gabyCrawlLock          = "gabycrawlsync"
+	gabyFeedbackSyncLock   = "gabyfeedbacksync"
+	gabyWorkloadLock       = "gabyworkload"
+	gabyDupMineLock        = "gabydupmine"
+	gabyMuteLock           = "gabymute"
+	gabyWeeklyLock         = "gabyweekly"
 
 	gabyFixCommentLock    = "gabyfixcommentaction"
 	gabyPostRelatedLock   = "gabyrelatedaction"
+	gabyPostDuplicateLock = "gabyduplicateaction"
 	gabyPostRulesLock     = "gabyrulesaction"
 	gabyLabelLock         = "gabylabelaction"
+	gabyPipelineLock      = "gabypipelineaction"
 	gabyPostBisectionLock = "gabybisectionaction"
+	gabyCommitMsgLock     = "gabycommitmsgaction"
+	gabyComposeLock       = "gabycomposeaction"
+	gabyApprovalLock      = "gabyapproval"
 	runActionsLock        = "gabyrunactions"
+	gabyGCLock            = "gabygc"
 )
 
 func (g *Gaby) syncGitHubIssues(ctx context.Context) error {
@@ -869,9 +1841,13 @@ func (g *Gaby) syncGitHubIssues(ctx context.Context) error {
 	if err := g.github.Sync(ctx); err != nil {
 		return err
 	}
+	for _, rl := range g.github.RateLimits() {
+		g.slog.Info("github rate limit", "resource", rl.Resource,
+			"remaining", rl.Remaining, "limit", rl.Limit, "reset", rl.Reset)
+	}
 	// Store newly downloaded GitHub issue events in the document
 	// database.
-	docs.Sync(g.docs, g.github)
+	docs.Sync(g.docs, g.githubDocs())
 	return nil
 }
 
@@ -916,20 +1892,104 @@ func (g *Gaby) syncGroups(ctx context.Context) error {
 	return nil
 }
 
+// syncFeedback tracks new comments posted by Gaby and refreshes
+// the emoji votes on recent ones.
+func (g *Gaby) syncFeedback(ctx context.Context) error {
+	g.db.Lock(gabyFeedbackSyncLock)
+	defer g.db.Unlock(gabyFeedbackSyncLock)
+
+	return g.feedback.Run(ctx)
+}
+
+// computeWorkload refreshes the daily maintainer workload reports.
+func (g *Gaby) computeWorkload(ctx context.Context) error {
+	g.db.Lock(gabyWorkloadLock)
+	defer g.db.Unlock(gabyWorkloadLock)
+
+	return g.workload.Run(ctx)
+}
+
+// mineDuplicates records the duplicate issues closed since the last call.
+func (g *Gaby) mineDuplicates(ctx context.Context) error {
+	g.db.Lock(gabyDupMineLock)
+	defer g.db.Unlock(gabyDupMineLock)
+
+	return g.dupMiner.Run(ctx)
+}
+
+// recordMutes records the issues that have opted out of bot comments
+// since the last call.
+func (g *Gaby) recordMutes(ctx context.Context) error {
+	g.db.Lock(gabyMuteLock)
+	defer g.db.Unlock(gabyMuteLock)
+
+	return g.mute.Run(ctx)
+}
+
 // embedAll store embeddings for all new documents in the vector database.
 // This must happen after all other syncs.
 func (g *Gaby) embedAll(ctx context.Context) error {
 	g.db.Lock(gabyEmbedLock)
 	defer g.db.Unlock(gabyEmbedLock)
 
+	if g.index != nil {
+		return g.index.Sync(ctx, g.docs)
+	}
+	// Without an index (as in tests), g.vector is a single namespace.
 	return embeddocs.Sync(ctx, g.slog, g.vector, g.embed, g.docs)
 }
 
+// diffSearch runs the benchmark search queries if the vector index
+// changed since they were last run, and reports an error if their
+// results changed too much.
+// It is a no-op if the -searchqueries flag is not set.
+func (g *Gaby) diffSearch(ctx context.Context) error {
+	if g.searchDiff == nil {
+		return nil
+	}
+	g.db.Lock(gabyEmbedLock)
+	defer g.db.Unlock(gabyEmbedLock)
+
+	index := fmt.Sprintf("%s@%d", g.index.Active().Model, g.index.Latest(g.docs))
+	r, err := g.searchDiff.Run(ctx, index)
+	if err != nil {
+		return err
+	}
+	if r.Alert {
+		return fmt.Errorf("searchdiff: mean churn %.2f of benchmark search results exceeds %.2f", r.Churn, flags.searchChurn)
+	}
+	return nil
+}
+
+// readSearchQueries reads the benchmark search queries in file,
+// one per line, ignoring blank lines and lines beginning with #.
+func readSearchQueries(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("-searchqueries: %w", err)
+	}
+	var queries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		queries = append(queries, line)
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("-searchqueries: no queries in %s", file)
+	}
+	return queries, nil
+}
+
 func (g *Gaby) fixAllComments(ctx context.Context) error {
 	g.db.Lock(gabyFixCommentLock)
 	defer g.db.Unlock(gabyFixCommentLock)
 
-	return g.commentFixer.Run(ctx)
+	if err := g.commentFixer.Run(ctx); err != nil {
+		return err
+	}
+	return g.issueFixer.Run(ctx)
 }
 
 func (g *Gaby) postAllRelated(ctx context.Context) error {
@@ -939,6 +1999,13 @@ func (g *Gaby) postAllRelated(ctx context.Context) error {
 	return g.relatedPoster.Run(ctx)
 }
 
+func (g *Gaby) postAllDuplicates(ctx context.Context) error {
+	g.db.Lock(gabyPostDuplicateLock)
+	defer g.db.Unlock(gabyPostDuplicateLock)
+
+	return g.duplicatePoster.Run(ctx)
+}
+
 func (g *Gaby) postAllRules(ctx context.Context) error {
 	g.db.Lock(gabyPostRulesLock)
 	defer g.db.Unlock(gabyPostRulesLock)
@@ -955,6 +2022,69 @@ func (g *Gaby) postAllOverviews(ctx context.Context) error {
 	return g.overview.Run(ctx)
 }
 
+func (g *Gaby) runPipelines(ctx context.Context) error {
+	g.db.Lock(gabyPipelineLock)
+	defer g.db.Unlock(gabyPipelineLock)
+
+	var errs []error
+	for _, p := range g.pipelines {
+		errs = append(errs, p.Run(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// loadPipelines returns the pipelines defined by the NAME.yaml files
+// in dir (see -pipelines), enabled on the projects they list.
+func (g *Gaby) loadPipelines(dir string) ([]*pipeline.Pipeline, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("-pipelines: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("-pipelines: no .yaml files in %s", dir)
+	}
+	env := &pipeline.Env{
+		Logger:   g.slog,
+		DB:       g.db,
+		GitHub:   g.github,
+		VectorDB: g.vector,
+		Docs:     g.docs,
+		LLM:      g.featureLLM("pipeline"),
+	}
+	if flags.pluginDir != "" {
+		ps, err := plugins.Load(g.slog, flags.pluginDir, strings.Fields(flags.pluginSandbox))
+		if err != nil {
+			return nil, fmt.Errorf("-plugins: %w", err)
+		}
+		env.Plugins = ps
+	}
+	var ps []*pipeline.Pipeline
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("-pipelines: %w", err)
+		}
+		c, err := pipeline.ParseConfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("-pipelines: %s: %w", file, err)
+		}
+		if slices.ContainsFunc(ps, func(p *pipeline.Pipeline) bool { return p.Name() == c.Name }) {
+			return nil, fmt.Errorf("-pipelines: %s: duplicate pipeline name %s", file, c.Name)
+		}
+		for _, proj := range c.Projects {
+			if !slices.Contains(g.githubProjects, proj) {
+				return nil, fmt.Errorf("-pipelines: %s: project %s is not synced", file, proj)
+			}
+		}
+		p, err := c.New(env)
+		if err != nil {
+			return nil, fmt.Errorf("-pipelines: %s: %w", file, err)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
 func (g *Gaby) labelAll(ctx context.Context) error {
 	g.db.Lock(gabyLabelLock)
 	defer g.db.Unlock(gabyLabelLock)
@@ -962,6 +2092,26 @@ func (g *Gaby) labelAll(ctx context.Context) error {
 	return g.labeler.Run(ctx)
 }
 
+func (g *Gaby) checkAllCommitMessages(ctx context.Context) error {
+	if !flags.commitMsgs {
+		return nil
+	}
+	g.db.Lock(gabyCommitMsgLock)
+	defer g.db.Unlock(gabyCommitMsgLock)
+
+	return g.commitChecker.Run(ctx)
+}
+
+func (g *Gaby) postAllCombined(ctx context.Context) error {
+	if g.composer == nil {
+		return nil
+	}
+	g.db.Lock(gabyComposeLock)
+	defer g.db.Unlock(gabyComposeLock)
+
+	return g.composer.Run(ctx)
+}
+
 func (g *Gaby) postAllBisections(ctx context.Context) error {
 	g.db.Lock(gabyPostBisectionLock)
 	defer g.db.Unlock(gabyPostBisectionLock)
@@ -1023,6 +2173,23 @@ var godevDeny = []string{
 	"https://go.dev/doc/go1.17_spec.html",
 }
 
+// pkgsiteAllow and pkgsiteDeny restrict crawling of pkg.go.dev
+// to the standard library documentation.
+// Non-standard-library packages are rejected by [pkgsiteClean].
+var pkgsiteAllow = []string{
+	"https://pkg.go.dev/",
+}
+
+var pkgsiteDeny = []string{
+	"https://pkg.go.dev/about",
+	"https://pkg.go.dev/badge/",
+	"https://pkg.go.dev/license-policy",
+	"https://pkg.go.dev/search",
+	"https://pkg.go.dev/search-help",
+	"https://pkg.go.dev/static/",
+	"https://pkg.go.dev/third_party/",
+}
+
 func godevClean(u *url.URL) error {
 	if u.Host == "go.dev" {
 		u.Fragment = ""
@@ -1034,3 +2201,27 @@ func godevClean(u *url.URL) error {
 	}
 	return nil
 }
+
+// pkgsiteClean canonicalizes pkg.go.dev URLs and rejects
+// URLs for packages outside the standard library.
+// The standard library is identified by the lack of a dot
+// in the first path element (as in "net/http" as opposed to
+// "golang.org/x/net/http2").
+func pkgsiteClean(u *url.URL) error {
+	if u.Host != "pkg.go.dev" {
+		return nil
+	}
+	u.Fragment = ""
+	u.RawQuery = ""
+	u.ForceQuery = false
+	// Drop version suffixes (/net/http@go1.22.0) so that only
+	// the latest documentation is indexed. This must come first,
+	// as versions contain dots.
+	path, _, _ := strings.Cut(u.Path, "@")
+	elem, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if strings.Contains(elem, ".") {
+		return fmt.Errorf("pkgsiteClean: %s is not a standard library package", u.Path)
+	}
+	u.Path = path
+	return nil
+}
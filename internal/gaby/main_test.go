@@ -9,12 +9,22 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"go.opentelemetry.io/otel/metric/noop"
+	"golang.org/x/oscar/internal/llmcost"
+	"golang.org/x/oscar/internal/policy"
+	"golang.org/x/oscar/internal/postgres"
+	"golang.org/x/oscar/internal/sqlite"
+	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/testutil"
 )
 
@@ -56,3 +66,228 @@ func TestNewServer(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestPkgsiteClean(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string // "" for error
+	}{
+		{"https://pkg.go.dev/net/http", "https://pkg.go.dev/net/http"},
+		{"https://pkg.go.dev/net/http?tab=versions#Client", "https://pkg.go.dev/net/http"},
+		{"https://pkg.go.dev/net/http@go1.22.0", "https://pkg.go.dev/net/http"},
+		{"https://pkg.go.dev/fmt@go1.22.0", "https://pkg.go.dev/fmt"},
+		{"https://pkg.go.dev/fmt@go1.22.0#Println", "https://pkg.go.dev/fmt"},
+		{"https://pkg.go.dev/std", "https://pkg.go.dev/std"},
+		{"https://pkg.go.dev/golang.org/x/net@v0.30.0/http2", ""},
+		{"https://pkg.go.dev/golang.org/x/net/http2", ""},
+		{"https://go.dev/doc/x?y#z", "https://go.dev/doc/x?y#z"},
+	} {
+		u, err := url.Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = pkgsiteClean(u)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("pkgsiteClean(%s) = %s, want error", tt.in, u)
+			}
+			continue
+		}
+		if err != nil || u.String() != tt.want {
+			t.Errorf("pkgsiteClean(%s) = %s, %v, want %s, nil", tt.in, u, err, tt.want)
+		}
+	}
+}
+
+func TestParseMinScores(t *testing.T) {
+	got, err := parseMinScores("GoDocumentation=0.78,GitHubIssue=0.85")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["GoDocumentation"] != 0.78 || got["GitHubIssue"] != 0.85 {
+		t.Errorf("parseMinScores = %v", got)
+	}
+	if got, err := parseMinScores(""); got != nil || err != nil {
+		t.Errorf("parseMinScores(\"\") = %v, %v; want nil, nil", got, err)
+	}
+	for _, bad := range []string{"GoDocumentation", "NotAKind=0.5", "GitHubIssue=x", "GitHubIssue=1.5"} {
+		if _, err := parseMinScores(bad); err == nil {
+			t.Errorf("parseMinScores(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestParseAcknowledge(t *testing.T) {
+	g := &Gaby{githubProjects: []string{"golang/go", "golang/oscar"}}
+	got, err := g.parseAcknowledge("golang/go=7,golang/oscar=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["golang/go"] != 7 || got["golang/oscar"] != 2 {
+		t.Errorf("parseAcknowledge = %v", got)
+	}
+	if got, err := g.parseAcknowledge(""); got != nil || err != nil {
+		t.Errorf("parseAcknowledge(\"\") = %v, %v; want nil, nil", got, err)
+	}
+	for _, bad := range []string{"golang/go", "rsc/markdown=3", "golang/go=x", "golang/go=0"} {
+		if _, err := g.parseAcknowledge(bad); err == nil {
+			t.Errorf("parseAcknowledge(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestParseProjects(t *testing.T) {
+	g := &Gaby{githubProjects: []string{"golang/go", "golang/oscar"}}
+	got, err := g.parseProjects("graphql", "golang/oscar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"golang/oscar"}) {
+		t.Errorf("parseProjects = %v, want [golang/oscar]", got)
+	}
+	if got, err := g.parseProjects("graphql", ""); got != nil || err != nil {
+		t.Errorf("parseProjects(\"\") = %v, %v; want nil, nil", got, err)
+	}
+	if _, err := g.parseProjects("graphql", "golang/go,rsc/markdown"); err == nil || !strings.Contains(err.Error(), "-graphql") {
+		t.Errorf("parseProjects(rsc/markdown) = %v, want -graphql error", err)
+	}
+}
+
+func TestParseLLMPrices(t *testing.T) {
+	got, err := parseLLMPrices("gemini-1.5-pro=1.25/5,llama3.1=0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]llmcost.Price{
+		"gemini-1.5-pro": {Input: 1.25, Output: 5},
+		"llama3.1":       {},
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("parseLLMPrices = %v, want %v", got, want)
+	}
+	if got, err := parseLLMPrices(""); got != nil || err != nil {
+		t.Errorf("parseLLMPrices(\"\") = %v, %v; want nil, nil", got, err)
+	}
+	for _, bad := range []string{"gemini-1.5-pro", "gemini-1.5-pro=1", "=1/2", "m=x/1", "m=1/-1"} {
+		if _, err := parseLLMPrices(bad); err == nil {
+			t.Errorf("parseLLMPrices(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestReadDenylist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deny")
+	data := "# prompt injection\n(?i)ignore previous instructions\n\n  system prompt  \n"
+	if err := os.WriteFile(file, []byte(data), 0666); err != nil {
+		t.Fatal(err)
+	}
+	res, err := readDenylist(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, re := range res {
+		got = append(got, re.String())
+	}
+	if want := []string{"(?i)ignore previous instructions", "system prompt"}; !slices.Equal(got, want) {
+		t.Errorf("readDenylist = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(file, []byte("ok\n(unclosed\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readDenylist(file); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("readDenylist with bad regexp = %v, want error on line 2", err)
+	}
+}
+
+func TestLoadPolicies(t *testing.T) {
+	g := &Gaby{db: storage.MemDB(), githubProjects: []string{"golang/go", "golang/tools"}}
+
+	ps, err := g.loadPolicies("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := ps.Policy("golang/go"); p.MaxPostsPerHour != 60 || !slices.Equal(p.SkipAuthors, []string{"gopherbot"}) {
+		t.Errorf("default policy = %+v, want 60 posts an hour, skipping gopherbot", p)
+	}
+
+	file := filepath.Join(t.TempDir(), "policies.yaml")
+	data := "projects:\n  golang/tools:\n    actions: [label]\n    skipauthors: []\n"
+	if err := os.WriteFile(file, []byte(data), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if ps, err = g.loadPolicies(file); err != nil {
+		t.Fatal(err)
+	}
+	if p := ps.Policy("golang/tools"); !slices.Equal(p.Actions, []policy.Action{policy.Label}) || len(p.SkipAuthors) != 0 || p.MaxPostsPerHour != 60 {
+		t.Errorf("golang/tools policy = %+v, want labels only, from anyone, 60 an hour", p)
+	}
+	if p := ps.Policy("golang/go"); p.MaxPostsPerHour != 60 || p.Actions != nil {
+		t.Errorf("golang/go policy = %+v, want default", p)
+	}
+
+	if err := os.WriteFile(file, []byte("projects: {golang/nope: {}}\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.loadPolicies(file); err == nil {
+		t.Errorf("loadPolicies with unknown project succeeded")
+	}
+}
+
+func TestOpenDB(t *testing.T) {
+	ctx := context.Background()
+	open := func(spec string) (*Gaby, error) {
+		g := &Gaby{ctx: ctx, slog: testutil.Slogger(t)}
+		return g, g.openDB(spec)
+	}
+
+	g, err := open("mem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.openVec("ns"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := open("mem~ns"); err == nil {
+		t.Error("vector spec accepted, want error")
+	}
+
+	// SQLite databases keep their vectors in the same file.
+	g, err = open("sqlite:" + filepath.Join(t.TempDir(), "gaby.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.db.Close()
+	g.db.Set([]byte("k"), []byte("v"))
+	if v, ok := g.db.Get([]byte("k")); !ok || string(v) != "v" {
+		t.Errorf("sqlite Get = %q, %v, want %q, true", v, ok, "v")
+	}
+	vdb, err := g.openVec("ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vdb.(*sqlite.VectorDB); !ok {
+		t.Errorf("openVec = %T, want *sqlite.VectorDB", vdb)
+	}
+
+	// The Postgres driver is linked in, so opening an unreachable
+	// database fails to connect rather than failing to find the driver.
+	_, err = open("postgres:postgres://oscar@127.0.0.1:1/oscar?connect_timeout=1")
+	if err == nil || strings.Contains(err.Error(), "unknown driver") {
+		t.Errorf("open unreachable postgres: %v, want connection error", err)
+	}
+	if dsn := os.Getenv("OSCAR_POSTGRES_DSN"); dsn != "" {
+		g, err := open("postgres:" + dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vdb, err := g.openVec("ns")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := vdb.(*postgres.VectorDB); !ok {
+			t.Errorf("openVec = %T, want *postgres.VectorDB", vdb)
+		}
+	}
+}
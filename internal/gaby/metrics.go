@@ -7,9 +7,15 @@ package main
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"go.opentelemetry.io/otel/attribute"
 	ometric "go.opentelemetry.io/otel/metric"
+	"golang.org/x/oscar/internal/circuit"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/gc"
+	"golang.org/x/oscar/internal/searchdiff"
+	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/storage/timed"
 )
 
@@ -50,6 +56,242 @@ func (g *Gaby) registerWatcherMetric(latests map[string]func() timed.DBTime) {
 	}
 }
 
+// registerBreakerMetrics adds metrics for the given circuit breakers:
+// "breaker-state" is the current [circuit.State] of each breaker,
+// and "breaker-calls" counts the calls made through each breaker, by outcome.
+// Both metrics are labeled by the breaker name in the "name" attribute.
+func (g *Gaby) registerBreakerMetrics(breakers []*circuit.Breaker) {
+	_, err := g.meter.Int64ObservableGauge(metricName("breaker-state"),
+		ometric.WithDescription("state of circuit breaker (0=closed, 1=open, 2=half-open)"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			for _, b := range breakers {
+				observer.Observe(int64(b.State()), ometric.WithAttributes(attribute.String("name", b.Name())))
+			}
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("breaker gauge creation failed")
+		panic(err)
+	}
+	_, err = g.meter.Int64ObservableCounter(metricName("breaker-calls"),
+		ometric.WithDescription("calls through circuit breaker, by outcome"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			for _, b := range breakers {
+				s := b.Stats()
+				for outcome, n := range map[string]int64{
+					"success":  s.Successes,
+					"failure":  s.Failures,
+					"rejected": s.Rejected,
+				} {
+					observer.Observe(n, ometric.WithAttributes(
+						attribute.String("name", b.Name()),
+						attribute.String("outcome", outcome)))
+				}
+			}
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("breaker counter creation failed")
+		panic(err)
+	}
+}
+
+// registerEmbedMetrics adds metrics for the embedding of new documents by ix:
+// "embed-docs" counts the documents (with "kind" attribute "docs") and the
+// chunks (with "kind" attribute "chunks") embedded, whose rate is the
+// throughput, "embed-requests" counts the batch-embedding requests by
+// outcome, and "embed-request-ms" is the total time spent in requests.
+func (g *Gaby) registerEmbedMetrics(ix *embeddocs.Index) {
+	_, err := g.meter.Int64ObservableCounter(metricName("embed-docs"),
+		ometric.WithDescription("documents and chunks embedded"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			s := ix.Stats()
+			observer.Observe(s.Docs, ometric.WithAttributes(attribute.String("kind", "docs")))
+			observer.Observe(s.Chunks, ometric.WithAttributes(attribute.String("kind", "chunks")))
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("embed-docs counter creation failed")
+		panic(err)
+	}
+	_, err = g.meter.Int64ObservableCounter(metricName("embed-requests"),
+		ometric.WithDescription("batch-embedding requests, by outcome"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			s := ix.Stats()
+			for outcome, n := range map[string]int64{
+				"success": s.Requests,
+				"retry":   s.Retries,
+				"failure": s.Failures,
+			} {
+				observer.Observe(n, ometric.WithAttributes(attribute.String("outcome", outcome)))
+			}
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("embed-requests counter creation failed")
+		panic(err)
+	}
+	_, err = g.meter.Int64ObservableCounter(metricName("embed-request-ms"),
+		ometric.WithDescription("milliseconds spent in batch-embedding requests"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			observer.Observe(ix.Stats().Time.Milliseconds())
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("embed-request-ms counter creation failed")
+		panic(err)
+	}
+}
+
+// A vectorMemory records the vector DBs opened for g.index
+// that report their memory use, by namespace.
+type vectorMemory struct {
+	mu  sync.Mutex
+	mrs map[string]storage.MemoryReporter
+}
+
+// add records vdb as the vector DB of the namespace,
+// if it reports its memory use.
+func (m *vectorMemory) add(namespace string, vdb storage.VectorDB) {
+	mr, ok := vdb.(storage.MemoryReporter)
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mrs == nil {
+		m.mrs = make(map[string]storage.MemoryReporter)
+	}
+	m.mrs[namespace] = mr
+}
+
+// usage returns the memory use of the vector DB of the namespace.
+// It returns ok=false if that DB has not been opened
+// or does not report its memory use.
+func (m *vectorMemory) usage(namespace string) (_ storage.MemoryUsage, ok bool) {
+	m.mu.Lock()
+	mr := m.mrs[namespace]
+	m.mu.Unlock()
+	if mr == nil {
+		return storage.MemoryUsage{}, false
+	}
+	return mr.MemoryUsage(), true
+}
+
+// vectorMemoryUsage returns the memory use of the vector DB
+// of g.index's active embedding model, if it reports it.
+func (g *Gaby) vectorMemoryUsage() (storage.MemoryUsage, bool) {
+	return g.vectorMem.usage(g.index.Active().Namespace)
+}
+
+// registerMemoryMetrics adds metrics for the memory use reported by usage,
+// which returns ok=false if there is nothing to report:
+// "memory-bytes" is the approximate memory used (with "kind" attribute "used")
+// and the limit (with "kind" attribute "limit"; 0 means no limit),
+// and "memory-spilled" is the number of items not kept in memory
+// because of the limit.
+// Both metrics are labeled by name in the "name" attribute.
+func (g *Gaby) registerMemoryMetrics(name string, usage func() (_ storage.MemoryUsage, ok bool)) {
+	_, err := g.meter.Int64ObservableGauge(metricName("memory-bytes"),
+		ometric.WithDescription("approximate bytes of memory used, and memory limit"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			u, ok := usage()
+			if !ok {
+				return nil
+			}
+			observer.Observe(u.Used, ometric.WithAttributes(attribute.String("name", name), attribute.String("kind", "used")))
+			observer.Observe(u.Limit, ometric.WithAttributes(attribute.String("name", name), attribute.String("kind", "limit")))
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("memory gauge creation failed")
+		panic(err)
+	}
+	_, err = g.meter.Int64ObservableGauge(metricName("memory-spilled"),
+		ometric.WithDescription("number of items not in memory because of the memory limit"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			if u, ok := usage(); ok {
+				observer.Observe(int64(u.Spilled), ometric.WithAttributes(attribute.String("name", name)))
+			}
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("memory-spilled gauge creation failed")
+		panic(err)
+	}
+}
+
+// registerCompressionMetrics adds metrics for the compression of DB values by cr:
+// "db-compression-bytes" is the total size of values written
+// (with "kind" attribute "written") and the size stored after compression
+// (with "kind" attribute "stored"), and "db-compression-values"
+// is the number of values written (with "kind" attribute "written")
+// and the number stored compressed (with "kind" attribute "compressed").
+// Both count values written since the process started.
+func (g *Gaby) registerCompressionMetrics(cr storage.CompressionReporter) {
+	_, err := g.meter.Int64ObservableGauge(metricName("db-compression-bytes"),
+		ometric.WithDescription("bytes of DB values written, and bytes stored after compression"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			s := cr.CompressionStats()
+			observer.Observe(s.Bytes, ometric.WithAttributes(attribute.String("kind", "written")))
+			observer.Observe(s.Stored, ometric.WithAttributes(attribute.String("kind", "stored")))
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("db-compression-bytes gauge creation failed")
+		panic(err)
+	}
+	_, err = g.meter.Int64ObservableGauge(metricName("db-compression-values"),
+		ometric.WithDescription("number of DB values written, and number stored compressed"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			s := cr.CompressionStats()
+			observer.Observe(s.Values, ometric.WithAttributes(attribute.String("kind", "written")))
+			observer.Observe(s.Compressed, ometric.WithAttributes(attribute.String("kind", "compressed")))
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("db-compression-values gauge creation failed")
+		panic(err)
+	}
+}
+
+// registerSearchDiffMetric adds a metric called "searchdiff-churn"
+// for the mean churn of the benchmark search results in the last run
+// of [Gaby.searchDiff].
+func (g *Gaby) registerSearchDiffMetric() {
+	_, err := g.meter.Float64ObservableGauge(metricName("searchdiff-churn"),
+		ometric.WithDescription("mean fraction of the previous top results of the benchmark search queries no longer found"),
+		ometric.WithFloat64Callback(func(_ context.Context, observer ometric.Float64Observer) error {
+			if r, ok := searchdiff.LastReport(g.db); ok {
+				observer.Observe(r.Churn)
+			}
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("searchdiff-churn gauge creation failed")
+		panic(err)
+	}
+}
+
+// registerLeaderMetric adds a metric called "leader" that is 1
+// while this instance leads (see -elect) and 0 otherwise.
+func (g *Gaby) registerLeaderMetric() {
+	_, err := g.meter.Int64ObservableGauge(metricName("leader"),
+		ometric.WithDescription("whether this instance leads (1) or stands by (0)"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			var v int64
+			if g.leader.IsLeader() {
+				v = 1
+			}
+			observer.Observe(v, ometric.WithAttributes(attribute.String("id", g.leader.ID())))
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("leader gauge creation failed")
+		panic(err)
+	}
+}
+
 // metricName returns the full metric name for the given short name.
 // The names are chosen to display nicely on the Metric Explorer's "select a metric"
 // dropdown. Production metrics will group under "Gaby", while others will
@@ -62,3 +304,35 @@ func metricName(shortName string) string {
 	// We want non-prod metrics to be in a different group.
 	return "gaby_" + flags.firestoredb + "/" + shortName
 }
+
+// registerGCMetrics adds metrics for the data deleted by c since
+// the process started: "gc-reclaimed-bytes" is the size of the
+// deleted keys and values, and "gc-reclaimed-entries" is the number
+// of deleted entries, both with the kind of data (see [gc.KindReport])
+// in the "kind" attribute.
+func (g *Gaby) registerGCMetrics(c *gc.Collector) {
+	_, err := g.meter.Int64ObservableCounter(metricName("gc-reclaimed-bytes"),
+		ometric.WithDescription("bytes of data deleted by garbage collection, by kind"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			for _, k := range c.Totals() {
+				observer.Observe(k.Bytes, ometric.WithAttributes(attribute.String("kind", k.Kind)))
+			}
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("gc-reclaimed-bytes counter creation failed")
+		panic(err)
+	}
+	_, err = g.meter.Int64ObservableCounter(metricName("gc-reclaimed-entries"),
+		ometric.WithDescription("DB entries and vectors deleted by garbage collection, by kind"),
+		ometric.WithInt64Callback(func(_ context.Context, observer ometric.Int64Observer) error {
+			for _, k := range c.Totals() {
+				observer.Observe(k.Entries, ometric.WithAttributes(attribute.String("kind", k.Kind)))
+			}
+			return nil
+		}))
+	if err != nil {
+		g.slog.Error("gc-reclaimed-entries counter creation failed")
+		panic(err)
+	}
+}
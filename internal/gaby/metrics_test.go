@@ -0,0 +1,55 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"golang.org/x/oscar/internal/circuit"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestVectorMemoryMetrics(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	reader := sdkmetric.NewManualReader()
+	db := storage.MemDB()
+	g := &Gaby{
+		slog:  lg,
+		db:    db,
+		meter: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"),
+		openVec: func(namespace string) (storage.VectorDB, error) {
+			return storage.MemVectorDBWithLimit(db, lg, namespace, 1<<20), nil
+		},
+	}
+	// The index and the circuit breaker hide the memory-limited DB.
+	g.index = embeddocs.NewIndex(lg, db, vectorDBNamespace, "test/model", g.openIndexVec(circuit.New(lg, "vector")))
+	g.index.Set("id", llm.Vector{1, 2, 3})
+	g.registerMemoryMetrics("vector", g.vectorMemoryUsage)
+
+	var rm metricdata.ResourceMetrics
+	check(reader.Collect(context.Background(), &rm))
+	used := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != metricName("memory-bytes") {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Gauge[int64]).DataPoints {
+				kind, _ := dp.Attributes.Value("kind")
+				used[kind.AsString()] = dp.Value
+			}
+		}
+	}
+	if used["used"] <= 0 || used["limit"] != 1<<20 {
+		t.Errorf("memory-bytes = %v, want used > 0 and limit %d", used, 1<<20)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/oscar/internal/gcp/checks"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/moderate"
+)
+
+// initModerator sets up g.moderator, the filter that checks all
+// LLM-generated text before it is used, according to the
+// -moderatedeny, -moderatepolicy, -moderatementions and
+// -moderatelinks flags.
+func (g *Gaby) initModerator() error {
+	f := moderate.New(g.slog)
+	if flags.moderateDeny != "" {
+		res, err := readDenylist(flags.moderateDeny)
+		if err != nil {
+			return err
+		}
+		for _, re := range res {
+			f.Deny(re)
+		}
+	}
+	if flags.moderatePolicy {
+		if g.policy == nil {
+			c, err := checks.New(g.ctx, g.slog, flags.project, llm.AllPolicyTypes())
+			if err != nil {
+				return fmt.Errorf("-moderatepolicy: %w", err)
+			}
+			g.policy = c
+		}
+		f.SetChecker(g.policy)
+	}
+	if flags.moderateMentions {
+		f.StripMentions()
+	}
+	if flags.moderateLinks != "" {
+		for _, h := range strings.Split(flags.moderateLinks, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				f.AllowLinks(h)
+			}
+		}
+	}
+	g.moderator = f
+	return nil
+}
+
+// readDenylist reads the file named by the -moderatedeny flag,
+// which holds one regular expression per line.
+// Blank lines and lines beginning with # are ignored.
+func readDenylist(file string) ([]*regexp.Regexp, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("-moderatedeny: %w", err)
+	}
+	var res []*regexp.Regexp
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		re, err := regexp.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("-moderatedeny: %s:%d: %w", file, i+1, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
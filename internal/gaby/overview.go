@@ -16,6 +16,8 @@ import (
 
 	"github.com/google/safehtml"
 	"github.com/google/safehtml/template"
+	"golang.org/x/oscar/internal/commentfix"
+	"golang.org/x/oscar/internal/entity"
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/htmlutil"
 	"golang.org/x/oscar/internal/llmapp"
@@ -41,6 +43,22 @@ type overviewResult struct {
 	// a text description of the type of result (for display), finishing
 	// the sentence "AI-generated Overview of ".
 	Desc string
+	// If non-nil, the result is an earlier overview, displayed because
+	// a new one could not be generated.
+	Stale *staleResult
+	// (for [relatedOverviewType]) whether related documents were
+	// searched for across all projects, in which case they are
+	// displayed grouped by source.
+	AllProjects bool
+	// (for [prDiffOverviewType] and [checklistOverviewType]) whether an
+	// action to post the result on the pull request was added to the action log.
+	Posted bool
+}
+
+// A staleResult describes why an out-of-date overview is displayed.
+type staleResult struct {
+	Generated time.Time // when the overview was generated
+	Err       error     // the error generating a new overview
 }
 
 // overviewParams holds the raw HTML parameters.
@@ -48,30 +66,46 @@ type overviewParams struct {
 	Query           string // the issue ID to lookup, or golang/go#12345 or github.com/golang/go/issues/12345 form
 	LastReadComment string // (for [updateOverviewType]: summarize all comments after this comment ID)
 	OverviewType    string // the type of overview to generate
+	AllProjects     bool   // (for [relatedOverviewType]: search all projects and crawled docs, not just the issue's project)
+	Length          string // (for [issueOverviewType], [updateOverviewType], [prOverviewType] and [prDiffOverviewType]: the length of the overview, see [llmapp.ParseLength])
+	Post            bool   // (for [prDiffOverviewType] and [checklistOverviewType]: log an action to post the result on the pull request)
 }
 
 // the possible overview types
 const (
-	issueOverviewType   = "issue_overview"
-	relatedOverviewType = "related_overview"
-	updateOverviewType  = "update_overview"
+	issueOverviewType     = "issue_overview"
+	relatedOverviewType   = "related_overview"
+	updateOverviewType    = "update_overview"
+	prOverviewType        = "pr_overview"
+	prDiffOverviewType    = "pr_diff"
+	checklistOverviewType = "review_checklist"
 )
 
 // validOverviewType reports whether the given type
 // is a recognized overview type.
 func validOverviewType(t string) bool {
-	return t == issueOverviewType || t == relatedOverviewType || t == updateOverviewType
+	return t == issueOverviewType || t == relatedOverviewType || t == updateOverviewType || t == prOverviewType || t == prDiffOverviewType || t == checklistOverviewType
 }
 
 func (g *Gaby) handleOverview(w http.ResponseWriter, r *http.Request) {
 	handlePage(w, g.populateOverviewPage(r), overviewPageTmpl)
 }
 
+// markdownFixer fixes mistakes that we have observed the LLM make
+// when generating markdown, such as text after a closing code fence.
+var markdownFixer = func() *commentfix.Fixer {
+	var f commentfix.Fixer
+	f.FenceCode()
+	return &f
+}()
+
 // fixMarkdown fixes mistakes that we have observed the LLM make
 // when generating markdown.
 func fixMarkdown(text string) string {
-	// add newline after backticks followed by a space
-	return strings.ReplaceAll(text, "``` ", "```\n")
+	if fixed, ok := markdownFixer.Fix(text); ok {
+		return fixed
+	}
+	return text
 }
 
 var overviewPageTmpl = newTemplate(overviewPageTmplFile, template.FuncMap{
@@ -97,51 +131,40 @@ func fmtTimeString(s string) string {
 //   - "golang/go#12345"
 //   - "github.com/golang/go/issues/12345" or "https://github.com/golang/go/issues/12345"
 //   - "go.dev/issues/12345" or "https://go.dev/issues/12345"
+//
+// See [entity.ParseIssue] for the details.
 func parseIssueNumber(issueID string) (project string, issue int64, _ error) {
 	issueID = strings.TrimSpace(issueID)
 	if issueID == "" {
 		return "", 0, nil
 	}
-	split := func(q string) (string, string) {
-		q = strings.TrimPrefix(q, "https://")
-		// recognize github.com/golang/go/issues/12345
-		if proj, ok := strings.CutPrefix(q, "github.com/"); ok {
-			i := strings.LastIndex(proj, "/issues/")
-			if i < 0 {
-				return "", q
-			}
-			return proj[:i], proj[i+len("/issues/"):]
-		}
-		// recognize "go.dev/issues/12345"
-		if num, ok := strings.CutPrefix(q, "go.dev/issues/"); ok {
-			return "golang/go", num
-		}
-		// recognize golang/go#12345
-		if proj, num, ok := strings.Cut(q, "#"); ok {
-			return proj, num
-		}
-		return "", q
-	}
-	proj, num := split(issueID)
-	issue, err := strconv.ParseInt(num, 10, 64)
-	if err != nil || issue <= 0 {
+	id, err := entity.ParseIssue(issueID)
+	if err != nil {
 		return "", 0, fmt.Errorf("invalid issue number %q", issueID)
 	}
-	return proj, issue, nil
+	return id.Project, id.Number, nil
 }
 
 // parseIssueComment parses the issue comment ID from the given commentID string.
-// The issue ID string can be in one of the following formats:
+// The comment ID string can be in one of the following formats:
 //   - "6789": returns 6789 (assumed to be issue comment 6789 for the issue
 //     in [overviewParams.Query] in the "golang/go" repo)
-//
-// TODO(tatianabradley): allow comments to be expressed as URLs, e.g.:
-//   - "golang/go#12345#issuecomment6789"
-//   - "github.com/golang/go/issues/12345#issuecomment6789" or "https://github.com/golang/go/issues/12345#issuecomment6789"
-//   - "go.dev/issues/12345#issuecomment6789" or "https://go.dev/issues/12345#issuecomment6789"
+//   - "github.com/golang/go/issues/12345#issuecomment-6789" or
+//     "https://github.com/golang/go/issues/12345#issuecomment-6789"
 func parseIssueComment(commentID string) (int64, error) {
 	commentID = trim(commentID)
-	return strconv.ParseInt(commentID, 10, 64)
+	if n, err := strconv.ParseInt(commentID, 10, 64); err == nil {
+		return n, nil
+	}
+	u := commentID
+	if !strings.HasPrefix(u, "https://") {
+		u = "https://" + u
+	}
+	id, err := entity.Parse(u)
+	if err != nil || id.Kind != entity.GitHubIssueComment {
+		return 0, fmt.Errorf("invalid issue comment %q", commentID)
+	}
+	return id.Comment, nil
 }
 
 // populateOverviewPage returns the contents of the overview page.
@@ -150,6 +173,9 @@ func (g *Gaby) populateOverviewPage(r *http.Request) *overviewPage {
 		Query:           r.FormValue(paramQuery),
 		OverviewType:    r.FormValue(paramOverviewType),
 		LastReadComment: r.FormValue(paramLastRead),
+		AllProjects:     parseCheckbox(r.FormValue(paramAllProjects)),
+		Length:          r.FormValue(paramLength),
+		Post:            parseCheckbox(r.FormValue(paramPost)),
 	}
 	p := &overviewPage{
 		Params: pm,
@@ -183,12 +209,30 @@ func (p *overviewPage) setCommonPage() {
 const (
 	paramOverviewType = "t"
 	paramLastRead     = "last_read"
+	paramAllProjects  = "all"
+	paramLength       = "len"
+	paramPost         = "post"
 )
 
 var (
-	safeLastRead = toSafeID(paramLastRead)
+	safeLastRead    = toSafeID(paramLastRead)
+	safeAllProjects = toSafeID(paramAllProjects)
+	safeLength      = toSafeID(paramLength)
+	safePost        = toSafeID(paramPost)
 )
 
+// parseCheckbox reports whether the form value v
+// represents a checked checkbox. In addition to the value
+// sent by the form ("on"), it accepts the values recognized
+// by [strconv.ParseBool] so the parameter can be set directly in a URL.
+func parseCheckbox(v string) bool {
+	if v == "on" {
+		return true
+	}
+	b, _ := strconv.ParseBool(trim(v))
+	return b
+}
+
 // inputs converts the params to HTML form inputs.
 func (pm *overviewParams) inputs() []FormInput {
 	return []FormInput{
@@ -206,7 +250,7 @@ func (pm *overviewParams) inputs() []FormInput {
 		{
 			Label:       "overview type",
 			Type:        "radio choice",
-			Description: `"issue and comments" generates an overview of the issue and its comments; "related documents" searches for related documents and summarizes them; "comments after" generates a summary of the comments after the specified comment ID`,
+			Description: `"issue and comments" generates an overview of the issue and its comments; "related documents" searches for related documents and summarizes them; "comments after" generates a summary of the comments after the specified comment ID; "pull request" generates an overview of a pull request, its comments and its review threads; "pull request diff" summarizes the changes made by a pull request for its reviewers; "review checklist" generates a checklist for reviewing a pull request, following its project's review guidelines`,
 			Name:        toSafeID(paramOverviewType),
 			Required:    true,
 			Typed: RadioInput{
@@ -236,9 +280,78 @@ func (pm *overviewParams) inputs() []FormInput {
 						Value:   updateOverviewType,
 						Checked: pm.checkRadio(updateOverviewType),
 					},
+					{
+						Label:   "pull request",
+						ID:      toSafeID(prOverviewType),
+						Value:   prOverviewType,
+						Checked: pm.checkRadio(prOverviewType),
+					},
+					{
+						Label:   "pull request diff",
+						ID:      toSafeID(prDiffOverviewType),
+						Value:   prDiffOverviewType,
+						Checked: pm.checkRadio(prDiffOverviewType),
+					},
+					{
+						Label:   "review checklist",
+						ID:      toSafeID(checklistOverviewType),
+						Value:   checklistOverviewType,
+						Checked: pm.checkRadio(checklistOverviewType),
+					},
 				},
 			},
 		},
+		{
+			Label:       "all projects",
+			Type:        "checkbox",
+			Description: `(for "related documents") search for related documents in all projects and crawled documentation, not just the issue's project`,
+			Name:        safeAllProjects,
+			Typed: CheckboxInput{
+				ID:      safeAllProjects,
+				Value:   "on",
+				Checked: pm.AllProjects,
+			},
+		},
+		{
+			Label:       "length",
+			Type:        "radio choice",
+			Description: `(for "issue overview", "comments after", "pull request" and "pull request diff") the length of the overview: "short" is at most 3 sentences, "medium" is a few paragraphs, and "long" is a comprehensive summary`,
+			Name:        safeLength,
+			Typed: RadioInput{
+				Choices: []RadioChoice{
+					pm.lengthChoice(llmapp.Short, toSafeID("len_short")),
+					pm.lengthChoice(llmapp.Medium, toSafeID("len_medium")),
+					pm.lengthChoice(llmapp.Long, toSafeID("len_long")),
+				},
+			},
+		},
+		{
+			Label:       "post",
+			Type:        "checkbox",
+			Description: `(for "pull request diff" and "review checklist") add an action to the action log to post the result on the pull request`,
+			Name:        safePost,
+			Typed: CheckboxInput{
+				ID:      safePost,
+				Value:   "on",
+				Checked: pm.Post,
+			},
+		},
+	}
+}
+
+// lengthChoice returns the radio button with the given id
+// for the overview length l.
+func (pm *overviewParams) lengthChoice(l llmapp.Length, id safeID) RadioChoice {
+	// Check the default (long) if the length is unset or invalid.
+	checked, err := llmapp.ParseLength(pm.Length)
+	if err != nil {
+		checked = llmapp.Long
+	}
+	return RadioChoice{
+		Label:   string(l),
+		ID:      id,
+		Value:   string(l),
+		Checked: l == checked,
 	}
 }
 
@@ -258,42 +371,75 @@ func (f *overviewParams) checkRadio(value string) bool {
 
 // newOverview generates an newOverview of the issue based on the given parameters.
 func (g *Gaby) newOverview(ctx context.Context, pm *overviewParams) (*overviewResult, error) {
-	proj, issue, err := parseIssueNumber(pm.Query)
+	iss, err := g.lookupIssue(pm.Query)
 	if err != nil {
-		return nil, fmt.Errorf("invalid form value: %v", err)
-	}
-	if proj == "" && len(g.githubProjects) > 0 {
-		proj = g.githubProjects[0] // default to first project.
+		return nil, err
 	}
-	if !slices.Contains(g.githubProjects, proj) {
-		return nil, fmt.Errorf("invalid form value (unrecognized project): %q", pm.Query)
+	if g.snapshot {
+		// Without the LLM, only stored overviews can be displayed.
+		return g.snapshotOverview(iss, pm.OverviewType)
 	}
-	iss, err := github.LookupIssue(g.db, proj, issue)
+	length, err := llmapp.ParseLength(pm.Length)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid form value: %v", err)
 	}
+	ov := g.overview.WithLength(length)
 
 	switch pm.OverviewType {
 	case "", issueOverviewType:
-		return g.issueOverview(ctx, iss)
+		return g.issueOverview(ctx, ov, iss)
 	case relatedOverviewType:
-		return g.relatedOverview(ctx, iss)
+		return g.relatedOverview(ctx, iss, pm.AllProjects)
 	case updateOverviewType:
 		lastReadComment, err := parseIssueComment(pm.LastReadComment)
 		if err != nil {
 			return nil, err
 		}
-		return g.updateOverview(ctx, iss, lastReadComment)
+		return g.updateOverview(ctx, ov, iss, lastReadComment)
+	case prOverviewType:
+		return g.prOverview(ctx, ov, iss)
+	case prDiffOverviewType:
+		return g.prDiffOverview(ctx, ov, iss, pm.Post)
+	case checklistOverviewType:
+		return g.checklistOverview(ctx, iss, pm.Post)
 	default:
 		return nil, fmt.Errorf("unknown overview type %q", pm.OverviewType)
 	}
 }
 
-// issueOverview generates an overview of the issue and its comments.
-func (g *Gaby) issueOverview(ctx context.Context, iss *github.Issue) (*overviewResult, error) {
-	overview, err := g.overview.ForIssue(ctx, iss)
+// lookupIssue looks up the issue identified by the form value q
+// (see [parseIssueNumber] for the accepted formats).
+// The issue must belong to one of the GitHub projects Gaby knows about;
+// if q does not name a project, the first project is assumed.
+func (g *Gaby) lookupIssue(q string) (*github.Issue, error) {
+	proj, issue, err := parseIssueNumber(q)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid form value: %v", err)
+	}
+	if proj == "" && len(g.githubProjects) > 0 {
+		proj = g.githubProjects[0] // default to first project.
+	}
+	if !slices.Contains(g.githubProjects, proj) {
+		return nil, fmt.Errorf("invalid form value (unrecognized project): %q", q)
+	}
+	return github.LookupIssue(g.db, proj, issue)
+}
+
+// issueOverview generates an overview of the issue and its comments using ov.
+// If a new overview cannot be generated (for example, because the LLM
+// is unavailable), it falls back to the last overview generated for the issue,
+// if any.
+func (g *Gaby) issueOverview(ctx context.Context, ov *overview.Client, iss *github.Issue) (*overviewResult, error) {
+	var stale *staleResult
+	overview, err := ov.ForIssue(ctx, iss)
+	if err != nil {
+		last, generated, ok := ov.LastForIssue(iss)
+		if !ok {
+			return nil, err
+		}
+		g.slog.Warn("overview: serving stale result", "issue", iss.HTMLURL, "generated", generated, "err", err)
+		overview = last
+		stale = &staleResult{Generated: generated, Err: err}
 	}
 	return &overviewResult{
 		Raw:   overview.Overview,
@@ -301,28 +447,41 @@ func (g *Gaby) issueOverview(ctx context.Context, iss *github.Issue) (*overviewR
 		Typed: overview,
 		Type:  issueOverviewType,
 		Desc:  fmt.Sprintf("issue %d and all %d comments", iss.Number, overview.TotalComments),
+		Stale: stale,
 	}, nil
 }
 
 // relatedOverview generates an overview of the issue and its related documents.
-func (g *Gaby) relatedOverview(ctx context.Context, iss *github.Issue) (*overviewResult, error) {
-	analysis, err := search.Analyze(ctx, g.llmapp, g.vector, g.docs, iss.DocID())
+// By default, only documents from the issue's own project are considered.
+// If allProjects is set, documents from all enabled projects and
+// crawled documentation are considered.
+func (g *Gaby) relatedOverview(ctx context.Context, iss *github.Issue, allProjects bool) (*overviewResult, error) {
+	opts := &search.AnalyzeOptions{}
+	if !allProjects {
+		opts.Sources = []string{iss.Project()}
+	}
+	analysis, err := search.Analyze(ctx, g.featureLLMApp("overview"), g.vector, g.docs, iss.DocID(), opts)
 	if err != nil {
 		return nil, err
 	}
+	desc := fmt.Sprintf("issue %d and %d related docs", iss.Number, len(analysis.Output.Related))
+	if allProjects {
+		desc += " from all projects"
+	}
 	return &overviewResult{
-		Raw:   &analysis.Result,
-		Issue: iss,
-		Typed: analysis,
-		Type:  relatedOverviewType,
-		Desc:  fmt.Sprintf("issue %d and %d related docs", iss.Number, len(analysis.Output.Related)),
+		Raw:         &analysis.Result,
+		Issue:       iss,
+		Typed:       analysis,
+		Type:        relatedOverviewType,
+		Desc:        desc,
+		AllProjects: allProjects,
 	}, nil
 }
 
 // updateOverview generates an overview of the issue and its comments, split
-// into "old" and "new" groups by lastReadComment.
-func (g *Gaby) updateOverview(ctx context.Context, iss *github.Issue, lastReadComment int64) (*overviewResult, error) {
-	overview, err := g.overview.ForIssueUpdate(ctx, iss, lastReadComment)
+// into "old" and "new" groups by lastReadComment, using ov.
+func (g *Gaby) updateOverview(ctx context.Context, ov *overview.Client, iss *github.Issue, lastReadComment int64) (*overviewResult, error) {
+	overview, err := ov.ForIssueUpdate(ctx, iss, lastReadComment)
 	if err != nil {
 		return nil, err
 	}
@@ -335,6 +494,46 @@ func (g *Gaby) updateOverview(ctx context.Context, iss *github.Issue, lastReadCo
 	}, nil
 }
 
+// prOverview generates an overview of the pull request, its comments
+// and its review threads using ov.
+func (g *Gaby) prOverview(ctx context.Context, ov *overview.Client, iss *github.Issue) (*overviewResult, error) {
+	overview, err := ov.ForPullRequest(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	return &overviewResult{
+		Raw:   overview.Overview,
+		Issue: iss,
+		Typed: overview,
+		Type:  prOverviewType,
+		Desc:  fmt.Sprintf("pull request %d, its %d comments and %d review threads", iss.Number, overview.TotalComments, overview.ReviewThreads),
+	}, nil
+}
+
+// prDiffOverview generates a summary of the pull request's diff for
+// reviewers using ov. If post is set, it also adds an action to the
+// action log to post the summary on the pull request.
+func (g *Gaby) prDiffOverview(ctx context.Context, ov *overview.Client, iss *github.Issue, post bool) (*overviewResult, error) {
+	r, err := ov.ForPullRequestDiff(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	return &overviewResult{
+		Raw:    r.Summary,
+		Issue:  iss,
+		Typed:  r,
+		Type:   prDiffOverviewType,
+		Desc:   fmt.Sprintf("the diff of pull request %d (%d files, +%d -%d), for reviewers", iss.Number, r.Files, r.Additions, r.Deletions),
+		Posted: post && ov.PostDiffSummary(iss, r),
+	}, nil
+}
+
+// History returns the relative URL of the overview history
+// for the issue. This is used in the overview page template.
+func (r *overviewResult) History() string {
+	return fmt.Sprintf("/%s?q=%s", overviewHistoryID, r.Issue.HTMLURL)
+}
+
 // Related returns the relative URL of the related-entity search
 // for the issue. This is used in the overview page template.
 func (r *overviewResult) Related() string {
@@ -344,8 +543,11 @@ func (r *overviewResult) Related() string {
 // TotalComments returns the total number of comments for the
 // analyzed issue, or 0 if not known.
 func (r *overviewResult) TotalComments() int {
-	if ir, ok := r.Typed.(*overview.IssueResult); ok {
-		return ir.TotalComments
+	switch t := r.Typed.(type) {
+	case *overview.IssueResult:
+		return t.TotalComments
+	case *overview.PullRequestResult:
+		return t.TotalComments
 	}
 	return 0
 }
@@ -353,45 +555,92 @@ func (r *overviewResult) TotalComments() int {
 // Display returns the overview result as safe HTML.
 func (r *overviewResult) Display() safehtml.HTML {
 	switch r.Type {
-	case issueOverviewType, updateOverviewType:
+	case issueOverviewType, updateOverviewType, prOverviewType, prDiffOverviewType, checklistOverviewType:
 		md := r.Raw.Response
 		md = fixMarkdown(md)
 		return htmlutil.MarkdownToSafeHTML(md)
 	case relatedOverviewType:
-		return displayRelated(r.Typed.(*search.Analysis))
+		return displayRelated(r.Typed.(*search.Analysis), r.AllProjects)
 	}
 	return safehtml.HTML{}
 }
 
-// Template for converting a [search.Analysis] to Markdown.
+// Template for converting a [relatedData] to Markdown.
 const relatedMD = `
 ## Original Post
 
-{{.Output.Summary}}
+{{.Summary}}
 
 ## Related Documents
-{{range .Output.Related}}
-### {{.Title}} ([{{.URL}}]({{.URL}}))
+{{range .Groups}}{{with .Source}}
+### From {{.}}
+{{end}}{{range .Docs}}
+{{$.DocHeading}} {{.Title}} ([{{.URL}}]({{.URL}}))
 
 * **Summary**: {{.Summary}}
-* **Relationship**: {{.Relationship}}
+* **Why related**: {{.Relationship}}
 * **Relevance**: {{.Relevance}}
 * **Relevance reason**: {{.RelevanceReason}}
-{{end}}
+{{end}}{{end}}
 
 `
 
 var relatedMDTmpl = template.Must(template.New("relatedMD").Parse(relatedMD))
 
+// relatedData is the data used to execute [relatedMDTmpl].
+type relatedData struct {
+	Summary    string
+	Groups     []relatedGroup
+	DocHeading string // Markdown heading for each document
+}
+
+// A relatedGroup is a group of related documents from the same source.
+type relatedGroup struct {
+	Source string // empty if the documents are not grouped
+	Docs   []llmapp.RelatedDoc
+}
+
+// groupRelated groups the related documents by source (see [search.Source]),
+// in order of each source's first appearance.
+// Documents with no recognized source are grouped under "other".
+func groupRelated(docs []llmapp.RelatedDoc) []relatedGroup {
+	var groups []relatedGroup
+	index := make(map[string]int)
+	for _, d := range docs {
+		src := search.Source(d.URL)
+		if src == "" {
+			src = "other"
+		}
+		i, ok := index[src]
+		if !ok {
+			i = len(groups)
+			index[src] = i
+			groups = append(groups, relatedGroup{Source: src})
+		}
+		groups[i].Docs = append(groups[i].Docs, d)
+	}
+	return groups
+}
+
 // displayRelated returns the result of a related documents
 // analysis as safe HTML.
-func displayRelated(a *search.Analysis) safehtml.HTML {
+// If grouped is set, the related documents are grouped by source.
+func displayRelated(a *search.Analysis, grouped bool) safehtml.HTML {
+	data := &relatedData{
+		Summary:    a.Output.Summary,
+		Groups:     []relatedGroup{{Docs: a.Output.Related}},
+		DocHeading: "###",
+	}
+	if grouped {
+		data.Groups = groupRelated(a.Output.Related)
+		data.DocHeading = "####"
+	}
 	// Convert to Markdown, then HTML.
 	// We could instead convert directly to HTML, but Markdown is easier
 	// to work with, and we will likely publish these summaries to GitHub,
 	// which uses Markdown.
 	var buf bytes.Buffer
-	if err := relatedMDTmpl.Execute(&buf, a); err != nil {
+	if err := relatedMDTmpl.Execute(&buf, data); err != nil {
 		panic(err)
 	}
 	return htmlutil.MarkdownToSafeHTML(buf.String())
@@ -57,6 +57,16 @@ func TestPopulateOverviewPage(t *testing.T) {
 		Title:  "hello 2",
 		Body:   "hello world 2",
 	}
+	pr := &github.Issue{
+		Number:      3,
+		Title:       "fix hello",
+		Body:        "fixes hello",
+		PullRequest: new(struct{}),
+	}
+	review := &github.ReviewComment{
+		Path: "hello.go",
+		Body: "a review comment",
+	}
 	comment := &github.IssueComment{
 		Body: "a comment",
 	}
@@ -75,6 +85,10 @@ func TestPopulateOverviewPage(t *testing.T) {
 	ctx := context.Background()
 	docs.Sync(g.docs, g.github)
 	embeddocs.Sync(ctx, g.slog, g.vector, g.embed, g.docs)
+	// Add the pull request after embedding, so that it is
+	// not a related document of iss1.
+	g.github.Testing().AddIssue(project, pr)
+	g.github.Testing().AddReviewComment(project, 3, review)
 
 	// Generate expected overviews.
 	// This only tests that the correct calls are made; the internals
@@ -83,11 +97,30 @@ func TestPopulateOverviewPage(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	wantShortResult, err := g.overview.WithLength(llmapp.Short).ForIssue(ctx, iss1)
+	if err != nil {
+		t.Fatal(err)
+	}
 	wantUpdateResult, err := g.overview.ForIssueUpdate(ctx, iss1, comment.CommentID())
 	if err != nil {
 		t.Fatal(err)
 	}
-	wantRelatedResult, err := search.Analyze(ctx, g.llmapp, g.vector, g.docs, iss1.HTMLURL)
+	wantPRResult, err := g.overview.ForPullRequest(ctx, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gh.Testing().SetPullRequestFiles(pr, []*github.PullRequestFile{
+		{Filename: "hello.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -1 +1 @@\n-hello\n+hello, world"},
+	})
+	wantDiffResult, err := g.overview.ForPullRequestDiff(ctx, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRelatedResult, err := search.Analyze(ctx, g.llmapp, g.vector, g.docs, iss1.HTMLURL, &search.AnalyzeOptions{Sources: []string{project}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAllRelatedResult, err := search.Analyze(ctx, g.llmapp, g.vector, g.docs, iss1.HTMLURL, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -153,6 +186,48 @@ func TestPopulateOverviewPage(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "issue overview (short)",
+			r: &http.Request{
+				Form: map[string][]string{
+					paramQuery:  {"1"},
+					paramLength: {"short"},
+				},
+			},
+			want: &overviewPage{
+				Params: overviewParams{
+					Query:  "1",
+					Length: "short",
+				},
+				Result: &overviewResult{
+					Raw: wantShortResult.Overview,
+					Typed: &overview.IssueResult{
+						TotalComments: 2,
+						LastComment:   comment2.CommentID(),
+						Overview:      wantShortResult.Overview,
+					},
+					Issue: iss1,
+					Type:  issueOverviewType,
+					Desc:  "issue 1 and all 2 comments",
+				},
+			},
+		},
+		{
+			name: "error/unknownLength",
+			r: &http.Request{
+				Form: map[string][]string{
+					paramQuery:  {"1"},
+					paramLength: {"tiny"},
+				},
+			},
+			want: &overviewPage{
+				Params: overviewParams{
+					Query:  "1",
+					Length: "tiny",
+				},
+				Error: cmpopts.AnyError,
+			},
+		},
 		{
 			name: "related overview",
 			r: &http.Request{
@@ -177,6 +252,33 @@ func TestPopulateOverviewPage(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "related overview (all projects)",
+			r: &http.Request{
+				Form: map[string][]string{
+					"q":   {"1"},
+					"t":   {relatedOverviewType},
+					"all": {"true"},
+				},
+			},
+			want: &overviewPage{
+				Params: overviewParams{
+					Query:        "1",
+					OverviewType: relatedOverviewType,
+					AllProjects:  true,
+				},
+				Result: &overviewResult{
+					Raw: &wantAllRelatedResult.Result,
+					Typed: &search.Analysis{
+						RelatedAnalysis: wantAllRelatedResult.RelatedAnalysis,
+					},
+					Issue:       iss1,
+					Type:        relatedOverviewType,
+					Desc:        "issue 1 and 1 related docs from all projects",
+					AllProjects: true,
+				},
+			},
+		},
 		{
 			name: "update overview",
 			r: &http.Request{
@@ -206,17 +308,106 @@ func TestPopulateOverviewPage(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "pull request overview",
+			r: &http.Request{
+				Form: map[string][]string{
+					paramQuery:        {"3"},
+					paramOverviewType: {prOverviewType},
+				},
+			},
+			want: &overviewPage{
+				Params: overviewParams{
+					Query:        "3",
+					OverviewType: prOverviewType,
+				},
+				Result: &overviewResult{
+					Raw: wantPRResult.Overview,
+					Typed: &overview.PullRequestResult{
+						ReviewThreads:  1,
+						ReviewComments: 1,
+						Overview:       wantPRResult.Overview,
+					},
+					Issue: pr,
+					Type:  prOverviewType,
+					Desc:  "pull request 3, its 0 comments and 1 review threads",
+				},
+			},
+		},
+		{
+			name: "pull request diff",
+			r: &http.Request{
+				Form: map[string][]string{
+					paramQuery:        {"3"},
+					paramOverviewType: {prDiffOverviewType},
+				},
+			},
+			want: &overviewPage{
+				Params: overviewParams{
+					Query:        "3",
+					OverviewType: prDiffOverviewType,
+				},
+				Result: &overviewResult{
+					Raw:   wantDiffResult.Summary,
+					Typed: wantDiffResult,
+					Issue: pr,
+					Type:  prDiffOverviewType,
+					Desc:  "the diff of pull request 3 (1 files, +1 -1), for reviewers",
+				},
+			},
+		},
+		{
+			name: "pull request diff/post",
+			r: &http.Request{
+				Form: map[string][]string{
+					paramQuery:        {"3"},
+					paramOverviewType: {prDiffOverviewType},
+					paramPost:         {"on"},
+				},
+			},
+			want: &overviewPage{
+				Params: overviewParams{
+					Query:        "3",
+					OverviewType: prDiffOverviewType,
+					Post:         true,
+				},
+				Result: &overviewResult{
+					Raw:    wantDiffResult.Summary,
+					Typed:  wantDiffResult,
+					Issue:  pr,
+					Type:   prDiffOverviewType,
+					Desc:   "the diff of pull request 3 (1 files, +1 -1), for reviewers",
+					Posted: true,
+				},
+			},
+		},
+		{
+			name: "error/notPullRequest",
+			r: &http.Request{
+				Form: map[string][]string{
+					paramQuery:        {"1"},
+					paramOverviewType: {prOverviewType},
+				},
+			},
+			want: &overviewPage{
+				Params: overviewParams{
+					Query:        "1",
+					OverviewType: prOverviewType,
+				},
+				Error: cmpopts.AnyError,
+			},
+		},
 		{
 			name: "error/unknownIssue",
 			r: &http.Request{
 				Form: map[string][]string{
-					"q": {"3"}, // not in DB
+					"q": {"4"}, // not in DB
 					"t": {relatedOverviewType},
 				},
 			},
 			want: &overviewPage{
 				Params: overviewParams{
-					Query:        "3",
+					Query:        "4",
 					OverviewType: relatedOverviewType,
 				},
 				Error: cmpopts.AnyError,
@@ -253,6 +444,24 @@ func TestPopulateOverviewPage(t *testing.T) {
 
 }
 
+func TestGroupRelated(t *testing.T) {
+	docs := []llmapp.RelatedDoc{
+		{Title: "a", URL: "https://github.com/golang/go/issues/1"},
+		{Title: "b", URL: "https://go.dev/doc/x"},
+		{Title: "c", URL: "https://github.com/golang/go/issues/2"},
+		{Title: "d"},
+	}
+	got := groupRelated(docs)
+	want := []relatedGroup{
+		{Source: "golang/go", Docs: []llmapp.RelatedDoc{docs[0], docs[2]}},
+		{Source: "go.dev", Docs: []llmapp.RelatedDoc{docs[1]}},
+		{Source: "other", Docs: []llmapp.RelatedDoc{docs[3]}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("groupRelated() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 var safeHTMLcmpopt = cmpopts.EquateComparable(safehtml.TrustedResourceURL{}, safehtml.Identifier{})
 
 func TestParseOverviewPageQuery(t *testing.T) {
@@ -323,3 +532,26 @@ func TestParseOverviewPageQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestParseIssueComment(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "6789", want: 6789},
+		{in: " 6789 ", want: 6789},
+		{in: "https://github.com/golang/go/issues/12345#issuecomment-6789", want: 6789},
+		{in: "github.com/golang/go/issues/12345#issuecomment-6789", want: 6789},
+		{in: "https://github.com/golang/go/issues/12345", wantErr: true},
+		{in: "x6789", wantErr: true},
+	} {
+		got, err := parseIssueComment(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("parseIssueComment(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("parseIssueComment(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/related"
+)
+
+// handleOverviewBatchAPI submits a batch that generates the overviews
+// of existing open issues of a project at batch prices
+// (see [overview.Client.PrepareBatch]).
+// The form values are the project and the ranges of issue numbers
+// and creation dates, as for [Gaby.handleRelatedBackfillAPI].
+//
+// Once the batch is done, which the cron job checks
+// (see [Gaby.pollOverviewBatches]), the overview poster finds the
+// overviews in the LLM cache instead of generating them one by one.
+// The response is a JSON [llmapp.BatchJob], which has no ID
+// if every overview was already cached.
+func (g *Gaby) handleOverviewBatchAPI(w http.ResponseWriter, r *http.Request) {
+	b, err := parseBackfill(r)
+	if err != nil {
+		writeAPIError(w, codeInvalidQuery, err)
+		return
+	}
+	if !slices.Contains(g.githubProjects, b.Project) {
+		writeAPIError(w, codeUnknownProject, fmt.Errorf("unknown project %q", b.Project))
+		return
+	}
+	job, err := g.submitOverviewBatch(r.Context(), b)
+	if err != nil {
+		writeAPIError(w, codeInternal, err)
+		return
+	}
+	writeBatchJob(w, job)
+}
+
+// handleOverviewBatchStatusAPI reports the progress of the batch job
+// with the "id" form value, first storing its results in the LLM cache
+// if it has finished. The response is a JSON [llmapp.BatchJob].
+func (g *Gaby) handleOverviewBatchStatusAPI(w http.ResponseWriter, r *http.Request) {
+	id := r.FormValue("id")
+	if id == "" {
+		writeAPIError(w, codeInvalidQuery, errors.New("missing id"))
+		return
+	}
+	if _, ok := g.llmapp.BatchJob(id); !ok {
+		writeAPIError(w, codeInvalidQuery, fmt.Errorf("unknown batch job %q", id))
+		return
+	}
+	job, err := g.llmapp.PollBatch(r.Context(), id)
+	if err != nil {
+		writeAPIError(w, codeInternal, err)
+		return
+	}
+	writeBatchJob(w, job)
+}
+
+// submitOverviewBatch submits a batch generating the overviews
+// of the open issues selected by b that are not already cached.
+// It returns an empty job if there is nothing to generate.
+func (g *Gaby) submitOverviewBatch(ctx context.Context, b *related.Backfill) (*llmapp.BatchJob, error) {
+	maxIssue := b.MaxIssue
+	if maxIssue == 0 {
+		maxIssue = -1
+	}
+	issues := func(yield func(*github.Issue) bool) {
+		for iss := range github.LookupIssues(g.db, b.Project, b.MinIssue, maxIssue) {
+			if iss.State != "open" || iss.PullRequest != nil {
+				continue
+			}
+			tm, err := time.Parse(time.RFC3339, iss.CreatedAt)
+			if err != nil || !b.After.IsZero() && tm.Before(b.After) || !b.Before.IsZero() && !tm.Before(b.Before) {
+				continue
+			}
+			if !yield(iss) {
+				return
+			}
+		}
+	}
+	batch, err := g.overview.PrepareBatch(ctx, issues)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Len() == 0 {
+		return &llmapp.BatchJob{State: llm.BatchDone}, nil
+	}
+	return batch.Submit(ctx)
+}
+
+// pollOverviewBatches checks on the running batch jobs, storing
+// the results of finished ones in the LLM cache, so that the
+// overview poster, which runs next, can use them.
+func (g *Gaby) pollOverviewBatches(ctx context.Context) error {
+	var errs []error
+	for job := range g.llmapp.BatchJobs() {
+		if job.State != llm.BatchRunning {
+			continue
+		}
+		if _, err := g.llmapp.PollBatch(ctx, job.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeBatchJob writes job to w as JSON.
+func writeBatchJob(w http.ResponseWriter, job *llmapp.BatchJob) {
+	// The cache keys are internal and can be long.
+	j := *job
+	j.CacheKeys = nil
+	data, err := json.Marshal(&j)
+	if err != nil {
+		writeAPIError(w, codeInternal, fmt.Errorf("json.Marshal: %w", err))
+		return
+	}
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/overview"
+)
+
+func TestOverviewBatchAPI(t *testing.T) {
+	g := newTestGaby(t)
+	project := "hello/world"
+	g.githubProjects = []string{project}
+	g.github.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "hello", Body: "hello world", State: "open", CreatedAt: "2024-01-02T00:00:00Z"})
+	g.github.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "closed", Body: "goodbye", State: "closed", CreatedAt: "2024-01-02T00:00:00Z"})
+	calls := 0
+	gen := llm.TestContentGenerator("overview-batch-test",
+		func(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+			calls++
+			return llm.EchoJSONResponse(parts...), nil
+		})
+	g.llmapp = llmapp.New(g.slog, gen, g.db)
+	g.llmapp.SetBatchGenerator(llm.TestBatchContentGenerator(gen))
+	g.overview = overview.New(g.slog, g.db, g.github, g.llmapp, "test", "test-bot")
+
+	call := func(method, query string) *llmapp.BatchJob {
+		t.Helper()
+		r := httptest.NewRequest(method, "/api/overviewbatch?"+query, nil)
+		w := httptest.NewRecorder()
+		if method == "POST" {
+			g.handleOverviewBatchAPI(w, r)
+		} else {
+			g.handleOverviewBatchStatusAPI(w, r)
+		}
+		if w.Code != 200 {
+			t.Fatalf("%s %s: %d %s", method, query, w.Code, w.Body)
+		}
+		var job llmapp.BatchJob
+		if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+		return &job
+	}
+
+	job := call("POST", "project="+project)
+	if job.State != llm.BatchRunning || job.Requests != 1 || calls != 0 {
+		t.Fatalf("submit: %+v, %d calls; want 1 running request and no calls", job, calls)
+	}
+	if err := g.pollOverviewBatches(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if job := call("GET", "id="+job.ID); job.State != llm.BatchDone || job.Succeeded != 1 {
+		t.Fatalf("status: %+v, want done", job)
+	}
+	calls = 0
+	iss, err := github.LookupIssue(g.db, project, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := g.overview.ForIssue(context.Background(), iss); err != nil || !r.Overview.Cached || calls != 0 {
+		t.Errorf("ForIssue after batch: %v, %d calls; want cached", err, calls)
+	}
+
+	// Everything is cached now.
+	if job := call("POST", "project="+project); job.ID != "" || job.Requests != 0 {
+		t.Errorf("second submit: %+v, want no batch", job)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"iter"
+	"net/http"
+	"slices"
+
+	"github.com/google/safehtml"
+	"golang.org/x/oscar/internal/diff"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/htmlutil"
+	"golang.org/x/oscar/internal/overview"
+)
+
+// overviewHistoryPage holds the fields needed to display
+// the history of overviews generated for an issue.
+type overviewHistoryPage struct {
+	CommonPage
+
+	Params  overviewHistoryParams // the raw parameters
+	Issue   *github.Issue         // the issue
+	Entries []*historyEntry       // history entries, newest first
+	Error   error                 // if non-nil, the error to display instead of the result
+}
+
+// overviewHistoryParams holds the raw inputs to the overview history form.
+type overviewHistoryParams struct {
+	Query string // the issue ID to lookup
+}
+
+// A historyEntry is an [overview.HistoryEntry] prepared for display.
+type historyEntry struct {
+	*overview.HistoryEntry
+	HTML safehtml.HTML // the overview text, as HTML
+	Diff string        // unified diff from the previous entry of the same type ("" if none)
+}
+
+var overviewHistoryPageTmpl = newTemplate(overviewHistoryTmplFile, nil)
+
+func (g *Gaby) handleOverviewHistory(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateOverviewHistoryPage(r), overviewHistoryPageTmpl)
+}
+
+// populateOverviewHistoryPage returns the contents of the overview history page.
+func (g *Gaby) populateOverviewHistoryPage(r *http.Request) *overviewHistoryPage {
+	p := &overviewHistoryPage{
+		Params: overviewHistoryParams{
+			Query: r.FormValue(paramQuery),
+		},
+	}
+	p.setCommonPage()
+	if trim(p.Params.Query) == "" {
+		return p
+	}
+	iss, err := g.lookupIssue(p.Params.Query)
+	if err != nil {
+		p.Error = err
+		return p
+	}
+	p.Issue = iss
+	p.Entries = historyEntries(g.overview.History(iss.Project(), iss.Number))
+	return p
+}
+
+// historyEntries converts the history entries (oldest first)
+// to display form (newest first), computing the diff between
+// each entry and the previous entry of the same type.
+func historyEntries(hist iter.Seq[*overview.HistoryEntry]) []*historyEntry {
+	var entries []*historyEntry
+	prev := make(map[string]*overview.HistoryEntry)
+	for h := range hist {
+		e := &historyEntry{
+			HistoryEntry: h,
+			HTML:         htmlutil.MarkdownToSafeHTML(fixMarkdown(h.Text)),
+		}
+		if old := prev[h.Type]; old != nil {
+			e.Diff = string(diff.Diff("previous", []byte(old.Text), "current", []byte(h.Text)))
+		}
+		prev[h.Type] = h
+		entries = append(entries, e)
+	}
+	slices.Reverse(entries)
+	return entries
+}
+
+func (p *overviewHistoryPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          overviewHistoryID,
+		Description: "Browse the AI-generated overviews of a golang/go issue over time, and how they changed.",
+		Styles:      []safeURL{searchID.CSS()},
+		Form: Form{
+			Inputs:     p.Params.inputs(),
+			SubmitText: "show history",
+		},
+	}
+}
+
+func (pm *overviewHistoryParams) inputs() []FormInput {
+	return []FormInput{
+		{
+			Label:       "issue",
+			Type:        "int or string",
+			Description: "the issue, as a number or URL (e.g. 1234, golang/go#1234, or https://github.com/golang/go/issues/1234)",
+			Name:        safeQuery,
+			Required:    true,
+			Typed: TextInput{
+				ID:    safeQuery,
+				Value: pm.Query,
+			},
+		},
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestPopulateOverviewHistoryPage(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, secret.Empty(), nil)
+	lc := llmapp.New(lg, llm.EchoContentGenerator(), db)
+	g := &Gaby{
+		slog:     lg,
+		db:       db,
+		github:   gh,
+		overview: overview.New(lg, db, gh, lc, "test", "test-bot"),
+	}
+	project := "hello/world"
+	g.githubProjects = []string{project}
+
+	iss := &github.Issue{Number: 1, Title: "hello"}
+	gh.Testing().AddIssue(project, iss)
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "first"})
+
+	ctx := context.Background()
+	if _, err := g.overview.ForIssue(ctx, iss); err != nil {
+		t.Fatal(err)
+	}
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "second"})
+	if _, err := g.overview.ForIssue(ctx, iss); err != nil {
+		t.Fatal(err)
+	}
+
+	p := g.populateOverviewHistoryPage(&http.Request{Form: map[string][]string{"q": {"1"}}})
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if len(p.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(p.Entries))
+	}
+	// Newest first; only the newest has a diff.
+	if !strings.Contains(p.Entries[0].Diff, "+") || !strings.Contains(p.Entries[0].Text, "second") {
+		t.Errorf("newest entry: Diff=%q Text=%q, want diff adding second comment", p.Entries[0].Diff, p.Entries[0].Text)
+	}
+	if p.Entries[1].Diff != "" {
+		t.Errorf("oldest entry has diff %q, want none", p.Entries[1].Diff)
+	}
+
+	p = g.populateOverviewHistoryPage(&http.Request{Form: map[string][]string{"q": {"other/project#1"}}})
+	if p.Error == nil {
+		t.Error("no error for unknown project")
+	}
+}
@@ -8,32 +8,56 @@ package main
 // Pages listed here will appear in navigation.
 var pages = []pageID{
 	// Dev pages.
-	actionlogID, dbviewID, bisectlogID,
+	actionlogID, dbviewID, bisectlogID, divertedEditsID, llmCacheID, llmCostID, suppressID, pinsID, apiTokensID, watchersID, evalsID,
 	// User pages.
-	overviewID, searchID, rulesID, labelsID,
+	overviewID, overviewHistoryID, searchID, rulesID, labelsID, feedbackID, workloadID, weeklyID,
 	// reviews omitted for now, as it loads very slowly
 }
 
 // Gaby webpage endpoints.
 const (
-	actionlogID pageID = "actionlog"
-	overviewID  pageID = "overview"
-	searchID    pageID = "search"
-	dbviewID    pageID = "dbview"
-	rulesID     pageID = "rules"
-	labelsID    pageID = "labels"
-	reviewsID   pageID = "reviews"
-	bisectlogID pageID = "bisectlog"
+	actionlogID       pageID = "actionlog"
+	overviewID        pageID = "overview"
+	overviewHistoryID pageID = "overviewhistory"
+	searchID          pageID = "search"
+	dbviewID          pageID = "dbview"
+	rulesID           pageID = "rules"
+	labelsID          pageID = "labels"
+	reviewsID         pageID = "reviews"
+	bisectlogID       pageID = "bisectlog"
+	divertedEditsID   pageID = "divertededits"
+	feedbackID        pageID = "feedback"
+	workloadID        pageID = "workload"
+	weeklyID          pageID = "weekly"
+	llmCacheID        pageID = "llmcache"
+	llmCostID         pageID = "llmcost"
+	suppressID        pageID = "suppress"
+	evalsID           pageID = "evals"
+	pinsID            pageID = "pins"
+	apiTokensID       pageID = "apitokens"
+	watchersID        pageID = "watchers"
 )
 
 // Gaby webpage titles.
 var titles = map[pageID]string{
-	actionlogID: "Action Log",
-	overviewID:  "Overviews",
-	searchID:    "Search",
-	dbviewID:    "Database Viewer",
-	rulesID:     "Rule Checker",
-	reviewsID:   "Reviews",
-	labelsID:    "Issue Labels",
-	bisectlogID: "Bisect Log",
+	actionlogID:       "Action Log",
+	overviewID:        "Overviews",
+	overviewHistoryID: "Overview History",
+	searchID:          "Search",
+	dbviewID:          "Database Viewer",
+	rulesID:           "Rule Checker",
+	reviewsID:         "Reviews",
+	labelsID:          "Issue Labels",
+	bisectlogID:       "Bisect Log",
+	divertedEditsID:   "Diverted Edits",
+	feedbackID:        "Feedback",
+	workloadID:        "Maintainer Workload",
+	weeklyID:          "Weekly Digest",
+	llmCacheID:        "LLM Cache",
+	llmCostID:         "LLM Cost",
+	suppressID:        "Suppressed Documents",
+	evalsID:           "Evaluations",
+	pinsID:            "Pinned Documents",
+	apiTokensID:       "API Tokens",
+	watchersID:        "Watchers",
 }
@@ -0,0 +1,188 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/search"
+)
+
+// pinsPage holds the fields needed to display the documents
+// pinned to search topics.
+type pinsPage struct {
+	CommonPage
+
+	Params pinsParams    // the raw parameters
+	Error  error         // if non-nil, the error from the requested action
+	Done   string        // description of the completed action, if any
+	Pins   []*search.Pin // the pinned documents, ordered by topic
+}
+
+// pinsParams holds the raw inputs to the pins form.
+type pinsParams struct {
+	Topic    string // the topic, such as "generic type aliases"
+	ID       string // ID (URL) of the document
+	Action   string // actionPin or actionUnpin
+	MinScore string // lowest similarity of a matching search (float64 in [0, 1])
+	Reason   string // why the document is pinned
+}
+
+const (
+	paramPinTopic    = "topic"
+	paramPinID       = "id"
+	paramPinAction   = "action"
+	paramPinMinScore = "min_score"
+	paramPinReason   = "reason"
+
+	actionPin   = "pin"
+	actionUnpin = "unpin"
+)
+
+var (
+	safePinTopic    = toSafeID(paramPinTopic)
+	safePinID       = toSafeID(paramPinID)
+	safePinMinScore = toSafeID(paramPinMinScore)
+	safePinReason   = toSafeID(paramPinReason)
+)
+
+var pinsPageTmpl = newTemplate(pinsTmplFile, nil)
+
+func (g *Gaby) handlePins(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populatePinsPage(r), pinsPageTmpl)
+}
+
+// populatePinsPage returns the contents of the pins page,
+// after pinning or unpinning the document in the "id" form
+// value to the "topic" form value, if any, as the "action"
+// form value says.
+func (g *Gaby) populatePinsPage(r *http.Request) *pinsPage {
+	p := &pinsPage{
+		Params: pinsParams{
+			Topic:    strings.TrimSpace(r.FormValue(paramPinTopic)),
+			ID:       strings.TrimSpace(r.FormValue(paramPinID)),
+			Action:   r.FormValue(paramPinAction),
+			MinScore: strings.TrimSpace(r.FormValue(paramPinMinScore)),
+			Reason:   strings.TrimSpace(r.FormValue(paramPinReason)),
+		},
+	}
+	if p.Params.Topic != "" || p.Params.ID != "" {
+		p.Error = g.pin(r.Context(), p.Params, requestUser(r))
+		if p.Error == nil {
+			p.Done = fmt.Sprintf("%sned %s to %q", p.Params.Action, p.Params.ID, p.Params.Topic)
+		}
+	}
+	p.Pins = g.pins.List()
+	p.setCommonPage()
+	return p
+}
+
+// pin performs the action in pm on behalf of user.
+func (g *Gaby) pin(ctx context.Context, pm pinsParams, user string) error {
+	switch pm.Action {
+	case actionPin:
+		if pm.Reason == "" {
+			return errors.New("a reason is required")
+		}
+		var minScore float64
+		if pm.MinScore != "" {
+			var err error
+			if minScore, err = strconv.ParseFloat(pm.MinScore, 64); err != nil {
+				return fmt.Errorf("invalid minimum score: %w", err)
+			}
+		}
+		vecs, err := g.embed.EmbedDocs(ctx, []llm.EmbedDoc{{Text: pm.Topic}})
+		if err != nil {
+			return fmt.Errorf("embedding topic: %w", llmError(err))
+		}
+		return g.pins.Pin(pm.Topic, vecs[0], minScore, pm.ID, user, pm.Reason)
+	case actionUnpin:
+		return g.pins.Unpin(pm.Topic, pm.ID, user)
+	}
+	return errors.New("unknown action " + pm.Action)
+}
+
+func (p *pinsPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          pinsID,
+		Description: "Pin authoritative documents, such as FAQ entries and umbrella issues, to topics. Searches that match a topic show its pinned documents first, regardless of their score.",
+		Form: Form{
+			Inputs: []FormInput{
+				{
+					Label:       "topic",
+					Type:        "string",
+					Description: `a short description of the topic (e.g. "generic type aliases"); searches similar to it show the document first`,
+					Name:        safePinTopic,
+					Required:    true,
+					Typed: TextInput{
+						ID:    safePinTopic,
+						Value: p.Params.Topic,
+					},
+				},
+				{
+					Label:       "document",
+					Type:        "string",
+					Description: "the ID of the document, usually its URL (e.g. https://go.dev/doc/faq)",
+					Name:        safePinID,
+					Required:    true,
+					Typed: TextInput{
+						ID:    safePinID,
+						Value: p.Params.ID,
+					},
+				},
+				{
+					Label:       "action",
+					Type:        "radio choice",
+					Description: `"pin" pins the document to the topic; "unpin" removes the pin`,
+					Name:        toSafeID(paramPinAction),
+					Required:    true,
+					Typed: RadioInput{
+						Choices: []RadioChoice{
+							{
+								Label:   actionPin,
+								ID:      toSafeID(actionPin),
+								Value:   actionPin,
+								Checked: p.Params.Action != actionUnpin,
+							},
+							{
+								Label:   actionUnpin,
+								ID:      toSafeID(actionUnpin),
+								Value:   actionUnpin,
+								Checked: p.Params.Action == actionUnpin,
+							},
+						},
+					},
+				},
+				{
+					Label:       "minimum score",
+					Type:        "float64 between 0 and 1",
+					Description: fmt.Sprintf("how similar a search must be to the topic to match it (default: %.2f)", search.DefaultPinScore),
+					Name:        safePinMinScore,
+					Typed: TextInput{
+						ID:    safePinMinScore,
+						Value: p.Params.MinScore,
+					},
+				},
+				{
+					Label:       "reason",
+					Type:        "string",
+					Description: "why the document is pinned",
+					Name:        safePinReason,
+					Typed: TextInput{
+						ID:    safePinReason,
+						Value: p.Params.Reason,
+					},
+				},
+			},
+			SubmitText: "submit",
+		},
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestPinsPage(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	g := &Gaby{slog: lg, db: db, embed: llm.QuoteEmbedder(), pins: search.NewPins(lg, db)}
+
+	topic, id := "generic type aliases", "https://go.dev/doc/faq"
+	populate := func(action, minScore, reason string) *pinsPage {
+		t.Helper()
+		q := url.Values{
+			paramPinTopic:    {topic},
+			paramPinID:       {id},
+			paramPinAction:   {action},
+			paramPinMinScore: {minScore},
+			paramPinReason:   {reason},
+		}
+		r := httptest.NewRequest("GET", "/pins?"+q.Encode(), nil)
+		r.Header.Set("X-Goog-Authenticated-User-Email", "accounts.google.com:gopher@golang.org")
+		return g.populatePinsPage(r)
+	}
+
+	if p := populate(actionPin, "", ""); p.Error == nil {
+		t.Error("pin without reason succeeded, want error")
+	}
+	if p := populate(actionPin, "high", "FAQ entry"); p.Error == nil {
+		t.Error("pin with invalid minimum score succeeded, want error")
+	}
+	p := populate(actionPin, "0.9", "FAQ entry")
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if len(p.Pins) != 1 || p.Pins[0].User != "gopher@golang.org" || p.Pins[0].MinScore != 0.9 {
+		t.Errorf("Pins = %v, want one by gopher@golang.org with minimum score 0.9", p.Pins)
+	}
+	vec, err := g.embed.EmbedDocs(context.Background(), []llm.EmbedDoc{{Text: topic}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ms := g.pins.Match(vec[0]); len(ms) != 1 || ms[0].ID != id {
+		t.Errorf("Match(%q) = %v, want %s", topic, ms, id)
+	}
+
+	p = populate(actionUnpin, "", "")
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if len(p.Pins) != 0 {
+		t.Errorf("Pins = %v after unpin, want none", p.Pins)
+	}
+	if p := populate(actionUnpin, "", ""); !errors.Is(p.Error, search.ErrNotPinned) {
+		t.Errorf("unpin of unpinned document: got %v, want ErrNotPinned", p.Error)
+	}
+}
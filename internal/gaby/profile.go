@@ -0,0 +1,240 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// Profiling lets an administrator diagnose slow runs in production.
+// When the -pprof flag is set, gaby serves the standard
+// [net/http/pprof] endpoints under /debug/pprof/, and
+// the /profile endpoint runs a single job (such as the related poster)
+// while capturing a CPU profile, then captures a heap profile.
+// The profiles are stored in the database alongside a
+// report of the run, so they can be examined after the fact with
+//
+//	go tool pprof https://gaby.example.com/profile/ID/cpu
+//
+// Usage:
+//
+//	/profile?job=NAME: run job NAME under the profiler and display its report
+//	/profile: display the reports of recent profiled runs
+//	/profile/ID/KIND: download the profile of KIND (cpu or heap) for report ID
+
+// A profileReport describes a job run under the profiler.
+type profileReport struct {
+	ID       string        // unique ID of the run
+	Job      string        // name of the job (see [Gaby.profileJobs])
+	Start    time.Time     // start time of the run
+	Duration time.Duration // duration of the run
+	Error    string        // error returned by the job, if any
+	Profiles []string      // URL paths of the captured profiles
+}
+
+// profileKinds are the kinds of profiles captured for each run.
+var profileKinds = []string{"cpu", "heap"}
+
+// maxProfileReports is the number of reports displayed by /profile.
+const maxProfileReports = 50
+
+// profileChunkSize is the maximum size of a database value
+// holding part of a profile. Profiles can be larger than the
+// values some databases allow (1 MiB in Firestore),
+// so they are split into chunks.
+var profileChunkSize = 512 << 10
+
+// registerPprof registers the [net/http/pprof] handlers on mux.
+func registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+}
+
+// profileJobs returns the jobs that can be run under the profiler,
+// by name. Only the jobs enabled by gaby's flags are included.
+func (g *Gaby) profileJobs() map[string]func(context.Context) error {
+	jobs := make(map[string]func(context.Context) error)
+	if flags.enablesync {
+		jobs["github"] = g.syncGitHubIssues
+		jobs["embed"] = g.embedAll
+		jobs["searchdiff"] = g.diffSearch
+		jobs["dupmine"] = g.mineDuplicates
+	}
+	if flags.enablechanges {
+		jobs["commentfix"] = g.fixAllComments
+		jobs["related"] = g.postAllRelated
+		jobs["duplicate"] = g.postAllDuplicates
+		jobs["labels"] = g.labelAll
+		jobs["rules"] = g.postAllRules
+		jobs["overview"] = g.postAllOverviews
+		jobs["actions"] = func(context.Context) error { return g.runActions() }
+	}
+	return jobs
+}
+
+// handleProfile handles the /profile endpoint.
+func (g *Gaby) handleProfile(w http.ResponseWriter, r *http.Request) {
+	if !flags.pprof {
+		http.Error(w, "profile: flag -pprof not set", http.StatusForbidden)
+		return
+	}
+	name := r.FormValue("job")
+	if name == "" {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(storage.JSON(g.profileReports(maxProfileReports)))
+		return
+	}
+	job, ok := g.profileJobs()[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("profile: unknown or disabled job %q", name), http.StatusBadRequest)
+		return
+	}
+	rep, err := g.profileRun(r.Context(), name, job)
+	if err != nil {
+		http.Error(w, "profile: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(storage.JSON(rep))
+}
+
+// handleProfileData handles the /profile/{id}/{kind} endpoint.
+func (g *Gaby) handleProfileData(w http.ResponseWriter, r *http.Request) {
+	if !flags.pprof {
+		http.Error(w, "profile: flag -pprof not set", http.StatusForbidden)
+		return
+	}
+	data, ok := g.profileData(r.PathValue("id"), r.PathValue("kind"))
+	if !ok {
+		http.Error(w, "profile not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", r.PathValue("id")+"."+r.PathValue("kind")+".pprof"))
+	_, _ = w.Write(data)
+}
+
+// errProfiling is returned by [Gaby.profileRun] if another
+// CPU profile is already being captured.
+var errProfiling = errors.New("CPU profiling already in progress")
+
+// profileRun runs the named job while capturing a CPU profile,
+// then captures a heap profile.
+// It stores the profiles and a report of the run in the database,
+// and returns the report.
+// An error from the job itself is recorded in the report; profileRun
+// only returns an error if it could not capture the profiles.
+func (g *Gaby) profileRun(ctx context.Context, name string, job func(context.Context) error) (*profileReport, error) {
+	start := time.Now()
+	rep := &profileReport{
+		ID:    start.UTC().Format("20060102T150405.000") + "-" + name,
+		Job:   name,
+		Start: start,
+	}
+	var cpu bytes.Buffer
+	if err := rpprof.StartCPUProfile(&cpu); err != nil {
+		return nil, fmt.Errorf("%w: %v", errProfiling, err)
+	}
+	g.slog.Info("profile start", "id", rep.ID, "job", name)
+	err := job(ctx)
+	rpprof.StopCPUProfile()
+	rep.Duration = time.Since(start)
+	if err != nil {
+		rep.Error = err.Error()
+	}
+
+	// Collect garbage so the heap profile is up to date.
+	runtime.GC()
+	var heap bytes.Buffer
+	if err := rpprof.Lookup("heap").WriteTo(&heap, 0); err != nil {
+		return nil, err
+	}
+
+	data := map[string][]byte{"cpu": cpu.Bytes(), "heap": heap.Bytes()}
+	b := g.db.Batch()
+	for _, kind := range profileKinds {
+		setProfileData(b, rep.ID, kind, data[kind])
+		rep.Profiles = append(rep.Profiles, "/profile/"+rep.ID+"/"+kind)
+	}
+	// Write the report last, so that it is only listed
+	// once all its profiles are stored.
+	b.Set(profileReportKey(rep.ID), storage.JSON(rep))
+	b.Apply()
+	g.db.Flush()
+	g.slog.Info("profile end", "id", rep.ID, "job", name, "duration", rep.Duration, "error", rep.Error)
+	return rep, nil
+}
+
+// profileReports returns up to n reports of profiled runs, most recent first.
+func (g *Gaby) profileReports(n int) []*profileReport {
+	var reps []*profileReport
+	for _, getVal := range g.db.Scan(profileReportKey(""), ordered.Encode("gaby.ProfileReport", ordered.Inf)) {
+		var rep profileReport
+		if err := json.Unmarshal(getVal(), &rep); err != nil {
+			g.slog.Error("profile report", "err", err)
+			continue
+		}
+		reps = append(reps, &rep)
+	}
+	slices.Reverse(reps)
+	if len(reps) > n {
+		reps = reps[:n]
+	}
+	return reps
+}
+
+// profileReportKey returns the database key for the report with the given ID.
+func profileReportKey(id string) []byte {
+	return ordered.Encode("gaby.ProfileReport", id)
+}
+
+// setProfileData stores the profile of the given kind for the report
+// with the given ID using b, in chunks of at most profileChunkSize bytes.
+func setProfileData(b storage.Batch, id, kind string, data []byte) {
+	for i := 0; i == 0 || len(data) > 0; i++ {
+		n := min(len(data), profileChunkSize)
+		b.Set(profileDataKey(id, kind, i), data[:n])
+		b.MaybeApply()
+		data = data[n:]
+	}
+}
+
+// profileData returns the profile of the given kind for the report
+// with the given ID, reassembled from its chunks.
+func (g *Gaby) profileData(id, kind string) ([]byte, bool) {
+	var data []byte
+	found := false
+	kind = strings.ToLower(kind)
+	for _, getVal := range g.db.Scan(
+		ordered.Encode("gaby.ProfileData", id, kind),
+		ordered.Encode("gaby.ProfileData", id, kind, ordered.Inf)) {
+		data = append(data, getVal()...)
+		found = true
+	}
+	return data, found
+}
+
+// profileDataKey returns the database key for chunk i of the
+// profile of the given kind for the report with the given ID.
+func profileDataKey(id, kind string, i int) []byte {
+	return ordered.Encode("gaby.ProfileData", id, strings.ToLower(kind), i)
+}
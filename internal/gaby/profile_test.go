@@ -0,0 +1,121 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+	"rsc.io/ordered"
+)
+
+func TestProfileRun(t *testing.T) {
+	g := &Gaby{slog: testutil.Slogger(t), db: storage.MemDB()}
+	ctx := context.Background()
+
+	ran := false
+	rep, err := g.profileRun(ctx, "test", func(context.Context) error {
+		ran = true
+		return errors.New("job failed")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Error("job did not run")
+	}
+	if rep.Job != "test" || rep.Error != "job failed" || len(rep.Profiles) != 2 {
+		t.Errorf("report = %+v", rep)
+	}
+	for _, kind := range profileKinds {
+		if data, ok := g.profileData(rep.ID, kind); !ok || len(data) == 0 {
+			t.Errorf("no %s profile stored", kind)
+		}
+	}
+	if reps := g.profileReports(10); len(reps) != 1 || reps[0].ID != rep.ID {
+		t.Errorf("profileReports() = %v, want [%s]", reps, rep.ID)
+	}
+}
+
+func TestProfileChunks(t *testing.T) {
+	g := &Gaby{slog: testutil.Slogger(t), db: storage.MemDB()}
+	for _, size := range []int{0, 100, profileChunkSize, 3<<20 + 1} {
+		id := fmt.Sprint(size)
+		want := make([]byte, size)
+		for i := range want {
+			want[i] = byte(i % 251)
+		}
+		b := g.db.Batch()
+		setProfileData(b, id, "heap", want)
+		b.Apply()
+
+		got, ok := g.profileData(id, "heap")
+		if !ok || !bytes.Equal(got, want) {
+			t.Errorf("profile of %d bytes: got %d bytes, %v", size, len(got), ok)
+		}
+		for key, getVal := range g.db.Scan(ordered.Encode("gaby.ProfileData", id), ordered.Encode("gaby.ProfileData", id, ordered.Inf)) {
+			if n := len(getVal()); n > profileChunkSize {
+				t.Errorf("%s: %d bytes, want at most %d", storage.Fmt(key), n, profileChunkSize)
+			}
+		}
+	}
+	if _, ok := g.profileData("missing", "heap"); ok {
+		t.Errorf("profileData(missing) succeeded")
+	}
+}
+
+func TestHandleProfile(t *testing.T) {
+	g := &Gaby{slog: testutil.Slogger(t), db: storage.MemDB()}
+	serve := func(target string) *httptest.ResponseRecorder {
+		t.Helper()
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /profile", g.handleProfile)
+		mux.HandleFunc("GET /profile/{id}/{kind}", g.handleProfileData)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w
+	}
+
+	defer func(old gabyFlags) { flags = old }(flags)
+	flags.pprof = false
+	if w := serve("/profile"); w.Code != http.StatusForbidden {
+		t.Errorf("without -pprof: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	flags.pprof = true
+	flags.enablechanges = false
+	if w := serve("/profile?job=related"); w.Code != http.StatusBadRequest {
+		t.Errorf("disabled job: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	rep, err := g.profileRun(context.Background(), "test", func(context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := serve("/profile")
+	var reps []*profileReport
+	if err := json.Unmarshal(w.Body.Bytes(), &reps); err != nil {
+		t.Fatal(err)
+	}
+	if len(reps) != 1 || reps[0].ID != rep.ID {
+		t.Errorf("/profile = %s, want report %s", w.Body, rep.ID)
+	}
+	for _, p := range rep.Profiles {
+		if w := serve(p); w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Errorf("%s: status %d, %d bytes", p, w.Code, w.Body.Len())
+		}
+	}
+	if w := serve("/profile/missing/cpu"); w.Code != http.StatusNotFound {
+		t.Errorf("missing profile: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
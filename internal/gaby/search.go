@@ -7,14 +7,20 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/safehtml/template"
 	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
 	"golang.org/x/oscar/internal/search"
 )
 
@@ -26,6 +32,7 @@ type searchPage struct {
 	Params  searchParams    // the raw query parameters
 	Results []search.Result // the search results to display
 	Error   error           // if non-nil, the error to display instead of results
+	Next    string          // relative URL of the next page of results ("" if none)
 }
 
 func (g *Gaby) handleSearch(w http.ResponseWriter, r *http.Request) {
@@ -55,12 +62,15 @@ func (g *Gaby) populateSearchPage(r *http.Request) *searchPage {
 		return p
 	}
 	q := trim(pm.Query)
-	results, err := g.search(r.Context(), q, *opts)
+	page, err := g.searchPage(r.Context(), q, *opts)
 	if err != nil {
 		p.Error = fmt.Errorf("search: %w", err)
 		return p
 	}
-	p.Results = results
+	p.Results = page.Results
+	if page.Next != nil {
+		p.Next = pm.next(page.Next)
+	}
 	return p
 }
 
@@ -73,32 +83,65 @@ func (g *Gaby) populateSearchPage(r *http.Request) *searchPage {
 // search for the embedding.
 //
 // It returns an error if search fails.
-func (g *Gaby) search(ctx context.Context, q string, opts search.Options) (results []search.Result, err error) {
+func (g *Gaby) search(ctx context.Context, q string, opts search.Options) ([]search.Result, error) {
+	page, err := g.searchPage(ctx, q, opts)
+	if err != nil {
+		return nil, err
+	}
+	return page.Results, nil
+}
+
+// searchPage is like [Gaby.search], but it also reports
+// where the next page of results starts.
+func (g *Gaby) searchPage(ctx context.Context, q string, opts search.Options) (_ *search.Page, err error) {
+	page := &search.Page{}
 	if q == "" {
-		return nil, nil
+		return page, nil
 	}
+	opts.Info = search.GitHubInfo(g.github)
+	opts.Denylist = g.denylist
+	opts.Pins = g.pins
+	opts.Reranker = search.LLMReranker(g.featureLLMApp("search"))
 
 	if vec, ok := g.vector.Get(q); ok {
-		results = search.Vector(g.vector, g.docs,
+		page = search.VectorPage(g.vector, g.docs,
 			&search.VectorRequest{
 				Options: opts,
 				Vector:  vec,
 			})
+		if opts.Rerank {
+			query := &llmapp.Doc{Text: q}
+			if d, ok := g.docs.Get(q); ok {
+				query = &llmapp.Doc{URL: q, Title: d.Title, Text: d.Text}
+			}
+			if page.Results, err = search.Rerank(ctx, opts.Reranker, g.docs, query, page.Results); err != nil {
+				return nil, llmError(err)
+			}
+		}
 	} else {
-		if results, err = search.Query(ctx, g.vector, g.docs, g.embed,
+		if page, err = search.QueryPage(ctx, g.vector, g.docs, g.embed,
 			&search.QueryRequest{
 				EmbedDoc: llm.EmbedDoc{Text: q},
 				Options:  opts,
 			}); err != nil {
-			return nil, err
+			return nil, llmError(err)
 		}
 	}
 
-	for i := range results {
-		results[i].Round()
+	for i := range page.Results {
+		page.Results[i].Round()
 	}
 
-	return results, nil
+	return page, nil
+}
+
+// llmError adds an explanation to a search error
+// if the LLM is unavailable.
+func llmError(err error) error {
+	if !llmAvailability.Available() {
+		return fmt.Errorf("%w (the LLM is unavailable; searching by document ID without reranking still works)", err)
+	}
+	return err
 }
 
 // searchParams holds the raw query parameters.
@@ -109,6 +152,17 @@ type searchParams struct {
 	Threshold   string
 	Limit       string
 	Allow, Deny string // comma separated lists
+	Projects    string // comma separated list
+	State       string
+	After       string // a date (YYYY-MM-DD)
+	Before      string // a date (YYYY-MM-DD)
+	Milestone   string
+	Board       string
+	Rerank      bool
+
+	// The position at which to resume the search
+	// (the text form of [search.Options.After]).
+	Cursor string
 }
 
 // parseParams parses the query params from the request.
@@ -118,6 +172,42 @@ func (pm *searchParams) parseParams(r *http.Request) {
 	pm.Limit = r.FormValue(paramLimit)
 	pm.Allow = r.FormValue(paramAllow)
 	pm.Deny = r.FormValue(paramDeny)
+	pm.Projects = r.FormValue(paramProjects)
+	pm.State = r.FormValue(paramState)
+	pm.After = r.FormValue(paramAfter)
+	pm.Before = r.FormValue(paramBefore)
+	pm.Milestone = r.FormValue(paramMilestone)
+	pm.Board = r.FormValue(paramBoard)
+	pm.Rerank = parseCheckbox(r.FormValue(paramRerank))
+	pm.Cursor = r.FormValue(paramCursor)
+}
+
+// next returns the relative URL of the search page that
+// resumes the search described by pm after the cursor.
+func (pm *searchParams) next(cursor *search.Cursor) string {
+	v := url.Values{}
+	for _, p := range []struct{ name, value string }{
+		{paramQuery, pm.Query},
+		{paramThreshold, pm.Threshold},
+		{paramLimit, pm.Limit},
+		{paramAllow, pm.Allow},
+		{paramDeny, pm.Deny},
+		{paramProjects, pm.Projects},
+		{paramState, pm.State},
+		{paramAfter, pm.After},
+		{paramBefore, pm.Before},
+		{paramMilestone, pm.Milestone},
+		{paramBoard, pm.Board},
+	} {
+		if trim(p.value) != "" {
+			v.Set(p.name, p.value)
+		}
+	}
+	if pm.Rerank {
+		v.Set(paramRerank, "on")
+	}
+	v.Set(paramCursor, cursor.String())
+	return searchID.Endpoint() + "?" + v.Encode()
 }
 
 func (p *searchPage) setCommonPage() {
@@ -138,6 +228,14 @@ const (
 	paramLimit     = "limit"
 	paramAllow     = "allow_kind"
 	paramDeny      = "deny_kind"
+	paramCursor    = "cursor"
+	paramProjects  = "project"
+	paramState     = "state"
+	paramAfter     = "created_after"
+	paramBefore    = "created_before"
+	paramMilestone = "milestone"
+	paramBoard     = "board"
+	paramRerank    = "rerank"
 )
 
 var (
@@ -146,6 +244,20 @@ var (
 	safeLimit     = toSafeID(paramLimit)
 	safeAllow     = toSafeID(paramAllow)
 	safeDeny      = toSafeID(paramDeny)
+	safeProjects  = toSafeID(paramProjects)
+	safeState     = toSafeID(paramState)
+	safeAfter     = toSafeID(paramAfter)
+	safeBefore    = toSafeID(paramBefore)
+	safeMilestone = toSafeID(paramMilestone)
+	safeBoard     = toSafeID(paramBoard)
+	safeRerank    = toSafeID(paramRerank)
+)
+
+// Values of the state parameter.
+const (
+	stateAny    = ""
+	stateOpen   = "open"
+	stateClosed = "closed"
 )
 
 // inputs converts the params into HTML form inputs.
@@ -178,7 +290,7 @@ func (pm *searchParams) inputs() []FormInput {
 
 			Label:       "max results",
 			Type:        "int",
-			Description: "maximum number of results to display (default: 20)",
+			Description: "maximum number of results to display per page (default: 20)",
 			Name:        safeLimit,
 			Typed: TextInput{
 				ID:    safeLimit,
@@ -207,9 +319,110 @@ func (pm *searchParams) inputs() []FormInput {
 				Value: pm.Deny,
 			},
 		},
+		{
+
+			Label:       "projects",
+			Type:        "comma-separated list",
+			Description: "GitHub projects or web sites to include, e.g `golang/go,go.dev` (default: empty, include all)",
+			Name:        safeProjects,
+			Typed: TextInput{
+				ID:    safeProjects,
+				Value: pm.Projects,
+			},
+		},
+		{
+
+			Label:       "state",
+			Type:        "radio choice",
+			Description: "include only open or only closed issues; documents without a state (such as doc pages) are excluded (default: any, include all)",
+			Name:        safeState,
+			Typed: RadioInput{
+				Choices: []RadioChoice{
+					{
+						Label:   "any",
+						ID:      toSafeID("state_any"),
+						Value:   stateAny,
+						Checked: pm.checkState(stateAny),
+					},
+					{
+						Label:   "open",
+						ID:      toSafeID("state_open"),
+						Value:   stateOpen,
+						Checked: pm.checkState(stateOpen),
+					},
+					{
+						Label:   "closed",
+						ID:      toSafeID("state_closed"),
+						Value:   stateClosed,
+						Checked: pm.checkState(stateClosed),
+					},
+				},
+			},
+		},
+		{
+
+			Label:       "created after",
+			Type:        "date (YYYY-MM-DD)",
+			Description: "include only issues created on or after this date; other documents are excluded (default: empty, no limit)",
+			Name:        safeAfter,
+			Typed: TextInput{
+				ID:    safeAfter,
+				Value: pm.After,
+			},
+		},
+		{
+
+			Label:       "created before",
+			Type:        "date (YYYY-MM-DD)",
+			Description: "include only issues created before this date; other documents are excluded (default: empty, no limit)",
+			Name:        safeBefore,
+			Typed: TextInput{
+				ID:    safeBefore,
+				Value: pm.Before,
+			},
+		},
+		{
+
+			Label:       "milestone",
+			Type:        "string",
+			Description: "include only issues in this milestone, e.g. `Go1.25`; other documents are excluded (default: empty, include all)",
+			Name:        safeMilestone,
+			Typed: TextInput{
+				ID:    safeMilestone,
+				Value: pm.Milestone,
+			},
+		},
+		{
+
+			Label:       "project board",
+			Type:        "string",
+			Description: "include only issues on the GitHub project board with this title or URL, e.g. `Proposals`; other documents are excluded (default: empty, include all)",
+			Name:        safeBoard,
+			Typed: TextInput{
+				ID:    safeBoard,
+				Value: pm.Board,
+			},
+		},
+		{
+			Label:       "rerank",
+			Type:        "checkbox",
+			Description: "ask the LLM to reorder each page of results by relevance to the query (slower; similarity scores are unchanged)",
+			Name:        safeRerank,
+			Typed: CheckboxInput{
+				ID:      safeRerank,
+				Value:   "on",
+				Checked: pm.Rerank,
+			},
+		},
 	}
 }
 
+// checkState reports whether the state radio choice
+// with the given value should be checked.
+func (pm *searchParams) checkState(value string) bool {
+	return trim(pm.State) == value
+}
+
 var trim = strings.TrimSpace
 
 // toSearchOptions converts a searchParams into a [search.Options],
@@ -234,6 +447,13 @@ func (f *searchParams) toOptions() (_ *search.Options, err error) {
 		}
 	}
 
+	if c := trim(f.Cursor); c != "" {
+		opts.After, err = search.ParseCursor(c)
+		if err != nil {
+			return nil, fmt.Errorf("cursor: %w", err)
+		}
+	}
+
 	if t := trim(f.Threshold); t != "" {
 		opts.Threshold, err = strconv.ParseFloat(t, 64)
 		if err != nil {
@@ -249,6 +469,29 @@ func (f *searchParams) toOptions() (_ *search.Options, err error) {
 		opts.DenyKind = splitAndTrim(d)
 	}
 
+	if p := trim(f.Projects); p != "" {
+		opts.Sources = splitAndTrim(p)
+	}
+
+	opts.State = trim(f.State)
+	opts.Milestone = trim(f.Milestone)
+	opts.Board = trim(f.Board)
+	opts.Rerank = f.Rerank
+
+	if a := trim(f.After); a != "" {
+		opts.CreatedAfter, err = time.Parse(time.DateOnly, a)
+		if err != nil {
+			return nil, fmt.Errorf("created after: %w", err)
+		}
+	}
+
+	if b := trim(f.Before); b != "" {
+		opts.CreatedBefore, err = time.Parse(time.DateOnly, b)
+		if err != nil {
+			return nil, fmt.Errorf("created before: %w", err)
+		}
+	}
+
 	if err := opts.Validate(); err != nil {
 		return nil, err
 	}
@@ -263,22 +506,60 @@ func (g *Gaby) handleSearchAPI(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		// The error could also come from failing to read the body, but then the
 		// connection is probably broken so it doesn't matter what status we send.
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeAPIError(w, codeInvalidQuery, err)
+		return
+	}
+	if trim(sreq.Text) == "" {
+		writeAPIError(w, codeInvalidQuery, errors.New("empty query"))
+		return
+	}
+	if err := sreq.Options.Validate(); err != nil {
+		writeAPIError(w, codeInvalidQuery, err)
 		return
 	}
+	for _, src := range sreq.Sources {
+		if !strings.Contains(src, "/") {
+			continue // a web host, not a GitHub project
+		}
+		if !slices.Contains(g.githubProjects, src) {
+			writeAPIError(w, codeUnknownProject, fmt.Errorf("unknown project %q", src))
+			return
+		}
+		if !g.githubSynced(src) {
+			writeAPIError(w, codeNotSynced, fmt.Errorf("project %q not synced yet", src))
+			return
+		}
+	}
+	sreq.Info = search.GitHubInfo(g.github)
+	sreq.Denylist = g.denylist
+	sreq.Pins = g.pins
+	sreq.Reranker = search.LLMReranker(g.featureLLMApp("search"))
 	sres, err := search.Query(r.Context(), g.vector, g.docs, g.embed, sreq)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		code := codeInternal
+		if !llmAvailability.Available() {
+			code = codeLLMUnavailable
+		}
+		writeAPIError(w, code, llmError(err))
 		return
 	}
 	data, err := json.Marshal(sres)
 	if err != nil {
-		http.Error(w, "json.Marshal: "+err.Error(), http.StatusInternalServerError)
+		writeAPIError(w, codeInternal, fmt.Errorf("json.Marshal: %w", err))
 		return
 	}
 	_, _ = w.Write(data)
 }
 
+// githubSynced reports whether the database has any data
+// for the GitHub project.
+func (g *Gaby) githubSynced(project string) bool {
+	for range g.github.Events(project, 0, math.MaxInt64) {
+		return true
+	}
+	return false
+}
+
 func readJSONBody[T any](r *http.Request) (*T, error) {
 	defer r.Body.Close()
 	data, err := io.ReadAll(r.Body)
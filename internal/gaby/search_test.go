@@ -9,8 +9,10 @@ import (
 	"errors"
 	"net/http"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -147,6 +149,56 @@ func TestToOptions(t *testing.T) {
 				DenyKind:  []string{search.KindGoDevPage, search.KindGoWiki},
 			},
 		},
+		{
+			name: "filters",
+			form: searchParams{
+				Projects:  " golang/go, go.dev ",
+				State:     "open",
+				After:     "2024-01-02",
+				Before:    " 2025-01-02",
+				Milestone: " Go1.25 ",
+				Board:     "Proposals",
+			},
+			want: &search.Options{
+				Sources:       []string{"golang/go", "go.dev"},
+				State:         "open",
+				CreatedAfter:  time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				CreatedBefore: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+				Milestone:     "Go1.25",
+				Board:         "Proposals",
+			},
+		},
+		{
+			name: "rerank",
+			form: searchParams{
+				Rerank: true,
+			},
+			want: &search.Options{
+				Rerank: true,
+			},
+		},
+		{
+			name: "invalid state",
+			form: searchParams{
+				State: "merged",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unparseable date",
+			form: searchParams{
+				After: "last year",
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty date range",
+			form: searchParams{
+				After:  "2025-01-01",
+				Before: "2024-01-01",
+			},
+			wantErr: true,
+		},
 		{
 			name: "unparseable limit",
 			form: searchParams{
@@ -263,6 +315,47 @@ func TestPopulateSearchPage(t *testing.T) {
 				// No results (blocked by DenyKind)
 			},
 		},
+		{
+			name: "full last page",
+			url:  "test/search?q=id1&limit=1",
+			want: &searchPage{
+				Params: searchParams{
+					Query: "id1",
+					Limit: "1",
+				},
+				Results: []search.Result{{
+					Kind:  search.KindUnknown,
+					Title: "hello",
+					VectorResult: storage.VectorResult{
+						ID:    "id1",
+						Score: 1,
+					},
+				}},
+				// No Next: there are no more results.
+			},
+		},
+		{
+			name: "after last result",
+			url:  "test/search?q=id1&limit=1&cursor=" + cursorEnd,
+			want: &searchPage{
+				Params: searchParams{
+					Query:  "id1",
+					Limit:  "1",
+					Cursor: cursorEnd,
+				},
+			},
+		},
+		{
+			name: "bad cursor",
+			url:  "test/search?q=id1&cursor=1",
+			want: &searchPage{
+				Params: searchParams{
+					Query:  "id1",
+					Cursor: "1",
+				},
+				Error: cmpopts.AnyError,
+			},
+		},
 		{
 			name: "error",
 			url:  "test/search?q=id1&deny_kind=Invalid",
@@ -289,6 +382,132 @@ func TestPopulateSearchPage(t *testing.T) {
 	}
 }
 
+// cursorEnd is a cursor after every possible result.
+var cursorEnd = (&search.Cursor{Score: -1}).String()
+
+func TestPopulateSearchPageNext(t *testing.T) {
+	g := newTestGaby(t)
+	g.docs.Add("id1", "hello", "hello world")
+	g.docs.Add("id2", "goodbye", "goodbye world")
+	g.embedAll(context.Background())
+
+	var ids []string
+	url := "test/search?q=id1&limit=1"
+	for url != "" {
+		r, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := g.populateSearchPage(r)
+		if p.Error != nil {
+			t.Fatal(p.Error)
+		}
+		for _, r := range p.Results {
+			ids = append(ids, r.ID)
+		}
+		url = p.Next
+		if len(ids) > 2 {
+			t.Fatalf("too many pages: %v", ids)
+		}
+	}
+	if want := []string{"id1", "id2"}; !slices.Equal(ids, want) {
+		t.Errorf("paged results = %v, want %v", ids, want)
+	}
+}
+
+func TestPopulateSearchPageFilters(t *testing.T) {
+	g := newTestGaby(t)
+
+	const (
+		open   = "https://github.com/golang/go/issues/1"
+		closed = "https://github.com/golang/go/issues/2"
+		doc    = "https://go.dev/doc/hello"
+	)
+	g.github.Testing().AddIssue("golang/go", &github.Issue{Number: 1, Title: "hello", State: "open", CreatedAt: "2024-06-01T00:00:00Z"})
+	g.github.Testing().AddIssue("golang/go", &github.Issue{Number: 2, Title: "hello", State: "closed", CreatedAt: "2023-06-01T00:00:00Z"})
+	g.docs.Add(open, "hello", "hello world")
+	g.docs.Add(closed, "hello", "hello, world")
+	g.docs.Add(doc, "hello", "hello world!")
+	g.embedAll(context.Background())
+
+	for _, tc := range []struct {
+		name string
+		url  string
+		want []string // sorted
+	}{
+		{"none", "test/search?q=hello", []string{open, closed, doc}},
+		{"project", "test/search?q=hello&project=golang/go", []string{open, closed}},
+		{"host", "test/search?q=hello&project=go.dev", []string{doc}},
+		{"open", "test/search?q=hello&state=open", []string{open}},
+		{"closed", "test/search?q=hello&state=closed", []string{closed}},
+		{"after", "test/search?q=hello&created_after=2024-01-01", []string{open}},
+		{"before", "test/search?q=hello&created_before=2024-01-01", []string{closed}},
+		{"none in range", "test/search?q=hello&state=closed&created_after=2024-01-01", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := g.populateSearchPage(r)
+			if p.Error != nil {
+				t.Fatal(p.Error)
+			}
+			var got []string
+			for _, r := range p.Results {
+				got = append(got, r.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPopulateSearchPageRerank(t *testing.T) {
+	g := newTestGaby(t)
+	// The reranker reverses the order of the candidates.
+	g.llmapp = llmapp.New(g.slog, llm.TestContentGenerator("rerank",
+		func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+			return `{"ranking": [2, 1, 0]}`, nil
+		}), g.db)
+
+	g.docs.Add("id1", "hello", "hello world")
+	g.docs.Add("id2", "hello", "hello world!")
+	g.docs.Add("id3", "hello", "hello, world!")
+	g.embedAll(context.Background())
+
+	for _, tc := range []struct {
+		name string
+		url  string
+		want []string
+	}{
+		{"text", "test/search?q=hello+world", []string{"id1", "id2", "id3"}},
+		{"text rerank", "test/search?q=hello+world&rerank=on", []string{"id3", "id2", "id1"}},
+		{"id", "test/search?q=id1", []string{"id1", "id2", "id3"}},
+		{"id rerank", "test/search?q=id1&rerank=on", []string{"id3", "id2", "id1"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := g.populateSearchPage(r)
+			if p.Error != nil {
+				t.Fatal(p.Error)
+			}
+			var got []string
+			for _, r := range p.Results {
+				got = append(got, r.ID)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func newTestGaby(t *testing.T) *Gaby {
 	t.Helper()
 
@@ -0,0 +1,302 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A selfTestReport is the result of running Gaby's self-test
+// (the -selftest flag).
+type selfTestReport struct {
+	OK     bool             `json:"ok"` // whether all checks passed
+	Time   time.Time        `json:"time"`
+	Checks []*selfTestCheck `json:"checks"`
+}
+
+// A selfTestCheck is the result of a single self-test check.
+type selfTestCheck struct {
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// githubAPI is the GitHub API endpoint used by the self-test.
+// It is a variable so tests can replace it.
+var githubAPI = "https://api.github.com"
+
+// selfTestKey is the key written (and then deleted) by the
+// database self-test check.
+var selfTestKey = []byte("gaby.selftest")
+
+// selfTest validates the configuration, connects to each of
+// Gaby's dependencies and performs a small end-to-end dry run:
+// it copies one GitHub issue into a scratch in-memory database,
+// embeds it, searches for it and generates an overview for it
+// using the echo content generator.
+// Apart from a temporary key in the database, the dry run
+// does not write to any of Gaby's databases or to GitHub.
+//
+// selfTest writes a JSON report to w and reports whether
+// all checks passed.
+// Checks that depend on an earlier failed check are skipped
+// and reported as failures.
+func (g *Gaby) selfTest(ctx context.Context, w io.Writer) bool {
+	r := &selfTestReport{OK: true, Time: time.Now()}
+	check := func(name string, f func() error) bool {
+		start := time.Now()
+		err := protect(f)
+		c := &selfTestCheck{Name: name, OK: err == nil, Duration: time.Since(start)}
+		if err != nil {
+			c.Error = err.Error()
+			r.OK = false
+		}
+		r.Checks = append(r.Checks, c)
+		return err == nil
+	}
+	skip := func(name string) {
+		r.Checks = append(r.Checks, &selfTestCheck{Name: name, Error: "skipped"})
+		r.OK = false
+	}
+
+	check("config", g.selfTestConfig)
+	check("db", g.selfTestDB)
+	check("vector", g.selfTestVector)
+	check("secret", g.selfTestSecret)
+
+	// End-to-end dry run.
+	var (
+		iss *github.Issue
+		e2e *selfTestEnv
+	)
+	ok := check("github", func() (err error) {
+		iss, err = g.selfTestFetchIssue(ctx)
+		return err
+	})
+	ok = ok && check("sync", func() (err error) {
+		e2e, err = g.selfTestSync(iss)
+		return err
+	})
+	ok = ok && check("embed", func() error { return e2e.embed(ctx, g.embed) })
+	ok = ok && check("search", func() error { return e2e.search(iss) })
+	ok = ok && check("overview", func() error { return e2e.overview(ctx, iss) })
+	if !ok {
+		for _, name := range []string{"github", "sync", "embed", "search", "overview"} {
+			if !r.ran(name) {
+				skip(name)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		// Unreachable: the report is always JSON-encodable.
+		panic(err)
+	}
+	fmt.Fprintf(w, "%s\n", data)
+	return r.OK
+}
+
+// ran reports whether the check with the given name was run.
+func (r *selfTestReport) ran(name string) bool {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// protect calls f, converting a panic into an error.
+// (Storage and vector databases report errors by panicking.)
+func protect(f func() error) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	return f()
+}
+
+// selfTestConfig checks that Gaby's configuration is complete.
+func (g *Gaby) selfTestConfig() error {
+	var errs []error
+	if len(g.githubProjects) == 0 {
+		errs = append(errs, errors.New("no GitHub projects"))
+	}
+	for _, p := range g.githubProjects {
+		if owner, repo, ok := strings.Cut(p, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			errs = append(errs, fmt.Errorf("invalid GitHub project %q", p))
+		}
+	}
+	for _, dep := range []struct {
+		name    string
+		missing bool
+	}{
+		{"database", g.db == nil},
+		{"vector database", g.vector == nil},
+		{"secret database", g.secret == nil},
+		{"embedder", g.embed == nil},
+		{"content generator", g.llm == nil},
+	} {
+		if dep.missing {
+			errs = append(errs, fmt.Errorf("no %s", dep.name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// selfTestDB checks that the database can be written, read and deleted.
+func (g *Gaby) selfTestDB() error {
+	val := []byte(time.Now().Format(time.RFC3339Nano))
+	g.db.Set(selfTestKey, val)
+	defer g.db.Delete(selfTestKey)
+	got, ok := g.db.Get(selfTestKey)
+	if !ok || string(got) != string(val) {
+		return fmt.Errorf("db.Get(%q) = %q, %v; want %q, true", selfTestKey, got, ok, val)
+	}
+	return nil
+}
+
+// selfTestVector checks that the vector database can be read.
+func (g *Gaby) selfTestVector() error {
+	// The result does not matter; the lookup panics if the
+	// database is unreachable.
+	g.vector.Get(string(selfTestKey))
+	return nil
+}
+
+// selfTestSecret checks that the GitHub token is available.
+func (g *Gaby) selfTestSecret() error {
+	if github.Token(g.secret) == "" {
+		return errors.New("no GitHub token (secret api.github.com)")
+	}
+	return nil
+}
+
+// selfTestFetchIssue fetches the most recently updated issue
+// in the first GitHub project.
+func (g *Gaby) selfTestFetchIssue(ctx context.Context) (*github.Issue, error) {
+	if len(g.githubProjects) == 0 {
+		return nil, errors.New("no GitHub projects")
+	}
+	project := g.githubProjects[0]
+	url := fmt.Sprintf("%s/repos/%s/issues?state=all&sort=updated&per_page=1", githubAPI, project)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := github.Token(g.secret); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	var issues []*github.Issue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, fmt.Errorf("GET %s: %v", url, err)
+	}
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("GET %s: no issues", url)
+	}
+	return issues[0], nil
+}
+
+// A selfTestEnv is the scratch environment used for
+// the self-test's end-to-end dry run.
+type selfTestEnv struct {
+	g       *Gaby
+	db      storage.DB
+	project string
+	github  *github.Client
+	docs    *docs.Corpus
+	vector  storage.VectorDB
+}
+
+// selfTestSync copies the issue into a scratch database and
+// converts it into a document.
+func (g *Gaby) selfTestSync(iss *github.Issue) (*selfTestEnv, error) {
+	db := storage.MemDB()
+	e := &selfTestEnv{
+		g:       g,
+		db:      db,
+		project: g.githubProjects[0],
+		github:  github.New(g.slog, db, secret.Empty(), nil),
+		docs:    docs.New(g.slog, db),
+		vector:  storage.MemVectorDB(db, g.slog, "selftest"),
+	}
+	e.github.EnableTesting()
+	if err := e.github.Add(e.project); err != nil {
+		return nil, err
+	}
+	e.github.Testing().AddIssue(e.project, iss)
+	docs.Sync(e.docs, e.github)
+	if _, ok := e.docs.Get(iss.DocID()); !ok {
+		return nil, fmt.Errorf("issue %s not in docs corpus after sync", iss.DocID())
+	}
+	return e, nil
+}
+
+// embed embeds the scratch documents using the given embedder.
+func (e *selfTestEnv) embed(ctx context.Context, embed llm.Embedder) error {
+	return embeddocs.Sync(ctx, e.g.slog, e.vector, embed, e.docs)
+}
+
+// search checks that a vector search for the issue finds the issue.
+func (e *selfTestEnv) search(iss *github.Issue) error {
+	v, ok := e.vector.Get(iss.DocID())
+	if !ok {
+		return fmt.Errorf("issue %s not in vector db after embedding", iss.DocID())
+	}
+	rs := search.Vector(e.vector, e.docs, &search.VectorRequest{
+		Options: search.Options{Limit: 1},
+		Vector:  v,
+	})
+	if len(rs) == 0 || rs[0].ID != iss.DocID() {
+		return fmt.Errorf("search for issue %s did not find it", iss.DocID())
+	}
+	return nil
+}
+
+// overview generates an overview of the issue
+// using the echo content generator.
+func (e *selfTestEnv) overview(ctx context.Context, iss *github.Issue) error {
+	lc := llmapp.New(e.g.slog, llm.EchoContentGenerator(), e.db)
+	r, err := overview.New(e.g.slog, e.db, e.github, lc, "selftest", "selftest").ForIssue(ctx, iss)
+	if err != nil {
+		return err
+	}
+	if r.Overview == nil || r.Overview.Response == "" {
+		return errors.New("empty overview")
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestSelfTest(t *testing.T) {
+	var auth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/hello/world/issues" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`[{"number": 7, "title": "hello", "body": "hello world", "state": "open"}]`))
+	}))
+	defer ts.Close()
+	defer func(api string) { githubAPI = api }(githubAPI)
+	githubAPI = ts.URL
+
+	newGaby := func(projects ...string) *Gaby {
+		lg := testutil.Slogger(t)
+		db := storage.MemDB()
+		return &Gaby{
+			slog:           lg,
+			db:             db,
+			vector:         storage.MemVectorDB(db, lg, "vector"),
+			secret:         secret.Map{"api.github.com": "user:token"},
+			http:           ts.Client(),
+			embed:          llm.QuoteEmbedder(),
+			llm:            llm.EchoContentGenerator(),
+			githubProjects: projects,
+		}
+	}
+
+	run := func(g *Gaby) (bool, map[string]string) {
+		var buf bytes.Buffer
+		ok := g.selfTest(context.Background(), &buf)
+		var r selfTestReport
+		if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+			t.Fatalf("report is not JSON: %v\n%s", err, buf.Bytes())
+		}
+		if r.OK != ok {
+			t.Errorf("report.OK = %v, selfTest returned %v", r.OK, ok)
+		}
+		errs := make(map[string]string)
+		for _, c := range r.Checks {
+			errs[c.Name] = c.Error
+		}
+		return ok, errs
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		g := newGaby("hello/world")
+		ok, errs := run(g)
+		want := map[string]string{
+			"config": "", "db": "", "vector": "", "secret": "",
+			"github": "", "sync": "", "embed": "", "search": "", "overview": "",
+		}
+		if diff := cmp.Diff(want, errs); diff != "" || !ok {
+			t.Errorf("selfTest() = %v, mismatch (-want +got):\n%s", ok, diff)
+		}
+		if auth != "Bearer token" {
+			t.Errorf("Authorization = %q, want %q", auth, "Bearer token")
+		}
+		if _, ok := g.db.Get(selfTestKey); ok {
+			t.Errorf("self-test key left in db")
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		g := newGaby("hello/nowhere")
+		g.secret = secret.Empty()
+		ok, errs := run(g)
+		if ok {
+			t.Fatal("selfTest() = true, want false")
+		}
+		for _, name := range []string{"secret", "github"} {
+			if errs[name] == "" {
+				t.Errorf("check %s passed, want failure", name)
+			}
+		}
+		for _, name := range []string{"sync", "embed", "search", "overview"} {
+			if errs[name] != "skipped" {
+				t.Errorf("check %s: error = %q, want skipped", name, errs[name])
+			}
+		}
+	})
+}
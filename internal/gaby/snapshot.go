@@ -0,0 +1,144 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel/metric/noop"
+	"golang.org/x/oscar/internal/dbspec"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// Snapshot mode (the -snapshot flag) serves a public, read-only
+// "issue explorer": the search page, for searching by document ID,
+// and the overview and overview history pages, for displaying the
+// overviews stored in the database.
+// It does not use the LLM, GitHub or any secrets, and it never
+// writes to the snapshot: writes made while serving (such as locks)
+// go to an in-memory overlay.
+
+// snapshotPages are the pages served, and listed in navigation,
+// in snapshot mode.
+var snapshotPages = []pageID{overviewID, overviewHistoryID, searchID}
+
+// errSnapshotLLM is the error returned by the LLM in snapshot mode.
+var errSnapshotLLM = errors.New("the LLM is not available in this read-only snapshot; search by document ID (such as https://github.com/golang/go/issues/1234) instead")
+
+// initSnapshot initializes g to serve the snapshot in the DB
+// described by the -snapshot flag.
+func (g *Gaby) initSnapshot() error {
+	spec, err := dbspec.Parse(flags.snapshot)
+	if err != nil {
+		return err
+	}
+	if spec.IsVector {
+		return errors.New("omit vector DB spec for -snapshot")
+	}
+	db, err := spec.Open(g.ctx, g.slog)
+	if err != nil {
+		return err
+	}
+	g.initSnapshotDB(db)
+	if g.cloud {
+		port := os.Getenv("PORT")
+		if port == "" {
+			return errors.New("$PORT not set")
+		}
+		g.meta["port"] = port
+		g.addr = ":" + port
+	}
+	pages = snapshotPages
+	return nil
+}
+
+// initSnapshotDB initializes g to serve the snapshot in db,
+// which it does not modify.
+func (g *Gaby) initSnapshotDB(db storage.DB) {
+	g.snapshot = true
+	g.db = storage.NewOverlayDB(storage.MemDB(), db)
+	g.secret = secret.Empty()
+	g.meter = noop.Meter{}
+
+	// Search the vectors of the index's active embedding model.
+	// There is no embedder, so only searches by document ID work.
+	g.index = embeddocs.NewIndex(g.slog, g.db, vectorDBNamespace, embeddingModel(flags.embedder),
+		func(namespace string) (storage.VectorDB, error) {
+			return storage.MemVectorDBWithLimit(g.db, g.slog, namespace, flags.vectorMem<<20), nil
+		})
+	g.vector = g.index
+	g.embed = snapshotLLM{}
+	g.llm = snapshotLLM{}
+
+	g.github = github.New(g.slog, g.db, g.secret, nil)
+	g.docs = docs.New(g.slog, g.db)
+	g.denylist = search.NewDenylist(g.slog, g.db)
+	g.pins = search.NewPins(g.slog, g.db)
+	g.llmapp = llmapp.New(g.slog, g.llm, g.db)
+	g.overview = overview.New(g.slog, g.db, g.github, g.llmapp, "overview", "gabyhelp")
+}
+
+// newSnapshotServer creates a new [http.ServeMux] serving
+// the pages of snapshot mode.
+// There are no /api/ endpoints, because the search API
+// only searches by text.
+func (g *Gaby) newSnapshotServer() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, searchID.Endpoint(), http.StatusFound)
+	})
+	mux.Handle("GET /static/", http.FileServerFS(staticFS))
+	mux.HandleFunc("GET "+searchID.Endpoint(), g.handleSearch)
+	mux.HandleFunc("GET "+overviewID.Endpoint(), g.handleOverview)
+	mux.HandleFunc("GET "+overviewHistoryID.Endpoint(), g.handleOverviewHistory)
+	return mux
+}
+
+// snapshotOverview returns the last stored overview of iss
+// of the given type. Only issue overviews are stored.
+func (g *Gaby) snapshotOverview(iss *github.Issue, typ string) (*overviewResult, error) {
+	if typ != "" && typ != issueOverviewType {
+		return nil, errors.New("only issue overviews are available in this read-only snapshot")
+	}
+	last, generated, ok := g.overview.LastForIssue(iss)
+	if !ok {
+		return nil, fmt.Errorf("no overview of issue %d in this read-only snapshot", iss.Number)
+	}
+	return &overviewResult{
+		Raw:   last.Overview,
+		Issue: iss,
+		Typed: last,
+		Type:  issueOverviewType,
+		Desc:  fmt.Sprintf("issue %d and its first %d comments, generated on %s", iss.Number, last.TotalComments, generated.Format("2006-01-02")),
+	}, nil
+}
+
+// snapshotLLM is the LLM used in snapshot mode.
+// All its methods fail with errSnapshotLLM.
+type snapshotLLM struct{}
+
+func (snapshotLLM) Model() string { return "none" }
+
+func (snapshotLLM) SetTemperature(float32) {}
+
+func (snapshotLLM) GenerateContent(context.Context, *llm.Schema, []llm.Part) (string, error) {
+	return "", errSnapshotLLM
+}
+
+func (snapshotLLM) EmbedDocs(context.Context, []llm.EmbedDoc) ([]llm.Vector, error) {
+	return nil, errSnapshotLLM
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	project := "hello/world"
+
+	// Build the snapshot as a full Gaby instance would:
+	// sync an issue, embed it and generate its overview.
+	db := storage.MemDB()
+	gh := github.New(lg, db, secret.Empty(), nil)
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "hello", Body: "hello world"})
+	gh.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "goodbye", Body: "goodbye world"})
+	dc := docs.New(lg, db)
+	docs.Sync(dc, gh)
+	vdb := storage.MemVectorDB(db, lg, vectorDBNamespace)
+	if err := embeddocs.Sync(ctx, lg, vdb, llm.QuoteEmbedder(), dc); err != nil {
+		t.Fatal(err)
+	}
+	ov := overview.New(lg, db, gh, llmapp.New(lg, llm.EchoContentGenerator(), db), "overview", "gabyhelp")
+	iss1, err := github.LookupIssue(db, project, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ov.ForIssue(ctx, iss1); err != nil {
+		t.Fatal(err)
+	}
+	var before []string
+	for k := range db.Scan(nil, []byte{0xff}) {
+		before = append(before, string(k))
+	}
+
+	g := &Gaby{
+		ctx:            ctx,
+		slog:           lg,
+		githubProjects: []string{project},
+	}
+	g.initSnapshotDB(db)
+	s := httptest.NewServer(g.newSnapshotServer())
+	defer s.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		res, err := s.Client().Get(s.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode, string(b)
+	}
+
+	// Search by document ID uses the stored vectors.
+	rs, err := g.search(ctx, "https://github.com/hello/world/issues/1", search.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) == 0 {
+		t.Error("search by ID: no results")
+	}
+	// Search by text needs the LLM.
+	if _, err := g.search(ctx, "hello", search.Options{}); err == nil {
+		t.Error("search by text succeeded, want error")
+	}
+
+	if code, body := get("/overview?q=1"); code != http.StatusOK || !strings.Contains(body, "(cached)") {
+		t.Errorf("/overview?q=1 = %d %q, want cached overview", code, body)
+	}
+	if _, body := get("/overview?q=2"); !strings.Contains(body, "no overview of issue 2") {
+		t.Errorf("/overview?q=2 = %q, want no overview", body)
+	}
+	if _, body := get("/overview?q=1&t=related_overview"); !strings.Contains(body, "only issue overviews") {
+		t.Errorf("related overview = %q, want error", body)
+	}
+	if code, _ := get("/overviewhistory?q=1"); code != http.StatusOK {
+		t.Errorf("/overviewhistory: status %d", code)
+	}
+	for _, path := range []string{"/cron", "/actionlog", "/dbview"} {
+		if code, _ := get(path); code != http.StatusNotFound {
+			t.Errorf("%s: status %d, want %d", path, code, http.StatusNotFound)
+		}
+	}
+
+	// Serving does not change the snapshot.
+	var after []string
+	for k := range db.Scan(nil, []byte{0xff}) {
+		after = append(after, string(k))
+	}
+	if strings.Join(after, "\n") != strings.Join(before, "\n") {
+		t.Errorf("snapshot changed: %d keys before, %d after", len(before), len(after))
+	}
+}
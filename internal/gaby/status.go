@@ -0,0 +1,14 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "golang.org/x/oscar/internal/llm"
+
+// llmAvailability tracks the availability of the LLM service
+// shared by all of gaby's features.
+// Features that depend on the LLM consult it to degrade gracefully
+// while the service is down, and every page displays a banner
+// when the service is unavailable (see "status-banner" in tmpl/common.tmpl).
+var llmAvailability = llm.NewAvailability()
@@ -0,0 +1,155 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oscar/internal/search"
+)
+
+// suppressPage holds the fields needed to display the documents
+// suppressed from search results and related posts.
+type suppressPage struct {
+	CommonPage
+
+	Params       suppressParams          // the raw parameters
+	Error        error                   // if non-nil, the error from the requested action
+	Done         string                  // description of the completed action, if any
+	Suppressions []*search.Suppression   // the suppressed documents
+	Events       []*search.DenylistEvent // the audit log, most recent first
+}
+
+// suppressParams holds the raw inputs to the suppression form.
+type suppressParams struct {
+	ID     string // ID (URL) of the document
+	Action string // search.ActionSuppress or search.ActionUnsuppress
+	Reason string // why the action was taken
+}
+
+const (
+	paramSuppressID     = "id"
+	paramSuppressAction = "action"
+	paramSuppressReason = "reason"
+)
+
+var (
+	safeSuppressID     = toSafeID(paramSuppressID)
+	safeSuppressReason = toSafeID(paramSuppressReason)
+)
+
+var suppressPageTmpl = newTemplate(suppressTmplFile, nil)
+
+func (g *Gaby) handleSuppress(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateSuppressPage(r), suppressPageTmpl)
+}
+
+// populateSuppressPage returns the contents of the suppression page,
+// after suppressing or unsuppressing the document in the "id" form
+// value, if any, as the "action" form value says.
+func (g *Gaby) populateSuppressPage(r *http.Request) *suppressPage {
+	p := &suppressPage{
+		Params: suppressParams{
+			ID:     strings.TrimSpace(r.FormValue(paramSuppressID)),
+			Action: r.FormValue(paramSuppressAction),
+			Reason: strings.TrimSpace(r.FormValue(paramSuppressReason)),
+		},
+	}
+	if p.Params.ID != "" {
+		p.Error = g.suppress(p.Params, requestUser(r))
+		if p.Error == nil {
+			p.Done = p.Params.Action + "ed " + p.Params.ID
+		}
+	}
+	p.Suppressions = g.denylist.Suppressions()
+	p.Events = g.denylist.Events()
+	p.setCommonPage()
+	return p
+}
+
+// suppress performs the action in pm on behalf of user.
+func (g *Gaby) suppress(pm suppressParams, user string) error {
+	if pm.Reason == "" {
+		return errors.New("a reason is required")
+	}
+	switch pm.Action {
+	case search.ActionSuppress:
+		return g.denylist.Suppress(pm.ID, user, pm.Reason)
+	case search.ActionUnsuppress:
+		return g.denylist.Unsuppress(pm.ID, user, pm.Reason)
+	}
+	return errors.New("unknown action " + pm.Action)
+}
+
+// requestUser returns the user making the request, as reported by
+// the Identity-Aware Proxy in front of Gaby, or "unknown".
+func requestUser(r *http.Request) string {
+	u := r.Header.Get("X-Goog-Authenticated-User-Email")
+	if u == "" {
+		return "unknown"
+	}
+	// The header has the form "accounts.google.com:user@example.com".
+	_, after, _ := strings.Cut(u, ":")
+	return after
+}
+
+func (p *suppressPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          suppressID,
+		Description: "Suppress documents from search results and related posts, such as a misleading old issue that keeps matching.",
+		Form: Form{
+			Inputs: []FormInput{
+				{
+					Label:       "document",
+					Type:        "string",
+					Description: "the ID of the document, usually its URL (e.g. https://github.com/golang/go/issues/1234)",
+					Name:        safeSuppressID,
+					Required:    true,
+					Typed: TextInput{
+						ID:    safeSuppressID,
+						Value: p.Params.ID,
+					},
+				},
+				{
+					Label:       "action",
+					Type:        "radio choice",
+					Description: `"suppress" removes the document from search results and related posts; "unsuppress" restores it`,
+					Name:        toSafeID(paramSuppressAction),
+					Required:    true,
+					Typed: RadioInput{
+						Choices: []RadioChoice{
+							{
+								Label:   search.ActionSuppress,
+								ID:      toSafeID(search.ActionSuppress),
+								Value:   search.ActionSuppress,
+								Checked: p.Params.Action != search.ActionUnsuppress,
+							},
+							{
+								Label:   search.ActionUnsuppress,
+								ID:      toSafeID(search.ActionUnsuppress),
+								Value:   search.ActionUnsuppress,
+								Checked: p.Params.Action == search.ActionUnsuppress,
+							},
+						},
+					},
+				},
+				{
+					Label:       "reason",
+					Type:        "string",
+					Description: "why the document is suppressed or unsuppressed, for the audit log",
+					Name:        safeSuppressReason,
+					Required:    true,
+					Typed: TextInput{
+						ID:    safeSuppressReason,
+						Value: p.Params.Reason,
+					},
+				},
+			},
+			SubmitText: "submit",
+		},
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestSuppressPage(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	g := &Gaby{slog: lg, db: db, denylist: search.NewDenylist(lg, db)}
+
+	id := "https://github.com/golang/go/issues/1"
+	populate := func(action, reason string) *suppressPage {
+		t.Helper()
+		q := url.Values{paramSuppressID: {id}, paramSuppressAction: {action}, paramSuppressReason: {reason}}
+		r := httptest.NewRequest("GET", "/suppress?"+q.Encode(), nil)
+		r.Header.Set("X-Goog-Authenticated-User-Email", "accounts.google.com:gopher@golang.org")
+		return g.populateSuppressPage(r)
+	}
+
+	p := populate(search.ActionSuppress, "")
+	if p.Error == nil {
+		t.Error("suppress without reason succeeded, want error")
+	}
+	p = populate(search.ActionSuppress, "misleading")
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if len(p.Suppressions) != 1 || p.Suppressions[0].User != "gopher@golang.org" {
+		t.Errorf("Suppressions = %v, want one by gopher@golang.org", p.Suppressions)
+	}
+	if !g.denylist.Suppressed(id) {
+		t.Errorf("%s not suppressed", id)
+	}
+
+	p = populate(search.ActionUnsuppress, "fixed")
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if len(p.Suppressions) != 0 || g.denylist.Suppressed(id) {
+		t.Errorf("Suppressions = %v after unsuppress, want none", p.Suppressions)
+	}
+	if len(p.Events) != 2 || p.Events[0].Action != search.ActionUnsuppress {
+		t.Errorf("Events = %v, want unsuppress then suppress", p.Events)
+	}
+	if p := populate(search.ActionUnsuppress, "again"); p.Error == nil {
+		t.Error("unsuppress of unsuppressed document succeeded, want error")
+	}
+}
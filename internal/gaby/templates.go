@@ -23,13 +23,25 @@ var staticFS embed.FS
 
 const (
 	// Landing pages
-	actionLogTmplFile    = "actionlog.tmpl"
-	searchPageTmplFile   = "searchpage.tmpl"
-	overviewPageTmplFile = "overviewpage.tmpl"
-	rulesPageTmplFile    = "rulespage.tmpl"
-	labelsPageTmplFile   = "labelspage.tmpl"
-	dbviewPageTmplFile   = "dbviewpage.tmpl"
-	bisectLogTmplFile    = "bisectlogpage.tmpl"
+	actionLogTmplFile       = "actionlog.tmpl"
+	searchPageTmplFile      = "searchpage.tmpl"
+	overviewPageTmplFile    = "overviewpage.tmpl"
+	overviewHistoryTmplFile = "overviewhistorypage.tmpl"
+	rulesPageTmplFile       = "rulespage.tmpl"
+	labelsPageTmplFile      = "labelspage.tmpl"
+	dbviewPageTmplFile      = "dbviewpage.tmpl"
+	bisectLogTmplFile       = "bisectlogpage.tmpl"
+	divertedEditsTmplFile   = "divertededitspage.tmpl"
+	feedbackTmplFile        = "feedbackpage.tmpl"
+	workloadTmplFile        = "workloadpage.tmpl"
+	weeklyTmplFile          = "weeklypage.tmpl"
+	llmCacheTmplFile        = "llmcachepage.tmpl"
+	llmCostTmplFile         = "llmcostpage.tmpl"
+	suppressTmplFile        = "suppresspage.tmpl"
+	evalsTmplFile           = "evalspage.tmpl"
+	pinsTmplFile            = "pinspage.tmpl"
+	apiTokensTmplFile       = "apitokenspage.tmpl"
+	watchersTmplFile        = "watcherspage.tmpl"
 
 	// Common template file
 	commonTmpl = "common.tmpl"
@@ -46,6 +58,7 @@ func newTemplate(filename string, funcs template.FuncMap) *template.Template {
 	funcs["dec"] = func(i int) int {
 		return i - 1
 	}
+	funcs["llmStatus"] = llmAvailability.Status
 	return template.Must(template.New(filename).Funcs(funcs).
 		ParseFS(template.TrustedFSFromEmbed(tmplFS),
 			path.Join("tmpl", filename),
@@ -59,6 +59,39 @@ func TestTemplates(t *testing.T) {
 				},
 				Type: issueOverviewType,
 			}}},
+		{"overview-stale", overviewPageTmpl, &overviewPage{
+			Params: overviewParams{Query: "12"},
+			Result: &overviewResult{
+				Raw: &llmapp.Result{
+					Response: "an old overview",
+					Cached:   true,
+				},
+				Typed: &overview.IssueResult{TotalComments: 2},
+				Issue: &github.Issue{
+					User:    github.User{Login: "abc"},
+					HTMLURL: "https://example.com",
+				},
+				Type:  issueOverviewType,
+				Stale: &staleResult{Err: fmt.Errorf("LLM down")},
+			}}},
+		{"overviewhistory", overviewHistoryPageTmpl, &overviewHistoryPage{
+			Params: overviewHistoryParams{Query: "12"},
+			Issue:  &github.Issue{Title: "t", HTMLURL: "https://example.com"},
+			Entries: []*historyEntry{{
+				HistoryEntry: &overview.HistoryEntry{Type: overview.IssueOverview, Text: "an overview"},
+				Diff:         "-old\n+new\n",
+			}},
+		}},
+		{"divertededits", divertedEditsPageTmpl, &divertedEditsPage{
+			DryRun: true,
+			Edits: []*github.DivertedEdit{{
+				Edit: &github.TestingEdit{
+					Project:             "golang/go",
+					Issue:               1,
+					IssueCommentChanges: &github.IssueCommentChanges{Body: "hello"},
+				},
+			}},
+		}},
 		{"overview-error", overviewPageTmpl, &overviewPage{
 			Params: overviewParams{Query: "12"},
 			Error:  fmt.Errorf("an error"),
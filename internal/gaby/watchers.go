@@ -0,0 +1,187 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/storage/timed"
+)
+
+// watchersPage holds the fields needed to display the positions
+// of the watchers and to move them.
+type watchersPage struct {
+	CommonPage
+
+	Params   watchersParams        // the raw parameters
+	Error    error                 // if non-nil, the error from the requested action
+	Done     string                // description of the completed action, if any
+	Watchers []*watcherRow         // the watchers, ordered by kind and name
+	Events   []*timed.WatcherEvent // the audit log, most recent first
+}
+
+// A watcherRow is a watcher's state, for display.
+type watcherRow struct {
+	*timed.WatcherState
+	Lag time.Duration // how long the oldest pending entry has waited
+}
+
+// watchersParams holds the raw inputs to the watcher form.
+type watchersParams struct {
+	Watcher string // the watcher, as "kind:name"
+	To      string // the new position (see parseWatcherPosition)
+	Reason  string // why the watcher is moved
+}
+
+const (
+	paramWatcher       = "watcher"
+	paramWatcherTo     = "to"
+	paramWatcherReason = "reason"
+)
+
+var (
+	safeWatcher       = toSafeID(paramWatcher)
+	safeWatcherTo     = toSafeID(paramWatcherTo)
+	safeWatcherReason = toSafeID(paramWatcherReason)
+)
+
+var watchersPageTmpl = newTemplate(watchersTmplFile, nil)
+
+func (g *Gaby) handleWatchers(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateWatchersPage(r), watchersPageTmpl)
+}
+
+// populateWatchersPage returns the contents of the watchers page,
+// after moving the watcher in the "watcher" form value, if any,
+// to the position in the "to" form value.
+func (g *Gaby) populateWatchersPage(r *http.Request) *watchersPage {
+	p := &watchersPage{
+		Params: watchersParams{
+			Watcher: strings.TrimSpace(r.FormValue(paramWatcher)),
+			To:      strings.TrimSpace(r.FormValue(paramWatcherTo)),
+			Reason:  strings.TrimSpace(r.FormValue(paramWatcherReason)),
+		},
+	}
+	if p.Params.Watcher != "" {
+		var e *timed.WatcherEvent
+		e, p.Error = g.moveWatcher(p.Params, requestUser(r))
+		if p.Error == nil {
+			verb := "advanced"
+			if e.Rewound() {
+				verb = "rewound"
+			}
+			p.Done = fmt.Sprintf("%s %s from %d to %d", verb, e.WatcherID, e.Old, e.New)
+		}
+	}
+	now := time.Now()
+	for _, id := range timed.Watchers() {
+		s := timed.State(g.db, id)
+		p.Watchers = append(p.Watchers, &watcherRow{s, s.Lag(now).Round(time.Second)})
+	}
+	p.Events = timed.WatcherEvents(g.db)
+	p.setCommonPage()
+	return p
+}
+
+// moveWatcher moves the watcher as pm says, on behalf of user.
+func (g *Gaby) moveWatcher(pm watchersParams, user string) (*timed.WatcherEvent, error) {
+	if pm.Reason == "" {
+		return nil, errors.New("a reason is required")
+	}
+	id, ok := timed.ParseWatcherID(pm.Watcher)
+	if !ok || !slices.Contains(timed.Watchers(), id) {
+		return nil, fmt.Errorf("unknown watcher %q", pm.Watcher)
+	}
+	t, err := parseWatcherPosition(pm.To, timed.State(g.db, id).Cursor, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	e := timed.MoveWatcher(g.db, id, t, user, pm.Reason)
+	g.slog.Info("gaby: moved watcher", "watcher", id.String(), "old", e.Old, "new", e.New, "user", user, "reason", pm.Reason)
+	return e, nil
+}
+
+// parseWatcherPosition parses the new position of a watcher
+// whose current position is cursor. The position is one of:
+//
+//   - "start", to visit all entries again;
+//   - "now", to skip all entries set before now;
+//   - a time in RFC 3339 format, such as "2025-01-02T15:04:05Z";
+//   - a DBTime, such as "1735830245000000000";
+//   - a signed duration relative to cursor, such as "-24h" to
+//     rewind by a day or "+1h" to advance by an hour.
+func parseWatcherPosition(s string, cursor timed.DBTime, now time.Time) (timed.DBTime, error) {
+	switch {
+	case s == "start":
+		return 0, nil
+	case s == "now":
+		return timed.DBTimeAt(now), nil
+	case strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+"):
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+		return max(0, timed.DBTimeAt(cursor.Time().Add(d))), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return timed.DBTimeAt(t), nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n >= 0 {
+		return timed.DBTime(n), nil
+	}
+	return 0, fmt.Errorf("invalid position %q", s)
+}
+
+func (p *watchersPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          watchersID,
+		Description: "Inspect the positions of the watchers that feed Gaby's posters, and rewind or advance them.",
+		Form: Form{
+			Inputs: []FormInput{
+				{
+					Label:       "watcher",
+					Type:        "string",
+					Description: `the watcher to move, as "kind:name" (see the table below)`,
+					Name:        safeWatcher,
+					Required:    true,
+					Typed: TextInput{
+						ID:    safeWatcher,
+						Value: p.Params.Watcher,
+					},
+				},
+				{
+					Label: "to",
+					Type:  "string",
+					Description: `the new position: "start", "now", an RFC 3339 time, a DBTime, ` +
+						`or a signed duration relative to the current position (e.g. "-24h")`,
+					Name:     safeWatcherTo,
+					Required: true,
+					Typed: TextInput{
+						ID:    safeWatcherTo,
+						Value: p.Params.To,
+					},
+				},
+				{
+					Label:       "reason",
+					Type:        "string",
+					Description: "why the watcher is moved, for the audit log",
+					Name:        safeWatcherReason,
+					Required:    true,
+					Typed: TextInput{
+						ID:    safeWatcherReason,
+						Value: p.Params.Reason,
+					},
+				},
+			},
+			SubmitText: "move",
+		},
+	}
+}
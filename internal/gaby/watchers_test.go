@@ -0,0 +1,106 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestWatchersPage(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	g := &Gaby{slog: lg, db: db}
+
+	b := db.Batch()
+	t1 := timed.Set(db, b, "gaby.TestKind", []byte("k1"), nil)
+	b.Apply()
+	timed.NewWatcher(lg, db, "test", "gaby.TestKind", func(e *timed.Entry) *timed.Entry { return e })
+	id := timed.WatcherID{Kind: "gaby.TestKind", Name: "test"}
+
+	populate := func(watcher, to, reason string) *watchersPage {
+		t.Helper()
+		q := url.Values{paramWatcher: {watcher}, paramWatcherTo: {to}, paramWatcherReason: {reason}}
+		r := httptest.NewRequest("GET", "/watchers?"+q.Encode(), nil)
+		r.Header.Set("X-Goog-Authenticated-User-Email", "accounts.google.com:gopher@golang.org")
+		return g.populateWatchersPage(r)
+	}
+	row := func(p *watchersPage) *watcherRow {
+		t.Helper()
+		for _, w := range p.Watchers {
+			if w.WatcherID == id {
+				return w
+			}
+		}
+		t.Fatalf("watcher %v not listed", id)
+		return nil
+	}
+
+	p := populate("", "", "")
+	if w := row(p); w.Cursor != 0 || w.Pending != 1 {
+		t.Errorf("watcher = %+v, want 1 pending", w)
+	}
+
+	for _, tc := range []struct{ watcher, to, reason string }{
+		{id.String(), "now", ""},
+		{"gaby.TestKind:nope", "now", "typo"},
+		{id.String(), "yesterday", "bad time"},
+	} {
+		if p := populate(tc.watcher, tc.to, tc.reason); p.Error == nil {
+			t.Errorf("move %v succeeded, want error", tc)
+		}
+	}
+
+	p = populate(id.String(), "now", "skip")
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if w := row(p); w.Pending != 0 || w.Cursor < t1 {
+		t.Errorf("watcher after advance = %+v, want none pending", w)
+	}
+	p = populate(id.String(), "start", "redo")
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if w := row(p); w.Cursor != 0 || w.Pending != 1 {
+		t.Errorf("watcher after rewind = %+v, want 1 pending", w)
+	}
+	if len(p.Events) != 2 || p.Events[0].Reason != "redo" || p.Events[0].User != "gopher@golang.org" {
+		t.Errorf("Events = %v, want redo then skip by gopher@golang.org", p.Events)
+	}
+}
+
+func TestParseWatcherPosition(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	cursor := timed.DBTimeAt(now.Add(-time.Hour))
+	for _, tc := range []struct {
+		in   string
+		want timed.DBTime
+	}{
+		{"start", 0},
+		{"now", timed.DBTimeAt(now)},
+		{"2025-01-02T15:04:05Z", timed.DBTimeAt(now)},
+		{"12345", 12345},
+		{"+1h", timed.DBTimeAt(now)},
+		{"-1h", timed.DBTimeAt(now.Add(-2 * time.Hour))},
+		{"-1000000h", 0},
+	} {
+		got, err := parseWatcherPosition(tc.in, cursor, now)
+		if err != nil || got != tc.want {
+			t.Errorf("parseWatcherPosition(%q) = %d, %v, want %d", tc.in, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{"", "soon", "-1", "-5"} {
+		if _, err := parseWatcherPosition(in, cursor, now); err == nil {
+			t.Errorf("parseWatcherPosition(%q) succeeded, want error", in)
+		}
+	}
+}
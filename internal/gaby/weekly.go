@@ -0,0 +1,103 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/oscar/internal/entity"
+	"golang.org/x/oscar/internal/weekly"
+)
+
+// weeklyPage holds the fields needed to display the weekly
+// report of a project.
+type weeklyPage struct {
+	CommonPage
+
+	Params weeklyParams   // the raw parameters
+	Report *weekly.Report // the report to display
+	Error  error          // if non-nil, the error to display instead of the report
+}
+
+// weeklyParams holds the raw inputs to the weekly form.
+type weeklyParams struct {
+	Project string // the GitHub project
+}
+
+var weeklyPageTmpl = newTemplate(weeklyTmplFile, template.FuncMap{})
+
+func (g *Gaby) handleWeekly(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateWeeklyPage(r), weeklyPageTmpl)
+}
+
+// populateWeeklyPage returns the contents of the weekly page.
+// It shows the last report computed for the project in the
+// "project" form value, by default the first GitHub project.
+func (g *Gaby) populateWeeklyPage(r *http.Request) *weeklyPage {
+	p := &weeklyPage{
+		Params: weeklyParams{Project: r.FormValue(paramProject)},
+	}
+	if p.Params.Project == "" && len(g.githubProjects) > 0 {
+		p.Params.Project = g.githubProjects[0]
+	}
+	p.setCommonPage()
+	if !slices.Contains(g.githubProjects, p.Params.Project) {
+		p.Error = fmt.Errorf("unknown project %q", p.Params.Project)
+		return p
+	}
+	rep, ok := g.weekly.Latest(p.Params.Project)
+	if !ok {
+		p.Error = fmt.Errorf("no weekly report for %s yet; reports are computed after each week (Monday to Monday, UTC)", p.Params.Project)
+		return p
+	}
+	p.Report = rep
+	return p
+}
+
+func (p *weeklyPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          weeklyID,
+		Description: "Read the digest of last week's activity in a project: new issues grouped by theme, hot discussions and closed issues.",
+		Form: Form{
+			Inputs: []FormInput{
+				{
+					Label:       "project",
+					Type:        "string",
+					Description: "the GitHub project to report on (e.g. golang/go)",
+					Name:        safeProject,
+					Typed: TextInput{
+						ID:    safeProject,
+						Value: p.Params.Project,
+					},
+				},
+			},
+			SubmitText: "report",
+		},
+	}
+}
+
+// parseWeeklyDiscussion parses the argument to the -weeklydiscussion flag,
+// a GitHub discussion such as "golang/go#12345".
+// It returns the discussion's project and number.
+func parseWeeklyDiscussion(s string) (project string, discussion int64, err error) {
+	id, err := entity.ParseIssue(s)
+	if err != nil || id.Project == "" {
+		return "", 0, fmt.Errorf("invalid arg %q to -weeklydiscussion: want OWNER/REPO#NUMBER", s)
+	}
+	return id.Project, id.Number, nil
+}
+
+// reportWeekly computes the weekly reports of the last full week,
+// and logs actions to post them if -weeklydiscussion is set.
+func (g *Gaby) reportWeekly(ctx context.Context) error {
+	g.db.Lock(gabyWeeklyLock)
+	defer g.db.Unlock(gabyWeeklyLock)
+
+	return g.weekly.Run(ctx)
+}
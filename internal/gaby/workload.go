@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/oscar/internal/workload"
+)
+
+// workloadPage holds the fields needed to display the workload
+// report of a project.
+type workloadPage struct {
+	CommonPage
+
+	Params workloadParams   // the raw parameters
+	Report *workload.Report // the report to display
+	Error  error            // if non-nil, the error to display instead of the report
+}
+
+// workloadParams holds the raw inputs to the workload form.
+type workloadParams struct {
+	Project string // the GitHub project
+}
+
+const paramProject = "project"
+
+var safeProject = toSafeID(paramProject)
+
+var workloadPageTmpl = newTemplate(workloadTmplFile, template.FuncMap{})
+
+func (g *Gaby) handleWorkload(w http.ResponseWriter, r *http.Request) {
+	handlePage(w, g.populateWorkloadPage(r), workloadPageTmpl)
+}
+
+// populateWorkloadPage returns the contents of the workload page.
+// It shows the last report computed for the project in the
+// "project" form value, by default the first GitHub project.
+func (g *Gaby) populateWorkloadPage(r *http.Request) *workloadPage {
+	p := &workloadPage{
+		Params: workloadParams{Project: r.FormValue(paramProject)},
+	}
+	if p.Params.Project == "" && len(g.githubProjects) > 0 {
+		p.Params.Project = g.githubProjects[0]
+	}
+	p.setCommonPage()
+	if !slices.Contains(g.githubProjects, p.Params.Project) {
+		p.Error = fmt.Errorf("unknown project %q", p.Params.Project)
+		return p
+	}
+	rep, ok := g.workload.Report(p.Params.Project)
+	if !ok {
+		p.Error = fmt.Errorf("no workload report for %s yet; reports are computed daily", p.Params.Project)
+		return p
+	}
+	p.Report = rep
+	return p
+}
+
+func (p *workloadPage) setCommonPage() {
+	p.CommonPage = CommonPage{
+		ID:          workloadID,
+		Description: "Compare the open-issue and review load of each maintainer area and maintainer, to help rebalance triage rotations.",
+		Form: Form{
+			Inputs: []FormInput{
+				{
+					Label:       "project",
+					Type:        "string",
+					Description: "the GitHub project to report on (e.g. golang/go)",
+					Name:        safeProject,
+					Typed: TextInput{
+						ID:    safeProject,
+						Value: p.Params.Project,
+					},
+				},
+			},
+			SubmitText: "report",
+		},
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gc deletes data that is no longer needed from the database,
+// according to a [Policy]: cached LLM responses past their time to live,
+// the entries of old completed actions, superseded overview history,
+// and the vectors of documents deleted from the corpus.
+//
+// A [Collector] can report what it would delete without deleting anything
+// (a dry run), and it can archive what it deletes as a backup
+// (see [storage.Export]), from which [storage.Import] restores the data.
+//
+// The data is deleted by the packages that own it
+// (see [llmapp.DeleteExpiredCache], [actions.DeleteBefore] and
+// [overview.DeleteSuperseded]), through a [storage.DB] that
+// records, archives and, in a dry run, drops their deletions.
+package gc
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/overview"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A Policy says which data a [Collector] deletes.
+// A zero field leaves the corresponding data alone.
+type Policy struct {
+	LLMCacheTTL  time.Duration // delete cached LLM responses older than this
+	ActionLogAge time.Duration // delete the entries of actions completed longer ago than this
+	OverviewAge  time.Duration // delete superseded overview history entries older than this
+	Vectors      bool          // delete the vectors of documents no longer in the corpus
+}
+
+// The kinds of data a [Collector] deletes.
+const (
+	KindLLMCache  = "llmcache"  // cached LLM responses
+	KindActionLog = "actionlog" // action log entries
+	KindOverview  = "overview"  // overview history entries
+	KindVectors   = "vectors"   // document vectors
+)
+
+// A KindReport describes the data of one kind deleted by a [Collector]
+// (or that would be deleted, in a dry run).
+type KindReport struct {
+	Kind    string // one of the Kind constants
+	Items   int64  // the number of responses, actions, overviews or documents
+	Entries int64  // the number of database entries and vectors
+	Bytes   int64  // the size of their keys and values (for vectors, IDs and 4 bytes per dimension)
+}
+
+// A Report describes a run of a [Collector].
+type Report struct {
+	DryRun bool          // whether nothing was deleted
+	Kinds  []*KindReport // in the order of the Kind constants, omitting those the Policy leaves alone
+}
+
+// Bytes returns the total size of the data in the report.
+func (r *Report) Bytes() int64 {
+	var n int64
+	for _, k := range r.Kinds {
+		n += k.Bytes
+	}
+	return n
+}
+
+// A Collector deletes data from a database according to a [Policy].
+type Collector struct {
+	slog   *slog.Logger
+	db     storage.DB
+	policy Policy
+	now    func() time.Time
+	index  *embeddocs.Index
+	docs   *docs.Corpus
+
+	mu     sync.Mutex
+	totals map[string]KindReport // by kind
+}
+
+// New returns a new Collector for db that deletes data according to policy.
+func New(lg *slog.Logger, db storage.DB, policy Policy) *Collector {
+	return &Collector{
+		slog:   lg,
+		db:     db,
+		policy: policy,
+		now:    time.Now,
+		totals: make(map[string]KindReport),
+	}
+}
+
+// SetIndex sets the index whose vectors of documents missing
+// from dc the Collector deletes, if its Policy says to.
+// Only the vectors of the active space are deleted.
+func (c *Collector) SetIndex(ix *embeddocs.Index, dc *docs.Corpus) {
+	c.index = ix
+	c.docs = dc
+}
+
+// Totals returns the data deleted by the Collector since it was created,
+// not counting dry runs, by kind.
+func (c *Collector) Totals() []KindReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ks []KindReport
+	for _, kind := range []string{KindLLMCache, KindActionLog, KindOverview, KindVectors} {
+		if k, ok := c.totals[kind]; ok {
+			ks = append(ks, k)
+		}
+	}
+	return ks
+}
+
+// Run deletes the data the Policy says to delete,
+// or in a dry run, only reports it.
+// If archive is non-nil, Run writes the data it deletes
+// (or would delete) to archive as a backup.
+//
+// Run returns an error only if writing to archive fails,
+// in which case it stops deleting data, so that nothing is
+// deleted without being archived.
+func (c *Collector) Run(dryRun bool, archive io.Writer) (_ *Report, err error) {
+	start := c.now()
+	r := &Report{DryRun: dryRun}
+	rdb := &recordDB{DB: c.db, dryRun: dryRun}
+	vectors := c.policy.Vectors && c.index != nil
+	if archive != nil {
+		var namespaces []string
+		if vectors {
+			namespaces = []string{c.index.Active().Namespace}
+		}
+		if rdb.archive, err = storage.NewBackupWriter(archive, namespaces); err != nil {
+			return nil, fmt.Errorf("gc: archive: %w", err)
+		}
+	}
+
+	collect := func(kind string, f func() int) {
+		if rdb.err != nil {
+			return
+		}
+		k := &KindReport{Kind: kind}
+		rdb.kind = k
+		k.Items = int64(f())
+		r.Kinds = append(r.Kinds, k)
+		c.slog.Info("gc collect", "kind", kind, "dryrun", dryRun, "items", k.Items, "entries", k.Entries, "bytes", k.Bytes)
+	}
+	if ttl := c.policy.LLMCacheTTL; ttl > 0 {
+		collect(KindLLMCache, func() int { return llmapp.DeleteExpiredCache(rdb, start.Add(-ttl)) })
+	}
+	if age := c.policy.ActionLogAge; age > 0 {
+		collect(KindActionLog, func() int { return actions.DeleteBefore(rdb, start.Add(-age)) })
+	}
+	if age := c.policy.OverviewAge; age > 0 {
+		collect(KindOverview, func() int { return overview.DeleteSuperseded(rdb, start.Add(-age)) })
+	}
+	if vectors {
+		collect(KindVectors, func() int { return c.deleteVectors(rdb) })
+	}
+
+	if rdb.archive != nil && rdb.err == nil {
+		_, rdb.err = rdb.archive.Close()
+	}
+	if !dryRun {
+		c.mu.Lock()
+		for _, k := range r.Kinds {
+			t := c.totals[k.Kind]
+			t.Kind = k.Kind
+			t.Items += k.Items
+			t.Entries += k.Entries
+			t.Bytes += k.Bytes
+			c.totals[k.Kind] = t
+		}
+		c.mu.Unlock()
+	}
+	if rdb.err != nil {
+		return r, fmt.Errorf("gc: archive: %w", rdb.err)
+	}
+	return r, nil
+}
+
+// deleteVectors deletes the vectors of the documents missing from
+// the corpus, recording them in rdb.kind. It returns the number of
+// documents whose vectors were deleted.
+func (c *Collector) deleteVectors(rdb *recordDB) int {
+	ns := c.index.Active().Namespace
+	var missing []string
+	for id, vec := range c.index.All() {
+		if _, ok := c.docs.Get(id); ok {
+			continue
+		}
+		v := vec()
+		if rdb.archive != nil {
+			if err := rdb.archive.WriteVector(ns, id, v); err != nil {
+				rdb.err = err
+				return len(missing)
+			}
+		}
+		rdb.kind.Entries++
+		rdb.kind.Bytes += int64(len(id) + 4*len(v))
+		missing = append(missing, id)
+	}
+	if !rdb.dryRun {
+		// Delete also deletes the vectors of the document's chunks.
+		for _, id := range missing {
+			c.index.Delete(id)
+		}
+		c.index.Flush()
+	}
+	return len(missing)
+}
+
+// A recordDB is a [storage.DB] that records the entries deleted
+// through it in a [KindReport] and writes them to an archive.
+// In a dry run, it drops all changes.
+// After an archive error, it drops all changes,
+// so that no entry is deleted without being archived.
+type recordDB struct {
+	storage.DB
+	dryRun  bool
+	kind    *KindReport           // report for the current kind
+	archive *storage.BackupWriter // nil if not archiving
+	err     error                 // first archive error
+}
+
+// delete records the deletion of key and reports whether to delete it.
+func (r *recordDB) delete(key []byte) bool {
+	val, ok := r.DB.Get(key)
+	if !ok || r.err != nil {
+		return false
+	}
+	if r.archive != nil {
+		if err := r.archive.WritePair(key, val); err != nil {
+			r.err = err
+			return false
+		}
+	}
+	r.kind.Entries++
+	r.kind.Bytes += int64(len(key) + len(val))
+	return !r.dryRun
+}
+
+// write reports whether to make changes other than deletions.
+func (r *recordDB) write() bool {
+	return !r.dryRun && r.err == nil
+}
+
+func (r *recordDB) Set(key, val []byte) {
+	if r.write() {
+		r.DB.Set(key, val)
+	}
+}
+
+func (r *recordDB) Delete(key []byte) {
+	if r.delete(key) {
+		r.DB.Delete(key)
+	}
+}
+
+func (r *recordDB) DeleteRange(start, end []byte) {
+	for key := range r.DB.Scan(start, end) {
+		r.Delete(key)
+	}
+}
+
+func (r *recordDB) Batch() storage.Batch {
+	return &recordBatch{Batch: r.DB.Batch(), r: r}
+}
+
+// A recordBatch is a [storage.Batch] of a [recordDB].
+type recordBatch struct {
+	storage.Batch
+	r *recordDB
+}
+
+func (b *recordBatch) Set(key, val []byte) {
+	if b.r.write() {
+		b.Batch.Set(key, val)
+	}
+}
+
+func (b *recordBatch) Delete(key []byte) {
+	if b.r.delete(key) {
+		b.Batch.Delete(key)
+	}
+}
+
+func (b *recordBatch) DeleteRange(start, end []byte) {
+	for key := range b.r.DB.Scan(start, end) {
+		b.Delete(key)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+	"rsc.io/ordered"
+)
+
+type testActioner struct{}
+
+func (testActioner) Run(context.Context, []byte) ([]byte, error) { return nil, nil }
+func (testActioner) ForDisplay([]byte) string                    { return "" }
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+
+	// An action that is done, a cached LLM response,
+	// and the vectors of a document and of a deleted document.
+	before := actions.Register("gc.test", testActioner{})
+	before(db, ordered.Encode("k"), []byte("action"), false)
+	check(actions.Run(ctx, lg, db))
+	_, err := llmapp.New(lg, llm.EchoContentGenerator(), db).Overview(ctx, &llmapp.Doc{Text: "hello"})
+	check(err)
+	dc := docs.New(lg, db)
+	dc.Add("doc", "title", "text")
+	ix := embeddocs.NewIndex(lg, db, "test", "test/model", func(ns string) (storage.VectorDB, error) {
+		return storage.MemVectorDB(db, lg, ns), nil
+	})
+	ix.Set("doc", llm.Vector{1, 0})
+	ix.Set("gone", llm.Vector{0, 1})
+
+	c := New(lg, db, Policy{LLMCacheTTL: time.Hour, ActionLogAge: time.Hour, OverviewAge: time.Hour, Vectors: true})
+	c.SetIndex(ix, dc)
+	c.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	checkReport := func(r *Report, dryRun bool) {
+		t.Helper()
+		if r.DryRun != dryRun {
+			t.Errorf("DryRun = %v, want %v", r.DryRun, dryRun)
+		}
+		var kinds []string
+		for _, k := range r.Kinds {
+			kinds = append(kinds, k.Kind)
+			want := int64(1)
+			if k.Kind == KindOverview {
+				want = 0
+			}
+			if k.Items != want || (want > 0) != (k.Entries > 0 && k.Bytes > 0) {
+				t.Errorf("%s: %+v, want %d items", k.Kind, k, want)
+			}
+		}
+		if want := []string{KindLLMCache, KindActionLog, KindOverview, KindVectors}; !slices.Equal(kinds, want) {
+			t.Errorf("kinds = %v, want %v", kinds, want)
+		}
+	}
+
+	// A dry run deletes nothing.
+	snapshot := dump(db)
+	r, err := c.Run(true, nil)
+	check(err)
+	checkReport(r, true)
+	if !slices.Equal(dump(db), snapshot) {
+		t.Errorf("dry run changed the database")
+	}
+	if len(c.Totals()) != 0 {
+		t.Errorf("Totals after dry run = %v, want none", c.Totals())
+	}
+
+	// A run deletes the data and archives it.
+	var archive bytes.Buffer
+	r, err = c.Run(false, &archive)
+	check(err)
+	checkReport(r, false)
+	// Only the tombstone of the action log entry remains.
+	if e, ok := actions.Get(db, "gc.test", ordered.Encode("k")); !ok || e.Action != nil || !e.IsDone() {
+		t.Errorf("action log entry not deleted to a tombstone: %v, %t", e, ok)
+	}
+	if _, ok := ix.Get("gone"); ok {
+		t.Errorf("vector of deleted document not deleted")
+	}
+	if _, ok := ix.Get("doc"); !ok {
+		t.Errorf("vector of document deleted")
+	}
+	var totalBytes int64
+	for _, k := range c.Totals() {
+		totalBytes += k.Bytes
+	}
+	if totalBytes != r.Bytes() {
+		t.Errorf("Totals() bytes = %d, want %d", totalBytes, r.Bytes())
+	}
+
+	// Nothing is left to delete.
+	r, err = c.Run(false, nil)
+	check(err)
+	if r.Bytes() != 0 {
+		t.Errorf("second run: %d bytes, want 0", r.Bytes())
+	}
+
+	// The archive restores the deleted data.
+	vdb := storage.MemVectorDB(storage.MemDB(), lg, "")
+	stats, err := storage.Import(&archive, db, func(string) storage.VectorDB { return vdb })
+	check(err)
+	if stats.Vectors != 1 || stats.Pairs == 0 {
+		t.Errorf("Import archive = %+v, want pairs and 1 vector", stats)
+	}
+	if e, ok := actions.Get(db, "gc.test", ordered.Encode("k")); !ok || string(e.Action) != "action" {
+		t.Errorf("action log entry not restored")
+	}
+	if _, ok := vdb.Get("gone"); !ok {
+		t.Errorf("vector not restored")
+	}
+}
+
+// dump returns the keys and values in db.
+func dump(db storage.DB) []string {
+	var kvs []string
+	for key, val := range db.Scan(nil, ordered.Encode(ordered.Inf)) {
+		kvs = append(kvs, storage.Fmt(key)+" "+storage.Fmt(val()))
+	}
+	return kvs
+}
@@ -141,7 +141,22 @@ func (db *VectorDB) All() iter.Seq2[string, func() llm.Vector] {
 }
 
 // Search implements [storage.VectorDB.Search].
-func (db *VectorDB) Search(vec llm.Vector, n int) []storage.VectorResult {
+// The filter keep is evaluated on the client, not by Firestore,
+// so a restrictive filter may take several queries
+// (see [storage.SearchFiltered]), and Search finds at most
+// the results kept among the [maxNearest] nearest vectors.
+func (db *VectorDB) Search(vec llm.Vector, n int, keep storage.VectorFilter) []storage.VectorResult {
+	return storage.SearchFiltered(n, keep, func(n int) []storage.VectorResult {
+		return db.search(vec, min(n, maxNearest))
+	})
+}
+
+// maxNearest is the maximum number of results of a
+// Firestore nearest-neighbor query.
+const maxNearest = 1000
+
+// search returns the n vectors most similar to vec.
+func (db *VectorDB) search(vec llm.Vector, n int) []storage.VectorResult {
 	q := db.coll.FindNearest("Embedding", firestore.Vector32(vec), n, firestore.DistanceMeasureDotProduct, nil)
 	iter := q.Documents(context.TODO())
 	defer iter.Stop()
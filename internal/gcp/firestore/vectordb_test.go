@@ -30,6 +30,9 @@ func TestVectorDB(t *testing.T) {
 		}
 		return vdb
 	})
+	// TODO: also run storage.TestVectorDBFilter, which needs
+	// the RPCs to be re-recorded; the filtering itself is
+	// storage.SearchFiltered, which is tested in package storage.
 }
 
 // TestDBLimit checks that [VectorDB.All] properly restarts from query limits (see docLimit).
@@ -0,0 +1,318 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+)
+
+// The genai package does not support Gemini's batch mode,
+// so this file calls the REST API directly.
+// See https://ai.google.dev/gemini-api/docs/batch-mode.
+
+// batchServer is the base URL of the batch API.
+const batchServer = "https://generativelanguage.googleapis.com/v1beta"
+
+var _ llm.BatchContentGenerator = (*Client)(nil)
+
+// SubmitBatch starts asynchronous generation for the requests
+// with the client's generative model, implementing
+// [llm.BatchContentGenerator.SubmitBatch].
+// The returned id is the name of the batch, such as "batches/123".
+func (c *Client) SubmitBatch(ctx context.Context, reqs []*llm.BatchRequest) (string, error) {
+	var inlined []batchInlinedRequest
+	for i, r := range reqs {
+		req, err := c.batchRequest(r)
+		if err != nil {
+			return "", fmt.Errorf("gemini.SubmitBatch: request %d: %w", i, err)
+		}
+		inlined = append(inlined, batchInlinedRequest{
+			Request:  req,
+			Metadata: batchMetadata{Key: strconv.Itoa(i)},
+		})
+	}
+	var body struct {
+		Batch struct {
+			DisplayName string `json:"displayName"`
+			InputConfig struct {
+				Requests struct {
+					Requests []batchInlinedRequest `json:"requests"`
+				} `json:"requests"`
+			} `json:"inputConfig"`
+		} `json:"batch"`
+	}
+	body.Batch.DisplayName = "oscar"
+	body.Batch.InputConfig.Requests.Requests = inlined
+
+	var op batchOperation
+	if err := c.batchCall(ctx, http.MethodPost, "models/"+c.generativeModel+":batchGenerateContent", body, &op); err != nil {
+		return "", fmt.Errorf("gemini.SubmitBatch: %w", err)
+	}
+	if op.Name == "" {
+		return "", fmt.Errorf("gemini.SubmitBatch: no batch name in response")
+	}
+	return op.Name, nil
+}
+
+// BatchResult reports the status of the batch with the given id,
+// implementing [llm.BatchContentGenerator.BatchResult].
+func (c *Client) BatchResult(ctx context.Context, id string) (*llm.BatchResult, error) {
+	if !strings.HasPrefix(id, "batches/") {
+		return nil, fmt.Errorf("gemini.BatchResult: invalid batch id %q", id)
+	}
+	var op batchOperation
+	if err := c.batchCall(ctx, http.MethodGet, id, nil, &op); err != nil {
+		return nil, fmt.Errorf("gemini.BatchResult: %w", err)
+	}
+	state := op.Metadata.State
+	switch {
+	case op.Error != nil:
+		return &llm.BatchResult{State: llm.BatchFailed, Error: op.Error.Message}, nil
+	case strings.HasSuffix(state, "_FAILED"), strings.HasSuffix(state, "_CANCELLED"), strings.HasSuffix(state, "_EXPIRED"):
+		return &llm.BatchResult{State: llm.BatchFailed, Error: state}, nil
+	case !op.Done || !strings.HasSuffix(state, "_SUCCEEDED"):
+		return &llm.BatchResult{State: llm.BatchRunning}, nil
+	}
+
+	// The responses carry the keys of their requests,
+	// which are the indexes assigned by SubmitBatch.
+	inlined := op.Response.InlinedResponses.InlinedResponses
+	resps := make([]llm.BatchResponse, len(inlined))
+	seen := make([]bool, len(inlined))
+	for i, ir := range inlined {
+		if ir.Metadata.Key != "" {
+			k, err := strconv.Atoi(ir.Metadata.Key)
+			if err != nil || k < 0 || k >= len(resps) || seen[k] {
+				return nil, fmt.Errorf("gemini.BatchResult: bad response key %q", ir.Metadata.Key)
+			}
+			i = k
+		}
+		seen[i] = true
+		switch {
+		case ir.Error != nil:
+			resps[i].Error = ir.Error.Message
+		case ir.Response != nil:
+			if texts := ir.Response.texts(); len(texts) > 0 {
+				resps[i].Text = strings.Join(texts, "\n")
+				break
+			}
+			fallthrough
+		default:
+			resps[i].Error = "no content generated"
+		}
+	}
+	return &llm.BatchResult{State: llm.BatchDone, Responses: resps}, nil
+}
+
+// batchRequest returns the REST form of the request r,
+// configured like the model used by [Client.GenerateContent].
+func (c *Client) batchRequest(r *llm.BatchRequest) (*batchGenerateRequest, error) {
+	req := &batchGenerateRequest{}
+	req.GenerationConfig.CandidateCount = 1
+	req.GenerationConfig.ResponseMIMEType = "text/plain"
+	if r.Schema != nil {
+		req.GenerationConfig.ResponseMIMEType = "application/json"
+		req.GenerationConfig.ResponseSchema = toRESTSchema(r.Schema)
+	}
+	if c.temperature >= 0 {
+		t := c.temperature
+		req.GenerationConfig.Temperature = &t
+	}
+	content := batchContent{Role: "user"}
+	for _, p := range r.Parts {
+		switch p := p.(type) {
+		case llm.Text:
+			content.Parts = append(content.Parts, batchPart{Text: string(p)})
+		case llm.Blob:
+			content.Parts = append(content.Parts, batchPart{InlineData: &batchBlob{
+				MIMEType: p.MIMEType,
+				Data:     base64.StdEncoding.EncodeToString(p.Data),
+			}})
+		default:
+			return nil, fmt.Errorf("bad type for part: %T; need llm.Text or llm.Blob", p)
+		}
+	}
+	req.Contents = []batchContent{content}
+	return req, nil
+}
+
+// batchCall makes a request with the given method to the batch API
+// at path, sending req (if non-nil) and decoding the reply into resp.
+func (c *Client) batchCall(ctx context.Context, method, path string, req, resp any) error {
+	var body io.Reader
+	if req != nil {
+		js, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(js)
+	}
+	hreq, err := http.NewRequestWithContext(ctx, method, c.batchURL.JoinPath(path).String(), body)
+	if err != nil {
+		return err
+	}
+	if req != nil {
+		hreq.Header.Set("Content-Type", "application/json")
+	}
+	hresp, err := c.hc.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+	data, err := io.ReadAll(hresp.Body)
+	if err != nil {
+		return err
+	}
+	if hresp.StatusCode != http.StatusOK {
+		// Google APIs return JSON with an error object on failure.
+		var e struct {
+			Error batchStatus `json:"error"`
+		}
+		herr := &llm.HTTPError{StatusCode: hresp.StatusCode, Status: hresp.Status}
+		if json.Unmarshal(data, &e) == nil {
+			herr.Message = e.Error.Message
+		}
+		return herr
+	}
+	return json.Unmarshal(data, resp)
+}
+
+// restTypes maps [llm.Type] values to their names in the REST API.
+var restTypes = map[llm.Type]string{
+	llm.TypeString:  "STRING",
+	llm.TypeNumber:  "NUMBER",
+	llm.TypeInteger: "INTEGER",
+	llm.TypeBoolean: "BOOLEAN",
+	llm.TypeArray:   "ARRAY",
+	llm.TypeObject:  "OBJECT",
+}
+
+// toRESTSchema converts an [llm.Schema] to its REST form.
+func toRESTSchema(s *llm.Schema) *restSchema {
+	if s == nil {
+		return nil
+	}
+	var props map[string]*restSchema
+	if len(s.Properties) != 0 {
+		props = make(map[string]*restSchema, len(s.Properties))
+		for k, v := range s.Properties {
+			props[k] = toRESTSchema(v)
+		}
+	}
+	return &restSchema{
+		Type:        restTypes[s.Type],
+		Format:      s.Format,
+		Description: s.Description,
+		Nullable:    s.Nullable,
+		Enum:        s.Enum,
+		Items:       toRESTSchema(s.Items),
+		Properties:  props,
+		Required:    s.Required,
+	}
+}
+
+// The types below are the parts of the REST API's JSON messages
+// used by this package.
+
+type restSchema struct {
+	Type        string                 `json:"type,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Nullable    bool                   `json:"nullable,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	Items       *restSchema            `json:"items,omitempty"`
+	Properties  map[string]*restSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+}
+
+type batchGenerateRequest struct {
+	Contents         []batchContent `json:"contents"`
+	GenerationConfig struct {
+		CandidateCount   int         `json:"candidateCount"`
+		ResponseMIMEType string      `json:"responseMimeType"`
+		ResponseSchema   *restSchema `json:"responseSchema,omitempty"`
+		Temperature      *float32    `json:"temperature,omitempty"`
+	} `json:"generationConfig"`
+}
+
+type batchContent struct {
+	Role  string      `json:"role,omitempty"`
+	Parts []batchPart `json:"parts"`
+}
+
+type batchPart struct {
+	Text       string     `json:"text,omitempty"`
+	InlineData *batchBlob `json:"inlineData,omitempty"`
+}
+
+type batchBlob struct {
+	MIMEType string `json:"mimeType"`
+	Data     string `json:"data"` // base64
+}
+
+type batchInlinedRequest struct {
+	Request  *batchGenerateRequest `json:"request"`
+	Metadata batchMetadata         `json:"metadata"`
+}
+
+type batchMetadata struct {
+	Key string `json:"key"`
+}
+
+type batchStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// A batchOperation is the long-running operation for a batch.
+type batchOperation struct {
+	Name     string       `json:"name"`
+	Done     bool         `json:"done"`
+	Error    *batchStatus `json:"error"`
+	Metadata struct {
+		State string `json:"state"` // such as "BATCH_STATE_SUCCEEDED"
+	} `json:"metadata"`
+	Response struct {
+		InlinedResponses struct {
+			InlinedResponses []struct {
+				Response *batchGenerateResponse `json:"response"`
+				Error    *batchStatus           `json:"error"`
+				Metadata batchMetadata          `json:"metadata"`
+			} `json:"inlinedResponses"`
+		} `json:"inlinedResponses"`
+	} `json:"response"`
+}
+
+type batchGenerateResponse struct {
+	Candidates []struct {
+		Content *batchContent `json:"content"`
+	} `json:"candidates"`
+}
+
+// texts returns the text parts of the response,
+// like [responses] does for a synchronous response.
+func (r *batchGenerateResponse) texts() []string {
+	var texts []string
+	for _, c := range r.Candidates {
+		if c.Content != nil {
+			for _, p := range c.Content.Parts {
+				if p.Text != "" {
+					texts = append(texts, p.Text)
+				}
+			}
+		}
+	}
+	return texts
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gemini
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// newBatchTestClient returns a client whose batch API is a fake server
+// that calls handle for each request.
+func newBatchTestClient(t *testing.T, handle func(method, path string, req map[string]any) (int, any)) *Client {
+	check := testutil.Checker(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Goog-Api-Key"); got != "test-key" {
+			t.Errorf("X-Goog-Api-Key = %q, want test-key", got)
+		}
+		var req map[string]any
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Error(err)
+			}
+		}
+		code, resp := handle(r.Method, r.URL.Path, req)
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	sdb := secret.Map{"ai.google.dev": "test-key"}
+	c, err := NewClient(ctx, testutil.Slogger(t), sdb, srv.Client(), DefaultEmbeddingModel, DefaultGenerativeModel)
+	check(err)
+	c.batchURL, err = url.Parse(srv.URL + "/v1beta")
+	check(err)
+	return c
+}
+
+func TestBatch(t *testing.T) {
+	state := "BATCH_STATE_RUNNING"
+	c := newBatchTestClient(t, func(method, path string, req map[string]any) (int, any) {
+		switch {
+		case method == "POST" && path == "/v1beta/models/"+DefaultGenerativeModel+":batchGenerateContent":
+			reqs := req["batch"].(map[string]any)["inputConfig"].(map[string]any)["requests"].(map[string]any)["requests"].([]any)
+			if len(reqs) != 2 {
+				t.Errorf("got %d requests, want 2", len(reqs))
+			}
+			second := reqs[1].(map[string]any)
+			cfg := second["request"].(map[string]any)["generationConfig"].(map[string]any)
+			if cfg["responseMimeType"] != "application/json" || cfg["responseSchema"].(map[string]any)["type"] != "OBJECT" {
+				t.Errorf("JSON request config = %v", cfg)
+			}
+			return 200, map[string]any{"name": "batches/b1"}
+		case method == "GET" && path == "/v1beta/batches/b1":
+			op := map[string]any{"name": "batches/b1", "metadata": map[string]any{"state": state}}
+			if state == "BATCH_STATE_SUCCEEDED" {
+				op["done"] = true
+				// Respond out of order; the keys say which is which.
+				op["response"] = map[string]any{"inlinedResponses": map[string]any{"inlinedResponses": []any{
+					map[string]any{"metadata": map[string]any{"key": "1"}, "error": map[string]any{"code": 400, "message": "bad"}},
+					map[string]any{"metadata": map[string]any{"key": "0"}, "response": map[string]any{
+						"candidates": []any{map[string]any{"content": map[string]any{"parts": []any{map[string]any{"text": "hello"}}}}},
+					}},
+				}}}
+			}
+			return 200, op
+		}
+		return 404, map[string]any{"error": map[string]any{"code": 404, "message": "not found"}}
+	})
+
+	id, err := c.SubmitBatch(ctx, []*llm.BatchRequest{
+		{Parts: []llm.Part{llm.Text("hi")}},
+		{Schema: &llm.Schema{Type: llm.TypeObject}, Parts: []llm.Part{llm.Text("json")}},
+	})
+	if err != nil || id != "batches/b1" {
+		t.Fatalf("SubmitBatch = %q, %v, want batches/b1", id, err)
+	}
+
+	r, err := c.BatchResult(ctx, id)
+	if err != nil || r.State != llm.BatchRunning {
+		t.Fatalf("BatchResult = %+v, %v, want running", r, err)
+	}
+
+	state = "BATCH_STATE_SUCCEEDED"
+	r, err = c.BatchResult(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &llm.BatchResult{State: llm.BatchDone, Responses: []llm.BatchResponse{{Text: "hello"}, {Error: "bad"}}}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("BatchResult = %+v, want %+v", r, want)
+	}
+
+	state = "BATCH_STATE_EXPIRED"
+	if r, err := c.BatchResult(ctx, id); err != nil || r.State != llm.BatchFailed {
+		t.Errorf("BatchResult = %+v, %v, want failed", r, err)
+	}
+	if _, err := c.BatchResult(ctx, "batches/nope"); err == nil {
+		t.Errorf("BatchResult of unknown batch succeeded")
+	}
+}
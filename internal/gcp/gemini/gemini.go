@@ -4,7 +4,8 @@
 
 // Package gemini implements access to Google's Gemini model.
 //
-// [Client] implements [llm.Embedder] and [llm.GenerateContent]. Use [NewClient] to connect.
+// [Client] implements [llm.Embedder], [llm.GenerateContent] and
+// [llm.BatchContentGenerator]. Use [NewClient] to connect.
 package gemini
 
 import (
@@ -16,6 +17,7 @@ import (
 	"log/slog"
 	"maps"
 	"net/http"
+	"net/url"
 	"slices"
 	"strings"
 
@@ -52,6 +54,8 @@ func Scrub(req *http.Request) error {
 type Client struct {
 	slog                            *slog.Logger
 	genai                           *genai.Client
+	hc                              *http.Client // adds the API key; used for batches
+	batchURL                        *url.URL     // base URL of the batch API
 	embeddingModel, generativeModel string
 	temperature                     float32 // negative means use default
 }
@@ -83,9 +87,14 @@ func NewClient(ctx context.Context, lg *slog.Logger, sdb secret.DB, hc *http.Cli
 	// otherwise NewClient complains that we haven't passed in a key.
 	// (If we pass in the key, it ignores it, but if we don't pass it in,
 	// it complains that we didn't give it a key.)
+	hc = withKey(hc, key)
 	ai, err := genai.NewClient(ctx,
 		option.WithAPIKey("ignored"),
-		option.WithHTTPClient(withKey(hc, key)))
+		option.WithHTTPClient(hc))
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(batchServer)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +102,8 @@ func NewClient(ctx context.Context, lg *slog.Logger, sdb secret.DB, hc *http.Cli
 	return &Client{
 		slog:            lg,
 		genai:           ai,
+		hc:              hc,
+		batchURL:        u,
 		embeddingModel:  embeddingModel,
 		generativeModel: generativeModel,
 		temperature:     -1,
@@ -184,6 +195,80 @@ func (c *Client) GenerateContent(ctx context.Context, schema *llm.Schema, prompt
 	return texts[0], nil
 }
 
+var _ llm.FunctionCaller = (*Client)(nil)
+
+// GenerateFunctionCalls returns the model's response for the prompt parts,
+// in which the model may ask to call any of the declared functions,
+// implementing [llm.FunctionCaller.GenerateFunctionCalls].
+func (c *Client) GenerateFunctionCalls(ctx context.Context, funcs []*llm.FunctionDeclaration, promptParts []llm.Part) (*llm.CallResponse, error) {
+	history, err := c.contents(promptParts)
+	if err != nil {
+		return nil, fmt.Errorf("gemini.GenerateFunctionCalls: %w", err)
+	}
+	if len(history) == 0 {
+		return nil, errors.New("gemini.GenerateFunctionCalls: empty prompt")
+	}
+	model := c.model("text/plain", nil)
+	model.Tools = []*genai.Tool{{FunctionDeclarations: toGenAIFunctions(funcs)}}
+	model.ToolConfig = &genai.ToolConfig{
+		FunctionCallingConfig: &genai.FunctionCallingConfig{Mode: genai.FunctionCallingAuto},
+	}
+	chat := model.StartChat()
+	last := history[len(history)-1]
+	chat.History = history[:len(history)-1]
+	resp, err := chat.SendMessage(ctx, last.Parts...)
+	if err != nil {
+		return nil, fmt.Errorf("gemini.GenerateFunctionCalls: %w", err)
+	}
+	r := new(llm.CallResponse)
+	for _, cand := range resp.Candidates {
+		for _, fc := range cand.FunctionCalls() {
+			r.Calls = append(r.Calls, llm.FunctionCall{Name: fc.Name, Args: fc.Args})
+		}
+	}
+	r.Text = strings.Join(responses(resp), "\n")
+	if len(r.Calls) == 0 && r.Text == "" {
+		return nil, errors.New("gemini.GenerateFunctionCalls: no content generated")
+	}
+	return r, nil
+}
+
+// toGenAIFunctions converts the function declarations to
+// their [genai.FunctionDeclaration] equivalents.
+func toGenAIFunctions(funcs []*llm.FunctionDeclaration) []*genai.FunctionDeclaration {
+	var decls []*genai.FunctionDeclaration
+	for _, f := range funcs {
+		decls = append(decls, &genai.FunctionDeclaration{
+			Name:        f.Name,
+			Description: f.Description,
+			Parameters:  toGenAISchema(f.Parameters),
+		})
+	}
+	return decls
+}
+
+// contents converts the prompt parts to a conversation:
+// each run of [llm.FunctionCall] parts is a turn by the model,
+// and each run of other parts is a turn by the user.
+func (c *Client) contents(promptParts []llm.Part) ([]*genai.Content, error) {
+	parts, err := c.parts(promptParts)
+	if err != nil {
+		return nil, err
+	}
+	var cs []*genai.Content
+	for i, p := range parts {
+		role := "user"
+		if _, ok := promptParts[i].(llm.FunctionCall); ok {
+			role = "model"
+		}
+		if len(cs) == 0 || cs[len(cs)-1].Role != role {
+			cs = append(cs, &genai.Content{Role: role})
+		}
+		cs[len(cs)-1].Parts = append(cs[len(cs)-1].Parts, p)
+	}
+	return cs, nil
+}
+
 // generate returns the model's response (of the specified MIME type) for the prompt parts.
 // It returns an error if a response cannot be generated.
 func (c *Client) generate(ctx context.Context, mimeType string, schema *genai.Schema, promptParts ...llm.Part) ([]string, error) {
@@ -229,8 +314,18 @@ func (c *Client) parts(promptParts []llm.Part) ([]genai.Part, error) {
 				MIMEType: p.MIMEType,
 				Data:     p.Data,
 			}
+		case llm.FunctionCall:
+			parts[i] = genai.FunctionCall{
+				Name: p.Name,
+				Args: p.Args,
+			}
+		case llm.FunctionResponse:
+			parts[i] = genai.FunctionResponse{
+				Name:     p.Name,
+				Response: p.Response,
+			}
 		default:
-			return nil, fmt.Errorf("bad type for part: %T; need string or llm.Blob", p)
+			return nil, fmt.Errorf("bad type for part: %T; need string, llm.Blob, llm.FunctionCall or llm.FunctionResponse", p)
 		}
 	}
 	return parts, nil
@@ -147,3 +147,37 @@ func TestBigBatch(t *testing.T) {
 		t.Fatalf("len(vecs) = %d, but len(docs) = %d", len(vecs), len(docs))
 	}
 }
+
+func TestContents(t *testing.T) {
+	c := &Client{}
+	cs, err := c.contents([]llm.Part{
+		llm.Text("look up issue 1"),
+		llm.FunctionCall{Name: "issue", Args: map[string]any{"number": 1.0}},
+		llm.FunctionResponse{Name: "issue", Response: map[string]any{"title": "bug"}},
+		llm.Text("now summarize it"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roles []string
+	var n []int
+	for _, c := range cs {
+		roles = append(roles, c.Role)
+		n = append(n, len(c.Parts))
+	}
+	if fmt.Sprint(roles) != "[user model user]" || fmt.Sprint(n) != "[1 1 2]" {
+		t.Errorf("contents: roles=%v, parts=%v; want [user model user], [1 1 2]", roles, n)
+	}
+
+	decls := toGenAIFunctions([]*llm.FunctionDeclaration{{
+		Name:        "issue",
+		Description: "look up an issue",
+		Parameters: &llm.Schema{
+			Type:       llm.TypeObject,
+			Properties: map[string]*llm.Schema{"number": {Type: llm.TypeInteger}},
+		},
+	}})
+	if len(decls) != 1 || decls[0].Name != "issue" || decls[0].Parameters.Properties["number"] == nil {
+		t.Errorf("toGenAIFunctions = %+v", decls)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gerrit
+
+import (
+	"encoding/json"
+	"iter"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// EnableDryRun enables dry-run mode, in which reviews are diverted
+// instead of being posted to Gerrit, as in testing mode.
+// Unlike testing mode, diverted reviews are recorded in the database,
+// where they can be inspected using [Client.DivertedReviews].
+// Syncing and other read-only operations are unaffected.
+func (c *Client) EnableDryRun() {
+	c.dryRun = true
+}
+
+// DryRun reports whether the client is in dry-run mode.
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
+
+// divertedReviewKind is the timed kind for reviews recorded in dry-run mode.
+// The key is (instance, project, change number, wall-clock nanoseconds).
+const divertedReviewKind = "gerrit.DivertedReview"
+
+// A DivertedReview is a review that was recorded in dry-run mode
+// instead of being posted to Gerrit.
+type DivertedReview struct {
+	DBTime timed.DBTime // time the review was recorded in the database
+	Time   time.Time    // wall-clock time the review was posted
+	Review *TestingReview
+}
+
+// recordReview records the diverted review r.
+// In testing mode, r is saved in memory for [TestingClient.Reviews].
+// In dry-run mode, r is written to the database.
+func (c *Client) recordReview(r *TestingReview) {
+	if c.divertChanges() {
+		c.testClient.postReview(r.Project, r.ChangeNum, r.Message)
+	}
+	if c.dryRun {
+		now := time.Now()
+		b := c.db.Batch()
+		timed.Set(c.db, b, divertedReviewKind, ordered.Encode(c.instance, r.Project, r.ChangeNum, now.UnixNano()), storage.JSON(r))
+		b.Apply()
+		c.slog.Info("gerrit dry run: diverted review", "project", r.Project, "change", r.ChangeNum)
+	}
+}
+
+// DivertedReviews returns an iterator over the reviews recorded in dry-run mode
+// after the given DBTime, in the order they were posted.
+func (c *Client) DivertedReviews(after timed.DBTime) iter.Seq[*DivertedReview] {
+	return func(yield func(*DivertedReview) bool) {
+		for te := range timed.ScanAfter(c.slog, c.db, divertedReviewKind, after, nil) {
+			var instance, project string
+			var num int
+			var nanos int64
+			if err := ordered.Decode(te.Key, &instance, &project, &num, &nanos); err != nil {
+				// unreachable unless database corruption
+				c.db.Panic("gerrit.DivertedReviews decode key", "key", storage.Fmt(te.Key), "err", err)
+			}
+			if instance != c.instance {
+				continue
+			}
+			d := &DivertedReview{
+				DBTime: te.ModTime,
+				Time:   time.Unix(0, nanos),
+				Review: new(TestingReview),
+			}
+			if err := json.Unmarshal(te.Val, d.Review); err != nil {
+				// unreachable unless database corruption
+				c.db.Panic("gerrit.DivertedReviews decode value", "key", storage.Fmt(te.Key), "err", err)
+			}
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
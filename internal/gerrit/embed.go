@@ -5,12 +5,12 @@
 package gerrit
 
 import (
-	"fmt"
 	"iter"
 	"slices"
 	"strings"
 
 	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/entity"
 	"golang.org/x/oscar/internal/storage/timed"
 )
 
@@ -138,7 +138,7 @@ func (c *Client) relatedDocBody(ci *changeInfo) (string, error) {
 // to the gerrit change info ci, intended for indexing documents used
 // to surface related content.
 func relatedDocURL(ci *changeInfo) string {
-	return fmt.Sprintf("https://%s/c/%s/+/%d#related-content", ci.instance, ci.project, ci.number)
+	return entity.Change(ci.instance, ci.project, int64(ci.number)).DocID()
 }
 
 // comments returns file comments for the gerrit change.
@@ -0,0 +1,70 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PostReview posts message as a top-level reply on the current
+// revision of the change, as though written by clicking the
+// REPLY button in the Gerrit UI. It does not vote on any labels.
+//
+// PostReview authenticates with the secret for the Gerrit instance,
+// which must have the form user:pass (see [New]).
+//
+// In testing mode, the review is recorded and can be retrieved
+// with [TestingClient.Reviews]; in dry-run mode (see [Client.EnableDryRun]),
+// it is recorded in the database for [Client.DivertedReviews].
+// In either mode, nothing is sent to Gerrit.
+func (c *Client) PostReview(ctx context.Context, ch *Change, message string) error {
+	project, num := c.ChangeProject(ch), c.ChangeNumber(ch)
+	if c.divertChanges() || c.dryRun {
+		c.recordReview(&TestingReview{Project: project, ChangeNum: num, Message: message})
+		return nil
+	}
+
+	auth, ok := c.secret.Get(c.instance)
+	if !ok {
+		return fmt.Errorf("no secret for %s", c.instance)
+	}
+	user, pass, _ := strings.Cut(auth, ":")
+
+	js, err := json.Marshal(struct {
+		Message string `json:"message"`
+	}{message})
+	if err != nil {
+		return err
+	}
+	addr := fmt.Sprintf("https://%s/a/changes/%s~%d/revisions/current/review",
+		c.instance, url.PathEscape(project), num)
+	c.slog.Info("gerrit POST", "addr", addr)
+	req, err := http.NewRequestWithContext(ctx, "POST", addr, bytes.NewReader(js))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.SetBasicAuth(user, pass)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading body: %v", err)
+		}
+		return fmt.Errorf("%s\n%s", resp.Status, data)
+	}
+	return nil
+}
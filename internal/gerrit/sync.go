@@ -99,6 +99,7 @@ type Client struct {
 	http     *http.Client
 
 	flushRequested atomic.Bool // flush database to disk when convenient
+	dryRun         bool        // see [Client.EnableDryRun]
 
 	ac accountCache
 
@@ -111,7 +112,8 @@ type Client struct {
 // The client uses the given logger, databases, and HTTP client.
 //
 // The secret database will look for a secret whose name is the
-// Gerrit instance. The value will be user:pass.  This is not yet used.
+// Gerrit instance. The value will be user:pass.
+// It is only used to post reviews (see [Client.PostReview]).
 func New(instance string, lg *slog.Logger, db storage.DB, sdb secret.DB, hc *http.Client) *Client {
 	return &Client{
 		instance: instance,
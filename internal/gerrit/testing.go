@@ -12,6 +12,7 @@ import (
 	"iter"
 	"os"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -54,6 +55,7 @@ type TestingClient struct {
 	queryLimit int                    // mimic Gerrit query limits
 	comments   map[int][]*CommentInfo // comments indexed by change number
 	mergeable  map[int]bool           // indexed by change number
+	reviews    []*TestingReview       // reviews posted by PostReview
 }
 
 func newTestingClient(c *Client) *TestingClient {
@@ -521,3 +523,26 @@ func makeTestAccountID(email string) int {
 	testAccounts[email] = testAccountID
 	return testAccountID
 }
+
+// A TestingReview is a review message posted by [Client.PostReview]
+// in testing mode.
+type TestingReview struct {
+	Project   string
+	ChangeNum int
+	Message   string
+}
+
+// postReview records a review posted in testing mode.
+func (tc *TestingClient) postReview(project string, changeNum int, message string) {
+	tc.c.testMu.Lock()
+	defer tc.c.testMu.Unlock()
+	tc.reviews = append(tc.reviews, &TestingReview{Project: project, ChangeNum: changeNum, Message: message})
+}
+
+// Reviews returns the reviews posted by [Client.PostReview]
+// in testing mode, in the order they were posted.
+func (tc *TestingClient) Reviews() []*TestingReview {
+	tc.c.testMu.Lock()
+	defer tc.c.testMu.Unlock()
+	return slices.Clone(tc.reviews)
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// AddSince is like [Client.Add], but the first sync of the project
+// only imports the issues, issue comments and review comments
+// updated at or after the time start, which makes it much faster
+// for projects with a long history.
+// Use [Client.Backfill] to import the older ones later.
+// If the project is already present, AddSince does nothing and returns nil.
+func (c *Client) AddSince(project string, start time.Time) error {
+	key := o(syncProjectKind, project)
+	if _, ok := c.db.Get(key); ok {
+		return nil
+	}
+	date := start.UTC().Format(time.RFC3339)
+	proj := &projectSync{
+		Name:              project,
+		IssueDate:         date,
+		CommentDate:       date,
+		ReviewCommentDate: date,
+		SyncStart:         date,
+	}
+	proj.store(c.db)
+	return nil
+}
+
+// A backfillSync is the state of a [Client.Backfill] in progress.
+type backfillSync struct {
+	Floor string // the date to import items from
+	// IssueDate, CommentDate and ReviewCommentDate are the
+	// latest update dates of the items imported so far.
+	IssueDate         string
+	CommentDate       string
+	ReviewCommentDate string
+}
+
+// Backfill imports the issues, issue comments and (if enabled with
+// [Client.EnableReviewComments]) review comments of the project that
+// were last updated at or after the date floor, but before the start
+// of its sync (see [Client.AddSince]).
+// Related-document search and other analyses depend on having the
+// project's history, not only its recent activity.
+//
+// Backfill records its progress in the database: if it is interrupted
+// (for example by canceling ctx), calling it again with the same floor
+// continues where it left off. Once it completes, the project's
+// sync start moves back to floor, so later calls with an earlier
+// floor only import the items before floor.
+// Backfill does nothing if every item at or after floor has been synced,
+// in particular for projects added with [Client.Add].
+//
+// Backfill uses the REST API even for projects synced using GraphQL
+// (see [Client.EnableGraphQL]), so the fields only set by the GraphQL
+// sync, such as [IssueComment.Minimized], are not set on the imported items.
+// Issue events are not imported. Like newly synced items, imported
+// items are new to the Client's event watchers.
+func (c *Client) Backfill(ctx context.Context, project string, floor time.Time) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Backfill(%q): %w", project, err)
+		}
+	}()
+
+	key := o(syncProjectKind, project)
+	c.db.Lock(string(key))
+	defer c.db.Unlock(string(key))
+
+	var proj projectSync
+	if val, ok := c.db.Get(key); !ok {
+		return fmt.Errorf("missing project %v", project)
+	} else if err := json.Unmarshal(val, &proj); err != nil {
+		return err
+	}
+
+	date := floor.UTC().Format(time.RFC3339)
+	if proj.SyncStart == "" || date >= proj.SyncStart {
+		return nil
+	}
+	if proj.Backfill == nil || proj.Backfill.Floor != date {
+		proj.Backfill = &backfillSync{
+			Floor:             date,
+			IssueDate:         date,
+			CommentDate:       date,
+			ReviewCommentDate: date,
+		}
+		proj.store(c.db)
+	}
+	c.slog.Info("github.Backfill start", "project", project, "floor", date, "start", proj.SyncStart)
+
+	bf := proj.Backfill
+	if err := c.backfillByDate(ctx, &proj, "/issues", &bf.IssueDate); err != nil {
+		return err
+	}
+	if err := c.backfillByDate(ctx, &proj, "/issues/comments", &bf.CommentDate); err != nil {
+		return err
+	}
+	if proj.ReviewComments {
+		if err := c.backfillByDate(ctx, &proj, "/pulls/comments", &bf.ReviewCommentDate); err != nil {
+			return err
+		}
+	}
+
+	proj.SyncStart = date
+	proj.Backfill = nil
+	proj.store(c.db)
+	c.slog.Info("github.Backfill done", "project", project, "floor", date)
+	return nil
+}
+
+// backfillByDate downloads and saves the issues, issue comments or
+// review comments (depending on api; see [Client.syncByDate])
+// updated at or after *since and before proj.SyncStart.
+// It updates *since to the latest update date saved before any error.
+func (c *Client) backfillByDate(ctx context.Context, proj *projectSync, api string, since *string) error {
+	defer proj.store(c.db)
+	b := c.db.Batch()
+	defer b.Apply()
+
+Restart:
+	values := url.Values{
+		"sort":      {"updated"},
+		"direction": {"asc"},
+		"since":     {*since},
+		"per_page":  {"100"},
+		"page":      {"1"},
+	}
+	if api == "/issues" {
+		values["state"] = []string{"all"}
+	}
+	urlStr := "https://api.github.com/repos/" + proj.Name + api + "?" + values.Encode()
+	npage := 0
+	for pg, err := range c.pages(ctx, urlStr, "") {
+		if err != nil {
+			return err
+		}
+		for _, raw := range pg.body {
+			meta, err := parseByDate(api, raw)
+			if err != nil {
+				return err
+			}
+			if meta.Updated >= proj.SyncStart {
+				// Synced by SyncProject.
+				return nil
+			}
+			if err := c.writeSynced(b, proj, meta.Number, api, meta.ID, raw); err != nil {
+				return err
+			}
+			b.MaybeApply()
+			*since = meta.Updated
+		}
+		b.Apply()
+		proj.store(c.db) // update *since
+
+		// As in syncByDate, restart pagination before
+		// GitHub stops returning results.
+		if npage++; npage >= 500 {
+			goto Restart
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"slices"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// Every issue records its milestone in its JSON ([Issue.Milestone]),
+// but the milestones themselves (with their due dates and states)
+// are only synced for projects enabled with [Client.EnableMilestones].
+//
+// GitHub's project boards (which GitHub calls "projects",
+// not to be confused with the "owner/repo" projects in this package)
+// come in two forms. Membership of classic boards is recorded in
+// issue events, which are always synced. Membership of the current
+// boards is only available from the GraphQL API; it is synced for
+// the boards enabled with [Client.EnableBoard].
+// Either way, [Client.Boards] reports the boards an issue is on.
+
+const (
+	milestoneKind = "github.Milestone"
+	boardItemKind = "github.BoardItem"
+)
+
+// A boardSync identifies a project board to sync.
+type boardSync struct {
+	Owner  string // organization or user owning the board
+	Number int    // board number, as in https://github.com/orgs/OWNER/projects/NUMBER
+}
+
+// EnableMilestones configures the client to also sync the milestones
+// of the project, which must already have been added with [Client.Add].
+// See [Client.Milestones].
+func (c *Client) EnableMilestones(project string) error {
+	return c.updateProject(project, "EnableMilestones", func(proj *projectSync) {
+		proj.Milestones = true
+	})
+}
+
+// EnableBoard configures the client to also sync which of the project's
+// issues are on the given project board, which is owned by an organization
+// or user and appears at https://github.com/orgs/OWNER/projects/NUMBER.
+// The project must already have been added with [Client.Add].
+// Only the board's items for issues and pull requests in the project are synced.
+// See [Client.Boards].
+func (c *Client) EnableBoard(project, owner string, number int) error {
+	return c.updateProject(project, "EnableBoard", func(proj *projectSync) {
+		b := boardSync{Owner: owner, Number: number}
+		if !slices.Contains(proj.Boards, b) {
+			proj.Boards = append(proj.Boards, b)
+		}
+	})
+}
+
+// Milestones returns an iterator over the project's milestones,
+// in number order, only consulting the database (not actual GitHub).
+// It yields nothing unless the project has been enabled with
+// [Client.EnableMilestones] and synced.
+func (c *Client) Milestones(project string) iter.Seq[*Milestone] {
+	return func(yield func(*Milestone) bool) {
+		for _, val := range c.db.Scan(o(milestoneKind, project), o(milestoneKind, project, ordered.Inf)) {
+			var m Milestone
+			if err := json.Unmarshal(val(), &m); err != nil {
+				c.db.Panic("github milestone decode", "project", project, "err", err)
+			}
+			if !yield(&m) {
+				return
+			}
+		}
+	}
+}
+
+// A BoardItem records that an issue or pull request is on a project board.
+type BoardItem struct {
+	Board   string // title of the board, such as "Proposals"; empty for classic boards
+	URL     string // URL of the board; for classic boards, its API URL
+	Status  string // the issue's status on the board (for classic boards, its column), if any
+	Classic bool   // whether the board is a classic project board
+}
+
+// Boards returns the project boards that the issue is on,
+// ordered by board URL, only consulting the database (not actual GitHub).
+// See [Client.EnableBoard] for which boards are synced.
+func (c *Client) Boards(iss *Issue) []*BoardItem {
+	project := iss.Project()
+	var items []*BoardItem
+	for _, val := range c.db.Scan(o(boardItemKind, project, iss.Number), o(boardItemKind, project, iss.Number, ordered.Inf)) {
+		var item BoardItem
+		if err := json.Unmarshal(val(), &item); err != nil {
+			c.db.Panic("github board item decode", "project", project, "issue", iss.Number, "err", err)
+		}
+		items = append(items, &item)
+	}
+
+	// Replay the classic board events.
+	classic := make(map[string]*BoardItem)
+	for e := range eventsByAPI(c.db, project, iss.Number, "/issues/events") {
+		ev := e.Typed.(*IssueEvent)
+		card := ev.ProjectCard
+		if card.ProjectURL == "" {
+			continue
+		}
+		switch ev.Event {
+		case "added_to_project", "converted_note_to_issue", "moved_columns_in_project":
+			classic[card.ProjectURL] = &BoardItem{URL: card.ProjectURL, Status: card.ColumnName, Classic: true}
+		case "removed_from_project":
+			delete(classic, card.ProjectURL)
+		}
+	}
+	for _, item := range classic {
+		items = append(items, item)
+	}
+	slices.SortFunc(items, func(x, y *BoardItem) int { return cmp.Compare(x.URL, y.URL) })
+	return items
+}
+
+// syncMilestones downloads and saves the project's milestones,
+// deleting any that no longer exist.
+// As in [Client.syncByDate], proj.MilestoneETag records the ETag of
+// a single-page response, so that an unchanged list costs nothing.
+func (c *Client) syncMilestones(ctx context.Context, proj *projectSync) error {
+	values := url.Values{
+		"state":    {"all"},
+		"per_page": {"100"},
+		"page":     {"1"},
+	}
+	urlStr := "https://api.github.com/repos/" + proj.Name + "/milestones?" + values.Encode()
+
+	b := c.db.Batch()
+	defer b.Apply()
+
+	seen := make(map[int64]bool)
+	etag := ""
+	npage := 0
+	for pg, err := range c.pages(ctx, urlStr, proj.MilestoneETag) {
+		if err == errNotModified {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if npage++; npage == 1 && findNext(pg.resp.Header.Get("Link")) == "" {
+			etag = pg.resp.Header.Get("Etag")
+		}
+		for _, raw := range pg.body {
+			var m Milestone
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return fmt.Errorf("parsing JSON: %v", err)
+			}
+			if m.Number == 0 {
+				return fmt.Errorf("parsing milestone: no number: %s", string(raw))
+			}
+			seen[m.Number] = true
+			b.Set(o(milestoneKind, proj.Name, m.Number), raw)
+			b.MaybeApply()
+		}
+	}
+
+	// Delete milestones that no longer exist.
+	for key := range c.db.Scan(o(milestoneKind, proj.Name), o(milestoneKind, proj.Name, ordered.Inf)) {
+		var n int64
+		if err := ordered.Decode(key, nil, nil, &n); err != nil {
+			return fmt.Errorf("github milestone key decode: key=%s err=%w", storage.Fmt(key), err)
+		}
+		if !seen[n] {
+			b.Delete(key)
+		}
+	}
+	b.Apply()
+	proj.MilestoneETag = etag
+	proj.store(c.db)
+	return nil
+}
+
+// boardQuery lists the items on a project board.
+const boardQuery = `query Board($owner: String!, $number: Int!, $first: Int!, $after: String) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        title url
+        items(first: $first, after: $after) {
+          pageInfo { hasNextPage endCursor }
+          nodes {
+            isArchived
+            content {
+              ... on Issue { number repository { nameWithOwner } }
+              ... on PullRequest { number repository { nameWithOwner } }
+            }
+            status: fieldValueByName(name: "Status") {
+              ... on ProjectV2ItemFieldSingleSelectValue { name }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// A gqlBoard is a page of a project board in GitHub GraphQL JSON.
+type gqlBoard struct {
+	RepositoryOwner *struct {
+		ProjectV2 *struct {
+			Title string `json:"title"`
+			URL   string `json:"url"`
+			Items struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []struct {
+					IsArchived bool `json:"isArchived"`
+					Content    *struct {
+						Number     int64 `json:"number"`
+						Repository struct {
+							NameWithOwner string `json:"nameWithOwner"`
+						} `json:"repository"`
+					} `json:"content"` // nil for draft items
+					Status *struct {
+						Name string `json:"name"`
+					} `json:"status"`
+				} `json:"nodes"`
+			} `json:"items"`
+		} `json:"projectV2"`
+	} `json:"repositoryOwner"`
+}
+
+// syncBoard records which of the project's issues are on the board,
+// deleting the records for issues that are no longer on it.
+// Boards have no "updated since" query, so syncBoard reads the whole board.
+func (c *Client) syncBoard(ctx context.Context, proj *projectSync, bs boardSync) error {
+	b := c.db.Batch()
+	defer b.Apply()
+
+	var boardURL string
+	seen := make(map[int64]bool)
+	vars := map[string]any{
+		"owner":  bs.Owner,
+		"number": bs.Number,
+		"first":  graphQLPageSize,
+	}
+	for {
+		var reply gqlBoard
+		if err := c.graphQL(ctx, boardQuery, vars, &reply); err != nil {
+			return err
+		}
+		if reply.RepositoryOwner == nil || reply.RepositoryOwner.ProjectV2 == nil {
+			return fmt.Errorf("github board %s/%d: not found", bs.Owner, bs.Number)
+		}
+		board := reply.RepositoryOwner.ProjectV2
+		boardURL = board.URL
+		for _, node := range board.Items.Nodes {
+			if node.IsArchived || node.Content == nil || node.Content.Repository.NameWithOwner != proj.Name {
+				continue
+			}
+			item := &BoardItem{Board: board.Title, URL: board.URL}
+			if node.Status != nil {
+				item.Status = node.Status.Name
+			}
+			n := node.Content.Number
+			seen[n] = true
+			key := o(boardItemKind, proj.Name, n, board.URL)
+			js := storage.JSON(item)
+			if old, ok := c.db.Get(key); !ok || !bytes.Equal(old, js) {
+				b.Set(key, js)
+				b.MaybeApply()
+			}
+		}
+		if !board.Items.PageInfo.HasNextPage {
+			break
+		}
+		vars["after"] = board.Items.PageInfo.EndCursor
+	}
+
+	// Delete the items that are no longer on the board.
+	for key := range c.db.Scan(o(boardItemKind, proj.Name), o(boardItemKind, proj.Name, ordered.Inf)) {
+		var n int64
+		var u string
+		if err := ordered.Decode(key, nil, nil, &n, &u); err != nil {
+			return fmt.Errorf("github board item key decode: key=%s err=%w", storage.Fmt(key), err)
+		}
+		if u == boardURL && !seen[n] {
+			b.Delete(key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestClassicBoards(t *testing.T) {
+	const project = "rsc/markdown"
+	const (
+		board1 = "https://api.github.com/projects/1"
+		board2 = "https://api.github.com/projects/2"
+	)
+	c := New(testutil.Slogger(t), storage.MemDB(), nil, nil)
+	tc := c.Testing()
+	iss := &Issue{Number: 1, URL: "https://api.github.com/repos/rsc/markdown/issues/1", Title: "title"}
+	tc.AddIssue(project, iss)
+	other := &Issue{Number: 2, URL: "https://api.github.com/repos/rsc/markdown/issues/2", Title: "other"}
+	tc.AddIssue(project, other)
+
+	event := func(n int64, event, board, column string) {
+		tc.AddIssueEvent(project, n, &IssueEvent{
+			Event:       event,
+			ProjectCard: ProjectCard{ProjectURL: board, ColumnName: column},
+		})
+	}
+	event(1, "added_to_project", board2, "Incoming")
+	event(1, "added_to_project", board1, "Triage")
+	event(1, "moved_columns_in_project", board1, "Done")
+	event(1, "labeled", "", "")
+	event(2, "added_to_project", board2, "Incoming")
+	event(2, "removed_from_project", board2, "Incoming")
+
+	want := []*BoardItem{
+		{URL: board1, Status: "Done", Classic: true},
+		{URL: board2, Status: "Incoming", Classic: true},
+	}
+	if diff := cmp.Diff(want, c.Boards(iss)); diff != "" {
+		t.Errorf("Boards(1) mismatch (-want +got):\n%s", diff)
+	}
+	if got := c.Boards(other); len(got) != 0 {
+		t.Errorf("Boards(2) = %v, want none", got)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/entity"
 	"golang.org/x/oscar/internal/model"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/storage/timed"
@@ -33,27 +34,11 @@ func (c *Client) LookupIssueURL(url string) (*Issue, error) {
 }
 
 func parseIssueURL(url string) (string, int64, error) {
-	bad := func() (string, int64, error) {
+	id, err := entity.Parse(url)
+	if err != nil || id.Kind != entity.GitHubIssue || id.Host != "github.com" {
 		return "", 0, fmt.Errorf("not a github URL: %q", url)
 	}
-	proj, ok := strings.CutPrefix(url, "https://github.com/")
-	if !ok {
-		return bad()
-	}
-	// If this used strings.Index instead of strings.LastIndex,
-	// we could use strings.Cut instead. But it doesn't, so we can't.
-	// Have to handle a hypothetical repo named golang/issues,
-	// which would have URLs like https://github.com/golang/issues/issues/12345.
-	i := strings.LastIndex(proj, "/issues/")
-	if i < 0 {
-		return bad()
-	}
-	proj, num := proj[:i], proj[i+len("/issues/"):]
-	n, err := strconv.ParseInt(num, 10, 64)
-	if err != nil || n <= 0 {
-		return bad()
-	}
-	return proj, n, nil
+	return id.Project, id.Number, nil
 }
 
 // LookupIssueCommentURL looks up an issue comment by HTML URL
@@ -76,16 +61,11 @@ func (c *Client) LookupIssueCommentURL(url string) (*IssueComment, error) {
 // (for example https://github.com/golang/go/issues/12345#issuecomment-135132324).
 // Note: this is the URL format stored in the [IssueComment.HTMLURL] field.
 func ParseIssueCommentURL(u string) (project string, issue, comment int64, err error) {
-	before, after, _ := strings.Cut(u, "#issuecomment-")
-	proj, i, err := parseIssueURL(before)
-	if err != nil {
-		return "", 0, 0, err
-	}
-	c, err := strconv.ParseInt(after, 10, 64)
-	if err != nil || i <= 0 {
-		return "", 0, 0, err
+	id, err := entity.Parse(u)
+	if err != nil || id.Kind != entity.GitHubIssueComment || id.Host != "github.com" {
+		return "", 0, 0, fmt.Errorf("not a github issue comment URL: %q", u)
 	}
-	return proj, i, c, nil
+	return id.Project, id.Number, id.Comment, nil
 }
 
 // LookupIssue looks up an issue by project and issue number
@@ -98,6 +78,17 @@ func LookupIssue(db storage.DB, project string, issue int64) (*Issue, error) {
 	return nil, fmt.Errorf("github.LookupIssue: issue %s#%d not in database", project, issue)
 }
 
+// LookupIssueAsOf is like [LookupIssue] but looks up the issue
+// as it was in the database at time t (see [timed.DBTimeAt]).
+// That is, it returns what a program reading the database
+// at time t would have seen.
+func LookupIssueAsOf(db storage.DB, project string, issue int64, t timed.DBTime) (*Issue, error) {
+	for e := range eventsByAPIAsOf(db, project, issue, "/issues", t) {
+		return e.Typed.(*Issue), nil
+	}
+	return nil, fmt.Errorf("github.LookupIssueAsOf: issue %s#%d not in database at %d", project, issue, t)
+}
+
 // LookupIssues returns an iterator over issues between issueMin and issueMax,
 // only consulting the database (not actual GitHub).
 func LookupIssues(db storage.DB, project string, issueMin, issueMax int64) iter.Seq[*Issue] {
@@ -136,6 +127,64 @@ func (c *Client) Comments(iss *Issue) iter.Seq[*IssueComment] {
 	}
 }
 
+// CommentsAsOf is like [Client.Comments] but returns the comments
+// as they were in the database at time t (see [LookupIssueAsOf]).
+func (c *Client) CommentsAsOf(iss *Issue, t timed.DBTime) iter.Seq[*IssueComment] {
+	return func(yield func(*IssueComment) bool) {
+		for e := range eventsByAPIAsOf(c.db, iss.Project(), iss.Number, "/issues/comments", t) {
+			if !yield(e.Typed.(*IssueComment)) {
+				return
+			}
+		}
+	}
+}
+
+// ReviewComments returns an iterator over the review comments
+// for the pull request in the db, in order of creation.
+// Review comments are only synced for projects enabled with
+// [Client.EnableReviewComments].
+func (c *Client) ReviewComments(pr *Issue) iter.Seq[*ReviewComment] {
+	return func(yield func(*ReviewComment) bool) {
+		for e := range eventsByAPI(c.db, pr.Project(), pr.Number, "/pulls/comments") {
+			if !yield(e.Typed.(*ReviewComment)) {
+				return
+			}
+		}
+	}
+}
+
+// A ReviewThread is a pull request review comment
+// together with the replies to it.
+type ReviewThread struct {
+	Path     string           // file the thread is about
+	Line     int64            // line of the file, if known
+	DiffHunk string           // diff context of the first comment
+	Comments []*ReviewComment // first comment, then replies in order
+}
+
+// ReviewThreads returns the review threads for the pull request in the db,
+// in order of their first comments.
+func (c *Client) ReviewThreads(pr *Issue) []*ReviewThread {
+	var threads []*ReviewThread
+	byID := make(map[int64]*ReviewThread)
+	for rc := range c.ReviewComments(pr) {
+		if t := byID[rc.InReplyTo]; rc.InReplyTo != 0 && t != nil {
+			t.Comments = append(t.Comments, rc)
+			byID[rc.CommentID()] = t
+			continue
+		}
+		t := &ReviewThread{
+			Path:     rc.Path,
+			Line:     rc.Line,
+			DiffHunk: rc.DiffHunk,
+			Comments: []*ReviewComment{rc},
+		}
+		threads = append(threads, t)
+		byID[rc.CommentID()] = t
+	}
+	return threads
+}
+
 // EventsByAPI returns an iterator over the events for the issue in the
 // Client's db with the given API.
 func (c *Client) EventsByAPI(project string, issue int64, api string) iter.Seq[*Event] {
@@ -156,15 +205,29 @@ func eventsByAPI(db storage.DB, project string, issue int64, api string) iter.Se
 	}
 }
 
+// eventsByAPIAsOf is like eventsByAPI but returns the events
+// as they were in the db at time t.
+func eventsByAPIAsOf(db storage.DB, project string, issue int64, api string, t timed.DBTime) iter.Seq[*Event] {
+	return func(yield func(*Event) bool) {
+		start := o(project, issue, api)
+		end := o(project, issue, api, ordered.Inf)
+		for te := range timed.ScanAsOf(db, eventKind, start, end, t) {
+			if !yield(decodeEvent(db, te)) {
+				return
+			}
+		}
+	}
+}
+
 // An Event is a single GitHub issue event stored in the database.
 type Event struct {
 	DBTime  timed.DBTime // when event was last written
 	Project string       // project ("golang/go")
 	Issue   int64        // issue number
-	API     string       // API endpoint for event: "/issues", "/issues/comments", or "/issues/events"
+	API     string       // API endpoint for event: "/issues", "/issues/comments", "/issues/events", "/issues/timeline", or "/pulls/comments"
 	ID      int64        // ID of event; each API has a different ID space. (Project, Issue, API, ID) is assumed unique
 	JSON    []byte       // JSON for the event data
-	Typed   any          // Typed unmarshaling of the event data, of type *Issue, *IssueComment, or *IssueEvent
+	Typed   any          // Typed unmarshaling of the event data, of type *Issue, *IssueComment, *IssueEvent, *CrossReference, or *ReviewComment
 }
 
 var _ docs.Entry = (*Event)(nil)
@@ -200,9 +263,11 @@ func (c *Client) Events(project string, issueMin, issueMax int64) iter.Seq[*Even
 // limited to issues in the range issueMin ≤ issue ≤ issueMax.
 // If issueMax < 0, there is no upper limit.
 // The events are iterated over in (Project, Issue, API, ID) order,
-// so "/issues" events come first, then "/issues/comments", then "/issues/events".
+// so "/issues" events come first, then "/issues/comments", then "/issues/events",
+// then "/issues/timeline".
 // Within a specific API, the events are ordered by increasing ID,
-// which corresponds to increasing event time on GitHub.
+// which corresponds to increasing event time on GitHub
+// (except for "/issues/timeline", whose IDs are those of the referring issues).
 func Events(db storage.DB, project string, issueMin, issueMax int64) iter.Seq[*Event] {
 	return func(yield func(*Event) bool) {
 		start := o(project, issueMin)
@@ -267,6 +332,10 @@ func decodeEvent(db storage.DB, t *timed.Entry) *Event {
 		e.Typed = new(IssueComment)
 	case "/issues/events":
 		e.Typed = new(IssueEvent)
+	case "/issues/timeline":
+		e.Typed = new(CrossReference)
+	case "/pulls/comments":
+		e.Typed = new(ReviewComment)
 	}
 	if err := json.Unmarshal(js, e.Typed); err != nil {
 		db.Panic("github event json", "js", string(js), "err", err)
@@ -300,6 +369,11 @@ type IssueEvent struct {
 	Assignees  []User    `json:"assignees"`
 	Milestone  Milestone `json:"milestone"`
 	Rename     Rename    `json:"rename"`
+
+	// ProjectCard is the issue's card on a classic project board,
+	// for "added_to_project", "moved_columns_in_project",
+	// "removed_from_project" and "converted_note_to_issue" events.
+	ProjectCard ProjectCard `json:"project_card"`
 }
 
 // A User represents a user or organization account in GitHub JSON.
@@ -316,7 +390,20 @@ type Label struct {
 
 // A Milestone represents a project issue milestone in GitHub JSON.
 type Milestone struct {
-	Title string `json:"title"`
+	Number      int64  `json:"number"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`  // "open" or "closed"
+	DueOn       string `json:"due_on"` // empty if there is no due date
+}
+
+// A ProjectCard represents an issue's card on a classic project board
+// in GitHub issue event JSON.
+type ProjectCard struct {
+	ID                 int64  `json:"id"`
+	ProjectURL         string `json:"project_url"` // API URL of the board
+	ColumnName         string `json:"column_name"`
+	PreviousColumnName string `json:"previous_column_name"`
 }
 
 // A Rename describes an issue title renaming in GitHub JSON.
@@ -328,16 +415,11 @@ type Rename struct {
 // ParseIssueURL expects a GitHUB API URL for an issue (for example "https://api.github.com/repos/org/r/issues/123")
 // and returns the project (for example "org/r") and issue number.
 func ParseIssueURL(u string) (project string, number int64, err error) {
-	bad := func() error { return fmt.Errorf("not a GitHub isue URL: %q", u) }
-	project = urlToProject(u)
-	if project == "" {
-		return "", 0, bad()
-	}
-	number = baseToInt64(u)
-	if number == 0 {
-		return "", 0, bad()
+	id, err := entity.Parse(u)
+	if err != nil || id.Kind != entity.GitHubIssue || !strings.HasPrefix(u, "https://api.github.com/") {
+		return "", 0, fmt.Errorf("not a GitHub issue URL: %q", u)
 	}
-	return project, number, nil
+	return id.Project, id.Number, nil
 }
 
 func urlToProject(u string) string {
@@ -373,6 +455,61 @@ type IssueComment struct {
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 	Body      string `json:"body"`
+
+	// AuthorAssociation is the commenter's relationship to the
+	// repository, such as "OWNER", "MEMBER", "COLLABORATOR" or "NONE"
+	// (see [MaintainerAssociation]).
+	AuthorAssociation string `json:"author_association,omitempty"`
+
+	// Reactions summarizes the emoji reactions to the comment.
+	// GitHub does not update the comment's UpdatedAt time when
+	// reactions change, so the counts are only as fresh as the
+	// last download of the comment (see [Client.DownloadIssueComment]).
+	Reactions *Reactions `json:"reactions,omitempty"`
+
+	// Minimized reports whether the comment has been hidden
+	// ("minimized") on GitHub, and MinimizedReason why,
+	// such as "off-topic" or "outdated".
+	// The REST API does not report minimization, so these
+	// fields are only set for projects synced using GraphQL
+	// (see [Client.EnableGraphQL]).
+	Minimized       bool   `json:"minimized,omitempty"`
+	MinimizedReason string `json:"minimized_reason,omitempty"`
+}
+
+// MaintainerAssociation reports whether the author association
+// of a comment (see [IssueComment.AuthorAssociation]) is that of
+// a maintainer: the repository's owner, a member of the organization
+// that owns it, or a collaborator.
+func MaintainerAssociation(assoc string) bool {
+	switch assoc {
+	case "OWNER", "MEMBER", "COLLABORATOR":
+		return true
+	}
+	return false
+}
+
+// Reactions is the summary of the emoji reactions to
+// an issue or comment.
+type Reactions struct {
+	TotalCount int `json:"total_count"`
+	PlusOne    int `json:"+1"` // 👍
+	MinusOne   int `json:"-1"` // 👎
+	Laugh      int `json:"laugh"`
+	Confused   int `json:"confused"`
+	Heart      int `json:"heart"`
+	Hooray     int `json:"hooray"`
+	Rocket     int `json:"rocket"`
+	Eyes       int `json:"eyes"`
+}
+
+// A Reaction is a single emoji reaction to an issue or comment,
+// as returned by [Client.CommentReactions].
+type Reaction struct {
+	ID        int64  `json:"id"`
+	User      User   `json:"user"`
+	Content   string `json:"content"` // "+1", "-1", "laugh", "confused", "heart", "hooray", "rocket" or "eyes"
+	CreatedAt string `json:"created_at"`
 }
 
 // Project returns the issue comment's GitHub project (for example, "golang/go").
@@ -411,6 +548,37 @@ func (x *IssueComment) Updates() model.PostUpdates {
 
 var _ model.Post = (*IssueComment)(nil)
 
+// ReviewComment is the GitHub JSON structure for a pull request
+// review comment, which is a comment on a line of the pull request's diff.
+type ReviewComment struct {
+	URL            string `json:"url"`
+	PullRequestURL string `json:"pull_request_url"`
+	HTMLURL        string `json:"html_url"`
+	User           User   `json:"user"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+	Body           string `json:"body"`
+	Path           string `json:"path"`
+	Line           int64  `json:"line"`
+	DiffHunk       string `json:"diff_hunk"`
+	InReplyTo      int64  `json:"in_reply_to_id"` // ID of the first comment in the thread, if a reply
+}
+
+// Project returns the review comment's GitHub project (for example, "golang/go").
+func (x *ReviewComment) Project() string {
+	return urlToProject(x.URL)
+}
+
+// PullRequest returns the review comment's pull request number.
+func (x *ReviewComment) PullRequest() int64 {
+	return baseToInt64(x.PullRequestURL)
+}
+
+// CommentID returns the review comment's numeric ID.
+func (x *ReviewComment) CommentID() int64 {
+	return baseToInt64(x.URL)
+}
+
 // Issue is the GitHub JSON structure for an issue creation event.
 type Issue struct {
 	URL              string    `json:"url"`
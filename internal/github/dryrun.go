@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"encoding/json"
+	"iter"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// EnableDryRun enables dry-run mode, in which edits are diverted
+// instead of being applied to GitHub, as in testing mode.
+// Unlike testing mode, diverted edits are recorded in the database,
+// where they can be inspected using [Client.DivertedEdits].
+// Syncing and other read-only operations are unaffected.
+func (c *Client) EnableDryRun() {
+	c.dryRun = true
+}
+
+// DryRun reports whether the client is in dry-run mode.
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
+
+// divertedEditKind is the timed kind for edits recorded in dry-run mode.
+// The key is (project, issue, wall-clock nanoseconds).
+const divertedEditKind = "github.DivertedEdit"
+
+// A DivertedEdit is an edit that was recorded in dry-run mode
+// instead of being applied to GitHub.
+type DivertedEdit struct {
+	DBTime timed.DBTime // time the edit was recorded in the database
+	Time   time.Time    // wall-clock time the edit was made
+	Edit   *TestingEdit
+}
+
+// recordEdit records the diverted edit e.
+// In testing mode, e is saved in memory for [TestingClient.Edits].
+// In dry-run mode, e is written to the database.
+// The caller must hold c.testMu.
+func (c *Client) recordEdit(e *TestingEdit) {
+	if c.testing {
+		c.testEdits = append(c.testEdits, e)
+	}
+	if c.dryRun {
+		now := time.Now()
+		b := c.db.Batch()
+		timed.Set(c.db, b, divertedEditKind, ordered.Encode(e.Project, e.Issue, now.UnixNano()), storage.JSON(e))
+		b.Apply()
+		c.slog.Info("github dry run: diverted edit", "edit", e.String())
+	}
+}
+
+// DivertedEdits returns an iterator over the edits recorded in dry-run mode
+// after the given DBTime, in the order they were made.
+func (c *Client) DivertedEdits(after timed.DBTime) iter.Seq[*DivertedEdit] {
+	return func(yield func(*DivertedEdit) bool) {
+		for te := range timed.ScanAfter(c.slog, c.db, divertedEditKind, after, nil) {
+			var project string
+			var issue, nanos int64
+			if err := ordered.Decode(te.Key, &project, &issue, &nanos); err != nil {
+				// unreachable unless database corruption
+				c.db.Panic("github.DivertedEdits decode key", "key", storage.Fmt(te.Key), "err", err)
+			}
+			d := &DivertedEdit{
+				DBTime: te.ModTime,
+				Time:   time.Unix(0, nanos),
+				Edit:   new(TestingEdit),
+			}
+			if err := json.Unmarshal(te.Val, d.Edit); err != nil {
+				// unreachable unless database corruption
+				c.db.Panic("github.DivertedEdits decode value", "key", storage.Fmt(te.Key), "err", err)
+			}
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
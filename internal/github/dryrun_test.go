@@ -0,0 +1,61 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestDryRun(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	ctx := context.Background()
+	db := storage.MemDB()
+
+	c := New(lg, db, secret.Empty(), nil)
+	c.testing = false // make sure nothing depends on testing mode
+	c.EnableDryRun()
+	if !c.DryRun() {
+		t.Fatalf("DryRun() = false after EnableDryRun")
+	}
+
+	issue := &Issue{
+		URL:    "https://api.github.com/repos/rsc/tmp/issues/5",
+		Number: 5,
+	}
+	_, _, err := c.PostIssueComment(ctx, issue, &IssueCommentChanges{Body: "hello"})
+	check(err)
+	check(c.EditIssue(ctx, issue, &IssueChanges{Labels: &[]string{"bug"}}))
+
+	var edits []string
+	for d := range c.DivertedEdits(0) {
+		edits = append(edits, d.Edit.String())
+	}
+	want := []string{
+		`PostIssueComment(rsc/tmp#5, {"body":"hello"})`,
+		`EditIssue(rsc/tmp#5, {"labels":["bug"]})`,
+	}
+	if !slices.Equal(edits, want) {
+		t.Errorf("DivertedEdits:\nhave %q\nwant %q", edits, want)
+	}
+	if len(c.testEdits) != 0 {
+		t.Errorf("dry run edits recorded in memory: %v", c.testEdits)
+	}
+
+	// Only edits after the given time are returned.
+	var last *DivertedEdit
+	for d := range c.DivertedEdits(0) {
+		last = d
+	}
+	for d := range c.DivertedEdits(last.DBTime) {
+		t.Errorf("DivertedEdits(last) returned %v", d.Edit)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"slices"
 	"strings"
 	"testing"
+
+	"golang.org/x/oscar/internal/policy"
 )
 
 // NOTE: It's possible that we should elevate TestingEdit to a general
@@ -26,12 +28,35 @@ import (
 
 // PostIssueComment posts a new comment with the given body (written in Markdown) on issue.
 // It returns an API URL for the new comment, and a URL suitable for display.
+// It returns an error wrapping [ErrOptedOut] if the issue has opted out
+// of further bot comments (see [Client.StopOnMinimized]), either
+// in the database or, when the client is not testing, on GitHub itself.
 func (c *Client) PostIssueComment(ctx context.Context, issue *Issue, changes *IssueCommentChanges) (id, url string, err error) {
+	optedOut := c.OptedOut(issue)
+	if !optedOut && len(c.bots) > 0 && !c.testing {
+		// The database may predate a recent minimization.
+		optedOut, err = c.minimizedNow(ctx, issue)
+		if err != nil {
+			return "", "", fmt.Errorf("github: checking %s#%d for minimized comments: %w", issue.Project(), issue.Number, err)
+		}
+	}
+	if optedOut {
+		return "", "", fmt.Errorf("%w: %s#%d", ErrOptedOut, issue.Project(), issue.Number)
+	}
+	r := &policy.Request{Project: issue.Project(), Action: policy.Comment, Author: issue.User.Login}
+	err = c.withPolicy(r, func() error {
+		id, url, err = c.postIssueComment(ctx, issue, changes)
+		return err
+	})
+	return id, url, err
+}
+
+func (c *Client) postIssueComment(ctx context.Context, issue *Issue, changes *IssueCommentChanges) (id, url string, err error) {
 	if c.divertEdits() {
 		c.testMu.Lock()
 		defer c.testMu.Unlock()
 
-		c.testEdits = append(c.testEdits, &TestingEdit{
+		c.recordEdit(&TestingEdit{
 			Project:             issue.Project(),
 			Issue:               issue.Number,
 			IssueCommentChanges: changes.clone(),
@@ -77,6 +102,32 @@ func (c *Client) DownloadIssueComment(ctx context.Context, url string) (*IssueCo
 	return x, nil
 }
 
+// CommentReactions downloads the reactions to the comment,
+// which, unlike their summary in [IssueComment.Reactions],
+// say who reacted.
+// It returns at most the first 100 reactions.
+func (c *Client) CommentReactions(ctx context.Context, comment *IssueComment) ([]*Reaction, error) {
+	url := reactionsURL(comment)
+	if c.testing {
+		c.testMu.Lock()
+		js := c.testEvents[url]
+		c.testMu.Unlock()
+		if js == nil {
+			return nil, nil
+		}
+	}
+	var rs []*Reaction
+	if _, err := c.get(ctx, url, "", &rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// reactionsURL returns the API URL listing the reactions to the comment.
+func reactionsURL(comment *IssueComment) string {
+	return comment.URL + "/reactions?per_page=100"
+}
+
 type IssueCommentChanges struct {
 	Body string `json:"body,omitempty"`
 }
@@ -101,11 +152,18 @@ func (ch *IssueCommentChanges) SetBody(s string) error {
 // that the live comment body matches the one obtained from the database,
 // to minimize race windows.
 func (c *Client) EditIssueComment(ctx context.Context, comment *IssueComment, changes *IssueCommentChanges) error {
+	r := &policy.Request{Project: comment.Project(), Action: policy.Edit, User: comment.User.Login}
+	return c.withPolicy(r, func() error {
+		return c.editIssueComment(ctx, comment, changes)
+	})
+}
+
+func (c *Client) editIssueComment(ctx context.Context, comment *IssueComment, changes *IssueCommentChanges) error {
 	if c.divertEdits() {
 		c.testMu.Lock()
 		defer c.testMu.Unlock()
 
-		c.testEdits = append(c.testEdits, &TestingEdit{
+		c.recordEdit(&TestingEdit{
 			Project:             comment.Project(),
 			Issue:               comment.Issue(),
 			Comment:             comment.CommentID(),
@@ -155,11 +213,28 @@ func (ch *IssueChanges) SetBody(s string) error {
 
 // EditIssue applies the changes to issue on GitHub.
 func (c *Client) EditIssue(ctx context.Context, issue *Issue, changes *IssueChanges) error {
+	r := &policy.Request{Project: issue.Project(), Action: policy.Edit, Author: issue.User.Login, User: issue.User.Login}
+	if changes.Title == "" && changes.Body == "" && changes.State == "" {
+		r.Action = policy.Label
+	} else if changes.Labels != nil && c.policies != nil {
+		// Both an edit and a label change; both must be allowed.
+		lr := *r
+		lr.Action = policy.Label
+		if err := c.policies.Check(&lr); err != nil {
+			return err
+		}
+	}
+	return c.withPolicy(r, func() error {
+		return c.editIssue(ctx, issue, changes)
+	})
+}
+
+func (c *Client) editIssue(ctx context.Context, issue *Issue, changes *IssueChanges) error {
 	if c.divertEdits() {
 		c.testMu.Lock()
 		defer c.testMu.Unlock()
 
-		c.testEdits = append(c.testEdits, &TestingEdit{
+		c.recordEdit(&TestingEdit{
 			Project:      issue.Project(),
 			Issue:        issue.Number,
 			IssueChanges: changes.clone(),
@@ -199,7 +274,11 @@ func (c *Client) json(ctx context.Context, method, url string, body any) ([]byte
 	}
 	user, pass, _ := strings.Cut(auth, ":")
 
+	nrate := 0
 Redo:
+	if err := c.pace(ctx, "core", method != "GET"); err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(js))
 	if err != nil {
 		return nil, err
@@ -210,12 +289,18 @@ Redo:
 	if err != nil {
 		return nil, err
 	}
+	c.observe(resp)
 	data, err := io.ReadAll(resp.Body)
 	resp.Body.Close()
 	if err != nil {
 		return nil, fmt.Errorf("reading body: %v", err)
 	}
-	if c.rateLimit(resp) {
+	if limited, err := c.rateLimit(ctx, resp, data, nrate); err != nil {
+		return nil, err
+	} else if limited {
+		if nrate++; nrate > 20 {
+			return nil, fmt.Errorf("%s # too many rate limits\n%s", resp.Status, data)
+		}
 		goto Redo
 	}
 	if resp.StatusCode/10 != 20 { // allow 200, 201, maybe others
@@ -0,0 +1,507 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+)
+
+// The GraphQL sync path fetches issues (including pull requests)
+// together with their comments, asking GitHub's search API for
+// everything in the project updated since the last sync,
+// in increasing update time order.
+// A page of 100 issues with their most recent 100 comments each
+// costs a single request, where the REST sync needs separate
+// paginated requests for /issues and /issues/comments.
+//
+// The results are converted to the REST JSON form before being stored,
+// so the database contents (and all the code reading them) are the same
+// no matter which sync path wrote them.
+// Fields not requested in the GraphQL queries are omitted from the stored JSON.
+//
+// Comments are only found through their issues, so a comment edit
+// that GitHub does not reflect in the issue's update time is not seen.
+// GraphQL cannot list an issue's comments by update time, so
+// for issues with more comments than the pages created since the
+// last sync, the edits to the earlier comments are found using
+// the REST API's list of the issue's comments updated since then.
+// Issue events and review comments are still synced using the REST API.
+
+const graphQLURL = "https://api.github.com/graphql"
+
+// graphQLPageSize is the number of issues and comments
+// requested in each GraphQL query.
+const graphQLPageSize = 100
+
+// maxSearchResults is the number of search results after which
+// syncGraphQL restarts its search. GitHub's search API returns
+// at most 1000 results for a query.
+const maxSearchResults = 900
+
+// EnableGraphQL configures the client to sync the issues and issue comments
+// of the project, which must already have been added with [Client.Add],
+// using GitHub's GraphQL API instead of the REST API.
+// The GraphQL sync uses far fewer requests, which matters for
+// large projects where the REST sync uses much of the rate limit.
+// Its progress is recorded in the same state as the REST sync,
+// so a project can be switched to GraphQL at any time.
+func (c *Client) EnableGraphQL(project string) error {
+	return c.updateProject(project, "EnableGraphQL", func(proj *projectSync) {
+		proj.GraphQL = true
+	})
+}
+
+// DisableGraphQL configures the client to sync the issues and issue comments
+// of the project, which must already have been added with [Client.Add],
+// using the REST API, undoing [Client.EnableGraphQL].
+// The REST sync resumes where the GraphQL sync stopped.
+func (c *Client) DisableGraphQL(project string) error {
+	return c.updateProject(project, "DisableGraphQL", func(proj *projectSync) {
+		proj.GraphQL = false
+	})
+}
+
+// issuesQuery searches for the issues and pull requests matching $q,
+// which syncGraphQL sets to list a project's issues in update order.
+const issuesQuery = `query Issues($q: String!, $first: Int!, $after: String) {
+  search(type: ISSUE, query: $q, first: $first, after: $after) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      __typename
+      ... on Issue {` + issueFields + `}
+      ... on PullRequest {` + issueFields + `}
+    }
+  }
+}`
+
+// issueCommentsQuery fetches the comments before a given cursor
+// on an issue or pull request.
+const issueCommentsQuery = `query IssueComments($owner: String!, $name: String!, $number: Int!, $last: Int!, $before: String) {
+  repository(owner: $owner, name: $name) {
+    issueOrPullRequest(number: $number) {
+      ... on Issue { comments(last: $last, before: $before) {` + commentsFields + `} }
+      ... on PullRequest { comments(last: $last, before: $before) {` + commentsFields + `} }
+    }
+  }
+}`
+
+const issueFields = `
+        databaseId number url title body createdAt updatedAt closedAt
+        state locked activeLockReason
+        author { login }
+        assignees(first: 20) { nodes { login } }
+        milestone { number title description state dueOn }
+        labels(first: 50) { nodes { name description color } }
+        comments(last: $first) {` + commentsFields + `}
+`
+
+const commentsFields = `
+          pageInfo { hasPreviousPage startCursor }
+          nodes { databaseId url body createdAt updatedAt isMinimized minimizedReason author { login } authorAssociation }
+`
+
+// A gqlIssue is an issue or pull request in GitHub GraphQL JSON.
+type gqlIssue struct {
+	Typename         string                  `json:"__typename"`
+	DatabaseID       int64                   `json:"databaseId"`
+	Number           int64                   `json:"number"`
+	URL              string                  `json:"url"`
+	Title            string                  `json:"title"`
+	Body             string                  `json:"body"`
+	CreatedAt        string                  `json:"createdAt"`
+	UpdatedAt        string                  `json:"updatedAt"`
+	ClosedAt         string                  `json:"closedAt"`
+	State            string                  `json:"state"` // OPEN, CLOSED or MERGED
+	Locked           bool                    `json:"locked"`
+	ActiveLockReason string                  `json:"activeLockReason"`
+	Author           *User                   `json:"author"` // nil for deleted accounts
+	Assignees        struct{ Nodes []User }  `json:"assignees"`
+	Milestone        *gqlMilestone           `json:"milestone"`
+	Labels           struct{ Nodes []Label } `json:"labels"`
+	Comments         gqlComments             `json:"comments"`
+}
+
+// A gqlMilestone is a milestone in GitHub GraphQL JSON.
+type gqlMilestone struct {
+	Number      int64  `json:"number"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"` // OPEN or CLOSED
+	DueOn       string `json:"dueOn"`
+}
+
+// gqlComments is a page of issue comments in GitHub GraphQL JSON,
+// ending at the most recent comment or at the start of the previous page.
+type gqlComments struct {
+	PageInfo struct {
+		HasPreviousPage bool   `json:"hasPreviousPage"`
+		StartCursor     string `json:"startCursor"`
+	} `json:"pageInfo"`
+	Nodes []*gqlComment `json:"nodes"`
+}
+
+// A gqlComment is an issue comment in GitHub GraphQL JSON.
+type gqlComment struct {
+	DatabaseID int64  `json:"databaseId"`
+	URL        string `json:"url"`
+	Body       string `json:"body"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+	Minimized  bool   `json:"isMinimized"`
+	Reason     string `json:"minimizedReason"`
+	Author     *User  `json:"author"`
+	Assoc      string `json:"authorAssociation"`
+}
+
+// syncGraphQL syncs the issues and issue comments for a given project
+// using the GraphQL API.
+// It records all issues updated since proj.IssueDate,
+// along with their comments updated since then.
+// syncGraphQL updates proj.IssueDate to the latest issue date seen
+// and proj.CommentDate to the latest comment date seen before any error.
+func (c *Client) syncGraphQL(ctx context.Context, proj *projectSync) error {
+	defer proj.store(c.db)
+
+	b := c.db.Batch()
+	defer b.Apply()
+
+Restart:
+	since := proj.IssueDate
+	q := "repo:" + proj.Name + " sort:updated-asc"
+	if since != "" {
+		q += " updated:>=" + since
+	}
+	vars := map[string]any{"q": q, "first": graphQLPageSize, "after": nil}
+	n := 0
+	for {
+		var data struct {
+			Search struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []*gqlIssue `json:"nodes"`
+			} `json:"search"`
+		}
+		if err := c.graphQL(ctx, issuesQuery, vars, &data); err != nil {
+			return err
+		}
+		for _, iss := range data.Search.Nodes {
+			if iss.Number == 0 {
+				// Not an issue or pull request.
+				continue
+			}
+			if err := c.writeGraphQLIssue(ctx, b, proj, iss, since); err != nil {
+				return err
+			}
+			b.MaybeApply()
+			proj.IssueDate = iss.UpdatedAt
+		}
+		b.Apply()
+		proj.store(c.db) // update proj.IssueDate, proj.CommentDate
+
+		if !data.Search.PageInfo.HasNextPage {
+			return nil
+		}
+		if n += len(data.Search.Nodes); n >= maxSearchResults {
+			if proj.IssueDate == since {
+				return fmt.Errorf("github graphql sync: more than %d issues updated at %s", n, since)
+			}
+			goto Restart
+		}
+		vars["after"] = data.Search.PageInfo.EndCursor
+	}
+}
+
+// writeGraphQLIssue writes iss and its comments updated since the given time
+// to the database, in REST JSON form.
+// If iss has more comments than the query returned,
+// writeGraphQLIssue fetches earlier comments until it reaches
+// comments created before since, and then fetches any even earlier
+// comments updated since then using [Client.writeEditedComments].
+func (c *Client) writeGraphQLIssue(ctx context.Context, b storage.Batch, proj *projectSync, iss *gqlIssue, since string) error {
+	if iss.DatabaseID == 0 || iss.UpdatedAt == "" {
+		return fmt.Errorf("github graphql sync: malformed issue %s#%d", proj.Name, iss.Number)
+	}
+	raw, err := json.Marshal(restIssue(proj.Name, iss))
+	if err != nil {
+		return err
+	}
+	if err := c.writeSynced(b, proj, iss.Number, "/issues", iss.DatabaseID, raw); err != nil {
+		return err
+	}
+
+	owner, name, _ := strings.Cut(proj.Name, "/")
+	comments := iss.Comments
+	seen := make(map[int64]bool)
+	for {
+		for _, gc := range comments.Nodes {
+			if gc.DatabaseID == 0 || gc.UpdatedAt == "" {
+				return fmt.Errorf("github graphql sync: malformed comment on %s#%d", proj.Name, iss.Number)
+			}
+			seen[gc.DatabaseID] = true
+			if gc.UpdatedAt < since {
+				continue
+			}
+			raw, err := json.Marshal(restIssueComment(proj.Name, iss.Number, gc))
+			if err != nil {
+				return err
+			}
+			if err := c.writeSynced(b, proj, iss.Number, "/issues/comments", gc.DatabaseID, raw); err != nil {
+				return err
+			}
+			b.MaybeApply()
+			proj.CommentDate = max(proj.CommentDate, gc.UpdatedAt)
+		}
+		if !comments.PageInfo.HasPreviousPage {
+			return nil
+		}
+		if len(comments.Nodes) > 0 && comments.Nodes[0].CreatedAt < since {
+			// The earlier comments were all created before since,
+			// but some may have been edited since then.
+			return c.writeEditedComments(ctx, b, proj, iss.Number, since, seen)
+		}
+
+		var data struct {
+			Repository struct {
+				IssueOrPullRequest struct {
+					Comments gqlComments `json:"comments"`
+				} `json:"issueOrPullRequest"`
+			} `json:"repository"`
+		}
+		vars := map[string]any{
+			"owner":  owner,
+			"name":   name,
+			"number": iss.Number,
+			"last":   graphQLPageSize,
+			"before": comments.PageInfo.StartCursor,
+		}
+		if err := c.graphQL(ctx, issueCommentsQuery, vars, &data); err != nil {
+			return err
+		}
+		comments = data.Repository.IssueOrPullRequest.Comments
+	}
+}
+
+// writeEditedComments writes the comments on the issue updated since
+// the given time to the database, except those already written
+// (listed in seen), fetching them using the REST API.
+// Like the comments fetched using GraphQL, they are stored without
+// the fields that GraphQL does not provide.
+// The REST API does not report minimization, so they keep the
+// minimization recorded by the previous sync.
+func (c *Client) writeEditedComments(ctx context.Context, b storage.Batch, proj *projectSync, issue int64, since string, seen map[int64]bool) error {
+	values := url.Values{
+		"since":    {since},
+		"per_page": {"100"},
+	}
+	urlStr := fmt.Sprintf("https://api.github.com/repos/%s/issues/%d/comments?%s", proj.Name, issue, values.Encode())
+	for pg, err := range c.pages(ctx, urlStr, "") {
+		if err != nil {
+			return err
+		}
+		for _, raw := range pg.body {
+			var x restIssueCommentJSON
+			if err := json.Unmarshal(raw, &x); err != nil {
+				return fmt.Errorf("parsing JSON: %v", err)
+			}
+			if x.ID == 0 || x.IssueComment == nil || x.UpdatedAt == "" {
+				return fmt.Errorf("github graphql sync: malformed comment on %s#%d: %s", proj.Name, issue, raw)
+			}
+			if seen[x.ID] {
+				continue
+			}
+			x.Reactions = nil
+			if e, err := c.LookupIssueCommentEvent(proj.Name, issue, x.ID); err == nil {
+				if old, ok := e.Typed.(*IssueComment); ok {
+					x.Minimized, x.MinimizedReason = old.Minimized, old.MinimizedReason
+				}
+			}
+			raw, err := json.Marshal(x)
+			if err != nil {
+				return err
+			}
+			if err := c.writeSynced(b, proj, issue, "/issues/comments", x.ID, raw); err != nil {
+				return err
+			}
+			b.MaybeApply()
+			proj.CommentDate = max(proj.CommentDate, x.UpdatedAt)
+		}
+	}
+	return nil
+}
+
+// A restIssueJSON is an [Issue] with the ID that the REST API includes.
+type restIssueJSON struct {
+	ID int64 `json:"id"`
+	*Issue
+}
+
+// restIssue converts iss to the REST JSON form.
+func restIssue(project string, iss *gqlIssue) *restIssueJSON {
+	x := &Issue{
+		URL:              fmt.Sprintf("https://api.github.com/repos/%s/issues/%d", project, iss.Number),
+		HTMLURL:          iss.URL,
+		Number:           iss.Number,
+		User:             gqlUser(iss.Author),
+		Title:            iss.Title,
+		CreatedAt:        iss.CreatedAt,
+		UpdatedAt:        iss.UpdatedAt,
+		ClosedAt:         iss.ClosedAt,
+		Body:             iss.Body,
+		Assignees:        iss.Assignees.Nodes,
+		State:            "open",
+		Locked:           iss.Locked,
+		ActiveLockReason: strings.ToLower(iss.ActiveLockReason),
+		Labels:           iss.Labels.Nodes,
+	}
+	if len(x.Assignees) == 0 {
+		x.Assignees = nil
+	}
+	if len(x.Labels) == 0 {
+		x.Labels = nil
+	}
+	if iss.State != "OPEN" {
+		// CLOSED, or MERGED for pull requests.
+		x.State = "closed"
+	}
+	if m := iss.Milestone; m != nil {
+		x.Milestone = Milestone{
+			Number:      m.Number,
+			Title:       m.Title,
+			Description: m.Description,
+			State:       strings.ToLower(m.State),
+			DueOn:       m.DueOn,
+		}
+	}
+	if iss.Typename == "PullRequest" {
+		x.PullRequest = new(struct{})
+	}
+	return &restIssueJSON{ID: iss.DatabaseID, Issue: x}
+}
+
+// A restIssueCommentJSON is an [IssueComment] with the ID
+// that the REST API includes.
+type restIssueCommentJSON struct {
+	ID int64 `json:"id"`
+	*IssueComment
+}
+
+// restIssueComment converts the comment on the given issue
+// to the REST JSON form.
+func restIssueComment(project string, issue int64, gc *gqlComment) *restIssueCommentJSON {
+	return &restIssueCommentJSON{
+		ID: gc.DatabaseID,
+		IssueComment: &IssueComment{
+			URL:       fmt.Sprintf("https://api.github.com/repos/%s/issues/comments/%d", project, gc.DatabaseID),
+			IssueURL:  fmt.Sprintf("https://api.github.com/repos/%s/issues/%d", project, issue),
+			HTMLURL:   gc.URL,
+			User:      gqlUser(gc.Author),
+			CreatedAt: gc.CreatedAt,
+			UpdatedAt: gc.UpdatedAt,
+			Body:      gc.Body,
+
+			AuthorAssociation: gc.Assoc,
+
+			Minimized:       gc.Minimized,
+			MinimizedReason: strings.ToLower(gc.Reason),
+		},
+	}
+}
+
+// gqlUser returns the user for a GraphQL author,
+// which is nil for deleted accounts.
+// The REST API reports those as "ghost".
+func gqlUser(u *User) User {
+	if u == nil {
+		return User{Login: "ghost"}
+	}
+	return *u
+}
+
+// graphQL runs the GraphQL query with the given variables
+// and decodes the response data into obj.
+// It uses the api.github.com secret if available.
+func (c *Client) graphQL(ctx context.Context, query string, vars map[string]any, obj any) error {
+	js, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	if err != nil {
+		return err
+	}
+
+	auth := Token(c.secret)
+	nrate := 0
+	nfail := 0
+Redo:
+	if err := c.pace(ctx, "graphql", false); err != nil {
+		return err
+	}
+	c.slog.Info("github graphql", "vars", vars)
+	req, err := http.NewRequestWithContext(ctx, "POST", graphQLURL, bytes.NewReader(js))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+auth)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	c.observe(resp)
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("reading body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if limited, err := c.rateLimit(ctx, resp, data, nrate); err != nil {
+			return err
+		} else if limited {
+			if nrate++; nrate > 20 {
+				return fmt.Errorf("%s # too many rate limits\n%s", resp.Status, data)
+			}
+			goto Redo
+		}
+		if resp.StatusCode == http.StatusInternalServerError || resp.StatusCode == http.StatusBadGateway { // 500, 502
+			c.slog.Error("github graphql server failure", "code", resp.StatusCode, "status", resp.Status, "body", string(data))
+			if nfail++; nfail < 3 {
+				if err := c.sleep(ctx, time.Duration(nfail)*2*time.Second); err != nil {
+					return err
+				}
+				goto Redo
+			}
+		}
+		return fmt.Errorf("%s\n%s", resp.Status, data)
+	}
+
+	var reply struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return fmt.Errorf("parsing GraphQL response: %v", err)
+	}
+	if len(reply.Errors) > 0 {
+		var errs []error
+		for _, e := range reply.Errors {
+			errs = append(errs, fmt.Errorf("github graphql: %s: %s", e.Type, e.Message))
+		}
+		return errors.Join(errs...)
+	}
+	return json.Unmarshal(reply.Data, obj)
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestEnableGraphQL(t *testing.T) {
+	check := testutil.Checker(t)
+	c := New(testutil.Slogger(t), storage.MemDB(), secret.Empty(), nil)
+	const project = "rsc/markdown"
+	graphQL := func() bool {
+		t.Helper()
+		var proj projectSync
+		val, _ := c.db.Get(o(syncProjectKind, project))
+		check(json.Unmarshal(val, &proj))
+		return proj.GraphQL
+	}
+
+	if err := c.EnableGraphQL(project); err == nil {
+		t.Errorf("EnableGraphQL before Add succeeded")
+	}
+	check(c.Add(project))
+	if graphQL() {
+		t.Errorf("new project synced using GraphQL")
+	}
+	check(c.EnableGraphQL(project))
+	if !graphQL() {
+		t.Errorf("after EnableGraphQL, project not synced using GraphQL")
+	}
+	check(c.DisableGraphQL(project))
+	if graphQL() {
+		t.Errorf("after DisableGraphQL, project synced using GraphQL")
+	}
+}
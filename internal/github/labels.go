@@ -26,7 +26,7 @@ func (c *Client) CreateLabel(ctx context.Context, project string, lab Label) err
 		c.testMu.Lock()
 		defer c.testMu.Unlock()
 
-		c.testEdits = append(c.testEdits, &TestingEdit{
+		c.recordEdit(&TestingEdit{
 			Project: project,
 			Label:   lab,
 		})
@@ -50,7 +50,7 @@ func (c *Client) EditLabel(ctx context.Context, project, name string, changes La
 		c.testMu.Lock()
 		defer c.testMu.Unlock()
 
-		c.testEdits = append(c.testEdits, &TestingEdit{
+		c.recordEdit(&TestingEdit{
 			Project:      project,
 			Label:        Label{Name: name},
 			LabelChanges: &changes,
@@ -5,6 +5,8 @@
 package github
 
 import (
+	"fmt"
+
 	"golang.org/x/oscar/internal/github/wrap"
 	"golang.org/x/oscar/internal/llmapp"
 )
@@ -33,6 +35,33 @@ func (ic *IssueComment) ToLLMDoc() *llmapp.Doc {
 	}
 }
 
+// ToLLMDoc converts a ReviewComment to a format that can be used as
+// an input to an LLM. The title is the path of the file under review.
+func (rc *ReviewComment) ToLLMDoc() *llmapp.Doc {
+	return &llmapp.Doc{
+		Type:   "review comment",
+		URL:    rc.HTMLURL,
+		Author: rc.User.ForDisplay(),
+		Title:  rc.Path,
+		Text:   rc.Body,
+	}
+}
+
+// ToLLMDoc converts a PullRequestFile to a format that can be used as
+// an input to an LLM. The title is the path of the file, and the text
+// is its status, the number of lines added and deleted, and its diff.
+func (f *PullRequestFile) ToLLMDoc() *llmapp.Doc {
+	patch := f.Patch
+	if patch == "" {
+		patch = "(diff not available)"
+	}
+	return &llmapp.Doc{
+		Type:  "file diff",
+		Title: f.Filename,
+		Text:  fmt.Sprintf("%s, +%d -%d\n%s", f.Status, f.Additions, f.Deletions, patch),
+	}
+}
+
 // ForDisplay returns the user's login username.
 func (u *User) ForDisplay() string {
 	if u.Login == "" {
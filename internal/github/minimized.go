@@ -0,0 +1,88 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ErrOptedOut is returned by [Client.PostIssueComment] for issues
+// on which a bot comment has been minimized (see [Client.StopOnMinimized]).
+var ErrOptedOut = errors.New("github: issue opted out of bot comments")
+
+// StopOnMinimized configures the client to stop posting comments
+// on an issue once any comment on it by one of the given users,
+// typically the bots posting through the client, has been minimized
+// (hidden as off-topic, outdated, and so on).
+// Minimizing a bot comment is treated as an explicit request
+// to leave the issue alone: [Client.PostIssueComment] then fails
+// with an error wrapping [ErrOptedOut].
+//
+// The database only records minimization for projects synced using GraphQL
+// (see [Client.EnableGraphQL] and [IssueComment.Minimized]),
+// and only as of the last sync of the minimized comment's issue,
+// so [Client.OptedOut] may miss a recently minimized comment.
+// PostIssueComment therefore also asks GitHub whether a bot comment
+// on the issue is minimized just before posting, whichever API syncs the project.
+func (c *Client) StopOnMinimized(users ...string) {
+	c.bots = append(c.bots, users...)
+}
+
+// OptedOut reports whether the issue has opted out of further
+// bot comments: whether a comment on it by one of the users passed to
+// [Client.StopOnMinimized] has been minimized, as of the last sync.
+// Posters can call OptedOut to avoid logging actions that cannot run;
+// an action on an issue minimized since the last sync still fails
+// when [Client.PostIssueComment] re-checks the issue.
+func (c *Client) OptedOut(issue *Issue) bool {
+	if len(c.bots) == 0 {
+		return false
+	}
+	for ic := range c.Comments(issue) {
+		if ic.Minimized && slices.Contains(c.bots, ic.User.Login) {
+			return true
+		}
+	}
+	return false
+}
+
+// minimizedNow reports whether a comment on the issue by one of the
+// users passed to [Client.StopOnMinimized] is currently minimized,
+// fetching the issue's comments from GitHub using the GraphQL API.
+func (c *Client) minimizedNow(ctx context.Context, issue *Issue) (bool, error) {
+	owner, name, _ := strings.Cut(issue.Project(), "/")
+	vars := map[string]any{
+		"owner":  owner,
+		"name":   name,
+		"number": issue.Number,
+		"last":   graphQLPageSize,
+		"before": nil,
+	}
+	for {
+		var data struct {
+			Repository struct {
+				IssueOrPullRequest struct {
+					Comments gqlComments `json:"comments"`
+				} `json:"issueOrPullRequest"`
+			} `json:"repository"`
+		}
+		if err := c.graphQL(ctx, issueCommentsQuery, vars, &data); err != nil {
+			return false, err
+		}
+		comments := data.Repository.IssueOrPullRequest.Comments
+		for _, gc := range comments.Nodes {
+			if gc.Minimized && gc.Author != nil && slices.Contains(c.bots, gc.Author.Login) {
+				return true, nil
+			}
+		}
+		if !comments.PageInfo.HasPreviousPage {
+			return false, nil
+		}
+		vars["before"] = comments.PageInfo.StartCursor
+	}
+}
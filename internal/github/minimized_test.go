@@ -0,0 +1,93 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestStopOnMinimized(t *testing.T) {
+	check := testutil.Checker(t)
+	ctx := context.Background()
+	db := storage.MemDB()
+	c := New(testutil.Slogger(t), db, secret.Empty(), nil)
+	tc := c.Testing()
+	project := "rsc/tmp"
+	tc.AddIssue(project, &Issue{Number: 1})
+	tc.AddIssue(project, &Issue{Number: 2})
+	tc.AddIssue(project, &Issue{Number: 3})
+	tc.AddIssueComment(project, 1, &IssueComment{User: User{Login: "gabyhelp"}, Body: "hi", Minimized: true, MinimizedReason: "off-topic"})
+	tc.AddIssueComment(project, 2, &IssueComment{User: User{Login: "gabyhelp"}, Body: "hi"})
+	tc.AddIssueComment(project, 3, &IssueComment{User: User{Login: "rsc"}, Body: "spam", Minimized: true, MinimizedReason: "spam"})
+	issue := func(n int64) *Issue {
+		iss, err := LookupIssue(db, project, n)
+		check(err)
+		return iss
+	}
+
+	// Without StopOnMinimized, minimized comments are ignored.
+	if c.OptedOut(issue(1)) {
+		t.Errorf("OptedOut(1) = true before StopOnMinimized")
+	}
+
+	c.StopOnMinimized("gabyhelp")
+	for n, want := range map[int64]bool{1: true, 2: false, 3: false} {
+		if got := c.OptedOut(issue(n)); got != want {
+			t.Errorf("OptedOut(%d) = %t, want %t", n, got, want)
+		}
+		_, _, err := c.PostIssueComment(ctx, issue(n), &IssueCommentChanges{Body: "more"})
+		if want && !errors.Is(err, ErrOptedOut) {
+			t.Errorf("PostIssueComment(%d) = %v, want ErrOptedOut", n, err)
+		}
+		if !want && err != nil {
+			t.Errorf("PostIssueComment(%d) = %v", n, err)
+		}
+	}
+	if n := len(tc.Edits()); n != 2 {
+		t.Errorf("posted %d comments, want 2", n)
+	}
+}
+
+func TestStopOnMinimizedRecheck(t *testing.T) {
+	ctx := context.Background()
+	page := func(prev bool, login string, minimized bool) *http.Response {
+		return response(200, fmt.Sprintf(`{"data":{"repository":{"issueOrPullRequest":{"comments":{
+			"pageInfo":{"hasPreviousPage":%t,"startCursor":"c"},
+			"nodes":[{"databaseId":1,"author":{"login":%q},"isMinimized":%t}]}}}}}`, prev, login, minimized))
+	}
+	var slept []time.Duration
+	c := newRateClient(t, &slept,
+		// Issue 1: gabyhelp's comment on the earlier page
+		// was minimized after the last sync.
+		page(true, "rsc", true),
+		page(false, "gabyhelp", true),
+		// Issue 2: no minimized bot comments.
+		page(false, "gabyhelp", false),
+		response(201, `{"url":"api-url","html_url":"url"}`),
+	)
+	c.StopOnMinimized("gabyhelp")
+	issue := func(n int64) *Issue {
+		return &Issue{URL: fmt.Sprintf("https://api.github.com/repos/rsc/tmp/issues/%d", n), Number: n}
+	}
+
+	if c.OptedOut(issue(1)) {
+		t.Errorf("OptedOut(1) = true, want false before sync")
+	}
+	if _, _, err := c.PostIssueComment(ctx, issue(1), &IssueCommentChanges{Body: "more"}); !errors.Is(err, ErrOptedOut) {
+		t.Errorf("PostIssueComment(1) = %v, want ErrOptedOut", err)
+	}
+	if _, url, err := c.PostIssueComment(ctx, issue(2), &IssueCommentChanges{Body: "more"}); err != nil || url != "url" {
+		t.Errorf("PostIssueComment(2) = %q, %v, want url, nil", url, err)
+	}
+}
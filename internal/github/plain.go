@@ -0,0 +1,46 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PlainText rewrites the GitHub-flavored markdown text md into a
+// plain-text form that reads well with a screen reader.
+// It removes HTML comments and tags such as <details> and <sub>
+// (keeping their text), drops heading markers and emphasis,
+// and rewrites links "[text](url)" as "text (url)".
+// Lists, paragraphs and code are left as is.
+func PlainText(md string) string {
+	s := htmlCommentRE.ReplaceAllString(md, "")
+	// Keep each summary on its own line, since it usually
+	// introduces the details that follow.
+	s = summaryRE.ReplaceAllString(s, "$1:\n")
+	s = htmlTagRE.ReplaceAllString(s, "")
+	s = headingRE.ReplaceAllString(s, "$1:")
+	s = linkRE.ReplaceAllStringFunc(s, func(m string) string {
+		sub := linkRE.FindStringSubmatch(m)
+		text, url := sub[1], sub[2]
+		if text == url {
+			return url
+		}
+		return text + " (" + url + ")"
+	})
+	s = emphasisRE.ReplaceAllString(s, "$2")
+	s = blankLinesRE.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s) + "\n"
+}
+
+var (
+	htmlCommentRE = regexp.MustCompile(`(?s)<!--.*?-->`)
+	summaryRE     = regexp.MustCompile(`<summary>(.*?)</summary>`)
+	htmlTagRE     = regexp.MustCompile(`</?(details|summary|sub|sup|b|i|em|strong|br)\s*/?>`)
+	headingRE     = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.*?)[ \t]*#*[ \t]*$`)
+	linkRE        = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)\)`)
+	emphasisRE    = regexp.MustCompile(`(\*\*|__|\*)([^*\n]+?)(\*\*|__|\*)`)
+	blankLinesRE  = regexp.MustCompile(`\n{3,}`)
+)
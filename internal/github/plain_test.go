@@ -0,0 +1,27 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"hello", "hello\n"},
+		{"a <!-- hidden --> b", "a  b\n"},
+		{"## Status ##\nopen", "Status:\nopen\n"},
+		{"**TL;DR:** it *works*", "TL;DR: it works\n"},
+		{"see [issue 1](https://go.dev/issue/1)", "see issue 1 (https://go.dev/issue/1)\n"},
+		{"[https://go.dev](https://go.dev)", "https://go.dev\n"},
+		{"<details><summary>Details</summary>\n\nmore\n\n</details>\n", "Details:\n\nmore\n"},
+		{"\n<sub>(Emoji vote.)</sub>\n", "(Emoji vote.)\n"},
+		{"- a\n- b\n\n\n\nc", "- a\n- b\n\nc\n"},
+	} {
+		if got := PlainText(tt.in); got != tt.want {
+			t.Errorf("PlainText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
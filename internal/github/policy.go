@@ -0,0 +1,25 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import "golang.org/x/oscar/internal/policy"
+
+// SetPolicies configures the client to enforce the posting policies in s
+// for every comment it posts and every issue and comment it edits,
+// including edits diverted in testing and dry-run modes.
+// Edits that a project's policy disallows fail with an error
+// from [policy.Set.Do].
+func (c *Client) SetPolicies(s *policy.Set) {
+	c.policies = s
+}
+
+// withPolicy calls f to carry out the action described by r,
+// if the client's policies allow it.
+func (c *Client) withPolicy(r *policy.Request, f func() error) error {
+	if c.policies == nil {
+		return f()
+	}
+	return c.policies.Do(r, f)
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/policy"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestPolicies(t *testing.T) {
+	check := testutil.Checker(t)
+	ctx := context.Background()
+	db := storage.MemDB()
+	c := New(testutil.Slogger(t), db, secret.Empty(), nil)
+	c.Testing() // divert edits
+
+	ps := policy.New(db)
+	ps.SetPolicy("rsc/tmp", &policy.Policy{
+		Actions:     []policy.Action{policy.Comment, policy.Edit},
+		SkipAuthors: []string{"gopherbot"},
+	})
+	c.SetPolicies(ps)
+
+	issue := &Issue{
+		URL:    "https://api.github.com/repos/rsc/tmp/issues/5",
+		Number: 5,
+		User:   User{Login: "gopher"},
+	}
+	botIssue := &Issue{
+		URL:    "https://api.github.com/repos/rsc/tmp/issues/6",
+		Number: 6,
+		User:   User{Login: "gopherbot"},
+	}
+
+	_, _, err := c.PostIssueComment(ctx, issue, &IssueCommentChanges{Body: "hello"})
+	check(err)
+	check(c.EditIssue(ctx, issue, &IssueChanges{Title: "new title"}))
+	if err := c.EditIssue(ctx, issue, &IssueChanges{Labels: &[]string{"bug"}}); !errors.Is(err, policy.ErrNotAllowed) {
+		t.Errorf("EditIssue(labels) = %v, want ErrNotAllowed", err)
+	}
+	if err := c.EditIssue(ctx, issue, &IssueChanges{Title: "x", Labels: &[]string{"bug"}}); !errors.Is(err, policy.ErrNotAllowed) {
+		t.Errorf("EditIssue(title, labels) = %v, want ErrNotAllowed", err)
+	}
+	if _, _, err := c.PostIssueComment(ctx, botIssue, &IssueCommentChanges{Body: "hello"}); !errors.Is(err, policy.ErrSkipped) {
+		t.Errorf("PostIssueComment(gopherbot issue) = %v, want ErrSkipped", err)
+	}
+
+	var edits []string
+	for _, e := range c.Testing().Edits() {
+		edits = append(edits, e.String())
+	}
+	want := []string{
+		`PostIssueComment(rsc/tmp#5, {"body":"hello"})`,
+		`EditIssue(rsc/tmp#5, {"title":"new title"})`,
+	}
+	if !slices.Equal(edits, want) {
+		t.Errorf("Edits:\nhave %q\nwant %q", edits, want)
+	}
+	if n := ps.Posts("rsc/tmp", time.Now().Add(-time.Hour)); n != 2 {
+		t.Errorf("Posts = %d, want 2", n)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oscar/internal/policy"
+)
+
+// A pull request is also an issue, with the same number,
+// so the [Issue] for a pull request (one with PullRequest set)
+// is used to identify it. The methods in this file report
+// information on a pull request without adding to its
+// conversation thread: a review that only comments,
+// or a check run on its head commit.
+
+// A CheckRun is a check run reported on a commit,
+// shown on the "Checks" tab of pull requests with that head commit.
+// See https://docs.github.com/en/rest/checks/runs.
+type CheckRun struct {
+	Name       string         `json:"name"`
+	HeadSHA    string         `json:"head_sha"`
+	Status     string         `json:"status"`               // "queued", "in_progress" or "completed"
+	Conclusion string         `json:"conclusion,omitempty"` // for example "neutral", if completed
+	Output     CheckRunOutput `json:"output"`
+}
+
+// A CheckRunOutput is the output shown for a [CheckRun].
+type CheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"` // Markdown
+}
+
+// pullURL returns the API URL of the pull request pr.
+func pullURL(pr *Issue) string {
+	return strings.Replace(pr.URL, "/issues/", "/pulls/", 1)
+}
+
+// PostPullReview posts a review that only comments (neither approves
+// nor requests changes) with the given body (written in Markdown) on the
+// pull request pr. It returns an API URL for the new review, and a URL
+// suitable for display.
+// Like a comment, a review is subject to the client's posting policies
+// (see [Client.SetPolicies]).
+func (c *Client) PostPullReview(ctx context.Context, pr *Issue, body string) (id, url string, err error) {
+	if pr.PullRequest == nil {
+		return "", "", fmt.Errorf("github.PostPullReview: %s#%d is not a pull request", pr.Project(), pr.Number)
+	}
+	r := &policy.Request{Project: pr.Project(), Action: policy.Comment, Author: pr.User.Login}
+	err = c.withPolicy(r, func() error {
+		id, url, err = c.postPullReview(ctx, pr, body)
+		return err
+	})
+	return id, url, err
+}
+
+func (c *Client) postPullReview(ctx context.Context, pr *Issue, body string) (id, url string, err error) {
+	if c.divertEdits() {
+		c.testMu.Lock()
+		defer c.testMu.Unlock()
+
+		c.recordEdit(&TestingEdit{
+			Project: pr.Project(),
+			Issue:   pr.Number,
+			Review:  body,
+		})
+		return "test-api-url", "test-url", nil
+	}
+
+	review := struct {
+		Body  string `json:"body"`
+		Event string `json:"event"`
+	}{body, "COMMENT"}
+	data, err := c.post(ctx, pullURL(pr)+"/reviews", &review)
+	if err != nil {
+		return "", "", err
+	}
+	var res struct {
+		ID         int64  `json:"id"`
+		DisplayURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%s/reviews/%d", pullURL(pr), res.ID), res.DisplayURL, nil
+}
+
+// CreateCheckRun reports a completed check run with the given name
+// and output on the head commit of the pull request pr, with a
+// "neutral" conclusion, so that it informs without passing or failing
+// the pull request. It returns a URL for the check run suitable for display.
+//
+// GitHub only allows GitHub Apps to create check runs,
+// so the client's api.github.com secret must be an installation token
+// for an app with the "checks: write" permission.
+// Like a comment, a check run is subject to the client's posting policies
+// (see [Client.SetPolicies]).
+func (c *Client) CreateCheckRun(ctx context.Context, pr *Issue, name string, output *CheckRunOutput) (url string, err error) {
+	if pr.PullRequest == nil {
+		return "", fmt.Errorf("github.CreateCheckRun: %s#%d is not a pull request", pr.Project(), pr.Number)
+	}
+	r := &policy.Request{Project: pr.Project(), Action: policy.Comment, Author: pr.User.Login}
+	err = c.withPolicy(r, func() error {
+		url, err = c.createCheckRun(ctx, pr, name, output)
+		return err
+	})
+	return url, err
+}
+
+func (c *Client) createCheckRun(ctx context.Context, pr *Issue, name string, output *CheckRunOutput) (string, error) {
+	run := &CheckRun{
+		Name:       name,
+		Status:     "completed",
+		Conclusion: "neutral",
+		Output:     *output,
+	}
+	if c.divertEdits() {
+		c.testMu.Lock()
+		defer c.testMu.Unlock()
+
+		c.recordEdit(&TestingEdit{
+			Project:  pr.Project(),
+			Issue:    pr.Number,
+			CheckRun: run,
+		})
+		return "test-url", nil
+	}
+
+	// The head commit is not part of the issue, so look it up.
+	var pull struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if _, err := c.get(ctx, pullURL(pr), "", &pull); err != nil {
+		return "", err
+	}
+	run.HeadSHA = pull.Head.SHA
+	data, err := c.post(ctx, "https://api.github.com/repos/"+pr.Project()+"/check-runs", run)
+	if err != nil {
+		return "", err
+	}
+	var res struct {
+		DisplayURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return "", err
+	}
+	return res.DisplayURL, nil
+}
+
+// A PullRequestFile is a file changed by a pull request.
+// See https://docs.github.com/en/rest/pulls/pulls#list-pull-requests-files.
+type PullRequestFile struct {
+	Filename  string `json:"filename"`
+	Status    string `json:"status"` // for example "added", "modified", "removed" or "renamed"
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Patch     string `json:"patch,omitempty"` // unified diff hunks; empty for binary or very large files
+}
+
+// maxFilePages is the maximum number of pages of files
+// fetched by [Client.PullRequestFiles].
+// GitHub lists at most 3000 files, in pages of at most 100.
+const maxFilePages = 30
+
+// PullRequestFiles downloads the list of files changed by the pull request pr,
+// along with their diffs. Unlike most methods that read pull requests,
+// it always makes requests to GitHub: file diffs are not synced to the database.
+func (c *Client) PullRequestFiles(ctx context.Context, pr *Issue) ([]*PullRequestFile, error) {
+	if pr.PullRequest == nil {
+		return nil, fmt.Errorf("github.PullRequestFiles: %s#%d is not a pull request", pr.Project(), pr.Number)
+	}
+	var files []*PullRequestFile
+	for page := 1; page <= maxFilePages; page++ {
+		url := filesURL(pr, page)
+		if c.testing {
+			c.testMu.Lock()
+			js := c.testEvents[url]
+			c.testMu.Unlock()
+			if js == nil {
+				break
+			}
+		}
+		var fs []*PullRequestFile
+		if _, err := c.get(ctx, url, "", &fs); err != nil {
+			return nil, err
+		}
+		files = append(files, fs...)
+		if len(fs) < 100 {
+			break
+		}
+	}
+	return files, nil
+}
+
+// filesURL returns the API URL listing the given page of files
+// changed by the pull request pr.
+func filesURL(pr *Issue, page int) string {
+	return fmt.Sprintf("%s/files?per_page=100&page=%d", pullURL(pr), page)
+}
@@ -0,0 +1,237 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitHub limits API requests in two ways
+// (see https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api).
+//
+// The primary rate limits allow a number of requests per hour
+// for each resource ("core" for most REST requests, "graphql", "search", ...).
+// Every response reports the status of its resource's limit in
+// X-Ratelimit-* headers.
+//
+// The secondary rate limits restrict bursts of requests, especially edits.
+// GitHub reports exceeding one with a 403 or 429 response,
+// usually with a Retry-After header.
+//
+// A Client records the primary rate limit status from every response
+// and paces its requests: before making a request, if the resource's
+// remaining requests are down to rateReserve, it waits until the limit resets.
+// It also waits at least the edit interval between edits
+// (see [Client.SetEditInterval]), as GitHub recommends.
+// When a request is rate limited anyway, the Client waits and retries it.
+// That way, long syncs and edit runs pause and resume instead of failing.
+
+// rateReserve is the number of remaining requests at which
+// a Client stops making requests until the rate limit resets.
+// Some requests are left for other users of the same token,
+// such as people running tools by hand.
+const rateReserve = 10
+
+// defaultEditInterval is the default minimum time between edits.
+const defaultEditInterval = 1 * time.Second
+
+// timeSkew is extra time to wait for a rate limit to reset,
+// in case our clock is out of sync with GitHub's.
+const timeSkew = 1 * time.Minute
+
+// secondaryBackoff is the time to wait after hitting a secondary rate limit
+// without a Retry-After header, doubled for each retry.
+const secondaryBackoff = 1 * time.Minute
+
+// A RateLimit is the status of one of GitHub's primary API rate limits,
+// as last reported to a [Client].
+type RateLimit struct {
+	Resource  string    // "core", "graphql", "search", ...
+	Limit     int       // requests allowed in each window
+	Remaining int       // requests remaining in the current window
+	Reset     time.Time // end of the current window
+}
+
+// RateLimits returns the status of the rate limits used by
+// the client's requests so far, sorted by resource.
+func (c *Client) RateLimits() []RateLimit {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	var limits []RateLimit
+	for _, rl := range c.rates {
+		limits = append(limits, rl)
+	}
+	slices.SortFunc(limits, func(x, y RateLimit) int {
+		return strings.Compare(x.Resource, y.Resource)
+	})
+	return limits
+}
+
+// SetEditInterval sets the minimum time between edits
+// made by the client (default one second).
+func (c *Client) SetEditInterval(d time.Duration) {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	c.editInterval = d
+}
+
+// pace waits, if necessary, before a request for the given resource.
+// If edit is true, the request is an edit.
+// pace returns an error only if ctx is canceled while waiting.
+func (c *Client) pace(ctx context.Context, resource string, edit bool) error {
+	c.rateMu.Lock()
+	var delay time.Duration
+	now := time.Now()
+	limited := false
+	if rl, ok := c.rates[resource]; ok && rl.Remaining <= rateReserve {
+		if d := rl.Reset.Sub(now); d > 0 {
+			delay = d + timeSkew
+			limited = true
+			c.slog.Info("github ratelimit pause", "resource", resource,
+				"remaining", rl.Remaining, "reset", rl.Reset.Format(time.RFC3339))
+		}
+	}
+	if edit && c.editInterval > 0 {
+		delay = max(delay, c.lastEdit.Add(c.editInterval).Sub(now))
+		// Reserve the edit slot now, so that concurrent edits
+		// wait for each other.
+		c.lastEdit = now.Add(delay)
+	}
+	c.rateMu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	if err := c.sleep(ctx, delay); err != nil {
+		return err
+	}
+	if limited {
+		c.limitReset(resource)
+	}
+	return nil
+}
+
+// limitReset records that the rate limit for resource has been reset,
+// after waiting for its reset time.
+func (c *Client) limitReset(resource string) {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	if rl, ok := c.rates[resource]; ok {
+		rl.Remaining = rl.Limit
+		c.rates[resource] = rl
+	}
+}
+
+// observe records the rate limit status reported in resp.
+func (c *Client) observe(resp *http.Response) {
+	h := resp.Header
+	limit, err1 := strconv.Atoi(h.Get("X-Ratelimit-Limit"))
+	remaining, err2 := strconv.Atoi(h.Get("X-Ratelimit-Remaining"))
+	reset, err3 := strconv.ParseInt(h.Get("X-Ratelimit-Reset"), 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return
+	}
+	resource := h.Get("X-Ratelimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	if c.rates == nil {
+		c.rates = make(map[string]RateLimit)
+	}
+	c.rates[resource] = RateLimit{
+		Resource:  resource,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}
+}
+
+// rateLimit looks at the response, with the given body,
+// to decide whether a rate limit has been applied.
+// If so, rateLimit waits until the limit should be lifted:
+// until the time specified in the response, plus a bit extra, for a primary limit,
+// or for the Retry-After time or an exponential backoff for a secondary limit.
+// The retry argument is the number of times the request has been retried so far.
+// rateLimit reports whether this was a rate-limit response, in which case
+// the request should be retried, or returns an error if ctx is canceled
+// while waiting.
+func (c *Client) rateLimit(ctx context.Context, resp *http.Response, body []byte, retry int) (bool, error) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false, nil
+	}
+
+	// Secondary rate limit.
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return false, nil
+		}
+		c.slog.Info("github secondary ratelimit", "retry-after", n)
+		return true, c.sleep(ctx, time.Duration(n)*time.Second)
+	}
+	if bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit")) {
+		delay := secondaryBackoff << min(retry, 5)
+		c.slog.Info("github secondary ratelimit", "backoff", delay)
+		return true, c.sleep(ctx, delay)
+	}
+
+	// Primary rate limit.
+	if resp.Header.Get("X-Ratelimit-Remaining") != "0" {
+		return false, nil
+	}
+	n, _ := strconv.Atoi(resp.Header.Get("X-Ratelimit-Reset"))
+	if n == 0 {
+		return false, nil
+	}
+	t := time.Unix(int64(n), 0)
+	delay := t.Sub(time.Now()) + timeSkew
+	if delay < 0 {
+		// We are being asked to sleep until a time that already happened.
+		// If it happened recently (within timeSkew), then maybe our clock
+		// is just out of sync with GitHub's clock.
+		// But if it happened long ago, we are probably reading an old HTTP trace.
+		// In that case, return false (we didn't find a valid ratelimit).
+		return false, nil
+	}
+	c.slog.Info("github ratelimit", "reset", t.Format(time.RFC3339),
+		"limit", resp.Header.Get("X-Ratelimit-Limit"),
+		"remaining", resp.Header.Get("X-Ratelimit-Remaining"),
+		"used", resp.Header.Get("X-Ratelimit-Used"))
+	if err := c.sleep(ctx, delay); err != nil {
+		return false, err
+	}
+	resource := resp.Header.Get("X-Ratelimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+	c.limitReset(resource)
+	return true, nil
+}
+
+// sleep waits for the duration d, or until ctx is canceled,
+// in which case it returns ctx.Err().
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
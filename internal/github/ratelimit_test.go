@@ -0,0 +1,197 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// A scriptedTransport is an [http.RoundTripper] that serves
+// a fixed sequence of responses.
+type scriptedTransport struct {
+	responses []*http.Response
+}
+
+func (t *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.responses) == 0 {
+		return nil, errors.New("no more responses")
+	}
+	resp := t.responses[0]
+	t.responses = t.responses[1:]
+	resp.Request = req
+	return resp, nil
+}
+
+// response returns a response with the given code and body,
+// and headers given as key, value pairs.
+func response(code int, body string, kv ...string) *http.Response {
+	h := make(http.Header)
+	for i := 0; i < len(kv); i += 2 {
+		h.Set(kv[i], kv[i+1])
+	}
+	return &http.Response{
+		StatusCode: code,
+		Status:     strconv.Itoa(code) + " " + http.StatusText(code),
+		Header:     h,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// newRateClient returns a client that serves the responses
+// and records its sleeps in *slept instead of sleeping.
+func newRateClient(t *testing.T, slept *[]time.Duration, responses ...*http.Response) *Client {
+	c := New(testutil.Slogger(t), storage.MemDB(), secret.Empty(), &http.Client{Transport: &scriptedTransport{responses}})
+	c.testing = false
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		*slept = append(*slept, d)
+		return nil
+	}
+	return c
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	reset := time.Now().Add(1 * time.Hour).Truncate(time.Second)
+	resetStr := strconv.FormatInt(reset.Unix(), 10)
+
+	// near returns whether d is within a few seconds of want,
+	// to allow for time passing during the test.
+	near := func(d, want time.Duration) bool {
+		return (d - want).Abs() < 5*time.Second
+	}
+
+	t.Run("track", func(t *testing.T) {
+		var slept []time.Duration
+		c := newRateClient(t, &slept,
+			response(200, "{}", "X-Ratelimit-Limit", "5000", "X-Ratelimit-Remaining", "5", "X-Ratelimit-Reset", resetStr),
+			response(200, `{"data":{}}`, "X-Ratelimit-Limit", "5000", "X-Ratelimit-Remaining", "4999", "X-Ratelimit-Reset", resetStr,
+				"X-Ratelimit-Resource", "graphql"),
+			response(200, "{}"),
+		)
+		var x any
+		if _, err := c.get(ctx, "https://api.github.com/x", "", &x); err != nil {
+			t.Fatal(err)
+		}
+		if len(slept) != 0 {
+			t.Fatalf("first get slept %v", slept)
+		}
+		want := []RateLimit{
+			{Resource: "core", Limit: 5000, Remaining: 5, Reset: reset},
+		}
+		if got := c.RateLimits(); len(got) != 1 || got[0] != want[0] {
+			t.Fatalf("RateLimits() = %v, want %v", got, want)
+		}
+
+		// The next core request waits for the reset,
+		// but graphql requests do not.
+		if err := c.graphQL(ctx, "query", nil, &x); err != nil {
+			t.Fatal(err)
+		}
+		if len(slept) != 0 {
+			t.Fatalf("graphql request slept %v", slept)
+		}
+		if _, err := c.get(ctx, "https://api.github.com/x", "", &x); err != nil {
+			t.Fatal(err)
+		}
+		if len(slept) != 1 || !near(slept[0], time.Until(reset)+timeSkew) {
+			t.Errorf("get near limit slept %v, want ~%v", slept, time.Until(reset)+timeSkew)
+		}
+		if got := c.RateLimits(); len(got) != 2 || got[1].Resource != "graphql" {
+			t.Errorf("RateLimits() = %v, want core and graphql", got)
+		}
+	})
+
+	t.Run("primary", func(t *testing.T) {
+		var slept []time.Duration
+		c := newRateClient(t, &slept,
+			response(403, "limit", "X-Ratelimit-Limit", "5000", "X-Ratelimit-Remaining", "0", "X-Ratelimit-Reset", resetStr),
+			response(200, "{}", "X-Ratelimit-Limit", "5000", "X-Ratelimit-Remaining", "4999", "X-Ratelimit-Reset", resetStr),
+		)
+		var x any
+		if _, err := c.get(ctx, "https://api.github.com/x", "", &x); err != nil {
+			t.Fatal(err)
+		}
+		// The rate limit response sleeps until the reset;
+		// the retry does not sleep again.
+		if len(slept) != 1 || !near(slept[0], time.Until(reset)+timeSkew) {
+			t.Errorf("slept %v, want ~%v", slept, time.Until(reset)+timeSkew)
+		}
+	})
+
+	t.Run("secondary", func(t *testing.T) {
+		var slept []time.Duration
+		c := newRateClient(t, &slept,
+			response(403, "slow down", "Retry-After", "30"),
+			response(429, "You have exceeded a secondary rate limit."),
+			response(403, "You have exceeded a Secondary Rate Limit."),
+			response(200, "{}"),
+		)
+		var x any
+		if _, err := c.get(ctx, "https://api.github.com/x", "", &x); err != nil {
+			t.Fatal(err)
+		}
+		want := []time.Duration{30 * time.Second, 2 * time.Minute, 4 * time.Minute}
+		if !slices.Equal(slept, want) {
+			t.Errorf("slept %v, want %v", slept, want)
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		var slept []time.Duration
+		c := newRateClient(t, &slept, response(403, "Resource not accessible by integration"))
+		var x any
+		if _, err := c.get(ctx, "https://api.github.com/x", "", &x); err == nil {
+			t.Fatal("get succeeded, want error")
+		}
+		if len(slept) != 0 {
+			t.Errorf("slept %v for a non-rate-limit 403", slept)
+		}
+	})
+
+	t.Run("edits", func(t *testing.T) {
+		var slept []time.Duration
+		c := newRateClient(t, &slept, response(200, "{}"), response(200, "{}"), response(200, "{}"))
+		for range 2 {
+			if _, err := c.patch(ctx, "https://api.github.com/x", map[string]string{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(slept) != 1 || !near(slept[0], defaultEditInterval) {
+			t.Errorf("second edit slept %v, want ~%v", slept, defaultEditInterval)
+		}
+		c.SetEditInterval(0)
+		slept = nil
+		if _, err := c.patch(ctx, "https://api.github.com/x", map[string]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if len(slept) != 0 {
+			t.Errorf("edit with no interval slept %v", slept)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		c := New(testutil.Slogger(t), storage.MemDB(), secret.Empty(),
+			&http.Client{Transport: &scriptedTransport{[]*http.Response{response(403, "", "Retry-After", "3600")}}})
+		c.testing = false
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		var x any
+		if _, err := c.get(ctx, "https://api.github.com/x", "", &x); !errors.Is(err, context.Canceled) {
+			t.Errorf("get with canceled context = %v, want context.Canceled", err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A repository's maintainers can tune the bots' behavior in the
+// repository with a settings file, [RepoConfigFile], in the root of
+// its default branch. The client syncs the file, unparsed, for projects
+// enabled with [Client.EnableRepoConfig]; package repoconfig
+// interprets it.
+
+// RepoConfigFile is the name of a repository's settings file.
+const RepoConfigFile = ".oscar.yaml"
+
+// maxRepoConfig is the maximum size of a settings file.
+const maxRepoConfig = 64 << 10
+
+const repoConfigKind = "github.RepoConfig"
+
+// EnableRepoConfig configures the client to also sync the settings file,
+// [RepoConfigFile], of the project, which must already have been added
+// with [Client.Add]. See [Client.RepoConfig].
+func (c *Client) EnableRepoConfig(project string) error {
+	return c.updateProject(project, "EnableRepoConfig", func(proj *projectSync) {
+		proj.RepoConfig = true
+	})
+}
+
+// RepoConfig returns the contents of the project's settings file,
+// [RepoConfigFile], as of the last sync, only consulting the database
+// (not actual GitHub). It reports false if the project has no settings
+// file, or has not been enabled with [Client.EnableRepoConfig] and synced.
+func (c *Client) RepoConfig(project string) ([]byte, bool) {
+	return c.db.Get(o(repoConfigKind, project))
+}
+
+// A repoContent is a file returned by the GitHub contents API.
+type repoContent struct {
+	Type     string
+	Encoding string
+	Size     int
+	Content  string
+}
+
+// syncRepoConfig downloads and saves the project's settings file,
+// deleting the saved copy if the file no longer exists.
+// proj.RepoConfigETag records the ETag of the last response,
+// so that an unchanged file costs nothing.
+func (c *Client) syncRepoConfig(ctx context.Context, proj *projectSync) error {
+	key := o(repoConfigKind, proj.Name)
+	var file repoContent
+	resp, err := c.get(ctx, repoConfigURL(proj.Name), proj.RepoConfigETag, &file)
+	if err == errNotModified {
+		return nil
+	}
+	if errors.Is(err, errNotFound) {
+		if _, ok := c.db.Get(key); ok {
+			c.slog.Info("github repo config deleted", "project", proj.Name)
+			c.db.Delete(key)
+		}
+		proj.RepoConfigETag = ""
+		proj.store(c.db)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := decodeRepoContent(&file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", RepoConfigFile, err)
+	}
+	if old, ok := c.db.Get(key); !ok || string(old) != string(data) {
+		c.slog.Info("github repo config updated", "project", proj.Name, "size", len(data))
+		c.db.Set(key, data)
+	}
+	if resp.Header != nil {
+		proj.RepoConfigETag = resp.Header.Get("Etag")
+	}
+	proj.store(c.db)
+	return nil
+}
+
+// decodeRepoContent returns the contents of the file,
+// checking that it is a regular file of at most maxRepoConfig bytes.
+func decodeRepoContent(file *repoContent) ([]byte, error) {
+	if file.Type != "file" {
+		return nil, fmt.Errorf("not a file (type %q)", file.Type)
+	}
+	if file.Size > maxRepoConfig {
+		return nil, fmt.Errorf("file too large (%d bytes, want at most %d)", file.Size, maxRepoConfig)
+	}
+	if file.Encoding != "base64" {
+		return nil, fmt.Errorf("unexpected encoding %q", file.Encoding)
+	}
+	// GitHub breaks the base64 into lines.
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+}
+
+// repoConfigURL returns the contents API URL of the project's settings file.
+func repoConfigURL(project string) string {
+	return "https://api.github.com/repos/" + project + "/contents/" + RepoConfigFile
+}
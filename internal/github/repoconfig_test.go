@@ -0,0 +1,68 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSyncRepoConfig(t *testing.T) {
+	ctx := context.Background()
+	const project = "rsc/markdown"
+	content := func(data string) string {
+		enc := base64.StdEncoding.EncodeToString([]byte(data))
+		// GitHub breaks the base64 into lines.
+		return fmt.Sprintf(`{"type":"file","encoding":"base64","size":%d,"content":"%s\n%s"}`, len(data), enc[:4], enc[4:])
+	}
+	var slept []time.Duration
+	c := newRateClient(t, &slept,
+		response(200, content("minscore: 0.9\n"), "Etag", `"v1"`),
+		response(304, ""),
+		response(200, content("language: ja\n"), "Etag", `"v2"`),
+		response(404, `{"message":"Not Found"}`),
+		response(200, `{"type":"dir"}`),
+	)
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(c.Add(project))
+	check(c.EnableRepoConfig(project))
+	sync := func() error {
+		var proj projectSync
+		c.db.Lock(string(o(syncProjectKind, project)))
+		defer c.db.Unlock(string(o(syncProjectKind, project)))
+		val, _ := c.db.Get(o(syncProjectKind, project))
+		check(json.Unmarshal(val, &proj))
+		return c.syncRepoConfig(ctx, &proj)
+	}
+	want := func(data string, wantOK bool) {
+		t.Helper()
+		got, ok := c.RepoConfig(project)
+		if string(got) != data || ok != wantOK {
+			t.Errorf("RepoConfig = %q, %v; want %q, %v", got, ok, data, wantOK)
+		}
+	}
+
+	want("", false)
+	check(sync())
+	want("minscore: 0.9\n", true)
+	check(sync()) // not modified
+	want("minscore: 0.9\n", true)
+	check(sync())
+	want("language: ja\n", true)
+	check(sync()) // deleted
+	want("", false)
+	if err := sync(); err == nil {
+		t.Errorf("sync of directory succeeded, want error")
+	}
+}
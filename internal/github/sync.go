@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"golang.org/x/oscar/internal/policy"
 	"golang.org/x/oscar/internal/secret"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/storage/timed"
@@ -32,6 +33,7 @@ import (
 
 const (
 	eventKind       = "github.Event"
+	bodyKind        = "github.Body"
 	syncProjectKind = "github.SyncProject"
 )
 
@@ -40,12 +42,25 @@ const (
 //	["github.SyncProject", Project] => JSON of projectSync structure
 //	["github.Event", Project, Issue, API, ID] => [DBTime, Raw(JSON)]
 //	["github.EventByTime", DBTime, Project, Issue, API, ID] => []
+//	["github.EventVersion", Key, DBTime] => version of "/issues" or "/issues/comments" event (see [timed.SetVersioned])
+//	["github.Milestone", Project, Number] => JSON of milestone
+//	["github.BoardItem", Project, Issue, BoardURL] => JSON of [BoardItem]
+//	["github.RepoConfig", Project] => contents of the project's [RepoConfigFile]
 //
 // To reconstruct the history of a given issue, scan for keys from
 // ["github.Event", Project, Issue] to ["github.Event", Project, Issue, ordered.Inf].
 //
-// The API field is "/issues", "/issues/comments", or "/issues/events",
+// The API field is "/issues", "/issues/comments", "/issues/events",
+// "/issues/timeline" (cross references, only synced for projects
+// enabled with [Client.EnableTimeline]),
+// or "/pulls/comments" (pull request review comments, only synced for projects
+// enabled with [Client.EnableReviewComments]),
 // so the first key-value pair is the issue creation event with the issue body text.
+// Issues and issue comments are downloaded using the REST API by default,
+// or using the GraphQL API for projects enabled with [Client.EnableGraphQL];
+// either way they are stored in the REST JSON form.
+// GitHub treats pull requests as issues, so a pull request is stored
+// as an "/issues" event with a non-nil [Issue.PullRequest] field.
 //
 // The IDs are GitHub's and appear to be ordered by time within an API,
 // so that the comments are time-ordered and the events are time-ordered,
@@ -60,6 +75,11 @@ const (
 // record was added to the database. Code that processes new events can
 // record which DBTime it has most recently processed and then scan forward in
 // the index to learn about new events.
+//
+// EventVersion records every value written for an issue or issue comment,
+// so that [LookupIssueAsOf] and [Client.CommentsAsOf] can report
+// what the database said about an issue at an earlier time.
+// The other events do not change once written, so they are not versioned.
 
 // o is short for ordered.Encode.
 func o(list ...any) []byte { return ordered.Encode(list...) }
@@ -79,7 +99,17 @@ type Client struct {
 	secret secret.DB
 	http   *http.Client
 
-	testing bool
+	testing  bool
+	dryRun   bool        // see [Client.EnableDryRun]
+	policies *policy.Set // see [Client.SetPolicies]
+	bots     []string    // see [Client.StopOnMinimized]
+
+	// rate limit state; see ratelimit.go
+	rateMu       sync.Mutex
+	rates        map[string]RateLimit // by resource
+	lastEdit     time.Time
+	editInterval time.Duration
+	sleep        func(context.Context, time.Duration) error
 
 	testMu     sync.Mutex
 	testClient *TestingClient
@@ -99,6 +129,9 @@ func New(lg *slog.Logger, db storage.DB, sdb secret.DB, hc *http.Client) *Client
 		secret:  sdb,
 		http:    hc,
 		testing: testing.Testing(),
+
+		editInterval: defaultEditInterval,
+		sleep:        sleep,
 	}
 }
 
@@ -111,8 +144,57 @@ type projectSync struct {
 	CommentDate string
 	RefillID    int64
 
+	// ReviewComments reports whether to sync pull request review comments;
+	// ReviewCommentDate is the latest review comment date seen.
+	ReviewComments    bool
+	ReviewCommentDate string
+
+	// IssueETag, CommentETag and ReviewCommentETag are the ETags
+	// of the last responses to the issue, issue comment and review comment
+	// list requests, if those responses were complete and contained
+	// nothing new (see [Client.syncByDate]).
+	IssueETag         string
+	CommentETag       string
+	ReviewCommentETag string
+
+	// Milestones reports whether to sync the project's milestones
+	// (see [Client.EnableMilestones]); MilestoneETag is the ETag
+	// of the last complete milestone list.
+	Milestones    bool
+	MilestoneETag string
+
+	// Boards lists the project boards to sync membership of
+	// (see [Client.EnableBoard]).
+	Boards []boardSync
+
+	// RepoConfig reports whether to sync the project's settings file
+	// (see [Client.EnableRepoConfig]); RepoConfigETag is the ETag
+	// of the last response for it.
+	RepoConfig     bool
+	RepoConfigETag string
+
+	// Timeline reports whether to sync the cross references in issue
+	// timelines (see [Client.EnableTimeline]); TimelineDBTime is the
+	// DBTime of the last issue change whose timeline has been synced.
+	Timeline       bool
+	TimelineDBTime timed.DBTime
+
+	// GraphQL reports whether to sync issues and issue comments
+	// using the GraphQL API (see [Client.EnableGraphQL]).
+	// The GraphQL sync records its progress in IssueDate and CommentDate.
+	GraphQL bool
+
 	FullSyncActive bool
 	FullSyncIssue  int64
+
+	// SyncStart is the update date before which issues and comments
+	// have not been synced (see [Client.AddSince]), or "" if they all have.
+	// Backfill is the state of a [Client.Backfill] in progress, if any.
+	SyncStart string
+	Backfill  *backfillSync `json:",omitempty"`
+
+	// Config selects the data to store (see [Client.SetSyncConfig]).
+	Config SyncConfig
 }
 
 // store stores proj into db.
@@ -135,6 +217,36 @@ func (c *Client) Add(project string) error {
 	return nil
 }
 
+// EnableReviewComments configures the client to also sync
+// pull request review comments (comments on lines of a pull request's diff)
+// for the project, which must already have been added with [Client.Add].
+// Review comments are stored as events with API "/pulls/comments";
+// see [Client.ReviewComments] and [Client.ReviewThreads].
+func (c *Client) EnableReviewComments(project string) error {
+	return c.updateProject(project, "EnableReviewComments", func(proj *projectSync) {
+		proj.ReviewComments = true
+	})
+}
+
+// updateProject loads the sync state for project, calls f to update it,
+// and stores the result. It is used to implement the Enable methods,
+// which are named by op in errors.
+func (c *Client) updateProject(project, op string, f func(*projectSync)) error {
+	key := o(syncProjectKind, project)
+	c.db.Lock(string(key))
+	defer c.db.Unlock(string(key))
+
+	var proj projectSync
+	if val, ok := c.db.Get(key); !ok {
+		return fmt.Errorf("github %s: missing project %v", op, project)
+	} else if err := json.Unmarshal(val, &proj); err != nil {
+		return err
+	}
+	f(&proj)
+	proj.store(c.db)
+	return nil
+}
+
 // Sync syncs all projects.
 func (c *Client) Sync(ctx context.Context) error {
 	for key := range c.db.Scan(o(syncProjectKind), o(syncProjectKind, ordered.Inf)) {
@@ -180,11 +292,37 @@ func (c *Client) SyncProject(ctx context.Context, project string) (err error) {
 	}
 
 	// Sync issues, comments, events.
-	if err := c.syncIssues(ctx, &proj); err != nil {
-		return err
+	if proj.GraphQL {
+		if err := c.syncGraphQL(ctx, &proj); err != nil {
+			return err
+		}
+	} else {
+		if err := c.syncIssues(ctx, &proj); err != nil {
+			return err
+		}
+		if err := c.syncIssueComments(ctx, &proj); err != nil {
+			return err
+		}
 	}
-	if err := c.syncIssueComments(ctx, &proj); err != nil {
-		return err
+	if proj.ReviewComments {
+		if err := c.syncReviewComments(ctx, &proj); err != nil {
+			return err
+		}
+	}
+	if proj.Milestones {
+		if err := c.syncMilestones(ctx, &proj); err != nil {
+			return err
+		}
+	}
+	for _, b := range proj.Boards {
+		if err := c.syncBoard(ctx, &proj, b); err != nil {
+			return err
+		}
+	}
+	if proj.RepoConfig {
+		if err := c.syncRepoConfig(ctx, &proj); err != nil {
+			return err
+		}
 	}
 
 	// See syncIssueEvents doc comment for details about this dance.
@@ -192,7 +330,8 @@ func (c *Client) SyncProject(ctx context.Context, project string) (err error) {
 	// of events. To initialize a repo, we need a “full sync” that scans one
 	// issue at a time. We also need the full sync if we fall too far behind
 	// by not syncing for many days.
-	if proj.EventID == 0 || proj.FullSyncActive {
+	// Projects configured with SkipEvents need neither.
+	if !proj.Config.SkipEvents && (proj.EventID == 0 || proj.FullSyncActive) {
 		// Full scan.
 		if proj.EventID == 0 {
 			proj.FullSyncActive = true
@@ -202,7 +341,11 @@ func (c *Client) SyncProject(ctx context.Context, project string) (err error) {
 				return err
 			}
 		}
-		if err := c.syncIssues(ctx, &proj); err != nil {
+		if proj.GraphQL {
+			if err := c.syncGraphQL(ctx, &proj); err != nil {
+				return err
+			}
+		} else if err := c.syncIssues(ctx, &proj); err != nil {
 			return err
 		}
 		for key := range c.db.Scan(o(eventKind, project), o(eventKind, project, ordered.Inf)) {
@@ -228,8 +371,16 @@ func (c *Client) SyncProject(ctx context.Context, project string) (err error) {
 	}
 
 	// Incremental scan.
-	if err := c.syncIssueEvents(ctx, &proj, 0, false); err != nil {
-		return err
+	if !proj.Config.SkipEvents {
+		if err := c.syncIssueEvents(ctx, &proj, 0, false); err != nil {
+			return err
+		}
+	}
+
+	if proj.Timeline {
+		if err := c.syncTimeline(ctx, &proj); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -248,17 +399,33 @@ func (c *Client) syncIssueComments(ctx context.Context, proj *projectSync) error
 	return c.syncByDate(ctx, proj, "/issues/comments")
 }
 
-// syncByDate downloads and saves issues or issue comments since
-// the date specified in proj (proj.IssueDate or proj.CommentDate).
-// api is "/issues" for issues or "/issues/comments" for issue comments.
+// syncReviewComments syncs the pull request review comments for a given project.
+// It records all new review comments since proj.ReviewCommentDate.
+// If successful, it updates proj.ReviewCommentDate to the latest comment date seen.
+func (c *Client) syncReviewComments(ctx context.Context, proj *projectSync) error {
+	return c.syncByDate(ctx, proj, "/pulls/comments")
+}
+
+// syncByDate downloads and saves issues, issue comments or review comments since
+// the date specified in proj (proj.IssueDate, proj.CommentDate or proj.ReviewCommentDate).
+// api is "/issues" for issues, "/issues/comments" for issue comments,
+// or "/pulls/comments" for pull request review comments.
 // syncByDate updates the proj date with the new latest date seen
 // before any error.
+//
+// Because the since parameter is inclusive, a sync with nothing new
+// still returns the last item seen. In that case syncByDate saves the
+// response's ETag in proj (proj.IssueETag, proj.CommentETag or
+// proj.ReviewCommentETag) and sends it in the next request, so that
+// GitHub can answer 304 Not Modified, which does not count against
+// the rate limit. An ETag is only saved for a single-page response,
+// since new items are added to the last page, leaving the first unchanged.
 func (c *Client) syncByDate(ctx context.Context, proj *projectSync, api string) error {
 Restart:
 	// For these APIs, we can ask GitHub for the event stream in increasing time order,
 	// so we can iterate through all the events, saving the latest time we have seen,
 	// and pick up where we left off.
-	var since *string
+	var since, etag *string
 	values := url.Values{
 		"sort":      {"updated"},
 		"direction": {"asc"},
@@ -268,15 +435,20 @@ Restart:
 	default:
 		panic("downloadByDate api: " + api)
 	case "/issues":
-		since = &proj.IssueDate
+		since, etag = &proj.IssueDate, &proj.IssueETag
 		values["state"] = []string{"all"}
 		values["per_page"] = []string{"100"}
 	case "/issues/comments":
-		since = &proj.CommentDate
+		since, etag = &proj.CommentDate, &proj.CommentETag
+	case "/pulls/comments":
+		since, etag = &proj.ReviewCommentDate, &proj.ReviewCommentETag
+		values["per_page"] = []string{"100"}
 	}
 	if *since != "" {
 		values["since"] = []string{*since}
 	}
+	oldSince := *since
+	var newETag string
 
 	b := c.db.Batch()
 	defer b.Apply()
@@ -284,50 +456,34 @@ Restart:
 	urlStr := "https://api.github.com/repos/" + proj.Name + api + "?" + values.Encode()
 	npage := 0
 	defer proj.store(c.db)
-	for pg, err := range c.pages(ctx, urlStr, "") {
+	for pg, err := range c.pages(ctx, urlStr, *etag) {
+		if err == errNotModified {
+			return nil
+		}
 		if err != nil {
 			return err
 		}
+		if npage == 0 && findNext(pg.resp.Header.Get("Link")) == "" {
+			newETag = pg.resp.Header.Get("Etag")
+		}
 
 		for _, raw := range pg.body {
-			var meta struct {
-				ID        int64  `json:"id"`
-				Updated   string `json:"updated_at"`
-				Number    int64  `json:"number"`    // for /issues feed
-				IssueURL  string `json:"issue_url"` // for /issues/comments feed
-				CreatedAt string `json:"created_at"`
-			}
-			if err := json.Unmarshal(raw, &meta); err != nil {
-				return fmt.Errorf("parsing JSON: %v", err)
-			}
-			if meta.ID == 0 {
-				return fmt.Errorf("parsing message: no id: %s", string(raw))
-			}
-			if meta.Updated == "" {
-				return fmt.Errorf("parsing JSON: no updated_at: %s", string(raw))
+			meta, err := parseByDate(api, raw)
+			if err != nil {
+				return err
 			}
-
-			switch api {
-			default:
-				c.db.Panic("github downloadByDate bad api", "api", api)
-			case "/issues":
-				if meta.Number == 0 {
-					return fmt.Errorf("parsing message: no number: %s", string(raw))
-				}
-			case "/issues/comments":
-				n, err := strconv.ParseInt(meta.IssueURL[strings.LastIndex(meta.IssueURL, "/")+1:], 10, 64)
-				if err != nil {
-					return fmt.Errorf("invalid comment URL: %s", meta.IssueURL)
-				}
-				meta.Number = n
+			if err := c.writeSynced(b, proj, meta.Number, api, meta.ID, raw); err != nil {
+				return err
 			}
-
-			c.writeEvent(b, proj.Name, meta.Number, api, meta.ID, raw)
 			b.MaybeApply()
 			*since = meta.Updated
 		}
+		if *since != oldSince {
+			newETag = ""
+		}
+		*etag = newETag
 		b.Apply()
-		proj.store(c.db) // update *since
+		proj.store(c.db) // update *since and *etag
 
 		// GitHub stops returning results after 1000 pages.
 		// After 500 pages, restart pagination with a new since value.
@@ -339,6 +495,55 @@ Restart:
 	return nil
 }
 
+// byDateMeta is the metadata that syncByDate needs
+// from each item in an issue, issue comment or review comment list.
+type byDateMeta struct {
+	ID        int64  `json:"id"`
+	Updated   string `json:"updated_at"`
+	Number    int64  `json:"number"`           // for /issues feed
+	IssueURL  string `json:"issue_url"`        // for /issues/comments feed
+	PullURL   string `json:"pull_request_url"` // for /pulls/comments feed
+	CreatedAt string `json:"created_at"`
+}
+
+// parseByDate parses the metadata of raw, an item in the list
+// returned by api (see [Client.syncByDate]).
+// It sets the Number field to the item's issue number for all APIs.
+func parseByDate(api string, raw json.RawMessage) (*byDateMeta, error) {
+	var meta byDateMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %v", err)
+	}
+	if meta.ID == 0 {
+		return nil, fmt.Errorf("parsing message: no id: %s", string(raw))
+	}
+	if meta.Updated == "" {
+		return nil, fmt.Errorf("parsing JSON: no updated_at: %s", string(raw))
+	}
+
+	switch api {
+	default:
+		panic("github parseByDate bad api: " + api)
+	case "/issues":
+		if meta.Number == 0 {
+			return nil, fmt.Errorf("parsing message: no number: %s", string(raw))
+		}
+	case "/issues/comments":
+		n, err := strconv.ParseInt(meta.IssueURL[strings.LastIndex(meta.IssueURL, "/")+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid comment URL: %s", meta.IssueURL)
+		}
+		meta.Number = n
+	case "/pulls/comments":
+		n, err := strconv.ParseInt(meta.PullURL[strings.LastIndex(meta.PullURL, "/")+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid review comment URL: %s", meta.PullURL)
+		}
+		meta.Number = n
+	}
+	return &meta, nil
+}
+
 // syncIssueEvents downloads and saves new issue events in the given project.
 //
 // The /issues/events API does not have a "since time T" option:
@@ -485,19 +690,30 @@ Pages:
 }
 
 // writeEvent writes a single event to the database using SetTimed, to maintain a time-ordered index.
+// Issues and issue comments are written using SetVersioned, to keep their history.
 func (c *Client) writeEvent(b storage.Batch, project string, issue int64, api string, id int64, raw json.RawMessage) {
-	timed.Set(c.db, b, eventKind, o(project, issue, api, id), o(ordered.Raw(raw)))
+	key, val := o(project, issue, api, id), o(ordered.Raw(raw))
+	if api == "/issues" || api == "/issues/comments" {
+		timed.SetVersioned(c.db, b, eventKind, key, val)
+		return
+	}
+	timed.Set(c.db, b, eventKind, key, val)
 }
 
 // errNotModified is returned by get when an etag is being used
 // and the server returns a 304 not modified response.
 var errNotModified = errors.New("304 not modified")
 
+// errNotFound is wrapped by the error get returns
+// when the server returns a 404 not found response.
+var errNotFound = errors.New("404 Not Found")
+
 // get fetches url and decodes the body as JSON into obj.
 //
 // If etag is non-empty, the request includes an If-None-Match: etag header
 // and get returns errNotModified if the server says the object is unmodified
 // since that etag.
+// If the object does not exist, get returns an error wrapping errNotFound.
 //
 // get uses the api.github.com secret if available.
 // Otherwise it makes an unauthenticated request.
@@ -519,6 +735,9 @@ func (c *Client) get(ctx context.Context, url, etag string, obj any) (*http.Resp
 	nrate := 0
 	nfail := 0
 Redo:
+	if err := c.pace(ctx, "core", false); err != nil {
+		return nil, err
+	}
 	c.slog.Info("github get", "url", url)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -532,6 +751,7 @@ Redo:
 	if err != nil {
 		return nil, err
 	}
+	c.observe(resp)
 	data, err := io.ReadAll(resp.Body)
 	resp.Body.Close()
 	if err != nil {
@@ -541,7 +761,12 @@ Redo:
 		if resp.StatusCode == http.StatusNotModified { // 304
 			return nil, errNotModified
 		}
-		if c.rateLimit(resp) {
+		if resp.StatusCode == http.StatusNotFound { // 404
+			return nil, fmt.Errorf("%w\n%s", errNotFound, data)
+		}
+		if limited, err := c.rateLimit(ctx, resp, data, nrate); err != nil {
+			return nil, err
+		} else if limited {
 			if nrate++; nrate > 20 {
 				return nil, fmt.Errorf("%s # too many rate limits\n%s", resp.Status, data)
 			}
@@ -550,7 +775,9 @@ Redo:
 		if resp.StatusCode == http.StatusInternalServerError || resp.StatusCode == http.StatusBadGateway { // 500, 502
 			c.slog.Error("github get server failure", "code", resp.StatusCode, "status", resp.Status, "body", string(data))
 			if nfail++; nfail < 3 {
-				time.Sleep(time.Duration(nfail) * 2 * time.Second)
+				if err := c.sleep(ctx, time.Duration(nfail)*2*time.Second); err != nil {
+					return nil, err
+				}
 				goto Redo
 			}
 		}
@@ -576,7 +803,9 @@ type page struct {
 	body []json.RawMessage
 }
 
-// pages returns a paginated result starting at url and using etag.
+// pages returns a paginated result starting at url.
+// The etag, if non-empty, is sent with the request for the first page,
+// in which case pages yields nil, errNotModified if that page is unmodified.
 // If pages encounters an error, it yields nil, err.
 func (c *Client) pages(ctx context.Context, url, etag string) iter.Seq2[*page, error] {
 	return func(yield func(*page, error) bool) {
@@ -591,6 +820,7 @@ func (c *Client) pages(ctx context.Context, url, etag string) iter.Seq2[*page, e
 				return
 			}
 			url = findNext(resp.Header.Get("Link"))
+			etag = ""
 		}
 	}
 }
@@ -636,35 +866,3 @@ func findNext(link string) string {
 	}
 	return ""
 }
-
-// rateLimit looks at the response to decide whether a rate limit has been applied.
-// If so, rateLimit sleeps until the time specified in the response, plus a bit extra.
-// rateLimit reports whether this was a rate-limit response.
-func (c *Client) rateLimit(resp *http.Response) bool {
-	if resp.StatusCode != http.StatusForbidden || resp.Header.Get("X-Ratelimit-Remaining") != "0" {
-		return false
-	}
-	n, _ := strconv.Atoi(resp.Header.Get("X-Ratelimit-Reset"))
-	if n == 0 {
-		return false
-	}
-	t := time.Unix(int64(n), 0)
-	now := time.Now()
-
-	const timeSkew = 1 * time.Minute
-	delay := t.Sub(now) + timeSkew
-	if delay < 0 {
-		// We are being asked to sleep until a time that already happened.
-		// If it happened recently (within timeSkew), then maybe our clock
-		// is just out of sync with GitHub's clock.
-		// But if it happened long ago, we are probably reading an old HTTP trace.
-		// In that case, return false (we didn't find a valid ratelimit).
-		return false
-	}
-	c.slog.Info("github ratelimit", "reset", t.Format(time.RFC3339),
-		"limit", resp.Header.Get("X-Ratelimit-Limit"),
-		"remaining", resp.Header.Get("X-Ratelimit-Remaining"),
-		"used", resp.Header.Get("X-Ratelimit-Used"))
-	time.Sleep(delay)
-	return true
-}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A SyncConfig selects which of a project's data the client stores,
+// to control the growth of the database for very large projects.
+// The zero SyncConfig stores everything.
+type SyncConfig struct {
+	// SkipReactions drops the summaries of emoji reactions
+	// from synced issues and comments.
+	SkipReactions bool
+
+	// SkipEvents skips syncing issue events (API "/issues/events"),
+	// such as label and assignment changes.
+	// Analyses that use events, such as finding when an issue
+	// was labeled, see none for the project.
+	SkipEvents bool
+
+	// MaxBodyKB, if positive, is the maximum size in kilobytes
+	// of the body of an issue, issue comment or review comment
+	// stored with it. Longer bodies are truncated, and the full
+	// body is stored separately, only once rather than in every
+	// version of the issue or comment (see [Client.FullBody]).
+	MaxBodyKB int
+}
+
+// SetSyncConfig sets the sync configuration of the project,
+// which must already have been added with [Client.Add].
+// The configuration applies to the data synced from then on.
+func (c *Client) SetSyncConfig(project string, cfg SyncConfig) error {
+	return c.updateProject(project, "SetSyncConfig", func(proj *projectSync) {
+		proj.Config = cfg
+	})
+}
+
+// SyncConfig returns the sync configuration of the project
+// (see [Client.SetSyncConfig]).
+func (c *Client) SyncConfig(project string) (SyncConfig, error) {
+	var proj projectSync
+	val, ok := c.db.Get(o(syncProjectKind, project))
+	if !ok {
+		return SyncConfig{}, fmt.Errorf("github SyncConfig: missing project %v", project)
+	}
+	if err := json.Unmarshal(val, &proj); err != nil {
+		return SyncConfig{}, err
+	}
+	return proj.Config, nil
+}
+
+// truncatedSuffix is appended to truncated bodies.
+const truncatedSuffix = "\n\n[…truncated]"
+
+// writeSynced writes a synced issue, issue comment or review comment
+// (depending on api) to the database, as [Client.writeEvent] does,
+// after removing the data that proj's configuration skips.
+func (c *Client) writeSynced(b storage.Batch, proj *projectSync, issue int64, api string, id int64, raw json.RawMessage) error {
+	cfg := proj.Config
+	if !cfg.SkipReactions && cfg.MaxBodyKB <= 0 {
+		c.writeEvent(b, proj.Name, issue, api, id, raw)
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return fmt.Errorf("parsing JSON: %v", err)
+	}
+	if cfg.SkipReactions {
+		delete(fields, "reactions")
+	}
+	if max := cfg.MaxBodyKB * 1024; max > 0 && len(fields["body"]) > max {
+		var body string
+		if err := json.Unmarshal(fields["body"], &body); err != nil {
+			return fmt.Errorf("parsing JSON body: %v", err)
+		}
+		if len(body) > max {
+			b.Set(o(bodyKind, proj.Name, issue, api, id), []byte(body))
+			fields["body"] = storage.JSON(truncate(body, max) + truncatedSuffix)
+		}
+	}
+	c.writeEvent(b, proj.Name, issue, api, id, storage.JSON(fields))
+	return nil
+}
+
+// truncate returns the longest prefix of s that is at most n bytes
+// and does not end in the middle of a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// FullBody returns the full body of the issue, issue comment
+// or review comment in the event e, which may have been truncated
+// when it was synced (see [SyncConfig.MaxBodyKB]).
+func (c *Client) FullBody(e *Event) string {
+	if body, ok := c.db.Get(o(bodyKind, e.Project, e.Issue, e.API, e.ID)); ok {
+		return string(body)
+	}
+	switch x := e.Typed.(type) {
+	case *Issue:
+		return x.Body
+	case *IssueComment:
+		return x.Body
+	case *ReviewComment:
+		return x.Body
+	}
+	return ""
+}
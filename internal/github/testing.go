@@ -52,6 +52,13 @@ func (c *Client) EnableTesting() {
 	c.testing = true
 }
 
+// DisableTesting disables testing mode, so that edits are sent to GitHub
+// even in a test binary. It is meant for tests that use an HTTP client
+// connected to a fake GitHub server, such as the one in package fakegithub.
+func (c *Client) DisableTesting() {
+	c.testing = false
+}
+
 // A TestingEdit is a diverted edit, which was logged instead of actually applied on GitHub.
 type TestingEdit struct {
 	Project             string
@@ -61,6 +68,8 @@ type TestingEdit struct {
 	IssueCommentChanges *IssueCommentChanges
 	Label               Label
 	LabelChanges        *LabelChanges
+	Review              string    // body of a pull request review
+	CheckRun            *CheckRun // check run on a pull request
 }
 
 // String returns a basic string representation of the edit.
@@ -87,6 +96,14 @@ func (e *TestingEdit) String() string {
 	case e.Label.Name != "":
 		js, _ := json.Marshal(e.Label)
 		return fmt.Sprintf("CreateLabel(%s#%d, %s)", e.Project, e.Issue, js)
+
+	case e.Review != "":
+		js, _ := json.Marshal(e.Review)
+		return fmt.Sprintf("PostPullReview(%s#%d, %s)", e.Project, e.Issue, js)
+
+	case e.CheckRun != nil:
+		js, _ := json.Marshal(e.CheckRun)
+		return fmt.Sprintf("CreateCheckRun(%s#%d, %s)", e.Project, e.Issue, js)
 	}
 	return "?"
 }
@@ -162,6 +179,53 @@ func (tc *TestingClient) AddIssueComment(project string, issue int64, comment *I
 	return id
 }
 
+// SetReactions sets the reactions to the given issue comment,
+// which must have been added with [TestingClient.AddIssueComment].
+// Like GitHub, SetReactions does not create a new event in the database:
+// the new reactions are only visible to [Client.DownloadIssueComment].
+func (tc *TestingClient) SetReactions(comment *IssueComment, r *Reactions) {
+	comment.Reactions = r
+	js := json.RawMessage(storage.JSON(comment))
+	tc.c.testMu.Lock()
+	tc.c.testEvents[comment.URL] = js
+	tc.c.testMu.Unlock()
+}
+
+// AddReaction adds a reaction by the given user to the issue comment,
+// which must have been added with [TestingClient.AddIssueComment].
+// The reaction is only visible to [Client.CommentReactions];
+// it does not change the comment's [Reactions] summary.
+func (tc *TestingClient) AddReaction(comment *IssueComment, user, content string) {
+	tc.c.testMu.Lock()
+	defer tc.c.testMu.Unlock()
+
+	url := reactionsURL(comment)
+	var rs []*Reaction
+	if js := tc.c.testEvents[url]; js != nil {
+		if err := json.Unmarshal(js, &rs); err != nil {
+			tc.c.db.Panic("github testing reactions decode", "url", url, "err", err)
+		}
+	}
+	rs = append(rs, &Reaction{ID: int64(len(rs) + 1), User: User{Login: user}, Content: content})
+	if tc.c.testEvents == nil {
+		tc.c.testEvents = make(map[string]json.RawMessage)
+	}
+	tc.c.testEvents[url] = json.RawMessage(storage.JSON(rs))
+}
+
+// SetPullRequestFiles sets the files changed by the pull request pr,
+// as returned by [Client.PullRequestFiles].
+// At most 100 files are supported.
+func (tc *TestingClient) SetPullRequestFiles(pr *Issue, files []*PullRequestFile) {
+	tc.c.testMu.Lock()
+	defer tc.c.testMu.Unlock()
+
+	if tc.c.testEvents == nil {
+		tc.c.testEvents = make(map[string]json.RawMessage)
+	}
+	tc.c.testEvents[filesURL(pr, 1)] = json.RawMessage(storage.JSON(files))
+}
+
 // AddIssueEvent adds the given issue event to the identified project issue,
 // assigning it a new comment ID starting at 10¹¹.
 // AddIssueEvent creates a new entry in the associated [Client]'s
@@ -184,6 +248,50 @@ func (tc *TestingClient) AddIssueEvent(project string, issue int64, event *Issue
 	})
 }
 
+// AddCrossReference adds a cross reference from the source issue
+// (which may be in any project) to the identified project issue,
+// as if synced by a client with [Client.EnableTimeline].
+// It uses the source issue's number as the source issue ID.
+// AddCrossReference creates a new entry in the associated [Client]'s
+// underlying database, so other Client's using the same database
+// will see the cross reference too.
+func (tc *TestingClient) AddCrossReference(project string, issue int64, ref *CrossReference) {
+	ref.Event = "cross-referenced"
+	if ref.Source.Type == "" {
+		ref.Source.Type = "issue"
+	}
+	id := ref.Source.Issue.Number
+	tc.addEvent(fmt.Sprintf("https://api.github.com/repos/%s/issues/%d/timeline/%d", project, issue, id), &Event{
+		Project: project,
+		Issue:   issue,
+		API:     "/issues/timeline",
+		ID:      id,
+		Typed:   ref,
+	})
+}
+
+// AddReviewComment adds the given review comment to the identified
+// project pull request, assigning it a new comment ID starting at 10¹⁰.
+// To add a reply, set comment.InReplyTo to the ID of the first
+// comment in the thread.
+// AddReviewComment creates a new entry in the associated [Client]'s
+// underlying database, so other Client's using the same database
+// will see the review comment too.
+func (tc *TestingClient) AddReviewComment(project string, pr int64, comment *ReviewComment) int64 {
+	id := atomic.AddInt64(&tc.commentID, +1)
+	comment.URL = fmt.Sprintf("https://api.github.com/repos/%s/pulls/comments/%d", project, id)
+	comment.PullRequestURL = fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d", project, pr)
+	comment.HTMLURL = fmt.Sprintf("https://github.com/%s/pull/%d#discussion_r%d", project, pr, id)
+	tc.addEvent(comment.URL, &Event{
+		Project: project,
+		Issue:   pr,
+		API:     "/pulls/comments",
+		ID:      id,
+		Typed:   comment,
+	})
+	return id
+}
+
 // AddLabel adds the given label to the client, so that calls
 // to DownloadLabel and ListLabels will return it.
 // It does not affect the database, since labels aren't stored there.
@@ -212,6 +320,17 @@ func (tc *TestingClient) AddLabel(project string, lab Label) {
 	tc.c.testMu.Unlock()
 }
 
+// SetRepoConfig sets the contents of the project's settings file
+// (see [Client.RepoConfig]) in the database, as a sync would.
+// A nil data deletes the settings file.
+func (tc *TestingClient) SetRepoConfig(project string, data []byte) {
+	if data == nil {
+		tc.c.db.Delete(o(repoConfigKind, project))
+		return
+	}
+	tc.c.db.Set(o(repoConfigKind, project), data)
+}
+
 // Edits returns a list of all the edits that have been applied using [Client] methods
 // (for example [Client.EditIssue], [Client.EditIssueComment], [Client.PostIssueComment]).
 // These edits have not been applied on GitHub, only diverted into the [TestingClient].
@@ -240,7 +359,7 @@ func (tc *TestingClient) ClearEdits() {
 
 // divertEdits reports whether edits are being diverted.
 func (c *Client) divertEdits() bool {
-	return c.testing
+	return c.testing || c.dryRun
 }
 
 // LoadTxtar loads issue histories from the named txtar file,
@@ -0,0 +1,184 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// An issue's timeline is the sequence of everything that happened to it.
+// Most timeline events (labels, closes, reopens, commit references, ...)
+// are also issue events, which are always synced ("/issues/events").
+// The exception of interest is cross references, recording that another
+// issue or pull request mentioned the issue, which are only available
+// from the timeline API. For projects enabled with [Client.EnableTimeline],
+// the sync fetches the timeline of each issue that has changed
+// and stores its cross references as events with API "/issues/timeline".
+// [Client.Timeline] combines the two kinds of events.
+//
+// Mentioning an issue does not change the issue, so a new cross reference
+// is only noticed the next time the issue itself changes.
+
+// EnableTimeline configures the client to also sync the cross references
+// in the timelines of the project's issues, which costs one request
+// per changed issue. The project must already have been added with [Client.Add].
+// See [Client.Timeline].
+func (c *Client) EnableTimeline(project string) error {
+	return c.updateProject(project, "EnableTimeline", func(proj *projectSync) {
+		proj.Timeline = true
+	})
+}
+
+// A CrossReference is an event in a GitHub issue timeline,
+// recording that another issue or pull request mentioned the issue.
+// The ID of a cross reference event is the ID of the source issue.
+type CrossReference struct {
+	Event     string               `json:"event"` // "cross-referenced"
+	Actor     User                 `json:"actor"`
+	CreatedAt string               `json:"created_at"`
+	Source    CrossReferenceSource `json:"source"`
+}
+
+// A CrossReferenceSource is the source of a [CrossReference].
+type CrossReferenceSource struct {
+	Type  string `json:"type"` // "issue" (which includes pull requests)
+	Issue *Issue `json:"issue"`
+}
+
+// A TimelineEvent is an event in an issue's timeline; see [Client.Timeline].
+// Exactly one of IssueEvent and CrossReference is non-nil.
+type TimelineEvent struct {
+	Event          string // for example "labeled", "closed", "reopened", "cross-referenced"
+	Actor          User
+	CreatedAt      string
+	IssueEvent     *IssueEvent
+	CrossReference *CrossReference
+}
+
+// Timeline returns an iterator over the events in the issue's timeline, oldest first,
+// only consulting the database (not actual GitHub).
+// The timeline includes the issue events (labels, closes, reopens,
+// commit references and so on) and, for projects enabled with
+// [Client.EnableTimeline], the cross references from other issues
+// and pull requests.
+// It does not include comments; see [Client.Comments].
+func (c *Client) Timeline(iss *Issue) iter.Seq[*TimelineEvent] {
+	return func(yield func(*TimelineEvent) bool) {
+		for _, e := range c.timeline(iss) {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// timeline returns the events in the issue's timeline, oldest first.
+func (c *Client) timeline(iss *Issue) []*TimelineEvent {
+	project := iss.Project()
+	var list []*TimelineEvent
+	for e := range eventsByAPI(c.db, project, iss.Number, "/issues/events") {
+		ev := e.Typed.(*IssueEvent)
+		list = append(list, &TimelineEvent{Event: ev.Event, Actor: ev.Actor, CreatedAt: ev.CreatedAt, IssueEvent: ev})
+	}
+	for e := range eventsByAPI(c.db, project, iss.Number, "/issues/timeline") {
+		ref := e.Typed.(*CrossReference)
+		list = append(list, &TimelineEvent{Event: ref.Event, Actor: ref.Actor, CreatedAt: ref.CreatedAt, CrossReference: ref})
+	}
+	slices.SortStableFunc(list, func(x, y *TimelineEvent) int {
+		return cmp.Compare(x.CreatedAt, y.CreatedAt)
+	})
+	return list
+}
+
+// syncTimeline syncs the timelines of the issues in the project
+// that have changed since proj.TimelineDBTime, and advances it.
+func (c *Client) syncTimeline(ctx context.Context, proj *projectSync) error {
+	// Find the issues that have changed, with the time of their last change.
+	last := make(map[int64]timed.DBTime)
+	filter := func(key []byte) bool {
+		var project, api string
+		var issue int64
+		if _, err := ordered.DecodePrefix(key, &project, &issue, &api); err != nil {
+			c.db.Panic("github timeline decode", "key", storage.Fmt(key), "err", err)
+		}
+		return project == proj.Name && (api == "/issues" || api == "/issues/comments")
+	}
+	for e := range timed.ScanAfter(c.slog, c.db, eventKind, proj.TimelineDBTime, filter) {
+		var issue int64
+		if _, err := ordered.DecodePrefix(e.Key, nil, &issue); err != nil {
+			c.db.Panic("github timeline decode", "key", storage.Fmt(e.Key), "err", err)
+		}
+		last[issue] = e.ModTime
+	}
+
+	// Sync them in order of last change, so that once an issue is done,
+	// every change at or before its last one has been handled,
+	// and proj.TimelineDBTime can be advanced.
+	issues := slices.SortedFunc(func(yield func(int64) bool) {
+		for n := range last {
+			if !yield(n) {
+				return
+			}
+		}
+	}, func(x, y int64) int { return cmp.Compare(last[x], last[y]) })
+	for _, n := range issues {
+		if err := c.syncIssueTimeline(ctx, proj.Name, n); err != nil {
+			return err
+		}
+		proj.TimelineDBTime = last[n]
+		proj.store(c.db)
+	}
+	return nil
+}
+
+// syncIssueTimeline fetches the timeline of a single issue
+// and saves its new or changed cross references.
+func (c *Client) syncIssueTimeline(ctx context.Context, project string, issue int64) error {
+	b := c.db.Batch()
+	defer b.Apply()
+
+	urlStr := fmt.Sprintf("https://api.github.com/repos/%s/issues/%d/timeline?per_page=100&page=1", project, issue)
+	for pg, err := range c.pages(ctx, urlStr, "") {
+		if err != nil {
+			return err
+		}
+		for _, raw := range pg.body {
+			var meta struct {
+				Event  string `json:"event"`
+				Source struct {
+					Issue struct {
+						ID int64 `json:"id"`
+					} `json:"issue"`
+				} `json:"source"`
+			}
+			if err := json.Unmarshal(raw, &meta); err != nil {
+				return fmt.Errorf("parsing JSON: %v", err)
+			}
+			if meta.Event != "cross-referenced" {
+				continue
+			}
+			id := meta.Source.Issue.ID
+			if id == 0 {
+				return fmt.Errorf("parsing cross reference: no source issue ID: %s", string(raw))
+			}
+			if old, ok := timed.Get(c.db, eventKind, o(project, issue, "/issues/timeline", id)); ok && bytes.Equal(old.Val, o(ordered.Raw(raw))) {
+				continue
+			}
+			c.writeEvent(b, project, issue, "/issues/timeline", id, raw)
+			b.MaybeApply()
+		}
+	}
+	return nil
+}
@@ -45,6 +45,7 @@ var alwaysAllowed = []string{
 	"github.com/golang/...",
 	"github.com/google/...",
 	"github.com/cockroachdb/pebble",
+	"github.com/klauspost/compress/...",
 	"rsc.io/markdown",
 	"rsc.io/omap",
 	"rsc.io/ordered",
@@ -79,6 +80,14 @@ var rules = []*rule{
 		packages: "internal/pebble/...",
 		allow:    anything,
 	},
+	{
+		packages: "internal/postgres/...",
+		allow:    anything,
+	},
+	{
+		packages: "internal/sqlite/...",
+		allow:    anything,
+	},
 	{
 		packages: "internal/dbspec/...",
 		allow:    anything,
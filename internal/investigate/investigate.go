@@ -0,0 +1,168 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package investigate provides read-only tools for an [llmapp.Agent]
+// that look things up in Oscar's databases and run reproduction cases,
+// and uses them to investigate GitHub issues.
+//
+// Each tool is created by a function such as [SearchTool];
+// pass the tools to [llmapp.Client.NewAgent] and the agent to [Issue].
+package investigate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/gerrit"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/repro"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// searchLimit is the number of results returned by the search tool.
+const searchLimit = 10
+
+// SearchTool returns a tool that searches the vector database for
+// the documents in dc closest to a query, embedded using embed.
+func SearchTool(vdb storage.VectorDB, dc *docs.Corpus, embed llm.Embedder) *llmapp.Tool {
+	return &llmapp.Tool{
+		Name:        "search",
+		Description: "Search for documents (issues, changes, documentation pages, discussions) related to a query. Returns the URLs, titles and similarity scores of the closest documents.",
+		Params:      map[string]string{"query": "the text to search for"},
+		Run: func(ctx context.Context, args map[string]string) (string, error) {
+			q := args["query"]
+			if q == "" {
+				return "", errors.New("missing query")
+			}
+			results, err := search.Query(ctx, vdb, dc, embed, &search.QueryRequest{
+				EmbedDoc: llm.EmbedDoc{Text: q},
+				Options:  search.Options{Limit: searchLimit},
+			})
+			if err != nil {
+				return "", err
+			}
+			if len(results) == 0 {
+				return "No results.", nil
+			}
+			var b strings.Builder
+			for _, r := range results {
+				r.Round()
+				fmt.Fprintf(&b, "%s %s %q (score %.2f)\n", r.ID, r.Kind, r.Title, r.Score)
+			}
+			return b.String(), nil
+		},
+	}
+}
+
+// IssueTool returns a tool that looks up a GitHub issue
+// and its comments in gh's database.
+func IssueTool(gh *github.Client) *llmapp.Tool {
+	return &llmapp.Tool{
+		Name:        "issue",
+		Description: "Fetch a GitHub issue or pull request and its comments.",
+		Params:      map[string]string{"url": "the issue URL, for example https://github.com/golang/go/issues/12345"},
+		Run: func(_ context.Context, args map[string]string) (string, error) {
+			iss, err := gh.LookupIssueURL(args["url"])
+			if err != nil {
+				return "", err
+			}
+			docs := []*llmapp.Doc{iss.ToLLMDoc()}
+			for c := range gh.Comments(iss) {
+				docs = append(docs, c.ToLLMDoc())
+			}
+			return string(storage.JSON(docs)), nil
+		},
+	}
+}
+
+// ChangeTool returns a tool that looks up a Gerrit change in gr's database.
+func ChangeTool(gr *gerrit.Client) *llmapp.Tool {
+	return &llmapp.Tool{
+		Name:        "change",
+		Description: "Fetch a Gerrit change (CL): its subject, status, description and review messages.",
+		Params: map[string]string{
+			"project": "the Gerrit project, for example go",
+			"number":  "the change number",
+		},
+		Run: func(_ context.Context, args map[string]string) (string, error) {
+			n, err := strconv.Atoi(args["number"])
+			if err != nil {
+				return "", fmt.Errorf("invalid change number %q", args["number"])
+			}
+			ch := gr.Change(args["project"], n)
+			if ch == nil {
+				return "", fmt.Errorf("change %s/%d not found", args["project"], n)
+			}
+			type message struct {
+				Author  string
+				Message string
+			}
+			var msgs []message
+			for _, m := range gr.ChangeMessages(ch) {
+				var author string
+				if m.Author != nil {
+					author = m.Author.Email
+				}
+				msgs = append(msgs, message{author, m.Message})
+			}
+			return string(storage.JSON(struct {
+				Subject     string
+				Status      string
+				Description string
+				Messages    []message
+			}{
+				gr.ChangeSubject(ch),
+				gr.ChangeStatus(ch),
+				gr.ChangeDescription(ch),
+				msgs,
+			})), nil
+		},
+	}
+}
+
+// ReproTool returns a tool that runs a test case in ct's sandbox
+// at a given version and reports whether it passed.
+func ReproTool(ct repro.CaseTester) *llmapp.Tool {
+	return &llmapp.Tool{
+		Name:        "repro",
+		Description: "Run a test case (a program that fails if a bug is present) at a version of the project, in a sandbox. Reports whether the test case passed or failed.",
+		Params: map[string]string{
+			"program": "the test case",
+			"version": "the version to run it at, for example go1.23 or a commit hash",
+		},
+		Run: func(ctx context.Context, args map[string]string) (string, error) {
+			body, err := ct.Clean(ctx, args["program"])
+			if err != nil {
+				return "", fmt.Errorf("cannot run test case: %w", err)
+			}
+			passed, err := ct.Try(ctx, body, args["version"])
+			if err != nil {
+				return "", err
+			}
+			if passed {
+				return fmt.Sprintf("The test case passed at version %s.", args["version"]), nil
+			}
+			return fmt.Sprintf("The test case failed at version %s.", args["version"]), nil
+		},
+	}
+}
+
+// Issue asks the agent to investigate the GitHub issue:
+// to find related issues and changes, try to reproduce it,
+// and suggest a likely cause.
+func Issue(ctx context.Context, a *llmapp.Agent, iss *github.Issue) (*llmapp.AgentResult, error) {
+	task := "Investigate the bug report in the following GitHub issue. " +
+		"Find any earlier issues or changes that it duplicates or is related to, " +
+		"try to reproduce it if it includes a test case, and suggest its likely cause. " +
+		"Cite the URLs of the documents your answer relies on.\n\n" +
+		string(storage.JSON(iss.ToLLMDoc()))
+	return a.Run(ctx, task)
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package investigate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/gerrit"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestIssue(t *testing.T) {
+	ctx := context.Background()
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+
+	gh := github.New(lg, db, nil, nil)
+	check(gh.Testing().LoadTxtar("../testdata/markdown.txt"))
+	gr := gerrit.New("investigate-test", lg, db, secret.Empty(), nil)
+	check(gr.Testing().LoadTxtar("testdata/change.txt"))
+	dc := docs.New(lg, db)
+	docs.Sync(dc, gh)
+	vdb := storage.MemVectorDB(db, lg, "vecs")
+	embeddocs.Sync(ctx, lg, vdb, llm.QuoteEmbedder(), dc)
+
+	// The LLM looks at each tool in turn, then answers.
+	responses := []string{
+		`{"thought":"search","tool":"search","args":[{"name":"query","value":"Support Github Emojis"}]}`,
+		`{"thought":"issue","tool":"issue","args":[{"name":"url","value":"https://github.com/rsc/markdown/issues/1"}]}`,
+		`{"thought":"change","tool":"change","args":[{"name":"project","value":"test"},{"name":"number","value":"1"}]}`,
+		`{"thought":"repro","tool":"repro","args":[{"name":"program","value":"package main"},{"name":"version","value":"go1.23"}]}`,
+		`{"thought":"bad repro","tool":"repro","args":[{"name":"program","value":""}]}`,
+		`{"thought":"done","tool":"","answer":"It is a feature request."}`,
+	}
+	g := llm.TestContentGenerator("investigate-test", func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+		r := responses[0]
+		responses = responses[1:]
+		return r, nil
+	})
+	lc := llmapp.New(lg, g, db)
+	a := lc.NewAgent(
+		SearchTool(vdb, dc, llm.QuoteEmbedder()),
+		IssueTool(gh),
+		ChangeTool(gr),
+		ReproTool(testTester{}),
+	)
+	iss, err := github.LookupIssue(db, "rsc/markdown", 1)
+	check(err)
+	res, err := Issue(ctx, a, iss)
+	check(err)
+
+	if !strings.Contains(res.Task, "Support Github Emojis") {
+		t.Errorf("task does not include the issue:\n%s", res.Task)
+	}
+	if res.Answer != "It is a feature request." {
+		t.Errorf("Answer = %q", res.Answer)
+	}
+	for i, want := range []string{
+		"https://github.com/rsc/markdown/issues/17 GitHubIssue \"Pretty-print tables in Markdown\" (score 0.54)",
+		`"title":"Support Github Emojis"`,
+		`"Subject":"my new change"`,
+		"The test case failed at version go1.23.",
+		"",
+	} {
+		if s := res.Steps[i]; s.Error != "" && want != "" || !strings.Contains(s.Output, want) {
+			t.Errorf("step %d (%s): output %q, error %q; want output containing %q", i+1, s.Tool, s.Output, s.Error, want)
+		}
+	}
+	if s := res.Steps[4]; s.Error == "" {
+		t.Errorf("repro of empty program: no error")
+	}
+}
+
+// testTester is a [repro.CaseTester] for which every test case fails,
+// and empty test cases are incomprehensible.
+type testTester struct{}
+
+func (testTester) Clean(_ context.Context, body string) (string, error) {
+	if body == "" {
+		return "", errors.New("empty test case")
+	}
+	return body, nil
+}
+
+func (testTester) CleanVersions(_ context.Context, pass, fail string) (string, string) {
+	return pass, fail
+}
+
+func (testTester) Try(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+func (testTester) Bisect(context.Context, *github.Issue, string, string, string) (string, error) {
+	return "", errors.New("not implemented")
+}
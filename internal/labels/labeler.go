@@ -12,9 +12,11 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/compose"
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/llm"
 	"golang.org/x/oscar/internal/storage"
@@ -37,6 +39,12 @@ type Labeler struct {
 	timeLimit   time.Time
 	skipAuthors map[string]bool
 	label       bool
+	suggest     bool
+	thresholds  map[string]float64 // per-label overrides of suggestion thresholds
+	composer    *compose.Composer  // if non-nil, note added labels in it; see SetComposer
+
+	mu            sync.Mutex
+	trackerLabels map[string]map[string]github.Label // project -> lowercase label name -> label
 	// For the action log.
 	requireApproval bool
 	actionKind      string
@@ -98,6 +106,37 @@ func (l *Labeler) RequireApproval() {
 	l.requireApproval = true
 }
 
+// SetComposer configures the Labeler to note the labels it adds to an
+// issue in c, which combines comments from several bots on the same
+// issue. The note is only posted as part of another bot's comment
+// (see [compose.Composer.AddNote]), so labeling alone never
+// adds a comment.
+func (l *Labeler) SetComposer(c *compose.Composer) {
+	l.composer = c
+}
+
+// EnableSuggestions configures the Labeler to also suggest labels
+// from the project's label taxonomy (see [SuggestLabels]), in addition
+// to the label for the issue's category.
+// Suggested labels whose confidence meets their threshold are added
+// to the same action as the category label.
+// The labels and their default thresholds are configured in
+// static/*-suggestions.yaml; see also [Labeler.SetLabelThreshold].
+func (l *Labeler) EnableSuggestions() {
+	l.suggest = true
+}
+
+// SetLabelThreshold sets the minimum confidence, between 0 and 1, that the
+// LLM must report for the Labeler to suggest the given label, overriding
+// the threshold in the project's configuration.
+// A threshold greater than 1 prevents the label from being suggested.
+func (l *Labeler) SetLabelThreshold(label string, min float64) {
+	if l.thresholds == nil {
+		l.thresholds = map[string]float64{}
+	}
+	l.thresholds[strings.ToLower(label)] = min
+}
+
 func (l *Labeler) SkipAuthor(author string) {
 	if l.skipAuthors == nil {
 		l.skipAuthors = map[string]bool{}
@@ -108,9 +147,10 @@ func (l *Labeler) SkipAuthor(author string) {
 // An action has all the information needed to label a GitHub issue.
 type action struct {
 	Issue        *github.Issue
-	Categories   []string // the names of the categories corresponding to the labels
-	NewLabels    []string // labels to add
-	Explanations []string // an explanation for each category
+	Categories   []string     // the names of the categories corresponding to the labels
+	NewLabels    []string     // labels to add
+	Explanations []string     // an explanation for each category
+	Suggestions  []Suggestion // labels suggested from the taxonomy, also in NewLabels
 }
 
 // result is the result of apply an action.
@@ -226,21 +266,107 @@ func (l *Labeler) logLabelIssue(ctx context.Context, e *github.Event) (advance b
 	l.slog.Info("labels.Labeler chose label", "name", l.name, "project", e.Project, "issue", e.Issue,
 		"label", cat.Label, "explanation", explanation)
 
-	if !l.label {
-		// Labeling is disabled so we did not handle this issue.
-		return false, nil
-	}
-
 	act := &action{
 		Issue:        issue,
 		Categories:   []string{cat.Name},
 		NewLabels:    []string{cat.Label},
 		Explanations: []string{explanation},
 	}
+	if l.suggest {
+		// Suggestions are extra; without them, still apply the category label.
+		sugs, err := l.suggestLabels(ctx, issue)
+		if err != nil {
+			l.slog.Error("labels.Labeler suggest labels failed", "name", l.name, "project", e.Project, "issue", e.Issue, "err", err)
+		}
+		for _, s := range sugs {
+			if !slices.ContainsFunc(act.NewLabels, func(name string) bool { return strings.EqualFold(name, s.Label) }) {
+				act.NewLabels = append(act.NewLabels, s.Label)
+				act.Suggestions = append(act.Suggestions, s)
+			}
+		}
+	}
+	if len(act.Suggestions) > 0 {
+		l.slog.Info("labels.Labeler suggested labels", "name", l.name, "project", e.Project, "issue", e.Issue,
+			"suggestions", act.Suggestions)
+	}
+
+	if !l.label {
+		// Labeling is disabled so we did not handle this issue.
+		return false, nil
+	}
 	l.logAction(l.db, logKey(e), storage.JSON(act), l.requireApproval)
 	return true, nil
 }
 
+// suggestLabels returns the labels from the issue's project's taxonomy
+// that the LLM suggests for the issue, omitting any already on the issue.
+func (l *Labeler) suggestLabels(ctx context.Context, issue *github.Issue) ([]Suggestion, error) {
+	project := issue.Project()
+	tlabs, err := l.projectLabels(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	var labs []LabelConfig
+	for _, lc := range config.suggestions[project] {
+		// Only suggest labels that exist on the issue tracker.
+		tlab, ok := tlabs[strings.ToLower(lc.Label)]
+		if !ok {
+			continue
+		}
+		lc.Label = tlab.Name
+		lc.Description = tlab.Description
+		if min, ok := l.thresholds[strings.ToLower(lc.Label)]; ok {
+			lc.Threshold = min
+		}
+		if lc.threshold() > 1 {
+			continue
+		}
+		labs = append(labs, lc)
+	}
+	sugs, err := SuggestLabels(ctx, l.cgen, issue, labs)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(sugs, func(s Suggestion) bool {
+		return slices.ContainsFunc(issue.Labels, func(lab github.Label) bool { return strings.EqualFold(lab.Name, s.Label) })
+	}), nil
+}
+
+// projectLabels returns the labels on the issue tracker for the project,
+// keyed by lowercase name.
+// It uses the labels fetched by the last call to [Labeler.syncLabels],
+// or fetches them if there are none.
+func (l *Labeler) projectLabels(ctx context.Context, project string) (map[string]github.Label, error) {
+	l.mu.Lock()
+	tlabs, ok := l.trackerLabels[project]
+	l.mu.Unlock()
+	if ok {
+		return tlabs, nil
+	}
+	tlabList, err := l.github.ListLabels(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return l.setTrackerLabels(project, tlabList), nil
+}
+
+// setTrackerLabels records the labels on the issue tracker for the project,
+// and returns them keyed by lowercase name.
+func (l *Labeler) setTrackerLabels(project string, tlabList []github.Label) map[string]github.Label {
+	// Labels are case-insensitive, so the keys are lowercase.
+	tlabs := map[string]github.Label{}
+	for _, lab := range tlabList {
+		tlabs[strings.ToLower(lab.Name)] = lab
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.trackerLabels == nil {
+		l.trackerLabels = map[string]map[string]github.Label{}
+	}
+	l.trackerLabels[project] = tlabs
+	return tlabs
+}
+
 func (l *Labeler) skip(e *github.Event) (bool, string) {
 	if !l.projects[e.Project] {
 		return true, fmt.Sprintf("project %s not enabled for this Labeler", e.Project)
@@ -281,11 +407,7 @@ func (l *Labeler) syncLabels(ctx context.Context, project string, cats []Categor
 	if err != nil {
 		return err
 	}
-	// Labels are case-insensitive, so the keys are lowercase.
-	tlabs := map[string]github.Label{}
-	for _, lab := range tlabList {
-		tlabs[strings.ToLower(lab.Name)] = lab
-	}
+	tlabs := l.setTrackerLabels(project, tlabList)
 
 	for _, cat := range cats {
 		lab, ok := tlabs[strings.ToLower(cat.Label)]
@@ -326,7 +448,11 @@ func (ar *actioner) ForDisplay(data []byte) string {
 	if err := json.Unmarshal(data, &a); err != nil {
 		return fmt.Sprintf("ERROR: %v", err)
 	}
-	return a.Issue.HTMLURL + "\n" + strings.Join(a.NewLabels, ", ") + "\n" + strings.Join(a.Explanations, ", ")
+	s := a.Issue.HTMLURL + "\n" + strings.Join(a.NewLabels, ", ") + "\n" + strings.Join(a.Explanations, ", ")
+	for _, sug := range a.Suggestions {
+		s += fmt.Sprintf("\n%s (confidence %.2f): %s", sug.Label, sug.Confidence, sug.Explanation)
+	}
+	return s
 }
 
 // runFromActionLog is called by actions.Run to execute an action.
@@ -381,6 +507,15 @@ func (l *Labeler) runAction(ctx context.Context, a *action) (*result, error) {
 		return nil, fmt.Errorf("Labeler: edit %s: %w", a.Issue.URL, err)
 	}
 	l.setCategories(a.Issue, a.Categories)
+	if l.composer != nil {
+		var added []string
+		for _, name := range a.NewLabels {
+			if !oldLabels[name] {
+				added = append(added, name)
+			}
+		}
+		l.composer.AddNote(a.Issue, "labels", "Labels added: "+strings.Join(added, ", ")+".")
+	}
 	return &result{URL: issue.URL}, nil
 }
 
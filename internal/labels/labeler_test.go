@@ -15,6 +15,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/compose"
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/llm"
 	"golang.org/x/oscar/internal/storage"
@@ -104,6 +105,8 @@ func TestRun(t *testing.T) {
 	l := New(lg, db, gh, cgen, "test")
 	l.EnableProject(project)
 	l.EnableLabels()
+	c := compose.New(lg, db, gh, "test")
+	l.SetComposer(c)
 
 	check(l.Run(ctx))
 	entries := slices.Collect(actions.ScanAfterDBTime(lg, db, 0, nil))
@@ -151,6 +154,12 @@ func TestRun(t *testing.T) {
 	if wi != len(wantEdits) {
 		t.Fatal("not enough edits")
 	}
+
+	// The added labels are noted for a combined comment.
+	wantParts := []*compose.Part{{Source: "labels", Body: "Labels added: BugReport.", Note: true}}
+	if gotParts := c.Pending(project, 1); !reflect.DeepEqual(gotParts, wantParts) {
+		t.Errorf("pending parts = %v, want %v", gotParts, wantParts)
+	}
 }
 
 func TestCategories(t *testing.T) {
@@ -6,13 +6,14 @@
 //
 // The categories it uses are stored in static/*-categories.yaml
 // files, one file per project.
+// The additional labels it can suggest for issues (see [SuggestLabels])
+// are stored in static/*-suggestions.yaml files.
 package labels
 
 import (
 	"bytes"
 	"context"
 	"embed"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
@@ -83,19 +84,15 @@ func IssueCategoryFromLists(ctx context.Context, cgen llm.ContentGenerator, iss
 		return Category{}, "", err
 	}
 	// Ask the LLM about the category of the issue.
-	jsonRes, err := cgen.GenerateContent(ctx, responseSchema, []llm.Part{llm.Text(prompt)})
-	if err != nil {
-		return Category{}, "", fmt.Errorf("llm request failed: %w\n", err)
-	}
 	var res response
-	if err := json.Unmarshal([]byte(jsonRes), &res); err != nil {
-		return Category{}, "", fmt.Errorf("unmarshaling %s: %w", jsonRes, err)
+	if err := llm.GenerateJSON(ctx, cgen, responseSchema, &res, []llm.Part{llm.Text(prompt)}); err != nil {
+		return Category{}, "", fmt.Errorf("llm request failed: %w\n", err)
 	}
 	cat, ok := lookupCategory(res.CategoryName, cats)
 	if ok {
 		return cat, res.Explanation, nil
 	}
-	return Category{}, "", fmt.Errorf("no category matches LLM response %q", jsonRes)
+	return Category{}, "", fmt.Errorf("no category matches LLM response %q", res.CategoryName)
 }
 
 func buildPrompt(title, body string, cats []Category, exs []Example) (string, error) {
@@ -123,6 +120,7 @@ type promptArgs struct {
 	Body       string
 	Categories []Category
 	Examples   []Example
+	Labels     []LabelConfig // for SuggestLabels
 }
 
 const promptTemplate = `
@@ -291,12 +289,14 @@ var responseSchema = &llm.Schema{
 			Description: "an explanation of why the issue belongs to the category",
 		},
 	},
+	Required: []string{"CategoryName", "Explanation"},
 }
 
 var config struct {
 	// Key is project, e.g. "golang/go".
 	categories   map[string][]Category
 	exampleSpecs map[string][]exampleSpec
+	suggestions  map[string][]LabelConfig
 	mu           sync.Mutex           // protects examples
 	examples     map[string][]Example // from exampleSpecs, with data retrieved from the DB
 }
@@ -382,6 +382,35 @@ func init() {
 
 	config.examples = map[string][]Example{}
 	// Populated by expandExampleSpecs.
+
+	type suggestionContents struct {
+		Project string
+		Labels  []struct {
+			Label     string
+			Threshold float64
+			Extra     string
+		}
+	}
+	scontents, err := readAllYAMLFiles[suggestionContents]("static/*-suggestions.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.suggestions = map[string][]LabelConfig{}
+	for _, sc := range scontents {
+		if sc.Project == "" {
+			log.Fatalf("empty or missing project")
+		}
+		if _, ok := config.suggestions[sc.Project]; ok {
+			log.Fatalf("duplicate project %s", sc.Project)
+		}
+		for _, l := range sc.Labels {
+			if l.Threshold < 0 || l.Threshold > 1 {
+				log.Fatalf("%s: label %s: threshold %g not in [0, 1]", sc.Project, l.Label, l.Threshold)
+			}
+			config.suggestions[sc.Project] = append(config.suggestions[sc.Project],
+				LabelConfig{Label: l.Label, Threshold: l.Threshold, Extra: l.Extra})
+		}
+	}
 }
 
 func readAllYAMLFiles[T any](glob string) ([]T, error) {
@@ -0,0 +1,162 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package labels
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+)
+
+// A LabelConfig describes an issue tracker label that can be
+// suggested for issues by [SuggestLabels].
+//
+// The labels that can be suggested for each project are stored
+// in static/*-suggestions.yaml files.
+type LabelConfig struct {
+	Label       string  // issue tracker label
+	Description string  // label description, from the issue tracker
+	Threshold   float64 // minimum confidence to suggest the label; 0 means 0.8
+	Extra       string  // additional description, not in issue tracker
+}
+
+const defaultThreshold = 0.8
+
+// threshold returns the minimum confidence for suggesting the label.
+func (lc *LabelConfig) threshold() float64 {
+	if lc.Threshold == 0 {
+		return defaultThreshold
+	}
+	return lc.Threshold
+}
+
+// A Suggestion is a label suggested for an issue by [SuggestLabels].
+type Suggestion struct {
+	Label       string
+	Confidence  float64 // between 0 and 1
+	Explanation string
+}
+
+// SuggestLabels asks the LLM which of the labels apply to the issue, and how
+// confident it is about each one.
+// It returns the suggestions whose confidence meets the label's threshold,
+// most confident first.
+// It ignores any labels in the LLM's response that are not in labs.
+func SuggestLabels(ctx context.Context, cgen llm.ContentGenerator, iss *github.Issue, labs []LabelConfig) ([]Suggestion, error) {
+	if iss.PullRequest != nil {
+		return nil, errors.New("issue is a pull request")
+	}
+	if len(labs) == 0 {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	err := suggestPromptTmpl.Execute(&buf, promptArgs{
+		Title:  iss.Title,
+		Body:   cleanIssueBody(github.ParseMarkdown(iss.Body)),
+		Labels: labs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res suggestResponse
+	if err := llm.GenerateJSON(ctx, cgen, suggestSchema, &res, []llm.Part{llm.Text(buf.String())}); err != nil {
+		return nil, fmt.Errorf("llm request failed: %w\n", err)
+	}
+	var sugs []Suggestion
+	seen := map[string]bool{}
+	for _, s := range res.Labels {
+		i := slices.IndexFunc(labs, func(lc LabelConfig) bool {
+			return strings.EqualFold(lc.Label, s.Label)
+		})
+		if i < 0 || seen[labs[i].Label] || s.Confidence < labs[i].threshold() {
+			continue
+		}
+		seen[labs[i].Label] = true
+		sugs = append(sugs, Suggestion{Label: labs[i].Label, Confidence: s.Confidence, Explanation: s.Explanation})
+	}
+	slices.SortStableFunc(sugs, func(a, b Suggestion) int {
+		switch {
+		case a.Confidence > b.Confidence:
+			return -1
+		case a.Confidence < b.Confidence:
+			return +1
+		}
+		return 0
+	})
+	return sugs, nil
+}
+
+const suggestPromptTemplate = `
+Your job is to choose labels for Go issues.
+The issue is described by a title and a body.
+The issue body is encoded in markdown.
+Report each label that applies to the issue, how confident you are
+that it applies (a number between 0 and 1), and an explanation of your decision.
+Report only labels from the list below; it is fine to report none.
+Each label and its description are listed below.
+{{range .Labels}}
+{{.Label}}: {{.Description}}
+{{.Extra}}
+{{end}}
+Here is the issue you should label:
+The title of the issue is: {{.Title}}
+The body of the issue is: {{.Body}}
+`
+
+var suggestPromptTmpl = template.Must(template.New("suggest").Parse(suggestPromptTemplate))
+
+// suggestResponse is the response that should generated by the LLM
+// for [SuggestLabels].
+// It must match [suggestSchema].
+type suggestResponse struct {
+	Labels []struct {
+		Label       string
+		Confidence  float64
+		Explanation string
+	}
+}
+
+var suggestSchema = &llm.Schema{
+	Type: llm.TypeObject,
+	Properties: map[string]*llm.Schema{
+		"Labels": {
+			Type: llm.TypeArray,
+			Items: &llm.Schema{
+				Type: llm.TypeObject,
+				Properties: map[string]*llm.Schema{
+					"Label": {
+						Type:        llm.TypeString,
+						Description: "the name of the label",
+					},
+					"Confidence": {
+						Type:        llm.TypeNumber,
+						Description: "how confident you are that the label applies, between 0 and 1",
+					},
+					"Explanation": {
+						Type:        llm.TypeString,
+						Description: "an explanation of why the label applies",
+					},
+				},
+				Required: []string{"Label", "Confidence", "Explanation"},
+			},
+		},
+	},
+	Required: []string{"Labels"},
+}
+
+// SuggestionLabelsForProject returns the labels that can be suggested
+// for the given project, or nil if there are none.
+// The descriptions of the returned labels are empty; see [Labeler] for
+// how they are filled in from the issue tracker.
+func SuggestionLabelsForProject(project string) []LabelConfig {
+	return config.suggestions[project]
+}
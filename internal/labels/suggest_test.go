@@ -0,0 +1,160 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package labels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestSuggestLabels(t *testing.T) {
+	ctx := context.Background()
+	var prompt string
+	cgen := llm.TestContentGenerator("test", func(_ context.Context, _ *llm.Schema, parts []llm.Part) (string, error) {
+		prompt = string(parts[0].(llm.Text))
+		return `{"Labels": [
+			{"Label": "a", "Confidence": 0.85, "Explanation": "a exp"},
+			{"Label": "B", "Confidence": 0.7, "Explanation": "b exp"},
+			{"Label": "C", "Confidence": 0.95, "Explanation": "c exp"},
+			{"Label": "unknown", "Confidence": 1, "Explanation": "?"}
+		]}`, nil
+	})
+	labs := []LabelConfig{
+		{Label: "A", Description: "desc A"},                 // default threshold 0.8
+		{Label: "B", Description: "desc B", Threshold: 0.6}, // low threshold
+		{Label: "C", Description: "desc C", Threshold: 0.99, Extra: "extra C"},
+	}
+	iss := &github.Issue{Title: "title", Body: "body"}
+	got, err := SuggestLabels(ctx, cgen, iss, labs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Suggestion{
+		{Label: "A", Confidence: 0.85, Explanation: "a exp"},
+		{Label: "B", Confidence: 0.7, Explanation: "b exp"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SuggestLabels mismatch (-want, +got):\n%s", diff)
+	}
+	for _, s := range []string{"A: desc A", "C: desc C", "extra C", "The title of the issue is: title"} {
+		if !strings.Contains(prompt, s) {
+			t.Errorf("prompt does not contain %q:\n%s", s, prompt)
+		}
+	}
+
+	if got, err := SuggestLabels(ctx, cgen, iss, nil); err != nil || got != nil {
+		t.Errorf("SuggestLabels(no labels) = %v, %v, want nil, nil", got, err)
+	}
+	if _, err := SuggestLabels(ctx, cgen, &github.Issue{PullRequest: new(struct{})}, labs); err == nil {
+		t.Error("SuggestLabels(pull request) succeeded, want error")
+	}
+}
+
+func TestRunSuggestions(t *testing.T) {
+	const project = "golang/go"
+	ctx := context.Background()
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+
+	for _, lab := range []string{"BugReport", "compiler/runtime", "NeedsInvestigation", "GoCommand"} {
+		gh.Testing().AddLabel(project, github.Label{Name: lab, Description: lab + " description"})
+	}
+	gh.Testing().AddIssue(project, &github.Issue{
+		Number:    1,
+		Title:     "runtime: crash",
+		Body:      "body",
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Labels:    []github.Label{{Name: "GoCommand"}},
+	})
+	cgen := llm.TestContentGenerator("test", func(_ context.Context, schema *llm.Schema, _ []llm.Part) (string, error) {
+		if schema == suggestSchema {
+			return `{"Labels": [
+				{"Label": "compiler/runtime", "Confidence": 0.9, "Explanation": "runtime crash"},
+				{"Label": "NeedsInvestigation", "Confidence": 0.95, "Explanation": "needs a look"},
+				{"Label": "GoCommand", "Confidence": 0.9, "Explanation": "already present"},
+				{"Label": "BugReport", "Confidence": 0.9, "Explanation": "not in taxonomy"}
+			]}`, nil
+		}
+		return `{"CategoryName": "bug", "Explanation": "exp"}`, nil
+	})
+	l := New(lg, db, gh, cgen, "test")
+	l.EnableProject(project)
+	l.EnableLabels()
+	l.EnableSuggestions()
+	l.SetLabelThreshold("NeedsInvestigation", 0.99)
+
+	check(l.Run(ctx))
+	entries := slices.Collect(actions.ScanAfterDBTime(lg, db, 0, nil))
+	if g := len(entries); g != 1 {
+		t.Fatalf("got %d actions, want 1", g)
+	}
+	var got action
+	check(json.Unmarshal(entries[0].Action, &got))
+	if want := []string{"BugReport", "compiler/runtime"}; !slices.Equal(got.NewLabels, want) {
+		t.Errorf("NewLabels = %v, want %v", got.NewLabels, want)
+	}
+	wantSugs := []Suggestion{{Label: "compiler/runtime", Confidence: 0.9, Explanation: "runtime crash"}}
+	if diff := cmp.Diff(wantSugs, got.Suggestions); diff != "" {
+		t.Errorf("Suggestions mismatch (-want, +got):\n%s", diff)
+	}
+	if !slices.Equal(got.Categories, []string{"bug"}) {
+		t.Errorf("Categories = %v, want [bug]", got.Categories)
+	}
+}
+
+func TestRunSuggestionsFail(t *testing.T) {
+	const project = "golang/go"
+	ctx := context.Background()
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+
+	for _, lab := range []string{"BugReport", "compiler/runtime"} {
+		gh.Testing().AddLabel(project, github.Label{Name: lab, Description: lab + " description"})
+	}
+	gh.Testing().AddIssue(project, &github.Issue{
+		Number:    1,
+		Title:     "runtime: crash",
+		Body:      "body",
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+	cgen := llm.TestContentGenerator("test", func(_ context.Context, schema *llm.Schema, _ []llm.Part) (string, error) {
+		if schema == suggestSchema {
+			return "", errors.New("suggestions unavailable")
+		}
+		return `{"CategoryName": "bug", "Explanation": "exp"}`, nil
+	})
+	l := New(lg, db, gh, cgen, "test")
+	l.EnableProject(project)
+	l.EnableLabels()
+	l.EnableSuggestions()
+
+	// The failure is logged, and the category label is still applied.
+	check(l.Run(ctx))
+	entries := slices.Collect(actions.ScanAfterDBTime(lg, db, 0, nil))
+	if g := len(entries); g != 1 {
+		t.Fatalf("got %d actions, want 1", g)
+	}
+	var got action
+	check(json.Unmarshal(entries[0].Action, &got))
+	if want := []string{"BugReport"}; !slices.Equal(got.NewLabels, want) || len(got.Suggestions) != 0 {
+		t.Errorf("NewLabels = %v, Suggestions = %v, want %v and none", got.NewLabels, got.Suggestions, want)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package leader elects a leader among processes sharing a [storage.DB],
+// so that a primary process runs the scheduled jobs and a standby
+// process takes them over if the primary dies.
+//
+// The leader holds a [Lease] stored in the database, which it renews
+// periodically (see [Elector.Run]). A lease that is not renewed
+// expires after its TTL, and the next [Elector.Campaign] by another
+// process takes it over. The lease records the job the leader was
+// running, so that the new leader can report what was interrupted.
+// The jobs themselves keep their progress in the database
+// (in watchers and the action log), so the new leader resumes
+// where the old one stopped.
+//
+// To avoid double-posting, a process considers itself the leader
+// only until a safety margin before its lease expires, and callers
+// should confirm leadership with [Elector.Verify] immediately before
+// making external changes: a process that stalled and lost its lease
+// then stops instead of acting alongside the new leader.
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// Default timing of an [Elector].
+// With these values, a standby takes over within 30 seconds
+// of the leader's last renewal.
+const (
+	DefaultTTL   = 30 * time.Second
+	DefaultRenew = 10 * time.Second
+)
+
+// A Lease is the record of a leader, stored in the database.
+type Lease struct {
+	Holder   string    // ID of the leading process
+	Term     int64     // incremented each time the lease changes hands
+	Acquired time.Time // when Holder acquired the lease
+	Expires  time.Time // when the lease expires unless renewed
+	Job      string    // job the leader is running ("" if none)
+	Previous string    // ID of the previous holder ("" if none)
+}
+
+// An Elector takes part in the election of a leader
+// among the processes sharing a database.
+// It is safe for concurrent use.
+type Elector struct {
+	slog *slog.Logger
+	db   storage.DB
+	name string
+	id   string
+	ttl  time.Duration
+	now  func() time.Time // for testing
+
+	mu    sync.Mutex
+	lease Lease // last lease seen
+	job   string
+}
+
+// New returns a new Elector for the election with the given name,
+// in which the calling process takes part under the given ID,
+// which must be unique among the processes sharing db.
+// The Elector does not campaign until [Elector.Campaign]
+// or [Elector.Run] is called.
+func New(lg *slog.Logger, db storage.DB, name, id string) *Elector {
+	return &Elector{
+		slog: lg,
+		db:   db,
+		name: name,
+		id:   id,
+		ttl:  DefaultTTL,
+		now:  time.Now,
+	}
+}
+
+// SetTTL sets the time after which a lease that is not renewed expires.
+// It should be several times the renewal interval passed to [Elector.Run].
+func (e *Elector) SetTTL(ttl time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ttl = ttl
+}
+
+// ID returns the ID of the calling process in the election.
+func (e *Elector) ID() string {
+	return e.id
+}
+
+func (e *Elector) key() []byte {
+	return ordered.Encode("leader.Lease", e.name)
+}
+
+// lockName is the database lock held while reading and writing the lease.
+func (e *Elector) lockName() string {
+	return "leader." + e.name
+}
+
+// load returns the lease stored in the database, if any.
+// The caller must hold the lease lock.
+func (e *Elector) load() (Lease, bool) {
+	data, ok := e.db.Get(e.key())
+	if !ok {
+		return Lease{}, false
+	}
+	var l Lease
+	if err := json.Unmarshal(data, &l); err != nil {
+		// unreachable except bad DB
+		e.db.Panic("leader: decode lease", "name", e.name, "err", err)
+	}
+	return l, true
+}
+
+// Campaign tries to acquire the lease, or renews it if the calling
+// process already holds it, and reports whether the process is now the leader.
+// A process acquires the lease only if no lease is recorded
+// or the recorded lease has expired.
+func (e *Elector) Campaign() bool {
+	e.db.Lock(e.lockName())
+	defer e.db.Unlock(e.lockName())
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	l, ok := e.load()
+	switch {
+	case ok && l.Holder == e.id:
+		// Renew.
+		l.Expires = now.Add(e.ttl)
+		l.Job = e.job
+	case !ok || !now.Before(l.Expires):
+		// Take over.
+		if ok {
+			e.slog.Warn("leader: taking over expired lease",
+				"name", e.name, "id", e.id, "previous", l.Holder, "expired", l.Expires, "interrupted", l.Job)
+		} else {
+			e.slog.Info("leader: acquiring lease", "name", e.name, "id", e.id)
+		}
+		l = Lease{
+			Holder:   e.id,
+			Term:     l.Term + 1,
+			Acquired: now,
+			Expires:  now.Add(e.ttl),
+			Job:      e.job,
+			Previous: l.Holder,
+		}
+	default:
+		// Someone else leads.
+		if e.lease.Holder == e.id {
+			e.slog.Warn("leader: lost lease", "name", e.name, "id", e.id, "holder", l.Holder)
+		}
+		e.lease = l
+		return false
+	}
+	e.db.Set(e.key(), storage.JSON(l))
+	e.db.Flush()
+	e.lease = l
+	return true
+}
+
+// margin returns the safety margin before the end of a lease
+// during which its holder no longer considers itself the leader,
+// to allow for clock skew and slow database writes.
+func (e *Elector) margin() time.Duration {
+	return e.ttl / 5
+}
+
+// IsLeader reports whether the calling process holds the lease,
+// according to the last [Elector.Campaign].
+// It does not access the database.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lease.Holder == e.id && e.now().Before(e.lease.Expires.Add(-e.margin()))
+}
+
+// Verify reports whether the calling process still holds the lease,
+// according to the database.
+// Callers should call Verify immediately before making changes
+// that must not be made by two processes.
+func (e *Elector) Verify() bool {
+	if !e.IsLeader() {
+		return false
+	}
+	e.db.Lock(e.lockName())
+	defer e.db.Unlock(e.lockName())
+	l, ok := e.load()
+	return ok && l.Holder == e.id && e.now().Before(l.Expires.Add(-e.margin()))
+}
+
+// Lease returns the last lease seen by the Elector,
+// and whether there is one.
+func (e *Elector) Lease() (Lease, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lease, e.lease.Holder != ""
+}
+
+// SetJob records the job that the calling process is running
+// ("" for none), so that if the process dies, the process that takes
+// over the lease can report the interrupted job.
+// The job is stored with the lease at the next [Elector.Campaign].
+func (e *Elector) SetJob(job string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.job = job
+}
+
+// Resign gives up the lease, if the calling process holds it,
+// so that another process can take it over without waiting
+// for it to expire.
+func (e *Elector) Resign() {
+	e.db.Lock(e.lockName())
+	defer e.db.Unlock(e.lockName())
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	l, ok := e.load()
+	if !ok || l.Holder != e.id {
+		return
+	}
+	l.Expires = e.now()
+	e.db.Set(e.key(), storage.JSON(l))
+	e.db.Flush()
+	e.lease = l
+	e.slog.Info("leader: resigned", "name", e.name, "id", e.id)
+}
+
+// Run campaigns every renew interval until ctx is done,
+// and then resigns.
+func (e *Elector) Run(ctx context.Context, renew time.Duration) {
+	t := time.NewTicker(renew)
+	defer t.Stop()
+	for {
+		e.Campaign()
+		select {
+		case <-ctx.Done():
+			e.Resign()
+			return
+		case <-t.C:
+		}
+	}
+}
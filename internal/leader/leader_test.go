@@ -0,0 +1,129 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestElection(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	primary := New(lg, db, "gaby", "primary")
+	primary.now = clock
+	standby := New(lg, db, "gaby", "standby")
+	standby.now = clock
+
+	if !primary.Campaign() || !primary.IsLeader() || !primary.Verify() {
+		t.Fatal("primary did not become leader")
+	}
+	if standby.Campaign() || standby.IsLeader() || standby.Verify() {
+		t.Fatal("standby became leader while primary holds the lease")
+	}
+
+	// Renewals keep the lease with the primary.
+	primary.SetJob("cron")
+	for range 5 {
+		now = now.Add(DefaultRenew)
+		if !primary.Campaign() {
+			t.Fatal("primary lost the lease while renewing")
+		}
+		if standby.Campaign() {
+			t.Fatal("standby took a renewed lease")
+		}
+	}
+
+	// The primary dies mid-job. Near the end of its lease,
+	// it no longer considers itself the leader.
+	now = now.Add(DefaultTTL - DefaultTTL/10)
+	if primary.IsLeader() {
+		t.Error("primary is leader within the safety margin of its lease")
+	}
+	if standby.Campaign() {
+		t.Fatal("standby took the lease before it expired")
+	}
+
+	// Within a minute, the standby takes over,
+	// learning which job was interrupted.
+	now = now.Add(DefaultTTL / 10)
+	if !standby.Campaign() || !standby.IsLeader() {
+		t.Fatal("standby did not take over the expired lease")
+	}
+	l, ok := standby.Lease()
+	if !ok || l.Holder != "standby" || l.Previous != "primary" || l.Term != 2 {
+		t.Errorf("lease after takeover = %+v", l)
+	}
+	if l.Job != "" {
+		t.Errorf("lease Job = %q, want none", l.Job)
+	}
+
+	// The primary comes back: it must not act.
+	if primary.Verify() {
+		t.Error("stale primary verified leadership")
+	}
+	if primary.Campaign() {
+		t.Error("stale primary took the lease back")
+	}
+
+	// The standby resigns; the primary takes over immediately.
+	standby.Resign()
+	if standby.IsLeader() {
+		t.Error("standby is leader after resigning")
+	}
+	if !primary.Campaign() {
+		t.Error("primary did not take over after the standby resigned")
+	}
+}
+
+func TestInterruptedJob(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	primary := New(lg, db, "gaby", "primary")
+	primary.now = func() time.Time { return now }
+	primary.Campaign()
+	primary.SetJob("cron")
+	primary.Campaign()
+
+	standby := New(lg, db, "gaby", "standby")
+	standby.now = func() time.Time { return now }
+	standby.Campaign()
+	if l, _ := standby.Lease(); l.Job != "cron" {
+		t.Errorf("standby sees job %q, want %q", l.Job, "cron")
+	}
+}
+
+func TestRun(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	e := New(lg, db, "gaby", "primary")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+	for !e.IsLeader() {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	if e.IsLeader() {
+		t.Error("leader after Run returned")
+	}
+	if !New(lg, db, "gaby", "standby").Campaign() {
+		t.Error("standby did not take over after Run returned")
+	}
+}
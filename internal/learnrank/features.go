@@ -0,0 +1,186 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package learnrank
+
+import (
+	"iter"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/entity"
+	"golang.org/x/oscar/internal/feedback"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/related"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A FeedbackSource provides the comments posted by a bot,
+// with the votes on them, such as a [feedback.Collector].
+type FeedbackSource interface {
+	Comments() iter.Seq[*feedback.Comment]
+}
+
+// FeedbackKind is the kind of the related comments
+// among the comments of a [FeedbackSource] (see [feedback.Collector.AddKind]).
+const FeedbackKind = "related"
+
+// historyTTL is how long an Extractor uses the feedback history
+// before computing it again.
+const historyTTL = time.Hour
+
+// An Extractor computes the features of candidate related documents.
+type Extractor struct {
+	db storage.DB
+	fb FeedbackSource // nil if none
+
+	mu          sync.Mutex
+	history     map[string]votes // votes on comments listing each document
+	historyTime time.Time        // when history was computed
+}
+
+// votes are the 👍 and 👎 votes on comments.
+type votes struct {
+	up, down int
+}
+
+// NewExtractor returns a new Extractor that reads GitHub issues
+// and posted related comments from db, and votes on the related comments
+// from fb. If fb is nil, the feedback feature is always 0.
+func NewExtractor(db storage.DB, fb FeedbackSource) *Extractor {
+	return &Extractor{db: db, fb: fb}
+}
+
+// Extract returns the features of the candidate r
+// for the query document with the given ID.
+func (x *Extractor) Extract(query string, r search.Result) Features {
+	return x.extract(query, r.ID, r.Score, x.feedbackHistory(), votes{})
+}
+
+// extract returns the features of the candidate with the given ID and
+// score for the query, using the feedback in history minus the votes in
+// exclude (the votes on the comment of a training example, which
+// must not count as its history).
+func (x *Extractor) extract(query, id string, score float64, history map[string]votes, exclude votes) Features {
+	f := Features{FeatureScore: score}
+	v := history[id]
+	up, down := v.up-exclude.up, v.down-exclude.down
+	f[FeatureFeedback] = float64(up-down) / float64(up+down+1)
+
+	q, qok := x.issue(query)
+	c, cok := x.issue(id)
+	if !qok || !cok {
+		return f
+	}
+	qt, err1 := time.Parse(time.RFC3339, q.CreatedAt)
+	ct, err2 := time.Parse(time.RFC3339, c.CreatedAt)
+	if err1 == nil && err2 == nil {
+		days := math.Abs(qt.Sub(ct).Hours()) / 24
+		f[FeatureRecency] = math.Exp(-days / 365)
+	}
+	f[FeatureLabels] = jaccard(labelNames(q), labelNames(c))
+	if x.participated(q.User.Login, c) {
+		f[FeatureAuthor] = 1
+	}
+	return f
+}
+
+// issue returns the GitHub issue with the given document ID,
+// if it is a GitHub issue in the database.
+func (x *Extractor) issue(id string) (*github.Issue, bool) {
+	e, err := entity.Parse(id)
+	if err != nil || e.Kind != entity.GitHubIssue {
+		return nil, false
+	}
+	iss, err := github.LookupIssue(x.db, e.Project, e.Number)
+	if err != nil {
+		return nil, false
+	}
+	return iss, true
+}
+
+// participated reports whether the GitHub user wrote
+// or commented on the issue.
+func (x *Extractor) participated(user string, iss *github.Issue) bool {
+	if user == "" {
+		return false
+	}
+	if iss.User.Login == user {
+		return true
+	}
+	for e := range github.Events(x.db, iss.Project(), iss.Number, iss.Number) {
+		if e.API == "/issues/comments" && e.Typed.(*github.IssueComment).User.Login == user {
+			return true
+		}
+	}
+	return false
+}
+
+func labelNames(iss *github.Issue) map[string]bool {
+	m := make(map[string]bool)
+	for _, l := range iss.Labels {
+		m[l.Name] = true
+	}
+	return m
+}
+
+// jaccard returns the Jaccard similarity of the sets x and y,
+// or 0 if both are empty.
+func jaccard(x, y map[string]bool) float64 {
+	both := 0
+	for k := range x {
+		if y[k] {
+			both++
+		}
+	}
+	either := len(x) + len(y) - both
+	if either == 0 {
+		return 0
+	}
+	return float64(both) / float64(either)
+}
+
+// feedbackHistory returns the votes on the related comments that listed
+// each document, computing them again if they are older than historyTTL.
+func (x *Extractor) feedbackHistory() map[string]votes {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.history == nil || time.Since(x.historyTime) > historyTTL {
+		x.history = make(map[string]votes)
+		for cm, ids := range x.relatedComments() {
+			for _, id := range ids {
+				v := x.history[id]
+				v.up += cm.Up()
+				v.down += cm.Down()
+				x.history[id] = v
+			}
+		}
+		x.historyTime = time.Now()
+	}
+	return x.history
+}
+
+// relatedComments returns the related comments with votes,
+// along with the IDs of the documents they listed.
+func (x *Extractor) relatedComments() iter.Seq2[*feedback.Comment, []string] {
+	return func(yield func(*feedback.Comment, []string) bool) {
+		if x.fb == nil {
+			return
+		}
+		for cm := range x.fb.Comments() {
+			if cm.Kind != FeedbackKind || cm.Up()+cm.Down() == 0 {
+				continue
+			}
+			ids, ok := related.Posted(x.db, cm.Project, cm.Issue)
+			if !ok {
+				continue
+			}
+			if !yield(cm, ids) {
+				return
+			}
+		}
+	}
+}
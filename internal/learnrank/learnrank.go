@@ -0,0 +1,182 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package learnrank implements a lightweight learned reranker
+// for related documents.
+//
+// The reranker is a logistic regression over a few features of each
+// candidate (see [FeatureNames]): its vector search score, how close in
+// time it is to the query, the labels and authors the two share, and
+// the feedback on earlier comments that listed it.
+// A [Model] is trained offline (see [Train] and [Extractor.Examples])
+// from the emoji votes on posted related comments, and stored
+// in the database (see [SaveModel]). A [Ranker] implements
+// [related.Reranker] with the latest stored model, so a newly
+// trained model is used without restarting the poster.
+//
+// Models are stored in the database:
+//
+//	(learnrank.Model, $name) -> JSON of [Model]
+package learnrank
+
+import (
+	"cmp"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// The features of a candidate related document.
+// Each is between 0 and 1, except [FeatureFeedback],
+// which is between -1 and 1.
+const (
+	// FeatureScore is the vector search score.
+	FeatureScore = "score"
+	// FeatureRecency is exp(-d/365), where d is the number of days
+	// between the creation of the query and of the candidate,
+	// or 0 if either is unknown.
+	FeatureRecency = "recency"
+	// FeatureLabels is the Jaccard similarity of the labels
+	// of the query and candidate issues.
+	FeatureLabels = "labels"
+	// FeatureAuthor is 1 if the author of the query issue wrote
+	// or commented on the candidate issue, and 0 otherwise.
+	FeatureAuthor = "author"
+	// FeatureFeedback is (up-down)/(up+down+1), where up and down
+	// are the 👍 and 👎 votes on earlier comments that listed
+	// the candidate.
+	FeatureFeedback = "feedback"
+)
+
+// FeatureNames are the names of the features, in a fixed order.
+var FeatureNames = []string{FeatureScore, FeatureRecency, FeatureLabels, FeatureAuthor, FeatureFeedback}
+
+// Features holds the values of the features of a candidate, by name.
+type Features map[string]float64
+
+// A Model is a logistic regression model that predicts
+// the probability that a candidate is a helpful related document.
+type Model struct {
+	Weights  map[string]float64 // weights by feature name; missing features have weight 0
+	Bias     float64
+	Trained  time.Time // when the model was trained
+	Examples int       // number of training examples
+	Positive int       // number of helpful training examples
+	Loss     float64   // mean log loss on the training examples
+	Accuracy float64   // accuracy on the training examples
+}
+
+// Predict returns the probability that a candidate
+// with the features f is helpful.
+func (m *Model) Predict(f Features) float64 {
+	z := m.Bias
+	for name, w := range m.Weights {
+		z += w * f[name]
+	}
+	return sigmoid(z)
+}
+
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+const modelKind = "learnrank.Model"
+
+// SaveModel stores m in db as the model with the given name,
+// replacing any previous one.
+// [Ranker] instances using the name load it on their next use.
+func SaveModel(db storage.DB, name string, m *Model) {
+	db.Set(ordered.Encode(modelKind, name), storage.JSON(m))
+}
+
+// LoadModel returns the model with the given name stored in db,
+// and whether there is one.
+func LoadModel(db storage.DB, name string) (*Model, bool) {
+	m, _, ok := loadModel(db, name)
+	return m, ok
+}
+
+// loadModel is like LoadModel, but also returns the stored JSON.
+func loadModel(db storage.DB, name string) (*Model, []byte, bool) {
+	data, ok := db.Get(ordered.Encode(modelKind, name))
+	if !ok {
+		return nil, nil, false
+	}
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		// unreachable unless database corruption
+		db.Panic("learnrank.LoadModel: cannot unmarshal", "name", name, "err", err)
+	}
+	return &m, data, true
+}
+
+// A Ranker reranks related documents using the latest stored model
+// with a given name. It implements [related.Reranker].
+type Ranker struct {
+	slog *slog.Logger
+	db   storage.DB
+	x    *Extractor
+	name string
+
+	mu    sync.Mutex
+	model *Model // nil if none
+	data  []byte // JSON of model, to detect changes
+}
+
+// NewRanker returns a new Ranker that reranks candidates
+// using the model with the given name stored in db (see [SaveModel])
+// and the features computed by x. It logs to lg.
+func NewRanker(lg *slog.Logger, db storage.DB, x *Extractor, name string) *Ranker {
+	return &Ranker{slog: lg, db: db, x: x, name: name}
+}
+
+// Model returns the current model, or nil if there is none.
+// It loads the stored model if it has changed since the last call.
+func (r *Ranker) Model() *Model {
+	m, data, ok := loadModel(r.db, r.name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !ok {
+		r.model, r.data = nil, nil
+		return nil
+	}
+	if !slices.Equal(data, r.data) {
+		r.slog.Info("learnrank.Ranker loaded model", "name", r.name, "trained", m.Trained, "examples", m.Examples)
+		r.model, r.data = m, data
+	}
+	return r.model
+}
+
+// Rerank returns the results ordered by the probability,
+// predicted by the model, that they are helpful related documents
+// for the document with ID query, most probable first.
+// Results with equal probabilities keep their order.
+// If there is no model, Rerank returns the results unchanged.
+func (r *Ranker) Rerank(query string, results []search.Result) []search.Result {
+	m := r.Model()
+	if m == nil || len(results) < 2 {
+		return results
+	}
+	type scored struct {
+		r search.Result
+		p float64
+	}
+	var list []scored
+	for _, res := range results {
+		list = append(list, scored{res, m.Predict(r.x.Extract(query, res))})
+	}
+	slices.SortStableFunc(list, func(x, y scored) int { return cmp.Compare(y.p, x.p) })
+	reranked := make([]search.Result, len(list))
+	for i, s := range list {
+		reranked[i] = s.r
+	}
+	return reranked
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package learnrank
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/feedback"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+	"rsc.io/ordered"
+)
+
+type testFeedback []*feedback.Comment
+
+func (f testFeedback) Comments() iter.Seq[*feedback.Comment] {
+	return slices.Values(f)
+}
+
+const project = "golang/go"
+
+func issueURL(n int64) string {
+	return fmt.Sprintf("https://github.com/%s/issues/%d", project, n)
+}
+
+// newTestExtractor returns an Extractor for a world in which
+// the related comments listing issues 1 and 2, which share the
+// labels and author of the query issues, get 👍 votes,
+// and those listing issues 3 and 4, which have higher
+// vector search scores, get 👎 votes.
+func newTestExtractor(t *testing.T) (*Extractor, storage.DB, storage.VectorDB) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	vdb := storage.MemVectorDB(db, lg, "vecs")
+	gh := github.New(lg, db, nil, nil)
+	tc := gh.Testing()
+	logRelated := actions.Register("related.Poster", nil)
+
+	addIssue := func(n int64, user, label, created string, vec llm.Vector) {
+		tc.AddIssue(project, &github.Issue{
+			Number:    n,
+			User:      github.User{Login: user},
+			Labels:    []github.Label{{Name: label}},
+			CreatedAt: created,
+		})
+		vdb.Set(issueURL(n), vec)
+	}
+	addIssue(1, "alice", "gopls", "2025-01-01T00:00:00Z", llm.Vector{0.6, 0.8})
+	addIssue(2, "carol", "gopls", "2025-01-02T00:00:00Z", llm.Vector{0.6, 0.8})
+	tc.AddIssueComment(project, 2, &github.IssueComment{User: github.User{Login: "alice"}})
+	addIssue(3, "bob", "compiler", "2020-01-01T00:00:00Z", llm.Vector{0.8, 0.6})
+	addIssue(4, "bob", "compiler", "2020-01-02T00:00:00Z", llm.Vector{0.8, 0.6})
+
+	var fb testFeedback
+	for n := int64(101); n <= 110; n++ {
+		addIssue(n, "alice", "gopls", fmt.Sprintf("2025-01-%02dT00:00:00Z", n-100), llm.Vector{1, 0})
+		cm := &feedback.Comment{Project: project, Issue: n, Kind: FeedbackKind}
+		ids := []string{issueURL(1), issueURL(2)}
+		if n%2 == 0 {
+			cm.Reactions.PlusOne = 2
+		} else {
+			ids = []string{issueURL(3), issueURL(4)}
+			cm.Reactions.MinusOne = 2
+		}
+		fb = append(fb, cm)
+		logRelated(db, ordered.Encode(project, n), storage.JSON(map[string]any{"Related": ids}), false)
+	}
+	// Comments of other kinds and without votes are ignored.
+	fb = append(fb, &feedback.Comment{Project: project, Issue: 101, Kind: "overview", Reactions: github.Reactions{PlusOne: 5}})
+	fb = append(fb, &feedback.Comment{Project: project, Issue: 102, Kind: FeedbackKind})
+
+	addIssue(111, "alice", "gopls", "2025-02-01T00:00:00Z", llm.Vector{1, 0})
+	return NewExtractor(db, fb), db, vdb
+}
+
+func TestExamples(t *testing.T) {
+	x, _, vdb := newTestExtractor(t)
+	exs := x.Examples(vdb)
+	if len(exs) != 20 {
+		t.Fatalf("got %d examples, want 20", len(exs))
+	}
+	helpful := 0
+	for _, ex := range exs {
+		if ex.Helpful {
+			helpful++
+		}
+	}
+	if helpful != 10 {
+		t.Errorf("got %d helpful examples, want 10", helpful)
+	}
+
+	// Issue 1 is listed in 5 comments with 2 👍 each;
+	// the example excludes the votes on its own comment.
+	// The examples of issue 101 (listing issues 3 and 4) come first.
+	ex := exs[2]
+	if ex.Query != issueURL(102) || ex.ID != issueURL(1) {
+		t.Fatalf("third example is %s in %s, want %s in %s", ex.ID, ex.Query, issueURL(1), issueURL(102))
+	}
+	want := Features{
+		FeatureScore:    0.6,
+		FeatureRecency:  math.Exp(-1.0 / 365),
+		FeatureLabels:   1,
+		FeatureAuthor:   1,
+		FeatureFeedback: 8.0 / 9,
+	}
+	for name, w := range want {
+		if got := ex.Features[name]; math.Abs(got-w) > 1e-6 {
+			t.Errorf("feature %s = %v, want %v", name, got, w)
+		}
+	}
+
+	// The query's author commented on issue 2.
+	if got := exs[3].Features[FeatureAuthor]; got != 1 {
+		t.Errorf("author feature of issue 2 = %v, want 1", got)
+	}
+	// Issue 3 has nothing in common with its queries.
+	for _, ex := range exs {
+		if ex.ID == issueURL(3) {
+			if ex.Features[FeatureLabels] != 0 || ex.Features[FeatureAuthor] != 0 || ex.Features[FeatureFeedback] >= 0 {
+				t.Errorf("features of issue 3 = %v", ex.Features)
+			}
+			break
+		}
+	}
+}
+
+func TestTrainAndRerank(t *testing.T) {
+	x, db, vdb := newTestExtractor(t)
+	lg := testutil.Slogger(t)
+	exs := x.Examples(vdb)
+
+	m, err := Train(exs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Examples != 20 || m.Positive != 10 || m.Accuracy != 1 || m.Loss > 0.3 {
+		t.Errorf("Train: examples %d, positive %d, accuracy %v, loss %v; want 20, 10, 1, < 0.3",
+			m.Examples, m.Positive, m.Accuracy, m.Loss)
+	}
+	for _, name := range []string{FeatureLabels, FeatureAuthor, FeatureFeedback} {
+		if m.Weights[name] <= 0 {
+			t.Errorf("weight of %s = %v, want > 0", name, m.Weights[name])
+		}
+	}
+
+	results := []search.Result{
+		{Kind: search.KindGitHubIssue, VectorResult: storage.VectorResult{ID: issueURL(3), Score: 0.8}},
+		{Kind: search.KindGitHubIssue, VectorResult: storage.VectorResult{ID: issueURL(1), Score: 0.6}},
+	}
+	ids := func(rs []search.Result) []string {
+		var ids []string
+		for _, r := range rs {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	byScore := ids(results)
+	byModel := []string{issueURL(1), issueURL(3)}
+
+	r := NewRanker(lg, db, x, "test")
+	if got := ids(r.Rerank(issueURL(111), results)); !slices.Equal(got, byScore) {
+		t.Errorf("Rerank without model = %v, want %v", got, byScore)
+	}
+	SaveModel(db, "test", m)
+	if got := ids(r.Rerank(issueURL(111), results)); !slices.Equal(got, byModel) {
+		t.Errorf("Rerank = %v, want %v", got, byModel)
+	}
+	if got, ok := LoadModel(db, "test"); !ok || got.Examples != m.Examples {
+		t.Errorf("LoadModel = %v, %v", got, ok)
+	}
+
+	// A new model is used without a new Ranker.
+	SaveModel(db, "test", &Model{Weights: map[string]float64{FeatureScore: 1}})
+	if got := ids(r.Rerank(issueURL(111), results)); !slices.Equal(got, byScore) {
+		t.Errorf("Rerank with new model = %v, want %v", got, byScore)
+	}
+}
+
+func TestTrainError(t *testing.T) {
+	exs := []*Example{{Features: Features{FeatureScore: 1}, Helpful: true}}
+	if _, err := Train(exs); err == nil {
+		t.Errorf("Train with only helpful examples succeeded, want error")
+	}
+	if _, err := Train(nil); err == nil {
+		t.Errorf("Train with no examples succeeded, want error")
+	}
+}
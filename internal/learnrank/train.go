@@ -0,0 +1,157 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package learnrank
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// An Example is a training example: a related document
+// listed in a comment that received votes.
+type Example struct {
+	Query    string // ID of the document the comment was posted on
+	ID       string // ID of the related document
+	Features Features
+	Helpful  bool // whether the comment received more 👍 than 👎 votes
+}
+
+// Examples returns the training examples from the related comments
+// with votes, using vdb to compute the vector search scores.
+// The feedback feature of an example excludes the votes on its own
+// comment. Related documents no longer in vdb are skipped.
+func (x *Extractor) Examples(vdb storage.VectorDB) []*Example {
+	history := x.feedbackHistory()
+	var exs []*Example
+	for cm, ids := range x.relatedComments() {
+		iss, err := github.LookupIssue(x.db, cm.Project, cm.Issue)
+		if err != nil {
+			continue
+		}
+		query := iss.DocID()
+		qvec, ok := vdb.Get(query)
+		if !ok {
+			continue
+		}
+		own := votes{cm.Up(), cm.Down()}
+		for _, id := range ids {
+			vec, ok := vdb.Get(id)
+			if !ok {
+				continue
+			}
+			exs = append(exs, &Example{
+				Query:    query,
+				ID:       id,
+				Features: x.extract(query, id, qvec.Dot(vec), history, own),
+				Helpful:  cm.Up() > cm.Down(),
+			})
+		}
+	}
+	return exs
+}
+
+// Training parameters.
+const (
+	iterations   = 2000
+	learningRate = 0.5
+	l2           = 0.01 // regularization of the weights
+)
+
+// Train trains a model on the examples, by gradient descent on the
+// regularized log loss. It returns an error if the examples are not
+// a mix of helpful and unhelpful ones.
+func Train(exs []*Example) (*Model, error) {
+	pos := 0
+	for _, ex := range exs {
+		if ex.Helpful {
+			pos++
+		}
+	}
+	if pos == 0 || pos == len(exs) {
+		return nil, errors.New("learnrank.Train: need both helpful and unhelpful examples")
+	}
+
+	n := float64(len(exs))
+	w := make([]float64, len(FeatureNames))
+	var b float64
+	xs := make([][]float64, len(exs))
+	for i, ex := range exs {
+		xs[i] = make([]float64, len(FeatureNames))
+		for j, name := range FeatureNames {
+			xs[i][j] = ex.Features[name]
+		}
+	}
+	predict := func(x []float64) float64 {
+		z := b
+		for j := range w {
+			z += w[j] * x[j]
+		}
+		return sigmoid(z)
+	}
+	grad := make([]float64, len(w))
+	for range iterations {
+		clear(grad)
+		var gb float64
+		for i, ex := range exs {
+			d := predict(xs[i]) - label(ex)
+			for j := range w {
+				grad[j] += d * xs[i][j]
+			}
+			gb += d
+		}
+		for j := range w {
+			w[j] -= learningRate * (grad[j]/n + l2*w[j])
+		}
+		b -= learningRate * gb / n
+	}
+
+	m := &Model{
+		Weights:  make(map[string]float64),
+		Bias:     b,
+		Trained:  time.Now(),
+		Examples: len(exs),
+		Positive: pos,
+	}
+	for j, name := range FeatureNames {
+		m.Weights[name] = w[j]
+	}
+	m.Loss, m.Accuracy = Evaluate(m, exs)
+	return m, nil
+}
+
+// Evaluate returns the mean log loss and the accuracy
+// of the model's predictions for the examples.
+func Evaluate(m *Model, exs []*Example) (loss, accuracy float64) {
+	if len(exs) == 0 {
+		return 0, 0
+	}
+	correct := 0
+	for _, ex := range exs {
+		p := m.Predict(ex.Features)
+		// Clamp to avoid infinite loss.
+		p = min(max(p, 1e-12), 1-1e-12)
+		if ex.Helpful {
+			loss -= math.Log(p)
+		} else {
+			loss -= math.Log(1 - p)
+		}
+		if (p >= 0.5) == ex.Helpful {
+			correct++
+		}
+	}
+	n := float64(len(exs))
+	return loss / n, float64(correct) / n
+}
+
+func label(ex *Example) float64 {
+	if ex.Helpful {
+		return 1
+	}
+	return 0
+}
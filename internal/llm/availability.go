@@ -0,0 +1,206 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// An Availability tracks whether an LLM service appears to be available,
+// based on the outcome of the most recent calls made through the
+// [Embedder] and [ContentGenerator] wrappers it returns.
+//
+// Only transient failures (see [Transient]) count against the service,
+// and it is marked unavailable only after several in a row
+// (see [Availability.SetFailureThreshold]), so that a bad request
+// or a single blip does not degrade every feature.
+//
+// An Availability is meant to be shared by all the features of a program
+// that depend on the same LLM service, so that they can degrade
+// consistently (for example, by serving cached results or deferring work)
+// while the service is down.
+//
+// An Availability is safe for concurrent use.
+type Availability struct {
+	mu        sync.Mutex
+	status    Status
+	threshold int // consecutive transient failures that make the service unavailable
+	failures  int // consecutive transient failures so far
+}
+
+// A Status is a snapshot of an [Availability].
+type Status struct {
+	Available bool      // whether the service appears to be available
+	Since     time.Time // time of the first call with the current outcome (zero if no calls yet)
+	Err       string    // error from the most recent failed call, if !Available
+}
+
+// DefaultFailureThreshold is the default number of consecutive
+// transient failures after which an [Availability] reports
+// the service unavailable.
+const DefaultFailureThreshold = 3
+
+// NewAvailability returns a new Availability.
+// The service is assumed to be available until
+// [DefaultFailureThreshold] calls in a row fail transiently.
+func NewAvailability() *Availability {
+	return &Availability{status: Status{Available: true}, threshold: DefaultFailureThreshold}
+}
+
+// SetFailureThreshold sets the number of consecutive transient
+// failures after which the service is reported unavailable.
+// SetFailureThreshold panics if n < 1.
+func (a *Availability) SetFailureThreshold(n int) {
+	if n < 1 {
+		panic(fmt.Sprintf("llm.Availability.SetFailureThreshold: bad threshold %d", n))
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.threshold = n
+}
+
+// Available reports whether the service appears to be available.
+func (a *Availability) Available() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.status.Available
+}
+
+// Status returns the current status.
+func (a *Availability) Status() Status {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.status
+}
+
+// Record records the outcome of a call to the service.
+// A transient error (see [Transient]) counts as a failure,
+// and enough consecutive failures mark the service unavailable.
+// Any other outcome, including an error that shows the service
+// answered (such as a rejected request), marks it available,
+// except for context cancellation, which is ignored.
+func (a *Availability) Record(err error) {
+	if errors.Is(err, context.Canceled) {
+		// The caller gave up; that says nothing about the service.
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ok := true
+	if Transient(err) {
+		a.failures++
+		ok = a.failures < a.threshold
+	} else {
+		a.failures = 0
+	}
+	if ok != a.status.Available || a.status.Since.IsZero() {
+		a.status.Since = time.Now()
+	}
+	a.status.Available = ok
+	a.status.Err = ""
+	if !ok {
+		a.status.Err = err.Error()
+	}
+}
+
+// Transient reports whether err is likely a temporary failure
+// of an LLM service rather than a problem with the request:
+// a timeout, a network error, or an HTTP response saying that
+// the server failed (5xx) or the quota is used up (429).
+// Errors with unknown causes are not transient.
+func Transient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return true
+	}
+	code := 0
+	var he *HTTPError
+	var ge *googleapi.Error
+	switch {
+	case errors.As(err, &he):
+		code = he.StatusCode
+	case errors.As(err, &ge):
+		code = ge.Code
+	}
+	return code >= 500 || code == http.StatusTooManyRequests || code == http.StatusRequestTimeout
+}
+
+// An HTTPError is an unsuccessful response from an LLM service's HTTP API.
+// Clients of such services return it so that callers can tell
+// server failures from rejected requests (see [Transient]).
+type HTTPError struct {
+	StatusCode int    // such as 503
+	Status     string // such as "503 Service Unavailable"
+	Message    string // error message from the response body, if any
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return e.Status
+	}
+	return e.Status + ": " + e.Message
+}
+
+// Embedder returns an Embedder that calls e and records
+// the outcome of each call in a.
+func (a *Availability) Embedder(e Embedder) Embedder {
+	return &availEmbedder{a: a, e: e}
+}
+
+type availEmbedder struct {
+	a *Availability
+	e Embedder
+}
+
+func (ae *availEmbedder) EmbedDocs(ctx context.Context, docs []EmbedDoc) ([]Vector, error) {
+	vecs, err := ae.e.EmbedDocs(ctx, docs)
+	ae.a.Record(err)
+	return vecs, err
+}
+
+// ContentGenerator returns a ContentGenerator that calls g and records
+// the outcome of each call to GenerateContent in a.
+func (a *Availability) ContentGenerator(g ContentGenerator) ContentGenerator {
+	return &availGenerator{a: a, g: g}
+}
+
+type availGenerator struct {
+	a *Availability
+	g ContentGenerator
+}
+
+func (ag *availGenerator) Model() string {
+	return ag.g.Model()
+}
+
+func (ag *availGenerator) SetTemperature(t float32) {
+	ag.g.SetTemperature(t)
+}
+
+func (ag *availGenerator) GenerateContent(ctx context.Context, schema *Schema, parts []Part) (string, error) {
+	s, _, err := ag.GenerateContentModel(ctx, schema, parts)
+	return s, err
+}
+
+func (ag *availGenerator) GenerateContentModel(ctx context.Context, schema *Schema, parts []Part) (string, string, error) {
+	s, model, err := GenerateContentModel(ctx, ag.g, schema, parts)
+	ag.a.Record(err)
+	return s, model, err
+}
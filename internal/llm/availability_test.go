@@ -0,0 +1,120 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestAvailability(t *testing.T) {
+	ctx := context.Background()
+	a := NewAvailability()
+	if !a.Available() {
+		t.Fatal("new Availability is not available")
+	}
+
+	var fail error
+	g := a.ContentGenerator(TestContentGenerator("test", func(ctx context.Context, schema *Schema, parts []Part) (string, error) {
+		return "ok", fail
+	}))
+
+	// Client errors say the service is up.
+	fail = &HTTPError{StatusCode: 400, Status: "400 Bad Request", Message: "bad prompt"}
+	for range DefaultFailureThreshold + 1 {
+		if _, err := g.GenerateContent(ctx, nil, []Part{Text("hi")}); err == nil {
+			t.Fatal("GenerateContent succeeded unexpectedly")
+		}
+	}
+	if !a.Available() {
+		t.Error("Available() = false after client errors")
+	}
+
+	// It takes DefaultFailureThreshold server errors in a row
+	// to make the service unavailable.
+	fail = &HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}
+	for i := range DefaultFailureThreshold {
+		if !a.Available() {
+			t.Fatalf("Available() = false after %d server errors", i)
+		}
+		if _, err := g.GenerateContent(ctx, nil, []Part{Text("hi")}); err == nil {
+			t.Fatal("GenerateContent succeeded unexpectedly")
+		}
+	}
+	if a.Available() {
+		t.Error("Available() = true after failed calls")
+	}
+	s := a.Status()
+	if s.Err != "503 Service Unavailable" || s.Since.IsZero() {
+		t.Errorf("Status() = %+v, want Err=503 Service Unavailable and non-zero Since", s)
+	}
+
+	// Cancellation does not change the status.
+	a.Record(context.Canceled)
+	if a.Available() {
+		t.Error("Available() = true after context.Canceled")
+	}
+
+	fail = nil
+	if _, err := g.GenerateContent(ctx, nil, []Part{Text("hi")}); err != nil {
+		t.Fatal(err)
+	}
+	if s := a.Status(); !s.Available || s.Err != "" {
+		t.Errorf("Status() = %+v after successful call, want available", s)
+	}
+
+	e := a.Embedder(QuoteEmbedder())
+	if _, err := e.EmbedDocs(ctx, []EmbedDoc{{Text: "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Available() {
+		t.Error("Available() = false after successful embedding")
+	}
+}
+
+func TestAvailabilityThreshold(t *testing.T) {
+	a := NewAvailability()
+	a.SetFailureThreshold(2)
+	down := &HTTPError{StatusCode: 500, Status: "500 Internal Server Error"}
+
+	// Failures that are not consecutive do not add up.
+	a.Record(down)
+	a.Record(nil)
+	a.Record(down)
+	if !a.Available() {
+		t.Error("Available() = false after non-consecutive failures")
+	}
+	a.Record(down)
+	if a.Available() {
+		t.Error("Available() = true after 2 consecutive failures")
+	}
+}
+
+func TestTransient(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("bad request"), false},
+		{&HTTPError{StatusCode: 400}, false},
+		{&HTTPError{StatusCode: 404}, false},
+		{fmt.Errorf("wrapped: %w", &HTTPError{StatusCode: 503}), true},
+		{&HTTPError{StatusCode: 429}, true},
+		{&googleapi.Error{Code: 500}, true},
+		{&googleapi.Error{Code: 403}, false},
+		{context.DeadlineExceeded, true},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+	} {
+		if got := Transient(tt.err); got != tt.want {
+			t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import "context"
+
+// A BatchContentGenerator is a [ContentGenerator] that can also
+// generate content asynchronously for many requests at once.
+// Providers typically charge less for batched generation
+// in exchange for higher latency, which suits backfill jobs
+// that do not need their results right away.
+//
+// See [TestBatchContentGenerator] for a test implementation.
+type BatchContentGenerator interface {
+	ContentGenerator
+	// SubmitBatch starts asynchronous generation for the requests
+	// and returns an identifier for the batch, for use with BatchResult.
+	SubmitBatch(ctx context.Context, reqs []*BatchRequest) (id string, err error)
+	// BatchResult reports the status of the batch with the given id.
+	// Once the batch is [BatchDone], the result's Responses
+	// correspond one-to-one with the submitted requests.
+	BatchResult(ctx context.Context, id string) (*BatchResult, error)
+}
+
+// A BatchRequest is a single request in a batch.
+// Its fields have the same meaning as the arguments
+// to [ContentGenerator.GenerateContent].
+type BatchRequest struct {
+	Schema *Schema
+	Parts  []Part
+}
+
+// A BatchState is the state of a batch.
+type BatchState string
+
+const (
+	BatchRunning BatchState = "running" // batch is queued or in progress
+	BatchDone    BatchState = "done"    // all requests have completed (some may have failed)
+	BatchFailed  BatchState = "failed"  // the batch as a whole failed
+)
+
+// A BatchResult is the status of a batch.
+type BatchResult struct {
+	State     BatchState
+	Responses []BatchResponse // set when State is BatchDone
+	Error     string          // set when State is BatchFailed
+}
+
+// A BatchResponse is the response to a single [BatchRequest].
+// Exactly one of Text and Error is set.
+type BatchResponse struct {
+	Text  string
+	Error string
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// A Failover is an [Embedder] and [ContentGenerator] that sends each
+// call to the first of a list of LLM providers that accepts it,
+// failing over to the next provider when one returns an error
+// or has used up its rate limit.
+//
+// A Failover does not remember failures: every call starts with
+// the first provider. To make calls to a provider that is down
+// fail fast, wrap its HTTP client with a circuit breaker.
+//
+// Embeddings from different models are not comparable,
+// so the embedders of a Failover must all compute the same
+// embeddings (for example, the same model served by different
+// endpoints). Providers that should only be used for content generation
+// can be added with a nil Embedder.
+//
+// A Failover is safe for concurrent use once it has been configured.
+type Failover struct {
+	slog      *slog.Logger
+	providers []*provider
+}
+
+// A provider is a single LLM service used by a Failover.
+type provider struct {
+	name    string
+	gen     ContentGenerator // nil if not used for content generation
+	embed   Embedder         // nil if not used for embedding
+	limiter *rate.Limiter    // nil means no limit
+}
+
+// NewFailover returns a new Failover with no providers.
+func NewFailover(lg *slog.Logger) *Failover {
+	return &Failover{slog: lg}
+}
+
+// Add adds a provider with the given name, to be tried after all the
+// providers already added. Either g or e may be nil if the provider
+// should not be used for content generation or embedding.
+func (f *Failover) Add(name string, g ContentGenerator, e Embedder) {
+	f.providers = append(f.providers, &provider{name: name, gen: g, embed: e})
+}
+
+// SetRateLimit limits the calls to the named provider to n per interval,
+// allowing bursts of up to n calls. Calls that would exceed the limit go
+// to the next provider instead, and wait for the limit only if
+// no other provider can take them.
+// SetRateLimit panics if there is no provider with that name.
+func (f *Failover) SetRateLimit(name string, n int, per time.Duration) {
+	for _, p := range f.providers {
+		if p.name == name {
+			p.limiter = rate.NewLimiter(rate.Every(per/time.Duration(n)), n)
+			return
+		}
+	}
+	panic(fmt.Sprintf("llm.Failover.SetRateLimit: unknown provider %q", name))
+}
+
+// Providers returns the names of the providers, in order.
+func (f *Failover) Providers() []string {
+	var names []string
+	for _, p := range f.providers {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+var (
+	_ Embedder      = (*Failover)(nil)
+	_ ModelReporter = (*Failover)(nil)
+)
+
+// Model returns the name of the generative model of the first
+// content-generating provider. Calls that fail over use a different
+// model; use [Failover.GenerateContentModel] to learn which one.
+// Implements [ContentGenerator.Model].
+func (f *Failover) Model() string {
+	for _, p := range f.providers {
+		if p.gen != nil {
+			return p.gen.Model()
+		}
+	}
+	return ""
+}
+
+// SetTemperature sets the temperature of every provider's generative model.
+// Implements [ContentGenerator.SetTemperature].
+func (f *Failover) SetTemperature(t float32) {
+	for _, p := range f.providers {
+		if p.gen != nil {
+			p.gen.SetTemperature(t)
+		}
+	}
+}
+
+// GenerateContent generates content using the first provider that succeeds.
+// Implements [ContentGenerator.GenerateContent].
+func (f *Failover) GenerateContent(ctx context.Context, schema *Schema, parts []Part) (string, error) {
+	out, _, err := f.GenerateContentModel(ctx, schema, parts)
+	return out, err
+}
+
+// GenerateContentModel generates content using the first provider
+// that succeeds, and returns the name of that provider's model.
+// Implements [ModelReporter.GenerateContentModel].
+func (f *Failover) GenerateContentModel(ctx context.Context, schema *Schema, parts []Part) (out, model string, err error) {
+	err = f.do(ctx, "GenerateContent",
+		func(p *provider) bool { return p.gen != nil },
+		func(p *provider) error {
+			var err error
+			out, model, err = GenerateContentModel(ctx, p.gen, schema, parts)
+			return err
+		})
+	if err != nil {
+		return "", "", err
+	}
+	return out, model, nil
+}
+
+// EmbedDocs embeds the docs using the first provider that succeeds.
+// Implements [Embedder.EmbedDocs].
+func (f *Failover) EmbedDocs(ctx context.Context, docs []EmbedDoc) ([]Vector, error) {
+	var vecs []Vector
+	err := f.do(ctx, "EmbedDocs",
+		func(p *provider) bool { return p.embed != nil },
+		func(p *provider) error {
+			var err error
+			vecs, err = p.embed.EmbedDocs(ctx, docs)
+			return err
+		})
+	return vecs, err
+}
+
+// do calls call with each provider for which use returns true,
+// in order, until a call succeeds. Providers over their rate limit
+// are skipped; if no other provider succeeds, do waits for the first
+// rate-limited provider and calls it.
+func (f *Failover) do(ctx context.Context, op string, use func(*provider) bool, call func(*provider) error) error {
+	var errs []error
+	var limited *provider
+	try := func(p *provider) bool {
+		err := call(p)
+		if err == nil {
+			return true
+		}
+		f.slog.Warn("llm provider failed", "op", op, "provider", p.name, "err", err)
+		errs = append(errs, fmt.Errorf("%s: %w", p.name, err))
+		return false
+	}
+	for _, p := range f.providers {
+		if !use(p) {
+			continue
+		}
+		if p.limiter != nil && !p.limiter.Allow() {
+			f.slog.Debug("llm provider rate-limited", "op", op, "provider", p.name)
+			if limited == nil {
+				limited = p
+			}
+			continue
+		}
+		if try(p) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	if limited != nil {
+		if err := limited.limiter.Wait(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", limited.name, err))
+		} else if try(limited) {
+			return nil
+		}
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("llm.Failover.%s: no providers", op)
+	}
+	return fmt.Errorf("llm.Failover.%s: all providers failed: %w", op, errors.Join(errs...))
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// A fakeProvider is a ContentGenerator and Embedder
+// that counts its calls and fails if err is set.
+type fakeProvider struct {
+	model string
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) Model() string          { return p.model }
+func (p *fakeProvider) SetTemperature(float32) {}
+
+func (p *fakeProvider) GenerateContent(ctx context.Context, schema *Schema, parts []Part) (string, error) {
+	p.calls++
+	if p.err != nil {
+		return "", p.err
+	}
+	return p.model, nil
+}
+
+func (p *fakeProvider) EmbedDocs(ctx context.Context, docs []EmbedDoc) ([]Vector, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return QuoteEmbedder().EmbedDocs(ctx, docs)
+}
+
+func TestFailover(t *testing.T) {
+	ctx := context.Background()
+	a := &fakeProvider{model: "a"}
+	b := &fakeProvider{model: "b"}
+	f := NewFailover(testutil.Slogger(t))
+	f.Add("a", a, a)
+	f.Add("b", b, nil)
+
+	generate := func() string {
+		t.Helper()
+		out, model, err := f.GenerateContentModel(ctx, nil, []Part{Text("hi")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if model != out {
+			t.Errorf("generate = %q, but model = %q", out, model)
+		}
+		return out
+	}
+
+	if out := generate(); out != "a" {
+		t.Errorf("generate = %q, want a", out)
+	}
+
+	// Fail over to b while a is down.
+	a.err = errors.New("a is down")
+	if out := generate(); out != "b" {
+		t.Errorf("generate with a down = %q, want b", out)
+	}
+	// The Model of the Failover does not depend on past calls.
+	if m := f.Model(); m != "a" {
+		t.Errorf("Model() = %q, want a", m)
+	}
+	// b does not embed.
+	if _, err := f.EmbedDocs(ctx, []EmbedDoc{{Text: "x"}}); err == nil || !strings.Contains(err.Error(), "a is down") {
+		t.Errorf("EmbedDocs with a down: err = %v, want a is down", err)
+	}
+
+	// All providers down.
+	b.err = errors.New("b is down")
+	_, err := f.GenerateContent(ctx, nil, []Part{Text("hi")})
+	if err == nil || !strings.Contains(err.Error(), "a is down") || !strings.Contains(err.Error(), "b is down") {
+		t.Errorf("GenerateContent with all down: err = %v, want both errors", err)
+	}
+
+	// Back to a once it recovers.
+	a.err = nil
+	b.err = nil
+	if out := generate(); out != "a" {
+		t.Errorf("generate after recovery = %q, want a", out)
+	}
+	if _, err := f.EmbedDocs(ctx, []EmbedDoc{{Text: "x"}}); err != nil {
+		t.Errorf("EmbedDocs: %v", err)
+	}
+}
+
+// A modelProvider is a ContentGenerator that responds with its model,
+// failing for prompts that contain "fail".
+type modelProvider string
+
+func (p modelProvider) Model() string          { return string(p) }
+func (p modelProvider) SetTemperature(float32) {}
+
+func (p modelProvider) GenerateContent(ctx context.Context, schema *Schema, parts []Part) (string, error) {
+	if strings.Contains(string(parts[0].(Text)), "fail") {
+		return "", errors.New(string(p) + " failed")
+	}
+	return string(p), nil
+}
+
+func TestFailoverModelConcurrent(t *testing.T) {
+	ctx := context.Background()
+	f := NewFailover(testutil.Slogger(t))
+	f.Add("a", modelProvider("a"), nil)
+	f.Add("b", EchoContentGenerator(), nil)
+
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prompt, want := "ok", "a"
+			if i%2 == 0 {
+				prompt, want = "fail", EchoContentGenerator().Model()
+			}
+			_, model, err := GenerateContentModel(ctx, f, nil, []Part{Text(prompt)})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if model != want {
+				t.Errorf("GenerateContentModel(%q): model = %q, want %q", prompt, model, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFailoverRateLimit(t *testing.T) {
+	ctx := context.Background()
+	a := &fakeProvider{model: "a"}
+	b := &fakeProvider{model: "b"}
+	f := NewFailover(testutil.Slogger(t))
+	f.Add("a", a, nil)
+	f.Add("b", b, nil)
+	f.SetRateLimit("a", 2, time.Hour)
+
+	var got []string
+	for range 4 {
+		out, err := f.GenerateContent(ctx, nil, []Part{Text("hi")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, out)
+	}
+	if want := "a a b b"; strings.Join(got, " ") != want {
+		t.Errorf("providers = %q, want %q", got, want)
+	}
+
+	// When the only other provider fails, wait for the limit.
+	b.err = errors.New("b is down")
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.GenerateContent(ctx, nil, []Part{Text("hi")}); err == nil || !strings.Contains(err.Error(), "b is down") {
+		t.Errorf("GenerateContent over limit: err = %v, want b is down", err)
+	}
+	if a.calls != 2 {
+		t.Errorf("a called %d times, want 2", a.calls)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// A FunctionDeclaration declares a function (or tool) that the model
+// may ask to call, using [CallFunctions].
+type FunctionDeclaration struct {
+	// Name is the function's name, for example "lookup_issue".
+	// It must consist of letters, digits and underscores.
+	Name string
+	// Description tells the model what the function does
+	// and when to call it.
+	Description string
+	// Parameters is the schema for the function's arguments,
+	// which must be of type [TypeObject].
+	// Nil means the function takes no arguments.
+	Parameters *Schema
+}
+
+// A FunctionCall is a [Part] that is a call to a declared function,
+// made by the model.
+// FunctionCalls are returned in a [CallResponse]. To continue a conversation,
+// include the call in the next prompt, followed by a [FunctionResponse]
+// with the result.
+type FunctionCall struct {
+	Name string
+	Args map[string]any // decoded JSON; see [FunctionCall.Decode]
+}
+
+// A FunctionResponse is a [Part] that is the result of a [FunctionCall],
+// to be sent to the model in a prompt.
+type FunctionResponse struct {
+	Name     string
+	Response map[string]any // must be JSON-encodable
+}
+
+func (FunctionCall) isPart()     {}
+func (FunctionResponse) isPart() {}
+
+// Decode decodes the call's arguments into v,
+// which is typically a pointer to a struct with JSON tags
+// matching the function's parameters.
+func (fc FunctionCall) Decode(v any) error {
+	js, err := json.Marshal(fc.Args)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(js, v); err != nil {
+		return fmt.Errorf("llm: decoding arguments to %s: %w", fc.Name, err)
+	}
+	return nil
+}
+
+// A CallResponse is the response to [CallFunctions].
+type CallResponse struct {
+	Calls []FunctionCall // the functions the model asked to call, in order
+	Text  string         // the model's text response, if any
+}
+
+// A FunctionCaller is a [ContentGenerator] with native support
+// for function calling.
+// Generators that do not implement FunctionCaller can still be used
+// with [CallFunctions], which emulates function calling for them.
+//
+// See [golang.org/x/oscar/internal/gcp/gemini] for a real implementation.
+type FunctionCaller interface {
+	ContentGenerator
+	// GenerateFunctionCalls generates a response to the prompt parts,
+	// in which the model may ask to call any of the declared functions.
+	GenerateFunctionCalls(ctx context.Context, funcs []*FunctionDeclaration, parts []Part) (*CallResponse, error)
+}
+
+// CallFunctions asks g to respond to the prompt parts,
+// in which the model may ask to call any of the declared functions,
+// and returns the calls and any text response.
+// Every call in the response is to one of the declared functions.
+//
+// If g implements [FunctionCaller], CallFunctions uses its native support.
+// Otherwise, CallFunctions emulates function calling using
+// a JSON schema with one (optional) property for each function
+// (see [FunctionSchema]).
+func CallFunctions(ctx context.Context, g ContentGenerator, funcs []*FunctionDeclaration, parts []Part) (*CallResponse, error) {
+	if len(funcs) == 0 {
+		return nil, errors.New("llm.CallFunctions: no functions")
+	}
+	for i, f := range funcs {
+		if f.Name == "" || f.Name == textProperty {
+			return nil, fmt.Errorf("llm.CallFunctions: invalid function name %q", f.Name)
+		}
+		if slices.ContainsFunc(funcs[:i], func(g *FunctionDeclaration) bool { return g.Name == f.Name }) {
+			return nil, fmt.Errorf("llm.CallFunctions: duplicate function %q", f.Name)
+		}
+		if f.Parameters != nil && f.Parameters.Type != TypeObject {
+			return nil, fmt.Errorf("llm.CallFunctions: parameters of %s must be an object", f.Name)
+		}
+	}
+
+	var resp *CallResponse
+	if fc, ok := g.(FunctionCaller); ok {
+		r, err := fc.GenerateFunctionCalls(ctx, funcs, parts)
+		if err != nil {
+			return nil, fmt.Errorf("llm.CallFunctions: %w", err)
+		}
+		resp = r
+	} else {
+		js, err := g.GenerateContent(ctx, FunctionSchema(funcs), TextParts(parts))
+		if err != nil {
+			return nil, fmt.Errorf("llm.CallFunctions: %w", err)
+		}
+		r, err := ParseFunctionCalls(funcs, js)
+		if err != nil {
+			return nil, fmt.Errorf("llm.CallFunctions: %w", err)
+		}
+		resp = r
+	}
+	for _, c := range resp.Calls {
+		if !slices.ContainsFunc(funcs, func(f *FunctionDeclaration) bool { return f.Name == c.Name }) {
+			return nil, fmt.Errorf("llm.CallFunctions: call to undeclared function %q", c.Name)
+		}
+	}
+	return resp, nil
+}
+
+// textProperty is the name of the property holding the text response
+// in the [FunctionSchema].
+const textProperty = "text"
+
+// FunctionSchema returns the JSON schema used to emulate function calling
+// for generators without native support.
+// It is an object with an optional property for each function,
+// holding the function's arguments if the model calls it,
+// and an optional "text" property holding any text response.
+func FunctionSchema(funcs []*FunctionDeclaration) *Schema {
+	s := &Schema{
+		Type: TypeObject,
+		Properties: map[string]*Schema{
+			textProperty: {
+				Type:        TypeString,
+				Description: "Your response to the prompt, if any.",
+			},
+		},
+	}
+	for _, f := range funcs {
+		var p Schema
+		if f.Parameters != nil {
+			p = *f.Parameters
+		} else {
+			p = Schema{Type: TypeObject}
+		}
+		p.Nullable = true
+		p.Description = "To call the function " + f.Name + ", the arguments to the call; otherwise omit. " + f.Description
+		s.Properties[f.Name] = &p
+	}
+	return s
+}
+
+// ParseFunctionCalls parses a JSON response generated using
+// the [FunctionSchema] for funcs.
+func ParseFunctionCalls(funcs []*FunctionDeclaration, js string) (*CallResponse, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(js), &m); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal response: %w\nresponse: %s", err, js)
+	}
+	resp := new(CallResponse)
+	if t, ok := m[textProperty]; ok {
+		if err := json.Unmarshal(t, &resp.Text); err != nil {
+			return nil, fmt.Errorf("malformed text in response: %w", err)
+		}
+	}
+	for _, f := range funcs {
+		raw, ok := m[f.Name]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		var args map[string]any
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, fmt.Errorf("malformed arguments to %s: %w", f.Name, err)
+		}
+		resp.Calls = append(resp.Calls, FunctionCall{Name: f.Name, Args: args})
+	}
+	return resp, nil
+}
+
+// TextParts returns a copy of parts with each [FunctionCall] and
+// [FunctionResponse] replaced by a [Text] describing it,
+// for use with generators that do not support function calling.
+func TextParts(parts []Part) []Part {
+	var out []Part
+	for _, p := range parts {
+		switch p := p.(type) {
+		case FunctionCall:
+			out = append(out, Text(fmt.Sprintf("call to function %s with arguments: %s", p.Name, jsonString(p.Args))))
+		case FunctionResponse:
+			out = append(out, Text(fmt.Sprintf("result of function %s: %s", p.Name, jsonString(p.Response))))
+		default:
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// jsonString returns the JSON encoding of v.
+func jsonString(v any) string {
+	js, err := json.Marshal(v)
+	if err != nil {
+		// Unreachable for decoded JSON; a FunctionResponse
+		// is documented to be JSON-encodable.
+		return fmt.Sprintf("%v", v)
+	}
+	return string(js)
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testFuncs = []*FunctionDeclaration{
+	{
+		Name:        "issue",
+		Description: "Look up an issue.",
+		Parameters: &Schema{
+			Type: TypeObject,
+			Properties: map[string]*Schema{
+				"number": {Type: TypeInteger},
+			},
+			Required: []string{"number"},
+		},
+	},
+	{
+		Name:        "now",
+		Description: "Report the current time.",
+	},
+}
+
+func TestCallFunctions(t *testing.T) {
+	ctx := context.Background()
+
+	var prompt []Part
+	g := TestContentGenerator("test", func(_ context.Context, schema *Schema, parts []Part) (string, error) {
+		prompt = parts
+		if schema.Properties["issue"] == nil || !schema.Properties["issue"].Nullable || schema.Properties["now"] == nil {
+			t.Errorf("bad schema %+v", schema)
+		}
+		return `{"text":"checking","now":{},"issue":{"number":12}}`, nil
+	})
+	resp, err := CallFunctions(ctx, g, testFuncs, []Part{
+		Text("what is issue 12?"),
+		FunctionCall{Name: "issue", Args: map[string]any{"number": 11}},
+		FunctionResponse{Name: "issue", Response: map[string]any{"title": "wrong issue"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &CallResponse{
+		Text: "checking",
+		Calls: []FunctionCall{
+			{Name: "issue", Args: map[string]any{"number": 12.0}},
+			{Name: "now", Args: map[string]any{}},
+		},
+	}
+	if diff := cmp.Diff(want, resp); diff != "" {
+		t.Errorf("CallFunctions mismatch (-want +got):\n%s", diff)
+	}
+	wantPrompt := []Part{
+		Text("what is issue 12?"),
+		Text(`call to function issue with arguments: {"number":11}`),
+		Text(`result of function issue: {"title":"wrong issue"}`),
+	}
+	if diff := cmp.Diff(wantPrompt, prompt); diff != "" {
+		t.Errorf("prompt mismatch (-want +got):\n%s", diff)
+	}
+
+	var args struct{ Number int }
+	if err := resp.Calls[0].Decode(&args); err != nil || args.Number != 12 {
+		t.Errorf("Decode = %v, %v; want {12}, nil", args, err)
+	}
+}
+
+func TestCallFunctionsErrors(t *testing.T) {
+	ctx := context.Background()
+	g := TestContentGenerator("test", func(context.Context, *Schema, []Part) (string, error) {
+		return `{"issue":"twelve"}`, nil
+	})
+	for _, tc := range []struct {
+		name  string
+		funcs []*FunctionDeclaration
+		want  string
+	}{
+		{"none", nil, "no functions"},
+		{"duplicate", []*FunctionDeclaration{testFuncs[1], testFuncs[1]}, "duplicate function"},
+		{"text", []*FunctionDeclaration{{Name: "text"}}, "invalid function name"},
+		{"params", []*FunctionDeclaration{{Name: "f", Parameters: &Schema{Type: TypeString}}}, "must be an object"},
+		{"malformed", testFuncs, "malformed arguments to issue"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CallFunctions(ctx, g, tc.funcs, []Part{Text("hi")})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("CallFunctions() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+// nativeCaller is a FunctionCaller that returns a fixed response.
+type nativeCaller struct {
+	ContentGenerator
+	resp *CallResponse
+}
+
+func (n nativeCaller) GenerateFunctionCalls(context.Context, []*FunctionDeclaration, []Part) (*CallResponse, error) {
+	return n.resp, nil
+}
+
+func TestCallFunctionsNative(t *testing.T) {
+	ctx := context.Background()
+	good := &CallResponse{Calls: []FunctionCall{{Name: "now"}}}
+	g := nativeCaller{EchoContentGenerator(), good}
+	resp, err := CallFunctions(ctx, g, testFuncs, []Part{Text("hi")})
+	if err != nil || resp != good {
+		t.Errorf("CallFunctions() = %v, %v; want %v, nil", resp, err, good)
+	}
+
+	g.resp = &CallResponse{Calls: []FunctionCall{{Name: "rm"}}}
+	if _, err := CallFunctions(ctx, g, testFuncs, []Part{Text("hi")}); err == nil || !strings.Contains(err.Error(), "undeclared function") {
+		t.Errorf("CallFunctions() error = %v, want undeclared function", err)
+	}
+}
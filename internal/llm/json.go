@@ -0,0 +1,299 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// jsonAttempts is the number of times [GenerateJSON] asks for
+// a response before giving up on invalid output.
+const jsonAttempts = 3
+
+// ErrInvalidJSON is wrapped by the error returned by [GenerateJSON]
+// when the model never produced a valid response.
+var ErrInvalidJSON = errors.New("invalid JSON response")
+
+// GenerateJSON asks g for a JSON response to the prompt parts,
+// constrained by schema, and decodes the response into v,
+// which must be a non-nil pointer, typically to a struct.
+// If schema is nil, GenerateJSON uses the schema for v's type
+// returned by [SchemaFor].
+//
+// The schema is passed to g, which uses the JSON or function calling
+// mode of its provider to constrain the response, if it has one.
+// Because constraints are not always enforced, GenerateJSON also
+// checks the response against the schema (see [Schema.Validate]).
+// If the response is invalid, GenerateJSON asks again, showing the
+// model its previous response and what is wrong with it, up to a total
+// of three attempts; after that, it returns an error wrapping [ErrInvalidJSON].
+// Errors from g are returned immediately.
+func GenerateJSON(ctx context.Context, g ContentGenerator, schema *Schema, v any, parts []Part) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("llm.GenerateJSON: need non-nil pointer, have %T", v)
+	}
+	if schema == nil {
+		s, err := SchemaFor(v)
+		if err != nil {
+			return fmt.Errorf("llm.GenerateJSON: %w", err)
+		}
+		schema = s
+	}
+
+	prompt := slices.Clip(parts)
+	var err error
+	for range jsonAttempts {
+		var js string
+		js, err = g.GenerateContent(ctx, schema, prompt)
+		if err != nil {
+			return fmt.Errorf("llm.GenerateJSON: %w", err)
+		}
+		js = trimJSON(js)
+		if err = schema.Validate([]byte(js)); err == nil {
+			if err = json.Unmarshal([]byte(js), v); err == nil {
+				return nil
+			}
+		}
+		prompt = append(prompt,
+			Text("Your previous response was:\n"+js),
+			Text("That response is invalid: "+err.Error()+"\nRespond again, with only JSON that matches the schema."))
+	}
+	return fmt.Errorf("llm.GenerateJSON: %w after %d attempts: %w", ErrInvalidJSON, jsonAttempts, err)
+}
+
+// trimJSON removes the Markdown code fence that models
+// sometimes put around JSON output.
+func trimJSON(js string) string {
+	js = strings.TrimSpace(js)
+	if rest, ok := strings.CutPrefix(js, "```"); ok {
+		rest = strings.TrimPrefix(rest, "json")
+		if rest, ok := strings.CutSuffix(rest, "```"); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return js
+}
+
+// SchemaFor returns the schema for JSON values that decode into v,
+// which is typically a pointer to a struct.
+//
+// Struct fields are named as by [encoding/json], including the
+// effect of json tags. Fields tagged with "omitempty" or "omitzero"
+// are optional; all others are required.
+// A field's "description" tag becomes its schema's description,
+// and an "enum" tag, a comma-separated list, limits a string field
+// to the listed values. For example:
+//
+//	type response struct {
+//		Kind        string `json:"kind" enum:"bug,feature" description:"the kind of issue"`
+//		Explanation string `json:"explanation,omitempty"`
+//	}
+//
+// Pointers are nullable, except for v itself. Maps, interfaces and embedded structs
+// are not supported.
+func SchemaFor(v any) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, errors.New("no schema for nil")
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return schemaForType(t, nil)
+}
+
+// schemaForType returns the schema for t.
+// The seen types are those being converted, to detect recursion.
+func schemaForType(t reflect.Type, seen []reflect.Type) (*Schema, error) {
+	if slices.Contains(seen, t) {
+		return nil, fmt.Errorf("no schema for recursive type %s", t)
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		s.Nullable = true
+		return s, nil
+	case reflect.String:
+		return &Schema{Type: TypeString}, nil
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil, fmt.Errorf("no schema for byte slice type %s", t)
+		}
+		items, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: TypeArray, Items: items}, nil
+	case reflect.Struct:
+		seen = append(seen, t)
+		s := &Schema{Type: TypeObject, Properties: make(map[string]*Schema)}
+		for f := range fields(t) {
+			if f.Anonymous {
+				return nil, fmt.Errorf("no schema for embedded field %s.%s", t, f.Name)
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" {
+				name = f.Name
+			}
+			p, err := schemaForType(f.Type, seen)
+			if err != nil {
+				return nil, err
+			}
+			p.Description = f.Tag.Get("description")
+			if enum := f.Tag.Get("enum"); enum != "" {
+				if p.Type != TypeString {
+					return nil, fmt.Errorf("enum tag on non-string field %s.%s", t, f.Name)
+				}
+				p.Format = "enum"
+				p.Enum = strings.Split(enum, ",")
+			}
+			s.Properties[name] = p
+			if !slices.Contains(strings.Split(opts, ","), "omitempty") &&
+				!slices.Contains(strings.Split(opts, ","), "omitzero") {
+				s.Required = append(s.Required, name)
+			}
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("no schema for type %s", t)
+}
+
+// fields returns the fields of the struct type t
+// that are encoded by [encoding/json].
+func fields(t reflect.Type) iter.Seq[reflect.StructField] {
+	return func(yield func(reflect.StructField) bool) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous || f.Tag.Get("json") == "-" {
+				continue
+			}
+			if !yield(f) {
+				return
+			}
+		}
+	}
+}
+
+// Validate reports whether the JSON value js matches s.
+// It checks types, nullability, enums and required properties.
+// Properties not in s are allowed, as they are by [encoding/json].
+func (s *Schema) Validate(js []byte) error {
+	d := json.NewDecoder(bytes.NewReader(js))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return fmt.Errorf("not JSON: %w", err)
+	}
+	if d.More() {
+		return errors.New("not JSON: extra data after value")
+	}
+	return s.validate("value", v)
+}
+
+// validate checks v, a decoded JSON value at the given path, against s.
+func (s *Schema) validate(path string, v any) error {
+	if v == nil {
+		if s.Nullable || s.Type == TypeUnspecified {
+			return nil
+		}
+		return fmt.Errorf("%s is null", path)
+	}
+	bad := func() error {
+		return fmt.Errorf("%s is %s, want %s", path, jsonTypeOf(v), s.Type.jsonType())
+	}
+	switch s.Type {
+	case TypeString:
+		str, ok := v.(string)
+		if !ok {
+			return bad()
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s is %q, want one of %q", path, str, s.Enum)
+		}
+	case TypeBoolean:
+		if _, ok := v.(bool); !ok {
+			return bad()
+		}
+	case TypeNumber:
+		if _, ok := v.(json.Number); !ok {
+			return bad()
+		}
+	case TypeInteger:
+		n, ok := v.(json.Number)
+		if !ok {
+			return bad()
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s is %s, want integer", path, n)
+		}
+	case TypeArray:
+		a, ok := v.([]any)
+		if !ok {
+			return bad()
+		}
+		if s.Items != nil {
+			for i, x := range a {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), x); err != nil {
+					return err
+				}
+			}
+		}
+	case TypeObject:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return bad()
+		}
+		for _, name := range s.Required {
+			if _, ok := m[name]; !ok {
+				return fmt.Errorf("%s is missing property %q", path, name)
+			}
+		}
+		for name, p := range s.Properties {
+			if x, ok := m[name]; ok {
+				if err := p.validate(path+"."+name, x); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonTypeOf returns the JSON type of v,
+// a value decoded with [json.Decoder.UseNumber].
+func jsonTypeOf(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "null"
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testResponse struct {
+	Kind   string   `json:"kind" enum:"bug,feature" description:"the kind of issue"`
+	Score  float64  `json:"score"`
+	Labels []string `json:"labels,omitempty"`
+	Count  *int
+	hidden int
+}
+
+func TestSchemaFor(t *testing.T) {
+	s, err := SchemaFor(&testResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Schema{
+		Type: TypeObject,
+		Properties: map[string]*Schema{
+			"kind":   {Type: TypeString, Format: "enum", Enum: []string{"bug", "feature"}, Description: "the kind of issue"},
+			"score":  {Type: TypeNumber},
+			"labels": {Type: TypeArray, Items: &Schema{Type: TypeString}},
+			"Count":  {Type: TypeInteger, Nullable: true},
+		},
+		Required: []string{"kind", "score", "Count"},
+	}
+	if diff := cmp.Diff(want, s); diff != "" {
+		t.Errorf("SchemaFor mismatch (-want +got):\n%s", diff)
+	}
+
+	type recursive struct{ Next *recursive }
+	for _, v := range []any{nil, map[string]int{}, []byte{}, &recursive{}, struct{ testResponse }{}} {
+		if _, err := SchemaFor(v); err == nil {
+			t.Errorf("SchemaFor(%T) succeeded, want error", v)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s, err := SchemaFor(testResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		js  string
+		err string // substring of error; "" means valid
+	}{
+		{`{"kind":"bug","score":0.5,"Count":null}`, ""},
+		{`{"kind":"feature","score":1,"labels":["a"],"Count":3,"extra":true}`, ""},
+		{`{"kind":"bug","score":0.5}`, `missing property "Count"`},
+		{`{"kind":"other","score":0.5,"Count":1}`, `value.kind is "other"`},
+		{`{"kind":"bug","score":"high","Count":1}`, "value.score is string, want number"},
+		{`{"kind":"bug","score":1,"Count":1.5}`, "want integer"},
+		{`{"kind":"bug","score":1,"Count":1,"labels":[1]}`, "value.labels[0] is number"},
+		{`{"kind":null,"score":1,"Count":1}`, "value.kind is null"},
+		{`[]`, "value is array, want object"},
+		{`{"kind":`, "not JSON"},
+		{`{} {}`, "extra data"},
+	} {
+		err := s.Validate([]byte(tc.js))
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("Validate(%s) = %v, want %q", tc.js, err, tc.err)
+		}
+	}
+}
+
+func TestGenerateJSON(t *testing.T) {
+	ctx := context.Background()
+
+	responses := []string{
+		`{"kind":"question","score":1,"Count":1}`,
+		"```json\n{\"kind\":\"bug\",\"score\":0.25,\"Count\":2}\n```",
+	}
+	var prompts [][]Part
+	g := TestContentGenerator("test", func(_ context.Context, schema *Schema, parts []Part) (string, error) {
+		if schema.Properties["kind"] == nil {
+			t.Errorf("bad schema %+v", schema)
+		}
+		prompts = append(prompts, parts)
+		r := responses[0]
+		responses = responses[1:]
+		return r, nil
+	})
+	var res testResponse
+	if err := GenerateJSON(ctx, g, nil, &res, []Part{Text("classify")}); err != nil {
+		t.Fatal(err)
+	}
+	two := 2
+	if diff := cmp.Diff(testResponse{Kind: "bug", Score: 0.25, Count: &two}, res, cmp.AllowUnexported(testResponse{})); diff != "" {
+		t.Errorf("GenerateJSON mismatch (-want +got):\n%s", diff)
+	}
+	if len(prompts) != 2 || len(prompts[1]) != 3 || !strings.Contains(string(prompts[1][2].(Text)), `value.kind is "question"`) {
+		t.Errorf("retry prompt = %v", prompts)
+	}
+
+	// Give up after repeated invalid output.
+	g = TestContentGenerator("test", func(context.Context, *Schema, []Part) (string, error) {
+		return "not json", nil
+	})
+	if err := GenerateJSON(ctx, g, nil, &res, []Part{Text("classify")}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("invalid output: err = %v, want ErrInvalidJSON", err)
+	}
+
+	// Generator errors are not retried.
+	calls := 0
+	errGen := errors.New("unavailable")
+	g = TestContentGenerator("test", func(context.Context, *Schema, []Part) (string, error) {
+		calls++
+		return "", errGen
+	})
+	if err := GenerateJSON(ctx, g, nil, &res, nil); !errors.Is(err, errGen) || calls != 1 {
+		t.Errorf("generator error: err = %v, calls = %d, want %v, 1", err, calls, errGen)
+	}
+
+	if err := GenerateJSON(ctx, g, nil, res, nil); err == nil {
+		t.Errorf("GenerateJSON with non-pointer succeeded")
+	}
+}
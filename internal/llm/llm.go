@@ -108,3 +108,28 @@ type ContentGenerator interface {
 	// SetTemperature changes the temperature of the model.
 	SetTemperature(float32)
 }
+
+// A ModelReporter is a [ContentGenerator] that can use a different
+// model for each call, such as a [Failover].
+// GenerateContentModel is like GenerateContent, but also returns
+// the name of the model that generated the response.
+type ModelReporter interface {
+	ContentGenerator
+	GenerateContentModel(ctx context.Context, schema *Schema, parts []Part) (out, model string, err error)
+}
+
+// GenerateContentModel generates content using g, returning the response
+// and the name of the model that generated it.
+// If g is a [ModelReporter], the model is the one reported for this call;
+// otherwise it is g.Model().
+//
+// Generators that wrap another generator should implement
+// [ModelReporter] using GenerateContentModel, so that the model
+// of the wrapped generator is not hidden.
+func GenerateContentModel(ctx context.Context, g ContentGenerator, schema *Schema, parts []Part) (out, model string, err error) {
+	if mr, ok := g.(ModelReporter); ok {
+		return mr.GenerateContentModel(ctx, schema, parts)
+	}
+	out, err = g.GenerateContent(ctx, schema, parts)
+	return out, g.Model(), err
+}
@@ -5,6 +5,7 @@
 package llm
 
 import (
+	"encoding/json"
 	"slices"
 	"testing"
 )
@@ -24,3 +25,26 @@ func TestVector(t *testing.T) {
 		t.Errorf("Decode(Encode(%v)) = %v, want %v", v1, v3, v1)
 	}
 }
+
+func TestJSONSchema(t *testing.T) {
+	s := &Schema{
+		Type: TypeObject,
+		Properties: map[string]*Schema{
+			"kind":  {Type: TypeString, Format: "enum", Enum: []string{"a", "b"}},
+			"count": {Type: TypeInteger, Format: "int64", Nullable: true},
+			"tags":  {Type: TypeArray, Items: &Schema{Type: TypeString}, Description: "labels"},
+		},
+		Required: []string{"kind"},
+	}
+	js, err := json.Marshal(s.JSONSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"properties":{"count":{"format":"int64","type":["integer","null"]},` +
+		`"kind":{"enum":["a","b"],"type":"string"},` +
+		`"tags":{"description":"labels","items":{"type":"string"},"type":"array"}},` +
+		`"required":["kind"],"type":"object"}`
+	if string(js) != want {
+		t.Errorf("JSONSchema =\n%s\nwant\n%s", js, want)
+	}
+}
@@ -60,3 +60,63 @@ const (
 	// TypeObject means object type.
 	TypeObject Type = 6
 )
+
+// JSONSchema returns s as a JSON Schema value, suitable for
+// encoding with [encoding/json] in requests to LLM services
+// that accept standard JSON Schemas rather than OpenAPI schemas.
+func (s *Schema) JSONSchema() map[string]any {
+	if s == nil {
+		return nil
+	}
+	js := make(map[string]any)
+	if t := s.Type.jsonType(); t != "" {
+		if s.Nullable {
+			js["type"] = []string{t, "null"}
+		} else {
+			js["type"] = t
+		}
+	}
+	if s.Format != "" && s.Format != "enum" {
+		js["format"] = s.Format
+	}
+	if s.Description != "" {
+		js["description"] = s.Description
+	}
+	if len(s.Enum) > 0 {
+		js["enum"] = s.Enum
+	}
+	if s.Items != nil {
+		js["items"] = s.Items.JSONSchema()
+	}
+	if s.Type == TypeObject {
+		props := make(map[string]any)
+		for name, p := range s.Properties {
+			props[name] = p.JSONSchema()
+		}
+		js["properties"] = props
+		if len(s.Required) > 0 {
+			js["required"] = s.Required
+		}
+	}
+	return js
+}
+
+// jsonType returns the JSON Schema name for t,
+// or "" if t is [TypeUnspecified].
+func (t Type) jsonType() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeNumber:
+		return "number"
+	case TypeInteger:
+		return "integer"
+	case TypeBoolean:
+		return "boolean"
+	case TypeArray:
+		return "array"
+	case TypeObject:
+		return "object"
+	}
+	return ""
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 )
 
 const quoteLen = 123
@@ -120,8 +121,10 @@ func EchoTextResponse(promptParts ...Part) string {
 			echos = append(echos, string(p))
 		case Blob:
 			echos = append(echos, fmt.Sprintf("%s%d", p.MIMEType, i))
+		case FunctionCall, FunctionResponse:
+			echos = append(echos, string(TextParts([]Part{p})[0].(Text)))
 		default:
-			panic(fmt.Sprintf("bad type for part: %T; need llm.Text or llm.Blob.", p))
+			panic(fmt.Sprintf("bad type for part: %T; need llm.Text, llm.Blob, llm.FunctionCall or llm.FunctionResponse.", p))
 		}
 	}
 	return strings.Join(echos, "")
@@ -172,3 +175,48 @@ func (g *generator) GenerateContent(ctx context.Context, schema *Schema, promptP
 	}
 	return g.generateContent(ctx, schema, promptParts)
 }
+
+// TestBatchContentGenerator returns a [BatchContentGenerator]
+// that wraps g. Submitted batches are run synchronously using g
+// the first time their result is requested.
+//
+// For testing.
+func TestBatchContentGenerator(g ContentGenerator) BatchContentGenerator {
+	return &batcher{ContentGenerator: g, batches: make(map[string][]*BatchRequest)}
+}
+
+// batcher is a test implementation of [BatchContentGenerator].
+type batcher struct {
+	ContentGenerator
+	mu      sync.Mutex
+	batches map[string][]*BatchRequest
+}
+
+// SubmitBatch implements [BatchContentGenerator.SubmitBatch].
+func (b *batcher) SubmitBatch(ctx context.Context, reqs []*BatchRequest) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := fmt.Sprintf("batch-%d", len(b.batches)+1)
+	b.batches[id] = reqs
+	return id, nil
+}
+
+// BatchResult implements [BatchContentGenerator.BatchResult].
+func (b *batcher) BatchResult(ctx context.Context, id string) (*BatchResult, error) {
+	b.mu.Lock()
+	reqs, ok := b.batches[id]
+	b.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("BatchResult: unknown batch %q", id)
+	}
+	r := &BatchResult{State: BatchDone}
+	for _, req := range reqs {
+		text, err := b.GenerateContent(ctx, req.Schema, req.Parts)
+		if err != nil {
+			r.Responses = append(r.Responses, BatchResponse{Error: err.Error()})
+			continue
+		}
+		r.Responses = append(r.Responses, BatchResponse{Text: text})
+	}
+	return r, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"encoding/json"
+	"unicode"
+	"unicode/utf8"
+)
+
+// blobTokens is the number of tokens counted for each [Blob].
+// Gemini counts every image as 258 tokens; other models
+// count small images similarly.
+const blobTokens = 258
+
+// EstimateTokens returns an estimate of the number of tokens
+// that an LLM would use for the prompt parts.
+//
+// The estimate follows the pre-tokenization rules of typical
+// byte-pair encoding tokenizers: text is split into words, numbers,
+// punctuation and whitespace, and words and numbers longer than
+// a typical token are counted as several tokens.
+// It tends to overestimate rather than underestimate,
+// so that a prompt estimated to fit in a context window does fit.
+func EstimateTokens(parts []Part) int {
+	n := 0
+	for _, p := range parts {
+		switch p := p.(type) {
+		case Text:
+			n += estimateTextTokens(string(p))
+		case Blob:
+			n += blobTokens
+		case FunctionCall:
+			n += estimateTextTokens(p.Name) + estimateJSONTokens(p.Args)
+		case FunctionResponse:
+			n += estimateTextTokens(p.Name) + estimateJSONTokens(p.Response)
+		}
+	}
+	return n
+}
+
+// estimateJSONTokens returns the estimated number of tokens
+// in the JSON encoding of v.
+func estimateJSONTokens(v any) int {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return estimateTextTokens(string(js))
+}
+
+// Typical lengths of tokens for runs of letters and digits.
+const (
+	letterTokenLen = 4
+	digitTokenLen  = 3
+)
+
+// estimateTextTokens returns the estimated number of tokens in s.
+func estimateTextTokens(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || r == '\''):
+			j := i
+			for j < len(s) && s[j] < utf8.RuneSelf && (unicode.IsLetter(rune(s[j])) || s[j] == '\'') {
+				j++
+			}
+			n += (j - i + letterTokenLen - 1) / letterTokenLen
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(s) && '0' <= s[j] && s[j] <= '9' {
+				j++
+			}
+			if j == i {
+				j = i + size // non-ASCII digit
+			}
+			n += (j - i + digitTokenLen - 1) / digitTokenLen
+			i = j
+		case r == ' ':
+			// A single space before a word is part of the word's token.
+			j := i
+			for j < len(s) && s[j] == ' ' {
+				j++
+			}
+			if j-i > 1 || j == len(s) || !isWordStart(s[j:]) {
+				n++
+			}
+			i = j
+		case unicode.IsSpace(r):
+			j := i
+			for j < len(s) && (s[j] == '\n' || s[j] == '\t' || s[j] == '\r') {
+				j++
+			}
+			if j == i {
+				j = i + size // non-ASCII space
+			}
+			n++
+			i = j
+		default:
+			// Punctuation, symbols and non-ASCII letters,
+			// which are typically one or more tokens each.
+			n++
+			i += size
+		}
+	}
+	return n
+}
+
+// isWordStart reports whether s begins with a letter or digit.
+func isWordStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,37 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	for _, tt := range []struct {
+		parts []Part
+		want  int
+	}{
+		{nil, 0},
+		{[]Part{Text("")}, 0},
+		{[]Part{Text("hello world")}, 4},  // two per five-letter word; the space joins "world"
+		{[]Part{Text("a b c")}, 3},        // spaces join the words
+		{[]Part{Text("x = 12345;\n")}, 7}, // x, " ", =, 123, 45, ;, \n
+		{[]Part{Text("don't")}, 2},        // don', t
+		{[]Part{Text("日本語")}, 3},          // one per rune
+		{[]Part{Text("a"), Blob{MIMEType: "image/png"}}, 1 + blobTokens},
+		{[]Part{FunctionCall{Name: "f", Args: map[string]any{"x": 1}}}, 1 + 7}, // f, {"x":1}
+	} {
+		if got := EstimateTokens(tt.parts); got != tt.want {
+			t.Errorf("EstimateTokens(%v) = %d, want %d", tt.parts, got, tt.want)
+		}
+	}
+
+	// English prose is about 4 bytes per token.
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+	if got := EstimateTokens([]Part{Text(text)}); got < len(text)/6 || got > len(text)/3 {
+		t.Errorf("EstimateTokens(prose of %d bytes) = %d, want about %d", len(text), got, len(text)/4)
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A Tool is an operation that an [Agent] can ask to invoke.
+// Tools should be read-only: they look things up or run
+// experiments, but they must not change any external state,
+// since an agent's choice of tools is not reviewed before they run.
+type Tool struct {
+	// Name identifies the tool to the LLM, for example "search".
+	Name string
+	// Description tells the LLM what the tool does
+	// and when to use it.
+	Description string
+	// Params maps the name of each of the tool's parameters
+	// to a description of it. All parameters are strings.
+	Params map[string]string
+	// Run runs the tool with the given arguments
+	// and returns its output, which is shown to the LLM.
+	// Arguments the LLM did not provide are empty strings.
+	Run func(ctx context.Context, args map[string]string) (string, error)
+}
+
+// An Agent uses the LLM to complete a task in multiple steps,
+// invoking its tools and reading their outputs
+// until it is ready to answer.
+// Every step is recorded in the [AgentResult], so that the
+// path to an answer can be audited.
+//
+// An Agent is created by [Client.NewAgent].
+type Agent struct {
+	c        *Client
+	tools    []*Tool
+	maxSteps int
+}
+
+// DefaultMaxSteps is the default limit on the number of
+// tool invocations an [Agent] makes for a single task.
+const DefaultMaxSteps = 10
+
+// maxToolOutput is the number of bytes of each tool output
+// that are shown to the LLM.
+const maxToolOutput = 8 << 10
+
+// ErrStepLimit is returned by [Agent.Run] when the LLM
+// does not answer within the agent's step limit.
+var ErrStepLimit = errors.New("llmapp agent: step limit reached without an answer")
+
+// NewAgent returns a new agent that uses c's LLM and the given tools.
+// The tools' names must be distinct.
+func (c *Client) NewAgent(tools ...*Tool) *Agent {
+	for i, t := range tools {
+		if slices.ContainsFunc(tools[:i], func(u *Tool) bool { return u.Name == t.Name }) {
+			panic("llmapp NewAgent: duplicate tool " + t.Name)
+		}
+	}
+	return &Agent{c: c, tools: tools, maxSteps: DefaultMaxSteps}
+}
+
+// SetMaxSteps sets the maximum number of tool invocations
+// the agent makes for a single task (default [DefaultMaxSteps]).
+func (a *Agent) SetMaxSteps(n int) {
+	a.maxSteps = n
+}
+
+// AgentResult is the result of [Agent.Run].
+type AgentResult struct {
+	Task   string       // the task given to the agent
+	Answer string       // the LLM's final answer; empty if there is none
+	Steps  []*AgentStep // every step taken, in order, including the final answer
+}
+
+// An AgentStep is a single step taken by an [Agent]:
+// a tool invocation, or the final answer.
+type AgentStep struct {
+	Thought string            // the LLM's reasoning for the step
+	Tool    string            // the tool invoked; empty for the final answer
+	Args    map[string]string // the tool's arguments
+	Output  string            // the tool's output, as shown to the LLM
+	Error   string            // the tool's error, if any
+	LLM     *Result           // the LLM call that chose the step
+}
+
+// agentAction represents the desired JSON structure of the LLM output
+// for each step of an [Agent].
+//
+// IMPORTANT: If you edit the types or JSON names of fields in this
+// struct, edit [Agent.schema] accordingly.
+type agentAction struct {
+	Thought string     `json:"thought"`
+	Tool    string     `json:"tool"`
+	Args    []agentArg `json:"args"`
+	Answer  string     `json:"answer"`
+}
+
+type agentArg struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// schema returns the JSON schema for a step of the agent.
+func (a *Agent) schema() *llm.Schema {
+	names := []string{""}
+	for _, t := range a.tools {
+		names = append(names, t.Name)
+	}
+	return &llm.Schema{
+		Type: llm.TypeObject,
+		Properties: map[string]*llm.Schema{
+			"thought": {
+				Type:        llm.TypeString,
+				Description: "Your reasoning about what to do next.",
+			},
+			"tool": {
+				Type:        llm.TypeString,
+				Enum:        names,
+				Description: "The name of the tool to invoke next, or the empty string to give the final answer.",
+			},
+			"args": {
+				Type: llm.TypeArray,
+				Items: &llm.Schema{
+					Type: llm.TypeObject,
+					Properties: map[string]*llm.Schema{
+						"name":  {Type: llm.TypeString},
+						"value": {Type: llm.TypeString},
+					},
+					Required: []string{"name", "value"},
+				},
+				Description: "The arguments to the tool.",
+			},
+			"answer": {
+				Type:        llm.TypeString,
+				Description: "The final answer to the task, styled with markdown, if tool is empty.",
+			},
+		},
+		Required: []string{"thought", "tool"},
+	}
+}
+
+// Run asks the agent to complete the task.
+// At each step, the LLM is shown the task, the tools,
+// and all the previous steps, and either picks a tool to invoke
+// or gives its final answer.
+// Tool errors are shown to the LLM, which may try something else.
+//
+// Run returns an error if the LLM is unable to generate a
+// well-formed step. If the LLM does not answer within the
+// step limit, Run returns the result so far along with [ErrStepLimit].
+func (a *Agent) Run(ctx context.Context, task string) (*AgentResult, error) {
+	res := &AgentResult{Task: task}
+	for i := 0; ; i++ {
+		last := i >= a.maxSteps
+		r, err := a.step(ctx, task, res.Steps, last)
+		if err != nil {
+			return nil, fmt.Errorf("llmapp agent step %d: %w", i, err)
+		}
+		var act agentAction
+		if err := json.Unmarshal([]byte(r.Response), &act); err != nil {
+			return nil, fmt.Errorf("llmapp agent step %d: cannot unmarshal response: %w\nresponse: %s", i, err, r.Response)
+		}
+		s := &AgentStep{Thought: act.Thought, Tool: act.Tool, LLM: r}
+		res.Steps = append(res.Steps, s)
+		if act.Tool == "" {
+			a.c.slog.Info("llmapp agent answer", "step", i, "cached", r.Cached)
+			res.Answer = act.Answer
+			return res, nil
+		}
+		if last {
+			return res, ErrStepLimit
+		}
+
+		s.Args = make(map[string]string)
+		for _, arg := range act.Args {
+			s.Args[arg.Name] = arg.Value
+		}
+		a.c.slog.Info("llmapp agent step", "step", i, "tool", s.Tool, "args", s.Args, "cached", r.Cached)
+		out, err := a.run(ctx, s.Tool, s.Args)
+		if err != nil {
+			s.Error = err.Error()
+			a.c.slog.Info("llmapp agent tool error", "step", i, "tool", s.Tool, "err", err)
+		}
+		if len(out) > maxToolOutput {
+			out = out[:maxToolOutput] + "\n[output truncated]"
+		}
+		s.Output = out
+	}
+}
+
+// run runs the named tool with the given arguments.
+func (a *Agent) run(ctx context.Context, name string, args map[string]string) (string, error) {
+	i := slices.IndexFunc(a.tools, func(t *Tool) bool { return t.Name == name })
+	if i < 0 {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	t := a.tools[i]
+	for name := range args {
+		if _, ok := t.Params[name]; !ok {
+			return "", fmt.Errorf("tool %s has no parameter %q", t.Name, name)
+		}
+	}
+	return t.Run(ctx, args)
+}
+
+// step asks the LLM for the next step of the task, given the steps so far.
+// If last is true, the LLM is told that it must answer now.
+func (a *Agent) step(ctx context.Context, task string, steps []*AgentStep, last bool) (*Result, error) {
+	type toolJSON struct {
+		Name        string
+		Description string
+		Params      map[string]string
+	}
+	var tools []toolJSON
+	for _, t := range a.tools {
+		tools = append(tools, toolJSON{t.Name, t.Description, t.Params})
+	}
+	prompt := []llm.Part{
+		untrustedInstructions(),
+		llm.Text("task"),
+		llm.Text(task),
+		llm.Text("tools"),
+		llm.Text(storage.JSON(tools)),
+	}
+	for i, s := range steps {
+		type stepJSON struct {
+			Thought string
+			Tool    string
+			Args    map[string]string `json:",omitempty"`
+			Output  string            `json:",omitempty"`
+			Error   string            `json:",omitempty"`
+		}
+		prompt = append(prompt,
+			llm.Text(fmt.Sprintf("step %d", i+1)),
+			llm.Text(storage.JSON(stepJSON{s.Thought, s.Tool, s.Args, neutralize(s.Output), s.Error})))
+	}
+	prompt = append(prompt, llm.Text(agentInstructions(last)))
+
+	schema := a.schema()
+	resp, cached, err := a.c.generate(ctx, a.version(), schema, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Response:         resp,
+		Cached:           cached,
+		Schema:           schema,
+		Prompt:           prompt,
+		Model:            a.c.g.Model(),
+		PromptVersion:    a.version(),
+		PolicyEvaluation: a.c.EvaluatePolicy(ctx, prompt, resp),
+	}, nil
+}
+
+// agentInstructions returns the instruction prompt for a step of an agent.
+func agentInstructions(last bool) string {
+	w := &strings.Builder{}
+	if err := tmpls.ExecuteTemplate(w, "agent", last); err != nil {
+		// unreachable except bug in this package
+		panic(err)
+	}
+	return w.String()
+}
+
+// version returns a short string identifying the instructions
+// and schema for the agent's steps.
+func (a *Agent) version() string {
+	h := sha256.New()
+	h.Write([]byte(untrustedInstructions()))
+	h.Write([]byte(agentInstructions(false)))
+	writeObjectToHash(h, a.schema())
+	return fmt.Sprintf("%x", h.Sum(nil))[:12]
+}
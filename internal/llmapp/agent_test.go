@@ -0,0 +1,140 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestAgent(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+
+	// script returns a generator that responds with the given
+	// responses in order, recording the last prompt.
+	var lastPrompt []llm.Part
+	script := func(responses ...string) llm.ContentGenerator {
+		return llm.TestContentGenerator("agent-test-generator",
+			func(_ context.Context, _ *llm.Schema, parts []llm.Part) (string, error) {
+				lastPrompt = parts
+				if len(responses) == 0 {
+					return "", errors.New("no more responses")
+				}
+				r := responses[0]
+				responses = responses[1:]
+				return r, nil
+			})
+	}
+	var calls []string
+	lookup := &Tool{
+		Name:        "lookup",
+		Description: "Look up an issue by number.",
+		Params:      map[string]string{"number": "the issue number"},
+		Run: func(_ context.Context, args map[string]string) (string, error) {
+			calls = append(calls, args["number"])
+			if args["number"] == "0" {
+				return "", errors.New("no such issue")
+			}
+			return "issue " + args["number"] + " is about " + strings.Repeat("x", maxToolOutput), nil
+		},
+	}
+
+	t.Run("answer", func(t *testing.T) {
+		calls = nil
+		c := New(lg, script(
+			`{"thought":"look it up","tool":"lookup","args":[{"name":"number","value":"0"}]}`,
+			`{"thought":"try again","tool":"lookup","args":[{"name":"number","value":"1"}]}`,
+			`{"thought":"done","tool":"","answer":"It is about x."}`,
+		), storage.MemDB())
+		a := c.NewAgent(lookup)
+		res, err := a.Run(ctx, "What is issue 1 about?")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Answer != "It is about x." {
+			t.Errorf("Answer = %q, want %q", res.Answer, "It is about x.")
+		}
+		if want := []string{"0", "1"}; !cmp.Equal(calls, want) {
+			t.Errorf("tool calls = %v, want %v", calls, want)
+		}
+		if len(res.Steps) != 3 {
+			t.Fatalf("got %d steps, want 3", len(res.Steps))
+		}
+		if s := res.Steps[0]; s.Error != "no such issue" || s.Output != "" {
+			t.Errorf("step 1 = %+v, want error", s)
+		}
+		if s := res.Steps[1]; s.Error != "" || !strings.HasSuffix(s.Output, "[output truncated]") {
+			t.Errorf("step 2 output not truncated: %q", s.Output[len(s.Output)-40:])
+		}
+		if s := res.Steps[2]; s.Tool != "" || s.Thought != "done" || s.LLM.PromptVersion != a.version() {
+			t.Errorf("step 3 = %+v", s)
+		}
+		// The final prompt includes the earlier steps.
+		var prompt []string
+		for _, p := range lastPrompt {
+			prompt = append(prompt, string(p.(llm.Text)))
+		}
+		if !strings.Contains(strings.Join(prompt, "\n"), `"Error":"no such issue"`) {
+			t.Errorf("final prompt does not include the tool error:\n%s", prompt)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		step := `{"thought":"again","tool":"lookup","args":[{"name":"number","value":"2"}]}`
+		c := New(lg, script(step, step, step), storage.MemDB())
+		a := c.NewAgent(lookup)
+		a.SetMaxSteps(2)
+		res, err := a.Run(ctx, "Loop forever.")
+		if !errors.Is(err, ErrStepLimit) {
+			t.Fatalf("Run() error = %v, want ErrStepLimit", err)
+		}
+		if len(res.Steps) != 3 || res.Answer != "" {
+			t.Errorf("got %d steps, answer %q; want 3 steps and no answer", len(res.Steps), res.Answer)
+		}
+		if !strings.Contains(string(lastPrompt[len(lastPrompt)-1].(llm.Text)), "step limit") {
+			t.Errorf("last prompt does not mention the step limit")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, resp := range []string{
+			`{"thought":"x","tool":"missing"}`,
+			`{"thought":"x","tool":"lookup","args":[{"name":"bad","value":"1"}]}`,
+		} {
+			c := New(lg, script(resp, `{"thought":"ok","tool":"","answer":"a"}`), storage.MemDB())
+			res, err := c.NewAgent(lookup).Run(ctx, "task")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Steps[0].Error == "" {
+				t.Errorf("response %s: no tool error", resp)
+			}
+		}
+
+		c := New(lg, script(`not json`), storage.MemDB())
+		if _, err := c.NewAgent(lookup).Run(ctx, "task"); err == nil {
+			t.Error("Run() with malformed response succeeded, want error")
+		}
+		c = New(lg, script(), storage.MemDB())
+		if _, err := c.NewAgent(lookup).Run(ctx, "task"); err == nil {
+			t.Error("Run() with failing LLM succeeded, want error")
+		}
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewAgent with duplicate tools did not panic")
+		}
+	}()
+	New(lg, script(), storage.MemDB()).NewAgent(lookup, &Tool{Name: "lookup"})
+}
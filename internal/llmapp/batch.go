@@ -0,0 +1,240 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// ErrBatched is the error returned by a batching [Client]
+// (see [Client.NewBatch]) for a request that was not in the cache
+// and has instead been added to the batch.
+var ErrBatched = errors.New("llmapp: request added to batch")
+
+// A Batch collects LLM requests to submit together
+// to a [llm.BatchContentGenerator].
+//
+// Batches are meant for backfills, such as pre-generating overviews
+// for many issues: run the backfill once with the batching client to
+// collect requests, submit the batch, wait for it with [Client.PollBatch],
+// and then run the backfill again with the ordinary client, which
+// finds every response in the cache.
+type Batch struct {
+	c *Client // the non-batching client
+
+	mu   sync.Mutex
+	seen map[string]bool // cache keys already in the batch
+	reqs []*llm.BatchRequest
+	keys [][]byte // cache keys, parallel to reqs
+}
+
+// NewBatch returns a new batch and a client that adds requests to it.
+// The returned client answers requests from the cache as usual,
+// but on a cache miss it adds the request to the batch and returns
+// an error wrapping [ErrBatched] instead of calling the LLM.
+func (c *Client) NewBatch() (*Client, *Batch) {
+	b := &Batch{c: c, seen: make(map[string]bool)}
+	bc := *c
+	bc.batch = b
+	if c.batcher != nil {
+		// Cache the responses under the model that generates them.
+		bc.g = c.batcher
+	}
+	return &bc, b
+}
+
+// SetBatchGenerator sets the generator used to submit and poll batches.
+// By default, batches use the client's own content generator, which must
+// implement [llm.BatchContentGenerator]. SetBatchGenerator allows
+// batching when that generator wraps one that does, as a failover
+// or metering generator might. The client's generator should normally
+// use the same model as bg, so that it finds the batched responses
+// in the cache.
+// Clients derived from c after the call use the same batch generator.
+func (c *Client) SetBatchGenerator(bg llm.BatchContentGenerator) {
+	c.batcher = bg
+}
+
+// batchGenerator returns the generator to use for batches.
+func (c *Client) batchGenerator() (llm.BatchContentGenerator, bool) {
+	if c.batcher != nil {
+		return c.batcher, true
+	}
+	bg, ok := c.g.(llm.BatchContentGenerator)
+	return bg, ok
+}
+
+// add adds a request with the given cache key to the batch,
+// unless an identical request is already present.
+func (b *Batch) add(key, hash []byte, schema *llm.Schema, parts []llm.Part) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.seen[string(key)] {
+		return
+	}
+	b.seen[string(key)] = true
+	b.reqs = append(b.reqs, &llm.BatchRequest{Schema: schema, Parts: parts})
+	b.keys = append(b.keys, key)
+}
+
+// Len returns the number of requests in the batch.
+func (b *Batch) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.reqs)
+}
+
+// A BatchJob records the progress of a submitted [Batch].
+type BatchJob struct {
+	ID        string         // identifier assigned by the generator
+	Model     string         // generative model
+	Created   time.Time      // time the batch was submitted
+	Updated   time.Time      // time of the last state change
+	State     llm.BatchState // current state
+	Error     string         // reason the batch failed, if State is [llm.BatchFailed]
+	Requests  int            // number of requests in the batch
+	Succeeded int            // number of responses stored in the cache
+	Failed    int            // number of requests that failed
+
+	CacheKeys [][]byte // cache keys of the requests, in order
+}
+
+// Submit submits the batch for asynchronous generation and
+// records the new job in the database.
+// It returns an error if the batch is empty or the client has
+// no batch generator (see [Client.SetBatchGenerator]).
+func (b *Batch) Submit(ctx context.Context) (*BatchJob, error) {
+	bg, ok := b.c.batchGenerator()
+	if !ok {
+		return nil, fmt.Errorf("llmapp: model %s does not support batch generation", b.c.g.Model())
+	}
+	b.mu.Lock()
+	reqs, keys := b.reqs, b.keys
+	b.mu.Unlock()
+	if len(reqs) == 0 {
+		return nil, errors.New("llmapp: empty batch")
+	}
+	id, err := bg.SubmitBatch(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	job := &BatchJob{
+		ID:        id,
+		Model:     bg.Model(),
+		Created:   now,
+		Updated:   now,
+		State:     llm.BatchRunning,
+		Requests:  len(reqs),
+		CacheKeys: keys,
+	}
+	b.c.setBatchJob(job)
+	b.c.slog.Info("llmapp: submitted batch", "id", id, "model", job.Model, "requests", job.Requests)
+	return job, nil
+}
+
+// PollBatch checks on the batch job with the given id.
+// If the job has completed, PollBatch stores each successful response
+// in the cache, so that later requests for the same prompts
+// do not call the LLM, and marks the job done.
+// PollBatch returns the updated job.
+func (c *Client) PollBatch(ctx context.Context, id string) (*BatchJob, error) {
+	job, ok := c.BatchJob(id)
+	if !ok {
+		return nil, fmt.Errorf("llmapp: unknown batch job %q", id)
+	}
+	if job.State != llm.BatchRunning {
+		return job, nil
+	}
+	bg, ok := c.batchGenerator()
+	if !ok || bg.Model() != job.Model {
+		return nil, fmt.Errorf("llmapp: batch job %q needs batch generation with model %s", id, job.Model)
+	}
+	r, err := bg.BatchResult(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch r.State {
+	case llm.BatchRunning:
+		return job, nil
+	case llm.BatchFailed:
+		job.State = llm.BatchFailed
+		job.Error = r.Error
+	case llm.BatchDone:
+		if len(r.Responses) != len(job.CacheKeys) {
+			return nil, fmt.Errorf("llmapp: batch job %q: got %d responses, want %d", id, len(r.Responses), len(job.CacheKeys))
+		}
+		b := c.db.Batch()
+		for i, resp := range r.Responses {
+			if resp.Error != "" {
+				job.Failed++
+				c.slog.Warn("llmapp: batch request failed", "id", id, "request", i, "err", resp.Error)
+				continue
+			}
+			key := job.CacheKeys[i]
+			var kind, version, model string
+			var hash []byte
+			if err := ordered.Decode(key, &kind, &version, &model, &hash); err != nil {
+				// Jobs submitted before prompt versions were in the keys.
+				if err := ordered.Decode(key, &kind, &model, &hash); err != nil {
+					return nil, err
+				}
+			}
+			b.Set(key, storage.JSON(responseGenerateContent{
+				Model:      job.Model,
+				Version:    version,
+				Time:       time.Now(),
+				PromptHash: hash,
+				Response:   resp.Text,
+			}))
+			job.Succeeded++
+			b.MaybeApply()
+		}
+		b.Apply()
+		job.State = llm.BatchDone
+	default:
+		return nil, fmt.Errorf("llmapp: batch job %q: unknown state %q", id, r.State)
+	}
+	job.Updated = time.Now()
+	c.setBatchJob(job)
+	c.slog.Info("llmapp: batch finished", "id", id, "state", job.State, "succeeded", job.Succeeded, "failed", job.Failed)
+	return job, nil
+}
+
+// BatchJob returns the batch job with the given id.
+func (c *Client) BatchJob(id string) (*BatchJob, bool) {
+	job := load[BatchJob](c, ordered.Encode(batchKind, id))
+	return job, job != nil
+}
+
+// BatchJobs returns an iterator over all batch jobs, in order of id.
+func (c *Client) BatchJobs() iter.Seq[*BatchJob] {
+	return func(yield func(*BatchJob) bool) {
+		for _, getVal := range c.db.Scan(ordered.Encode(batchKind), ordered.Encode(batchKind, ordered.Inf)) {
+			var job BatchJob
+			if err := json.Unmarshal(getVal(), &job); err != nil {
+				c.db.Panic("llmapp: cannot unmarshal batch job", "err", err)
+			}
+			if !yield(&job) {
+				return
+			}
+		}
+	}
+}
+
+// setBatchJob stores job in the database.
+func (c *Client) setBatchJob(job *BatchJob) {
+	c.db.Set(ordered.Encode(batchKind, job.ID), storage.JSON(job))
+}
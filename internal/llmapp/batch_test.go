@@ -0,0 +1,126 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+
+	calls := 0
+	g := llm.TestBatchContentGenerator(llm.TestContentGenerator("batch-test-generator",
+		func(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+			calls++
+			return llm.EchoTextResponse(parts...), nil
+		}))
+	c := New(lg, g, db)
+
+	// Cache one overview ahead of time.
+	if _, err := c.Overview(ctx, doc1); err != nil {
+		t.Fatal(err)
+	}
+	calls = 0
+
+	bc, b := c.NewBatch()
+	if _, err := bc.Overview(ctx, doc1); err != nil {
+		t.Fatalf("cached Overview: %v", err)
+	}
+	for range 2 {
+		if _, err := bc.Overview(ctx, doc2); !errors.Is(err, ErrBatched) {
+			t.Fatalf("uncached Overview: got %v, want ErrBatched", err)
+		}
+	}
+	if b.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", b.Len())
+	}
+	if calls != 0 {
+		t.Fatalf("batching client called LLM %d times", calls)
+	}
+
+	job, err := b.Submit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != llm.BatchRunning || job.Requests != 1 {
+		t.Fatalf("Submit() = %+v, want 1 running request", job)
+	}
+
+	job, err = c.PollBatch(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != llm.BatchDone || job.Succeeded != 1 || job.Failed != 0 {
+		t.Fatalf("PollBatch() = %+v, want 1 succeeded", job)
+	}
+	calls = 0
+
+	r, err := c.Overview(ctx, doc2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Cached || calls != 0 {
+		t.Errorf("Overview after batch: cached=%v, calls=%d; want cached with no calls", r.Cached, calls)
+	}
+
+	var ids []string
+	for j := range c.BatchJobs() {
+		ids = append(ids, j.ID)
+	}
+	if want := []string{job.ID}; !slices.Equal(ids, want) {
+		t.Errorf("BatchJobs() = %v, want %v", ids, want)
+	}
+}
+
+func TestBatchUnsupported(t *testing.T) {
+	c := New(testutil.Slogger(t), llm.EchoContentGenerator(), storage.MemDB())
+	bc, b := c.NewBatch()
+	if _, err := bc.Overview(context.Background(), doc1); !errors.Is(err, ErrBatched) {
+		t.Fatalf("Overview: got %v, want ErrBatched", err)
+	}
+	if _, err := b.Submit(context.Background()); err == nil {
+		t.Error("Submit with non-batch generator succeeded, want error")
+	}
+}
+
+func TestSetBatchGenerator(t *testing.T) {
+	ctx := context.Background()
+	echo := func(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+		return llm.EchoTextResponse(parts...), nil
+	}
+	calls := 0
+	g := llm.TestContentGenerator("batch-test-generator",
+		func(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+			calls++
+			return echo(ctx, schema, parts)
+		})
+	c := New(testutil.Slogger(t), g, storage.MemDB())
+	c.SetBatchGenerator(llm.TestBatchContentGenerator(llm.TestContentGenerator("batch-test-generator", echo)))
+
+	bc, b := c.NewBatch()
+	if _, err := bc.Overview(ctx, doc1); !errors.Is(err, ErrBatched) {
+		t.Fatalf("Overview: got %v, want ErrBatched", err)
+	}
+	job, err := b.Submit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job, err = c.PollBatch(ctx, job.ID); err != nil || job.Succeeded != 1 {
+		t.Fatalf("PollBatch() = %+v, %v, want 1 succeeded", job, err)
+	}
+	if r, err := c.Overview(ctx, doc1); err != nil || !r.Cached || calls != 0 {
+		t.Errorf("Overview after batch: %v, calls=%d; want cached with no calls", err, calls)
+	}
+}
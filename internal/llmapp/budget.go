@@ -0,0 +1,220 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/oscar/internal/llm"
+)
+
+// DefaultContextWindow is the size, in tokens, of the context window
+// assumed for a Client's model unless set with [Client.SetContextWindow].
+// It is smaller than the windows of most current models, so that
+// the default is safe for any of them.
+const DefaultContextWindow = 128_000
+
+// responseTokens is the number of tokens of the context window
+// reserved for the model's response.
+const responseTokens = 8_192
+
+// SetContextWindow sets the size, in tokens, of the context window
+// of the Client's model. Overviews of discussions whose prompts
+// are estimated (using [llm.EstimateTokens]) not to fit in the window
+// are generated in stages: the comments are summarized in batches
+// that fit, batches of those summaries are summarized in turn
+// if necessary, and the overview is generated from the summaries.
+func (c *Client) SetContextWindow(tokens int) {
+	c.contextWindow = tokens
+}
+
+// inputBudget returns the maximum number of tokens in a prompt.
+func (c *Client) inputBudget() int {
+	w := c.contextWindow
+	if w <= 0 {
+		w = DefaultContextWindow
+	}
+	return max(w-responseTokens, w/2)
+}
+
+// reducible reports whether the documents of kind k can be summarized
+// in batches when they do not fit in the context window.
+// For all kinds but [documents], the first group of documents
+// (such as the post) is context for the others and is kept.
+func (k docsKind) reducible() bool {
+	switch k {
+	case documents, postAndComments, postAndCommentsUpdated, pullRequest:
+		return true
+	}
+	return false
+}
+
+// fit returns the groups of documents for an overview of the given kind,
+// with instructions instr, reduced as needed so that the prompt fits
+// in the client's context window (see [Client.SetContextWindow]).
+// Documents larger than half the window are truncated, and then
+// the largest group is repeatedly replaced by summaries of batches
+// of its documents until the prompt fits.
+func (c *Client) fit(ctx context.Context, kind docsKind, instr string, groups []*docGroup) ([]*docGroup, error) {
+	budget := c.inputBudget()
+	if !kind.reducible() || llm.EstimateTokens(prompt(instr, groups)) <= budget {
+		return groups, nil
+	}
+
+	var kept []*docGroup // groups kept as context for each batch
+	if kind != documents {
+		kept, groups = groups[:1], groups[1:]
+	}
+	truncate := func(gs []*docGroup) []*docGroup {
+		var out []*docGroup
+		for _, g := range gs {
+			out = append(out, &docGroup{label: g.label, docs: truncateDocs(g.docs, budget/2)})
+		}
+		return out
+	}
+	kept, groups = truncate(kept), truncate(groups)
+	all := func() []*docGroup { return slices.Concat(kept, groups) }
+
+	batchBudget := budget - llm.EstimateTokens(prompt(docsChunk.instructions(), kept))
+	for {
+		tokens := llm.EstimateTokens(prompt(instr, all()))
+		if tokens <= budget {
+			break
+		}
+		i := largestGroup(groups)
+		if i < 0 {
+			break
+		}
+		g, err := c.summarizeGroup(ctx, kept, groups[i], batchBudget)
+		if err != nil {
+			return nil, err
+		}
+		old := groups[i]
+		groups[i] = g
+		if llm.EstimateTokens(prompt(instr, all())) >= tokens {
+			// No progress: summaries are as long as the documents.
+			groups[i] = old
+			c.slog.Warn("llmapp: documents do not fit in context window", "kind", kind, "tokens", tokens, "budget", budget)
+			break
+		}
+	}
+	return all(), nil
+}
+
+// summarizeGroup returns a group holding summaries of batches of the
+// documents in g, each batch summarized after the kept groups
+// in a prompt of at most budget tokens.
+func (c *Client) summarizeGroup(ctx context.Context, kept []*docGroup, g *docGroup, budget int) (*docGroup, error) {
+	label := g.label
+	if !strings.HasPrefix(label, "summaries of ") {
+		label = "summaries of " + cmp.Or(label, "documents")
+	}
+	// The batch summaries are inputs to the final result,
+	// so they are not subject to the client's length.
+	cc := c.WithLength(Long)
+	out := &docGroup{label: label}
+	for _, batch := range batchDocs(g.docs, budget) {
+		r, err := cc.overview(ctx, docsChunk, slices.Concat(kept, []*docGroup{{label: g.label, docs: batch}})...)
+		if err != nil {
+			return nil, err
+		}
+		out.docs = append(out.docs, &Doc{
+			Type: "summary of " + cmp.Or(g.label, "documents"),
+			Text: r.Response,
+		})
+	}
+	return out, nil
+}
+
+// largestGroup returns the index of the group in groups
+// with the most tokens, or -1 if no group has more than
+// one document or any tokens.
+func largestGroup(groups []*docGroup) int {
+	best, bestTokens := -1, 0
+	for i, g := range groups {
+		if len(g.docs) < 2 {
+			// Summarizing a single document rarely helps.
+			continue
+		}
+		if t := docsTokens(g.docs); t > bestTokens {
+			best, bestTokens = i, t
+		}
+	}
+	return best
+}
+
+// batchDocs splits docs into batches, in order, whose tokens
+// total at most budget, except that each batch has at least one
+// document and at least two batches are returned for two or more docs,
+// so that summarizing the batches makes progress.
+func batchDocs(docs []*Doc, budget int) [][]*Doc {
+	budget = min(budget, docsTokens(docs)/2+1)
+	var batches [][]*Doc
+	var batch []*Doc
+	n := 0
+	for _, d := range docs {
+		t := docTokens(d)
+		if len(batch) > 0 && n+t > budget {
+			batches = append(batches, batch)
+			batch, n = nil, 0
+		}
+		batch = append(batch, d)
+		n += t
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// docTokens returns the estimated number of tokens
+// used for the document d in a prompt.
+func docTokens(d *Doc) int {
+	return llm.EstimateTokens([]llm.Part{untrusted(d)})
+}
+
+// docsTokens returns the estimated number of tokens
+// used for the documents in a prompt.
+func docsTokens(docs []*Doc) int {
+	n := 0
+	for _, d := range docs {
+		n += docTokens(d)
+	}
+	return n
+}
+
+// truncateDocs returns docs with the text of each document
+// of more than max tokens truncated to fit.
+func truncateDocs(docs []*Doc, max int) []*Doc {
+	var out []*Doc
+	for _, d := range docs {
+		if docTokens(d) > max {
+			x := *d
+			for t := docTokens(&x); t > max; t = docTokens(&x) {
+				// Cut the text in proportion,
+				// leaving room for estimation error.
+				text := strings.TrimSuffix(x.Text, truncatedNote)
+				n := len(text) * max / t * 9 / 10
+				for n > 0 && !utf8.RuneStart(text[n]) {
+					n--
+				}
+				x.Text = text[:n] + truncatedNote
+				if n == 0 {
+					break
+				}
+			}
+			d = &x
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// truncatedNote marks the end of a truncated document.
+const truncatedNote = "\n[truncated]"
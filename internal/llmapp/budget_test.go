@@ -0,0 +1,94 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestContextWindow(t *testing.T) {
+	ctx := context.Background()
+	chunkInstr := llm.Part(llm.Text(docsChunk.instructions()))
+
+	// The generator answers batch prompts with a short summary
+	// and the final prompt by echoing it.
+	var batches [][]llm.Part
+	g := llm.TestContentGenerator("test", func(_ context.Context, _ *llm.Schema, parts []llm.Part) (string, error) {
+		if slices.Contains(parts, chunkInstr) {
+			batches = append(batches, parts)
+			return strings.Repeat("summary ", 150), nil
+		}
+		return llm.EchoTextResponse(parts...), nil
+	})
+	c := New(testutil.Slogger(t), g, storage.MemDB())
+	c.SetContextWindow(4000)
+	budget := c.inputBudget()
+
+	post := &Doc{Type: "issue", Title: "bug", Text: "it is broken"}
+	var comments []*Doc
+	for i := range 100 {
+		comments = append(comments, &Doc{Type: "comment", Author: fmt.Sprint("user", i), Text: strings.Repeat("I agree. ", 30)})
+	}
+
+	// Small discussions are summarized at once.
+	r, err := c.PostOverview(ctx, post, comments[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 0 || len(r.Prompt) != 7 {
+		t.Fatalf("small discussion: %d batches, %d prompt parts; want 0, 7", len(batches), len(r.Prompt))
+	}
+
+	r, err = c.PostOverview(ctx, post, comments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := llm.EstimateTokens(r.Prompt); n > budget {
+		t.Errorf("final prompt has %d tokens, want at most %d", n, budget)
+	}
+	if r.Prompt[3] != llm.Text("summaries of comments") {
+		t.Errorf("final prompt part 3 = %q, want summaries of comments", r.Prompt[3])
+	}
+	// Each batch includes the post and fits in the window;
+	// some batches summarize earlier summaries.
+	var reduced int
+	for _, b := range batches {
+		if n := llm.EstimateTokens(b); n > budget {
+			t.Errorf("batch prompt has %d tokens, want at most %d", n, budget)
+		}
+		if b[1] != llm.Text("post") || b[2] != untrusted(post) {
+			t.Errorf("batch prompt does not start with the post: %q", b[1:3])
+		}
+		if b[3] == llm.Text("summaries of comments") {
+			reduced++
+		}
+	}
+	if reduced == 0 || reduced == len(batches) {
+		t.Errorf("%d of %d batches summarize summaries, want some", reduced, len(batches))
+	}
+}
+
+func TestTruncateDocs(t *testing.T) {
+	small := &Doc{Text: "short"}
+	big := &Doc{Text: strings.Repeat("word ", 1000)}
+	docs := truncateDocs([]*Doc{small, big}, 100)
+	if docs[0] != small {
+		t.Errorf("small doc changed")
+	}
+	if n := docTokens(docs[1]); n > 100 || !strings.HasSuffix(docs[1].Text, truncatedNote) {
+		t.Errorf("truncated doc has %d tokens, text ending %q", n, docs[1].Text[len(docs[1].Text)-20:])
+	}
+	if big.Text != strings.Repeat("word ", 1000) {
+		t.Errorf("truncateDocs modified its input")
+	}
+}
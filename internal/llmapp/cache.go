@@ -8,6 +8,8 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"hash"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/oscar/internal/llm"
 	"golang.org/x/oscar/internal/storage"
@@ -18,20 +20,172 @@ import (
 //
 // The llmapp cache stores the following database entries:
 //
+//   - ("llmapp.Generate", version, model, SHA-256(schema, prompts)) -> [responseGenerateContent]
+//     where version is the version of the prompt (see [Result.PromptVersion]), model is the
+//     name of the generative model used to generate responses, schema is the input schema
+//     to the model, and prompts are the input prompts.
+//
 //   - ("llmapp.GenerateText", model, SHA-256(schema, prompts)) -> [responseGenerateContent]
-//     where model is the name of the generative model used to generate responses, schema is
-//     the input schema to the model, and prompts are the input prompts.
+//     is the same, without the version. Entries in this older form are moved to the
+//     current form when they are first read.
 //
 //   - ("llmapp.CheckPolicy", checker, SHA-256(policies, input, prompts)) -> [responseCheckText]
 //     where checker is the name of the policy checker used to check LLM inputs/outputs,
 //     policies are the applied policies, input is the text to check, and prompts are the
 //     optional prompts used to generate the input (only relevant if the input is itself
 //     an LLM output).
+//
+//   - ("llmapp.BatchJob", id) -> [BatchJob]
+//     where id is the identifier of a batch submitted to a [llm.BatchContentGenerator].
 const (
-	generateKind = "llmapp.GenerateText"
-	checkKind    = "llmapp.CheckPolicy"
+	generateKind       = "llmapp.Generate"
+	legacyGenerateKind = "llmapp.GenerateText"
+	checkKind          = "llmapp.CheckPolicy"
+	batchKind          = "llmapp.BatchJob"
 )
 
+// A CacheStats holds statistics about the use of the
+// cache of generated responses since the [Client] was created.
+type CacheStats struct {
+	Hits    int64 // responses found in the cache
+	Misses  int64 // responses generated (or batched), including Expired
+	Expired int64 // responses found in the cache but older than the TTL
+}
+
+// HitRate returns the fraction of lookups that were hits,
+// or 0 if there were no lookups.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// cacheStats is the shared, concurrency-safe form of [CacheStats].
+type cacheStats struct {
+	hits, misses, expired atomic.Int64
+}
+
+// CacheStats returns the statistics of the cache of generated
+// responses, shared by c and the Clients derived from it
+// (for example, by [Client.WithLength]).
+func (c *Client) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:    c.stats.hits.Load(),
+		Misses:  c.stats.misses.Load(),
+		Expired: c.stats.expired.Load(),
+	}
+}
+
+// SetCacheTTL sets the time to live of cached responses:
+// responses cached longer ago than ttl are generated again.
+// The default, 0, means that cached responses never expire.
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.cacheTTL = ttl
+}
+
+// expired reports whether the cached response r has expired.
+func (c *Client) expired(r *responseGenerateContent) bool {
+	return c.cacheTTL > 0 && time.Since(r.Time) > c.cacheTTL
+}
+
+// A CacheVersion describes the cached responses
+// for a prompt version and model.
+type CacheVersion struct {
+	Version string // the prompt version; "" for entries in the older form
+	Model   string // the generative model
+	Entries int    // the number of cached responses
+}
+
+// CacheVersions returns the number of cached responses for each
+// prompt version and model, in version and model order.
+func (c *Client) CacheVersions() []*CacheVersion {
+	var cvs []*CacheVersion
+	add := func(version, model string) {
+		if n := len(cvs); n > 0 && cvs[n-1].Version == version && cvs[n-1].Model == model {
+			cvs[n-1].Entries++
+			return
+		}
+		cvs = append(cvs, &CacheVersion{Version: version, Model: model, Entries: 1})
+	}
+	for key := range c.db.Scan(ordered.Encode(legacyGenerateKind), ordered.Encode(legacyGenerateKind, ordered.Inf)) {
+		var model string
+		if _, err := ordered.DecodePrefix(key, nil, &model); err != nil {
+			c.db.Panic("llmapp cache decode", "key", storage.Fmt(key), "err", err)
+		}
+		add("", model)
+	}
+	for key := range c.db.Scan(ordered.Encode(generateKind), ordered.Encode(generateKind, ordered.Inf)) {
+		var version, model string
+		if _, err := ordered.DecodePrefix(key, nil, &version, &model); err != nil {
+			c.db.Panic("llmapp cache decode", "key", storage.Fmt(key), "err", err)
+		}
+		add(version, model)
+	}
+	return cvs
+}
+
+// InvalidateCache deletes the cached responses generated with
+// the given prompt version (see [Result.PromptVersion]),
+// so that they are generated again when next requested.
+// If version is empty, InvalidateCache deletes all cached responses.
+// It returns the number of responses deleted.
+func (c *Client) InvalidateCache(version string) int {
+	var start, end []byte
+	if version == "" {
+		start, end = ordered.Encode(generateKind), ordered.Encode(generateKind, ordered.Inf)
+	} else {
+		start, end = ordered.Encode(generateKind, version), ordered.Encode(generateKind, version, ordered.Inf)
+	}
+	n := 0
+	b := c.db.Batch()
+	for key := range c.db.Scan(start, end) {
+		b.Delete(key)
+		b.MaybeApply()
+		n++
+	}
+	if version == "" {
+		for key := range c.db.Scan(ordered.Encode(legacyGenerateKind), ordered.Encode(legacyGenerateKind, ordered.Inf)) {
+			b.Delete(key)
+			b.MaybeApply()
+			n++
+		}
+	}
+	b.Apply()
+	c.db.Flush()
+	c.slog.Info("llmapp: invalidated cached responses", "version", version, "n", n)
+	return n
+}
+
+// DeleteExpiredCache deletes from db the cached responses generated
+// before the given time, which a [Client] with a time to live
+// of time.Since(before) would generate again (see [Client.SetCacheTTL]).
+// Responses cached before their time was recorded count as expired.
+// Cached policy checks do not expire and are not deleted.
+// It returns the number of responses deleted.
+//
+// DeleteExpiredCache is a function rather than a [Client] method
+// so that a garbage collector can call it on a database of its choosing.
+func DeleteExpiredCache(db storage.DB, before time.Time) int {
+	n := 0
+	b := db.Batch()
+	for _, kind := range []string{legacyGenerateKind, generateKind} {
+		for key, val := range db.Scan(ordered.Encode(kind), ordered.Encode(kind, ordered.Inf)) {
+			var r responseGenerateContent
+			if err := json.Unmarshal(val(), &r); err == nil && !r.Time.Before(before) {
+				continue
+			}
+			// Unreadable responses are ignored by load, so delete them too.
+			b.Delete(key)
+			b.MaybeApply()
+			n++
+		}
+	}
+	b.Apply()
+	db.Flush()
+	return n
+}
+
 // load loads a cached response from the database.
 // load returns nil if the response cannot be unmarshaled
 // or there is no entry for the key.
@@ -52,6 +206,11 @@ func load[R any](c *Client, key []byte) *R {
 type responseGenerateContent struct {
 	// The generative model used to generate the response.
 	Model string
+	// The version of the prompt used to generate the response.
+	Version string
+	// The time the response was cached.
+	// It is zero for responses cached before it was recorded.
+	Time time.Time
 	// The SHA-256 hash of the schema and prompts used to generate the response.
 	PromptHash []byte
 	// The raw generated response.
@@ -59,16 +218,23 @@ type responseGenerateContent struct {
 }
 
 // keyAndHashGenerateContent returns the database key and input hash (hash of schema and parts)
-// for cached responses from [llm.ContentGenerator.GenerateContent] queries.
-func (c *Client) keyAndHashGenerateContent(schema *llm.Schema, parts []llm.Part) (key, hash []byte) {
+// for cached responses from [llm.ContentGenerator.GenerateContent] queries
+// using the prompt version.
+func (c *Client) keyAndHashGenerateContent(version string, schema *llm.Schema, parts []llm.Part) (key, hash []byte) {
 	h := sha256.New()
 	writeObjectToHash(h, schema)
 	c.writePromptsToHash(h, parts)
 	hash = h.Sum(nil)
-	key = ordered.Encode(generateKind, c.g.Model(), hash)
+	key = ordered.Encode(generateKind, version, c.g.Model(), hash)
 	return key, hash
 }
 
+// legacyKeyGenerateContent returns the database key
+// of the older form of cache entry for the input hash.
+func (c *Client) legacyKeyGenerateContent(hash []byte) []byte {
+	return ordered.Encode(legacyGenerateKind, c.g.Model(), hash)
+}
+
 // responseCheckText is a cached result of a [llm.PolicyChecker.CheckText] call.
 type responseCheckText struct {
 	// The name of the PolicyChecker used to generate this response.
@@ -102,7 +268,8 @@ func writeObjectToHash(h hash.Hash, obj any) {
 	}
 }
 
-// writePromptsToHash writes the given prompts (text or blob) to the hash.
+// writePromptsToHash writes the given prompts (text, blob,
+// function call or function response) to the hash.
 func (c *Client) writePromptsToHash(h hash.Hash, prompts []llm.Part) {
 	for _, p := range prompts {
 		switch p := p.(type) {
@@ -111,6 +278,8 @@ func (c *Client) writePromptsToHash(h hash.Hash, prompts []llm.Part) {
 		case llm.Blob:
 			h.Write([]byte(p.MIMEType))
 			h.Write(p.Data)
+		case llm.FunctionCall, llm.FunctionResponse:
+			writeObjectToHash(h, p)
 		default:
 			c.db.Panic("llmapp.Client.writePromptsToHash: unknown prompt type", "prompt", p)
 		}
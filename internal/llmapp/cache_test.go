@@ -0,0 +1,150 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	calls := 0
+	g := llm.TestContentGenerator("counter", func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+		calls++
+		return "response", nil
+	})
+	c := New(lg, g, db)
+	a := []llm.Part{llm.Text("a")}
+	b := []llm.Part{llm.Text("b")}
+
+	gen := func(c *Client, version string, prompt []llm.Part, wantCached bool) {
+		t.Helper()
+		_, cached, err := c.generate(ctx, version, nil, prompt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached != wantCached {
+			t.Errorf("generate(%s, %v): cached = %v, want %v", version, prompt, cached, wantCached)
+		}
+	}
+
+	gen(c, "v1", a, false)
+	gen(c, "v1", a, true)
+	gen(c, "v2", a, false) // new prompt version
+	gen(c.WithLength(Short), "v1", b, false)
+	gen(c, "v1", b, true)
+	if want := (CacheStats{Hits: 2, Misses: 3}); c.CacheStats() != want {
+		t.Errorf("CacheStats() = %+v, want %+v", c.CacheStats(), want)
+	}
+	if got, want := c.CacheStats().HitRate(), 0.4; got != want {
+		t.Errorf("HitRate() = %v, want %v", got, want)
+	}
+	want := []*CacheVersion{
+		{Version: "v1", Model: "test-model", Entries: 2},
+		{Version: "v2", Model: "test-model", Entries: 1},
+	}
+	if diff := cmp.Diff(want, c.CacheVersions()); diff != "" {
+		t.Errorf("CacheVersions() (-want, +got):\n%s", diff)
+	}
+
+	// Expiry.
+	c.SetCacheTTL(time.Hour)
+	gen(c, "v1", a, true)
+	c.SetCacheTTL(time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	gen(c, "v1", a, false)
+	if s := c.CacheStats(); s.Expired != 1 {
+		t.Errorf("CacheStats() = %+v, want 1 expired", s)
+	}
+	c.SetCacheTTL(0)
+
+	// Invalidation.
+	if n := c.InvalidateCache("v1"); n != 2 {
+		t.Errorf("InvalidateCache(v1) = %d, want 2", n)
+	}
+	gen(c, "v1", a, false)
+	gen(c, "v2", a, true)
+	if n := c.InvalidateCache(""); n != 2 {
+		t.Errorf("InvalidateCache() = %d, want 2", n)
+	}
+	if cvs := c.CacheVersions(); len(cvs) != 0 {
+		t.Errorf("CacheVersions() after InvalidateCache = %v, want none", cvs)
+	}
+	if calls != 5 {
+		t.Errorf("generated %d responses, want 5", calls)
+	}
+
+	// Entries without a version are moved on first use.
+	_, h := c.keyAndHashGenerateContent("v3", nil, a)
+	db.Set(c.legacyKeyGenerateContent(h), storage.JSON(responseGenerateContent{Model: "test-model", PromptHash: h, Response: "old"}))
+	if cvs := c.CacheVersions(); len(cvs) != 1 || cvs[0].Version != "" {
+		t.Errorf("CacheVersions() with legacy entry = %v", cvs)
+	}
+	resp, cached, err := c.generate(ctx, "v3", nil, a)
+	if err != nil || !cached || resp != "old" {
+		t.Errorf("generate with legacy entry = %q, %v, %v, want %q, true, nil", resp, cached, err, "old")
+	}
+	want = []*CacheVersion{{Version: "v3", Model: "test-model", Entries: 1}}
+	if diff := cmp.Diff(want, c.CacheVersions()); diff != "" {
+		t.Errorf("CacheVersions() after migration (-want, +got):\n%s", diff)
+	}
+
+	// Deletion of expired entries.
+	gen(c, "v4", a, false)
+	time.Sleep(time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(time.Millisecond)
+	gen(c, "v5", a, false)
+	if n := DeleteExpiredCache(db, cutoff); n != 2 {
+		t.Errorf("DeleteExpiredCache() = %d, want 2", n)
+	}
+	want = []*CacheVersion{{Version: "v5", Model: "test-model", Entries: 1}}
+	if diff := cmp.Diff(want, c.CacheVersions()); diff != "" {
+		t.Errorf("CacheVersions() after DeleteExpiredCache (-want, +got):\n%s", diff)
+	}
+}
+
+func TestWithContentGenerator(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	var calls []string
+	counter := func(name string) llm.ContentGenerator {
+		return llm.TestContentGenerator(name, func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+			calls = append(calls, name)
+			return "response", nil
+		})
+	}
+	c := New(lg, counter("c"), db)
+	d := c.WithContentGenerator(counter("d"))
+	a := []llm.Part{llm.Text("a")}
+	b := []llm.Part{llm.Text("b")}
+	for _, p := range [][]llm.Part{a, b} {
+		if _, _, err := d.generate(ctx, "v1", nil, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The clients share the cache: the response to a
+	// is cached for c, for the same model.
+	if _, cached, err := c.generate(ctx, "v1", nil, a); err != nil || !cached {
+		t.Errorf("c.generate(a) = cached %v, %v, want cached", cached, err)
+	}
+	if want := []string{"d", "d"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if want := (CacheStats{Hits: 1, Misses: 2}); c.CacheStats() != want {
+		t.Errorf("CacheStats() = %+v, want %+v", c.CacheStats(), want)
+	}
+}
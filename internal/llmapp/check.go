@@ -10,6 +10,7 @@ import (
 	"log/slog"
 
 	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/moderate"
 	"golang.org/x/oscar/internal/storage"
 )
 
@@ -22,7 +23,7 @@ import (
 //
 // If the checker is nil, [NewWithChecker] is identical to [New].
 func NewWithChecker(lg *slog.Logger, g llm.ContentGenerator, checker llm.PolicyChecker, db storage.DB) *Client {
-	return &Client{slog: lg, g: g, checker: checker, db: db}
+	return &Client{slog: lg, g: g, checker: checker, db: db, stats: new(cacheStats)}
 }
 
 // EvaluatePolicy invokes the policy checker on the given prompts and LLM output and
@@ -106,3 +107,13 @@ func violations(prs []*llm.PolicyResult) []*llm.PolicyResult {
 	}
 	return vs
 }
+
+// SetModerator sets the filter that checks and cleans every response
+// of the LLM, including cached ones, before the Client uses it.
+// Responses that the filter blocks are reported as errors
+// wrapping [moderate.ErrBlocked].
+// Clients derived from c after the call (such as by [Client.WithLength])
+// use the same filter.
+func (c *Client) SetModerator(f *moderate.Filter) {
+	c.moderator = f
+}
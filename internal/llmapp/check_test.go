@@ -6,6 +6,8 @@ package llmapp
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"strings"
 	"sync"
 	"testing"
@@ -13,6 +15,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/moderate"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/testutil"
 )
@@ -37,6 +40,8 @@ func TestWithChecker(t *testing.T) {
 	want := &PolicyEvaluation{
 		Violative: true,
 		PromptResults: []*PolicyResult{
+			// instructions for untrusted documents
+			{Results: []*llm.PolicyResult{okResult}},
 			// doc1
 			{
 				Results:    []*llm.PolicyResult{violationResult},
@@ -68,6 +73,8 @@ func TestWithChecker(t *testing.T) {
 	want = &PolicyEvaluation{
 		Violative: false,
 		PromptResults: []*PolicyResult{
+			// instructions for untrusted documents
+			{Results: []*llm.PolicyResult{okResult}},
 			// doc2
 			{Results: []*llm.PolicyResult{okResult}},
 			// instructions
@@ -255,3 +262,34 @@ func (c *cacheChecker) CheckText(context.Context, string, ...llm.Part) ([]*llm.P
 	}
 	return []*llm.PolicyResult{violationResult}, nil
 }
+
+func TestSetModerator(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	calls := 0
+	g := llm.TestContentGenerator("test", func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+		calls++
+		return "cc @rsc, see https://evil.example/x", nil
+	})
+	c := New(lg, g, storage.MemDB())
+	prompt := []llm.Part{llm.Text("a")}
+	if got, _, err := c.generate(ctx, "v1", nil, prompt); err != nil || !strings.Contains(got, "@rsc") {
+		t.Fatalf("generate = %q, %v", got, err)
+	}
+
+	// Cached responses are moderated too.
+	m := moderate.New(lg)
+	m.StripMentions()
+	c.SetModerator(m)
+	want := "cc rsc, see https://evil.example/x"
+	if got, cached, err := c.WithLength(Short).generate(ctx, "v1", nil, prompt); got != want || !cached || err != nil {
+		t.Errorf("generate = %q, %v, %v, want %q, true, nil", got, cached, err, want)
+	}
+	m.Deny(regexp.MustCompile(`evil`))
+	if _, _, err := c.generate(ctx, "v1", nil, prompt); !errors.Is(err, moderate.ErrBlocked) {
+		t.Errorf("generate = %v, want ErrBlocked", err)
+	}
+	if calls != 1 {
+		t.Errorf("generated %d responses, want 1", calls)
+	}
+}
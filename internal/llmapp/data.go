@@ -32,6 +32,8 @@ type Result struct {
 	Cached           bool              // whether the response was cached
 	Schema           *llm.Schema       // the JSON schema used to generate the result (nil if none)
 	Prompt           []llm.Part        // the prompt(s) used to generate the result
+	Model            string            // the generative model used to generate the response
+	PromptVersion    string            // identifies the instructions and schema used to generate the result, prefixed by the [Prompt] version if set
 	PolicyEvaluation *PolicyEvaluation // (if a policy checker is configured) the policy evaluation result
 }
 
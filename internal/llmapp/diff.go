@@ -0,0 +1,119 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxChunkBytes is the maximum size of the file diffs
+// summarized by a single LLM call in [Client.PullRequestDiffSummary].
+// Larger file diffs are truncated.
+var maxChunkBytes = 200_000
+
+// PullRequestDiffSummary returns an LLM-generated summary of the changes
+// made by the pull request pr, written for its reviewers and styled with
+// markdown: what changed, the areas that deserve the closest review,
+// and a suggested order in which to review the files.
+// Each of files is the diff of one changed file, with the file's path as
+// its title.
+//
+// If the diffs are too large for a single LLM call, they are split
+// into chunks of whole files; each chunk is summarized separately,
+// and the final summary is generated from the chunk summaries.
+// PullRequestDiffSummary returns an error if no pull request is provided
+// or the LLM is unable to generate a response.
+func (c *Client) PullRequestDiffSummary(ctx context.Context, pr *Doc, files []*Doc) (*Result, error) {
+	if pr == nil {
+		return nil, errors.New("llmapp PullRequestDiffSummary: no pull request")
+	}
+	diffs, err := c.diffGroup(ctx, pr, files)
+	if err != nil {
+		return nil, err
+	}
+	return c.overview(ctx, pullRequestDiff,
+		&docGroup{label: "pull request", docs: []*Doc{pr}},
+		diffs)
+}
+
+// ReviewChecklist returns an LLM-generated checklist, styled with markdown,
+// for reviewing the pull request pr, tailored to the change (whose file
+// diffs are files, as in [Client.PullRequestDiffSummary]) and to the
+// project's review guidelines. If guidelines is empty, the checklist
+// follows general code review practice.
+// Large diffs are summarized in chunks, as in [Client.PullRequestDiffSummary].
+// ReviewChecklist returns an error if no pull request is provided or the LLM
+// is unable to generate a response.
+func (c *Client) ReviewChecklist(ctx context.Context, pr *Doc, guidelines string, files []*Doc) (*Result, error) {
+	if pr == nil {
+		return nil, errors.New("llmapp ReviewChecklist: no pull request")
+	}
+	diffs, err := c.diffGroup(ctx, pr, files)
+	if err != nil {
+		return nil, err
+	}
+	groups := []*docGroup{{label: "pull request", docs: []*Doc{pr}}, diffs}
+	if guidelines != "" {
+		groups = append(groups, &docGroup{label: "review guidelines", docs: []*Doc{{Type: "review guidelines", Text: guidelines}}, trusted: true})
+	}
+	return c.overview(ctx, reviewChecklist, groups...)
+}
+
+// diffGroup returns the group of documents describing the file
+// diffs of the pull request pr: the diffs themselves or, if they
+// are too large for a single LLM call, summaries of chunks of them.
+func (c *Client) diffGroup(ctx context.Context, pr *Doc, files []*Doc) (*docGroup, error) {
+	chunks := chunkDiffs(files, maxChunkBytes)
+	if len(chunks) <= 1 {
+		return &docGroup{label: "file diffs", docs: files}, nil
+	}
+
+	// The chunk summaries are inputs to the final result,
+	// so they are not subject to the client's length.
+	cc := c.WithLength(Long)
+	var summaries []*Doc
+	for _, chunk := range chunks {
+		r, err := cc.overview(ctx, diffChunk,
+			&docGroup{label: "pull request", docs: []*Doc{pr}},
+			&docGroup{label: "file diffs", docs: chunk})
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, &Doc{
+			Type:  "summary of file diffs",
+			Title: fmt.Sprintf("%s to %s", chunk[0].Title, chunk[len(chunk)-1].Title),
+			Text:  r.Response,
+		})
+	}
+	return &docGroup{label: "summaries of file diffs", docs: summaries}, nil
+}
+
+// chunkDiffs splits the file diffs into chunks whose texts total
+// at most max bytes, keeping the files in order.
+// A file whose diff alone is larger than max is truncated.
+func chunkDiffs(files []*Doc, max int) [][]*Doc {
+	var chunks [][]*Doc
+	var chunk []*Doc
+	n := 0
+	for _, f := range files {
+		if len(f.Text) > max {
+			x := *f
+			x.Text = x.Text[:max] + "\n[diff truncated]"
+			f = &x
+		}
+		if len(chunk) > 0 && n+len(f.Text) > max {
+			chunks = append(chunks, chunk)
+			chunk, n = nil, 0
+		}
+		chunk = append(chunk, f)
+		n += len(f.Text)
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+func TestPullRequestDiffSummary(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	pr := &Doc{Type: "pull request", Title: "fix bug", Text: "fixes the bug"}
+	f1 := &Doc{Type: "file diff", Title: "a.go", Text: "@@ -1 +1 @@\n-x\n+y"}
+	f2 := &Doc{Type: "file diff", Title: "b.go", Text: "@@ -1 +1 @@\n-z\n+w"}
+
+	t.Run("single", func(t *testing.T) {
+		got, err := c.PullRequestDiffSummary(ctx, pr, []*Doc{f1, f2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		promptParts := []llm.Part{
+			guard,
+			llm.Text("pull request"), untrusted(pr),
+			llm.Text("file diffs"), untrusted(f1), untrusted(f2),
+			llm.Text(pullRequestDiff.instructions()),
+		}
+		want := &Result{
+			Response:      llm.EchoTextResponse(promptParts...),
+			Prompt:        promptParts,
+			Model:         "echo",
+			PromptVersion: pullRequestDiff.version(),
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("PullRequestDiffSummary() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		defer func(n int) { maxChunkBytes = n }(maxChunkBytes)
+		maxChunkBytes = len(f1.Text) + 1 // one file per chunk
+
+		got, err := c.PullRequestDiffSummary(ctx, pr, []*Doc{f1, f2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The final prompt is the pull request and two chunk summaries.
+		if n := len(got.Prompt); n != 7 {
+			t.Fatalf("got %d prompt parts, want 7:\n%v", n, got.Prompt)
+		}
+		if got.Prompt[3] != llm.Text("summaries of file diffs") {
+			t.Errorf("prompt part 3 = %q", got.Prompt[3])
+		}
+		for i, f := range []*Doc{f1, f2} {
+			s := string(got.Prompt[4+i].(llm.Text))
+			if !strings.Contains(s, diffChunk.instructions()[:20]) || !strings.Contains(s, f.Title) {
+				t.Errorf("chunk %d summary does not summarize %s:\n%s", i, f.Title, s)
+			}
+		}
+	})
+
+	if _, err := c.PullRequestDiffSummary(ctx, nil, nil); err == nil {
+		t.Error("PullRequestDiffSummary(nil) succeeded, want error")
+	}
+}
+
+func TestReviewChecklist(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	pr := &Doc{Type: "pull request", Title: "fix bug", Text: "fixes the bug"}
+	f1 := &Doc{Type: "file diff", Title: "a.go", Text: "@@ -1 +1 @@\n-x\n+y"}
+	guidelines := "All exported functions need doc comments."
+
+	got, err := c.ReviewChecklist(ctx, pr, guidelines, []*Doc{f1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	promptParts := []llm.Part{
+		guard,
+		llm.Text("pull request"), untrusted(pr),
+		llm.Text("file diffs"), untrusted(f1),
+		llm.Text("review guidelines"), llm.Text(storage.JSON(&Doc{Type: "review guidelines", Text: guidelines})),
+		llm.Text(reviewChecklist.instructions()),
+	}
+	want := &Result{
+		Response:      llm.EchoTextResponse(promptParts...),
+		Prompt:        promptParts,
+		Model:         "echo",
+		PromptVersion: reviewChecklist.version(),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReviewChecklist() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Without guidelines, there is no guidelines group.
+	got, err = c.ReviewChecklist(ctx, pr, "", []*Doc{f1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(got.Prompt); n != 6 {
+		t.Errorf("ReviewChecklist without guidelines: %d prompt parts, want 6", n)
+	}
+
+	if _, err := c.ReviewChecklist(ctx, nil, "", nil); err == nil {
+		t.Error("ReviewChecklist(nil) succeeded, want error")
+	}
+}
+
+func TestChunkDiffs(t *testing.T) {
+	doc := func(title string, n int) *Doc {
+		return &Doc{Title: title, Text: strings.Repeat("x", n)}
+	}
+	titles := func(chunks [][]*Doc) [][]string {
+		var ts [][]string
+		for _, c := range chunks {
+			var t []string
+			for _, d := range c {
+				t = append(t, d.Title)
+			}
+			ts = append(ts, t)
+		}
+		return ts
+	}
+
+	files := []*Doc{doc("a", 4), doc("b", 4), doc("c", 3), doc("d", 12), doc("e", 1)}
+	chunks := chunkDiffs(files, 10)
+	want := [][]string{{"a", "b"}, {"c"}, {"d"}, {"e"}}
+	if diff := cmp.Diff(want, titles(chunks)); diff != "" {
+		t.Errorf("chunkDiffs (-want +got):\n%s", diff)
+	}
+	if got := chunks[2][0].Text; got != strings.Repeat("x", 10)+"\n[diff truncated]" {
+		t.Errorf("truncated diff = %q", got)
+	}
+	if files[3].Text != strings.Repeat("x", 12) {
+		t.Error("chunkDiffs modified its input")
+	}
+	if chunks := chunkDiffs(nil, 10); chunks != nil {
+		t.Errorf("chunkDiffs(nil) = %v, want nil", chunks)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/oscar/internal/llm"
+)
+
+// DuplicateResult is the output of [Client.CheckDuplicate].
+type DuplicateResult struct {
+	Result
+	// The index of the candidate that the issue duplicates,
+	// or -1 if it duplicates none of them.
+	Duplicate int
+	// The LLM's explanation of its decision.
+	Explanation string
+}
+
+// Duplicate represents the desired JSON structure of the LLM output
+// requested by [Client.CheckDuplicate].
+//
+// IMPORTANT: If you edit the types or JSON names of fields in this
+// struct, edit [duplicateSchema] accordingly.
+type Duplicate struct {
+	Duplicate   int    `json:"duplicate"`
+	Explanation string `json:"explanation"`
+}
+
+// The [*llm.Schema] corresponding to the [Duplicate] type.
+var duplicateSchema = &llm.Schema{
+	Type: llm.TypeObject,
+	Properties: map[string]*llm.Schema{
+		"duplicate": {
+			Type:        llm.TypeInteger,
+			Description: "The number of the candidate that the issue duplicates, or -1 if it duplicates none of them.",
+		},
+		"explanation": {
+			Type:        llm.TypeString,
+			Description: "A one-sentence explanation of the decision.",
+		},
+	},
+	Required: []string{"duplicate", "explanation"},
+}
+
+// CheckDuplicate asks the LLM whether the issue reports the same
+// problem or request as one of the candidate documents.
+// It returns an error if there is no issue or there are no candidates,
+// or if the LLM is unable to generate a well-formed response.
+func (c *Client) CheckDuplicate(ctx context.Context, issue *Doc, candidates []*Doc) (*DuplicateResult, error) {
+	if issue == nil {
+		return nil, errors.New("llmapp CheckDuplicate: no issue")
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("llmapp CheckDuplicate: no candidates")
+	}
+	groups := []*docGroup{{label: "issue", docs: []*Doc{issue}}}
+	for i, d := range candidates {
+		groups = append(groups, &docGroup{label: fmt.Sprintf("candidate %d", i), docs: []*Doc{d}})
+	}
+	result, err := c.overview(ctx, issueAndCandidates, groups...)
+	if err != nil {
+		return nil, fmt.Errorf("llmapp CheckDuplicate: cannot generate response: %w", err)
+	}
+	var typed Duplicate
+	if err := json.Unmarshal([]byte(result.Response), &typed); err != nil {
+		return nil, fmt.Errorf("llmapp CheckDuplicate: cannot unmarshal response: %w\nresponse: %s", err, result.Response)
+	}
+	if typed.Duplicate < -1 || typed.Duplicate >= len(candidates) {
+		return nil, fmt.Errorf("llmapp CheckDuplicate: malformed LLM output (candidate %d out of range [-1, %d))", typed.Duplicate, len(candidates))
+	}
+	return &DuplicateResult{Result: *result, Duplicate: typed.Duplicate, Explanation: typed.Explanation}, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestCheckDuplicate(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+
+	gen := func(response string) llm.ContentGenerator {
+		return llm.TestContentGenerator("duplicate-test-generator",
+			func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+				return response, nil
+			})
+	}
+
+	t.Run("basic", func(t *testing.T) {
+		response := `{"duplicate": 0, "explanation": "same crash"}`
+		c := New(lg, gen(response), storage.MemDB())
+		got, err := c.CheckDuplicate(ctx, doc1, []*Doc{doc2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &DuplicateResult{
+			Result: Result{
+				Response: response,
+				Prompt: []llm.Part{
+					guard,
+					llm.Text("issue"), raw1,
+					llm.Text("candidate 0"), raw2,
+					llm.Text(issueAndCandidates.instructions()),
+				},
+				Schema:        duplicateSchema,
+				Model:         "test-model",
+				PromptVersion: issueAndCandidates.version(),
+			},
+			Duplicate:   0,
+			Explanation: "same crash",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("CheckDuplicate() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		c := New(lg, gen(`{"duplicate": -1, "explanation": "different"}`), storage.MemDB())
+		got, err := c.CheckDuplicate(ctx, doc1, []*Doc{doc2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Duplicate != -1 {
+			t.Errorf("CheckDuplicate().Duplicate = %d, want -1", got.Duplicate)
+		}
+	})
+
+	for _, response := range []string{
+		`{"duplicate": 1, "explanation": ""}`,
+		`{"duplicate": -2, "explanation": ""}`,
+		`not json`,
+	} {
+		c := New(lg, gen(response), storage.MemDB())
+		if _, err := c.CheckDuplicate(ctx, doc1, []*Doc{doc2}); err == nil {
+			t.Errorf("CheckDuplicate() with response %s succeeded, want error", response)
+		}
+	}
+
+	c := New(lg, gen(`{"duplicate": -1}`), storage.MemDB())
+	if _, err := c.CheckDuplicate(ctx, nil, []*Doc{doc2}); err == nil {
+		t.Error("CheckDuplicate(nil issue) succeeded, want error")
+	}
+	if _, err := c.CheckDuplicate(ctx, doc1, nil); err == nil {
+		t.Error("CheckDuplicate(no candidates) succeeded, want error")
+	}
+}
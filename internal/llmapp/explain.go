@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+)
+
+// ExplainResult is the output of [Client.ExplainRelated].
+type ExplainResult struct {
+	Result
+	// One explanation for each related document, in order.
+	// An explanation is empty if the LLM did not provide one.
+	Explanations []string
+}
+
+// Explanations represents the desired JSON structure of the LLM output
+// requested by [Client.ExplainRelated].
+//
+// IMPORTANT: If you edit the types or JSON names of fields in this
+// struct, edit [explainSchema] accordingly.
+type Explanations struct {
+	Explanations []Explanation `json:"explanations"`
+}
+
+// An Explanation is the LLM's explanation of why one
+// related document is related.
+type Explanation struct {
+	Related     int    `json:"related"`
+	Explanation string `json:"explanation"`
+}
+
+// The [*llm.Schema] corresponding to the [Explanations] type.
+var explainSchema = &llm.Schema{
+	Type: llm.TypeObject,
+	Properties: map[string]*llm.Schema{
+		"explanations": {
+			Type: llm.TypeArray,
+			Items: &llm.Schema{
+				Type: llm.TypeObject,
+				Properties: map[string]*llm.Schema{
+					"related": {
+						Type:        llm.TypeInteger,
+						Description: "The number of the related document.",
+					},
+					"explanation": {
+						Type:        llm.TypeString,
+						Description: "A one-line explanation of the connection between the related document and the original.",
+					},
+				},
+				Required: []string{"related", "explanation"},
+			},
+		},
+	},
+	Required: []string{"explanations"},
+}
+
+// ExplainRelated asks the LLM to explain, in one line each, how the
+// related documents are connected to the original document,
+// such as by a matching symptom, the same API or the same crash signature.
+// It returns an error if there is no original document or there are
+// no related documents, or if the LLM is unable to generate a
+// well-formed response.
+func (c *Client) ExplainRelated(ctx context.Context, doc *Doc, related []*Doc) (*ExplainResult, error) {
+	if doc == nil {
+		return nil, errors.New("llmapp ExplainRelated: no doc")
+	}
+	if len(related) == 0 {
+		return nil, errors.New("llmapp ExplainRelated: no related docs")
+	}
+	groups := []*docGroup{{label: "original", docs: []*Doc{doc}}}
+	for i, d := range related {
+		groups = append(groups, &docGroup{label: fmt.Sprintf("related %d", i), docs: []*Doc{d}})
+	}
+	result, err := c.overview(ctx, docAndExplanations, groups...)
+	if err != nil {
+		return nil, fmt.Errorf("llmapp ExplainRelated: cannot generate response: %w", err)
+	}
+	var typed Explanations
+	if err := json.Unmarshal([]byte(result.Response), &typed); err != nil {
+		return nil, fmt.Errorf("llmapp ExplainRelated: cannot unmarshal response: %w\nresponse: %s", err, result.Response)
+	}
+	explanations := make([]string, len(related))
+	for _, e := range typed.Explanations {
+		if e.Related < 0 || e.Related >= len(related) {
+			return nil, fmt.Errorf("llmapp ExplainRelated: malformed LLM output (related document %d out of range [0, %d))", e.Related, len(related))
+		}
+		explanations[e.Related] = oneLine(e.Explanation)
+	}
+	return &ExplainResult{Result: *result, Explanations: explanations}, nil
+}
+
+// oneLine collapses the white space in s, including newlines,
+// into single spaces.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestExplainRelated(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+
+	gen := func(response string) llm.ContentGenerator {
+		return llm.TestContentGenerator("explain-test-generator",
+			func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+				return response, nil
+			})
+	}
+	doc3 := &Doc{Text: "some text 3"}
+
+	t.Run("basic", func(t *testing.T) {
+		response := `{"explanations": [{"related": 1, "explanation": "same crash\nin runtime"}, {"related": 0, "explanation": "uses net/http"}]}`
+		c := New(lg, gen(response), storage.MemDB())
+		got, err := c.ExplainRelated(ctx, doc1, []*Doc{doc2, doc3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &ExplainResult{
+			Result: Result{
+				Response: response,
+				Prompt: []llm.Part{
+					guard,
+					llm.Text("original"), raw1,
+					llm.Text("related 0"), raw2,
+					llm.Text("related 1"), untrusted(doc3),
+					llm.Text(docAndExplanations.instructions()),
+				},
+				Schema:        explainSchema,
+				Model:         "test-model",
+				PromptVersion: docAndExplanations.version(),
+			},
+			Explanations: []string{"uses net/http", "same crash in runtime"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ExplainRelated() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		c := New(lg, gen(`{"explanations": [{"related": 1, "explanation": "same API"}]}`), storage.MemDB())
+		got, err := c.ExplainRelated(ctx, doc1, []*Doc{doc2, doc3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"", "same API"}; !cmp.Equal(got.Explanations, want) {
+			t.Errorf("ExplainRelated().Explanations = %q, want %q", got.Explanations, want)
+		}
+	})
+
+	for _, response := range []string{
+		`{"explanations": [{"related": 2, "explanation": ""}]}`,
+		`{"explanations": [{"related": -1, "explanation": ""}]}`,
+		`not json`,
+	} {
+		c := New(lg, gen(response), storage.MemDB())
+		if _, err := c.ExplainRelated(ctx, doc1, []*Doc{doc2, doc3}); err == nil {
+			t.Errorf("ExplainRelated() with response %s succeeded, want error", response)
+		}
+	}
+
+	c := New(lg, gen(`{"explanations": []}`), storage.MemDB())
+	if _, err := c.ExplainRelated(ctx, nil, []*Doc{doc2}); err == nil {
+		t.Error("ExplainRelated(nil doc) succeeded, want error")
+	}
+	if _, err := c.ExplainRelated(ctx, doc1, nil); err == nil {
+		t.Error("ExplainRelated(no related docs) succeeded, want error")
+	}
+}
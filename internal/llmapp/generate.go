@@ -6,33 +6,83 @@ package llmapp
 
 import (
 	"context"
+	"time"
 
 	"golang.org/x/oscar/internal/llm"
 	"golang.org/x/oscar/internal/storage"
 )
 
-// generate returns a (possibly cached) response for the prompts.
-func (c *Client) generate(ctx context.Context, schema *llm.Schema, prompts []llm.Part) (string, bool, error) {
-	k, h := c.keyAndHashGenerateContent(schema, prompts)
+// generate returns a (possibly cached) response for the prompts,
+// generated using the prompt version, and checked by the moderator
+// if there is one (see [Client.SetModerator]).
+func (c *Client) generate(ctx context.Context, version string, schema *llm.Schema, prompts []llm.Part) (string, bool, error) {
+	result, cached, err := c.generateRaw(ctx, version, schema, prompts)
+	if err != nil || c.moderator == nil {
+		return result, cached, err
+	}
+	// Responses are cached as generated, so that they are
+	// checked by the moderator as configured when they are used.
+	result, err = c.moderator.Check(ctx, result, prompts...)
+	if err != nil {
+		return "", cached, err
+	}
+	return result, cached, nil
+}
+
+// generateRaw is like generate, but does not check the response
+// with the moderator.
+func (c *Client) generateRaw(ctx context.Context, version string, schema *llm.Schema, prompts []llm.Part) (string, bool, error) {
+	k, h := c.keyAndHashGenerateContent(version, schema, prompts)
 	c.db.Lock(string(k))
 	defer c.db.Unlock(string(k))
 
 	r := load[responseGenerateContent](c, k)
-	if r != nil {
+	if r == nil {
+		r = c.migrate(k, h, version)
+	}
+	if r != nil && !c.expired(r) {
 		// cache hit
+		c.stats.hits.Add(1)
 		return r.Response, true, nil
 	}
 
 	// cache miss
-	result, err := c.g.GenerateContent(ctx, schema, prompts)
+	c.stats.misses.Add(1)
+	if r != nil {
+		c.stats.expired.Add(1)
+	}
+	if c.batch != nil {
+		c.batch.add(k, h, schema, prompts)
+		return "", false, ErrBatched
+	}
+	result, model, err := llm.GenerateContentModel(ctx, c.g, schema, prompts)
 	if err != nil {
 		return "", false, err
 	}
 
 	c.db.Set(k, storage.JSON(responseGenerateContent{
-		Model:      c.g.Model(),
+		Model:      model,
+		Version:    version,
+		Time:       time.Now(),
 		PromptHash: h,
 		Response:   result,
 	}))
 	return result, false, nil
 }
+
+// migrate moves the cached response for the input hash h from
+// its older form of key, if any, to the key k for the prompt version,
+// and returns it.
+func (c *Client) migrate(k, h []byte, version string) *responseGenerateContent {
+	lk := c.legacyKeyGenerateContent(h)
+	r := load[responseGenerateContent](c, lk)
+	if r == nil {
+		return nil
+	}
+	r.Version = version
+	b := c.db.Batch()
+	b.Set(k, storage.JSON(r))
+	b.Delete(lk)
+	b.Apply()
+	return r
+}
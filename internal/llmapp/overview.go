@@ -9,25 +9,35 @@
 //
 // Cached LLM responses are stored in the Client's database as:
 //
-//	("llmapp.GenerateText", generativeModel, promptHash) -> [response]
+//	("llmapp.Generate", promptVersion, generativeModel, promptHash) -> [response]
 //
-// Note that currently there is no clear way to clean up old cache values
-// that are no longer relevant, but we might want to add this in the future.
+// Cached responses can be given a time to live ([Client.SetCacheTTL]),
+// and the responses for a prompt version, or all responses,
+// can be deleted with [Client.InvalidateCache].
 //
-// We can, however, easily delete ALL cache values and start over by deleting
-// all database entries starting with "llmapp.GenerateText".
+// The built-in prompts can be replaced by deployment-specific
+// ones (see [Prompt]).
+//
+// Documents such as issues and comments are untrusted input:
+// in prompts, they are enclosed in delimiters and stripped of
+// instruction-like content, and the LLM is told not to follow
+// instructions inside them.
 package llmapp
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
 	_ "embed"
 	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
 	"text/template"
+	"time"
 
 	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/moderate"
 	"golang.org/x/oscar/internal/storage"
 )
 
@@ -36,7 +46,19 @@ type Client struct {
 	slog    *slog.Logger
 	g       llm.ContentGenerator
 	checker llm.PolicyChecker
-	db      storage.DB // cache for LLM responses
+	db      storage.DB                     // cache for LLM responses
+	batch   *Batch                         // if non-nil, record cache misses here instead of generating
+	batcher llm.BatchContentGenerator      // if non-nil, generator for batches; see [Client.SetBatchGenerator]
+	length  Length                         // length of generated overviews (default [Long])
+	lang    string                         // language of generated overviews; see [Client.WithLanguage]
+	prompts map[docsKind]*registeredPrompt // prompts replacing the built-in ones; see [Client.SetPrompt]
+
+	contextWindow int // size of the model's context window, in tokens; see [Client.SetContextWindow]
+
+	cacheTTL time.Duration // time to live of cached responses; see [Client.SetCacheTTL]
+	stats    *cacheStats   // cache statistics, shared with derived Clients
+
+	moderator *moderate.Filter // if non-nil, checks responses; see [Client.SetModerator]
 }
 
 // New returns a new client.
@@ -80,10 +102,110 @@ func (c *Client) UpdatedPostOverview(ctx context.Context, post *Doc, oldComments
 	)
 }
 
+// PullRequestOverview returns an LLM-generated overview of the given pull
+// request, its comments, and its review threads, styled with markdown.
+// Each review thread is a list of review comments, starting with the
+// comment that began the thread.
+// PullRequestOverview returns an error if no pull request is provided or the
+// LLM is unable to generate a response.
+func (c *Client) PullRequestOverview(ctx context.Context, pr *Doc, comments []*Doc, threads [][]*Doc) (*Result, error) {
+	if pr == nil {
+		return nil, errors.New("llmapp PullRequestOverview: no pull request")
+	}
+	groups := []*docGroup{
+		{label: "pull request", docs: []*Doc{pr}},
+		{label: "comments", docs: comments},
+	}
+	for i, t := range threads {
+		groups = append(groups, &docGroup{label: fmt.Sprintf("review thread %d", i+1), docs: t})
+	}
+	return c.overview(ctx, pullRequest, groups...)
+}
+
+// A Length is the desired length of an overview.
+type Length string
+
+const (
+	Short  Length = "short"  // at most 3 sentences, for example for triage comments
+	Medium Length = "medium" // a few paragraphs
+	Long   Length = "long"   // a comprehensive summary; the default
+	TLDR   Length = "tldr"   // exactly 2 sentences, for example to lead a longer summary
+)
+
+// ParseLength parses the name of a [Length].
+// The empty string is parsed as [Long].
+func ParseLength(s string) (Length, error) {
+	switch l := Length(s); l {
+	case "":
+		return Long, nil
+	case Short, Medium, Long, TLDR:
+		return l, nil
+	}
+	return "", fmt.Errorf("llmapp: unknown overview length %q", s)
+}
+
+// instructions returns the additional instruction prompt for
+// overviews of length l, or "" if there are none.
+func (l Length) instructions() string {
+	if l == "" || l == Long {
+		return ""
+	}
+	w := &strings.Builder{}
+	if err := tmpls.ExecuteTemplate(w, "length-"+string(l), nil); err != nil {
+		// unreachable except bug in this package
+		panic(err)
+	}
+	return w.String()
+}
+
+// WithLength returns a copy of the client that generates overviews
+// of the given length. The length applies to the plain-text overviews
+// ([Client.Overview], [Client.PostOverview] and [Client.UpdatedPostOverview]),
+// not to structured results such as [Client.AnalyzeRelated].
+func (c *Client) WithLength(l Length) *Client {
+	lc := *c
+	lc.length = l
+	return &lc
+}
+
+// WithLanguage returns a copy of the client that writes overviews
+// in the language with the given BCP 47 tag, such as "ja" or "pt-BR".
+// Like the length, the language applies to the plain-text overviews.
+// The empty string, the default, leaves the language to the prompt.
+func (c *Client) WithLanguage(lang string) *Client {
+	lc := *c
+	lc.lang = lang
+	return &lc
+}
+
+// languageInstructions returns the additional instruction prompt
+// for overviews in the language lang, or "" if there are none.
+func languageInstructions(lang string) string {
+	if lang == "" {
+		return ""
+	}
+	w := &strings.Builder{}
+	if err := tmpls.ExecuteTemplate(w, "language", lang); err != nil {
+		// unreachable except bug in this package
+		panic(err)
+	}
+	return w.String()
+}
+
+// WithContentGenerator returns a copy of the client that generates content
+// using g, such as a generator that attributes its use to a feature.
+// The copy shares the cache, prompts and cache statistics of c.
+func (c *Client) WithContentGenerator(g llm.ContentGenerator) *Client {
+	gc := *c
+	gc.g = g
+	return &gc
+}
+
 // a docGroup is a group of documents.
 type docGroup struct {
-	label string // (optional) label for the group to give to the LLM.
-	docs  []*Doc
+	label   string // (optional) label for the group to give to the LLM.
+	docs    []*Doc
+	trusted bool // the docs are written by us, not by users; see [prompt]
 }
 
 // overview returns an LLM-generated overview of the given documents.
@@ -95,9 +217,26 @@ func (c *Client) overview(ctx context.Context, kind docsKind, groups ...*docGrou
 	if len(groups) == 0 {
 		return nil, errors.New("llmapp overview: no documents")
 	}
-	prompt := prompt(kind, groups)
+	instr, label := c.instructions(kind)
+	groups, err := c.fit(ctx, kind, instr, groups)
+	if err != nil {
+		return nil, err
+	}
+	prompt := prompt(instr, groups)
 	schema := kind.schema()
-	overview, cached, err := c.generate(ctx, schema, prompt)
+	version := promptVersion(instr, schema)
+	if li := c.length.instructions(); li != "" && schema == nil {
+		prompt = append(prompt, llm.Text(li))
+		version = lengthVersion(version, li)
+	}
+	if li := languageInstructions(c.lang); li != "" && schema == nil {
+		prompt = append(prompt, llm.Text(li))
+		version = lengthVersion(version, li)
+	}
+	if label != "" {
+		version = label + "-" + version
+	}
+	overview, cached, err := c.generate(ctx, version, schema, prompt)
 	if err != nil {
 		return nil, err
 	}
@@ -106,24 +245,32 @@ func (c *Client) overview(ctx context.Context, kind docsKind, groups ...*docGrou
 		Cached:           cached,
 		Schema:           schema,
 		Prompt:           prompt,
+		Model:            c.g.Model(),
+		PromptVersion:    version,
 		PolicyEvaluation: c.EvaluatePolicy(ctx, prompt, overview),
 	}, nil
 }
 
 // prompt converts the given docs into a slice of
-// text prompts, followed by an instruction prompt based
-// on the documents kind.
-func prompt(kind docsKind, groups []*docGroup) []llm.Part {
-	var inputs []llm.Part
+// text prompts, preceded by the instructions for untrusted documents
+// and followed by the instruction prompt instr.
+// The docs of groups that are not trusted are sanitized
+// (see sanitize.go).
+func prompt(instr string, groups []*docGroup) []llm.Part {
+	inputs := []llm.Part{untrustedInstructions()}
 	for _, g := range groups {
 		if g.label != "" {
 			inputs = append(inputs, llm.Text(g.label))
 		}
 		for _, d := range g.docs {
-			inputs = append(inputs, llm.Text(storage.JSON(d)))
+			if g.trusted {
+				inputs = append(inputs, llm.Text(storage.JSON(d)))
+			} else {
+				inputs = append(inputs, untrusted(d))
+			}
 		}
 	}
-	return append(inputs, llm.Text(kind.instructions()))
+	return append(inputs, llm.Text(instr))
 }
 
 // docsKind is a descriptor for a group of documents.
@@ -141,13 +288,42 @@ var (
 	// The documents represent a document followed by documents
 	// that are related to it in some way.
 	docAndRelated docsKind = "doc_and_related"
+	// The documents represent a document followed by numbered
+	// documents related to it, whose connections to explain.
+	docAndExplanations docsKind = "doc_and_explanations"
+	// The documents represent numbered documents, such as
+	// new issues, to group by theme.
+	docsThemes docsKind = "docs_themes"
+	// The documents represent a search query followed by
+	// candidate search results to rank.
+	queryAndCandidates docsKind = "query_and_candidates"
+	// The documents represent an issue followed by
+	// earlier issues that it may duplicate.
+	issueAndCandidates docsKind = "issue_and_candidates"
+	// The documents represent a pull request, its comments,
+	// and its review threads.
+	pullRequest docsKind = "pull_request"
+	// The documents represent a pull request followed by
+	// the diffs of the files it changes, or summaries of them.
+	pullRequestDiff docsKind = "pull_request_diff"
+	// The documents represent a pull request followed by
+	// the diffs of some of the files it changes.
+	diffChunk docsKind = "diff_chunk"
+	// The documents represent a pull request, the diffs of
+	// the files it changes (or summaries of them), and
+	// (possibly) the project's review guidelines.
+	reviewChecklist docsKind = "review_checklist"
+	// The documents represent a post followed by some of the
+	// comments on it, or summaries of them, in a discussion
+	// too long for a single prompt (see [Client.SetContextWindow]).
+	docsChunk docsKind = "docs_chunk"
 )
 
 //go:embed prompts/*.tmpl
 var promptFS embed.FS
 var tmpls = template.Must(template.ParseFS(promptFS, "prompts/*.tmpl"))
 
-// instructions returns the instruction prompt for the given
+// instructions returns the built-in instruction prompt for the given
 // document kind.
 func (k docsKind) instructions() string {
 	w := &strings.Builder{}
@@ -159,13 +335,50 @@ func (k docsKind) instructions() string {
 	return w.String()
 }
 
+// version returns a short string identifying the built-in
+// instructions and schema for the given document kind.
+// It changes whenever the prompt template or schema changes,
+// so that results generated with different prompts can be told apart.
+func (k docsKind) version() string {
+	return promptVersion(k.instructions(), k.schema())
+}
+
+// promptVersion returns a short string identifying
+// the instructions instr and the schema.
+func promptVersion(instr string, schema *llm.Schema) string {
+	h := sha256.New()
+	h.Write([]byte(untrustedInstructions()))
+	h.Write([]byte(instr))
+	writeObjectToHash(h, schema)
+	return fmt.Sprintf("%x", h.Sum(nil))[:12]
+}
+
+// lengthVersion returns the prompt version for the
+// instructions with the given version followed by the
+// length or language instructions li.
+func lengthVersion(version, li string) string {
+	h := sha256.New()
+	h.Write([]byte(version))
+	h.Write([]byte(li))
+	return fmt.Sprintf("%x", h.Sum(nil))[:12]
+}
+
 // schema returns the JSON schema for the given document kind,
 // or nil if there is no corresponding JSON schema.
 // TODO(tatianabradley): Use schemas instead of unstructured
 // prompts for all [docsKind]s.
 func (k docsKind) schema() *llm.Schema {
-	if k == docAndRelated {
+	switch k {
+	case docAndRelated:
 		return relatedSchema
+	case docAndExplanations:
+		return explainSchema
+	case docsThemes:
+		return themesSchema
+	case queryAndCandidates:
+		return rerankSchema
+	case issueAndCandidates:
+		return duplicateSchema
 	}
 	return nil
 }
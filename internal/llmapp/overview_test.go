@@ -27,10 +27,12 @@ func TestOverview(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		promptParts := []llm.Part{raw1, raw2, llm.Text(documents.instructions())}
+		promptParts := []llm.Part{guard, raw1, raw2, llm.Text(documents.instructions())}
 		want := &Result{
-			Response: llm.EchoTextResponse(promptParts...),
-			Prompt:   promptParts,
+			Response:      llm.EchoTextResponse(promptParts...),
+			Prompt:        promptParts,
+			Model:         "echo",
+			PromptVersion: documents.version(),
 		}
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("Overview() mismatch (-want +got):\n%s", diff)
@@ -42,10 +44,12 @@ func TestOverview(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		promptParts := []llm.Part{llm.Text("post"), raw1, llm.Text("comments"), raw2, llm.Text(postAndComments.instructions())}
+		promptParts := []llm.Part{guard, llm.Text("post"), raw1, llm.Text("comments"), raw2, llm.Text(postAndComments.instructions())}
 		want := &Result{
-			Response: llm.EchoTextResponse(promptParts...),
-			Prompt:   promptParts,
+			Response:      llm.EchoTextResponse(promptParts...),
+			Prompt:        promptParts,
+			Model:         "echo",
+			PromptVersion: postAndComments.version(),
 		}
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("PostOverview() mismatch (-want +got):\n%s", diff)
@@ -57,24 +61,107 @@ func TestOverview(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		promptParts := []llm.Part{llm.Text("post"), raw1, llm.Text("old comments"), raw2, llm.Text("new comments"), raw3, llm.Text(postAndCommentsUpdated.instructions())}
+		promptParts := []llm.Part{guard, llm.Text("post"), raw1, llm.Text("old comments"), raw2, llm.Text("new comments"), raw3, llm.Text(postAndCommentsUpdated.instructions())}
 		want := &Result{
-			Response: llm.EchoTextResponse(promptParts...),
-			Prompt:   promptParts,
+			Response:      llm.EchoTextResponse(promptParts...),
+			Prompt:        promptParts,
+			Model:         "echo",
+			PromptVersion: postAndCommentsUpdated.version(),
 		}
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("UpdatedPostOverview() mismatch (-want +got):\n%s", diff)
 		}
 	})
+
+	t.Run("PullRequestOverview", func(t *testing.T) {
+		got, err := c.PullRequestOverview(ctx, doc1, []*Doc{doc2}, [][]*Doc{{doc3}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		promptParts := []llm.Part{guard, llm.Text("pull request"), raw1, llm.Text("comments"), raw2, llm.Text("review thread 1"), raw3, llm.Text(pullRequest.instructions())}
+		want := &Result{
+			Response:      llm.EchoTextResponse(promptParts...),
+			Prompt:        promptParts,
+			Model:         "echo",
+			PromptVersion: pullRequest.version(),
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("PullRequestOverview() mismatch (-want +got):\n%s", diff)
+		}
+		if _, err := c.PullRequestOverview(ctx, nil, nil, nil); err == nil {
+			t.Error("PullRequestOverview(nil) succeeded, want error")
+		}
+	})
+
+	t.Run("Length", func(t *testing.T) {
+		for _, l := range []Length{Short, Medium, TLDR} {
+			got, err := c.WithLength(l).PostOverview(ctx, doc1, []*Doc{doc2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			promptParts := []llm.Part{guard, llm.Text("post"), raw1, llm.Text("comments"), raw2, llm.Text(postAndComments.instructions()), llm.Text(l.instructions())}
+			want := &Result{
+				Response:      llm.EchoTextResponse(promptParts...),
+				Prompt:        promptParts,
+				Model:         "echo",
+				PromptVersion: lengthVersion(postAndComments.version(), l.instructions()),
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("WithLength(%s).PostOverview() mismatch (-want +got):\n%s", l, diff)
+			}
+			if got.PromptVersion == postAndComments.version() {
+				t.Errorf("WithLength(%s): prompt version unchanged", l)
+			}
+		}
+
+		// Long is the default.
+		got, err := c.WithLength(Long).PostOverview(ctx, doc1, []*Doc{doc2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.PromptVersion != postAndComments.version() {
+			t.Errorf("WithLength(Long): prompt version %s, want %s", got.PromptVersion, postAndComments.version())
+		}
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		got, err := c.WithLength(TLDR).WithLanguage("ja").PostOverview(ctx, doc1, []*Doc{doc2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		li := languageInstructions("ja")
+		if !strings.Contains(li, `"ja"`) {
+			t.Errorf("language instructions %q do not name the language", li)
+		}
+		promptParts := []llm.Part{guard, llm.Text("post"), raw1, llm.Text("comments"), raw2, llm.Text(postAndComments.instructions()), llm.Text(TLDR.instructions()), llm.Text(li)}
+		want := &Result{
+			Response:      llm.EchoTextResponse(promptParts...),
+			Prompt:        promptParts,
+			Model:         "echo",
+			PromptVersion: lengthVersion(lengthVersion(postAndComments.version(), TLDR.instructions()), li),
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("WithLanguage(ja).PostOverview() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("PromptVersion", func(t *testing.T) {
+		if documents.version() == postAndComments.version() {
+			t.Errorf("documents and postAndComments have the same prompt version %s", documents.version())
+		}
+	})
 }
 
 var (
 	doc1 = &Doc{URL: "https://example.com", Author: "rsc", Title: "title", Text: "some text"}
 	doc2 = &Doc{Text: "some text 2"}
 	doc3 = &Doc{Text: "some text 3"}
-	raw1 = llm.Text(`{"url":"https://example.com","author":"rsc","title":"title","text":"some text"}`)
-	raw2 = llm.Text(`{"text":"some text 2"}`)
-	raw3 = llm.Text(`{"text":"some text 3"}`)
+	raw1 = untrusted(doc1)
+	raw2 = untrusted(doc2)
+	raw3 = untrusted(doc3)
+
+	// guard is the first part of every prompt.
+	guard = untrustedInstructions()
 )
 
 func newTestClient(t *testing.T) *Client {
@@ -91,7 +178,7 @@ func TestGenerate(t *testing.T) {
 	t.Run("echo", func(t *testing.T) {
 		c := New(lg, llm.EchoContentGenerator(), db)
 		prompt := []llm.Part{llm.Text("a"), llm.Text("b"), llm.Text("c")}
-		got, cached, err := c.generate(ctx, nil, prompt)
+		got, cached, err := c.generate(ctx, "v1", nil, prompt)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -104,7 +191,7 @@ func TestGenerate(t *testing.T) {
 		}
 
 		// The result should be cached on the second call.
-		got, cached, err = c.generate(ctx, nil, prompt)
+		got, cached, err = c.generate(ctx, "v1", nil, prompt)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -121,7 +208,7 @@ func TestGenerate(t *testing.T) {
 	t.Run("random", func(t *testing.T) {
 		c := New(lg, randomContentGenerator(), db)
 		prompt := []llm.Part{llm.Text("a"), llm.Text("b"), llm.Text("c")}
-		got1, cached, err := c.generate(ctx, nil, prompt)
+		got1, cached, err := c.generate(ctx, "v1", nil, prompt)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -129,7 +216,7 @@ func TestGenerate(t *testing.T) {
 			t.Error("generate() = cached, want not cached")
 		}
 
-		got2, cached, err := c.generate(ctx, nil, prompt)
+		got2, cached, err := c.generate(ctx, "v1", nil, prompt)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -157,6 +244,27 @@ func randomContentGenerator() llm.ContentGenerator {
 	)
 }
 
+func TestParseLength(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want Length
+	}{
+		{"", Long},
+		{"short", Short},
+		{"medium", Medium},
+		{"long", Long},
+		{"tldr", TLDR},
+	} {
+		got, err := ParseLength(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLength(%q) = %q, %v; want %q, nil", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseLength("tiny"); err == nil {
+		t.Error("ParseLength(tiny) succeeded, want error")
+	}
+}
+
 func TestResponseUnmarshal(t *testing.T) {
 	// Do not remove or edit this test case without a good reason.
 	// It ensures that no backwards incompatible changes are made to the [response] struct.
@@ -0,0 +1,210 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+//go:generate go run golang.org/x/oscar/internal/devtools/cmd/kindgen -type Prompt -name storedPrompt -kind llmapp.Prompt -key Name
+
+// A Prompt is a named, versioned instruction prompt, which replaces
+// the built-in instructions that a [Client] gives the LLM for one kind
+// of request, so that deployments can tune prompts without changing code.
+//
+// Prompts are stored in the database as:
+//
+//	("llmapp.Prompt", name) -> [Prompt], encoded by [storage.EncodeRecord]
+type Prompt struct {
+	// Name is the name of the built-in prompt to replace
+	// (see [PromptNames]), such as "post_and_comments" for
+	// [Client.PostOverview] or "post_and_comments_updated"
+	// for [Client.UpdatedPostOverview].
+	Name string
+	// Version labels the prompt. It is recorded, along with a hash
+	// of the prompt, in the PromptVersion of the results generated
+	// using the prompt (see [Result]).
+	Version string
+	// Template is the text/template source of the prompt.
+	// It may use the templates defined by the built-in prompts,
+	// such as {{template "requirements"}}.
+	Template string
+}
+
+// A registeredPrompt is a Prompt with its parsed template.
+type registeredPrompt struct {
+	p    *Prompt
+	tmpl *template.Template
+}
+
+// PromptNames returns the names of the prompts that can be
+// replaced using [Client.SetPrompt], in sorted order.
+func PromptNames() []string {
+	var names []string
+	for _, k := range promptKinds {
+		names = append(names, string(k))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// promptKinds lists the document kinds whose prompts can be replaced.
+var promptKinds = []docsKind{
+	documents,
+	postAndComments,
+	postAndCommentsUpdated,
+	docAndRelated,
+	docAndExplanations,
+	docsThemes,
+	queryAndCandidates,
+	issueAndCandidates,
+	pullRequest,
+	pullRequestDiff,
+	diffChunk,
+	reviewChecklist,
+	docsChunk,
+}
+
+// SetPrompt configures the Client (and the copies made from it later,
+// for example by [Client.WithLength]) to use the prompt p instead of
+// the built-in prompt with the same name.
+// It returns an error if there is no such built-in prompt, p has no
+// version, or its template cannot be parsed or executed.
+func (c *Client) SetPrompt(p *Prompt) error {
+	rp, err := parsePrompt(p)
+	if err != nil {
+		return err
+	}
+	if c.prompts == nil {
+		c.prompts = make(map[docsKind]*registeredPrompt)
+	}
+	c.prompts[docsKind(p.Name)] = rp
+	c.slog.Info("llmapp: using prompt", "name", p.Name, "version", p.Version)
+	return nil
+}
+
+// parsePrompt checks and parses p.
+func parsePrompt(p *Prompt) (*registeredPrompt, error) {
+	if !slices.Contains(promptKinds, docsKind(p.Name)) {
+		return nil, fmt.Errorf("llmapp: unknown prompt %q (known prompts: %s)", p.Name, strings.Join(PromptNames(), ", "))
+	}
+	if p.Version == "" {
+		return nil, fmt.Errorf("llmapp: prompt %q has no version", p.Name)
+	}
+	t, err := tmpls.Clone()
+	if err != nil {
+		// unreachable except bug in this package
+		return nil, err
+	}
+	t, err = t.New(p.Name).Parse(p.Template)
+	if err != nil {
+		return nil, fmt.Errorf("llmapp: prompt %q: %w", p.Name, err)
+	}
+	if err := t.Execute(&strings.Builder{}, nil); err != nil {
+		return nil, fmt.Errorf("llmapp: prompt %q: %w", p.Name, err)
+	}
+	return &registeredPrompt{p: p, tmpl: t}, nil
+}
+
+// Prompts returns the prompts set by [Client.SetPrompt],
+// in name order.
+func (c *Client) Prompts() []*Prompt {
+	var ps []*Prompt
+	for _, rp := range c.prompts {
+		ps = append(ps, rp.p)
+	}
+	slices.SortFunc(ps, func(x, y *Prompt) int { return strings.Compare(x.Name, y.Name) })
+	return ps
+}
+
+// instructions returns the instruction prompt for the given document
+// kind and the label of its version: the prompt set by [Client.SetPrompt],
+// if any, and its version, or else the built-in prompt and "".
+func (c *Client) instructions(k docsKind) (instr, label string) {
+	rp := c.prompts[k]
+	if rp == nil {
+		return k.instructions(), ""
+	}
+	w := &strings.Builder{}
+	if err := rp.tmpl.Execute(w, nil); err != nil {
+		// unreachable: checked by parsePrompt
+		panic(err)
+	}
+	return w.String(), rp.p.Version
+}
+
+// SavePrompt checks the prompt p and stores it in the Client's database,
+// replacing any earlier prompt with the same name.
+// [Client.LoadPrompts] uses the stored prompts.
+func (c *Client) SavePrompt(p *Prompt) error {
+	if _, err := parsePrompt(p); err != nil {
+		return err
+	}
+	setStoredPrompt(c.db, p)
+	c.db.Flush()
+	return nil
+}
+
+// DeletePrompt deletes the prompt with the given name
+// from the Client's database, if present.
+func (c *Client) DeletePrompt(name string) {
+	deleteStoredPrompt(c.db, name)
+	c.db.Flush()
+}
+
+// LoadPrompts configures the Client to use the prompts
+// stored in its database by [Client.SavePrompt].
+func (c *Client) LoadPrompts() error {
+	for p := range scanStoredPrompt(c.db) {
+		if err := c.SetPrompt(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// versionComment matches the version comment of a prompt file.
+var versionComment = regexp.MustCompile(`^\{\{/\*\s*version:\s*(\S+)\s*\*/\}\}\n?`)
+
+// LoadPromptDir configures the Client to use the prompts in the
+// NAME.tmpl files in dir, where NAME is the name of the prompt
+// (see [Prompt]). Each file must begin with a comment giving
+// the prompt's version, such as
+//
+//	{{/* version: v2 */}}
+//
+// followed by the prompt's template.
+// Files in dir with other extensions are ignored.
+func (c *Client) LoadPromptDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		m := versionComment.FindSubmatch(data)
+		if m == nil {
+			return fmt.Errorf("llmapp: %s: missing {{/* version: V */}} comment", file)
+		}
+		p := &Prompt{
+			Name:     strings.TrimSuffix(filepath.Base(file), ".tmpl"),
+			Version:  string(m[1]),
+			Template: string(data[len(m[0]):]),
+		}
+		if err := c.SetPrompt(p); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestSetPrompt(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+	p := &Prompt{
+		Name:     "post_and_comments",
+		Version:  "v2",
+		Template: `Summarize the post briefly. {{template "requirements"}}`,
+	}
+	if err := c.SetPrompt(p); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.PostOverview(ctx, doc1, []*Doc{doc2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	instr := got.Prompt[len(got.Prompt)-1].(llm.Text)
+	if !strings.HasPrefix(string(instr), "Summarize the post briefly. Formatting Requirements:") {
+		t.Errorf("PostOverview instructions = %q, want the prompt's", instr)
+	}
+	if want := "v2-" + promptVersion(string(instr), nil); got.PromptVersion != want {
+		t.Errorf("PromptVersion = %q, want %q", got.PromptVersion, want)
+	}
+
+	// Other prompts are unchanged.
+	got, err = c.UpdatedPostOverview(ctx, doc1, []*Doc{doc2}, []*Doc{doc3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PromptVersion != postAndCommentsUpdated.version() {
+		t.Errorf("UpdatedPostOverview PromptVersion = %q, want built-in %q", got.PromptVersion, postAndCommentsUpdated.version())
+	}
+	if diff := cmp.Diff([]*Prompt{p}, c.Prompts()); diff != "" {
+		t.Errorf("Prompts() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetPromptErrors(t *testing.T) {
+	c := newTestClient(t)
+	for _, tt := range []struct {
+		p    *Prompt
+		want string
+	}{
+		{&Prompt{Name: "unknown", Version: "v1", Template: "x"}, "unknown prompt"},
+		{&Prompt{Name: "documents", Template: "x"}, "no version"},
+		{&Prompt{Name: "documents", Version: "v1", Template: "{{"}, "unclosed action"},
+		{&Prompt{Name: "documents", Version: "v1", Template: `{{template "missing"}}`}, "not defined"},
+	} {
+		if err := c.SetPrompt(tt.p); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("SetPrompt(%+v) = %v, want error containing %q", tt.p, err, tt.want)
+		}
+	}
+	if ps := c.Prompts(); len(ps) != 0 {
+		t.Errorf("after errors, Prompts() = %v, want none", ps)
+	}
+}
+
+func TestLoadPrompts(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	p := &Prompt{Name: "documents", Version: "v3", Template: "Summarize."}
+	check(New(lg, llm.EchoContentGenerator(), db).SavePrompt(p))
+
+	c := New(lg, llm.EchoContentGenerator(), db)
+	check(c.LoadPrompts())
+	if diff := cmp.Diff([]*Prompt{p}, c.Prompts()); diff != "" {
+		t.Errorf("LoadPrompts: Prompts() mismatch (-want +got):\n%s", diff)
+	}
+
+	c.DeletePrompt("documents")
+	c = New(lg, llm.EchoContentGenerator(), db)
+	check(c.LoadPrompts())
+	if ps := c.Prompts(); len(ps) != 0 {
+		t.Errorf("after DeletePrompt, Prompts() = %v, want none", ps)
+	}
+}
+
+func TestLoadPromptDir(t *testing.T) {
+	check := testutil.Checker(t)
+	dir := t.TempDir()
+	write := func(name, data string) {
+		check(os.WriteFile(filepath.Join(dir, name), []byte(data), 0o666))
+	}
+	write("documents.tmpl", "{{/* version: v4 */}}\nSummarize.")
+	write("README", "ignored")
+
+	c := newTestClient(t)
+	check(c.LoadPromptDir(dir))
+	want := []*Prompt{{Name: "documents", Version: "v4", Template: "Summarize."}}
+	if diff := cmp.Diff(want, c.Prompts()); diff != "" {
+		t.Errorf("LoadPromptDir: Prompts() mismatch (-want +got):\n%s", diff)
+	}
+
+	write("post_and_comments.tmpl", "Summarize.")
+	if err := c.LoadPromptDir(dir); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("LoadPromptDir without version = %v, want missing version error", err)
+	}
+}
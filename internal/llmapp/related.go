@@ -74,7 +74,7 @@ var relatedSchema = &llm.Schema{
 					},
 					"relationship": {
 						Type:        llm.TypeString,
-						Description: "Explain how the document is related to the original document.",
+						Description: "In one line, explain the specific connection to the original document, such as a matching symptom, the same API, or the same crash signature.",
 					},
 					"relevance": {
 						Type: llm.TypeString,
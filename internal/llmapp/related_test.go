@@ -21,13 +21,15 @@ func TestAnalyzeRelated(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		promptParts := []llm.Part{llm.Text("original"), raw1, llm.Text("related"), raw2, llm.Text(docAndRelated.instructions())}
+		promptParts := []llm.Part{guard, llm.Text("original"), raw1, llm.Text("related"), raw2, llm.Text(docAndRelated.instructions())}
 		rawOut, out := relatedTestOutput(t, 1)
 		want := &RelatedAnalysis{
 			Result: Result{
-				Response: rawOut,
-				Prompt:   promptParts,
-				Schema:   docAndRelated.schema(),
+				Response:      rawOut,
+				Prompt:        promptParts,
+				Schema:        docAndRelated.schema(),
+				Model:         "test-model",
+				PromptVersion: docAndRelated.version(),
 			},
 			Output: out,
 		}
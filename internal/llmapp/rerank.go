@@ -0,0 +1,101 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/oscar/internal/llm"
+)
+
+// RerankResult is the output of [Client.Rerank].
+type RerankResult struct {
+	Result
+	// The indexes of the candidates, most relevant first.
+	// Each index appears exactly once.
+	Order []int
+}
+
+// Ranking represents the desired JSON structure of the LLM output
+// requested by [Client.Rerank].
+//
+// IMPORTANT: If you edit the types or JSON names of fields in this
+// struct, edit [rerankSchema] accordingly.
+type Ranking struct {
+	Ranking []int `json:"ranking"`
+}
+
+// The [*llm.Schema] corresponding to the [Ranking] type.
+var rerankSchema = &llm.Schema{
+	Type: llm.TypeObject,
+	Properties: map[string]*llm.Schema{
+		"ranking": {
+			Type:        llm.TypeArray,
+			Items:       &llm.Schema{Type: llm.TypeInteger},
+			Description: "The numbers of the candidate documents, most relevant first.",
+		},
+	},
+	Required: []string{"ranking"},
+}
+
+// Rerank asks the LLM to order the candidate documents by their
+// relevance to the query document.
+// Candidates the LLM leaves out of its ranking are placed after
+// the ranked ones, in their original order.
+// Rerank returns an error if there is no query or there are no candidates,
+// or if the LLM is unable to generate a well-formed ranking.
+func (c *Client) Rerank(ctx context.Context, query *Doc, candidates []*Doc) (*RerankResult, error) {
+	if query == nil {
+		return nil, errors.New("llmapp Rerank: no query")
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("llmapp Rerank: no candidates")
+	}
+	groups := []*docGroup{{label: "query", docs: []*Doc{query}}}
+	for i, d := range candidates {
+		groups = append(groups, &docGroup{label: fmt.Sprintf("candidate %d", i), docs: []*Doc{d}})
+	}
+	result, err := c.overview(ctx, queryAndCandidates, groups...)
+	if err != nil {
+		return nil, fmt.Errorf("llmapp Rerank: cannot generate response: %w", err)
+	}
+	var typed Ranking
+	if err := json.Unmarshal([]byte(result.Response), &typed); err != nil {
+		return nil, fmt.Errorf("llmapp Rerank: cannot unmarshal response: %w\nresponse: %s", err, result.Response)
+	}
+	order, err := rankingOrder(typed.Ranking, len(candidates))
+	if err != nil {
+		return nil, fmt.Errorf("llmapp Rerank: malformed LLM output (%w)", err)
+	}
+	return &RerankResult{Result: *result, Order: order}, nil
+}
+
+// rankingOrder converts the ranking generated by the LLM into
+// a permutation of [0, n), appending any missing indexes in order.
+// It returns an error if the ranking contains an index
+// out of range or a duplicate.
+func rankingOrder(ranking []int, n int) ([]int, error) {
+	seen := make([]bool, n)
+	var order []int
+	for _, i := range ranking {
+		if i < 0 || i >= n {
+			return nil, fmt.Errorf("candidate %d out of range [0, %d)", i, n)
+		}
+		if seen[i] {
+			return nil, fmt.Errorf("duplicate candidate %d", i)
+		}
+		seen[i] = true
+		order = append(order, i)
+	}
+	for i, ok := range seen {
+		if !ok {
+			order = append(order, i)
+		}
+	}
+	return order, nil
+}
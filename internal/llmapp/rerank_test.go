@@ -0,0 +1,87 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestRerank(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+
+	gen := func(response string) llm.ContentGenerator {
+		return llm.TestContentGenerator("rerank-test-generator",
+			func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+				return response, nil
+			})
+	}
+	doc3 := &Doc{Text: "some text 3"}
+
+	t.Run("basic", func(t *testing.T) {
+		c := New(lg, gen(`{"ranking": [1, 0]}`), storage.MemDB())
+		got, err := c.Rerank(ctx, doc1, []*Doc{doc2, doc3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &RerankResult{
+			Result: Result{
+				Response: `{"ranking": [1, 0]}`,
+				Prompt: []llm.Part{
+					guard,
+					llm.Text("query"), raw1,
+					llm.Text("candidate 0"), raw2,
+					llm.Text("candidate 1"), raw3,
+					llm.Text(queryAndCandidates.instructions()),
+				},
+				Schema:        rerankSchema,
+				Model:         "test-model",
+				PromptVersion: queryAndCandidates.version(),
+			},
+			Order: []int{1, 0},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Rerank() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		c := New(lg, gen(`{"ranking": [2]}`), storage.MemDB())
+		got, err := c.Rerank(ctx, doc1, []*Doc{doc2, doc3, doc1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []int{2, 0, 1}; !slices.Equal(got.Order, want) {
+			t.Errorf("Rerank().Order = %v, want %v", got.Order, want)
+		}
+	})
+
+	for _, response := range []string{
+		`{"ranking": [0, 0]}`,
+		`{"ranking": [2]}`,
+		`{"ranking": [-1]}`,
+		`not json`,
+	} {
+		c := New(lg, gen(response), storage.MemDB())
+		if _, err := c.Rerank(ctx, doc1, []*Doc{doc2, doc3}); err == nil {
+			t.Errorf("Rerank() with response %s succeeded, want error", response)
+		}
+	}
+
+	c := New(lg, gen(`{"ranking": []}`), storage.MemDB())
+	if _, err := c.Rerank(ctx, nil, []*Doc{doc2}); err == nil {
+		t.Error("Rerank(nil query) succeeded, want error")
+	}
+	if _, err := c.Rerank(ctx, doc1, nil); err == nil {
+		t.Error("Rerank(no candidates) succeeded, want error")
+	}
+}
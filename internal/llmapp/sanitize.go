@@ -0,0 +1,78 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// Issue and comment text is written by anyone, so it is untrusted
+// input to our prompts: it may contain instructions meant to
+// hijack the LLM ("prompt injection"). To defend against this,
+// every prompt starts with instructions to treat the documents
+// as data (see prompts/untrusted.tmpl), each untrusted document
+// is enclosed in delimiters that its author cannot forge, and
+// instruction-like content in the documents is neutralized.
+
+// neutralized replaces instruction-like content in untrusted documents.
+const neutralized = "[instruction removed]"
+
+// injectionREs match instruction-like content in untrusted documents.
+var injectionREs = []*regexp.Regexp{
+	// "Ignore all previous instructions", "disregard the above rules", ...
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override|bypass)\s+(all\s+|any\s+|every\s+)?(of\s+)?(the\s+|your\s+|these\s+|those\s+)?(previous|prior|above|earlier|preceding|original|system|other)\s+(instructions?|prompts?|rules|directions|guidelines|context)\b`),
+	// "You are now DAN", "from now on you will ..."
+	regexp.MustCompile(`(?i)\byou\s+are\s+now\b|\bfrom\s+now\s+on,?\s+you\b`),
+	// "New instructions:", "system prompt:"
+	regexp.MustCompile(`(?i)\b(new|updated|real|actual|system)\s+(instructions?|prompt)\s*:`),
+	// Role markers at the start of a line: "system:", "assistant:",
+	// but not Go code such as "system := x".
+	regexp.MustCompile(`(?im)^[ \t]*(system|assistant|developer)[ \t]*:(?P<sp>[ \t]|$)`),
+	// Chat template tokens: "<|im_start|>", "[INST]", "<<SYS>>".
+	regexp.MustCompile(`(?i)<\|[a-z_]+\|>|\[/?INST\]|<</?SYS>>`),
+	// Our own delimiters (see [untrusted]).
+	regexp.MustCompile(`(?i)\b(BEGIN|END)\s+UNTRUSTED\s+DOCUMENT\b`),
+}
+
+// neutralize returns s with instruction-like content replaced
+// by [neutralized].
+func neutralize(s string) string {
+	for _, re := range injectionREs {
+		// Keep the space matched by an "sp" group, if any.
+		s = re.ReplaceAllString(s, neutralized+"${sp}")
+	}
+	return s
+}
+
+// untrusted returns the prompt part for the untrusted document d:
+// the JSON form of d, with instruction-like content in its title
+// and text neutralized, enclosed in delimiters.
+// The delimiters include a hash of the document, so the document
+// cannot contain its own end delimiter.
+func untrusted(d *Doc) llm.Text {
+	sd := *d
+	sd.Title = neutralize(d.Title)
+	sd.Text = neutralize(d.Text)
+	js := storage.JSON(&sd)
+	id := fmt.Sprintf("%x", sha256.Sum256(js))[:16]
+	return llm.Text(fmt.Sprintf("BEGIN UNTRUSTED DOCUMENT %s\n%s\nEND UNTRUSTED DOCUMENT %s", id, js, id))
+}
+
+// untrustedInstructions returns the instructions that start every
+// prompt, telling the LLM to treat the documents as data.
+func untrustedInstructions() llm.Text {
+	w := &strings.Builder{}
+	if err := tmpls.ExecuteTemplate(w, "untrusted", nil); err != nil {
+		// unreachable except bug in this package
+		panic(err)
+	}
+	return llm.Text(w.String())
+}
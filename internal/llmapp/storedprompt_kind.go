@@ -0,0 +1,73 @@
+// Code generated by "kindgen -type Prompt -name storedPrompt -kind llmapp.Prompt -key Name"; DO NOT EDIT.
+
+package llmapp
+
+import (
+	"iter"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// storedPromptKind is the key kind of a stored [Prompt]:
+//
+//	(llmapp.Prompt, Name) -> [Prompt]
+const storedPromptKind = "llmapp.Prompt"
+
+// storedPromptVersion is the encoding version of a stored [Prompt].
+const storedPromptVersion = 1
+
+// storedPromptKey returns the database key of the [Prompt] with the given key fields.
+func storedPromptKey(name string) []byte {
+	return ordered.Encode(storedPromptKind, name)
+}
+
+// getStoredPrompt returns the stored [Prompt] with the given key fields, if any.
+func getStoredPrompt(db storage.DB, name string) (*Prompt, bool) {
+	val, ok := db.Get(storedPromptKey(name))
+	if !ok {
+		return nil, false
+	}
+	return decodeStoredPrompt(db, val), true
+}
+
+// setStoredPrompt stores x under the key made from its key fields.
+func setStoredPrompt(db storage.DB, x *Prompt) {
+	db.Set(storedPromptKey(x.Name), storage.EncodeRecord(storedPromptVersion, x))
+}
+
+// deleteStoredPrompt deletes the stored [Prompt] with the given key fields, if any.
+func deleteStoredPrompt(db storage.DB, name string) {
+	db.Delete(storedPromptKey(name))
+}
+
+// scanStoredPrompt returns the stored [Prompt] records, in key order.
+func scanStoredPrompt(db storage.DB) iter.Seq[*Prompt] {
+	return scanStoredPromptRange(db, ordered.Encode(storedPromptKind), ordered.Encode(storedPromptKind, ordered.Inf))
+}
+
+// scanStoredPromptRange returns the stored [Prompt] records
+// with keys in the range [start, end], in key order.
+func scanStoredPromptRange(db storage.DB, start, end []byte) iter.Seq[*Prompt] {
+	return func(yield func(*Prompt) bool) {
+		for _, val := range db.Scan(start, end) {
+			if !yield(decodeStoredPrompt(db, val())) {
+				return
+			}
+		}
+	}
+}
+
+// decodeStoredPrompt decodes a stored [Prompt].
+func decodeStoredPrompt(db storage.DB, val []byte) *Prompt {
+	var x Prompt
+	v, err := storage.DecodeRecord(val, &x)
+	if err != nil {
+		// unreachable unless database corruption
+		db.Panic("decode llmapp.Prompt", "err", err)
+	}
+	if v > storedPromptVersion {
+		db.Panic("decode llmapp.Prompt: stored with newer encoding", "version", v, "known", storedPromptVersion)
+	}
+	return &x
+}
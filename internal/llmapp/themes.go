@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/oscar/internal/llm"
+)
+
+// ThemesResult is the output of [Client.GroupByTheme].
+type ThemesResult struct {
+	Result
+	Themes []*Theme // the themes, in the order the LLM gave them
+}
+
+// Themes represents the desired JSON structure of the LLM output
+// requested by [Client.GroupByTheme].
+//
+// IMPORTANT: If you edit the types or JSON names of fields in this
+// struct, edit [themesSchema] accordingly.
+type Themes struct {
+	Themes []*Theme `json:"themes"`
+}
+
+// A Theme is a group of documents with a common theme.
+type Theme struct {
+	Name    string `json:"name"`    // a short name for the theme
+	Summary string `json:"summary"` // a one-sentence summary of the documents
+	Docs    []int  `json:"docs"`    // the numbers of the documents with the theme
+}
+
+// The [*llm.Schema] corresponding to the [Themes] type.
+var themesSchema = &llm.Schema{
+	Type: llm.TypeObject,
+	Properties: map[string]*llm.Schema{
+		"themes": {
+			Type: llm.TypeArray,
+			Items: &llm.Schema{
+				Type: llm.TypeObject,
+				Properties: map[string]*llm.Schema{
+					"name": {
+						Type:        llm.TypeString,
+						Description: "A short name for the theme, such as a component or kind of problem.",
+					},
+					"summary": {
+						Type:        llm.TypeString,
+						Description: "A one-sentence summary of the documents with the theme.",
+					},
+					"docs": {
+						Type:        llm.TypeArray,
+						Items:       &llm.Schema{Type: llm.TypeInteger},
+						Description: "The numbers of the documents with the theme.",
+					},
+				},
+				Required: []string{"name", "summary", "docs"},
+			},
+		},
+	},
+	Required: []string{"themes"},
+}
+
+// GroupByTheme asks the LLM to group the documents, typically
+// new issues, by theme, such as the component they affect or the
+// kind of problem they report.
+// Each document is in at most one theme; the LLM may leave out
+// documents that fit no theme.
+// It returns an error if there are no documents, or if the LLM
+// is unable to generate a well-formed response.
+func (c *Client) GroupByTheme(ctx context.Context, docs []*Doc) (*ThemesResult, error) {
+	if len(docs) == 0 {
+		return nil, errors.New("llmapp GroupByTheme: no docs")
+	}
+	var groups []*docGroup
+	for i, d := range docs {
+		groups = append(groups, &docGroup{label: fmt.Sprintf("doc %d", i), docs: []*Doc{d}})
+	}
+	result, err := c.overview(ctx, docsThemes, groups...)
+	if err != nil {
+		return nil, fmt.Errorf("llmapp GroupByTheme: cannot generate response: %w", err)
+	}
+	var typed Themes
+	if err := json.Unmarshal([]byte(result.Response), &typed); err != nil {
+		return nil, fmt.Errorf("llmapp GroupByTheme: cannot unmarshal response: %w\nresponse: %s", err, result.Response)
+	}
+	seen := make(map[int]bool)
+	for _, t := range typed.Themes {
+		for _, d := range t.Docs {
+			if d < 0 || d >= len(docs) {
+				return nil, fmt.Errorf("llmapp GroupByTheme: malformed LLM output (document %d out of range [0, %d))", d, len(docs))
+			}
+			if seen[d] {
+				return nil, fmt.Errorf("llmapp GroupByTheme: malformed LLM output (document %d in more than one theme)", d)
+			}
+			seen[d] = true
+		}
+		t.Name, t.Summary = oneLine(t.Name), oneLine(t.Summary)
+	}
+	return &ThemesResult{Result: *result, Themes: typed.Themes}, nil
+}
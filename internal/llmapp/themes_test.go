@@ -0,0 +1,66 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmapp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestGroupByTheme(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+
+	gen := func(response string) llm.ContentGenerator {
+		return llm.TestContentGenerator("themes-test-generator",
+			func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+				return response, nil
+			})
+	}
+
+	response := `{"themes": [{"name": "runtime\ncrashes", "summary": "Two crashes.", "docs": [1, 0]}]}`
+	c := New(lg, gen(response), storage.MemDB())
+	got, err := c.GroupByTheme(ctx, []*Doc{doc1, doc2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ThemesResult{
+		Result: Result{
+			Response: response,
+			Prompt: []llm.Part{
+				guard,
+				llm.Text("doc 0"), raw1,
+				llm.Text("doc 1"), raw2,
+				llm.Text(docsThemes.instructions()),
+			},
+			Schema:        themesSchema,
+			Model:         "test-model",
+			PromptVersion: docsThemes.version(),
+		},
+		Themes: []*Theme{{Name: "runtime crashes", Summary: "Two crashes.", Docs: []int{1, 0}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GroupByTheme() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, response := range []string{
+		`{"themes": [{"name": "a", "summary": "", "docs": [2]}]}`,
+		`{"themes": [{"name": "a", "summary": "", "docs": [0]}, {"name": "b", "summary": "", "docs": [0]}]}`,
+		`not json`,
+	} {
+		c := New(lg, gen(response), storage.MemDB())
+		if _, err := c.GroupByTheme(ctx, []*Doc{doc1, doc2}); err == nil {
+			t.Errorf("GroupByTheme() with response %s succeeded, want error", response)
+		}
+	}
+	if _, err := c.GroupByTheme(ctx, nil); err == nil {
+		t.Error("GroupByTheme(nil) succeeded, want error")
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package llmcost accounts for the cost of LLM content generation,
+// attributed to the features that request it, and enforces budgets.
+//
+// A [Meter] wraps the [llm.ContentGenerator] used by each feature
+// (see [Meter.ContentGenerator]) and records the number of calls,
+// the prompt and completion tokens and their cost, for each day,
+// feature and model. Token counts are estimated with
+// [llm.EstimateTokens], and costs are computed from per-model
+// prices (see [Meter.SetPrice]).
+//
+// The usage is stored in the database:
+//
+//	(llmcost.Usage, $day, $feature, $model) -> [Usage], encoded by [storage.EncodeRecord]
+//
+// where $day is a UTC date such as "2025-01-02".
+package llmcost
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// ErrOverBudget is returned by the content generators of paused
+// features (see [Meter.Paused]) instead of generating content.
+var ErrOverBudget = errors.New("llmcost: LLM budget exceeded")
+
+// A Price is the price of a model, in dollars per million tokens.
+type Price struct {
+	Input  float64 // prompt tokens
+	Output float64 // completion tokens
+}
+
+// DefaultPrices are the list prices of the default models of the
+// LLM providers, as of this writing.
+var DefaultPrices = map[string]Price{
+	"gemini-1.5-pro":           {Input: 1.25, Output: 5.00},
+	"gpt-4o":                   {Input: 2.50, Output: 10.00},
+	"claude-3-5-sonnet-latest": {Input: 3.00, Output: 15.00},
+}
+
+// spentTTL is how long a Meter uses the amounts spent
+// before reading them from the database again, which
+// accounts for the spending of other processes.
+const spentTTL = time.Minute
+
+// A Meter records the use of LLMs and enforces budgets.
+type Meter struct {
+	slog      *slog.Logger
+	db        storage.DB
+	now       func() time.Time
+	prices    map[string]Price
+	daily     float64 // daily budget in dollars; 0 means none
+	monthly   float64 // monthly budget in dollars; 0 means none
+	enforce   bool
+	essential map[string]bool
+
+	mu        sync.Mutex
+	spentTime time.Time // when today and month were computed; zero if never
+	today     float64   // amount spent today
+	month     float64   // amount spent this month
+}
+
+// New returns a new Meter that stores usage in db and logs to lg.
+// It knows the [DefaultPrices] and has no budgets.
+func New(lg *slog.Logger, db storage.DB) *Meter {
+	m := &Meter{
+		slog:      lg,
+		db:        db,
+		now:       time.Now,
+		prices:    make(map[string]Price),
+		essential: make(map[string]bool),
+	}
+	for model, p := range DefaultPrices {
+		m.prices[model] = p
+	}
+	return m
+}
+
+// SetPrice sets the price of the model.
+// Calls to models without a price are recorded at no cost.
+func (m *Meter) SetPrice(model string, p Price) {
+	m.prices[model] = p
+}
+
+// SetBudgets sets the daily and monthly budgets, in dollars.
+// A budget of 0 means no budget.
+// Budgets are only enforced if [Meter.EnableEnforcement] is called.
+func (m *Meter) SetBudgets(daily, monthly float64) {
+	m.daily = daily
+	m.monthly = monthly
+}
+
+// EnableEnforcement enables the enforcement of budgets:
+// once the daily or monthly budget is spent, features other than
+// the essential ones are paused until the next day or month.
+func (m *Meter) EnableEnforcement(essential ...string) {
+	m.enforce = true
+	for _, f := range essential {
+		m.essential[f] = true
+	}
+}
+
+// OverBudget reports whether the daily or monthly budget is spent.
+func (m *Meter) OverBudget() bool {
+	today, month := m.spent()
+	return m.daily > 0 && today >= m.daily || m.monthly > 0 && month >= m.monthly
+}
+
+// Paused reports whether the feature is paused: enforcement is enabled,
+// a budget is spent and the feature is not essential.
+// Callers should skip work that would use the LLM for a paused feature.
+func (m *Meter) Paused(feature string) bool {
+	return m.enforce && !m.essential[feature] && m.OverBudget()
+}
+
+// ContentGenerator returns a content generator that generates
+// content using g and records its use by the feature.
+// If the feature is paused (see [Meter.Paused]), the generator
+// returns [ErrOverBudget] instead.
+func (m *Meter) ContentGenerator(feature string, g llm.ContentGenerator) llm.ContentGenerator {
+	return &generator{m: m, feature: feature, g: g}
+}
+
+type generator struct {
+	m       *Meter
+	feature string
+	g       llm.ContentGenerator
+}
+
+func (g *generator) Model() string { return g.g.Model() }
+
+func (g *generator) SetTemperature(t float32) { g.g.SetTemperature(t) }
+
+func (g *generator) GenerateContent(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+	out, _, err := g.GenerateContentModel(ctx, schema, parts)
+	return out, err
+}
+
+func (g *generator) GenerateContentModel(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, string, error) {
+	if g.m.Paused(g.feature) {
+		return "", "", fmt.Errorf("%s: %w", g.feature, ErrOverBudget)
+	}
+	// Record the model that answered this call, which for
+	// a failover generator may not be g.g.Model().
+	out, model, err := llm.GenerateContentModel(ctx, g.g, schema, parts)
+	if err != nil {
+		return "", "", err
+	}
+	in := llm.EstimateTokens(parts)
+	if schema != nil {
+		in += llm.EstimateTokens([]llm.Part{llm.Text(storage.JSON(schema.JSONSchema()))})
+	}
+	g.m.record(g.feature, model, in, llm.EstimateTokens([]llm.Part{llm.Text(out)}))
+	return out, model, nil
+}
+
+//go:generate go run golang.org/x/oscar/internal/devtools/cmd/kindgen -type Usage -kind llmcost.Usage -key Day,Feature,Model
+
+// A Usage records the use of a model by a feature on a day.
+type Usage struct {
+	Day          string // UTC date, such as "2025-01-02"
+	Feature      string // the feature, such as "overview"
+	Model        string // the generative model
+	Calls        int64  // number of calls
+	InputTokens  int64  // estimated prompt tokens
+	OutputTokens int64  // estimated completion tokens
+	Cost         float64
+}
+
+// cost returns the cost of the tokens using the model.
+func (m *Meter) cost(model string, in, out int) float64 {
+	p := m.prices[model]
+	return (float64(in)*p.Input + float64(out)*p.Output) / 1e6
+}
+
+// record records a call by the feature to the model
+// with in prompt tokens and out completion tokens.
+func (m *Meter) record(feature, model string, in, out int) {
+	now := m.now().UTC()
+	day := now.Format(time.DateOnly)
+	key := usageKey(day, feature, model)
+	m.db.Lock(string(key))
+	defer m.db.Unlock(string(key))
+
+	u, ok := getUsage(m.db, day, feature, model)
+	if !ok {
+		u = &Usage{Day: day, Feature: feature, Model: model}
+	}
+	c := m.cost(model, in, out)
+	u.Calls++
+	u.InputTokens += int64(in)
+	u.OutputTokens += int64(out)
+	u.Cost += c
+	setUsage(m.db, u)
+
+	m.mu.Lock()
+	if !m.spentTime.IsZero() && sameMonth(m.spentTime, now) {
+		if sameDay(m.spentTime, now) {
+			m.today += c
+		}
+		m.month += c
+	}
+	m.mu.Unlock()
+}
+
+// spent returns the amounts spent today and this month.
+func (m *Meter) spent() (today, month float64) {
+	now := m.now().UTC()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.spentTime.IsZero() || now.Sub(m.spentTime) > spentTTL || !sameDay(m.spentTime, now) {
+		m.today, m.month = 0, 0
+		for u := range m.monthUsage(now) {
+			if u.Day == now.Format(time.DateOnly) {
+				m.today += u.Cost
+			}
+			m.month += u.Cost
+		}
+		m.spentTime = now
+	}
+	return m.today, m.month
+}
+
+// monthUsage returns the usage records of the month of t.
+func (m *Meter) monthUsage(t time.Time) func(func(*Usage) bool) {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	return scanUsageRange(m.db,
+		ordered.Encode(usageKind, first.Format(time.DateOnly)),
+		ordered.Encode(usageKind, last.Format(time.DateOnly), ordered.Inf))
+}
+
+func sameDay(t, u time.Time) bool {
+	return t.Format(time.DateOnly) == u.Format(time.DateOnly)
+}
+
+func sameMonth(t, u time.Time) bool {
+	return t.Year() == u.Year() && t.Month() == u.Month()
+}
+
+// A Total is the total use of LLMs for a day, feature or model.
+type Total struct {
+	Name         string // the day, feature or model
+	Calls        int64
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64
+}
+
+func (t *Total) add(u *Usage) {
+	t.Calls += u.Calls
+	t.InputTokens += u.InputTokens
+	t.OutputTokens += u.OutputTokens
+	t.Cost += u.Cost
+}
+
+// A Report summarizes the use of LLMs in a month.
+type Report struct {
+	Time          time.Time
+	DailyBudget   float64  // 0 if none
+	MonthlyBudget float64  // 0 if none
+	Enforced      bool     // whether budgets are enforced
+	Paused        bool     // whether non-essential features are paused
+	Essential     []string // the features that are never paused, if Enforced
+	Today         Total    // use today (Name is the day)
+	Month         Total    // use this month (Name is the month, such as "2025-01")
+	Days          []*Total // use on each day of the month, most recent first
+	Features      []*Total // use by each feature this month, most expensive first
+	Models        []*Total // use of each model this month, most expensive first
+}
+
+// Report returns a report of the use of LLMs this month (in UTC),
+// including the spending of other processes using the same database.
+func (m *Meter) Report() *Report {
+	now := m.now().UTC()
+	r := &Report{
+		Time:          now,
+		DailyBudget:   m.daily,
+		MonthlyBudget: m.monthly,
+		Enforced:      m.enforce,
+		Today:         Total{Name: now.Format(time.DateOnly)},
+		Month:         Total{Name: now.Format("2006-01")},
+	}
+	if m.enforce {
+		for f := range m.essential {
+			r.Essential = append(r.Essential, f)
+		}
+		slices.Sort(r.Essential)
+	}
+	days := make(map[string]*Total)
+	features := make(map[string]*Total)
+	models := make(map[string]*Total)
+	total := func(m map[string]*Total, name string) *Total {
+		t := m[name]
+		if t == nil {
+			t = &Total{Name: name}
+			m[name] = t
+		}
+		return t
+	}
+	for u := range m.monthUsage(now) {
+		if u.Day == r.Today.Name {
+			r.Today.add(u)
+		}
+		r.Month.add(u)
+		total(days, u.Day).add(u)
+		total(features, u.Feature).add(u)
+		total(models, u.Model).add(u)
+	}
+	r.Days = sortedTotals(days, func(x, y *Total) int { return cmp.Compare(y.Name, x.Name) })
+	byCost := func(x, y *Total) int { return cmp.Or(cmp.Compare(y.Cost, x.Cost), cmp.Compare(x.Name, y.Name)) }
+	r.Features = sortedTotals(features, byCost)
+	r.Models = sortedTotals(models, byCost)
+	r.Paused = m.enforce && (m.daily > 0 && r.Today.Cost >= m.daily || m.monthly > 0 && r.Month.Cost >= m.monthly)
+	return r
+}
+
+// sortedTotals returns the totals in m sorted by cmp.
+func sortedTotals(m map[string]*Total, cmp func(x, y *Total) int) []*Total {
+	var ts []*Total
+	for _, t := range m {
+		ts = append(ts, t)
+	}
+	slices.SortFunc(ts, cmp)
+	return ts
+}
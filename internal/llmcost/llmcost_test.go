@@ -0,0 +1,148 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llmcost
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestMeter(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+
+	now := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+	m := New(lg, db)
+	m.now = func() time.Time { return now }
+	// One dollar per token, to make costs easy to check.
+	m.SetPrice("test-model", Price{Input: 1e6, Output: 2e6})
+	m.SetBudgets(100, 150)
+
+	g := llm.TestContentGenerator("test", func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+		return "the response", nil
+	})
+	prompt := []llm.Part{llm.Text("a prompt that is a bit longer")}
+	in := int64(llm.EstimateTokens(prompt))
+	out := int64(llm.EstimateTokens([]llm.Part{llm.Text("the response")}))
+	cost := float64(in + 2*out)
+
+	generate := func(feature string) error {
+		t.Helper()
+		_, err := m.ContentGenerator(feature, g).GenerateContent(ctx, nil, prompt)
+		return err
+	}
+	check := testutil.Checker(t)
+	check(generate("overview"))
+	check(generate("overview"))
+	check(generate("labels"))
+	now = now.AddDate(0, 0, 1) // February
+	check(generate("labels"))
+
+	r := m.Report()
+	want := &Report{
+		Time:          now,
+		DailyBudget:   100,
+		MonthlyBudget: 150,
+		Today:         Total{Name: "2025-02-01", Calls: 1, InputTokens: in, OutputTokens: out, Cost: cost},
+		Month:         Total{Name: "2025-02", Calls: 1, InputTokens: in, OutputTokens: out, Cost: cost},
+		Days:          []*Total{{Name: "2025-02-01", Calls: 1, InputTokens: in, OutputTokens: out, Cost: cost}},
+		Features:      []*Total{{Name: "labels", Calls: 1, InputTokens: in, OutputTokens: out, Cost: cost}},
+		Models:        []*Total{{Name: "test-model", Calls: 1, InputTokens: in, OutputTokens: out, Cost: cost}},
+	}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("Report() mismatch (-want +got):\n%s", diff)
+	}
+
+	now = now.AddDate(0, 0, -1) // back to January
+	r = m.Report()
+	if got, want := r.Month.Calls, int64(3); got != want {
+		t.Errorf("January calls = %d, want %d", got, want)
+	}
+	if got, want := r.Features[0], (&Total{Name: "overview", Calls: 2, InputTokens: 2 * in, OutputTokens: 2 * out, Cost: 2 * cost}); !cmp.Equal(got, want) {
+		t.Errorf("January top feature = %+v, want %+v", got, want)
+	}
+
+	// Without enforcement, budgets are reported but not enforced.
+	for m.Report().Today.Cost < 100 {
+		check(generate("overview"))
+	}
+	if !m.OverBudget() {
+		t.Errorf("OverBudget() = false, want true")
+	}
+	if m.Paused("overview") {
+		t.Errorf("Paused(overview) = true without enforcement")
+	}
+	check(generate("overview"))
+
+	m.EnableEnforcement("labels")
+	if !m.Paused("overview") || m.Paused("labels") {
+		t.Errorf("Paused(overview), Paused(labels) = %v, %v, want true, false", m.Paused("overview"), m.Paused("labels"))
+	}
+	if err := generate("overview"); !errors.Is(err, ErrOverBudget) {
+		t.Errorf("generate(overview) = %v, want ErrOverBudget", err)
+	}
+	check(generate("labels"))
+	if r := m.Report(); !r.Paused || !cmp.Equal(r.Essential, []string{"labels"}) {
+		t.Errorf("Report().Paused, Essential = %v, %v, want true, [labels]", r.Paused, r.Essential)
+	}
+
+	// The next day, the daily budget is available again.
+	now = now.AddDate(0, 0, 1)
+	if m.Paused("overview") {
+		t.Errorf("Paused(overview) = true on a new day")
+	}
+
+	// Another meter sees the spending in the database.
+	m2 := New(lg, db)
+	m2.now = m.now
+	m2.SetBudgets(0, 1)
+	m2.EnableEnforcement()
+	now = now.AddDate(0, 0, -1)
+	if !m2.Paused("overview") {
+		t.Errorf("second meter: Paused(overview) = false, want true")
+	}
+}
+
+func TestUnknownModel(t *testing.T) {
+	m := New(testutil.Slogger(t), storage.MemDB())
+	g := m.ContentGenerator("search", llm.EchoContentGenerator())
+	if _, err := g.GenerateContent(context.Background(), nil, []llm.Part{llm.Text("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	r := m.Report()
+	if r.Month.Calls != 1 || r.Month.Cost != 0 || r.Month.InputTokens == 0 {
+		t.Errorf("Report().Month = %+v, want 1 free call", r.Month)
+	}
+}
+
+func TestFailoverModel(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	m := New(lg, storage.MemDB())
+	f := llm.NewFailover(lg)
+	f.Add("down", llm.TestContentGenerator("down", func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+		return "", errors.New("down")
+	}), nil)
+	f.Add("echo", llm.EchoContentGenerator(), nil)
+
+	// The call is recorded for the model that answered it,
+	// not the failover's first model.
+	g := m.ContentGenerator("search", f)
+	if _, err := g.GenerateContent(ctx, nil, []llm.Part{llm.Text("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	r := m.Report()
+	if len(r.Models) != 1 || r.Models[0].Name != llm.EchoContentGenerator().Model() {
+		t.Errorf("Report().Models = %v, want only %s", r.Models, llm.EchoContentGenerator().Model())
+	}
+}
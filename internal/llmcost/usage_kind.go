@@ -0,0 +1,85 @@
+// Code generated by "kindgen -type Usage -kind llmcost.Usage -key Day,Feature,Model"; DO NOT EDIT.
+
+package llmcost
+
+import (
+	"iter"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// usageKind is the key kind of a stored [Usage]:
+//
+//	(llmcost.Usage, Day, Feature, Model) -> [Usage]
+const usageKind = "llmcost.Usage"
+
+// usageVersion is the encoding version of a stored [Usage].
+const usageVersion = 1
+
+// usageKey returns the database key of the [Usage] with the given key fields.
+func usageKey(day string, feature string, model string) []byte {
+	return ordered.Encode(usageKind, day, feature, model)
+}
+
+// getUsage returns the stored [Usage] with the given key fields, if any.
+func getUsage(db storage.DB, day string, feature string, model string) (*Usage, bool) {
+	val, ok := db.Get(usageKey(day, feature, model))
+	if !ok {
+		return nil, false
+	}
+	return decodeUsage(db, val), true
+}
+
+// setUsage stores x under the key made from its key fields.
+func setUsage(db storage.DB, x *Usage) {
+	db.Set(usageKey(x.Day, x.Feature, x.Model), storage.EncodeRecord(usageVersion, x))
+}
+
+// deleteUsage deletes the stored [Usage] with the given key fields, if any.
+func deleteUsage(db storage.DB, day string, feature string, model string) {
+	db.Delete(usageKey(day, feature, model))
+}
+
+// scanUsage returns the stored [Usage] records, in key order.
+func scanUsage(db storage.DB) iter.Seq[*Usage] {
+	return scanUsageRange(db, ordered.Encode(usageKind), ordered.Encode(usageKind, ordered.Inf))
+}
+
+// scanUsageByDay returns the stored [Usage] records
+// with the given Day, in key order.
+func scanUsageByDay(db storage.DB, day string) iter.Seq[*Usage] {
+	return scanUsageRange(db, ordered.Encode(usageKind, day), ordered.Encode(usageKind, day, ordered.Inf))
+}
+
+// scanUsageByDayFeature returns the stored [Usage] records
+// with the given Day and Feature, in key order.
+func scanUsageByDayFeature(db storage.DB, day string, feature string) iter.Seq[*Usage] {
+	return scanUsageRange(db, ordered.Encode(usageKind, day, feature), ordered.Encode(usageKind, day, feature, ordered.Inf))
+}
+
+// scanUsageRange returns the stored [Usage] records
+// with keys in the range [start, end], in key order.
+func scanUsageRange(db storage.DB, start, end []byte) iter.Seq[*Usage] {
+	return func(yield func(*Usage) bool) {
+		for _, val := range db.Scan(start, end) {
+			if !yield(decodeUsage(db, val())) {
+				return
+			}
+		}
+	}
+}
+
+// decodeUsage decodes a stored [Usage].
+func decodeUsage(db storage.DB, val []byte) *Usage {
+	var x Usage
+	v, err := storage.DecodeRecord(val, &x)
+	if err != nil {
+		// unreachable unless database corruption
+		db.Panic("decode llmcost.Usage", "err", err)
+	}
+	if v > usageVersion {
+		db.Panic("decode llmcost.Usage: stored with newer encoding", "version", v, "known", usageVersion)
+	}
+	return &x
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package moderate checks and cleans text generated by an LLM
+// before it is posted, so that a bot never posts prompt-injected
+// or otherwise unsafe content, nor pings arbitrary users.
+//
+// A [Filter] runs a configurable sequence of stages on each text:
+//
+//   - a denylist of regular expressions (see [Filter.Deny]),
+//     any match of which blocks the text;
+//   - a provider's moderation API (see [Filter.SetChecker]),
+//     any policy violation of which blocks the text;
+//   - the removal of GitHub @-mentions (see [Filter.StripMentions]);
+//   - the removal of links to hosts that are not allowed
+//     (see [Filter.AllowLinks]).
+//
+// Blocked texts are reported as errors wrapping [ErrBlocked].
+package moderate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+)
+
+// ErrBlocked is the error wrapped by the errors of [Filter.Check]
+// for texts that must not be posted.
+var ErrBlocked = errors.New("moderate: text blocked")
+
+// A Filter checks and cleans LLM-generated text.
+// The zero configuration (see [New]) accepts all texts unchanged.
+type Filter struct {
+	slog     *slog.Logger
+	deny     []*regexp.Regexp
+	checker  llm.PolicyChecker // if non-nil, the moderation API
+	mentions bool              // whether to strip mentions
+	allowed  map[string]bool   // users that may be mentioned
+	hosts    []string          // hosts that may be linked; nil means all
+}
+
+// New returns a new Filter that logs to lg.
+// Use the Filter methods to configure its stages
+// before calling [Filter.Check].
+func New(lg *slog.Logger) *Filter {
+	return &Filter{slog: lg, allowed: make(map[string]bool)}
+}
+
+// Deny adds the regular expression re to the denylist:
+// texts containing a match of re are blocked.
+func (f *Filter) Deny(re *regexp.Regexp) {
+	f.deny = append(f.deny, re)
+}
+
+// SetChecker sets the policy checker, typically a provider's
+// moderation API, that checks each text: texts that violate
+// any of its policies, or that it fails to check, are blocked.
+func (f *Filter) SetChecker(c llm.PolicyChecker) {
+	f.checker = c
+}
+
+// StripMentions configures the Filter to remove the @ of GitHub
+// @-mentions ("@user" becomes "user"), so that posting the text
+// does not notify anyone, except for the given users.
+func (f *Filter) StripMentions(allow ...string) {
+	f.mentions = true
+	for _, u := range allow {
+		f.allowed[strings.ToLower(u)] = true
+	}
+}
+
+// AllowLinks configures the Filter to remove the links (http and https
+// URLs) to hosts other than the given ones and their subdomains.
+// Each removed link is replaced with [LinkRemoved].
+// AllowLinks can be called multiple times to allow more hosts.
+// By default, all links are allowed.
+func (f *Filter) AllowLinks(hosts ...string) {
+	if f.hosts == nil {
+		f.hosts = []string{}
+	}
+	for _, h := range hosts {
+		f.hosts = append(f.hosts, strings.ToLower(h))
+	}
+}
+
+// LinkRemoved replaces the links removed by a [Filter].
+const LinkRemoved = "(link removed)"
+
+// Check returns the text, cleaned by the Filter, or an error
+// wrapping [ErrBlocked] if it must not be posted.
+// If the text was generated by an LLM, the prompt parts used
+// to generate it may be given as context for the policy checker.
+// Check returns other errors if the policy checker fails.
+func (f *Filter) Check(ctx context.Context, text string, prompt ...llm.Part) (string, error) {
+	for _, re := range f.deny {
+		if m := re.FindString(text); m != "" {
+			f.slog.Warn("moderate: blocked text", "reason", "denylist", "regexp", re.String(), "match", m)
+			return "", fmt.Errorf("%w: matches denylist %#q", ErrBlocked, re.String())
+		}
+	}
+	if f.checker != nil {
+		prs, err := f.checker.CheckText(ctx, text, prompt...)
+		if err != nil {
+			f.slog.Warn("moderate: blocked text", "reason", "checker error", "err", err)
+			return "", fmt.Errorf("%w: checking policies: %w", ErrBlocked, err)
+		}
+		for _, pr := range prs {
+			if pr.IsViolative() {
+				f.slog.Warn("moderate: blocked text", "reason", "policy", "policy", pr.String())
+				return "", fmt.Errorf("%w: violates policy %s", ErrBlocked, pr.PolicyType)
+			}
+		}
+	}
+	if f.mentions {
+		text = f.stripMentions(text)
+	}
+	if f.hosts != nil {
+		text = f.stripLinks(text)
+	}
+	return text, nil
+}
+
+// mentionRE matches a GitHub @-mention, preceded by the start of the
+// text or a character that cannot be part of a word, email address
+// or path (as in "user@example.com" or "pkg@v1.2.3").
+var mentionRE = regexp.MustCompile(`(^|[^\w@/.-])@([A-Za-z0-9](?:[A-Za-z0-9-]{0,38})(?:/[A-Za-z0-9_.-]+)?)\b`)
+
+// stripMentions returns text with the @ removed from the mentions
+// of users that are not allowed.
+func (f *Filter) stripMentions(text string) string {
+	return mentionRE.ReplaceAllStringFunc(text, func(m string) string {
+		sub := mentionRE.FindStringSubmatch(m)
+		prefix, user := sub[1], sub[2]
+		if f.allowed[strings.ToLower(user)] {
+			return m
+		}
+		f.slog.Info("moderate: stripped mention", "user", user)
+		return prefix + user
+	})
+}
+
+// linkRE matches an http or https URL.
+var linkRE = regexp.MustCompile(`https?://[^\s<>"'\x60()\[\]\\]+`)
+
+// stripLinks returns text with the links to hosts
+// that are not allowed replaced by LinkRemoved.
+func (f *Filter) stripLinks(text string) string {
+	return linkRE.ReplaceAllStringFunc(text, func(m string) string {
+		// Trailing punctuation ends a sentence, not the link.
+		link := strings.TrimRight(m, ".,;:!?")
+		if u, err := url.Parse(link); err == nil && f.allowedHost(u.Hostname()) {
+			return m
+		}
+		f.slog.Info("moderate: removed link", "link", link)
+		return LinkRemoved + m[len(link):]
+	})
+}
+
+// allowedHost reports whether links to host are allowed.
+func (f *Filter) allowedHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, h := range f.hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentGenerator returns a content generator that generates
+// content using g and checks it with the Filter: it returns the
+// cleaned content, or the error from [Filter.Check].
+func (f *Filter) ContentGenerator(g llm.ContentGenerator) llm.ContentGenerator {
+	return &generator{f: f, g: g}
+}
+
+type generator struct {
+	f *Filter
+	g llm.ContentGenerator
+}
+
+func (g *generator) Model() string { return g.g.Model() }
+
+func (g *generator) SetTemperature(t float32) { g.g.SetTemperature(t) }
+
+func (g *generator) GenerateContent(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+	out, _, err := g.GenerateContentModel(ctx, schema, parts)
+	return out, err
+}
+
+func (g *generator) GenerateContentModel(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, string, error) {
+	out, model, err := llm.GenerateContentModel(ctx, g.g, schema, parts)
+	if err != nil {
+		return "", "", err
+	}
+	out, err = g.f.Check(ctx, out, parts...)
+	if err != nil {
+		return "", "", err
+	}
+	return out, model, nil
+}
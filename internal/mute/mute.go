@@ -0,0 +1,228 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mute maintains the set of GitHub issues that have
+// opted out of bot comments.
+//
+// An issue is muted when it is given a configurable label
+// (see [Set.SetLabel]) or when a maintainer replies to it with a
+// comment consisting of a command (see [Set.SetCommand]).
+// Only users with triage access can add labels, and only
+// maintainers (see [github.MaintainerAssociation]) and the users
+// listed with [Set.AllowUsers] can use the commands, so that
+// anyone else cannot turn the bots off (or on) for an issue.
+//
+// Once muted, an issue stays muted, even if the label is later removed,
+// until a maintainer replies with the unmute command
+// (see [Set.SetUnmuteCommand]).
+// The set is stored in the database:
+//
+//	(mute.Issue, $project, $issue) -> [Mute], encoded by [storage.EncodeRecord]
+//
+// Posters consult the set with [Set.Muted].
+package mute
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+)
+
+const (
+	// DefaultLabel is the default label that mutes an issue.
+	DefaultLabel = "oscar-ignore"
+	// DefaultCommand is the default command that mutes an issue.
+	DefaultCommand = "/oscar mute"
+	// DefaultUnmuteCommand is the default command that unmutes an issue.
+	DefaultUnmuteCommand = "/oscar unmute"
+)
+
+// A Set is a persistent set of muted issues.
+type Set struct {
+	slog     *slog.Logger
+	db       storage.DB
+	projects map[string]bool
+	label    string
+	command  string
+	unmute   string
+	allowed  map[string]bool // users who can use the commands besides maintainers
+	watcher  *timed.Watcher[*github.Event]
+}
+
+// New creates and returns a new Set. It logs to lg, stores the
+// set in db, and watches for labels and commands using gh.
+//
+// Use [Set.EnableProject] to configure the projects to watch
+// before calling [Set.Run].
+func New(lg *slog.Logger, db storage.DB, gh *github.Client) *Set {
+	return &Set{
+		slog:     lg,
+		db:       db,
+		projects: make(map[string]bool),
+		label:    DefaultLabel,
+		command:  DefaultCommand,
+		unmute:   DefaultUnmuteCommand,
+		allowed:  make(map[string]bool),
+		watcher:  gh.EventWatcher("mute.Set"),
+	}
+}
+
+// EnableProject enables the Set to watch for labels and commands
+// in the given GitHub project (for example "golang/go").
+func (s *Set) EnableProject(project string) {
+	s.projects[project] = true
+}
+
+// SetLabel sets the label that mutes an issue.
+// The empty string disables muting by label.
+// The default is [DefaultLabel].
+func (s *Set) SetLabel(label string) {
+	s.label = label
+}
+
+// SetCommand sets the command that mutes an issue when a comment
+// on the issue contains it on a line by itself.
+// The empty string disables muting by command.
+// The default is [DefaultCommand].
+func (s *Set) SetCommand(cmd string) {
+	s.command = cmd
+}
+
+// SetUnmuteCommand sets the command that removes an issue from the
+// set when a comment on the issue contains it on a line by itself.
+// An issue that still has the muting label is muted again.
+// The empty string disables unmuting.
+// The default is [DefaultUnmuteCommand].
+func (s *Set) SetUnmuteCommand(cmd string) {
+	s.unmute = cmd
+}
+
+// AllowUsers allows the GitHub users with the given logins
+// to use the commands, in addition to the maintainers.
+func (s *Set) AllowUsers(logins ...string) {
+	for _, u := range logins {
+		s.allowed[u] = true
+	}
+}
+
+//go:generate go run golang.org/x/oscar/internal/devtools/cmd/kindgen -type Mute -kind mute.Issue -key Project,Issue
+
+// A Mute records why an issue was muted.
+type Mute struct {
+	Project string
+	Issue   int64
+	Time    time.Time // when the mute was recorded
+	Reason  string    // "label" or "command"
+	URL     string    // the issue or comment that muted the issue
+}
+
+// Run records the issues in the enabled projects that were
+// muted since the last call to Run.
+// Callers should call Run before running the posters that consult
+// the set, so that they see the latest mutes.
+func (s *Set) Run(ctx context.Context) error {
+	s.slog.Info("mute.Set start")
+	defer s.slog.Info("mute.Set end")
+
+	defer s.watcher.Flush()
+	for e := range s.watcher.Recent() {
+		s.watcher.MarkOld(e.DBTime)
+		if !s.projects[e.Project] {
+			continue
+		}
+		muted := s.Muted(e.Project, e.Issue)
+		switch e.API {
+		case "/issues":
+			iss := e.Typed.(*github.Issue)
+			if !muted && s.hasLabel(iss) {
+				s.mute(e.Project, e.Issue, "label", iss.HTMLURL)
+			}
+		case "/issues/comments":
+			ic := e.Typed.(*github.IssueComment)
+			switch {
+			case !muted && hasCommand(ic.Body, s.command):
+				if s.canCommand(ic) {
+					s.mute(e.Project, e.Issue, "command", ic.HTMLURL)
+				} else {
+					s.slog.Info("mute.Set ignoring command from non-maintainer", "url", ic.HTMLURL, "user", ic.User.Login)
+				}
+			case muted && hasCommand(ic.Body, s.unmute):
+				if s.canCommand(ic) {
+					s.unmuteIssue(e.Project, e.Issue, ic.HTMLURL)
+				} else {
+					s.slog.Info("mute.Set ignoring command from non-maintainer", "url", ic.HTMLURL, "user", ic.User.Login)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasLabel reports whether the issue has the muting label.
+func (s *Set) hasLabel(iss *github.Issue) bool {
+	if s.label == "" {
+		return false
+	}
+	for _, l := range iss.Labels {
+		if l.Name == s.label {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCommand reports whether the comment body contains
+// the command cmd on a line by itself.
+func hasCommand(body, cmd string) bool {
+	if cmd == "" {
+		return false
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// canCommand reports whether the author of the comment
+// can use the commands.
+func (s *Set) canCommand(ic *github.IssueComment) bool {
+	return github.MaintainerAssociation(ic.AuthorAssociation) || s.allowed[ic.User.Login]
+}
+
+// mute adds the issue to the set.
+func (s *Set) mute(project string, issue int64, reason, url string) {
+	s.slog.Info("mute.Set muting issue", "project", project, "issue", issue, "reason", reason, "url", url)
+	m := &Mute{
+		Project: project,
+		Issue:   issue,
+		Time:    time.Now(),
+		Reason:  reason,
+		URL:     url,
+	}
+	setMute(s.db, m)
+}
+
+// unmuteIssue removes the issue from the set.
+func (s *Set) unmuteIssue(project string, issue int64, url string) {
+	s.slog.Info("mute.Set unmuting issue", "project", project, "issue", issue, "url", url)
+	deleteMute(s.db, project, issue)
+}
+
+// Muted reports whether the issue is in the set.
+func (s *Set) Muted(project string, issue int64) bool {
+	_, ok := s.Lookup(project, issue)
+	return ok
+}
+
+// Lookup returns the record of the issue's mute, if it is in the set.
+func (s *Set) Lookup(project string, issue int64) (*Mute, bool) {
+	return getMute(s.db, project, issue)
+}
@@ -0,0 +1,79 @@
+// Code generated by "kindgen -type Mute -kind mute.Issue -key Project,Issue"; DO NOT EDIT.
+
+package mute
+
+import (
+	"iter"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// muteKind is the key kind of a stored [Mute]:
+//
+//	(mute.Issue, Project, Issue) -> [Mute]
+const muteKind = "mute.Issue"
+
+// muteVersion is the encoding version of a stored [Mute].
+const muteVersion = 1
+
+// muteKey returns the database key of the [Mute] with the given key fields.
+func muteKey(project string, issue int64) []byte {
+	return ordered.Encode(muteKind, project, issue)
+}
+
+// getMute returns the stored [Mute] with the given key fields, if any.
+func getMute(db storage.DB, project string, issue int64) (*Mute, bool) {
+	val, ok := db.Get(muteKey(project, issue))
+	if !ok {
+		return nil, false
+	}
+	return decodeMute(db, val), true
+}
+
+// setMute stores x under the key made from its key fields.
+func setMute(db storage.DB, x *Mute) {
+	db.Set(muteKey(x.Project, x.Issue), storage.EncodeRecord(muteVersion, x))
+}
+
+// deleteMute deletes the stored [Mute] with the given key fields, if any.
+func deleteMute(db storage.DB, project string, issue int64) {
+	db.Delete(muteKey(project, issue))
+}
+
+// scanMute returns the stored [Mute] records, in key order.
+func scanMute(db storage.DB) iter.Seq[*Mute] {
+	return scanMuteRange(db, ordered.Encode(muteKind), ordered.Encode(muteKind, ordered.Inf))
+}
+
+// scanMuteByProject returns the stored [Mute] records
+// with the given Project, in key order.
+func scanMuteByProject(db storage.DB, project string) iter.Seq[*Mute] {
+	return scanMuteRange(db, ordered.Encode(muteKind, project), ordered.Encode(muteKind, project, ordered.Inf))
+}
+
+// scanMuteRange returns the stored [Mute] records
+// with keys in the range [start, end], in key order.
+func scanMuteRange(db storage.DB, start, end []byte) iter.Seq[*Mute] {
+	return func(yield func(*Mute) bool) {
+		for _, val := range db.Scan(start, end) {
+			if !yield(decodeMute(db, val())) {
+				return
+			}
+		}
+	}
+}
+
+// decodeMute decodes a stored [Mute].
+func decodeMute(db storage.DB, val []byte) *Mute {
+	var x Mute
+	v, err := storage.DecodeRecord(val, &x)
+	if err != nil {
+		// unreachable unless database corruption
+		db.Panic("decode mute.Issue", "err", err)
+	}
+	if v > muteVersion {
+		db.Panic("decode mute.Issue: stored with newer encoding", "version", v, "known", muteVersion)
+	}
+	return &x
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mute
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestSet(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	ctx := context.Background()
+
+	const project = "test/test"
+	tc := gh.Testing()
+	tc.AddIssue(project, &github.Issue{Number: 1, Labels: []github.Label{{Name: "bug"}, {Name: DefaultLabel}}})
+	tc.AddIssue(project, &github.Issue{Number: 2})
+	tc.AddIssue(project, &github.Issue{Number: 3})
+	tc.AddIssue(project, &github.Issue{Number: 4})
+	tc.AddIssue("other/project", &github.Issue{Number: 5, Labels: []github.Label{{Name: DefaultLabel}}})
+	tc.AddIssueComment(project, 2, &github.IssueComment{Body: "Thanks, but no.\n\n  /Oscar Mute  \n", AuthorAssociation: "MEMBER"})
+	tc.AddIssueComment(project, 3, &github.IssueComment{Body: "Try /oscar mute to stop the bot.", AuthorAssociation: "OWNER"})
+
+	s := New(lg, db, gh)
+	s.EnableProject(project)
+	check(s.Run(ctx))
+
+	for _, tt := range []struct {
+		project string
+		issue   int64
+		want    bool
+	}{
+		{project, 1, true},
+		{project, 2, true},
+		{project, 3, false}, // command not on a line by itself
+		{project, 4, false},
+		{"other/project", 5, false}, // project not enabled
+	} {
+		if got := s.Muted(tt.project, tt.issue); got != tt.want {
+			t.Errorf("Muted(%s#%d) = %t, want %t", tt.project, tt.issue, got, tt.want)
+		}
+	}
+	if m, ok := s.Lookup(project, 2); !ok || m.Reason != "command" {
+		t.Errorf("Lookup(%s#2) = %+v, %t, want reason command", project, m, ok)
+	}
+
+	// Mutes persist even if the label is removed.
+	tc.AddIssueComment(project, 1, &github.IssueComment{Body: "hello"})
+	s = New(lg, db, gh)
+	s.EnableProject(project)
+	s.SetLabel("")
+	s.SetCommand("")
+	tc.AddIssueComment(project, 4, &github.IssueComment{Body: "/oscar mute", AuthorAssociation: "COLLABORATOR"})
+	check(s.Run(ctx))
+	if !s.Muted(project, 1) {
+		t.Errorf("Muted(%s#1) = false after rerun, want true", project)
+	}
+	if s.Muted(project, 4) {
+		t.Errorf("Muted(%s#4) = true with command disabled, want false", project)
+	}
+}
+
+func TestCommandAuthor(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	ctx := context.Background()
+
+	const project = "test/test"
+	tc := gh.Testing()
+	for i := range int64(3) {
+		tc.AddIssue(project, &github.Issue{Number: i + 1})
+	}
+	s := New(lg, db, gh)
+	s.EnableProject(project)
+	s.AllowUsers("helper")
+
+	// Only maintainers and allowed users can mute.
+	tc.AddIssueComment(project, 1, &github.IssueComment{Body: "/oscar mute", User: github.User{Login: "troll"}, AuthorAssociation: "NONE"})
+	tc.AddIssueComment(project, 2, &github.IssueComment{Body: "/oscar mute", User: github.User{Login: "contrib"}, AuthorAssociation: "CONTRIBUTOR"})
+	tc.AddIssueComment(project, 3, &github.IssueComment{Body: "/oscar mute", User: github.User{Login: "helper"}, AuthorAssociation: "NONE"})
+	check(s.Run(ctx))
+	for issue, want := range map[int64]bool{1: false, 2: false, 3: true} {
+		if got := s.Muted(project, issue); got != want {
+			t.Errorf("Muted(#%d) = %t, want %t", issue, got, want)
+		}
+	}
+
+	// Only maintainers and allowed users can unmute.
+	tc.AddIssueComment(project, 3, &github.IssueComment{Body: "/oscar unmute", User: github.User{Login: "troll"}, AuthorAssociation: "NONE"})
+	check(s.Run(ctx))
+	if !s.Muted(project, 3) {
+		t.Errorf("Muted(#3) = false after unmute by non-maintainer, want true")
+	}
+	tc.AddIssueComment(project, 3, &github.IssueComment{Body: "/oscar unmute", User: github.User{Login: "gopher"}, AuthorAssociation: "MEMBER"})
+	check(s.Run(ctx))
+	if s.Muted(project, 3) {
+		t.Errorf("Muted(#3) = true after unmute by maintainer, want false")
+	}
+
+	// Muting again after an unmute works.
+	tc.AddIssueComment(project, 3, &github.IssueComment{Body: "/oscar mute", User: github.User{Login: "gopher"}, AuthorAssociation: "OWNER"})
+	check(s.Run(ctx))
+	if !s.Muted(project, 3) {
+		t.Errorf("Muted(#3) = false after mute, want true")
+	}
+}
@@ -4,12 +4,14 @@
 
 // Package ollama implements access to offline Ollama model.
 //
-// [Client] implements [llm.Embedder]. Use [NewClient] to connect.
+// [Client] implements [llm.Embedder] and [llm.ContentGenerator].
+// Use [NewClient] to connect.
 package ollama
 
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,6 +20,7 @@ import (
 	"net/url"
 	"os"
 	"slices"
+	"strings"
 
 	"golang.org/x/oscar/internal/llm"
 )
@@ -31,6 +34,9 @@ type Client struct {
 	hc    *http.Client
 	url   *url.URL // url of the ollama server
 	model string
+
+	generativeModel string  // model for content generation
+	temperature     float32 // negative means use default
 }
 
 // NewClient returns a connection to Ollama server. If empty, the
@@ -49,9 +55,20 @@ func NewClient(lg *slog.Logger, hc *http.Client, server string, model string) (*
 	if err != nil {
 		return nil, err
 	}
-	return &Client{slog: lg, hc: hc, url: u, model: model}, nil
+	return &Client{slog: lg, hc: hc, url: u, model: model, temperature: -1}, nil
+}
+
+// SetGenerativeModel sets the model name to use for content generation,
+// such as "llama3.1". Without a generative model, GenerateContent fails.
+func (c *Client) SetGenerativeModel(model string) {
+	c.generativeModel = model
 }
 
+const (
+	DefaultEmbeddingModel  = "mxbai-embed-large"
+	DefaultGenerativeModel = "llama3.1"
+)
+
 const maxBatch = 512 // default physical batch size in ollama
 
 // EmbedDocs returns the vector embeddings for the docs,
@@ -125,9 +142,9 @@ func embedError(resp *http.Response, embResp []byte) error {
 		if err := json.Unmarshal(embResp, &e); err != nil {
 			return err
 		}
-		return fmt.Errorf("ollama response error: %s", e.Error)
+		return fmt.Errorf("ollama response error: %w", &llm.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Message: e.Error})
 	}
-	return fmt.Errorf("ollama response error: %s", resp.Status)
+	return fmt.Errorf("ollama response error: %w", &llm.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status})
 }
 
 func embeddings(embResp []byte) ([]llm.Vector, error) {
@@ -141,3 +158,82 @@ func embeddings(embResp []byte) ([]llm.Vector, error) {
 	}
 	return e.Embeddings, nil
 }
+
+var _ llm.ContentGenerator = (*Client)(nil)
+
+// Model returns the name of the client's generative model.
+func (c *Client) Model() string {
+	return c.generativeModel
+}
+
+// SetTemperature sets the temperature of the client's generative model.
+func (c *Client) SetTemperature(t float32) {
+	c.temperature = t
+}
+
+// GenerateContent returns the model's response for the prompt parts,
+// implementing [llm.ContentGenerator.GenerateContent].
+func (c *Client) GenerateContent(ctx context.Context, schema *llm.Schema, promptParts []llm.Part) (string, error) {
+	if c.generativeModel == "" {
+		return "", fmt.Errorf("ollama.GenerateContent: no generative model")
+	}
+	// ollama takes text and images as separate fields of a message.
+	var texts, images []string
+	for _, p := range promptParts {
+		switch p := p.(type) {
+		case llm.Text:
+			texts = append(texts, string(p))
+		case llm.Blob:
+			images = append(images, base64.StdEncoding.EncodeToString(p.Data))
+		default:
+			return "", fmt.Errorf("ollama.GenerateContent: bad type for part: %T; need string or llm.Blob", p)
+		}
+	}
+	msg := map[string]any{"role": "user", "content": strings.Join(texts, "\n\n")}
+	if len(images) > 0 {
+		msg["images"] = images
+	}
+	req := map[string]any{
+		"model":    c.generativeModel,
+		"messages": []any{msg},
+		"stream":   false,
+	}
+	if c.temperature >= 0 {
+		req["options"] = map[string]any{"temperature": c.temperature}
+	}
+	if schema != nil {
+		req["format"] = schema.JSONSchema()
+	}
+	rj, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url.JoinPath("/api/chat").String(), bytes.NewReader(rj))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := c.hc.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("ollama.GenerateContent: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("ollama.GenerateContent: %w", err)
+	}
+	if err := embedError(response, data); err != nil {
+		return "", fmt.Errorf("ollama.GenerateContent: %w", err)
+	}
+	var resp struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("ollama.GenerateContent: %w", err)
+	}
+	return resp.Message.Content, nil
+}
@@ -7,8 +7,10 @@ package ollama
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"golang.org/x/oscar/internal/httprr"
@@ -102,3 +104,37 @@ func TestBigBatch(t *testing.T) {
 		t.Fatalf("len(vecs) = %d, but len(docs) = %d", len(vecs), len(docs))
 	}
 }
+
+func TestGenerateContent(t *testing.T) {
+	ctx := context.Background()
+	check := testutil.Checker(t)
+	var req map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %s, want /api/chat", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		fmt.Fprintf(w, `{"message":{"role":"assistant","content":"hello"}}`)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(testutil.Slogger(t), srv.Client(), srv.URL, "mxbai-embed-large")
+	check(err)
+	if _, err := c.GenerateContent(ctx, nil, []llm.Part{llm.Text("hi")}); err == nil {
+		t.Errorf("GenerateContent without generative model succeeded")
+	}
+
+	c.SetGenerativeModel("llama3.1")
+	schema := &llm.Schema{Type: llm.TypeString}
+	out, err := c.GenerateContent(ctx, schema, []llm.Part{llm.Text("hi"), llm.Text("there")})
+	check(err)
+	if out != "hello" {
+		t.Errorf("GenerateContent = %q, want hello", out)
+	}
+	js, _ := json.Marshal(req)
+	if want := `{"format":{"type":"string"},"messages":[{"content":"hi\n\nthere","role":"user"}],"model":"llama3.1","stream":false}`; string(js) != want {
+		t.Errorf("request = %s\nwant %s", js, want)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package openai implements access to OpenAI's models,
+// and to other services with an OpenAI-compatible API.
+//
+// [Client] implements [llm.Embedder] and [llm.ContentGenerator].
+// Use [NewClient] to connect.
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/secret"
+)
+
+// NOTE: Like package ollama, this package does not use third party
+// packages for the OpenAI API to avoid bringing in their many dependencies.
+
+// A Client represents a connection to OpenAI.
+type Client struct {
+	slog                            *slog.Logger
+	hc                              *http.Client
+	url                             *url.URL // base URL of the API
+	key                             string
+	embeddingModel, generativeModel string
+	temperature                     float32 // negative means use default
+}
+
+const (
+	DefaultServer          = "https://api.openai.com/v1"
+	DefaultEmbeddingModel  = "text-embedding-3-small"
+	DefaultGenerativeModel = "gpt-4o"
+)
+
+// NewClient returns a connection to OpenAI, using the given logger and HTTP client.
+// It expects to find a secret of the form "sk-..." or "user:sk-..." in sdb
+// under the name "api.openai.com".
+// The embeddingModel is the model name to use for embedding, such as text-embedding-3-small,
+// and the generativeModel is the model name to use for generation, such as gpt-4o.
+func NewClient(lg *slog.Logger, sdb secret.DB, hc *http.Client, embeddingModel, generativeModel string) (*Client, error) {
+	key, ok := sdb.Get("api.openai.com")
+	if !ok {
+		return nil, fmt.Errorf("missing api key for api.openai.com")
+	}
+	// If key is from .netrc, ignore user name.
+	if _, pass, ok := strings.Cut(key, ":"); ok {
+		key = pass
+	}
+	u, err := url.Parse(DefaultServer)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		slog:            lg,
+		hc:              hc,
+		url:             u,
+		key:             key,
+		embeddingModel:  embeddingModel,
+		generativeModel: generativeModel,
+		temperature:     -1,
+	}, nil
+}
+
+// SetServer sets the base URL of the API, such as "https://api.openai.com/v1",
+// for use with OpenAI-compatible services.
+func (c *Client) SetServer(server string) error {
+	u, err := url.Parse(server)
+	if err != nil {
+		return err
+	}
+	c.url = u
+	return nil
+}
+
+const maxBatch = 512 // documented limit is 2048 inputs; stay well below
+
+var _ llm.Embedder = (*Client)(nil)
+
+// EmbedDocs returns the vector embeddings for the docs,
+// implementing [llm.Embedder].
+func (c *Client) EmbedDocs(ctx context.Context, docs []llm.EmbedDoc) ([]llm.Vector, error) {
+	var vecs []llm.Vector
+	for docs := range slices.Chunk(docs, maxBatch) {
+		var inputs []string
+		for _, d := range docs {
+			// The API has no separate title field.
+			input := d.Text
+			if d.Title != "" {
+				input = d.Title + "\n\n" + d.Text
+			}
+			inputs = append(inputs, input)
+		}
+		req := struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}{c.embeddingModel, inputs}
+		var resp struct {
+			Data []struct {
+				Index     int        `json:"index"`
+				Embedding llm.Vector `json:"embedding"`
+			} `json:"data"`
+		}
+		if err := c.post(ctx, "embeddings", req, &resp); err != nil {
+			return nil, fmt.Errorf("openai.EmbedDocs: %w", err)
+		}
+		if len(resp.Data) != len(docs) {
+			return nil, fmt.Errorf("openai.EmbedDocs: got %d embeddings for %d docs", len(resp.Data), len(docs))
+		}
+		vs := make([]llm.Vector, len(docs))
+		for _, d := range resp.Data {
+			if d.Index < 0 || d.Index >= len(vs) {
+				return nil, fmt.Errorf("openai.EmbedDocs: bad embedding index %d", d.Index)
+			}
+			vs[d.Index] = d.Embedding
+		}
+		vecs = append(vecs, vs...)
+	}
+	return vecs, nil
+}
+
+var _ llm.ContentGenerator = (*Client)(nil)
+
+// Model returns the name of the client's generative model.
+func (c *Client) Model() string {
+	return c.generativeModel
+}
+
+// SetTemperature sets the temperature of the client's generative model.
+func (c *Client) SetTemperature(t float32) {
+	c.temperature = t
+}
+
+// GenerateContent returns the model's response for the prompt parts,
+// implementing [llm.ContentGenerator.GenerateContent].
+func (c *Client) GenerateContent(ctx context.Context, schema *llm.Schema, promptParts []llm.Part) (string, error) {
+	content, err := parts(promptParts)
+	if err != nil {
+		return "", fmt.Errorf("openai.GenerateContent: %w", err)
+	}
+	req := map[string]any{
+		"model":    c.generativeModel,
+		"messages": []map[string]any{{"role": "user", "content": content}},
+	}
+	if c.temperature >= 0 {
+		req["temperature"] = c.temperature
+	}
+	if schema != nil {
+		req["response_format"] = map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   "response",
+				"schema": schema.JSONSchema(),
+			},
+		}
+	}
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := c.post(ctx, "chat/completions", req, &resp); err != nil {
+		return "", fmt.Errorf("openai.GenerateContent: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai.GenerateContent: no response")
+	}
+	// Return just the first response, as it's not clear how to concatenate
+	// multiple JSON responses.
+	return resp.Choices[0].Message.Content, nil
+}
+
+// parts converts the prompt parts to OpenAI message content.
+func parts(promptParts []llm.Part) ([]map[string]any, error) {
+	var content []map[string]any
+	for _, p := range promptParts {
+		switch p := p.(type) {
+		case llm.Text:
+			content = append(content, map[string]any{"type": "text", "text": string(p)})
+		case llm.Blob:
+			u := "data:" + p.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(p.Data)
+			content = append(content, map[string]any{"type": "image_url", "image_url": map[string]any{"url": u}})
+		default:
+			return nil, fmt.Errorf("bad type for part: %T; need string or llm.Blob", p)
+		}
+	}
+	return content, nil
+}
+
+// post sends req as JSON to the API endpoint with the given path
+// and decodes the JSON response into resp.
+func (c *Client) post(ctx context.Context, path string, req, resp any) error {
+	js, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url.JoinPath(path).String(), bytes.NewReader(js))
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.Header.Set("Accept", "application/json")
+	hreq.Header.Set("Authorization", "Bearer "+c.key)
+
+	hresp, err := c.hc.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+	data, err := io.ReadAll(hresp.Body)
+	if err != nil {
+		return err
+	}
+	if hresp.StatusCode != http.StatusOK {
+		// OpenAI returns JSON with an error object on failure.
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		herr := &llm.HTTPError{StatusCode: hresp.StatusCode, Status: hresp.Status}
+		if json.Unmarshal(data, &e) == nil {
+			herr.Message = e.Error.Message
+		}
+		return herr
+	}
+	return json.Unmarshal(data, resp)
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// newTestClient returns a client for a fake OpenAI server
+// that calls handle for each request.
+func newTestClient(t *testing.T, handle func(path string, req map[string]any) (int, any)) *Client {
+	check := testutil.Checker(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q, want Bearer sk-test", got)
+		}
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		code, resp := handle(r.URL.Path, req)
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	sdb := secret.Map{"api.openai.com": "user:sk-test"}
+	c, err := NewClient(testutil.Slogger(t), sdb, srv.Client(), DefaultEmbeddingModel, DefaultGenerativeModel)
+	check(err)
+	check(c.SetServer(srv.URL + "/v1"))
+	return c
+}
+
+func TestEmbedDocs(t *testing.T) {
+	c := newTestClient(t, func(path string, req map[string]any) (int, any) {
+		if path != "/v1/embeddings" || req["model"] != DefaultEmbeddingModel {
+			t.Errorf("request %s %v", path, req)
+		}
+		// Respond out of order, with one-element vectors
+		// holding the length of each input.
+		inputs := req["input"].([]any)
+		var data []map[string]any
+		for i := len(inputs) - 1; i >= 0; i-- {
+			data = append(data, map[string]any{"index": i, "embedding": []float32{float32(len(inputs[i].(string)))}})
+		}
+		return 200, map[string]any{"data": data}
+	})
+	vecs, err := c.EmbedDocs(context.Background(), []llm.EmbedDoc{{Text: "a"}, {Title: "t", Text: "bb"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != 2 || vecs[0][0] != 1 || vecs[1][0] != 5 {
+		t.Errorf("EmbedDocs = %v, want [[1] [5]]", vecs)
+	}
+}
+
+func TestGenerateContent(t *testing.T) {
+	var last map[string]any
+	c := newTestClient(t, func(path string, req map[string]any) (int, any) {
+		if path != "/v1/chat/completions" {
+			t.Errorf("path = %s", path)
+		}
+		last = req
+		return 200, map[string]any{"choices": []any{map[string]any{"message": map[string]any{"content": "hello"}}}}
+	})
+	ctx := context.Background()
+	c.SetTemperature(0)
+	out, err := c.GenerateContent(ctx, nil, []llm.Part{llm.Text("hi"), llm.Blob{MIMEType: "image/png", Data: []byte("x")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "hello" {
+		t.Errorf("GenerateContent = %q, want hello", out)
+	}
+	js, _ := json.Marshal(last)
+	for _, want := range []string{`"temperature":0`, `"text":"hi"`, `"url":"data:image/png;base64,eA=="`} {
+		if !strings.Contains(string(js), want) {
+			t.Errorf("request %s missing %s", js, want)
+		}
+	}
+
+	schema := &llm.Schema{Type: llm.TypeObject, Properties: map[string]*llm.Schema{"x": {Type: llm.TypeString}}}
+	if _, err := c.GenerateContent(ctx, schema, []llm.Part{llm.Text("hi")}); err != nil {
+		t.Fatal(err)
+	}
+	js, _ = json.Marshal(last["response_format"])
+	if want := `{"json_schema":{"name":"response","schema":{"properties":{"x":{"type":"string"}},"type":"object"}},"type":"json_schema"}`; string(js) != want {
+		t.Errorf("response_format = %s, want %s", js, want)
+	}
+}
+
+func TestError(t *testing.T) {
+	c := newTestClient(t, func(path string, req map[string]any) (int, any) {
+		return 429, map[string]any{"error": map[string]any{"message": "slow down"}}
+	})
+	_, err := c.GenerateContent(context.Background(), nil, []llm.Part{llm.Text("hi")})
+	if err == nil || !strings.Contains(err.Error(), "429") || !strings.Contains(err.Error(), "slow down") {
+		t.Errorf("GenerateContent error = %v, want 429 slow down", err)
+	}
+}
@@ -26,6 +26,9 @@ type action struct {
 	// If the following is nil, this a first post.
 	// Otherwise, it is an update.
 	IssueComment *github.IssueComment // the comment to modify
+	// Digest reports whether a first post is a digest of
+	// new comments (see [Client.EnableDigests]).
+	Digest bool `json:",omitempty"`
 }
 
 // isPost reports whether this action is a first post action.
@@ -51,12 +54,21 @@ func (p *poster) getAction(ctx context.Context, iss *github.Issue, getOverview o
 	if err != nil {
 		return nil, err
 	}
-	comment, err := comment(r.Overview.Response, p.w)
-	if err != nil {
-		return nil, err
+	tldr := ""
+	if r.TLDR != nil {
+		tldr = r.TLDR.Response
+	}
+	var body string
+	if p.plainText[iss.Project()] {
+		body = plainComment(tldr, r.Overview.Response)
+	} else {
+		body, err = comment(tldr, r.Overview.Response, p.w)
+		if err != nil {
+			return nil, err
+		}
 	}
 	changes := &github.IssueCommentChanges{
-		Body: comment,
+		Body: body,
 	}
 	return &action{
 		Issue:        iss,
@@ -82,12 +94,29 @@ func (ar *actioner) ForDisplay(data []byte) string {
 	if err != nil {
 		return fmt.Sprintf("ERROR: %v", err)
 	}
+	if a.Digest {
+		return "post digest comment to: " + a.Issue.HTMLURL + "\nnew comment:\n" + a.Changes.Body
+	}
+	if a.isPost() && a.Issue.PullRequest != nil {
+		return "post pull request comment to: " + a.Issue.HTMLURL + "\nnew comment:\n" + a.Changes.Body
+	}
 	if a.isPost() {
 		return "post issue comment (and add link) to: " + a.Issue.HTMLURL + "\nnew comment:\n" + a.Changes.Body
 	}
 	return "update issue comment: " + a.IssueComment.HTMLURL + "\nupdated comment:\n" + a.Changes.Body
 }
 
+// Implements [actions.Coalescer.Target].
+// Successive updates of the same overview comment are coalesced;
+// first posts are not.
+func (ar *actioner) Target(data []byte) string {
+	a, err := decodeAction(data)
+	if err != nil || a.isPost() {
+		return ""
+	}
+	return a.IssueComment.URL
+}
+
 // decodeAction unmarshals the JSON into an action.
 func decodeAction(b []byte) (*action, error) {
 	var action action
@@ -119,6 +148,7 @@ func runFromActionLog(ctx context.Context, data []byte,
 
 var (
 	errStaleAction            = errors.New("stale update action")
+	errMuted                  = errors.New("issue muted")
 	errEditIssueCommentFailed = errors.New("edit issue comment failed")
 	errPostIssueCommentFailed = errors.New("post issue comment failed")
 	errDownloadIssueFailed    = errors.New("download issue failed")
@@ -131,7 +161,13 @@ var (
 // If GitHub returns an error, add it to the action log for this action.
 // It is unclear what the right behavior is, but at least at present all
 // failed actions are available to the program and could be re-run.
+//
+// Actions on issues muted since they were logged fail with an error
+// wrapping errMuted, so they are never run.
 func (p *poster) runAction(ctx context.Context, a *action) (*result, error) {
+	if p.isMuted(a.Issue) {
+		return nil, fmt.Errorf("%w: %s#%d", errMuted, a.Issue.Project(), a.Issue.Number)
+	}
 	if a.isPost() {
 		return p.runPostAction(ctx, a)
 	}
@@ -146,7 +182,12 @@ func (p *poster) runPostAction(ctx context.Context, a *action) (*result, error)
 	p.slog.Info("overview: running post action", "action", a)
 	_, url, err := p.gh.PostIssueComment(ctx, a.Issue, a.Changes)
 	if err != nil {
-		return nil, fmt.Errorf("%w issue=%d: %v", errPostIssueCommentFailed, a.Issue.Number, err)
+		return nil, fmt.Errorf("%w issue=%d: %w", errPostIssueCommentFailed, a.Issue.Number, err)
+	}
+	if a.Issue.PullRequest != nil || a.Digest {
+		// Pull request descriptions belong to their authors,
+		// and issue bodies link only to overviews.
+		return &result{URL: url}, nil
 	}
 	if err := p.addLinkToComment(ctx, a.Issue, url); err != nil {
 		// A failure here is not fatal, as it will be re-tried when the overview is updated.
@@ -199,10 +240,12 @@ func (p *poster) runUpdateAction(ctx context.Context, a *action) (*result, error
 	// Check if the update action is stale.
 	// This happens if a newer update action is created while an older update action is
 	// still waiting for approval.
+	// (Pull request overviews are updated on review comments,
+	// which the issue state does not track.)
 	project, issue := a.Issue.Project(), a.Issue.Number
 	k := string(p.issueStateKey(project, issue))
 	p.db.Lock(k)
-	if lc := p.lastComment(project, issue); lc > a.LastComment {
+	if lc := p.lastComment(project, issue); lc > a.LastComment && a.Issue.PullRequest == nil {
 		p.db.Unlock(k)
 		return nil, fmt.Errorf("%w issue=%d, (last comment in action = %d, last comment in db = %d)", errStaleAction,
 			issue, a.LastComment, lc)
@@ -216,10 +259,13 @@ func (p *poster) runUpdateAction(ctx context.Context, a *action) (*result, error
 	// from itself or other entities.
 	err := p.gh.EditIssueComment(ctx, a.IssueComment, a.Changes)
 	if err != nil {
-		return nil, fmt.Errorf("%w issue=%d, comment=%s: %v", errEditIssueCommentFailed,
+		return nil, fmt.Errorf("%w issue=%d, comment=%s: %w", errEditIssueCommentFailed,
 			a.IssueComment.Issue(), a.IssueComment.HTMLURL, err)
 	}
 
+	if a.Issue.PullRequest != nil {
+		return &result{URL: a.IssueComment.HTMLURL}, nil
+	}
 	if err := p.addLinkToComment(ctx, a.Issue, a.IssueComment.HTMLURL); err != nil {
 		// A failure here is not fatal, as it will be re-tried next time the overview is updated.
 		p.slog.Error("overview: could not add link to comment", "error", err)
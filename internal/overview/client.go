@@ -14,18 +14,25 @@
 //
 //   - (overview.Run, $name, $bot) -> [runState]: holds state about calls to [Client.Run]
 //   - (overview.IssueState, $name, $bot, $project, $issue) -> [issueState]: holds state about individual GitHub issues
+//   - (overview.DigestState, $name, $bot, $project, $issue) -> [digestState]: holds state about digests of individual GitHub issues
+//   - (overview.History, $name, $bot, $project, $issue, $unixnano) -> [HistoryEntry]: holds the history of generated overviews
 //   - Watchers with name "overview.PostOrUpdate"+$name+$bot.
 //   - Action log entries of kind "overview.Post" and "overview.Update".
+//   - Action log entries of kind "overview.DiffSummary", keyed by ($name, $bot, $project, $issue, $hash).
 package overview
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"iter"
 	"log/slog"
 	"time"
 
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/mute"
+	"golang.org/x/oscar/internal/repoconfig"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/storage/timed"
 	"rsc.io/ordered"
@@ -39,6 +46,9 @@ type Client struct {
 
 	g *generator // for generating overviews
 	p *poster    // for modifying GitHub
+
+	postLength llmapp.Length      // length of overviews posted by [Client.Run]
+	repo       *repoconfig.Config // if non-nil, per-repository settings; see [Client.SetRepoConfig]
 }
 
 // New returns a new Client used to generate and post overviews to GitHub.
@@ -53,14 +63,21 @@ func New(lg *slog.Logger, db storage.DB, gh *github.Client, lc *llmapp.Client, n
 		p:    newPoster(lg, db, gh, name, bot),
 	}
 	c.g.skipCommentsBy(bot)
+	c.p.pullOverview = c.forPullRequestPost
+	c.p.digestOverview = func(ctx context.Context, iss *github.Issue, lastRead int64) (*IssueUpdateResult, error) {
+		return c.forProject(iss.Project()).ForIssueUpdate(ctx, iss, lastRead)
+	}
 	return c
 }
 
 // the minimum time between calls to [poster.run]
 var minTimeBetweenUpdates = 24 * time.Hour
 
-// Run computes AI-generated overviews of GitHub issues, and adds
+// Run computes AI-generated overviews of GitHub issues (and, if
+// [Client.EnablePullRequests] has been called, pull requests), and adds
 // appropriate actions (post or update) to the action log.
+// If [Client.EnableDigests] has been called, it also adds actions
+// to post digests of new comments on hot issues.
 //
 // Run is configured to only do work once every 24 hours, to avoid
 // making too many LLM calls. If not enough time has passed since the
@@ -93,9 +110,10 @@ func (c *Client) run(ctx context.Context, now time.Time) error {
 		c.slog.Info("overview.Run: skipped (last successful run happened too recently)", "last run", lr, "min time", minTimeBetweenUpdates)
 		return nil
 	}
-	if err := c.p.run(ctx, c.ForIssue, now); err != nil {
+	if err := c.p.run(ctx, c.forPost, now); err != nil {
 		return err
 	}
+	c.p.runDigests(ctx, now)
 
 	c.setLastRun(now)
 	return nil
@@ -109,8 +127,112 @@ func (c *Client) Latest() timed.DBTime {
 // ForIssue returns an LLM-generated overview of the issue and its comments.
 // It does not make any requests to, or modify, GitHub; the issue and comment data must already
 // be stored in the database.
+//
+// On success, ForIssue records the result in the issue's overview history
+// (see [Client.History]), so that it can be retrieved with [Client.LastForIssue]
+// if the LLM later becomes unavailable.
 func (c *Client) ForIssue(ctx context.Context, iss *github.Issue) (*IssueResult, error) {
-	return c.g.issue(ctx, iss)
+	r, err := c.g.issue(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	c.record(iss, &HistoryEntry{
+		Type:            IssueOverview,
+		TotalComments:   r.TotalComments,
+		LastComment:     r.LastComment,
+		SkippedComments: r.SkippedComments,
+	}, r.Overview)
+	return r, nil
+}
+
+// ForPullRequest returns an LLM-generated overview of the pull request,
+// its comments, and its review threads. It returns an error if iss is
+// not a pull request.
+// Like [Client.ForIssue], it does not make any requests to GitHub,
+// and review comments are only present in the database if they are synced
+// (see [github.Client.EnableReviewComments]).
+//
+// On success, ForPullRequest records the result in the pull request's
+// overview history (see [Client.History]).
+func (c *Client) ForPullRequest(ctx context.Context, iss *github.Issue) (*PullRequestResult, error) {
+	r, err := c.g.pullRequest(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	c.record(iss, &HistoryEntry{
+		Type:            PullRequestOverview,
+		TotalComments:   r.TotalComments,
+		LastComment:     r.LastComment,
+		SkippedComments: r.SkippedComments,
+	}, r.Overview)
+	return r, nil
+}
+
+// forPost returns the overview of the issue to post to GitHub:
+// a TL;DR (see [IssueResult.TLDR]) together with an overview of
+// the length set by [Client.SetPostLength].
+// The two are generated by separate LLM calls.
+func (c *Client) forPost(ctx context.Context, iss *github.Issue) (*IssueResult, error) {
+	c = c.forProject(iss.Project())
+	tldr, err := c.WithLength(llmapp.TLDR).g.issue(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.WithLength(c.postLength).ForIssue(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	r.TLDR = tldr.Overview
+	return r, nil
+}
+
+// PrepareBatch returns a batch of the LLM requests needed to generate
+// overviews of the given issues, skipping issues whose overviews are
+// already cached. Submitting the batch (see [llmapp.Batch.Submit]) and
+// waiting for it with [llmapp.Client.PollBatch] pre-generates the
+// overviews at batch prices, so that later calls to [Client.ForIssue]
+// are answered from the LLM cache.
+//
+// Like ForIssue, PrepareBatch does not make any requests to GitHub.
+func (c *Client) PrepareBatch(ctx context.Context, issues iter.Seq[*github.Issue]) (*llmapp.Batch, error) {
+	lc, b := c.g.lc.NewBatch()
+	g := *c.g
+	g.lc = lc
+	for iss := range issues {
+		if _, err := g.issue(ctx, iss); err != nil && !errors.Is(err, llmapp.ErrBatched) {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// LastForIssue returns the most recent overview of the issue generated
+// by [Client.ForIssue], along with the time it was generated.
+// The result may be stale: the issue may have changed since.
+// Only the overview text is saved, so the result's Overview field
+// is marked as cached and has no prompt or policy evaluation.
+// It returns ok=false if there is no saved overview for the issue.
+func (c *Client) LastForIssue(iss *github.Issue) (_ *IssueResult, generated time.Time, ok bool) {
+	var last *HistoryEntry
+	for h := range c.History(iss.Project(), iss.Number) {
+		if h.Type == IssueOverview {
+			last = h
+		}
+	}
+	if last == nil {
+		return nil, time.Time{}, false
+	}
+	return &IssueResult{
+		TotalComments:   last.TotalComments,
+		LastComment:     last.LastComment,
+		SkippedComments: last.SkippedComments,
+		Overview: &llmapp.Result{
+			Response:      last.Text,
+			Cached:        true,
+			Model:         last.Model,
+			PromptVersion: last.PromptVersion,
+		},
+	}, last.Time, true
 }
 
 // ForIssueUpdate returns an LLM-generated overview of the issue and its
@@ -120,8 +242,64 @@ func (c *Client) ForIssue(ctx context.Context, iss *github.Issue) (*IssueResult,
 //
 // ForIssueUpdate does not make any requests to, or modify, GitHub; the issue and comment data must already
 // be stored in db.
+//
+// On success, ForIssueUpdate records the result in the issue's overview history
+// (see [Client.History]).
 func (c *Client) ForIssueUpdate(ctx context.Context, iss *github.Issue, lastRead int64) (*IssueUpdateResult, error) {
-	return c.g.issueUpdate(ctx, iss, lastRead)
+	r, err := c.g.issueUpdate(ctx, iss, lastRead)
+	if err != nil {
+		return nil, err
+	}
+	c.record(iss, &HistoryEntry{
+		Type:            UpdateOverview,
+		TotalComments:   r.TotalComments,
+		LastComment:     r.LastComment,
+		SkippedComments: r.SkippedComments,
+		LastRead:        lastRead,
+	}, r.Overview)
+	return r, nil
+}
+
+// WithLength returns a copy of the Client that generates overviews
+// of the given length. The copy shares the Client's state,
+// including its overview history.
+func (c *Client) WithLength(l llmapp.Length) *Client {
+	g := *c.g
+	g.lc = g.lc.WithLength(l)
+	cc := *c
+	cc.g = &g
+	return &cc
+}
+
+// WithLanguage returns a copy of the Client that generates overviews
+// in the language with the given BCP 47 tag (see [llmapp.Client.WithLanguage]).
+// The copy shares the Client's state, including its overview history.
+func (c *Client) WithLanguage(lang string) *Client {
+	g := *c.g
+	g.lc = g.lc.WithLanguage(lang)
+	cc := *c
+	cc.g = &g
+	return &cc
+}
+
+// forProject returns a copy of the Client that generates the overviews
+// it posts in the project in the project's language, if it has one
+// (see [Client.SetRepoConfig]), or c itself.
+func (c *Client) forProject(project string) *Client {
+	if c.repo == nil {
+		return c
+	}
+	if lang := c.repo.Settings(project).Language; lang != "" {
+		return c.WithLanguage(lang)
+	}
+	return c
+}
+
+// SetPostLength sets the length of the overviews that [Client.Run]
+// posts to GitHub, in the collapsible details section below the TL;DR.
+// The default is [llmapp.Long].
+func (c *Client) SetPostLength(l llmapp.Length) {
+	c.postLength = l
 }
 
 // EnableProject enables the Client to post on and update issues in the given
@@ -130,6 +308,13 @@ func (c *Client) EnableProject(project string) {
 	c.p.EnableProject(project)
 }
 
+// EnablePlainText configures the Client to post overviews in the given
+// GitHub project as plain text, without hidden tags, collapsible
+// sections or heavy formatting, so that they read well with a screen reader.
+func (c *Client) EnablePlainText(project string) {
+	c.p.EnablePlainText(project)
+}
+
 // RequireApproval configures the Client to require approval for all actions.
 func (c *Client) RequireApproval() {
 	c.p.RequireApproval()
@@ -172,6 +357,23 @@ func (c *Client) SkipCommentsBy(user string) {
 	c.p.SkipCommentsBy(user)
 }
 
+// SkipMuted configures the Client to never post or update
+// overviews, digests or diff summaries on the issues and pull
+// requests in the mute set m, including for actions logged
+// before the issue was muted.
+func (c *Client) SkipMuted(m *mute.Set) {
+	c.p.muted = m
+}
+
+// SetRepoConfig configures the Client to apply the per-repository
+// settings in rc: it does not post or update overviews, digests
+// or diff summaries on issues with their project's opt-out labels,
+// and it writes the overviews it posts in their project's language.
+func (c *Client) SetRepoConfig(rc *repoconfig.Config) {
+	c.repo = rc
+	c.p.repo = rc
+}
+
 type runState struct {
 	LastRun string // the time the last sucessful (non-skipped) call to [Client.Run] began
 }
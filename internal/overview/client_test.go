@@ -6,6 +6,9 @@ package overview
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/llm"
 	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/repoconfig"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/testutil"
 )
@@ -44,6 +48,9 @@ func TestClientRun(t *testing.T) {
 	if len(edits) == 0 {
 		t.Fatal("Client.run (first): expected edits, got none")
 	}
+	if body := edits[0].IssueCommentChanges.Body; !strings.Contains(body, "**TL;DR:**") || !strings.Contains(body, "<details><summary>Details</summary>") {
+		t.Errorf("Client.run (first): comment missing TL;DR or details:\n%s", body)
+	}
 	gh.Testing().ClearEdits()
 
 	gh.Testing().AddIssue(project, &github.Issue{Number: 2, CreatedAt: jan1_2024})
@@ -63,3 +70,219 @@ func TestClientRun(t *testing.T) {
 		t.Fatalf("Client.run (third): expected edits, got none")
 	}
 }
+
+func TestLastForIssue(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	ctx := context.Background()
+
+	var fail error
+	g := llm.TestContentGenerator("test", func(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+		if fail != nil {
+			return "", fail
+		}
+		return llm.EchoTextResponse(parts...), nil
+	})
+	lc := llmapp.New(lg, g, db)
+
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, CreatedAt: jan1_2024})
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "hello"})
+	iss, err := github.LookupIssue(db, project, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(lg, db, gh, lc, "test", "testbot")
+	if _, _, ok := c.LastForIssue(iss); ok {
+		t.Fatal("LastForIssue: ok before any overview was generated")
+	}
+
+	want, err := c.ForIssue(ctx, iss)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new comment means the cached LLM response can't be used.
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "goodbye"})
+	fail = errors.New("LLM down")
+	if _, err := c.ForIssue(ctx, iss); err == nil {
+		t.Fatal("ForIssue: succeeded unexpectedly")
+	}
+
+	got, when, ok := c.LastForIssue(iss)
+	if !ok || when.IsZero() {
+		t.Fatalf("LastForIssue = _, %v, %v; want saved result", when, ok)
+	}
+	if got.Overview.Response != want.Overview.Response || got.TotalComments != 1 {
+		t.Errorf("LastForIssue = %+v, want %+v", got, want)
+	}
+}
+
+func TestPrepareBatch(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	check := testutil.Checker(t)
+	ctx := context.Background()
+
+	calls := 0
+	g := llm.TestBatchContentGenerator(llm.TestContentGenerator("test", func(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+		calls++
+		return llm.EchoTextResponse(parts...), nil
+	}))
+	lc := llmapp.New(lg, g, db)
+
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	var issues []*github.Issue
+	for i := range 3 {
+		iss := &github.Issue{Number: int64(i + 1), CreatedAt: jan1_2024}
+		gh.Testing().AddIssue(project, iss)
+		gh.Testing().AddIssueComment(project, iss.Number, &github.IssueComment{Body: "hello"})
+		issues = append(issues, iss)
+	}
+	c := New(lg, db, gh, lc, "test", "testbot")
+
+	// Issue 1 is already cached.
+	_, err := c.ForIssue(ctx, issues[0])
+	check(err)
+
+	b, err := c.PrepareBatch(ctx, slices.Values(issues))
+	check(err)
+	if b.Len() != 2 {
+		t.Fatalf("PrepareBatch: got %d requests, want 2", b.Len())
+	}
+	job, err := b.Submit(ctx)
+	check(err)
+	_, err = lc.PollBatch(ctx, job.ID)
+	check(err)
+
+	calls = 0
+	for _, iss := range issues {
+		r, err := c.ForIssue(ctx, iss)
+		check(err)
+		if !r.Overview.Cached {
+			t.Errorf("ForIssue(%d): not cached after batch", iss.Number)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("ForIssue after batch: %d LLM calls, want 0", calls)
+	}
+}
+
+func TestLength(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	check := testutil.Checker(t)
+	ctx := context.Background()
+
+	var last string // last instruction prompt
+	g := llm.TestContentGenerator("test", func(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+		last = string(parts[len(parts)-1].(llm.Text))
+		return llm.EchoTextResponse(parts...), nil
+	})
+	lc := llmapp.New(lg, g, db)
+
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	iss := &github.Issue{Number: 1, CreatedAt: jan1_2024}
+	gh.Testing().AddIssue(project, iss)
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "hello"})
+
+	c := New(lg, db, gh, lc, "test", "testbot")
+	const lengthReq = "Length Requirements"
+
+	_, err := c.ForIssue(ctx, iss)
+	check(err)
+	if strings.Contains(last, lengthReq) {
+		t.Errorf("ForIssue: unexpected length requirements in prompt:\n%s", last)
+	}
+
+	_, err = c.WithLength(llmapp.Medium).ForIssue(ctx, iss)
+	check(err)
+	if !strings.Contains(last, "3 short paragraphs") {
+		t.Errorf("WithLength(Medium).ForIssue: prompt missing medium length requirements:\n%s", last)
+	}
+
+	c.EnableProject(project)
+	c.SetMinComments(1)
+	c.SetPostLength(llmapp.Short)
+	check(c.run(ctx, time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC)))
+	if !strings.Contains(last, "3 sentences") {
+		t.Errorf("Run with SetPostLength(Short): prompt missing short length requirements:\n%s", last)
+	}
+}
+
+func TestClientRunPlainText(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	lc := llmapp.New(lg, llm.EchoContentGenerator(), db)
+	check := testutil.Checker(t)
+
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, CreatedAt: jan1_2024})
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "hello"})
+
+	c := New(lg, db, gh, lc, "test", "testbot")
+	c.EnableProject(project)
+	c.EnablePlainText(project)
+	c.SetMinComments(1)
+	c.AutoApprove()
+
+	ctx := context.Background()
+	check(c.run(ctx, time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC)))
+	check(actions.Run(ctx, lg, db))
+	edits := gh.Testing().Edits()
+	if len(edits) == 0 {
+		t.Fatal("Client.run: expected edits, got none")
+	}
+	body := edits[0].IssueCommentChanges.Body
+	for _, bad := range []string{"<!--", "<details>", "<sub>", "**"} {
+		if strings.Contains(body, bad) {
+			t.Errorf("plain-text comment contains %q:\n%s", bad, body)
+		}
+	}
+	if !strings.Contains(body, "TL;DR: ") {
+		t.Errorf("plain-text comment missing TL;DR:\n%s", body)
+	}
+}
+
+func TestClientRunRepoConfig(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	check := testutil.Checker(t)
+	ctx := context.Background()
+
+	var last string // last instruction prompt
+	g := llm.TestContentGenerator("test", func(ctx context.Context, schema *llm.Schema, parts []llm.Part) (string, error) {
+		last = string(parts[len(parts)-1].(llm.Text))
+		return llm.EchoTextResponse(parts...), nil
+	})
+	lc := llmapp.New(lg, g, db)
+
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, CreatedAt: jan1_2024, Labels: []github.Label{{Name: "NoBot"}}})
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "hello"})
+	gh.Testing().AddIssue(project, &github.Issue{Number: 2, CreatedAt: jan1_2024})
+	gh.Testing().AddIssueComment(project, 2, &github.IssueComment{Body: "hello"})
+	gh.Testing().SetRepoConfig(project, []byte("optoutlabels: [NoBot]\nlanguage: ja\n"))
+
+	c := New(lg, db, gh, lc, "test", "testbot")
+	c.EnableProject(project)
+	c.SetMinComments(1)
+	c.AutoApprove()
+	c.SetRepoConfig(repoconfig.New(lg, gh))
+
+	check(c.run(ctx, time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC)))
+	check(actions.Run(ctx, lg, db))
+	edits := gh.Testing().Edits()
+	if len(edits) == 0 || slices.ContainsFunc(edits, func(e *github.TestingEdit) bool { return e.Issue != 2 }) {
+		t.Fatalf("Client.run: edits %v, want only on issue 2 (issue 1 opted out)", edits)
+	}
+	if !strings.Contains(last, `language with the BCP 47 tag "ja"`) {
+		t.Errorf("Client.run: prompt missing the repository's language:\n%s", last)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// DiffResult is the result of [Client.ForPullRequestDiff].
+// It contains the generated summary and metadata about the diff.
+type DiffResult struct {
+	Files     int            // number of files changed by the pull request
+	Additions int            // number of lines added
+	Deletions int            // number of lines deleted
+	Summary   *llmapp.Result // the LLM-generated summary for reviewers
+}
+
+// ForPullRequestDiff returns an LLM-generated summary of the changes
+// made by the pull request, written for its reviewers: what changed,
+// the areas that deserve the closest review, and a suggested review order.
+// It returns an error if iss is not a pull request.
+//
+// Unlike [Client.ForPullRequest], ForPullRequestDiff downloads the
+// pull request's diff from GitHub.
+//
+// On success, ForPullRequestDiff records the result in the pull request's
+// overview history (see [Client.History]).
+func (c *Client) ForPullRequestDiff(ctx context.Context, iss *github.Issue) (*DiffResult, error) {
+	r, err := c.g.pullRequestDiff(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	c.record(iss, &HistoryEntry{Type: DiffSummary}, r.Summary)
+	return r, nil
+}
+
+// See comment on [Client.ForPullRequestDiff].
+func (g *generator) pullRequestDiff(ctx context.Context, pr *github.Issue) (*DiffResult, error) {
+	if pr.PullRequest == nil {
+		return nil, fmt.Errorf("%s#%d is not a pull request", pr.Project(), pr.Number)
+	}
+	files, err := g.gh.PullRequestFiles(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	r := &DiffResult{Files: len(files)}
+	var docs []*llmapp.Doc
+	for _, f := range files {
+		r.Additions += f.Additions
+		r.Deletions += f.Deletions
+		docs = append(docs, f.ToLLMDoc())
+	}
+	r.Summary, err = g.lc.PullRequestDiffSummary(ctx, pr.ToLLMDoc(), docs)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// PostDiffSummary adds an action to the action log to post the summary r
+// of the pull request's diff (see [Client.ForPullRequestDiff]) as a comment
+// on the pull request. Like the Client's other actions, it requires approval
+// unless the Client is configured with [Client.AutoApprove].
+// It reports whether the action was added: an identical summary
+// is only posted once, and none is posted on pull requests with
+// an opt-out label (see [Client.SetRepoConfig]).
+func (c *Client) PostDiffSummary(iss *github.Issue, r *DiffResult) bool {
+	if _, ok := c.p.optedOut(iss); ok {
+		return false
+	}
+	body := diffCommentBody(r.Summary.Response)
+	if c.p.plainText[iss.Project()] {
+		body = github.PlainText(body)
+	}
+	a := &diffAction{
+		Issue:   iss,
+		Changes: &github.IssueCommentChanges{Body: body},
+	}
+	h := sha256.Sum256([]byte(body))
+	key := ordered.Encode(c.p.name, c.p.bot, iss.Project(), iss.Number, fmt.Sprintf("%x", h[:8]))
+	return c.p.logDiff(c.db, key, storage.JSON(a), c.p.requireApproval)
+}
+
+// diffCommentBody returns the text of a comment posting
+// the diff summary s.
+func diffCommentBody(s string) string {
+	// These strings may be freely edited.
+	return "**AI-generated summary of this pull request's changes, for reviewers**\n\n" +
+		strings.TrimSpace(s) + "\n\n" +
+		"<sub>(Generated by AI from the diff. It may be wrong or incomplete; please review the code itself.)</sub>\n"
+}
+
+// diffActionKind is the action log kind of the actions
+// logged by [Client.PostDiffSummary].
+const diffActionKind = "overview.DiffSummary"
+
+// A diffAction is an action to post a diff summary.
+type diffAction struct {
+	Issue   *github.Issue               // the pull request
+	Changes *github.IssueCommentChanges // the comment to post
+}
+
+// diffActioner implements [actions.Actioner] for diff actions.
+type diffActioner struct {
+	p *poster
+}
+
+var _ actions.Actioner = (*diffActioner)(nil)
+
+// Implements [actions.Actioner.Run].
+func (ar *diffActioner) Run(ctx context.Context, data []byte) ([]byte, error) {
+	var a diffAction
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	if ar.p.isMuted(a.Issue) {
+		return nil, fmt.Errorf("%w: %s#%d", errMuted, a.Issue.Project(), a.Issue.Number)
+	}
+	_, url, err := ar.p.gh.PostIssueComment(ctx, a.Issue, a.Changes)
+	if err != nil {
+		return nil, fmt.Errorf("%w issue=%d: %w", errPostIssueCommentFailed, a.Issue.Number, err)
+	}
+	return storage.JSON(&result{URL: url}), nil
+}
+
+// Implements [actions.Actioner.ForDisplay].
+func (ar *diffActioner) ForDisplay(data []byte) string {
+	var a diffAction
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	return "post diff summary to: " + a.Issue.HTMLURL + "\nnew comment:\n" + a.Changes.Body
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestForPullRequestDiff(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	lc := llmapp.New(lg, llm.EchoContentGenerator(), db)
+	check := testutil.Checker(t)
+
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "an issue", CreatedAt: jan1_2024})
+	gh.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "a change", CreatedAt: jan1_2024, PullRequest: new(struct{})})
+	pr, err := github.LookupIssue(db, project, 2)
+	check(err)
+	gh.Testing().SetPullRequestFiles(pr, []*github.PullRequestFile{
+		{Filename: "a.go", Status: "modified", Additions: 2, Deletions: 1, Patch: "@@ -1 +1,2 @@\n-x\n+y\n+z"},
+		{Filename: "logo.png", Status: "added"},
+	})
+
+	c := New(lg, db, gh, lc, "test", "testbot")
+	c.AutoApprove()
+
+	iss, err := github.LookupIssue(db, project, 1)
+	check(err)
+	if _, err := c.ForPullRequestDiff(ctx, iss); err == nil {
+		t.Error("ForPullRequestDiff(issue) succeeded, want error")
+	}
+
+	r, err := c.ForPullRequestDiff(ctx, pr)
+	check(err)
+	if r.Files != 2 || r.Additions != 2 || r.Deletions != 1 {
+		t.Errorf("ForPullRequestDiff: files=%d +%d -%d, want 2 +2 -1", r.Files, r.Additions, r.Deletions)
+	}
+	for _, s := range []string{"a.go", `modified, +2 -1\n@@ -1 +1,2 @@`, "logo.png", "(diff not available)"} {
+		if !strings.Contains(r.Summary.Response, s) {
+			t.Errorf("summary missing %q:\n%s", s, r.Summary.Response)
+		}
+	}
+	var types []string
+	for h := range c.History(project, 2) {
+		types = append(types, h.Type)
+	}
+	if len(types) != 1 || types[0] != DiffSummary {
+		t.Errorf("history types = %v, want [%s]", types, DiffSummary)
+	}
+
+	if !c.PostDiffSummary(pr, r) {
+		t.Fatal("PostDiffSummary: not logged")
+	}
+	if c.PostDiffSummary(pr, r) {
+		t.Error("PostDiffSummary (same summary): logged again")
+	}
+	check(actions.Run(ctx, lg, db))
+	edits := gh.Testing().Edits()
+	if len(edits) != 1 {
+		t.Fatalf("got %d edits, want 1", len(edits))
+	}
+	if e := edits[0]; e.Issue != 2 || !strings.HasPrefix(e.IssueCommentChanges.Body, "**AI-generated summary") {
+		t.Errorf("edit = %v", e)
+	}
+}
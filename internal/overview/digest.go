@@ -0,0 +1,188 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/github/wrap"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// EnableDigests configures [Client.Run] to also post "what changed"
+// digests on hot issues: issues that already have an overview posted
+// by the Client and that have received at least minComments new comments
+// since the last overview or digest.
+// A digest is a new comment summarizing only the comments since the
+// previous summary (see [Client.ForIssueUpdate]), and is posted at most
+// once per every interval for each issue.
+// Digests are posted through the action log, and no link to them
+// is added to the issue body.
+//
+// An every of 0 or less disables digests, which is the default.
+func (c *Client) EnableDigests(every time.Duration, minComments int) {
+	c.p.digestEvery = every
+	c.p.digestMinComments = minComments
+}
+
+// A digestFunc returns an overview of the issue's comments after lastRead.
+type digestFunc func(ctx context.Context, iss *github.Issue, lastRead int64) (*IssueUpdateResult, error)
+
+// digestState holds the state of an issue's digests for a [poster].
+type digestState struct {
+	LastRead int64     `json:"last_read"` // the last comment covered by the last summary
+	Time     time.Time `json:"time"`      // the time of the last summary
+}
+
+// digestStateKey returns the key to look up the digest state of the
+// given issue.
+func (p *poster) digestStateKey(project string, issue int64) []byte {
+	return ordered.Encode(digestStateKind, p.bot, p.name, project, issue)
+}
+
+// getDigestState returns the stored digest state of the given issue.
+func (p *poster) getDigestState(project string, issue int64) digestState {
+	var st digestState
+	if b, ok := p.db.Get(p.digestStateKey(project, issue)); ok {
+		if err := json.Unmarshal(b, &st); err != nil {
+			// Unreachable except bug in this package.
+			p.db.Panic("poster: could not unmarshal digestState", "err", err)
+		}
+	}
+	return st
+}
+
+// runDigests logs actions to post digests on the hot issues
+// that have overviews posted by p (see [Client.EnableDigests]).
+// Problems with individual issues are logged, not returned.
+func (p *poster) runDigests(ctx context.Context, now time.Time) {
+	if p.digestEvery <= 0 {
+		return
+	}
+	start := ordered.Encode(actionKind, actionContextPost)
+	end := ordered.Encode(actionKind, actionContextPost, ordered.Inf)
+	for e := range actions.Scan(p.db, start, end) {
+		if !e.IsDone() || e.Error != "" {
+			continue
+		}
+		a, err := decodeAction(e.Action)
+		if err != nil {
+			p.slog.Error("overview: digest: action decode", "key", storage.Fmt(e.Key), "err", err)
+			continue
+		}
+		if a.Issue.PullRequest != nil || !p.projects[a.Issue.Project()] {
+			continue
+		}
+		if err := p.logDigest(ctx, a, e.Done, now); err != nil {
+			p.slog.Error("overview: digest", "project", a.Issue.Project(), "issue", a.Issue.Number, "err", err)
+		}
+	}
+}
+
+// logDigest logs an action to post a digest on the issue whose
+// overview was posted by action a at the given time, if it needs one.
+func (p *poster) logDigest(ctx context.Context, a *action, posted, now time.Time) error {
+	project, num := a.Issue.Project(), a.Issue.Number
+	iss, err := github.LookupIssue(p.db, project, num)
+	if err != nil {
+		return err
+	}
+	st := p.getDigestState(project, num)
+	if st.Time.IsZero() {
+		st = digestState{LastRead: a.LastComment, Time: posted}
+	}
+
+	// Count the new comments, and find the last comment
+	// read by the last summary that a digest can start from.
+	lastRead, n := int64(0), 0
+	for ic := range p.gh.Comments(iss) {
+		if ic.User.Login == p.bot || p.skipCommentAuthors[ic.User.Login] {
+			continue
+		}
+		if id := ic.CommentID(); id <= st.LastRead {
+			lastRead = max(lastRead, id)
+		} else {
+			n++
+		}
+	}
+	if skip, reason := p.skipDigest(iss, st, lastRead, n, now); skip {
+		p.slog.Debug("overview: skipping digest", "project", project, "issue", num, "reason", reason)
+		return nil
+	}
+
+	r, err := p.digestOverview(ctx, iss, lastRead)
+	if err != nil {
+		return err
+	}
+	var body string
+	if p.plainText[project] {
+		body = github.PlainText(digestBody(r.Overview.Response))
+	} else {
+		body, err = p.dw.Wrap(digestBody(r.Overview.Response), nil)
+		if err != nil {
+			return err
+		}
+	}
+	act := &action{
+		Issue:       iss,
+		LastComment: r.LastComment,
+		Changes:     &github.IssueCommentChanges{Body: body},
+		Digest:      true,
+	}
+	p.slog.Info("overview: logging digest", "project", project, "issue", num, "new comments", n)
+	p.logAction(p.db, ordered.Encode(actionContextDigest, project, num, r.LastComment), act.encode(), p.requireApproval)
+	p.db.Set(p.digestStateKey(project, num), storage.JSON(digestState{LastRead: r.LastComment, Time: now}))
+	p.db.Flush()
+	return nil
+}
+
+// skipDigest reports whether the issue, which has the given digest
+// state and n new comments since the comment lastRead, should be skipped,
+// and if so, the reason why.
+func (p *poster) skipDigest(iss *github.Issue, st digestState, lastRead int64, n int, now time.Time) (skip bool, reason string) {
+	if iss.State == "closed" {
+		return true, "issue closed"
+	}
+	if p.isMuted(iss) {
+		return true, "issue muted"
+	}
+	if label, ok := p.optedOut(iss); ok {
+		return true, "issue has opt-out label " + label
+	}
+	if now.Sub(st.Time) < p.digestEvery {
+		return true, fmt.Sprintf("last summary too recent (%s)", st.Time)
+	}
+	if n < p.digestMinComments {
+		return true, fmt.Sprintf("not enough new comments (%d < %d)", n, p.digestMinComments)
+	}
+	if lastRead == 0 {
+		return true, "no comments read by last summary"
+	}
+	return false, ""
+}
+
+// digestBody returns the unwrapped text of a digest comment
+// summarizing new comments.
+func digestBody(s string) string {
+	// These strings may be freely edited.
+	body := "\n**What changed since the last summary:**\n\n" + strings.TrimSpace(s) + "\n"
+	footer := "<sub>(Generated by AI. Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](https://github.com/golang/go/discussions/67901).)</sub>\n"
+	return strings.Join([]string{body, footer}, "\n")
+}
+
+// newDigestWrapper returns the wrapper for digest comments
+// posted by the named poster, which differs from the wrapper
+// for overviews so that digests are not mistaken for them
+// (see [poster.isOverviewComment]).
+func newDigestWrapper(bot, name string) *wrap.Wrapper {
+	return wrap.New(bot, name+".digest")
+}
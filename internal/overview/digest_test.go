@@ -0,0 +1,107 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestRunDigests(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	check := testutil.Checker(t)
+	ctx := context.Background()
+
+	comment := func(login string) int64 {
+		return gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "a comment", User: github.User{Login: login}})
+	}
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, Body: "issue 1", CreatedAt: jan1_2024})
+	comment("rsc")
+	read := comment("gri")
+
+	p := newPoster(lg, db, gh, "test", "testbot")
+	p.EnableProject(project)
+	p.SetMinComments(1)
+	p.SkipCommentsBy("skipper")
+	p.SkipCommentsBy("") // the author of comments posted by testPoster
+	p.AutoApprove()
+	p.digestEvery = 24 * time.Hour
+	p.digestMinComments = 2
+	var lastReads []int64
+	p.digestOverview = func(_ context.Context, iss *github.Issue, lastRead int64) (*IssueUpdateResult, error) {
+		lastReads = append(lastReads, lastRead)
+		cs := slices.Collect(gh.Comments(iss))
+		return &IssueUpdateResult{
+			LastComment: cs[len(cs)-1].CommentID(),
+			Overview:    &llmapp.Result{Response: fmt.Sprintf("what changed in issue %d", iss.Number)},
+		}, nil
+	}
+	p.logAction = actions.Register(actionKind, &testPoster{p: p})
+	check(p.run(ctx, overviewFuncForTest(gh), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	check(actions.Run(ctx, lg, db))
+	digests := func(now time.Time) []string {
+		t.Helper()
+		gh.Testing().ClearEdits()
+		p.runDigests(ctx, now)
+		check(actions.Run(ctx, lg, db))
+		iss, err := github.LookupIssue(db, project, 1)
+		check(err)
+		var bodies []string
+		for ic := range gh.Comments(iss) {
+			if ic.User.Login == "" && ic.Body != "a comment" {
+				bodies = append(bodies, ic.Body)
+			}
+		}
+		return bodies
+	}
+	want, err := p.dw.Wrap(digestBody("what changed in issue 1"), nil)
+	check(err)
+	overviews := len(digests(time.Now()))
+
+	// Not hot: too few new comments, some of them skipped.
+	comment("rsc")
+	comment("skipper")
+	later := time.Now().Add(48 * time.Hour)
+	if got := digests(later); len(got) != overviews {
+		t.Fatalf("digest posted for too few comments: %q", got)
+	}
+
+	// Too soon after the overview.
+	comment("ianlancetaylor")
+	if got := digests(time.Now()); len(got) != overviews {
+		t.Fatalf("digest posted too soon: %q", got)
+	}
+
+	// Hot: a digest is posted, without a link from the issue body.
+	got := digests(later)
+	if len(got) != overviews+1 || got[len(got)-1] != want {
+		t.Fatalf("digests = %q, want new %q", got, want)
+	}
+	if !slices.Equal(lastReads, []int64{read}) {
+		t.Errorf("digest last read = %v, want [%d]", lastReads, read)
+	}
+	if e := gh.Testing().Edits(); len(e) != 0 {
+		t.Errorf("digest edited GitHub: %v", e)
+	}
+
+	// The next digest waits for the interval to pass again.
+	comment("rsc")
+	comment("gri")
+	if got := digests(later); len(got) != overviews+1 {
+		t.Fatalf("second digest posted too soon: %q", got)
+	}
+}
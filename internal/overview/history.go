@@ -0,0 +1,150 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"bytes"
+	"encoding/json"
+	"iter"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// The types of overviews recorded in the history.
+const (
+	IssueOverview       = "issue"        // generated by [Client.ForIssue]
+	UpdateOverview      = "update"       // generated by [Client.ForIssueUpdate]
+	PullRequestOverview = "pull_request" // generated by [Client.ForPullRequest]
+	DiffSummary         = "diff_summary" // generated by [Client.ForPullRequestDiff]
+)
+
+// A HistoryEntry is a record of an overview generated for an issue.
+type HistoryEntry struct {
+	Project       string
+	Issue         int64
+	Type          string    // [IssueOverview], [UpdateOverview], [PullRequestOverview] or [DiffSummary]
+	Time          time.Time // when the overview was generated
+	Model         string    // the generative model used
+	PromptVersion string    // the version of the prompt used (see [llmapp.Result])
+	Text          string    // the overview
+
+	// Metadata about the issue at the time the overview was generated.
+	TotalComments   int
+	LastComment     int64
+	SkippedComments int
+	LastRead        int64 // for [UpdateOverview], the last comment considered "old"
+}
+
+const historyKind = "overview.History"
+
+// record adds an entry for the overview r of iss to the history.
+// The entry h must have its Type and issue metadata set;
+// record fills in the rest.
+//
+// To keep the history to a reasonable size, record does not add an entry
+// if the most recent entry of the same type has the same text, model,
+// and prompt version (as is typically the case when r is cached).
+func (c *Client) record(iss *github.Issue, h *HistoryEntry, r *llmapp.Result) {
+	h.Project = iss.Project()
+	h.Issue = iss.Number
+	h.Time = time.Now()
+	h.Model = r.Model
+	h.PromptVersion = r.PromptVersion
+	h.Text = r.Response
+
+	var last *HistoryEntry
+	for e := range c.History(h.Project, h.Issue) {
+		if e.Type == h.Type && e.LastRead == h.LastRead {
+			last = e
+		}
+	}
+	if last != nil && last.Text == h.Text && last.Model == h.Model && last.PromptVersion == h.PromptVersion {
+		return
+	}
+	c.db.Set(ordered.Encode(historyKind, c.p.name, c.p.bot, h.Project, h.Issue, h.Time.UnixNano()), storage.JSON(h))
+}
+
+// History returns an iterator over the overviews generated for the given
+// issue by [Client.ForIssue], [Client.ForIssueUpdate],
+// [Client.ForPullRequest] and [Client.ForPullRequestDiff], oldest first.
+func (c *Client) History(project string, issue int64) iter.Seq[*HistoryEntry] {
+	return func(yield func(*HistoryEntry) bool) {
+		start := ordered.Encode(historyKind, c.p.name, c.p.bot, project, issue)
+		end := ordered.Encode(historyKind, c.p.name, c.p.bot, project, issue, ordered.Inf)
+		for key, fn := range c.db.Scan(start, end) {
+			var h HistoryEntry
+			if err := json.Unmarshal(fn(), &h); err != nil {
+				// unreachable unless database corruption
+				c.db.Panic("overview.History: cannot unmarshal", "key", storage.Fmt(key), "err", err)
+			}
+			if !yield(&h) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteSuperseded deletes from db the history entries, of all
+// overview clients, that were generated before time t and superseded
+// by a newer entry for the same issue with the same type
+// (and, for [UpdateOverview], the same LastRead).
+// The newest entry of each kind is kept, however old.
+// It returns the number of entries deleted.
+func DeleteSuperseded(db storage.DB, t time.Time) int {
+	type group struct {
+		client, bot, project string
+		issue                int64
+	}
+	type kind struct {
+		typ      string
+		lastRead int64
+	}
+	n := 0
+	b := db.Batch()
+	var cur group
+	var keys [][]byte
+	var kinds []kind
+	var times []time.Time
+	// flush deletes the superseded entries of the current issue.
+	flush := func() {
+		newer := make(map[kind]bool)
+		for i := len(keys) - 1; i >= 0; i-- {
+			if newer[kinds[i]] && times[i].Before(t) {
+				b.Delete(keys[i])
+				b.MaybeApply()
+				n++
+			}
+			newer[kinds[i]] = true
+		}
+		keys, kinds, times = keys[:0], kinds[:0], times[:0]
+	}
+	for key, val := range db.Scan(ordered.Encode(historyKind), ordered.Encode(historyKind, ordered.Inf)) {
+		var g group
+		if _, err := ordered.DecodePrefix(key, nil, &g.client, &g.bot, &g.project, &g.issue); err != nil {
+			// unreachable unless database corruption
+			db.Panic("overview.DeleteSuperseded: decode", "key", storage.Fmt(key), "err", err)
+		}
+		var h HistoryEntry
+		if err := json.Unmarshal(val(), &h); err != nil {
+			// unreachable unless database corruption
+			db.Panic("overview.DeleteSuperseded: cannot unmarshal", "key", storage.Fmt(key), "err", err)
+		}
+		if g != cur {
+			flush()
+			cur = g
+		}
+		keys = append(keys, bytes.Clone(key))
+		kinds = append(kinds, kind{h.Type, h.LastRead})
+		times = append(times, h.Time)
+	}
+	flush()
+	b.Apply()
+	db.Flush()
+	return n
+}
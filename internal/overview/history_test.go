@@ -0,0 +1,83 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestHistory(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	ctx := context.Background()
+	check := testutil.Checker(t)
+
+	lc := llmapp.New(lg, llm.EchoContentGenerator(), db)
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, CreatedAt: jan1_2024})
+	c1 := &github.IssueComment{Body: "hello"}
+	gh.Testing().AddIssueComment(project, 1, c1)
+	iss, err := github.LookupIssue(db, project, 1)
+	check(err)
+
+	c := New(lg, db, gh, lc, "test", "testbot")
+
+	_, err = c.ForIssue(ctx, iss)
+	check(err)
+	// Cached result with the same text is not recorded again.
+	_, err = c.ForIssue(ctx, iss)
+	check(err)
+
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "goodbye"})
+	_, err = c.ForIssue(ctx, iss)
+	check(err)
+	_, err = c.ForIssueUpdate(ctx, iss, c1.CommentID())
+	check(err)
+
+	hist := slices.Collect(c.History(project, 1))
+	var types []string
+	for _, h := range hist {
+		types = append(types, h.Type)
+		if h.Project != project || h.Issue != 1 || h.Model != "echo" || h.PromptVersion == "" || h.Text == "" || h.Time.IsZero() {
+			t.Errorf("incomplete history entry: %+v", h)
+		}
+	}
+	if want := []string{IssueOverview, IssueOverview, UpdateOverview}; !slices.Equal(types, want) {
+		t.Fatalf("History types = %v, want %v", types, want)
+	}
+	if hist[0].TotalComments != 1 || hist[1].TotalComments != 2 {
+		t.Errorf("History TotalComments = %d, %d; want 1, 2", hist[0].TotalComments, hist[1].TotalComments)
+	}
+	if hist[2].LastRead != c1.CommentID() {
+		t.Errorf("History LastRead = %d, want %d", hist[2].LastRead, c1.CommentID())
+	}
+
+	// Other issues have no history.
+	for h := range c.History(project, 2) {
+		t.Errorf("unexpected history for issue 2: %+v", h)
+	}
+
+	// Only the superseded issue overview is deleted;
+	// the update overview is for a different LastRead.
+	if n := DeleteSuperseded(db, hist[0].Time); n != 0 {
+		t.Errorf("DeleteSuperseded(before first) = %d, want 0", n)
+	}
+	if n := DeleteSuperseded(db, time.Now()); n != 1 {
+		t.Errorf("DeleteSuperseded(now) = %d, want 1", n)
+	}
+	if after := slices.Collect(c.History(project, 1)); len(after) != 2 || after[0].Time != hist[1].Time || after[1].Type != UpdateOverview {
+		t.Errorf("History after DeleteSuperseded = %+v, want last two entries", after)
+	}
+}
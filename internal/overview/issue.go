@@ -7,6 +7,7 @@ package overview
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/llmapp"
@@ -40,6 +41,11 @@ type IssueResult struct {
 	LastComment     int64          // ID of the highest-numbered comment present for this issue
 	SkippedComments int            // number of comments not included in the summary
 	Overview        *llmapp.Result // the LLM-generated issue and comment summary
+
+	// TLDR is a separately generated two-sentence summary of the issue,
+	// which leads the overview comments posted by [Client.Run].
+	// It is nil for results of [Client.ForIssue].
+	TLDR *llmapp.Result
 }
 
 // See comment on [Client.ForIssue].
@@ -53,6 +59,9 @@ func (g *generator) issue(ctx context.Context, iss *github.Issue) (*IssueResult,
 		}
 		cds = append(cds, ic.ToLLMDoc())
 	}
+	if d := g.timelineDoc(iss); d != nil {
+		cds = append(cds, d)
+	}
 	overview, err := g.lc.PostOverview(ctx, post, cds)
 	if err != nil {
 		return nil, err
@@ -65,6 +74,101 @@ func (g *generator) issue(ctx context.Context, iss *github.Issue) (*IssueResult,
 	}, nil
 }
 
+// timelineDoc returns a document listing the notable events in the
+// issue's timeline, or nil if there are none.
+// The events tell the LLM how the issue was resolved (for example,
+// closed by a commit or marked as a duplicate) and which other
+// issues and pull requests mention it.
+func (g *generator) timelineDoc(iss *github.Issue) *llmapp.Doc {
+	var lines []string
+	for e := range g.gh.Timeline(iss) {
+		var what string
+		switch e.Event {
+		default:
+			continue
+		case "closed":
+			if e.IssueEvent.CommitID == "" {
+				continue
+			}
+			what = "closed by commit " + e.IssueEvent.CommitID
+		case "referenced":
+			what = "referenced by commit " + e.IssueEvent.CommitID
+		case "marked_as_duplicate":
+			what = "marked as a duplicate"
+		case "unmarked_as_duplicate":
+			what = "no longer marked as a duplicate"
+		case "cross-referenced":
+			src := e.CrossReference.Source.Issue
+			kind := "issue"
+			if src.PullRequest != nil {
+				kind = "pull request"
+			}
+			what = fmt.Sprintf("mentioned by %s %s (%q)", kind, src.HTMLURL, src.Title)
+		}
+		if e.Actor.Login != "" {
+			what += " (" + e.Actor.Login + ")"
+		}
+		lines = append(lines, e.CreatedAt+": "+what)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return &llmapp.Doc{
+		Type: "issue timeline",
+		URL:  iss.HTMLURL,
+		Text: strings.Join(lines, "\n"),
+	}
+}
+
+// PullRequestResult is the result of [Client.ForPullRequest].
+// It contains the generated overview and metadata about the pull request.
+type PullRequestResult struct {
+	TotalComments   int            // total number of comments for this pull request
+	LastComment     int64          // ID of the highest-numbered comment present for this pull request
+	SkippedComments int            // number of comments not included in the summary
+	ReviewThreads   int            // number of review threads included in the summary
+	ReviewComments  int            // number of review comments included in the summary
+	Overview        *llmapp.Result // the LLM-generated pull request, comment and review summary
+}
+
+// See comment on [Client.ForPullRequest].
+func (g *generator) pullRequest(ctx context.Context, pr *github.Issue) (*PullRequestResult, error) {
+	if pr.PullRequest == nil {
+		return nil, fmt.Errorf("%s#%d is not a pull request", pr.Project(), pr.Number)
+	}
+	post := pr.ToLLMDoc()
+	var cds []*llmapp.Doc
+	m := g.newIssueMeta()
+	for ic := range g.gh.Comments(pr) {
+		if m.add(ic) {
+			continue
+		}
+		cds = append(cds, ic.ToLLMDoc())
+	}
+	var threads [][]*llmapp.Doc
+	reviewComments := 0
+	for _, t := range g.gh.ReviewThreads(pr) {
+		var tds []*llmapp.Doc
+		for _, rc := range t.Comments {
+			tds = append(tds, rc.ToLLMDoc())
+		}
+		threads = append(threads, tds)
+		reviewComments += len(tds)
+	}
+	overview, err := g.lc.PullRequestOverview(ctx, post, cds, threads)
+	if err != nil {
+		return nil, err
+	}
+	return &PullRequestResult{
+		TotalComments:   m.TotalComments,
+		SkippedComments: m.SkippedComments,
+		LastComment:     m.LastComment,
+		ReviewThreads:   len(threads),
+		ReviewComments:  reviewComments,
+		Overview:        overview,
+	}, nil
+}
+
 // ignore reports whether the given issue comment should be ignored
 // when generating issue overviews.
 func (g *generator) ignore(ic *github.IssueComment) bool {
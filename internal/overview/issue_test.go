@@ -165,3 +165,93 @@ func TestIssueUpdate(t *testing.T) {
 		t.Errorf("UpdateOverview() mismatch (-want,+got):\n%s", diff)
 	}
 }
+
+func TestPullRequest(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	lc := llmapp.New(lg, llm.EchoContentGenerator(), db)
+	c := New(lg, db, gh, lc, "test-name", "test-bot")
+
+	project := "test/test"
+	tc := gh.Testing()
+	pr := &github.Issue{Number: 1, Title: "fix bug", Body: "fixes a bug", PullRequest: new(struct{})}
+	tc.AddIssue(project, pr)
+	ic := &github.IssueComment{User: github.User{Login: "a"}, Body: "LGTM"}
+	last := tc.AddIssueComment(project, 1, ic)
+	rc1 := &github.ReviewComment{User: github.User{Login: "b"}, Path: "x.go", Body: "typo"}
+	id := tc.AddReviewComment(project, 1, rc1)
+	rc2 := &github.ReviewComment{User: github.User{Login: "c"}, Path: "x.go", Body: "fixed", InReplyTo: id}
+	tc.AddReviewComment(project, 1, rc2)
+	rc3 := &github.ReviewComment{User: github.User{Login: "b"}, Path: "y.go", Body: "why?"}
+	tc.AddReviewComment(project, 1, rc3)
+
+	got, err := c.ForPullRequest(ctx, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// This merely checks that the correct call to [llmapp.PullRequestOverview] is made.
+	// The internals of [llmapp.Client.PullRequestOverview] are tested in the llmapp package.
+	wantOverview, err := lc.PullRequestOverview(ctx, pr.ToLLMDoc(),
+		[]*llmapp.Doc{ic.ToLLMDoc()},
+		[][]*llmapp.Doc{
+			{rc1.ToLLMDoc(), rc2.ToLLMDoc()},
+			{rc3.ToLLMDoc()},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PullRequestResult{
+		TotalComments:  1,
+		LastComment:    last,
+		ReviewThreads:  2,
+		ReviewComments: 3,
+		Overview:       wantOverview,
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(llmapp.Result{}, "Cached")); diff != "" {
+		t.Errorf("ForPullRequest() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := c.ForPullRequest(ctx, &github.Issue{Number: 2}); err == nil {
+		t.Error("ForPullRequest(issue) succeeded, want error")
+	}
+}
+
+func TestTimelineDoc(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	tc := gh.Testing()
+	const project = "golang/go"
+	iss := &github.Issue{Number: 1, Title: "bug"}
+	tc.AddIssue(project, iss)
+	g := newGenerator(gh, nil)
+
+	if d := g.timelineDoc(iss); d != nil {
+		t.Errorf("timelineDoc with no events = %+v, want nil", d)
+	}
+
+	tc.AddIssueEvent(project, 1, &github.IssueEvent{Event: "labeled", CreatedAt: "2025-01-01T00:00:00Z", Label: github.Label{Name: "bug"}})
+	tc.AddIssueEvent(project, 1, &github.IssueEvent{Event: "closed", CreatedAt: "2025-01-03T00:00:00Z", Actor: github.User{Login: "gopherbot"}, CommitID: "abc123"})
+	tc.AddCrossReference(project, 1, &github.CrossReference{
+		CreatedAt: "2025-01-02T00:00:00Z",
+		Actor:     github.User{Login: "gopher"},
+		Source: github.CrossReferenceSource{Issue: &github.Issue{
+			Number:      2,
+			Title:       "fix bug",
+			HTMLURL:     "https://github.com/golang/go/pull/2",
+			PullRequest: &struct{}{},
+		}},
+	})
+	want := &llmapp.Doc{
+		Type: "issue timeline",
+		URL:  iss.HTMLURL,
+		Text: `2025-01-02T00:00:00Z: mentioned by pull request https://github.com/golang/go/pull/2 ("fix bug") (gopher)
+2025-01-03T00:00:00Z: closed by commit abc123 (gopherbot)`,
+	}
+	if diff := cmp.Diff(want, g.timelineDoc(iss)); diff != "" {
+		t.Errorf("timelineDoc mismatch (-want +got):\n%s", diff)
+	}
+}
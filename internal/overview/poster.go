@@ -14,6 +14,8 @@ import (
 	"golang.org/x/oscar/internal/actions"
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/github/wrap"
+	"golang.org/x/oscar/internal/mute"
+	"golang.org/x/oscar/internal/repoconfig"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/storage/timed"
 	"rsc.io/ordered"
@@ -30,16 +32,30 @@ type poster struct {
 	maxIssueAge        time.Duration   // the maximum age (time since creation) of an issue to get an overview (default: [defaultMaxAge])
 	skipIssueAuthors   map[string]bool // skip issues authored by these GitHub users (default: none)
 	skipCommentAuthors map[string]bool // skip comments authored by these GitHub users when determining whether an issue meets the threshold to get an overview (default: none)
+	muted              *mute.Set       // if non-nil, never post to or update the issues in this set (default: nil)
+
+	minReviewComments int          // if > 0, the minimum number of review comments a pull request must have to get an overview (default: 0, no pull request overviews)
+	pullOverview      overviewFunc // the function to generate an overview of a pull request
+
+	digestEvery       time.Duration // if > 0, the minimum time between summaries of an issue for digests (default: 0, no digests)
+	digestMinComments int           // the minimum number of new comments an issue must have to get a digest
+	digestOverview    digestFunc    // the function to generate the overview of new comments for a digest
 
 	name     string
 	bot      string          // the login name of GitHub user that will post overviews, e.g. "gabyhelp"
 	projects map[string]bool // the GitHub projects this poster will post to (default: none)
 
-	w *wrap.Wrapper // used to wrap edits made to GitHub with tags. Allows the poster to identify its own edits
+	plainText map[string]bool // post plain-text overviews in these GitHub projects (default: none)
+
+	repo *repoconfig.Config // if non-nil, skip issues with the opt-out labels of their projects (default: nil)
+
+	w  *wrap.Wrapper // used to wrap edits made to GitHub with tags. Allows the poster to identify its own edits
+	dw *wrap.Wrapper // used to wrap digest comments (see [Client.EnableDigests])
 
 	// For the action log.
 	requireApproval bool // whether to require approval for actions (default: true)
 	logAction       actions.BeforeFunc
+	logDiff         actions.BeforeFunc // for diff summaries; see [Client.PostDiffSummary]
 
 	// if true, attempt to find actions by the bot that are missing from the action log (using tags)
 	findUnloggedActions bool
@@ -64,10 +80,12 @@ func newPoster(lg *slog.Logger, db storage.DB, gh *github.Client, name, bot stri
 		projects:        make(map[string]bool),
 		minComments:     defaultMinComments,
 		w:               wrap.New(bot, name),
+		dw:              newDigestWrapper(bot, name),
 		requireApproval: true,
 		maxIssueAge:     defaultMaxAge,
 	}
 	p.logAction = actions.Register(actionKind, &actioner{p})
+	p.logDiff = actions.Register(diffActionKind, &diffActioner{p})
 	return p
 }
 
@@ -123,6 +141,9 @@ func (p *poster) run(ctx context.Context, getOverview overviewFunc, now time.Tim
 		if !p.projects[project] {
 			return false
 		}
+		if api == "/pulls/comments" {
+			return p.minReviewComments > 0
+		}
 		if api != "/issues/comments" {
 			return false
 		}
@@ -132,6 +153,10 @@ func (p *poster) run(ctx context.Context, getOverview overviewFunc, now time.Tim
 		return true
 	}
 	for e := range p.watcher.RecentFiltered(filter) {
+		if e.API == "/pulls/comments" {
+			p.maybeProcessReviewComment(ctx, e, now)
+			continue
+		}
 		p.maybeProcessIssueComment(ctx, e, getOverview, now)
 	}
 	return nil
@@ -336,6 +361,12 @@ func (p *poster) skip(iss *github.Issue, m *issueMeta, now time.Time) (skip bool
 	if iss.State == "closed" {
 		return true, "issue closed"
 	}
+	if p.isMuted(iss) {
+		return true, "issue muted"
+	}
+	if label, ok := p.optedOut(iss); ok {
+		return true, "issue has opt-out label " + label
+	}
 	tm, err := time.Parse(time.RFC3339, iss.CreatedAt)
 	if err != nil {
 		return true, fmt.Sprintf("parse CreatedAt failed: %s", err)
@@ -354,13 +385,31 @@ func (p *poster) skip(iss *github.Issue, m *issueMeta, now time.Time) (skip bool
 
 // comment returns the text of overview comment to post to GitHub,
 // including hidden tags to help identify it later.
-func comment(s string, w *wrap.Wrapper) (string, error) {
+// If tldr is non-empty, the comment leads with it and places the
+// overview s in a collapsible details section, to keep the comment
+// short on busy issues.
+func comment(tldr, s string, w *wrap.Wrapper) (string, error) {
+	// Do not remove this wrapping call; it is used to identify the comment.
+	return w.Wrap(commentBody(tldr, s), nil)
+}
+
+// plainComment returns the text of the overview comment to post
+// to GitHub in plain-text mode (see [poster.EnablePlainText]).
+// Unlike [comment], it has no hidden tags or collapsible sections.
+func plainComment(tldr, s string) string {
+	return github.PlainText(commentBody(tldr, s))
+}
+
+// commentBody returns the unwrapped text of an overview comment.
+func commentBody(tldr, s string) string {
 	// These strings may be freely edited.
 	body := "\n" + s
+	if tldr != "" {
+		body = "\n**TL;DR:** " + strings.TrimSpace(tldr) + "\n\n" +
+			"<details><summary>Details</summary>\n\n" + s + "\n\n</details>\n"
+	}
 	footer := "<sub>(Generated by AI. Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](https://github.com/golang/go/discussions/67901).)</sub>\n"
-	c := strings.Join([]string{body, footer}, "\n")
-	// Do not remove this wrapping call; it is used to identify the comment.
-	return w.Wrap(c, nil)
+	return strings.Join([]string{body, footer}, "\n")
 }
 
 // isOverviewComment reports whether the given comment was authored
@@ -425,6 +474,19 @@ func (p *poster) EnableProject(project string) {
 	p.projects[project] = true
 }
 
+// EnablePlainText configures the poster to post overviews in the
+// given GitHub project as plain text (see [github.PlainText]),
+// without hidden tags, collapsible sections or heavy formatting,
+// so that they read well with a screen reader.
+// Plain-text overview comments cannot be identified without the action
+// log, so they are not found by [Client.FindUnloggedActions].
+func (p *poster) EnablePlainText(project string) {
+	if p.plainText == nil {
+		p.plainText = map[string]bool{}
+	}
+	p.plainText[project] = true
+}
+
 // RequireApproval configures the poster to require approval
 // for all logged actions.
 func (p *poster) RequireApproval() {
@@ -458,6 +520,21 @@ func (p *poster) SkipIssueAuthor(author string) {
 	p.skipIssueAuthors[author] = true
 }
 
+// isMuted reports whether the issue is in the poster's
+// mute set (see [Client.SkipMuted]).
+func (p *poster) isMuted(iss *github.Issue) bool {
+	return p.muted != nil && p.muted.Muted(iss.Project(), iss.Number)
+}
+
+// optedOut reports whether the issue has one of its project's
+// opt-out labels (see [Client.SetRepoConfig]), and returns the label.
+func (p *poster) optedOut(iss *github.Issue) (label string, ok bool) {
+	if p.repo == nil {
+		return "", false
+	}
+	return p.repo.Settings(iss.Project()).OptedOut(iss)
+}
+
 // SkipCommentsBy configures the poster to ignore comments
 // by the given author when determining whether an issue
 // has enough comments to get an overview.
@@ -473,11 +550,15 @@ const (
 	actionKind = "overview.PostOrUpdate"
 
 	// Additional context to distinguish a post vs. an update action.
-	actionContextPost   = "overview.Post"
-	actionContextUpdate = "overview.Update"
-
-	// DB key context for issue state entries.
-	issueStateKind = "overview.IssueState"
+	actionContextPost       = "overview.Post"
+	actionContextUpdate     = "overview.Update"
+	actionContextPullUpdate = "overview.PullUpdate"
+	actionContextDigest     = "overview.Digest"
+
+	// DB key context for issue, pull request and digest state entries.
+	issueStateKind  = "overview.IssueState"
+	pullStateKind   = "overview.PullState"
+	digestStateKind = "overview.DigestState"
 )
 
 // Default configurations.
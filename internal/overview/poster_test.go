@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/github/wrap"
 	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/mute"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/testutil"
 )
@@ -63,6 +65,7 @@ func TestRun(t *testing.T) {
 		minComments *int
 		maxAge      *time.Duration
 		autoApprove *bool
+		muted       bool // whether to skip issues muted by label or command
 		wantReport  *actions.RunReport
 		wantEdits   []*github.TestingEdit
 	}{
@@ -137,6 +140,35 @@ func TestRun(t *testing.T) {
 				}},
 			},
 		},
+		{
+			name: "muted",
+			setup: func(gh *github.Client) {
+				// Muted by label.
+				gh.Testing().AddIssue(project, &github.Issue{Number: 1, Body: "issue 1", CreatedAt: jan1_2024,
+					Labels: []github.Label{{Name: mute.DefaultLabel}}})
+				gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "issue 1 comment 1"})
+
+				// Muted by command.
+				gh.Testing().AddIssue(project, &github.Issue{Number: 2, Body: "issue 2", CreatedAt: jan1_2024})
+				gh.Testing().AddIssueComment(project, 2, &github.IssueComment{Body: mute.DefaultCommand, AuthorAssociation: "MEMBER"})
+
+				gh.Testing().AddIssue(project, &github.Issue{Number: 3, Body: "issue 3", CreatedAt: jan1_2024})
+				gh.Testing().AddIssueComment(project, 3, &github.IssueComment{Body: "issue 3 comment 1"})
+			},
+			autoApprove: ptr(true),
+			muted:       true,
+			wantReport: &actions.RunReport{
+				Completed: 1,
+			},
+			wantEdits: []*github.TestingEdit{
+				{Project: project, Issue: 3, IssueCommentChanges: &github.IssueCommentChanges{
+					Body: mustComment(t, "an overview of issue 3 with 1 comment(s)", w),
+				}},
+				{Project: project, Issue: 3, IssueChanges: &github.IssueChanges{
+					Body: "issue 3" + issueLink,
+				}},
+			},
+		},
 		// TODO(tatianabradley): Additional unit test cases:
 		//  - Other configuration (min comments, project, auto-approve)
 		//  - Ignored events
@@ -163,6 +195,12 @@ func TestRun(t *testing.T) {
 					p.RequireApproval()
 				}
 			}
+			if tc.muted {
+				m := mute.New(lg, db, gh)
+				m.EnableProject(project)
+				check(m.Run(ctx))
+				p.muted = m
+			}
 
 			check(p.run(ctx, overviewFuncForTest(gh), now))
 			gotReport := actions.RunWithReport(ctx, lg, db)
@@ -182,7 +220,7 @@ func ptr[T any](v T) *T {
 
 func mustComment(t *testing.T, s string, w *wrap.Wrapper) string {
 	t.Helper()
-	c, err := comment(s, w)
+	c, err := comment("", s, w)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -235,6 +273,46 @@ func TestRunUpdate(t *testing.T) {
 	}
 }
 
+func TestRunUpdateAfterDeleteBefore(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	check := testutil.Checker(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, Body: "issue 1", CreatedAt: jan1_2024})
+	gh.Testing().AddIssueComment(project, 1, &github.IssueComment{Body: "issue 1 comment 1"})
+
+	p := newPoster(lg, db, gh, "test", "testbot")
+	p.EnableProject(project)
+	p.SetMinComments(1)
+	p.AutoApprove()
+	p.logAction = actions.Register(actionKind, &testPoster{p: p})
+	check(p.run(ctx, overviewFuncForTest(gh), now))
+	check(actions.Run(ctx, lg, db))
+	iss, err := github.LookupIssue(db, project, 1)
+	check(err)
+	posted := slices.Collect(gh.Comments(iss))
+
+	// Deleting the completed post action from the log
+	// must not make the poster post a second overview.
+	if n := actions.DeleteBefore(db, time.Now().Add(time.Hour)); n == 0 {
+		t.Fatal("DeleteBefore deleted nothing")
+	}
+	check(p.run(ctx, overviewFuncForTest(gh), now))
+	check(actions.Run(ctx, lg, db))
+
+	if got := slices.Collect(gh.Comments(iss)); len(got) != len(posted) {
+		t.Errorf("after DeleteBefore, issue has %d comments, want %d (no new post)", len(got), len(posted))
+	}
+	edits := gh.Testing().Edits()
+	if len(edits) != 1 || edits[0].Comment != posted[len(posted)-1].CommentID() {
+		t.Errorf("after DeleteBefore, edits = %v, want one edit of comment %d", edits, posted[len(posted)-1].CommentID())
+	}
+}
+
 // testPoster is a test implementation of [actioner]
 // that, for post actions, modifies the GitHub testing database (instead
 // of diverting edits, which is what happens when we use
@@ -252,6 +330,12 @@ func (*testPoster) ForDisplay([]byte) string { return "" }
 
 func (tp *testPoster) runTestAction(ctx context.Context, a *action) (*result, error) {
 	// Use test implementation for post actions.
+	if a.Digest {
+		n := tp.p.gh.Testing().AddIssueComment(a.Issue.Project(), a.Issue.Number, &github.IssueComment{
+			Body: a.Changes.Body,
+		})
+		return &result{URL: fmt.Sprintf("%s#issuecomment-%d", a.Issue.HTMLURL, n)}, nil
+	}
 	if a.isPost() {
 		n := tp.p.gh.Testing().AddIssueComment(a.Issue.Project(), a.Issue.Number, &github.IssueComment{
 			Body: a.Changes.Body,
@@ -288,7 +372,7 @@ func TestIsOverviewComment(t *testing.T) {
 	db := storage.MemDB()
 	gh := github.New(lg, db, nil, nil)
 	p := newPoster(lg, db, gh, "test", "testbot")
-	c, err := comment("a comment", p.w)
+	c, err := comment("", "a comment", p.w)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -296,3 +380,22 @@ func TestIsOverviewComment(t *testing.T) {
 		t.Fatal("p.isOverviewComment = false, want true")
 	}
 }
+
+func TestCommentTLDR(t *testing.T) {
+	w := wrap.New("testbot", "test")
+	got, err := comment("First sentence. Second sentence.\n", "the details", w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"\n**TL;DR:** First sentence. Second sentence.\n\n<details><summary>Details</summary>\n\nthe details\n\n</details>\n",
+		"Generated by AI",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("comment() = %q, missing %q", got, want)
+		}
+	}
+	if got, want := mustComment(t, "the details", w), "\nthe details\n"; !strings.Contains(got, want) || strings.Contains(got, "TL;DR") {
+		t.Errorf("comment() without TL;DR = %q, want plain overview", got)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// EnablePullRequests configures [Client.Run] to also propose overviews
+// of open pull requests in enabled projects once they have at least
+// minReviewComments review comments. The overview summarizes the change
+// description together with the accumulated discussion and review threads
+// (see [Client.ForPullRequest]), and is updated each time another
+// minReviewComments review comments accumulate.
+// Like issue overviews, pull request overviews are posted and updated
+// through the action log, but no link to them is added to the
+// pull request description.
+//
+// Review comments are only present in the database if they are synced
+// (see [github.Client.EnableReviewComments]).
+// A minReviewComments of 0 or less disables pull request overviews,
+// which is the default.
+func (c *Client) EnablePullRequests(minReviewComments int) {
+	c.p.minReviewComments = minReviewComments
+}
+
+// forPullRequestPost returns the overview of the pull request to post
+// to GitHub, of the length set by [Client.SetPostLength].
+func (c *Client) forPullRequestPost(ctx context.Context, pr *github.Issue) (*IssueResult, error) {
+	r, err := c.forProject(pr.Project()).WithLength(c.postLength).ForPullRequest(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	return &IssueResult{
+		TotalComments:   r.TotalComments,
+		LastComment:     r.LastComment,
+		SkippedComments: r.SkippedComments,
+		Overview:        r.Overview,
+	}, nil
+}
+
+// pullState holds the state of a pull request for a [poster].
+type pullState struct {
+	// The number of review comments the pull request had
+	// when its overview was last posted or updated.
+	ReviewComments int `json:"review_comments"`
+}
+
+// pullStateKey returns the key to look up the state of the
+// given pull request.
+func (p *poster) pullStateKey(project string, pr int64) []byte {
+	return ordered.Encode(pullStateKind, p.bot, p.name, project, pr)
+}
+
+// getPullState returns the stored state of the given pull request.
+func (p *poster) getPullState(project string, pr int64) pullState {
+	var st pullState
+	if b, ok := p.db.Get(p.pullStateKey(project, pr)); ok {
+		if err := json.Unmarshal(b, &st); err != nil {
+			// Unreachable except bug in this package.
+			p.db.Panic("poster: could not unmarshal pullState", "err", err)
+		}
+	}
+	return st
+}
+
+// maybeProcessReviewComment determines whether the pull request of the given
+// event (which must be a review comment in an enabled project) should get a
+// new or updated overview, and if so, logs an action for it.
+// Each pull request is considered at most once per call to [poster.run].
+//
+// Like [poster.maybeProcessIssueComment], it must be run inside a
+// watcher Recent* loop, as it marks processed events as old.
+func (p *poster) maybeProcessReviewComment(ctx context.Context, e *github.Event, now time.Time) {
+	markOld := func() {
+		p.watcher.MarkOld(e.DBTime)
+		p.watcher.Flush()
+	}
+	key := string(p.pullStateKey(e.Project, e.Issue))
+	if p.runState[key] != nil {
+		markOld()
+		return
+	}
+	if err := p.logPullRequest(ctx, e.Project, e.Issue, now); err != nil {
+		p.slog.Error("run", "kind", actionKind, "bot", p.bot, "pull", e.Issue, "event", e, "error", err)
+		return
+	}
+	p.runState[key] = &issueState{}
+	markOld()
+}
+
+// logPullRequest logs an action to post or update the overview of
+// the pull request, if it needs one.
+func (p *poster) logPullRequest(ctx context.Context, project string, num int64, now time.Time) error {
+	pr, err := github.LookupIssue(p.db, project, num)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for rc := range p.gh.ReviewComments(pr) {
+		if !p.skipCommentAuthors[rc.User.Login] {
+			n++
+		}
+	}
+	st := p.getPullState(project, num)
+	if skip, reason := p.skipPull(pr, n, st, now); skip {
+		p.slog.Info("overview: skipping pull request", "project", project, "pull", num, "reason", reason)
+		return nil
+	}
+	act, err := p.getAction(ctx, pr, p.pullOverview)
+	if err != nil {
+		return err
+	}
+	p.slog.Info("overview: logging action for pull request", "action", act, "project", project, "pull", num, "review comments", n)
+	if act.isPost() {
+		p.logAction(p.db, logPostKey(project, num), act.encode(), p.requireApproval)
+	} else {
+		p.logAction(p.db, ordered.Encode(actionContextPullUpdate, project, num, n), act.encode(), p.requireApproval)
+	}
+	st.ReviewComments = n
+	p.db.Set(p.pullStateKey(project, num), storage.JSON(st))
+	p.db.Flush()
+	return nil
+}
+
+// skipPull reports whether the pull request, which has n review comments
+// (not counting skipped authors) and the given state, should be skipped,
+// and if so, the reason why.
+func (p *poster) skipPull(pr *github.Issue, n int, st pullState, now time.Time) (skip bool, reason string) {
+	if pr.PullRequest == nil {
+		return true, "not a pull request"
+	}
+	if pr.State == "closed" {
+		return true, "pull request closed"
+	}
+	if p.isMuted(pr) {
+		return true, "pull request muted"
+	}
+	if label, ok := p.optedOut(pr); ok {
+		return true, "pull request has opt-out label " + label
+	}
+	tm, err := time.Parse(time.RFC3339, pr.CreatedAt)
+	if err != nil {
+		return true, fmt.Sprintf("parse CreatedAt failed: %s", err)
+	}
+	if now.Sub(tm) > p.maxIssueAge {
+		return true, fmt.Sprintf("pull request too old CreatedAt=%s, maxAge=%s", tm, p.maxIssueAge)
+	}
+	if p.skipIssueAuthors[pr.User.Login] {
+		return true, fmt.Sprintf("pull request author %s skipped", pr.User.Login)
+	}
+	if n < p.minReviewComments {
+		return true, fmt.Sprintf("not enough review comments (%d < %d)", n, p.minReviewComments)
+	}
+	if st.ReviewComments > 0 && n < st.ReviewComments+p.minReviewComments {
+		return true, fmt.Sprintf("not enough new review comments since last overview (%d < %d+%d)", n, st.ReviewComments, p.minReviewComments)
+	}
+	return false, ""
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestRunPullRequests(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	project := "test/test"
+	check := testutil.Checker(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	pr := &github.Issue{Number: 3, Body: "pull 3", CreatedAt: jan1_2024, PullRequest: new(struct{})}
+	gh.Testing().AddIssue(project, pr)
+	closed := &github.Issue{Number: 4, Body: "pull 4", CreatedAt: jan1_2024, State: "closed", PullRequest: new(struct{})}
+	gh.Testing().AddIssue(project, closed)
+	review := func(num int64, login string) {
+		gh.Testing().AddReviewComment(project, num, &github.ReviewComment{Body: "nit", User: github.User{Login: login}})
+	}
+
+	p := newPoster(lg, db, gh, "test", "testbot")
+	p.EnableProject(project)
+	p.SkipCommentsBy("testbot")
+	p.AutoApprove()
+	p.minReviewComments = 2
+	p.pullOverview = func(ctx context.Context, i *github.Issue) (*IssueResult, error) {
+		n := len(slices.Collect(gh.ReviewComments(i)))
+		return &IssueResult{Overview: &llmapp.Result{
+			Response: fmt.Sprintf("an overview of pull %d with %d review comment(s)", i.Number, n),
+		}}, nil
+	}
+	p.logAction = actions.Register(actionKind, &pullTestPoster{p: p})
+
+	run := func() {
+		t.Helper()
+		check(p.run(ctx, overviewFuncForTest(gh), now))
+		check(actions.Run(ctx, lg, db))
+	}
+	edits := func() []*github.TestingEdit {
+		e := gh.Testing().Edits()
+		gh.Testing().ClearEdits()
+		return e
+	}
+
+	// Not enough review comments yet.
+	review(3, "rsc")
+	review(3, "testbot") // skipped
+	review(4, "rsc")
+	review(4, "rsc")
+	run()
+	if got := p.getPullState(project, 3); got.ReviewComments != 0 {
+		t.Fatalf("posted overview with too few review comments: %+v", got)
+	}
+
+	// The pull request gets an overview, without a link in its description.
+	// The closed one does not.
+	review(3, "gri")
+	run()
+	if got := p.getPullState(project, 3); got.ReviewComments != 2 {
+		t.Errorf("pull state = %+v, want 2 review comments", got)
+	}
+	if e := edits(); len(e) != 0 {
+		t.Errorf("post edited GitHub: %v", e)
+	}
+	c, err := p.findOverviewComment(pr)
+	check(err)
+	if want := mustComment(t, "an overview of pull 3 with 3 review comment(s)", p.w); c == nil || c.Body != want {
+		t.Fatalf("overview comment = %v, want %q", c, want)
+	}
+
+	// Updated only once another 2 review comments accumulate.
+	review(3, "rsc")
+	run()
+	if e := edits(); len(e) != 0 {
+		t.Errorf("updated with too few new review comments: %v", e)
+	}
+	review(3, "gri")
+	run()
+	want := []*github.TestingEdit{{Project: project, Issue: 3, Comment: c.CommentID(),
+		IssueCommentChanges: &github.IssueCommentChanges{
+			Body: mustComment(t, "an overview of pull 3 with 5 review comment(s)", p.w),
+		}}}
+	if diff := cmp.Diff(want, edits()); diff != "" {
+		t.Errorf("update edits mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// pullTestPoster is like [testPoster], but for pull requests:
+// it adds posted comments to the GitHub testing database
+// without changing the pull request.
+type pullTestPoster struct {
+	p *poster
+}
+
+func (tp *pullTestPoster) Run(ctx context.Context, data []byte) ([]byte, error) {
+	return runFromActionLog(ctx, data, func(ctx context.Context, a *action) (*result, error) {
+		if a.isPost() {
+			n := tp.p.gh.Testing().AddIssueComment(a.Issue.Project(), a.Issue.Number, &github.IssueComment{
+				Body: a.Changes.Body,
+			})
+			return &result{URL: fmt.Sprintf("%s#issuecomment-%d", a.Issue.HTMLURL, n)}, nil
+		}
+		return tp.p.runUpdateAction(ctx, a)
+	})
+}
+
+func (*pullTestPoster) ForDisplay([]byte) string { return "" }
@@ -0,0 +1,51 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/termfmt"
+)
+
+// TerminalText returns the overview r of the issue, generated at the
+// given time, formatted for display in a terminal with the given options:
+// a header with the issue's title, URL and state and a description of
+// the overview, followed by the TL;DR, if any, and the overview itself.
+// A zero generated time is left out.
+func TerminalText(iss *github.Issue, r *IssueResult, generated time.Time, opts termfmt.Options) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s#%d: %s\n\n", iss.Project(), iss.Number, iss.Title)
+	info := []string{iss.HTMLURL, iss.State, commentsDesc(r)}
+	if !generated.IsZero() {
+		info = append(info, "generated "+generated.UTC().Format(time.DateTime)+" UTC")
+	}
+	if r.Overview != nil && r.Overview.Model != "" {
+		info = append(info, "by "+r.Overview.Model)
+	}
+	fmt.Fprintf(&b, "%s\n\n", strings.Join(info, " · "))
+	if r.TLDR != nil {
+		fmt.Fprintf(&b, "**TL;DR:** %s\n\n", r.TLDR.Response)
+	}
+	if r.Overview != nil {
+		b.WriteString(r.Overview.Response)
+	}
+	return termfmt.Markdown(b.String(), opts)
+}
+
+// commentsDesc describes the comments summarized in r.
+func commentsDesc(r *IssueResult) string {
+	s := fmt.Sprintf("%d comments", r.TotalComments)
+	if r.TotalComments == 1 {
+		s = "1 comment"
+	}
+	if r.SkippedComments > 0 {
+		s += fmt.Sprintf(" (%d skipped)", r.SkippedComments)
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/termfmt"
+)
+
+func TestTerminalText(t *testing.T) {
+	iss := &github.Issue{
+		URL:     "https://api.github.com/repos/golang/go/issues/12",
+		HTMLURL: "https://github.com/golang/go/issues/12",
+		Number:  12,
+		Title:   "spec: allow type aliases",
+		State:   "open",
+	}
+	r := &IssueResult{
+		TotalComments:   3,
+		SkippedComments: 1,
+		TLDR:            &llmapp.Result{Response: "Aliases are coming."},
+		Overview: &llmapp.Result{
+			Model:    "model",
+			Response: "## Discussion\n\n* **rsc** proposed generic aliases.\n* gri agreed.",
+		},
+	}
+	generated := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	got := TerminalText(iss, r, generated, termfmt.Options{Width: 60})
+	want := `golang/go#12: spec: allow type aliases
+
+https://github.com/golang/go/issues/12 · open · 3 comments
+(1 skipped) · generated 2025-03-01 12:00:00 UTC · by model
+
+TL;DR: Aliases are coming.
+
+Discussion
+
+  • rsc proposed generic aliases.
+  • gri agreed.
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TerminalText mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// Cache returns a [Stage] that runs s and remembers its result,
+// so that a later run of the same [Pipeline] on the same input
+// reuses the result instead of running s again.
+// It is meant for expensive stages, such as [Related] and [Summarize]:
+// if a later stage fails, the item is retried on the next
+// [Pipeline.Run], and the cached stages before the failure
+// are not redone.
+//
+// The input is the name of s (see [Stage.Name]) and the [Item]
+// it receives, so a cached result is reused only if the issue and
+// the results of the earlier stages are unchanged.
+// A stage whose result depends on other state, or that must not
+// be repeated even if its input is unchanged, should not be cached.
+//
+// The Pipeline keeps the cached results of an issue only until
+// the issue makes it through the pipeline (or is dropped):
+// they exist to recover from failures, not to save work across
+// updates of the issue.
+func Cache(s Stage) Stage {
+	return &cachedStage{s}
+}
+
+type cachedStage struct {
+	Stage
+}
+
+// A cacheEntry is the cached result of a stage, stored in the
+// database under the key ("pipeline.Cache", name, project, issue, stage)
+// where name is the name of the Pipeline and stage is the index
+// of the stage in the Pipeline.
+type cacheEntry struct {
+	Hash []byte // SHA-256 of the stage name and input item
+	Keep bool   // result of the stage
+	Item *Item  // item after the stage
+}
+
+const cacheKind = "pipeline.Cache"
+
+// cacheKey returns the key of the cache entry for stage i on the issue.
+func (p *Pipeline) cacheKey(iss *github.Issue, i int) []byte {
+	return ordered.Encode(cacheKind, p.name, iss.Project(), iss.Number, i)
+}
+
+// runCached runs s, the i'th stage of the pipeline, on it,
+// using and updating the cache.
+// It reports whether the result came from the cache.
+func (p *Pipeline) runCached(ctx context.Context, i int, s *cachedStage, it *Item) (keep, hit bool, err error) {
+	h := sha256.New()
+	h.Write(storage.JSON(s.Name()))
+	h.Write(storage.JSON(it))
+	sum := h.Sum(nil)
+
+	key := p.cacheKey(it.Issue, i)
+	if data, ok := p.db.Get(key); ok {
+		var e cacheEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			// unreachable unless the database is corrupt
+			p.db.Panic("pipeline cache unmarshal", "key", storage.Fmt(key), "err", err)
+		}
+		if string(e.Hash) == string(sum) {
+			*it = *e.Item
+			return e.Keep, true, nil
+		}
+	}
+	keep, err = s.Run(ctx, it)
+	if err != nil {
+		return false, false, err
+	}
+	p.db.Set(key, storage.JSON(&cacheEntry{Hash: sum, Keep: keep, Item: it}))
+	return keep, false, nil
+}
+
+// clearCache deletes the cache entries for the issue.
+func (p *Pipeline) clearCache(iss *github.Issue) {
+	p.db.DeleteRange(
+		ordered.Encode(cacheKind, p.name, iss.Project(), iss.Number),
+		ordered.Encode(cacheKind, p.name, iss.Project(), iss.Number, ordered.Inf))
+}
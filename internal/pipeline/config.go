@@ -0,0 +1,187 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/plugins"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// A Config is the definition of a [Pipeline], typically read
+// from a YAML file with [ParseConfig]. For example:
+//
+//	name: needsinfo
+//	projects: [golang/go]
+//	approval: true
+//	steps:
+//	  - labels: {has: [WaitingForInfo], not: [NeedsFix]}
+//	  - match: 'NOT Body:"go version"'
+//	  - related: {limit: 3, threshold: 0.8}
+//	  - summarize: |
+//	      In one sentence, what information is missing from this issue?
+//	      {{.Issue.Title}}
+//	      {{.Issue.Body}}
+//	  - comment: |
+//	      {{.Summary}}
+//	      {{range .Related}}
+//	      - [{{.Title}}]({{.ID}})
+//	      {{- end}}
+//	  - label: [NeedsInvestigation]
+//
+// Each step sets exactly one field; see [Step].
+// The related and summarize steps are cached (see [Cache]).
+// The filter and postprocess steps name plugins in [Env.Plugins].
+type Config struct {
+	Name     string   // name of the Pipeline; see [New]
+	Projects []string // GitHub projects on which to run
+	Approval bool     // whether the actions require approval
+	Steps    []Step   // stages of the pipeline, in order
+}
+
+// A Step is the definition of one [Stage] of a [Config].
+// Exactly one of its fields must be set.
+type Step struct {
+	Match       string       // filter expression; see [Match]
+	Labels      *LabelsStep  // see [Labels]
+	Related     *RelatedStep // see [Related]
+	Summarize   string       // LLM prompt template; see [Summarize]
+	Comment     string       // comment template; see [Comment]
+	Label       []string     // labels to add; see [Label]
+	Filter      string       // name of a filter plugin; see [Filter]
+	PostProcess string       `yaml:"postprocess"` // name of a post-processor plugin; see [PostProcess]
+}
+
+// A LabelsStep is the definition of a [Labels] stage.
+type LabelsStep struct {
+	Has []string
+	Not []string
+}
+
+// A RelatedStep is the definition of a [Related] stage.
+type RelatedStep struct {
+	Limit     int      // maximum number of documents; default 5
+	Threshold float64  // minimum similarity score
+	AllowKind []string `yaml:"allowkind"` // kinds of documents to keep (see [search.Options])
+	DenyKind  []string `yaml:"denykind"`  // kinds of documents to remove
+}
+
+// ParseConfig parses a YAML pipeline definition.
+// Unknown fields are errors.
+func ParseConfig(data []byte) (*Config, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("pipeline: %w", err)
+	}
+	if c.Name == "" {
+		return nil, errors.New("pipeline: missing name")
+	}
+	if len(c.Steps) == 0 {
+		return nil, fmt.Errorf("pipeline %s: no steps", c.Name)
+	}
+	return &c, nil
+}
+
+// An Env provides the dependencies of the stages built by [Config.New].
+// A Config that does not use a stage need not provide its dependencies.
+type Env struct {
+	Logger   *slog.Logger
+	DB       storage.DB
+	GitHub   *github.Client
+	VectorDB storage.VectorDB     // for related steps
+	Docs     *docs.Corpus         // for related steps
+	LLM      llm.ContentGenerator // for summarize steps
+	Plugins  *plugins.Set         // for filter and postprocess steps
+}
+
+// New returns a new [Pipeline] with the stages defined by c,
+// enabled on c's projects. It does not call [Pipeline.EnablePosts].
+func (c *Config) New(env *Env) (*Pipeline, error) {
+	var stages []Stage
+	for i, st := range c.Steps {
+		s, err := st.stage(env)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: step %d: %w", c.Name, i+1, err)
+		}
+		stages = append(stages, s)
+	}
+	p := New(env.Logger, env.DB, env.GitHub, c.Name, stages...)
+	for _, proj := range c.Projects {
+		p.EnableProject(proj)
+	}
+	if c.Approval {
+		p.RequireApproval()
+	}
+	return p, nil
+}
+
+// stage returns the stage defined by st.
+func (st *Step) stage(env *Env) (Stage, error) {
+	n := 0
+	for _, set := range []bool{st.Match != "", st.Labels != nil, st.Related != nil, st.Summarize != "", st.Comment != "", st.Label != nil, st.Filter != "", st.PostProcess != ""} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return nil, fmt.Errorf("want exactly one of match, labels, related, summarize, comment, label, filter or postprocess; have %d", n)
+	}
+	switch {
+	case st.Match != "":
+		return Match(st.Match)
+	case st.Labels != nil:
+		return Labels(st.Labels.Has, st.Labels.Not), nil
+	case st.Related != nil:
+		if env.VectorDB == nil || env.Docs == nil {
+			return nil, errors.New("related: no vector database")
+		}
+		opts := search.Options{
+			Limit:     st.Related.Limit,
+			Threshold: st.Related.Threshold,
+			AllowKind: st.Related.AllowKind,
+			DenyKind:  st.Related.DenyKind,
+		}
+		if err := opts.Validate(); err != nil {
+			return nil, fmt.Errorf("related: %w", err)
+		}
+		return Cache(Related(env.VectorDB, env.Docs, opts)), nil
+	case st.Summarize != "":
+		if env.LLM == nil {
+			return nil, errors.New("summarize: no LLM")
+		}
+		s, err := Summarize(env.LLM, st.Summarize)
+		if err != nil {
+			return nil, err
+		}
+		return Cache(s), nil
+	case st.Comment != "":
+		return Comment(st.Comment)
+	case st.Filter != "":
+		p, ok := env.Plugins.Get(st.Filter)
+		if !ok {
+			return nil, fmt.Errorf("filter: no plugin %s", st.Filter)
+		}
+		return Filter(p)
+	case st.PostProcess != "":
+		p, ok := env.Plugins.Get(st.PostProcess)
+		if !ok {
+			return nil, fmt.Errorf("postprocess: no plugin %s", st.PostProcess)
+		}
+		return PostProcess(p)
+	default:
+		return Label(st.Label...), nil
+	}
+}
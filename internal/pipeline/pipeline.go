@@ -0,0 +1,437 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pipeline assembles GitHub bots from reusable stages.
+//
+// A [Pipeline] watches new and updated GitHub issues and passes each
+// one, as an [Item], through a sequence of [Stage]s: for example,
+// select issues by label ([Labels]), search for related documents
+// ([Related]), summarize the issue with the LLM ([Summarize]),
+// and compose a comment from the results ([Comment]).
+// A stage may drop the item, ending the pipeline for that issue.
+// If an item makes it through every stage with a comment to post
+// or labels to add ([Label]), the Pipeline logs an action to do so,
+// which is approved and run like the actions of any other bot.
+// The Pipeline acts on each issue at most once.
+//
+// Pipelines can be built in Go, with [New] and the stage constructors,
+// or from a YAML configuration file; see [ParseConfig].
+//
+// Expensive stages can be wrapped with [Cache], so that when a later
+// stage fails for an issue, retrying the issue does not redo them.
+// [Pipeline.RunWithReport] reports how often the cached results were used.
+//
+// Database entries are as follows:
+//
+//   - The Pipeline's GitHub event watcher, named "pipeline.Pipeline:$name".
+//   - Action log entries of kind "pipeline.Pipeline:$name", keyed by ($project, $issue).
+//   - ("pipeline.Cache", $name, $project, $issue, $stage) -> [cacheEntry]
+//     where $stage is the index of a cached stage in the Pipeline.
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/storage/timed"
+	"rsc.io/ordered"
+)
+
+// An Item is the state of one issue as it passes through a [Pipeline].
+// Each stage reads the fields set by earlier stages and sets its own.
+type Item struct {
+	Issue   *github.Issue   // the issue
+	Related []search.Result // related documents; see [Related]
+	Summary string          // LLM output; see [Summarize]
+	Comment string          // comment to post; see [Comment]
+	Labels  []string        // labels to add; see [Label]
+}
+
+// A Stage is one step of a [Pipeline].
+type Stage interface {
+	// Name returns a short description of the stage, for logging.
+	Name() string
+	// Run runs the stage on the item, updating it.
+	// It reports whether the item continues through the pipeline:
+	// a stage returns false to drop items that do not concern it.
+	// An error stops the pipeline for the item.
+	Run(ctx context.Context, it *Item) (keep bool, err error)
+}
+
+// Func returns a [Stage] with the given name that runs f.
+func Func(name string, f func(ctx context.Context, it *Item) (keep bool, err error)) Stage {
+	return &funcStage{name, f}
+}
+
+type funcStage struct {
+	name string
+	f    func(context.Context, *Item) (bool, error)
+}
+
+func (s *funcStage) Name() string { return s.name }
+
+func (s *funcStage) Run(ctx context.Context, it *Item) (bool, error) {
+	return s.f(ctx, it)
+}
+
+// A Pipeline passes GitHub issues through a sequence of stages
+// and acts on the results.
+type Pipeline struct {
+	slog      *slog.Logger
+	db        storage.DB
+	github    *github.Client
+	name      string
+	stages    []Stage
+	cached    bool // some stage is cached
+	projects  map[string]bool
+	watcher   *timed.Watcher[*github.Event]
+	timeLimit time.Time
+	post      bool
+
+	// For the action log.
+	requireApproval bool
+	actionKind      string
+	logAction       actions.BeforeFunc
+}
+
+// defaultTooOld is how old an issue can be, at the time of [New],
+// for the Pipeline to act on it.
+const defaultTooOld = 48 * time.Hour
+
+// New returns a new Pipeline that passes issues through the stages
+// in order. It logs to lg, stores state in db and reads and edits
+// GitHub issues using gh.
+//
+// For the purposes of storing its own state, it uses the given name.
+// Future calls to New with the same name will use the same state.
+//
+// Use the Pipeline methods to configure it (especially
+// [Pipeline.EnableProject] and [Pipeline.EnablePosts])
+// before calling [Pipeline.Run].
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, name string, stages ...Stage) *Pipeline {
+	p := &Pipeline{
+		slog:      lg,
+		db:        db,
+		github:    gh,
+		name:      name,
+		stages:    stages,
+		projects:  make(map[string]bool),
+		watcher:   gh.EventWatcher("pipeline.Pipeline:" + name),
+		timeLimit: time.Now().Add(-defaultTooOld),
+	}
+	for _, s := range stages {
+		if _, ok := s.(*cachedStage); ok {
+			p.cached = true
+		}
+	}
+	p.actionKind = "pipeline.Pipeline:" + name
+	p.logAction = actions.Register(p.actionKind, &actioner{p})
+	return p
+}
+
+// Name returns the name of the Pipeline.
+func (p *Pipeline) Name() string {
+	return p.name
+}
+
+// Latest returns the latest known DBTime marked old by the Pipeline's Watcher.
+func (p *Pipeline) Latest() timed.DBTime {
+	return p.watcher.Latest()
+}
+
+// EnableProject enables the Pipeline to act on issues in the given
+// GitHub project (for example "golang/go").
+func (p *Pipeline) EnableProject(project string) {
+	p.projects[project] = true
+}
+
+// EnablePosts enables the Pipeline to log actions.
+// If EnablePosts has not been called, [Pipeline.Run] logs
+// what it would do but does not act.
+func (p *Pipeline) EnablePosts() {
+	p.post = true
+}
+
+// RequireApproval configures the Pipeline to log actions that require approval.
+func (p *Pipeline) RequireApproval() {
+	p.requireApproval = true
+}
+
+// SetTimeLimit controls how old an issue can be for the Pipeline to act on it.
+// Issues created before time t are skipped.
+// The default is to skip issues that are more than 48 hours old
+// at the time of the call to [New].
+func (p *Pipeline) SetTimeLimit(t time.Time) {
+	p.timeLimit = t
+}
+
+// Run runs the pipeline on the issues created or updated
+// since the last call to Run by a Pipeline with the same name.
+// It skips closed issues, pull requests, and issues it has already acted on.
+// An issue that a stage drops is reconsidered when it is next updated.
+// An issue for which a stage fails is retried on the next call to Run.
+func (p *Pipeline) Run(ctx context.Context) error {
+	p.RunWithReport(ctx)
+	return nil
+}
+
+// A RunReport contains information about a pipeline run.
+type RunReport struct {
+	Issues  int           // the number of issues passed through the stages
+	Acted   int           // the number of issues acted on (or that would be, without EnablePosts)
+	Dropped int           // the number of issues dropped by a stage or with nothing to do
+	Errors  []error       // the errors returned by stages that failed
+	Stages  []StageReport // per-stage information, in pipeline order
+}
+
+// A StageReport contains information about the runs of
+// one stage during a pipeline run.
+type StageReport struct {
+	Name   string // the name of the stage
+	Items  int    // the number of items that reached the stage
+	Hits   int    // the number of results found in the cache (see [Cache])
+	Misses int    // the number of results computed and cached
+}
+
+// HitRate returns the fraction of cache lookups that were hits,
+// or 0 if there were no lookups.
+func (r *StageReport) HitRate() float64 {
+	if r.Hits+r.Misses == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Hits+r.Misses)
+}
+
+// newReport returns an empty report for a run of p.
+func (p *Pipeline) newReport() *RunReport {
+	r := &RunReport{}
+	for _, s := range p.stages {
+		r.Stages = append(r.Stages, StageReport{Name: s.Name()})
+	}
+	return r
+}
+
+// RunWithReport is like [Pipeline.Run], except it returns a report
+// with information about the run.
+func (p *Pipeline) RunWithReport(ctx context.Context) *RunReport {
+	p.slog.Info("pipeline.Pipeline start", "name", p.name, "post", p.post, "latest", p.watcher.Latest())
+	report := p.newReport()
+	defer func() {
+		p.slog.Info("pipeline.Pipeline end", "name", p.name, "latest", p.watcher.Latest(),
+			"issues", report.Issues, "acted", report.Acted, "dropped", report.Dropped, "errors", len(report.Errors))
+		for _, sr := range report.Stages {
+			if sr.Hits+sr.Misses > 0 {
+				p.slog.Info("pipeline.Pipeline cache", "name", p.name, "stage", sr.Name, "hits", sr.Hits, "misses", sr.Misses)
+			}
+		}
+	}()
+
+	defer p.watcher.Flush()
+	for e := range p.watcher.Recent() {
+		advance, err := p.runEvent(ctx, e, report)
+		if err != nil {
+			p.slog.Error("pipeline.Pipeline", "name", p.name, "project", e.Project, "issue", e.Issue, "err", err)
+			report.Errors = append(report.Errors, err)
+			continue
+		}
+		if advance {
+			p.watcher.MarkOld(e.DBTime)
+			// Flush immediately to make sure we don't re-post if interrupted later in the loop.
+			p.watcher.Flush()
+		}
+	}
+	return report
+}
+
+// runEvent runs the pipeline for the event, if it concerns an issue,
+// recording the outcome in report.
+// It reports whether the event has been handled, so that the
+// watcher can advance past it.
+func (p *Pipeline) runEvent(ctx context.Context, e *github.Event, report *RunReport) (advance bool, _ error) {
+	if !p.projects[e.Project] || e.API != "/issues" {
+		return true, nil
+	}
+	iss := e.Typed.(*github.Issue)
+	if skip, reason := p.skip(iss); skip {
+		p.slog.Debug("pipeline.Pipeline skip", "name", p.name, "project", e.Project, "issue", e.Issue, "reason", reason)
+		return true, nil
+	}
+	if _, ok := actions.Get(p.db, p.actionKind, logKey(iss)); ok {
+		// Already acted on.
+		return true, nil
+	}
+	report.Issues++
+	it, err := p.runIssue(ctx, iss, report)
+	if err != nil {
+		return false, err
+	}
+	if it == nil {
+		report.Dropped++
+		return true, nil
+	}
+	report.Acted++
+	if !p.post {
+		return false, nil
+	}
+	p.logAction(p.db, logKey(iss), storage.JSON(&action{
+		Issue:   iss,
+		Comment: it.Comment,
+		Labels:  it.Labels,
+	}), p.requireApproval)
+	return true, nil
+}
+
+// skip reports whether the Pipeline should skip the issue and why.
+func (p *Pipeline) skip(iss *github.Issue) (_ bool, reason string) {
+	if iss.State == "closed" {
+		return true, "issue is closed"
+	}
+	if iss.PullRequest != nil {
+		return true, "pull request"
+	}
+	tm, err := time.Parse(time.RFC3339, iss.CreatedAt)
+	if err != nil {
+		return true, "could not parse createdat"
+	}
+	if tm.Before(p.timeLimit) {
+		return true, fmt.Sprintf("created=%s before time limit=%s", tm, p.timeLimit)
+	}
+	return false, ""
+}
+
+// RunIssue passes the issue through the stages, without logging an action,
+// and returns the resulting item. It returns nil if a stage dropped
+// the issue or if the item has neither a comment nor labels to add.
+// It does not rely on or modify the Pipeline's watcher,
+// but it does use the results of cached stages (see [Cache]).
+func (p *Pipeline) RunIssue(ctx context.Context, iss *github.Issue) (*Item, error) {
+	return p.runIssue(ctx, iss, p.newReport())
+}
+
+// runIssue implements [Pipeline.RunIssue], recording
+// the runs of the stages in report.
+func (p *Pipeline) runIssue(ctx context.Context, iss *github.Issue, report *RunReport) (_ *Item, err error) {
+	if p.cached {
+		defer func() {
+			// Once the issue is through the pipeline, its cached
+			// results are no longer needed. After a failure,
+			// they are kept for the retry.
+			if err == nil {
+				p.clearCache(iss)
+			}
+		}()
+	}
+	it := &Item{Issue: iss}
+	for i, s := range p.stages {
+		sr := &report.Stages[i]
+		sr.Items++
+		var keep bool
+		if cs, ok := s.(*cachedStage); ok {
+			var hit bool
+			keep, hit, err = p.runCached(ctx, i, cs, it)
+			if hit {
+				sr.Hits++
+			} else if err == nil {
+				sr.Misses++
+			}
+		} else {
+			keep, err = s.Run(ctx, it)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: %s#%d: %s: %w", p.name, iss.Project(), iss.Number, s.Name(), err)
+		}
+		if !keep {
+			p.slog.Debug("pipeline.Pipeline dropped", "name", p.name, "project", iss.Project(), "issue", iss.Number, "stage", s.Name())
+			return nil, nil
+		}
+	}
+	if strings.TrimSpace(it.Comment) == "" {
+		it.Comment = ""
+	}
+	if it.Comment == "" && len(it.Labels) == 0 {
+		return nil, nil
+	}
+	p.slog.Info("pipeline.Pipeline acting", "name", p.name, "project", iss.Project(), "issue", iss.Number,
+		"comment", it.Comment != "", "labels", it.Labels)
+	return it, nil
+}
+
+// logKey returns the key for the issue in the action log.
+func logKey(iss *github.Issue) []byte {
+	return ordered.Encode(iss.Project(), iss.Number)
+}
+
+// An action is an action to comment on and label an issue.
+type action struct {
+	Issue   *github.Issue
+	Comment string   `json:",omitempty"`
+	Labels  []string `json:",omitempty"`
+}
+
+// A result is the result of running an [action].
+type result struct {
+	URL string `json:",omitempty"` // URL of the posted comment
+}
+
+// actioner implements [actions.Actioner].
+type actioner struct {
+	p *Pipeline
+}
+
+// Implements [actions.Actioner.Run].
+func (ar *actioner) Run(ctx context.Context, data []byte) ([]byte, error) {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	var res result
+	if len(a.Labels) > 0 {
+		labels := make([]string, 0, len(a.Issue.Labels)+len(a.Labels))
+		for _, l := range a.Issue.Labels {
+			labels = append(labels, l.Name)
+		}
+		for _, l := range a.Labels {
+			if !slices.Contains(labels, l) {
+				labels = append(labels, l)
+			}
+		}
+		if err := ar.p.github.EditIssue(ctx, a.Issue, &github.IssueChanges{Labels: &labels}); err != nil {
+			return nil, fmt.Errorf("pipeline %s: labeling %s: %w", ar.p.name, a.Issue.HTMLURL, err)
+		}
+	}
+	if a.Comment != "" {
+		_, url, err := ar.p.github.PostIssueComment(ctx, a.Issue, &github.IssueCommentChanges{Body: a.Comment})
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: posting on %s: %w", ar.p.name, a.Issue.HTMLURL, err)
+		}
+		res.URL = url
+	}
+	return storage.JSON(&res), nil
+}
+
+// Implements [actions.Actioner.ForDisplay].
+func (ar *actioner) ForDisplay(data []byte) string {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "pipeline %s on: %s\n", ar.p.name, a.Issue.HTMLURL)
+	if len(a.Labels) > 0 {
+		fmt.Fprintf(&b, "add labels: %s\n", strings.Join(a.Labels, ", "))
+	}
+	if a.Comment != "" {
+		fmt.Fprintf(&b, "new comment:\n%s", a.Comment)
+	}
+	return b.String()
+}
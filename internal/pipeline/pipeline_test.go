@@ -0,0 +1,263 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/plugins"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+	"rsc.io/ordered"
+)
+
+var ctx = context.Background()
+
+const project = "golang/go"
+
+const config = `
+name: needsinfo
+projects: [golang/go]
+steps:
+  - labels: {has: [WaitingForInfo], not: [NeedsFix]}
+  - match: 'NOT Body:"go version"'
+  - related: {limit: 1}
+  - summarize: |
+      What is missing from this issue?
+      {{.Issue.Title}}
+  - comment: |
+      {{.Summary}}
+      {{range .Related}}
+      - [{{.Title}}]({{.ID}})
+      {{- end}}
+  - label: [NeedsInvestigation]
+`
+
+func TestPipeline(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+
+	add := func(n int64, title, body string, labels ...string) {
+		iss := &github.Issue{Number: n, Title: title, Body: body, CreatedAt: time.Now().Format(time.RFC3339)}
+		for _, l := range labels {
+			iss.Labels = append(iss.Labels, github.Label{Name: l})
+		}
+		gh.Testing().AddIssue(project, iss)
+	}
+	add(1, "crash in net/http", "it crashes", "WaitingForInfo")
+	add(2, "crash in net/http again", "go version go1.24", "WaitingForInfo") // has go version
+	add(3, "crash in os", "it crashes", "WaitingForInfo", "NeedsFix")        // wrong labels
+	add(4, "crash in net/http too", "no labels")                             // wrong labels
+
+	dc := docs.New(lg, db)
+	docs.Sync(dc, gh)
+	vdb := storage.MemVectorDB(db, lg, "")
+	embeddocs.Sync(ctx, lg, vdb, llm.QuoteEmbedder(), dc)
+
+	cgen := llm.TestContentGenerator("test", func(_ context.Context, _ *llm.Schema, parts []llm.Part) (string, error) {
+		prompt := string(parts[0].(llm.Text))
+		if !strings.Contains(prompt, "crash in net/http") {
+			t.Errorf("prompt %q lacks issue title", prompt)
+		}
+		return "Please add the output of go version.\n", nil
+	})
+
+	c, err := ParseConfig([]byte(config))
+	check(err)
+	p, err := c.New(&Env{Logger: lg, DB: db, GitHub: gh, VectorDB: vdb, Docs: dc, LLM: cgen})
+	check(err)
+	p.EnablePosts()
+
+	check(p.Run(ctx))
+	check(actions.Run(ctx, lg, db))
+
+	edits := gh.Testing().Edits()
+	if len(edits) != 2 {
+		t.Fatalf("edits:\n%v\nwant label and comment on issue 1", edits)
+	}
+	for _, e := range edits {
+		if e.Issue != 1 {
+			t.Errorf("edited issue %d, want 1", e.Issue)
+		}
+	}
+	if l := edits[0].IssueChanges.Labels; l == nil || strings.Join(*l, ",") != "WaitingForInfo,NeedsInvestigation" {
+		t.Errorf("labels = %v, want WaitingForInfo,NeedsInvestigation", l)
+	}
+	body := edits[1].IssueCommentChanges.Body
+	want := "Please add the output of go version.\n\n- [crash in "
+	if !strings.HasPrefix(body, want) || strings.Count(body, "- [") != 1 {
+		t.Errorf("comment:\n%s\nwant prefix:\n%s\nand one related document", body, want)
+	}
+
+	// The pipeline acts on each issue only once.
+	gh.Testing().ClearEdits()
+	add(1, "crash in net/http", "it still crashes", "WaitingForInfo")
+	check(p.Run(ctx))
+	check(actions.Run(ctx, lg, db))
+	if edits := gh.Testing().Edits(); len(edits) != 0 {
+		t.Errorf("edits on second run:\n%v", edits)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	env := &Env{Logger: testutil.Slogger(t), DB: storage.MemDB()}
+	env.GitHub = github.New(env.Logger, env.DB, nil, nil)
+	for _, tc := range []struct {
+		config string
+		err    string
+	}{
+		{"steps: [{label: [x]}]", "missing name"},
+		{"name: x", "no steps"},
+		{"name: x\nsteps: [{lable: [x]}]", "not found"},
+		{"name: x\nsteps: [{label: [x], comment: hi}]", "exactly one"},
+		{"name: x\nsteps: [{related: {}}]", "no vector database"},
+		{"name: x\nsteps: [{summarize: hi}]", "no LLM"},
+		{"name: x\nsteps: [{comment: '{{.Nope'}]", "comment"},
+		{"name: x\nsteps: [{match: 'title:'}]", "match"},
+		{"name: x\nsteps: [{filter: nope}]", "no plugin"},
+		{"name: x\nsteps: [{postprocess: nope}]", "no plugin"},
+	} {
+		c, err := ParseConfig([]byte(tc.config))
+		if err == nil {
+			_, err = c.New(env)
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("config %q: error %v, want %q", tc.config, err, tc.err)
+		}
+	}
+}
+
+// Plugins that are shell scripts, for TestPlugins.
+var testPlugins = map[string]string{
+	"keep.yaml": `
+name: keep
+kind: filter
+command: [/bin/sh, -c, 'read -r req; case "$req" in *keep*) echo "{\"Keep\":true}";; *) echo "{}";; esac']
+`,
+	"sign.yaml": `
+name: sign
+kind: postprocess
+command: [/bin/sh, -c, 'echo "{\"Comment\":\"signed\"}"']
+`,
+}
+
+func TestPlugins(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no /bin/sh")
+	}
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "keep me", CreatedAt: time.Now().Format(time.RFC3339)})
+	gh.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "drop me", CreatedAt: time.Now().Format(time.RFC3339)})
+
+	dir := t.TempDir()
+	for file, m := range testPlugins {
+		check(os.WriteFile(filepath.Join(dir, file), []byte(m), 0o666))
+	}
+	ps, err := plugins.Load(lg, dir, nil)
+	check(err)
+	env := &Env{Logger: lg, DB: db, GitHub: gh, Plugins: ps}
+
+	// Plugins must be used as their kind.
+	c, err := ParseConfig([]byte("name: x\nsteps: [{filter: sign}]"))
+	check(err)
+	if _, err := c.New(env); err == nil || !strings.Contains(err.Error(), "is a postprocess") {
+		t.Errorf("filter step with post-processor: error %v, want kind error", err)
+	}
+
+	c, err = ParseConfig([]byte("name: plugins\nprojects: [golang/go]\nsteps: [{filter: keep}, {comment: hello}, {postprocess: sign}]"))
+	check(err)
+	p, err := c.New(env)
+	check(err)
+	p.EnablePosts()
+	check(p.Run(ctx))
+	check(actions.Run(ctx, lg, db))
+
+	edits := gh.Testing().Edits()
+	if len(edits) != 1 || edits[0].Issue != 1 || edits[0].IssueCommentChanges.Body != "signed" {
+		t.Errorf("edits:\n%v\nwant signed comment on issue 1", edits)
+	}
+}
+
+func TestCache(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	gh.Testing().AddIssue(project, &github.Issue{Number: 1, Title: "crash", CreatedAt: time.Now().Format(time.RFC3339)})
+
+	summaries := 0
+	summarize := Cache(Func("summarize", func(_ context.Context, it *Item) (bool, error) {
+		summaries++
+		it.Summary = "summary of " + it.Issue.Title
+		return true, nil
+	}))
+	fail := true
+	comment := Func("comment", func(_ context.Context, it *Item) (bool, error) {
+		if fail {
+			return false, errors.New("comment failed")
+		}
+		it.Comment = it.Summary
+		return true, nil
+	})
+	p := New(lg, db, gh, "test", summarize, comment)
+	p.EnableProject(project)
+	p.EnablePosts()
+
+	// The late stage fails: the issue is retried on the next run,
+	// without redoing the cached stage.
+	r := p.RunWithReport(ctx)
+	if len(r.Errors) != 1 || r.Acted != 0 {
+		t.Fatalf("first run: %+v, want one error", r)
+	}
+	if s := r.Stages[0]; s.Hits != 0 || s.Misses != 1 {
+		t.Errorf("first run: cache %+v, want 1 miss", s)
+	}
+	fail = false
+	r = p.RunWithReport(ctx)
+	if len(r.Errors) != 0 || r.Acted != 1 {
+		t.Fatalf("second run: %+v, want one action", r)
+	}
+	if s := r.Stages[0]; s.Hits != 1 || s.Misses != 0 || s.HitRate() != 1 {
+		t.Errorf("second run: cache %+v, want 1 hit", s)
+	}
+	if summaries != 1 {
+		t.Errorf("summarized %d times, want 1", summaries)
+	}
+	a, ok := actions.Get(db, p.actionKind, ordered.Encode(project, int64(1)))
+	if !ok || !strings.Contains(string(a.Action), "summary of crash") {
+		t.Errorf("action = %v, %v, want comment with summary", a, ok)
+	}
+
+	// Once the issue is through the pipeline, its cache entries are deleted.
+	for key := range db.Scan(ordered.Encode(cacheKind), ordered.Encode(cacheKind, ordered.Inf)) {
+		t.Errorf("cache entry %s remains", storage.Fmt(key))
+	}
+
+	// A change to the input invalidates the cached result.
+	// (The retried event for the unedited issue still hits.)
+	fail = true
+	gh.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "hang", CreatedAt: time.Now().Format(time.RFC3339)})
+	p.Run(ctx)
+	gh.Testing().AddIssue(project, &github.Issue{Number: 2, Title: "hang forever", CreatedAt: time.Now().Format(time.RFC3339)})
+	r = p.RunWithReport(ctx)
+	if s := r.Stages[0]; s.Hits != 1 || s.Misses != 1 || summaries != 3 {
+		t.Errorf("after edit: cache %+v, %d summaries, want 1 hit, 1 miss, 3 summaries", s, summaries)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/filter"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/plugins"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// Match returns a [Stage] that keeps the issues matching the
+// filter expression (see package filter), evaluated on the [github.Issue],
+// such as
+//
+//	Title:"x/tools" AND NOT Body:"gopls"
+func Match(expr string) (Stage, error) {
+	e, err := filter.ParseFilter(expr)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline: match %q: %w", expr, err)
+	}
+	match, msgs := filter.Evaluator[github.Issue](e, nil)
+	if len(msgs) > 0 {
+		return nil, fmt.Errorf("pipeline: match %q: %s", expr, strings.Join(msgs, "; "))
+	}
+	return Func("match "+expr, func(_ context.Context, it *Item) (bool, error) {
+		return match(*it.Issue), nil
+	}), nil
+}
+
+// Labels returns a [Stage] that keeps the issues that have
+// all the labels in has and none of the labels in not.
+// Labels are compared case-insensitively.
+func Labels(has, not []string) Stage {
+	name := fmt.Sprintf("labels has=%v not=%v", has, not)
+	return Func(name, func(_ context.Context, it *Item) (bool, error) {
+		on := func(name string) bool {
+			return slices.ContainsFunc(it.Issue.Labels, func(l github.Label) bool {
+				return strings.EqualFold(l.Name, name)
+			})
+		}
+		for _, l := range has {
+			if !on(l) {
+				return false, nil
+			}
+		}
+		for _, l := range not {
+			if on(l) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// Related returns a [Stage] that sets [Item.Related] to the documents
+// in vdb most similar to the issue, subject to opts, omitting the issue itself.
+// If there are none, it drops the issue.
+// The issue must already be embedded in vdb; if it is not,
+// the stage fails.
+func Related(vdb storage.VectorDB, dc *docs.Corpus, opts search.Options) Stage {
+	if opts.Limit == 0 {
+		opts.Limit = 5
+	}
+	name := fmt.Sprintf("related limit=%d threshold=%g", opts.Limit, opts.Threshold)
+	return Func(name, func(_ context.Context, it *Item) (bool, error) {
+		u := it.Issue.HTMLURL
+		vec, ok := vdb.Get(u)
+		if !ok {
+			return false, fmt.Errorf("%s not embedded", u)
+		}
+		req := &search.VectorRequest{Options: opts, Vector: vec}
+		req.Limit++ // the issue itself
+		results := search.Vector(vdb, dc, req)
+		results = slices.DeleteFunc(results, func(r search.Result) bool { return r.ID == u })
+		if len(results) > opts.Limit {
+			results = results[:opts.Limit]
+		}
+		it.Related = results
+		return len(results) > 0, nil
+	})
+}
+
+// Summarize returns a [Stage] that sets [Item.Summary] to the response
+// of the LLM cgen to a prompt, which is the result of executing
+// the text/template prompt on the [Item].
+// The stage's name identifies the model and prompt, so that
+// cached results (see [Cache]) are not reused if either changes.
+// The template may use the fields set by earlier stages, for example:
+//
+//	Summarize this issue in one sentence.
+//	Title: {{.Issue.Title}}
+//	{{.Issue.Body}}
+func Summarize(cgen llm.ContentGenerator, prompt string) (Stage, error) {
+	t, err := template.New("prompt").Parse(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline: summarize: %w", err)
+	}
+	sum := sha256.Sum256([]byte(prompt))
+	name := fmt.Sprintf("summarize model=%s prompt=%x", cgen.Model(), sum[:8])
+	return Func(name, func(ctx context.Context, it *Item) (bool, error) {
+		var b strings.Builder
+		if err := t.Execute(&b, it); err != nil {
+			return false, err
+		}
+		out, err := cgen.GenerateContent(ctx, nil, []llm.Part{llm.Text(b.String())})
+		if err != nil {
+			return false, err
+		}
+		it.Summary = strings.TrimSpace(out)
+		return true, nil
+	}), nil
+}
+
+// Comment returns a [Stage] that sets [Item.Comment] to the result
+// of executing the text/template text on the [Item], for example:
+//
+//	{{.Summary}}
+//
+//	Related issues:
+//	{{range .Related}} - [{{.Title}}]({{.ID}})
+//	{{end}}
+//
+// A comment that is empty or only white space is not posted.
+func Comment(text string) (Stage, error) {
+	t, err := template.New("comment").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline: comment: %w", err)
+	}
+	return Func("comment", func(_ context.Context, it *Item) (bool, error) {
+		var b strings.Builder
+		if err := t.Execute(&b, it); err != nil {
+			return false, err
+		}
+		it.Comment = b.String()
+		return true, nil
+	}), nil
+}
+
+// Label returns a [Stage] that adds the labels to [Item.Labels],
+// omitting those already on the issue.
+func Label(labels ...string) Stage {
+	return Func("label "+strings.Join(labels, ","), func(_ context.Context, it *Item) (bool, error) {
+		for _, l := range labels {
+			on := slices.ContainsFunc(it.Issue.Labels, func(x github.Label) bool { return strings.EqualFold(x.Name, l) })
+			if !on && !slices.Contains(it.Labels, l) {
+				it.Labels = append(it.Labels, l)
+			}
+		}
+		return true, nil
+	})
+}
+
+// Filter returns a [Stage] that keeps the issues that the
+// filter plugin p keeps (see package plugins).
+func Filter(p *plugins.Plugin) (Stage, error) {
+	if p.Kind() != plugins.KindFilter {
+		return nil, fmt.Errorf("pipeline: filter: plugin %s is a %s", p.Name(), p.Kind())
+	}
+	return Func("filter "+p.Name(), func(ctx context.Context, it *Item) (bool, error) {
+		return p.Filter(ctx, it.Issue)
+	}), nil
+}
+
+// PostProcess returns a [Stage] that replaces [Item.Comment]
+// with the result of the post-processor plugin p (see package plugins).
+// It does not call p if there is no comment.
+func PostProcess(p *plugins.Plugin) (Stage, error) {
+	if p.Kind() != plugins.KindPostProcess {
+		return nil, fmt.Errorf("pipeline: postprocess: plugin %s is a %s", p.Name(), p.Kind())
+	}
+	return Func("postprocess "+p.Name(), func(ctx context.Context, it *Item) (bool, error) {
+		if strings.TrimSpace(it.Comment) == "" {
+			return true, nil
+		}
+		c, err := p.PostProcess(ctx, it.Issue, it.Comment)
+		if err != nil {
+			return false, err
+		}
+		it.Comment = c
+		return true, nil
+	}), nil
+}
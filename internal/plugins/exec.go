@@ -0,0 +1,94 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Limits on the output of exec plugins.
+const (
+	maxOutput = 1 << 20 // standard output (the response)
+	maxStderr = 4 << 10 // standard error kept for error messages
+)
+
+// execCall returns the call function of the exec plugin
+// with manifest m in dir.
+func execCall(dir string, m *Manifest, sandbox []string) (func(context.Context, []byte) ([]byte, error), error) {
+	for _, kv := range m.Env {
+		if !strings.Contains(kv, "=") {
+			return nil, fmt.Errorf("env %q: want KEY=VALUE", kv)
+		}
+	}
+	// The program runs in a temporary directory,
+	// so resolve paths relative to the manifest now.
+	args := slices.Clone(m.Command)
+	if strings.HasPrefix(args[0], "./") || strings.HasPrefix(args[0], "../") {
+		path, err := filepath.Abs(filepath.Join(dir, args[0]))
+		if err != nil {
+			return nil, err
+		}
+		args[0] = path
+	}
+	args = append(slices.Clone(sandbox), args...)
+	// A nil Env would inherit Oscar's environment, which holds secrets.
+	env := append([]string{}, m.Env...)
+
+	return func(ctx context.Context, req []byte) ([]byte, error) {
+		wd, err := os.MkdirTemp("", "oscar-plugin-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(wd)
+
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = wd
+		cmd.Env = env
+		cmd.Stdin = bytes.NewReader(req)
+		stdout := &limitWriter{max: maxOutput}
+		stderr := &limitWriter{max: maxStderr}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		cmd.WaitDelay = time.Second
+		killGroup(cmd)
+		err = cmd.Run()
+		if err != nil {
+			if s := bytes.TrimSpace(stderr.buf.Bytes()); len(s) > 0 {
+				err = fmt.Errorf("%w: %s", err, s)
+			}
+			return nil, err
+		}
+		if stdout.over {
+			return nil, fmt.Errorf("output exceeds %d bytes", maxOutput)
+		}
+		return stdout.buf.Bytes(), nil
+	}, nil
+}
+
+// A limitWriter keeps at most max bytes written to it,
+// discarding the rest.
+type limitWriter struct {
+	buf  bytes.Buffer
+	max  int
+	over bool // whether bytes were discarded
+}
+
+func (w *limitWriter) Write(b []byte) (int, error) {
+	n := len(b)
+	if room := w.max - w.buf.Len(); len(b) > room {
+		b = b[:max(room, 0)]
+		w.over = true
+	}
+	w.buf.Write(b)
+	return n, nil
+}
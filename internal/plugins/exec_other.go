@@ -0,0 +1,13 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix
+
+package plugins
+
+import "os/exec"
+
+// killGroup does nothing: on this system, only the plugin process
+// itself is killed when its context is done.
+func killGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,23 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package plugins
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killGroup arranges for cmd to run in its own process group
+// and for the whole group to be killed when its context is done,
+// so that a plugin cannot outlive its time limit by starting
+// other processes.
+func killGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
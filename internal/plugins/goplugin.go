@@ -0,0 +1,48 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goplugin
+
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"plugin"
+)
+
+// goPluginCall returns the call function of the Go plugin in file.
+func goPluginCall(file string) (func(context.Context, []byte) ([]byte, error), error) {
+	pl, err := plugin.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := pl.Lookup("Handle")
+	if err != nil {
+		return nil, err
+	}
+	handle, ok := sym.(func(context.Context, []byte) ([]byte, error))
+	if !ok {
+		return nil, fmt.Errorf("%s: Handle has type %T, want func(context.Context, []byte) ([]byte, error)", file, sym)
+	}
+	return func(ctx context.Context, req []byte) ([]byte, error) {
+		// The plugin cannot be stopped, so wait for it
+		// only until the time limit.
+		type result struct {
+			out []byte
+			err error
+		}
+		c := make(chan result, 1)
+		go func() {
+			out, err := handle(ctx, req)
+			c <- result{out, err}
+		}()
+		select {
+		case r := <-c:
+			return r.out, r.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, nil
+}
@@ -0,0 +1,20 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !goplugin
+
+package plugins
+
+import (
+	"context"
+	"errors"
+)
+
+// errNoGoPlugins is the error returned when loading a Go plugin
+// in a binary built without the "goplugin" build tag.
+var errNoGoPlugins = errors.New("this binary does not support Go plugins (built without the goplugin tag)")
+
+func goPluginCall(string) (func(context.Context, []byte) ([]byte, error), error) {
+	return nil, errNoGoPlugins
+}
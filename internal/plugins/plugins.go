@@ -0,0 +1,254 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package plugins lets a deployment extend Oscar with custom
+// issue filters and comment post-processors, without changing
+// Oscar's source. Pipelines use them (see package pipeline).
+//
+// A plugin is described by a YAML manifest, NAME.yaml, in a plugin
+// directory (see [Load]). For example:
+//
+//	name: nospam
+//	kind: filter
+//	command: [./nospam, -strict]
+//	timeout: 2s
+//
+// # Protocol
+//
+// Oscar calls a plugin once per issue, with a JSON [Request],
+// and the plugin answers with a JSON [Response].
+// A filter ([KindFilter]) reports whether to keep the issue;
+// a post-processor ([KindPostProcess]) returns the comment
+// to post in place of the one in the request.
+//
+// # Exec plugins
+//
+// Most plugins are programs, named by the manifest's command,
+// which can be written in any language. Oscar runs the program for
+// each call, writing the request to its standard input and reading the
+// response from its standard output. The program runs:
+//
+//   - with a time limit (the manifest's timeout, 10 seconds by default),
+//     after which it is killed, along with any processes it started;
+//   - with a limit of 1 MiB on its output;
+//   - with only the environment variables listed in the manifest;
+//   - in a new, empty working directory, deleted after the call;
+//   - inside the sandbox command given to [Load], if any, such as
+//     a gVisor (runsc) or bubblewrap invocation that removes network
+//     and file system access.
+//
+// # Go plugins
+//
+// In binaries built with the "goplugin" build tag (which requires cgo),
+// a manifest can instead name a Go plugin, built with
+// "go build -buildmode=plugin", that exports a function
+//
+//	func Handle(ctx context.Context, request []byte) (response []byte, err error)
+//
+// taking and returning the same JSON as an exec plugin.
+// Go plugins avoid starting a process per call, but they run inside
+// Oscar, unsandboxed: only trusted code should be loaded this way.
+// A call that exceeds its time limit fails, but the plugin code
+// cannot be stopped and keeps running in the background.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"gopkg.in/yaml.v3"
+)
+
+// The kinds of plugins.
+const (
+	KindFilter      = "filter"      // decides whether to keep an issue
+	KindPostProcess = "postprocess" // rewrites a comment before it is posted
+)
+
+// A Request is the input to a plugin call.
+type Request struct {
+	Kind    string        // the kind of the plugin
+	Issue   *github.Issue // the issue
+	Comment string        `json:",omitempty"` // for post-processors, the comment to rewrite
+}
+
+// A Response is the output of a plugin call.
+type Response struct {
+	Keep    bool   `json:",omitempty"` // for filters, whether to keep the issue
+	Comment string `json:",omitempty"` // for post-processors, the comment to post; "" for none
+	Error   string `json:",omitempty"` // if non-empty, the call failed
+}
+
+// A Manifest describes a plugin. It is read from a YAML file by [Load].
+// Exactly one of Command and GoPlugin must be set.
+type Manifest struct {
+	Name     string        // name by which the plugin is used
+	Kind     string        // [KindFilter] or [KindPostProcess]
+	Command  []string      // program and arguments; "./" and "../" paths are relative to the manifest
+	GoPlugin string        `yaml:"goplugin"` // Go plugin file, relative to the manifest
+	Env      []string      // environment of the program, as KEY=VALUE
+	Timeout  time.Duration // time limit of each call; default 10s
+}
+
+// defaultTimeout is the time limit of a call
+// if the manifest does not set one.
+const defaultTimeout = 10 * time.Second
+
+// A Plugin is a loaded plugin.
+type Plugin struct {
+	slog    *slog.Logger
+	name    string
+	kind    string
+	timeout time.Duration
+	call    func(ctx context.Context, req []byte) ([]byte, error)
+}
+
+// Name returns the name of the plugin.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Kind returns the kind of the plugin: [KindFilter] or [KindPostProcess].
+func (p *Plugin) Kind() string {
+	return p.kind
+}
+
+// Filter calls the filter plugin p and reports whether to keep the issue.
+func (p *Plugin) Filter(ctx context.Context, iss *github.Issue) (keep bool, err error) {
+	resp, err := p.do(ctx, &Request{Kind: KindFilter, Issue: iss})
+	if err != nil {
+		return false, err
+	}
+	return resp.Keep, nil
+}
+
+// PostProcess calls the post-processor plugin p and returns
+// the comment to post on the issue in place of comment.
+func (p *Plugin) PostProcess(ctx context.Context, iss *github.Issue, comment string) (string, error) {
+	resp, err := p.do(ctx, &Request{Kind: KindPostProcess, Issue: iss, Comment: comment})
+	if err != nil {
+		return "", err
+	}
+	return resp.Comment, nil
+}
+
+// do makes a call to p with a time limit.
+func (p *Plugin) do(ctx context.Context, req *Request) (_ *Response, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("plugin %s: %w", p.name, err)
+		}
+	}()
+	if req.Kind != p.kind {
+		return nil, fmt.Errorf("called as %s, but is a %s", req.Kind, p.kind)
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	start := time.Now()
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	out, err := p.call(ctx, data)
+	p.slog.Debug("plugins call", "name", p.name, "duration", time.Since(start), "err", err)
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("time limit %v exceeded", p.timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// A Set is a collection of plugins, by name.
+type Set struct {
+	plugins map[string]*Plugin
+}
+
+// Get returns the plugin with the given name.
+func (s *Set) Get(name string) (*Plugin, bool) {
+	if s == nil {
+		return nil, false
+	}
+	p, ok := s.plugins[name]
+	return p, ok
+}
+
+// Load loads the plugins described by the NAME.yaml manifests in dir.
+// Exec plugins are run inside the sandbox command, if it is non-empty:
+// the plugin's command is appended to it.
+// Unknown manifest fields are errors.
+func Load(lg *slog.Logger, dir string, sandbox []string) (*Set, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("plugins: %w", err)
+	}
+	s := &Set{plugins: make(map[string]*Plugin)}
+	for _, file := range files {
+		p, err := load(lg, file, sandbox)
+		if err != nil {
+			return nil, fmt.Errorf("plugins: %s: %w", file, err)
+		}
+		if _, ok := s.plugins[p.name]; ok {
+			return nil, fmt.Errorf("plugins: %s: duplicate plugin name %s", file, p.name)
+		}
+		s.plugins[p.name] = p
+	}
+	return s, nil
+}
+
+// load loads the plugin described by the manifest file.
+func load(lg *slog.Logger, file string, sandbox []string) (*Plugin, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	if m.Name == "" {
+		return nil, errors.New("missing name")
+	}
+	if m.Kind != KindFilter && m.Kind != KindPostProcess {
+		return nil, fmt.Errorf("kind %q: want %s or %s", m.Kind, KindFilter, KindPostProcess)
+	}
+	if m.Timeout < 0 {
+		return nil, fmt.Errorf("negative timeout %v", m.Timeout)
+	}
+	p := &Plugin{slog: lg, name: m.Name, kind: m.Kind, timeout: m.Timeout}
+	if p.timeout == 0 {
+		p.timeout = defaultTimeout
+	}
+	dir := filepath.Dir(file)
+	switch {
+	case len(m.Command) > 0 && m.GoPlugin == "":
+		p.call, err = execCall(dir, &m, sandbox)
+	case len(m.Command) == 0 && m.GoPlugin != "":
+		p.call, err = goPluginCall(filepath.Join(dir, m.GoPlugin))
+	default:
+		err = errors.New("want exactly one of command and goplugin")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// The test binary acts as an exec plugin when
+// $OSCAR_PLUGIN_TEST is set to one of these modes.
+var testModes = map[string]func(req *Request) *Response{
+	"filter": func(req *Request) *Response {
+		return &Response{Keep: strings.Contains(req.Issue.Title, "keep")}
+	},
+	"upper": func(req *Request) *Response {
+		return &Response{Comment: strings.ToUpper(req.Comment)}
+	},
+	"env": func(req *Request) *Response {
+		wd, _ := os.Getwd()
+		return &Response{Comment: fmt.Sprintf("%d %s", len(os.Environ()), wd)}
+	},
+	"fail": func(req *Request) *Response {
+		return &Response{Error: "cannot decide"}
+	},
+	"sleep": func(req *Request) *Response {
+		time.Sleep(time.Minute)
+		return &Response{}
+	},
+	"flood": func(req *Request) *Response {
+		return &Response{Comment: strings.Repeat("x", 2*maxOutput)}
+	},
+}
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("OSCAR_PLUGIN_TEST"); mode != "" {
+		var req Request
+		if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		json.NewEncoder(os.Stdout).Encode(testModes[mode](&req))
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// writePlugins writes manifests for the test modes to a new
+// directory and returns the directory.
+func writePlugins(t *testing.T) string {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for mode := range testModes {
+		kind := KindPostProcess
+		if mode == "filter" || mode == "sleep" {
+			kind = KindFilter
+		}
+		m := fmt.Sprintf("name: %s\nkind: %s\ncommand: [%q]\nenv: [OSCAR_PLUGIN_TEST=%s]\ntimeout: 500ms\n", mode, kind, exe, mode)
+		if err := os.WriteFile(filepath.Join(dir, mode+".yaml"), []byte(m), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExec(t *testing.T) {
+	ctx := context.Background()
+	check := testutil.Checker(t)
+	s, err := Load(testutil.Slogger(t), writePlugins(t), nil)
+	check(err)
+	get := func(name string) *Plugin {
+		t.Helper()
+		p, ok := s.Get(name)
+		if !ok {
+			t.Fatalf("plugin %s not loaded", name)
+		}
+		return p
+	}
+
+	for title, want := range map[string]bool{"keep me": true, "drop me": false} {
+		keep, err := get("filter").Filter(ctx, &github.Issue{Title: title})
+		check(err)
+		if keep != want {
+			t.Errorf("Filter(%q) = %v, want %v", title, keep, want)
+		}
+	}
+	out, err := get("upper").PostProcess(ctx, &github.Issue{}, "hello")
+	check(err)
+	if out != "HELLO" {
+		t.Errorf("PostProcess(hello) = %q, want HELLO", out)
+	}
+
+	// The plugin sees only its own environment variable,
+	// and runs in a temporary directory.
+	out, err = get("env").PostProcess(ctx, &github.Issue{}, "")
+	check(err)
+	var n int
+	var wd string
+	fmt.Sscan(out, &n, &wd)
+	if n != 1 || !strings.Contains(wd, "oscar-plugin-") {
+		t.Errorf("plugin environment: %d variables, working directory %s; want 1, temporary", n, wd)
+	}
+	if _, err := os.Stat(wd); !os.IsNotExist(err) {
+		t.Errorf("working directory %s not deleted", wd)
+	}
+
+	// Failures.
+	if _, err := get("upper").Filter(ctx, &github.Issue{}); err == nil || !strings.Contains(err.Error(), "called as filter") {
+		t.Errorf("Filter with post-processor: %v, want kind error", err)
+	}
+	if _, err := get("fail").PostProcess(ctx, &github.Issue{}, "x"); err == nil || !strings.Contains(err.Error(), "cannot decide") {
+		t.Errorf("fail: %v, want plugin error", err)
+	}
+	start := time.Now()
+	if _, err := get("sleep").Filter(ctx, &github.Issue{}); err == nil || !strings.Contains(err.Error(), "time limit") {
+		t.Errorf("sleep: %v, want time limit error", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("sleep: took %v, want about 500ms", d)
+	}
+	if _, err := get("flood").PostProcess(ctx, &github.Issue{}, "x"); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("flood: %v, want output limit error", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	lg := testutil.Slogger(t)
+	for _, tc := range []struct {
+		manifest string
+		err      string
+	}{
+		{"kind: filter\ncommand: [x]", "missing name"},
+		{"name: x\nkind: sort\ncommand: [x]", "kind"},
+		{"name: x\nkind: filter", "exactly one"},
+		{"name: x\nkind: filter\ncommand: [x]\ngoplugin: x.so", "exactly one"},
+		{"name: x\nkind: filter\ncommand: [x]\nenv: [X]", "KEY=VALUE"},
+		{"name: x\nkind: filter\ncommand: [x]\ntimeout: -1s", "negative"},
+		{"name: x\nkind: filter\ncomand: [x]", "not found"},
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "x.yaml"), []byte(tc.manifest), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(lg, dir, nil); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("manifest %q: error %v, want %q", tc.manifest, err, tc.err)
+		}
+	}
+
+	// Plugins must have distinct names.
+	dir := t.TempDir()
+	for _, f := range []string{"a.yaml", "b.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("name: x\nkind: filter\ncommand: [x]"), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Load(lg, dir, nil); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("duplicate names: error %v, want duplicate", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Config is a set of per-project policies,
+// typically read from a YAML file with [ParseConfig].
+type Config struct {
+	Default  *Policy            // policy for projects not in Projects
+	Projects map[string]*Policy // policies by project
+}
+
+// Policy returns the policy for the project.
+func (c *Config) Policy(project string) *Policy {
+	if p := c.Projects[project]; p != nil {
+		return p
+	}
+	return c.Default
+}
+
+// ParseConfig parses a YAML file of per-project policies. For example:
+//
+//	default:
+//	  maxpostsperhour: 30
+//	projects:
+//	  golang/go:
+//	    actions: [comment, label]
+//	    quiethours: {start: 22, end: 6, location: America/New_York}
+//	    skipusers: [rsc]
+//	  golang/tools:
+//	    maxpostsperhour: 0 # no limit
+//	    skipauthors: []    # act on everyone's issues
+//
+// The fields are those of [Policy], in lower case. Each field omitted
+// from the default policy keeps its value in def, and each field omitted
+// from a project's policy keeps its value in the default policy.
+// So that lists can be cleared, an empty list is not the same as an
+// omitted one; an empty actions list allows no actions.
+// Unknown fields are errors.
+func ParseConfig(data []byte, def *Policy) (*Config, error) {
+	var y struct {
+		Default  *policyYAML
+		Projects map[string]*policyYAML
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&y); err != nil && err != io.EOF { // EOF: empty file
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+	if def == nil {
+		def = new(Policy)
+	}
+	c := &Config{Projects: make(map[string]*Policy)}
+	var err error
+	if c.Default, err = y.Default.apply(def); err != nil {
+		return nil, fmt.Errorf("policy: default: %w", err)
+	}
+	for project, py := range y.Projects {
+		if c.Projects[project], err = py.apply(c.Default); err != nil {
+			return nil, fmt.Errorf("policy: %s: %w", project, err)
+		}
+	}
+	return c, nil
+}
+
+// policyYAML is the YAML form of a [Policy].
+// Nil fields are omitted.
+type policyYAML struct {
+	Actions         []Action
+	QuietHours      *quietHoursYAML
+	MaxPostsPerHour *int
+	SkipAuthors     []string
+	SkipUsers       []string
+}
+
+type quietHoursYAML struct {
+	Start, End int
+	Location   string // time zone name, such as "America/New_York"; UTC if empty
+}
+
+// apply returns a copy of p with the fields set in y replaced.
+// y may be nil.
+func (y *policyYAML) apply(p *Policy) (*Policy, error) {
+	np := *p
+	if y == nil {
+		return &np, nil
+	}
+	if y.Actions != nil {
+		for _, a := range y.Actions {
+			if !slices.Contains([]Action{Comment, Edit, Label}, a) {
+				return nil, fmt.Errorf("unknown action %q", a)
+			}
+		}
+		np.Actions = y.Actions
+	}
+	if q := y.QuietHours; q != nil {
+		if q.Start < 0 || q.Start >= 24 || q.End < 0 || q.End >= 24 {
+			return nil, fmt.Errorf("quiet hours %d to %d: hours must be between 0 and 23", q.Start, q.End)
+		}
+		var loc *time.Location
+		if q.Location != "" {
+			var err error
+			if loc, err = time.LoadLocation(q.Location); err != nil {
+				return nil, fmt.Errorf("quiet hours: %w", err)
+			}
+		}
+		np.QuietHours = &QuietHours{Start: q.Start, End: q.End, Location: loc}
+	}
+	if y.MaxPostsPerHour != nil {
+		if *y.MaxPostsPerHour < 0 {
+			return nil, fmt.Errorf("negative maxpostsperhour %d", *y.MaxPostsPerHour)
+		}
+		np.MaxPostsPerHour = *y.MaxPostsPerHour
+	}
+	if y.SkipAuthors != nil {
+		np.SkipAuthors = y.SkipAuthors
+	}
+	if y.SkipUsers != nil {
+		np.SkipUsers = y.SkipUsers
+	}
+	return &np, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseConfig(t *testing.T) {
+	def := &Policy{MaxPostsPerHour: 60, SkipAuthors: []string{"gopherbot"}}
+	c, err := ParseConfig([]byte(`
+default:
+  maxpostsperhour: 30
+projects:
+  golang/go:
+    actions: [comment, label]
+    quiethours: {start: 22, end: 6}
+    skipusers: [rsc]
+  golang/tools:
+    maxpostsperhour: 0
+    skipauthors: []
+`), def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		project string
+		want    *Policy
+	}{
+		{"golang/go", &Policy{
+			Actions:         []Action{Comment, Label},
+			QuietHours:      &QuietHours{Start: 22, End: 6},
+			MaxPostsPerHour: 30,
+			SkipAuthors:     []string{"gopherbot"},
+			SkipUsers:       []string{"rsc"},
+		}},
+		{"golang/tools", &Policy{SkipAuthors: []string{}}},
+		{"golang/other", &Policy{MaxPostsPerHour: 30, SkipAuthors: []string{"gopherbot"}}},
+	} {
+		if diff := cmp.Diff(tc.want, c.Policy(tc.project)); diff != "" {
+			t.Errorf("Policy(%s): (-want, +got):\n%s", tc.project, diff)
+		}
+	}
+
+	c, err = ParseConfig(nil, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(def, c.Policy("golang/go")); diff != "" {
+		t.Errorf("empty config: (-want, +got):\n%s", diff)
+	}
+
+	for _, bad := range []string{
+		"projects: {golang/go: {actions: [close]}}",
+		"projects: {golang/go: {quiethours: {start: 24, end: 1}}}",
+		"projects: {golang/go: {quiethours: {start: 1, end: 2, location: Nowhere/Special}}}",
+		"default: {maxpostsperhour: -1}",
+		"default: {maxposts: 1}",
+	} {
+		if _, err := ParseConfig([]byte(bad), def); err == nil {
+			t.Errorf("ParseConfig(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package policy implements per-project posting policies,
+// shared by everything that posts to a project.
+//
+// A [Policy] says which kinds of [Action] may be taken in a project,
+// when they may not be taken ([QuietHours]), how many may be taken
+// in an hour, and whose issues and text must be left alone.
+// A [Set] holds the policies for all projects and enforces them;
+// the GitHub client consults it before every edit
+// (see [golang.org/x/oscar/internal/github.Client.SetPolicies]),
+// so that individual posters do not need to.
+// Operators typically write the policies in a YAML file,
+// read with [ParseConfig].
+//
+// Actions that are not allowed at all fail with [ErrNotAllowed]
+// or [ErrSkipped]. Actions that are only disallowed for now,
+// during quiet hours or after the hourly budget has been spent,
+// fail with an error wrapping [actions.ErrDeferred], so that the
+// action log leaves them pending and retries them later.
+//
+// The Set records the time of every action in the past hour
+// in the database under keys
+//
+//	("policy.Post", project, time.UnixNano)
+//
+// so that the hourly budget is shared by all posters and survives restarts.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// An Action is a kind of action that a policy can allow.
+type Action string
+
+const (
+	Comment Action = "comment" // post a new comment
+	Edit    Action = "edit"    // edit the title, body or state of an issue, or the body of a comment
+	Label   Action = "label"   // change an issue's labels
+)
+
+// A Policy is the posting policy for a project.
+// The zero Policy allows everything.
+type Policy struct {
+	// Actions lists the allowed actions.
+	// If Actions is nil, all actions are allowed.
+	Actions []Action
+
+	// QuietHours, if non-nil, is a daily period during which
+	// no actions are taken.
+	QuietHours *QuietHours
+
+	// MaxPostsPerHour, if positive, is the maximum number of actions
+	// of any kind taken in the project in any one-hour period.
+	MaxPostsPerHour int
+
+	// SkipAuthors lists users whose issues are left alone:
+	// no comments, edits or labels.
+	SkipAuthors []string
+
+	// SkipUsers lists users whose text (issue or comment body)
+	// is never edited.
+	SkipUsers []string
+}
+
+// QuietHours is a daily period, from hour Start up to but not including
+// hour End, in Location (UTC if nil).
+// If End is less than Start, the period spans midnight.
+type QuietHours struct {
+	Start, End int // hours in the range [0, 24)
+	Location   *time.Location
+}
+
+// contains reports whether t is in the quiet hours.
+func (q *QuietHours) contains(t time.Time) bool {
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	h := t.In(loc).Hour()
+	if q.Start <= q.End {
+		return q.Start <= h && h < q.End
+	}
+	return h >= q.Start || h < q.End
+}
+
+// A Request describes an action to be checked against a policy.
+type Request struct {
+	Project string // GitHub project, for example "golang/go"
+	Action  Action
+	Author  string // author of the issue being acted on
+	User    string // for [Edit], author of the text being edited
+}
+
+var (
+	// ErrNotAllowed is returned for actions not allowed by a project's policy.
+	ErrNotAllowed = errors.New("action not allowed by project policy")
+
+	// ErrSkipped is returned for actions on issues or text by users
+	// that a project's policy says to leave alone.
+	ErrSkipped = errors.New("user skipped by project policy")
+
+	// ErrQuietHours is returned for actions during a project's quiet hours.
+	ErrQuietHours = fmt.Errorf("project quiet hours: %w", actions.ErrDeferred)
+
+	// ErrBudget is returned for actions after a project's hourly
+	// posting budget has been spent.
+	ErrBudget = fmt.Errorf("project hourly posting budget spent: %w", actions.ErrDeferred)
+)
+
+const postKind = "policy.Post"
+
+// A Set is a set of per-project policies.
+// Projects without a policy are governed by the zero Policy.
+type Set struct {
+	db  storage.DB
+	now func() time.Time // for testing
+
+	mu       sync.Mutex
+	policies map[string]*Policy
+}
+
+// New returns a new, empty Set that records actions in db.
+func New(db storage.DB) *Set {
+	return &Set{db: db, now: time.Now, policies: make(map[string]*Policy)}
+}
+
+// SetPolicy sets the policy for the project.
+func (s *Set) SetPolicy(project string, p *Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies[project] = p
+}
+
+// Policy returns the policy for the project.
+func (s *Set) Policy(project string) *Policy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := s.policies[project]; p != nil {
+		return p
+	}
+	return new(Policy)
+}
+
+// Do checks r against the policy for r.Project and, if it is allowed,
+// calls f to carry it out.
+// Do counts the action against the project's hourly budget before
+// calling f, so that concurrent actions cannot overspend the budget
+// without holding a lock during f, and it uncounts the action if f fails.
+// Do returns an error wrapping one of [ErrNotAllowed], [ErrSkipped],
+// [ErrQuietHours] or [ErrBudget] if the action is not allowed,
+// and otherwise the result of f.
+func (s *Set) Do(r *Request, f func() error) error {
+	key, err := s.reserve(r)
+	if err != nil {
+		return err
+	}
+	if err := f(); err != nil {
+		// The action was not taken.
+		s.db.Delete(key)
+		return err
+	}
+	return nil
+}
+
+// reserve checks r and, if it is allowed, records it in the project's
+// hourly budget, returning the database key of the record.
+func (s *Set) reserve(r *Request) ([]byte, error) {
+	lock := postKind + "-" + r.Project
+	s.db.Lock(lock)
+	defer s.db.Unlock(lock)
+
+	now := s.now()
+	if err := s.check(r, now); err != nil {
+		return nil, fmt.Errorf("policy: %s %s: %w", r.Action, r.Project, err)
+	}
+	key := ordered.Encode(postKind, r.Project, now.UnixNano())
+	b := s.db.Batch()
+	b.Set(key, nil)
+	// Records older than an hour are no longer needed.
+	b.DeleteRange(ordered.Encode(postKind, r.Project), ordered.Encode(postKind, r.Project, now.Add(-time.Hour).UnixNano()-1))
+	b.Apply()
+	return key, nil
+}
+
+// Check reports whether r is allowed by the policy for r.Project now,
+// returning an error like [Set.Do] if not.
+// Posters can call Check to avoid logging actions that cannot run.
+func (s *Set) Check(r *Request) error {
+	if err := s.check(r, s.now()); err != nil {
+		return fmt.Errorf("policy: %s %s: %w", r.Action, r.Project, err)
+	}
+	return nil
+}
+
+func (s *Set) check(r *Request, now time.Time) error {
+	p := s.Policy(r.Project)
+	if p.Actions != nil && !slices.Contains(p.Actions, r.Action) {
+		return ErrNotAllowed
+	}
+	if r.Author != "" && slices.Contains(p.SkipAuthors, r.Author) {
+		return fmt.Errorf("%w: issue author %s", ErrSkipped, r.Author)
+	}
+	if r.Action == Edit && r.User != "" && slices.Contains(p.SkipUsers, r.User) {
+		return fmt.Errorf("%w: text by %s", ErrSkipped, r.User)
+	}
+	if p.QuietHours != nil && p.QuietHours.contains(now) {
+		return ErrQuietHours
+	}
+	if p.MaxPostsPerHour > 0 && s.Posts(r.Project, now.Add(-time.Hour)) >= p.MaxPostsPerHour {
+		return ErrBudget
+	}
+	return nil
+}
+
+// Posts returns the number of actions taken in the project since time t,
+// which must be within the past hour.
+func (s *Set) Posts(project string, since time.Time) int {
+	n := 0
+	for range s.db.Scan(ordered.Encode(postKind, project, since.UnixNano()), ordered.Encode(postKind, project, ordered.Inf)) {
+		n++
+	}
+	return n
+}
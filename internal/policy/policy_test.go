@@ -0,0 +1,154 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/storage"
+)
+
+func TestCheck(t *testing.T) {
+	s := New(storage.MemDB())
+	now := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.SetPolicy("golang/go", &Policy{
+		Actions:     []Action{Comment, Label},
+		SkipAuthors: []string{"gopherbot"},
+		SkipUsers:   []string{"rsc"},
+	})
+	s.SetPolicy("golang/quiet", &Policy{
+		QuietHours: &QuietHours{Start: 22, End: 13},
+	})
+
+	for _, tc := range []struct {
+		r    Request
+		want error
+	}{
+		{Request{Project: "golang/go", Action: Comment, Author: "gopher"}, nil},
+		{Request{Project: "golang/go", Action: Label}, nil},
+		{Request{Project: "golang/go", Action: Edit}, ErrNotAllowed},
+		{Request{Project: "golang/go", Action: Comment, Author: "gopherbot"}, ErrSkipped},
+		{Request{Project: "golang/go", Action: Comment, Author: "gopher", User: "rsc"}, nil},
+		{Request{Project: "golang/other", Action: Edit, User: "rsc"}, nil},
+		{Request{Project: "golang/quiet", Action: Comment}, ErrQuietHours},
+	} {
+		err := s.Check(&tc.r)
+		if !errors.Is(err, tc.want) || (err == nil) != (tc.want == nil) {
+			t.Errorf("Check(%+v) = %v, want %v", tc.r, err, tc.want)
+		}
+	}
+
+	s.SetPolicy("golang/tools", &Policy{SkipUsers: []string{"rsc"}})
+	if err := s.Check(&Request{Project: "golang/tools", Action: Edit, User: "rsc"}); !errors.Is(err, ErrSkipped) {
+		t.Errorf("edit of skipped user's text: got %v, want ErrSkipped", err)
+	}
+	if err := s.Check(&Request{Project: "golang/tools", Action: Comment, User: "rsc"}); err != nil {
+		t.Errorf("comment with skipped user: %v", err)
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, tc := range []struct {
+		q    QuietHours
+		hour int // UTC
+		want bool
+	}{
+		{QuietHours{Start: 1, End: 5}, 0, false},
+		{QuietHours{Start: 1, End: 5}, 1, true},
+		{QuietHours{Start: 1, End: 5}, 5, false},
+		{QuietHours{Start: 22, End: 6}, 23, true},
+		{QuietHours{Start: 22, End: 6}, 3, true},
+		{QuietHours{Start: 22, End: 6}, 12, false},
+		{QuietHours{Start: 22, End: 6, Location: ny}, 3, true}, // 22:00 or 23:00 in New York
+		{QuietHours{Start: 22, End: 6, Location: ny}, 12, false},
+	} {
+		tm := time.Date(2025, 1, 15, tc.hour, 0, 0, 0, time.UTC)
+		if got := tc.q.contains(tm); got != tc.want {
+			t.Errorf("%+v.contains(%v) = %t, want %t", tc.q, tm, got, tc.want)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	db := storage.MemDB()
+	s := New(db)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.SetPolicy("golang/go", &Policy{MaxPostsPerHour: 2})
+
+	r := &Request{Project: "golang/go", Action: Comment}
+	ran := 0
+	do := func() error {
+		return s.Do(r, func() error { ran++; return nil })
+	}
+
+	for range 2 {
+		if err := do(); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(10 * time.Minute)
+	}
+	err := do()
+	if !errors.Is(err, ErrBudget) || !errors.Is(err, actions.ErrDeferred) {
+		t.Fatalf("third post: got %v, want ErrBudget", err)
+	}
+	if ran != 2 {
+		t.Errorf("ran %d actions, want 2", ran)
+	}
+
+	// Failed actions do not count.
+	s.SetPolicy("golang/go", &Policy{MaxPostsPerHour: 3})
+	errFail := errors.New("fail")
+	if err := s.Do(r, func() error { return errFail }); err != errFail {
+		t.Fatalf("failing action: got %v, want %v", err, errFail)
+	}
+	if err := do(); err != nil {
+		t.Fatal(err)
+	}
+	if err := do(); !errors.Is(err, ErrBudget) {
+		t.Fatalf("fourth post: got %v, want ErrBudget", err)
+	}
+
+	// An hour after the first post, there is room again.
+	now = time.Date(2025, 3, 1, 13, 0, 0, 1, time.UTC)
+	if err := do(); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.Posts("golang/go", now.Add(-time.Hour)); n != 3 {
+		t.Errorf("Posts = %d, want 3", n)
+	}
+
+	// Other projects have their own budgets.
+	if err := s.Do(&Request{Project: "golang/tools", Action: Comment}, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDoUnlocked(t *testing.T) {
+	s := New(storage.MemDB())
+	s.SetPolicy("golang/go", &Policy{MaxPostsPerHour: 1})
+	r := &Request{Project: "golang/go", Action: Comment}
+
+	// The action is counted while it runs, without holding the
+	// project's lock: a concurrent check must not block,
+	// and must see the budget spent.
+	err := s.Do(r, func() error {
+		if err := s.Check(r); !errors.Is(err, ErrBudget) {
+			t.Errorf("Check during Do: got %v, want ErrBudget", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package postgres implements a [storage.DB] and a [storage.VectorDB]
+// using a Postgres database with the [pgvector] extension.
+// Unlike a Pebble database, a Postgres database can be shared
+// by many processes.
+//
+// The package uses [database/sql] with the pgx driver,
+// which it links in under the name [DefaultDriver].
+// [Open] accepts the name of any other registered driver.
+//
+// [Open] creates or upgrades the tables it needs, recording the
+// schema version in the table oscar_schema, so that a newer program
+// can migrate a database written by an older one.
+//
+// [pgvector]: https://github.com/pgvector/pgvector
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"iter"
+	"log/slog"
+	"sync"
+
+	"golang.org/x/oscar/internal/storage"
+)
+
+// DefaultDriver is the name of the database/sql driver used by
+// [golang.org/x/oscar/internal/dbspec] to open Postgres databases.
+const DefaultDriver = "pgx"
+
+// A DB is a [storage.DB] using Postgres.
+// Keys and values are stored in the table oscar_kv.
+type DB struct {
+	sql  *sql.DB
+	slog *slog.Logger
+
+	mu    sync.Mutex
+	locks map[string]*sql.Conn // connections holding advisory locks, by name
+}
+
+// Open opens the Postgres database described by dsn,
+// using the named database/sql driver,
+// and migrates its schema to the latest version.
+func Open(ctx context.Context, lg *slog.Logger, driver, dsn string) (*DB, error) {
+	sdb, err := sql.Open(driver, dsn)
+	if err != nil {
+		lg.Error("postgres open", "driver", driver, "err", err)
+		return nil, err
+	}
+	if err := sdb.PingContext(ctx); err != nil {
+		sdb.Close()
+		lg.Error("postgres ping", "err", err)
+		return nil, err
+	}
+	db := &DB{sql: sdb, slog: lg, locks: make(map[string]*sql.Conn)}
+	if err := db.migrate(ctx); err != nil {
+		sdb.Close()
+		lg.Error("postgres migrate", "err", err)
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrations are the changes to the schema, in order.
+// Version N of the schema is the result of applying the first N migrations.
+// Migrations must never be edited or removed once released;
+// add a new one instead.
+var migrations = [][]string{
+	// 1: key-value storage.
+	// Postgres compares bytea values bytewise, as storage.DB requires.
+	{
+		`CREATE TABLE oscar_kv (key bytea PRIMARY KEY, value bytea NOT NULL)`,
+	},
+	// 2: vector storage.
+	// The "C" collation orders IDs bytewise, as VectorDB.All requires.
+	{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		`CREATE TABLE oscar_vectors (
+			namespace text COLLATE "C" NOT NULL,
+			id text COLLATE "C" NOT NULL,
+			embedding vector NOT NULL,
+			PRIMARY KEY (namespace, id))`,
+	},
+}
+
+// migrateLock is the advisory lock held while migrating,
+// so that processes opening the same new database at once
+// do not both migrate it.
+var migrateLock = lockID("oscar_schema")
+
+// migrate applies the migrations that db lacks,
+// all in a single transaction.
+func (db *DB) migrate(ctx context.Context) (err error) {
+	tx, err := db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrateLock); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS oscar_schema (version integer NOT NULL)`); err != nil {
+		return err
+	}
+	var version int
+	if err := tx.QueryRowContext(ctx, `SELECT coalesce(max(version), 0) FROM oscar_schema`).Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("postgres: database schema version %d is newer than the latest known version %d", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		for _, stmt := range migrations[i] {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("postgres: migration %d: %w", i+1, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO oscar_schema (version) VALUES ($1)`, i+1); err != nil {
+			return err
+		}
+		db.slog.Info("postgres migrated schema", "version", i+1)
+	}
+	return tx.Commit()
+}
+
+// lockID returns the advisory lock key for the lock name.
+func lockID(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// Lock implements [storage.DB.Lock] using a Postgres session-level
+// advisory lock, which Postgres releases if the process dies.
+// Each held lock uses a connection of its own.
+func (db *DB) Lock(name string) {
+	conn, err := db.sql.Conn(context.TODO())
+	if err != nil {
+		// unreachable except db error
+		db.Panic("postgres lock conn", "name", name, "err", err)
+	}
+	if _, err := conn.ExecContext(context.TODO(), `SELECT pg_advisory_lock($1)`, lockID(name)); err != nil {
+		conn.Close()
+		// unreachable except db error
+		db.Panic("postgres lock", "name", name, "err", err)
+	}
+	db.mu.Lock()
+	db.locks[name] = conn
+	db.mu.Unlock()
+}
+
+// Unlock implements [storage.DB.Unlock].
+func (db *DB) Unlock(name string) {
+	db.mu.Lock()
+	conn, ok := db.locks[name]
+	delete(db.locks, name)
+	db.mu.Unlock()
+	if !ok {
+		db.Panic("postgres unlock of never-locked key", "name", name)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(context.TODO(), `SELECT pg_advisory_unlock($1)`, lockID(name)); err != nil {
+		// unreachable except db error
+		db.Panic("postgres unlock", "name", name, "err", err)
+	}
+}
+
+// Panic implements [storage.DB.Panic].
+func (db *DB) Panic(msg string, args ...any) {
+	db.slog.Error(msg, args...)
+	storage.Panic(msg, args...)
+}
+
+// Get implements [storage.DB.Get].
+func (db *DB) Get(key []byte) (val []byte, ok bool) {
+	err := db.sql.QueryRowContext(context.TODO(), `SELECT value FROM oscar_kv WHERE key = $1`, key).Scan(&val)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false
+	}
+	if err != nil {
+		// unreachable except db error
+		db.Panic("postgres get", "key", storage.Fmt(key), "err", err)
+	}
+	if val == nil {
+		val = []byte{}
+	}
+	return val, true
+}
+
+const (
+	setSQL         = `INSERT INTO oscar_kv (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value`
+	deleteSQL      = `DELETE FROM oscar_kv WHERE key = $1`
+	deleteRangeSQL = `DELETE FROM oscar_kv WHERE key >= $1 AND key <= $2`
+	scanSQL        = `SELECT key, value FROM oscar_kv WHERE key >= $1 AND key <= $2 ORDER BY key LIMIT $3`
+)
+
+// Set implements [storage.DB.Set].
+func (db *DB) Set(key, val []byte) {
+	if len(key) == 0 {
+		db.Panic("postgres set: empty key")
+	}
+	if _, err := db.sql.ExecContext(context.TODO(), setSQL, key, notNil(val)); err != nil {
+		// unreachable except db error
+		db.Panic("postgres set", "key", storage.Fmt(key), "val", storage.Fmt(val), "err", err)
+	}
+}
+
+// notNil returns b, or an empty slice if b is nil,
+// which drivers would otherwise send as NULL.
+func notNil(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Delete implements [storage.DB.Delete].
+func (db *DB) Delete(key []byte) {
+	if _, err := db.sql.ExecContext(context.TODO(), deleteSQL, key); err != nil {
+		// unreachable except db error
+		db.Panic("postgres delete", "key", storage.Fmt(key), "err", err)
+	}
+}
+
+// DeleteRange implements [storage.DB.DeleteRange].
+func (db *DB) DeleteRange(start, end []byte) {
+	if _, err := db.sql.ExecContext(context.TODO(), deleteRangeSQL, start, end); err != nil {
+		// unreachable except db error
+		db.Panic("postgres delete range", "start", storage.Fmt(start), "end", storage.Fmt(end), "err", err)
+	}
+}
+
+// scanPage is the number of rows Scan and VectorDB.All read per query.
+const scanPage = 1000
+
+// Scan implements [storage.DB.Scan].
+// It reads the keys a page at a time, and does not hold a connection
+// while the caller's loop body runs, so the body may use the database.
+func (db *DB) Scan(start, end []byte) iter.Seq2[[]byte, func() []byte] {
+	start = bytes.Clone(start)
+	end = bytes.Clone(end)
+	return func(yield func(key []byte, val func() []byte) bool) {
+		for {
+			keys, vals := db.scan(start, end)
+			for i, key := range keys {
+				if !yield(key, func() []byte { return vals[i] }) {
+					return
+				}
+			}
+			if len(keys) < scanPage {
+				return
+			}
+			// Continue with the smallest key after the last one.
+			start = append(bytes.Clone(keys[len(keys)-1]), 0)
+		}
+	}
+}
+
+// scan returns the first scanPage keys in [start, end] and their values.
+func (db *DB) scan(start, end []byte) (keys, vals [][]byte) {
+	rows, err := db.sql.QueryContext(context.TODO(), scanSQL, start, end, scanPage)
+	if err != nil {
+		// unreachable except db error
+		db.Panic("postgres scan", "start", storage.Fmt(start), "end", storage.Fmt(end), "err", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, val []byte
+		if err := rows.Scan(&key, &val); err != nil {
+			// unreachable except db error
+			db.Panic("postgres scan row", "err", err)
+		}
+		keys = append(keys, key)
+		vals = append(vals, notNil(val))
+	}
+	if err := rows.Err(); err != nil {
+		// unreachable except db error
+		db.Panic("postgres scan", "start", storage.Fmt(start), "end", storage.Fmt(end), "err", err)
+	}
+	return keys, vals
+}
+
+// Flush implements [storage.DB.Flush].
+// Postgres makes every committed change durable, so Flush does nothing.
+func (db *DB) Flush() {}
+
+// Close implements [storage.DB.Close].
+// It also releases any locks the DB holds.
+func (db *DB) Close() {
+	db.mu.Lock()
+	for _, conn := range db.locks {
+		conn.Close()
+	}
+	clear(db.locks)
+	db.mu.Unlock()
+	if err := db.sql.Close(); err != nil {
+		// unreachable except db error
+		db.Panic("postgres close", "err", err)
+	}
+}
+
+// Batch implements [storage.DB.Batch].
+func (db *DB) Batch() storage.Batch {
+	return &batch{db: db}
+}
+
+// A batch is a [storage.Batch] for a [DB].
+// It applies its operations in a single transaction.
+type batch struct {
+	db   *DB
+	ops  []op
+	size int // total bytes of keys and values in ops
+}
+
+// An op is a single SQL statement in a batch.
+type op struct {
+	query string
+	args  []any
+}
+
+// maxBatch is the number of bytes of keys and values
+// at which MaybeApply applies a batch.
+const maxBatch = 16 << 20
+
+func (b *batch) add(query string, size int, args ...any) {
+	b.ops = append(b.ops, op{query, args})
+	b.size += size
+}
+
+// Set implements [storage.Batch.Set].
+func (b *batch) Set(key, val []byte) {
+	if len(key) == 0 {
+		b.db.Panic("postgres batch set: empty key")
+	}
+	b.add(setSQL, len(key)+len(val), bytes.Clone(key), bytes.Clone(notNil(val)))
+}
+
+// Delete implements [storage.Batch.Delete].
+func (b *batch) Delete(key []byte) {
+	b.add(deleteSQL, len(key), bytes.Clone(key))
+}
+
+// DeleteRange implements [storage.Batch.DeleteRange].
+func (b *batch) DeleteRange(start, end []byte) {
+	b.add(deleteRangeSQL, len(start)+len(end), bytes.Clone(start), bytes.Clone(end))
+}
+
+// MaybeApply implements [storage.Batch.MaybeApply].
+func (b *batch) MaybeApply() bool {
+	if b.size > maxBatch {
+		b.Apply()
+		return true
+	}
+	return false
+}
+
+// Apply implements [storage.Batch.Apply].
+func (b *batch) Apply() {
+	if err := b.db.exec(b.ops); err != nil {
+		// unreachable except db error
+		b.db.Panic("postgres batch apply", "ops", len(b.ops), "err", err)
+	}
+	b.ops = nil
+	b.size = 0
+}
+
+// exec runs the ops in a single transaction.
+func (db *DB) exec(ops []op) (err error) {
+	if len(ops) == 0 {
+		return nil
+	}
+	ctx := context.TODO()
+	tx, err := db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	for _, o := range ops {
+		if _, err := tx.ExecContext(ctx, o.query, o.args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
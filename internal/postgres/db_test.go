@@ -0,0 +1,142 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// testDriver returns the driver named by $OSCAR_POSTGRES_DRIVER
+// (default [DefaultDriver]) and the database named by $OSCAR_POSTGRES_DSN.
+// It skips the test if either is not available.
+func testDriver(t *testing.T) (driver, dsn string) {
+	dsn = os.Getenv("OSCAR_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("$OSCAR_POSTGRES_DSN not set")
+	}
+	driver = os.Getenv("OSCAR_POSTGRES_DRIVER")
+	if driver == "" {
+		driver = DefaultDriver
+	}
+	if !slices.Contains(sql.Drivers(), driver) {
+		t.Skipf("database/sql driver %q not linked in", driver)
+	}
+	return driver, dsn
+}
+
+// openTestDB opens the test database, emptying it first.
+func openTestDB(t *testing.T) *DB {
+	driver, dsn := testDriver(t)
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	sdb, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`DROP TABLE IF EXISTS oscar_kv`,
+		`DROP TABLE IF EXISTS oscar_vectors`,
+		`DROP TABLE IF EXISTS oscar_schema`,
+	} {
+		if _, err := sdb.ExecContext(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sdb.Close()
+
+	db, err := Open(ctx, lg, driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
+
+func TestDB(t *testing.T) {
+	db := openTestDB(t)
+	storage.TestDB(t, db)
+	storage.TestDBLock(t, db)
+
+	// Reopening the database finds it migrated.
+	driver, dsn := testDriver(t)
+	db2, err := Open(context.Background(), testutil.Slogger(t), driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db2.Close()
+	var version int
+	if err := db2.sql.QueryRow(`SELECT max(version) FROM oscar_schema`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations) {
+		t.Errorf("schema version = %d, want %d", version, len(migrations))
+	}
+}
+
+func TestVectorDB(t *testing.T) {
+	db := openTestDB(t)
+	storage.TestVectorDB(t, func() storage.VectorDB { return NewVectorDB(db, "test") })
+	storage.TestVectorDBFilter(t, NewVectorDB(db, "filter"))
+
+	// Namespaces are separate.
+	NewVectorDB(db, "other").Set("x", llm.Vector{1, 0})
+	if _, ok := NewVectorDB(db, "test").Get("x"); ok {
+		t.Error("vector set in namespace other is visible in namespace test")
+	}
+}
+
+func TestFormatVector(t *testing.T) {
+	for _, vec := range []llm.Vector{
+		{},
+		{1},
+		{0.5, -2, 1e-7, math.MaxFloat32, float32(math.SmallestNonzeroFloat32)},
+	} {
+		text := formatVector(vec)
+		got, err := parseVector(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, vec) {
+			t.Errorf("parseVector(formatVector(%v)) = %v (text %q)", vec, got, text)
+		}
+	}
+	if got := formatVector(llm.Vector{1, 0.25, -3}); got != "[1,0.25,-3]" {
+		t.Errorf("formatVector = %q, want %q", got, "[1,0.25,-3]")
+	}
+	for _, bad := range []string{"", "[", "1,2]", "[1,x]", "[1,,2]"} {
+		if v, err := parseVector(bad); err == nil {
+			t.Errorf("parseVector(%q) = %v, want error", bad, v)
+		}
+	}
+	// pgvector may print spaces.
+	if v, err := parseVector("[1, 2]"); err != nil || !slices.Equal(v, llm.Vector{1, 2}) {
+		t.Errorf("parseVector(%q) = %v, %v", "[1, 2]", v, err)
+	}
+}
+
+func TestLockID(t *testing.T) {
+	ids := make(map[int64]string)
+	for i := range 1000 {
+		name := fmt.Sprintf("lock%d", i)
+		id := lockID(name)
+		if other, ok := ids[id]; ok {
+			t.Fatalf("lockID(%q) == lockID(%q)", name, other)
+		}
+		ids[id] = name
+		if lockID(name) != id {
+			t.Fatalf("lockID(%q) not deterministic", name)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+// Link in the pgx driver, which registers itself as [DefaultDriver].
+import _ "github.com/jackc/pgx/v5/stdlib"
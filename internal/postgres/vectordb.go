@@ -0,0 +1,268 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A VectorDB is a [storage.VectorDB] using Postgres and pgvector.
+// Vectors are stored in the table oscar_vectors, keyed by namespace and ID.
+//
+// Search is an exact nearest-neighbor search. Because vectors of
+// different lengths can share the table, it has no approximate index;
+// a deployment with many vectors of a single length can add one
+// (see the pgvector documentation).
+type VectorDB struct {
+	db        *DB
+	namespace string
+}
+
+// NewVectorDB returns a [VectorDB] storing vectors in db
+// under the given namespace.
+// Namespaces allow multiple vector DBs to be stored in the same database.
+func NewVectorDB(db *DB, namespace string) *VectorDB {
+	return &VectorDB{db: db, namespace: namespace}
+}
+
+const (
+	vecSetSQL    = `INSERT INTO oscar_vectors (namespace, id, embedding) VALUES ($1, $2, $3::vector) ON CONFLICT (namespace, id) DO UPDATE SET embedding = EXCLUDED.embedding`
+	vecDeleteSQL = `DELETE FROM oscar_vectors WHERE namespace = $1 AND id = $2`
+	vecGetSQL    = `SELECT embedding::text FROM oscar_vectors WHERE namespace = $1 AND id = $2`
+	vecAllSQL    = `SELECT id, embedding::text FROM oscar_vectors WHERE namespace = $1 AND id > $2 ORDER BY id LIMIT $3`
+	// <#> is pgvector's negative inner product, so the score
+	// is the dot product, as in [storage.MemVectorDB].
+	vecSearchSQL = `SELECT id, -(embedding <#> $2::vector) AS score FROM oscar_vectors
+		WHERE namespace = $1 AND vector_dims(embedding) = $3
+		ORDER BY score DESC, id DESC LIMIT $4`
+)
+
+// Set implements [storage.VectorDB.Set].
+func (db *VectorDB) Set(id string, vec llm.Vector) {
+	if id == "" {
+		db.db.Panic("postgres VectorDB Set: empty ID")
+	}
+	if _, err := db.db.sql.ExecContext(context.TODO(), vecSetSQL, db.namespace, id, formatVector(vec)); err != nil {
+		// unreachable except db error
+		db.db.Panic("postgres VectorDB Set", "namespace", db.namespace, "id", id, "err", err)
+	}
+}
+
+// Delete implements [storage.VectorDB.Delete].
+func (db *VectorDB) Delete(id string) {
+	if _, err := db.db.sql.ExecContext(context.TODO(), vecDeleteSQL, db.namespace, id); err != nil {
+		// unreachable except db error
+		db.db.Panic("postgres VectorDB Delete", "namespace", db.namespace, "id", id, "err", err)
+	}
+}
+
+// Get implements [storage.VectorDB.Get].
+func (db *VectorDB) Get(id string) (llm.Vector, bool) {
+	rows, err := db.db.sql.QueryContext(context.TODO(), vecGetSQL, db.namespace, id)
+	if err != nil {
+		// unreachable except db error
+		db.db.Panic("postgres VectorDB Get", "namespace", db.namespace, "id", id, "err", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			// unreachable except db error
+			db.db.Panic("postgres VectorDB Get", "namespace", db.namespace, "id", id, "err", err)
+		}
+		return nil, false
+	}
+	var text string
+	if err := rows.Scan(&text); err != nil {
+		// unreachable except db error
+		db.db.Panic("postgres VectorDB Get", "namespace", db.namespace, "id", id, "err", err)
+	}
+	return db.parse(id, text), true
+}
+
+// parse parses the stored vector with the given ID,
+// panicking if it is corrupt.
+func (db *VectorDB) parse(id, text string) llm.Vector {
+	vec, err := parseVector(text)
+	if err != nil {
+		db.db.Panic("postgres VectorDB corrupt vector", "namespace", db.namespace, "id", id, "err", err)
+	}
+	return vec
+}
+
+// All implements [storage.VectorDB.All].
+// Like [DB.Scan], it reads the vectors a page at a time.
+func (db *VectorDB) All() iter.Seq2[string, func() llm.Vector] {
+	return func(yield func(string, func() llm.Vector) bool) {
+		last := ""
+		for {
+			ids, texts := db.all(last)
+			for i, id := range ids {
+				if !yield(id, func() llm.Vector { return db.parse(id, texts[i]) }) {
+					return
+				}
+			}
+			if len(ids) < scanPage {
+				return
+			}
+			last = ids[len(ids)-1]
+		}
+	}
+}
+
+// all returns the first scanPage IDs after last and their vectors' text.
+func (db *VectorDB) all(last string) (ids, texts []string) {
+	rows, err := db.db.sql.QueryContext(context.TODO(), vecAllSQL, db.namespace, last, scanPage)
+	if err != nil {
+		// unreachable except db error
+		db.db.Panic("postgres VectorDB All", "namespace", db.namespace, "err", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, text string
+		if err := rows.Scan(&id, &text); err != nil {
+			// unreachable except db error
+			db.db.Panic("postgres VectorDB All row", "namespace", db.namespace, "err", err)
+		}
+		ids = append(ids, id)
+		texts = append(texts, text)
+	}
+	if err := rows.Err(); err != nil {
+		// unreachable except db error
+		db.db.Panic("postgres VectorDB All", "namespace", db.namespace, "err", err)
+	}
+	return ids, texts
+}
+
+// Search implements [storage.VectorDB.Search].
+// The filter keep is evaluated in Go, not by the database,
+// so a restrictive filter may take several queries
+// (see [storage.SearchFiltered]).
+func (db *VectorDB) Search(vec llm.Vector, n int, keep storage.VectorFilter) []storage.VectorResult {
+	if len(vec) == 0 || n <= 0 {
+		return nil
+	}
+	return storage.SearchFiltered(n, keep, func(n int) []storage.VectorResult {
+		return db.search(vec, n)
+	})
+}
+
+// search returns the n vectors most similar to vec.
+func (db *VectorDB) search(vec llm.Vector, n int) []storage.VectorResult {
+	rows, err := db.db.sql.QueryContext(context.TODO(), vecSearchSQL, db.namespace, formatVector(vec), len(vec), n)
+	if err != nil {
+		// unreachable except db error
+		db.db.Panic("postgres VectorDB Search", "namespace", db.namespace, "err", err)
+	}
+	defer rows.Close()
+	var res []storage.VectorResult
+	for rows.Next() {
+		var r storage.VectorResult
+		if err := rows.Scan(&r.ID, &r.Score); err != nil {
+			// unreachable except db error
+			db.db.Panic("postgres VectorDB Search row", "namespace", db.namespace, "err", err)
+		}
+		res = append(res, r)
+	}
+	if err := rows.Err(); err != nil {
+		// unreachable except db error
+		db.db.Panic("postgres VectorDB Search", "namespace", db.namespace, "err", err)
+	}
+	return res
+}
+
+// Flush implements [storage.VectorDB.Flush].
+// Like [DB.Flush], it does nothing.
+func (db *VectorDB) Flush() {}
+
+// Batch implements [storage.VectorDB.Batch].
+func (db *VectorDB) Batch() storage.VectorBatch {
+	return &vectorBatch{db: db}
+}
+
+// A vectorBatch is a [storage.VectorBatch] for a [VectorDB].
+// It applies its operations in a single transaction.
+type vectorBatch struct {
+	db   *VectorDB
+	ops  []op
+	size int // approximate bytes of SQL arguments in ops
+}
+
+// Set implements [storage.VectorBatch.Set].
+func (b *vectorBatch) Set(id string, vec llm.Vector) {
+	if id == "" {
+		b.db.db.Panic("postgres VectorDB batch Set: empty ID")
+	}
+	text := formatVector(vec)
+	b.ops = append(b.ops, op{vecSetSQL, []any{b.db.namespace, id, text}})
+	b.size += len(id) + len(text)
+}
+
+// Delete implements [storage.VectorBatch.Delete].
+func (b *vectorBatch) Delete(id string) {
+	b.ops = append(b.ops, op{vecDeleteSQL, []any{b.db.namespace, id}})
+	b.size += len(id)
+}
+
+// MaybeApply implements [storage.VectorBatch.MaybeApply].
+func (b *vectorBatch) MaybeApply() bool {
+	if b.size > maxBatch {
+		b.Apply()
+		return true
+	}
+	return false
+}
+
+// Apply implements [storage.VectorBatch.Apply].
+func (b *vectorBatch) Apply() {
+	if err := b.db.db.exec(b.ops); err != nil {
+		// unreachable except db error
+		b.db.db.Panic("postgres VectorDB batch apply", "namespace", b.db.namespace, "ops", len(b.ops), "err", err)
+	}
+	b.ops = nil
+	b.size = 0
+}
+
+// formatVector returns the pgvector text form of vec, such as "[1,0.5,-2]".
+func formatVector(vec llm.Vector) string {
+	buf := make([]byte, 0, 2+10*len(vec))
+	buf = append(buf, '[')
+	for i, f := range vec {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendFloat(buf, float64(f), 'g', -1, 32)
+	}
+	buf = append(buf, ']')
+	return string(buf)
+}
+
+// parseVector parses the pgvector text form of a vector.
+func parseVector(text string) (llm.Vector, error) {
+	s, ok1 := strings.CutPrefix(text, "[")
+	s, ok2 := strings.CutSuffix(s, "]")
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("invalid vector %q", text)
+	}
+	if s == "" {
+		return llm.Vector{}, nil
+	}
+	fields := strings.Split(s, ",")
+	vec := make(llm.Vector, len(fields))
+	for i, f := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(f), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector %q: %v", text, err)
+		}
+		vec[i] = float32(x)
+	}
+	return vec, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import "fmt"
+
+// EnableAcknowledgement configures the Poster to acknowledge new issues
+// (but not pull requests) in the given GitHub project.
+// Instead of a list of related documents alone, the Poster's first
+// comment on a new issue then thanks the author and says that triage
+// usually takes about triageDays days, followed by the related
+// documents found, if any. If there are none, the Poster still posts
+// the acknowledgement.
+// Merging the two keeps new issues down to a single bot comment.
+//
+// Acknowledgements are only posted by [Poster.Run], not by
+// [Poster.Backfill], which comments on issues that are no longer new.
+// A triageDays of 0 or less disables acknowledgements, which is the default.
+func (p *Poster) EnableAcknowledgement(project string, triageDays int) {
+	if p.ack == nil {
+		p.ack = make(map[string]int)
+	}
+	if triageDays <= 0 {
+		delete(p.ack, project)
+		return
+	}
+	p.ack[project] = triageDays
+}
+
+// acknowledges reports whether the Poster acknowledges
+// new issues in the project.
+func (p *Poster) acknowledges(project string) bool {
+	return p.ack[project] > 0
+}
+
+// acknowledgement returns the acknowledgement text for a new issue in
+// the project, introducing related documents if related is true.
+func (p *Poster) acknowledgement(project string, related bool) string {
+	days := "about 1 day"
+	if n := p.ack[project]; n != 1 {
+		days = fmt.Sprintf("about %d days", n)
+	}
+	s := "Thank you for filing this issue. Triage usually takes " + days + "."
+	if related {
+		s += " In the meantime, these issues and documents may be related and could help."
+	}
+	return s + "\n\n"
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"testing"
+
+	"golang.org/x/oscar/internal/actions"
+)
+
+func TestAcknowledgement(t *testing.T) {
+	const ack = "Thank you for filing this issue. Triage usually takes about 3 days." +
+		" In the meantime, these issues and documents may be related and could help.\n\n"
+
+	t.Run("run", func(t *testing.T) {
+		p, _, project, check := newTestPoster(t)
+		p.EnableAcknowledgement(project, 3)
+		check(p.Run(ctx))
+		check(actions.Run(ctx, p.slog, p.db))
+		checkActionLog(t, p.db, map[int64]string{13: ack + post13, 19: ack + post19})
+	})
+
+	t.Run("other-project", func(t *testing.T) {
+		p, _, project, check := newTestPoster(t)
+		p.EnableAcknowledgement("other/project", 3)
+		check(p.Run(ctx))
+		check(actions.Run(ctx, p.slog, p.db))
+		checkActionLog(t, p.db, map[int64]string{13: post13, 19: post19})
+
+		p.EnableAcknowledgement(project, 0)
+		if p.acknowledges(project) {
+			t.Errorf("EnableAcknowledgement(%q, 0) did not disable acknowledgements", project)
+		}
+	})
+
+	t.Run("backfill", func(t *testing.T) {
+		p, _, project, check := newTestPoster(t)
+		p.EnableAcknowledgement(project, 3)
+		_, err := p.Backfill(ctx, &Backfill{Project: project})
+		check(err)
+		check(actions.Run(ctx, p.slog, p.db))
+		checkActionLog(t, p.db, map[int64]string{13: post13, 19: post19})
+	})
+
+	t.Run("no-related", func(t *testing.T) {
+		p, _, project, _ := newTestPoster(t)
+		p.EnableAcknowledgement(project, 1)
+		want := "Thank you for filing this issue. Triage usually takes about 1 day.\n\n" +
+			"\n<sub>(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](" + defaultFeedbackURL + ").)</sub>\n"
+		if got := p.comment(project, nil, KindIssue, true, nil); got != want {
+			t.Errorf("comment without related documents:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
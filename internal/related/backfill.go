@@ -0,0 +1,158 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"rsc.io/ordered"
+)
+
+// [Poster.Run] only posts on issues created after the Poster's
+// time limit, so a newly enabled project would only get comments on
+// its future issues. A backfill posts on a project's existing open
+// issues instead, a bounded number at a time, recording its progress
+// in the database so that it can resume where it left off:
+//
+//	(related.Backfill, $name, $project, $minIssue, $maxIssue, $after, $before) -> $nextIssue
+//
+// where $after and $before are Unix times (0 for none).
+
+// A Backfill selects existing issues to post related documents on,
+// and limits the rate of posting. See [Poster.Backfill].
+type Backfill struct {
+	Project  string    // GitHub project, such as "golang/go"
+	MinIssue int64     // lowest issue number; 0 for no lower bound
+	MaxIssue int64     // highest issue number; 0 for no upper bound
+	After    time.Time // if non-zero, only issues created at or after After
+	Before   time.Time // if non-zero, only issues created before Before
+
+	MaxPosts int           // maximum comments to log per call; 0 for no limit
+	Interval time.Duration // time to wait after logging each comment
+}
+
+// A BackfillResult is the result of [Poster.Backfill].
+type BackfillResult struct {
+	Posted int   // number of comments logged by this call
+	Next   int64 // next issue to consider, if not done
+	Done   bool  // whether every issue in the range has been considered
+}
+
+var errBackfillNotEnabled = errors.New("related.Poster.Backfill: posting not enabled")
+
+// Backfill logs actions to post related documents on the open issues
+// in the range selected by b, in increasing issue order, following
+// the same logic as [Poster.Run] but ignoring the Poster's time limit
+// (see [Poster.SetTimeLimit]). Issues that already have a comment
+// are skipped.
+//
+// Backfill stops after logging b.MaxPosts comments, waiting b.Interval
+// after each one. Its progress is recorded in the database for the
+// range (the project, issue numbers and dates), so that calling Backfill
+// again with the same range resumes where it stopped. Once the range
+// is done, further calls consider only issues created since.
+//
+// The project must be enabled with [Poster.EnableProject], and posting
+// must be enabled with [Poster.EnablePosts].
+func (p *Poster) Backfill(ctx context.Context, b *Backfill) (*BackfillResult, error) {
+	if !p.post {
+		return nil, errBackfillNotEnabled
+	}
+	if !p.projects[b.Project] {
+		return nil, fmt.Errorf("related.Poster.Backfill: project %s not enabled", b.Project)
+	}
+
+	key := p.backfillKey(b)
+	next := b.MinIssue
+	if val, ok := p.db.Get(key); ok {
+		if err := ordered.Decode(val, &next); err != nil {
+			p.db.Panic("related.Poster.Backfill decode", "key", key, "err", err)
+		}
+	}
+	maxIssue := b.MaxIssue
+	if maxIssue == 0 {
+		maxIssue = -1
+	}
+
+	s := p.runSeed()
+	p.slog.Info("related.Poster backfill start", "name", p.name, "project", b.Project, "next", next, "seed", s)
+	p.calibration = p.Calibration()
+	res := &BackfillResult{}
+	for e := range p.github.Events(b.Project, next, maxIssue) {
+		if e.API != "/issues" {
+			continue
+		}
+		if b.MaxPosts > 0 && res.Posted >= b.MaxPosts {
+			res.Next = e.Issue
+			return res, nil
+		}
+		if p.inBackfill(e, b) {
+			_, logged := actions.Get(p.db, p.actionKind, logKey(e))
+			if _, err := p.logPostIssue(ctx, e, s, time.Time{}, false); err != nil {
+				if errors.Is(err, errVectorSearchFailed) && p.llm != nil && !p.llm.Available() {
+					// Try this issue again later.
+					p.slog.Warn("related.Poster backfill deferred (LLM unavailable)", "issue", e.Issue, "error", err)
+					res.Next = e.Issue
+					return res, nil
+				}
+				p.slog.Error("related.Poster backfill", "issue", e.Issue, "error", err)
+			}
+			if _, ok := actions.Get(p.db, p.actionKind, logKey(e)); ok && !logged {
+				res.Posted++
+				if err := sleep(ctx, b.Interval); err != nil {
+					p.db.Set(key, ordered.Encode(e.Issue+1))
+					res.Next = e.Issue + 1
+					return res, err
+				}
+			}
+		}
+		p.db.Set(key, ordered.Encode(e.Issue+1))
+	}
+	p.slog.Info("related.Poster backfill done", "name", p.name, "project", b.Project, "posted", res.Posted)
+	res.Done = true
+	return res, nil
+}
+
+// backfillKey returns the database key for the progress of the
+// backfill of the range in b.
+func (p *Poster) backfillKey(b *Backfill) []byte {
+	unix := func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Unix()
+	}
+	return ordered.Encode("related.Backfill", p.name, b.Project, b.MinIssue, b.MaxIssue, unix(b.After), unix(b.Before))
+}
+
+// inBackfill reports whether the issue of event e was created
+// in the time range of the backfill b.
+func (p *Poster) inBackfill(e *github.Event, b *Backfill) bool {
+	issue := e.Typed.(*github.Issue)
+	tm, err := time.Parse(time.RFC3339, issue.CreatedAt)
+	if err != nil {
+		p.slog.Error("related.Poster backfill parse createdat", "CreatedAt", issue.CreatedAt, "err", err)
+		return false
+	}
+	return (b.After.IsZero() || !tm.Before(b.After)) && (b.Before.IsZero() || tm.Before(b.Before))
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
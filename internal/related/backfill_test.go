@@ -0,0 +1,53 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+)
+
+func TestBackfill(t *testing.T) {
+	p, _, project, check := newTestPoster(t)
+	// Backfill ignores the time limit that keeps Run from posting.
+	p.SetTimeLimit(time.Now())
+	check(p.Run(ctx))
+	checkActionLog(t, p.db, nil)
+
+	backfill := func(b *Backfill, wantPosted int, wantDone bool) {
+		t.Helper()
+		res, err := p.Backfill(ctx, b)
+		check(err)
+		check(actions.Run(ctx, p.slog, p.db))
+		if res.Posted != wantPosted || res.Done != wantDone {
+			t.Fatalf("Backfill = %+v, want Posted=%d Done=%v", res, wantPosted, wantDone)
+		}
+	}
+
+	// Only issues 13 and 19 are open. Issue 13 is too old.
+	b := &Backfill{Project: project, After: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}
+	backfill(b, 1, true)
+	checkActionLog(t, p.db, map[int64]string{19: post19})
+
+	// A different range has its own progress.
+	// With one post per call, the first call stops after issue 13;
+	// the second finds that issue 19 already has a comment.
+	b = &Backfill{Project: project, MinIssue: 10, MaxPosts: 1}
+	backfill(b, 1, false)
+	checkActionLog(t, p.db, map[int64]string{13: post13, 19: post19})
+	backfill(b, 0, true)
+	backfill(b, 0, true)
+
+	if _, err := p.Backfill(ctx, &Backfill{Project: "golang/go"}); err == nil {
+		t.Error("Backfill of disabled project succeeded")
+	}
+	p.post = false
+	if _, err := p.Backfill(ctx, b); !errors.Is(err, errBackfillNotEnabled) {
+		t.Errorf("Backfill without posts: got %v, want %v", err, errBackfillNotEnabled)
+	}
+}
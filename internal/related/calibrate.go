@@ -0,0 +1,184 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// Documents of different kinds (issues, documentation, code changes)
+// have different distributions of similarity scores, so a single
+// minimum score (see [Poster.SetMinScore]) can be too strict for
+// some kinds and too lax for others. A Poster can use a different
+// minimum score for each kind, set explicitly with [Poster.SetKindMinScore]
+// or calibrated from the scores it has seen with [Poster.EnableCalibration].
+//
+// The scores are recorded in histograms in the database:
+//
+//	(related.Scores, $name, $kind) -> [scoreHistogram]
+//	(related.Calibration, $name) -> map[$kind]$minScore
+
+// numBuckets is the number of buckets in a [scoreHistogram].
+const numBuckets = 100
+
+// minCalibrationSamples is the minimum number of scores that must be
+// recorded for a kind before its minimum score is calibrated.
+var minCalibrationSamples int64 = 200
+
+// A scoreHistogram counts the scores of candidate related documents
+// of one kind. Counts[i] is the number of scores s with
+// i/numBuckets ≤ s < (i+1)/numBuckets (scores of 1 are in the last bucket).
+type scoreHistogram struct {
+	Counts [numBuckets]int64
+	Total  int64
+}
+
+// add adds the score s to the histogram.
+func (h *scoreHistogram) add(s float64) {
+	i := int(s * numBuckets)
+	i = max(0, min(i, numBuckets-1))
+	h.Counts[i]++
+	h.Total++
+}
+
+// percentile returns the lowest score such that at least the fraction q
+// of the recorded scores are below it, rounded up to a bucket boundary.
+func (h *scoreHistogram) percentile(q float64) float64 {
+	want := q * float64(h.Total)
+	var n int64
+	for i, c := range h.Counts {
+		n += c
+		if float64(n) >= want {
+			return float64(i+1) / numBuckets
+		}
+	}
+	return 1
+}
+
+// SetKindMinScore sets the minimum score for related documents of the
+// given kind (one of the search.Kind constants, such as
+// [search.KindGoDocumentation]), overriding both [Poster.SetMinScore]
+// and any calibrated minimum score for the kind.
+func (p *Poster) SetKindMinScore(kind string, min float64) {
+	p.kindMinScores[kind] = min
+}
+
+// EnableCalibration configures the Poster to record the scores of the
+// candidate related documents it considers, and at the start of each
+// [Poster.Run], to set the minimum score for each kind of document to the
+// given percentile of the recorded scores of that kind. For example,
+// a percentile of 0.95 only posts documents that score higher than 95% of
+// the candidates of their kind. A kind's minimum score is only calibrated
+// once enough scores have been recorded; until then, the minimum score
+// set by [Poster.SetMinScore] applies.
+//
+// Scores are only recorded while calibration is enabled,
+// so it takes a number of runs for the calibration to take effect.
+func (p *Poster) EnableCalibration(percentile float64) {
+	p.calibrate = percentile
+}
+
+// Calibration returns the calibrated minimum scores, by kind,
+// computed by the last [Poster.Run] with calibration enabled.
+func (p *Poster) Calibration() map[string]float64 {
+	m := make(map[string]float64)
+	if data, ok := p.db.Get(ordered.Encode("related.Calibration", p.name)); ok {
+		if err := json.Unmarshal(data, &m); err != nil {
+			// unreachable unless database corruption
+			p.db.Panic("related.Poster calibration decode", "name", p.name, "err", err)
+		}
+	}
+	return m
+}
+
+// minScore returns the minimum score for related documents of the
+// given kind, using the calibrated minimum scores cal.
+func (p *Poster) minScore(kind string, cal map[string]float64) float64 {
+	if s, ok := p.kindMinScores[kind]; ok {
+		return s
+	}
+	if s, ok := cal[kind]; ok {
+		return s
+	}
+	return p.scoreCutoff
+}
+
+// searchThreshold returns the threshold to use for the vector search:
+// the lowest of the minimum scores, or 0 if scores are being recorded.
+func (p *Poster) searchThreshold(cal map[string]float64) float64 {
+	if p.calibrate > 0 {
+		return 0
+	}
+	t := p.scoreCutoff
+	for _, s := range p.kindMinScores {
+		t = min(t, s)
+	}
+	for _, s := range cal {
+		t = min(t, s)
+	}
+	return t
+}
+
+// recordScores adds the scores of the candidate results to
+// the histograms for their kinds.
+func (p *Poster) recordScores(results []search.Result) {
+	byKind := make(map[string][]float64)
+	for _, r := range results {
+		byKind[r.Kind] = append(byKind[r.Kind], r.Score)
+	}
+	for kind, scores := range byKind {
+		key := ordered.Encode("related.Scores", p.name, kind)
+		p.db.Lock(string(key))
+		h := p.histogram(kind)
+		for _, s := range scores {
+			h.add(s)
+		}
+		p.db.Set(key, storage.JSON(h))
+		p.db.Unlock(string(key))
+	}
+}
+
+// histogram returns the recorded histogram of scores for the kind.
+func (p *Poster) histogram(kind string) *scoreHistogram {
+	h := new(scoreHistogram)
+	if data, ok := p.db.Get(ordered.Encode("related.Scores", p.name, kind)); ok {
+		if err := json.Unmarshal(data, h); err != nil {
+			// unreachable unless database corruption
+			p.db.Panic("related.Poster histogram decode", "kind", kind, "err", err)
+		}
+	}
+	return h
+}
+
+// recalibrate computes the calibrated minimum score for each kind with
+// enough recorded scores, stores them in the database, and returns them.
+func (p *Poster) recalibrate() map[string]float64 {
+	cal := make(map[string]float64)
+	for key, val := range p.db.Scan(ordered.Encode("related.Scores", p.name), ordered.Encode("related.Scores", p.name, ordered.Inf)) {
+		var name, kind string
+		if err := ordered.Decode(key, nil, &name, &kind); err != nil {
+			p.db.Panic("related.Poster histogram key decode", "key", storage.Fmt(key), "err", err)
+		}
+		var h scoreHistogram
+		if err := json.Unmarshal(val(), &h); err != nil {
+			p.db.Panic("related.Poster histogram decode", "key", storage.Fmt(key), "err", err)
+		}
+		if h.Total < minCalibrationSamples {
+			continue
+		}
+		cal[kind] = h.percentile(p.calibrate)
+	}
+	if !maps.Equal(cal, p.Calibration()) {
+		p.slog.Info("related.Poster calibrated", "name", p.name, "minScores", fmt.Sprint(cal))
+	}
+	p.db.Set(ordered.Encode("related.Calibration", p.name), storage.JSON(cal))
+	return cal
+}
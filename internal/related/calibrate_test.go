@@ -0,0 +1,99 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"testing"
+
+	"golang.org/x/oscar/internal/entity"
+	"golang.org/x/oscar/internal/search"
+)
+
+func TestScoreHistogram(t *testing.T) {
+	var h scoreHistogram
+	for _, s := range []float64{0.105, 0.5, 0.5, 0.805, 1, 1.2, -0.1} {
+		h.add(s)
+	}
+	if h.Total != 7 || h.Counts[0] != 1 || h.Counts[10] != 1 || h.Counts[50] != 2 || h.Counts[80] != 1 || h.Counts[99] != 2 {
+		t.Fatalf("histogram = %+v", h)
+	}
+	for _, tt := range []struct {
+		q    float64
+		want float64
+	}{
+		{0, 0.01},
+		{2.0 / 7, 0.11},
+		{0.5, 0.51},
+		{5.0 / 7, 0.81},
+		{1, 1},
+	} {
+		if got := h.percentile(tt.q); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestKindMinScore(t *testing.T) {
+	p, _, project, _ := newTestPoster(t)
+	u := entity.Issue(project, 19).DocID()
+
+	count := func() int {
+		t.Helper()
+		results, ok := p.search(u, 0, false)
+		if !ok {
+			t.Fatal("search failed")
+		}
+		return len(results)
+	}
+	n := count()
+	if n == 0 {
+		t.Fatal("no related documents")
+	}
+
+	p.SetKindMinScore(search.KindGitHubIssue, 1.1)
+	if got := count(); got != 0 {
+		t.Errorf("with high issue min score: %d results, want 0", got)
+	}
+	p.SetKindMinScore(search.KindGitHubIssue, p.scoreCutoff)
+	p.SetMinScore(1.1)
+	if got := count(); got != n {
+		t.Errorf("with issue min score overriding default: %d results, want %d", got, n)
+	}
+}
+
+func TestCalibration(t *testing.T) {
+	p, _, _, check := newTestPoster(t)
+	defer func(n int64) { minCalibrationSamples = n }(minCalibrationSamples)
+	minCalibrationSamples = 1
+
+	p.EnableCalibration(0.5)
+	// The first run records scores; the second calibrates from them.
+	check(p.Run(ctx))
+	if cal := p.Calibration(); len(cal) != 0 {
+		t.Errorf("calibration before any scores = %v, want none", cal)
+	}
+	h := p.histogram(search.KindGitHubIssue)
+	if h.Total == 0 {
+		t.Fatal("no scores recorded")
+	}
+	check(p.Run(ctx))
+	cal := p.Calibration()
+	want := h.percentile(0.5)
+	if got, ok := cal[search.KindGitHubIssue]; !ok || got != want {
+		t.Errorf("calibrated issue min score = %v, %v; want %v", got, ok, want)
+	}
+	if got := p.minScore(search.KindGitHubIssue, cal); got != want {
+		t.Errorf("minScore(issue) = %v, want %v", got, want)
+	}
+	if got := p.minScore(search.KindGoBlog, cal); got != p.scoreCutoff {
+		t.Errorf("minScore(blog) = %v, want default %v", got, p.scoreCutoff)
+	}
+
+	// An explicit minimum score overrides calibration.
+	p.SetKindMinScore(search.KindGitHubIssue, 0.9)
+	if got := p.minScore(search.KindGitHubIssue, cal); got != 0.9 {
+		t.Errorf("minScore(issue) with explicit min = %v, want 0.9", got)
+	}
+}
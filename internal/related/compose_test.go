@@ -0,0 +1,47 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"testing"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/compose"
+)
+
+func TestComposer(t *testing.T) {
+	p, _, project, check := newTestPoster(t)
+	c := compose.New(p.slog, p.db, p.github, t.Name())
+	c.SetWindow(0)
+	p.SetComposer(c)
+	p.UpdateExisting(1)
+
+	check(p.Run(ctx))
+	check(actions.Run(ctx, p.slog, p.db))
+	if n := len(p.github.Testing().Edits()); n != 0 {
+		t.Fatalf("Run posted %d comments, want 0", n)
+	}
+	for issue, want := range map[int64]string{13: post13, 19: post19} {
+		parts := c.Pending(project, issue)
+		if len(parts) != 1 || parts[0].Source != "related" || parts[0].Body != want {
+			t.Errorf("issue %d: pending parts = %v, want related comment", issue, parts)
+		}
+	}
+
+	// The combined comment is posted by the Composer.
+	check(c.Run(ctx))
+	check(actions.Run(ctx, p.slog, p.db))
+	if n := len(p.github.Testing().Edits()); n != 2 {
+		t.Errorf("Composer posted %d comments, want 2", n)
+	}
+
+	// Comments added to the Composer are not updated.
+	p.github.Testing().ClearEdits()
+	check(p.Run(ctx))
+	check(actions.Run(ctx, p.slog, p.db))
+	if n := len(p.github.Testing().Edits()); n != 0 {
+		t.Errorf("second Run edited %d comments, want 0", n)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/oscar/internal/search"
+)
+
+// The same content is often indexed more than once: an issue and
+// a page mirroring it, a change under both its go.dev/cl and
+// Gerrit URLs, or two issues that duplicate each other. Listing
+// both wastes space in a post, so before rendering, the Poster
+// drops results whose canonical URL (see [canonicalURL]) matches
+// a better result or the query itself, and results whose embedding
+// is nearly identical to a better result's (see [Poster.SetDedupCutoff]).
+
+// SetDedupCutoff sets the similarity (dot product of embeddings)
+// at or above which two related documents are considered the same,
+// so that only the higher-scoring one is posted.
+// The default is 0.97. A cutoff of 0 disables the comparison of
+// embeddings; documents with the same canonical URL are always
+// considered the same.
+func (p *Poster) SetDedupCutoff(cutoff float64) {
+	p.dedupCutoff = cutoff
+}
+
+const defaultDedupCutoff = 0.97
+
+// dedup returns results, which must be sorted by decreasing score,
+// without the results that duplicate a higher-scoring one or the
+// query document u. It edits results in place.
+func (p *Poster) dedup(u string, results []search.Result) []search.Result {
+	seen := map[string]bool{canonicalURL(u): true}
+	var kept []search.Result
+	for _, r := range results {
+		cu := canonicalURL(r.ID)
+		if seen[cu] {
+			p.slog.Debug("related.Poster dedup", "query", u, "result", r.ID, "reason", "url")
+			continue
+		}
+		if dup, ok := p.similar(r, kept); ok {
+			p.slog.Debug("related.Poster dedup", "query", u, "result", r.ID, "reason", "similar", "to", dup)
+			continue
+		}
+		seen[cu] = true
+		kept = append(kept, r)
+	}
+	return append(results[:0], kept...)
+}
+
+// similar reports whether the embedding of r is at least as similar
+// as the dedup cutoff to the embedding of one of kept,
+// returning that one's ID.
+func (p *Poster) similar(r search.Result, kept []search.Result) (string, bool) {
+	if p.dedupCutoff <= 0 || len(kept) == 0 {
+		return "", false
+	}
+	vec, ok := p.vdb.Get(r.ID)
+	if !ok {
+		return "", false
+	}
+	for _, k := range kept {
+		kvec, ok := p.vdb.Get(k.ID)
+		if ok && vec.Dot(kvec) >= p.dedupCutoff {
+			return k.ID, true
+		}
+	}
+	return "", false
+}
+
+var (
+	issueShortRE   = regexp.MustCompile(`^/issues?/(\d+)$`)
+	clShortRE      = regexp.MustCompile(`^/cl/(\d+)$`)
+	gerritChangeRE = regexp.MustCompile(`^/c/[^+]+/\+/(\d+)(?:/.*)?$`)
+)
+
+// canonicalURL returns a canonical form of the document URL u,
+// so that different URLs for the same document compare equal.
+// It ignores the scheme, a "www." prefix, a trailing slash,
+// the query and the fragment, and maps the go.dev and golang.org
+// short links for Go issues and changes to the URLs they redirect to.
+// If u cannot be parsed, canonicalURL returns it unchanged.
+func canonicalURL(u string) string {
+	pu, err := url.Parse(u)
+	if err != nil || pu.Host == "" {
+		return u
+	}
+	host := strings.TrimPrefix(strings.ToLower(pu.Host), "www.")
+	path := strings.TrimSuffix(pu.Path, "/")
+	switch host {
+	case "golang.org", "go.dev":
+		if m := issueShortRE.FindStringSubmatch(path); m != nil {
+			host, path = "github.com", "/golang/go/issues/"+m[1]
+		} else if m := clShortRE.FindStringSubmatch(path); m != nil {
+			host, path = "go-review.googlesource.com", "/"+m[1]
+		} else if host == "golang.org" && !strings.HasPrefix(path, "/x/") {
+			host = "go.dev"
+		}
+	case "go-review.googlesource.com":
+		if m := gerritChangeRE.FindStringSubmatch(path); m != nil {
+			path = "/" + m[1]
+		}
+	}
+	return host + path
+}
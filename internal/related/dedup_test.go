@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	for _, tt := range []struct {
+		u1, u2 string
+	}{
+		{"https://go.dev/issue/123", "https://github.com/golang/go/issues/123"},
+		{"https://golang.org/issues/123", "https://github.com/golang/go/issues/123#issuecomment-1"},
+		{"https://go.dev/cl/456", "https://go-review.googlesource.com/c/tools/+/456"},
+		{"https://go.dev/cl/456", "https://go-review.googlesource.com/c/go/+/456/3#related-content"},
+		{"http://www.go.dev/doc/effective_go/", "https://go.dev/doc/effective_go?x=1"},
+		{"https://golang.org/doc/faq", "https://go.dev/doc/faq"},
+	} {
+		if c1, c2 := canonicalURL(tt.u1), canonicalURL(tt.u2); c1 != c2 {
+			t.Errorf("canonicalURL(%q) = %q, canonicalURL(%q) = %q, want equal", tt.u1, c1, tt.u2, c2)
+		}
+	}
+	for _, tt := range []struct {
+		u1, u2 string
+	}{
+		{"https://go.dev/issue/123", "https://github.com/golang/go/issues/124"},
+		{"https://golang.org/x/tools", "https://go.dev/x/tools"},
+		{"https://github.com/golang/go/issues/1", "https://github.com/golang/tools/issues/1"},
+	} {
+		if c := canonicalURL(tt.u1); c == canonicalURL(tt.u2) {
+			t.Errorf("canonicalURL(%q) = canonicalURL(%q) = %q, want different", tt.u1, tt.u2, c)
+		}
+	}
+}
+
+func TestDedup(t *testing.T) {
+	p, _, _, _ := newTestPoster(t)
+	vec, ok := p.vdb.Get("https://github.com/rsc/markdown/issues/19")
+	if !ok {
+		t.Fatal("no embedding for issue 19")
+	}
+	p.vdb.Set("https://go.dev/doc/a", nearby(vec, 0))
+	p.vdb.Set("https://go.dev/doc/a-copy", nearby(vec, 0))
+	p.vdb.Set("https://go.dev/doc/b", nearby(vec, 1))
+
+	results := func() []search.Result {
+		return []search.Result{
+			{Kind: search.KindGitHubIssue, VectorResult: storage.VectorResult{ID: "https://go.dev/issue/1", Score: 0.99}},
+			{Kind: search.KindGoDocumentation, VectorResult: storage.VectorResult{ID: "https://go.dev/doc/a", Score: 0.98}},
+			{Kind: search.KindGitHubIssue, VectorResult: storage.VectorResult{ID: "https://github.com/golang/go/issues/2", Score: 0.97}},
+			{Kind: search.KindGoDocumentation, VectorResult: storage.VectorResult{ID: "https://go.dev/doc/a-copy", Score: 0.96}},
+			{Kind: search.KindGoDocumentation, VectorResult: storage.VectorResult{ID: "https://go.dev/doc/b", Score: 0.95}},
+			{Kind: search.KindGitHubIssue, VectorResult: storage.VectorResult{ID: "https://github.com/golang/go/issues/1#issuecomment-1", Score: 0.94}},
+		}
+	}
+	ids := func(rs []search.Result) []string {
+		var ids []string
+		for _, r := range rs {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	// The query issue 2 and the copies of issue 1 and doc a are dropped.
+	got := ids(p.dedup("https://go.dev/issue/2", results()))
+	want := []string{"https://go.dev/issue/1", "https://go.dev/doc/a", "https://go.dev/doc/b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("dedup = %q, want %q", got, want)
+	}
+
+	// Without the similarity cutoff, only the URLs are compared.
+	p.SetDedupCutoff(0)
+	got = ids(p.dedup("https://go.dev/issue/2", results()))
+	want = []string{"https://go.dev/issue/1", "https://go.dev/doc/a", "https://go.dev/doc/a-copy", "https://go.dev/doc/b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("dedup without cutoff = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/discussion"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/seed"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// A discAction has all the information needed to post a comment
+// to a GitHub discussion.
+type discAction struct {
+	Discussion *discussion.Discussion
+	Body       string
+	Seed       seed.Seed // seed used to choose the related documents
+	Related    []string  // IDs of the related documents in the comment
+}
+
+// runDiscussions posts on the discussions that are new since the last run,
+// as [Poster.runIssues] does for issues.
+func (p *Poster) runDiscussions(ctx context.Context, s seed.Seed) {
+	defer p.discWatcher.Flush()
+	for e := range p.discWatcher.Recent() {
+		advance, err := p.logPostDiscussion(ctx, e, s)
+		if err != nil {
+			if errors.Is(err, errVectorSearchFailed) && p.llm != nil && !p.llm.Available() {
+				p.slog.Warn("related.Poster deferred (LLM unavailable)", "discussion", e.Discussion, "error", err)
+				return
+			}
+			p.slog.Error("related.Poster", "discussion", e.Discussion, "error", err)
+			continue
+		}
+		if advance {
+			p.discWatcher.MarkOld(e.DBTime)
+			p.discWatcher.Flush()
+		}
+	}
+}
+
+// logPostDiscussion logs an action to post on the discussion in the event.
+// Its result is like that of [Poster.logPostIssue].
+func (p *Poster) logPostDiscussion(ctx context.Context, e *discussion.Event, s seed.Seed) (advance bool, _ error) {
+	if skip, reason := p.skipDiscussion(e); skip {
+		p.slog.Info("related.Poster skip", "name", p.name, "project", e.Project,
+			"discussion", e.Discussion, "reason", reason)
+		return false, nil
+	}
+	key := ordered.Encode(e.Project, e.Discussion)
+	if _, ok := actions.Get(p.db, p.discKind, key); ok {
+		return p.post, nil
+	}
+
+	d := e.Typed.(*discussion.Discussion)
+	results, ok := p.search(d.URL, s, true)
+	if !ok {
+		return false, fmt.Errorf("%w url=%s", errVectorSearchFailed, d.URL)
+	}
+	if len(results) == 0 {
+		p.slog.Info("related.Poster found no related documents", "name", p.name, "project", e.Project, "discussion", e.Discussion)
+		return p.post, nil
+	}
+	comment := p.comment(e.Project, results, KindDiscussion, false, p.explain(ctx, d.URL, results))
+	p.slog.Info("related.Poster post", "name", p.name, "project", e.Project, "discussion", e.Discussion, "seed", s, "comment", comment)
+	if !p.post {
+		return false, nil
+	}
+	act := &discAction{
+		Discussion: d,
+		Body:       comment,
+		Seed:       s,
+		Related:    resultIDs(results),
+	}
+	p.logDisc(p.db, key, storage.JSON(act), p.requireApproval)
+	return true, nil
+}
+
+// skipDiscussion reports whether the event should be skipped and why.
+func (p *Poster) skipDiscussion(e *discussion.Event) (_ bool, reason string) {
+	if !p.projects[e.Project] {
+		return true, fmt.Sprintf("project %s not enabled for this Poster", e.Project)
+	}
+	if e.API != discussion.DiscussionAPI {
+		return true, fmt.Sprintf("wrong API %s (expected %s)", e.API, discussion.DiscussionAPI)
+	}
+	d := e.Typed.(*discussion.Discussion)
+	if d.ClosedAt != "" {
+		return true, "discussion is closed"
+	}
+	if d.Locked {
+		return true, "discussion is locked"
+	}
+	tm, err := time.Parse(time.RFC3339, d.CreatedAt)
+	if err != nil {
+		p.slog.Error("related.Poster parse createdat", "CreatedAt", d.CreatedAt, "err", err)
+		return true, "could not parse createdat"
+	}
+	if tm.Before(p.timeLimit) {
+		return true, fmt.Sprintf("created=%s before time limit=%s", tm, p.timeLimit)
+	}
+	issue := &github.Issue{Title: d.Title, Body: d.Body}
+	for i, ig := range p.ignores {
+		if ig(issue) {
+			return true, fmt.Sprintf("ignored by function ignores[%d]", i)
+		}
+	}
+	return false, ""
+}
+
+type discActioner struct {
+	p *Poster
+}
+
+func (ar *discActioner) Run(ctx context.Context, data []byte) ([]byte, error) {
+	var a discAction
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	if ar.p.disc == nil {
+		return nil, errors.New("related.Poster: discussions not enabled")
+	}
+	url, err := ar.p.disc.PostComment(ctx, a.Discussion, a.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w discussion=%d: %w", errPostIssueCommentFailed, a.Discussion.Number, err)
+	}
+	return storage.JSON(&result{URL: url}), nil
+}
+
+func (ar *discActioner) ForDisplay(data []byte) string {
+	var a discAction
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	return a.Discussion.URL + "\n" + a.Body
+}
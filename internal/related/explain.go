@@ -0,0 +1,91 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"context"
+
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/search"
+)
+
+// EnableExplanations configures the Poster to ask the LLM behind lc
+// for a one-line explanation of why each related document is related
+// to the issue or discussion being commented on (for example,
+// a matching symptom, the same API or the same crash signature).
+// Comments then show each explanation under its link
+// (see also the Why field of [TemplateDocument]).
+// If no explanations can be generated, for instance because the
+// LLM is unavailable or its output violates a policy, the Poster
+// posts the related documents without them.
+func (p *Poster) EnableExplanations(lc *llmapp.Client) {
+	p.explainer = lc
+}
+
+// maxExplanation is the maximum length in bytes of an explanation
+// in a comment. The LLM is asked for a short line; this guards against
+// long ones.
+const maxExplanation = 200
+
+// explain returns one-line explanations of how the results are
+// related to the document with the given ID, keyed by result ID.
+// It returns nil if explanations are not enabled or could not be
+// generated; problems are logged, not returned.
+func (p *Poster) explain(ctx context.Context, id string, results []search.Result) map[string]string {
+	if p.explainer == nil || len(results) == 0 {
+		return nil
+	}
+	d, ok := p.docs.Get(id)
+	if !ok {
+		p.slog.Error("related.Poster explain: doc not in corpus", "id", id)
+		return nil
+	}
+	doc := &llmapp.Doc{Type: "original", URL: id, Title: d.Title, Text: d.Text}
+	var ids []string
+	var related []*llmapp.Doc
+	for _, r := range results {
+		rd, ok := p.docs.Get(r.ID)
+		if !ok {
+			continue
+		}
+		ids = append(ids, r.ID)
+		related = append(related, &llmapp.Doc{Type: "related", URL: r.ID, Title: rd.Title, Text: rd.Text})
+	}
+	if len(related) == 0 {
+		return nil
+	}
+	res, err := p.explainer.ExplainRelated(ctx, doc, related)
+	if err != nil {
+		p.slog.Error("related.Poster explain", "id", id, "err", err)
+		return nil
+	}
+	if res.HasPolicyViolation() {
+		p.slog.Warn("related.Poster explain: policy violation", "id", id, "policy", res.PolicyEvaluation)
+		return nil
+	}
+	why := make(map[string]string)
+	for i, e := range res.Explanations {
+		if e == "" {
+			continue
+		}
+		if len(e) > maxExplanation {
+			e = truncate(e, maxExplanation-len("…")) + "…"
+		}
+		why[ids[i]] = e
+	}
+	return why
+}
+
+// truncate returns a prefix of s of at most n bytes,
+// without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// explainGenerator returns an [llm.ContentGenerator] that explains
+// every related document in the prompt with "shares code N",
+// where N is the number of the related document.
+func explainGenerator() llm.ContentGenerator {
+	return llm.TestContentGenerator("explain-test-generator",
+		func(_ context.Context, _ *llm.Schema, parts []llm.Part) (string, error) {
+			var es []llmapp.Explanation
+			for _, p := range parts {
+				var n int
+				if t, ok := p.(llm.Text); ok && strings.HasPrefix(string(t), "related ") {
+					fmt.Sscanf(string(t), "related %d", &n)
+					es = append(es, llmapp.Explanation{Related: n, Explanation: fmt.Sprintf("shares `code` %d", n)})
+				}
+			}
+			return string(storage.JSON(llmapp.Explanations{Explanations: es})), nil
+		})
+}
+
+func TestExplanations(t *testing.T) {
+	t.Run("run", func(t *testing.T) {
+		p, _, _, check := newTestPoster(t)
+		p.EnableExplanations(llmapp.New(p.slog, explainGenerator(), p.db))
+		check(p.Run(ctx))
+		check(actions.Run(ctx, p.slog, p.db))
+
+		checkActionLog(t, p.db, map[int64]string{13: explained(post13), 19: explained(post19)})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p, _, _, check := newTestPoster(t)
+		fail := llm.TestContentGenerator("fail", func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+			return "", errors.New("LLM unavailable")
+		})
+		p.EnableExplanations(llmapp.New(p.slog, fail, p.db))
+		check(p.Run(ctx))
+		check(actions.Run(ctx, p.slog, p.db))
+		// Posts go ahead without explanations.
+		checkActionLog(t, p.db, map[int64]string{13: post13, 19: post19})
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		p, _, project, _ := newTestPoster(t)
+		p.EnablePlainText(project)
+		results := []search.Result{{
+			Kind:         search.KindGitHubIssue,
+			VectorResult: storage.VectorResult{ID: "https://example.com/1", Score: 0.9},
+			Title:        "a bug",
+		}}
+		got := p.comment(project, results, KindIssue, false, map[string]string{"https://example.com/1": "same `panic`"})
+		want := "Related Issues:\n\n - a bug (https://example.com/1)\n   same `panic`\n"
+		if !strings.HasPrefix(got, want) {
+			t.Errorf("plain comment:\n%s\nwant prefix:\n%s", got, want)
+		}
+	})
+}
+
+// explained adds the explanations of explainGenerator
+// to the comment, under each link.
+func explained(comment string) string {
+	var b strings.Builder
+	n := 0
+	for _, line := range strings.SplitAfter(comment, "\n") {
+		b.WriteString(line)
+		if strings.HasPrefix(line, " - ") {
+			fmt.Fprintf(&b, "   <br>*shares \\`code\\` %d*\n", n)
+			n++
+		}
+	}
+	return b.String()
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"},
+		{"héllo", 3, "hé"},
+	} {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
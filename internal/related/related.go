@@ -10,38 +10,77 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
+	"text/template"
 	"time"
 
 	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/compose"
+	"golang.org/x/oscar/internal/discussion"
 	"golang.org/x/oscar/internal/docs"
 	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/mute"
+	"golang.org/x/oscar/internal/repoconfig"
 	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/seed"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/storage/timed"
 	"rsc.io/ordered"
 )
 
 // A Poster posts to GitHub about related issues (and eventually other documents).
+// It posts on new issues, and optionally on new pull requests
+// (see [Poster.EnablePullRequests]) and discussions
+// (see [Poster.EnableDiscussions]).
 type Poster struct {
-	slog        *slog.Logger
-	db          storage.DB
-	vdb         storage.VectorDB
-	github      *github.Client
-	docs        *docs.Corpus
-	projects    map[string]bool
-	watcher     *timed.Watcher[*github.Event]
-	name        string
-	timeLimit   time.Time
-	ignores     []func(*github.Issue) bool
-	maxResults  int
-	scoreCutoff float64
-	post        bool
+	slog          *slog.Logger
+	db            storage.DB
+	vdb           storage.VectorDB
+	github        *github.Client
+	docs          *docs.Corpus
+	projects      map[string]bool
+	plainText     map[string]bool // projects to post plain-text comments in
+	watcher       *timed.Watcher[*github.Event]
+	name          string
+	timeLimit     time.Time
+	ignores       []func(*github.Issue) bool
+	maxResults    int
+	scoreCutoff   float64
+	kindMinScores map[string]float64 // minimum scores by kind; see SetKindMinScore
+	calibrate     float64            // if > 0, the percentile for calibration; see EnableCalibration
+	calibration   map[string]float64 // calibrated minimum scores by kind, for the current run
+	dedupCutoff   float64            // similarity at which results are duplicates; see SetDedupCutoff
+	reranker      Reranker           // if non-nil, reorders the candidates; see SetReranker
+	denylist      *search.Denylist   // if non-nil, documents never to post; see SetDenylist
+	seed          *seed.Seed         // if non-nil, the seed for every run; see SetSeed
+	post          bool
+	llm           *llm.Availability // if non-nil, defer posts while the LLM is unavailable
+	explainer     *llmapp.Client    // if non-nil, explain related documents; see EnableExplanations
+	composer      *compose.Composer // if non-nil, add comments on issues to it; see SetComposer
+	muted         *mute.Set         // if non-nil, skip the issues in it; see SkipMuted
+	updateMin     int               // if > 0, update posted comments; see UpdateExisting
+	pulls         bool              // post on pull requests too
+	pullMode      PullMode          // how to post on pull requests
+	disc          *discussion.Client
+	discWatcher   *timed.Watcher[*discussion.Event]
+	templates     map[templateKey]*template.Template
+	feedback      map[string]string  // project → feedback URL; see SetFeedbackURL
+	ack           map[string]int     // project → usual triage days; see EnableAcknowledgement
+	repo          *repoconfig.Config // if non-nil, per-repository settings; see SetRepoConfig
 	// For the action log.
 	requireApproval bool
 	actionKind      string
 	logAction       actions.BeforeFunc
+	updateKind      string
+	logUpdate       actions.BeforeFunc
+	discKind        string
+	logDisc         actions.BeforeFunc
 }
 
 // New creates and returns a new Poster. It logs to lg, stores state in db,
@@ -55,22 +94,30 @@ type Poster struct {
 // before calling [Poster.Run].
 func New(lg *slog.Logger, db storage.DB, gh *github.Client, vdb storage.VectorDB, docs *docs.Corpus, name string) *Poster {
 	p := &Poster{
-		slog:        lg,
-		db:          db,
-		vdb:         vdb,
-		github:      gh,
-		docs:        docs,
-		projects:    make(map[string]bool),
-		watcher:     gh.EventWatcher("related.Poster:" + name),
-		name:        name,
-		timeLimit:   time.Now().Add(-defaultTooOld),
-		maxResults:  defaultMaxResults,
-		scoreCutoff: defaultScoreCutoff,
+		slog:          lg,
+		db:            db,
+		vdb:           vdb,
+		github:        gh,
+		docs:          docs,
+		projects:      make(map[string]bool),
+		watcher:       gh.EventWatcher("related.Poster:" + name),
+		name:          name,
+		timeLimit:     time.Now().Add(-defaultTooOld),
+		maxResults:    defaultMaxResults,
+		scoreCutoff:   defaultScoreCutoff,
+		kindMinScores: make(map[string]float64),
+		dedupCutoff:   defaultDedupCutoff,
+		templates:     maps.Clone(defaultTemplates),
+		feedback:      make(map[string]string),
 	}
 	// TODO: Perhaps the action kind should include name, but perhaps not.
 	// This makes sure we only ever post to each issue once.
-	p.actionKind = "related.Poster"
+	p.actionKind = actionKind
 	p.logAction = actions.Register(p.actionKind, &actioner{p})
+	p.updateKind = "related.Poster.Update"
+	p.logUpdate = actions.Register(p.updateKind, &updater{p})
+	p.discKind = "related.Poster.Discussion"
+	p.logDisc = actions.Register(p.discKind, &discActioner{p})
 	return p
 }
 
@@ -96,12 +143,39 @@ const defaultMaxResults = 10
 // SetMinScore sets the minimum vector search score that a
 // [storage.VectorResult] must have to be considered a related document
 // The default is 0.82, which was determined empirically.
+// See also [Poster.SetKindMinScore] and [Poster.EnableCalibration].
 func (p *Poster) SetMinScore(min float64) {
 	p.scoreCutoff = min
 }
 
 const defaultScoreCutoff = 0.82
 
+// SetDenylist sets the list of documents that the Poster never
+// posts as related documents, such as a misleading old issue
+// that keeps matching.
+// The default is to post any document.
+func (p *Poster) SetDenylist(d *search.Denylist) {
+	p.denylist = d
+}
+
+// SetSeed configures the Poster to use s as the seed for
+// every run, instead of a new random seed.
+// The seed breaks ties between equally related documents
+// when there are more than the maximum number of results.
+// Each run logs its seed, and each logged action records it,
+// so that SetSeed can be used to reproduce an earlier decision.
+func (p *Poster) SetSeed(s seed.Seed) {
+	p.seed = &s
+}
+
+// runSeed returns the seed to use for a run.
+func (p *Poster) runSeed() seed.Seed {
+	if p.seed != nil {
+		return *p.seed
+	}
+	return seed.New()
+}
+
 // SkipBodyContains configures the Poster to skip issues with a body containing
 // the given text.
 func (p *Poster) SkipBodyContains(text string) {
@@ -132,6 +206,170 @@ func (p *Poster) EnableProject(project string) {
 	p.projects[project] = true
 }
 
+// EnablePlainText configures the Poster to post plain-text comments
+// on issues in the given GitHub project, without hidden HTML comments
+// or heavy formatting, so that they read well with a screen reader.
+func (p *Poster) EnablePlainText(project string) {
+	if p.plainText == nil {
+		p.plainText = make(map[string]bool)
+	}
+	p.plainText[project] = true
+}
+
+// EnablePullRequests enables the Poster to post on new pull requests
+// in the enabled projects, as well as on new issues.
+func (p *Poster) EnablePullRequests() {
+	p.pulls = true
+}
+
+// A PullMode is a way of posting related documents on a pull request.
+type PullMode int
+
+const (
+	// PullComment posts a regular comment, as on issues.
+	PullComment PullMode = iota
+	// PullReview posts a review that only comments,
+	// which GitHub shows apart from the conversation's comments.
+	PullReview
+	// PullCheckRun reports a neutral check run on the pull request's
+	// head commit, whose summary lists the related documents.
+	// It keeps the conversation clean, but requires GitHub App
+	// credentials; see [github.Client.CreateCheckRun].
+	PullCheckRun
+)
+
+// SetPullMode sets how the Poster posts on pull requests
+// (see [Poster.EnablePullRequests]). The default is [PullComment].
+// [Poster.UpdateExisting] only updates comments, not reviews or check runs.
+func (p *Poster) SetPullMode(m PullMode) {
+	p.pullMode = m
+}
+
+// checkRunName is the name of the check runs posted with [PullCheckRun].
+const checkRunName = "related"
+
+// EnableDiscussions enables the Poster to post on new discussions
+// in the enabled projects, which must be synced by dc.
+// The Poster watches dc for new discussions, posting once each
+// discussion has been embedded, like an issue.
+func (p *Poster) EnableDiscussions(dc *discussion.Client) {
+	p.disc = dc
+	p.discWatcher = dc.EventWatcher("related.Poster.Discussions:" + p.name)
+}
+
+// A Kind is a kind of GitHub content that the Poster posts on.
+type Kind int
+
+const (
+	KindIssue Kind = iota
+	KindPullRequest
+	KindDiscussion
+)
+
+// A templateKey identifies a comment template.
+// The empty project is the default for all projects.
+type templateKey struct {
+	project string
+	kind    Kind
+}
+
+// defaultTemplates are the default comment templates for each [Kind];
+// see [Poster.SetTemplate].
+var defaultTemplates = map[templateKey]*template.Template{
+	{"", KindIssue}:       template.Must(template.New("issue").Parse("{{.Acknowledgement}}{{.Sections}}{{.Footer}}")),
+	{"", KindPullRequest}: template.Must(template.New("pull").Parse("Issues and changes that may be related to this pull request:\n\n{{.Sections}}{{.Footer}}")),
+	{"", KindDiscussion}:  template.Must(template.New("discussion").Parse("Related content that may help with this discussion:\n\n{{.Sections}}{{.Footer}}")),
+}
+
+// defaultFeedbackURL is the default discussion linked from comments
+// for feedback; see [Poster.SetFeedbackURL].
+const defaultFeedbackURL = "https://github.com/golang/go/discussions/67901"
+
+// TemplateData is the data for comment templates; see [Poster.SetTemplate].
+//
+// Sections and Footer are ready-made English text, in Markdown or,
+// for projects enabled with [Poster.EnablePlainText], plain text.
+// Templates that localize or rebrand the comment can instead
+// build their own text from Groups and FeedbackURL.
+type TemplateData struct {
+	Project     string           // GitHub project, such as "golang/go"
+	Kind        Kind             // kind of content being commented on
+	Plain       bool             // whether the comment should be plain text
+	Sections    string           // lists of related documents, one section per group
+	Footer      string           // request for feedback
+	FeedbackURL string           // where to leave feedback
+	Groups      []*TemplateGroup // related documents, by group
+
+	// For new issues in projects enabled with [Poster.EnableAcknowledgement]:
+	Acknowledgement string // thanks and triage time, introducing Sections if not empty
+	TriageDays      int    // usual triage time in days
+}
+
+// A TemplateGroup is a group of related documents in a [TemplateData].
+type TemplateGroup struct {
+	Name  string              // "issues", "changes", "documentation" or "discussions"
+	Title string              // English title, such as "Related Issues"
+	Docs  []*TemplateDocument // related documents, most related first
+}
+
+// A TemplateDocument is a related document in a [TemplateGroup].
+type TemplateDocument struct {
+	URL   string  // URL of the document
+	Title string  // title of the document
+	Info  string  // for GitHub issues, the number and whether it is closed, as in " #123 (closed)"
+	Score float64 // similarity score
+	Why   string  // if not empty, a one-line explanation of why the document is related; see [Poster.EnableExplanations]
+}
+
+// SetTemplate sets the default template (see [text/template]) for
+// comments on content of kind k, for all projects without a template
+// set by [Poster.SetProjectTemplate].
+// The template is executed with a [*TemplateData].
+// The simplest templates use its Sections field, the lists of related
+// documents grouped into sections (such as "Related Issues"),
+// and its Footer field, a request for feedback.
+// The default template for issues is
+// "{{.Acknowledgement}}{{.Sections}}{{.Footer}}";
+// the defaults for pull requests and discussions add an introduction.
+func (p *Poster) SetTemplate(k Kind, text string) error {
+	return p.setTemplate("", k, text)
+}
+
+// SetProjectTemplate is like [Poster.SetTemplate] but sets the template
+// for comments on content of kind k in the given project only.
+// It lets a project localize or rebrand the comments, for instance.
+func (p *Poster) SetProjectTemplate(project string, k Kind, text string) error {
+	return p.setTemplate(project, k, text)
+}
+
+func (p *Poster) setTemplate(project string, k Kind, text string) error {
+	t, err := template.New(fmt.Sprint(project, "kind", k)).Parse(text)
+	if err != nil {
+		return fmt.Errorf("related.Poster.SetTemplate: %w", err)
+	}
+	if err := t.Execute(io.Discard, &TemplateData{}); err != nil {
+		return fmt.Errorf("related.Poster.SetTemplate: %w", err)
+	}
+	p.templates[templateKey{project, k}] = t
+	return nil
+}
+
+// template returns the template for comments on content
+// of kind k in the project.
+func (p *Poster) template(project string, k Kind) *template.Template {
+	if t := p.templates[templateKey{project, k}]; t != nil {
+		return t
+	}
+	return p.templates[templateKey{"", k}]
+}
+
+// SetFeedbackURL sets the URL linked from comments in the project
+// (in their Footer; see [TemplateData]) as the place to leave feedback.
+// The default is a discussion on the Go issue tracker.
+func (p *Poster) SetFeedbackURL(project, url string) {
+	p.feedback[project] = url
+}
+
 // EnablePosts enables the Poster to post to GitHub.
 // If EnablePosts has not been called, [Poster.Run] logs what it would post but does not post the messages.
 // See also [Poster.EnableProject], which must also be called to set the projects being considered.
@@ -144,21 +382,86 @@ func (p *Poster) RequireApproval() {
 	p.requireApproval = true
 }
 
+// SetComposer configures the Poster to add its comments on issues
+// (but not on pull requests or discussions) to c, which combines them
+// with other bots' comments on the same issue, instead of posting them
+// itself. Comments added to c are not updated (see [Poster.UpdateExisting]).
+func (p *Poster) SetComposer(c *compose.Composer) {
+	p.composer = c
+}
+
+// SkipMuted configures the Poster to never post to or update its
+// comments on the issues and pull requests in the mute set m,
+// including for actions logged before the issue was muted.
+func (p *Poster) SkipMuted(m *mute.Set) {
+	p.muted = m
+}
+
+// SetRepoConfig configures the Poster to apply the per-repository
+// settings in c: it skips issues with the repository's opt-out labels,
+// and posts only related documents scoring at least the repository's
+// minimum score, if that is higher than the Poster's own.
+func (p *Poster) SetRepoConfig(c *repoconfig.Config) {
+	p.repo = c
+}
+
+// isMuted reports whether the issue in project is in the
+// Poster's mute set (see [Poster.SkipMuted]).
+func (p *Poster) isMuted(project string, issue int64) bool {
+	return p.muted != nil && p.muted.Muted(project, issue)
+}
+
+// DeferWhenUnavailable configures the Poster to defer, rather than skip,
+// issues that cannot be searched while a is reporting the LLM service
+// (used to compute embeddings) as unavailable.
+// When such an issue is found, [Poster.Run] stops without advancing past it,
+// so that it is retried on a later call.
+func (p *Poster) DeferWhenUnavailable(a *llm.Availability) {
+	p.llm = a
+}
+
+// UpdateExisting configures the Poster to update the comments it has
+// posted when at least minChange related documents that are not in the
+// comment have appeared since it was posted (or last updated).
+// [Poster.Run] then edits the comment instead of leaving it stale.
+// Only comments on issues that the Poster would still post to
+// (for example, open issues within the time limit set by
+// [Poster.SetTimeLimit]) are updated, and only if no one else has
+// edited them. Like posts, updates are made through the action log,
+// and only if [Poster.EnablePosts] has been called.
+// A minChange of 0 or less disables updates, which is the default.
+func (p *Poster) UpdateExisting(minChange int) {
+	p.updateMin = minChange
+}
+
+// actionKind is the kind of the actions that post related documents.
+const actionKind = "related.Poster"
+
 // An action has all the information needed to post a comment to a GitHub issue.
 type action struct {
 	Issue   *github.Issue
 	Changes *github.IssueCommentChanges
+	Seed    seed.Seed // seed used to choose the related documents
+	Related []string  // IDs of the related documents in the comment
+	Mode    PullMode  // for pull requests, how to post the comment
+	Ack     bool      // whether the comment acknowledges a new issue
 }
 
 // result is the result of apply an action.
 type result struct {
-	URL string // URL of new comment
+	URL    string // URL of new comment
+	APIURL string // API URL of new comment
 }
 
 // Run runs a single round of posting to GitHub.
 // It scans all open issues that have been created since the last call to [Poster.Run]
 // using a Poster with the same name (see [New]).
-// Run skips closed issues, and it also skips pull requests.
+// Run skips closed issues, and it also skips pull requests unless
+// [Poster.EnablePullRequests] has been called.
+// If [Poster.EnableDiscussions] has been called, Run then does the same
+// for the discussions created since the last call, and if
+// [Poster.UpdateExisting] has been called, it finally updates
+// earlier comments.
 //
 // For each issue that matches the configured posting constraints
 // (see [Poster.EnableProject], [Poster.SetTimeLimit], [Poster.IgnoreBodyContains], [Poster.IgnoreTitlePrefix], and [Poster.IgnoreTitleSuffix]),
@@ -176,15 +479,39 @@ type result struct {
 // When [Poster.EnablePosts] has not been called, Run only logs the comments it would post.
 // Future calls to Run will reprocess the same issues and re-log the same comments.
 func (p *Poster) Run(ctx context.Context) error {
-	p.slog.Info("related.Poster start", "name", p.name, "post", p.post, "latest", p.watcher.Latest())
+	s := p.runSeed()
+	p.slog.Info("related.Poster start", "name", p.name, "post", p.post, "latest", p.watcher.Latest(), "seed", s)
 	defer func() {
 		p.slog.Info("related.Poster end", "name", p.name, "latest", p.watcher.Latest())
 	}()
 
+	p.calibration = p.Calibration()
+	if p.calibrate > 0 {
+		p.calibration = p.recalibrate()
+	}
+	p.runIssues(ctx, s)
+	if p.disc != nil {
+		p.runDiscussions(ctx, s)
+	}
+	if p.post && p.updateMin > 0 {
+		p.updateAll(ctx, s)
+	}
+	return nil
+}
+
+// runIssues posts on the issues (and pull requests) that are new
+// since the last run.
+func (p *Poster) runIssues(ctx context.Context, s seed.Seed) {
 	defer p.watcher.Flush()
 	for e := range p.watcher.Recent() {
-		advance, err := p.logPostIssue(ctx, e)
+		advance, err := p.logPostIssue(ctx, e, s, p.timeLimit, true)
 		if err != nil {
+			if errors.Is(err, errVectorSearchFailed) && p.llm != nil && !p.llm.Available() {
+				// The issue is probably not embedded yet because
+				// the LLM is down. Try again later.
+				p.slog.Warn("related.Poster deferred (LLM unavailable)", "issue", e.Issue, "event", e, "error", err)
+				return
+			}
 			p.slog.Error("related.Poster", "issue", e.Issue, "event", e, "error", err)
 			continue
 		}
@@ -197,7 +524,6 @@ func (p *Poster) Run(ctx context.Context) error {
 			p.slog.Info("related.Poster watcher not advanced", "latest", p.watcher.Latest(), "event", e)
 		}
 	}
-	return nil
 }
 
 // Post posts an issue comment for the given GitHub issue.
@@ -215,14 +541,23 @@ func (p *Poster) Post(ctx context.Context, project string, issue int64) error {
 	if e == nil {
 		return fmt.Errorf("related.Poster.Post(project=%s, issue=%d): %w", project, issue, errEventNotFound)
 	}
-	_, err := p.logPostIssue(ctx, e)
+	_, err := p.logPostIssue(ctx, e, p.runSeed(), p.timeLimit, true)
 	return err
 }
 
+// Related returns the documents related to the issue, pull request or
+// discussion with the given URL, as [Poster.Run] would choose them
+// (without the LLM explanations), but without posting anything.
+// It returns ok=false if there is no vector database entry for the URL.
+func (p *Poster) Related(u string) (_ []search.Result, ok bool) {
+	return p.search(u, p.runSeed(), false)
+}
+
 var (
 	errEventNotFound          = errors.New("event not found in database")
 	errVectorSearchFailed     = errors.New("vector search failed")
 	errPostIssueCommentFailed = errors.New("post issue comment failed")
+	errMuted                  = errors.New("issue muted")
 )
 
 // lookupIssueEvent returns the first event for the "/issues" API with
@@ -246,8 +581,12 @@ func lookupIssueEvent(project string, issue int64, gh *github.Client) *github.Ev
 //     because no related documents were found
 //
 // Skipped issues are not considered handled.
-func (p *Poster) logPostIssue(ctx context.Context, e *github.Event) (advance bool, _ error) {
-	if skip, reason := p.skip(e); skip {
+// The seed s breaks ties when choosing related documents.
+// Issues created before the time limit are skipped.
+// If ack is true, new issues in projects enabled with
+// [Poster.EnableAcknowledgement] are acknowledged.
+func (p *Poster) logPostIssue(ctx context.Context, e *github.Event, s seed.Seed, limit time.Time, ack bool) (advance bool, _ error) {
+	if skip, reason := p.skip(e, limit); skip {
 		p.slog.Info("related.Poster skip", "name", p.name, "project",
 			e.Project, "issue", e.Issue, "reason", reason, "event", e)
 		return false, nil
@@ -263,20 +602,26 @@ func (p *Poster) logPostIssue(ctx context.Context, e *github.Event) (advance boo
 		return p.post, nil
 	}
 
-	u := issueURL(e.Project, e.Issue)
+	issue := e.Typed.(*github.Issue)
+	ack = ack && issue.PullRequest == nil && p.acknowledges(e.Project)
+	u := issue.DocID()
 	p.slog.Debug("related.Poster consider", "url", u)
-	results, ok := p.search(u)
+	results, ok := p.search(u, s, true)
 	if !ok {
 		return false, fmt.Errorf("%w url=%s", errVectorSearchFailed, u)
 	}
-	if len(results) == 0 {
+	if p.repo != nil {
+		min := p.repo.Settings(e.Project).MinScore
+		results = slices.DeleteFunc(results, func(r search.Result) bool { return r.Score < min })
+	}
+	if len(results) == 0 && !ack {
 		p.slog.Info("related.Poster found no related documents", "name", p.name, "project", e.Project, "issue", e.Issue, "event", e)
 		// If posting is enabled, an issue with no related documents
 		// should be considered handled, and not looked at again.
 		return p.post, nil
 	}
-	comment := p.comment(results)
-	p.slog.Info("related.Poster post", "name", p.name, "project", e.Project, "issue", e.Issue, "comment", comment)
+	comment := p.comment(e.Project, results, issueKind(issue), ack, p.explain(ctx, u, results))
+	p.slog.Info("related.Poster post", "name", p.name, "project", e.Project, "issue", e.Issue, "seed", s, "comment", comment)
 
 	if !p.post {
 		// Posting is disabled so we did not handle this issue.
@@ -284,8 +629,14 @@ func (p *Poster) logPostIssue(ctx context.Context, e *github.Event) (advance boo
 	}
 
 	act := &action{
-		Issue:   e.Typed.(*github.Issue),
+		Issue:   issue,
 		Changes: &github.IssueCommentChanges{Body: comment},
+		Seed:    s,
+		Related: resultIDs(results),
+		Ack:     ack,
+	}
+	if issue.PullRequest != nil {
+		act.Mode = p.pullMode
 	}
 	p.logAction(p.db, logKey(e), storage.JSON(act), p.requireApproval)
 	return true, nil
@@ -322,8 +673,27 @@ func (p *Poster) runFromActionLog(ctx context.Context, data []byte) ([]byte, err
 }
 
 // runAction runs the given action.
+// Actions on issues muted since they were logged fail with an error
+// wrapping errMuted.
 func (p *Poster) runAction(ctx context.Context, a *action) (*result, error) {
-	_, url, err := p.github.PostIssueComment(ctx, a.Issue, a.Changes)
+	if p.isMuted(a.Issue.Project(), a.Issue.Number) {
+		return nil, fmt.Errorf("%w: %s#%d", errMuted, a.Issue.Project(), a.Issue.Number)
+	}
+	var apiURL, url string
+	var err error
+	switch a.Mode {
+	case PullReview:
+		apiURL, url, err = p.github.PostPullReview(ctx, a.Issue, a.Changes.Body)
+	case PullCheckRun:
+		out := &github.CheckRunOutput{Title: "Related issues and documents", Summary: a.Changes.Body}
+		url, err = p.github.CreateCheckRun(ctx, a.Issue, checkRunName, out)
+	default:
+		if p.composer != nil && a.Issue.PullRequest == nil {
+			p.composer.Add(a.Issue, "related", a.Changes.Body)
+			return &result{}, nil
+		}
+		apiURL, url, err = p.github.PostIssueComment(ctx, a.Issue, a.Changes)
+	}
 	// If GitHub returns an error, add it to the action log for this action.
 	//
 	// Gaby's original behavior was to log the error, not advance the watcher,
@@ -337,43 +707,57 @@ func (p *Poster) runAction(ctx context.Context, a *action) (*result, error) {
 	// It is unclear what the right behavior is, but at least at present all
 	// failed actions are available to the program and could be re-run.
 	if err != nil {
-		return nil, fmt.Errorf("%w issue=%d: %v", errPostIssueCommentFailed, a.Issue.Number, err)
+		return nil, fmt.Errorf("%w issue=%d: %w", errPostIssueCommentFailed, a.Issue.Number, err)
 	}
 
-	return &result{URL: url}, nil
-}
-
-// issueURL returns the URL of the GitHub issue in the given project.
-func issueURL(project string, issue int64) string {
-	return fmt.Sprintf("https://github.com/%s/issues/%d", project, issue)
+	return &result{URL: url, APIURL: apiURL}, nil
 }
 
 // search performs a vector search to find related issues for the given
-// issue URL. It removes any results that don't meet the cutoff in
-// p.scoreCutoff and trims the results list to a max length of p.maxResults.
+// issue URL. It removes any results that don't meet the minimum score
+// for their kind (see [Poster.minScore]) or duplicate a better result
+// (see [Poster.SetDedupCutoff]) and trims the results list to a
+// max length of p.maxResults, using the seed s to break ties (see [seed.Cap]).
+// If record is set and calibration is enabled, it records the scores of
+// the candidate results (see [Poster.EnableCalibration]).
 // It expects that there is already an entry for the url in the vector
 // database, and returns ok=false if there is no such entry.
-func (p *Poster) search(u string) (_ []search.Result, ok bool) {
+func (p *Poster) search(u string, s seed.Seed, record bool) (_ []search.Result, ok bool) {
 	vec, ok := p.vdb.Get(u)
 	if !ok {
 		return nil, false
 	}
 	results := search.Vector(p.vdb, p.docs, &search.VectorRequest{
 		Options: search.Options{
-			Threshold: p.scoreCutoff,
+			Threshold: p.searchThreshold(p.calibration),
 			Limit:     p.maxResults + 5, // add a buffer for filters
 			DenyKind:  []string{search.KindUnknown},
+			Denylist:  p.denylist,
 		},
 		Vector: vec,
 	})
 	// Remove the query itself if present.
-	if len(results) > 0 && results[0].ID == u {
-		results = results[1:]
+	// It is usually first, but documents with identical
+	// embeddings can tie with it.
+	results = slices.DeleteFunc(results, func(r search.Result) bool { return r.ID == u })
+	if record && p.calibrate > 0 {
+		p.recordScores(results)
 	}
-	// Trim length.
-	if len(results) > p.maxResults {
-		results = results[:p.maxResults]
+	results = slices.DeleteFunc(results, func(r search.Result) bool { return r.Score < p.minScore(r.Kind, p.calibration) })
+	results = p.dedup(u, results)
+	if p.reranker != nil {
+		// The reranked order replaces the scores for choosing
+		// the results, so there are no ties to break.
+		results = p.reranker.Rerank(u, results)
+		if len(results) > p.maxResults {
+			results = results[:p.maxResults]
+		}
+		return results, true
 	}
+	// Trim length.
+	results = seed.Cap(s.Rand(u), results, p.maxResults,
+		func(r search.Result) float64 { return r.Score },
+		func(r search.Result) string { return r.ID })
 	return results, true
 }
 
@@ -400,9 +784,34 @@ var relatedGroupTitles = map[relatedContentGroup]string{
 	documentation: "Related Documentation",
 }
 
-// comment returns the comment to post to GitHub for the given related
-// issues.
-func (p *Poster) comment(results []search.Result) string {
+// relatedGroupNames are the names for each related
+// content group, for use in templates (see [TemplateGroup]).
+var relatedGroupNames = map[relatedContentGroup]string{
+	issues:        "issues",
+	changes:       "changes",
+	discussions:   "discussions",
+	documentation: "documentation",
+}
+
+// issueKind returns the kind of the issue: KindIssue or KindPullRequest.
+func issueKind(issue *github.Issue) Kind {
+	if issue.PullRequest != nil {
+		return KindPullRequest
+	}
+	return KindIssue
+}
+
+// comment returns the comment to post to GitHub on content of kind k
+// in the project for the given related documents, using the template
+// for the project and k (see [Poster.SetTemplate]).
+// The comment is plain text if the project is enabled with
+// [Poster.EnablePlainText].
+// If ack is true, the comment begins with an acknowledgement
+// (see [Poster.EnableAcknowledgement]).
+// why holds explanations of the results, keyed by ID; see [Poster.explain].
+func (p *Poster) comment(project string, results []search.Result, k Kind, ack bool, why map[string]string) string {
+	plain := p.plainText[project]
+
 	// Break results into issues, changes, discusssions
 	// and documentation sections.
 	rg := make(map[relatedContentGroup][]search.Result)
@@ -410,51 +819,98 @@ func (p *Poster) comment(results []search.Result) string {
 		switch r.Kind {
 		case search.KindGitHubIssue:
 			rg[issues] = append(rg[issues], r)
-		case search.KindGoGerritChange:
+		case search.KindGoGerritChange, search.KindGitHubPullRequest:
 			rg[changes] = append(rg[changes], r)
 		case search.KindGitHubDiscussion, search.KindGoogleGroupConversation:
 			rg[discussions] = append(rg[discussions], r)
 		default:
 			// KindGoDocumentation, KindGoDevPage, KindGoWiki,
-			// KindGoBlog, KindGoReference
+			// KindGoBlog, KindGoReference, KindGoPackage
 			rg[documentation] = append(rg[documentation], r)
 		}
 	}
 
-	// section generates a comment markdown for a group
-	// of results with a title.
-	section := func(title string, results []search.Result) string {
-		var comment strings.Builder
-		fmt.Fprintf(&comment, "**%s**\n\n", title)
+	// group converts a group of results to a TemplateGroup.
+	group := func(g relatedContentGroup, results []search.Result) *TemplateGroup {
+		tg := &TemplateGroup{Name: relatedGroupNames[g], Title: relatedGroupTitles[g]}
 		for _, r := range results {
-			title := cleanTitle(r.ID)
+			d := &TemplateDocument{URL: r.ID, Title: cleanTitle(r.ID), Score: r.Score, Why: why[r.ID]}
 			if r.Title != "" {
-				title = r.Title
+				d.Title = r.Title
 			}
-			info := ""
 			if issue, err := p.github.LookupIssueURL(r.ID); err == nil {
-				info = fmt.Sprint(" #", issue.Number)
+				d.Info = fmt.Sprint(" #", issue.Number)
 				if issue.ClosedAt != "" {
-					info += " (closed)"
+					d.Info += " (closed)"
 				}
 			}
-			fmt.Fprintf(&comment, " - [%s%s](%s) <!-- score=%.5f -->\n", markdownEscape(title), info, r.ID, r.Score)
+			tg.Docs = append(tg.Docs, d)
+		}
+		return tg
+	}
+
+	// section generates a comment markdown for a group.
+	section := func(g *TemplateGroup) string {
+		var comment strings.Builder
+		if plain {
+			fmt.Fprintf(&comment, "%s:\n\n", g.Title)
+		} else {
+			fmt.Fprintf(&comment, "**%s**\n\n", g.Title)
+		}
+		for _, d := range g.Docs {
+			if plain {
+				fmt.Fprintf(&comment, " - %s%s (%s)\n", d.Title, d.Info, d.URL)
+				if d.Why != "" {
+					fmt.Fprintf(&comment, "   %s\n", d.Why)
+				}
+				continue
+			}
+			fmt.Fprintf(&comment, " - [%s%s](%s) <!-- score=%.5f -->\n", markdownEscape(d.Title), d.Info, d.URL, d.Score)
+			if d.Why != "" {
+				fmt.Fprintf(&comment, "   <br>*%s*\n", markdownEscape(d.Why))
+			}
 		}
 		return comment.String()
 	}
 
+	data := &TemplateData{
+		Project:     project,
+		Kind:        k,
+		Plain:       plain,
+		FeedbackURL: defaultFeedbackURL,
+	}
+	if u := p.feedback[project]; u != "" {
+		data.FeedbackURL = u
+	}
 	var sections []string
-	for _, group := range []relatedContentGroup{issues, changes, documentation, discussions} {
-		res := rg[group]
+	for _, g := range []relatedContentGroup{issues, changes, documentation, discussions} {
+		res := rg[g]
 		if len(res) == 0 {
 			continue
 		}
-		s := section(relatedGroupTitles[group], res)
-		sections = append(sections, s)
+		tg := group(g, res)
+		data.Groups = append(data.Groups, tg)
+		sections = append(sections, section(tg))
+	}
+	data.Sections = strings.Join(sections, "\n")
+	if ack {
+		data.Acknowledgement = p.acknowledgement(project, len(sections) > 0)
+		data.TriageDays = p.ack[project]
+	}
+
+	data.Footer = "\n<sub>(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](" + data.FeedbackURL + ").)</sub>\n"
+	if plain {
+		data.Footer = "\n(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in this discussion: " + data.FeedbackURL + ".)\n"
 	}
 
-	footer := "\n<sub>(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](https://github.com/golang/go/discussions/67901).)</sub>\n"
-	return strings.Join(sections, "\n") + footer
+	var b strings.Builder
+	if err := p.template(project, k).Execute(&b, data); err != nil {
+		// Templates are checked by SetTemplate, but they can
+		// still fail on data they have not seen before.
+		p.slog.Error("related.Poster template", "project", project, "kind", k, "err", err)
+		return data.Acknowledgement + data.Sections + data.Footer
+	}
+	return b.String()
 }
 
 // cleanTitle cleans up document title t to make it more readable
@@ -465,39 +921,75 @@ func cleanTitle(t string) string {
 }
 
 // skip reports whether the event should be skipped and why.
-func (p *Poster) skip(e *github.Event) (_ bool, reason string) {
+// Issues created before the time limit are skipped.
+func (p *Poster) skip(e *github.Event, limit time.Time) (_ bool, reason string) {
 	if !p.projects[e.Project] {
 		return true, fmt.Sprintf("project %s not enabled for this Poster", e.Project)
 	}
 	if e.API != "/issues" {
 		return true, fmt.Sprintf("wrong API %s (expected %s)", e.API, "/issues")
 	}
-	issue := e.Typed.(*github.Issue)
+	if skip, reason := p.skipIssue(e.Typed.(*github.Issue), limit); skip {
+		return true, reason
+	}
+	if p.posted(e) {
+		return true, "already posted"
+	}
+	return false, ""
+}
+
+// skipIssue reports whether the issue should be skipped and why.
+// Issues created before the time limit are skipped.
+func (p *Poster) skipIssue(issue *github.Issue, limit time.Time) (_ bool, reason string) {
 	if issue.State == "closed" {
 		return true, "issue is closed"
 	}
-	if issue.PullRequest != nil {
+	if issue.PullRequest != nil && !p.pulls {
 		return true, "pull request"
 	}
+	if p.markedDuplicate(issue) {
+		return true, "issue is marked as a duplicate"
+	}
+	if p.isMuted(issue.Project(), issue.Number) {
+		return true, "issue is muted"
+	}
+	if p.repo != nil {
+		if label, ok := p.repo.Settings(issue.Project()).OptedOut(issue); ok {
+			return true, "issue has opt-out label " + label
+		}
+	}
 	tm, err := time.Parse(time.RFC3339, issue.CreatedAt)
 	if err != nil {
 		p.slog.Error("related.Poster parse createdat", "CreatedAt", issue.CreatedAt, "err", err)
 		return true, "could not parse createdat"
 	}
-	if tm.Before(p.timeLimit) {
-		return true, fmt.Sprintf("created=%s before time limit=%s", tm, p.timeLimit)
+	if tm.Before(limit) {
+		return true, fmt.Sprintf("created=%s before time limit=%s", tm, limit)
 	}
 	for i, ig := range p.ignores {
 		if ig(issue) {
 			return true, fmt.Sprintf("ignored by function ignores[%d]", i)
 		}
 	}
-	if p.posted(e) {
-		return true, "already posted"
-	}
 	return false, ""
 }
 
+// markedDuplicate reports whether the issue's timeline shows that
+// it has been marked as a duplicate of another issue (and not unmarked since).
+// Such issues need no related documents: they have a better one.
+func (p *Poster) markedDuplicate(issue *github.Issue) bool {
+	dup := false
+	for e := range p.github.Timeline(issue) {
+		switch e.Event {
+		case "marked_as_duplicate":
+			dup = true
+		case "unmarked_as_duplicate":
+			dup = false
+		}
+	}
+	return dup
+}
+
 // posted reports whether the event has already been posted.
 // This should only be necessary for a short time, since the action log
 // is now handling this check.
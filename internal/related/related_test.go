@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"math"
 	"slices"
 	"strings"
 	"testing"
@@ -18,13 +19,20 @@ import (
 
 	"golang.org/x/oscar/internal/actions"
 	"golang.org/x/oscar/internal/diff"
+	"golang.org/x/oscar/internal/discussion"
 	"golang.org/x/oscar/internal/docs"
 	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/entity"
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/mute"
+	"golang.org/x/oscar/internal/repoconfig"
 	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/seed"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/testutil"
+	"rsc.io/ordered"
 )
 
 var ctx = context.Background()
@@ -167,6 +175,16 @@ func TestPost(t *testing.T) {
 		testutil.ExpectLog(t, buf, "advanced watcher", 2) // no change
 	})
 
+	t.Run("duplicate", func(t *testing.T) {
+		p, _, project, _ := newTestPoster(t)
+		tc := p.github.Testing()
+		tc.AddIssueEvent(project, 13, &github.IssueEvent{Event: "marked_as_duplicate"})
+		tc.AddIssueEvent(project, 19, &github.IssueEvent{Event: "marked_as_duplicate"})
+		tc.AddIssueEvent(project, 19, &github.IssueEvent{Event: "unmarked_as_duplicate"})
+		run(p)
+		checkActionLog(t, p.db, map[int64]string{19: post19})
+	})
+
 	t.Run("post-run-async", func(t *testing.T) {
 		p, _, project, _ := newTestPoster(t)
 
@@ -182,6 +200,95 @@ func TestPost(t *testing.T) {
 	})
 }
 
+func TestRelated(t *testing.T) {
+	p, _, project, _ := newTestPoster(t)
+
+	u := entity.Issue(project, 19).DocID()
+	results, ok := p.Related(u)
+	if !ok {
+		t.Fatal("Related: no embedding for issue 19")
+	}
+	if len(results) == 0 {
+		t.Fatal("Related: no results")
+	}
+	for _, r := range results {
+		if r.ID == u {
+			t.Errorf("Related(%s) includes the issue itself", u)
+		}
+	}
+	checkActionLog(t, p.db, nil)
+
+	if _, ok := p.Related(entity.Issue(project, 9999).DocID()); ok {
+		t.Error("Related(unknown issue): ok = true, want false")
+	}
+}
+
+func TestSeed(t *testing.T) {
+	p, _, project, check := newTestPoster(t)
+
+	// Give several issues the same embedding as issue 19,
+	// so that they tie for the maximum number of results.
+	u := entity.Issue(project, 19).DocID()
+	vec, ok := p.vdb.Get(u)
+	if !ok {
+		t.Fatal("no embedding for issue 19")
+	}
+	var tied []string
+	for _, n := range []int64{2, 4, 6, 7} {
+		tied = append(tied, entity.Issue(project, n).DocID())
+		p.vdb.Set(tied[len(tied)-1], vec)
+	}
+	p.SetMaxResults(2)
+	p.SetDedupCutoff(0) // keep the tied results
+
+	ids := func(s seed.Seed) []string {
+		results, ok := p.search(u, s, false)
+		if !ok {
+			t.Fatal("search failed")
+		}
+		var ids []string
+		for _, r := range results {
+			if !slices.Contains(tied, r.ID) {
+				t.Errorf("seed %d: unexpected result %s", s, r.ID)
+			}
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	picks := make(map[string]bool)
+	for s := range seed.Seed(20) {
+		x := ids(s)
+		if y := ids(s); !slices.Equal(x, y) {
+			t.Errorf("seed %d: different results: %v, %v", s, x, y)
+		}
+		picks[fmt.Sprint(x)] = true
+	}
+	if len(picks) < 2 {
+		t.Errorf("20 seeds made only %d different choices", len(picks))
+	}
+
+	// The seed is recorded in the action.
+	p.SetSeed(7)
+	check(p.Post(ctx, project, 19))
+	var n int
+	for e := range actions.ScanAfter(p.slog, p.db, time.Time{}, nil) {
+		var a action
+		check(json.Unmarshal(e.Action, &a))
+		if a.Seed != 7 {
+			t.Errorf("action seed = %d, want 7", a.Seed)
+		}
+		for _, id := range ids(7) {
+			if !strings.Contains(a.Changes.Body, id) {
+				t.Errorf("comment does not mention %s:\n%s", id, a.Changes.Body)
+			}
+		}
+		n++
+	}
+	if n != 1 {
+		t.Errorf("logged %d actions, want 1", n)
+	}
+}
+
 func TestPostError(t *testing.T) {
 	t.Run("event not in DB", func(t *testing.T) {
 		p, _, project, _ := newTestPoster(t)
@@ -199,7 +306,7 @@ func TestPostError(t *testing.T) {
 		// Vector search will fail if there is no embedding
 		// for the issue.
 		id := int64(19)
-		p.vdb.Delete(issueURL(project, id))
+		p.vdb.Delete(entity.Issue(project, id).DocID())
 
 		wantErr := errVectorSearchFailed
 		if err := p.Post(ctx, project, id); !errors.Is(err, wantErr) {
@@ -208,6 +315,89 @@ func TestPostError(t *testing.T) {
 	})
 }
 
+func TestDeferWhenUnavailable(t *testing.T) {
+	p, buf, project, check := newTestPoster(t)
+	run := func() {
+		t.Helper()
+		check(p.Run(ctx))
+		check(actions.Run(ctx, p.slog, p.db))
+	}
+
+	avail := llm.NewAvailability()
+	p.DeferWhenUnavailable(avail)
+
+	// Pretend issue 13 could not be embedded because the LLM is down.
+	u := entity.Issue(project, 13).DocID()
+	vec, ok := p.vdb.Get(u)
+	if !ok {
+		t.Fatalf("no vector for %s", u)
+	}
+	p.vdb.Delete(u)
+	avail.SetFailureThreshold(1)
+	avail.Record(&llm.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"})
+
+	// Run stops at issue 13 without advancing past it.
+	run()
+	checkActionLog(t, p.db, nil)
+	testutil.ExpectLog(t, buf, "deferred", 1)
+
+	// Once the issue is embedded, both issues are handled.
+	p.vdb.Set(u, vec)
+	avail.Record(nil)
+	run()
+	checkActionLog(t, p.db, map[int64]string{13: post13, 19: post19})
+}
+
+func TestSkipMuted(t *testing.T) {
+	p, _, project, check := newTestPoster(t)
+	m := mute.New(p.slog, p.db, p.github)
+	m.EnableProject(project)
+	p.SkipMuted(m)
+
+	// Issue 13 is muted before the Poster runs: no action is logged.
+	p.github.Testing().AddIssueComment(project, 13, &github.IssueComment{Body: mute.DefaultCommand, AuthorAssociation: "MEMBER"})
+	check(m.Run(ctx))
+	check(p.Run(ctx))
+
+	// Issue 19 is muted after its action is logged: the action fails.
+	p.github.Testing().AddIssueComment(project, 19, &github.IssueComment{Body: mute.DefaultCommand, AuthorAssociation: "MEMBER"})
+	check(m.Run(ctx))
+	if err := actions.Run(ctx, p.slog, p.db); !errors.Is(err, errMuted) {
+		t.Fatalf("actions.Run err = %v, want %v", err, errMuted)
+	}
+	if edits := p.github.Testing().Edits(); len(edits) != 0 {
+		t.Errorf("edits on muted issues: %v", edits)
+	}
+}
+
+func TestRepoConfig(t *testing.T) {
+	p, out, project, check := newTestPoster(t)
+	rc := repoconfig.New(p.slog, p.github)
+	check(rc.SetOperator(project, &repoconfig.Settings{OptOutLabels: []string{"NoBot"}}))
+	p.SetRepoConfig(rc)
+
+	// Issues with the operator's opt-out label are skipped.
+	iss, err := github.LookupIssue(p.db, project, 13)
+	check(err)
+	iss.Labels = append(iss.Labels, github.Label{Name: "NoBot"})
+	if skip, reason := p.skipIssue(iss, time.Time{}); !skip || !strings.Contains(reason, "opt-out label NoBot") {
+		t.Errorf("skipIssue(labeled issue) = %v, %q; want opt-out", skip, reason)
+	}
+
+	// The repository's minimum score excludes all the related documents.
+	p.github.Testing().SetRepoConfig(project, []byte("minscore: 0.999\n"))
+	p.post = false
+	check(p.Run(ctx))
+	testutil.ExpectLog(t, out, "related.Poster post", 0)
+
+	// Without the repository's settings, the issues get their posts.
+	p.github.Testing().SetRepoConfig(project, nil)
+	p.EnablePosts()
+	check(p.Run(ctx))
+	check(actions.Run(ctx, p.slog, p.db))
+	checkActionLog(t, p.db, map[int64]string{13: post13, 19: post19})
+}
+
 func TestPostComment(t *testing.T) {
 	lg := testutil.Slogger(t)
 	db := storage.MemDB()
@@ -280,9 +470,36 @@ func TestPostComment(t *testing.T) {
 <sub>(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](https://github.com/golang/go/discussions/67901).)</sub>
 `
 
-	if got := p.comment(results); want != got {
+	if got := p.comment("rsc/markdown", results, KindIssue, false, nil); want != got {
 		t.Errorf("want %s comment; got %s", want, got)
 	}
+
+	wantPlain := `Related Issues:
+
+ - Support Github Emojis (https://github.com/rsc/markdown/issues/1)
+ - allow capital X in task list items (https://github.com/rsc/markdown/issues/2)
+
+Related Code Changes:
+
+ - all: update dependencies (https://go-review.googlesource.com/c/test/+/1#related-content)
+
+Related Documentation:
+
+ - Govulncheck v1.0.0 is released! (https://go.dev/blog/govulncheck)
+ - Go Wiki: Iota (https://go.dev/wiki/Iota)
+ - The Go Programming Language Specification (https://go.dev/ref/spec)
+
+Related Discussions:
+
+ - gabyhelp feedback (https://github.com/golang/go/discussions/67901)
+ - Returning a pointer or value struct. (https://groups.google.com/g/golang-nuts/c/MKgGqer_taI)
+
+(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in this discussion: https://github.com/golang/go/discussions/67901.)
+`
+	p.EnablePlainText("rsc/markdown")
+	if got := p.comment("rsc/markdown", results, KindIssue, false, nil); wantPlain != got {
+		t.Errorf("want %s plain comment; got %s", wantPlain, got)
+	}
 }
 
 func newTestPoster(t *testing.T) (_ *Poster, out *bytes.Buffer, project string, check func(err error)) {
@@ -368,7 +585,7 @@ var post13 = unQUOT(`**Related Issues**
  - [allow capital X in task list items #2 (closed)](https://github.com/rsc/markdown/issues/2) <!-- score=0.90850 -->
  - [build(deps): bump golang.org/x/text from 0.3.6 to 0.3.8 in /rmplay #10](https://github.com/rsc/tmp/issues/10) <!-- score=0.90453 -->
  - [Render reference links in Markdown #14 (closed)](https://github.com/rsc/markdown/issues/14) <!-- score=0.90175 -->
- - [Render reference links in Markdown #15 (closed)](https://github.com/rsc/markdown/issues/15) <!-- score=0.90103 -->
+ - [build(deps): bump golang.org/x/net from 0.0.0-20200320220750-118fecf932d8 to 0.7.0 in /html2md #11](https://github.com/rsc/tmp/issues/11) <!-- score=0.90053 -->
 
 <sub>(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](https://github.com/golang/go/discussions/67901).)</sub>
 `)
@@ -379,14 +596,304 @@ var post19 = unQUOT(`**Related Issues**
  - [Support escaped \QUOT|\QUOT in table cells #9 (closed)](https://github.com/rsc/markdown/issues/9) <!-- score=0.91994 -->
  - [goldmark and markdown diff with h1 inside p #6 (closed)](https://github.com/rsc/markdown/issues/6) <!-- score=0.91813 -->
  - [Render reference links in Markdown #14 (closed)](https://github.com/rsc/markdown/issues/14) <!-- score=0.91513 -->
- - [Render reference links in Markdown #15 (closed)](https://github.com/rsc/markdown/issues/15) <!-- score=0.91487 -->
  - [Empty column heading not recognized in table #7 (closed)](https://github.com/rsc/markdown/issues/7) <!-- score=0.90874 -->
  - [Correctly render reference links in Markdown #13](https://github.com/rsc/markdown/issues/13) <!-- score=0.90867 -->
  - [markdown: fix markdown printing for inline code #12 (closed)](https://github.com/rsc/markdown/issues/12) <!-- score=0.90795 -->
  - [Replace newlines with spaces in alt text #4 (closed)](https://github.com/rsc/markdown/issues/4) <!-- score=0.90278 -->
  - [build(deps): bump golang.org/x/text from 0.3.6 to 0.3.8 in /rmplay #10](https://github.com/rsc/tmp/issues/10) <!-- score=0.90259 -->
+ - [support : in autolinks #3 (closed)](https://github.com/rsc/markdown/issues/3) <!-- score=0.90236 -->
 
 <sub>(Emoji vote if this was helpful or unhelpful; more detailed feedback welcome in [this discussion](https://github.com/golang/go/discussions/67901).)</sub>
 `)
 
+// nearby returns a unit vector with a dot product of 0.97 with
+// the unit vector vec, tilted toward the i'th axis.
+// Vectors tilted toward different axes have a dot product of
+// about 0.94 with each other.
+func nearby(vec llm.Vector, i int) llm.Vector {
+	e := make(llm.Vector, len(vec))
+	e[i%len(e)] = 1
+	d := float32(e.Dot(vec))
+	var norm float64
+	for j := range e {
+		e[j] -= d * vec[j]
+		norm += float64(e[j] * e[j])
+	}
+	const cos = 0.97
+	sin := math.Sqrt(1 - cos*cos)
+	v := make(llm.Vector, len(vec))
+	for j := range v {
+		v[j] = float32(cos*float64(vec[j]) + sin*float64(e[j])/math.Sqrt(norm))
+	}
+	return v
+}
+
 func unQUOT(s string) string { return strings.ReplaceAll(s, "QUOT", "`") }
+
+func TestUpdateExisting(t *testing.T) {
+	p, _, project, check := newTestPoster(t)
+	p.UpdateExisting(2)
+	run := func() {
+		t.Helper()
+		check(p.Run(ctx))
+		check(actions.Run(ctx, p.slog, p.db))
+	}
+	check(p.Post(ctx, project, 19))
+	run()
+	checkActionLog(t, p.db, map[int64]string{13: post13, 19: post19})
+	p.github.Testing().ClearEdits()
+
+	// Nothing new: no updates.
+	run()
+	if n := len(p.github.Testing().Edits()); n != 0 {
+		t.Fatalf("no new documents: %d edits", n)
+	}
+
+	// Add new documents with embeddings close to issue 19's,
+	// but not so close to each other that they are duplicates.
+	vec, ok := p.vdb.Get(entity.Issue(project, 19).DocID())
+	if !ok {
+		t.Fatal("no embedding for issue 19")
+	}
+	addDoc := func(id string, axis int) {
+		p.docs.Add(id, "new doc "+id, "text")
+		p.vdb.Set(id, nearby(vec, axis))
+	}
+
+	// One new document is below the threshold.
+	addDoc("https://go.dev/doc/new1", 0)
+	run()
+	if n := len(p.github.Testing().Edits()); n != 0 {
+		t.Fatalf("one new document: %d edits", n)
+	}
+
+	// Two new documents update the comment.
+	addDoc("https://go.dev/doc/new2", 1)
+	run()
+	got := 0
+	for _, e := range p.github.Testing().Edits() {
+		body := e.IssueCommentChanges.Body
+		if strings.Contains(body, "https://go.dev/doc/new1") && strings.Contains(body, "https://go.dev/doc/new2") {
+			got++
+		}
+	}
+	if got == 0 {
+		t.Fatalf("no update mentions the new documents:\n%v", p.github.Testing().Edits())
+	}
+	n := len(p.github.Testing().Edits())
+
+	// The update is recorded: running again changes nothing.
+	run()
+	if m := len(p.github.Testing().Edits()); m != n {
+		t.Errorf("after update: %d edits, want %d", m, n)
+	}
+}
+
+func TestPullRequestsAndDiscussions(t *testing.T) {
+	p, _, project, check := newTestPoster(t)
+	vec, ok := p.vdb.Get(entity.Issue(project, 19).DocID())
+	if !ok {
+		t.Fatal("no embedding for issue 19")
+	}
+	embed := func(id, title string) {
+		p.docs.Add(id, title, "text")
+		p.vdb.Set(id, vec)
+	}
+	run := func() {
+		t.Helper()
+		check(p.Run(ctx))
+		check(actions.Run(ctx, p.slog, p.db))
+	}
+	now := time.Now().Format(time.RFC3339)
+
+	pr := &github.Issue{Number: 100, Title: "a change", CreatedAt: now, PullRequest: new(struct{})}
+	p.github.Testing().AddIssue(project, pr)
+	embed(pr.DocID(), pr.Title)
+
+	dc := discussion.New(ctx, p.slog, secret.Empty(), p.db)
+	d := &discussion.Discussion{Title: "a question", CreatedAt: now}
+	dc.Testing().AddDiscussion(project, d)
+	embed(d.URL, d.Title)
+
+	newPoster := func(name string) *Poster {
+		p := New(p.slog, p.db, p.github, p.vdb, p.docs, name)
+		p.EnableProject(project)
+		p.SetTimeLimit(time.Time{})
+		p.EnablePosts()
+		return p
+	}
+
+	// Without EnablePullRequests and EnableDiscussions,
+	// only issues are posted on.
+	check(newPoster("issuesonly").Run(ctx))
+	for e := range actions.ScanAfter(p.slog, p.db, time.Time{}, nil) {
+		if e.Kind != p.actionKind || bytes.Equal(e.Key, ordered.Encode(project, pr.Number)) {
+			t.Errorf("issues-only poster logged %s %s", e.Kind, storage.Fmt(e.Key))
+		}
+	}
+	actions.ClearLogForTesting(t, p.db)
+
+	// (A new Poster, to register its actions last.)
+	p = newPoster("all")
+	p.EnablePullRequests()
+	p.EnableDiscussions(dc)
+	check(p.SetTemplate(KindDiscussion, "Maybe these help:\n\n{{.Sections}}"))
+	run()
+
+	var prBody string
+	for _, e := range p.github.Testing().Edits() {
+		if e.Issue == pr.Number {
+			prBody = e.IssueCommentChanges.Body
+		}
+	}
+	if !strings.HasPrefix(prBody, "Issues and changes that may be related to this pull request:") {
+		t.Errorf("pull request comment:\n%s", prBody)
+	}
+
+	edits := dc.Testing().Edits()
+	if len(edits) != 1 {
+		t.Fatalf("got %d discussion comments, want 1", len(edits))
+	}
+	if e := edits[0]; e.URL != d.URL || !strings.HasPrefix(e.Body, "Maybe these help:\n\n**Related") || strings.Contains(e.Body, "Emoji vote") {
+		t.Errorf("discussion comment on %s:\n%s", e.URL, e.Body)
+	}
+
+	// Running again posts nothing new.
+	run()
+	if n := len(dc.Testing().Edits()); n != 1 {
+		t.Errorf("after second run: %d discussion comments, want 1", n)
+	}
+
+	if err := p.SetTemplate(KindIssue, "{{.Missing}}"); err == nil {
+		t.Errorf("SetTemplate with bad field: no error")
+	}
+}
+
+func TestPullMode(t *testing.T) {
+	for _, mode := range []PullMode{PullReview, PullCheckRun} {
+		p, _, project, check := newTestPoster(t)
+		p.SetTimeLimit(time.Time{})
+		p.EnablePosts()
+		p.EnablePullRequests()
+		p.SetPullMode(mode)
+		vec, ok := p.vdb.Get(entity.Issue(project, 19).DocID())
+		if !ok {
+			t.Fatal("no embedding for issue 19")
+		}
+		pr := &github.Issue{Number: 100, Title: "a change", CreatedAt: time.Now().Format(time.RFC3339), PullRequest: new(struct{})}
+		p.github.Testing().AddIssue(project, pr)
+		p.docs.Add(pr.DocID(), pr.Title, "text")
+		p.vdb.Set(pr.DocID(), vec)
+		p.github.Testing().ClearEdits()
+
+		check(p.Run(ctx))
+		check(actions.Run(ctx, p.slog, p.db))
+
+		var found bool
+		for _, e := range p.github.Testing().Edits() {
+			if e.Issue != pr.Number {
+				continue
+			}
+			found = true
+			switch mode {
+			case PullReview:
+				if !strings.HasPrefix(e.Review, "Issues and changes that may be related") {
+					t.Errorf("review: %v", e)
+				}
+			case PullCheckRun:
+				if e.CheckRun == nil || e.CheckRun.Name != checkRunName || !strings.Contains(e.CheckRun.Output.Summary, "Related Issues") {
+					t.Errorf("check run: %v", e)
+				}
+			}
+			if e.IssueCommentChanges != nil {
+				t.Errorf("mode %d posted a comment: %v", mode, e)
+			}
+		}
+		if !found {
+			t.Errorf("mode %d: nothing posted on pull request", mode)
+		}
+	}
+}
+
+func TestProjectTemplate(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	p := New(lg, db, gh, nil, nil, t.Name())
+
+	results := []search.Result{
+		{
+			Kind:         search.KindGitHubIssue,
+			VectorResult: storage.VectorResult{ID: "https://github.com/example/proj/issues/1", Score: 0.9},
+			Title:        "a bug",
+		},
+		{
+			Kind:         search.KindGoBlog,
+			VectorResult: storage.VectorResult{ID: "https://go.dev/blog/govulncheck", Score: 0.8},
+			Title:        "Govulncheck",
+		},
+	}
+
+	const text = `Peut-être lié :
+{{range .Groups}}{{if eq .Name "issues"}}Tickets{{else}}Docs{{end}} :
+{{range .Docs}} - {{.Title}} {{.URL}} {{printf "%.1f" .Score}}
+{{end}}{{end}}Avis : {{.FeedbackURL}} ({{.Project}})
+`
+	if err := p.SetProjectTemplate("example/proj", KindIssue, text); err != nil {
+		t.Fatal(err)
+	}
+	p.SetFeedbackURL("example/proj", "https://example.com/feedback")
+
+	want := `Peut-être lié :
+Tickets :
+ - a bug https://github.com/example/proj/issues/1 0.9
+Docs :
+ - Govulncheck https://go.dev/blog/govulncheck 0.8
+Avis : https://example.com/feedback (example/proj)
+`
+	if got := p.comment("example/proj", results, KindIssue, false, nil); got != want {
+		t.Errorf("project comment:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Other projects and kinds use the defaults.
+	got := p.comment("other/proj", results, KindIssue, false, nil)
+	if !strings.HasPrefix(got, "**Related Issues**") || !strings.Contains(got, defaultFeedbackURL) {
+		t.Errorf("other project comment:\n%s", got)
+	}
+	got = p.comment("example/proj", results, KindPullRequest, false, nil)
+	if !strings.HasPrefix(got, "Issues and changes that may be related") || !strings.Contains(got, "https://example.com/feedback") {
+		t.Errorf("pull request comment:\n%s", got)
+	}
+
+	if err := p.SetProjectTemplate("example/proj", KindIssue, "{{.Nope}}"); err == nil {
+		t.Errorf("SetProjectTemplate with bad field: no error")
+	}
+}
+
+func TestDenylist(t *testing.T) {
+	p, _, project, check := newTestPoster(t)
+	check(p.Post(ctx, project, 19))
+	check(actions.Run(ctx, p.slog, p.db))
+	posted, _ := Posted(p.db, project, 19)
+	if len(posted) == 0 {
+		t.Fatal("no related documents posted")
+	}
+
+	p, _, project, check = newTestPoster(t)
+	d := search.NewDenylist(p.slog, p.db)
+	if err := d.Suppress(posted[0], "alice", "misleading"); err != nil {
+		t.Fatal(err)
+	}
+	p.SetDenylist(d)
+	check(p.Post(ctx, project, 19))
+	check(actions.Run(ctx, p.slog, p.db))
+	got, _ := Posted(p.db, project, 19)
+	if slices.Contains(got, posted[0]) {
+		t.Errorf("Posted = %v, includes suppressed %s", got, posted[0])
+	}
+	for _, id := range posted[1:] {
+		if !slices.Contains(got, id) {
+			t.Errorf("Posted = %v, missing unsuppressed %s", got, id)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"encoding/json"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// A Reranker reorders the candidate related documents of a query
+// document, such as a learned ranking model (see package learnrank).
+type Reranker interface {
+	// Rerank returns the results reordered by their relevance
+	// to the document with the given ID, most relevant first.
+	// It must not add results, nor change their scores.
+	Rerank(query string, results []search.Result) []search.Result
+}
+
+// SetReranker sets the reranker that orders the candidate related
+// documents before they are capped at the maximum number of results
+// (see [Poster.SetMaxResults]). The reranker sees the candidates that
+// pass the score cutoffs and deduplication, plus a few extra.
+// By default, the candidates are ordered by decreasing score.
+func (p *Poster) SetReranker(r Reranker) {
+	p.reranker = r
+}
+
+// Posted returns the IDs of the related documents that a Poster logged
+// for posting on the issue, and whether it logged a comment at all.
+// The IDs are those of the first comment, not of later updates.
+func Posted(db storage.DB, project string, issue int64) ([]string, bool) {
+	e, ok := actions.Get(db, actionKind, ordered.Encode(project, issue))
+	if !ok {
+		return nil, false
+	}
+	var a action
+	if err := json.Unmarshal(e.Action, &a); err != nil {
+		// unreachable unless database corruption
+		db.Panic("related.Posted: cannot unmarshal", "err", err)
+	}
+	return a.Related, true
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/search"
+)
+
+// reverser is a Reranker that reverses the candidates.
+type reverser struct {
+	query      string
+	candidates []search.Result
+}
+
+func (r *reverser) Rerank(query string, results []search.Result) []search.Result {
+	r.query, r.candidates = query, results
+	results = slices.Clone(results)
+	slices.Reverse(results)
+	return results
+}
+
+func TestReranker(t *testing.T) {
+	p, _, project, check := newTestPoster(t)
+	if _, ok := Posted(p.db, project, 19); ok {
+		t.Fatal("Posted before posting = true")
+	}
+
+	rr := new(reverser)
+	p.SetReranker(rr)
+	p.SetMaxResults(2)
+	check(p.Post(ctx, project, 19))
+	check(actions.Run(ctx, p.slog, p.db))
+
+	if want := "https://github.com/rsc/markdown/issues/19"; rr.query != want {
+		t.Errorf("reranked query = %q, want %q", rr.query, want)
+	}
+	// The reranker sees more candidates than are posted,
+	// and its first ones are posted.
+	n := len(rr.candidates)
+	if n <= 2 {
+		t.Fatalf("reranker saw %d candidates, want more than 2", n)
+	}
+	got, ok := Posted(p.db, project, 19)
+	if !ok {
+		t.Fatal("Posted after posting = false")
+	}
+	want := []string{rr.candidates[n-1].ID, rr.candidates[n-2].ID}
+	if !slices.Equal(got, want) {
+		t.Errorf("Posted = %v, want %v", got, want)
+	}
+}
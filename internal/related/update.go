@@ -0,0 +1,201 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/seed"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// An update has all the information needed to edit
+// a posted comment with new related documents.
+type update struct {
+	Comment *github.IssueComment // only URL and HTMLURL are set
+	Changes *github.IssueCommentChanges
+	Seed    seed.Seed // seed used to choose the related documents
+	Related []string  // IDs of the related documents in the new comment
+}
+
+// updateAll logs updates of the comments posted by the Poster
+// (see [Poster.UpdateExisting]).
+// Problems are logged, not returned: a comment that cannot be
+// updated is not a reason to stop posting.
+func (p *Poster) updateAll(ctx context.Context, s seed.Seed) {
+	for project := range p.projects {
+		start := ordered.Encode(p.actionKind, project)
+		end := ordered.Encode(p.actionKind, project, ordered.Inf)
+		for e := range actions.Scan(p.db, start, end) {
+			if !e.IsDone() || e.Error != "" {
+				continue
+			}
+			var a action
+			var res result
+			if err := json.Unmarshal(e.Action, &a); err != nil {
+				p.slog.Error("related.Poster update: action decode", "key", storage.Fmt(e.Key), "err", err)
+				continue
+			}
+			if a.Mode != PullComment {
+				continue // only comments can be updated
+			}
+			if err := json.Unmarshal(e.Result, &res); err != nil {
+				p.slog.Error("related.Poster update: result decode", "key", storage.Fmt(e.Key), "err", err)
+				continue
+			}
+			if res.URL == "" {
+				continue // added to a combined comment; see SetComposer
+			}
+			if err := p.maybeUpdate(ctx, &a, &res, s); err != nil {
+				p.slog.Error("related.Poster update", "project", project, "issue", a.Issue.Number, "err", err)
+			}
+		}
+	}
+}
+
+// maybeUpdate logs an update of the comment posted by action a,
+// with result res, if enough new related documents have appeared.
+func (p *Poster) maybeUpdate(ctx context.Context, a *action, res *result, s seed.Seed) error {
+	project, num := a.Issue.Project(), a.Issue.Number
+	issue, err := github.LookupIssue(p.db, project, num)
+	if err != nil {
+		return err
+	}
+	if skip, reason := p.skipIssue(issue, p.timeLimit); skip {
+		p.slog.Debug("related.Poster update skip", "project", project, "issue", num, "reason", reason)
+		return nil
+	}
+
+	// Find the comment's current contents: the last update, if any.
+	body, related := a.Changes.Body, a.Related
+	n := int64(0)
+	start := ordered.Encode(p.updateKind, project, num)
+	end := ordered.Encode(p.updateKind, project, num, ordered.Inf)
+	for e := range actions.Scan(p.db, start, end) {
+		n++
+		if !e.IsDone() {
+			return nil // update pending
+		}
+		if e.Error != "" {
+			continue
+		}
+		var u update
+		if err := json.Unmarshal(e.Action, &u); err != nil {
+			return err
+		}
+		body, related = u.Changes.Body, u.Related
+	}
+	if related == nil {
+		// Posted before related documents were recorded.
+		return nil
+	}
+
+	comment, err := postedComment(res)
+	if err != nil {
+		return err
+	}
+	if c, err := p.github.LookupIssueCommentURL(comment.HTMLURL); err == nil &&
+		strings.TrimSpace(c.Body) != strings.TrimSpace(body) {
+		p.slog.Info("related.Poster update skip: comment edited", "project", project, "issue", num, "comment", comment.HTMLURL)
+		return nil
+	}
+
+	u := issue.DocID()
+	results, ok := p.search(u, s, false)
+	if !ok {
+		return fmt.Errorf("%w url=%s", errVectorSearchFailed, u)
+	}
+	added := 0
+	for _, id := range resultIDs(results) {
+		if !slices.Contains(related, id) {
+			added++
+		}
+	}
+	if added < p.updateMin {
+		return nil
+	}
+	newBody := p.comment(project, results, issueKind(issue), a.Ack, p.explain(ctx, u, results))
+	if newBody == body {
+		return nil
+	}
+	p.slog.Info("related.Poster update", "name", p.name, "project", project, "issue", num, "added", added, "seed", s, "comment", newBody)
+	up := &update{
+		Comment: comment,
+		Changes: &github.IssueCommentChanges{Body: newBody},
+		Seed:    s,
+		Related: resultIDs(results),
+	}
+	p.logUpdate(p.db, ordered.Encode(project, num, n+1), storage.JSON(up), p.requireApproval)
+	return nil
+}
+
+// postedComment returns the comment posted with result res.
+func postedComment(res *result) (*github.IssueComment, error) {
+	if res.APIURL != "" {
+		return &github.IssueComment{URL: res.APIURL, HTMLURL: res.URL}, nil
+	}
+	// Posted before API URLs were recorded.
+	project, issue, comment, err := github.ParseIssueCommentURL(res.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &github.IssueComment{
+		URL:     fmt.Sprintf("https://api.github.com/repos/%s/issues/comments/%d", project, comment),
+		HTMLURL: fmt.Sprintf("https://github.com/%s/issues/%d#issuecomment-%d", project, issue, comment),
+	}, nil
+}
+
+// resultIDs returns the IDs of the results.
+func resultIDs(results []search.Result) []string {
+	ids := []string{}
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+type updater struct {
+	p *Poster
+}
+
+func (ur *updater) Run(ctx context.Context, data []byte) ([]byte, error) {
+	var u update
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, err
+	}
+	if project, num, _, err := github.ParseIssueCommentURL(u.Comment.HTMLURL); err == nil && ur.p.isMuted(project, num) {
+		return nil, fmt.Errorf("related.Poster update %s: %w", u.Comment.HTMLURL, errMuted)
+	}
+	if err := ur.p.github.EditIssueComment(ctx, u.Comment, u.Changes); err != nil {
+		return nil, fmt.Errorf("related.Poster update %s: %w", u.Comment.HTMLURL, err)
+	}
+	return storage.JSON(&result{URL: u.Comment.HTMLURL, APIURL: u.Comment.URL}), nil
+}
+
+// Target implements [actions.Coalescer.Target].
+// Successive updates of the same comment are coalesced.
+func (ur *updater) Target(data []byte) string {
+	var u update
+	if err := json.Unmarshal(data, &u); err != nil {
+		return ""
+	}
+	return u.Comment.URL
+}
+
+func (ur *updater) ForDisplay(data []byte) string {
+	var u update
+	if err := json.Unmarshal(data, &u); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	return u.Comment.HTMLURL + "\n" + u.Changes.Body
+}
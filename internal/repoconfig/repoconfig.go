@@ -0,0 +1,190 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package repoconfig lets the maintainers of a GitHub repository tune
+// the bots' behavior in their repository, with a settings file,
+// .oscar.yaml ([github.RepoConfigFile]), in the root of its default branch.
+// For example:
+//
+//	# Bots do not post on issues with any of these labels.
+//	optoutlabels: [Security, NoBot]
+//	# Related documents must score at least this much (from 0 to 1).
+//	minscore: 0.85
+//	# Language of LLM-generated overviews, as a BCP 47 tag.
+//	language: ja
+//
+// The file is synced with the repository's issues
+// (see [github.Client.EnableRepoConfig]).
+// A [Config] merges its [Settings] with the operator's.
+//
+// # Precedence
+//
+// A repository's settings can make the bots quieter than the
+// operator's settings do, but never louder:
+//
+//   - the opt-out labels are those of both the operator and the repository;
+//   - the minimum score is the higher of the two;
+//   - the language is the repository's, if set, and otherwise the operator's.
+//
+// Which bots run on a repository, and with which features, remains the
+// operator's choice. A settings file that is not valid (for example, one
+// with an unknown field) is ignored as a whole, leaving the operator's
+// settings, and the error is logged once for each version of the file.
+//
+// Unlike the mute label (see package mute), an opt-out label only
+// applies while the issue has it.
+package repoconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings are the bots' settings for a repository.
+type Settings struct {
+	OptOutLabels []string `yaml:"optoutlabels"` // bots do not post on issues with any of these labels
+	MinScore     float64  `yaml:"minscore"`     // minimum score of related documents; 0 for the bot's own
+	Language     string   `yaml:"language"`     // BCP 47 tag of the language of overviews; "" for the prompt's
+}
+
+// Parse parses the contents of a settings file.
+// Unknown fields are errors.
+func Parse(data []byte) (*Settings, error) {
+	s := new(Settings)
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(s); err != nil && err != io.EOF { // EOF: no settings
+		return nil, fmt.Errorf("repoconfig: %w", err)
+	}
+	if err := s.check(); err != nil {
+		return nil, fmt.Errorf("repoconfig: %w", err)
+	}
+	return s, nil
+}
+
+// check checks that s is valid, and canonicalizes its language tag.
+func (s *Settings) check() error {
+	if s.MinScore < 0 || s.MinScore > 1 {
+		return fmt.Errorf("minscore %g not between 0 and 1", s.MinScore)
+	}
+	if s.Language != "" {
+		tag, err := language.Parse(s.Language)
+		if err != nil {
+			return fmt.Errorf("language %q: want a BCP 47 tag such as ja or pt-BR", s.Language)
+		}
+		s.Language = tag.String()
+	}
+	return nil
+}
+
+// Merge returns the settings that result from the operator's settings
+// and a repository's settings (see the package doc for the precedence rules).
+// Either may be nil.
+func Merge(operator, repo *Settings) *Settings {
+	s := new(Settings)
+	for _, x := range []*Settings{operator, repo} {
+		if x == nil {
+			continue
+		}
+		for _, l := range x.OptOutLabels {
+			if !slices.Contains(s.OptOutLabels, l) {
+				s.OptOutLabels = append(s.OptOutLabels, l)
+			}
+		}
+		s.MinScore = max(s.MinScore, x.MinScore)
+		if x.Language != "" {
+			s.Language = x.Language
+		}
+	}
+	return s
+}
+
+// OptedOut reports whether the issue has one of the opt-out labels,
+// and returns the first such label.
+func (s *Settings) OptedOut(iss *github.Issue) (label string, ok bool) {
+	for _, l := range iss.Labels {
+		if slices.Contains(s.OptOutLabels, l.Name) {
+			return l.Name, true
+		}
+	}
+	return "", false
+}
+
+// A Config provides the merged settings of repositories.
+type Config struct {
+	slog     *slog.Logger
+	gh       *github.Client
+	operator map[string]*Settings // by project; see SetOperator
+
+	mu     sync.Mutex
+	parsed map[string]*parsed // by project; the last version of each settings file seen
+}
+
+// parsed is a parsed version of a settings file.
+type parsed struct {
+	data string
+	s    *Settings
+	err  error
+}
+
+// New returns a new Config, which reads the repositories'
+// settings files synced by gh.
+func New(lg *slog.Logger, gh *github.Client) *Config {
+	return &Config{
+		slog:     lg,
+		gh:       gh,
+		operator: make(map[string]*Settings),
+		parsed:   make(map[string]*parsed),
+	}
+}
+
+// SetOperator sets the operator's settings for the project,
+// to be merged with the project's own (see [Config.Settings]).
+// It returns an error if the settings are not valid.
+func (c *Config) SetOperator(project string, s *Settings) error {
+	s = Merge(s, nil) // copy
+	if err := s.check(); err != nil {
+		return fmt.Errorf("repoconfig: %s: %w", project, err)
+	}
+	c.operator[project] = s
+	return nil
+}
+
+// Repo returns the settings in the project's settings file,
+// or nil if it has none. It returns an error if the file is not valid.
+func (c *Config) Repo(project string) (*Settings, error) {
+	data, ok := c.gh.RepoConfig(project)
+	if !ok {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p := c.parsed[project]; p != nil && p.data == string(data) {
+		return p.s, p.err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		c.slog.Warn("repoconfig invalid settings file", "project", project, "err", err)
+	}
+	c.parsed[project] = &parsed{data: string(data), s: s, err: err}
+	return s, err
+}
+
+// Settings returns the project's settings: the operator's settings
+// merged with those in the project's settings file, if it is valid.
+func (c *Config) Settings(project string) *Settings {
+	repo, err := c.Repo(project)
+	if err != nil {
+		repo = nil
+	}
+	return Merge(c.operator[project], repo)
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repoconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte("# settings\noptoutlabels: [Security]\nminscore: 0.9\nlanguage: pt-br\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Settings{OptOutLabels: []string{"Security"}, MinScore: 0.9, Language: "pt-BR"}
+	if diff := cmp.Diff(want, s); diff != "" {
+		t.Errorf("Parse mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, data := range []string{"", "# nothing yet\n"} {
+		if s, err := Parse([]byte(data)); err != nil || !cmp.Equal(s, &Settings{}) {
+			t.Errorf("Parse(%q) = %+v, %v; want empty settings", data, s, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		data string
+		err  string
+	}{
+		{"minscore: 2", "between 0 and 1"},
+		{"language: not a language", "BCP 47"},
+		{"optout: [x]", "not found"},
+		{"minscore: high", "cannot unmarshal"},
+	} {
+		if _, err := Parse([]byte(tc.data)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Parse(%q): error %v, want %q", tc.data, err, tc.err)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	const project = "golang/go"
+	gh := github.New(testutil.Slogger(t), storage.MemDB(), nil, nil)
+	c := New(testutil.Slogger(t), gh)
+	if err := c.SetOperator(project, &Settings{OptOutLabels: []string{"NoBot"}, MinScore: 0.8, Language: "en"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetOperator(project, &Settings{Language: "?"}); err == nil {
+		t.Errorf("SetOperator with invalid language succeeded")
+	}
+
+	check := func(want *Settings) {
+		t.Helper()
+		if diff := cmp.Diff(want, c.Settings(project)); diff != "" {
+			t.Errorf("Settings mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	// Without a settings file, the operator's settings apply.
+	check(&Settings{OptOutLabels: []string{"NoBot"}, MinScore: 0.8, Language: "en"})
+	if s, err := c.Repo(project); s != nil || err != nil {
+		t.Errorf("Repo = %v, %v; want nil, nil", s, err)
+	}
+
+	// A repository can add opt-out labels, raise the minimum score
+	// and choose the language.
+	gh.Testing().SetRepoConfig(project, []byte("optoutlabels: [Security, NoBot]\nminscore: 0.9\nlanguage: ja\n"))
+	check(&Settings{OptOutLabels: []string{"NoBot", "Security"}, MinScore: 0.9, Language: "ja"})
+
+	// It cannot lower the minimum score.
+	gh.Testing().SetRepoConfig(project, []byte("minscore: 0.5\n"))
+	check(&Settings{OptOutLabels: []string{"NoBot"}, MinScore: 0.8, Language: "en"})
+
+	// An invalid file is ignored.
+	gh.Testing().SetRepoConfig(project, []byte("minscore: 0.9\nlanguages: ja\n"))
+	check(&Settings{OptOutLabels: []string{"NoBot"}, MinScore: 0.8, Language: "en"})
+	if _, err := c.Repo(project); err == nil {
+		t.Errorf("Repo with invalid file succeeded")
+	}
+
+	s := c.Settings(project)
+	for _, tc := range []struct {
+		labels []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"NeedsFix"}, ""},
+		{[]string{"NeedsFix", "NoBot"}, "NoBot"},
+	} {
+		iss := &github.Issue{}
+		for _, l := range tc.labels {
+			iss.Labels = append(iss.Labels, github.Label{Name: l})
+		}
+		if label, ok := s.OptedOut(iss); label != tc.want || ok != (tc.want != "") {
+			t.Errorf("OptedOut(%v) = %q, %v; want %q", tc.labels, label, ok, tc.want)
+		}
+	}
+}
@@ -8,7 +8,6 @@ package repro
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"iter"
 	"log/slog"
@@ -93,14 +92,9 @@ func CheckReproduction(ctx context.Context, lg *slog.Logger, db storage.DB, cgen
 		return "", err
 	}
 
-	jsonRes, err := cgen.GenerateContent(ctx, reproSchema, []llm.Part{llm.Text(sb.String())})
-	if err != nil {
-		return "", err
-	}
-
 	var res reproResponse
-	if err := json.Unmarshal([]byte(jsonRes), &res); err != nil {
-		return "", fmt.Errorf("unmarshaling %q: %w", jsonRes, err)
+	if err := llm.GenerateJSON(ctx, cgen, reproSchema, &res, []llm.Part{llm.Text(sb.String())}); err != nil {
+		return "", err
 	}
 
 	if res.Repro == "" || res.Repro == "unknown" {
@@ -208,6 +202,7 @@ var reproSchema = &llm.Schema{
 			Description: `A Go release in which the test passes, or "unknown" if not known`,
 		},
 	},
+	Required: []string{"Repro", "FailRelease", "PassRelease"},
 }
 
 // TODO(iant): copied from ../labels/labels.go.
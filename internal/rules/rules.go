@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/compose"
 	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/labels"
 	"golang.org/x/oscar/internal/llm"
@@ -34,10 +35,12 @@ type Poster struct {
 	llm       llm.ContentGenerator
 	github    *github.Client
 	projects  map[string]bool
+	plainText map[string]bool // projects to post plain-text comments in
 	watcher   *timed.Watcher[*github.Event]
 	name      string
 	timeLimit time.Time
 	post      bool
+	composer  *compose.Composer // if non-nil, add comments to it; see SetComposer
 	// For the action log.
 	requireApproval bool
 	actionKind      string
@@ -73,6 +76,16 @@ func (p *Poster) EnableProject(project string) {
 	p.projects[project] = true
 }
 
+// EnablePlainText configures the Poster to post plain-text comments
+// (see [github.PlainText]) on issues in the given GitHub project,
+// so that they read well with a screen reader.
+func (p *Poster) EnablePlainText(project string) {
+	if p.plainText == nil {
+		p.plainText = make(map[string]bool)
+	}
+	p.plainText[project] = true
+}
+
 // EnablePosts enables the Poster to post to GitHub.
 // If EnablePosts has not been called, [Poster.Run] logs what it would post but does not post the messages.
 // See also [Poster.EnableProject], which must also be called to set the projects being considered.
@@ -85,6 +98,13 @@ func (p *Poster) RequireApproval() {
 	p.requireApproval = true
 }
 
+// SetComposer configures the Poster to add its comments on issues
+// to c, which combines them with other bots' comments on the same
+// issue, instead of posting them itself.
+func (p *Poster) SetComposer(c *compose.Composer) {
+	p.composer = c
+}
+
 const defaultTooOld = 48 * time.Hour
 
 func (p *Poster) Run(ctx context.Context) error {
@@ -147,9 +167,13 @@ func (p *Poster) logPostIssue(ctx context.Context, e *github.Event) (advance boo
 		p.slog.Info("no rule violations for", "issue", i.Number)
 		return true, nil
 	}
+	body := r.Response
+	if p.plainText[e.Project] {
+		body = github.PlainText(body)
+	}
 	act := &action{
 		Issue:   i,
-		Changes: &github.IssueCommentChanges{Body: r.Response},
+		Changes: &github.IssueCommentChanges{Body: body},
 	}
 	p.slog.Info("queueing response for", "issue", i.Number, "response", r.Response)
 	p.logAction(p.db, logKey(e), storage.JSON(act), p.requireApproval)
@@ -213,6 +237,10 @@ type result struct {
 
 // runAction runs the given action.
 func (p *Poster) runAction(ctx context.Context, a *action) (*result, error) {
+	if p.composer != nil {
+		p.composer.Add(a.Issue, "rules", a.Changes.Body)
+		return &result{}, nil
+	}
 	_, url, err := p.github.PostIssueComment(ctx, a.Issue, a.Changes)
 	// If GitHub returns an error, add it to the action log for this action.
 	//
@@ -227,7 +255,7 @@ func (p *Poster) runAction(ctx context.Context, a *action) (*result, error) {
 	// It is unclear what the right behavior is, but at least at present all
 	// failed actions are available to the program and could be re-run.
 	if err != nil {
-		return nil, fmt.Errorf("%w issue=%d: %v", errPostIssueCommentFailed, a.Issue.Number, err)
+		return nil, fmt.Errorf("%w issue=%d: %w", errPostIssueCommentFailed, a.Issue.Number, err)
 	}
 
 	return &result{URL: url}, nil
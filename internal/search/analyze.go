@@ -18,15 +18,26 @@ type Analysis struct {
 	llmapp.RelatedAnalysis
 }
 
+// AnalyzeOptions are the options for [Analyze].
+type AnalyzeOptions struct {
+	// Sources of related documents to keep (see [Source]);
+	// empty means keep documents from all sources.
+	Sources []string
+}
+
 // Analyze returns an LLM-generated analysis of a document with respect to its related documents.
 // id is the ID of the main document, which must be present in both the docs corpus and the vector db.
-// Analyze finds related documents using vector search (see [Vector]) with fixed options.
-func Analyze(ctx context.Context, lc *llmapp.Client, vdb storage.VectorDB, dc *docs.Corpus, id string) (*Analysis, error) {
+// Analyze finds related documents using vector search (see [Vector]) with fixed options,
+// keeping only documents allowed by opts. A nil opts keeps all documents.
+func Analyze(ctx context.Context, lc *llmapp.Client, vdb storage.VectorDB, dc *docs.Corpus, id string, opts *AnalyzeOptions) (*Analysis, error) {
 	doc, ok := llmDoc(dc, "main", id)
 	if !ok {
 		return nil, fmt.Errorf("search.Analyze: main doc %q not in docs corpus", id)
 	}
-	rs, err := searchRelated(vdb, dc, id)
+	if opts == nil {
+		opts = &AnalyzeOptions{}
+	}
+	rs, err := searchRelated(vdb, dc, id, opts.Sources)
 	if err != nil {
 		return nil, err
 	}
@@ -49,16 +60,27 @@ func Analyze(ctx context.Context, lc *llmapp.Client, vdb storage.VectorDB, dc *d
 
 var maxResults = 5
 
+// sourcesBuffer is the factor by which to increase the number of
+// vector search results when filtering by source, as many of the
+// nearest neighbors may come from other sources.
+const sourcesBuffer = 10
+
 // searchRelated finds up to [maxResults] documents related to the document
 // identified by id in vdb.
-func searchRelated(vdb storage.VectorDB, dc *docs.Corpus, id string) ([]Result, error) {
+// If sources is non-empty, only documents from those sources are kept.
+func searchRelated(vdb storage.VectorDB, dc *docs.Corpus, id string, sources []string) ([]Result, error) {
 	v, ok := vdb.Get(id)
 	if !ok {
 		return nil, fmt.Errorf("search: main doc %q not in vector db", id)
 	}
+	limit := maxResults + 1 // buffer for self
+	if len(sources) > 0 {
+		limit *= sourcesBuffer
+	}
 	rs := Vector(vdb, dc, &VectorRequest{
 		Options: Options{
-			Limit: maxResults + 1, // buffer for self
+			Limit:   limit,
+			Sources: sources,
 		},
 		Vector: v,
 	})
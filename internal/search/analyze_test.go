@@ -42,7 +42,7 @@ func TestAnalyze(t *testing.T) {
 	// Add the documents to vdb.
 	testutil.Check(t, embeddocs.Sync(ctx, lg, vdb, llm.QuoteEmbedder(), dc))
 
-	got, err := Analyze(ctx, lc, vdb, dc, id)
+	got, err := Analyze(ctx, lc, vdb, dc, id, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -75,3 +75,38 @@ func TestAnalyze(t *testing.T) {
 		t.Errorf("Analyze() mismatch (-got +want):\n%s", cmp.Diff(got, want))
 	}
 }
+
+func TestSearchRelatedSources(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	vdb := storage.MemVectorDB(db, lg, "test")
+	dc := docs.New(lg, db)
+
+	id := "https://github.com/a/b/issues/1"
+	dc.Add(id, "title", "text")
+	dc.Add("https://github.com/a/b/issues/2", "title2", "text2")
+	dc.Add("https://github.com/c/d/issues/3", "title3", "text3")
+	dc.Add("https://go.dev/doc/x", "title4", "text4")
+	testutil.Check(t, embeddocs.Sync(ctx, lg, vdb, llm.QuoteEmbedder(), dc))
+
+	ids := func(sources []string) []string {
+		rs, err := searchRelated(vdb, dc, id, sources)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, r := range rs {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	if got := ids(nil); len(got) != 3 {
+		t.Errorf("searchRelated(all) = %v, want 3 results", got)
+	}
+	want := []string{"https://github.com/a/b/issues/2"}
+	if diff := cmp.Diff(want, ids([]string{"a/b"})); diff != "" {
+		t.Errorf("searchRelated(a/b) mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A Cursor is the position of a result in the order of nearest
+// neighbors (highest score first, ties broken by ID),
+// used to resume a search just after that result (see [Options.After]).
+//
+// Because a Cursor records a position rather than a count of
+// results, pages stay consistent when documents are added to
+// or removed from the vector database between requests.
+//
+// The text form of a Cursor, used in URLs and JSON, is opaque.
+type Cursor struct {
+	Score float64
+	ID    string
+}
+
+// cursorOf returns the cursor of the result r.
+func cursorOf(r storage.VectorResult) *Cursor {
+	return &Cursor{Score: r.Score, ID: r.ID}
+}
+
+// before reports whether the result r comes before
+// (or at) the position c, so that a search resuming at c skips it.
+func (c *Cursor) before(r storage.VectorResult) bool {
+	return r.Score > c.Score || r.Score == c.Score && r.ID >= c.ID
+}
+
+// String returns the text form of c.
+func (c *Cursor) String() string {
+	b, _ := c.MarshalText()
+	return string(b)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (c *Cursor) MarshalText() ([]byte, error) {
+	s := strconv.FormatFloat(c.Score, 'g', -1, 64) + " " + c.ID
+	return []byte(base64.RawURLEncoding.EncodeToString([]byte(s))), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (c *Cursor) UnmarshalText(text []byte) error {
+	errBad := errors.New("invalid cursor")
+	data, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return errBad
+	}
+	score, id, ok := strings.Cut(string(data), " ")
+	if !ok {
+		return errBad
+	}
+	c.Score, err = strconv.ParseFloat(score, 64)
+	if err != nil {
+		return errBad
+	}
+	c.ID = id
+	return nil
+}
+
+// ParseCursor parses the text form of a cursor.
+func ParseCursor(s string) (*Cursor, error) {
+	c := new(Cursor)
+	if err := c.UnmarshalText([]byte(s)); err != nil {
+		return nil, fmt.Errorf("%w %q", err, s)
+	}
+	return c, nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// A Denylist is a list of documents that are suppressed from
+// search results, such as a misleading old issue that keeps matching.
+// Suppressing and unsuppressing documents is recorded in an audit log.
+//
+// Pass a Denylist in [Options.Denylist] to remove its documents
+// from the results of [Query] and [Vector].
+type Denylist struct {
+	slog *slog.Logger
+	db   storage.DB
+	now  func() time.Time
+
+	mu       sync.Mutex
+	loadTime time.Time       // when ids was loaded; zero if never
+	ids      map[string]bool // the suppressed documents
+}
+
+// A Suppression is an entry of a [Denylist].
+type Suppression struct {
+	ID     string    // ID of the suppressed document
+	User   string    // who suppressed the document
+	Reason string    // why the document was suppressed
+	Time   time.Time // when the document was suppressed
+}
+
+// A DenylistEvent is an entry of the audit log of a [Denylist].
+type DenylistEvent struct {
+	Time   time.Time
+	User   string
+	Action string // "suppress" or "unsuppress"
+	ID     string // ID of the document
+	Reason string
+}
+
+// Actions recorded in [DenylistEvent.Action].
+const (
+	ActionSuppress   = "suppress"
+	ActionUnsuppress = "unsuppress"
+)
+
+const (
+	denylistKind      = "search.Denylist"
+	denylistEventKind = "search.DenylistEvent"
+)
+
+// denylistTTL is how long a Denylist uses the suppressed documents
+// before reading them from the database again, which accounts
+// for the changes made by other processes.
+const denylistTTL = time.Minute
+
+// ErrNotSuppressed is returned by [Denylist.Unsuppress] for
+// a document that is not suppressed.
+var ErrNotSuppressed = errors.New("document not suppressed")
+
+// NewDenylist returns a new Denylist that stores its entries and
+// audit log in db and logs to lg.
+func NewDenylist(lg *slog.Logger, db storage.DB) *Denylist {
+	return &Denylist{slog: lg, db: db, now: time.Now}
+}
+
+// Suppress adds the document with the given ID to the denylist,
+// recording that user did so for the given reason.
+// Suppressing a suppressed document updates its entry.
+func (d *Denylist) Suppress(id, user, reason string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return errors.New("missing document ID")
+	}
+	s := &Suppression{ID: id, User: user, Reason: reason, Time: d.now().UTC()}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b := d.db.Batch()
+	b.Set(ordered.Encode(denylistKind, id), storage.JSON(s))
+	d.logEvent(b, ActionSuppress, s)
+	b.Apply()
+	d.db.Flush()
+	if d.ids != nil {
+		d.ids[id] = true
+	}
+	d.slog.Info("search.Denylist suppress", "id", id, "user", user, "reason", reason)
+	return nil
+}
+
+// Unsuppress removes the document with the given ID from the denylist,
+// recording that user did so for the given reason.
+// It returns an error wrapping [ErrNotSuppressed] if the document
+// is not suppressed.
+func (d *Denylist) Unsuppress(id, user, reason string) error {
+	id = strings.TrimSpace(id)
+	key := ordered.Encode(denylistKind, id)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.db.Get(key); !ok {
+		return fmt.Errorf("%w: %q", ErrNotSuppressed, id)
+	}
+	s := &Suppression{ID: id, User: user, Reason: reason, Time: d.now().UTC()}
+	b := d.db.Batch()
+	b.Delete(key)
+	d.logEvent(b, ActionUnsuppress, s)
+	b.Apply()
+	d.db.Flush()
+	if d.ids != nil {
+		delete(d.ids, id)
+	}
+	d.slog.Info("search.Denylist unsuppress", "id", id, "user", user, "reason", reason)
+	return nil
+}
+
+// logEvent adds the audit log entry for the action on s to b.
+func (d *Denylist) logEvent(b storage.Batch, action string, s *Suppression) {
+	e := &DenylistEvent{Time: s.Time, User: s.User, Action: action, ID: s.ID, Reason: s.Reason}
+	b.Set(ordered.Encode(denylistEventKind, s.Time.UnixNano(), s.ID, action), storage.JSON(e))
+}
+
+// Suppressed reports whether the document with the given ID is suppressed.
+// A nil Denylist suppresses nothing.
+func (d *Denylist) Suppressed(id string) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	if d.ids == nil || now.Sub(d.loadTime) > denylistTTL {
+		d.ids = make(map[string]bool)
+		for s := range d.scan() {
+			d.ids[s.ID] = true
+		}
+		d.loadTime = now
+	}
+	return d.ids[id]
+}
+
+// Suppressions returns the entries of the denylist, ordered by document ID.
+func (d *Denylist) Suppressions() []*Suppression {
+	var ss []*Suppression
+	for s := range d.scan() {
+		ss = append(ss, s)
+	}
+	return ss
+}
+
+// scan returns the entries of the denylist, ordered by document ID.
+func (d *Denylist) scan() func(func(*Suppression) bool) {
+	return func(yield func(*Suppression) bool) {
+		for _, val := range d.db.Scan(ordered.Encode(denylistKind), ordered.Encode(denylistKind, ordered.Inf)) {
+			var s Suppression
+			if err := json.Unmarshal(val(), &s); err != nil {
+				// unreachable unless db corruption
+				d.db.Panic("search.Denylist decode", "err", err)
+			}
+			if !yield(&s) {
+				return
+			}
+		}
+	}
+}
+
+// Events returns the audit log of the denylist, most recent first.
+func (d *Denylist) Events() []*DenylistEvent {
+	var es []*DenylistEvent
+	for _, val := range d.db.Scan(ordered.Encode(denylistEventKind), ordered.Encode(denylistEventKind, ordered.Inf)) {
+		var e DenylistEvent
+		if err := json.Unmarshal(val(), &e); err != nil {
+			// unreachable unless db corruption
+			d.db.Panic("search.Denylist decode event", "err", err)
+		}
+		es = append(es, &e)
+	}
+	slices.Reverse(es)
+	return es
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestDenylist(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	d := NewDenylist(lg, db)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	id := "https://github.com/golang/go/issues/1"
+	if d.Suppressed(id) {
+		t.Fatalf("Suppressed(%q) = true before Suppress", id)
+	}
+	if err := d.Suppress("", "alice", "x"); err == nil {
+		t.Error("Suppress(\"\") succeeded, want error")
+	}
+	if err := d.Suppress(" "+id+" ", "alice", "misleading"); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Suppressed(id) {
+		t.Errorf("Suppressed(%q) = false after Suppress", id)
+	}
+	// Another Denylist on the same database sees the suppression.
+	if !NewDenylist(lg, db).Suppressed(id) {
+		t.Errorf("new Denylist: Suppressed(%q) = false after Suppress", id)
+	}
+	wantS := []*Suppression{{ID: id, User: "alice", Reason: "misleading", Time: now}}
+	if diff := cmp.Diff(wantS, d.Suppressions()); diff != "" {
+		t.Errorf("Suppressions() mismatch (-want +got):\n%s", diff)
+	}
+
+	now = now.Add(time.Hour)
+	if err := d.Unsuppress(id, "bob", "fixed"); err != nil {
+		t.Fatal(err)
+	}
+	if d.Suppressed(id) {
+		t.Errorf("Suppressed(%q) = true after Unsuppress", id)
+	}
+	if err := d.Unsuppress(id, "bob", "again"); !errors.Is(err, ErrNotSuppressed) {
+		t.Errorf("Unsuppress of unsuppressed document: got %v, want ErrNotSuppressed", err)
+	}
+	if ss := d.Suppressions(); len(ss) != 0 {
+		t.Errorf("Suppressions() = %v, want none", ss)
+	}
+
+	wantE := []*DenylistEvent{
+		{Time: now, User: "bob", Action: ActionUnsuppress, ID: id, Reason: "fixed"},
+		{Time: now.Add(-time.Hour), User: "alice", Action: ActionSuppress, ID: id, Reason: "misleading"},
+	}
+	if diff := cmp.Diff(wantE, d.Events()); diff != "" {
+		t.Errorf("Events() mismatch (-want +got):\n%s", diff)
+	}
+
+	// A nil Denylist suppresses nothing.
+	var nd *Denylist
+	if nd.Suppressed(id) {
+		t.Error("nil Denylist: Suppressed = true")
+	}
+}
+
+func TestDenylistVector(t *testing.T) {
+	lg := testutil.Slogger(t)
+	embedder := llm.QuoteEmbedder()
+	db := storage.MemDB()
+	vdb := storage.MemVectorDB(db, lg, "")
+	corpus := docs.New(lg, db)
+
+	for i := range 5 {
+		id := fmt.Sprintf("id%d", i)
+		doc := llm.EmbedDoc{Title: id, Text: id}
+		corpus.Add(id, doc.Title, doc.Text)
+		vdb.Set(id, mustEmbed(t, embedder, doc))
+	}
+	vec := mustEmbed(t, embedder, llm.EmbedDoc{Title: "id1", Text: "id1"})
+
+	d := NewDenylist(lg, db)
+	if err := d.Suppress("id1", "alice", "misleading"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Suppress("id3", "alice", "obsolete"); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range Vector(vdb, corpus, &VectorRequest{Options: Options{Denylist: d}, Vector: vec}) {
+		got = append(got, r.ID)
+	}
+	slices.Sort(got)
+	want := []string{"id0", "id2", "id4"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Vector with Denylist = %v, want %v", got, want)
+	}
+}
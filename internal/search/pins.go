@@ -0,0 +1,184 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// Pins is a set of authoritative documents, such as FAQ entries and
+// umbrella issues, that are pinned to topics: searches on a matching
+// topic return the pinned documents first, regardless of their score.
+//
+// A topic is a short text, such as "generic type aliases", along
+// with its embedding. A search matches the topic if its vector
+// is close enough to the topic's (see [Pin.MinScore]).
+//
+// Pass Pins in [Options.Pins] to apply them in [Query] and [Vector].
+type Pins struct {
+	slog *slog.Logger
+	db   storage.DB
+	now  func() time.Time
+
+	mu       sync.Mutex
+	loadTime time.Time // when pins was loaded; zero if never
+	pins     []*Pin
+}
+
+// A Pin pins a document to a topic.
+type Pin struct {
+	Topic    string     // the topic, such as "generic type aliases"
+	Vector   llm.Vector // embedding of the topic
+	MinScore float64    // lowest similarity of a search to the topic that matches it
+	ID       string     // ID of the pinned document
+	User     string     // who pinned the document
+	Reason   string     // why the document was pinned
+	Time     time.Time  // when the document was pinned
+}
+
+// DefaultPinScore is the default [Pin.MinScore].
+const DefaultPinScore = 0.8
+
+const pinKind = "search.Pin"
+
+// pinsTTL is how long Pins uses the pins before reading them
+// from the database again, which accounts for the changes
+// made by other processes.
+const pinsTTL = time.Minute
+
+// ErrNotPinned is returned by [Pins.Unpin] for a document
+// that is not pinned to the topic.
+var ErrNotPinned = errors.New("document not pinned to topic")
+
+// NewPins returns a new Pins that stores its pins in db and logs to lg.
+func NewPins(lg *slog.Logger, db storage.DB) *Pins {
+	return &Pins{slog: lg, db: db, now: time.Now}
+}
+
+// Pin pins the document with the given ID to the topic, whose
+// embedding is vec, recording that user did so for the given reason.
+// Searches match the topic if their similarity to it is at least
+// minScore; if minScore is 0, [DefaultPinScore] is used.
+// Pinning a pinned document updates its pin.
+func (p *Pins) Pin(topic string, vec llm.Vector, minScore float64, id, user, reason string) error {
+	topic, id = strings.TrimSpace(topic), strings.TrimSpace(id)
+	switch {
+	case topic == "":
+		return errors.New("missing topic")
+	case id == "":
+		return errors.New("missing document ID")
+	case len(vec) == 0:
+		return errors.New("missing topic embedding")
+	case minScore < 0 || minScore > 1:
+		return fmt.Errorf("minimum score must be >= 0 and <= 1 (got: %.3f)", minScore)
+	}
+	pin := &Pin{
+		Topic:    topic,
+		Vector:   vec,
+		MinScore: cmp.Or(minScore, DefaultPinScore),
+		ID:       id,
+		User:     user,
+		Reason:   reason,
+		Time:     p.now().UTC(),
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.db.Set(ordered.Encode(pinKind, topic, id), storage.JSON(pin))
+	p.db.Flush()
+	p.pins = nil // reload
+	p.slog.Info("search.Pins pin", "topic", topic, "id", id, "user", user, "reason", reason)
+	return nil
+}
+
+// Unpin unpins the document with the given ID from the topic.
+// It returns an error wrapping [ErrNotPinned] if the document
+// is not pinned to the topic.
+func (p *Pins) Unpin(topic, id, user string) error {
+	topic, id = strings.TrimSpace(topic), strings.TrimSpace(id)
+	key := ordered.Encode(pinKind, topic, id)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.db.Get(key); !ok {
+		return fmt.Errorf("%w: %q, %q", ErrNotPinned, topic, id)
+	}
+	p.db.Delete(key)
+	p.db.Flush()
+	p.pins = nil // reload
+	p.slog.Info("search.Pins unpin", "topic", topic, "id", id, "user", user)
+	return nil
+}
+
+// List returns the pins, ordered by topic and document ID.
+func (p *Pins) List() []*Pin {
+	var pins []*Pin
+	for _, val := range p.db.Scan(ordered.Encode(pinKind), ordered.Encode(pinKind, ordered.Inf)) {
+		var pin Pin
+		if err := json.Unmarshal(val(), &pin); err != nil {
+			// unreachable unless db corruption
+			p.db.Panic("search.Pins decode", "err", err)
+		}
+		pins = append(pins, &pin)
+	}
+	return pins
+}
+
+// A PinMatch is a pinned document whose topic matches a search.
+type PinMatch struct {
+	*Pin
+	Score float64 // similarity of the search to the topic
+}
+
+// Match returns the pins whose topics match a search for vec,
+// most similar topic first. A document pinned to several matching
+// topics is returned once, for its most similar topic.
+// A nil Pins matches nothing.
+func (p *Pins) Match(vec llm.Vector) []*PinMatch {
+	if p == nil {
+		return nil
+	}
+	var ms []*PinMatch
+	for _, pin := range p.load() {
+		if s := vec.Dot(pin.Vector); s >= pin.MinScore {
+			ms = append(ms, &PinMatch{Pin: pin, Score: s})
+		}
+	}
+	slices.SortStableFunc(ms, func(a, b *PinMatch) int { return cmp.Compare(b.Score, a.Score) })
+	seen := make(map[string]bool)
+	return slices.DeleteFunc(ms, func(m *PinMatch) bool {
+		if seen[m.ID] {
+			return true
+		}
+		seen[m.ID] = true
+		return false
+	})
+}
+
+// load returns the pins, reading them from the database
+// if they are older than pinsTTL.
+func (p *Pins) load() []*Pin {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if p.pins == nil || now.Sub(p.loadTime) > pinsTTL {
+		p.pins = p.List()
+		if p.pins == nil {
+			p.pins = []*Pin{}
+		}
+		p.loadTime = now
+	}
+	return p.pins
+}
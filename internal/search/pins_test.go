@@ -0,0 +1,160 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestPins(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	p := NewPins(lg, db)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	embedder := llm.QuoteEmbedder()
+	aliases := mustEmbed(t, embedder, llm.EmbedDoc{Text: "generic type aliases"})
+	loops := mustEmbed(t, embedder, llm.EmbedDoc{Text: "loop variables"})
+
+	for _, tc := range []struct {
+		topic    string
+		vec      llm.Vector
+		minScore float64
+		id       string
+	}{
+		{"", aliases, 0, "faq"},
+		{"aliases", aliases, 0, ""},
+		{"aliases", nil, 0, "faq"},
+		{"aliases", aliases, 1.5, "faq"},
+	} {
+		if err := p.Pin(tc.topic, tc.vec, tc.minScore, tc.id, "alice", "x"); err == nil {
+			t.Errorf("Pin(%q, %v, %v, %q) succeeded, want error", tc.topic, tc.vec, tc.minScore, tc.id)
+		}
+	}
+
+	if err := p.Pin(" aliases ", aliases, 0, " faq ", "alice", "FAQ entry"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Pin("aliases", aliases, 0.9, "umbrella", "alice", "umbrella issue"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Pin("loops", loops, 0, "faq", "bob", ""); err != nil {
+		t.Fatal(err)
+	}
+	want := []*Pin{
+		{Topic: "aliases", Vector: aliases, MinScore: DefaultPinScore, ID: "faq", User: "alice", Reason: "FAQ entry", Time: now},
+		{Topic: "aliases", Vector: aliases, MinScore: 0.9, ID: "umbrella", User: "alice", Reason: "umbrella issue", Time: now},
+		{Topic: "loops", Vector: loops, MinScore: DefaultPinScore, ID: "faq", User: "bob", Time: now},
+	}
+	if diff := cmp.Diff(want, p.List()); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+
+	// A document pinned to several matching topics is matched once.
+	var got []string
+	for _, m := range p.Match(aliases) {
+		got = append(got, m.ID)
+	}
+	if want := []string{"faq", "umbrella"}; !slices.Equal(got, want) {
+		t.Errorf("Match(aliases) = %v, want %v", got, want)
+	}
+
+	if err := p.Unpin("aliases", "faq", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Unpin("aliases", "faq", "bob"); !errors.Is(err, ErrNotPinned) {
+		t.Errorf("Unpin of unpinned document: got %v, want ErrNotPinned", err)
+	}
+	got = nil
+	for _, m := range p.Match(aliases) {
+		got = append(got, m.ID)
+	}
+	if want := []string{"umbrella"}; !slices.Equal(got, want) {
+		t.Errorf("Match(aliases) after Unpin = %v, want %v", got, want)
+	}
+	// Another Pins on the same database sees the pins.
+	if n := len(NewPins(lg, db).List()); n != 2 {
+		t.Errorf("new Pins: len(List()) = %d, want 2", n)
+	}
+
+	// A nil Pins matches nothing.
+	var np *Pins
+	if ms := np.Match(aliases); ms != nil {
+		t.Errorf("nil Pins: Match = %v, want nil", ms)
+	}
+}
+
+func TestPinsVector(t *testing.T) {
+	lg := testutil.Slogger(t)
+	embedder := llm.QuoteEmbedder()
+	db := storage.MemDB()
+	vdb := storage.MemVectorDB(db, lg, "")
+	corpus := docs.New(lg, db)
+
+	for i := range 5 {
+		id := fmt.Sprintf("id%d", i)
+		doc := llm.EmbedDoc{Title: id, Text: id}
+		corpus.Add(id, doc.Title, doc.Text)
+		vdb.Set(id, mustEmbed(t, embedder, doc))
+	}
+	vec := mustEmbed(t, embedder, llm.EmbedDoc{Title: "id1", Text: "id1"})
+
+	p := NewPins(lg, db)
+	if err := p.Pin("topic", vec, 0, "id3", "alice", "authoritative"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Pin("topic", vec, 0, "id4", "alice", "authoritative"); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDenylist(lg, db)
+	if err := d.Suppress("id4", "bob", "obsolete"); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(opts Options) []Result {
+		opts.Pins = p
+		opts.Denylist = d
+		return Vector(vdb, corpus, &VectorRequest{Options: opts, Vector: vec})
+	}
+
+	// The pinned document comes first, even below the threshold;
+	// the suppressed one is left out.
+	rs := search(Options{Threshold: 0.9999999})
+	if len(rs) != 2 {
+		t.Fatalf("Vector with Pins = %v, want 2 results", rs)
+	}
+	if rs[0].ID != "id3" || !rs[0].Pinned || rs[0].Title != "id3" {
+		t.Errorf("Vector with Pins: first result = %+v, want pinned id3", rs[0])
+	}
+	if rs[1].ID != "id1" || rs[1].Pinned {
+		t.Errorf("Vector with Pins: second result = %+v, want unpinned id1", rs[1])
+	}
+
+	// Pinned documents are not repeated, and only appear on the first page.
+	rs = search(Options{})
+	var ids []string
+	for _, r := range rs {
+		ids = append(ids, r.ID)
+	}
+	if !slices.Contains(ids, "id3") || slices.Index(ids, "id3") != 0 || len(ids) != 4 {
+		t.Errorf("Vector with Pins = %v, want id3 first and once, without id4", ids)
+	}
+	for _, r := range search(Options{After: &Cursor{Score: 1, ID: "id3"}}) {
+		if r.Pinned {
+			t.Errorf("Vector with Pins and After: got pinned result %+v", r)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/llmapp"
+)
+
+// A Reranker orders candidate search results by their
+// relevance to a query.
+// Implementations may use an LLM (see [LLMReranker])
+// or a dedicated ranking model, such as a cross-encoder.
+type Reranker interface {
+	// Rerank returns the indexes of the candidates,
+	// most relevant first. Each index must appear exactly once.
+	Rerank(ctx context.Context, query *llmapp.Doc, candidates []*llmapp.Doc) ([]int, error)
+}
+
+// LLMReranker returns a [Reranker] that asks the LLM
+// to rank the candidates (see [llmapp.Client.Rerank]).
+func LLMReranker(lc *llmapp.Client) Reranker {
+	return llmReranker{lc}
+}
+
+type llmReranker struct {
+	lc *llmapp.Client
+}
+
+func (r llmReranker) Rerank(ctx context.Context, query *llmapp.Doc, candidates []*llmapp.Doc) ([]int, error) {
+	res, err := r.lc.Rerank(ctx, query, candidates)
+	if err != nil {
+		return nil, err
+	}
+	return res.Order, nil
+}
+
+// Rerank reorders results by their relevance to query,
+// as judged by rr. The scores of the results are unchanged.
+//
+// Only the given results are reordered, so when paging through
+// search results, each page is reranked separately.
+func Rerank(ctx context.Context, rr Reranker, dc *docs.Corpus, query *llmapp.Doc, results []Result) ([]Result, error) {
+	if rr == nil {
+		return nil, errors.New("search.Rerank: no reranker")
+	}
+	if len(results) < 2 {
+		return results, nil
+	}
+	var candidates []*llmapp.Doc
+	for _, r := range results {
+		d, ok := llmDoc(dc, "candidate", r.ID)
+		if !ok {
+			// Rank the document using what we know.
+			d = &llmapp.Doc{Type: "candidate", Title: r.Title, Text: r.ID}
+		}
+		candidates = append(candidates, d)
+	}
+	order, err := rr.Rerank(ctx, query, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("search.Rerank: %w", err)
+	}
+	if len(order) != len(results) {
+		return nil, fmt.Errorf("search.Rerank: reranker returned %d results, want %d", len(order), len(results))
+	}
+	seen := make([]bool, len(results))
+	reranked := make([]Result, 0, len(results))
+	for _, i := range order {
+		if i < 0 || i >= len(results) || seen[i] {
+			return nil, fmt.Errorf("search.Rerank: reranker returned invalid order %v", order)
+		}
+		seen[i] = true
+		reranked = append(reranked, results[i])
+	}
+	return reranked, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// A fakeReranker returns a fixed order, recording its arguments.
+type fakeReranker struct {
+	order      []int
+	query      *llmapp.Doc
+	candidates []*llmapp.Doc
+}
+
+func (r *fakeReranker) Rerank(_ context.Context, query *llmapp.Doc, candidates []*llmapp.Doc) ([]int, error) {
+	r.query, r.candidates = query, candidates
+	return r.order, nil
+}
+
+func TestRerank(t *testing.T) {
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	embedder := llm.QuoteEmbedder()
+	db := storage.MemDB()
+	vdb := storage.MemVectorDB(db, lg, "")
+	corpus := docs.New(lg, db)
+
+	for i := range 3 {
+		id := fmt.Sprintf("id%d", i)
+		doc := llm.EmbedDoc{Title: fmt.Sprintf("title%d", i), Text: fmt.Sprintf("text-%s", strings.Repeat("x", i))}
+		corpus.Add(id, doc.Title, doc.Text)
+		vdb.Set(id, mustEmbed(t, embedder, doc))
+	}
+	ids := func(rs []Result) []string {
+		var ids []string
+		for _, r := range rs {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	req := &QueryRequest{EmbedDoc: llm.EmbedDoc{Title: "title0", Text: "text-"}}
+
+	rs, err := Query(ctx, vdb, corpus, embedder, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(rs), []string{"id0", "id1", "id2"}; !slices.Equal(got, want) {
+		t.Fatalf("Query() = %v, want %v", got, want)
+	}
+
+	rr := &fakeReranker{order: []int{2, 0, 1}}
+	req.Rerank = true
+	req.Reranker = rr
+	reranked, err := Query(ctx, vdb, corpus, embedder, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(reranked), []string{"id2", "id0", "id1"}; !slices.Equal(got, want) {
+		t.Errorf("Query(Rerank) = %v, want %v", got, want)
+	}
+	if reranked[0] != rs[2] {
+		t.Errorf("Query(Rerank)[0] = %v, want %v (unchanged score)", reranked[0], rs[2])
+	}
+	if rr.query.Title != "title0" || len(rr.candidates) != 3 || rr.candidates[1].Title != "title1" {
+		t.Errorf("reranker called with query %+v, candidates %+v", rr.query, rr.candidates)
+	}
+
+	for _, order := range [][]int{{0, 1}, {0, 1, 1}, {0, 1, 3}, {-1, 0, 1}} {
+		if _, err := Rerank(ctx, &fakeReranker{order: order}, corpus, &llmapp.Doc{}, rs); err == nil {
+			t.Errorf("Rerank() with order %v succeeded, want error", order)
+		}
+	}
+	req.Reranker = nil
+	if _, err := Query(ctx, vdb, corpus, embedder, req); err == nil {
+		t.Error("Query(Rerank) with no reranker succeeded, want error")
+	}
+}
@@ -14,11 +14,16 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/entity"
+	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
 	"golang.org/x/oscar/internal/storage"
 )
 
@@ -37,33 +42,144 @@ type QueryRequest struct {
 type Options struct {
 	Threshold float64  // lowest score to keep; default 0. Max is 1.
 	Limit     int      // max results (fewer if Threshold is set); 0 means use a fixed default
+	After     *Cursor  // resume the search after this result, for pagination; see [Page]
 	AllowKind []string // kinds of documents to keep; empty means keep all
 	DenyKind  []string // kinds of documents to remove; empty means remove none
+	Sources   []string // sources of documents to keep (see [Source]), such as "golang/go"; empty means keep all
+
+	// Filters on document state, creation time, milestone and project board.
+	// They require Info; documents for which Info reports nothing
+	// (or that have no state, for the State filter) are removed.
+	State         string    // "open" or "closed"; empty means keep all
+	CreatedAfter  time.Time // keep documents created at or after this time; zero means no lower bound
+	CreatedBefore time.Time // keep documents created before this time; zero means no upper bound
+	Milestone     string    // keep documents in the milestone with this title, such as "Go1.25" (case-insensitive); empty means keep all
+	Board         string    // keep documents on the project board with this title or URL (case-insensitive); empty means keep all
+
+	// Denylist holds documents to remove from the results; nil means remove none.
+	// It is not part of the JSON form of Options; see [NewDenylist].
+	Denylist *Denylist `json:"-"`
+
+	// Pins holds documents pinned to topics; nil means none.
+	// The pinned documents of the topics that match the search
+	// come first in the results, regardless of Threshold and Limit,
+	// but subject to the other filters.
+	// It is not part of the JSON form of Options; see [NewPins].
+	Pins *Pins `json:"-"`
+
+	// Info reports metadata about documents for the
+	// State, CreatedAfter, CreatedBefore, Milestone and Board filters.
+	// It is not part of the JSON form of Options; see [GitHubInfo].
+	Info InfoFunc `json:"-"`
+
+	// Rerank reports whether [Query] should reorder the results
+	// using Reranker (see [Rerank]).
+	Rerank bool
+	// Reranker is the reranker to use if Rerank is set.
+	// It is not part of the JSON form of Options; see [LLMReranker].
+	Reranker Reranker `json:"-"`
+}
+
+// DocInfo is metadata about a document, used to filter search results.
+type DocInfo struct {
+	State     string    // "open" or "closed"; empty if the document has no state
+	Created   time.Time // creation time; zero if unknown
+	Milestone string    // title of the document's milestone; empty if none
+	Boards    []string  // titles and URLs of the project boards the document is on
+}
+
+// An InfoFunc returns metadata about the document with the given ID.
+// It returns false if it knows nothing about the document.
+type InfoFunc func(id string) (DocInfo, bool)
+
+// GitHubInfo returns an [InfoFunc] that reports the state, creation
+// time, milestone and project boards of the GitHub issues in gh's database
+// (see [github.Client.Boards] for which boards are known).
+// It knows nothing about other documents.
+func GitHubInfo(gh *github.Client) InfoFunc {
+	return func(id string) (DocInfo, bool) {
+		if docIDKind(id) != KindGitHubIssue {
+			return DocInfo{}, false
+		}
+		iss, err := gh.LookupIssueURL(id)
+		if err != nil {
+			return DocInfo{}, false
+		}
+		info := DocInfo{State: iss.State, Milestone: iss.Milestone.Title}
+		if created, err := time.Parse(time.RFC3339, iss.CreatedAt); err == nil {
+			info.Created = created
+		}
+		for _, b := range gh.Boards(iss) {
+			if b.Board != "" {
+				info.Boards = append(info.Boards, b.Board)
+			}
+			info.Boards = append(info.Boards, b.URL)
+		}
+		return info, true
+	}
 }
 
 // Result is a single result of a search ([Query] or [Vector]).
 // It represents a single document in a vector database which is a
 // nearest neighbor of the request.
 type Result struct {
-	Kind  string // kind of document: issue, doc page, etc.
-	Title string
+	Kind   string // kind of document: issue, doc page, etc.
+	Title  string
+	Pinned bool `json:",omitempty"` // pinned to a topic of the search (see [Pins])
 	storage.VectorResult
 }
 
+// A Page is a page of search results ([QueryPage] or [VectorPage]).
+type Page struct {
+	Results []Result
+	// Next is the position at which the next page starts
+	// (to be passed as [Options.After]), or nil if there are
+	// no more results.
+	// Next is found before reranking, so it is not necessarily
+	// the position of the last of the Results.
+	Next *Cursor
+}
+
 // Query performs a nearest neighbors search for the request's document
 // over the given vector database, respecting the options set in [QueryRequest].
 //
 // It embeds the request's document onto the vector space using the given embedder.
 //
+// If req.Rerank is set, it reorders the results using req.Reranker,
+// except for pinned documents (see [Options.Pins]), which stay first.
+//
 // It expects that vdb is a vector database containing embeddings of
 // the documents in dc, embedded using embed.
 func Query(ctx context.Context, vdb storage.VectorDB, dc *docs.Corpus, embed llm.Embedder, req *QueryRequest) ([]Result, error) {
+	p, err := QueryPage(ctx, vdb, dc, embed, req)
+	if err != nil {
+		return nil, err
+	}
+	return p.Results, nil
+}
+
+// QueryPage is like [Query], but it also reports where the
+// next page of results starts.
+func QueryPage(ctx context.Context, vdb storage.VectorDB, dc *docs.Corpus, embed llm.Embedder, req *QueryRequest) (*Page, error) {
 	vecs, err := embed.EmbedDocs(ctx, []llm.EmbedDoc{req.EmbedDoc})
 	if err != nil {
 		return nil, fmt.Errorf("EmbedDocs: %w", err)
 	}
 	vec := vecs[0]
-	return vector(vdb, dc, vec, &req.Options), nil
+	p := vector(vdb, dc, vec, &req.Options)
+	if req.Rerank {
+		// Pinned documents stay first.
+		n := 0
+		for n < len(p.Results) && p.Results[n].Pinned {
+			n++
+		}
+		reranked, err := Rerank(ctx, req.Reranker, dc, &llmapp.Doc{Title: req.Title, Text: req.Text}, p.Results[n:])
+		if err != nil {
+			return nil, err
+		}
+		p.Results = append(p.Results[:n:n], reranked...)
+	}
+	return p, nil
 }
 
 // VectorRequest is a [Vector] request.
@@ -81,6 +197,12 @@ type VectorRequest struct {
 // the documents in dc, embedded using the same embedder used to create
 // the request's vector.
 func Vector(vdb storage.VectorDB, dc *docs.Corpus, req *VectorRequest) []Result {
+	return vector(vdb, dc, req.Vector, &req.Options).Results
+}
+
+// VectorPage is like [Vector], but it also reports where the
+// next page of results starts.
+func VectorPage(vdb storage.VectorDB, dc *docs.Corpus, req *VectorRequest) *Page {
 	return vector(vdb, dc, req.Vector, &req.Options)
 }
 
@@ -102,14 +224,66 @@ func (o *Options) Validate() error {
 			return fmt.Errorf("unrecognized deny kind %q (case-sensitive)", deny)
 		}
 	}
+	switch o.State {
+	case "", "open", "closed":
+	default:
+		return fmt.Errorf("state must be \"open\" or \"closed\" (got: %q)", o.State)
+	}
+	if !o.CreatedAfter.IsZero() && !o.CreatedBefore.IsZero() && !o.CreatedAfter.Before(o.CreatedBefore) {
+		return fmt.Errorf("created after (%s) must be before created before (%s)",
+			o.CreatedAfter.Format(time.DateOnly), o.CreatedBefore.Format(time.DateOnly))
+	}
 	return nil
 }
 
-func vector(vdb storage.VectorDB, dc *docs.Corpus, vec llm.Vector, opts *Options) []Result {
-	limit := defaultLimit
-	if opts.Limit > 0 {
-		limit = opts.Limit
+// infoFiltered reports whether o filters results using o.Info.
+func (o *Options) infoFiltered() bool {
+	return o.State != "" || !o.CreatedAfter.IsZero() || !o.CreatedBefore.IsZero() ||
+		o.Milestone != "" || o.Board != ""
+}
+
+// keepInfo reports whether the document with the given ID
+// passes the State, CreatedAfter, CreatedBefore, Milestone and Board filters.
+func (o *Options) keepInfo(id string) bool {
+	if !o.infoFiltered() {
+		return true
+	}
+	if o.Info == nil {
+		return false
+	}
+	info, ok := o.Info(id)
+	if !ok {
+		return false
+	}
+	if o.State != "" && info.State != o.State {
+		return false
+	}
+	if !o.CreatedAfter.IsZero() || !o.CreatedBefore.IsZero() {
+		if info.Created.IsZero() ||
+			info.Created.Before(o.CreatedAfter) ||
+			(!o.CreatedBefore.IsZero() && !info.Created.Before(o.CreatedBefore)) {
+			return false
+		}
+	}
+	if o.Milestone != "" && !strings.EqualFold(info.Milestone, o.Milestone) {
+		return false
+	}
+	if o.Board != "" && !slices.ContainsFunc(info.Boards, func(b string) bool { return strings.EqualFold(b, o.Board) }) {
+		return false
+	}
+	return true
+}
+
+// limit returns the maximum number of nearest neighbors
+// to consider for a single page of results.
+func (o *Options) limit() int {
+	if o.Limit > 0 {
+		return o.Limit
 	}
+	return defaultLimit
+}
+
+func vector(vdb storage.VectorDB, dc *docs.Corpus, vec llm.Vector, opts *Options) *Page {
 	// Search uses normalized dot product, so higher numbers are better.
 	// Max is 1, min is 0.
 	threshold := 0.0
@@ -126,26 +300,84 @@ func vector(vdb storage.VectorDB, dc *docs.Corpus, vec llm.Vector, opts *Options
 	if len(opts.DenyKind) != 0 {
 		denyKind = containsFunc(opts.DenyKind)
 	}
-	var srs []Result
-	for _, r := range vdb.Search(vec, limit) {
-		if r.Score < threshold {
-			break
+	// By default, allow all sources.
+	allowSource := func(string) bool { return true }
+	if len(opts.Sources) != 0 {
+		allowSource = containsFunc(opts.Sources)
+	}
+	keep := func(id, kind string) bool {
+		return allowKind(kind) && !denyKind(kind) && allowSource(Source(id)) &&
+			opts.keepInfo(id) && !opts.Denylist.Suppressed(id)
+	}
+	title := func(id string) string {
+		if d, ok := dc.Get(id); ok {
+			return d.Title
 		}
-		kind := docIDKind(r.ID)
-		if !allowKind(kind) || denyKind(kind) {
+		return ""
+	}
+
+	// Pinned documents come first on the first page,
+	// and are left out of the other results.
+	var srs []Result
+	pinned := make(map[string]bool)
+	for _, m := range opts.Pins.Match(vec) {
+		pinned[m.ID] = true
+		kind := docIDKind(m.ID)
+		if opts.After != nil || !keep(m.ID, kind) {
 			continue
 		}
-		title := ""
-		if d, ok := dc.Get(r.ID); ok {
-			title = d.Title
+		score := m.Score
+		if v, ok := vdb.Get(m.ID); ok {
+			score = vec.Dot(v)
 		}
 		srs = append(srs, Result{
 			Kind:         kind,
-			Title:        title,
+			Title:        title(m.ID),
+			Pinned:       true,
+			VectorResult: storage.VectorResult{ID: m.ID, Score: score},
+		})
+	}
+
+	// Ask for one more result than the limit, to learn whether
+	// there is a next page. To resume after opts.After, ask for
+	// more and more neighbors until enough come after it.
+	// The vector database applies the filters other than Threshold,
+	// so a short answer means there are no more results.
+	limit := opts.limit()
+	var rs []storage.VectorResult
+	for n := limit + 1; ; n *= 2 {
+		rs = vdb.Search(vec, n, func(id string) bool {
+			return !pinned[id] && keep(id, docIDKind(id))
+		})
+		done := len(rs) < n
+		if opts.After != nil {
+			i := slices.IndexFunc(rs, func(r storage.VectorResult) bool { return !opts.After.before(r) })
+			if i < 0 {
+				i = len(rs)
+			}
+			rs = rs[i:]
+		}
+		if done || len(rs) > limit {
+			break
+		}
+	}
+
+	p := &Page{Results: srs}
+	for i, r := range rs {
+		if r.Score < threshold {
+			break
+		}
+		if i == limit {
+			p.Next = cursorOf(rs[i-1])
+			break
+		}
+		p.Results = append(p.Results, Result{
+			Kind:         docIDKind(r.ID),
+			Title:        title(r.ID),
 			VectorResult: r,
 		})
 	}
-	return srs
+	return p
 }
 
 func containsFunc(s []string) func(string) bool {
@@ -181,11 +413,13 @@ const defaultLimit = 20
 const (
 	KindGitHubIssue             = "GitHubIssue"
 	KindGitHubDiscussion        = "GitHubDiscussion"
+	KindGitHubPullRequest       = "GitHubPullRequest"
 	KindGoWiki                  = "GoWiki"
 	KindGoDocumentation         = "GoDocumentation"
 	KindGoReference             = "GoReference"
 	KindGoBlog                  = "GoBlog"
 	KindGoDevPage               = "GoDevPage"
+	KindGoPackage               = "GoPackage"
 	KindGoGerritChange          = "GoGerritChange"
 	KindGoogleGroupConversation = "GoogleGroupsConversation"
 	// Unknown document.
@@ -196,10 +430,12 @@ const (
 var kinds = map[string]bool{
 	KindGitHubIssue:             true,
 	KindGitHubDiscussion:        true,
+	KindGitHubPullRequest:       true,
 	KindGoWiki:                  true,
 	KindGoDocumentation:         true,
 	KindGoBlog:                  true,
 	KindGoDevPage:               true,
+	KindGoPackage:               true,
 	KindUnknown:                 true,
 	KindGoGerritChange:          true,
 	KindGoogleGroupConversation: true,
@@ -210,14 +446,30 @@ var kinds = map[string]bool{
 //
 // The function assumes that we only care about the Go project.
 func docIDKind(id string) string {
+	e, err := entity.Parse(id)
+	if err != nil {
+		return KindUnknown
+	}
+	switch e.Kind {
+	case entity.GitHubIssue, entity.GitHubPullRequest, entity.GitHubDiscussion:
+		return githubKind(e)
+	case entity.GerritChange:
+		if e.Host == "go-review.googlesource.com" {
+			return KindGoGerritChange
+		}
+		return KindUnknown
+	case entity.WebPage:
+		// Handled below.
+	default:
+		// We don't currently recognize GitHub comments.
+		return KindUnknown
+	}
 	u, err := url.Parse(id)
 	if err != nil {
 		return KindUnknown
 	}
 	hp := path.Join(u.Host, u.Path)
 	switch {
-	case githubRE.MatchString(hp):
-		return githubKind(hp, u.Fragment)
 	case strings.HasPrefix(hp, "go.dev/wiki/"):
 		return KindGoWiki
 	case strings.HasPrefix(hp, "go.dev/doc/"):
@@ -228,6 +480,8 @@ func docIDKind(id string) string {
 		return KindGoBlog
 	case strings.HasPrefix(hp, "go.dev/"):
 		return KindGoDevPage
+	case strings.HasPrefix(hp, "pkg.go.dev/"):
+		return KindGoPackage
 	case strings.HasPrefix(hp, "go-review.googlesource.com/"):
 		return KindGoGerritChange
 	case goGoogleGroupConversation(hp):
@@ -236,36 +490,44 @@ func docIDKind(id string) string {
 	return KindUnknown
 }
 
-func githubKind(hostPath string, fragment string) string {
-	// We don't currently recognize Github URLs with fragments.
-	if fragment != "" {
-		return KindUnknown
+// Source returns the source of the document with the given ID:
+// the GitHub project ("owner/repo") for GitHub documents, and
+// the host name (for example "go.dev") for other web documents.
+// It returns the empty string if the ID is not a URL.
+func Source(id string) string {
+	if !isURL(id) {
+		return ""
 	}
-
-	s := githubRE.FindStringSubmatch(hostPath)
-	if len(s) != 3 { // malformed
-		return KindUnknown
+	if e, err := entity.Parse(id); err == nil && e.Host == "github.com" && e.Project != "" {
+		return e.Project
 	}
-	project, api := s[1], s[2]
+	u, err := url.Parse(id)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
 
+// githubKind returns the kind of the GitHub issue, pull request
+// or discussion e.
+func githubKind(e entity.ID) string {
 	// Project must be "golang/go", except in tests.
-	if project != "golang/go" && !testing.Testing() {
+	if e.Project != "golang/go" && !testing.Testing() {
 		return KindUnknown
 	}
 
-	switch api {
-	case "issues":
+	switch e.Kind {
+	case entity.GitHubIssue:
 		return KindGitHubIssue
-	case "discussions":
+	case entity.GitHubDiscussion:
 		return KindGitHubDiscussion
+	case entity.GitHubPullRequest:
+		return KindGitHubPullRequest
 	default:
 		return KindUnknown
 	}
 }
 
-// Matches GitHub URLs in any project of the form github.com/owner/repo/api/num.
-var githubRE = regexp.MustCompile(`^github\.com/([\w-]+/[\w-]+)/([\w-]+)/\d+$`)
-
 func goGoogleGroupConversation(hostPath string) bool {
 	s := googleGroupRE.FindStringSubmatch(hostPath)
 	if len(s) != 2 { // malformed
@@ -12,9 +12,11 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/github"
 	"golang.org/x/oscar/internal/llm"
 	"golang.org/x/oscar/internal/storage"
 	"golang.org/x/oscar/internal/testutil"
@@ -30,10 +32,13 @@ func TestKind(t *testing.T) {
 		{"https://go.dev/doc/x", "GoDocumentation"},
 		{"https://go.dev/ref/x", "GoReference"},
 		{"https://go.dev/wiki/x", "GoWiki"},
+		{"https://pkg.go.dev/net/http", "GoPackage"},
 		{"https://github.com/golang/go/issues/123", "GitHubIssue"},
 		{"https://github.com/golang/go/issues/123#issuecomment-1234", "Unknown"},
 		{"https://github.com/golang/go/discussions/123", "GitHubDiscussion"},
 		{"https://github.com/golang/go/discussions/123#discussioncomment-1234", "Unknown"},
+		{"https://github.com/golang/go/pull/123", "GitHubPullRequest"},
+		{"https://github.com/golang/go/pull/123#discussion_r1234", "Unknown"},
 		{"https://go-review.googlesource.com/c/test/+/1#related-content", "GoGerritChange"},
 		{"https://groups.google.com/g/golang-nuts/c/12142142354", "GoogleGroupsConversation"},
 	} {
@@ -44,6 +49,23 @@ func TestKind(t *testing.T) {
 	}
 }
 
+func TestSource(t *testing.T) {
+	for _, test := range []struct {
+		id, want string
+	}{
+		{"something", ""},
+		{"https://go.dev/doc/x", "go.dev"},
+		{"https://pkg.go.dev/net/http", "pkg.go.dev"},
+		{"https://github.com/golang/go/issues/123", "golang/go"},
+		{"https://github.com/golang/vscode-go/discussions/1", "golang/vscode-go"},
+		{"https://github.com/golang/go", "github.com"},
+	} {
+		if got := Source(test.id); got != test.want {
+			t.Errorf("Source(%q) = %q, want %q", test.id, got, test.want)
+		}
+	}
+}
+
 func TestSearch(t *testing.T) {
 	ctx := context.Background()
 	lg := testutil.Slogger(t)
@@ -119,6 +141,78 @@ func TestSearch(t *testing.T) {
 	}
 }
 
+func TestPagination(t *testing.T) {
+	lg := testutil.Slogger(t)
+	embedder := llm.QuoteEmbedder()
+	db := storage.MemDB()
+	vdb := storage.MemVectorDB(db, lg, "")
+	corpus := docs.New(lg, db)
+
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("id%d", i)
+		doc := llm.EmbedDoc{Title: fmt.Sprintf("title%d", i), Text: fmt.Sprintf("text-%s", strings.Repeat("x", i))}
+		corpus.Add(id, doc.Title, doc.Text)
+		vdb.Set(id, mustEmbed(t, embedder, doc))
+	}
+	vec := mustEmbed(t, embedder, llm.EmbedDoc{Title: "title3", Text: "text-xxx"})
+
+	all := Vector(vdb, corpus, &VectorRequest{Options: Options{Limit: 10}, Vector: vec})
+	if len(all) != 10 {
+		t.Fatalf("got %d results, want 10", len(all))
+	}
+
+	// Pages of 3 end after 4 pages, the last of which
+	// has the one remaining result.
+	var paged []Result
+	opts := Options{Limit: 3}
+	pages := 0
+	for {
+		page := VectorPage(vdb, corpus, &VectorRequest{Options: opts, Vector: vec})
+		paged = append(paged, page.Results...)
+		pages++
+		if page.Next == nil {
+			break
+		}
+		if pages > 4 {
+			t.Fatalf("too many pages")
+		}
+		// Round trip the cursor through its text form.
+		next, err := ParseCursor(page.Next.String())
+		if err != nil || *next != *page.Next {
+			t.Fatalf("ParseCursor(%v) = %v, %v", page.Next, next, err)
+		}
+		opts.After = next
+	}
+	if !slices.Equal(paged, all) || pages != 4 {
+		t.Errorf("%d pages of results:\n%v\nwant 4 pages of\n%v", pages, paged, all)
+	}
+
+	// Has-more is decided after the threshold is applied:
+	// a full page whose successors are all below the threshold is the last.
+	page := VectorPage(vdb, corpus, &VectorRequest{Options: Options{Limit: 1, Threshold: all[1].Score + 1e-9}, Vector: vec})
+	if len(page.Results) != 1 || page.Next != nil {
+		t.Errorf("page above threshold = %v, next %v; want 1 result and no next", page.Results, page.Next)
+	}
+
+	// Adding a document between pages does not repeat or skip results.
+	opts = Options{Limit: 3}
+	first := VectorPage(vdb, corpus, &VectorRequest{Options: opts, Vector: vec})
+	doc := llm.EmbedDoc{Title: "title3", Text: "text-xxx"}
+	corpus.Add("new", doc.Title, doc.Text)
+	vdb.Set("new", mustEmbed(t, embedder, doc)) // the best match
+	opts.After = first.Next
+	second := VectorPage(vdb, corpus, &VectorRequest{Options: opts, Vector: vec})
+	if got, want := append(first.Results, second.Results...), all[:6]; !slices.Equal(got, want) {
+		t.Errorf("pages around insertion:\n%v\nwant\n%v", got, want)
+	}
+
+	for _, bad := range []string{"", "!", "bm8tc3BhY2U"} {
+		if _, err := ParseCursor(bad); err == nil {
+			t.Errorf("ParseCursor(%q) succeeded, want error", bad)
+		}
+	}
+}
+
 func round(rs []Result) {
 	for i := range rs {
 		rs[i].Round()
@@ -274,12 +368,13 @@ func TestOptions(t *testing.T) {
 			},
 		},
 		{
+			// The limit counts the documents that are kept.
 			name: "allow-limit",
 			options: Options{
 				AllowKind: []string{KindGoWiki, KindGitHubIssue},
-				Limit:     6,
+				Limit:     2,
 			},
-			want: []Result{results[5]},
+			want: []Result{results[5], results[6]},
 		},
 		{
 			name: "allow-threshold",
@@ -329,6 +424,96 @@ func TestOptions(t *testing.T) {
 
 }
 
+func TestFilters(t *testing.T) {
+	lg := testutil.Slogger(t)
+	embedder := llm.QuoteEmbedder()
+	db := storage.MemDB()
+	vdb := storage.MemVectorDB(db, lg, "")
+	corpus := docs.New(lg, db)
+	gh := github.New(lg, db, nil, nil)
+	gh.Testing().AddIssue("golang/go", &github.Issue{Number: 1, State: "open", CreatedAt: "2024-01-01T00:00:00Z"})
+	gh.Testing().AddIssue("golang/go", &github.Issue{Number: 2, State: "closed", CreatedAt: "2024-06-01T00:00:00Z"})
+	gh.Testing().AddIssue("golang/go", &github.Issue{Number: 3, State: "open", CreatedAt: "2025-01-01T00:00:00Z", Milestone: github.Milestone{Title: "Go1.25"}})
+	gh.Testing().AddIssue("golang/vscode-go", &github.Issue{Number: 4, State: "open", CreatedAt: "2024-03-01T00:00:00Z"})
+	gh.Testing().AddIssueEvent("golang/go", 2, &github.IssueEvent{
+		Event:       "added_to_project",
+		ProjectCard: github.ProjectCard{ProjectURL: "https://api.github.com/projects/1", ColumnName: "Incoming"},
+	})
+
+	ids := []string{
+		"https://github.com/golang/go/issues/1",
+		"https://github.com/golang/go/issues/2",
+		"https://github.com/golang/go/issues/3",
+		"https://github.com/golang/vscode-go/issues/4",
+		"https://go.dev/doc/x",
+	}
+	for i, id := range ids {
+		doc := llm.EmbedDoc{Title: fmt.Sprintf("title%d", i), Text: fmt.Sprintf("text-%s", strings.Repeat("x", i))}
+		corpus.Add(id, doc.Title, doc.Text)
+		vdb.Set(id, mustEmbed(t, embedder, doc))
+	}
+	vec := mustEmbed(t, embedder, llm.EmbedDoc{Title: "title0", Text: "text-"})
+	date := func(s string) time.Time {
+		t, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	for _, test := range []struct {
+		name string
+		opts Options
+		want []string // in any order
+	}{
+		{"none", Options{}, ids},
+		{"sources", Options{Sources: []string{"golang/go"}}, ids[:3]},
+		{"sources-host", Options{Sources: []string{"go.dev", "golang/vscode-go"}}, ids[3:]},
+		{"open", Options{State: "open"}, []string{ids[0], ids[2], ids[3]}},
+		{"closed", Options{State: "closed"}, ids[1:2]},
+		{"after", Options{CreatedAfter: date("2024-06-01")}, ids[1:3]},
+		{"before", Options{CreatedBefore: date("2024-06-01")}, []string{ids[0], ids[3]}},
+		{"range", Options{CreatedAfter: date("2024-02-01"), CreatedBefore: date("2024-12-31")}, []string{ids[1], ids[3]}},
+		{"all", Options{Sources: []string{"golang/go"}, State: "open", CreatedAfter: date("2024-06-01")}, ids[2:3]},
+		{"no-info", Options{State: "open", Info: func(string) (DocInfo, bool) { return DocInfo{}, false }}, nil},
+		{"milestone", Options{Milestone: "go1.25"}, ids[2:3]},
+		{"board-url", Options{Board: "https://api.github.com/projects/1"}, ids[1:2]},
+		{"board-title", Options{Board: "proposals", Info: func(id string) (DocInfo, bool) {
+			return DocInfo{Boards: []string{"Proposals", "https://github.com/orgs/golang/projects/17"}}, id == ids[0]
+		}}, ids[:1]},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.opts
+			if opts.Info == nil {
+				opts.Info = GitHubInfo(gh)
+			}
+			opts.Limit = len(ids)
+			if err := opts.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range Vector(vdb, corpus, &VectorRequest{Options: opts, Vector: vec}) {
+				got = append(got, r.ID)
+			}
+			want := slices.Clone(test.want)
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+
+	for _, opts := range []Options{
+		{State: "merged"},
+		{CreatedAfter: date("2025-01-01"), CreatedBefore: date("2024-01-01")},
+	} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("%+v.Validate() succeeded, want error", opts)
+		}
+	}
+}
+
 func mustEmbed(t *testing.T, embedder llm.Embedder, doc llm.EmbedDoc) llm.Vector {
 	t.Helper()
 	vec, err := embedder.EmbedDocs(context.Background(), []llm.EmbedDoc{doc})
@@ -0,0 +1,215 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package searchdiff watches for regressions in search results.
+//
+// A [Checker] runs a fixed set of benchmark queries against the
+// vector database whenever the index changes (for example, after
+// new documents are embedded or the embedding model changes),
+// and compares the top results of each query with those of the
+// previous run. It reports the churn: the fraction of the previous
+// top results that are no longer among the top results.
+// Steady growth of the corpus causes a little churn; a large amount
+// usually means that something went wrong with the embeddings.
+//
+// Each run is stored in the database:
+//
+//	(searchdiff.Report, $id) -> [Report], encoded by [storage.EncodeRecord]
+//
+// where $id is the time of the run in nanoseconds since the Unix epoch.
+package searchdiff
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/search"
+	"golang.org/x/oscar/internal/storage"
+)
+
+const (
+	// DefaultThreshold is the default maximum mean churn
+	// before a run raises an alert.
+	DefaultThreshold = 0.3
+
+	// topN is the number of top results compared for each query.
+	topN = 10
+)
+
+// A Checker runs the benchmark queries and compares their results.
+type Checker struct {
+	slog      *slog.Logger
+	db        storage.DB
+	vector    storage.VectorDB
+	docs      *docs.Corpus
+	embed     llm.Embedder
+	queries   []string
+	threshold float64
+}
+
+// New returns a new Checker that searches the vector database vdb,
+// which holds embeddings made with embed of the documents in dc,
+// and stores its reports in db.
+// The Checker has no queries until [Checker.SetQueries] is called.
+func New(lg *slog.Logger, db storage.DB, vdb storage.VectorDB, dc *docs.Corpus, embed llm.Embedder) *Checker {
+	return &Checker{
+		slog:      lg,
+		db:        db,
+		vector:    vdb,
+		docs:      dc,
+		embed:     embed,
+		threshold: DefaultThreshold,
+	}
+}
+
+// SetQueries sets the benchmark queries.
+// Changing the queries does not cause churn: results are only compared
+// for queries that were also run by the previous run.
+func (c *Checker) SetQueries(queries []string) {
+	c.queries = slices.Clone(queries)
+}
+
+// SetThreshold sets the maximum mean churn, between 0 and 1,
+// above which a run raises an alert.
+// The default is [DefaultThreshold].
+func (c *Checker) SetThreshold(t float64) {
+	c.threshold = t
+}
+
+//go:generate go run golang.org/x/oscar/internal/devtools/cmd/kindgen -type Report -kind searchdiff.Report -key ID
+
+// A Report is the result of a run of the benchmark queries.
+type Report struct {
+	ID      int64     // time of the run, in nanoseconds since the Unix epoch
+	Time    time.Time // time of the run
+	Index   string    // identifies the state of the index; see [Checker.Run]
+	Queries []*Query  // results of each query, in the order of [Checker.SetQueries]
+
+	// Churn is the mean churn of the queries
+	// compared with the previous run.
+	Churn float64
+	// Alert reports whether Churn exceeded the threshold
+	// (see [Checker.SetThreshold]).
+	Alert bool
+}
+
+// A Query is the result of one benchmark query.
+type Query struct {
+	Query   string
+	Results []string // IDs of the top results, best first
+
+	// Compared reports whether the previous run also ran the query.
+	// The other fields are only set if Compared is true.
+	Compared bool
+	Added    []string // results not in the previous run's top results
+	Removed  []string // previous run's top results no longer in the top results
+	Churn    float64  // fraction of the previous top results that were removed
+}
+
+// Run runs the benchmark queries and compares the results with the
+// last run, unless the index is unchanged since the last run.
+// The index string identifies the state of the vector database
+// (such as the latest embedding time and the embedding model):
+// Run returns the last report, without running the queries,
+// if it has the same index.
+//
+// Run returns an error if a query fails.
+// Callers should check the report's Alert field.
+func (c *Checker) Run(ctx context.Context, index string) (*Report, error) {
+	last, hasLast := LastReport(c.db)
+	if hasLast && last.Index == index {
+		return last, nil
+	}
+	c.slog.Info("searchdiff start", "index", index, "queries", len(c.queries))
+
+	now := time.Now()
+	r := &Report{ID: now.UnixNano(), Time: now, Index: index}
+	prev := make(map[string]*Query)
+	if hasLast {
+		for _, q := range last.Queries {
+			prev[q.Query] = q
+		}
+	}
+	var total float64
+	var compared int
+	for _, text := range c.queries {
+		results, err := search.Query(ctx, c.vector, c.docs, c.embed, &search.QueryRequest{
+			Options:  search.Options{Limit: topN},
+			EmbedDoc: llm.EmbedDoc{Text: text},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searchdiff: query %q: %w", text, err)
+		}
+		q := &Query{Query: text}
+		for _, res := range results {
+			q.Results = append(q.Results, res.ID)
+		}
+		if p, ok := prev[text]; ok {
+			diff(p, q)
+			total += q.Churn
+			compared++
+		}
+		r.Queries = append(r.Queries, q)
+	}
+	if compared > 0 {
+		r.Churn = total / float64(compared)
+	}
+	r.Alert = r.Churn > c.threshold
+	setReport(c.db, r)
+	c.db.Flush()
+
+	if r.Alert {
+		c.slog.Error("searchdiff churn above threshold", "churn", r.Churn, "threshold", c.threshold, "index", index)
+	} else {
+		c.slog.Info("searchdiff done", "churn", r.Churn, "compared", compared)
+	}
+	return r, nil
+}
+
+// diff sets the comparison fields of q by comparing
+// its results with those of prev, for the same query.
+func diff(prev, q *Query) {
+	q.Compared = true
+	for _, id := range q.Results {
+		if !slices.Contains(prev.Results, id) {
+			q.Added = append(q.Added, id)
+		}
+	}
+	for _, id := range prev.Results {
+		if !slices.Contains(q.Results, id) {
+			q.Removed = append(q.Removed, id)
+		}
+	}
+	if len(prev.Results) > 0 {
+		q.Churn = float64(len(q.Removed)) / float64(len(prev.Results))
+	} else if len(q.Results) > 0 {
+		// Results appeared where there were none.
+		q.Churn = 1
+	}
+}
+
+// LastReport returns the report of the last run on db, if any.
+func LastReport(db storage.DB) (*Report, bool) {
+	var last *Report
+	for r := range scanReport(db) {
+		last = r
+	}
+	return last, last != nil
+}
+
+// Reports returns the reports of the runs on db, most recent first,
+// up to a maximum of n reports.
+func Reports(db storage.DB, n int) []*Report {
+	var all []*Report
+	for r := range scanReport(db) {
+		all = append(all, r)
+	}
+	slices.Reverse(all)
+	return all[:min(n, len(all))]
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package searchdiff
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/docs"
+	"golang.org/x/oscar/internal/embeddocs"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+var ctx = context.Background()
+
+func TestChecker(t *testing.T) {
+	check := testutil.Checker(t)
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	dc := docs.New(lg, db)
+	vdb := storage.MemVectorDB(db, lg, "vecs")
+	embed := llm.QuoteEmbedder()
+
+	for i := range 20 {
+		dc.Add(fmt.Sprintf("https://go.dev/doc/%d", i), "doc", fmt.Sprintf("document number %d", i))
+	}
+	check(embeddocs.Sync(ctx, lg, vdb, embed, dc))
+
+	c := New(lg, db, vdb, dc, embed)
+	c.SetQueries([]string{"document", "number 7"})
+	r1, err := c.Run(ctx, "v1")
+	check(err)
+	if len(r1.Queries) != 2 || len(r1.Queries[0].Results) != topN || r1.Queries[0].Compared {
+		t.Fatalf("first run: report %+v", r1)
+	}
+	if r1.Churn != 0 || r1.Alert {
+		t.Errorf("first run: Churn = %v, Alert = %v, want 0, false", r1.Churn, r1.Alert)
+	}
+
+	// Same index: the last report is returned without running the queries.
+	r, err := c.Run(ctx, "v1")
+	check(err)
+	if r.ID != r1.ID {
+		t.Errorf("unchanged index: got report %d, want %d", r.ID, r1.ID)
+	}
+
+	// New index with the same vectors: no churn.
+	r2, err := c.Run(ctx, "v2")
+	check(err)
+	if r2.ID == r1.ID || r2.Churn != 0 || r2.Alert {
+		t.Fatalf("unchanged vectors: report %+v", r2)
+	}
+	for _, q := range r2.Queries {
+		if !q.Compared || q.Added != nil || q.Removed != nil {
+			t.Errorf("unchanged vectors: query %+v", q)
+		}
+	}
+
+	// Replace every vector: total churn.
+	for i := range 20 {
+		vdb.Delete(fmt.Sprintf("https://go.dev/doc/%d", i))
+		dc.Add(fmt.Sprintf("https://go.dev/new/%d", i), "doc", fmt.Sprintf("document number %d", i))
+	}
+	check(embeddocs.Sync(ctx, lg, vdb, embed, dc))
+	r3, err := c.Run(ctx, "v3")
+	check(err)
+	if r3.Churn != 1 || !r3.Alert {
+		t.Errorf("replaced vectors: Churn = %v, Alert = %v, want 1, true", r3.Churn, r3.Alert)
+	}
+	q := r3.Queries[0]
+	if diff := cmp.Diff(r2.Queries[0].Results, q.Removed); diff != "" {
+		t.Errorf("replaced vectors: Removed (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(q.Results, q.Added); diff != "" {
+		t.Errorf("replaced vectors: Added (-want, +got):\n%s", diff)
+	}
+
+	// A new query is not compared and does not cause churn.
+	c.SetQueries([]string{"document", "something else"})
+	c.SetThreshold(1)
+	r4, err := c.Run(ctx, "v4")
+	check(err)
+	if r4.Churn != 0 || r4.Alert || r4.Queries[1].Compared {
+		t.Errorf("new query: report %+v", r4)
+	}
+
+	if diff := cmp.Diff([]int64{r4.ID, r3.ID}, reportIDs(Reports(db, 2))); diff != "" {
+		t.Errorf("Reports (-want, +got):\n%s", diff)
+	}
+	if last, ok := LastReport(db); !ok || last.ID != r4.ID {
+		t.Errorf("LastReport = %+v, %v, want %d", last, ok, r4.ID)
+	}
+}
+
+func reportIDs(rs []*Report) []int64 {
+	var ids []int64
+	for _, r := range rs {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package seed provides seeded randomness for decisions that must
+// be reproducible, such as which of several equally good candidates
+// a poster chooses.
+//
+// A poster picks a new [Seed] for each run and records it
+// alongside its decisions (in its logs and in the actions it logs).
+// Running the poster again on the same inputs with the recorded seed
+// makes exactly the same decisions.
+//
+// Each decision uses its own random source, obtained from
+// [Seed.Rand] with a key identifying what is being decided
+// (typically an issue URL), so that it does not depend on
+// how many other decisions were made before it in the same run.
+package seed
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand/v2"
+	"slices"
+)
+
+// A Seed determines the random choices made during a run.
+type Seed uint64
+
+// New returns a new random seed.
+func New() Seed {
+	return Seed(rand.Uint64())
+}
+
+// Rand returns the random source for the decision identified by key.
+// The source depends only on s and key.
+func (s Seed) Rand(key string) *rand.Rand {
+	h := sha256.Sum256([]byte(key))
+	return rand.New(rand.NewPCG(uint64(s), binary.BigEndian.Uint64(h[:])))
+}
+
+// Cap returns the first n elements of list, which must be sorted
+// by decreasing score.
+//
+// If elements with equal scores straddle the cutoff, Cap chooses
+// among them using r, so that no candidate is favored just because
+// of the order in which it was found. The choice depends only on r and
+// the IDs and scores of the elements, not on the order of equal elements in list.
+// The chosen elements with the tied score are returned in ID order.
+//
+// Cap does not modify list, but the result may share its storage.
+func Cap[T any](r *rand.Rand, list []T, n int, score func(T) float64, id func(T) string) []T {
+	if n < 0 {
+		n = 0
+	}
+	if len(list) <= n {
+		return list
+	}
+	if n == 0 {
+		return list[:0]
+	}
+	s := score(list[n-1])
+	lo := n - 1
+	for lo > 0 && score(list[lo-1]) == s {
+		lo--
+	}
+	hi := n
+	for hi < len(list) && score(list[hi]) == s {
+		hi++
+	}
+	if hi == n {
+		// No tie at the cutoff.
+		return list[:n]
+	}
+
+	byID := func(x, y T) int { return cmp.Compare(id(x), id(y)) }
+	tied := slices.Clone(list[lo:hi])
+	slices.SortFunc(tied, byID)
+	r.Shuffle(len(tied), func(i, j int) { tied[i], tied[j] = tied[j], tied[i] })
+	tied = tied[:n-lo]
+	slices.SortFunc(tied, byID)
+	return append(slices.Clip(list[:lo]), tied...)
+}
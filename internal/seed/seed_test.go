@@ -0,0 +1,76 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package seed
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type cand struct {
+	ID    string
+	Score float64
+}
+
+func score(c cand) float64 { return c.Score }
+func id(c cand) string     { return c.ID }
+
+func TestCap(t *testing.T) {
+	list := []cand{
+		{"a", 0.9},
+		{"b", 0.8}, {"c", 0.8}, {"d", 0.8}, {"e", 0.8}, {"f", 0.8},
+		{"g", 0.7},
+	}
+	for _, tc := range []struct {
+		n    int
+		want []cand // nil means a tie is broken
+	}{
+		{-1, []cand{}},
+		{0, []cand{}},
+		{1, list[:1]},
+		{6, list[:6]},
+		{7, list},
+		{10, list},
+		{3, nil},
+	} {
+		got := Cap(Seed(1).Rand("key"), list, tc.n, score, id)
+		if tc.want != nil {
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Cap(%d) mismatch (-want +got):\n%s", tc.n, diff)
+			}
+			continue
+		}
+		if len(got) != tc.n || got[0] != list[0] {
+			t.Errorf("Cap(%d) = %v", tc.n, got)
+		}
+	}
+
+	// The choice depends only on the seed and key,
+	// not on the order of the tied candidates,
+	// and different seeds make different choices.
+	reversed := slices.Clone(list)
+	slices.Reverse(reversed[1:6])
+	picks := make(map[string]bool)
+	for s := range Seed(20) {
+		x := Cap(s.Rand("key"), list, 3, score, id)
+		y := Cap(s.Rand("key"), reversed, 3, score, id)
+		if !cmp.Equal(x, y) {
+			t.Errorf("seed %d: Cap depends on order: %v, %v", s, x, y)
+		}
+		if !slices.IsSortedFunc(x[1:], func(a, b cand) int { return strings.Compare(a.ID, b.ID) }) {
+			t.Errorf("seed %d: tied results not in ID order: %v", s, x)
+		}
+		picks[x[1].ID+x[2].ID] = true
+	}
+	if len(picks) < 2 {
+		t.Errorf("20 seeds made only %d different choices", len(picks))
+	}
+	if slices.Equal(list, reversed) {
+		t.Fatal("bad test")
+	}
+}
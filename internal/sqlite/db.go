@@ -0,0 +1,348 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sqlite implements a [storage.DB] and a [storage.VectorDB]
+// using a single SQLite database file, so that a small deployment
+// can keep all its state in one file, with no external services.
+// Like a Pebble database, a SQLite database must be used
+// by only one process at a time: its locks are held in memory.
+//
+// The package uses [database/sql] with the pure Go modernc.org/sqlite
+// driver, which it links in under the name [DefaultDriver].
+// [Open] accepts the name of any other registered driver.
+//
+// [Open] creates or upgrades the tables it needs, recording the
+// schema version in the table oscar_schema, so that a newer program
+// can migrate a database written by an older one.
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+
+	"golang.org/x/oscar/internal/storage"
+)
+
+// DefaultDriver is the name of the database/sql driver used by
+// [golang.org/x/oscar/internal/dbspec] to open SQLite databases.
+const DefaultDriver = "sqlite"
+
+// A DB is a [storage.DB] using SQLite.
+// Keys and values are stored in the table oscar_kv.
+type DB struct {
+	storage.MemLocker
+	sql  *sql.DB
+	slog *slog.Logger
+}
+
+// Open opens the SQLite database in file, creating it if needed,
+// using the named database/sql driver,
+// and migrates its schema to the latest version.
+func Open(ctx context.Context, lg *slog.Logger, driver, file string) (*DB, error) {
+	sdb, err := sql.Open(driver, file)
+	if err != nil {
+		lg.Error("sqlite open", "driver", driver, "file", file, "err", err)
+		return nil, err
+	}
+	// SQLite allows one writer at a time; a single connection
+	// serializes the writes instead of failing with SQLITE_BUSY.
+	sdb.SetMaxOpenConns(1)
+	db := &DB{sql: sdb, slog: lg}
+	if err := db.init(ctx); err != nil {
+		sdb.Close()
+		lg.Error("sqlite init", "file", file, "err", err)
+		return nil, err
+	}
+	return db, nil
+}
+
+// init configures the database and migrates its schema.
+func (db *DB) init(ctx context.Context) error {
+	for _, stmt := range []string{
+		// Write-ahead logging makes commits cheaper and
+		// lets readers proceed during a write.
+		`PRAGMA journal_mode = WAL`,
+		`PRAGMA synchronous = NORMAL`,
+	} {
+		if _, err := db.sql.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return db.migrate(ctx)
+}
+
+// migrations are the changes to the schema, in order.
+// Version N of the schema is the result of applying the first N migrations.
+// Migrations must never be edited or removed once released;
+// add a new one instead.
+var migrations = [][]string{
+	// 1: key-value storage.
+	// SQLite compares blobs bytewise, as storage.DB requires.
+	{
+		`CREATE TABLE oscar_kv (key BLOB PRIMARY KEY, value BLOB NOT NULL) WITHOUT ROWID`,
+	},
+	// 2: vector storage.
+	// The default BINARY collation orders IDs bytewise, as VectorDB.All requires.
+	// Embeddings are encoded with [llm.Vector.Encode].
+	{
+		`CREATE TABLE oscar_vectors (
+			namespace TEXT NOT NULL,
+			id TEXT NOT NULL,
+			embedding BLOB NOT NULL,
+			PRIMARY KEY (namespace, id)) WITHOUT ROWID`,
+	},
+}
+
+// migrate applies the migrations that db lacks,
+// all in a single transaction.
+func (db *DB) migrate(ctx context.Context) (err error) {
+	tx, err := db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS oscar_schema (version INTEGER NOT NULL)`); err != nil {
+		return err
+	}
+	var version int
+	if err := tx.QueryRowContext(ctx, `SELECT coalesce(max(version), 0) FROM oscar_schema`).Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("sqlite: database schema version %d is newer than the latest known version %d", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		for _, stmt := range migrations[i] {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("sqlite: migration %d: %w", i+1, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO oscar_schema (version) VALUES (?)`, i+1); err != nil {
+			return err
+		}
+		db.slog.Info("sqlite migrated schema", "version", i+1)
+	}
+	return tx.Commit()
+}
+
+// Panic implements [storage.DB.Panic].
+func (db *DB) Panic(msg string, args ...any) {
+	db.slog.Error(msg, args...)
+	storage.Panic(msg, args...)
+}
+
+// Get implements [storage.DB.Get].
+func (db *DB) Get(key []byte) (val []byte, ok bool) {
+	err := db.sql.QueryRowContext(context.TODO(), `SELECT value FROM oscar_kv WHERE key = ?`, key).Scan(&val)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false
+	}
+	if err != nil {
+		// unreachable except db error
+		db.Panic("sqlite get", "key", storage.Fmt(key), "err", err)
+	}
+	return notNil(val), true
+}
+
+const (
+	setSQL         = `INSERT INTO oscar_kv (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`
+	deleteSQL      = `DELETE FROM oscar_kv WHERE key = ?`
+	deleteRangeSQL = `DELETE FROM oscar_kv WHERE key >= ? AND key <= ?`
+	scanSQL        = `SELECT key, value FROM oscar_kv WHERE key >= ? AND key <= ? ORDER BY key LIMIT ?`
+)
+
+// Set implements [storage.DB.Set].
+func (db *DB) Set(key, val []byte) {
+	if len(key) == 0 {
+		db.Panic("sqlite set: empty key")
+	}
+	if _, err := db.sql.ExecContext(context.TODO(), setSQL, key, notNil(val)); err != nil {
+		// unreachable except db error
+		db.Panic("sqlite set", "key", storage.Fmt(key), "val", storage.Fmt(val), "err", err)
+	}
+}
+
+// notNil returns b, or an empty slice if b is nil,
+// which drivers would otherwise send (or return) as NULL.
+func notNil(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Delete implements [storage.DB.Delete].
+func (db *DB) Delete(key []byte) {
+	if _, err := db.sql.ExecContext(context.TODO(), deleteSQL, key); err != nil {
+		// unreachable except db error
+		db.Panic("sqlite delete", "key", storage.Fmt(key), "err", err)
+	}
+}
+
+// DeleteRange implements [storage.DB.DeleteRange].
+func (db *DB) DeleteRange(start, end []byte) {
+	if _, err := db.sql.ExecContext(context.TODO(), deleteRangeSQL, notNil(start), notNil(end)); err != nil {
+		// unreachable except db error
+		db.Panic("sqlite delete range", "start", storage.Fmt(start), "end", storage.Fmt(end), "err", err)
+	}
+}
+
+// scanPage is the number of rows Scan and VectorDB.All read per query.
+const scanPage = 1000
+
+// Scan implements [storage.DB.Scan].
+// It reads the keys a page at a time, and does not hold the connection
+// while the caller's loop body runs, so the body may use the database.
+func (db *DB) Scan(start, end []byte) iter.Seq2[[]byte, func() []byte] {
+	start = bytes.Clone(notNil(start))
+	end = bytes.Clone(notNil(end))
+	return func(yield func(key []byte, val func() []byte) bool) {
+		for {
+			keys, vals := db.scan(start, end)
+			for i, key := range keys {
+				if !yield(key, func() []byte { return vals[i] }) {
+					return
+				}
+			}
+			if len(keys) < scanPage {
+				return
+			}
+			// Continue with the smallest key after the last one.
+			start = append(bytes.Clone(keys[len(keys)-1]), 0)
+		}
+	}
+}
+
+// scan returns the first scanPage keys in [start, end] and their values.
+func (db *DB) scan(start, end []byte) (keys, vals [][]byte) {
+	rows, err := db.sql.QueryContext(context.TODO(), scanSQL, start, end, scanPage)
+	if err != nil {
+		// unreachable except db error
+		db.Panic("sqlite scan", "start", storage.Fmt(start), "end", storage.Fmt(end), "err", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, val []byte
+		if err := rows.Scan(&key, &val); err != nil {
+			// unreachable except db error
+			db.Panic("sqlite scan row", "err", err)
+		}
+		keys = append(keys, key)
+		vals = append(vals, notNil(val))
+	}
+	if err := rows.Err(); err != nil {
+		// unreachable except db error
+		db.Panic("sqlite scan", "start", storage.Fmt(start), "end", storage.Fmt(end), "err", err)
+	}
+	return keys, vals
+}
+
+// Flush implements [storage.DB.Flush].
+// SQLite makes every committed change durable, so Flush does nothing.
+func (db *DB) Flush() {}
+
+// Close implements [storage.DB.Close].
+func (db *DB) Close() {
+	if err := db.sql.Close(); err != nil {
+		// unreachable except db error
+		db.Panic("sqlite close", "err", err)
+	}
+}
+
+// Batch implements [storage.DB.Batch].
+func (db *DB) Batch() storage.Batch {
+	return &batch{db: db}
+}
+
+// A batch is a [storage.Batch] for a [DB].
+// It applies its operations in a single transaction.
+type batch struct {
+	db   *DB
+	ops  []op
+	size int // total bytes of keys and values in ops
+}
+
+// An op is a single SQL statement in a batch.
+type op struct {
+	query string
+	args  []any
+}
+
+// maxBatch is the number of bytes of keys and values
+// at which MaybeApply applies a batch.
+const maxBatch = 16 << 20
+
+func (b *batch) add(query string, size int, args ...any) {
+	b.ops = append(b.ops, op{query, args})
+	b.size += size
+}
+
+// Set implements [storage.Batch.Set].
+func (b *batch) Set(key, val []byte) {
+	if len(key) == 0 {
+		b.db.Panic("sqlite batch set: empty key")
+	}
+	b.add(setSQL, len(key)+len(val), bytes.Clone(key), bytes.Clone(notNil(val)))
+}
+
+// Delete implements [storage.Batch.Delete].
+func (b *batch) Delete(key []byte) {
+	b.add(deleteSQL, len(key), bytes.Clone(key))
+}
+
+// DeleteRange implements [storage.Batch.DeleteRange].
+func (b *batch) DeleteRange(start, end []byte) {
+	b.add(deleteRangeSQL, len(start)+len(end), bytes.Clone(notNil(start)), bytes.Clone(notNil(end)))
+}
+
+// MaybeApply implements [storage.Batch.MaybeApply].
+func (b *batch) MaybeApply() bool {
+	if b.size > maxBatch {
+		b.Apply()
+		return true
+	}
+	return false
+}
+
+// Apply implements [storage.Batch.Apply].
+func (b *batch) Apply() {
+	if err := b.db.exec(b.ops); err != nil {
+		// unreachable except db error
+		b.db.Panic("sqlite batch apply", "ops", len(b.ops), "err", err)
+	}
+	b.ops = nil
+	b.size = 0
+}
+
+// exec runs the ops in a single transaction.
+func (db *DB) exec(ops []op) (err error) {
+	if len(ops) == 0 {
+		return nil
+	}
+	ctx := context.TODO()
+	tx, err := db.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	for _, o := range ops {
+		if _, err := tx.ExecContext(ctx, o.query, o.args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+// testDriver returns the driver named by $OSCAR_SQLITE_DRIVER
+// (default [DefaultDriver]), skipping the test if it is not linked in.
+func testDriver(t *testing.T) string {
+	driver := os.Getenv("OSCAR_SQLITE_DRIVER")
+	if driver == "" {
+		driver = DefaultDriver
+	}
+	if !slices.Contains(sql.Drivers(), driver) {
+		t.Skipf("database/sql driver %q not linked in", driver)
+	}
+	return driver
+}
+
+// openTestDB opens a new database in a temporary directory,
+// returning it and its file.
+func openTestDB(t *testing.T) (*DB, string) {
+	driver := testDriver(t)
+	file := filepath.Join(t.TempDir(), "oscar.db")
+	db, err := Open(context.Background(), testutil.Slogger(t), driver, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+	return db, file
+}
+
+func TestDB(t *testing.T) {
+	db, file := openTestDB(t)
+	storage.TestDB(t, db)
+	storage.TestDBLock(t, db)
+
+	// Reopening the database finds it migrated, with its contents.
+	db.Set([]byte("k"), []byte("v"))
+	db.Close()
+	db2, err := Open(context.Background(), testutil.Slogger(t), testDriver(t), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db2.Close()
+	var version int
+	if err := db2.sql.QueryRow(`SELECT max(version) FROM oscar_schema`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations) {
+		t.Errorf("schema version = %d, want %d", version, len(migrations))
+	}
+	if v, ok := db2.Get([]byte("k")); !ok || string(v) != "v" {
+		t.Errorf("after reopen, Get(k) = %q, %v, want %q, true", v, ok, "v")
+	}
+}
+
+func TestVectorDB(t *testing.T) {
+	db, _ := openTestDB(t)
+	storage.TestVectorDB(t, func() storage.VectorDB { return NewVectorDB(db, "test") })
+	storage.TestVectorDBFilter(t, NewVectorDB(db, "filter"))
+
+	// Namespaces are separate.
+	NewVectorDB(db, "other").Set("x", llm.Vector{1, 0})
+	if _, ok := NewVectorDB(db, "test").Get("x"); ok {
+		t.Error("vector set in namespace other is visible in namespace test")
+	}
+}
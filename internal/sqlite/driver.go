@@ -0,0 +1,9 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+// Link in the pure Go modernc.org/sqlite driver,
+// which registers itself as [DefaultDriver].
+import _ "modernc.org/sqlite"
@@ -0,0 +1,207 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"context"
+	"iter"
+
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// A VectorDB is a [storage.VectorDB] using SQLite.
+// Vectors are stored in the table oscar_vectors, keyed by namespace and ID.
+//
+// Search is a brute-force scan over all the vectors in the namespace,
+// which is fast enough for the corpora of small projects
+// and needs no SQLite extension.
+type VectorDB struct {
+	db        *DB
+	namespace string
+}
+
+// NewVectorDB returns a [VectorDB] storing vectors in db
+// under the given namespace.
+// Namespaces allow multiple vector DBs to be stored in the same database.
+func NewVectorDB(db *DB, namespace string) *VectorDB {
+	return &VectorDB{db: db, namespace: namespace}
+}
+
+const (
+	vecSetSQL    = `INSERT INTO oscar_vectors (namespace, id, embedding) VALUES (?, ?, ?) ON CONFLICT (namespace, id) DO UPDATE SET embedding = excluded.embedding`
+	vecDeleteSQL = `DELETE FROM oscar_vectors WHERE namespace = ? AND id = ?`
+	vecGetSQL    = `SELECT embedding FROM oscar_vectors WHERE namespace = ? AND id = ?`
+	vecAllSQL    = `SELECT id, embedding FROM oscar_vectors WHERE namespace = ? AND id > ? ORDER BY id LIMIT ?`
+)
+
+// Set implements [storage.VectorDB.Set].
+func (db *VectorDB) Set(id string, vec llm.Vector) {
+	if id == "" {
+		db.db.Panic("sqlite VectorDB Set: empty ID")
+	}
+	if _, err := db.db.sql.ExecContext(context.TODO(), vecSetSQL, db.namespace, id, notNil(vec.Encode())); err != nil {
+		// unreachable except db error
+		db.db.Panic("sqlite VectorDB Set", "namespace", db.namespace, "id", id, "err", err)
+	}
+}
+
+// Delete implements [storage.VectorDB.Delete].
+func (db *VectorDB) Delete(id string) {
+	if _, err := db.db.sql.ExecContext(context.TODO(), vecDeleteSQL, db.namespace, id); err != nil {
+		// unreachable except db error
+		db.db.Panic("sqlite VectorDB Delete", "namespace", db.namespace, "id", id, "err", err)
+	}
+}
+
+// Get implements [storage.VectorDB.Get].
+func (db *VectorDB) Get(id string) (llm.Vector, bool) {
+	rows, err := db.db.sql.QueryContext(context.TODO(), vecGetSQL, db.namespace, id)
+	if err != nil {
+		// unreachable except db error
+		db.db.Panic("sqlite VectorDB Get", "namespace", db.namespace, "id", id, "err", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			// unreachable except db error
+			db.db.Panic("sqlite VectorDB Get", "namespace", db.namespace, "id", id, "err", err)
+		}
+		return nil, false
+	}
+	var enc []byte
+	if err := rows.Scan(&enc); err != nil {
+		// unreachable except db error
+		db.db.Panic("sqlite VectorDB Get", "namespace", db.namespace, "id", id, "err", err)
+	}
+	return db.decode(id, enc), true
+}
+
+// decode decodes the stored vector with the given ID,
+// panicking if it is corrupt.
+func (db *VectorDB) decode(id string, enc []byte) llm.Vector {
+	if len(enc)%4 != 0 {
+		db.db.Panic("sqlite VectorDB corrupt vector", "namespace", db.namespace, "id", id, "len", len(enc))
+	}
+	var vec llm.Vector
+	vec.Decode(enc)
+	if vec == nil {
+		vec = llm.Vector{}
+	}
+	return vec
+}
+
+// All implements [storage.VectorDB.All].
+// Like [DB.Scan], it reads the vectors a page at a time.
+func (db *VectorDB) All() iter.Seq2[string, func() llm.Vector] {
+	return func(yield func(string, func() llm.Vector) bool) {
+		last := ""
+		for {
+			ids, encs := db.all(last)
+			for i, id := range ids {
+				if !yield(id, func() llm.Vector { return db.decode(id, encs[i]) }) {
+					return
+				}
+			}
+			if len(ids) < scanPage {
+				return
+			}
+			last = ids[len(ids)-1]
+		}
+	}
+}
+
+// all returns the first scanPage IDs after last and their encoded vectors.
+func (db *VectorDB) all(last string) (ids []string, encs [][]byte) {
+	rows, err := db.db.sql.QueryContext(context.TODO(), vecAllSQL, db.namespace, last, scanPage)
+	if err != nil {
+		// unreachable except db error
+		db.db.Panic("sqlite VectorDB All", "namespace", db.namespace, "err", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var enc []byte
+		if err := rows.Scan(&id, &enc); err != nil {
+			// unreachable except db error
+			db.db.Panic("sqlite VectorDB All row", "namespace", db.namespace, "err", err)
+		}
+		ids = append(ids, id)
+		encs = append(encs, enc)
+	}
+	if err := rows.Err(); err != nil {
+		// unreachable except db error
+		db.db.Panic("sqlite VectorDB All", "namespace", db.namespace, "err", err)
+	}
+	return ids, encs
+}
+
+// Search implements [storage.VectorDB.Search].
+// As in [storage.MemVectorDB], vectors of a different length
+// than vec are ignored, and ties are broken by ID.
+func (db *VectorDB) Search(vec llm.Vector, n int, keep storage.VectorFilter) []storage.VectorResult {
+	if len(vec) == 0 || n <= 0 {
+		return nil
+	}
+	best := storage.NewVectorTop(n, keep)
+	for id, v := range db.All() {
+		if w := v(); len(w) == len(vec) {
+			best.Add(storage.VectorResult{ID: id, Score: vec.Dot(w)})
+		}
+	}
+	return best.Take()
+}
+
+// Flush implements [storage.VectorDB.Flush].
+// Like [DB.Flush], it does nothing.
+func (db *VectorDB) Flush() {}
+
+// Batch implements [storage.VectorDB.Batch].
+func (db *VectorDB) Batch() storage.VectorBatch {
+	return &vectorBatch{db: db}
+}
+
+// A vectorBatch is a [storage.VectorBatch] for a [VectorDB].
+// It applies its operations in a single transaction.
+type vectorBatch struct {
+	db   *VectorDB
+	ops  []op
+	size int // bytes of IDs and encoded vectors in ops
+}
+
+// Set implements [storage.VectorBatch.Set].
+func (b *vectorBatch) Set(id string, vec llm.Vector) {
+	if id == "" {
+		b.db.db.Panic("sqlite VectorDB batch Set: empty ID")
+	}
+	enc := notNil(vec.Encode())
+	b.ops = append(b.ops, op{vecSetSQL, []any{b.db.namespace, id, enc}})
+	b.size += len(id) + len(enc)
+}
+
+// Delete implements [storage.VectorBatch.Delete].
+func (b *vectorBatch) Delete(id string) {
+	b.ops = append(b.ops, op{vecDeleteSQL, []any{b.db.namespace, id}})
+	b.size += len(id)
+}
+
+// MaybeApply implements [storage.VectorBatch.MaybeApply].
+func (b *vectorBatch) MaybeApply() bool {
+	if b.size > maxBatch {
+		b.Apply()
+		return true
+	}
+	return false
+}
+
+// Apply implements [storage.VectorBatch.Apply].
+func (b *vectorBatch) Apply() {
+	if err := b.db.db.exec(b.ops); err != nil {
+		// unreachable except db error
+		b.db.db.Panic("sqlite VectorDB batch apply", "namespace", b.db.namespace, "ops", len(b.ops), "err", err)
+	}
+	b.ops = nil
+	b.size = 0
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"golang.org/x/oscar/internal/llm"
+	"rsc.io/ordered"
+)
+
+// A backup, written by [Export] and read by [Import], is a portable
+// copy of a [DB] and of some [VectorDB] namespaces, independent of
+// the implementations that held them.
+//
+// It is a sequence of JSON values, one per line.
+// The first is a [BackupHeader]. Each of the others except the last
+// is either a key-value pair ({"Key": ..., "Value": ...}, with the bytes
+// in base64) or a vector ({"Namespace": ..., "ID": ..., "Vector": [...]}).
+// The last ({"End": ...}) holds the [BackupStats] of the backup,
+// so that a truncated backup can be detected.
+
+// backupFormat identifies the format of a backup.
+// It changes if the format does.
+const backupFormat = "oscar-backup-1"
+
+// A BackupHeader is the first line of a backup.
+type BackupHeader struct {
+	Format     string    // backup format; always "oscar-backup-1"
+	Time       time.Time // when the export started
+	Namespaces []string  // vector namespaces in the backup
+}
+
+// A backupRecord is a line of a backup after the header:
+// either a key-value pair or a vector.
+type backupRecord struct {
+	Key       []byte       `json:",omitempty"`
+	Value     []byte       `json:",omitempty"`
+	Namespace string       `json:",omitempty"`
+	ID        string       `json:",omitempty"`
+	Vector    llm.Vector   `json:",omitempty"`
+	End       *BackupStats `json:",omitempty"`
+}
+
+// BackupStats are the numbers of entries written by [Export]
+// or read by [Import].
+type BackupStats struct {
+	Pairs   int64 // key-value pairs
+	Vectors int64 // vectors, in all namespaces
+}
+
+// Export writes a backup of db and of the vector databases vdbs,
+// keyed by namespace, to w.
+//
+// Export does not take a consistent snapshot: entries written
+// while it runs may or may not be in the backup.
+// It skips the entries of db that hold the vectors of
+// the namespaces in vdbs, if db holds them (see [MemVectorDB]),
+// since those vectors are written from vdbs instead.
+//
+// Export returns an error only if writing to w fails.
+// Like other storage operations, it panics on database errors.
+func Export(w io.Writer, db DB, vdbs map[string]VectorDB) (BackupStats, error) {
+	namespaces := slices.Sorted(maps.Keys(vdbs))
+	bw, err := NewBackupWriter(w, namespaces)
+	if err != nil {
+		return BackupStats{}, err
+	}
+
+	// The vectors of a MemVectorDB with namespace ns are
+	// stored under keys beginning with ("llm.Vector", ns).
+	var skip [][]byte
+	for _, ns := range namespaces {
+		skip = append(skip, ordered.Encode("llm.Vector", ns))
+	}
+	for key, val := range db.Scan(nil, ordered.Encode(ordered.Inf)) {
+		if slices.ContainsFunc(skip, func(prefix []byte) bool { return bytes.HasPrefix(key, prefix) }) {
+			continue
+		}
+		if err := bw.WritePair(key, val()); err != nil {
+			return bw.stats, err
+		}
+	}
+
+	for _, ns := range namespaces {
+		for id, vec := range vdbs[ns].All() {
+			if err := bw.WriteVector(ns, id, vec()); err != nil {
+				return bw.stats, err
+			}
+		}
+	}
+	return bw.Close()
+}
+
+// A BackupWriter writes a backup (see [Export]) one entry at a time,
+// for callers that choose the entries themselves, such as
+// a garbage collector archiving the entries it deletes.
+// The result can be read by [Import] like any other backup.
+type BackupWriter struct {
+	bw         *bufio.Writer
+	enc        *json.Encoder
+	namespaces []string
+	stats      BackupStats
+}
+
+// NewBackupWriter returns a BackupWriter that writes to w a backup
+// holding vectors of the given namespaces.
+// It writes the [BackupHeader] immediately.
+func NewBackupWriter(w io.Writer, namespaces []string) (*BackupWriter, error) {
+	bw := bufio.NewWriter(w)
+	b := &BackupWriter{bw: bw, enc: json.NewEncoder(bw), namespaces: namespaces}
+	if err := b.enc.Encode(&BackupHeader{Format: backupFormat, Time: time.Now(), Namespaces: namespaces}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// WritePair writes a key-value pair to the backup.
+func (b *BackupWriter) WritePair(key, val []byte) error {
+	if err := b.enc.Encode(&backupRecord{Key: key, Value: val}); err != nil {
+		return err
+	}
+	b.stats.Pairs++
+	return nil
+}
+
+// WriteVector writes a vector of the namespace ns to the backup.
+// The namespace must be one of those passed to [NewBackupWriter].
+func (b *BackupWriter) WriteVector(ns, id string, vec llm.Vector) error {
+	if !slices.Contains(b.namespaces, ns) {
+		return fmt.Errorf("storage: backup of %v: vector in namespace %q", b.namespaces, ns)
+	}
+	if err := b.enc.Encode(&backupRecord{Namespace: ns, ID: id, Vector: vec}); err != nil {
+		return err
+	}
+	b.stats.Vectors++
+	return nil
+}
+
+// Close ends the backup and flushes it to the underlying writer.
+// It does not close that writer.
+// It returns the numbers of entries written.
+func (b *BackupWriter) Close() (BackupStats, error) {
+	if err := b.enc.Encode(&backupRecord{End: &b.stats}); err != nil {
+		return b.stats, err
+	}
+	return b.stats, b.bw.Flush()
+}
+
+// Import reads a backup written by [Export] from r,
+// writing its key-value pairs to db and its vectors to
+// the vector database vdb(namespace) of their namespace.
+// Import calls vdb at most once per namespace.
+//
+// Import adds to the databases: it overwrites the entries
+// in the backup but does not delete other entries.
+// To restore a backup exactly, import it into empty databases.
+//
+// Import returns an error if r is not a valid backup,
+// after applying the entries read before the error.
+// Like other storage operations, it panics on database errors.
+func Import(r io.Reader, db DB, vdb func(namespace string) VectorDB) (BackupStats, error) {
+	var stats BackupStats
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var hdr BackupHeader
+	if err := dec.Decode(&hdr); err != nil || hdr.Format != backupFormat {
+		return stats, errors.New("storage: not an oscar backup")
+	}
+
+	b := db.Batch()
+	vdbs := make(map[string]VectorDB)
+	vbs := make(map[string]VectorBatch)
+	defer func() {
+		b.Apply()
+		db.Flush()
+		for ns, vb := range vbs {
+			vb.Apply()
+			vdbs[ns].Flush()
+		}
+	}()
+
+	for {
+		var rec backupRecord
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			return stats, errors.New("storage: reading backup: truncated")
+		}
+		if err != nil {
+			return stats, fmt.Errorf("storage: reading backup: %w", err)
+		}
+		switch {
+		case rec.End != nil:
+			if *rec.End != stats {
+				return stats, fmt.Errorf("storage: reading backup: read %+v, want %+v", stats, *rec.End)
+			}
+			if dec.More() {
+				return stats, errors.New("storage: reading backup: data after end")
+			}
+			return stats, nil
+		case len(rec.Key) > 0 && rec.Namespace == "":
+			b.Set(rec.Key, rec.Value)
+			b.MaybeApply()
+			stats.Pairs++
+		case len(rec.Key) == 0 && rec.ID != "" && slices.Contains(hdr.Namespaces, rec.Namespace):
+			vb := vbs[rec.Namespace]
+			if vb == nil {
+				vdbs[rec.Namespace] = vdb(rec.Namespace)
+				vb = vdbs[rec.Namespace].Batch()
+				vbs[rec.Namespace] = vb
+			}
+			vb.Set(rec.ID, rec.Vector)
+			vb.MaybeApply()
+			stats.Vectors++
+		default:
+			return stats, fmt.Errorf("storage: reading backup: invalid entry after %d pairs and %d vectors", stats.Pairs, stats.Vectors)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/oscar/internal/testutil"
+	"rsc.io/ordered"
+)
+
+func TestBackup(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := MemDB()
+	db.Set(ordered.Encode("a", 1), []byte("one"))
+	db.Set(ordered.Encode("a", 2), nil)
+	db.Set(ordered.Encode("b"), []byte{0, 0xff})
+	vdb := MemVectorDB(db, lg, "v")
+	vdb.Set("apple", embed("apple"))
+	vdb.Set("orange", embed("orange"))
+	odb := MemVectorDB(db, lg, "other") // not exported as vectors
+	odb.Set("pear", embed("pear"))
+
+	var buf bytes.Buffer
+	stats, err := Export(&buf, db, map[string]VectorDB{"v": vdb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The vectors of "other" are exported as key-value pairs.
+	if want := (BackupStats{Pairs: 4, Vectors: 2}); stats != want {
+		t.Errorf("Export stats = %+v, want %+v", stats, want)
+	}
+	backup := buf.String()
+
+	db2 := MemDB()
+	var vdb2 VectorDB
+	stats, err = Import(strings.NewReader(backup), db2, func(ns string) VectorDB {
+		if ns != "v" || vdb2 != nil {
+			t.Fatalf("Import opened namespace %q", ns)
+		}
+		vdb2 = MemVectorDB(db2, lg, ns)
+		return vdb2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (BackupStats{Pairs: 4, Vectors: 2}); stats != want {
+		t.Errorf("Import stats = %+v, want %+v", stats, want)
+	}
+	if have, want := dump(db2), dump(db); !slices.Equal(have, want) {
+		t.Errorf("imported DB:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+	if have := MemVectorDB(db2, lg, "other").Search(embed("pear"), 1, nil); len(have) != 1 || have[0].ID != "pear" {
+		t.Errorf("imported other namespace: Search(pear) = %v", have)
+	}
+
+	// Invalid backups are rejected.
+	for _, bad := range []string{
+		"",
+		`{"Format":"other"}`,
+		strings.Replace(backup, `"ID":"apple"`, `"ID":""`, 1),
+		backup[:len(backup)-10],
+		backup[:strings.LastIndex(backup[:len(backup)-1], "\n")+1], // missing end
+		backup + backup[strings.Index(backup, "\n")+1:],            // data after end
+	} {
+		if _, err := Import(strings.NewReader(bad), MemDB(), func(ns string) VectorDB { return MemVectorDB(MemDB(), lg, ns) }); err == nil {
+			t.Errorf("Import(%.40q...) succeeded, want error", bad)
+		}
+	}
+}
+
+// dump returns the key-value pairs of db, formatted.
+func dump(db DB) []string {
+	var list []string
+	for key, val := range db.Scan(nil, ordered.Encode(ordered.Inf)) {
+		list = append(list, Fmt(key)+": "+Fmt(val()))
+	}
+	return list
+}
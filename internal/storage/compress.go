@@ -0,0 +1,165 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"iter"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressPrefix marks a stored value as a zstd-compressed frame.
+// No JSON or UTF-8 encoded value can begin with the byte 0xff,
+// so in practice uncompressed values never carry the prefix.
+// An uncompressed value that does begin with compressPrefix
+// is compressed regardless of its size, so that every stored value
+// beginning with the prefix is a compressed value.
+const compressPrefix = "\xffZ"
+
+// A CompressionReporter is a database that compresses
+// some of its values and can report how much space that saves.
+type CompressionReporter interface {
+	CompressionStats() CompressionStats
+}
+
+// CompressionStats describes the values written by a [CompressionReporter]
+// since it was created.
+type CompressionStats struct {
+	Values     int64 // number of values written
+	Compressed int64 // number of values stored compressed
+	Bytes      int64 // total bytes of values written, before compression
+	Stored     int64 // total bytes of values stored, after compression
+}
+
+// Saved returns the number of bytes saved by compression.
+func (s CompressionStats) Saved() int64 {
+	return s.Bytes - s.Stored
+}
+
+// A compressedDB is a DB that transparently compresses large values.
+type compressedDB struct {
+	DB
+	threshold int
+	enc       *zstd.Encoder
+	dec       *zstd.Decoder
+
+	values     atomic.Int64
+	compressed atomic.Int64
+	bytes      atomic.Int64
+	stored     atomic.Int64
+}
+
+// NewCompressedDB returns a DB that stores its values in db,
+// compressing with zstd each value of at least threshold bytes.
+// Reads decompress values transparently, and values written
+// to db before compression was enabled are returned unchanged,
+// so an existing database can be wrapped at any time.
+// Values written through the returned DB must be read through
+// a compressed DB as well.
+//
+// The returned DB implements [CompressionReporter].
+func NewCompressedDB(db DB, threshold int) DB {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		// Only possible with invalid options.
+		panic(err)
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	if err != nil {
+		panic(err)
+	}
+	return &compressedDB{
+		DB:        db,
+		threshold: max(threshold, 1),
+		enc:       enc,
+		dec:       dec,
+	}
+}
+
+// CompressionStats implements [CompressionReporter].
+func (db *compressedDB) CompressionStats() CompressionStats {
+	return CompressionStats{
+		Values:     db.values.Load(),
+		Compressed: db.compressed.Load(),
+		Bytes:      db.bytes.Load(),
+		Stored:     db.stored.Load(),
+	}
+}
+
+// compress returns the stored form of val.
+func (db *compressedDB) compress(val []byte) []byte {
+	db.values.Add(1)
+	db.bytes.Add(int64(len(val)))
+	if len(val) < db.threshold && !bytes.HasPrefix(val, []byte(compressPrefix)) {
+		db.stored.Add(int64(len(val)))
+		return val
+	}
+	z := db.enc.EncodeAll(val, []byte(compressPrefix))
+	db.compressed.Add(1)
+	db.stored.Add(int64(len(z)))
+	return z
+}
+
+// decompress returns the value whose stored form is z.
+func (db *compressedDB) decompress(key, z []byte) []byte {
+	if !bytes.HasPrefix(z, []byte(compressPrefix)) {
+		return z
+	}
+	val, err := db.dec.DecodeAll(z[len(compressPrefix):], nil)
+	if err != nil {
+		db.Panic("compresseddb decompress", "key", string(key), "err", err)
+	}
+	return val
+}
+
+// Set sets the value associated with key to val.
+func (db *compressedDB) Set(key, val []byte) {
+	db.DB.Set(key, db.compress(val))
+}
+
+// Get returns the value associated with the key.
+func (db *compressedDB) Get(key []byte) (val []byte, ok bool) {
+	z, ok := db.DB.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return db.decompress(key, z), true
+}
+
+// Scan returns an iterator over all key-value pairs with start ≤ key ≤ end.
+func (db *compressedDB) Scan(start, end []byte) iter.Seq2[[]byte, func() []byte] {
+	return func(yield func([]byte, func() []byte) bool) {
+		for key, getVal := range db.DB.Scan(start, end) {
+			if !yield(key, func() []byte { return db.decompress(key, getVal()) }) {
+				return
+			}
+		}
+	}
+}
+
+// Batch returns a new [Batch] that compresses the values it sets.
+func (db *compressedDB) Batch() Batch {
+	return &compressedBatch{Batch: db.DB.Batch(), db: db}
+}
+
+// Close closes the underlying database and releases
+// the compression state.
+func (db *compressedDB) Close() {
+	db.DB.Close()
+	db.enc.Close()
+	db.dec.Close()
+}
+
+// A compressedBatch is a Batch for a compressedDB.
+type compressedBatch struct {
+	Batch
+	db *compressedDB
+}
+
+func (b *compressedBatch) Set(key, val []byte) {
+	b.Batch.Set(key, b.db.compress(val))
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"rsc.io/ordered"
+)
+
+func TestCompressedDB(t *testing.T) {
+	db := NewCompressedDB(MemDB(), 1)
+	TestDB(t, db)
+	TestDBLock(t, db)
+}
+
+func TestCompressedDBValues(t *testing.T) {
+	base := MemDB()
+	db := NewCompressedDB(base, 100)
+	o := func(n int) []byte { return ordered.Encode(n) }
+
+	small := []byte(`{"x":1}`)
+	large := []byte(strings.Repeat(`{"body":"hello, world"}`, 100))
+	prefixed := []byte(compressPrefix + "x")
+
+	db.Set(o(1), small)
+	db.Set(o(2), large)
+	b := db.Batch()
+	b.Set(o(3), prefixed)
+	b.Apply()
+
+	// Values read back unchanged.
+	want := [][]byte{small, large, prefixed}
+	for i, w := range want {
+		if v, ok := db.Get(o(i + 1)); !ok || !bytes.Equal(v, w) {
+			t.Errorf("Get(%d) = %q, %v, want %q, true", i+1, v, ok, w)
+		}
+	}
+	i := 0
+	for _, vf := range db.Scan(o(1), o(3)) {
+		if v := vf(); !bytes.Equal(v, want[i]) {
+			t.Errorf("Scan value %d = %q, want %q", i+1, v, want[i])
+		}
+		i++
+	}
+
+	// Only the large value and the prefixed value are stored compressed.
+	if v, _ := base.Get(o(1)); !bytes.Equal(v, small) {
+		t.Errorf("stored small value = %q, want %q", v, small)
+	}
+	if v, _ := base.Get(o(2)); !bytes.HasPrefix(v, []byte(compressPrefix)) || len(v) >= len(large) {
+		t.Errorf("stored large value not compressed (%d bytes)", len(v))
+	}
+	if v, _ := base.Get(o(3)); bytes.Equal(v, prefixed) {
+		t.Errorf("stored prefixed value not compressed")
+	}
+
+	// Values written before compression are read unchanged.
+	base.Set(o(4), large)
+	if v, _ := db.Get(o(4)); !bytes.Equal(v, large) {
+		t.Errorf("Get(uncompressed) = %q, want %q", v, large)
+	}
+
+	st := db.(CompressionReporter).CompressionStats()
+	if st.Values != 3 || st.Compressed != 2 {
+		t.Errorf("stats = %+v, want 3 values, 2 compressed", st)
+	}
+	if wantBytes := int64(len(small) + len(large) + len(prefixed)); st.Bytes != wantBytes {
+		t.Errorf("stats.Bytes = %d, want %d", st.Bytes, wantBytes)
+	}
+	if st.Saved() <= 0 {
+		t.Errorf("stats.Saved() = %d, want > 0", st.Saved())
+	}
+}
@@ -15,7 +15,6 @@ import (
 	"golang.org/x/oscar/internal/llm"
 	"rsc.io/omap"
 	"rsc.io/ordered"
-	"rsc.io/top"
 )
 
 // A MemLocker is a single-process implementation
@@ -199,7 +198,14 @@ type memVectorDB struct {
 	namespace string
 
 	mu    sync.RWMutex
-	cache omap.Map[string, []float32] // in-memory cache of all vectors, indexed by id
+	cache omap.Map[string, []float32] // in-memory cache of vectors, indexed by id
+
+	// Memory accounting (see [MemVectorDBWithLimit]), guarded by mu.
+	limit   int64           // maximum bytes of cached vectors; 0 means no limit
+	used    int64           // bytes of cached vectors
+	cached  int             // number of cached vectors
+	spilled map[string]bool // ids of vectors not cached (stored only in storage)
+	warned  memWarning      // most severe warning logged since usage was last low
 }
 
 // MemVectorDB returns a VectorDB that stores its vectors in db
@@ -214,6 +220,7 @@ type memVectorDB struct {
 // Set method.
 //
 // A MemVectorDB requires approximately 3kB of memory per stored vector.
+// To bound its memory use, see [MemVectorDBWithLimit].
 //
 // The db keys used by a MemVectorDB have the form
 //
@@ -221,6 +228,30 @@ type memVectorDB struct {
 //
 // where id is the document ID passed to Set.
 func MemVectorDB(db DB, lg *slog.Logger, namespace string) VectorDB {
+	return MemVectorDBWithLimit(db, lg, namespace, 0)
+}
+
+// MemVectorDBWithLimit is like [MemVectorDB], but it caches at most
+// limit bytes of vectors in memory (no limit if limit is 0).
+// Vectors that do not fit are "spilled": they are kept only in db,
+// and Get, All and Search read them from db as needed,
+// which is slower but does not exhaust memory.
+// When deletions free memory, spilled vectors are cached again.
+// MemVectorDBWithLimit panics if limit is negative.
+//
+// The limit covers only the cached vectors. It does not cover
+// the underlying db's own caches, nor memory used by wrappers
+// such as the HNSW graph of package internal/ann, nor the set of
+// spilled IDs, which is small compared to their vectors.
+//
+// The returned VectorDB implements [MemoryReporter].
+// It logs a warning when its memory use nears the limit
+// and when it starts spilling vectors.
+func MemVectorDBWithLimit(db DB, lg *slog.Logger, namespace string, limit int64) VectorDB {
+	if limit < 0 {
+		db.Panic("MemVectorDBWithLimit: negative limit", "namespace", namespace, "limit", limit)
+	}
+
 	// NOTE: We could cut the memory per stored vector in half by quantizing to int16.
 	//
 	// The worst case score error in a dot product over 768 entries
@@ -251,6 +282,8 @@ func MemVectorDB(db DB, lg *slog.Logger, namespace string) VectorDB {
 		storage:   db,
 		slog:      lg,
 		namespace: namespace,
+		limit:     limit,
+		spilled:   make(map[string]bool),
 	}
 
 	// Load all the previously-stored vectors.
@@ -264,18 +297,11 @@ func MemVectorDB(db DB, lg *slog.Logger, namespace string) VectorDB {
 			// unreachable except data corruption
 			panic(fmt.Errorf("MemVectorDB decode key=%v: %v", Fmt(key), err))
 		}
-		val := getVal()
-		if len(val)%4 != 0 {
-			// unreachable except data corruption
-			panic(fmt.Errorf("MemVectorDB decode key=%v bad len(val)=%d", Fmt(key), len(val)))
-		}
-		var vec llm.Vector
-		vec.Decode(val)
-		vdb.cache.Set(id, vec)
+		vdb.add(id, vdb.decode(key, getVal()))
 		clen++
 	}
 
-	vdb.slog.Info("loaded vectordb", "n", clen, "namespace", namespace)
+	vdb.slog.Info("loaded vectordb", "n", clen, "namespace", namespace, "bytes", vdb.used, "spilled", len(vdb.spilled))
 	return vdb
 }
 
@@ -292,7 +318,7 @@ func (db *memVectorDB) Set(id string, vec llm.Vector) {
 	db.storage.Set(ordered.Encode("llm.Vector", db.namespace, id), vec.Encode())
 
 	db.mu.Lock()
-	db.cache.Set(id, slices.Clone(vec))
+	db.add(id, slices.Clone(vec))
 	db.mu.Unlock()
 }
 
@@ -300,14 +326,22 @@ func (db *memVectorDB) Delete(id string) {
 	db.storage.Delete(ordered.Encode("llm.Vector", db.namespace, id))
 
 	db.mu.Lock()
-	db.cache.Delete(id)
+	db.remove(id)
+	db.promote()
 	db.mu.Unlock()
 }
 
 func (db *memVectorDB) Get(name string) (llm.Vector, bool) {
 	db.mu.RLock()
 	vec, ok := db.cache.Get(name)
+	spilled := db.spilled[name]
 	db.mu.RUnlock()
+	if spilled {
+		key := ordered.Encode("llm.Vector", db.namespace, name)
+		if val, ok := db.storage.Get(key); ok {
+			return db.decode(key, val), true
+		}
+	}
 	return vec, ok
 }
 
@@ -323,7 +357,13 @@ func (db *memVectorDB) All() iter.Seq2[string, func() llm.Vector] {
 		}()
 		// Iterate through the cache since we have an invariant that
 		// both the cache and the underlying storage are synced.
-		for id, vec := range db.cache.All() {
+		// If some vectors are only in storage, iterate through
+		// the storage instead.
+		all := db.cache.All()
+		if len(db.spilled) > 0 {
+			all = db.scan()
+		}
+		for id, vec := range all {
 			val := func() llm.Vector { return vec }
 			db.mu.RUnlock()
 			locked = false
@@ -336,15 +376,30 @@ func (db *memVectorDB) All() iter.Seq2[string, func() llm.Vector] {
 	}
 }
 
-func (db *memVectorDB) Search(target llm.Vector, n int) []VectorResult {
+func (db *memVectorDB) Search(target llm.Vector, n int, keep VectorFilter) []VectorResult {
+	best := NewVectorTop(n, keep)
+	add := func(name string, vec []float32) {
+		if len(vec) == len(target) {
+			best.Add(VectorResult{name, target.Dot(vec)})
+		}
+	}
 	db.mu.RLock()
-	defer db.mu.RUnlock()
-	best := top.New(n, VectorResult.cmp)
 	for name, vec := range db.cache.All() {
-		if len(vec) != len(target) {
-			continue
+		add(name, vec)
+	}
+	var spilled []string
+	for id := range db.spilled {
+		spilled = append(spilled, id)
+	}
+	db.mu.RUnlock()
+
+	// Read the spilled vectors without holding db.mu,
+	// so that slow reads do not block writers.
+	for _, id := range spilled {
+		key := ordered.Encode("llm.Vector", db.namespace, id)
+		if val, ok := db.storage.Get(key); ok {
+			add(id, db.decode(key, val))
 		}
-		best.Add(VectorResult{name, target.Dot(vec)})
 	}
 	return best.Take()
 }
@@ -397,12 +452,13 @@ func (b *memVectorBatch) Apply() {
 	defer b.db.mu.Unlock()
 
 	for name, vec := range b.w {
-		b.db.cache.Set(name, vec)
+		b.db.add(name, vec)
 	}
 	clear(b.w)
 
 	for name := range b.d {
-		b.db.cache.Delete(name)
+		b.db.remove(name)
 	}
 	clear(b.d)
+	b.db.promote()
 }
@@ -5,6 +5,7 @@
 package storage
 
 import (
+	"slices"
 	"testing"
 
 	"golang.org/x/oscar/internal/testutil"
@@ -19,6 +20,84 @@ func TestMemDB(t *testing.T) {
 func TestMemVectorDB(t *testing.T) {
 	db := MemDB()
 	TestVectorDB(t, func() VectorDB { return MemVectorDB(db, testutil.Slogger(t), "") })
+	TestVectorDBFilter(t, MemVectorDB(MemDB(), testutil.Slogger(t), ""))
+}
+
+func TestMemVectorDBLimit(t *testing.T) {
+	// Room for about two vectors; the rest are spilled.
+	db := MemDB()
+	TestVectorDB(t, func() VectorDB { return MemVectorDBWithLimit(db, testutil.Slogger(t), "", 300) })
+	TestVectorDBFilter(t, MemVectorDBWithLimit(MemDB(), testutil.Slogger(t), "", 300))
+}
+
+func TestMemoryUsage(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := MemDB()
+	size := vectorSize("apple1", embed("apple1"))
+	vdb := MemVectorDBWithLimit(db, lg, "", 2*size)
+	usage := func() MemoryUsage { return vdb.(MemoryReporter).MemoryUsage() }
+
+	vdb.Set("apple1", embed("apple1"))
+	vdb.Set("apple2", embed("apple2"))
+	vdb.Set("apple3", embed("apple3"))
+	want := MemoryUsage{Used: 2 * size, Limit: 2 * size, Cached: 2, Spilled: 1}
+	if u := usage(); u != want {
+		t.Errorf("MemoryUsage() = %+v, want %+v", u, want)
+	}
+	if v, ok := vdb.Get("apple3"); !ok || !slices.Equal(v, embed("apple3")) {
+		t.Errorf("Get(apple3) = %v, %v, want %v, true", v, ok, embed("apple3"))
+	}
+	if r := vdb.Search(embed("apple3"), 1, nil); len(r) != 1 || r[0].ID != "apple3" {
+		t.Errorf("Search(apple3) = %v, want apple3", r)
+	}
+
+	// Deleting a cached vector makes room for new ones.
+	vdb.Delete("apple1")
+	vdb.Delete("apple3")
+	vdb.Set("apple4", embed("apple4"))
+	want = MemoryUsage{Used: 2 * size, Limit: 2 * size, Cached: 2, Spilled: 0}
+	if u := usage(); u != want {
+		t.Errorf("after Delete, MemoryUsage() = %+v, want %+v", u, want)
+	}
+
+	// Reloading respects the limit.
+	vdb.Set("apple5", embed("apple5"))
+	vdb = MemVectorDBWithLimit(db, lg, "", 2*size)
+	want = MemoryUsage{Used: 2 * size, Limit: 2 * size, Cached: 2, Spilled: 1}
+	if u := usage(); u != want {
+		t.Errorf("after reload, MemoryUsage() = %+v, want %+v", u, want)
+	}
+	if ids, want := allIDs(vdb), []string{"apple2", "apple4", "apple5"}; !slices.Equal(ids, want) {
+		t.Errorf("All() = %v, want %v", ids, want)
+	}
+
+	// Deleting a cached vector brings a spilled one back into memory.
+	vdb.Delete("apple2")
+	want = MemoryUsage{Used: 2 * size, Limit: 2 * size, Cached: 2, Spilled: 0}
+	if u := usage(); u != want {
+		t.Errorf("after Delete of cached vector, MemoryUsage() = %+v, want %+v", u, want)
+	}
+	if r := vdb.Search(embed("apple5"), 1, nil); len(r) != 1 || r[0].ID != "apple5" {
+		t.Errorf("Search(apple5) = %v, want apple5", r)
+	}
+
+	// So does a batch deletion.
+	vdb.Set("apple6", embed("apple6"))
+	b := vdb.Batch()
+	b.Delete("apple4")
+	b.Apply()
+	if u := usage(); u != want {
+		t.Errorf("after batch Delete, MemoryUsage() = %+v, want %+v", u, want)
+	}
+}
+
+func TestMemVectorDBNegativeLimit(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MemVectorDBWithLimit with negative limit did not panic")
+		}
+	}()
+	MemVectorDBWithLimit(MemDB(), testutil.Slogger(t), "", -1)
 }
 
 type maybeDB struct {
@@ -0,0 +1,175 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"fmt"
+	"iter"
+
+	"golang.org/x/oscar/internal/llm"
+	"rsc.io/ordered"
+)
+
+// A MemoryReporter is a data structure that keeps
+// some of its data in memory and can report how much.
+type MemoryReporter interface {
+	MemoryUsage() MemoryUsage
+}
+
+// MemoryUsage describes the memory use of a [MemoryReporter].
+type MemoryUsage struct {
+	Used    int64 // approximate bytes of memory used
+	Limit   int64 // maximum bytes to use; 0 means no limit
+	Cached  int   // number of items in memory
+	Spilled int   // number of items not in memory because of the limit
+}
+
+// memWarnFraction is the fraction of its memory limit at which
+// a MemVectorDB logs a warning.
+const memWarnFraction = 0.9
+
+// vectorOverhead is the approximate memory used by an entry
+// in a memVectorDB's cache, in addition to the ID and vector data.
+const vectorOverhead = 64
+
+// vectorSize returns the approximate memory used by
+// a cached vector.
+func vectorSize(id string, vec []float32) int64 {
+	return int64(len(id) + 4*len(vec) + vectorOverhead)
+}
+
+// A memWarning is a level of memory-use warning.
+type memWarning int
+
+const (
+	memOK       memWarning = iota
+	memNearing             // usage is near the limit
+	memSpilling            // vectors are being spilled
+)
+
+// MemoryUsage implements [MemoryReporter].
+func (db *memVectorDB) MemoryUsage() MemoryUsage {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return MemoryUsage{
+		Used:    db.used,
+		Limit:   db.limit,
+		Cached:  db.cached,
+		Spilled: len(db.spilled),
+	}
+}
+
+// add sets the vector for id, caching it if it fits
+// in the memory limit and spilling it otherwise.
+// db.mu must be held.
+func (db *memVectorDB) add(id string, vec []float32) {
+	db.remove(id)
+	size := vectorSize(id, vec)
+	if db.limit > 0 && db.used+size > db.limit {
+		db.spilled[id] = true
+	} else {
+		db.cache.Set(id, vec)
+		db.used += size
+		db.cached++
+	}
+	db.warn()
+}
+
+// remove removes the vector for id from memory.
+// db.mu must be held.
+func (db *memVectorDB) remove(id string) {
+	if vec, ok := db.cache.Get(id); ok {
+		db.used -= vectorSize(id, vec)
+		db.cache.Delete(id)
+		db.cached--
+	}
+	delete(db.spilled, id)
+}
+
+// promote caches spilled vectors, reading them from storage,
+// while they fit in the memory limit.
+// db.mu must be held.
+func (db *memVectorDB) promote() {
+	for id := range db.spilled {
+		key := ordered.Encode("llm.Vector", db.namespace, id)
+		val, ok := db.storage.Get(key)
+		if !ok {
+			// unreachable unless storage was changed behind db's back
+			delete(db.spilled, id)
+			continue
+		}
+		vec := db.decode(key, val)
+		size := vectorSize(id, vec)
+		if db.used+size > db.limit {
+			break
+		}
+		delete(db.spilled, id)
+		db.cache.Set(id, vec)
+		db.used += size
+		db.cached++
+	}
+	db.warn()
+}
+
+// warn logs a warning if the memory use has become
+// more severe since the last warning.
+// db.mu must be held.
+func (db *memVectorDB) warn() {
+	if db.limit <= 0 {
+		return
+	}
+	level := memOK
+	switch {
+	case len(db.spilled) > 0:
+		level = memSpilling
+	case float64(db.used) >= memWarnFraction*float64(db.limit):
+		level = memNearing
+	}
+	if level <= db.warned {
+		if level == memOK {
+			db.warned = memOK
+		}
+		return
+	}
+	db.warned = level
+	args := []any{"namespace", db.namespace, "bytes", db.used, "limit", db.limit}
+	switch level {
+	case memNearing:
+		db.slog.Warn("MemVectorDB memory use nearing limit", args...)
+	case memSpilling:
+		db.slog.Warn("MemVectorDB memory limit reached; reading some vectors from storage", args...)
+	}
+}
+
+// scan returns an iterator over the vectors in storage,
+// in lexicographic order of IDs.
+func (db *memVectorDB) scan() iter.Seq2[string, []float32] {
+	return func(yield func(string, []float32) bool) {
+		for key, getVal := range db.storage.Scan(
+			ordered.Encode("llm.Vector", db.namespace),
+			ordered.Encode("llm.Vector", db.namespace, ordered.Inf)) {
+			var id string
+			if err := ordered.Decode(key, nil, nil, &id); err != nil {
+				// unreachable except data corruption
+				panic(fmt.Errorf("MemVectorDB decode key=%v: %v", Fmt(key), err))
+			}
+			if !yield(id, db.decode(key, getVal())) {
+				return
+			}
+		}
+	}
+}
+
+// decode decodes the vector stored with the given key.
+func (db *memVectorDB) decode(key, val []byte) llm.Vector {
+	if len(val)%4 != 0 {
+		// unreachable except data corruption
+		panic(fmt.Errorf("MemVectorDB decode key=%v bad len(val)=%d", Fmt(key), len(val)))
+	}
+	var vec llm.Vector
+	vec.Decode(val)
+	return vec
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"rsc.io/ordered"
+)
+
+// EncodeRecord returns the stored form of the record v
+// at the given encoding version: the [rsc.io/ordered] encoding
+// of the version followed by the JSON encoding of v.
+//
+// Records are usually stored and loaded using typed helpers
+// generated by golang.org/x/oscar/internal/devtools/cmd/kindgen.
+func EncodeRecord(version int64, v any) []byte {
+	return append(ordered.Encode(version), JSON(v)...)
+}
+
+// DecodeRecord decodes the stored record data into v and returns
+// the encoding version with which it was stored.
+// Records stored as plain JSON objects (using [JSON])
+// before they had an encoding version have version 0.
+func DecodeRecord(data []byte, v any) (version int64, err error) {
+	if len(data) > 0 && data[0] != '{' {
+		data, err = ordered.DecodePrefix(data, &version)
+		if err != nil {
+			return 0, fmt.Errorf("storage.DecodeRecord: bad version: %w", err)
+		}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return 0, fmt.Errorf("storage.DecodeRecord: %w", err)
+	}
+	return version, nil
+}
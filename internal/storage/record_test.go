@@ -0,0 +1,40 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	type rec struct {
+		Name string
+		N    int
+	}
+	in := rec{"x", 1}
+	for _, tt := range []struct {
+		data    []byte
+		version int64
+	}{
+		{EncodeRecord(3, in), 3},
+		{JSON(in), 0},
+	} {
+		var out rec
+		v, err := DecodeRecord(tt.data, &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != tt.version || out != in {
+			t.Errorf("DecodeRecord(%q) = %v, %d, want %v, %d", tt.data, out, v, in, tt.version)
+		}
+	}
+
+	var out rec
+	for _, data := range []string{"", "{", "\xff{}"} {
+		if _, err := DecodeRecord([]byte(data), &out); err == nil {
+			t.Errorf("DecodeRecord(%q) succeeded, want error", data)
+		}
+	}
+}
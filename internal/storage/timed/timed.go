@@ -295,6 +295,7 @@ func NewWatcher[T any](lg *slog.Logger, db storage.DB, name, kind string, decode
 		kind:   kind,
 		decode: decode,
 	}
+	register(WatcherID{kind, name})
 	// Set w.latest to current DB value.
 	w.cutoffUnlocked()
 	return w
@@ -325,20 +326,13 @@ func (w *Watcher[T]) cutoff() DBTime {
 }
 
 // cutoffUnlocked returns the value of the watcher key in the DB.
-// (The key is the maximum of calls to [Watcher.MarkOld].)
+// (The key is the maximum of calls to [Watcher.MarkOld],
+// unless the watcher has been moved by [MoveWatcher] or [Watcher.Restart].)
 // It also updates [Watcher.latest].
 func (w *Watcher[T]) cutoffUnlocked() DBTime {
-	var t int64
-	if dval, ok := w.db.Get(w.dkey); ok {
-		if err := ordered.Decode(dval, &t); err != nil {
-			// unreachable unless corrupt storage
-			w.db.Panic("watcher decode", "dval", storage.Fmt(dval), "err", err)
-		}
-	}
-	if w.latest.Load() < t {
-		w.latest.Store(t)
-	}
-	return DBTime(t)
+	t := readCursor(w.db, w.dkey)
+	w.latest.Store(int64(t))
+	return t
 }
 
 // Recent returns an iterator over recent entries,
@@ -424,6 +418,8 @@ func (w *Watcher[T]) Flush() {
 
 // Latest returns the latest known DBTime marked old by the Watcher.
 // It does not require the lock to be held.
+// After a call to [MoveWatcher], Latest reports the new position
+// once the Watcher next iterates.
 func (w *Watcher[T]) Latest() DBTime {
 	return DBTime(w.latest.Load())
 }
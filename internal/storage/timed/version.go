@@ -0,0 +1,168 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package timed
+
+import (
+	"bytes"
+	"iter"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// Versioned entries.
+//
+// Plain timed storage keeps only the latest value of each entry.
+// For kinds where it matters what an entry looked like in the past
+// (for example, to replay a decision using the data it was made with),
+// [SetVersioned] and [DeleteVersioned] also record each change
+// in a version index, with actual entries:
+//
+//   - (kindVersion, key, modtime) → (versionSet, val)
+//   - (kindVersion, key, modtime) → (versionDeleted)
+//
+// The key is encoded as a single []byte in the version index,
+// so that the versions of each entry are contiguous and in time order.
+//
+// [GetAsOf] and [ScanAsOf] read entries as they were at a given time.
+// Entries that have not changed since that time are read from the
+// ordinary storage, so they work for entries written (only) before
+// versioning was used, but older values of those entries are not known.
+
+// Version index values.
+const (
+	versionDeleted int64 = 0
+	versionSet     int64 = 1
+)
+
+// DBTimeAt returns the DBTime corresponding to the wall-clock time t.
+// Entries written before t (according to the clock of the system that
+// wrote them) have earlier DBTimes, and entries written after t later ones,
+// so DBTimeAt(t) can be passed to [GetAsOf] and [ScanAsOf]
+// to read entries as they were at time t.
+func DBTimeAt(t time.Time) DBTime {
+	return DBTime(t.UnixNano())
+}
+
+// SetVersioned is like [Set] but also records val as a version
+// of (kind, key), for use by [GetAsOf] and [ScanAsOf].
+func SetVersioned(db storage.DB, b storage.Batch, kind string, key, val []byte) DBTime {
+	t := Set(db, b, kind, key, val)
+	b.Set(ordered.Encode(kind+"Version", key, int64(t)), append(ordered.Encode(versionSet), val...))
+	return t
+}
+
+// DeleteVersioned is like [Delete] but also records the deletion
+// as a version of (kind, key), for use by [GetAsOf] and [ScanAsOf].
+func DeleteVersioned(db storage.DB, b storage.Batch, kind string, key []byte) {
+	if _, ok := db.Get(append(ordered.Encode(kind), key...)); !ok {
+		return
+	}
+	Delete(db, b, kind, key)
+	b.Set(ordered.Encode(kind+"Version", key, int64(now())), ordered.Encode(versionDeleted))
+}
+
+// GetAsOf retrieves the value corresponding to (kind, key) as of time t,
+// meaning the value most recently set at or before t.
+// It returns false if there was no such value at time t.
+// The returned entry's ModTime is the time the value was set.
+func GetAsOf(db storage.DB, kind string, key []byte, t DBTime) (*Entry, bool) {
+	if e, ok := Get(db, kind, key); ok && e.ModTime <= t {
+		return e, true
+	}
+	var last *Entry
+	start := ordered.Encode(kind+"Version", key)
+	end := ordered.Encode(kind+"Version", key, int64(t))
+	for vkey, vvalf := range db.Scan(start, end) {
+		last = decodeVersion(db, kind, vkey, vvalf())
+	}
+	return last, last != nil
+}
+
+// ScanAsOf returns an iterator over entries (kind, key) → val with start ≤ key ≤ end
+// as they were at time t, in key order.
+// Like [GetAsOf], it yields the value most recently set at or before t
+// for each key that had a value at time t.
+func ScanAsOf(db storage.DB, kind string, start, end []byte, t DBTime) iter.Seq[*Entry] {
+	return func(yield func(*Entry) bool) {
+		next, stop := iter.Pull(Scan(db, kind, start, end))
+		defer stop()
+		cur, curOK := next()
+
+		// yieldCurrent yields the current entries with keys before key
+		// (or all of them, if key is nil) that were set at or before t.
+		yieldCurrent := func(key []byte) bool {
+			for curOK && (key == nil || bytes.Compare(cur.Key, key) < 0) {
+				if cur.ModTime <= t && !yield(cur) {
+					return false
+				}
+				cur, curOK = next()
+			}
+			return true
+		}
+
+		// flush yields the versioned entry for key,
+		// whose last version at or before t is last.
+		flush := func(key []byte, last *Entry) bool {
+			if !yieldCurrent(key) {
+				return false
+			}
+			if curOK && bytes.Equal(cur.Key, key) {
+				if cur.ModTime <= t {
+					last = cur
+				}
+				cur, curOK = next()
+			}
+			return last == nil || yield(last)
+		}
+
+		var key []byte
+		var last *Entry
+		vstart := ordered.Encode(kind+"Version", start)
+		vend := ordered.Encode(kind+"Version", end, ordered.Inf)
+		for vkey, vvalf := range db.Scan(vstart, vend) {
+			var k []byte
+			var vt int64
+			if err := ordered.Decode(vkey, nil, &k, &vt); err != nil {
+				// unreachable unless corrupt storage
+				db.Panic("timed.ScanAsOf decode", "vkey", storage.Fmt(vkey), "err", err)
+			}
+			if key == nil || !bytes.Equal(k, key) {
+				if key != nil && !flush(key, last) {
+					return
+				}
+				key, last = k, nil
+			}
+			if DBTime(vt) <= t {
+				last = decodeVersion(db, kind, vkey, vvalf())
+			}
+		}
+		if key != nil && !flush(key, last) {
+			return
+		}
+		yieldCurrent(nil)
+	}
+}
+
+// decodeVersion decodes a version index entry.
+// It returns nil for a deletion.
+func decodeVersion(db storage.DB, kind string, vkey, vval []byte) *Entry {
+	var key []byte
+	var t, op int64
+	if err := ordered.Decode(vkey, nil, &key, &t); err != nil {
+		// unreachable unless corrupt storage
+		db.Panic("timed version decode", "vkey", storage.Fmt(vkey), "err", err)
+	}
+	val, err := ordered.DecodePrefix(vval, &op)
+	if err != nil {
+		// unreachable unless corrupt storage
+		db.Panic("timed version decode", "vkey", storage.Fmt(vkey), "vval", storage.Fmt(vval), "err", err)
+	}
+	if op == versionDeleted {
+		return nil
+	}
+	return &Entry{DBTime(t), kind, key, val}
+}
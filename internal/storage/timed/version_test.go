@@ -0,0 +1,98 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package timed
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+)
+
+func TestVersions(t *testing.T) {
+	db := storage.MemDB()
+	b := db.Batch()
+	set := func(key, val string) DBTime {
+		t := SetVersioned(db, b, "kind", []byte(key), []byte(val))
+		b.Apply()
+		return t
+	}
+
+	t1 := set("k1", "a")
+	t2 := set("k2", "x")
+	t3 := set("k1", "b")
+	DeleteVersioned(db, b, "kind", []byte("k2"))
+	b.Apply()
+	t4 := now()
+	DeleteVersioned(db, b, "kind", []byte("missing"))
+	b.Apply()
+	t5 := Set(db, b, "kind", []byte("k3"), []byte("p")) // not versioned
+	b.Apply()
+	t6 := set("k1", "c")
+	t7 := set("k2", "y")
+
+	for _, tt := range []struct {
+		key  string
+		t    DBTime
+		want string // "" for no value
+	}{
+		{"k1", t1 - 1, ""},
+		{"k1", t1, "a"},
+		{"k1", t3 - 1, "a"},
+		{"k1", t3, "b"},
+		{"k1", t6, "c"},
+		{"k1", t7 + 100, "c"},
+		{"k2", t2, "x"},
+		{"k2", t4, ""},
+		{"k2", t7, "y"},
+		{"k3", t4, ""},
+		{"k3", t5, "p"},
+		{"missing", t7, ""},
+	} {
+		e, ok := GetAsOf(db, "kind", []byte(tt.key), tt.t)
+		got := ""
+		if ok {
+			got = string(e.Val)
+			if e.ModTime > tt.t {
+				t.Errorf("GetAsOf(%s, %d): ModTime %d after t", tt.key, tt.t, e.ModTime)
+			}
+		}
+		if got != tt.want {
+			t.Errorf("GetAsOf(%s, %d) = %q, want %q", tt.key, tt.t, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		t    DBTime
+		want string
+	}{
+		{t1 - 1, ""},
+		{t1, "k1=a "},
+		{t2, "k1=a k2=x "},
+		{t3, "k1=b k2=x "},
+		{t4, "k1=b "},
+		{t5, "k1=b k3=p "},
+		{t6, "k1=c k3=p "},
+		{t7, "k1=c k2=y k3=p "},
+	} {
+		got := ""
+		for e := range ScanAsOf(db, "kind", []byte("k"), []byte("l"), tt.t) {
+			got += fmt.Sprintf("%s=%s ", e.Key, e.Val)
+		}
+		if got != tt.want {
+			t.Errorf("ScanAsOf(%d) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+
+	// Stopping early.
+	for range ScanAsOf(db, "kind", []byte("k"), []byte("l"), t7) {
+		break
+	}
+
+	if got, want := DBTimeAt(time.Unix(0, int64(t3))), t3; got != want {
+		t.Errorf("DBTimeAt(t3) = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package timed
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// This file implements inspection and repositioning of Watchers,
+// for operators who need to see how far behind a Watcher is
+// or to make it revisit or skip entries.
+// Positions are DBTimes, which can be converted to and from
+// wall-clock times using [DBTime.Time] and [DBTimeAt].
+
+// A WatcherID identifies the Watchers with a given kind and name.
+type WatcherID struct {
+	Kind string // kind of keys watched
+	Name string // name of the watcher
+}
+
+// String returns the form of id parsed by [ParseWatcherID].
+func (id WatcherID) String() string {
+	return id.Kind + ":" + id.Name
+}
+
+// ParseWatcherID parses a watcher ID of the form "kind:name".
+func ParseWatcherID(s string) (WatcherID, bool) {
+	kind, name, ok := strings.Cut(s, ":")
+	if !ok || kind == "" || name == "" {
+		return WatcherID{}, false
+	}
+	return WatcherID{kind, name}, true
+}
+
+func (id WatcherID) dkey() []byte {
+	return ordered.Encode(id.Kind+"Watcher", id.Name)
+}
+
+// registry records the watchers created in this process.
+var registry struct {
+	mu  sync.Mutex
+	ids map[WatcherID]bool
+}
+
+func register(id WatcherID) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.ids == nil {
+		registry.ids = make(map[WatcherID]bool)
+	}
+	registry.ids[id] = true
+}
+
+// Watchers returns the IDs of the Watchers created by [NewWatcher]
+// in this process, ordered by kind and then name.
+func Watchers() []WatcherID {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	var ids []WatcherID
+	for id := range registry.ids {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(x, y WatcherID) int {
+		return cmp.Or(cmp.Compare(x.Kind, y.Kind), cmp.Compare(x.Name, y.Name))
+	})
+	return ids
+}
+
+// Time returns the wall-clock time corresponding to t.
+// It is the inverse of [DBTimeAt].
+func (t DBTime) Time() time.Time {
+	return time.Unix(0, int64(t))
+}
+
+// maxPending is the most pending entries [State] counts.
+const maxPending = 10000
+
+// A WatcherState is a snapshot of the position of a Watcher.
+type WatcherState struct {
+	WatcherID
+	Cursor  DBTime // latest time marked old; 0 if none
+	Pending int    // number of entries set after Cursor, up to a limit
+	More    bool   // whether there are more than Pending entries
+	Oldest  DBTime // time of the oldest pending entry; 0 if none
+}
+
+// Lag returns how long the oldest pending entry has been waiting
+// for the watcher, as of now, or 0 if no entries are pending.
+func (s *WatcherState) Lag(now time.Time) time.Duration {
+	if s.Pending == 0 {
+		return 0
+	}
+	return now.Sub(s.Oldest.Time())
+}
+
+// State returns the current state of the watcher id in db.
+// It does not wait for an iteration of the watcher to finish,
+// so the state may be out of date by the time it is returned.
+//
+// To keep State fast, it counts the pending entries
+// using only the time index, so the count may include entries
+// that have been set more than once and it stops after a limit
+// (indicated by [WatcherState.More]).
+func State(db storage.DB, id WatcherID) *WatcherState {
+	s := &WatcherState{WatcherID: id, Cursor: readCursor(db, id.dkey())}
+	start, end := ordered.Encode(id.Kind+"ByTime", int64(s.Cursor+1)), ordered.Encode(id.Kind+"ByTime", ordered.Inf)
+	for tkey := range db.Scan(start, end) {
+		if s.Pending == maxPending {
+			s.More = true
+			break
+		}
+		if s.Pending == 0 {
+			var t int64
+			if _, err := ordered.DecodePrefix(tkey, nil, &t); err != nil {
+				// unreachable unless corrupt storage
+				db.Panic("timed.State decode", "tkey", storage.Fmt(tkey), "err", err)
+			}
+			s.Oldest = DBTime(t)
+		}
+		s.Pending++
+	}
+	return s
+}
+
+// readCursor returns the value of the watcher key dkey in db.
+func readCursor(db storage.DB, dkey []byte) DBTime {
+	var t int64
+	if dval, ok := db.Get(dkey); ok {
+		if err := ordered.Decode(dval, &t); err != nil {
+			// unreachable unless corrupt storage
+			db.Panic("watcher decode", "dval", storage.Fmt(dval), "err", err)
+		}
+	}
+	return DBTime(t)
+}
+
+const watcherEventKind = "timed.WatcherEvent"
+
+// A WatcherEvent records a call to [MoveWatcher].
+type WatcherEvent struct {
+	WatcherID
+	Time   time.Time // when the watcher was moved
+	User   string    // who moved it
+	Old    DBTime    // the cursor before the move
+	New    DBTime    // the cursor after the move
+	Reason string    // why it was moved
+}
+
+// Rewound reports whether the move made the watcher revisit entries
+// (as opposed to skipping them).
+func (e *WatcherEvent) Rewound() bool {
+	return e.New < e.Old
+}
+
+// MoveWatcher sets the cursor of the watcher id in db to t,
+// so that the next iteration over [Watcher.Recent] starts
+// immediately after time t, and it records in the audit log that
+// user did so for the given reason.
+// Unlike [Watcher.MarkOld], MoveWatcher can move the cursor backward,
+// so that the watcher visits entries again, as well as forward,
+// so that it skips entries. Moving to time 0 is like [Watcher.Restart].
+//
+// MoveWatcher waits for any iteration of the watcher to finish,
+// and it must not be called during one in this process.
+// It returns the recorded event.
+func MoveWatcher(db storage.DB, id WatcherID, t DBTime, user, reason string) *WatcherEvent {
+	dkey := id.dkey()
+	db.Lock(string(dkey))
+	defer db.Unlock(string(dkey))
+
+	e := &WatcherEvent{
+		WatcherID: id,
+		Time:      time.Now(),
+		User:      user,
+		Old:       readCursor(db, dkey),
+		New:       t,
+		Reason:    reason,
+	}
+	b := db.Batch()
+	if t == 0 {
+		b.Delete(dkey)
+	} else {
+		b.Set(dkey, ordered.Encode(int64(t)))
+	}
+	b.Set(ordered.Encode(watcherEventKind, e.Time.UnixNano(), id.Kind, id.Name), storage.JSON(e))
+	b.Apply()
+	db.Flush()
+	return e
+}
+
+// WatcherEvents returns the audit log of calls to [MoveWatcher]
+// recorded in db, most recent first.
+func WatcherEvents(db storage.DB) []*WatcherEvent {
+	var es []*WatcherEvent
+	for _, val := range db.Scan(ordered.Encode(watcherEventKind), ordered.Encode(watcherEventKind, ordered.Inf)) {
+		var e WatcherEvent
+		if err := json.Unmarshal(val(), &e); err != nil {
+			// unreachable unless db corruption
+			db.Panic("timed.WatcherEvents decode", "err", err)
+		}
+		es = append(es, &e)
+	}
+	slices.Reverse(es)
+	return es
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package timed
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestMoveWatcher(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+
+	var times []DBTime
+	for _, key := range []string{"k1", "k2", "k3"} {
+		b := db.Batch()
+		times = append(times, Set(db, b, "kind", []byte(key), nil))
+		b.Apply()
+	}
+
+	w := NewWatcher(lg, db, "move", "kind", func(e *Entry) string { return string(e.Key) })
+	id := WatcherID{"kind", "move"}
+	if !slices.Contains(Watchers(), id) {
+		t.Errorf("Watchers() = %v, missing %v", Watchers(), id)
+	}
+	if s, ok := ParseWatcherID(id.String()); !ok || s != id {
+		t.Errorf("ParseWatcherID(%q) = %v, %v, want %v", id.String(), s, ok, id)
+	}
+
+	recent := func() []string {
+		var keys []string
+		for key := range w.Recent() {
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	s := State(db, id)
+	if s.Cursor != 0 || s.Pending != 3 || s.More || s.Oldest != times[0] {
+		t.Errorf("State = %+v, want 3 pending from %d", s, times[0])
+	}
+	if lag := s.Lag(times[0].Time().Add(time.Hour)); lag != time.Hour {
+		t.Errorf("Lag = %v, want 1h", lag)
+	}
+
+	// Advance past k2.
+	e := MoveWatcher(db, id, times[1], "gopher", "skip")
+	if e.Old != 0 || e.New != times[1] || e.Rewound() {
+		t.Errorf("MoveWatcher = %+v, want advance to %d", e, times[1])
+	}
+	if s := State(db, id); s.Cursor != times[1] || s.Pending != 1 || s.Oldest != times[2] {
+		t.Errorf("State after advance = %+v, want 1 pending", s)
+	}
+	if keys, want := recent(), []string{"k3"}; !slices.Equal(keys, want) {
+		t.Errorf("Recent after advance = %v, want %v", keys, want)
+	}
+	if w.Latest() != times[1] {
+		t.Errorf("Latest after advance = %d, want %d", w.Latest(), times[1])
+	}
+
+	// Mark everything old, then rewind to after k1.
+	w.lock()
+	w.MarkOld(times[2])
+	w.unlock()
+	if s := State(db, id); s.Pending != 0 || s.Lag(time.Now()) != 0 {
+		t.Errorf("State after MarkOld = %+v, want none pending", s)
+	}
+	e = MoveWatcher(db, id, times[0], "gopher", "redo")
+	if !e.Rewound() {
+		t.Errorf("MoveWatcher = %+v, want rewind", e)
+	}
+	if keys, want := recent(), []string{"k2", "k3"}; !slices.Equal(keys, want) {
+		t.Errorf("Recent after rewind = %v, want %v", keys, want)
+	}
+	if w.Latest() != times[0] {
+		t.Errorf("Latest after rewind = %d, want %d", w.Latest(), times[0])
+	}
+
+	// Rewind to the start.
+	MoveWatcher(db, id, 0, "gopher", "restart")
+	if keys, want := recent(), []string{"k1", "k2", "k3"}; !slices.Equal(keys, want) {
+		t.Errorf("Recent after restart = %v, want %v", keys, want)
+	}
+
+	es := WatcherEvents(db)
+	var reasons []string
+	for _, e := range es {
+		if e.WatcherID != id || e.User != "gopher" {
+			t.Errorf("event %+v, want %v by gopher", e, id)
+		}
+		reasons = append(reasons, e.Reason)
+	}
+	if want := []string{"restart", "redo", "skip"}; !slices.Equal(reasons, want) {
+		t.Errorf("WatcherEvents reasons = %v, want %v", reasons, want)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// A VectorTop collects the best results of a [VectorDB.Search]
+// that a [VectorFilter] keeps. It calls the filter only for results
+// that score high enough to be among the best so far,
+// so it is suitable for implementations of Search that scan
+// all vectors.
+type VectorTop struct {
+	n    int
+	keep VectorFilter
+	h    resultHeap
+}
+
+// NewVectorTop returns a new VectorTop that collects the n best results
+// kept by keep. A nil keep keeps all results.
+func NewVectorTop(n int, keep VectorFilter) *VectorTop {
+	return &VectorTop{n: n, keep: keep}
+}
+
+// Add considers r for the results.
+func (t *VectorTop) Add(r VectorResult) {
+	if t.n <= 0 || len(t.h) == t.n && r.cmp(t.h[0]) <= 0 {
+		return
+	}
+	if t.keep != nil && !t.keep(r.ID) {
+		return
+	}
+	if len(t.h) < t.n {
+		heap.Push(&t.h, r)
+		return
+	}
+	t.h[0] = r
+	heap.Fix(&t.h, 0)
+}
+
+// Take returns the results, best first,
+// breaking ties in score by decreasing ID,
+// and resets t to empty.
+func (t *VectorTop) Take() []VectorResult {
+	res := t.h
+	t.h = nil
+	slices.SortFunc(res, func(x, y VectorResult) int { return y.cmp(x) })
+	return res
+}
+
+// A resultHeap is a min-heap of VectorResults.
+type resultHeap []VectorResult
+
+func (h resultHeap) Len() int           { return len(h) }
+func (h resultHeap) Less(i, j int) bool { return h[i].cmp(h[j]) < 0 }
+func (h resultHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x any)        { *h = append(*h, x.(VectorResult)) }
+func (h *resultHeap) Pop() any {
+	x := (*h)[len(*h)-1]
+	*h = (*h)[:len(*h)-1]
+	return x
+}
+
+// SearchFiltered implements a filtered [VectorDB.Search] for a vector
+// database that can only search for the m most similar vectors,
+// using search(m), such as one that searches on a remote server.
+// It asks for more and more results, starting with 2n,
+// until n of them are kept by keep or there are no more.
+// It assumes that each search extends the results of the previous one.
+// A nil keep keeps all results.
+func SearchFiltered(n int, keep VectorFilter, search func(m int) []VectorResult) []VectorResult {
+	if keep == nil || n <= 0 {
+		return search(n)
+	}
+	var res []VectorResult
+	done := 0 // results of the previous search already considered
+	for m := 2 * n; ; m *= 2 {
+		rs := search(m)
+		if done > len(rs) {
+			done = len(rs)
+		}
+		for _, r := range rs[done:] {
+			if keep(r.ID) {
+				res = append(res, r)
+				if len(res) == n {
+					return res
+				}
+			}
+		}
+		if len(rs) < m {
+			return res
+		}
+		done = len(rs)
+	}
+}
@@ -48,12 +48,19 @@ type VectorDB interface {
 	Batch() VectorBatch
 
 	// Search searches the database for the n vectors
-	// most similar to vec, returning the document IDs
-	// and similarity scores.
+	// most similar to vec whose document IDs keep reports true for,
+	// returning the document IDs and similarity scores.
+	// A nil keep keeps all documents.
+	//
+	// Search evaluates keep as it finds candidates, so that
+	// a restrictive filter still yields n results if there are
+	// that many matching vectors. Implementations avoid calling keep
+	// for candidates that could not be among the n best,
+	// so keep may be relatively expensive, such as a database lookup.
 	//
 	// Normally a VectorDB is used entirely with vectors of a single length.
 	// Search ignores stored vectors with a different length than vec.
-	Search(vec llm.Vector, n int) []VectorResult
+	Search(vec llm.Vector, n int, keep VectorFilter) []VectorResult
 
 	// Flush flushes storage to disk.
 	Flush()
@@ -89,6 +96,11 @@ type VectorBatch interface {
 	Apply()
 }
 
+// A VectorFilter reports whether a [VectorDB.Search] may return
+// the document with the given ID. Filters typically check metadata
+// about the document, such as its kind, project, state or creation time.
+type VectorFilter func(id string) bool
+
 // A VectorResult is a single document returned by a VectorDB search.
 type VectorResult struct {
 	ID    string  // document ID
@@ -4,7 +4,12 @@
 
 package storage
 
-import "testing"
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+)
 
 func TestVectorResultCompare(t *testing.T) {
 	type R = VectorResult
@@ -28,3 +33,53 @@ func TestVectorResultCompare(t *testing.T) {
 		try(tt.y, tt.x, -tt.cmp)
 	}
 }
+
+func TestVectorTop(t *testing.T) {
+	var checked []string
+	keep := func(id string) bool {
+		checked = append(checked, id)
+		return id != "c"
+	}
+	top := NewVectorTop(2, keep)
+	for _, r := range []VectorResult{{"a", 0.1}, {"b", 0.5}, {"c", 0.9}, {"d", 0.7}, {"e", 0.2}} {
+		top.Add(r)
+	}
+	want := []VectorResult{{"d", 0.7}, {"b", 0.5}}
+	if have := top.Take(); !slices.Equal(have, want) {
+		t.Errorf("Take() = %v, want %v", have, want)
+	}
+	// e scores below the two best kept results, so it is not checked.
+	if want := []string{"a", "b", "c", "d"}; !slices.Equal(checked, want) {
+		t.Errorf("checked %v, want %v", checked, want)
+	}
+}
+
+func TestSearchFiltered(t *testing.T) {
+	var all []VectorResult
+	for i := range 100 {
+		all = append(all, VectorResult{fmt.Sprint(i), 1 - float64(i)/100})
+	}
+	var searches []int
+	search := func(m int) []VectorResult {
+		searches = append(searches, m)
+		return all[:min(m, len(all))]
+	}
+	div7 := func(id string) bool { n, _ := strconv.Atoi(id); return n%7 == 0 }
+
+	have := SearchFiltered(3, div7, search)
+	if want := []VectorResult{all[0], all[7], all[14]}; !slices.Equal(have, want) {
+		t.Errorf("SearchFiltered(3) = %v, want %v", have, want)
+	}
+	if want := []int{6, 12, 24}; !slices.Equal(searches, want) {
+		t.Errorf("searches = %v, want %v", searches, want)
+	}
+
+	// With too few matches, SearchFiltered stops at the end.
+	searches = nil
+	if have := SearchFiltered(20, div7, search); len(have) != 15 {
+		t.Errorf("SearchFiltered(20) returned %d results, want 15", len(have))
+	}
+	if want := []int{40, 80, 160}; !slices.Equal(searches, want) {
+		t.Errorf("searches = %v, want %v", searches, want)
+	}
+}
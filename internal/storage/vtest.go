@@ -8,6 +8,7 @@ import (
 	"math"
 	"reflect"
 	"slices"
+	"strings"
 	"testing"
 
 	"golang.org/x/oscar/internal/llm"
@@ -86,7 +87,7 @@ func TestVectorDB(t *testing.T, opendb func() VectorDB) {
 		{"orange2", 0.3785152783773009},
 		{"orange4", 0.37429777504303363},
 	}
-	have := vdb.Search(embed("apple5"), 5)
+	have := vdb.Search(embed("apple5"), 5, nil)
 	if !reflect.DeepEqual(have, want) {
 		// unreachable except bad vectordb
 		t.Fatalf("Search(apple5, 5):\nhave %v\nwant %v", have, want)
@@ -95,7 +96,7 @@ func TestVectorDB(t *testing.T, opendb func() VectorDB) {
 	vdb.Flush()
 
 	vdb = opendb()
-	have = vdb.Search(embed("apple5"), 3)
+	have = vdb.Search(embed("apple5"), 3, nil)
 	want = want[:3]
 	if !reflect.DeepEqual(have, want) {
 		// unreachable except bad vectordb
@@ -103,6 +104,41 @@ func TestVectorDB(t *testing.T, opendb func() VectorDB) {
 	}
 }
 
+// TestVectorDBFilter verifies that implementations of [VectorDB]
+// apply the filter passed to [VectorDB.Search] while searching.
+// The vdb should be empty.
+func TestVectorDBFilter(t *testing.T, vdb VectorDB) {
+	b := vdb.Batch()
+	for _, id := range []string{"apple1", "apple2", "apple3", "apple4", "orange1", "orange2", "orange3"} {
+		b.Set(id, embed(id))
+	}
+	b.Apply()
+
+	// The best matches are all apples, but the filter
+	// removes them, so the oranges fill the results.
+	var checked []string
+	orange := func(id string) bool {
+		checked = append(checked, id)
+		return strings.HasPrefix(id, "orange")
+	}
+	have := vdb.Search(embed("apple5"), 2, orange)
+	var haveIDs []string
+	for _, r := range have {
+		haveIDs = append(haveIDs, r.ID)
+	}
+	if want := []string{"orange1", "orange2"}; !slices.Equal(haveIDs, want) {
+		// unreachable except bad vectordb
+		t.Fatalf("Search(apple5, 2, orange) = %v, want %v", have, want)
+	}
+	if len(checked) == 0 {
+		t.Fatalf("Search(apple5, 2, orange) did not call the filter")
+	}
+
+	if have := vdb.Search(embed("apple5"), 10, func(string) bool { return false }); len(have) != 0 {
+		t.Fatalf("Search with filter removing everything = %v, want none", have)
+	}
+}
+
 func allIDs(vdb VectorDB) []string {
 	var all []string
 	for k := range vdb.All() {
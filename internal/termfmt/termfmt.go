@@ -0,0 +1,407 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package termfmt renders Markdown, such as the text of
+// LLM-generated overviews, for display in a terminal.
+//
+// [Markdown] wraps paragraphs and list items at a given width and,
+// if requested, styles headings, emphasis, code and links with ANSI
+// escape sequences. Use [ForTerminal] to choose the options
+// appropriate for an output file.
+package termfmt
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+	"rsc.io/markdown"
+)
+
+// Options configures the output of [Markdown].
+type Options struct {
+	Width int  // wrap lines at this many columns; 0 means don't wrap
+	Color bool // style the text with ANSI escape sequences
+}
+
+// DefaultWidth is the width used by [ForTerminal] when
+// the width of the terminal is unknown.
+// It is also the maximum width [ForTerminal] uses,
+// since longer lines are hard to read.
+const DefaultWidth = 100
+
+// ForTerminal returns the options for writing to f.
+// The text is colored if f is a terminal and neither the NO_COLOR
+// environment variable is set (see https://no-color.org)
+// nor TERM is "dumb", and it is wrapped to fit the terminal.
+func ForTerminal(f *os.File) Options {
+	fd := int(f.Fd())
+	if !term.IsTerminal(fd) {
+		return Options{Width: DefaultWidth}
+	}
+	width := DefaultWidth
+	if w, _, err := term.GetSize(fd); err == nil && w > 0 && w < width {
+		width = w
+	}
+	return Options{
+		Width: width,
+		Color: os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb",
+	}
+}
+
+// SGR parameters of the styles.
+const (
+	styleBold      = "1"
+	styleDim       = "2"
+	styleItalic    = "3"
+	styleUnderline = "4"
+	styleStrike    = "9"
+	styleCode      = "33" // yellow
+	styleHeading   = "1;36"
+)
+
+// Markdown returns the Markdown text rendered for a terminal.
+// The result ends in a newline unless it is empty.
+func Markdown(text string, opts Options) string {
+	p := markdown.Parser{
+		Strikethrough: true,
+		TaskListItems: true,
+		AutoLinkText:  true,
+		Table:         true,
+	}
+	r := &renderer{opts: opts}
+	r.blocks(p.Parse(text).Blocks, "", "", false)
+	return r.buf.String()
+}
+
+// A renderer renders Markdown blocks for a terminal.
+type renderer struct {
+	opts Options
+	buf  strings.Builder
+}
+
+// A span is text in a single style.
+type span struct {
+	text  string
+	style string // SGR parameters; "" for none
+}
+
+// style returns s in the style, if colors are enabled.
+func (r *renderer) style(s, style string) string {
+	if !r.opts.Color || style == "" || s == "" {
+		return s
+	}
+	return "\x1b[" + style + "m" + s + "\x1b[0m"
+}
+
+// line writes a line made of the prefix followed by s.
+func (r *renderer) line(prefix, s string) {
+	r.buf.WriteString(strings.TrimRightFunc(prefix+s, unicode.IsSpace))
+	r.buf.WriteString("\n")
+}
+
+// blocks renders bs, starting the first line with first
+// and the others with rest. Unless tight is set,
+// a blank line separates the blocks.
+func (r *renderer) blocks(bs []markdown.Block, first, rest string, tight bool) {
+	n := 0
+	for _, b := range bs {
+		if _, ok := b.(*markdown.Empty); ok {
+			continue
+		}
+		if n > 0 {
+			if !tight {
+				r.line(rest, "")
+			}
+			first = rest
+		}
+		r.block(b, first, rest)
+		n++
+	}
+}
+
+// block renders b, starting the first line with first
+// and the others with rest.
+func (r *renderer) block(b markdown.Block, first, rest string) {
+	switch b := b.(type) {
+	case *markdown.Paragraph:
+		r.wrap(r.inlines(nil, b.Text.Inline, ""), first, rest)
+	case *markdown.Text:
+		// The paragraph of a tight list item.
+		r.wrap(r.inlines(nil, b.Inline, ""), first, rest)
+	case *markdown.Heading:
+		r.wrap(r.inlines(nil, b.Text.Inline, styleHeading), first, rest)
+	case *markdown.List:
+		ordered := b.Bullet == '.' || b.Bullet == ')'
+		digits := len(strconv.Itoa(b.Start + len(b.Items) - 1))
+		for i, item := range b.Items {
+			marker := "  • "
+			if ordered {
+				marker = fmt.Sprintf("%*d%c ", digits, b.Start+i, b.Bullet)
+			}
+			if i > 0 {
+				if b.Loose {
+					r.line(rest, "")
+				}
+				first = rest
+			}
+			indent := strings.Repeat(" ", utf8.RuneCountInString(marker))
+			r.blocks(item.(*markdown.Item).Blocks, first+marker, rest+indent, !b.Loose)
+		}
+	case *markdown.Quote:
+		bar := r.style("│ ", styleDim)
+		r.blocks(b.Blocks, first+bar, rest+bar, false)
+	case *markdown.CodeBlock:
+		text := b.Text
+		for len(text) > 0 && strings.TrimSpace(text[len(text)-1]) == "" {
+			text = text[:len(text)-1]
+		}
+		for i, t := range text {
+			if i > 0 {
+				first = rest
+			}
+			r.line(first, "    "+r.style(t, styleCode))
+		}
+	case *markdown.ThematicBreak:
+		n := 40
+		if r.opts.Width > 0 {
+			n = max(1, r.opts.Width-visibleWidth(rest))
+		}
+		r.line(first, r.style(strings.Repeat("─", n), styleDim))
+	case *markdown.Table:
+		r.table(b, first, rest)
+	case *markdown.HTMLBlock:
+		for i, t := range b.Text {
+			if i > 0 {
+				first = rest
+			}
+			r.line(first, t)
+		}
+	default:
+		// Unknown blocks are shown as Markdown.
+		for i, t := range strings.Split(strings.TrimSuffix(markdown.Format(b), "\n"), "\n") {
+			if i > 0 {
+				first = rest
+			}
+			r.line(first, t)
+		}
+	}
+}
+
+// inlines appends the spans of the inline elements in to spans,
+// using the given style for plain text, and returns the result.
+func (r *renderer) inlines(spans []span, in []markdown.Inline, style string) []span {
+	add := func(style, extra string) string {
+		if style == "" {
+			return extra
+		}
+		return style + ";" + extra
+	}
+	for _, x := range in {
+		switch x := x.(type) {
+		case *markdown.Plain:
+			spans = append(spans, span{x.Text, style})
+		case *markdown.Escaped:
+			spans = append(spans, span{x.Text, style})
+		case *markdown.Code:
+			spans = append(spans, span{x.Text, add(style, styleCode)})
+		case *markdown.Strong:
+			spans = r.inlines(spans, x.Inner, add(style, styleBold))
+		case *markdown.Emph:
+			spans = r.inlines(spans, x.Inner, add(style, styleItalic))
+		case *markdown.Del:
+			spans = r.inlines(spans, x.Inner, add(style, styleStrike))
+		case *markdown.Link:
+			n := len(spans)
+			spans = r.inlines(spans, x.Inner, add(style, styleUnderline))
+			if x.URL != "" && plainText(spans[n:]) != x.URL {
+				spans = append(spans, span{" (" + x.URL + ")", add(style, styleDim)})
+			}
+		case *markdown.AutoLink:
+			spans = append(spans, span{x.Text, add(style, styleUnderline)})
+		case *markdown.Image:
+			spans = append(spans, span{"[image: ", add(style, styleDim)})
+			spans = r.inlines(spans, x.Inner, add(style, styleDim))
+			spans = append(spans, span{"]", add(style, styleDim)})
+		case *markdown.Emoji:
+			spans = append(spans, span{x.Text, style})
+		case *markdown.Task:
+			if x.Checked {
+				spans = append(spans, span{"[x] ", style})
+			} else {
+				spans = append(spans, span{"[ ] ", style})
+			}
+		case *markdown.HTMLTag:
+			spans = append(spans, span{x.Text, add(style, styleDim)})
+		case *markdown.SoftBreak:
+			spans = append(spans, span{" ", style})
+		case *markdown.HardBreak:
+			spans = append(spans, span{"\n", style})
+		}
+	}
+	return spans
+}
+
+// plainText returns the text of spans, without styles.
+func plainText(spans []span) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(s.text)
+	}
+	return b.String()
+}
+
+// wrap writes the spans as lines that fit in the width,
+// starting the first line with first and the others with rest.
+// Words longer than the width are not broken.
+func (r *renderer) wrap(spans []span, first, rest string) {
+	var (
+		out     strings.Builder // the current line
+		n       int             // the visible width of out
+		prefix  = first
+		pending bool // a space is needed before the next word
+	)
+	flush := func() {
+		r.line(prefix, out.String())
+		out.Reset()
+		n = 0
+		pending = false
+		prefix = rest
+	}
+	// Gather the words, each a list of spans without spaces.
+	var word []span
+	wordWidth := 0
+	emit := func() {
+		if len(word) == 0 {
+			return
+		}
+		limit := r.opts.Width - visibleWidth(prefix)
+		if r.opts.Width > 0 && n > 0 && n+1+wordWidth > limit {
+			flush()
+		}
+		if pending {
+			out.WriteString(" ")
+			n++
+		}
+		out.WriteString(r.renderSpans(word))
+		n += wordWidth
+		word, wordWidth = word[:0], 0
+		pending = false
+	}
+	for _, s := range spans {
+		for s.text != "" {
+			i := strings.IndexAny(s.text, " \t\n")
+			if i < 0 {
+				word = append(word, s)
+				wordWidth += utf8.RuneCountInString(s.text)
+				break
+			}
+			if i > 0 {
+				word = append(word, span{s.text[:i], s.style})
+				wordWidth += utf8.RuneCountInString(s.text[:i])
+			}
+			emit()
+			if s.text[i] == '\n' {
+				flush()
+			} else if n > 0 {
+				pending = true
+			}
+			s.text = s.text[i+1:]
+		}
+	}
+	emit()
+	if n > 0 || prefix == first {
+		flush()
+	}
+}
+
+// table writes the table t, without wrapping.
+func (r *renderer) table(t *markdown.Table, first, rest string) {
+	cells := func(row []*markdown.Text, style string) [][]span {
+		var cs [][]span
+		for _, c := range row {
+			var in []markdown.Inline
+			if c != nil {
+				in = c.Inline
+			}
+			cs = append(cs, r.inlines(nil, in, style))
+		}
+		return cs
+	}
+	rows := [][][]span{cells(t.Header, styleBold)}
+	for _, row := range t.Rows {
+		rows = append(rows, cells(row, ""))
+	}
+	var widths []int
+	for _, row := range rows {
+		for i, c := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(plainText(c)))
+		}
+	}
+	sep := r.style(" │ ", styleDim)
+	for i, row := range rows {
+		var b strings.Builder
+		for j, c := range row {
+			if j > 0 {
+				b.WriteString(sep)
+			}
+			cell := r.renderSpans(c)
+			pad := strings.Repeat(" ", widths[j]-utf8.RuneCountInString(plainText(c)))
+			if j < len(t.Align) && t.Align[j] == "right" {
+				cell = pad + cell
+			} else {
+				cell += pad
+			}
+			b.WriteString(cell)
+		}
+		if i > 0 {
+			first = rest
+		}
+		r.line(first, b.String())
+		if i == 0 {
+			var d strings.Builder
+			for j, w := range widths {
+				if j > 0 {
+					d.WriteString("─┼─")
+				}
+				d.WriteString(strings.Repeat("─", w))
+			}
+			r.line(rest, r.style(d.String(), styleDim))
+		}
+	}
+}
+
+// renderSpans returns the spans in their styles.
+func (r *renderer) renderSpans(spans []span) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(r.style(s.text, s.style))
+	}
+	return b.String()
+}
+
+// visibleWidth returns the number of columns s occupies,
+// ignoring ANSI escape sequences.
+func visibleWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		if s[i] < utf8.RuneSelf || utf8.RuneStart(s[i]) {
+			n++
+		}
+	}
+	return n
+}
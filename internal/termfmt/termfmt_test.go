@@ -0,0 +1,126 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package termfmt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkdown(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{
+			name:  "wrap",
+			in:    "The issue reports that generic type aliases crash the compiler.",
+			width: 30,
+			want: `The issue reports that generic
+type aliases crash the
+compiler.
+`,
+		},
+		{
+			name: "nowrap",
+			in:   "The issue reports that generic type aliases crash the compiler.",
+			want: "The issue reports that generic type aliases crash the compiler.\n",
+		},
+		{
+			name:  "long word",
+			in:    "see https://go.dev/doc/go1.24#language now",
+			width: 10,
+			want: `see
+https://go.dev/doc/go1.24#language
+now
+`,
+		},
+		{
+			name:  "heading and inlines",
+			in:    "## Overview\n\nA **bold** claim, *emphasized* `code` and [the FAQ](https://go.dev/doc/faq).",
+			width: 40,
+			want: `Overview
+
+A bold claim, emphasized code and the
+FAQ (https://go.dev/doc/faq).
+`,
+		},
+		{
+			name:  "lists",
+			in:    "* one\n* two is long enough to wrap\n  * nested\n\n9. nine\n10. ten",
+			width: 20,
+			want: `  • one
+  • two is long
+    enough to wrap
+      • nested
+
+ 9. nine
+10. ten
+`,
+		},
+		{
+			name:  "quote and code",
+			in:    "> quoted text to wrap\n\n    code that is not wrapped\n",
+			width: 15,
+			want: `│ quoted text
+│ to wrap
+
+    code that is not wrapped
+`,
+		},
+		{
+			name:  "rule",
+			in:    "a\n\n---\n\nb",
+			width: 5,
+			want:  "a\n\n─────\n\nb\n",
+		},
+		{
+			name: "table",
+			in:   "| name | count |\n|---|--:|\n| x | 1 |\n| long | 22 |\n",
+			want: `name │ count
+─────┼──────
+x    │     1
+long │    22
+`,
+		},
+		{
+			name: "hard break",
+			in:   "line one\\\nline two",
+			want: "line one\nline two\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Markdown(tc.in, Options{Width: tc.width})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Markdown(%q) mismatch (-want +got):\n%s", tc.in, diff)
+			}
+		})
+	}
+}
+
+func TestMarkdownColor(t *testing.T) {
+	in := "## Title\n\n**bold *both*** `code` [x](https://go.dev)"
+	got := Markdown(in, Options{Color: true})
+	want := "\x1b[1;36mTitle\x1b[0m\n\n" +
+		"\x1b[1mbold\x1b[0m \x1b[1;3mboth\x1b[0m \x1b[33mcode\x1b[0m \x1b[4mx\x1b[0m \x1b[2m(https://go.dev)\x1b[0m\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Markdown(%q) mismatch (-want +got):\n%s", in, diff)
+	}
+
+	// Escape sequences do not count toward the width.
+	in = strings.Repeat("**word** ", 10)
+	for _, line := range strings.Split(strings.TrimSuffix(Markdown(in, Options{Width: 20, Color: true}), "\n"), "\n") {
+		if w := visibleWidth(line); w > 20 {
+			t.Errorf("line %q has width %d, want <= 20", line, w)
+		}
+	}
+	if n := strings.Count(Markdown(in, Options{Width: 20, Color: true}), "\n"); n != 3 {
+		t.Errorf("got %d lines, want 3", n)
+	}
+}
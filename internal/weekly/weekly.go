@@ -0,0 +1,419 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package weekly produces a weekly digest of the activity in GitHub
+// projects: the new issues grouped by theme, the hot discussions and
+// the issues closed during the week, for release managers who would
+// otherwise write the report by hand.
+//
+// A [Reporter] computes the report for each week (Monday to Monday, UTC)
+// once the week is over, using an LLM to group the new issues by theme.
+// It stores the report in the database:
+//
+//	(weekly.Report, $project, $start) -> JSON [Report]
+//
+// where $start is the start of the week in Unix seconds.
+// If configured with [Reporter.PublishTo], it also posts the report as
+// a comment on a GitHub discussion, through the action log
+// (action kind "weekly.Post", keyed by ($project, $start)).
+package weekly
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/discussion"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// A Reporter computes and publishes weekly reports.
+type Reporter struct {
+	slog *slog.Logger
+	db   storage.DB
+	gh   *github.Client
+	lc   *llmapp.Client
+	disc *discussion.Client
+
+	projects        map[string]bool
+	publish         map[string]int64 // project → discussion to post reports on
+	hotComments     int
+	requireApproval bool
+	logAction       actions.BeforeFunc
+}
+
+const (
+	reportKind = "weekly.Report"
+	actionKind = "weekly.Post"
+
+	// defaultHotComments is the number of comments in a week
+	// that make a discussion hot.
+	defaultHotComments = 10
+	// maxHot is the maximum number of hot discussions in a report.
+	maxHot = 10
+)
+
+// New returns a new Reporter that reads GitHub issues from db (as
+// stored by gh), groups them using lc, stores its reports in db, and
+// posts them on discussions using disc.
+// disc may be nil if reports are not published.
+func New(lg *slog.Logger, db storage.DB, gh *github.Client, lc *llmapp.Client, disc *discussion.Client) *Reporter {
+	r := &Reporter{
+		slog:            lg,
+		db:              db,
+		gh:              gh,
+		lc:              lc,
+		disc:            disc,
+		projects:        make(map[string]bool),
+		publish:         make(map[string]int64),
+		hotComments:     defaultHotComments,
+		requireApproval: true,
+	}
+	r.logAction = actions.Register(actionKind, &actioner{r})
+	return r
+}
+
+// EnableProject enables reports for the given GitHub project (for example "golang/go").
+func (r *Reporter) EnableProject(project string) {
+	r.projects[project] = true
+}
+
+// PublishTo enables reports for the GitHub project and configures the
+// Reporter to post each of them as a comment on the project's discussion
+// with the given number, which must have been synced by the discussion client.
+func (r *Reporter) PublishTo(project string, discussion int64) {
+	r.projects[project] = true
+	r.publish[project] = discussion
+}
+
+// SetHotComments sets the number of comments during a week
+// that make an issue or pull request a hot discussion.
+// The default is 10.
+func (r *Reporter) SetHotComments(n int) {
+	r.hotComments = n
+}
+
+// RequireApproval configures the Reporter to require approval
+// for posting reports, which is the default.
+func (r *Reporter) RequireApproval() {
+	r.requireApproval = true
+}
+
+// AutoApprove configures the Reporter to post reports
+// without approval.
+func (r *Reporter) AutoApprove() {
+	r.requireApproval = false
+}
+
+// A Report is the weekly digest of a project.
+type Report struct {
+	Project    string
+	Start, End time.Time // the week, from Start up to but not including End
+	Time       time.Time // when the report was computed
+	Themes     []*Theme  // new issues, grouped by theme
+	Hot        []*Hot    // hot discussions, by decreasing comments
+	Closed     []*Issue  // issues closed during the week
+}
+
+// An Issue identifies an issue or pull request in a [Report].
+type Issue struct {
+	Number int64
+	Title  string
+	URL    string
+}
+
+// A Theme is a group of new issues with a common theme.
+type Theme struct {
+	Name    string
+	Summary string
+	Issues  []*Issue
+}
+
+// A Hot is a hot discussion: an issue or pull request with
+// many comments during the week.
+type Hot struct {
+	Issue
+	Comments int // comments during the week
+}
+
+// NewIssues returns the number of new issues in the report.
+func (rep *Report) NewIssues() int {
+	n := 0
+	for _, t := range rep.Themes {
+		n += len(t.Issues)
+	}
+	return n
+}
+
+// Run computes, stores and (if configured) logs actions to publish
+// the report for each enabled project for the last full week,
+// unless it has already been computed.
+func (r *Reporter) Run(ctx context.Context) error {
+	return r.run(ctx, time.Now())
+}
+
+func (r *Reporter) run(ctx context.Context, now time.Time) error {
+	end := weekStart(now)
+	start := end.AddDate(0, 0, -7)
+	var errs []error
+	for _, project := range slices.Sorted(maps.Keys(r.projects)) {
+		if _, ok := r.Report(project, start); ok {
+			continue
+		}
+		rep, err := r.Compute(ctx, project, start, end)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rep.Time = now
+		r.slog.Info("weekly.Reporter report", "project", project, "start", start, "new", rep.NewIssues(), "hot", len(rep.Hot), "closed", len(rep.Closed))
+		r.db.Set(ordered.Encode(reportKind, project, start.Unix()), storage.JSON(rep))
+		r.db.Flush()
+		if err := r.logPost(project, rep); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// weekStart returns the start of the week (Monday, 00:00 UTC) containing t.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Report returns the report for the project and the week
+// starting at start, stored by [Reporter.Run].
+func (r *Reporter) Report(project string, start time.Time) (*Report, bool) {
+	data, ok := r.db.Get(ordered.Encode(reportKind, project, start.Unix()))
+	if !ok {
+		return nil, false
+	}
+	return r.decode(project, data), true
+}
+
+// Latest returns the most recent report stored for the project by [Reporter.Run].
+func (r *Reporter) Latest(project string) (*Report, bool) {
+	var data []byte
+	for _, val := range r.db.Scan(ordered.Encode(reportKind, project), ordered.Encode(reportKind, project, ordered.Inf)) {
+		data = val()
+	}
+	if data == nil {
+		return nil, false
+	}
+	return r.decode(project, data), true
+}
+
+func (r *Reporter) decode(project string, data []byte) *Report {
+	var rep Report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		r.db.Panic("weekly.Reporter decode", "project", project, "err", err)
+	}
+	return &rep
+}
+
+// Compute computes the report for the project and the week
+// from start up to end from the issues in the database.
+// It returns an error if the LLM cannot group the new issues.
+func (r *Reporter) Compute(ctx context.Context, project string, start, end time.Time) (*Report, error) {
+	in := func(ts string) bool {
+		t, err := time.Parse(time.RFC3339, ts)
+		return err == nil && !t.Before(start) && t.Before(end)
+	}
+	rep := &Report{Project: project, Start: start, End: end}
+	var created []*github.Issue
+	for iss := range github.LookupIssues(r.db, project, 0, -1) {
+		if iss.PullRequest == nil && in(iss.CreatedAt) {
+			created = append(created, iss)
+		}
+		if iss.PullRequest == nil && iss.State == "closed" && in(iss.ClosedAt) {
+			rep.Closed = append(rep.Closed, toIssue(iss))
+		}
+		if t, err := time.Parse(time.RFC3339, iss.UpdatedAt); err != nil || t.Before(start) {
+			continue
+		}
+		n := 0
+		for ic := range r.gh.Comments(iss) {
+			if in(ic.CreatedAt) {
+				n++
+			}
+		}
+		if n >= r.hotComments {
+			rep.Hot = append(rep.Hot, &Hot{Issue: *toIssue(iss), Comments: n})
+		}
+	}
+	slices.SortStableFunc(rep.Hot, func(x, y *Hot) int {
+		return cmp.Compare(y.Comments, x.Comments)
+	})
+	rep.Hot = rep.Hot[:min(len(rep.Hot), maxHot)]
+
+	if len(created) == 0 {
+		return rep, nil
+	}
+	var docs []*llmapp.Doc
+	for _, iss := range created {
+		docs = append(docs, iss.ToLLMDoc())
+	}
+	res, err := r.lc.GroupByTheme(ctx, docs)
+	if err != nil {
+		return nil, fmt.Errorf("weekly.Reporter %s: %w", project, err)
+	}
+	grouped := make([]bool, len(created))
+	for _, t := range res.Themes {
+		theme := &Theme{Name: t.Name, Summary: t.Summary}
+		for _, i := range t.Docs {
+			theme.Issues = append(theme.Issues, toIssue(created[i]))
+			grouped[i] = true
+		}
+		if len(theme.Issues) > 0 {
+			rep.Themes = append(rep.Themes, theme)
+		}
+	}
+	other := &Theme{Name: "Other"}
+	for i, iss := range created {
+		if !grouped[i] {
+			other.Issues = append(other.Issues, toIssue(iss))
+		}
+	}
+	if len(other.Issues) > 0 {
+		rep.Themes = append(rep.Themes, other)
+	}
+	return rep, nil
+}
+
+func toIssue(iss *github.Issue) *Issue {
+	return &Issue{Number: iss.Number, Title: iss.Title, URL: iss.HTMLURL}
+}
+
+// Markdown returns the report as Markdown, as posted on GitHub.
+func (rep *Report) Markdown() string {
+	var b strings.Builder
+	item := func(iss *Issue, suffix string) {
+		fmt.Fprintf(&b, "- [#%d](%s) %s%s\n", iss.Number, iss.URL, markdownEscape(iss.Title), suffix)
+	}
+	fmt.Fprintf(&b, "## Weekly digest: %s to %s\n\n", rep.Start.Format("Jan 2"), rep.End.AddDate(0, 0, -1).Format("Jan 2, 2006"))
+
+	fmt.Fprintf(&b, "### New issues (%d)\n\n", rep.NewIssues())
+	if len(rep.Themes) == 0 {
+		b.WriteString("None.\n\n")
+	}
+	for _, t := range rep.Themes {
+		fmt.Fprintf(&b, "**%s**", markdownEscape(t.Name))
+		if t.Summary != "" {
+			fmt.Fprintf(&b, ": %s", t.Summary)
+		}
+		b.WriteString("\n\n")
+		for _, iss := range t.Issues {
+			item(iss, "")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("### Hot discussions\n\n")
+	if len(rep.Hot) == 0 {
+		b.WriteString("None.\n\n")
+	}
+	for _, h := range rep.Hot {
+		item(&h.Issue, fmt.Sprintf(" (%d comments)", h.Comments))
+	}
+	if len(rep.Hot) > 0 {
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "### Closed issues (%d)\n\n", len(rep.Closed))
+	if len(rep.Closed) == 0 {
+		b.WriteString("None.\n\n")
+	}
+	for _, iss := range rep.Closed {
+		item(iss, "")
+	}
+	if len(rep.Closed) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("<sub>(Themes generated by AI.)</sub>\n")
+	return b.String()
+}
+
+// An action posts a report on a discussion.
+type action struct {
+	Discussion *discussion.Discussion
+	Body       string
+}
+
+// result is the result of an [action].
+type result struct {
+	URL string // URL of the posted comment
+}
+
+// logPost logs an action to post the report, if the project
+// publishes its reports.
+func (r *Reporter) logPost(project string, rep *Report) error {
+	num, ok := r.publish[project]
+	if !ok {
+		return nil
+	}
+	if r.disc == nil {
+		return errors.New("weekly.Reporter: no discussion client")
+	}
+	d := r.disc.LookupDiscussion(project, num)
+	if d == nil {
+		return fmt.Errorf("weekly.Reporter: discussion %s#%d not found", project, num)
+	}
+	act := &action{Discussion: d, Body: rep.Markdown()}
+	r.logAction(r.db, ordered.Encode(project, rep.Start.Unix()), storage.JSON(act), r.requireApproval)
+	return nil
+}
+
+// actioner implements [actions.Actioner].
+type actioner struct {
+	r *Reporter
+}
+
+func (ar *actioner) Run(ctx context.Context, data []byte) ([]byte, error) {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	url, err := ar.r.disc.PostComment(ctx, a.Discussion, a.Body)
+	if err != nil {
+		return nil, fmt.Errorf("weekly.Reporter post %s: %w", a.Discussion.URL, err)
+	}
+	return storage.JSON(&result{URL: url}), nil
+}
+
+func (ar *actioner) ForDisplay(data []byte) string {
+	var a action
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+	return "post weekly report to: " + a.Discussion.URL + "\n" + a.Body
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"\\", `\\`,
+	"_", `\_`,
+	"*", `\*`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"&", `\&`,
+)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
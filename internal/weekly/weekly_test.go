@@ -0,0 +1,201 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package weekly
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/actions"
+	"golang.org/x/oscar/internal/discussion"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/llm"
+	"golang.org/x/oscar/internal/llmapp"
+	"golang.org/x/oscar/internal/secret"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestRun(t *testing.T) {
+	check := testutil.Checker(t)
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	disc := discussion.New(ctx, lg, secret.Empty(), db)
+	const project = "golang/go"
+
+	day := func(d int) string {
+		return time.Date(2025, 6, d, 12, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+	add := func(num int64, title, created, updated string, edits ...func(*github.Issue)) {
+		iss := &github.Issue{
+			Number:    num,
+			Title:     title,
+			HTMLURL:   fmt.Sprintf("https://github.com/golang/go/issues/%d", num),
+			CreatedAt: created,
+			UpdatedAt: updated,
+			State:     "open",
+		}
+		for _, edit := range edits {
+			edit(iss)
+		}
+		gh.Testing().AddIssue(project, iss)
+	}
+	add(1, "runtime: crash", day(3), day(3))
+	add(2, "doc: typo", day(4), day(4))
+	add(3, "runtime: hang", day(5), day(5))
+	add(4, "proposal: x", "2025-05-01T00:00:00Z", day(6))
+	for _, d := range []int{1, 6, 7, 8} { // only 3 during the week
+		gh.Testing().AddIssueComment(project, 4, &github.IssueComment{Body: "+1", CreatedAt: day(d)})
+	}
+	add(5, "old bug", "2025-01-01T00:00:00Z", day(6), func(iss *github.Issue) {
+		iss.State, iss.ClosedAt = "closed", day(6)
+	})
+	add(6, "fix it", day(3), day(3), func(iss *github.Issue) {
+		iss.PullRequest = new(struct{})
+	})
+	add(7, "next week", day(9), day(9))
+
+	gen := llm.TestContentGenerator("weekly-test-generator",
+		func(context.Context, *llm.Schema, []llm.Part) (string, error) {
+			return `{"themes": [{"name": "runtime", "summary": "Runtime failures.", "docs": [0, 2]}]}`, nil
+		})
+	num := disc.Testing().AddDiscussion(project, &discussion.Discussion{Title: "Weekly digests"})
+
+	r := New(lg, db, gh, llmapp.New(lg, gen, db), disc)
+	r.PublishTo(project, num)
+	r.SetHotComments(3)
+	r.AutoApprove()
+	now := time.Date(2025, 6, 11, 8, 0, 0, 0, time.UTC) // a Wednesday
+	check(r.run(ctx, now))
+	check(actions.Run(ctx, lg, db))
+
+	start := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	got, ok := r.Report(project, start)
+	if !ok {
+		t.Fatal("no report")
+	}
+	issue := func(n int64, title string) *Issue {
+		return &Issue{Number: n, Title: title, URL: fmt.Sprintf("https://github.com/golang/go/issues/%d", n)}
+	}
+	want := &Report{
+		Project: project,
+		Start:   start,
+		End:     start.AddDate(0, 0, 7),
+		Time:    now,
+		Themes: []*Theme{
+			{Name: "runtime", Summary: "Runtime failures.", Issues: []*Issue{issue(1, "runtime: crash"), issue(3, "runtime: hang")}},
+			{Name: "Other", Issues: []*Issue{issue(2, "doc: typo")}},
+		},
+		Hot:    []*Hot{{Issue: *issue(4, "proposal: x"), Comments: 3}},
+		Closed: []*Issue{issue(5, "old bug")},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%s", diff)
+	}
+	if latest, ok := r.Latest(project); !ok || !cmp.Equal(latest, got) {
+		t.Errorf("Latest = %v, want %v", latest, got)
+	}
+
+	wantEdits := []*discussion.TestingEdit{{
+		URL: fmt.Sprintf("https://github.com/golang/go/discussions/%d", num),
+		Body: `## Weekly digest: Jun 2 to Jun 8, 2025
+
+### New issues (3)
+
+**runtime**: Runtime failures.
+
+- [#1](https://github.com/golang/go/issues/1) runtime: crash
+- [#3](https://github.com/golang/go/issues/3) runtime: hang
+
+**Other**
+
+- [#2](https://github.com/golang/go/issues/2) doc: typo
+
+### Hot discussions
+
+- [#4](https://github.com/golang/go/issues/4) proposal: x (3 comments)
+
+### Closed issues (1)
+
+- [#5](https://github.com/golang/go/issues/5) old bug
+
+<sub>(Themes generated by AI.)</sub>
+`}}
+	if diff := cmp.Diff(wantEdits, disc.Testing().Edits()); diff != "" {
+		t.Errorf("posted mismatch (-want +got):\n%s", diff)
+	}
+
+	// The report is computed and posted once.
+	check(r.run(ctx, now.Add(24*time.Hour)))
+	check(actions.Run(ctx, lg, db))
+	if n := len(disc.Testing().Edits()); n != 1 {
+		t.Errorf("posted %d reports, want 1", n)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	check := testutil.Checker(t)
+	ctx := context.Background()
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	disc := discussion.New(ctx, lg, secret.Empty(), db)
+	const project = "golang/go"
+
+	created := time.Date(2025, 6, 3, 12, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	gh.Testing().AddIssue(project, &github.Issue{
+		Number:    1,
+		Title:     "runtime: crash",
+		HTMLURL:   "https://github.com/golang/go/issues/1",
+		CreatedAt: created,
+		UpdatedAt: created,
+		State:     "open",
+	})
+	num := disc.Testing().AddDiscussion(project, &discussion.Discussion{Title: "Weekly digests"})
+
+	// Outside testing mode, only dry-run mode keeps
+	// the digest from being posted.
+	disc.DisableTesting()
+	disc.EnableDryRun()
+	r := New(lg, db, gh, llmapp.New(lg, llm.EchoContentGenerator(), db), disc)
+	r.PublishTo(project, num)
+	r.AutoApprove()
+	check(r.run(ctx, time.Date(2025, 6, 11, 8, 0, 0, 0, time.UTC)))
+	check(actions.Run(ctx, lg, db))
+
+	if edits := disc.Testing().Edits(); len(edits) != 0 {
+		t.Errorf("posted %v in testing mode, want none", edits)
+	}
+	diverted := slices.Collect(disc.DivertedComments(0))
+	wantURL := fmt.Sprintf("https://github.com/golang/go/discussions/%d", num)
+	if len(diverted) != 1 || diverted[0].Edit.URL != wantURL || !strings.Contains(diverted[0].Edit.Body, "## Weekly digest") {
+		t.Errorf("DivertedComments = %v, want one weekly digest for %s", diverted, wantURL)
+	}
+	for e := range actions.ScanAfterDBTime(lg, db, 0, nil) {
+		if e.Kind == actionKind && e.Error != "" {
+			t.Errorf("action error: %s", e.Error)
+		}
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	monday := time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)
+	for _, tm := range []time.Time{
+		monday,
+		monday.Add(36 * time.Hour),
+		time.Date(2025, 6, 15, 23, 59, 0, 0, time.UTC), // Sunday
+	} {
+		if got := weekStart(tm); !got.Equal(monday) {
+			t.Errorf("weekStart(%s) = %s, want %s", tm, got, monday)
+		}
+	}
+}
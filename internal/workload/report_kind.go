@@ -0,0 +1,73 @@
+// Code generated by "kindgen -type Report -kind workload.Report -key Project"; DO NOT EDIT.
+
+package workload
+
+import (
+	"iter"
+
+	"golang.org/x/oscar/internal/storage"
+	"rsc.io/ordered"
+)
+
+// reportKind is the key kind of a stored [Report]:
+//
+//	(workload.Report, Project) -> [Report]
+const reportKind = "workload.Report"
+
+// reportVersion is the encoding version of a stored [Report].
+const reportVersion = 1
+
+// reportKey returns the database key of the [Report] with the given key fields.
+func reportKey(project string) []byte {
+	return ordered.Encode(reportKind, project)
+}
+
+// getReport returns the stored [Report] with the given key fields, if any.
+func getReport(db storage.DB, project string) (*Report, bool) {
+	val, ok := db.Get(reportKey(project))
+	if !ok {
+		return nil, false
+	}
+	return decodeReport(db, val), true
+}
+
+// setReport stores x under the key made from its key fields.
+func setReport(db storage.DB, x *Report) {
+	db.Set(reportKey(x.Project), storage.EncodeRecord(reportVersion, x))
+}
+
+// deleteReport deletes the stored [Report] with the given key fields, if any.
+func deleteReport(db storage.DB, project string) {
+	db.Delete(reportKey(project))
+}
+
+// scanReport returns the stored [Report] records, in key order.
+func scanReport(db storage.DB) iter.Seq[*Report] {
+	return scanReportRange(db, ordered.Encode(reportKind), ordered.Encode(reportKind, ordered.Inf))
+}
+
+// scanReportRange returns the stored [Report] records
+// with keys in the range [start, end], in key order.
+func scanReportRange(db storage.DB, start, end []byte) iter.Seq[*Report] {
+	return func(yield func(*Report) bool) {
+		for _, val := range db.Scan(start, end) {
+			if !yield(decodeReport(db, val())) {
+				return
+			}
+		}
+	}
+}
+
+// decodeReport decodes a stored [Report].
+func decodeReport(db storage.DB, val []byte) *Report {
+	var x Report
+	v, err := storage.DecodeRecord(val, &x)
+	if err != nil {
+		// unreachable unless database corruption
+		db.Panic("decode workload.Report", "err", err)
+	}
+	if v > reportVersion {
+		db.Panic("decode workload.Report: stored with newer encoding", "version", v, "known", reportVersion)
+	}
+	return &x
+}
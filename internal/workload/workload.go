@@ -0,0 +1,249 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package workload reports the open-issue and review load of each
+// maintainer area of a GitHub project and of each maintainer, to help
+// leads rebalance triage rotations.
+//
+// An area is an issue label, such as "gopls" or "compiler/runtime"
+// (see [Analyzer.SetAreas]). The maintainers of an area are the users
+// who have been assigned its issues and pull requests recently.
+// An area's load is its open issues and pull requests; a maintainer's
+// load is the open issues and pull requests assigned to them.
+//
+// An [Analyzer] computes a [Report] for each enabled project at most
+// once a day and stores it in the database:
+//
+//	(workload.Report, $project) -> [Report], encoded by [storage.EncodeRecord]
+package workload
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+)
+
+// An Analyzer computes workload reports.
+type Analyzer struct {
+	slog     *slog.Logger
+	db       storage.DB
+	projects map[string][]string // project → area labels; nil for all labels
+	window   time.Duration
+	interval time.Duration
+}
+
+const (
+	// defaultWindow is how far back assignments count toward
+	// the maintainers of an area.
+	defaultWindow = 365 * 24 * time.Hour
+	// defaultInterval is how often reports are recomputed.
+	defaultInterval = 24 * time.Hour
+)
+
+// New returns a new Analyzer that reads GitHub issues from db
+// (as stored by a [github.Client]) and stores its reports in db.
+func New(lg *slog.Logger, db storage.DB) *Analyzer {
+	return &Analyzer{
+		slog:     lg,
+		db:       db,
+		projects: make(map[string][]string),
+		window:   defaultWindow,
+		interval: defaultInterval,
+	}
+}
+
+// EnableProject enables reports for the given GitHub project (for example "golang/go").
+func (a *Analyzer) EnableProject(project string) {
+	if _, ok := a.projects[project]; !ok {
+		a.projects[project] = nil
+	}
+}
+
+// SetAreas enables reports for the GitHub project and sets its
+// maintainer areas to the issues with the given labels.
+// An issue with several of the labels belongs to each of their areas;
+// an issue with none belongs to no area.
+// By default, every label is an area.
+func (a *Analyzer) SetAreas(project string, labels ...string) {
+	a.projects[project] = slices.Clone(labels)
+}
+
+// SetWindow sets how long an assignment counts toward the maintainers
+// of an area, measured from the last update of the issue.
+// The default is one year.
+func (a *Analyzer) SetWindow(d time.Duration) {
+	a.window = d
+}
+
+//go:generate go run golang.org/x/oscar/internal/devtools/cmd/kindgen -type Report -kind workload.Report -key Project
+
+// A Report is the workload of a project's areas and maintainers.
+type Report struct {
+	Project     string
+	Time        time.Time         // when the report was computed
+	Areas       []*AreaLoad       // by decreasing open issues and pull requests
+	Maintainers []*MaintainerLoad // by decreasing open issues and pull requests
+	NoArea      AreaLoad          // open issues and pull requests not in any area
+}
+
+// An AreaLoad is the workload of one area.
+type AreaLoad struct {
+	Area        string
+	OpenIssues  int      // open issues in the area
+	OpenPulls   int      // open pull requests in the area
+	Unassigned  int      // open issues and pull requests without an assignee
+	Maintainers []string // recent assignees, most frequent first
+}
+
+// PerMaintainer returns the area's open issues and pull requests
+// per maintainer, or 0 if the area has no maintainers.
+func (l *AreaLoad) PerMaintainer() float64 {
+	if len(l.Maintainers) == 0 {
+		return 0
+	}
+	return float64(l.OpenIssues+l.OpenPulls) / float64(len(l.Maintainers))
+}
+
+// A MaintainerLoad is the workload of one maintainer.
+type MaintainerLoad struct {
+	Login      string
+	Areas      []string // areas the maintainer has recently been assigned in
+	OpenIssues int      // open issues assigned to the maintainer
+	OpenPulls  int      // open pull requests assigned to the maintainer
+	Closed     int      // assigned issues and pull requests closed within the window
+}
+
+// Run computes and stores a report for each enabled project
+// whose last report is more than a day old.
+func (a *Analyzer) Run(ctx context.Context) error {
+	return a.run(time.Now())
+}
+
+func (a *Analyzer) run(now time.Time) error {
+	for _, project := range slices.Sorted(maps.Keys(a.projects)) {
+		if r, ok := a.Report(project); ok && now.Sub(r.Time) < a.interval {
+			continue
+		}
+		r := a.Compute(project, now)
+		a.slog.Info("workload.Analyzer report", "project", project, "areas", len(r.Areas), "maintainers", len(r.Maintainers))
+		setReport(a.db, r)
+	}
+	return nil
+}
+
+// Report returns the last report stored for the project by [Analyzer.Run].
+func (a *Analyzer) Report(project string) (*Report, bool) {
+	return getReport(a.db, project)
+}
+
+// Compute computes the report for the project as of now
+// from the issues in the database.
+func (a *Analyzer) Compute(project string, now time.Time) *Report {
+	areaLabels, all := a.projects[project], a.projects[project] == nil
+	areas := make(map[string]*AreaLoad)
+	for _, l := range areaLabels {
+		areas[l] = &AreaLoad{Area: l}
+	}
+	assigned := make(map[string]map[string]int) // area → login → assignments
+	people := make(map[string]*MaintainerLoad)
+	person := func(login string) *MaintainerLoad {
+		m := people[login]
+		if m == nil {
+			m = &MaintainerLoad{Login: login}
+			people[login] = m
+		}
+		return m
+	}
+
+	r := &Report{Project: project, Time: now}
+	for issue := range github.LookupIssues(a.db, project, 0, -1) {
+		var in []*AreaLoad
+		for _, l := range issue.Labels {
+			area := areas[l.Name]
+			if area == nil && all {
+				area = &AreaLoad{Area: l.Name}
+				areas[l.Name] = area
+			}
+			if area != nil {
+				in = append(in, area)
+			}
+		}
+
+		if issue.State != "closed" {
+			if len(in) == 0 {
+				in = []*AreaLoad{&r.NoArea}
+			}
+			for _, area := range in {
+				if issue.PullRequest != nil {
+					area.OpenPulls++
+				} else {
+					area.OpenIssues++
+				}
+				if len(issue.Assignees) == 0 {
+					area.Unassigned++
+				}
+			}
+			for _, u := range issue.Assignees {
+				if issue.PullRequest != nil {
+					person(u.Login).OpenPulls++
+				} else {
+					person(u.Login).OpenIssues++
+				}
+			}
+		}
+
+		// Recent assignments determine the maintainers of areas.
+		updated, err := time.Parse(time.RFC3339, issue.UpdatedAt)
+		if err != nil || now.Sub(updated) > a.window {
+			continue
+		}
+		for _, u := range issue.Assignees {
+			if issue.State == "closed" {
+				person(u.Login).Closed++
+			}
+			for _, area := range in {
+				if area == &r.NoArea {
+					continue
+				}
+				if assigned[area.Area] == nil {
+					assigned[area.Area] = make(map[string]int)
+				}
+				assigned[area.Area][u.Login]++
+			}
+		}
+	}
+
+	for name, area := range areas {
+		counts := assigned[name]
+		area.Maintainers = slices.SortedFunc(maps.Keys(counts), func(x, y string) int {
+			return cmp.Or(cmp.Compare(counts[y], counts[x]), cmp.Compare(x, y))
+		})
+		for _, login := range area.Maintainers {
+			m := person(login)
+			m.Areas = append(m.Areas, name)
+		}
+		if all && area.OpenIssues+area.OpenPulls == 0 && len(area.Maintainers) == 0 {
+			continue // an unused label
+		}
+		r.Areas = append(r.Areas, area)
+	}
+	slices.SortFunc(r.Areas, func(x, y *AreaLoad) int {
+		return cmp.Or(cmp.Compare(y.OpenIssues+y.OpenPulls, x.OpenIssues+x.OpenPulls), cmp.Compare(x.Area, y.Area))
+	})
+
+	for _, m := range people {
+		slices.Sort(m.Areas)
+		r.Maintainers = append(r.Maintainers, m)
+	}
+	slices.SortFunc(r.Maintainers, func(x, y *MaintainerLoad) int {
+		return cmp.Or(cmp.Compare(y.OpenIssues+y.OpenPulls, x.OpenIssues+x.OpenPulls), cmp.Compare(x.Login, y.Login))
+	})
+	return r
+}
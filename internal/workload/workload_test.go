@@ -0,0 +1,104 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package workload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oscar/internal/github"
+	"golang.org/x/oscar/internal/storage"
+	"golang.org/x/oscar/internal/testutil"
+)
+
+func TestCompute(t *testing.T) {
+	lg := testutil.Slogger(t)
+	db := storage.MemDB()
+	gh := github.New(lg, db, nil, nil)
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	recent := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	old := now.Add(-2 * defaultWindow).Format(time.RFC3339)
+
+	const project = "golang/go"
+	num := int64(0)
+	add := func(state, updated string, pull bool, labels []string, assignees ...string) {
+		num++
+		issue := &github.Issue{Number: num, State: state, UpdatedAt: updated}
+		for _, l := range labels {
+			issue.Labels = append(issue.Labels, github.Label{Name: l})
+		}
+		for _, a := range assignees {
+			issue.Assignees = append(issue.Assignees, github.User{Login: a})
+		}
+		if pull {
+			issue.PullRequest = new(struct{})
+		}
+		gh.Testing().AddIssue(project, issue)
+	}
+	gopls := []string{"gopls"}
+	add("open", recent, false, gopls, "alice")
+	add("open", recent, false, gopls)
+	add("open", recent, true, gopls, "bob")
+	add("closed", recent, false, gopls, "bob")
+	add("closed", old, false, gopls, "carol") // too old to count
+	add("open", recent, false, []string{"compiler/runtime", "NeedsFix"}, "dave")
+	add("open", recent, false, []string{"NeedsFix"})
+
+	a := New(lg, db)
+	a.SetAreas(project, "gopls", "compiler/runtime", "Documentation")
+	got := a.Compute(project, now)
+	want := &Report{
+		Project: project,
+		Time:    now,
+		Areas: []*AreaLoad{
+			{Area: "gopls", OpenIssues: 2, OpenPulls: 1, Unassigned: 1, Maintainers: []string{"bob", "alice"}},
+			{Area: "compiler/runtime", OpenIssues: 1, Maintainers: []string{"dave"}},
+			{Area: "Documentation"},
+		},
+		Maintainers: []*MaintainerLoad{
+			{Login: "alice", Areas: []string{"gopls"}, OpenIssues: 1},
+			{Login: "bob", Areas: []string{"gopls"}, OpenPulls: 1, Closed: 1},
+			{Login: "dave", Areas: []string{"compiler/runtime"}, OpenIssues: 1},
+		},
+		NoArea: AreaLoad{OpenIssues: 1, Unassigned: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Compute (-want, +got):\n%s", diff)
+	}
+	if got, want := got.Areas[0].PerMaintainer(), 1.5; got != want {
+		t.Errorf("PerMaintainer = %v, want %v", got, want)
+	}
+
+	// Without configured areas, every label in use is an area.
+	a = New(lg, db)
+	a.EnableProject(project)
+	var areas []string
+	for _, l := range a.Compute(project, now).Areas {
+		areas = append(areas, l.Area)
+	}
+	if want := []string{"gopls", "NeedsFix", "compiler/runtime"}; !cmp.Equal(areas, want) {
+		t.Errorf("areas = %q, want %q", areas, want)
+	}
+
+	// Run stores the report, and only recomputes it daily.
+	if err := a.run(now); err != nil {
+		t.Fatal(err)
+	}
+	add("open", recent, false, gopls)
+	if err := a.run(now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	r, ok := a.Report(project)
+	if !ok || !r.Time.Equal(now) {
+		t.Fatalf("Report = %v, %v; want report at %v", r, ok, now)
+	}
+	if err := a.run(now.Add(defaultInterval)); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := a.Report(project); r.Areas[0].OpenIssues != 3 {
+		t.Errorf("after a day, gopls has %d open issues, want 3", r.Areas[0].OpenIssues)
+	}
+}